@@ -10,23 +10,27 @@ It exposes REST endpoints that the Go backend calls to:
 
 import os
 import asyncio
+import json
 import logging
+import re
+import shutil
 from typing import Dict, Any, List, Optional
 from datetime import datetime
 from pathlib import Path
 from dataclasses import dataclass, field
 from enum import Enum
 import threading
+import time
 import uuid
 
-from fastapi import FastAPI, HTTPException, BackgroundTasks
+from fastapi import FastAPI, HTTPException, BackgroundTasks, Request
 from fastapi.middleware.cors import CORSMiddleware
 from pydantic import BaseModel
 import uvicorn
 
 # Import our agents
-from .mssql_extractor import MSSQLExtractor, extract_mssql_metadata
-from .dbt_generator import DBTProjectGenerator, create_dbt_project
+from .mssql_extractor import MSSQLExtractor, extract_mssql_metadata, off_peak_open
+from .dbt_generator import DBTProjectGenerator, create_dbt_project, staging_model_name
 from .validation_agent import (
     ValidationAgent,
     validate_migration,
@@ -40,6 +44,7 @@ from .dbt_executor import (
     DeploymentResult, DeploymentStatus, deploy_to_warehouse
 )
 from .data_quality_agent import DataQualityAgent, scan_source_data_quality
+from .pii_masking import PIIMasker
 from .guardian_agent import (
     GuardianAgent, get_guardian, SecurityException,
     ComplianceLogger, get_compliance_logger, ComplianceEventType,
@@ -64,6 +69,10 @@ ANTHROPIC_API_KEY = os.getenv("ANTHROPIC_API_KEY")
 if ANTHROPIC_AVAILABLE and ANTHROPIC_API_KEY:
     anthropic_client = anthropic.Anthropic(api_key=ANTHROPIC_API_KEY)
 
+# The data region this instance serves (eu or us); empty for the default instance.
+# A regional instance refuses migrations pinned to another region.
+DATA_REGION = os.getenv("DATA_REGION", "")
+
 # Configure logging
 logging.basicConfig(
     level=logging.INFO,
@@ -84,6 +93,9 @@ class SourceConnection(BaseModel):
     username: str = ""
     password: str = ""
     use_windows_auth: bool = False
+    # Read settings that keep extraction from blocking the source's workload
+    read_replica: bool = False
+    read_isolation: str = ""
 
 
 class MigrationStartRequest(BaseModel):
@@ -94,6 +106,39 @@ class MigrationStartRequest(BaseModel):
     target_warehouse: str = "snowflake"
     tables: Optional[List[str]] = None
     include_views: bool = False
+    # Per-table row filters and excluded columns for the staging models
+    table_filters: Optional[List[Dict[str, Any]]] = None
+    # The organization's column masking rules (mask, hash or drop)
+    column_masks: Optional[List[Dict[str, Any]]] = None
+    # The dbt tests to generate, with every default filled in by the backend
+    test_coverage: Optional[Dict[str, Any]] = None
+    # The project's layers, folders and model naming, with every default filled in
+    scaffolding: Optional[Dict[str, Any]] = None
+    # The organization's PII policy; profiled sample values are masked with it
+    pii_policy: Optional[Dict[str, Any]] = None
+    # Tables a resumed run already generated; their models are kept as they are
+    skip_tables: Optional[List[str]] = None
+    # The migration's exposures, rendered by the backend, for models/exposures.yml
+    exposures_yaml: Optional[str] = None
+    # The source connection's extraction throttle: max_parallel_queries, fetch_size,
+    # timezone and off_peak_windows
+    throttle: Optional[Dict[str, Any]] = None
+    # The target connection: type, adapter, database, workspace, lakehouse and tsql.
+    # Its type overrides target_warehouse.
+    target: Optional[Dict[str, Any]] = None
+    # The organization's model name prefixes: staging_prefix, intermediate_prefix,
+    # fact_prefix and dimension_prefix
+    naming: Optional[Dict[str, Any]] = None
+    # Tables to generate as dbt snapshots (SCD type 2): table, unique_key, strategy,
+    # updated_at, check_cols and hard_deletes, validated by the backend
+    snapshots: Optional[List[Dict[str, Any]]] = None
+    # env_var() values the generated project needs, by variable name. They are set in
+    # dbt's environment and never written into the project.
+    secrets: Optional[Dict[str, str]] = None
+    # The organization's data region and that region's bucket; the generated project is
+    # stored in the bucket and reported by its s3:// location
+    data_region: Optional[str] = None
+    artifact_bucket: Optional[str] = None
 
 
 class MigrationStatusResponse(BaseModel):
@@ -114,6 +159,7 @@ class MigrationStatus(str, Enum):
     RUNNING = "running"
     COMPLETED = "completed"
     FAILED = "failed"
+    CANCELLED = "cancelled"
 
 
 @dataclass
@@ -129,6 +175,18 @@ class MigrationState:
     total_models: int = 0
     metadata: Optional[Dict[str, Any]] = None
     dbt_project_path: Optional[str] = None
+    test_coverage: Optional[Dict[str, Any]] = None
+    scaffolding: Optional[Dict[str, Any]] = None
+    # Set by a stop request; the workflow stops at the next phase boundary
+    cancel_requested: bool = False
+    # LLM tokens per phase, sent with the phase metrics: {"validating": {"prompt_tokens": 0, ...}}
+    token_usage: Dict[str, Dict[str, int]] = field(default_factory=dict)
+    # env_var() values the generated project needs; kept in memory for dbt runs only
+    secrets: Dict[str, str] = field(default_factory=dict, repr=False)
+    data_region: str = ""
+    artifact_bucket: Optional[str] = None
+    # Where the project is stored in the region's bucket: s3://bucket/migrations/<id>/<project>
+    artifacts_uri: Optional[str] = None
     started_at: Optional[datetime] = None
     completed_at: Optional[datetime] = None
 
@@ -175,6 +233,18 @@ def create_migration(migration_id: int) -> MigrationState:
 # =============================================================================
 
 GO_BACKEND_URL = os.getenv("GO_BACKEND_URL", "http://localhost:8080")
+# Shared with the backend, whose /internal routes require it on the public port
+INTERNAL_SERVICE_TOKEN = os.getenv("INTERNAL_SERVICE_TOKEN", "")
+
+# How long a stop request waits for the workflow to reach a phase boundary and stop
+STOP_ACK_TIMEOUT = float(os.getenv("STOP_ACK_TIMEOUT", "20"))
+
+
+def service_headers() -> Dict[str, str]:
+    """Headers that authenticate a callback to the backend's /internal routes"""
+    if not INTERNAL_SERVICE_TOKEN:
+        return {}
+    return {"X-Service-Token": INTERNAL_SERVICE_TOKEN}
 
 
 async def notify_go_backend(
@@ -185,7 +255,8 @@ async def notify_go_backend(
     tables_count: Optional[int] = None,
     views_count: Optional[int] = None,
     foreign_keys_count: Optional[int] = None,
-    models_generated: Optional[int] = None
+    models_generated: Optional[int] = None,
+    metrics: Optional[List[Dict[str, Any]]] = None
 ):
     """Notify Go backend of migration status update"""
     import httpx
@@ -206,10 +277,13 @@ async def notify_go_backend(
                 payload["foreign_keys_count"] = foreign_keys_count
             if models_generated is not None:
                 payload["models_generated"] = models_generated
+            if metrics:
+                payload["metrics"] = metrics
 
             await client.patch(
                 f"{GO_BACKEND_URL}/api/v1/internal/migrations/{migration_id}/status",
                 json=payload,
+                headers=service_headers(),
                 timeout=10.0
             )
             logger.info(f"Notified Go backend: migration {migration_id} -> {status} ({progress}%)")
@@ -217,6 +291,105 @@ async def notify_go_backend(
         logger.error(f"Failed to notify Go backend: {e}")
 
 
+def upload_project_to_bucket(migration_id: int, project_path: Path, bucket: str) -> str:
+    """Copy a generated project into the region's bucket; returns its s3:// location"""
+    import boto3
+
+    s3 = boto3.client("s3")
+    prefix = f"migrations/{migration_id}/{project_path.name}"
+    for file_path in project_path.rglob("*"):
+        if file_path.is_file():
+            s3.upload_file(str(file_path), bucket, f"{prefix}/{file_path.relative_to(project_path).as_posix()}")
+    return f"s3://{bucket}/{prefix}"
+
+
+def record_token_usage(migration_id: int, phase: str, usage: Any) -> None:
+    """Add an LLM response's usage to the phase's token totals"""
+    with migrations_lock:
+        state = migrations_store.get(migration_id)
+        if state is None or usage is None:
+            return
+        totals = state.token_usage.setdefault(phase, {"prompt_tokens": 0, "completion_tokens": 0})
+        totals["prompt_tokens"] += int(getattr(usage, "input_tokens", 0) or 0)
+        totals["completion_tokens"] += int(getattr(usage, "output_tokens", 0) or 0)
+
+
+def phase_metrics(migration_id: int, phase: str, started: float, **counts: int) -> Dict[str, Any]:
+    """Resource usage of a finished phase, as recorded in the backend's migration_metrics.
+    The phase's LLM tokens count against the organization's monthly AI token budget."""
+    state = get_migration(migration_id)
+    tokens = (state.token_usage.get(phase) if state else None) or {}
+    return {
+        "phase": phase,
+        "duration_ms": int((time.monotonic() - started) * 1000),
+        "prompt_tokens": tokens.get("prompt_tokens", 0),
+        "completion_tokens": tokens.get("completion_tokens", 0),
+        **counts,
+    }
+
+
+def count_generated_lines(project_path: Path) -> int:
+    """Lines of SQL and YAML in a generated dbt project"""
+    lines = 0
+    for pattern in ("*.sql", "*.yml"):
+        for path in project_path.rglob(pattern):
+            try:
+                with open(path, encoding="utf-8", errors="ignore") as f:
+                    lines += sum(1 for _ in f)
+            except OSError:
+                continue
+    return lines
+
+
+def count_generated_models(project_path: Optional[Path]) -> int:
+    """SQL models in a generated dbt project"""
+    if not project_path:
+        return 0
+    models_dir = project_path / "models"
+    if not models_dir.exists():
+        return 0
+    return len(list(models_dir.rglob("*.sql")))
+
+
+def generated_tables(state: MigrationState) -> List[Dict[str, Any]]:
+    """Progress of each extracted table: generated once its staging model is on disk"""
+    if not state.metadata:
+        return []
+    generator = None
+    if state.dbt_project_path:
+        generator = DBTProjectGenerator(
+            project_name=Path(state.dbt_project_path).name,
+            output_path=state.dbt_project_path,
+            scaffolding=state.scaffolding
+        )
+    tables = []
+    for table in state.metadata.get('tables', []):
+        schema = table.get('schema', 'dbo')
+        status = "pending"
+        if generator:
+            model_dir = generator.staging_model_dir(table['name'], schema)
+            if (model_dir / f"{staging_model_name(table['name'], state.scaffolding)}.sql").exists():
+                status = "generated"
+        tables.append({"name": f"{schema}.{table['name']}", "status": status})
+    return tables
+
+
+def stop_if_cancelled(migration_id: int) -> bool:
+    """Mark a migration cancelled if a stop was requested. The backend already marked it
+    cancelled, so it isn't notified."""
+    state = get_migration(migration_id)
+    if not state or not state.cancel_requested:
+        return False
+    update_migration(
+        migration_id,
+        status=MigrationStatus.CANCELLED,
+        current_phase="cancelled",
+        completed_at=datetime.now()
+    )
+    logger.info(f"Migration {migration_id}: Cancelled at {state.progress}%")
+    return True
+
+
 # =============================================================================
 # MIGRATION WORKFLOW
 # =============================================================================
@@ -227,7 +400,17 @@ async def run_migration_workflow(
     target_project: str,
     target_warehouse: str,
     tables: Optional[List[str]] = None,
-    include_views: bool = False
+    include_views: bool = False,
+    table_filters: Optional[List[Dict[str, Any]]] = None,
+    column_masks: Optional[List[Dict[str, Any]]] = None,
+    scaffolding: Optional[Dict[str, Any]] = None,
+    pii_policy: Optional[Dict[str, Any]] = None,
+    skip_tables: Optional[List[str]] = None,
+    exposures_yaml: Optional[str] = None,
+    throttle: Optional[Dict[str, Any]] = None,
+    target: Optional[Dict[str, Any]] = None,
+    snapshots: Optional[List[Dict[str, Any]]] = None,
+    artifact_bucket: Optional[str] = None
 ):
     """
     Run the complete migration workflow.
@@ -236,6 +419,8 @@ async def run_migration_workflow(
     1. Extract MSSQL metadata
     2. Generate dbt project
     3. (Future) Run AI analysis with LangGraph
+
+    A stop request takes effect between phases; what earlier phases generated stays on disk.
     """
     try:
         # Initialize migration state
@@ -247,8 +432,19 @@ async def run_migration_workflow(
         )
         await notify_go_backend(migration_id, "running", 0)
 
+        # Extraction only reads from the source inside the connection's off-peak windows
+        if not off_peak_open(throttle):
+            logger.info(f"Migration {migration_id}: Waiting for an off-peak window of the source")
+            update_migration(migration_id, current_phase="waiting_for_off_peak_window")
+            while not off_peak_open(throttle):
+                # Checked every second so a stop request is answered promptly
+                if stop_if_cancelled(migration_id):
+                    return
+                await asyncio.sleep(1)
+
         # Phase 1: Extract metadata (0-30%)
         logger.info(f"Migration {migration_id}: Starting metadata extraction")
+        phase_started = time.monotonic()
         update_migration(
             migration_id,
             current_phase="extracting_metadata",
@@ -263,7 +459,10 @@ async def run_migration_workflow(
                 username=source_connection.username,
                 password=source_connection.password,
                 port=source_connection.port,
-                trusted_connection=source_connection.use_windows_auth
+                trusted_connection=source_connection.use_windows_auth,
+                read_replica=source_connection.read_replica,
+                read_isolation=source_connection.read_isolation,
+                fetch_size=(throttle or {}).get("fetch_size") or 0
             )
 
             # Test connection first
@@ -278,6 +477,8 @@ async def run_migration_workflow(
                 include_procedures=False,  # Skip for faster extraction
                 include_indexes=False
             )
+            # Profiled values are kept in the state and written into the project
+            PIIMasker(pii_policy).mask_metadata(metadata)
 
             total_tables = len(metadata.get('tables', []))
             total_views = len(metadata.get('views', []))
@@ -289,7 +490,9 @@ async def run_migration_workflow(
                 total_models=total_tables,
                 metadata=metadata
             )
-            await notify_go_backend(migration_id, "running", 30)
+            await notify_go_backend(migration_id, "running", 30, metrics=[
+                phase_metrics(migration_id, "extracting_metadata", phase_started, tables_processed=total_tables)
+            ])
 
             logger.info(f"Migration {migration_id}: Extracted {total_tables} tables, {total_views} views, {total_foreign_keys} FKs")
 
@@ -303,8 +506,12 @@ async def run_migration_workflow(
             await notify_go_backend(migration_id, "failed", 30, str(e))
             return
 
+        if stop_if_cancelled(migration_id):
+            return
+
         # Phase 2: Generate dbt project (30-70%)
         logger.info(f"Migration {migration_id}: Generating dbt project")
+        phase_started = time.monotonic()
         update_migration(
             migration_id,
             current_phase="generating_dbt_project",
@@ -322,18 +529,50 @@ async def run_migration_workflow(
             generator = DBTProjectGenerator(
                 project_name=target_project,
                 output_path=str(project_path),
-                target_warehouse=target_warehouse
+                target_warehouse=target_warehouse,
+                scaffolding=scaffolding,
+                target=target
             )
 
-            result = generator.generate_full_project(metadata)
+            result = generator.generate_full_project(
+                metadata,
+                table_filters=table_filters,
+                column_masks=column_masks,
+                skip_tables=skip_tables
+            )
+            if exposures_yaml:
+                generator.generate_exposures_yml(exposures_yaml)
+            if snapshots:
+                generator.generate_snapshots_yml(snapshots)
+            seeds_copied = copy_staged_seeds(migration_id, project_path)
+            if seeds_copied:
+                logger.info(f"Migration {migration_id}: Copied {seeds_copied} seeds into the project")
+
+            # A pinned organization's project must end up in its region's bucket; a failed
+            # upload fails the migration rather than leaving the project only on this instance
+            artifacts_uri = None
+            if artifact_bucket:
+                artifacts_uri = await asyncio.to_thread(
+                    upload_project_to_bucket, migration_id, project_path, artifact_bucket
+                )
+                logger.info(f"Migration {migration_id}: Stored the project at {artifacts_uri}")
 
             update_migration(
                 migration_id,
                 progress=70,
                 dbt_project_path=str(project_path),
+                artifacts_uri=artifacts_uri,
                 completed_models=total_tables
             )
-            await notify_go_backend(migration_id, "running", 70)
+            await notify_go_backend(migration_id, "running", 70, metrics=[
+                phase_metrics(
+                    migration_id,
+                    "generating_dbt_project",
+                    phase_started,
+                    tables_processed=total_tables,
+                    lines_generated=count_generated_lines(project_path),
+                )
+            ])
 
             logger.info(f"Migration {migration_id}: Generated dbt project at {project_path}")
 
@@ -347,8 +586,12 @@ async def run_migration_workflow(
             await notify_go_backend(migration_id, "failed", 70, str(e))
             return
 
+        if stop_if_cancelled(migration_id):
+            return
+
         # Phase 3: Validation (70-100%)
         logger.info(f"Migration {migration_id}: Validating generated models")
+        phase_started = time.monotonic()
         update_migration(
             migration_id,
             current_phase="validating",
@@ -360,6 +603,9 @@ async def run_migration_workflow(
         # For now, just mark as complete
         await asyncio.sleep(1)  # Simulate validation
 
+        if stop_if_cancelled(migration_id):
+            return
+
         # Complete!
         update_migration(
             migration_id,
@@ -370,11 +616,7 @@ async def run_migration_workflow(
         )
 
         # Count generated models (SQL files in models directory)
-        models_count = 0
-        if project_path.exists():
-            models_dir = project_path / "models"
-            if models_dir.exists():
-                models_count = len(list(models_dir.rglob("*.sql")))
+        models_count = count_generated_models(project_path)
 
         await notify_go_backend(
             migration_id,
@@ -383,7 +625,8 @@ async def run_migration_workflow(
             tables_count=total_tables,
             views_count=total_views,
             foreign_keys_count=total_foreign_keys,
-            models_generated=models_count
+            models_generated=models_count,
+            metrics=[phase_metrics(migration_id, "validating", phase_started, tables_processed=total_tables)]
         )
 
         logger.info(f"Migration {migration_id}: Completed successfully! ({total_tables} tables, {total_views} views, {total_foreign_keys} FKs, {models_count} models generated)")
@@ -1175,6 +1418,12 @@ async def start_migration(
     """Start a new migration workflow"""
     migration_id = request.migration_id
 
+    if DATA_REGION and request.data_region and request.data_region != DATA_REGION:
+        raise HTTPException(
+            status_code=409,
+            detail=f"Migration {migration_id} is pinned to {request.data_region}; this instance serves {DATA_REGION}"
+        )
+
     # Check if migration already exists
     existing = get_migration(migration_id)
     if existing and existing.status == MigrationStatus.RUNNING:
@@ -1184,7 +1433,21 @@ async def start_migration(
         )
 
     # Create migration state
+    # Staging models take the organization's prefix; the generator reads it with the
+    # rest of the model naming from the scaffolding
+    scaffolding = request.scaffolding
+    if request.naming and request.naming.get("staging_prefix"):
+        scaffolding = {**(scaffolding or {}), "staging_prefix": request.naming["staging_prefix"]}
+
     create_migration(migration_id)
+    update_migration(
+        migration_id,
+        test_coverage=request.test_coverage,
+        scaffolding=scaffolding,
+        secrets=request.secrets or {},
+        data_region=request.data_region or "",
+        artifact_bucket=request.artifact_bucket
+    )
 
     # Start migration workflow in background
     background_tasks.add_task(
@@ -1194,7 +1457,17 @@ async def start_migration(
         target_project=request.target_project,
         target_warehouse=request.target_warehouse,
         tables=request.tables,
-        include_views=request.include_views
+        include_views=request.include_views,
+        table_filters=request.table_filters,
+        column_masks=request.column_masks,
+        scaffolding=scaffolding,
+        pii_policy=request.pii_policy,
+        skip_tables=request.skip_tables,
+        exposures_yaml=request.exposures_yaml,
+        throttle=request.throttle,
+        target=request.target,
+        snapshots=request.snapshots,
+        artifact_bucket=request.artifact_bucket
     )
 
     logger.info(f"Started migration {migration_id}")
@@ -1230,7 +1503,12 @@ async def get_migration_status(migration_id: int):
 
 @app.post("/migrations/{migration_id}/stop")
 async def stop_migration(migration_id: int):
-    """Stop a running migration"""
+    """
+    Stop a running migration and wait for it to stop.
+
+    Answers once the workflow has stopped, or after STOP_ACK_TIMEOUT seconds with status
+    cancelling if it is still in a phase. Either way the response lists what it generated.
+    """
     state = get_migration(migration_id)
 
     if not state:
@@ -1245,13 +1523,20 @@ async def stop_migration(migration_id: int):
             detail=f"Migration {migration_id} is not running"
         )
 
-    update_migration(
-        migration_id,
-        status=MigrationStatus.FAILED,
-        error="Stopped by user"
-    )
+    update_migration(migration_id, cancel_requested=True)
 
-    return {"message": "Migration stopped", "migration_id": migration_id}
+    deadline = time.monotonic() + STOP_ACK_TIMEOUT
+    while state.status == MigrationStatus.RUNNING and time.monotonic() < deadline:
+        await asyncio.sleep(0.5)
+
+    project_path = Path(state.dbt_project_path) if state.dbt_project_path else None
+    return {
+        "migration_id": migration_id,
+        "status": "cancelling" if state.status == MigrationStatus.RUNNING else "cancelled",
+        "progress": state.progress,
+        "models_generated": count_generated_models(project_path),
+        "tables": generated_tables(state),
+    }
 
 
 def find_migration_project_path(migration_id: int) -> Optional[Path]:
@@ -1310,9 +1595,11 @@ async def get_migration_files(migration_id: int):
                 "type": file_path.suffix
             })
 
+    # A project stored in the region's bucket is reported by its location there
+    state = get_migration(migration_id)
     return {
         "migration_id": migration_id,
-        "project_path": str(project_path),
+        "project_path": (state.artifacts_uri if state else None) or str(project_path),
         "files": files
     }
 
@@ -1405,6 +1692,77 @@ async def download_migration_project(migration_id: int):
     )
 
 
+# Seed CSVs are uploaded before the migration generates its project, so they wait here
+# and are copied into the project's seeds/ folder when it is generated
+SEEDS_STAGING_DIR = Path("./dbt_projects/_seeds")
+MAX_SEED_BYTES = 100 * 1024 * 1024
+SEED_NAME_PATTERN = re.compile(r"^[a-z0-9_]+$")
+
+
+def staged_seeds_dir(migration_id: int) -> Path:
+    return SEEDS_STAGING_DIR / f"migration_{migration_id}"
+
+
+def copy_staged_seeds(migration_id: int, project_path: Path) -> int:
+    """Copy a migration's uploaded seeds into its project; returns how many were copied"""
+    staged = staged_seeds_dir(migration_id)
+    if not staged.exists():
+        return 0
+    seeds_path = project_path / "seeds"
+    seeds_path.mkdir(parents=True, exist_ok=True)
+    copied = 0
+    for csv_path in staged.glob("*.csv"):
+        shutil.copyfile(csv_path, seeds_path / csv_path.name)
+        copied += 1
+    return copied
+
+
+@app.put("/migrations/{migration_id}/seeds/{seed_name}", status_code=201)
+async def upload_seed(migration_id: int, seed_name: str, request: Request):
+    """
+    Store a seed CSV streamed by the Go backend as seeds/<seed_name>.csv.
+
+    The CSV is written to a temporary file first, so a failed or aborted upload never
+    leaves a partial seed behind.
+    """
+    if not SEED_NAME_PATTERN.match(seed_name):
+        raise HTTPException(status_code=400, detail="Seed names may contain only lowercase letters, digits and underscores")
+
+    staged = staged_seeds_dir(migration_id)
+    staged.mkdir(parents=True, exist_ok=True)
+    target = staged / f"{seed_name}.csv"
+    partial = staged / f".{seed_name}.csv.part"
+
+    size = 0
+    try:
+        with open(partial, "wb") as f:
+            async for chunk in request.stream():
+                size += len(chunk)
+                if size > MAX_SEED_BYTES:
+                    raise HTTPException(status_code=413, detail=f"Seed exceeds {MAX_SEED_BYTES // (1024 * 1024)} MB")
+                f.write(chunk)
+        partial.replace(target)
+    except HTTPException:
+        partial.unlink(missing_ok=True)
+        raise
+    except Exception as e:
+        partial.unlink(missing_ok=True)
+        logger.error(f"Seed upload {seed_name} for migration {migration_id} failed: {e}")
+        raise HTTPException(status_code=400, detail=f"Seed upload failed: {str(e)}")
+
+    # A project generated before the upload gets the seed right away
+    project_path = find_migration_project_path(migration_id)
+    if project_path:
+        copy_staged_seeds(migration_id, project_path)
+        # and so does its copy in the region's bucket
+        state = get_migration(migration_id)
+        if state and state.artifacts_uri:
+            await asyncio.to_thread(upload_project_to_bucket, migration_id, project_path, state.artifact_bucket)
+
+    logger.info(f"Stored seed {seed_name} for migration {migration_id} ({size} bytes)")
+    return {"migration_id": migration_id, "seed_name": seed_name, "path": f"seeds/{seed_name}.csv", "size": size}
+
+
 # =============================================================================
 # VALIDATION ENDPOINTS
 # =============================================================================
@@ -1569,7 +1927,8 @@ async def validate_migration_endpoint(migration_id: int, request: ValidationRequ
             validate_row_counts=validate_row_counts,
             validate_data_types=validate_data_types,
             generate_dbt_tests=generate_dbt_tests,
-            source_connection=source_connection
+            source_connection=source_connection,
+            secrets=state.secrets if state else None
         )
 
         # Add migration_id to result
@@ -1716,10 +2075,13 @@ async def enhance_schema_endpoint(migration_id: int):
         )
 
     try:
-        enhanced_yaml = enhance_schema_yml(str(project_path), state.metadata)
+        enhanced_yaml = enhance_schema_yml(str(project_path), state.metadata, state.test_coverage, state.scaffolding)
 
-        # Write the enhanced schema
-        schema_path = project_path / "models" / "staging" / "_schema.yml"
+        # Write the enhanced schema next to the staging models
+        staging_path = DBTProjectGenerator(
+            project_path.name, str(project_path), scaffolding=state.scaffolding
+        ).staging_path
+        schema_path = staging_path / "_schema.yml"
         schema_path.write_text(enhanced_yaml)
 
         logger.info(f"Migration {migration_id}: Enhanced schema.yml generated")
@@ -1748,11 +2110,39 @@ class ChatMessage(BaseModel):
     content: str
 
 
+class ChatMigrationSummary(BaseModel):
+    """A migration of the user, as summarized by the Go backend"""
+    id: int
+    name: str
+    status: str
+    progress: int = 0
+    source_database: str = ""
+    tables_count: int = 0
+    error: Optional[str] = None
+
+
+class ChatConnectionSummary(BaseModel):
+    """A connection of the user; the backend sends no hosts or credentials"""
+    name: str
+    db_type: str
+    database_name: str = ""
+    is_source: bool = False
+
+
+class ChatContext(BaseModel):
+    """The user's own resources, built by the Go backend to ground answers"""
+    migrations: List[ChatMigrationSummary] = []
+    connections: List[ChatConnectionSummary] = []
+    recent_errors: List[str] = []
+
+
 class ChatRequest(BaseModel):
     """Chat request model"""
     message: str
     history: Optional[List[ChatMessage]] = None
     language: str = "en"  # Language code: en, da, es, pt, no, sv, de
+    # Set by the Go backend when the organization allows grounding (ai_context_enabled)
+    context: Optional[ChatContext] = None
 
 
 class ChatResponse(BaseModel):
@@ -2137,7 +2527,34 @@ Vad vill du veta mer om?"""
 }
 
 
-def get_claude_response(message: str, history: Optional[List[ChatMessage]], language: str) -> Optional[str]:
+def format_chat_context(context: Optional[ChatContext]) -> str:
+    """Render the user's resources as a system prompt section, or "" when there are none"""
+    if not context or not (context.migrations or context.connections or context.recent_errors):
+        return ""
+
+    lines = ["## The User's Workspace",
+             "Ground answers about the user's migrations and connections in this data. "
+             "Don't invent resources that aren't listed."]
+    if context.migrations:
+        lines.append("\n### Migrations (most recently updated first)")
+        for m in context.migrations:
+            line = f"- #{m.id} {m.name}: {m.status}, {m.progress}%, {m.tables_count} tables"
+            if m.source_database:
+                line += f", source database {m.source_database}"
+            lines.append(line)
+    if context.connections:
+        lines.append("\n### Connections")
+        for c in context.connections:
+            role = "source" if c.is_source else "target"
+            lines.append(f"- {c.name}: {c.db_type} {role}, database {c.database_name or 'unknown'}")
+    if context.recent_errors:
+        lines.append("\n### Recent migration errors")
+        lines.extend(f"- {e}" for e in context.recent_errors)
+    return "\n".join(lines)
+
+
+def get_claude_response(message: str, history: Optional[List[ChatMessage]], language: str,
+                        context: Optional[ChatContext] = None) -> Optional[str]:
     """
     Get response from Claude AI with DataMigrate context and multilingual support.
     Returns None if Claude is not available.
@@ -2207,6 +2624,10 @@ All SQL Server data types: VARCHAR, NVARCHAR, INT, BIGINT, DECIMAL, FLOAT, DATE,
 
 Be helpful, concise, and friendly. Answer any questions about our services, pricing, features, or technical capabilities."""
 
+    workspace = format_chat_context(context)
+    if workspace:
+        system_prompt += "\n\n" + workspace
+
     # Build messages for Claude
     messages = []
 
@@ -2236,14 +2657,15 @@ Be helpful, concise, and friendly. Answer any questions about our services, pric
         return None
 
 
-def get_ai_response(message: str, history: Optional[List[ChatMessage]] = None, language: str = "en") -> str:
+def get_ai_response(message: str, history: Optional[List[ChatMessage]] = None, language: str = "en",
+                    context: Optional[ChatContext] = None) -> str:
     """
     Generate AI response based on user message in the specified language.
 
     Uses Claude AI if available, otherwise falls back to keyword matching.
     """
     # Try Claude AI first for intelligent responses
-    claude_response = get_claude_response(message, history, language)
+    claude_response = get_claude_response(message, history, language, context)
     if claude_response:
         return claude_response
 
@@ -2360,7 +2782,7 @@ async def chat(request: ChatRequest):
     Supports multilingual responses based on the language parameter.
     """
     try:
-        response = get_ai_response(request.message, request.history, request.language)
+        response = get_ai_response(request.message, request.history, request.language, request.context)
         return ChatResponse(response=response)
     except Exception as e:
         logger.error(f"Chat error: {e}")
@@ -2379,6 +2801,167 @@ async def chat(request: ChatRequest):
         )
 
 
+# =============================================================================
+# EMBEDDINGS
+# =============================================================================
+
+class EmbeddingRequest(BaseModel):
+    """Text to embed for a RAG similarity search"""
+    text: str
+
+
+class EmbeddingResponse(BaseModel):
+    """Embedding of the text, from the provider the RAG service indexes with"""
+    embedding: List[float]
+    model: Optional[str] = None
+
+
+# Created on first use; ada-002 when OPENAI_API_KEY is set, deterministic mock otherwise
+_embedding_provider = None
+
+
+def get_rag_embedding_provider():
+    """
+    Return the embedding provider of rag_service, so queries embed like the index.
+
+    schema_embeddings and transformation_embeddings are vector(1536) columns filled by
+    rag_service with ada-002; rag_service_v2's Voyage vectors can't be compared with them.
+    """
+    global _embedding_provider
+    if _embedding_provider is None:
+        from .rag_service import EmbeddingProvider
+        _embedding_provider = EmbeddingProvider()
+    return _embedding_provider
+
+
+@app.post("/embeddings", response_model=EmbeddingResponse)
+async def create_embedding(request: EmbeddingRequest):
+    """Embed a search query for the Go backend's pgvector similarity search"""
+    if not request.text.strip():
+        raise HTTPException(status_code=400, detail="text is required")
+
+    try:
+        provider = get_rag_embedding_provider()
+    except ImportError as e:
+        logger.error(f"Embedding provider unavailable: {e}")
+        raise HTTPException(status_code=503, detail="Embeddings are not available")
+
+    try:
+        embedding = await asyncio.to_thread(provider.embed, request.text)
+    except Exception as e:
+        logger.error(f"Embedding failed: {e}")
+        raise HTTPException(status_code=502, detail=f"Embedding failed: {str(e)}")
+
+    return EmbeddingResponse(
+        embedding=list(embedding),
+        model=getattr(provider, "model", type(provider).__name__)
+    )
+
+
+# =============================================================================
+# T-SQL TRANSLATION PLAYGROUND
+# =============================================================================
+
+class TranslateTarget(BaseModel):
+    """The warehouse a snippet is translated for, as the Go backend describes it"""
+    type: str
+    adapter: str = ""
+    database: str = ""
+    tsql: bool = False
+
+
+class TranslateRequest(BaseModel):
+    """An ad-hoc T-SQL snippet to translate"""
+    sql: str
+    target: TranslateTarget
+    format: str = "dbt"  # dbt for a model with source() and ref(), sql for plain SQL
+
+
+class TokenUsage(BaseModel):
+    prompt_tokens: int
+    completion_tokens: int
+
+
+class TranslateResponse(BaseModel):
+    sql: str
+    warnings: List[str] = []
+    usage: Optional[TokenUsage] = None
+
+
+def parse_translation(text: str) -> Dict[str, Any]:
+    """Read the model's JSON answer; an answer that isn't JSON is taken as the SQL itself"""
+    text = text.strip()
+    start, end = text.find("{"), text.rfind("}")
+    if start != -1 and end > start:
+        try:
+            parsed = json.loads(text[start:end + 1])
+            if isinstance(parsed, dict) and isinstance(parsed.get("sql"), str):
+                warnings = parsed.get("warnings") or []
+                return {"sql": parsed["sql"].strip(), "warnings": [str(w) for w in warnings]}
+        except json.JSONDecodeError:
+            pass
+    if text.startswith("```"):
+        text = text.split("\n", 1)[1] if "\n" in text else ""
+        text = text.rsplit("```", 1)[0]
+    return {"sql": text.strip(), "warnings": ["The translation wasn't returned in the expected format; review it before use"]}
+
+
+@app.post("/translate", response_model=TranslateResponse)
+async def translate_sql(request: TranslateRequest):
+    """
+    Translate a T-SQL snippet for the query playground.
+
+    With format dbt the answer is a dbt model selecting through source() and ref(); with
+    format sql it is plain SQL in the target's dialect.
+    """
+    if not request.sql.strip():
+        raise HTTPException(status_code=400, detail="sql is required")
+    if request.format not in ("dbt", "sql"):
+        raise HTTPException(status_code=400, detail="format must be dbt or sql")
+    if not anthropic_client:
+        raise HTTPException(status_code=503, detail="Translation requires the Claude API, which is not configured")
+
+    dialect = request.target.type
+    if request.target.tsql:
+        dialect += " (T-SQL compatible: no QUALIFY, ILIKE or boolean columns, TOP instead of LIMIT)"
+    if request.format == "dbt":
+        output = (f"a dbt model for the {request.target.adapter or request.target.type} adapter. "
+                  "Replace table references with {{ source('<schema>', '<table>') }}, "
+                  "and keep the model a single SELECT with CTEs.")
+    else:
+        output = f"plain SQL in the {dialect} dialect."
+
+    system_prompt = f"""You translate Microsoft SQL Server T-SQL for {dialect}.
+Translate the user's snippet to {output}
+Preserve the semantics exactly. Where a construct has no exact equivalent, translate it as closely as possible and add a short warning naming it.
+Respond with only a JSON object: {{"sql": "<translated SQL>", "warnings": ["<warning>", ...]}}"""
+
+    try:
+        response = await asyncio.to_thread(
+            anthropic_client.messages.create,
+            model="claude-sonnet-4-20250514",
+            max_tokens=4096,
+            system=system_prompt,
+            messages=[{"role": "user", "content": request.sql}]
+        )
+    except Exception as e:
+        logger.error(f"Translation failed: {e}")
+        raise HTTPException(status_code=502, detail=f"Translation failed: {str(e)}")
+
+    result = parse_translation(response.content[0].text)
+    if not result["sql"]:
+        raise HTTPException(status_code=502, detail="Translation returned no SQL")
+
+    return TranslateResponse(
+        sql=result["sql"],
+        warnings=result["warnings"],
+        usage=TokenUsage(
+            prompt_tokens=response.usage.input_tokens,
+            completion_tokens=response.usage.output_tokens
+        )
+    )
+
+
 # =============================================================================
 # WAREHOUSE DEPLOYMENT ENDPOINTS
 # =============================================================================
@@ -2572,6 +3155,8 @@ class DataQualityScanRequest(BaseModel):
     use_windows_auth: bool = False
     tables: Optional[List[str]] = None
     sample_size: int = 10000
+    # The organization's PII policy; profiled values are masked with it
+    pii_policy: Optional[Dict[str, Any]] = None
 
 
 class DataQualityScanResponse(BaseModel):
@@ -2618,7 +3203,8 @@ async def scan_data_quality(request: DataQualityScanRequest):
             port=request.port,
             use_windows_auth=request.use_windows_auth,
             tables=request.tables,
-            sample_size=request.sample_size
+            sample_size=request.sample_size,
+            pii_policy=request.pii_policy
         )
 
         logger.info(f"Data quality scan completed: {result['tables_scanned']} tables, score: {result['overall_score']}")
@@ -24,6 +24,8 @@ from langchain_anthropic import ChatAnthropic
 from langchain_core.messages import HumanMessage, SystemMessage
 from langgraph.graph import StateGraph, END
 
+from .pii_masking import PIIMasker
+
 # Configure logging
 logging.basicConfig(level=logging.INFO)
 logger = logging.getLogger(__name__)
@@ -810,7 +812,8 @@ class DataQualityAgent:
         data: List[Dict[str, Any]],
         table_name: str = "data",
         rules: Optional[List[Dict]] = None,
-        auto_remediate: bool = False
+        auto_remediate: bool = False,
+        pii_policy: Optional[Dict[str, Any]] = None
     ) -> Dict[str, Any]:
         """
         Perform comprehensive quality assessment.
@@ -820,6 +823,7 @@ class DataQualityAgent:
             table_name: Name of the table/dataset
             rules: Custom quality rules to apply
             auto_remediate: Whether to apply automatic remediation
+            pii_policy: The organization's PII policy; sample values are masked with it
 
         Returns:
             Quality assessment results with scores and issues
@@ -835,6 +839,12 @@ class DataQualityAgent:
 
         result = await self.workflow.ainvoke(state)
 
+        # Mask only now, so the statistics are computed on the real values
+        masker = PIIMasker(pii_policy)
+        _mask_profile(masker, result.get("profile"))
+        for issue in result.get("issues", []):
+            _mask_issue(masker, issue)
+
         return {
             "profile": result.get("profile"),
             "scores": result.get("scores"),
@@ -846,7 +856,8 @@ class DataQualityAgent:
     async def profile_data(
         self,
         data: List[Dict[str, Any]],
-        table_name: str = "data"
+        table_name: str = "data",
+        pii_policy: Optional[Dict[str, Any]] = None
     ) -> Dict[str, Any]:
         """
         Profile data without full quality assessment.
@@ -854,6 +865,7 @@ class DataQualityAgent:
         Args:
             data: List of data records
             table_name: Name of the table/dataset
+            pii_policy: The organization's PII policy; sample values are masked with it
 
         Returns:
             Data profile with statistics and patterns
@@ -863,7 +875,9 @@ class DataQualityAgent:
         state = {"data": data, "table_name": table_name}
         result = await self._profile_data_node(state)
 
-        return result.get("profile", {})
+        profile = result.get("profile") or {}
+        _mask_profile(PIIMasker(pii_policy), profile)
+        return profile
 
     def add_rule(self, rule: QualityRule) -> None:
         """Add a quality rule to the library"""
@@ -966,6 +980,21 @@ class DataQualityAgent:
 
 
 # Example usage and testing
+def _mask_profile(masker: PIIMasker, profile: Optional[Dict[str, Any]]) -> None:
+    """Mask the sample, min and max values of a table profile in place"""
+    for col in (profile or {}).get("columns", []):
+        name = col.get("name", "")
+        col["sample_values"] = masker.mask_values(name, col.get("sample_values"))
+        for key in ("min_value", "max_value"):
+            col[key] = masker.mask_value(name, col.get(key))
+
+
+def _mask_issue(masker: PIIMasker, issue: Dict[str, Any]) -> None:
+    """Mask the sample values of an issue in place"""
+    column = (issue.get("rule") or {}).get("column") or ""
+    issue["sample_values"] = masker.mask_values(column, issue.get("sample_values"))
+
+
 async def main():
     """Example usage of the Data Quality Agent"""
 
@@ -1017,7 +1046,8 @@ def scan_source_data_quality(
     port: int = 1433,
     use_windows_auth: bool = False,
     tables: Optional[List[str]] = None,
-    sample_size: int = 10000
+    sample_size: int = 10000,
+    pii_policy: Optional[Dict[str, Any]] = None
 ) -> Dict[str, Any]:
     """
     Scan source MSSQL database for data quality issues.
@@ -1034,6 +1064,7 @@ def scan_source_data_quality(
         use_windows_auth: Use Windows/Trusted authentication
         tables: Specific tables to scan (None = all)
         sample_size: Sample size for profiling
+        pii_policy: The organization's PII policy; min and max values are masked with it
 
     Returns:
         Data quality report as dictionary
@@ -1042,6 +1073,7 @@ def scan_source_data_quality(
     from datetime import datetime
 
     logger.info(f"Scanning data quality for {database} on {server}")
+    masker = PIIMasker(pii_policy)
 
     # Build connection string
     if use_windows_auth:
@@ -1102,6 +1134,9 @@ def scan_source_data_quality(
         for schema, table in table_list:
             try:
                 table_profile = _scan_table(cursor, schema, table, sample_size)
+                for col in table_profile.get("columns", []):
+                    for key in ("min_value", "max_value"):
+                        col[key] = masker.mask_value(col["column_name"], col.get(key))
                 report["tables"].append(table_profile)
                 report["tables_scanned"] += 1
                 report["total_rows_scanned"] += table_profile.get("row_count", 0)
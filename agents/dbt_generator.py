@@ -12,6 +12,7 @@ This creates real, runnable dbt projects from the AI-generated models.
 """
 
 import os
+import re
 import yaml
 import logging
 from pathlib import Path
@@ -21,6 +22,61 @@ from datetime import datetime
 logger = logging.getLogger(__name__)
 
 
+# =============================================================================
+# PROJECT SCAFFOLDING
+# =============================================================================
+# Mirrors the backend's dbtgen scaffolding, which previews the same names and paths
+
+SHARED_DOMAIN = "shared"
+
+
+def inflect_model_name(name: str, naming: Optional[str]) -> str:
+    """Make the last word of a snake_case name singular or plural, following the
+    English rules for regular nouns; other namings leave the name as it is"""
+    head, _, word = name.rpartition("_")
+    head = f"{head}_" if head else ""
+    if naming == "singular":
+        word = _singular(word)
+    elif naming == "plural":
+        word = _plural(word)
+    return head + word
+
+
+def _singular(word: str) -> str:
+    if len(word) > 3 and word.endswith("ies"):
+        return word[:-3] + "y"
+    if word.endswith(("sses", "xes", "zes", "ches", "shes")):
+        return word[:-2]
+    if word.endswith(("ss", "us", "is")) or not word.endswith("s") or len(word) < 3:
+        return word
+    return word[:-1]
+
+
+def _plural(word: str) -> str:
+    if not word or _singular(word) != word:
+        return word  # Already plural
+    if word.endswith("y") and len(word) > 1 and word[-2] not in "aeiou":
+        return word[:-1] + "ies"
+    if word.endswith("is"):
+        return word[:-2] + "es"
+    if word.endswith(("s", "x", "z", "ch", "sh")):
+        return word + "es"
+    return word + "s"
+
+
+def _resource_name(name: str) -> str:
+    """A source name as a dbt resource name: lowercase letters, digits and underscores"""
+    return re.sub(r"[^a-z0-9_]+", "_", str(name).lower()).strip("_")
+
+
+def staging_model_name(table_name: str, scaffolding: Optional[Dict[str, Any]] = None) -> str:
+    """Name of a table's staging model under the scaffolding's naming and the
+    organization's staging prefix"""
+    scaffolding = scaffolding or {}
+    prefix = scaffolding.get('staging_prefix') or "stg_"
+    return prefix + inflect_model_name(_resource_name(table_name), scaffolding.get('naming'))
+
+
 class DBTProjectGenerator:
     """
     Generates a complete dbt project structure.
@@ -38,7 +94,9 @@ class DBTProjectGenerator:
         project_name: str,
         output_path: str,
         target_warehouse: str = "snowflake",
-        source_name: str = "mssql_source"
+        source_name: str = "mssql_source",
+        scaffolding: Optional[Dict[str, Any]] = None,
+        target: Optional[Dict[str, Any]] = None
     ):
         """
         Initialize the dbt project generator.
@@ -48,11 +106,52 @@ class DBTProjectGenerator:
             output_path: Directory to create the project in
             target_warehouse: Target data warehouse (snowflake, databricks, bigquery)
             source_name: Name for the source in sources.yml
+            scaffolding: Optional project structure chosen by the user: layers
+                (layered or simple), folders (flat, schema or domain, with domains),
+                naming (source, singular or plural) and staging_prefix
+            target: Optional target connection from the backend: type, adapter,
+                database and, for Fabric, workspace and lakehouse. Its type overrides
+                target_warehouse.
         """
         self.project_name = self._sanitize_name(project_name)
         self.output_path = Path(output_path)
-        self.target_warehouse = target_warehouse
+        self.target = target or {}
+        # dbt calls the SQL Server adapter sqlserver; connections call it mssql
+        target_type = self.target.get('type')
+        self.target_warehouse = "sqlserver" if target_type == "mssql" else target_type or target_warehouse
         self.source_name = source_name
+        self.scaffolding = scaffolding or {}
+
+    @property
+    def adapter(self) -> str:
+        """The dbt adapter package the project is run with"""
+        return self.target.get('adapter') or f"dbt-{self.target_warehouse}"
+
+    @property
+    def layered(self) -> bool:
+        """Whether models are split into staging, intermediate and marts folders"""
+        return self.scaffolding.get('layers', 'layered') != 'simple'
+
+    @property
+    def staging_path(self) -> Path:
+        """Folder of the staging models, sources and their schema.yml"""
+        if self.layered:
+            return self.output_path / "models" / "staging"
+        return self.output_path / "models"
+
+    def staging_model_dir(self, table_name: str, schema_name: str = "dbo") -> Path:
+        """Folder of a table's staging model: per source schema or per domain when
+        the scaffolding asks for it"""
+        folders = self.scaffolding.get('folders')
+        if folders == 'schema':
+            return self.staging_path / _resource_name(schema_name)
+        if folders == 'domain':
+            names = (f"{schema_name}.{table_name}".lower(), str(table_name).lower())
+            for domain in self.scaffolding.get('domains') or []:
+                if any(str(t).lower() in names for t in domain.get('tables') or []):
+                    return self.staging_path / domain.get('name')
+            return self.staging_path / SHARED_DOMAIN
+        return self.staging_path
 
     def _sanitize_name(self, name: str) -> str:
         """Sanitize project name to be valid dbt identifier"""
@@ -74,15 +173,18 @@ class DBTProjectGenerator:
         directories = [
             self.output_path,
             self.output_path / "models",
-            self.output_path / "models" / "staging",
-            self.output_path / "models" / "intermediate",
-            self.output_path / "models" / "marts",
             self.output_path / "seeds",
             self.output_path / "snapshots",
             self.output_path / "tests",
             self.output_path / "macros",
             self.output_path / "analyses",
         ]
+        if self.layered:
+            directories[2:2] = [
+                self.output_path / "models" / "staging",
+                self.output_path / "models" / "intermediate",
+                self.output_path / "models" / "marts",
+            ]
 
         for directory in directories:
             directory.mkdir(parents=True, exist_ok=True)
@@ -107,6 +209,24 @@ class DBTProjectGenerator:
         """
         profile = profile_name or self.project_name
 
+        if self.layered:
+            model_config = {
+                'staging': {
+                    '+materialized': 'view',
+                    '+schema': 'staging'
+                },
+                'intermediate': {
+                    '+materialized': 'view',
+                    '+schema': 'intermediate'
+                },
+                'marts': {
+                    '+materialized': 'table',
+                    '+schema': 'marts'
+                }
+            }
+        else:
+            model_config = {'+materialized': 'view'}
+
         project_config = {
             'name': self.project_name,
             'version': version,
@@ -123,20 +243,7 @@ class DBTProjectGenerator:
             'clean-targets': ["target", "dbt_packages"],
 
             'models': {
-                self.project_name: {
-                    'staging': {
-                        '+materialized': 'view',
-                        '+schema': 'staging'
-                    },
-                    'intermediate': {
-                        '+materialized': 'view',
-                        '+schema': 'intermediate'
-                    },
-                    'marts': {
-                        '+materialized': 'table',
-                        '+schema': 'marts'
-                    }
-                }
+                self.project_name: model_config
             }
         }
 
@@ -232,6 +339,31 @@ class DBTProjectGenerator:
                     }
                 }
             }
+        elif self.target_warehouse in ("fabric", "synapse", "sqlserver"):
+            # T-SQL warehouses; Fabric signs in with a service principal
+            output = {
+                'type': self.target_warehouse,
+                'driver': 'ODBC Driver 18 for SQL Server',
+                'server': '{{ env_var("DBT_SERVER") }}',
+                'port': 1433,
+                'database': self.target.get('database') or '{{ env_var("DBT_DATABASE") }}',
+                'schema': 'dbo',
+                'threads': 4
+            }
+            if self.target_warehouse == "sqlserver":
+                output['user'] = '{{ env_var("DBT_USER") }}'
+                output['password'] = '{{ env_var("DBT_PASSWORD") }}'
+            else:
+                output['authentication'] = 'ServicePrincipal'
+                output['tenant_id'] = '{{ env_var("AZURE_TENANT_ID") }}'
+                output['client_id'] = '{{ env_var("AZURE_CLIENT_ID") }}'
+                output['client_secret'] = '{{ env_var("AZURE_CLIENT_SECRET") }}'
+            profile_config = {
+                self.project_name: {
+                    'target': 'dev',
+                    'outputs': {'dev': output}
+                }
+            }
         else:
             # Generic/PostgreSQL fallback
             profile_config = {
@@ -308,14 +440,15 @@ class DBTProjectGenerator:
                 {
                     'name': self.source_name,
                     'description': f"MSSQL source database: {database_name}",
-                    'database': '{{ env_var("SOURCE_DATABASE", "raw") }}',
+                    # Fabric staging models read the source tables landed in the lakehouse
+                    'database': self.target.get('lakehouse') or '{{ env_var("SOURCE_DATABASE", "raw") }}',
                     'schema': '{{ env_var("SOURCE_SCHEMA", "mssql") }}',
                     'tables': source_tables
                 }
             ]
         }
 
-        file_path = self.output_path / "models" / "staging" / "_sources.yml"
+        file_path = self.staging_path / "_sources.yml"
         with open(file_path, 'w') as f:
             yaml.dump(sources_config, f, default_flow_style=False, sort_keys=False)
 
@@ -330,7 +463,8 @@ class DBTProjectGenerator:
         self,
         model_name: str,
         sql_content: str,
-        model_type: str = "staging"
+        model_type: str = "staging",
+        model_dir: Optional[Path] = None
     ) -> str:
         """
         Generate a single model SQL file.
@@ -339,19 +473,23 @@ class DBTProjectGenerator:
             model_name: Name of the model (without .sql extension)
             sql_content: SQL content for the model
             model_type: Type of model (staging, intermediate, marts)
+            model_dir: Optional folder, overriding the model type's
 
         Returns:
             Path to the generated file
         """
-        # Determine subdirectory based on model type
-        if model_type == "staging":
-            model_dir = self.output_path / "models" / "staging"
-        elif model_type == "intermediate":
-            model_dir = self.output_path / "models" / "intermediate"
-        elif model_type in ("marts", "fact", "dimension"):
-            model_dir = self.output_path / "models" / "marts"
-        else:
-            model_dir = self.output_path / "models"
+        # Determine subdirectory based on model type; the simple layout has one folder
+        if model_dir is None:
+            if not self.layered:
+                model_dir = self.output_path / "models"
+            elif model_type == "staging":
+                model_dir = self.output_path / "models" / "staging"
+            elif model_type == "intermediate":
+                model_dir = self.output_path / "models" / "intermediate"
+            elif model_type in ("marts", "fact", "dimension"):
+                model_dir = self.output_path / "models" / "marts"
+            else:
+                model_dir = self.output_path / "models"
 
         model_dir.mkdir(parents=True, exist_ok=True)
 
@@ -366,7 +504,10 @@ class DBTProjectGenerator:
         self,
         table_name: str,
         schema_name: str = "dbo",
-        columns: Optional[List[Dict]] = None
+        columns: Optional[List[Dict]] = None,
+        where: Optional[str] = None,
+        exclude_columns: Optional[List[str]] = None,
+        column_masks: Optional[Dict[str, str]] = None
     ) -> str:
         """
         Generate a staging model SQL file for a source table.
@@ -375,20 +516,39 @@ class DBTProjectGenerator:
             table_name: Source table name
             schema_name: Source schema name
             columns: Optional list of column definitions
+            where: Optional row filter, validated by the backend
+            exclude_columns: Optional columns to leave out of the model
+            column_masks: Optional masking action per lowercased column name:
+                mask (NULL), hash (SHA-256) or drop
 
         Returns:
             SQL content for the staging model
         """
-        model_name = f"stg_{table_name.lower()}"
+        model_name = staging_model_name(table_name, self.scaffolding)
+        masks = column_masks or {}
 
-        # Build column list
+        if columns:
+            excluded = {c.lower() for c in (exclude_columns or [])}
+            excluded |= {c for c, action in masks.items() if action == 'drop'}
+            columns = [col for col in columns if col.get('name', '').lower() not in excluded]
+        elif masks:
+            logger.warning(f"No columns known for {schema_name}.{table_name}; its masking rules can't be applied")
+        where_sql = f"\n    WHERE {where}" if where else ""
+
+        # Build column list; masked columns are replaced in the staged CTE, so the
+        # row filter still sees their source values
         if columns:
             column_sql = ",\n    ".join([
                 f"{col.get('name')}"
                 for col in columns
             ])
+            staged_sql = ",\n    ".join([
+                self._masked_column(col.get('name'), masks.get(col.get('name', '').lower()))
+                for col in columns
+            ])
         else:
             column_sql = "*"
+            staged_sql = "*"
 
         sql_content = f"""-- Staging model for {schema_name}.{table_name}
 -- Generated by DataMigrate AI on {datetime.now().strftime('%Y-%m-%d %H:%M:%S')}
@@ -402,14 +562,14 @@ WITH source AS (
 
     SELECT
         {column_sql}
-    FROM {{{{ source('{self.source_name}', '{table_name}') }}}}
+    FROM {{{{ source('{self.source_name}', '{table_name}') }}}}{where_sql}
 
 ),
 
 staged AS (
 
     SELECT
-        {column_sql}
+        {staged_sql}
     FROM source
 
 )
@@ -418,10 +578,50 @@ SELECT * FROM staged
 """
 
         # Save the file
-        self.generate_model_file(model_name, sql_content, "staging")
+        self.generate_model_file(
+            model_name, sql_content, "staging",
+            model_dir=self.staging_model_dir(table_name, schema_name)
+        )
 
         return sql_content
 
+    def _masks_for_table(
+        self,
+        column_masks: Optional[List[Dict[str, Any]]],
+        table: Dict[str, Any]
+    ) -> Dict[str, str]:
+        """Masking action per lowercased column of a table; rules for the table itself
+        override rules for every table"""
+        name = str(table.get('name', '')).lower()
+        qualified = f"{table.get('schema', 'dbo')}.{table.get('name')}".lower()
+        masks: Dict[str, str] = {}
+        # Least specific first: every table, then the unqualified name, then schema.table
+        for target in ('', name, qualified):
+            for rule in column_masks or []:
+                if str(rule.get('table', '')).lower() == target:
+                    masks[str(rule.get('column', '')).lower()] = rule.get('action', '')
+        return masks
+
+    def _masked_column(self, name: str, action: Optional[str]) -> str:
+        """Select expression for a staging column under its masking action"""
+        if action == 'mask':
+            return f"NULL AS {name}"
+        if action == 'hash':
+            return f"{self._sha256_hex(name)} AS {name}"
+        return name
+
+    def _sha256_hex(self, column: str) -> str:
+        """SHA-256 digest of a column's text value, as lowercase hex, in the target's SQL"""
+        if self.target_warehouse in ("snowflake", "redshift"):
+            return f"LOWER(SHA2(CAST({column} AS VARCHAR), 256))"
+        if self.target_warehouse == "databricks":
+            return f"sha2(CAST({column} AS STRING), 256)"
+        if self.target_warehouse == "bigquery":
+            return f"TO_HEX(SHA256(CAST({column} AS STRING)))"
+        if self.target_warehouse in ("fabric", "synapse", "sqlserver"):
+            return f"LOWER(CONVERT(VARCHAR(64), HASHBYTES('SHA2_256', CAST({column} AS VARCHAR(8000))), 2))"
+        return f"encode(sha256(convert_to(CAST({column} AS TEXT), 'UTF8')), 'hex')"
+
     # =========================================================================
     # SCHEMA.YML GENERATION
     # =========================================================================
@@ -467,8 +667,8 @@ SELECT * FROM staged
         }
 
         # Determine path based on model type
-        if model_type == "staging":
-            file_path = self.output_path / "models" / "staging" / "_schema.yml"
+        if model_type == "staging" or not self.layered:
+            file_path = self.staging_path / "_schema.yml"
         elif model_type == "intermediate":
             file_path = self.output_path / "models" / "intermediate" / "_schema.yml"
         else:
@@ -482,6 +682,71 @@ SELECT * FROM staged
         logger.info(f"Generated schema.yml at: {file_path}")
         return str(file_path)
 
+    def generate_snapshots_yml(self, snapshots: List[Dict[str, Any]]) -> str:
+        """
+        Generate snapshots/snapshots.yml (the dbt 1.9 layout) for the tables the user
+        marked as slowly changing dimensions, as the backend previews it.
+
+        Args:
+            snapshots: Validated snapshot configs: table, unique_key, strategy, updated_at,
+                check_cols and hard_deletes
+
+        Returns:
+            Path to the generated file
+        """
+        entries = []
+        for snapshot in snapshots:
+            # Named after the table without its schema, e.g. Sales.Customer -> customer_snapshot
+            table = str(snapshot.get('table', '')).split('.')[-1]
+            unique_key = snapshot.get('unique_key') or []
+            strategy = snapshot.get('strategy') or 'timestamp'
+            config: Dict[str, Any] = {
+                'schema': 'snapshots',
+                'unique_key': unique_key[0] if len(unique_key) == 1 else unique_key,
+                'strategy': strategy,
+            }
+            if strategy == 'timestamp':
+                config['updated_at'] = snapshot.get('updated_at')
+            elif strategy == 'check':
+                check_cols = snapshot.get('check_cols') or []
+                config['check_cols'] = 'all' if check_cols == ['all'] else check_cols
+            if snapshot.get('hard_deletes'):
+                config['hard_deletes'] = snapshot['hard_deletes']
+
+            entries.append({
+                'name': f"{_resource_name(table)}_snapshot",
+                'relation': f"source('{self.source_name}', '{table}')",
+                'config': config
+            })
+
+        file_path = self.output_path / "snapshots" / "snapshots.yml"
+        file_path.parent.mkdir(parents=True, exist_ok=True)
+
+        with open(file_path, 'w') as f:
+            yaml.dump({'snapshots': entries}, f, default_flow_style=False, sort_keys=False)
+
+        logger.info(f"Generated snapshots.yml at: {file_path}")
+        return str(file_path)
+
+    def generate_exposures_yml(self, exposures_yaml: str) -> str:
+        """
+        Write the migration's exposures, rendered by the backend, to models/exposures.yml.
+
+        Args:
+            exposures_yaml: The complete exposures.yml content
+
+        Returns:
+            Path to the generated file
+        """
+        file_path = self.output_path / "models" / "exposures.yml"
+        file_path.parent.mkdir(parents=True, exist_ok=True)
+
+        with open(file_path, 'w') as f:
+            f.write(exposures_yaml)
+
+        logger.info(f"Generated exposures.yml at: {file_path}")
+        return str(file_path)
+
     # =========================================================================
     # FULL PROJECT GENERATION
     # =========================================================================
@@ -489,7 +754,10 @@ SELECT * FROM staged
     def generate_full_project(
         self,
         metadata: Dict[str, Any],
-        models: Optional[List[Dict[str, Any]]] = None
+        models: Optional[List[Dict[str, Any]]] = None,
+        table_filters: Optional[List[Dict[str, Any]]] = None,
+        column_masks: Optional[List[Dict[str, Any]]] = None,
+        skip_tables: Optional[List[str]] = None
     ) -> Dict[str, Any]:
         """
         Generate a complete dbt project from metadata and models.
@@ -497,6 +765,11 @@ SELECT * FROM staged
         Args:
             metadata: Extracted MSSQL metadata
             models: Optional list of model configurations from AI
+            table_filters: Optional row filters and excluded columns per schema.table
+            column_masks: Optional masking rules; a rule without a table applies to
+                the column in every table
+            skip_tables: Optional schema.table or table names whose staging models were
+                generated by an earlier run; their files are kept as they are
 
         Returns:
             Dictionary with paths to all generated files
@@ -524,17 +797,32 @@ SELECT * FROM staged
         generated_files['files'].append(sources_path)
 
         # 5. Generate staging models for each table
+        filters = {
+            f.get('table', '').lower(): f for f in (table_filters or [])
+        }
+        skipped = {t.lower() for t in (skip_tables or [])}
         staging_models = []
         for table in metadata.get('tables', []):
+            qualified = f"{table.get('schema', 'dbo')}.{table.get('name')}".lower()
+            # A skipped table is still documented in schema.yml, but its model is not rewritten
+            staging_models.append({
+                'name': staging_model_name(table.get('name', ''), self.scaffolding),
+                'description': table.get('description') or f"Staging model for {table.get('name')}"
+            })
+            if qualified in skipped or str(table.get('name', '')).lower() in skipped:
+                continue
+            table_filter = (
+                filters.get(qualified)
+                or filters.get(str(table.get('name', '')).lower(), {})
+            )
             self.generate_staging_model(
                 table_name=table.get('name'),
                 schema_name=table.get('schema', 'dbo'),
-                columns=table.get('columns')
+                columns=table.get('columns'),
+                where=table_filter.get('where'),
+                exclude_columns=table_filter.get('exclude_columns'),
+                column_masks=self._masks_for_table(column_masks, table)
             )
-            staging_models.append({
-                'name': f"stg_{table.get('name', '').lower()}",
-                'description': table.get('description') or f"Staging model for {table.get('name')}"
-            })
 
         # 6. Generate schema.yml for staging models
         if staging_models:
@@ -549,6 +837,12 @@ SELECT * FROM staged
                     generated_files['files'].append(model.get('file_path'))
 
         # 8. Generate README
+        if self.layered:
+            layout = """- **staging/**: Raw source data transformations
+- **intermediate/**: Business logic layers
+- **marts/**: Final analytics-ready tables"""
+        else:
+            layout = "- **models/**: Source data transformations, in one folder"
         readme_content = f"""# {self.project_name}
 
 dbt project generated by DataMigrate AI on {datetime.now().strftime('%Y-%m-%d')}.
@@ -557,7 +851,7 @@ dbt project generated by DataMigrate AI on {datetime.now().strftime('%Y-%m-%d')}
 
 1. Install dbt:
    ```bash
-   pip install dbt-{self.target_warehouse}
+   pip install {self.adapter}
    ```
 
 2. Copy `profiles.yml` to `~/.dbt/profiles.yml` and update credentials
@@ -571,9 +865,7 @@ dbt project generated by DataMigrate AI on {datetime.now().strftime('%Y-%m-%d')}
 
 ## Models
 
-- **staging/**: Raw source data transformations
-- **intermediate/**: Business logic layers
-- **marts/**: Final analytics-ready tables
+{layout}
 
 ## Source Database
 
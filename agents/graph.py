@@ -4,7 +4,7 @@ LangGraph Workflow for MSSQL to dbt Migration
 This module defines the StateGraph that orchestrates the 6-agent migration workflow.
 """
 
-from typing import Literal, Dict, Any
+from typing import Callable, Literal, Dict, Any, Optional
 from langgraph.graph import StateGraph, END
 from langgraph.checkpoint.memory import MemorySaver
 import logging
@@ -223,7 +223,8 @@ def compile_graph(
 def run_migration(
     graph: StateGraph,
     initial_state: MigrationState,
-    config: Dict[str, Any] = None
+    config: Dict[str, Any] = None,
+    on_node: Optional[Callable[[str, MigrationState], bool]] = None
 ) -> MigrationState:
     """
     Run the migration workflow using the compiled graph.
@@ -232,6 +233,8 @@ def run_migration(
         graph: Compiled StateGraph
         initial_state: Initial migration state
         config: Optional configuration for graph execution
+        on_node: Optional callback after each node with its name and state; the
+            workflow stops early when it returns False
 
     Returns:
         Final migration state
@@ -252,6 +255,9 @@ def run_migration(
             logger.debug(f"State after {node_name}: {node_state.get('phase')}")
 
             final_state = node_state
+            if on_node is not None and not on_node(node_name, node_state):
+                logger.info(f"Migration workflow stopped after node: {node_name}")
+                break
 
         logger.info("Migration workflow completed")
         return final_state
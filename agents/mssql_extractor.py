@@ -9,12 +9,61 @@ This is the real implementation that replaces mock data for the MVP.
 
 import pyodbc
 import logging
+from datetime import datetime, timezone
 from typing import Dict, Any, List, Optional
 from dataclasses import dataclass, asdict
 from contextlib import contextmanager
+from zoneinfo import ZoneInfo, ZoneInfoNotFoundError
 
 logger = logging.getLogger(__name__)
 
+# The connection's read_isolation setting, as SQL Server isolation levels
+READ_ISOLATION_LEVELS = {
+    "snapshot": "SNAPSHOT",
+    "read_uncommitted": "READ UNCOMMITTED",
+}
+
+# Off-peak window days, indexed by datetime.weekday()
+WINDOW_DAYS = ["mon", "tue", "wed", "thu", "fri", "sat", "sun"]
+
+
+def off_peak_open(throttle: Optional[Dict[str, Any]], now: Optional[datetime] = None) -> bool:
+    """
+    Whether a connection's extraction throttle lets extraction run now.
+
+    Without off-peak windows extraction may always run. A window running past midnight
+    belongs to the day it starts on; an unknown time zone is read as UTC, as the backend
+    does.
+    """
+    windows = (throttle or {}).get("off_peak_windows") or []
+    if not windows:
+        return True
+    try:
+        tz = ZoneInfo(throttle.get("timezone") or "UTC")
+    except (ZoneInfoNotFoundError, ValueError):
+        tz = timezone.utc
+    now = (now or datetime.now(timezone.utc)).astimezone(tz)
+    minute = now.hour * 60 + now.minute
+    today = WINDOW_DAYS[now.weekday()]
+    yesterday = WINDOW_DAYS[(now.weekday() + 6) % 7]
+
+    def on_day(window: Dict[str, Any], day: str) -> bool:
+        return not window.get("days") or day in window["days"]
+
+    for window in windows:
+        try:
+            start_h, start_m = (int(p) for p in window["start"].split(":"))
+            end_h, end_m = (int(p) for p in window["end"].split(":"))
+        except (KeyError, ValueError):
+            continue
+        start, end = start_h * 60 + start_m, end_h * 60 + end_m
+        if start < end:
+            if on_day(window, today) and start <= minute < end:
+                return True
+        elif (on_day(window, today) and minute >= start) or (on_day(window, yesterday) and minute < end):
+            return True
+    return False
+
 
 # =============================================================================
 # DATA CLASSES
@@ -115,7 +164,10 @@ class MSSQLExtractor:
         password: Optional[str] = None,
         driver: str = "ODBC Driver 17 for SQL Server",
         trusted_connection: bool = False,
-        port: int = 1433
+        port: int = 1433,
+        read_replica: bool = False,
+        read_isolation: str = "",
+        fetch_size: int = 0
     ):
         """
         Initialize MSSQL connection parameters.
@@ -128,6 +180,12 @@ class MSSQLExtractor:
             driver: ODBC driver name
             trusted_connection: Use Windows authentication
             port: SQL Server port (default 1433)
+            read_replica: Connect with read-only application intent, which availability
+                group listeners route to a readable secondary
+            read_isolation: Isolation level of the extraction queries: snapshot,
+                read_uncommitted (NOLOCK) or empty for the server's default
+            fetch_size: Rows fetched per round trip; all at once when 0. Queries run
+                one at a time, so any max_parallel_queries throttle is met.
         """
         self.server = server
         self.database = database
@@ -136,25 +194,31 @@ class MSSQLExtractor:
         self.driver = driver
         self.trusted_connection = trusted_connection
         self.port = port
+        self.read_replica = read_replica
+        self.read_isolation = read_isolation
+        self.fetch_size = fetch_size
         self._connection = None
 
     def _build_connection_string(self) -> str:
         """Build ODBC connection string"""
         if self.trusted_connection:
-            return (
+            conn_string = (
                 f"DRIVER={{{self.driver}}};"
                 f"SERVER={self.server},{self.port};"
                 f"DATABASE={self.database};"
                 f"Trusted_Connection=yes;"
             )
         else:
-            return (
+            conn_string = (
                 f"DRIVER={{{self.driver}}};"
                 f"SERVER={self.server},{self.port};"
                 f"DATABASE={self.database};"
                 f"UID={self.username};"
                 f"PWD={self.password};"
             )
+        if self.read_replica:
+            conn_string += "ApplicationIntent=ReadOnly;"
+        return conn_string
 
     @contextmanager
     def connection(self):
@@ -163,6 +227,10 @@ class MSSQLExtractor:
         try:
             conn_string = self._build_connection_string()
             conn = pyodbc.connect(conn_string, timeout=30)
+            isolation = READ_ISOLATION_LEVELS.get(self.read_isolation)
+            if isolation:
+                # Session-wide, so every extraction query on the connection avoids blocking writers
+                conn.cursor().execute(f"SET TRANSACTION ISOLATION LEVEL {isolation}")
             yield conn
         except pyodbc.Error as e:
             logger.error(f"Database connection error: {e}")
@@ -171,6 +239,17 @@ class MSSQLExtractor:
             if conn:
                 conn.close()
 
+    def _rows(self, cursor):
+        """A query's rows, fetched fetch_size at a time when the throttle sets one"""
+        if not self.fetch_size:
+            yield from cursor.fetchall()
+            return
+        while True:
+            batch = cursor.fetchmany(self.fetch_size)
+            if not batch:
+                return
+            yield from batch
+
     def test_connection(self) -> bool:
         """Test database connection"""
         try:
@@ -225,7 +304,7 @@ class MSSQLExtractor:
                 cursor = conn.cursor()
                 cursor.execute(tables_query)
 
-                for row in cursor.fetchall():
+                for row in self._rows(cursor):
                     schema_name, table_name, row_count, description = row
 
                     # Get columns for this table
@@ -290,7 +369,7 @@ class MSSQLExtractor:
         cursor = conn.cursor()
         cursor.execute(columns_query, (schema_name, table_name))
 
-        for row in cursor.fetchall():
+        for row in self._rows(cursor):
             columns.append(Column(
                 name=row.column_name,
                 data_type=row.data_type,
@@ -339,7 +418,7 @@ class MSSQLExtractor:
                 cursor = conn.cursor()
                 cursor.execute(views_query)
 
-                for row in cursor.fetchall():
+                for row in self._rows(cursor):
                     schema_name, view_name, definition, description = row
 
                     # Get columns for this view
@@ -388,7 +467,7 @@ class MSSQLExtractor:
         cursor = conn.cursor()
         cursor.execute(columns_query, (schema_name, view_name))
 
-        for row in cursor.fetchall():
+        for row in self._rows(cursor):
             columns.append(Column(
                 name=row.column_name,
                 data_type=row.data_type,
@@ -439,7 +518,7 @@ class MSSQLExtractor:
                 cursor = conn.cursor()
                 cursor.execute(proc_query)
 
-                for row in cursor.fetchall():
+                for row in self._rows(cursor):
                     schema_name, proc_name, definition, description = row
 
                     # Get parameters for this procedure
@@ -490,7 +569,7 @@ class MSSQLExtractor:
         cursor = conn.cursor()
         cursor.execute(params_query, (schema_name, proc_name))
 
-        for row in cursor.fetchall():
+        for row in self._rows(cursor):
             parameters.append({
                 'name': row.param_name,
                 'data_type': row.data_type,
@@ -540,7 +619,7 @@ class MSSQLExtractor:
                 cursor = conn.cursor()
                 cursor.execute(fk_query)
 
-                for row in cursor.fetchall():
+                for row in self._rows(cursor):
                     foreign_keys.append(ForeignKey(
                         name=row.fk_name,
                         source_schema=row.source_schema,
@@ -587,7 +666,7 @@ class MSSQLExtractor:
                 cursor = conn.cursor()
                 cursor.execute(idx_query)
 
-                for row in cursor.fetchall():
+                for row in self._rows(cursor):
                     # Get columns for this index
                     columns = self._extract_index_columns(
                         conn, row.schema_name, row.table_name, row.index_name
@@ -634,7 +713,7 @@ class MSSQLExtractor:
         cursor = conn.cursor()
         cursor.execute(cols_query, (schema_name, table_name, index_name))
 
-        for row in cursor.fetchall():
+        for row in self._rows(cursor):
             columns.append(row.name)
 
         return columns
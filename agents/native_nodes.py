@@ -50,6 +50,26 @@ def get_llm() -> Optional[ChatAnthropic]:
     return _llm
 
 
+def record_token_usage(state: MigrationState, phase: str, response: Any) -> None:
+    """Add an LLM response's tokens to the phase's totals, which are reported to the
+    backend with the phase metrics and count against the monthly AI token budget"""
+    usage = getattr(response, 'usage_metadata', None) or {}
+    totals = state.setdefault('token_usage', {}).setdefault(
+        phase, {'prompt_tokens': 0, 'completion_tokens': 0}
+    )
+    totals['prompt_tokens'] += int(usage.get('input_tokens') or 0)
+    totals['completion_tokens'] += int(usage.get('output_tokens') or 0)
+
+
+# Model name prefixes of the dbt style guide, used where the organization sets none
+DEFAULT_MODEL_PREFIXES = {
+    'staging_prefix': 'stg_',
+    'intermediate_prefix': 'int_',
+    'fact_prefix': 'fct_',
+    'dimension_prefix': 'dim_',
+}
+
+
 # =============================================================================
 # ASSESSMENT NODE
 # =============================================================================
@@ -137,6 +157,7 @@ Full metadata:
                 SystemMessage(content=system_prompt),
                 HumanMessage(content=user_prompt)
             ])
+            record_token_usage(state, "assessment", response)
 
             # Extract and validate output
             assessment_text = validate_llm_output(response.content)
@@ -213,11 +234,12 @@ def planner_node(state: MigrationState) -> MigrationState:
             raise ValueError("No assessment data available for planning")
 
         # Build prompt
+        prefixes = {**DEFAULT_MODEL_PREFIXES, **{k: v for k, v in (state.get('naming') or {}).items() if v}}
         system_prompt = """You are an expert dbt migration planner.
 Create a detailed migration plan based on the assessment.
 
 For each MSSQL object, create a dbt model specification:
-1. Model name (following dbt naming conventions: stg_, int_, fct_, dim_)
+1. Model name (following the naming conventions below)
 2. Source object (table/view name)
 3. Model type (staging/intermediate/fact/dimension)
 4. Dependencies (which other models it depends on)
@@ -238,6 +260,8 @@ Respond in JSON format:
   ],
   "total_models": int
 }
+""" + f"""
+Naming conventions: prefix staging models with {prefixes['staging_prefix']}, intermediate models with {prefixes['intermediate_prefix']}, facts with {prefixes['fact_prefix']} and dimensions with {prefixes['dimension_prefix']}.
 """
 
         user_prompt = f"""Create migration plan for this database:
@@ -267,7 +291,7 @@ Create a model for each table and view.
             models = []
             for i, table in enumerate(tables[:20]):  # Limit to 20 for demo
                 models.append({
-                    "name": f"stg_{table.get('name', f'table_{i}')}",
+                    "name": f"{prefixes['staging_prefix']}{table.get('name', f'table_{i}')}",
                     "source_object": f"{table.get('schema', 'dbo')}.{table.get('name', f'table_{i}')}",
                     "model_type": "staging",
                     "dependencies": [],
@@ -277,7 +301,7 @@ Create a model for each table and view.
 
             for i, view in enumerate(views[:10]):  # Limit to 10 for demo
                 models.append({
-                    "name": f"int_{view.get('name', f'view_{i}')}",
+                    "name": f"{prefixes['intermediate_prefix']}{view.get('name', f'view_{i}')}",
                     "source_object": f"{view.get('schema', 'dbo')}.{view.get('name', f'view_{i}')}",
                     "model_type": "intermediate",
                     "dependencies": [],
@@ -295,6 +319,7 @@ Create a model for each table and view.
                 SystemMessage(content=system_prompt),
                 HumanMessage(content=user_prompt)
             ])
+            record_token_usage(state, "planning", response)
 
             # Extract and validate output
             plan_text = validate_llm_output(response.content)
@@ -320,7 +345,7 @@ Create a model for each table and view.
                 models = []
                 for i, table in enumerate(tables[:20]):  # Limit to 20 for demo
                     models.append({
-                        "name": f"stg_{table.get('name', f'table_{i}')}",
+                        "name": f"{prefixes['staging_prefix']}{table.get('name', f'table_{i}')}",
                         "source_object": f"{table.get('schema', 'dbo')}.{table.get('name', f'table_{i}')}",
                         "model_type": "staging",
                         "dependencies": [],
@@ -330,7 +355,7 @@ Create a model for each table and view.
 
                 for i, view in enumerate(views[:10]):  # Limit to 10 for demo
                     models.append({
-                        "name": f"int_{view.get('name', f'view_{i}')}",
+                        "name": f"{prefixes['intermediate_prefix']}{view.get('name', f'view_{i}')}",
                         "source_object": f"{view.get('schema', 'dbo')}.{view.get('name', f'view_{i}')}",
                         "model_type": "intermediate",
                         "dependencies": [],
@@ -349,7 +374,8 @@ Create a model for each table and view.
         state['plan_complete'] = True
         state['phase'] = 'execution'
 
-        # Create model tracking entries
+        # Create model tracking entries; tables a resumed run already generated are kept
+        skipped = {t.lower() for t in state.get('skip_tables') or []}
         state['models'] = [
             {
                 'name': model['name'],
@@ -363,6 +389,7 @@ Create a model for each table and view.
                 'dependencies': model.get('dependencies', [])
             }
             for model in plan_data['models']
+            if str(model.get('source_object') or '').lower() not in skipped
         ]
         state['current_model_index'] = 0
 
@@ -379,6 +406,16 @@ Create a model for each table and view.
 # EXECUTOR NODE
 # =============================================================================
 
+def target_dialect(target: Dict[str, Any]) -> str:
+    """The warehouse and SQL dialect a model is written for, as told to the LLM"""
+    if not target.get('type'):
+        return "Snowflake"
+    dialect = f"{target['type']} with the {target.get('adapter') or 'dbt-' + target['type']} adapter"
+    if target.get('tsql'):
+        dialect += " (T-SQL compatible: no QUALIFY, ILIKE or boolean columns, TOP instead of LIMIT)"
+    return dialect
+
+
 def executor_node(state: MigrationState) -> MigrationState:
     """
     Executor Agent - Generates dbt model SQL for current model.
@@ -451,6 +488,7 @@ Model Name: {model_name}
 Model Type: {model_type}
 Source Object: {source_object}
 Dependencies: {', '.join(dependencies) if dependencies else 'None'}
+Target: {target_dialect(state.get('target') or {})}
 
 Source Schema:
 {columns_info if columns_info else 'Schema not available - generate SELECT * with basic transformations'}
@@ -481,6 +519,7 @@ FROM {{{{ source('mssql', '{source_object.split('.')[-1] if '.' in source_object
                 SystemMessage(content=system_prompt),
                 HumanMessage(content=user_prompt)
             ])
+            record_token_usage(state, "execution", response)
 
             # Extract and validate SQL
             sql_code = validate_llm_output(response.content)
@@ -623,6 +662,7 @@ Provide validation result.
                 SystemMessage(content=system_prompt),
                 HumanMessage(content=user_prompt)
             ])
+            record_token_usage(state, "testing", response)
 
             # Parse validation result
             validation_text = validate_llm_output(response.content)
@@ -751,6 +791,7 @@ Generate corrected SQL now.
                 SystemMessage(content=system_prompt),
                 HumanMessage(content=user_prompt)
             ])
+            record_token_usage(state, "rebuilding", response)
 
             # Extract SQL
             sql_code = validate_llm_output(response.content)
@@ -0,0 +1,112 @@
+"""
+PII masking for sample values
+
+The Go backend sends the organization's PII policy with every request that profiles
+source data. Sample values, min/max values and distinct values are masked with it
+before they leave the profiler, the same way the backend's PIIMasker masks them.
+"""
+
+import hashlib
+import logging
+import re
+from typing import Any, Dict, List, Optional
+
+logger = logging.getLogger(__name__)
+
+# Mask strategies, as in the backend
+MASK_REDACT = "redact"
+MASK_HASH = "hash"
+MASK_PARTIAL = "partial"
+
+# Applied when a request carries no policy; the backend's built-in value patterns
+BUILTIN_VALUE_PATTERNS = [
+    r"\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b",
+    r"\b\d{3}-\d{2}-\d{4}\b",
+    r"\b(?:\d{4}[- ]?){3}\d{4}\b",
+    r"\b[A-Z]{2}\d{2}[A-Z0-9]{11,30}\b",
+    r"\+\d{1,3}[\s-]?\(?\d{1,4}\)?[\s-]?\d{3,4}[\s-]?\d{3,4}\b",
+]
+
+
+def mask_with(strategy: str, value: str) -> str:
+    """Apply a mask strategy to a value"""
+    if strategy == MASK_HASH:
+        return "pii_" + hashlib.sha256(value.encode("utf-8")).hexdigest()[:12]
+    if strategy == MASK_PARTIAL:
+        if len(value) <= 4:
+            return "*" * len(value)
+        return "*" * (len(value) - 4) + value[-4:]
+    return "[PII]"
+
+
+def _compile(patterns: List[str]) -> List[re.Pattern]:
+    compiled = []
+    for pattern in patterns or []:
+        try:
+            compiled.append(re.compile(pattern))
+        except re.error as e:
+            # The backend validates patterns with Go's syntax; skip the few Python can't read
+            logger.warning(f"Skipping PII pattern {pattern!r}: {e}")
+    return compiled
+
+
+class PIIMasker:
+    """Masks sample values per an organization's PII policy"""
+
+    def __init__(self, policy: Optional[Dict[str, Any]] = None):
+        policy = policy or {}
+        self.strategy = policy.get("default_strategy") or MASK_REDACT
+        self.column_rules = _compile(policy.get("sensitive_columns") or [])
+        # The policy lists the built-in patterns itself; without one, apply them anyway
+        value_patterns = policy.get("value_patterns")
+        if value_patterns is None:
+            value_patterns = BUILTIN_VALUE_PATTERNS
+        self.value_rules = _compile(value_patterns)
+
+    def is_sensitive_column(self, column: str) -> bool:
+        return any(rule.search(column or "") for rule in self.column_rules)
+
+    def mask_value(self, column: str, value: Any) -> Any:
+        """Mask a single sample value from the given column"""
+        if value is None:
+            return None
+        text = str(value)
+        if text == "":
+            return value
+        if self.is_sensitive_column(column):
+            return mask_with(self.strategy, text)
+        masked = self.mask_text(text)
+        return value if masked == text else masked
+
+    def mask_text(self, text: str) -> str:
+        """Mask value-pattern matches within free text"""
+        for rule in self.value_rules:
+            text = rule.sub(lambda m: mask_with(self.strategy, m.group(0)), text)
+        return text
+
+    def mask_values(self, column: str, values: List[Any]) -> List[Any]:
+        return [self.mask_value(column, v) for v in values or []]
+
+    def mask_sample_rows(self, rows: List[Dict[str, Any]]) -> List[Dict[str, Any]]:
+        """Mask every value of a set of sample rows"""
+        return [{column: self.mask_value(column, value) for column, value in row.items()} for row in rows or []]
+
+    def mask_metadata(self, metadata: Dict[str, Any]) -> Dict[str, Any]:
+        """
+        Mask the sample values profiling adds to extracted metadata, in place.
+
+        Distinct values become accepted_values tests in the generated project, where a
+        masked value would fail every run; a column whose values need masking loses them.
+        """
+        for relation in (metadata.get("tables") or []) + (metadata.get("views") or []):
+            for col in relation.get("columns") or []:
+                name = col.get("name") or col.get("column_name") or ""
+                if col.get("sample_values"):
+                    col["sample_values"] = self.mask_values(name, col["sample_values"])
+                for key in ("min_value", "max_value"):
+                    if col.get(key) is not None:
+                        col[key] = self.mask_value(name, col[key])
+                distinct = col.get("distinct_values")
+                if distinct and self.mask_values(name, distinct) != list(distinct):
+                    col.pop("distinct_values")
+        return metadata
@@ -133,6 +133,15 @@ class MigrationState(TypedDict, total=False):
     # Metadata
     metadata: Optional[Dict[str, Any]]
     project_path: Optional[str]
+    # schema.table names a resumed run already generated; the planner leaves them out
+    skip_tables: List[str]
+    # The target connection the models are written for, and the organization's model
+    # name prefixes (staging_prefix, intermediate_prefix, fact_prefix, dimension_prefix)
+    target: Dict[str, Any]
+    naming: Dict[str, str]
+
+    # LLM tokens per phase: {"planning": {"prompt_tokens": 0, "completion_tokens": 0}}
+    token_usage: Dict[str, Dict[str, int]]
 
     # Error tracking
     errors: List[str]
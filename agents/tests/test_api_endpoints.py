@@ -75,6 +75,58 @@ class TestMigrationEndpoints:
         response = client.get("/migrations/99999/files")
         assert response.status_code == 404
 
+    @pytest.mark.unit
+    def test_phase_metrics_include_tokens(self):
+        """Test that a phase's LLM usage is sent with its metrics"""
+        import time
+        from types import SimpleNamespace
+        from agents.api import create_migration, phase_metrics, record_token_usage
+
+        create_migration(99998)
+        record_token_usage(99998, "validating", SimpleNamespace(input_tokens=120, output_tokens=30))
+        record_token_usage(99998, "validating", SimpleNamespace(input_tokens=80, output_tokens=20))
+
+        metrics = phase_metrics(99998, "validating", time.monotonic(), tables_processed=2)
+        assert metrics["prompt_tokens"] == 200
+        assert metrics["completion_tokens"] == 50
+        assert metrics["tables_processed"] == 2
+        assert phase_metrics(99998, "extracting_metadata", time.monotonic())["prompt_tokens"] == 0
+
+    @pytest.mark.unit
+    def test_project_uploaded_to_region_bucket(self, tmp_path, monkeypatch):
+        """Test that a pinned project is copied into its region's bucket"""
+        import sys
+        from types import SimpleNamespace
+        from agents.api import upload_project_to_bucket
+
+        uploaded = []
+        client = SimpleNamespace(upload_file=lambda path, bucket, key: uploaded.append((bucket, key)))
+        monkeypatch.setitem(sys.modules, "boto3", SimpleNamespace(client=lambda name: client))
+
+        project = tmp_path / "migration_7_sales"
+        (project / "models").mkdir(parents=True)
+        (project / "dbt_project.yml").write_text("name: sales")
+        (project / "models" / "stg_orders.sql").write_text("select 1")
+
+        uri = upload_project_to_bucket(7, project, "artifacts-eu")
+        assert uri == "s3://artifacts-eu/migrations/7/migration_7_sales"
+        assert sorted(uploaded) == [
+            ("artifacts-eu", "migrations/7/migration_7_sales/dbt_project.yml"),
+            ("artifacts-eu", "migrations/7/migration_7_sales/models/stg_orders.sql"),
+        ]
+
+    @pytest.mark.unit
+    def test_migration_pinned_to_other_region_refused(self, client, monkeypatch):
+        """Test that a regional instance refuses another region's migrations"""
+        monkeypatch.setattr("agents.api.DATA_REGION", "eu")
+        response = client.post("/migrations/start", json={
+            "migration_id": 99997,
+            "source_connection": {"type": "mssql", "host": "db", "port": 1433, "database": "Sales"},
+            "target_project": "sales",
+            "data_region": "us",
+        })
+        assert response.status_code == 409
+
 
 class TestChatEndpoint:
     """Test AI chat endpoint"""
@@ -261,3 +313,29 @@ class TestDeploymentEndpoints:
         data = response.json()
         assert "migration_id" in data
         assert "deployments" in data
+
+
+class TestEmbeddingEndpoint:
+    """Test the query embedding endpoint used by the backend's RAG search"""
+
+    @pytest.fixture
+    def client(self, no_api_keys):
+        """Create test client with mock embeddings"""
+        import agents.api
+        agents.api._embedding_provider = None
+        yield TestClient(agents.api.app)
+        agents.api._embedding_provider = None
+
+    @pytest.mark.unit
+    def test_embedding_matches_index_dimensions(self, client):
+        """Query embeddings must be 1536-dim, like the vector(1536) index columns"""
+        response = client.post("/embeddings", json={"text": "customer orders by region"})
+
+        assert response.status_code == 200
+        assert len(response.json()["embedding"]) == 1536
+
+    @pytest.mark.unit
+    def test_embedding_requires_text(self, client):
+        """Test blank text is rejected"""
+        response = client.post("/embeddings", json={"text": "  "})
+        assert response.status_code == 400
@@ -78,6 +78,30 @@ class TestDBTProjectGenerator:
         assert "SELECT" in model_sql or "select" in model_sql.lower()
         assert "FROM" in model_sql or "from" in model_sql.lower()
 
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_generate_staging_model_with_masks(self, generator, mock_mssql_metadata):
+        """Test masked, hashed and dropped columns in a staging model"""
+        table = mock_mssql_metadata["tables"][0]  # customers table
+        masks = generator._masks_for_table([
+            {"column": "email", "action": "hash"},
+            {"table": "customers", "column": "last_name", "action": "mask"},
+            {"table": "dbo.customers", "column": "email", "action": "drop"},
+            {"table": "dbo.orders", "column": "first_name", "action": "drop"},
+        ], table)
+        assert masks == {"email": "drop", "last_name": "mask"}
+
+        model_sql = generator.generate_staging_model(
+            table_name=table["name"],
+            schema_name=table.get("schema", "dbo"),
+            columns=table.get("columns", []),
+            column_masks={"first_name": "hash", "last_name": "mask", "email": "drop"}
+        )
+
+        assert "LOWER(SHA2(CAST(first_name AS VARCHAR), 256)) AS first_name" in model_sql
+        assert "NULL AS last_name" in model_sql
+        assert "email" not in model_sql
+
     @pytest.mark.unit
     @pytest.mark.agent
     def test_generate_full_project(self, generator, mock_mssql_metadata, tmp_path):
@@ -93,6 +117,102 @@ class TestDBTProjectGenerator:
         assert (output_path / "dbt_project.yml").exists()
         assert (output_path / "models").exists()
 
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_generate_full_project_skips_tables(self, generator, mock_mssql_metadata, tmp_path):
+        """Test that a resumed run keeps the models of skipped tables as they are"""
+        output_path = tmp_path / "resumed_project"
+        staging = output_path / "models" / "staging"
+        staging.mkdir(parents=True, exist_ok=True)
+        (staging / "stg_customers.sql").write_text("-- generated by the failed run\n")
+        generator.output_path = output_path
+
+        generator.generate_full_project(mock_mssql_metadata, skip_tables=["DBO.Customers"])
+
+        assert (staging / "stg_customers.sql").read_text() == "-- generated by the failed run\n"
+        assert "mssql_source" in (staging / "stg_orders.sql").read_text()
+        with open(staging / "_schema.yml") as f:
+            schema = yaml.safe_load(f)
+        assert "stg_customers" in [m["name"] for m in schema["models"]]
+
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_generate_snapshots_yml(self, generator):
+        """Test that snapshot configs become snapshots/snapshots.yml as the backend previews it"""
+        path = generator.generate_snapshots_yml([
+            {"table": "Sales.Customer", "unique_key": ["CustomerID"], "strategy": "timestamp",
+             "updated_at": "ModifiedDate", "hard_deletes": "invalidate"},
+            {"table": "dbo.OrderLine", "unique_key": ["OrderID", "LineNo"], "strategy": "check",
+             "check_cols": ["all"]},
+        ])
+
+        assert path.endswith("snapshots/snapshots.yml")
+        with open(path) as f:
+            snapshots = yaml.safe_load(f)["snapshots"]
+        assert snapshots[0] == {
+            "name": "customer_snapshot",
+            "relation": "source('mssql_source', 'Customer')",
+            "config": {
+                "schema": "snapshots",
+                "unique_key": "CustomerID",
+                "strategy": "timestamp",
+                "updated_at": "ModifiedDate",
+                "hard_deletes": "invalidate",
+            },
+        }
+        assert snapshots[1]["config"]["unique_key"] == ["OrderID", "LineNo"]
+        assert snapshots[1]["config"]["check_cols"] == "all"
+
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_generate_exposures_yml(self, generator):
+        """Test that the backend's exposures are written to models/exposures.yml"""
+        exposures_yaml = (
+            "version: 2\n"
+            "exposures:\n"
+            "  - name: sales_dashboard\n"
+            "    type: dashboard\n"
+            "    owner:\n"
+            "      name: BI Team\n"
+            "    depends_on:\n"
+            "      - ref('stg_orders')\n"
+        )
+
+        path = generator.generate_exposures_yml(exposures_yaml)
+
+        assert path.endswith("models/exposures.yml")
+        with open(path) as f:
+            exposures = yaml.safe_load(f)
+        assert exposures["exposures"][0]["name"] == "sales_dashboard"
+
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_generate_full_project_with_scaffolding(self, mock_mssql_metadata, tmp_path):
+        """Test the simple layout with domain folders and singular model names"""
+        from agents.dbt_generator import DBTProjectGenerator, inflect_model_name
+
+        output_path = tmp_path / "scaffolded_project"
+        generator = DBTProjectGenerator(
+            project_name="scaffolded",
+            output_path=str(output_path),
+            scaffolding={
+                "layers": "simple",
+                "folders": "domain",
+                "naming": "singular",
+                "domains": [{"name": "sales", "tables": ["dbo.orders"]}],
+            }
+        )
+        generator.generate_full_project(mock_mssql_metadata)
+
+        assert (output_path / "models" / "sales" / "stg_order.sql").exists()
+        assert (output_path / "models" / "shared" / "stg_customer.sql").exists()
+        assert (output_path / "models" / "_schema.yml").exists()
+        assert not (output_path / "models" / "staging").exists()
+
+        assert inflect_model_name("order_details", "singular") == "order_detail"
+        assert inflect_model_name("sales_tax", "plural") == "sales_taxes"
+        assert inflect_model_name("person_addresses", "source") == "person_addresses"
+
     @pytest.mark.unit
     @pytest.mark.agent
     def test_generate_sources_yml(self, generator, mock_mssql_metadata):
@@ -240,3 +360,34 @@ class TestDBTGeneratorWarehouses:
         content = Path(file_path).read_text()
         # Fabric uses similar config to SQL Server
         assert "fabric" in content.lower() or "type:" in content
+
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_target_and_naming(self, mock_mssql_metadata, tmp_path):
+        """Test that the backend's target connection and staging prefix shape the project"""
+        from agents.dbt_generator import DBTProjectGenerator
+
+        output_path = tmp_path / "target_output"
+        generator = DBTProjectGenerator(
+            project_name="target_project",
+            output_path=str(output_path),
+            scaffolding={"staging_prefix": "src_"},
+            target={
+                "type": "fabric",
+                "adapter": "dbt-fabric",
+                "database": "SalesWarehouse",
+                "lakehouse": "SalesLakehouse",
+                "tsql": True,
+            }
+        )
+        generator.generate_full_project(mock_mssql_metadata)
+
+        with open(output_path / "profiles.yml") as f:
+            profile = yaml.safe_load(f)["target_project"]["outputs"]["dev"]
+        assert profile["type"] == "fabric"
+        assert profile["database"] == "SalesWarehouse"
+        with open(output_path / "models" / "staging" / "_sources.yml") as f:
+            sources = yaml.safe_load(f)
+        assert sources["sources"][0]["database"] == "SalesLakehouse"
+        assert (output_path / "models" / "staging" / "src_customers.sql").exists()
+        assert "pip install dbt-fabric" in (output_path / "README.md").read_text()
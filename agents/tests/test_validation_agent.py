@@ -182,6 +182,27 @@ FROM {{{{ source('raw', '{table['name']}') }}}}
 
         assert result is not None
 
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_dbt_compile_gets_secrets(self, tmp_path, monkeypatch):
+        """Test that secrets reach dbt's environment only"""
+        import subprocess
+        from types import SimpleNamespace
+        from agents.validation_agent import ValidationAgent
+
+        calls = []
+
+        def fake_run(args, **kwargs):
+            calls.append(kwargs)
+            return SimpleNamespace(returncode=0, stdout="", stderr="")
+
+        monkeypatch.setattr(subprocess, "run", fake_run)
+        agent = ValidationAgent(str(tmp_path), secrets={"DBT_ENV_SECRET_TOKEN": "s3cret"})
+
+        assert agent._run_dbt_compile()["success"]
+        assert calls[0]["env"]["DBT_ENV_SECRET_TOKEN"] == "s3cret"
+        assert not any("s3cret" in p.read_text() for p in tmp_path.rglob("*") if p.is_file())
+
 
 class TestValidationTypes:
     """Test different validation types"""
@@ -304,3 +325,38 @@ class TestEnhanceSchemaYml:
 
         # Should contain unique tests for PK columns
         assert result is not None
+
+    @pytest.mark.unit
+    @pytest.mark.agent
+    def test_enhance_schema_applies_test_coverage(self, tmp_path):
+        """Test that per-table test coverage overrides the migration defaults"""
+        from agents.validation_agent import enhance_schema_yml
+
+        metadata = {
+            "tables": [
+                {"schema": "dbo", "name": "orders", "columns": [
+                    {"name": "order_id", "is_primary_key": True, "is_nullable": False},
+                    {"name": "status", "is_nullable": False, "distinct_values": ["open", "closed"]},
+                ]},
+                {"schema": "dbo", "name": "customers", "columns": [
+                    {"name": "customer_id", "is_primary_key": True, "is_nullable": False},
+                ]},
+            ],
+            "foreign_keys": [],
+        }
+        defaults = {"not_null": True, "unique": True, "relationships": True,
+                    "accepted_values": {"enabled": False, "max_distinct": 10}}
+        coverage = dict(defaults, tables=[
+            dict(defaults, table="dbo.orders", not_null=False,
+                 accepted_values={"enabled": True, "max_distinct": 5}),
+        ])
+
+        schema = yaml.safe_load(enhance_schema_yml(str(tmp_path), metadata, coverage))
+        tests = {
+            (model["name"], col["name"]): col.get("tests", [])
+            for model in schema["models"] for col in model["columns"]
+        }
+
+        assert tests[("stg_orders", "order_id")] == ["unique"]
+        assert tests[("stg_orders", "status")] == [{"accepted_values": {"values": ["open", "closed"]}}]
+        assert tests[("stg_customers", "customer_id")] == ["not_null", "unique"]
@@ -26,6 +26,8 @@ from datetime import datetime
 from enum import Enum
 import yaml
 
+from .dbt_generator import staging_model_name
+
 logger = logging.getLogger(__name__)
 
 
@@ -207,18 +209,26 @@ class ValidationAgent:
         'sql_variant': ['variant', 'string', 'any'],
     }
 
-    def __init__(self, project_path: str, source_connection: Optional[SourceConnectionInfo] = None):
+    def __init__(
+        self,
+        project_path: str,
+        source_connection: Optional[SourceConnectionInfo] = None,
+        secrets: Optional[Dict[str, str]] = None
+    ):
         """
         Initialize the validation agent.
 
         Args:
             project_path: Path to the dbt project directory
             source_connection: Optional source database connection for row count validation
+            secrets: Optional env_var() values the project needs, set only in dbt's
+                environment and never written to disk
         """
         self.project_path = Path(project_path)
         self.models_path = self.project_path / "models"
         self.staging_path = self.models_path / "staging"
         self.source_connection = source_connection
+        self.secrets = secrets or {}
         self._mssql_extractor = None
 
     def _get_mssql_connection(self):
@@ -447,6 +457,7 @@ class ValidationAgent:
             process = subprocess.run(
                 ["dbt", "compile"],
                 cwd=str(self.project_path),
+                env={**os.environ, **self.secrets},
                 capture_output=True,
                 text=True,
                 timeout=300
@@ -1010,13 +1021,20 @@ class ValidationAgent:
 
     def generate_enhanced_schema_yml(
         self,
-        source_metadata: Dict[str, Any]
+        source_metadata: Dict[str, Any],
+        test_coverage: Optional[Dict[str, Any]] = None,
+        scaffolding: Optional[Dict[str, Any]] = None
     ) -> str:
         """
         Generate enhanced schema.yml with column-level tests based on source constraints.
 
         Args:
             source_metadata: Extracted MSSQL metadata
+            test_coverage: Optional tests to generate, with every default filled in by
+                the backend: not_null, unique, relationships and accepted_values, plus
+                overrides per schema.table
+            scaffolding: Optional project structure the models were generated with,
+                whose naming the model names follow
 
         Returns:
             YAML content for enhanced schema.yml
@@ -1031,13 +1049,17 @@ class ValidationAgent:
 
         for table in tables:
             table_name = table.get('name', '')
-            model_name = f"stg_{table_name.lower()}"
+            model_name = staging_model_name(table_name, scaffolding)
+            coverage = _table_test_coverage(test_coverage, table)
+            accepted_values = coverage.get('accepted_values') or {}
 
             model_config = {
                 "name": model_name,
                 "description": f"Staging model for {table.get('schema', 'dbo')}.{table_name}",
                 "columns": []
             }
+            # Columns of a composite primary key aren't unique on their own
+            single_key = sum(1 for col in table.get('columns', []) if col.get('is_primary_key')) == 1
 
             for col in table.get('columns', []):
                 col_config = {
@@ -1047,20 +1069,21 @@ class ValidationAgent:
                 }
 
                 # Add NOT NULL test
-                if not col.get('is_nullable', True):
+                if coverage.get('not_null', True) and not col.get('is_nullable', True):
                     col_config["tests"].append("not_null")
 
                 # Add UNIQUE test for primary keys
-                if col.get('is_primary_key'):
+                if coverage.get('unique', True) and col.get('is_primary_key') and single_key:
                     col_config["tests"].append("unique")
 
                 # Add relationship tests for foreign keys
                 table_fks = [fk for fk in foreign_keys
-                            if fk.get('source_table', '').lower() == table_name.lower()
+                            if coverage.get('relationships', True)
+                            and fk.get('source_table', '').lower() == table_name.lower()
                             and fk.get('source_column', '').lower() == col['name'].lower()]
 
                 for fk in table_fks:
-                    target_model = f"stg_{fk['target_table'].lower()}"
+                    target_model = staging_model_name(fk['target_table'], scaffolding)
                     target_col = fk['target_column'].lower()
                     col_config["tests"].append({
                         "relationships": {
@@ -1069,8 +1092,14 @@ class ValidationAgent:
                         }
                     })
 
-                # Add accepted_values test for small enums (if we detect them)
-                # This would require data profiling - skipped for now
+                # Add accepted_values test for small enums, when profiling found the
+                # column's distinct values
+                distinct_values = col.get('distinct_values')
+                max_distinct = accepted_values.get('max_distinct') or 10
+                if accepted_values.get('enabled') and distinct_values and len(distinct_values) <= max_distinct:
+                    col_config["tests"].append({
+                        "accepted_values": {"values": list(distinct_values)}
+                    })
 
                 # Only add column if it has tests or description
                 if col_config["tests"] or col.get('description'):
@@ -1509,7 +1538,8 @@ def validate_migration(
     validate_row_counts: bool = False,
     validate_data_types: bool = True,
     generate_dbt_tests: bool = True,
-    source_connection: Optional[SourceConnectionInfo] = None
+    source_connection: Optional[SourceConnectionInfo] = None,
+    secrets: Optional[Dict[str, str]] = None
 ) -> Dict[str, Any]:
     """
     Convenience function to validate a migration.
@@ -1522,11 +1552,12 @@ def validate_migration(
         validate_data_types: Whether to validate data type mappings
         generate_dbt_tests: Whether to generate dbt tests
         source_connection: Optional source connection for row count validation
+        secrets: Optional env_var() values the project needs to compile
 
     Returns:
         Validation report as dictionary
     """
-    agent = ValidationAgent(project_path, source_connection=source_connection)
+    agent = ValidationAgent(project_path, source_connection=source_connection, secrets=secrets)
     report = agent.validate_project(
         source_metadata,
         run_dbt_compile=run_compile,
@@ -1537,9 +1568,26 @@ def validate_migration(
     return agent.to_dict(report)
 
 
+def _table_test_coverage(
+    test_coverage: Optional[Dict[str, Any]],
+    table: Dict[str, Any]
+) -> Dict[str, Any]:
+    """Tests to generate for a table: its override, or the migration's defaults"""
+    if not test_coverage:
+        return {}
+    name = str(table.get('name', '')).lower()
+    qualified = f"{table.get('schema', 'dbo')}.{table.get('name')}".lower()
+    for override in test_coverage.get('tables') or []:
+        if str(override.get('table', '')).lower() in (qualified, name):
+            return override
+    return test_coverage
+
+
 def enhance_schema_yml(
     project_path: str,
-    source_metadata: Dict[str, Any]
+    source_metadata: Dict[str, Any],
+    test_coverage: Optional[Dict[str, Any]] = None,
+    scaffolding: Optional[Dict[str, Any]] = None
 ) -> str:
     """
     Generate enhanced schema.yml with tests.
@@ -1547,12 +1595,14 @@ def enhance_schema_yml(
     Args:
         project_path: Path to dbt project
         source_metadata: Source database metadata
+        test_coverage: Optional tests to generate, as sent by the backend
+        scaffolding: Optional project structure, as sent by the backend
 
     Returns:
         Enhanced YAML content
     """
     agent = ValidationAgent(project_path)
-    return agent.generate_enhanced_schema_yml(source_metadata)
+    return agent.generate_enhanced_schema_yml(source_metadata, test_coverage, scaffolding)
 
 
 # CLI interface
@@ -12,6 +12,7 @@ import asyncio
 import logging
 from typing import Dict, Any, Optional, List
 from datetime import datetime
+from pathlib import Path
 from contextlib import asynccontextmanager
 from dataclasses import dataclass, field
 
@@ -21,6 +22,7 @@ import httpx
 
 from agents.state import MigrationState
 from agents.graph import create_migration_graph, run_migration
+from agents.dbt_generator import DBTProjectGenerator
 from agents.native_nodes import (
     assessment_node,
     planner_node,
@@ -36,6 +38,15 @@ logger = logging.getLogger(__name__)
 
 # Backend URL for updating migration status
 BACKEND_URL = os.getenv("BACKEND_URL", "http://localhost:8080")
+# Shared with the backend, whose /internal routes require it on the public port
+INTERNAL_SERVICE_TOKEN = os.getenv("INTERNAL_SERVICE_TOKEN", "")
+# Migrations this instance runs at once; reported to the backend through /capacity
+MAX_CONCURRENT_MIGRATIONS = int(os.getenv("MAX_CONCURRENT_MIGRATIONS", "4"))
+# How long a stop request waits for the migration task to finish cancelling
+STOP_ACK_TIMEOUT = float(os.getenv("STOP_ACK_TIMEOUT", "20"))
+# The data region this instance serves (eu or us); empty for the default instance.
+# A regional instance refuses migrations pinned to another region.
+DATA_REGION = os.getenv("DATA_REGION", "")
 
 # Store active migrations
 active_migrations: Dict[int, Dict[str, Any]] = {}
@@ -186,6 +197,46 @@ class MigrationRequest(BaseModel):
     target_project: str
     tables: Optional[List[str]] = None
     include_views: bool = False
+    # Per-table row filters and excluded columns for the staging models:
+    # [{"table": "Sales.Customer", "where": "IsDeleted = 0", "exclude_columns": ["SSN"]}]
+    table_filters: Optional[List[Dict[str, Any]]] = None
+    # The organization's column masking rules: [{"table": "Sales.Customer", "column": "SSN",
+    # "action": "hash"}]; mask NULLs the column, hash replaces it with its SHA-256, drop
+    # leaves it out. A rule without a table applies to every table.
+    column_masks: Optional[List[Dict[str, Any]]] = None
+    # The dbt tests to generate, with every default filled in by the backend:
+    # {"not_null": true, "unique": true, "relationships": true,
+    #  "accepted_values": {"enabled": false, "max_distinct": 10}, "tables": [...]}
+    test_coverage: Optional[Dict[str, Any]] = None
+    # The project structure, with every default filled in by the backend:
+    # {"layers": "layered", "folders": "domain", "naming": "singular",
+    #  "domains": [{"name": "finance", "tables": ["Sales.Invoice"]}]}
+    scaffolding: Optional[Dict[str, Any]] = None
+    # schema.table names the failed run being resumed already generated; they are not
+    # planned again
+    skip_tables: Optional[List[str]] = None
+    # The migration's exposures, rendered by the backend, for models/exposures.yml
+    exposures_yaml: Optional[str] = None
+    # The source connection's extraction throttle. The LangGraph workflow reads only the
+    # metadata it is given, never the source, so there is nothing to throttle here.
+    throttle: Optional[Dict[str, Any]] = None
+    # The target connection: {"type": "fabric", "adapter": "dbt-fabric", "database": ...,
+    #  "workspace": ..., "lakehouse": ..., "tsql": true}; models are written for its dialect
+    target: Optional[Dict[str, Any]] = None
+    # The organization's model name prefixes: {"staging_prefix": "stg_",
+    #  "intermediate_prefix": "int_", "fact_prefix": "fct_", "dimension_prefix": "dim_"}
+    naming: Optional[Dict[str, Any]] = None
+    # Tables to generate as dbt snapshots (SCD type 2), validated by the backend:
+    # [{"table": "Sales.Customer", "unique_key": ["CustomerID"], "strategy": "timestamp",
+    #   "updated_at": "ModifiedDate"}]
+    snapshots: Optional[List[Dict[str, Any]]] = None
+    # env_var() values the generated project needs. The LangGraph workflow never runs
+    # dbt, so they are accepted and not kept.
+    secrets: Optional[Dict[str, str]] = None
+    # The organization's data region and that region's bucket; the generated project is
+    # copied into the bucket under migrations/<id>/
+    data_region: Optional[str] = None
+    artifact_bucket: Optional[str] = None
 
 
 class MigrationStatus(BaseModel):
@@ -207,6 +258,14 @@ class HealthResponse(BaseModel):
     version: str
 
 
+class CapacityResponse(BaseModel):
+    """Current load, polled by the backend to queue migrations while saturated."""
+    slots: int
+    active: int
+    queue_depth: int
+    saturated: bool
+
+
 @asynccontextmanager
 async def lifespan(app: FastAPI):
     """Application lifespan handler."""
@@ -227,13 +286,35 @@ app = FastAPI(
 )
 
 
+def service_headers() -> Dict[str, str]:
+    """Headers that authenticate a callback to the backend's /internal routes."""
+    if not INTERNAL_SERVICE_TOKEN:
+        return {}
+    return {"X-Service-Token": INTERNAL_SERVICE_TOKEN}
+
+
+def upload_project_to_bucket(migration_id: int, project_path: str, bucket: str) -> str:
+    """Copy a generated project into the region's bucket; returns its s3:// location."""
+    import boto3
+
+    s3 = boto3.client("s3")
+    root = Path(project_path)
+    prefix = f"migrations/{migration_id}/{root.name}"
+    for file_path in root.rglob("*"):
+        if file_path.is_file():
+            s3.upload_file(str(file_path), bucket, f"{prefix}/{file_path.relative_to(root).as_posix()}")
+    return f"s3://{bucket}/{prefix}"
+
+
 async def update_backend_status(
     migration_id: int,
     status: str,
     progress: int,
-    error: Optional[str] = None
-):
-    """Update migration status in the Go backend."""
+    error: Optional[str] = None,
+    metrics: Optional[List[Dict[str, Any]]] = None
+) -> bool:
+    """Update migration status in the Go backend. Returns whether the organization's
+    monthly AI token budget is used up."""
     try:
         async with httpx.AsyncClient() as client:
             payload = {
@@ -242,14 +323,50 @@ async def update_backend_status(
             }
             if error:
                 payload["error"] = error
+            if metrics:
+                payload["metrics"] = metrics
 
-            await client.patch(
+            response = await client.patch(
                 f"{BACKEND_URL}/api/v1/internal/migrations/{migration_id}/status",
                 json=payload,
+                headers=service_headers(),
                 timeout=10.0,
             )
+            if response.status_code == 200:
+                return bool(response.json().get("token_budget_exceeded"))
     except Exception as e:
         logger.error(f"Failed to update backend status: {e}")
+    return False
+
+
+async def report_ai_usage(
+    migration_id: int,
+    phase: str,
+    prompt_tokens: int,
+    completion_tokens: int
+) -> bool:
+    """Report a phase's LLM tokens so far to the Go backend; a later report for the
+    phase replaces it. Returns whether the organization's monthly AI token budget is
+    used up."""
+    try:
+        async with httpx.AsyncClient() as client:
+            response = await client.post(
+                f"{BACKEND_URL}/api/v1/internal/ai-usage",
+                json={
+                    "migration_id": migration_id,
+                    "phase": phase,
+                    "prompt_tokens": prompt_tokens,
+                    "completion_tokens": completion_tokens,
+                },
+                headers=service_headers(),
+                timeout=10.0,
+            )
+            if response.status_code == 200:
+                return bool(response.json().get("token_budget_exceeded"))
+            logger.error(f"Failed to report AI usage: {response.status_code} {response.text}")
+    except Exception as e:
+        logger.error(f"Failed to report AI usage: {e}")
+    return False
 
 
 async def run_migration_task(
@@ -257,6 +374,7 @@ async def run_migration_task(
     initial_state: MigrationState
 ):
     """Background task to run a migration."""
+    active_migrations[migration_id]["task"] = asyncio.current_task()
     try:
         active_migrations[migration_id]["status"] = "running"
         await update_backend_status(migration_id, "running", 0)
@@ -274,21 +392,66 @@ async def run_migration_task(
 
         # Run migration in a thread pool to not block async
         loop = asyncio.get_event_loop()
+        reported: Dict[str, Dict[str, int]] = {}
+        budget_exceeded = False
+
+        def report_tokens(node_name: str, state: MigrationState) -> bool:
+            """Report the phases whose LLM tokens changed; stops the graph once the
+            monthly AI token budget is used up"""
+            nonlocal budget_exceeded
+            for phase, totals in (state.get("token_usage") or {}).items():
+                if reported.get(phase) == totals:
+                    continue
+                reported[phase] = dict(totals)
+                exceeded = asyncio.run_coroutine_threadsafe(
+                    report_ai_usage(
+                        migration_id, phase, totals["prompt_tokens"], totals["completion_tokens"]
+                    ),
+                    loop,
+                ).result()
+                budget_exceeded = budget_exceeded or exceeded
+            return not budget_exceeded
+
         final_state = await loop.run_in_executor(
             None,
             lambda: run_migration(
                 graph,
                 initial_state,
-                {"configurable": {"thread_id": f"migration-{migration_id}"}}
+                {"configurable": {"thread_id": f"migration-{migration_id}"}},
+                on_node=report_tokens,
             )
         )
 
+        # Exposures and snapshots go into the project next to the generated models
+        generator = DBTProjectGenerator(
+            project_name=initial_state["target_project"],
+            output_path=initial_state["project_path"],
+        )
+        if initial_state.get("exposures_yaml"):
+            generator.generate_exposures_yml(initial_state["exposures_yaml"])
+        if initial_state.get("snapshots"):
+            generator.generate_snapshots_yml(initial_state["snapshots"])
+
+        # A pinned organization's project must end up in its region's bucket; a failed
+        # upload fails the migration
+        bucket = active_migrations[migration_id].get("artifact_bucket")
+        if bucket:
+            uri = await asyncio.to_thread(
+                upload_project_to_bucket, migration_id, initial_state["project_path"], bucket
+            )
+            active_migrations[migration_id]["artifacts_uri"] = uri
+            logger.info(f"Migration {migration_id}: stored the project at {uri}")
+
         # Update final status
         models = final_state.get("models", [])
         completed = sum(1 for m in models if m.get("status") == "completed")
         failed = sum(1 for m in models if m.get("status") == "failed")
 
-        if failed == 0 and completed > 0:
+        if budget_exceeded:
+            status = "failed"
+            progress = 0
+            error = "Monthly AI token budget used up"
+        elif failed == 0 and completed > 0:
             status = "completed"
             progress = 100
             error = None
@@ -308,13 +471,23 @@ async def run_migration_task(
             "final_state": final_state,
         })
 
-        await update_backend_status(migration_id, status, progress, error)
+        # The phases' token totals are kept with the migration's metrics
+        metrics = [
+            {"phase": phase, **totals}
+            for phase, totals in (final_state.get("token_usage") or {}).items()
+        ]
+        await update_backend_status(migration_id, status, progress, error, metrics=metrics)
 
     except asyncio.CancelledError:
         logger.info(f"Migration {migration_id} was cancelled")
+        if active_migrations[migration_id].get("cancel_requested"):
+            # Stopped by the backend, which already marked it cancelled
+            active_migrations[migration_id]["status"] = "cancelled"
+            active_migrations[migration_id]["error"] = "Cancelled by user"
+            return
         active_migrations[migration_id]["status"] = "failed"
-        active_migrations[migration_id]["error"] = "Cancelled by user"
-        await update_backend_status(migration_id, "failed", 0, "Cancelled by user")
+        active_migrations[migration_id]["error"] = "AI service shut down"
+        await update_backend_status(migration_id, "failed", 0, "AI service shut down")
 
     except Exception as e:
         logger.error(f"Migration {migration_id} failed: {e}", exc_info=True)
@@ -333,12 +506,33 @@ async def health_check():
     )
 
 
+@app.get("/capacity", response_model=CapacityResponse)
+async def capacity():
+    """Report how many migrations are running against the configured slots."""
+    active = sum(
+        1 for m in active_migrations.values()
+        if m.get("status") in ("pending", "running")
+    )
+    return CapacityResponse(
+        slots=MAX_CONCURRENT_MIGRATIONS,
+        active=active,
+        queue_depth=max(active - MAX_CONCURRENT_MIGRATIONS, 0),
+        saturated=active >= MAX_CONCURRENT_MIGRATIONS,
+    )
+
+
 @app.post("/migrations/start")
 async def start_migration(
     request: MigrationRequest,
     background_tasks: BackgroundTasks
 ):
     """Start a new migration."""
+    if DATA_REGION and request.data_region and request.data_region != DATA_REGION:
+        raise HTTPException(
+            status_code=409,
+            detail=f"Migration is pinned to {request.data_region}; this instance serves {DATA_REGION}"
+        )
+
     if request.migration_id in active_migrations:
         existing = active_migrations[request.migration_id]
         if existing.get("status") == "running":
@@ -354,6 +548,16 @@ async def start_migration(
         "target_project": request.target_project,
         "tables": request.tables or [],
         "include_views": request.include_views,
+        "table_filters": request.table_filters or [],
+        "column_masks": request.column_masks or [],
+        "test_coverage": request.test_coverage or {},
+        "scaffolding": request.scaffolding or {},
+        "skip_tables": request.skip_tables or [],
+        "exposures_yaml": request.exposures_yaml or "",
+        "target": request.target or {},
+        "naming": request.naming or {},
+        "snapshots": request.snapshots or [],
+        "project_path": f"./dbt_projects/migration_{request.migration_id}_{request.target_project}",
         "phase": "assessment",
         "models": [],
         "current_model_index": 0,
@@ -367,6 +571,8 @@ async def start_migration(
         "status": "pending",
         "progress": 0,
         "initial_state": initial_state,
+        "data_region": request.data_region or "",
+        "artifact_bucket": request.artifact_bucket,
         "created_at": datetime.utcnow().isoformat(),
     }
 
@@ -408,7 +614,7 @@ async def get_migration_status(migration_id: int):
 
 @app.post("/migrations/{migration_id}/stop")
 async def stop_migration(migration_id: int):
-    """Stop a running migration."""
+    """Stop a running migration and wait for its task to finish cancelling."""
     if migration_id not in active_migrations:
         raise HTTPException(
             status_code=404,
@@ -423,15 +629,21 @@ async def stop_migration(migration_id: int):
             detail="Migration is not running"
         )
 
-    if "task" in migration:
-        migration["task"].cancel()
-
-    migration["status"] = "failed"
-    migration["error"] = "Stopped by user"
-
-    await update_backend_status(migration_id, "failed", 0, "Stopped by user")
+    migration["cancel_requested"] = True
+    task = migration.get("task")
+    if task:
+        task.cancel()
+        await asyncio.wait({task}, timeout=STOP_ACK_TIMEOUT)
+    else:
+        migration["status"] = "cancelled"
 
-    return {"message": "Migration stopped", "migration_id": migration_id}
+    # The agent graph only reports its models when it finishes, so none are kept
+    return {
+        "migration_id": migration_id,
+        "status": "cancelled" if migration.get("status") != "running" else "cancelling",
+        "progress": migration.get("progress", 0),
+        "models_generated": 0,
+    }
 
 
 @app.get("/migrations")
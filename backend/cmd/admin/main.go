@@ -0,0 +1,442 @@
+// admin is the operator CLI for a DataMigrate AI deployment.
+//
+// It connects to the same database as the API server (configured through the usual
+// DB_* environment variables or .env file) and performs account maintenance that
+// can't be done through the API, e.g. recovering the last admin account.
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/crypto"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/security"
+)
+
+const usage = `DataMigrate AI admin CLI
+
+Usage:
+  admin <command> [flags] [arguments]
+
+Commands:
+  reset-password <email> [--password-stdin]   Set a new password (a random one is generated unless given on stdin)
+  unlock-account <email>                      Clear a login lockout and reactivate a deactivated account
+  promote-admin <email> [--demote]            Grant (or revoke) platform admin rights
+  list-orgs                                   List organizations with member and migration counts
+  generate-encryption-key                     Print a new ENCRYPTION_KEY value
+
+Flags:
+  -y, --yes   Skip confirmation prompts
+
+The database is configured with DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME and DB_SSL_MODE.
+`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		fmt.Print(usage)
+		return
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	var err error
+	switch command {
+	case "reset-password":
+		err = resetPassword(args)
+	case "unlock-account":
+		err = unlockAccount(args)
+	case "promote-admin":
+		err = promoteAdmin(args)
+	case "list-orgs":
+		err = listOrgs(args)
+	case "generate-encryption-key":
+		err = generateEncryptionKey(args)
+	default:
+		err = fmt.Errorf("unknown command %q (run 'admin help' for usage)", command)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// commandFlags holds the flags shared by every subcommand
+type commandFlags struct {
+	*flag.FlagSet
+	yes bool
+}
+
+func newCommandFlags(name string) *commandFlags {
+	fs := &commandFlags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&fs.yes, "yes", false, "skip confirmation prompts")
+	fs.BoolVar(&fs.yes, "y", false, "skip confirmation prompts")
+	return fs
+}
+
+// parse accepts flags before or after the positional arguments and checks their count
+func (fs *commandFlags) parse(args []string, want int, usage string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", fs.Name(), err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != want {
+		return nil, errors.New("usage: admin " + usage)
+	}
+	return positional, nil
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question. Without a terminal the answer is no unless --yes was given.
+func confirm(fs *commandFlags, question string) bool {
+	if fs.yes {
+		return true
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintln(os.Stderr, "Refusing to continue without confirmation; re-run with --yes")
+		return false
+	}
+
+	fmt.Printf("%s [y/N]: ", question)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// connect opens the application database using the server's configuration
+func connect() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	err = security.ConfigurePasswordHashing(security.PasswordHashing{
+		Algorithm:         cfg.PasswordHashAlgorithm,
+		BcryptCost:        cfg.BcryptCost,
+		Argon2Memory:      uint32(cfg.Argon2MemoryKiB),
+		Argon2Iterations:  uint32(cfg.Argon2Iterations),
+		Argon2Parallelism: uint8(cfg.Argon2Parallelism),
+	})
+	if err != nil {
+		return fmt.Errorf("invalid password hashing settings: %w", err)
+	}
+	return db.Connect(cfg)
+}
+
+// accountInfo is the subset of a user needed by the account commands
+type accountInfo struct {
+	ID             int64  `db:"id"`
+	Email          string `db:"email"`
+	IsAdmin        bool   `db:"is_admin"`
+	IsActive       bool   `db:"is_active"`
+	AccountType    string `db:"account_type"`
+	OrganizationID *int64 `db:"organization_id"`
+}
+
+func findAccount(email string) (*accountInfo, error) {
+	var account accountInfo
+	err := db.DB.Get(&account, `
+		SELECT id, email, COALESCE(is_admin, FALSE) as is_admin, COALESCE(is_active, TRUE) as is_active,
+		       COALESCE(account_type, 'human') as account_type, organization_id
+		FROM users WHERE LOWER(email) = LOWER($1)
+	`, strings.TrimSpace(email))
+	if err != nil {
+		return nil, fmt.Errorf("no user with email %s", email)
+	}
+	return &account, nil
+}
+
+func resetPassword(args []string) error {
+	fs := newCommandFlags("reset-password")
+	passwordStdin := fs.Bool("password-stdin", false, "read the new password from stdin")
+	positional, err := fs.parse(args, 1, "reset-password <email> [--password-stdin]")
+	if err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	account, err := findAccount(positional[0])
+	if err != nil {
+		return err
+	}
+	if account.AccountType != "human" {
+		return errors.New("service accounts have no password; issue a new API key instead")
+	}
+
+	password := ""
+	generated := false
+	if *passwordStdin {
+		line, err := stdin.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		password = strings.TrimRight(line, "\r\n")
+
+		orgID := int64(0)
+		if account.OrganizationID != nil {
+			orgID = *account.OrganizationID
+		}
+		if violations := security.LoadPasswordPolicy(orgID).CheckPassword(password, account.ID); len(violations) > 0 {
+			for _, v := range violations {
+				fmt.Fprintln(os.Stderr, "  -", v.Message)
+			}
+			return errors.New("password does not meet the password policy")
+		}
+	} else {
+		if password, err = randomPassword(20); err != nil {
+			return err
+		}
+		generated = true
+	}
+
+	if !confirm(fs, fmt.Sprintf("Reset the password for %s?", account.Email)) {
+		return errors.New("aborted")
+	}
+
+	hashedPassword, err := security.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldHash string
+	if err := tx.Get(&oldHash, "SELECT password FROM users WHERE id = $1", account.ID); err == nil {
+		if err := security.RecordPasswordHistory(tx, account.ID, oldHash); err != nil {
+			return fmt.Errorf("failed to record password history: %w", err)
+		}
+	}
+	if _, err := tx.Exec("UPDATE users SET password = $1, password_changed_at = NOW(), updated_at = NOW() WHERE id = $2", hashedPassword, account.ID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	// Outstanding reset links must not be able to override the new password
+	if _, err := tx.Exec("UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL", account.ID); err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Password reset for %s\n", account.Email)
+	if generated {
+		fmt.Printf("Temporary password: %s\n", password)
+		fmt.Println("Share it over a secure channel and ask the user to change it after logging in.")
+	}
+	return nil
+}
+
+func unlockAccount(args []string) error {
+	fs := newCommandFlags("unlock-account")
+	positional, err := fs.parse(args, 1, "unlock-account <email>")
+	if err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	account, err := findAccount(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if !confirm(fs, fmt.Sprintf("Unlock %s?", account.Email)) {
+		return errors.New("aborted")
+	}
+
+	// Lockouts are tracked in the API server's memory; it clears them on the next login
+	// attempt after lockout_cleared_at moves forward. locked_at is set when the user
+	// reported a sign-in from the notification email.
+	_, err = db.DB.Exec(`
+		UPDATE users SET is_active = TRUE, lockout_cleared_at = NOW(), locked_at = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	fmt.Printf("Unlocked %s", account.Email)
+	if !account.IsActive {
+		fmt.Print(" (account reactivated)")
+	}
+	fmt.Println()
+	return nil
+}
+
+func promoteAdmin(args []string) error {
+	fs := newCommandFlags("promote-admin")
+	demote := fs.Bool("demote", false, "revoke admin rights instead")
+	positional, err := fs.parse(args, 1, "promote-admin <email> [--demote]")
+	if err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	account, err := findAccount(positional[0])
+	if err != nil {
+		return err
+	}
+	if account.AccountType != "human" {
+		return errors.New("service accounts cannot be platform admins")
+	}
+	if account.IsAdmin == !*demote {
+		fmt.Printf("%s is already %s\n", account.Email, map[bool]string{true: "an admin", false: "not an admin"}[account.IsAdmin])
+		return nil
+	}
+
+	if *demote {
+		var admins int
+		if err := db.DB.Get(&admins, "SELECT COUNT(*) FROM users WHERE is_admin = TRUE AND COALESCE(is_active, TRUE)"); err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return errors.New("refusing to demote the last active admin")
+		}
+	}
+
+	question := fmt.Sprintf("Grant platform admin rights to %s?", account.Email)
+	if *demote {
+		question = fmt.Sprintf("Revoke platform admin rights from %s?", account.Email)
+	}
+	if !confirm(fs, question) {
+		return errors.New("aborted")
+	}
+
+	if _, err := db.DB.Exec("UPDATE users SET is_admin = $1, updated_at = NOW() WHERE id = $2", !*demote, account.ID); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if *demote {
+		fmt.Printf("%s is no longer an admin\n", account.Email)
+	} else {
+		fmt.Printf("%s is now an admin (takes effect on their next login)\n", account.Email)
+	}
+	return nil
+}
+
+func listOrgs(args []string) error {
+	fs := newCommandFlags("list-orgs")
+	if _, err := fs.parse(args, 0, "list-orgs"); err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var orgs []struct {
+		ID         int64  `db:"id"`
+		Name       string `db:"name"`
+		Slug       string `db:"slug"`
+		Plan       string `db:"plan"`
+		Members    int    `db:"members"`
+		Migrations int    `db:"migrations"`
+		CreatedAt  string `db:"created_at"`
+	}
+	err := db.DB.Select(&orgs, `
+		SELECT o.id, o.name, o.slug, COALESCE(o.plan, 'free') as plan,
+		       (SELECT COUNT(*) FROM organization_members om WHERE om.organization_id = o.id) as members,
+		       (SELECT COUNT(*) FROM migrations m WHERE m.organization_id = o.id) as migrations,
+		       TO_CHAR(o.created_at, 'YYYY-MM-DD') as created_at
+		FROM organizations o
+		ORDER BY o.id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSLUG\tPLAN\tMEMBERS\tMIGRATIONS\tCREATED")
+	for _, org := range orgs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%s\n", org.ID, org.Name, org.Slug, org.Plan, org.Members, org.Migrations, org.CreatedAt)
+	}
+	return w.Flush()
+}
+
+func generateEncryptionKey(args []string) error {
+	fs := newCommandFlags("generate-encryption-key")
+	if _, err := fs.parse(args, 0, "generate-encryption-key"); err != nil {
+		return err
+	}
+
+	if os.Getenv("ENCRYPTION_KEY") != "" {
+		fmt.Fprintln(os.Stderr, "Warning: ENCRYPTION_KEY is already set. Replacing it makes stored connection passwords unreadable.")
+		if !confirm(fs, "Generate a new key anyway?") {
+			return errors.New("aborted")
+		}
+	}
+
+	key, err := crypto.GenerateKeyString()
+	if err != nil {
+		return err
+	}
+	fmt.Println(key)
+	return nil
+}
+
+// randomPassword returns a password that satisfies the default complexity rules
+func randomPassword(length int) (string, error) {
+	const (
+		lower   = "abcdefghijkmnopqrstuvwxyz"
+		upper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+		digits  = "23456789"
+		symbols = "!@#$%^&*-_=+"
+	)
+	sets := []string{lower, upper, digits, symbols}
+	all := strings.Join(sets, "")
+
+	password := make([]byte, length)
+	for i := range password {
+		charset := all
+		if i < len(sets) {
+			charset = sets[i] // one character from each class
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = charset[n.Int64()]
+	}
+
+	// Shuffle so the guaranteed classes aren't always at the front
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// cliConfig is persisted in ~/.dmctl/config.json
+type cliConfig struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+const defaultServer = "http://localhost:8080"
+
+func configPath() string {
+	if path := os.Getenv("DMCTL_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".dmctl.json"
+	}
+	return filepath.Join(home, ".dmctl", "config.json")
+}
+
+// loadSavedConfig reads the config file without environment overrides
+func loadSavedConfig() *cliConfig {
+	cfg := &cliConfig{Server: defaultServer}
+	if data, err := os.ReadFile(configPath()); err == nil {
+		json.Unmarshal(data, cfg)
+	}
+	return cfg
+}
+
+// loadConfig reads the saved config; environment variables take precedence
+func loadConfig() *cliConfig {
+	cfg := loadSavedConfig()
+	if server := os.Getenv("DMCTL_SERVER"); server != "" {
+		cfg.Server = server
+	}
+	if token := os.Getenv("DMCTL_TOKEN"); token != "" {
+		cfg.Token = token
+	}
+	if key := os.Getenv("DMCTL_API_KEY"); key != "" {
+		cfg.APIKey = key
+	}
+	return cfg
+}
+
+func saveConfig(cfg *cliConfig) error {
+	path := configPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Holds credentials, so keep it private to the user
+	return os.WriteFile(path, data, 0600)
+}
+
+// apiClient calls the DataMigrate REST API
+type apiClient struct {
+	cfg  *cliConfig
+	http *http.Client
+}
+
+func newAPIClient(cfg *cliConfig) *apiClient {
+	return &apiClient{cfg: cfg, http: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// apiError is an error response from the API
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+// do sends a request to /api/v1<path> and decodes the JSON response into out (if non-nil)
+func (c *apiClient) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := c.newRequest(method, path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot reach %s: %w", c.cfg.Server, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		return responseError(resp.StatusCode, data)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if raw, ok := out.(*json.RawMessage); ok {
+		*raw = data
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// download saves the response to GET /api/v1<path> to a local file
+func (c *apiClient) download(path, dest string) (int64, error) {
+	req, err := c.newRequest("GET", path, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("cannot reach %s: %w", c.cfg.Server, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		return 0, responseError(resp.StatusCode, data)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(f, resp.Body)
+}
+
+// newRequest builds an authenticated request to /api/v1<path>
+func (c *apiClient) newRequest(method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, strings.TrimRight(c.cfg.Server, "/")+"/api/v1"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", c.cfg.APIKey)
+	} else if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	return req, nil
+}
+
+// responseError turns an error response body into an apiError
+func responseError(status int, data []byte) error {
+	var errBody struct {
+		Error string `json:"error"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &errBody) == nil && errBody.Error != "" {
+		message = errBody.Error
+	}
+	if status == http.StatusUnauthorized {
+		message += " - run 'dmctl login' or set DMCTL_API_KEY"
+	}
+	return &apiError{Status: status, Message: message}
+}
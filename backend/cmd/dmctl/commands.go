@@ -0,0 +1,643 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+// newFlagSet creates a flag set that reports errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses flags that may appear before or after positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", fs.Name(), err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
+
+// readSecret reads a single line from stdin, prompting when attached to a terminal
+func readSecret(prompt string) (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, prompt)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func runLogin(client *apiClient, args []string) error {
+	fs := newFlagSet("login")
+	email := fs.String("email", "", "account email")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("login: --email is required")
+	}
+
+	password := os.Getenv("DMCTL_PASSWORD")
+	if password == "" {
+		var err error
+		if password, err = readSecret("Password: "); err != nil {
+			return err
+		}
+	}
+
+	var resp struct {
+		AccessToken     string      `json:"access_token"`
+		User            models.User `json:"user"`
+		PasswordExpired bool        `json:"password_expired"`
+	}
+	// Log in with a password even if an API key is configured
+	client.cfg.APIKey = ""
+	if err := client.do("POST", "/auth/login", map[string]string{"email": *email, "password": password}, &resp); err != nil {
+		return err
+	}
+
+	cfg := loadSavedConfig()
+	cfg.Token = resp.AccessToken
+	cfg.APIKey = ""
+	if err := saveConfig(cfg); err != nil {
+		return fmt.Errorf("logged in but could not save token: %w", err)
+	}
+
+	fmt.Printf("Logged in as %s\n", resp.User.Email)
+	if resp.PasswordExpired {
+		fmt.Fprintln(os.Stderr, "Warning: your password has expired; change it in the web UI")
+	}
+	return nil
+}
+
+func runLogout() error {
+	cfg := loadSavedConfig()
+	cfg.Token = ""
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Println("Logged out")
+	return nil
+}
+
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.New("config: expected set-server, set-key, or show")
+	}
+
+	// Only the saved values are edited so environment overrides never get persisted
+	cfg := loadSavedConfig()
+	if args[0] == "show" {
+		cfg = loadConfig()
+	}
+
+	switch args[0] {
+	case "set-server":
+		if len(args) != 2 {
+			return errors.New("usage: dmctl config set-server <url>")
+		}
+		if _, err := url.ParseRequestURI(args[1]); err != nil {
+			return fmt.Errorf("invalid server URL: %w", err)
+		}
+		cfg.Server = strings.TrimRight(args[1], "/")
+	case "set-key":
+		if len(args) != 2 {
+			return errors.New("usage: dmctl config set-key <api-key>")
+		}
+		if !strings.HasPrefix(args[1], "dm_") {
+			return errors.New("API keys start with dm_")
+		}
+		cfg.APIKey = args[1]
+		cfg.Token = ""
+	case "show":
+		auth := "none"
+		if cfg.APIKey != "" {
+			auth = "api key " + cfg.APIKey[:min(len(cfg.APIKey), 8)] + "..."
+		} else if cfg.Token != "" {
+			auth = "login token"
+		}
+		fmt.Printf("Config: %s\nServer: %s\nAuth:   %s\n", configPath(), cfg.Server, auth)
+		return nil
+	default:
+		return fmt.Errorf("config: unknown subcommand %q", args[0])
+	}
+
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Println("Configuration saved")
+	return nil
+}
+
+func runConnections(client *apiClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("connections: expected list or create")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		var raw json.RawMessage
+		if err := client.do("GET", "/connections", nil, &raw); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(raw)
+		}
+		var conns []models.DatabaseConnection
+		if err := json.Unmarshal(raw, &conns); err != nil {
+			return err
+		}
+		w := newTable()
+		fmt.Fprintln(w, "ID\tNAME\tTYPE\tHOST\tDATABASE\tROLE")
+		for _, conn := range conns {
+			role := "target"
+			if conn.IsSource {
+				role = "source"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s:%d\t%s\t%s\n", conn.ID, conn.Name, conn.DBType, conn.Host, conn.Port, conn.DatabaseName, role)
+		}
+		return w.Flush()
+
+	case "create":
+		fs := newFlagSet("connections create")
+		req := models.CreateConnectionRequest{IsSource: true}
+		fs.StringVar(&req.Name, "name", "", "connection name")
+		fs.StringVar(&req.DBType, "type", "mssql", "database type")
+		fs.StringVar(&req.Host, "host", "", "server host")
+		fs.IntVar(&req.Port, "port", 1433, "server port")
+		fs.StringVar(&req.DatabaseName, "database", "", "database name")
+		fs.StringVar(&req.Username, "username", "", "login user")
+		fs.BoolVar(&req.UseWindowsAuth, "windows-auth", false, "use Windows authentication")
+		passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+		target := fs.Bool("target", false, "register as a target warehouse instead of a source")
+		if _, err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if req.Name == "" || req.Host == "" || req.DatabaseName == "" {
+			return errors.New("connections create: --name, --host, and --database are required")
+		}
+		req.IsSource = !*target
+
+		if *passwordStdin {
+			password, err := readSecret("Password: ")
+			if err != nil {
+				return err
+			}
+			req.Password = password
+		} else {
+			req.Password = os.Getenv("DMCTL_DB_PASSWORD")
+		}
+
+		var conn json.RawMessage
+		if err := client.do("POST", "/connections", req, &conn); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(conn)
+		}
+		var created models.DatabaseConnection
+		json.Unmarshal(conn, &created)
+		fmt.Printf("Created connection %d (%s)\n", created.ID, created.Name)
+		return nil
+
+	default:
+		return fmt.Errorf("connections: unknown subcommand %q", args[0])
+	}
+}
+
+func runMigrations(client *apiClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("migrations: expected list, get, create, start, stop, watch, files, cat, or download")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		var raw json.RawMessage
+		if err := client.do("GET", "/migrations", nil, &raw); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(raw)
+		}
+		var migrations []models.Migration
+		if err := json.Unmarshal(raw, &migrations); err != nil {
+			return err
+		}
+		w := newTable()
+		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tPROGRESS\tSOURCE\tTABLES\tCREATED")
+		for _, m := range migrations {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d%%\t%s\t%d\t%s\n", m.ID, m.Name, m.Status, m.Progress,
+				m.SourceDatabase, m.TablesCount, m.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+
+	case "get", "show":
+		id, err := requireArgs(args[1:], "migrations get <id>", 1)
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := client.do("GET", "/migrations/"+id[0], nil, &raw); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(raw)
+		}
+		var m models.Migration
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return err
+		}
+		printMigration(&m)
+		return nil
+
+	case "create":
+		fs := newFlagSet("migrations create")
+		var req models.CreateMigrationRequest
+		fs.StringVar(&req.Name, "name", "", "migration name")
+		fs.StringVar(&req.SourceDatabase, "source-database", "", "source database name")
+		fs.StringVar(&req.TargetProject, "target-project", "", "dbt project name (defaults to the migration name)")
+		fs.BoolVar(&req.IncludeViews, "include-views", false, "also migrate views")
+		tables := fs.String("tables", "", "comma-separated list of tables (default: all)")
+		if _, err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if req.Name == "" || req.SourceDatabase == "" {
+			return errors.New("migrations create: --name and --source-database are required")
+		}
+		if req.TargetProject == "" {
+			req.TargetProject = strings.ToLower(strings.ReplaceAll(req.Name, " ", "_"))
+		}
+		for _, table := range strings.Split(*tables, ",") {
+			if table = strings.TrimSpace(table); table != "" {
+				req.Tables = append(req.Tables, table)
+			}
+		}
+
+		var raw json.RawMessage
+		if err := client.do("POST", "/migrations", req, &raw); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(raw)
+		}
+		var m models.Migration
+		json.Unmarshal(raw, &m)
+		fmt.Printf("Created migration %d (%s). Start it with: dmctl migrations start %d\n", m.ID, m.Name, m.ID)
+		return nil
+
+	case "start":
+		fs := newFlagSet("migrations start")
+		wait := fs.Bool("wait", false, "follow progress until the migration finishes")
+		id, err := requireFlagArgs(fs, args[1:], "migrations start <id> [--wait]", 1)
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := client.do("POST", "/migrations/"+id[0]+"/start", nil, &raw); err != nil {
+			return err
+		}
+		if !*wait {
+			if jsonOutput {
+				return printJSON(raw)
+			}
+			fmt.Printf("Migration %s started. Follow it with: dmctl migrations watch %s\n", id[0], id[0])
+			return nil
+		}
+		return watchMigration(client, id[0], 2*time.Second)
+
+	case "stop":
+		id, err := requireArgs(args[1:], "migrations stop <id>", 1)
+		if err != nil {
+			return err
+		}
+		if err := client.do("POST", "/migrations/"+id[0]+"/stop", nil, nil); err != nil {
+			return err
+		}
+		fmt.Printf("Migration %s stopped\n", id[0])
+		return nil
+
+	case "watch":
+		fs := newFlagSet("migrations watch")
+		interval := fs.Duration("interval", 2*time.Second, "polling interval")
+		id, err := requireFlagArgs(fs, args[1:], "migrations watch <id> [--interval 2s]", 1)
+		if err != nil {
+			return err
+		}
+		return watchMigration(client, id[0], *interval)
+
+	case "files":
+		id, err := requireArgs(args[1:], "migrations files <id>", 1)
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := client.do("GET", "/migrations/"+id[0]+"/files", nil, &raw); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(raw)
+		}
+		var resp struct {
+			ProjectPath string `json:"project_path"`
+			Files       []struct {
+				Path string `json:"path"`
+				Size int64  `json:"size"`
+				Type string `json:"type"`
+			} `json:"files"`
+		}
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return err
+		}
+		w := newTable()
+		fmt.Fprintln(w, "PATH\tTYPE\tSIZE")
+		for _, f := range resp.Files {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", f.Path, f.Type, f.Size)
+		}
+		return w.Flush()
+
+	case "cat":
+		args, err := requireArgs(args[1:], "migrations cat <id> <path>", 2)
+		if err != nil {
+			return err
+		}
+		var resp struct {
+			Content string `json:"content"`
+		}
+		if err := client.do("GET", "/migrations/"+args[0]+"/files/"+strings.TrimLeft(args[1], "/"), nil, &resp); err != nil {
+			return err
+		}
+		fmt.Print(resp.Content)
+		return nil
+
+	case "download":
+		fs := newFlagSet("migrations download")
+		output := fs.String("o", "", "output file (default <project>.zip)")
+		id, err := requireFlagArgs(fs, args[1:], "migrations download <id> [-o file]", 1)
+		if err != nil {
+			return err
+		}
+		dest := *output
+		if dest == "" {
+			dest = "migration-" + id[0] + ".zip"
+		}
+		size, err := client.download("/migrations/"+id[0]+"/download", dest)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved %s (%d bytes)\n", dest, size)
+		return nil
+
+	default:
+		return fmt.Errorf("migrations: unknown subcommand %q", args[0])
+	}
+}
+
+// watchMigration polls a migration and redraws a progress line until it finishes
+func watchMigration(client *apiClient, id string, interval time.Duration) error {
+	if interval < 500*time.Millisecond {
+		interval = 500 * time.Millisecond
+	}
+
+	lastStatus := ""
+	lastProgress := -1
+	for {
+		var m models.Migration
+		if err := client.do("GET", "/migrations/"+id, nil, &m); err != nil {
+			return err
+		}
+
+		if m.Status != lastStatus || m.Progress != lastProgress {
+			if jsonOutput {
+				json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
+					"id": m.ID, "status": m.Status, "progress": m.Progress, "time": time.Now().UTC(),
+				})
+			} else {
+				fmt.Printf("%s  %-10s %s %3d%%\n", time.Now().Format("15:04:05"), m.Status, progressBar(m.Progress), m.Progress)
+			}
+			lastStatus, lastProgress = m.Status, m.Progress
+		}
+
+		switch m.Status {
+		case "completed":
+			if !jsonOutput {
+				fmt.Printf("Migration %d completed: %d models generated. List them with: dmctl migrations files %d\n",
+					m.ID, m.ModelsGenerated, m.ID)
+			}
+			return nil
+		case "failed", "stopped", "cancelled":
+			if m.Error != nil {
+				return fmt.Errorf("migration %d %s: %s", m.ID, m.Status, *m.Error)
+			}
+			return fmt.Errorf("migration %d %s", m.ID, m.Status)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func progressBar(progress int) string {
+	const width = 30
+	filled := max(0, min(width, progress*width/100))
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
+
+func printMigration(m *models.Migration) {
+	w := newTable()
+	fmt.Fprintf(w, "ID:\t%d\n", m.ID)
+	fmt.Fprintf(w, "Name:\t%s\n", m.Name)
+	fmt.Fprintf(w, "Status:\t%s (%d%%)\n", m.Status, m.Progress)
+	fmt.Fprintf(w, "Source database:\t%s\n", m.SourceDatabase)
+	fmt.Fprintf(w, "Target project:\t%s\n", m.TargetProject)
+	fmt.Fprintf(w, "Tables / views:\t%d / %d\n", m.TablesCount, m.ViewsCount)
+	fmt.Fprintf(w, "Models generated:\t%d\n", m.ModelsGenerated)
+	fmt.Fprintf(w, "Created:\t%s\n", m.CreatedAt.Local().Format(time.RFC1123))
+	if m.CompletedAt != nil {
+		fmt.Fprintf(w, "Completed:\t%s\n", m.CompletedAt.Local().Format(time.RFC1123))
+	}
+	if m.Error != nil {
+		fmt.Fprintf(w, "Error:\t%s\n", *m.Error)
+	}
+	w.Flush()
+}
+
+// deployment mirrors a warehouse deployment returned by the API
+type deployment struct {
+	ID             int64      `json:"id"`
+	Status         string     `json:"status"`
+	DbtCloudRunURL string     `json:"dbt_cloud_run_url,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// deploymentList is the response of GET /migrations/:id/deployments
+type deploymentList struct {
+	Deployments []deployment `json:"deployments"`
+	Warning     string       `json:"warning,omitempty"`
+}
+
+// runDeploy triggers the migration's dbt Cloud job, which holds the warehouse
+// connection and the dbt commands to run
+func runDeploy(client *apiClient, args []string) error {
+	fs := newFlagSet("deploy")
+	wait := fs.Bool("wait", false, "follow the deployment until it finishes")
+	id, err := requireFlagArgs(fs, args, "deploy <migration-id> [--wait]", 1)
+	if err != nil {
+		return err
+	}
+
+	var resp deployment
+	if err := client.do("POST", "/migrations/"+id[0]+"/dbt-cloud/run", nil, &resp); err != nil {
+		return err
+	}
+
+	if !*wait {
+		if jsonOutput {
+			return printJSON(resp)
+		}
+		fmt.Printf("Deployment %d %s. Check it with: dmctl deployments get %s %d\n", resp.ID, resp.Status, id[0], resp.ID)
+		return nil
+	}
+
+	lastStatus := ""
+	for {
+		d, err := getDeployment(client, id[0], resp.ID)
+		if err != nil {
+			return err
+		}
+		if d.Status != lastStatus {
+			fmt.Printf("%s  %s\n", time.Now().Format("15:04:05"), d.Status)
+			lastStatus = d.Status
+		}
+		switch d.Status {
+		case "completed":
+			return nil
+		case "failed", "cancelled":
+			if d.Error != "" {
+				return fmt.Errorf("deployment %d failed: %s", d.ID, d.Error)
+			}
+			return fmt.Errorf("deployment %d %s", d.ID, d.Status)
+		}
+		time.Sleep(3 * time.Second)
+	}
+}
+
+// getDeployment finds a deployment in the migration's list, which also refreshes the
+// status of dbt Cloud runs
+func getDeployment(client *apiClient, migrationID string, deploymentID int64) (*deployment, error) {
+	var list deploymentList
+	if err := client.do("GET", "/migrations/"+migrationID+"/deployments", nil, &list); err != nil {
+		return nil, err
+	}
+	for i := range list.Deployments {
+		if list.Deployments[i].ID == deploymentID {
+			return &list.Deployments[i], nil
+		}
+	}
+	return nil, fmt.Errorf("deployment %d not found for migration %s", deploymentID, migrationID)
+}
+
+func runDeployments(client *apiClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New("deployments: expected list or get")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		id, err := requireArgs(args[1:], "deployments list <migration-id>", 1)
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := client.do("GET", "/migrations/"+id[0]+"/deployments", nil, &raw); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(raw)
+		}
+		var list deploymentList
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return err
+		}
+		if list.Warning != "" {
+			fmt.Fprintln(os.Stderr, "Warning:", list.Warning)
+		}
+		w := newTable()
+		fmt.Fprintln(w, "ID\tSTATUS\tSTARTED\tCOMPLETED")
+		for _, d := range list.Deployments {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", d.ID, d.Status, formatTime(d.CreatedAt), formatTime(d.CompletedAt))
+		}
+		return w.Flush()
+
+	case "get", "show":
+		ids, err := requireArgs(args[1:], "deployments get <migration-id> <deployment-id>", 2)
+		if err != nil {
+			return err
+		}
+		deploymentID, err := strconv.ParseInt(ids[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid deployment ID %q", ids[1])
+		}
+		d, err := getDeployment(client, ids[0], deploymentID)
+		if err != nil {
+			return err
+		}
+		return printJSON(d)
+
+	default:
+		return fmt.Errorf("deployments: unknown subcommand %q", args[0])
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// requireArgs checks the number of positional arguments
+func requireArgs(args []string, usage string, n int) ([]string, error) {
+	if len(args) != n {
+		return nil, errors.New("usage: dmctl " + usage)
+	}
+	return args, nil
+}
+
+// requireFlagArgs parses flags and checks the number of positional arguments
+func requireFlagArgs(fs *flag.FlagSet, args []string, usage string, n int) ([]string, error) {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	return requireArgs(positional, usage, n)
+}
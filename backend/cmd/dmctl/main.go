@@ -0,0 +1,112 @@
+// dmctl is a command-line client for the DataMigrate AI API.
+//
+// It covers the day-to-day migration workflow from a terminal: authenticate with a
+// password or an API key, register database connections, create and start
+// migrations, follow their progress, fetch the generated dbt project, and deploy it.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const usage = `dmctl - DataMigrate AI command-line client
+
+Usage:
+  dmctl [--json] <command> [arguments]
+
+Authentication:
+  login --email <email>             Log in (password read from DMCTL_PASSWORD or prompted)
+  logout                            Forget the saved token
+  config set-server <url>           Set the API server (default http://localhost:8080)
+  config set-key <api-key>          Authenticate with an API key instead of a password
+  config show                       Show the active configuration
+
+Connections:
+  connections list
+  connections create --name <n> --host <h> --database <db> --username <u> [--port 1433]
+                     [--type mssql] [--password-stdin] [--windows-auth] [--target]
+
+Migrations:
+  migrations list
+  migrations get <id>
+  migrations create --name <n> --source-database <db> [--target-project <p>]
+                    [--tables a,b,c] [--include-views]
+  migrations start <id> [--wait]
+  migrations stop <id>
+  migrations watch <id> [--interval 2s]
+  migrations files <id>
+  migrations cat <id> <path>
+  migrations download <id> [-o project.zip]
+
+Deployments:
+  deploy <migration-id> [--wait]    Run the migration's dbt Cloud job
+  deployments list <migration-id>
+  deployments get <migration-id> <deployment-id>
+
+Environment:
+  DMCTL_SERVER, DMCTL_TOKEN, DMCTL_API_KEY override the saved configuration.
+  DMCTL_CONFIG sets the config file location (default ~/.dmctl/config.json).
+`
+
+// jsonOutput prints raw API responses instead of tables when --json is given
+var jsonOutput bool
+
+func main() {
+	args := os.Args[1:]
+	for len(args) > 0 && (args[0] == "--json" || args[0] == "-json") {
+		jsonOutput = true
+		args = args[1:]
+	}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Print(usage)
+		return
+	}
+
+	client := newAPIClient(loadConfig())
+
+	var err error
+	switch args[0] {
+	case "login":
+		err = runLogin(client, args[1:])
+	case "logout":
+		err = runLogout()
+	case "config":
+		err = runConfig(args[1:])
+	case "connections", "connection", "conn":
+		err = runConnections(client, args[1:])
+	case "migrations", "migration", "mig":
+		err = runMigrations(client, args[1:])
+	case "deploy":
+		err = runDeploy(client, args[1:])
+	case "deployments", "deployment":
+		err = runDeployments(client, args[1:])
+	default:
+		err = fmt.Errorf("unknown command %q (see 'dmctl help')", args[0])
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.Status == 401 {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+// printJSON pretty-prints a value to stdout
+func printJSON(v interface{}) error {
+	if raw, ok := v.(json.RawMessage); ok {
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err == nil {
+			v = decoded
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
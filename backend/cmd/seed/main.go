@@ -0,0 +1,105 @@
+// seed creates the demo organization, users, connections, and migrations used for
+// trials and E2E tests. It reads the same DB_* configuration as the API server.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/crypto"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/seed"
+)
+
+const usage = `Usage:
+  seed [--reset] [--password <pw>] [--fixture-host <host>] [--fixture-port 1433]
+      Create the demo data (skipped if it already exists unless --reset)
+  seed remove
+      Delete the demo organization and everything it owns
+  seed fixture
+      Print the AdventureWorksLite T-SQL fixture for loading into SQL Server
+`
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fixture":
+			fmt.Print(seed.FixtureSQL)
+			return
+		case "remove":
+			connect()
+			defer db.Close()
+			if err := seed.Reset(); err != nil {
+				log.Fatalf("Failed to remove demo data: %v", err)
+			}
+			fmt.Println("Demo data removed")
+			return
+		case "help", "-h", "--help":
+			fmt.Print(usage)
+			return
+		}
+	}
+
+	opts := seed.DefaultOptions()
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	fs.BoolVar(&opts.Reset, "reset", false, "delete and recreate existing demo data")
+	fs.StringVar(&opts.Password, "password", envOr("SEED_DEMO_PASSWORD", opts.Password), "password for the demo users")
+	fs.StringVar(&opts.FixtureHost, "fixture-host", envOr("SEED_FIXTURE_HOST", opts.FixtureHost), "SQL Server hosting AdventureWorksLite")
+	fs.IntVar(&opts.FixturePort, "fixture-port", opts.FixturePort, "SQL Server port")
+	fs.Parse(os.Args[1:])
+
+	cfg := connect()
+	defer db.Close()
+
+	// Encrypt connection passwords like the API server does
+	if cfg.EncryptionKey != "" {
+		if err := crypto.GetEncryptionService().SetKeyFromString(cfg.EncryptionKey); err != nil {
+			log.Fatalf("Invalid ENCRYPTION_KEY: %v", err)
+		}
+	}
+
+	summary, err := seed.Seed(opts)
+	if err != nil {
+		log.Fatalf("Failed to seed demo data: %v", err)
+	}
+	if !summary.Created {
+		fmt.Printf("Demo organization already exists (id %d); use --reset to recreate it\n", summary.OrganizationID)
+		return
+	}
+
+	fmt.Printf("Created demo organization %d\n", summary.OrganizationID)
+	fmt.Printf("  %d connections, %d migrations\n", summary.Connections, summary.Migrations)
+	fmt.Println("  Users (password: " + opts.Password + "):")
+	for _, email := range summary.Users {
+		fmt.Println("   ", email)
+	}
+}
+
+// connect opens the database and makes sure the schema exists
+func connect() *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.IsProduction() {
+		log.Fatal("Refusing to seed demo data in production")
+	}
+	if err := db.Connect(cfg); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	if err := db.RunMigrations(); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+	return cfg
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
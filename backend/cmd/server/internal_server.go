@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/api"
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/security"
+)
+
+// startInternalServer serves the internal routes on INTERNAL_PORT to clients presenting
+// a certificate issued by INTERNAL_TLS_CLIENT_CA
+func startInternalServer(cfg *config.Config) {
+	tlsConfig, err := security.MutualTLSServerConfig(cfg.InternalTLSCert, cfg.InternalTLSKey, cfg.InternalTLSClientCA)
+	if err != nil {
+		log.Fatalf("Failed to configure internal TLS: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:      cfg.ServerHost + ":" + cfg.InternalPort,
+		Handler:   api.SetupInternalRouter(cfg),
+		TLSConfig: tlsConfig,
+	}
+
+	go func() {
+		log.Printf("Serving internal routes over mutual TLS on %s", server.Addr)
+		// The certificate is already in TLSConfig
+		if err := server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Internal server failed: %v", err)
+		}
+	}()
+}
@@ -2,12 +2,21 @@ package main
 
 import (
 	"log"
+	"os"
+	"time"
 
 	"github.com/datamigrate-ai/backend/internal/aiservice"
 	"github.com/datamigrate-ai/backend/internal/api"
 	"github.com/datamigrate-ai/backend/internal/config"
 	"github.com/datamigrate-ai/backend/internal/crypto"
 	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtcloud"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/metacache"
+	"github.com/datamigrate-ai/backend/internal/oauth"
+	"github.com/datamigrate-ai/backend/internal/powerbi"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/datamigrate-ai/backend/internal/seed"
 )
 
 // @title DataMigrate AI API
@@ -37,6 +46,21 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Validate configuration before touching anything else
+	report := cfg.Preflight(func() error { return db.Ping(cfg, 5*time.Second) })
+	report.Write(os.Stdout)
+	if err := report.Err(); err != nil {
+		if cfg.IsProduction() {
+			log.Fatalf("Refusing to start: %v", err)
+		}
+		log.Printf("Warning: %v", err)
+	}
+
+	// New password hashes use the configured algorithm; older ones are upgraded on sign-in
+	if err := security.ConfigurePasswordHashing(passwordHashing(cfg)); err != nil {
+		log.Fatalf("Invalid password hashing settings: %v", err)
+	}
+
 	// Connect to database
 	if err := db.Connect(cfg); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -70,17 +94,131 @@ func main() {
 		}
 	}
 
-	// Initialize AI service client
+	// Create demo data for trials and E2E environments
+	if cfg.SeedDemoData {
+		if cfg.IsProduction() {
+			log.Printf("WARNING: SEED_DEMO_DATA ignored in production (demo users have a well-known password)")
+		} else {
+			opts := seed.DefaultOptions()
+			if cfg.SeedDemoPassword != "" {
+				opts.Password = cfg.SeedDemoPassword
+			}
+			if cfg.SeedFixtureHost != "" {
+				opts.FixtureHost = cfg.SeedFixtureHost
+			}
+			if summary, err := seed.Seed(opts); err != nil {
+				log.Printf("Warning: Failed to seed demo data: %v", err)
+			} else if summary.Created {
+				log.Printf("Seeded demo organization %d with %d users, %d connections, and %d migrations",
+					summary.OrganizationID, len(summary.Users), summary.Connections, summary.Migrations)
+			}
+		}
+	}
+
+	// Initialize AI service client, over mutual TLS if configured
+	if cfg.AIServiceTLSEnabled() {
+		err := aiservice.ConfigureTLS(aiservice.TLSOptions{
+			CAFile:     cfg.AIServiceTLSCA,
+			CertFile:   cfg.AIServiceTLSCert,
+			KeyFile:    cfg.AIServiceTLSKey,
+			ServerName: cfg.AIServiceTLSServerName,
+		})
+		if err != nil {
+			log.Fatalf("Failed to configure AI service TLS: %v", err)
+		}
+	}
 	aiservice.Init(cfg.AIServiceURL)
-	log.Printf("AI service client initialized: %s", cfg.AIServiceURL)
+	log.Printf("AI service client initialized: %s (mutual TLS: %t)", cfg.AIServiceURL, cfg.AIServiceTLSEnabled())
+	aiservice.InitRegions(map[string]aiservice.RegionConfig{
+		aiservice.RegionEU: {URL: cfg.AIServiceURLEU, ArtifactBucket: cfg.ArtifactBucketEU},
+		aiservice.RegionUS: {URL: cfg.AIServiceURLUS, ArtifactBucket: cfg.ArtifactBucketUS},
+	})
+	// Instances report their load; migrations queue while one is saturated
+	aiservice.StartCapacityPolling(api.DispatchAIServiceQueue)
+
+	// Initialize Power BI client (optional, used for exposure discovery)
+	powerbi.Init(cfg.PowerBITenantID, cfg.PowerBIClientID, cfg.PowerBIClientSecret)
+
+	// Initialize dbt Cloud client (optional, used to provision jobs for completed migrations)
+	dbtcloud.Init(cfg.DbtCloudAPIURL, int64(cfg.DbtCloudAccountID), cfg.DbtCloudAPIToken)
+
+	// Initialize social login providers (optional)
+	oauth.Init(oauthSettings(cfg))
+
+	// Stream security audit events to an external SIEM if configured
+	if err := security.InitSIEMExporter(security.SIEMConfig{
+		Provider:      cfg.SIEMProvider,
+		Endpoint:      cfg.SIEMEndpoint,
+		Token:         cfg.SIEMToken,
+		BatchSize:     cfg.SIEMBatchSize,
+		FlushInterval: time.Duration(cfg.SIEMFlushSeconds) * time.Second,
+		MaxRetries:    cfg.SIEMMaxRetries,
+		FieldMapping:  security.ParseSIEMFieldMapping(cfg.SIEMFieldMapping),
+	}); err != nil {
+		log.Printf("Warning: SIEM export disabled: %v", err)
+	}
+
+	// Start background anomaly detection over the security audit log
+	security.GetAnomalyDetector().Start()
+
+	// Keep monthly audit log partitions ahead of the clock
+	db.StartAuditLogPartitionMaintenance()
+
+	// Deliver queued emails with retries
+	email.GetOutbox().Start()
+
+	// Start migrations queued behind a connection's concurrency limit or their run window
+	api.StartMigrationQueue()
+
+	// Compare deployed warehouse tables with the generated models
+	api.StartDriftDetection()
+
+	// Sample the tables of completed deployments against the source, for migrations that ask
+	api.StartReconciliation()
+
+	// Cache metadata scans in Redis if configured (otherwise Postgres)
+	if err := metacache.Configure(cfg.RedisURL, time.Duration(cfg.MetadataCacheTTLMinutes)*time.Minute); err != nil {
+		log.Printf("Warning: %v; metadata cache will use PostgreSQL", err)
+	}
 
 	// Setup router
 	router := api.SetupRouter(cfg)
 
+	// Pick up rotated secret files on SIGHUP
+	reloadSecretsOnSIGHUP(cfg)
+
+	// Serve internal routes to the AI service over mutual TLS if configured
+	if cfg.InternalTLSEnabled() {
+		startInternalServer(cfg)
+	}
+
 	// Start server
-	addr := cfg.ServerHost + ":" + cfg.ServerPort
-	log.Printf("Starting DataMigrate API server on %s", addr)
-	if err := router.Run(addr); err != nil {
+	if err := servePublic(cfg, router); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// passwordHashing returns the parameters new password hashes are made with
+func passwordHashing(cfg *config.Config) security.PasswordHashing {
+	return security.PasswordHashing{
+		Algorithm:         cfg.PasswordHashAlgorithm,
+		BcryptCost:        cfg.BcryptCost,
+		Argon2Memory:      uint32(cfg.Argon2MemoryKiB),
+		Argon2Iterations:  uint32(cfg.Argon2Iterations),
+		Argon2Parallelism: uint8(cfg.Argon2Parallelism),
+	}
+}
+
+// oauthSettings returns the social login provider configuration
+func oauthSettings(cfg *config.Config) oauth.Settings {
+	return oauth.Settings{
+		RedirectBaseURL:    cfg.OAuthRedirectBaseURL,
+		GoogleClientID:     cfg.OAuthGoogleClientID,
+		GoogleClientSecret: cfg.OAuthGoogleClientSecret,
+		GitHubClientID:     cfg.OAuthGitHubClientID,
+		GitHubClientSecret: cfg.OAuthGitHubClientSecret,
+		AzureTenantID:      cfg.OAuthAzureTenantID,
+		AzureClientID:      cfg.OAuthAzureClientID,
+		AzureClientSecret:  cfg.OAuthAzureClientSecret,
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// servePublic serves the API on SERVER_PORT: plain HTTP, or HTTPS with HTTP/2 when a
+// certificate or Let's Encrypt domains are configured
+func servePublic(cfg *config.Config, handler http.Handler) error {
+	addr := cfg.ServerHost + ":" + cfg.ServerPort
+	if !cfg.TLSEnabled() {
+		log.Printf("Starting DataMigrate API server on %s", addr)
+		return http.ListenAndServe(addr, handler)
+	}
+
+	server := &http.Server{Addr: addr, Handler: handler}
+	var challenges func(http.Handler) http.Handler
+	if len(cfg.TLSAutocertDomains) > 0 {
+		// Verified custom domains get certificates too
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: domains.HostPolicy(cfg.TLSAutocertDomains),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		// Offers h2 and answers TLS-ALPN-01 challenges on this port
+		server.TLSConfig = manager.TLSConfig()
+		challenges = manager.HTTPHandler
+	} else {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return err
+		}
+		// net/http adds h2 to the protocols of TLS servers
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	if cfg.HTTPRedirectPort != "" {
+		startRedirectServer(cfg, challenges)
+	}
+
+	log.Printf("Starting DataMigrate API server on %s (HTTPS, HTTP/2)", addr)
+	// The certificate is already in TLSConfig
+	return server.ListenAndServeTLS("", "")
+}
+
+// startRedirectServer sends plain HTTP requests on HTTP_REDIRECT_PORT to HTTPS. With
+// Let's Encrypt it also answers HTTP-01 challenges.
+func startRedirectServer(cfg *config.Config, challenges func(http.Handler) http.Handler) {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, httpsURL(r, cfg.ServerPort), status)
+	})
+	if challenges != nil {
+		handler = challenges(handler)
+	}
+
+	addr := cfg.ServerHost + ":" + cfg.HTTPRedirectPort
+	go func() {
+		log.Printf("Redirecting HTTP on %s to HTTPS", addr)
+		if err := http.ListenAndServe(addr, handler); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("HTTP redirect server failed: %v", err)
+		}
+	}()
+}
+
+// httpsURL is the HTTPS address of a plain HTTP request, on the HTTPS port
+func httpsURL(r *http.Request, httpsPort string) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if httpsPort != "443" {
+		host = net.JoinHostPort(host, httpsPort)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return "https://" + host + r.URL.RequestURI()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/crypto"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtcloud"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/oauth"
+	"github.com/datamigrate-ai/backend/internal/powerbi"
+)
+
+// reloadSecretsOnSIGHUP re-reads secrets (including *_FILE paths) whenever the process
+// receives SIGHUP, so rotated Docker/Kubernetes secrets apply without a restart
+func reloadSecretsOnSIGHUP(cfg *config.Config) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		// current is only touched by this goroutine; cfg itself stays as loaded at startup
+		current := *cfg
+		for range hup {
+			log.Printf("SIGHUP received, reloading secrets")
+			if next, ok := reloadSecrets(current); ok {
+				current = next
+			}
+		}
+	}()
+}
+
+// reloadSecrets applies any changed secrets and returns the resulting configuration.
+// Secrets that fail validation keep their previous value.
+func reloadSecrets(current config.Config) (config.Config, bool) {
+	secrets, err := config.LoadSecrets()
+	if err != nil {
+		log.Printf("Warning: secret reload failed: %v", err)
+		return current, false
+	}
+
+	next := current
+	next.ApplySecrets(secrets)
+	if err := next.Preflight(nil).Err(); err != nil && next.IsProduction() {
+		log.Printf("Warning: secret reload rejected: %v", err)
+		return current, false
+	}
+
+	if next.DBPassword != current.DBPassword {
+		if err := db.UpdateCredentials(&next); err != nil {
+			log.Printf("Warning: keeping previous DB_PASSWORD: %v", err)
+			next.DBPassword = current.DBPassword
+		} else {
+			log.Printf("DB_PASSWORD rotated; new connections use the new credentials")
+		}
+	}
+
+	if next.JWTSecret != current.JWTSecret {
+		middleware.RotateJWTSecret(next.JWTSecret)
+		log.Printf("JWT_SECRET rotated; tokens signed with the previous secret stay valid until they expire")
+	}
+
+	if next.EncryptionKey != current.EncryptionKey {
+		if err := rotateEncryptionKey(next.EncryptionKey); err != nil {
+			log.Printf("Warning: keeping previous ENCRYPTION_KEY: %v", err)
+			next.EncryptionKey = current.EncryptionKey
+		} else {
+			log.Printf("ENCRYPTION_KEY rotated; values encrypted with the previous key remain readable")
+		}
+	}
+
+	if next.CaptchaSecret != current.CaptchaSecret || next.SIEMToken != current.SIEMToken {
+		log.Printf("Note: CAPTCHA_SECRET and SIEM_TOKEN changes take effect after a restart")
+		next.CaptchaSecret, next.SIEMToken = current.CaptchaSecret, current.SIEMToken
+	}
+
+	if next.PowerBIClientSecret != current.PowerBIClientSecret {
+		powerbi.Init(current.PowerBITenantID, current.PowerBIClientID, next.PowerBIClientSecret)
+		log.Printf("POWERBI_CLIENT_SECRET rotated")
+	}
+
+	if next.DbtCloudAPIToken != current.DbtCloudAPIToken {
+		dbtcloud.Init(current.DbtCloudAPIURL, int64(current.DbtCloudAccountID), next.DbtCloudAPIToken)
+		log.Printf("DBT_CLOUD_API_TOKEN rotated")
+	}
+
+	if next.OAuthGoogleClientSecret != current.OAuthGoogleClientSecret ||
+		next.OAuthGitHubClientSecret != current.OAuthGitHubClientSecret ||
+		next.OAuthAzureClientSecret != current.OAuthAzureClientSecret {
+		oauth.Init(oauthSettings(&next))
+		log.Printf("OAuth client secrets rotated")
+	}
+
+	return next, true
+}
+
+var errKeyRemoved = errors.New("ENCRYPTION_KEY can't be removed while the server is running")
+
+func rotateEncryptionKey(keyStr string) error {
+	if keyStr == "" {
+		// Dropping the key would leave stored connection passwords unreadable
+		return errKeyRemoved
+	}
+	key, err := crypto.ParseKeyString(keyStr)
+	if err != nil {
+		return err
+	}
+	return crypto.GetEncryptionService().RotateKey(key)
+}
@@ -0,0 +1,131 @@
+package aiservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CapacityPollInterval is how often each instance's /capacity endpoint is polled
+const CapacityPollInterval = 15 * time.Second
+
+// capacityStaleAfter is how old a capacity report may be before it's ignored, so an
+// instance that stopped answering isn't treated as having capacity it last reported
+const capacityStaleAfter = 4 * CapacityPollInterval
+
+// Capacity is an instance's load, as reported by its /capacity endpoint
+type Capacity struct {
+	Region     string    `json:"region,omitempty"` // Data region of the instance; empty for the default
+	Slots      int       `json:"slots"`            // Migrations the instance runs at once
+	Active     int       `json:"active"`           // Migrations it's running
+	QueueDepth int       `json:"queue_depth"`      // Work waiting inside the instance
+	Saturated  bool      `json:"saturated"`        // No room for another migration
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// capacityState holds the last capacity report of a client
+type capacityState struct {
+	mu       sync.RWMutex
+	capacity *Capacity
+	failing  bool // The last poll failed; failures are logged once per streak
+}
+
+// saturated reports whether a capacity report leaves no room for another migration.
+// Instances that don't report the flag are saturated once their slots are taken or
+// work waits inside them.
+func (c *Capacity) saturated() bool {
+	return c.Saturated || c.QueueDepth > 0 || (c.Slots > 0 && c.Active >= c.Slots)
+}
+
+// FetchCapacity asks the instance for its current load
+func (c *Client) FetchCapacity() (*Capacity, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/capacity")
+	if err != nil {
+		return nil, fmt.Errorf("AI service unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AI service capacity unavailable (status %d)", resp.StatusCode)
+	}
+
+	var capacity Capacity
+	if err := json.NewDecoder(resp.Body).Decode(&capacity); err != nil {
+		return nil, fmt.Errorf("failed to decode capacity: %w", err)
+	}
+	capacity.Region = c.region
+	capacity.Saturated = capacity.saturated()
+	capacity.CheckedAt = time.Now()
+	return &capacity, nil
+}
+
+// Capacity returns the instance's last polled capacity, or nil when it hasn't been
+// polled recently
+func (c *Client) Capacity() *Capacity {
+	c.capacity.mu.RLock()
+	defer c.capacity.mu.RUnlock()
+	if c.capacity.capacity == nil || time.Since(c.capacity.capacity.CheckedAt) > capacityStaleAfter {
+		return nil
+	}
+	capacity := *c.capacity.capacity
+	return &capacity
+}
+
+func (c *Client) setCapacity(capacity *Capacity) {
+	c.capacity.mu.Lock()
+	defer c.capacity.mu.Unlock()
+	c.capacity.capacity = capacity
+}
+
+// Clients returns the default client, when configured, and the regional ones
+func Clients() []*Client {
+	clients := make([]*Client, 0, len(regional)+1)
+	if client != nil {
+		clients = append(clients, client)
+	}
+	for _, region := range Regions {
+		if c, ok := regional[region]; ok {
+			clients = append(clients, c)
+		}
+	}
+	return clients
+}
+
+// pollCapacity refreshes a client's capacity and reports whether the instance went from
+// saturated (or unknown) to having room
+func (c *Client) pollCapacity() bool {
+	before := c.Capacity()
+	capacity, err := c.FetchCapacity()
+	c.capacity.mu.Lock()
+	wasFailing := c.capacity.failing
+	c.capacity.failing = err != nil
+	c.capacity.mu.Unlock()
+	if err != nil {
+		// Keep the last report until it goes stale; a missed poll isn't saturation
+		if !wasFailing {
+			log.Printf("AI service capacity poll failed for %s: %v", c.baseURL, err)
+		}
+		return false
+	}
+	c.setCapacity(capacity)
+	return !capacity.Saturated && (before == nil || before.Saturated)
+}
+
+// StartCapacityPolling polls every instance's capacity in the background. onAvailable
+// runs with the instance's region when it has room again after being saturated.
+func StartCapacityPolling(onAvailable func(region string)) {
+	go func() {
+		ticker := time.NewTicker(CapacityPollInterval)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			for _, c := range Clients() {
+				if c.pollCapacity() && onAvailable != nil {
+					onAvailable(c.region)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,72 @@
+package aiservice
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchCapacity(t *testing.T) {
+	cases := []struct {
+		body      string
+		saturated bool
+	}{
+		{`{"slots": 4, "active": 2, "queue_depth": 0}`, false},
+		{`{"slots": 4, "active": 4, "queue_depth": 0}`, true},
+		{`{"slots": 4, "active": 1, "queue_depth": 3}`, true},
+		{`{"slots": 4, "active": 1, "queue_depth": 0, "saturated": true}`, true},
+	}
+	for _, tc := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/capacity" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, tc.body)
+		}))
+		c := &Client{baseURL: server.URL, httpClient: server.Client(), region: RegionEU}
+
+		capacity, err := c.FetchCapacity()
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.body, err)
+		}
+		if capacity.Saturated != tc.saturated || capacity.Region != RegionEU || capacity.Slots != 4 {
+			t.Errorf("%s: capacity = %+v", tc.body, capacity)
+		}
+	}
+}
+
+func TestPollCapacityReportsRoomAgain(t *testing.T) {
+	active := 4
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"slots": 4, "active": %d, "queue_depth": 0}`, active)
+	}))
+	defer server.Close()
+	c := &Client{baseURL: server.URL, httpClient: server.Client()}
+
+	if c.pollCapacity() {
+		t.Error("saturated instance reported as having room")
+	}
+	if capacity := c.Capacity(); capacity == nil || !capacity.Saturated {
+		t.Fatalf("capacity = %+v", capacity)
+	}
+
+	active = 3
+	if !c.pollCapacity() {
+		t.Error("instance with a free slot after saturation wasn't reported")
+	}
+	if c.pollCapacity() {
+		t.Error("instance that already had room was reported again")
+	}
+}
+
+func TestCapacityGoesStale(t *testing.T) {
+	c := &Client{}
+	c.setCapacity(&Capacity{Slots: 4, CheckedAt: time.Now().Add(-capacityStaleAfter - time.Second)})
+	if capacity := c.Capacity(); capacity != nil {
+		t.Errorf("stale capacity = %+v", capacity)
+	}
+}
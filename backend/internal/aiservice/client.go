@@ -4,14 +4,26 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
 )
 
 // Client handles communication with the AI service
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	// The data region of a regional instance, and the bucket its artifacts are stored in
+	region         string
+	artifactBucket string
+	// Load last polled from the instance's /capacity endpoint
+	capacity capacityState
 }
 
 // MigrationRequest represents the request to start a migration
@@ -21,12 +33,74 @@ type MigrationRequest struct {
 	TargetProject    string                 `json:"target_project"`
 	Tables           []string               `json:"tables,omitempty"`
 	IncludeViews     bool                   `json:"include_views"`
+	// PIIPolicy tells the profiler which columns and value patterns to mask in sample data
+	PIIPolicy *security.PIIPolicy `json:"pii_policy,omitempty"`
+	// Dependencies orders model generation and drives ref() between models
+	Dependencies *dbtest.DependencyGraph `json:"dependencies,omitempty"`
+	// Snapshots are tables to generate as dbt snapshots (SCD type 2)
+	Snapshots []models.SnapshotConfig `json:"snapshots,omitempty"`
+	// TableFilters are WHERE clauses and excluded columns the staging models of the
+	// tables apply when reading from the source
+	TableFilters []models.TableFilter `json:"table_filters,omitempty"`
+	// TestCoverage is the migration's test coverage with every default filled in; a table
+	// without an override uses the top-level settings
+	TestCoverage models.TestCoverageConfig `json:"test_coverage"`
+	// Scaffolding is the generated project's layers, folders and model naming, with
+	// every default filled in
+	Scaffolding models.ProjectScaffolding `json:"scaffolding"`
+	// ColumnMasks are the organization's masking rules for the migrated tables; staging
+	// models NULL, hash or drop the columns. A rule without a table applies to every table.
+	ColumnMasks []models.ColumnMask `json:"column_masks,omitempty"`
+	// Seeds are tables already uploaded as seed CSVs; models ref() them instead of the source
+	Seeds []SeedRef `json:"seeds,omitempty"`
+	// ExposuresYAML is written to models/exposures.yml when the migration has exposures
+	ExposuresYAML string `json:"exposures_yaml,omitempty"`
+	// Target selects the dbt adapter and SQL dialect the models are generated for
+	Target *TargetAdapter `json:"target,omitempty"`
+	// Naming holds the organization's model name prefixes (stg_, int_, fct_, dim_ by default)
+	Naming *models.NamingConventions `json:"naming,omitempty"`
+	// Secrets are env_var() values the generated project needs, by variable name
+	Secrets map[string]string `json:"secrets,omitempty"`
+	// SkipTables were generated by the failed run being resumed; their models are kept as
+	// they are and generation continues with the remaining tables
+	SkipTables []string `json:"skip_tables,omitempty"`
+	// Throttle limits parallel queries, fetch size and the hours extraction may read
+	// from the source connection
+	Throttle *models.ExtractionThrottle `json:"throttle,omitempty"`
+	// DataRegion and ArtifactBucket pin the generated project to the organization's region
+	DataRegion     string `json:"data_region,omitempty"`
+	ArtifactBucket string `json:"artifact_bucket,omitempty"`
+}
+
+// TargetAdapter describes the warehouse a project is generated for
+type TargetAdapter struct {
+	Type      string `json:"type"`    // Connection db_type, e.g. fabric
+	Adapter   string `json:"adapter"` // dbt adapter package, e.g. dbt-fabric
+	Database  string `json:"database"`
+	Workspace string `json:"workspace,omitempty"` // Fabric workspace
+	Lakehouse string `json:"lakehouse,omitempty"` // Fabric lakehouse staging models read from
+	// TSQL asks for T-SQL-compatible SQL: no QUALIFY, ILIKE or boolean columns, TOP
+	// instead of LIMIT, and nested CTEs hoisted out of views
+	TSQL bool `json:"tsql"`
+}
+
+// SeedRef maps a source table to the dbt seed replacing it
+type SeedRef struct {
+	Table string `json:"table"` // schema.table
+	Name  string `json:"name"`
 }
 
 // MigrationResponse represents the response from starting a migration
 type MigrationResponse struct {
-	Message     string `json:"message"`
-	MigrationID int64  `json:"migration_id"`
+	Message     string      `json:"message"`
+	MigrationID int64       `json:"migration_id"`
+	Usage       *TokenUsage `json:"usage,omitempty"`
+}
+
+// TokenUsage reports the LLM tokens consumed by a request, when the AI service provides it
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
 }
 
 // MigrationStatus represents the status of a migration
@@ -41,15 +115,30 @@ type MigrationStatus struct {
 	TotalModels     int     `json:"total_models"`
 }
 
+// StopMigrationResponse is the AI service's acknowledgment of a stop, with what the run
+// generated before it stopped
+type StopMigrationResponse struct {
+	MigrationID int64 `json:"migration_id"`
+	// Status is cancelled once the run has stopped, or cancelling if it was still winding
+	// down when the AI service stopped waiting for it
+	Status          string                  `json:"status"`
+	Progress        int                     `json:"progress"`
+	ModelsGenerated int                     `json:"models_generated"`
+	Tables          []models.MigrationTable `json:"tables,omitempty"`
+}
+
+// Stopped reports whether the run had stopped when the AI service acknowledged
+func (r *StopMigrationResponse) Stopped() bool {
+	return r.Status == "cancelled"
+}
+
 var client *Client
 
 // Init initializes the AI service client
 func Init(baseURL string) {
 	client = &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    baseURL,
+		httpClient: NewHTTPClient(30 * time.Second),
 	}
 }
 
@@ -58,12 +147,15 @@ func GetClient() *Client {
 	return client
 }
 
-// StartMigration triggers a new migration in the AI service
+// StartMigration triggers a new migration in the AI service. Only the source connection
+// fields the AI service needs are sent.
 func (c *Client) StartMigration(req MigrationRequest) (*MigrationResponse, error) {
-	body, err := json.Marshal(req)
+	body, digest, err := encodeMigrationRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
+	log.Printf("AI service: starting migration %d, payload sha256=%s (%d bytes, source fields %v)",
+		req.MigrationID, digest.SHA256, digest.Bytes, digest.SourceFields)
 
 	resp, err := c.httpClient.Post(
 		c.baseURL+"/migrations/start",
@@ -117,20 +209,59 @@ func (c *Client) GetMigrationStatus(migrationID int64) (*MigrationStatus, error)
 	return &result, nil
 }
 
-// StopMigration stops a running migration
-func (c *Client) StopMigration(migrationID int64) error {
+// StopMigration asks the AI service to stop a running migration. The AI service answers
+// once the run has stopped, or once it gives up waiting for it to.
+func (c *Client) StopMigration(migrationID int64) (*StopMigrationResponse, error) {
 	resp, err := c.httpClient.Post(
 		fmt.Sprintf("%s/migrations/%d/stop", c.baseURL, migrationID),
 		"application/json",
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to call AI service: %w", err)
+		return nil, fmt.Errorf("failed to call AI service: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("AI service error (status %d)", resp.StatusCode)
+		var errResp struct {
+			Detail string `json:"detail"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, fmt.Errorf("AI service error: %s (status %d)", errResp.Detail, resp.StatusCode)
+	}
+
+	var result StopMigrationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UploadSeed streams a seed CSV into the migration's dbt project as seeds/<name>.csv
+func (c *Client) UploadSeed(migrationID int64, name string, csv io.Reader) error {
+	req, err := http.NewRequest(
+		http.MethodPut,
+		fmt.Sprintf("%s/migrations/%d/seeds/%s", c.baseURL, migrationID, url.PathEscape(name)),
+		csv,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/csv")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call AI service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		var errResp struct {
+			Detail string `json:"detail"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return fmt.Errorf("AI service error: %s (status %d)", errResp.Detail, resp.StatusCode)
 	}
 
 	return nil
@@ -192,7 +323,7 @@ func (c *Client) GetMigrationFiles(migrationID int64) (*DBTFilesResponse, error)
 			Detail string `json:"detail"`
 		}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return nil, fmt.Errorf(errResp.Detail)
+		return nil, fmt.Errorf("%s", errResp.Detail)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -233,7 +364,159 @@ func (c *Client) GetMigrationFileContent(migrationID int64, filePath string) (*D
 	return &result, nil
 }
 
-// GetMigrationDownloadURL returns the URL to download the dbt project
-func (c *Client) GetMigrationDownloadURL(migrationID int64) string {
-	return fmt.Sprintf("%s/migrations/%d/download", c.baseURL, migrationID)
+// DownloadMigrationProject returns the dbt project as a ZIP archive; the caller closes it
+func (c *Client) DownloadMigrationProject(migrationID int64) (io.ReadCloser, error) {
+	resp, err := c.httpClient.Get(fmt.Sprintf("%s/migrations/%d/download", c.baseURL, migrationID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call AI service: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("AI service error (status %d)", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// EmbeddingRequest represents a request to embed text
+type EmbeddingRequest struct {
+	Text string `json:"text"`
+}
+
+// EmbeddingResponse represents an embedding vector returned by the AI service
+type EmbeddingResponse struct {
+	Embedding []float64 `json:"embedding"`
+	Model     string    `json:"model,omitempty"`
+}
+
+// Embed generates an embedding vector for the given text
+func (c *Client) Embed(text string) (*EmbeddingResponse, error) {
+	body, err := json.Marshal(EmbeddingRequest{Text: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(
+		c.baseURL+"/embeddings",
+		"application/json",
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call AI service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AI service error (status %d)", resp.StatusCode)
+	}
+
+	var result EmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("AI service returned an empty embedding")
+	}
+
+	return &result, nil
+}
+
+// TranslateRequest asks for an ad-hoc T-SQL snippet to be translated for a warehouse
+type TranslateRequest struct {
+	SQL    string        `json:"sql"`
+	Target TargetAdapter `json:"target"`
+	Format string        `json:"format"` // dbt for a model with source() and ref(), sql for plain SQL
+}
+
+// TranslateResponse is the translated snippet
+type TranslateResponse struct {
+	SQL      string      `json:"sql"`
+	Warnings []string    `json:"warnings,omitempty"` // Constructs that couldn't be translated exactly
+	Usage    *TokenUsage `json:"usage,omitempty"`
+}
+
+// Translate converts a T-SQL snippet to the target's SQL dialect or a dbt model
+func (c *Client) Translate(req TranslateRequest) (*TranslateResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(
+		c.baseURL+"/translate",
+		"application/json",
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call AI service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Detail string `json:"detail"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, fmt.Errorf("AI service error: %s (status %d)", errResp.Detail, resp.StatusCode)
+	}
+
+	var result TranslateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
+
+// DataQualityScanRequest asks for a source database to be profiled for data quality issues
+type DataQualityScanRequest struct {
+	Host           string   `json:"host"`
+	Port           int      `json:"port"`
+	Database       string   `json:"database"`
+	Username       string   `json:"username"`
+	Password       string   `json:"password"`
+	UseWindowsAuth bool     `json:"use_windows_auth"`
+	Tables         []string `json:"tables,omitempty"`
+	SampleSize     int      `json:"sample_size,omitempty"`
+	// PIIPolicy tells the profiler which columns and value patterns to mask
+	PIIPolicy *security.PIIPolicy `json:"pii_policy,omitempty"`
+}
+
+// ScanDataQuality profiles a source database. The report is returned as the AI service
+// sends it.
+func (c *Client) ScanDataQuality(req DataQualityScanRequest) (map[string]interface{}, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(
+		c.baseURL+"/data-quality/scan",
+		"application/json",
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call AI service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Detail string `json:"detail"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, fmt.Errorf("AI service error: %s (status %d)", errResp.Detail, resp.StatusCode)
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result, nil
 }
@@ -0,0 +1,44 @@
+package aiservice
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/metrics"
+)
+
+// instrumentedTransport records every call to the AI service for the AI error ratio SLO.
+// Calls that fail to connect or get a 5xx count as failures; 4xx answers are the
+// caller's mistake and count as successes.
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+	success := err == nil && resp.StatusCode < http.StatusInternalServerError
+	metrics.RecordAIRequest(operationName(req.URL.Path), success, time.Since(start))
+	return resp, err
+}
+
+// operationName is the metrics label of an AI service path: IDs become :id and only the
+// first three segments are kept, e.g. /migrations/7/files/models/a.sql -> migrations/:id/files
+func operationName(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 3 {
+		segments = segments[:3]
+	}
+	for i, s := range segments {
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,18 @@
+package aiservice
+
+import "testing"
+
+func TestOperationName(t *testing.T) {
+	cases := map[string]string{
+		"/health":                          "health",
+		"/migrations/start":                "migrations/start",
+		"/migrations/7/status":             "migrations/:id/status",
+		"/migrations/7/files/models/a.sql": "migrations/:id/files",
+		"/migrations/7/seeds/countries":    "migrations/:id/seeds",
+	}
+	for path, want := range cases {
+		if got := operationName(path); got != want {
+			t.Errorf("operationName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package aiservice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// sourceConnectionFields are the source connection fields the AI service reads to
+// connect to the source database. Anything else is dropped before a request is sent.
+var sourceConnectionFields = map[string]bool{
+	"type":             true,
+	"host":             true,
+	"port":             true,
+	"database":         true,
+	"username":         true,
+	"password":         true,
+	"use_windows_auth": true,
+	"read_replica":     true,
+	"read_isolation":   true,
+}
+
+// PayloadDigest identifies a request body exactly as it was sent, so audit records can
+// show what left the platform without storing the credentials in it
+type PayloadDigest struct {
+	SHA256       string   `json:"sha256"`
+	Bytes        int      `json:"bytes"`
+	SourceFields []string `json:"source_fields"` // Source connection fields sent, sorted
+	Secrets      []string `json:"secrets,omitempty"`
+}
+
+// RedactSourceConnection returns the allowlisted fields of a source connection. SQL
+// credentials are left out for Windows authentication, which doesn't use them.
+func RedactSourceConnection(conn map[string]interface{}) map[string]interface{} {
+	windowsAuth, _ := conn["use_windows_auth"].(bool)
+
+	redacted := make(map[string]interface{}, len(sourceConnectionFields))
+	for field, value := range conn {
+		if !sourceConnectionFields[field] {
+			continue
+		}
+		if windowsAuth && (field == "username" || field == "password") {
+			continue
+		}
+		if s, ok := value.(string); ok && s == "" && field == "password" {
+			continue
+		}
+		redacted[field] = value
+	}
+	return redacted
+}
+
+// encodeMigrationRequest redacts a request and encodes the body sent to the AI service
+func encodeMigrationRequest(req MigrationRequest) ([]byte, PayloadDigest, error) {
+	req.SourceConnection = RedactSourceConnection(req.SourceConnection)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, PayloadDigest{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	sum := sha256.Sum256(body)
+	digest := PayloadDigest{
+		SHA256:       hex.EncodeToString(sum[:]),
+		Bytes:        len(body),
+		SourceFields: make([]string, 0, len(req.SourceConnection)),
+	}
+	for field := range req.SourceConnection {
+		digest.SourceFields = append(digest.SourceFields, field)
+	}
+	sort.Strings(digest.SourceFields)
+	for name := range req.Secrets {
+		digest.Secrets = append(digest.Secrets, name)
+	}
+	sort.Strings(digest.Secrets)
+
+	return body, digest, nil
+}
+
+// Digest returns the digest of the body StartMigration sends for req
+func (req MigrationRequest) Digest() (PayloadDigest, error) {
+	_, digest, err := encodeMigrationRequest(req)
+	return digest, err
+}
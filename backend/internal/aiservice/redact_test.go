@@ -0,0 +1,76 @@
+package aiservice
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactSourceConnection(t *testing.T) {
+	conn := map[string]interface{}{
+		"type":           "mssql",
+		"host":           "203.0.113.10",
+		"port":           1433,
+		"database":       "AdventureWorks",
+		"username":       "migrator",
+		"password":       "S3cure-Passw0rd",
+		"extra_config":   `{"token":"x"}`,
+		"read_replica":   true,
+		"read_isolation": "snapshot",
+	}
+
+	redacted := RedactSourceConnection(conn)
+	if _, ok := redacted["extra_config"]; ok {
+		t.Errorf("extra_config was not dropped: %v", redacted)
+	}
+	if redacted["read_replica"] != true || redacted["read_isolation"] != "snapshot" {
+		t.Errorf("read settings were dropped: %v", redacted)
+	}
+	if redacted["password"] != "S3cure-Passw0rd" {
+		t.Errorf("password for SQL authentication was dropped: %v", redacted)
+	}
+
+	conn["use_windows_auth"] = true
+	redacted = RedactSourceConnection(conn)
+	if _, ok := redacted["password"]; ok {
+		t.Errorf("password sent for Windows authentication: %v", redacted)
+	}
+	if _, ok := redacted["username"]; ok {
+		t.Errorf("username sent for Windows authentication: %v", redacted)
+	}
+}
+
+func TestMigrationRequestDigest(t *testing.T) {
+	req := MigrationRequest{
+		MigrationID: 7,
+		SourceConnection: map[string]interface{}{
+			"type":     "mssql",
+			"host":     "203.0.113.10",
+			"password": "S3cure-Passw0rd",
+			"internal": "dropped",
+		},
+		Secrets: map[string]string{"SALESFORCE_TOKEN": "s3cret"},
+	}
+
+	body, digest, err := encodeMigrationRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "dropped") {
+		t.Errorf("body contains a field outside the allowlist: %s", body)
+	}
+	if want := []string{"host", "password", "type"}; !reflect.DeepEqual(digest.SourceFields, want) {
+		t.Errorf("source fields = %v, want %v", digest.SourceFields, want)
+	}
+	if want := []string{"SALESFORCE_TOKEN"}; !reflect.DeepEqual(digest.Secrets, want) {
+		t.Errorf("secrets = %v, want %v", digest.Secrets, want)
+	}
+
+	again, err := req.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.SHA256 != digest.SHA256 || len(digest.SHA256) != 64 {
+		t.Errorf("digest = %q, then %q", digest.SHA256, again.SHA256)
+	}
+}
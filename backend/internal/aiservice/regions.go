@@ -0,0 +1,110 @@
+package aiservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Data regions an organization can be pinned to
+const (
+	RegionEU = "eu"
+	RegionUS = "us"
+)
+
+// Regions lists the supported data regions
+var Regions = []string{RegionEU, RegionUS}
+
+// ErrRegionUnavailable is returned for a data region without an AI service instance.
+// Pinned migrations never fall back to another region's instance.
+var ErrRegionUnavailable = errors.New("AI service is not available in the organization's data region")
+
+// RegionConfig is the AI service instance and artifact bucket of a data region
+type RegionConfig struct {
+	URL            string
+	ArtifactBucket string
+}
+
+var regional = map[string]*Client{}
+
+// InitRegions initializes a client for each data region with an AI service URL. Call
+// after ConfigureTLS, like Init.
+func InitRegions(regions map[string]RegionConfig) {
+	regional = map[string]*Client{}
+	for region, cfg := range regions {
+		if cfg.URL == "" || !IsValidRegion(region) {
+			continue
+		}
+		regional[region] = &Client{
+			baseURL:        cfg.URL,
+			httpClient:     NewHTTPClient(30 * time.Second),
+			region:         region,
+			artifactBucket: cfg.ArtifactBucket,
+		}
+	}
+}
+
+// IsValidRegion reports whether region is a supported data region
+func IsValidRegion(region string) bool {
+	for _, r := range Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// RegionAvailable reports whether a data region has an AI service instance
+func RegionAvailable(region string) bool {
+	_, ok := regional[region]
+	return ok
+}
+
+// ForRegion returns the client of a data region. Organizations without a region use
+// the default client, which may be nil when the AI service isn't configured.
+func ForRegion(region string) (*Client, error) {
+	if region == "" {
+		return client, nil
+	}
+	if c, ok := regional[region]; ok {
+		return c, nil
+	}
+	return nil, ErrRegionUnavailable
+}
+
+// ArtifactBucket returns the storage bucket of a data region, or "" when none is set
+func ArtifactBucket(region string) string {
+	if c, ok := regional[region]; ok {
+		return c.artifactBucket
+	}
+	return ""
+}
+
+// Region returns the data region the client serves, or "" for the default instance
+func (c *Client) Region() string {
+	return c.region
+}
+
+// BaseURL returns the URL of the client's instance
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// ArtifactBucket returns the bucket the client's instance stores projects in
+func (c *Client) ArtifactBucket() string {
+	return c.artifactBucket
+}
+
+// InBucket reports whether an artifacts path such as s3://bucket/projects/42 is stored in
+// bucket
+func InBucket(path, bucket string) bool {
+	if bucket == "" {
+		return false
+	}
+	_, rest, ok := strings.Cut(path, "://")
+	if !ok {
+		return false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return name == bucket
+}
@@ -0,0 +1,56 @@
+package aiservice
+
+import (
+	"errors"
+	"testing"
+)
+
+// useRegions configures regional instances for one test
+func useRegions(t *testing.T, regions map[string]RegionConfig) {
+	t.Helper()
+	saved := regional
+	t.Cleanup(func() { regional = saved })
+	InitRegions(regions)
+}
+
+func TestForRegion(t *testing.T) {
+	useRegions(t, map[string]RegionConfig{
+		RegionEU: {URL: "https://ai-eu.internal", ArtifactBucket: "artifacts-eu"},
+		RegionUS: {}, // No instance
+		"apac":   {URL: "https://ai-apac.internal"},
+	})
+
+	eu, err := ForRegion(RegionEU)
+	if err != nil || eu.BaseURL() != "https://ai-eu.internal" || eu.Region() != RegionEU || eu.ArtifactBucket() != "artifacts-eu" {
+		t.Fatalf("ForRegion(eu) = %+v, %v", eu, err)
+	}
+	// A pinned region never falls back to the default instance
+	if _, err := ForRegion(RegionUS); !errors.Is(err, ErrRegionUnavailable) {
+		t.Errorf("ForRegion(us) without an instance = %v", err)
+	}
+	if _, err := ForRegion("apac"); !errors.Is(err, ErrRegionUnavailable) {
+		t.Errorf("ForRegion of an unsupported region = %v", err)
+	}
+	if c, err := ForRegion(""); err != nil || c != client {
+		t.Errorf("ForRegion without a region = %v, %v; want the default client", c, err)
+	}
+}
+
+func TestInBucket(t *testing.T) {
+	cases := []struct {
+		path, bucket string
+		want         bool
+	}{
+		{"s3://artifacts-eu/projects/42", "artifacts-eu", true},
+		{"gs://artifacts-eu", "artifacts-eu", true},
+		{"s3://artifacts-eu-backup/projects/42", "artifacts-eu", false},
+		{"s3://artifacts-us/projects/42", "artifacts-eu", false},
+		{"/data/projects/42", "artifacts-eu", false},
+		{"s3://artifacts-eu/projects/42", "", false},
+	}
+	for _, tc := range cases {
+		if got := InBucket(tc.path, tc.bucket); got != tc.want {
+			t.Errorf("InBucket(%q, %q) = %v, want %v", tc.path, tc.bucket, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package aiservice
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/security"
+)
+
+// TLSOptions configure mutual TLS to the AI service. All files are PEM.
+type TLSOptions struct {
+	CAFile     string // CA the AI service's certificate must chain to; system roots aren't trusted
+	CertFile   string // Client certificate presented to the AI service
+	KeyFile    string
+	ServerName string // Name expected in the AI service's certificate, if not the URL host
+}
+
+// Enabled reports whether any TLS option is set
+func (o TLSOptions) Enabled() bool {
+	return o.CAFile != "" || o.CertFile != "" || o.KeyFile != ""
+}
+
+// Config builds the client TLS configuration. The CA is required so the AI service is
+// pinned to a private CA, and the client certificate and key must be set together.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	if o.CAFile == "" {
+		return nil, fmt.Errorf("AI service TLS needs a CA file to verify the AI service against")
+	}
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, fmt.Errorf("AI service client certificate and key must be set together")
+	}
+
+	pool, err := security.LoadCertPool(o.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		RootCAs:    pool,
+		ServerName: o.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AI service client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// transport carries all traffic to the AI service; nil uses http.DefaultTransport
+var transport http.RoundTripper
+
+// NewHTTPClient returns a client for calls to the AI service, using mutual TLS when
+// it's configured. Its calls are recorded in the AI request metrics.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: instrumentedTransport{next: transport}}
+}
+
+// ConfigureTLS switches traffic to the AI service to mutual TLS. Call it before Init
+// and before creating other clients with NewHTTPClient.
+func ConfigureTLS(opts TLSOptions) error {
+	config, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = config
+	transport = t
+	return nil
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// maxAPIActivityDays is how far back a user can list their own API requests
+const maxAPIActivityDays = 30
+
+// AccountHandler serves a user's views of their own account
+type AccountHandler struct {
+	db db.Querier
+}
+
+func NewAccountHandler(store db.Querier) *AccountHandler {
+	return &AccountHandler{db: store}
+}
+
+// GetAPIActivity lists the current user's recent API requests
+// @Summary List my API activity
+// @Description The current user's own API requests from the security audit log, newest first: endpoint, time, response status and the API key used, if any. Lets API-key users debug their integrations.
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days to look back (1-30, default 7)"
+// @Param api_key_id query int false "Only requests made with this API key"
+// @Param errors query bool false "Only requests that failed (status 400 or above) or were blocked"
+// @Param limit query int false "Page size (1-200, default 50)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/api-activity [get]
+func (h *AccountHandler) GetAPIActivity(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	days := 7
+	if d := c.Query("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 || parsed > maxAPIActivityDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", maxAPIActivityDays)})
+			return
+		}
+		days = parsed
+	}
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = parsed
+	}
+
+	// The date bound also limits the audit log partitions scanned
+	query := `
+		SELECT l.id, COALESCE(l.method, '') AS method, COALESCE(l.endpoint, '') AS endpoint,
+		       COALESCE(l.response_status, 0) AS response_status,
+		       (l.metadata->>'duration_ms')::bigint AS duration_ms,
+		       COALESCE(l.ip_address, '') AS ip_address, COALESCE(l.blocked, false) AS blocked,
+		       COALESCE(l.block_reason, '') AS block_reason,
+		       COALESCE(l.metadata->>'actor_type', 'user') AS actor_type,
+		       (l.metadata->>'api_key_id')::bigint AS api_key_id, k.name AS key_name, k.key AS key_masked,
+		       l.created_at
+		FROM security_audit_logs l
+		LEFT JOIN api_keys k ON k.id = (l.metadata->>'api_key_id')::bigint AND k.user_id = l.user_id
+		WHERE l.user_id = $1 AND l.created_at >= $2
+	`
+	args := []interface{}{userID, time.Now().AddDate(0, 0, -days)}
+
+	if k := c.Query("api_key_id"); k != "" {
+		keyID, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid api_key_id"})
+			return
+		}
+		args = append(args, keyID)
+		query += fmt.Sprintf(" AND (l.metadata->>'api_key_id')::bigint = $%d", len(args))
+	}
+	if c.Query("errors") == "true" {
+		query += " AND (l.response_status >= 400 OR l.blocked)"
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		before, err := security.ParseAuditCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		args = append(args, before.CreatedAt, before.ID)
+		query += fmt.Sprintf(" AND (l.created_at, l.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY l.created_at DESC, l.id DESC LIMIT $%d", len(args))
+
+	var rows []struct {
+		models.APIActivity
+		APIKeyID  sql.NullInt64  `db:"api_key_id"`
+		KeyName   sql.NullString `db:"key_name"`
+		KeyMasked sql.NullString `db:"key_masked"`
+	}
+	if err := h.db.Select(&rows, query, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API activity"})
+		return
+	}
+
+	requests := make([]models.APIActivity, 0, len(rows))
+	for _, row := range rows {
+		request := row.APIActivity
+		if row.APIKeyID.Valid {
+			request.APIKey = &models.APIKeyRef{ID: row.APIKeyID.Int64, Name: row.KeyName.String, Key: row.KeyMasked.String}
+		}
+		requests = append(requests, request)
+	}
+
+	response := gin.H{"requests": requests, "count": len(requests)}
+	if len(requests) == limit {
+		last := requests[len(requests)-1]
+		response["next_cursor"] = security.AuditCursor{CreatedAt: last.CreatedAt, ID: last.ID}.String()
+	}
+	c.JSON(http.StatusOK, response)
+}
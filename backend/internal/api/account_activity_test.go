@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func TestAccountGetAPIActivity(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`FROM security_audit_logs l\s+LEFT JOIN api_keys k .+ WHERE l.user_id = \$1 AND l.created_at >= \$2 AND \(l.metadata->>'api_key_id'\)::bigint = \$3 AND \(l.response_status >= 400 OR l.blocked\) ORDER BY l.created_at DESC, l.id DESC LIMIT \$4`).
+		WithArgs(testUserID, sqlmock.AnyArg(), int64(5), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "method", "endpoint", "response_status", "duration_ms", "ip_address", "blocked", "block_reason", "actor_type", "api_key_id", "key_name", "key_masked", "created_at"}).
+			AddRow(91, "POST", "/api/v1/migrations", 409, 12, "203.0.113.7", false, "", "api_key", 5, "CI", "dm_1a2b3...9f0e", now).
+			AddRow(88, "GET", "/api/v1/migrations/4", 404, 3, "203.0.113.7", false, "", "api_key", 5, nil, nil, now.Add(-time.Minute)))
+
+	status, body := serve(t, "GET", "/account/api-activity", "/account/api-activity?api_key_id=5&errors=true&limit=2", nil, NewAccountHandler(store).GetAPIActivity)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Requests   []models.APIActivity `json:"requests"`
+		NextCursor string               `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Requests) != 2 || resp.NextCursor == "" {
+		t.Fatalf("response = %s, want two requests and a next page", body)
+	}
+	if key := resp.Requests[0].APIKey; key == nil || key.Name != "CI" || key.Key != "dm_1a2b3...9f0e" {
+		t.Errorf("api_key = %+v", key)
+	}
+	if key := resp.Requests[1].APIKey; key == nil || key.ID != 5 || key.Name != "" {
+		t.Errorf("api_key of a deleted key = %+v, want only its ID", key)
+	}
+}
+
+func TestAccountGetAPIActivityRejectsLongRange(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/account/api-activity", "/account/api-activity?days=90", nil, NewAccountHandler(store).GetAPIActivity)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+type AIInteractionsHandler struct {
+	auditor *security.AIAuditor
+}
+
+func NewAIInteractionsHandler() *AIInteractionsHandler {
+	return &AIInteractionsHandler{
+		auditor: security.GetAIAuditor(),
+	}
+}
+
+// parseAIInteractionFilter reads the shared filter query parameters
+func parseAIInteractionFilter(c *gin.Context) (security.AIInteractionFilter, error) {
+	filter := security.AIInteractionFilter{
+		InteractionType: c.Query("type"),
+		DataRegion:      c.Query("data_region"),
+	}
+
+	if u := c.Query("user_id"); u != "" {
+		id, err := strconv.ParseInt(u, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid user_id")
+		}
+		filter.UserID = id
+	}
+
+	if o := c.Query("organization_id"); o != "" {
+		id, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid organization_id")
+		}
+		filter.OrganizationID = id
+	}
+
+	if s := c.Query("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter, fmt.Errorf("since must be an RFC3339 timestamp")
+		}
+		filter.Since = &t
+	}
+
+	if u := c.Query("until"); u != "" {
+		t, err := time.Parse(time.RFC3339, u)
+		if err != nil {
+			return filter, fmt.Errorf("until must be an RFC3339 timestamp")
+		}
+		filter.Until = &t
+	}
+
+	return filter, nil
+}
+
+// GetAll lists recorded AI interactions for compliance review (admin only)
+// @Summary List AI interactions
+// @Description List sanitized AI prompts and filtered responses for compliance review
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Interaction type (chat, generation, translation)"
+// @Param user_id query int false "Filter by user"
+// @Param organization_id query int false "Filter by organization"
+// @Param data_region query string false "Filter by the data region of the AI service instance (eu, us)"
+// @Param since query string false "RFC3339 start time"
+// @Param until query string false "RFC3339 end time"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} security.AIInteraction
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/ai-interactions [get]
+func (h *AIInteractionsHandler) GetAll(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	filter, err := parseAIInteractionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	interactions, err := h.auditor.List(filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve AI interactions"})
+		return
+	}
+
+	if interactions == nil {
+		interactions = []security.AIInteraction{}
+	}
+
+	c.JSON(http.StatusOK, interactions)
+}
+
+// Export exports AI interactions as CSV or JSON (admin only)
+// @Summary Export AI interactions
+// @Description Export sanitized AI interactions for compliance archives
+// @Tags admin
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param format query string false "csv (default) or json"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/ai-interactions/export [get]
+func (h *AIInteractionsHandler) Export(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	filter, err := parseAIInteractionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+		return
+	}
+
+	// Exports are capped to keep a single request bounded
+	interactions, err := h.auditor.List(filter, 10000, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export AI interactions"})
+		return
+	}
+
+	filename := fmt.Sprintf("ai-interactions-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if format == "json" {
+		if interactions == nil {
+			interactions = []security.AIInteraction{}
+		}
+		c.JSON(http.StatusOK, interactions)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"id", "created_at", "interaction_type", "user_id", "organization_id", "migration_id",
+		"status", "latency_ms", "prompt_tokens", "completion_tokens", "tokens_estimated",
+		"was_filtered", "contains_injection", "data_region", "prompt", "response", "error",
+	})
+	for _, i := range interactions {
+		errMsg := ""
+		if i.Error != nil {
+			errMsg = *i.Error
+		}
+		w.Write([]string{
+			strconv.FormatInt(i.ID, 10),
+			i.CreatedAt.Format(time.RFC3339),
+			i.InteractionType,
+			formatOptionalID(i.UserID),
+			formatOptionalID(i.OrganizationID),
+			formatOptionalID(i.MigrationID),
+			i.Status,
+			strconv.FormatInt(i.LatencyMs, 10),
+			strconv.Itoa(i.PromptTokens),
+			strconv.Itoa(i.CompletionTokens),
+			strconv.FormatBool(i.TokensEstimated),
+			strconv.FormatBool(i.WasFiltered),
+			strconv.FormatBool(i.ContainsInjection),
+			i.DataRegion,
+			i.Prompt,
+			i.Response,
+			errMsg,
+		})
+	}
+	w.Flush()
+}
+
+// formatOptionalID renders a nullable ID for CSV output
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
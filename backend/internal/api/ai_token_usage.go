@@ -0,0 +1,234 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/quota"
+	"github.com/gin-gonic/gin"
+)
+
+var chatMessageIDRegex = regexp.MustCompile(`^[a-f0-9]{32}$`)
+
+// newChatMessageID identifies a chat message in the AI token ledger, so the AI service
+// can report the message's final token usage later
+func newChatMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// recordChatTokens adds a chat message's tokens to the ledger
+func recordChatTokens(store db.Querier, messageID string, userID, orgID int64, promptTokens, completionTokens int) {
+	if messageID == "" {
+		// Without a ledger entry the tokens still count against the quota
+		recordUsage(orgID, quota.MetricAITokens, int64(promptTokens+completionTokens))
+		return
+	}
+	report := quota.TokenReport{
+		Key:              quota.ChatTokenKey(messageID),
+		Source:           quota.TokenSourceChat,
+		UserID:           &userID,
+		PromptTokens:     int64(promptTokens),
+		CompletionTokens: int64(completionTokens),
+	}
+	if orgID > 0 {
+		report.OrganizationID = &orgID
+	}
+	if _, err := quota.RecordTokens(store, report); err != nil {
+		log.Printf("Failed to record AI tokens of chat message %s: %v", messageID, err)
+	}
+}
+
+// recordMigrationTokens adds the tokens of the reported phases to the ledger. It
+// returns whether the organization's monthly AI token budget is used up, which tells
+// the AI service to stop generating.
+func recordMigrationTokens(store db.Querier, migrationID int64, metrics []models.PhaseMetrics) (bool, error) {
+	var owner struct {
+		UserID         int64  `db:"user_id"`
+		OrganizationID *int64 `db:"organization_id"`
+	}
+	err := store.Get(&owner, "SELECT user_id, organization_id FROM migrations WHERE id = $1", migrationID)
+	if err != nil {
+		return false, err
+	}
+
+	exhausted := false
+	for _, m := range metrics {
+		record, err := quota.RecordTokens(store, quota.TokenReport{
+			Key:              quota.MigrationTokenKey(migrationID, m.Phase),
+			Source:           quota.TokenSourceMigration,
+			OrganizationID:   owner.OrganizationID,
+			UserID:           &owner.UserID,
+			MigrationID:      &migrationID,
+			PromptTokens:     m.PromptTokens,
+			CompletionTokens: m.CompletionTokens,
+		})
+		if err != nil {
+			return exhausted, err
+		}
+		exhausted = exhausted || record.Exhausted
+	}
+	return exhausted, nil
+}
+
+// hasTokens reports whether any phase used AI tokens
+func hasTokens(metrics []models.PhaseMetrics) bool {
+	for _, m := range metrics {
+		if m.PromptTokens > 0 || m.CompletionTokens > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AIUsageReport is the AI service's token usage for a migration phase or a chat message
+type AIUsageReport struct {
+	MigrationID      int64  `json:"migration_id,omitempty"`
+	Phase            string `json:"phase,omitempty"`           // With migration_id
+	ChatMessageID    string `json:"chat_message_id,omitempty"` // message_id of the chat response
+	PromptTokens     int64  `json:"prompt_tokens" binding:"min=0"`
+	CompletionTokens int64  `json:"completion_tokens" binding:"min=0"`
+}
+
+// ReportAIUsage records AI token usage (internal endpoint for AI service)
+// @Summary Report AI token usage (Internal)
+// @Description Internal endpoint for the AI service to report the tokens a migration phase or chat message used. A report sent again for the same phase or message replaces the earlier one.
+// @Tags internal
+// @Accept json
+// @Produce json
+// @Param request body AIUsageReport true "Token usage"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /internal/ai-usage [post]
+func (h *MigrationsHandler) ReportAIUsage(c *gin.Context) {
+	var req AIUsageReport
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var report quota.TokenReport
+	switch {
+	case req.MigrationID > 0 && req.ChatMessageID == "":
+		if !phaseNameRegex.MatchString(req.Phase) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phase: " + req.Phase})
+			return
+		}
+		var owner struct {
+			UserID         int64  `db:"user_id"`
+			OrganizationID *int64 `db:"organization_id"`
+		}
+		err := h.db.Get(&owner, "SELECT user_id, organization_id FROM migrations WHERE id = $1", req.MigrationID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		report = quota.TokenReport{
+			Key:            quota.MigrationTokenKey(req.MigrationID, req.Phase),
+			Source:         quota.TokenSourceMigration,
+			OrganizationID: owner.OrganizationID,
+			UserID:         &owner.UserID,
+			MigrationID:    &req.MigrationID,
+		}
+
+	case req.ChatMessageID != "" && req.MigrationID == 0:
+		if !chatMessageIDRegex.MatchString(req.ChatMessageID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chat_message_id"})
+			return
+		}
+		// Chat messages are in the ledger from the time the backend answered them
+		var entry struct {
+			UserID         *int64 `db:"user_id"`
+			OrganizationID *int64 `db:"organization_id"`
+		}
+		key := quota.ChatTokenKey(req.ChatMessageID)
+		err := h.db.Get(&entry, "SELECT user_id, organization_id FROM ai_token_usage WHERE usage_key = $1", key)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Chat message not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		report = quota.TokenReport{
+			Key:            key,
+			Source:         quota.TokenSourceChat,
+			OrganizationID: entry.OrganizationID,
+			UserID:         entry.UserID,
+		}
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Report either migration_id and phase, or chat_message_id"})
+		return
+	}
+
+	report.PromptTokens = req.PromptTokens
+	report.CompletionTokens = req.CompletionTokens
+	record, err := quota.RecordTokens(h.db, report)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record AI usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"added":                 record.Added,
+		"used":                  record.Used,
+		"limit":                 record.Limit,
+		"token_budget_exceeded": record.Exhausted,
+	})
+}
+
+// GetAITokenUsage returns the organization's AI token usage by source, migration and member
+// @Summary Get AI token usage
+// @Description AI tokens used in a month against the plan's budget, broken down by source (migration, chat, other), top migrations and members
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "Month as YYYY-MM (default current month)"
+// @Success 200 {object} quota.TokenUsageReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/usage/ai-tokens [get]
+func (h *OrganizationsHandler) GetAITokenUsage(c *gin.Context) {
+	orgID := middleware.GetOrganizationID(c)
+	if orgID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User does not belong to an organization"})
+		return
+	}
+
+	period := time.Now()
+	if p := c.Query("period"); p != "" {
+		parsed, err := time.Parse("2006-01", p)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "period must be a month as YYYY-MM"})
+			return
+		}
+		period = parsed
+	}
+
+	report, err := quota.GetTokenUsage(db.DB, orgID, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch AI token usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
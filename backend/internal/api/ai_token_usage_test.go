@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestReportAIUsageForMigrationPhase(t *testing.T) {
+	store, mock := newMockDB(t)
+	period := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT user_id, organization_id FROM migrations WHERE id = \$1`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "organization_id"}).AddRow(testUserID, testOrgID))
+	mock.ExpectQuery(`INSERT INTO ai_token_usage`).
+		WithArgs("migration/8/generating_dbt_project", "migration", testOrgID, testUserID, int64(8), sqlmock.AnyArg(), int64(90000), int64(30000)).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "period", "added"}).AddRow(testOrgID, period, 20000))
+	mock.ExpectQuery(`INSERT INTO organization_usage`).
+		WithArgs(testOrgID, period, "ai_tokens", int64(20000)).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(510000))
+	mock.ExpectQuery(`SELECT COALESCE\(plan, 'free'\) FROM organizations`).
+		WithArgs(testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"plan"}).AddRow("free"))
+
+	status, body := serve(t, "POST", "/internal/ai-usage", "/internal/ai-usage", map[string]interface{}{
+		"migration_id":      8,
+		"phase":             "generating_dbt_project",
+		"prompt_tokens":     90000,
+		"completion_tokens": 30000,
+	}, NewMigrationsHandler(store).ReportAIUsage)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Added    int64 `json:"added"`
+		Used     int64 `json:"used"`
+		Limit    int64 `json:"limit"`
+		Exceeded bool  `json:"token_budget_exceeded"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Added != 20000 || resp.Used != 510000 || resp.Limit != 500000 || !resp.Exceeded {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestReportAIUsageUnknownChatMessage(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT user_id, organization_id FROM ai_token_usage WHERE usage_key = \$1`).
+		WithArgs("chat/0123456789abcdef0123456789abcdef").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "organization_id"}))
+
+	status, body := serve(t, "POST", "/internal/ai-usage", "/internal/ai-usage", map[string]interface{}{
+		"chat_message_id":   "0123456789abcdef0123456789abcdef",
+		"prompt_tokens":     1200,
+		"completion_tokens": 400,
+	}, NewMigrationsHandler(store).ReportAIUsage)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestReportAIUsageNeedsOneTarget(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	for _, req := range []map[string]interface{}{
+		{"prompt_tokens": 10},
+		{"migration_id": 8, "phase": "generating_dbt_project", "chat_message_id": "0123456789abcdef0123456789abcdef"},
+		{"migration_id": 8, "phase": "Generate; DROP"},
+		{"chat_message_id": "../other"},
+	} {
+		status, body := serve(t, "POST", "/internal/ai-usage", "/internal/ai-usage", req, NewMigrationsHandler(store).ReportAIUsage)
+		expectStatus(t, status, http.StatusBadRequest, body)
+	}
+}
+
+func TestMigrationsUpdateStatusReportsExhaustedTokenBudget(t *testing.T) {
+	store, mock := newMockDB(t)
+	period := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`UPDATE migrations SET status = \$1, progress = \$2`).
+		WithArgs("running", 70, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO migration_metrics`).
+		WithArgs(int64(8), "generating_dbt_project", int64(0), int64(4000), int64(1000), 0, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`SELECT user_id, organization_id FROM migrations`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "organization_id"}).AddRow(testUserID, testOrgID))
+	mock.ExpectQuery(`INSERT INTO ai_token_usage`).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "period", "added"}).AddRow(testOrgID, period, 5000))
+	mock.ExpectQuery(`INSERT INTO organization_usage`).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(5002000))
+	mock.ExpectQuery(`SELECT COALESCE\(plan, 'free'\) FROM organizations`).
+		WillReturnRows(sqlmock.NewRows([]string{"plan"}).AddRow("starter"))
+	mock.ExpectExec(`UPDATE migration_runs`).
+		WithArgs(int64(8), "running", 70, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":   "running",
+		"progress": 70,
+		"metrics": []map[string]interface{}{
+			{"phase": "generating_dbt_project", "prompt_tokens": 4000, "completion_tokens": 1000},
+		},
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Exceeded bool `json:"token_budget_exceeded"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Exceeded {
+		t.Errorf("response = %s", body)
+	}
+}
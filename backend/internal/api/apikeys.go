@@ -3,13 +3,16 @@ package api
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/datamigrate-ai/backend/internal/db"
 	"github.com/datamigrate-ai/backend/internal/middleware"
 	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 type APIKeysHandler struct{}
@@ -29,45 +32,67 @@ func generateAPIKey() (string, error) {
 
 // GetAll returns all API keys for the current user
 func (h *APIKeysHandler) GetAll(c *gin.Context) {
-	userID := middleware.GetUserID(c)
-
-	var keys []models.APIKey
-	err := db.DB.Select(&keys, `
-		SELECT id, name, key, is_active, rate_limit, user_id, created_at, last_used_at
-		FROM api_keys
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`, userID)
-
+	keys, err := fetchAPIKeys(middleware.GetUserID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
 		return
 	}
 
-	if keys == nil {
-		keys = []models.APIKey{}
-	}
-
-	// Mask the keys for security (show only first 8 and last 4 characters)
-	for i := range keys {
-		if len(keys[i].Key) > 12 {
-			keys[i].Key = keys[i].Key[:8] + "..." + keys[i].Key[len(keys[i].Key)-4:]
-		}
-	}
-
 	c.JSON(http.StatusOK, keys)
 }
 
+// fetchAPIKeys lists the (masked) API keys owned by a user
+func fetchAPIKeys(userID int64) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := db.DB.Select(&keys, `
+		SELECT id, name, key, is_active, rate_limit, COALESCE(scopes, '{}') as scopes,
+		       COALESCE(allowed_ips, '{}') as allowed_ips, expires_at, user_id, created_at, last_used_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	return keys, err
+}
+
 // Create creates a new API key
 func (h *APIKeysHandler) Create(c *gin.Context) {
-	userID := middleware.GetUserID(c)
+	issueAPIKey(c, middleware.GetUserID(c))
+}
 
+// issueAPIKey creates an API key owned by userID from the request body and returns
+// the full key once. It's shared by personal keys and service account keys.
+func issueAPIKey(c *gin.Context, userID int64) {
 	var req models.CreateAPIKeyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := security.ValidateScopes(req.Scopes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := security.ValidateIPList(req.AllowedIPs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	keyConfig := security.DefaultAPIKeyConfig()
+	if keyConfig.RequireIPRestriction && len(req.AllowedIPs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "allowed_ips is required for new API keys"})
+		return
+	}
+
+	expiresInDays := keyConfig.DefaultExpirationDays
+	if req.ExpiresInDays != nil {
+		expiresInDays = *req.ExpiresInDays
+	}
+	if expiresInDays < 0 || expiresInDays > keyConfig.MaxExpirationDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expires_in_days must be between 0 and %d", keyConfig.MaxExpirationDays)})
+		return
+	}
+	expiresAt := security.GenerateExpirationDate(expiresInDays)
+
 	// Generate API key
 	key, err := generateAPIKey()
 	if err != nil {
@@ -82,10 +107,11 @@ func (h *APIKeysHandler) Create(c *gin.Context) {
 
 	var keyID int64
 	err = db.DB.QueryRow(`
-		INSERT INTO api_keys (name, key, rate_limit, user_id)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO api_keys (name, key, key_hash, rate_limit, scopes, allowed_ips, expires_at, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
-	`, req.Name, key, rateLimit, userID).Scan(&keyID)
+	`, req.Name, key[:8]+"..."+key[len(key)-4:], security.HashAPIKey(key), rateLimit,
+		pq.Array(req.Scopes), pq.Array(req.AllowedIPs), expiresAt, userID).Scan(&keyID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
@@ -94,11 +120,14 @@ func (h *APIKeysHandler) Create(c *gin.Context) {
 
 	// Return the full key only once during creation
 	c.JSON(http.StatusCreated, gin.H{
-		"id":         keyID,
-		"name":       req.Name,
-		"key":        key,
-		"rate_limit": rateLimit,
-		"message":    "Save this key securely. It won't be shown again.",
+		"id":          keyID,
+		"name":        req.Name,
+		"key":         key,
+		"rate_limit":  rateLimit,
+		"scopes":      req.Scopes,
+		"allowed_ips": req.AllowedIPs,
+		"expires_at":  expiresAt,
+		"message":     "Save this key securely. It won't be shown again.",
 	})
 }
 
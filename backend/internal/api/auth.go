@@ -2,23 +2,25 @@ package api
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"log"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/datamigrate-ai/backend/internal/config"
 	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
 	"github.com/datamigrate-ai/backend/internal/email"
 	"github.com/datamigrate-ai/backend/internal/middleware"
 	"github.com/datamigrate-ai/backend/internal/models"
 	"github.com/datamigrate-ai/backend/internal/security"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // generateSlug creates a URL-safe slug from organization name
@@ -38,12 +40,135 @@ func generateSlug(name string) string {
 	return slug
 }
 
+// uniqueOrganizationSlug returns base, or base with a numeric suffix, that no other
+// organization uses. excludeOrgID is the organization being renamed (0 when creating).
+func uniqueOrganizationSlug(base string, excludeOrgID int64) (string, error) {
+	slug := base
+	for counter := 1; counter <= 100; counter++ {
+		var count int
+		err := db.DB.Get(&count, "SELECT COUNT(*) FROM organizations WHERE slug = $1 AND id <> $2", slug, excludeOrgID)
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = base + "-" + strconv.Itoa(counter)
+	}
+	return "", fmt.Errorf("no free slug for %q", base)
+}
+
+// getUserOrganizationID returns the user's default organization (0 if none). Requests
+// act in the organization of their token; see middleware.GetOrganizationID.
+func getUserOrganizationID(userID int64) (int64, error) {
+	var orgID sql.NullInt64
+	err := db.DB.Get(&orgID, "SELECT organization_id FROM users WHERE id = $1", userID)
+	if err != nil {
+		return 0, err
+	}
+	return orgID.Int64, nil
+}
+
+// lockoutClearedByAdmin applies an unlock made with the admin CLI, which can only
+// record it in the database since lockouts live in this process's memory
+func lockoutClearedByAdmin(email string) bool {
+	var clearedAt sql.NullTime
+	err := db.DB.Get(&clearedAt, "SELECT lockout_cleared_at FROM users WHERE email = $1", email)
+	if err != nil || !clearedAt.Valid {
+		return false
+	}
+	return security.GetAccountLockout().UnlockIfClearedSince(email, clearedAt.Time)
+}
+
+// respondPasswordPolicyViolations writes a 400 listing every failed password rule
+func respondPasswordPolicyViolations(c *gin.Context, violations []security.PasswordPolicyViolation) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      "Password does not meet the password policy",
+		"violations": violations,
+	})
+}
+
+func hashResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func unsupportedLanguageMessage() string {
+	return "preferred_language must be one of: " + strings.Join(email.SupportedLanguages, ", ")
+}
+
+// requireOrgAdmin resolves the organization the request acts in and rejects members who aren't admins of it.
+// Platform admins are always allowed. It writes the error response itself and returns ok=false.
+func requireOrgAdmin(c *gin.Context) (int64, bool) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	if orgID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User does not belong to an organization"})
+		return 0, false
+	}
+
+	// The token carries the role; API keys don't, so look it up for them
+	role := middleware.GetOrg(c).Role
+	if role == "" {
+		err := db.DB.Get(&role, "SELECT role FROM organization_members WHERE user_id = $1 AND organization_id = $2", userID, orgID)
+		if err != nil && err != sql.ErrNoRows {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return 0, false
+		}
+	}
+
+	if role != "admin" && !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Organization admin access required"})
+		return 0, false
+	}
+
+	return orgID, true
+}
+
 type AuthHandler struct {
-	cfg *config.Config
+	cfg     *config.Config
+	captcha *security.CaptchaVerifier
 }
 
 func NewAuthHandler(cfg *config.Config) *AuthHandler {
-	return &AuthHandler{cfg: cfg}
+	return &AuthHandler{
+		cfg:     cfg,
+		captcha: security.NewCaptchaVerifier(cfg.CaptchaProvider, cfg.CaptchaSecret),
+	}
+}
+
+// verifyCaptcha checks the request's CAPTCHA token and writes a 400 on failure
+func (h *AuthHandler) verifyCaptcha(c *gin.Context, token string) bool {
+	if err := h.captcha.Verify(token, c.ClientIP()); err != nil {
+		log.Printf("CAPTCHA verification failed for %s on %s: %v", c.ClientIP(), c.FullPath(), err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "CAPTCHA verification failed",
+			"captcha_required": true,
+		})
+		return false
+	}
+	return true
+}
+
+// GetCaptchaConfig returns the public CAPTCHA settings the frontend needs to render a widget
+// @Summary Get CAPTCHA configuration
+// @Description Get the CAPTCHA provider and site key (empty when CAPTCHA is disabled)
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /auth/captcha-config [get]
+func (h *AuthHandler) GetCaptchaConfig(c *gin.Context) {
+	if !h.captcha.Enabled() {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":        true,
+		"provider":       h.captcha.Provider(),
+		"site_key":       h.cfg.CaptchaSiteKey,
+		"login_failures": h.cfg.CaptchaLoginFailures,
+	})
 }
 
 // Register creates a new user and organization
@@ -65,6 +190,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	if !h.verifyCaptcha(c, req.CaptchaToken) {
+		return
+	}
+
 	// Check if user already exists
 	var existingUser models.User
 	err := db.DB.Get(&existingUser, "SELECT id FROM users WHERE email = $1", req.Email)
@@ -73,31 +202,33 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	language := email.LanguageFromAcceptLanguage(c.GetHeader("Accept-Language"))
+	if req.PreferredLanguage != nil {
+		if !email.IsSupportedLanguage(*req.PreferredLanguage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": unsupportedLanguageMessage()})
+			return
+		}
+		language = email.NormalizeLanguage(*req.PreferredLanguage)
+	}
+
+	// New organizations start with the default password policy
+	if violations := security.DefaultPasswordPolicy().CheckPassword(req.Password, 0); len(violations) > 0 {
+		respondPasswordPolicyViolations(c, violations)
+		return
+	}
+
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := security.HashPassword(req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
 
 	// Generate unique slug for organization
-	baseSlug := generateSlug(req.OrganizationName)
-	slug := baseSlug
-	counter := 1
-
-	// Ensure slug is unique
-	for {
-		var count int
-		db.DB.Get(&count, "SELECT COUNT(*) FROM organizations WHERE slug = $1", slug)
-		if count == 0 {
-			break
-		}
-		slug = baseSlug + "-" + string(rune('0'+counter))
-		counter++
-		if counter > 100 {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate unique organization slug"})
-			return
-		}
+	slug, err := uniqueOrganizationSlug(generateSlug(req.OrganizationName), 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate unique organization slug"})
+		return
 	}
 
 	// Start transaction
@@ -124,9 +255,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// Create user with organization and admin role
 	var userID int64
 	err = tx.QueryRow(
-		`INSERT INTO users (email, password, first_name, last_name, job_title, phone, organization_id, role, is_admin, is_active)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, 'admin', false, true) RETURNING id`,
-		req.Email, string(hashedPassword), req.FirstName, req.LastName, req.JobTitle, req.Phone, orgID,
+		`INSERT INTO users (email, password, first_name, last_name, job_title, phone, organization_id, role, is_admin, is_active, preferred_language)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, 'admin', false, true, $8) RETURNING id`,
+		req.Email, hashedPassword, req.FirstName, req.LastName, req.JobTitle, req.Phone, orgID, language,
 	).Scan(&userID)
 
 	if err != nil {
@@ -134,57 +265,59 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	_, err = tx.Exec("INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, 'admin')", orgID, userID)
+	if err == nil {
+		_, err = tx.Exec("UPDATE organizations SET owner_id = $1 WHERE id = $2", userID, orgID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
-	// Send welcome email (async, don't block registration)
-	go func() {
-		emailService := email.NewService()
-		if emailService.IsConfigured() {
-			err := emailService.SendWelcomeEmail(req.Email, req.FirstName, req.OrganizationName)
-			if err != nil {
-				log.Printf("Failed to send welcome email to %s: %v", req.Email, err)
-			} else {
-				log.Printf("Welcome email sent to %s", req.Email)
-			}
+	// Queue the welcome email; the outbox worker sends it
+	emailService := email.NewService()
+	if emailService.IsConfigured() {
+		if err := emailService.SendWelcomeEmail(req.Email, req.FirstName, req.OrganizationName, language); err != nil {
+			log.Printf("Failed to queue welcome email to %s: %v", req.Email, err)
 		} else {
-			// Use mock service in development
-			mockService := email.NewMockService()
-			mockService.SendWelcomeEmail(req.Email, req.FirstName, req.OrganizationName)
+			log.Printf("Welcome email queued for %s", req.Email)
 		}
-	}()
-
-	// Generate token
-	token, err := middleware.GenerateToken(userID, req.Email, false, h.cfg.JWTExpiration)
+	} else {
+		// Use mock service in development
+		mockService := email.NewMockService()
+		mockService.SendWelcomeEmail(req.Email, req.FirstName, req.OrganizationName, language)
+	}
+
+	response, err := h.issueSession(models.User{
+		ID:             userID,
+		Email:          req.Email,
+		FirstName:      &req.FirstName,
+		LastName:       &req.LastName,
+		JobTitle:       req.JobTitle,
+		Phone:          req.Phone,
+		OrganizationID: &orgID,
+		Role:           "admin",
+		IsAdmin:        false,
+		IsActive:       true,
+		Organization: &models.Organization{
+			ID:   orgID,
+			Name: req.OrganizationName,
+			Slug: slug,
+			Plan: "free",
+		},
+	}, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, models.LoginResponse{
-		AccessToken: token,
-		User: models.User{
-			ID:             userID,
-			Email:          req.Email,
-			FirstName:      &req.FirstName,
-			LastName:       &req.LastName,
-			JobTitle:       req.JobTitle,
-			Phone:          req.Phone,
-			OrganizationID: &orgID,
-			Role:           "admin",
-			IsAdmin:        false,
-			IsActive:       true,
-			Organization: &models.Organization{
-				ID:   orgID,
-				Name: req.OrganizationName,
-				Slug: slug,
-				Plan: "free",
-			},
-		},
-	})
+	c.JSON(http.StatusCreated, response)
 }
 
 // Login authenticates a user
@@ -213,6 +346,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// Check if account is locked
 	lockoutStatus := accountLockout.IsLocked(req.Email)
+	if lockoutStatus.Locked && lockoutClearedByAdmin(req.Email) {
+		lockoutStatus = accountLockout.IsLocked(req.Email)
+	}
 	if lockoutStatus.Locked {
 		log.Printf("Login attempt for locked account: %s from IP: %s", req.Email, clientIP)
 		c.JSON(http.StatusTooManyRequests, gin.H{
@@ -233,15 +369,23 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Require a CAPTCHA once this email or IP has accumulated failed attempts
+	if h.captcha.Enabled() && accountLockout.RequiresCaptcha(req.Email, clientIP, h.cfg.CaptchaLoginFailures) {
+		if !h.verifyCaptcha(c, req.CaptchaToken) {
+			return
+		}
+	}
+
 	// Find user with all fields
 	var user models.User
 	err := db.DB.Get(&user, `
 		SELECT id, email, password, first_name, last_name, job_title, phone,
 		       organization_id, role, is_admin, is_active, last_login_at, created_at, updated_at
-		FROM users WHERE email = $1`, req.Email)
+		FROM users WHERE email = $1 AND COALESCE(account_type, 'human') = 'human'`, req.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			// Record failed attempt even for non-existent accounts (prevent enumeration)
+			// Record failed attempt even for non-existent accounts (prevent enumeration).
+			// Service accounts never match, so they can't log in interactively.
 			status := accountLockout.RecordFailedAttempt(req.Email, clientIP)
 			log.Printf("Failed login (user not found): %s from IP: %s, attempts left: %d", req.Email, clientIP, status.AttemptsLeft)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
@@ -259,7 +403,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Check password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if !security.VerifyPassword(user.Password, req.Password) {
 		// Record failed attempt
 		status := accountLockout.RecordFailedAttempt(req.Email, clientIP)
 		log.Printf("Failed login (wrong password): %s from IP: %s, attempts left: %d", req.Email, clientIP, status.AttemptsLeft)
@@ -278,6 +422,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		if status.AttemptsLeft <= 3 && status.AttemptsLeft > 0 {
 			response["warning"] = fmt.Sprintf("%d login attempts remaining before account lockout", status.AttemptsLeft)
 		}
+		if h.captcha.Enabled() && accountLockout.RequiresCaptcha(req.Email, clientIP, h.cfg.CaptchaLoginFailures) {
+			response["captcha_required"] = true
+		}
 		c.JSON(http.StatusUnauthorized, response)
 		return
 	}
@@ -285,30 +432,103 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Successful login - clear failed attempts
 	accountLockout.RecordSuccessfulLogin(req.Email, clientIP)
 	log.Printf("Successful login: %s from IP: %s", req.Email, clientIP)
+	rehashPassword(user.ID, user.Password, req.Password)
+
+	h.completeLogin(c, user, "")
+}
+
+// rehashPassword replaces a password hash made with other hashing settings than the
+// configured ones, now that the password is at hand. It's not a password change, so the
+// password's age and history are left alone.
+func rehashPassword(userID int64, oldHash, password string) {
+	if !security.PasswordNeedsRehash(oldHash) {
+		return
+	}
+	hash, err := security.HashPassword(password)
+	if err != nil {
+		log.Printf("Failed to rehash the password of user %d: %v", userID, err)
+		return
+	}
+	if _, err := db.DB.Exec("UPDATE users SET password = $1 WHERE id = $2 AND password = $3", hash, userID, oldHash); err != nil {
+		log.Printf("Failed to store the rehashed password of user %d: %v", userID, err)
+	}
+}
+
+// completeLogin starts a session for an authenticated user: it records the login and
+// responds with an access token for the user's default organization. provider is the
+// social login provider, or empty for a password login.
+func (h *AuthHandler) completeLogin(c *gin.Context, user models.User, provider string) {
+	// An account locked from a new sign-in email stays locked until the password is reset
+	if lockedAt, err := security.AccountLockedAt(user.ID); err == nil && lockedAt != nil {
+		log.Printf("Login attempt for account locked by its owner: %s from IP: %s", user.Email, c.ClientIP())
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":  "This account was locked after a sign-in was reported. Reset your password to unlock it.",
+			"locked": true,
+		})
+		return
+	}
+
+	// Start the session in the user's default organization
+	orgID, err := loginOrganizationID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	// On an organization's custom domain, only its members sign in, and into it
+	if domainOrgID, ok := domains.RequestOrganization(c.Request); ok {
+		member, err := security.ResolveUserOrganization(user.ID, domainOrgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if member == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This account is not a member of the organization this site belongs to"})
+			return
+		}
+		orgID = domainOrgID
+	}
+	loadUserOrganization(&user, orgID)
+
+	// Record the login for anomaly detection (e.g. logins from a new country)
+	loginEvent := &security.SecurityEvent{
+		EventType:      "login_success",
+		Severity:       "info",
+		UserID:         &user.ID,
+		OrganizationID: user.OrganizationID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusOK,
+		Metadata:       map[string]interface{}{},
+	}
+	if provider != "" {
+		loginEvent.Metadata["provider"] = provider
+	}
+	if country := security.ClientCountry(c); country != "" {
+		loginEvent.Metadata["country"] = country
+	}
+	security.GetGuardian().LogSecurityEvent(loginEvent)
+
+	// Email the user about sign-ins from a new device or country
+	notifyNewLogin(c, user)
 
 	// Update last login
 	db.DB.Exec("UPDATE users SET last_login_at = NOW() WHERE id = $1", user.ID)
 
-	// Get organization if user belongs to one
-	if user.OrganizationID != nil {
-		var org models.Organization
-		err := db.DB.Get(&org, "SELECT id, name, slug, plan, max_users, max_migrations FROM organizations WHERE id = $1", *user.OrganizationID)
-		if err == nil {
-			user.Organization = &org
-		}
+	// Restrict passwords that have exceeded the organization's max age to changing them.
+	// Social logins don't use the password, so they aren't interrupted by it.
+	passwordExpired := false
+	if user.OrganizationID != nil && provider == "" {
+		passwordExpired = security.IsPasswordExpired(user.ID, *user.OrganizationID)
 	}
 
-	// Generate token
-	token, err := middleware.GenerateToken(user.ID, user.Email, user.IsAdmin, h.cfg.JWTExpiration)
+	response, err := h.issueSession(user, passwordExpired)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
-
-	c.JSON(http.StatusOK, models.LoginResponse{
-		AccessToken: token,
-		User:        user,
-	})
+	c.JSON(http.StatusOK, response)
 }
 
 // GetCurrentUser returns the current authenticated user
@@ -327,35 +547,42 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	var user models.User
 	err := db.DB.Get(&user, `
 		SELECT id, email, first_name, last_name, job_title, phone,
-		       organization_id, role, is_admin, is_active, last_login_at, created_at, updated_at
+		       organization_id, role, is_admin, is_active, COALESCE(preferred_language, 'en') as preferred_language,
+		       COALESCE(timezone, 'UTC') as timezone, last_login_at, created_at, updated_at
 		FROM users WHERE id = $1`, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	// Get organization if user belongs to one
-	if user.OrganizationID != nil {
-		var org models.Organization
-		err := db.DB.Get(&org, "SELECT id, name, slug, plan, max_users, max_migrations FROM organizations WHERE id = $1", *user.OrganizationID)
-		if err == nil {
-			user.Organization = &org
-		}
-	}
+	// Report the organization the request acts in
+	loadUserOrganization(&user, middleware.GetOrganizationID(c))
 
 	c.JSON(http.StatusOK, user)
 }
 
-// Logout (client-side token removal, but we can add token blacklisting later)
+// Logout ends the session of the refresh token in the body, if any. The access token
+// stays valid until it expires, which is at most JWT_ACCESS_TTL_MINUTES.
 // @Summary Logout user
-// @Description Logout the current user (client-side token removal)
+// @Description Logout the current user. Send the session's refresh token to revoke it; the client discards the access token.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body models.RefreshTokenRequest false "Refresh token to revoke"
 // @Success 200 {object} map[string]string
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if c.ShouldBindJSON(&req) == nil {
+		_, err := db.DB.Exec(`
+			UPDATE refresh_tokens SET revoked_at = NOW()
+			WHERE token_hash = $1 AND user_id = $2 AND revoked_at IS NULL
+		`, hashRefreshToken(req.RefreshToken), middleware.GetUserID(c))
+		if err != nil {
+			log.Printf("Failed to revoke refresh token on logout: %v", err)
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
@@ -421,6 +648,25 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		query += fmt.Sprintf(", phone = $%d", argCount)
 		args = append(args, *req.Phone)
 	}
+	if req.PreferredLanguage != nil {
+		if !email.IsSupportedLanguage(*req.PreferredLanguage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": unsupportedLanguageMessage()})
+			return
+		}
+		argCount++
+		query += fmt.Sprintf(", preferred_language = $%d", argCount)
+		args = append(args, email.NormalizeLanguage(*req.PreferredLanguage))
+	}
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if timezone != "" && !email.IsValidTimezone(timezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "timezone must be an IANA time zone, e.g. Europe/Copenhagen"})
+			return
+		}
+		argCount++
+		query += fmt.Sprintf(", timezone = NULLIF($%d, '')", argCount)
+		args = append(args, timezone)
+	}
 
 	argCount++
 	query += fmt.Sprintf(" WHERE id = $%d", argCount)
@@ -436,21 +682,16 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	var user models.User
 	err = db.DB.Get(&user, `
 		SELECT id, email, first_name, last_name, job_title, phone,
-		       organization_id, role, is_admin, is_active, last_login_at, created_at, updated_at
+		       organization_id, role, is_admin, is_active, COALESCE(preferred_language, 'en') as preferred_language,
+		       COALESCE(timezone, 'UTC') as timezone, last_login_at, created_at, updated_at
 		FROM users WHERE id = $1`, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated user"})
 		return
 	}
 
-	// Get organization if user belongs to one
-	if user.OrganizationID != nil {
-		var org models.Organization
-		err := db.DB.Get(&org, "SELECT id, name, slug, plan, max_users, max_migrations FROM organizations WHERE id = $1", *user.OrganizationID)
-		if err == nil {
-			user.Organization = &org
-		}
-	}
+	// Report the organization the request acts in
+	loadUserOrganization(&user, middleware.GetOrganizationID(c))
 
 	c.JSON(http.StatusOK, user)
 }
@@ -487,25 +728,50 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	}
 
 	// Verify current password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+	if !security.VerifyPassword(user.Password, req.CurrentPassword) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
 		return
 	}
 
+	// Enforce the organization's password policy
+	orgID := middleware.GetOrganizationID(c)
+	if violations := security.LoadPasswordPolicy(orgID).CheckPassword(req.NewPassword, userID); len(violations) > 0 {
+		respondPasswordPolicyViolations(c, violations)
+		return
+	}
+
 	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := security.HashPassword(req.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
 
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Remember the outgoing password for history checks
+	if err := security.RecordPasswordHistory(tx, userID, user.Password); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
 	// Update password
-	_, err = db.DB.Exec("UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2", string(hashedPassword), userID)
+	_, err = tx.Exec("UPDATE users SET password = $1, password_changed_at = NOW(), locked_at = NULL, updated_at = NOW() WHERE id = $2", hashedPassword, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
 }
 
@@ -527,14 +793,21 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 
+	if !h.verifyCaptcha(c, req.CaptchaToken) {
+		return
+	}
+
 	// Find user by email
 	var user struct {
 		ID        int64   `db:"id"`
 		Email     string  `db:"email"`
 		FirstName *string `db:"first_name"`
 		IsActive  bool    `db:"is_active"`
+		Language  string  `db:"preferred_language"`
 	}
-	err := db.DB.Get(&user, "SELECT id, email, first_name, is_active FROM users WHERE email = $1", req.Email)
+	err := db.DB.Get(&user, `
+		SELECT id, email, first_name, is_active, COALESCE(preferred_language, 'en') as preferred_language
+		FROM users WHERE email = $1 AND COALESCE(account_type, 'human') = 'human'`, req.Email)
 	if err != nil {
 		// Don't reveal if email exists or not - always return success
 		log.Printf("Password reset requested for unknown email: %s", req.Email)
@@ -563,34 +836,35 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	// Delete any existing tokens for this user
 	db.DB.Exec("DELETE FROM password_reset_tokens WHERE user_id = $1", user.ID)
 
-	// Store the reset token
+	// Store only the token's hash, so the table can't be used to reset passwords
 	_, err = db.DB.Exec(`
-		INSERT INTO password_reset_tokens (user_id, token, expires_at)
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
 		VALUES ($1, $2, $3)
-	`, user.ID, resetToken, expiresAt)
+	`, user.ID, hashResetToken(resetToken), expiresAt)
 	if err != nil {
 		log.Printf("Failed to store password reset token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create password reset request"})
 		return
 	}
 
-	// Send password reset email
-	emailService := email.NewService()
+	// Send password reset email, linking back to the custom domain it was requested on
+	emailService := email.NewService().WithFrontendURL(domains.RequestBaseURL(c.Request))
 	firstName := "User"
 	if user.FirstName != nil && *user.FirstName != "" {
 		firstName = *user.FirstName
 	}
 
 	if emailService.IsConfigured() {
-		err = emailService.SendPasswordResetEmail(user.Email, firstName, resetToken)
+		// Queued for the outbox worker, so SMTP latency doesn't show in the response time
+		err = emailService.SendPasswordResetEmail(user.Email, firstName, resetToken, user.Language)
 		if err != nil {
-			log.Printf("Failed to send password reset email: %v", err)
+			log.Printf("Failed to queue password reset email: %v", err)
 			// Don't reveal email sending failures to the user
 		}
 	} else {
 		// Use mock service in development
 		mockService := email.NewMockService()
-		mockService.SendPasswordResetEmail(user.Email, firstName, resetToken)
+		mockService.SendPasswordResetEmail(user.Email, firstName, resetToken, user.Language)
 		log.Printf("Email service not configured, using mock. Reset token for %s: %s", user.Email, resetToken)
 	}
 
@@ -615,6 +889,15 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
+	// Refuse addresses that have been guessing tokens
+	guard := security.GetResetTokenGuard()
+	clientIP := c.ClientIP()
+	if blocked, retryAfter := guard.Blocked(clientIP); blocked {
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many invalid reset tokens. Please try again later."})
+		return
+	}
+
 	// Find the reset token
 	var tokenRecord struct {
 		ID        int64      `db:"id"`
@@ -625,12 +908,32 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	err := db.DB.Get(&tokenRecord, `
 		SELECT id, user_id, expires_at, used_at
 		FROM password_reset_tokens
-		WHERE token = $1
-	`, req.Token)
-	if err != nil {
+		WHERE token_hash = $1
+	`, hashResetToken(req.Token))
+	if err == sql.ErrNoRows {
+		blocked := guard.RecordFailure(clientIP)
+		event := &security.SecurityEvent{
+			EventType:      "password_reset_invalid_token",
+			Severity:       "medium",
+			IPAddress:      clientIP,
+			UserAgent:      c.Request.UserAgent(),
+			Endpoint:       c.Request.URL.Path,
+			Method:         c.Request.Method,
+			ResponseStatus: http.StatusBadRequest,
+		}
+		if blocked {
+			event.Severity = "high"
+			event.Blocked = true
+			event.BlockReason = "Too many invalid password reset tokens"
+		}
+		security.GetGuardian().LogSecurityEvent(event)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
 
 	// Check if token has already been used
 	if tokenRecord.UsedAt != nil {
@@ -644,8 +947,15 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
+	// Enforce the organization's password policy
+	orgID, _ := getUserOrganizationID(tokenRecord.UserID)
+	if violations := security.LoadPasswordPolicy(orgID).CheckPassword(req.NewPassword, tokenRecord.UserID); len(violations) > 0 {
+		respondPasswordPolicyViolations(c, violations)
+		return
+	}
+
 	// Hash the new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := security.HashPassword(req.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
@@ -659,8 +969,17 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
+	// Remember the outgoing password for history checks
+	var oldHash string
+	if err := tx.Get(&oldHash, "SELECT password FROM users WHERE id = $1", tokenRecord.UserID); err == nil {
+		if err := security.RecordPasswordHistory(tx, tokenRecord.UserID, oldHash); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+			return
+		}
+	}
+
 	// Update the user's password
-	_, err = tx.Exec("UPDATE users SET password = $1, updated_at = NOW() WHERE id = $2", string(hashedPassword), tokenRecord.UserID)
+	_, err = tx.Exec("UPDATE users SET password = $1, password_changed_at = NOW(), updated_at = NOW() WHERE id = $2", hashedPassword, tokenRecord.UserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
 		return
@@ -673,6 +992,9 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
+	// Sign out every session that may have been started with the old password
+	revokeRefreshTokens(tx, tokenRecord.UserID)
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
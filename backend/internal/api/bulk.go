@@ -0,0 +1,178 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// bulkTestConcurrency bounds how many connections a bulk test dials at once
+const bulkTestConcurrency = 5
+
+// actionError is a failed single-resource action with the response its own endpoint
+// would send, so batch endpoints can report it per item
+type actionError struct {
+	Status int
+	Body   gin.H
+}
+
+func newActionError(status int, message string) *actionError {
+	return &actionError{Status: status, Body: gin.H{"error": message}}
+}
+
+// itemResult converts an action's outcome into a batch result
+func itemResult(id int64, failure *actionError) models.BulkItemResult {
+	if failure == nil {
+		return models.BulkItemResult{ID: id, Success: true, Status: http.StatusOK}
+	}
+	result := models.BulkItemResult{ID: id, Status: failure.Status}
+	result.Error, _ = failure.Body["error"].(string)
+	if details, ok := failure.Body["details"]; ok {
+		result.Details = details
+	} else if len(failure.Body) > 1 {
+		// e.g. the quota limit that was hit
+		result.Details = failure.Body
+	}
+	return result
+}
+
+// bindBulkRequest reads a batch request, dropping repeated IDs so each resource is
+// acted on once
+func bindBulkRequest(c *gin.Context) ([]int64, bool) {
+	var req models.BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return uniqueIDs(req.IDs), true
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func bulkResponse(results []models.BulkItemResult) models.BulkResponse {
+	response := models.BulkResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
+
+// BulkDelete deletes several migrations
+// @Summary Delete multiple migrations
+// @Description Delete up to 100 migrations in one request. Each migration succeeds or fails on its own; running migrations are skipped.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkRequest true "Migration IDs"
+// @Success 200 {object} models.BulkResponse
+// @Failure 400 {object} map[string]string
+// @Router /migrations/bulk/delete [post]
+func (h *MigrationsHandler) BulkDelete(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	ids, ok := bindBulkRequest(c)
+	if !ok {
+		return
+	}
+
+	results := make([]models.BulkItemResult, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, itemResult(id, h.deleteMigration(id, userID, orgID)))
+	}
+	c.JSON(http.StatusOK, bulkResponse(results))
+}
+
+// BulkStart starts several pending migrations
+// @Summary Start multiple migrations
+// @Description Start up to 100 pending migrations in one request. Quotas are checked per migration, so later items can fail with status 402 once a limit is reached.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkRequest true "Migration IDs"
+// @Success 200 {object} models.BulkResponse
+// @Failure 400 {object} map[string]string
+// @Router /migrations/bulk/start [post]
+func (h *MigrationsHandler) BulkStart(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	ids, ok := bindBulkRequest(c)
+	if !ok {
+		return
+	}
+
+	results := make([]models.BulkItemResult, 0, len(ids))
+	for _, id := range ids {
+		queueReason, failure := h.startMigration(id, userID, orgID, nil)
+		result := itemResult(id, failure)
+		if queueReason != "" {
+			result.Details = gin.H{"status": "queued", "queue_reason": queueReason}
+		}
+		results = append(results, result)
+	}
+	c.JSON(http.StatusOK, bulkResponse(results))
+}
+
+// BulkTest tests several database connections
+// @Summary Test multiple connections
+// @Description Test up to 100 connections in one request, a few at a time. Each item's details hold the connection test result.
+// @Tags connections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkRequest true "Connection IDs"
+// @Success 200 {object} models.BulkResponse
+// @Failure 400 {object} map[string]string
+// @Router /connections/bulk/test [post]
+func (h *ConnectionsHandler) BulkTest(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	ids, ok := bindBulkRequest(c)
+	if !ok {
+		return
+	}
+
+	results := make([]models.BulkItemResult, len(ids))
+	sem := make(chan struct{}, bulkTestConcurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			test, failure := h.testConnection(id, userID, orgID)
+			if failure != nil {
+				results[i] = itemResult(id, failure)
+				return
+			}
+			results[i] = models.BulkItemResult{ID: id, Success: test.Success, Status: http.StatusOK, Details: test}
+			if !test.Success {
+				results[i].Status = http.StatusBadRequest
+				results[i].Error = test.Message
+			}
+		}(i, id)
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, bulkResponse(results))
+}
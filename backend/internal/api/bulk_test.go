@@ -0,0 +1,96 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func decodeBulkResponse(t *testing.T, body []byte) models.BulkResponse {
+	t.Helper()
+	var resp models.BulkResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestMigrationsBulkDelete(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT status FROM migrations`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("completed"))
+	mock.ExpectExec(`DELETE FROM migrations WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT status FROM migrations`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("running"))
+	mock.ExpectQuery(`SELECT status FROM migrations`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	req := map[string]interface{}{"ids": []int64{3, 4, 3, 5}}
+	status, body := serve(t, "POST", "/migrations/bulk/delete", "/migrations/bulk/delete", req, NewMigrationsHandler(store).BulkDelete)
+	expectStatus(t, status, http.StatusOK, body)
+
+	resp := decodeBulkResponse(t, body)
+	if resp.Succeeded != 1 || resp.Failed != 2 || len(resp.Results) != 3 {
+		t.Fatalf("response = %+v", resp)
+	}
+	want := []models.BulkItemResult{
+		{ID: 3, Success: true, Status: http.StatusOK},
+		{ID: 4, Status: http.StatusBadRequest, Error: "Cannot delete a running migration"},
+		{ID: 5, Status: http.StatusNotFound, Error: "Migration not found"},
+	}
+	for i, result := range resp.Results {
+		if result != want[i] {
+			t.Errorf("results[%d] = %+v, want %+v", i, result, want[i])
+		}
+	}
+}
+
+func TestMigrationsBulkDeleteRequiresIDs(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	req := map[string]interface{}{"ids": []int64{}}
+	status, body := serve(t, "POST", "/migrations/bulk/delete", "/migrations/bulk/delete", req, NewMigrationsHandler(store).BulkDelete)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestMigrationsBulkStartNotPending(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT id, connection_id, source_database, target_project, config, status`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "source_database", "target_project", "config", "status", "tables_count"}).
+			AddRow(5, "AdventureWorks", "aw_dbt", nil, "completed", 3))
+
+	req := map[string]interface{}{"ids": []int64{5}}
+	status, body := serve(t, "POST", "/migrations/bulk/start", "/migrations/bulk/start", req, NewMigrationsHandler(store).BulkStart)
+	expectStatus(t, status, http.StatusOK, body)
+
+	resp := decodeBulkResponse(t, body)
+	if resp.Failed != 1 || resp.Results[0].Error != "Migration is not in pending status" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestConnectionsBulkTestNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	req := map[string]interface{}{"ids": []int64{4}}
+	status, body := serve(t, "POST", "/connections/bulk/test", "/connections/bulk/test", req, NewConnectionsHandler(store).BulkTest)
+	expectStatus(t, status, http.StatusOK, body)
+
+	resp := decodeBulkResponse(t, body)
+	if resp.Failed != 1 || resp.Results[0].Status != http.StatusNotFound {
+		t.Errorf("response = %+v", resp)
+	}
+}
@@ -8,9 +8,16 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/datamigrate-ai/backend/internal/aiservice"
 	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/quota"
+	"github.com/datamigrate-ai/backend/internal/security"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,6 +26,7 @@ type ChatHandler struct {
 	cfg           *config.Config
 	aiServiceURL  string
 	httpClient    *http.Client
+	outputFilter  *security.AIOutputFilter
 }
 
 // ChatMessage represents a chat message
@@ -32,12 +40,65 @@ type ChatRequest struct {
 	Message  string        `json:"message"`
 	History  []ChatMessage `json:"history,omitempty"`
 	Language string        `json:"language,omitempty"` // Language code: en, da, es, pt, no, sv, de
+	// Context is populated server-side from the user's own resources; client-supplied values are discarded
+	Context *ChatContext `json:"context,omitempty" swaggerignore:"true"`
+	// Tools the assistant may call are set server-side, like Context
+	Tools []ChatTool `json:"tools,omitempty" swaggerignore:"true"`
+	// MessageID is set server-side; the AI service reports the message's tokens under it
+	MessageID string `json:"message_id,omitempty" swaggerignore:"true"`
+}
+
+// ChatTool is a function the AI service may call, described by a JSON schema of its
+// arguments
+type ChatTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// ChatToolCall is a call of a ChatTool in the AI service's answer
+type ChatToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// ChatContext grounds the assistant in the authenticated user's resources
+type ChatContext struct {
+	Migrations   []ChatMigrationSummary  `json:"migrations"`
+	Connections  []ChatConnectionSummary `json:"connections"`
+	RecentErrors []string                `json:"recent_errors"`
+}
+
+// ChatMigrationSummary is the subset of a migration shared with the AI service
+type ChatMigrationSummary struct {
+	ID             int64   `db:"id" json:"id"`
+	Name           string  `db:"name" json:"name"`
+	Status         string  `db:"status" json:"status"`
+	Progress       int     `db:"progress" json:"progress"`
+	SourceDatabase string  `db:"source_database" json:"source_database"`
+	TablesCount    int     `db:"tables_count" json:"tables_count"`
+	Error          *string `db:"error" json:"error,omitempty"`
+}
+
+// ChatConnectionSummary is the subset of a connection shared with the AI service (no hosts or credentials)
+type ChatConnectionSummary struct {
+	Name         string `db:"name" json:"name"`
+	DBType       string `db:"db_type" json:"db_type"`
+	DatabaseName string `db:"database_name" json:"database_name"`
+	IsSource     bool   `db:"is_source" json:"is_source"`
 }
 
 // ChatResponse represents the chat response
 type ChatResponse struct {
-	Response string   `json:"response"`
-	Sources  []string `json:"sources,omitempty"`
+	Response string                `json:"response"`
+	Sources  []string              `json:"sources,omitempty"`
+	Usage    *aiservice.TokenUsage `json:"usage,omitempty"`
+	// MessageID identifies the message in the organization's AI token usage
+	MessageID string `json:"message_id,omitempty"`
+	// ToolCalls are read from the AI service and never returned; each becomes an Action
+	ToolCalls []ChatToolCall `json:"tool_calls,omitempty" swaggerignore:"true"`
+	// Actions wait for the user to confirm them with POST /chat/actions/{id}/confirm
+	Actions []models.ChatAction `json:"actions,omitempty"`
 }
 
 // NewChatHandler creates a new chat handler
@@ -50,15 +111,14 @@ func NewChatHandler(cfg *config.Config) *ChatHandler {
 	return &ChatHandler{
 		cfg:          cfg,
 		aiServiceURL: aiServiceURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient:   aiservice.NewHTTPClient(30 * time.Second),
+		outputFilter: security.NewAIOutputFilter(security.DefaultAIOutputFilterConfig()),
 	}
 }
 
 // Chat handles chat messages by proxying to the AI service
 // @Summary Send chat message
-// @Description Send a message to the AI support assistant
+// @Description Send a message to the AI support assistant. The assistant can propose actions, such as creating a draft migration, which only run once confirmed with POST /chat/actions/{id}/confirm.
 // @Tags chat
 // @Accept json
 // @Produce json
@@ -80,19 +140,179 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		return
 	}
 
-	// Try to proxy to AI service
-	response, err := h.proxyToAIService(req)
+	// Never trust context sent by the client - build it from the user's own resources
+	req.Context = nil
+	userID := middleware.GetUserID(c)
+
+	orgID := middleware.GetOrganizationID(c)
+	if !enforceQuota(c, orgID, quota.MetricChatMessages, 1) || !enforceQuota(c, orgID, quota.MetricAITokens, 0) {
+		return
+	}
+	if h.isContextGroundingEnabled(userID, orgID) {
+		req.Context = h.buildUserContext(userID, orgID)
+	}
+	req.Tools = chatTools
+	req.MessageID = newChatMessageID()
+
+	// Try to proxy to AI service; a pinned organization's messages and context only go
+	// to its data region's instance
+	start := time.Now()
+	var response *ChatResponse
+	aiURL, region, err := h.chatServiceURL(orgID)
+	if err == nil {
+		response, err = h.proxyToAIService(aiURL, req)
+	}
+	latency := time.Since(start)
 	if err != nil {
 		log.Printf("[Chat] AI service error, using fallback: %v", err)
 		// Fallback to local knowledge base response
 		response = h.getFallbackResponse(req.Message, req.Language)
 	}
 
+	// Tool calls only propose actions; nothing runs until the user confirms
+	if len(response.ToolCalls) > 0 {
+		actions, notes := proposeChatActions(db.DB, userID, orgID, response.ToolCalls)
+		response.Actions = actions
+		for _, note := range notes {
+			response.Response = strings.TrimSpace(response.Response + "\n\n" + note)
+		}
+		response.ToolCalls = nil
+	}
+
+	// Filter the AI output so grounded answers never echo secrets back to the browser
+	filtered := h.outputFilter.FilterOutput(response.Response)
+	if filtered.WasFiltered {
+		log.Printf("[Chat] Sensitive content filtered from AI response for user %d", userID)
+	}
+	response.Response = filtered.Filtered
+	response.MessageID = req.MessageID
+
+	h.recordInteraction(userID, orgID, region, req.Message, response, latency, err)
+
 	c.JSON(http.StatusOK, response)
 }
 
+// recordInteraction writes the exchange to the AI interaction audit trail
+func (h *ChatHandler) recordInteraction(userID, orgID int64, region, prompt string, response *ChatResponse, latency time.Duration, callErr error) {
+	interaction := &security.AIInteraction{
+		InteractionType: "chat",
+		UserID:          &userID,
+		Prompt:          prompt,
+		Response:        response.Response,
+		LatencyMs:       latency.Milliseconds(),
+		Status:          "success",
+		DataRegion:      region,
+	}
+
+	if orgID > 0 {
+		interaction.OrganizationID = &orgID
+	}
+
+	if response.Usage != nil {
+		interaction.PromptTokens = response.Usage.PromptTokens
+		interaction.CompletionTokens = response.Usage.CompletionTokens
+	} else {
+		interaction.PromptTokens = security.EstimateTokens(prompt)
+		interaction.CompletionTokens = security.EstimateTokens(response.Response)
+		interaction.TokensEstimated = true
+	}
+
+	if callErr != nil {
+		// The user received a fallback answer, so record why the AI service wasn't used
+		errMsg := callErr.Error()
+		interaction.Status = "error"
+		interaction.Error = &errMsg
+	}
+
+	if interaction.OrganizationID != nil {
+		recordUsage(*interaction.OrganizationID, quota.MetricChatMessages, 1)
+	}
+	if callErr == nil {
+		recordChatTokens(db.DB, response.MessageID, userID, orgID, interaction.PromptTokens, interaction.CompletionTokens)
+	}
+
+	security.GetAIAuditor().Record(interaction)
+}
+
+// isContextGroundingEnabled checks the organization's privacy setting for sharing resource context with the AI.
+// Grounding is opt-in, so users outside an organization never get it.
+func (h *ChatHandler) isContextGroundingEnabled(userID, orgID int64) bool {
+	if orgID == 0 {
+		return false
+	}
+	var enabled bool
+	err := db.DB.Get(&enabled, "SELECT COALESCE(ai_context_enabled, false) FROM organizations WHERE id = $1", orgID)
+	if err != nil {
+		log.Printf("[Chat] Failed to read AI context setting for user %d: %v", userID, err)
+		return false
+	}
+	return enabled
+}
+
+// buildUserContext collects the user's migrations, connections, and recent errors in the
+// organization for grounding
+func (h *ChatHandler) buildUserContext(userID, orgID int64) *ChatContext {
+	ctx := &ChatContext{
+		Migrations:   []ChatMigrationSummary{},
+		Connections:  []ChatConnectionSummary{},
+		RecentErrors: []string{},
+	}
+
+	if err := db.DB.Select(&ctx.Migrations, `
+		SELECT id, name, status, progress, COALESCE(source_database, '') as source_database,
+		       tables_count, error
+		FROM migrations
+		WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2
+		ORDER BY updated_at DESC
+		LIMIT 10
+	`, userID, orgID); err != nil {
+		log.Printf("[Chat] Failed to load migrations for context: %v", err)
+	}
+
+	if err := db.DB.Select(&ctx.Connections, `
+		SELECT name, db_type, database_name, is_source
+		FROM database_connections
+		WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2
+		ORDER BY created_at DESC
+		LIMIT 10
+	`, userID, orgID); err != nil {
+		log.Printf("[Chat] Failed to load connections for context: %v", err)
+	}
+
+	// Error messages can contain connection strings, credentials, or sample values, so filter them first
+	masker := security.NewPIIMasker(nil)
+	if m, err := security.LoadPIIMasker(orgID); err == nil {
+		masker = m
+	}
+	for i := range ctx.Migrations {
+		if ctx.Migrations[i].Error == nil {
+			continue
+		}
+		filtered := masker.MaskText(h.outputFilter.FilterOutput(*ctx.Migrations[i].Error).Filtered)
+		ctx.Migrations[i].Error = &filtered
+		if len(ctx.RecentErrors) < 5 {
+			ctx.RecentErrors = append(ctx.RecentErrors, fmt.Sprintf("%s: %s", ctx.Migrations[i].Name, filtered))
+		}
+	}
+
+	return ctx
+}
+
+// chatServiceURL returns the URL of the AI service instance to chat with and its data
+// region: the organization's region when it is pinned to one, else the default instance
+func (h *ChatHandler) chatServiceURL(orgID int64) (string, string, error) {
+	aiClient, err := organizationAIClient(db.DB, orgID)
+	if err != nil {
+		return "", "", err
+	}
+	if aiClient == nil || aiClient.Region() == "" {
+		return h.aiServiceURL, "", nil
+	}
+	return aiClient.BaseURL(), aiClient.Region(), nil
+}
+
 // proxyToAIService forwards the request to the Python AI service
-func (h *ChatHandler) proxyToAIService(req ChatRequest) (*ChatResponse, error) {
+func (h *ChatHandler) proxyToAIService(aiServiceURL string, req ChatRequest) (*ChatResponse, error) {
 	// Marshal request
 	jsonBody, err := json.Marshal(req)
 	if err != nil {
@@ -101,7 +321,7 @@ func (h *ChatHandler) proxyToAIService(req ChatRequest) (*ChatResponse, error) {
 	}
 
 	// Create request to AI service
-	aiURL := h.aiServiceURL + "/chat"
+	aiURL := aiServiceURL + "/chat"
 	log.Printf("[Chat] Proxying to AI service: %s", aiURL)
 	aiReq, err := http.NewRequest("POST", aiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
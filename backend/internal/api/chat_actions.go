@@ -0,0 +1,397 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// chatActionTTL is how long a proposed action can be confirmed
+const chatActionTTL = 15 * time.Minute
+
+// draftSchemaListTimeout bounds listing the tables of a schema when a draft is confirmed
+const draftSchemaListTimeout = 60 * time.Second
+
+const toolCreateDraftMigration = "create_draft_migration"
+
+// chatTools are the tools the assistant may call. A call only proposes an action; it
+// runs when the user confirms it.
+var chatTools = []ChatTool{
+	{
+		Name: toolCreateDraftMigration,
+		Description: "Prepare a draft migration from one of the user's source connections to a warehouse connection. " +
+			"The user must confirm it before it is created, and it isn't started.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name":              map[string]interface{}{"type": "string", "description": "Migration name"},
+				"source_connection": map[string]interface{}{"type": "string", "description": "Name of the source connection"},
+				"target_connection": map[string]interface{}{"type": "string", "description": "Name of the warehouse connection to generate for"},
+				"schema":            map[string]interface{}{"type": "string", "description": "Source schema whose tables are migrated"},
+				"tables": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "schema.table names to migrate instead of a whole schema",
+				},
+			},
+			"required": []string{"source_connection"},
+		},
+	},
+}
+
+// draftMigrationCall is the arguments of a create_draft_migration call
+type draftMigrationCall struct {
+	Name             string   `json:"name"`
+	SourceConnection string   `json:"source_connection"`
+	TargetConnection string   `json:"target_connection"`
+	Schema           string   `json:"schema"`
+	Tables           []string `json:"tables"`
+}
+
+// chatConnection is a connection a tool call names
+type chatConnection struct {
+	ID     int64  `db:"id"`
+	Name   string `db:"name"`
+	DBType string `db:"db_type"`
+}
+
+// proposeChatActions stores the assistant's tool calls as pending actions. Calls that
+// can't be prepared become notes for the user instead.
+func proposeChatActions(store db.Querier, userID, orgID int64, calls []ChatToolCall) ([]models.ChatAction, []string) {
+	actions := []models.ChatAction{}
+	var notes []string
+	for _, call := range calls {
+		if call.Name != toolCreateDraftMigration {
+			log.Printf("[Chat] Ignoring unknown tool call %q for user %d", call.Name, userID)
+			continue
+		}
+		var args draftMigrationCall
+		if err := json.Unmarshal(call.Arguments, &args); err != nil {
+			notes = append(notes, "I couldn't prepare the migration: the request was incomplete.")
+			continue
+		}
+		draft, summary, err := prepareDraftMigration(store, userID, orgID, args)
+		if err != nil {
+			notes = append(notes, "I couldn't prepare the migration: "+err.Error())
+			continue
+		}
+
+		action := models.ChatAction{Tool: toolCreateDraftMigration, Summary: summary, Status: "pending"}
+		action.Arguments, _ = json.Marshal(draft)
+		err = store.QueryRow(`
+			INSERT INTO chat_actions (user_id, organization_id, tool, arguments, summary, expires_at)
+			VALUES ($1, NULLIF($2, 0), $3, $4, $5, NOW() + $6 * INTERVAL '1 second')
+			RETURNING id, created_at, expires_at
+		`, userID, orgID, action.Tool, string(action.Arguments), summary, int(chatActionTTL.Seconds())).Scan(&action.ID, &action.CreatedAt, &action.ExpiresAt)
+		if err != nil {
+			log.Printf("[Chat] Failed to save proposed action for user %d: %v", userID, err)
+			notes = append(notes, "I couldn't prepare the migration, please try again.")
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, notes
+}
+
+// prepareDraftMigration resolves the connections a create_draft_migration call names
+// and describes what confirming it does. Its errors are shown to the user.
+func prepareDraftMigration(store db.Querier, userID, orgID int64, args draftMigrationCall) (models.DraftMigration, string, error) {
+	var draft models.DraftMigration
+	source, err := findChatConnection(store, args.SourceConnection, userID, orgID)
+	if err != nil {
+		return draft, "", err
+	}
+	draft.SourceConnectionID = source.ID
+	draft.SourceConnection = source.Name
+
+	target := "the organization's default warehouse"
+	if strings.TrimSpace(args.TargetConnection) != "" {
+		conn, err := findChatConnection(store, args.TargetConnection, userID, orgID)
+		if err != nil {
+			return draft, "", err
+		}
+		if _, ok := dbtAdapters[conn.DBType]; !ok {
+			return draft, "", fmt.Errorf("%s isn't a warehouse connection", conn.Name)
+		}
+		draft.TargetConnectionID = &conn.ID
+		draft.TargetConnection = conn.Name
+		target = fmt.Sprintf("%s (%s)", conn.Name, conn.DBType)
+	}
+
+	draft.Schema = strings.TrimSpace(args.Schema)
+	for _, table := range args.Tables {
+		table = strings.TrimSpace(table)
+		if table == "" {
+			continue
+		}
+		if !strings.Contains(table, ".") && draft.Schema != "" {
+			table = draft.Schema + "." + table
+		}
+		draft.Tables = append(draft.Tables, table)
+	}
+	selection := "all tables"
+	switch {
+	case len(draft.Tables) > 0:
+		selection = strings.Join(draft.Tables, ", ")
+	case draft.Schema != "":
+		selection = "all tables in schema " + draft.Schema
+	}
+
+	draft.Name = strings.TrimSpace(args.Name)
+	if len(draft.Name) < 3 {
+		from := draft.SourceConnection
+		if draft.Schema != "" {
+			from = draft.Schema
+		}
+		to := draft.TargetConnection
+		if to == "" {
+			to = "warehouse"
+		}
+		draft.Name = from + " to " + to
+	}
+	draft.TargetProject = draftProjectName(draft.Name)
+
+	summary := fmt.Sprintf("Create the draft migration %q from %s (%s) for %s. It won't start until you start it.",
+		draft.Name, draft.SourceConnection, selection, target)
+	return draft, summary, nil
+}
+
+// findChatConnection finds one of the user's connections in the organization by name,
+// ignoring case
+func findChatConnection(store db.Querier, name string, userID, orgID int64) (chatConnection, error) {
+	var conn chatConnection
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return conn, fmt.Errorf("a source connection is required")
+	}
+	err := store.Get(&conn, `
+		SELECT id, name, db_type
+		FROM database_connections
+		WHERE LOWER(name) = LOWER($1) AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+		ORDER BY id LIMIT 1
+	`, name, userID, orgID)
+	if err == sql.ErrNoRows {
+		return conn, fmt.Errorf("there's no connection named %q", name)
+	}
+	if err != nil {
+		log.Printf("[Chat] Failed to look up connection %q for user %d: %v", name, userID, err)
+		return conn, fmt.Errorf("the connection %q couldn't be looked up", name)
+	}
+	return conn, nil
+}
+
+var projectNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// draftProjectName derives a dbt project name from a migration name, e.g. sales_to_snowflake_prod
+func draftProjectName(name string) string {
+	project := strings.Trim(projectNameInvalid.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if project == "" || project[0] < 'a' || project[0] > 'z' {
+		project = "migration_" + project
+	}
+	return project
+}
+
+// ChatActionsHandler confirms and cancels actions the chat assistant proposed
+type ChatActionsHandler struct {
+	db db.Querier
+}
+
+func NewChatActionsHandler(store db.Querier) *ChatActionsHandler {
+	return &ChatActionsHandler{db: store}
+}
+
+// Confirm runs a pending action
+// @Summary Confirm a chat action
+// @Description Run an action the chat assistant proposed. create_draft_migration creates a pending migration; it isn't started. An action runs at most once and expires 15 minutes after it was proposed.
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Action ID"
+// @Success 201 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /chat/actions/{id}/confirm [post]
+func (h *ChatActionsHandler) Confirm(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	// Claim the action first, so confirming twice can't create two migrations
+	var action models.ChatAction
+	err = h.db.Get(&action, `
+		UPDATE chat_actions SET status = 'confirmed', resolved_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+		  AND status = 'pending' AND expires_at > NOW()
+		RETURNING id, tool, arguments, summary, status, migration_id, error, created_at, expires_at
+	`, id, userID, orgID)
+	if err == sql.ErrNoRows {
+		h.unclaimable(c, id, userID, orgID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm action"})
+		return
+	}
+
+	var draft models.DraftMigration
+	if err := json.Unmarshal(action.Arguments, &draft); err != nil || action.Tool != toolCreateDraftMigration {
+		h.fail(c, &action, http.StatusInternalServerError, "Action can't be run")
+		return
+	}
+	migrationID, status, message := h.createDraftMigration(c.Request.Context(), draft, userID, orgID)
+	if message != "" {
+		h.fail(c, &action, status, message)
+		return
+	}
+
+	action.MigrationID = &migrationID
+	if _, err := h.db.Exec("UPDATE chat_actions SET migration_id = $1 WHERE id = $2", migrationID, action.ID); err != nil {
+		log.Printf("[Chat] Failed to link action %d to migration %d: %v", action.ID, migrationID, err)
+	}
+
+	var migration models.Migration
+	if err := h.db.Get(&migration, `
+		SELECT id, name, status, progress, connection_id, source_database, target_project,
+		       tables_count, user_id, created_at, updated_at
+		FROM migrations WHERE id = $1
+	`, migrationID); err != nil {
+		log.Printf("[Chat] Failed to fetch migration %d created by action %d: %v", migrationID, action.ID, err)
+	}
+	c.JSON(http.StatusCreated, gin.H{"action": action, "migration": migration})
+}
+
+// Cancel discards a pending action
+// @Summary Cancel a chat action
+// @Tags chat
+// @Security BearerAuth
+// @Param id path int true "Action ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /chat/actions/{id}/cancel [post]
+func (h *ChatActionsHandler) Cancel(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE chat_actions SET status = 'cancelled', resolved_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3 AND status = 'pending'
+	`, id, userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel action"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending action not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// unclaimable explains why an action can't be confirmed
+func (h *ChatActionsHandler) unclaimable(c *gin.Context, id, userID, orgID int64) {
+	var status string
+	err := h.db.Get(&status, `
+		SELECT status FROM chat_actions WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Action not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm action"})
+	case status == "pending":
+		c.JSON(http.StatusConflict, gin.H{"error": "Action expired; ask the assistant again"})
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": "Action is already " + status})
+	}
+}
+
+// fail records why a confirmed action didn't run
+func (h *ChatActionsHandler) fail(c *gin.Context, action *models.ChatAction, status int, message string) {
+	if _, err := h.db.Exec("UPDATE chat_actions SET status = 'failed', error = $1 WHERE id = $2", message, action.ID); err != nil {
+		log.Printf("[Chat] Failed to record failure of action %d: %v", action.ID, err)
+	}
+	c.JSON(status, gin.H{"error": message})
+}
+
+// createDraftMigration creates the pending migration of a confirmed draft. It returns an
+// HTTP status and message when it fails.
+func (h *ChatActionsHandler) createDraftMigration(ctx context.Context, draft models.DraftMigration, userID, orgID int64) (int64, int, string) {
+	// The connections may have been deleted since the action was proposed
+	source, err := resolveSourceConnection(h.db, &draft.SourceConnectionID, "", userID, orgID)
+	if err != nil {
+		return 0, http.StatusConflict, "Source connection not found"
+	}
+	if draft.TargetConnectionID != nil {
+		if _, err := resolveSourceConnection(h.db, draft.TargetConnectionID, "", userID, orgID); err != nil {
+			return 0, http.StatusConflict, "Target connection not found"
+		}
+	}
+
+	tables := draft.Tables
+	if len(tables) == 0 && draft.Schema != "" {
+		var status int
+		var message string
+		if tables, status, message = h.listSchemaTables(ctx, source.ID, draft.Schema); message != "" {
+			return 0, status, message
+		}
+	}
+	tablesCount := len(tables)
+	if tablesCount == 0 {
+		tablesCount = 1 // Default if no tables specified
+	}
+
+	config, err := json.Marshal(models.MigrationConfig{Tables: tables, TargetConnectionID: draft.TargetConnectionID})
+	if err != nil {
+		return 0, http.StatusInternalServerError, "Failed to create migration"
+	}
+	migrationID, err := insertPendingMigration(h.db, draft.Name, source, draft.TargetProject, tablesCount, userID, orgID, config)
+	if err != nil {
+		return 0, http.StatusInternalServerError, "Failed to create migration"
+	}
+	return migrationID, 0, ""
+}
+
+// listSchemaTables lists the tables of a source schema as schema.table
+func (h *ChatActionsHandler) listSchemaTables(ctx context.Context, connectionID int64, schema string) ([]string, int, string) {
+	connection, err := loadConnectionByID(h.db, sql.NullInt64{Int64: connectionID, Valid: true})
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to fetch source connection"
+	}
+	metadata := dbtest.ExtractMetadataContext(ctx, connection.params(), dbtest.ExtractOptions{Timeout: draftSchemaListTimeout})
+	if !metadata.Success {
+		return nil, http.StatusBadGateway, "Failed to list the tables of schema " + schema + ": " + metadata.Error
+	}
+	var tables []string
+	for _, table := range metadata.Tables {
+		if strings.EqualFold(table.Schema, schema) {
+			tables = append(tables, table.Schema+"."+table.Name)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, http.StatusConflict, fmt.Sprintf("Schema %s has no tables in %s", schema, connection.Name)
+	}
+	return tables, 0, ""
+}
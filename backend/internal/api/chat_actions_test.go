@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func TestProposeDraftMigration(t *testing.T) {
+	store, mock := newMockDB(t)
+	created := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM database_connections\s+WHERE LOWER\(name\) = LOWER\(\$1\)`).
+		WithArgs("contoso", testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "db_type"}).AddRow(4, "Contoso", "mssql"))
+	mock.ExpectQuery(`FROM database_connections\s+WHERE LOWER\(name\) = LOWER\(\$1\)`).
+		WithArgs("Snowflake prod", testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "db_type"}).AddRow(9, "Snowflake Prod", "snowflake"))
+	mock.ExpectQuery(`INSERT INTO chat_actions`).
+		WithArgs(testUserID, testOrgID, toolCreateDraftMigration, sqlmock.AnyArg(), sqlmock.AnyArg(), 900).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "expires_at"}).AddRow(11, created, created.Add(chatActionTTL)))
+
+	actions, notes := proposeChatActions(store, testUserID, testOrgID, []ChatToolCall{
+		{Name: toolCreateDraftMigration, Arguments: json.RawMessage(`{"source_connection":"contoso","target_connection":"Snowflake prod","schema":"Sales"}`)},
+		{Name: "drop_database", Arguments: json.RawMessage(`{}`)},
+	})
+	if len(notes) > 0 || len(actions) != 1 {
+		t.Fatalf("actions = %+v, notes = %v", actions, notes)
+	}
+	want := `Create the draft migration "Sales to Snowflake Prod" from Contoso (all tables in schema Sales) for Snowflake Prod (snowflake). It won't start until you start it.`
+	if actions[0].ID != 11 || actions[0].Status != "pending" || actions[0].Summary != want {
+		t.Errorf("action = %+v", actions[0])
+	}
+
+	var draft models.DraftMigration
+	if err := json.Unmarshal(actions[0].Arguments, &draft); err != nil {
+		t.Fatal(err)
+	}
+	if draft.SourceConnectionID != 4 || draft.TargetConnectionID == nil || *draft.TargetConnectionID != 9 || draft.TargetProject != "sales_to_snowflake_prod" {
+		t.Errorf("draft = %+v", draft)
+	}
+}
+
+func TestProposeDraftMigrationUnknownConnection(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections`).
+		WithArgs("Fabrikam", testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "db_type"}))
+
+	actions, notes := proposeChatActions(store, testUserID, testOrgID, []ChatToolCall{
+		{Name: toolCreateDraftMigration, Arguments: json.RawMessage(`{"source_connection":"Fabrikam"}`)},
+	})
+	if len(actions) != 0 || len(notes) != 1 || !strings.Contains(notes[0], `there's no connection named "Fabrikam"`) {
+		t.Errorf("actions = %+v, notes = %v", actions, notes)
+	}
+}
+
+func TestConfirmChatActionCreatesDraft(t *testing.T) {
+	store, mock := newMockDB(t)
+	created := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	args := `{"name":"Orders to Snowflake","target_project":"orders_to_snowflake","source_connection_id":4,"source_connection":"Contoso","tables":["Sales.Orders"]}`
+
+	mock.ExpectQuery(`UPDATE chat_actions SET status = 'confirmed'`).
+		WithArgs(int64(11), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tool", "arguments", "summary", "status", "migration_id", "error", "created_at", "expires_at"}).
+			AddRow(11, toolCreateDraftMigration, []byte(args), "Create the draft migration", "confirmed", nil, nil, created, created.Add(chatActionTTL)))
+	mock.ExpectQuery(`FROM database_connections WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id"}).AddRow(4, "Contoso", testOrgID))
+	mock.ExpectQuery(`INSERT INTO migrations`).
+		WithArgs("Orders to Snowflake", int64(4), "Contoso", "orders_to_snowflake", 1, testUserID, testOrgID, `{"tables":["Sales.Orders"]}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(21))
+	mock.ExpectExec(`UPDATE chat_actions SET migration_id = \$1`).
+		WithArgs(int64(21), int64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM migrations WHERE id = \$1`).
+		WithArgs(int64(21)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status"}).AddRow(21, "Orders to Snowflake", "pending"))
+
+	status, body := serve(t, "POST", "/chat/actions/:id/confirm", "/chat/actions/11/confirm", nil, NewChatActionsHandler(store).Confirm)
+	expectStatus(t, status, http.StatusCreated, body)
+
+	var resp struct {
+		Action    models.ChatAction `json:"action"`
+		Migration models.Migration  `json:"migration"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response is not JSON: %s", body)
+	}
+	if resp.Action.MigrationID == nil || *resp.Action.MigrationID != 21 || resp.Migration.Status != "pending" {
+		t.Errorf("response = %s", body)
+	}
+}
+
+func TestConfirmChatActionTwice(t *testing.T) {
+	for _, tc := range []struct {
+		status string
+		want   string
+	}{
+		{"confirmed", "Action is already confirmed"},
+		{"pending", "Action expired; ask the assistant again"},
+	} {
+		t.Run(tc.status, func(t *testing.T) {
+			store, mock := newMockDB(t)
+			mock.ExpectQuery(`UPDATE chat_actions SET status = 'confirmed'`).
+				WithArgs(int64(11), testUserID, testOrgID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(`SELECT status FROM chat_actions`).
+				WithArgs(int64(11), testUserID, testOrgID).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(tc.status))
+
+			status, body := serve(t, "POST", "/chat/actions/:id/confirm", "/chat/actions/11/confirm", nil, NewChatActionsHandler(store).Confirm)
+			expectStatus(t, status, http.StatusConflict, body)
+			if got := errorMessage(t, body); got != tc.want {
+				t.Errorf("error = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCancelChatActionNotPending(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE chat_actions SET status = 'cancelled'`).
+		WithArgs(int64(11), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	status, body := serve(t, "POST", "/chat/actions/:id/cancel", "/chat/actions/11/cancel", nil, NewChatActionsHandler(store).Cancel)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
@@ -0,0 +1,10 @@
+package api
+
+import "testing"
+
+func TestContextGroundingOffWithoutOrganization(t *testing.T) {
+	h := &ChatHandler{}
+	if h.isContextGroundingEnabled(testUserID, 0) {
+		t.Error("grounding is on for a user outside an organization")
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type CommentsHandler struct {
+	db db.Querier
+}
+
+func NewCommentsHandler(store db.Querier) *CommentsHandler {
+	return &CommentsHandler{db: store}
+}
+
+// migrationID parses the migration ID and checks the user owns it
+func (h *CommentsHandler) migrationID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return 0, false
+	}
+
+	var owned int64
+	err = h.db.Get(&owned, "SELECT id FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, middleware.GetUserID(c), middleware.GetOrganizationID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return 0, false
+	}
+	return id, true
+}
+
+// GetAll lists a migration's comments
+// @Summary List migration comments
+// @Description List comments on a migration, oldest first
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {array} models.MigrationComment
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/comments [get]
+func (h *CommentsHandler) GetAll(c *gin.Context) {
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	comments := []models.MigrationComment{}
+	err := h.db.Select(&comments, `
+		SELECT id, migration_id, user_id, body, created_at
+		FROM migration_comments
+		WHERE migration_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// Create adds a comment to a migration
+// @Summary Comment on a migration
+// @Description Add a comment to a migration
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.CreateCommentRequest true "Comment"
+// @Success 201 {object} models.MigrationComment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/comments [post]
+func (h *CommentsHandler) Create(c *gin.Context) {
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment body is required"})
+		return
+	}
+
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	var comment models.MigrationComment
+	err := h.db.Get(&comment, `
+		INSERT INTO migration_comments (migration_id, user_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, migration_id, user_id, body, created_at
+	`, id, middleware.GetUserID(c), req.Body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// Delete removes one of the user's comments
+// @Summary Delete a migration comment
+// @Description Delete a comment you wrote on a migration
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param commentId path int true "Comment ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/comments/{commentId} [delete]
+func (h *CommentsHandler) Delete(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.db.Exec(
+		"DELETE FROM migration_comments WHERE id = $1 AND migration_id = $2 AND user_id = $3",
+		commentID, id, middleware.GetUserID(c),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
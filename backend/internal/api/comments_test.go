@@ -0,0 +1,49 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCommentsCreate(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT id FROM migrations WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`INSERT INTO migration_comments`).
+		WithArgs(int64(3), testUserID, "Check the SalesOrderHeader snapshot").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "migration_id", "user_id", "body", "created_at"}).
+			AddRow(1, 3, testUserID, "Check the SalesOrderHeader snapshot", time.Now()))
+
+	req := map[string]string{"body": "  Check the SalesOrderHeader snapshot "}
+	status, body := serve(t, "POST", "/migrations/:id/comments", "/migrations/3/comments", req, NewCommentsHandler(store).Create)
+	expectStatus(t, status, http.StatusCreated, body)
+}
+
+func TestCommentsCreateMigrationNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT id FROM migrations`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	req := map[string]string{"body": "Looks good"}
+	status, body := serve(t, "POST", "/migrations/:id/comments", "/migrations/3/comments", req, NewCommentsHandler(store).Create)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestCommentsDeleteOthersComment(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT id FROM migrations`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec(`DELETE FROM migration_comments WHERE id = \$1 AND migration_id = \$2 AND user_id = \$3`).
+		WithArgs(int64(8), int64(3), testUserID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	status, body := serve(t, "DELETE", "/migrations/:id/comments/:commentId", "/migrations/3/comments/8", nil, NewCommentsHandler(store).Delete)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ownedConnection is the part of a connection its dependents are matched on
+type ownedConnection struct {
+	ID             int64  `db:"id"`
+	Name           string `db:"name"`
+	OrganizationID int64  `db:"organization_id"`
+}
+
+func getOwnedConnection(store db.Querier, id, userID, orgID int64) (ownedConnection, error) {
+	var conn ownedConnection
+	err := store.Get(&conn, `
+		SELECT id, name, COALESCE(organization_id, 0) AS organization_id
+		FROM database_connections WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
+	return conn, err
+}
+
+// resolveSourceConnection finds the source connection of a new migration in the
+// organization it's created in: by ID, or by name for clients that still send one
+func resolveSourceConnection(store db.Querier, id *int64, name string, userID, orgID int64) (ownedConnection, error) {
+	var conn ownedConnection
+	if id != nil {
+		err := store.Get(&conn, `
+			SELECT id, name, COALESCE(organization_id, 0) AS organization_id
+			FROM database_connections WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+		`, *id, userID, orgID)
+		return conn, err
+	}
+	err := store.Get(&conn, `
+		SELECT id, name, COALESCE(organization_id, 0) AS organization_id
+		FROM database_connections WHERE name = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+		ORDER BY id LIMIT 1
+	`, name, userID, orgID)
+	return conn, err
+}
+
+// listConnectionDependents returns the user's migrations that read from the connection or
+// generate for it, newest first
+func listConnectionDependents(store db.Querier, conn ownedConnection, userID int64) ([]models.ConnectionDependent, error) {
+	dependents := []models.ConnectionDependent{}
+	err := store.Select(&dependents, `
+		SELECT id, name, status,
+		       COALESCE(connection_id = $1, false) AS as_source,
+		       COALESCE(config->>'target_connection_id' = $1::text, false) AS as_target,
+		       created_at
+		FROM migrations
+		WHERE user_id = $2 AND COALESCE(organization_id, 0) = $3
+		  AND (connection_id = $1 OR config->>'target_connection_id' = $1::text)
+		ORDER BY created_at DESC
+	`, conn.ID, userID, conn.OrganizationID)
+	return dependents, err
+}
+
+// activeDependents filters dependents to the migrations that still need the connection
+func activeDependents(dependents []models.ConnectionDependent) (active []models.ConnectionDependent, running bool) {
+	active = []models.ConnectionDependent{}
+	for _, d := range dependents {
+		switch d.Status {
+		case "running":
+			running = true
+			active = append(active, d)
+		case "pending", "queued":
+			active = append(active, d)
+		}
+	}
+	return active, running
+}
+
+// GetDependents lists the migrations that use a connection
+// @Summary List connection dependents
+// @Description List the migrations that read from the connection (as_source) or generate for its warehouse (as_target)
+// @Tags connections
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Connection ID"
+// @Success 200 {array} models.ConnectionDependent
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /connections/{id}/dependents [get]
+func (h *ConnectionsHandler) GetDependents(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
+		return
+	}
+
+	conn, err := getOwnedConnection(h.db, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection"})
+		return
+	}
+
+	dependents, err := listConnectionDependents(h.db, conn, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dependents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dependents)
+}
+
+// deleteRepointingDependents moves the connection's migrations that aren't running to
+// replacement and deletes the connection, in one transaction
+func (h *ConnectionsHandler) deleteRepointingDependents(conn, replacement ownedConnection, userID int64) error {
+	tx, err := h.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE migrations SET connection_id = $1, source_database = $2, updated_at = NOW()
+		WHERE connection_id = $3 AND user_id = $4 AND COALESCE(organization_id, 0) = $5 AND status <> 'running'
+	`, replacement.ID, replacement.Name, conn.ID, userID, conn.OrganizationID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE migrations SET config = jsonb_set(config, '{target_connection_id}', to_jsonb($1::bigint)), updated_at = NOW()
+		WHERE config->>'target_connection_id' = $2::text AND user_id = $3 AND COALESCE(organization_id, 0) = $4 AND status <> 'running'
+	`, replacement.ID, conn.ID, userID, conn.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM database_connections WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", conn.ID, userID, conn.OrganizationID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
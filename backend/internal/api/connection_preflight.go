@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/gin-gonic/gin"
+)
+
+// startPreflightBudget is how long a migration start waits for its source database to
+// accept the login
+const startPreflightBudget = 5 * time.Second
+
+// preflightSourceConnection checks that the source database accepts the connection's
+// login before the migration starts, so stale credentials fail the start instead of
+// the AI service minutes later. The result goes to the connection's test history.
+// Database types the backend can't connect to itself are left to the AI service.
+func (h *MigrationsHandler) preflightSourceConnection(connection *sourceConnection) *actionError {
+	if !dbtest.CanTest(connection.DBType) {
+		return nil
+	}
+	result := dbtest.QuickTest(connection.params(), startPreflightBudget)
+	recordConnectionTest(h.db, connection.ID, result)
+	if result.Success {
+		return nil
+	}
+	log.Printf("Source connection %d failed the start check: %s", connection.ID, result.Message)
+	return preflightFailure(connection.ID, connection.Name, result)
+}
+
+// preflightFailure is the start error for a failed source connection check, saying what
+// to fix
+func preflightFailure(connectionID int64, name string, result dbtest.TestResult) *actionError {
+	return &actionError{Status: http.StatusUnprocessableEntity, Body: gin.H{
+		"error":         fmt.Sprintf("Source connection %q failed its check: %s", name, connectionTestHint(result.Message)),
+		"details":       result.Message,
+		"connection_id": connectionID,
+		"latency_ms":    result.Latency,
+	}}
+}
+
+// connectionTestHint turns a driver's connection error into what the user should check
+func connectionTestHint(message string) string {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "login failed"),
+		strings.Contains(msg, "password authentication failed"),
+		strings.Contains(msg, "login error"),
+		strings.Contains(msg, "aadsts"):
+		return "the database rejected its credentials. Update the connection's username and password, then start again."
+	case strings.Contains(msg, "cannot open database"),
+		strings.Contains(msg, "does not exist"):
+		return "the database or login doesn't exist on the server. Check the connection's database name and username."
+	case strings.Contains(msg, "timeout"),
+		strings.Contains(msg, "deadline exceeded"),
+		strings.Contains(msg, "connection refused"),
+		strings.Contains(msg, "no such host"),
+		strings.Contains(msg, "unreachable"):
+		return fmt.Sprintf("the server didn't answer within %s. Check the host, port and firewall rules.", startPreflightBudget)
+	}
+	return "the server refused the connection. Test the connection for details."
+}
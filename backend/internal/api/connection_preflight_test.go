@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+)
+
+func TestConnectionTestHint(t *testing.T) {
+	for _, tc := range []struct {
+		message string
+		want    string
+	}{
+		{"Connection failed: mssql: login error: Login failed for user 'etl'.", "rejected its credentials"},
+		{`Connection failed: pq: password authentication failed for user "etl"`, "rejected its credentials"},
+		{`Connection failed: pq: database "sales" does not exist`, "doesn't exist"},
+		{"Connection failed: dial tcp 10.0.0.5:1433: i/o timeout", "didn't answer within 5s"},
+		{"Connection failed: dial tcp 10.0.0.5:5432: connect: connection refused", "didn't answer within 5s"},
+		{"Connection failed: TLS handshake failed", "Test the connection for details"},
+	} {
+		if got := connectionTestHint(tc.message); !strings.Contains(got, tc.want) {
+			t.Errorf("connectionTestHint(%q) = %q, want it to contain %q", tc.message, got, tc.want)
+		}
+	}
+}
+
+func TestPreflightFailure(t *testing.T) {
+	failure := preflightFailure(4, "AdventureWorks", dbtest.TestResult{
+		Message: "Connection failed: mssql: login error: Login failed for user 'etl'.",
+		Latency: 120,
+	})
+	if failure.Status != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", failure.Status)
+	}
+	if msg, _ := failure.Body["error"].(string); !strings.HasPrefix(msg, `Source connection "AdventureWorks" failed its check: the database rejected its credentials`) {
+		t.Errorf("error = %q", msg)
+	}
+	if failure.Body["connection_id"] != int64(4) {
+		t.Errorf("connection_id = %v, want 4", failure.Body["connection_id"])
+	}
+}
+
+func TestPreflightSkipsUntestableTypes(t *testing.T) {
+	store, _ := newMockDB(t)
+	h := NewMigrationsHandler(store)
+	if failure := h.preflightSourceConnection(&sourceConnection{ID: 4, DBType: "snowflake"}); failure != nil {
+		t.Errorf("preflight = %+v, want it skipped", failure)
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Connection test history is kept for maxConnectionTestDays and listed in pages of at
+// most maxConnectionTests
+const (
+	defaultConnectionTestDays = 30
+	maxConnectionTestDays     = 90
+	defaultConnectionTests    = 50
+	maxConnectionTests        = 500
+)
+
+// recordConnectionTest adds a test result to the connection's history and drops tests
+// older than the history keeps
+func recordConnectionTest(store db.Querier, connectionID int64, result dbtest.TestResult) {
+	_, err := store.Exec(`
+		INSERT INTO connection_test_history (connection_id, success, latency_ms, message)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+	`, connectionID, result.Success, result.Latency, result.Message)
+	if err != nil {
+		log.Printf("Failed to record test of connection %d: %v", connectionID, err)
+		return
+	}
+	_, err = store.Exec(`
+		DELETE FROM connection_test_history
+		WHERE connection_id = $1 AND tested_at < NOW() - make_interval(days => $2)
+	`, connectionID, maxConnectionTestDays)
+	if err != nil {
+		log.Printf("Failed to prune test history of connection %d: %v", connectionID, err)
+	}
+}
+
+const connectionTestStatsColumns = `COUNT(*) AS tests,
+	COUNT(*) FILTER (WHERE NOT success) AS failures,
+	AVG(latency_ms) FILTER (WHERE success)::float8 AS avg_latency_ms,
+	(percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE success))::float8 AS p95_latency_ms,
+	MAX(latency_ms) FILTER (WHERE success) AS max_latency_ms`
+
+// GetTests returns a connection's test history and latency trend
+// @Summary Get connection test history
+// @Description Results of the connection's recent tests, with its failure count and latency (average, p95, max) over the period and per UTC day. Tests are kept for 90 days.
+// @Tags connections
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Connection ID"
+// @Param days query int false "Days the summary and trend cover (default 30, max 90)"
+// @Param limit query int false "Most recent tests to list (default 50, max 500)"
+// @Success 200 {object} models.ConnectionTestHistory
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /connections/{id}/tests [get]
+func (h *ConnectionsHandler) GetTests(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
+		return
+	}
+
+	days := defaultConnectionTestDays
+	if d := c.Query("days"); d != "" {
+		days, err = strconv.Atoi(d)
+		if err != nil || days < 1 || days > maxConnectionTestDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+	}
+	limit := defaultConnectionTests
+	if l := c.Query("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxConnectionTests {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+	}
+
+	if _, err := getOwnedConnection(h.db, id, userID, orgID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection"})
+		return
+	}
+
+	history := models.ConnectionTestHistory{
+		ConnectionID: id,
+		Days:         days,
+		Daily:        []models.ConnectionTestStats{},
+		Tests:        []models.ConnectionTest{},
+	}
+
+	err = h.db.Get(&history.Summary, `
+		SELECT `+connectionTestStatsColumns+`
+		FROM connection_test_history
+		WHERE connection_id = $1 AND tested_at >= NOW() - make_interval(days => $2)
+	`, id, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection tests"})
+		return
+	}
+
+	err = h.db.Select(&history.Daily, `
+		SELECT date_trunc('day', tested_at AT TIME ZONE 'UTC') AS day, `+connectionTestStatsColumns+`
+		FROM connection_test_history
+		WHERE connection_id = $1 AND tested_at >= NOW() - make_interval(days => $2)
+		GROUP BY 1
+		ORDER BY 1
+	`, id, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection tests"})
+		return
+	}
+
+	err = h.db.Select(&history.Tests, `
+		SELECT id, success, latency_ms, message, tested_at
+		FROM connection_test_history
+		WHERE connection_id = $1
+		ORDER BY tested_at DESC, id DESC
+		LIMIT $2
+	`, id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection tests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+var connectionTestStatsColumnNames = []string{"tests", "failures", "avg_latency_ms", "p95_latency_ms", "max_latency_ms"}
+
+func TestConnectionsGetTests(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectOwnedConnection(mock, 4, "AdventureWorks")
+	mock.ExpectQuery(`FROM connection_test_history`).
+		WithArgs(int64(4), 7).
+		WillReturnRows(sqlmock.NewRows(connectionTestStatsColumnNames).AddRow(3, 1, 150.0, 195.0, 200))
+	day := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`GROUP BY 1`).
+		WithArgs(int64(4), 7).
+		WillReturnRows(sqlmock.NewRows(append([]string{"day"}, connectionTestStatsColumnNames...)).
+			AddRow(day, 2, 0, 150.0, 195.0, 200).
+			AddRow(day.AddDate(0, 0, 1), 1, 1, nil, nil, nil))
+	mock.ExpectQuery(`ORDER BY tested_at DESC`).
+		WithArgs(int64(4), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "success", "latency_ms", "message", "tested_at"}).
+			AddRow(12, false, 30000, "Connection failed: i/o timeout", day.AddDate(0, 0, 1)))
+
+	status, body := serve(t, "GET", "/connections/:id/tests", "/connections/4/tests?days=7&limit=10", nil, NewConnectionsHandler(store).GetTests)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var history models.ConnectionTestHistory
+	if err := json.Unmarshal(body, &history); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if history.Summary.Tests != 3 || history.Summary.Failures != 1 || *history.Summary.P95LatencyMs != 195 {
+		t.Errorf("summary = %+v", history.Summary)
+	}
+	if len(history.Daily) != 2 || history.Daily[1].AvgLatencyMs != nil {
+		t.Errorf("daily = %+v, want a second day without latency", history.Daily)
+	}
+	if len(history.Tests) != 1 || history.Tests[0].Success {
+		t.Errorf("tests = %+v", history.Tests)
+	}
+}
+
+func TestConnectionsGetTestsValidatesParams(t *testing.T) {
+	store, _ := newMockDB(t)
+	for _, query := range []string{"?days=0", "?days=91", "?limit=501", "?limit=x"} {
+		status, body := serve(t, "GET", "/connections/:id/tests", "/connections/4/tests"+query, nil, NewConnectionsHandler(store).GetTests)
+		expectStatus(t, status, http.StatusBadRequest, body)
+	}
+}
+
+func TestConnectionsGetTestsNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	status, body := serve(t, "GET", "/connections/:id/tests", "/connections/4/tests", nil, NewConnectionsHandler(store).GetTests)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestRecordConnectionTest(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`INSERT INTO connection_test_history`).
+		WithArgs(int64(4), true, int64(85), "Connection successful").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`DELETE FROM connection_test_history`).
+		WithArgs(int64(4), maxConnectionTestDays).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	recordConnectionTest(store, 4, dbtest.TestResult{Success: true, Message: "Connection successful", Latency: 85})
+}
@@ -2,15 +2,19 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
 	"log"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/datamigrate-ai/backend/internal/crypto"
 	"github.com/datamigrate-ai/backend/internal/db"
 	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/metacache"
 	"github.com/datamigrate-ai/backend/internal/middleware"
 	"github.com/datamigrate-ai/backend/internal/models"
 	"github.com/datamigrate-ai/backend/internal/security"
@@ -19,11 +23,16 @@ import (
 )
 
 type ConnectionsHandler struct {
+	db                db.Querier
 	encryptionService *crypto.EncryptionService
 	ipValidator       *security.IPValidator
+	metadataCache     metacache.Cache
 }
 
-func NewConnectionsHandler() *ConnectionsHandler {
+// metadataColumnsTimeout bounds metadata scans that fetch columns for every table
+const metadataColumnsTimeout = 5 * time.Minute
+
+func NewConnectionsHandler(store db.Querier) *ConnectionsHandler {
 	// Check if running in production mode
 	isProduction := strings.ToLower(os.Getenv("ENVIRONMENT")) == "production"
 
@@ -35,8 +44,10 @@ func NewConnectionsHandler() *ConnectionsHandler {
 	}
 
 	return &ConnectionsHandler{
+		db:                store,
 		encryptionService: crypto.GetEncryptionService(),
 		ipValidator:       ipValidator,
+		metadataCache:     metacache.New(store),
 	}
 }
 
@@ -64,7 +75,12 @@ func (h *ConnectionsHandler) encryptPassword(password string) string {
 
 // decryptPassword decrypts a password if it appears to be encrypted
 func (h *ConnectionsHandler) decryptPassword(password string) string {
-	if !h.encryptionService.IsKeySet() || password == "" {
+	return decryptConnectionPassword(h.encryptionService, password)
+}
+
+// decryptConnectionPassword decrypts a stored connection password if it appears to be encrypted
+func decryptConnectionPassword(encryptionService *crypto.EncryptionService, password string) string {
+	if !encryptionService.IsKeySet() || password == "" {
 		return password
 	}
 
@@ -73,7 +89,7 @@ func (h *ConnectionsHandler) decryptPassword(password string) string {
 		return password // Not encrypted, return as-is
 	}
 
-	decrypted, err := h.encryptionService.Decrypt(password)
+	decrypted, err := encryptionService.Decrypt(password)
 	if err != nil {
 		log.Printf("Warning: Failed to decrypt password (may be plaintext): %v", err)
 		return password // Return as-is if decryption fails (might be old plaintext)
@@ -93,15 +109,16 @@ func (h *ConnectionsHandler) decryptPassword(password string) string {
 // @Router /connections [get]
 func (h *ConnectionsHandler) GetAll(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 
 	var connections []models.DatabaseConnection
-	err := db.DB.Select(&connections, `
+	err := h.db.Select(&connections, `
 		SELECT id, name, db_type, host, port, database_name, username,
-		       is_source, user_id, created_at, updated_at
+		       is_source, max_concurrent_migrations, user_id, extra_config, created_at, updated_at
 		FROM database_connections
-		WHERE user_id = $1
+		WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2
 		ORDER BY created_at DESC
-	`, userID)
+	`, userID, orgID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connections"})
@@ -130,6 +147,7 @@ func (h *ConnectionsHandler) GetAll(c *gin.Context) {
 // @Router /connections/{id} [get]
 func (h *ConnectionsHandler) GetOne(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
@@ -137,12 +155,12 @@ func (h *ConnectionsHandler) GetOne(c *gin.Context) {
 	}
 
 	var connection models.DatabaseConnection
-	err = db.DB.Get(&connection, `
+	err = h.db.Get(&connection, `
 		SELECT id, name, db_type, host, port, database_name, username,
-		       is_source, user_id, created_at, updated_at
+		       is_source, max_concurrent_migrations, user_id, extra_config, created_at, updated_at
 		FROM database_connections
-		WHERE id = $1 AND user_id = $2
-	`, id, userID)
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -199,6 +217,15 @@ func (h *ConnectionsHandler) Create(c *gin.Context) {
 		return
 	}
 
+	extraConfig, extraResult := connectionExtraConfig(validator, &req)
+	if !extraResult.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": extraResult.Errors,
+		})
+		return
+	}
+
 	// SSRF Protection: Validate host is not internal/private
 	if err := h.validateHostSSRF(req.Host); err != nil {
 		log.Printf("SSRF validation failed for host %s: %v", req.Host, err)
@@ -219,11 +246,11 @@ func (h *ConnectionsHandler) Create(c *gin.Context) {
 	encryptedPassword := h.encryptPassword(req.Password)
 
 	var connectionID int64
-	err := db.DB.QueryRow(`
-		INSERT INTO database_connections (name, db_type, host, port, database_name, username, password, use_windows_auth, is_source, user_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	err := h.db.QueryRow(`
+		INSERT INTO database_connections (name, db_type, host, port, database_name, username, password, use_windows_auth, is_source, user_id, organization_id, extra_config, max_concurrent_migrations)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, 0), $12, COALESCE($13, 1))
 		RETURNING id
-	`, req.Name, req.DBType, req.Host, req.Port, req.DatabaseName, req.Username, encryptedPassword, req.UseWindowsAuth, req.IsSource, userID).Scan(&connectionID)
+	`, req.Name, req.DBType, req.Host, req.Port, req.DatabaseName, req.Username, encryptedPassword, req.UseWindowsAuth, req.IsSource, userID, middleware.GetOrganizationID(c), extraConfig, req.MaxConcurrentMigrations).Scan(&connectionID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create connection"})
@@ -231,9 +258,9 @@ func (h *ConnectionsHandler) Create(c *gin.Context) {
 	}
 
 	var connection models.DatabaseConnection
-	db.DB.Get(&connection, `
+	h.db.Get(&connection, `
 		SELECT id, name, db_type, host, port, database_name, username,
-		       is_source, user_id, created_at, updated_at
+		       is_source, max_concurrent_migrations, user_id, extra_config, created_at, updated_at
 		FROM database_connections WHERE id = $1
 	`, connectionID)
 
@@ -256,6 +283,7 @@ func (h *ConnectionsHandler) Create(c *gin.Context) {
 // @Router /connections/{id} [put]
 func (h *ConnectionsHandler) Update(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
@@ -290,6 +318,15 @@ func (h *ConnectionsHandler) Update(c *gin.Context) {
 		return
 	}
 
+	extraConfig, extraResult := connectionExtraConfig(validator, &req)
+	if !extraResult.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": extraResult.Errors,
+		})
+		return
+	}
+
 	// SSRF Protection: Validate host is not internal/private
 	if err := h.validateHostSSRF(req.Host); err != nil {
 		log.Printf("SSRF validation failed for host %s: %v", req.Host, err)
@@ -309,12 +346,13 @@ func (h *ConnectionsHandler) Update(c *gin.Context) {
 	// Encrypt password before storing
 	encryptedPassword := h.encryptPassword(req.Password)
 
-	result, err := db.DB.Exec(`
+	result, err := h.db.Exec(`
 		UPDATE database_connections
 		SET name = $1, db_type = $2, host = $3, port = $4, database_name = $5,
-		    username = $6, password = $7, use_windows_auth = $8, is_source = $9, updated_at = NOW()
-		WHERE id = $10 AND user_id = $11
-	`, req.Name, req.DBType, req.Host, req.Port, req.DatabaseName, req.Username, encryptedPassword, req.UseWindowsAuth, req.IsSource, id, userID)
+		    username = $6, password = $7, use_windows_auth = $8, is_source = $9, extra_config = $10,
+		    max_concurrent_migrations = COALESCE($13, max_concurrent_migrations), updated_at = NOW()
+		WHERE id = $11 AND user_id = $12 AND COALESCE(organization_id, 0) = $14
+	`, req.Name, req.DBType, req.Host, req.Port, req.DatabaseName, req.Username, encryptedPassword, req.UseWindowsAuth, req.IsSource, extraConfig, id, userID, req.MaxConcurrentMigrations, orgID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update connection"})
@@ -326,11 +364,15 @@ func (h *ConnectionsHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
 		return
 	}
+	if req.MaxConcurrentMigrations != nil {
+		// A raised limit frees slots for migrations queued on the connection
+		go NewMigrationsHandler(h.db).dispatchQueued(id)
+	}
 
 	var connection models.DatabaseConnection
-	db.DB.Get(&connection, `
+	h.db.Get(&connection, `
 		SELECT id, name, db_type, host, port, database_name, username,
-		       is_source, user_id, created_at, updated_at
+		       is_source, max_concurrent_migrations, user_id, extra_config, created_at, updated_at
 		FROM database_connections WHERE id = $1
 	`, id)
 
@@ -339,26 +381,91 @@ func (h *ConnectionsHandler) Update(c *gin.Context) {
 
 // Delete deletes a database connection
 // @Summary Delete a connection
-// @Description Delete a database connection
+// @Description Delete a database connection. A connection used by pending or running migrations can't be deleted; pass force=true with repoint_to to move its migrations that aren't running to another connection first. Running migrations always block the delete.
 // @Tags connections
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Connection ID"
+// @Param force query bool false "Delete even though pending migrations use the connection"
+// @Param repoint_to query int false "Connection the migrations are moved to; required with force"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /connections/{id} [delete]
 func (h *ConnectionsHandler) Delete(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
 		return
 	}
+	force := c.Query("force") == "true"
 
-	result, err := db.DB.Exec("DELETE FROM database_connections WHERE id = $1 AND user_id = $2", id, userID)
+	conn, err := getOwnedConnection(h.db, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection"})
+		return
+	}
+
+	dependents, err := listConnectionDependents(h.db, conn, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dependents"})
+		return
+	}
+	active, running := activeDependents(dependents)
+	if running {
+		c.JSON(http.StatusConflict, gin.H{"error": "Connection is used by running migrations", "dependents": active})
+		return
+	}
+	if len(active) > 0 && !force {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "Connection is used by pending migrations; pass force=true with repoint_to to move them to another connection",
+			"dependents": active,
+		})
+		return
+	}
+
+	if force && c.Query("repoint_to") != "" {
+		replacementID, err := strconv.ParseInt(c.Query("repoint_to"), 10, 64)
+		if err != nil || replacementID == id {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "repoint_to must be the ID of another connection"})
+			return
+		}
+		replacement, err := getOwnedConnection(h.db, replacementID, userID, orgID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Replacement connection not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch replacement connection"})
+			return
+		}
+		if replacement.OrganizationID != conn.OrganizationID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Replacement connection belongs to another organization"})
+			return
+		}
+
+		if err := h.deleteRepointingDependents(conn, replacement, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete connection"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Connection deleted", "repointed_to": replacement.ID})
+		return
+	}
+	if len(active) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repoint_to is required to delete a connection pending migrations use"})
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM database_connections WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete connection"})
 		return
@@ -388,53 +495,69 @@ func (h *ConnectionsHandler) Delete(c *gin.Context) {
 // @Router /connections/{id}/test [post]
 func (h *ConnectionsHandler) Test(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
 		return
 	}
 
+	result, failure := h.testConnection(id, userID, orgID)
+	if failure != nil {
+		c.JSON(failure.Status, failure.Body)
+		return
+	}
+
+	if result.Success {
+		c.JSON(http.StatusOK, result)
+	} else {
+		c.JSON(http.StatusBadRequest, result)
+	}
+}
+
+// testConnection connects to one of the user's databases in the organization. A failed connection is reported
+// in the result; the error is for connections that couldn't be tested at all.
+func (h *ConnectionsHandler) testConnection(id, userID, orgID int64) (dbtest.TestResult, *actionError) {
 	// Fetch connection with password for testing
 	var connection struct {
-		ID             int64  `db:"id"`
-		DBType         string `db:"db_type"`
-		Host           string `db:"host"`
-		Port           int    `db:"port"`
-		Database       string `db:"database_name"`
-		Username       string `db:"username"`
-		Password       string `db:"password"`
-		UseWindowsAuth bool   `db:"use_windows_auth"`
-	}
-
-	err = db.DB.Get(&connection, `
-		SELECT id, db_type, host, port, database_name, username, password, COALESCE(use_windows_auth, false) as use_windows_auth
+		ID             int64   `db:"id"`
+		DBType         string  `db:"db_type"`
+		Host           string  `db:"host"`
+		Port           int     `db:"port"`
+		Database       string  `db:"database_name"`
+		Username       string  `db:"username"`
+		Password       string  `db:"password"`
+		UseWindowsAuth bool    `db:"use_windows_auth"`
+		ExtraConfig    *string `db:"extra_config"`
+	}
+
+	err := h.db.Get(&connection, `
+		SELECT id, db_type, host, port, database_name, username, password, COALESCE(use_windows_auth, false) as use_windows_auth, extra_config
 		FROM database_connections
-		WHERE id = $1 AND user_id = $2
-	`, id, userID)
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
-			return
+			return dbtest.TestResult{}, newActionError(http.StatusNotFound, "Connection not found")
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection"})
-		return
+		return dbtest.TestResult{}, newActionError(http.StatusInternalServerError, "Failed to fetch connection")
 	}
 
 	// SSRF Protection: Validate host before testing connection
 	if err := h.validateHostSSRF(connection.Host); err != nil {
 		log.Printf("SSRF validation failed for connection test, host %s: %v", connection.Host, err)
-		c.JSON(http.StatusBadRequest, gin.H{
+		return dbtest.TestResult{}, &actionError{Status: http.StatusBadRequest, Body: gin.H{
 			"error":   "Connection test blocked",
 			"details": "The specified host address is not allowed for security reasons",
-		})
-		return
+		}}
 	}
 
 	// Decrypt password before testing connection
 	decryptedPassword := h.decryptPassword(connection.Password)
 
 	// Test the actual database connection
+	extra := parseExtraConfig(connection.ExtraConfig)
 	result := dbtest.TestConnection(dbtest.ConnectionParams{
 		DBType:         connection.DBType,
 		Host:           connection.Host,
@@ -443,13 +566,12 @@ func (h *ConnectionsHandler) Test(c *gin.Context) {
 		Username:       connection.Username,
 		Password:       decryptedPassword,
 		UseWindowsAuth: connection.UseWindowsAuth,
+		TenantID:       extra.TenantID,
+		ReadReplica:    extra.ReadReplica,
+		ReadIsolation:  extra.ReadIsolation,
 	})
-
-	if result.Success {
-		c.JSON(http.StatusOK, result)
-	} else {
-		c.JSON(http.StatusBadRequest, result)
-	}
+	recordConnectionTest(h.db, connection.ID, result)
+	return result, nil
 }
 
 // GetMetadata extracts metadata (tables, views, procedures) from a database connection
@@ -460,7 +582,11 @@ func (h *ConnectionsHandler) Test(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Connection ID"
+// @Param refresh query bool false "Bypass the metadata cache and rescan the database"
+// @Param include query string false "Extra detail to extract, comma-separated: columns, foreign_keys"
 // @Success 200 {object} map[string]interface{}
+// @Header 200 {string} X-Metadata-Cache "hit or miss"
+// @Header 200 {string} X-Metadata-Cached-At "When a cached result was extracted (RFC3339)"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -473,31 +599,164 @@ func (h *ConnectionsHandler) GetMetadata(c *gin.Context) {
 		return
 	}
 
-	// Fetch connection details
-	var connection struct {
-		ID             int64  `db:"id"`
-		DBType         string `db:"db_type"`
-		Host           string `db:"host"`
-		Port           int    `db:"port"`
-		Database       string `db:"database_name"`
-		Username       string `db:"username"`
-		Password       string `db:"password"`
-		UseWindowsAuth bool   `db:"use_windows_auth"`
-	}
-
-	err = db.DB.Get(&connection, `
-		SELECT id, db_type, host, port, database_name, username, password, COALESCE(use_windows_auth, false) as use_windows_auth
+	connection, ok := h.metadataConnection(c, id, userID)
+	if !ok {
+		return
+	}
+
+	opts := dbtest.ExtractOptions{}
+	var include []string
+	for _, part := range strings.Split(c.Query("include"), ",") {
+		switch strings.TrimSpace(part) {
+		case "":
+			continue
+		case "columns":
+			opts.IncludeColumns = true
+		case "foreign_keys":
+			opts.IncludeForeignKeys = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include must be a comma-separated list of: columns, foreign_keys"})
+			return
+		}
+	}
+	if opts.IncludeColumns {
+		include = append(include, "columns")
+		// A column query per table takes far longer than the catalog queries
+		opts.Timeout = metadataColumnsTimeout
+	}
+	if opts.IncludeForeignKeys {
+		include = append(include, "foreign_keys")
+	}
+
+	// Serve a cached scan unless the caller asks for a fresh one
+	cacheKey := metacache.Key(connection.DBType, connection.Host, connection.Port, connection.Database, connection.Username, strings.Join(include, ","))
+	if c.Query("refresh") != "true" {
+		entry, err := h.metadataCache.Get(cacheKey)
+		if err != nil {
+			log.Printf("Metadata cache lookup failed for connection %d: %v", id, err)
+		} else if entry != nil {
+			c.Header("X-Metadata-Cache", "hit")
+			c.Header("X-Metadata-Cached-At", entry.CachedAt.UTC().Format(time.RFC3339))
+			c.Data(http.StatusOK, "application/json; charset=utf-8", entry.Payload)
+			return
+		}
+	}
+
+	// Log column progress for large databases roughly every 10%
+	lastLogged := 0
+	opts.OnProgress = func(p dbtest.MetadataProgress) {
+		if p.Stage != dbtest.StageColumns || p.Total < 500 {
+			return
+		}
+		if pct := p.Completed * 100 / p.Total; pct >= lastLogged+10 {
+			lastLogged = pct - pct%10
+			log.Printf("Metadata extraction for connection %d: columns for %d/%d tables", id, p.Completed, p.Total)
+		}
+	}
+
+	// Extract metadata using dbtest package; the scan stops if the client goes away
+	metadata := dbtest.ExtractMetadataContext(c.Request.Context(), h.metadataParams(connection), opts)
+
+	// Only complete scans are cached, so a fixed connection is picked up immediately
+	if metadata.Success && !metadata.Partial {
+		if err := h.metadataCache.Set(cacheKey, metadata); err != nil {
+			log.Printf("Failed to cache metadata for connection %d: %v", id, err)
+		}
+	}
+
+	c.Header("X-Metadata-Cache", "miss")
+	c.JSON(http.StatusOK, metadata)
+}
+
+// GetDependencies returns the foreign key and view dependency graph of a database
+// @Summary Get database dependency graph
+// @Description Extract foreign keys and view dependencies and return them as a graph, with tables and views in build order
+// @Tags connections
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Connection ID"
+// @Param refresh query bool false "Bypass the metadata cache and rescan the database"
+// @Success 200 {object} dbtest.DependencyGraph
+// @Header 200 {string} X-Metadata-Cache "hit or miss"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /connections/{id}/metadata/dependencies [get]
+func (h *ConnectionsHandler) GetDependencies(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
+		return
+	}
+
+	connection, ok := h.metadataConnection(c, id, userID)
+	if !ok {
+		return
+	}
+
+	cacheKey := metacache.Key(connection.DBType, connection.Host, connection.Port, connection.Database, connection.Username, "dependencies")
+	if c.Query("refresh") != "true" {
+		entry, err := h.metadataCache.Get(cacheKey)
+		if err != nil {
+			log.Printf("Metadata cache lookup failed for connection %d: %v", id, err)
+		} else if entry != nil {
+			c.Header("X-Metadata-Cache", "hit")
+			c.Header("X-Metadata-Cached-At", entry.CachedAt.UTC().Format(time.RFC3339))
+			c.Data(http.StatusOK, "application/json; charset=utf-8", entry.Payload)
+			return
+		}
+	}
+
+	graph, metadata := dbtest.ExtractDependencies(c.Request.Context(), h.metadataParams(connection))
+	if !metadata.Success {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to extract dependencies", "details": metadata.Error})
+		return
+	}
+
+	if !metadata.Partial {
+		if err := h.metadataCache.Set(cacheKey, graph); err != nil {
+			log.Printf("Failed to cache dependencies for connection %d: %v", id, err)
+		}
+	} else {
+		log.Printf("Dependency extraction for connection %d was partial: %v", id, metadata.Warnings)
+	}
+
+	c.Header("X-Metadata-Cache", "miss")
+	c.JSON(http.StatusOK, graph)
+}
+
+// metadataConnection is a connection loaded for metadata extraction
+type metadataConnection struct {
+	ID             int64   `db:"id"`
+	DBType         string  `db:"db_type"`
+	Host           string  `db:"host"`
+	Port           int     `db:"port"`
+	Database       string  `db:"database_name"`
+	Username       string  `db:"username"`
+	Password       string  `db:"password"`
+	UseWindowsAuth bool    `db:"use_windows_auth"`
+	ExtraConfig    *string `db:"extra_config"`
+}
+
+// metadataConnection loads the user's connection in the active organization and checks
+// its host is allowed. On failure it writes the error response and returns false.
+func (h *ConnectionsHandler) metadataConnection(c *gin.Context, id, userID int64) (*metadataConnection, bool) {
+	var connection metadataConnection
+	err := h.db.Get(&connection, `
+		SELECT id, db_type, host, port, database_name, username, password, COALESCE(use_windows_auth, false) as use_windows_auth,
+		       extra_config
 		FROM database_connections
-		WHERE id = $1 AND user_id = $2
-	`, id, userID)
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, middleware.GetOrganizationID(c))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Connection not found"})
-			return
+			return nil, false
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch connection"})
-		return
+		return nil, false
 	}
 
 	// SSRF Protection: Validate host before extracting metadata
@@ -507,22 +766,73 @@ func (h *ConnectionsHandler) GetMetadata(c *gin.Context) {
 			"error":   "Metadata extraction blocked",
 			"details": "The specified host address is not allowed for security reasons",
 		})
-		return
+		return nil, false
 	}
 
-	// Decrypt password before extracting metadata
-	decryptedPassword := h.decryptPassword(connection.Password)
+	return &connection, true
+}
 
-	// Extract metadata using dbtest package
-	metadata := dbtest.ExtractMetadata(dbtest.ConnectionParams{
+// metadataParams returns the dbtest parameters for a connection, with the password decrypted
+func (h *ConnectionsHandler) metadataParams(connection *metadataConnection) dbtest.ConnectionParams {
+	extra := parseExtraConfig(connection.ExtraConfig)
+	return dbtest.ConnectionParams{
 		DBType:         connection.DBType,
 		Host:           connection.Host,
 		Port:           connection.Port,
 		Database:       connection.Database,
 		Username:       connection.Username,
-		Password:       decryptedPassword,
+		Password:       h.decryptPassword(connection.Password),
 		UseWindowsAuth: connection.UseWindowsAuth,
-	})
+		ReadReplica:    extra.ReadReplica,
+		ReadIsolation:  extra.ReadIsolation,
+	}
+}
 
-	c.JSON(http.StatusOK, metadata)
+// connectionExtraConfig validates the warehouse settings for the connection type and the
+// extraction throttle, and returns the extra_config JSON to store (nil when there is none)
+func connectionExtraConfig(validator *validation.ConnectionValidator, req *models.CreateConnectionRequest) (*string, *validation.ValidationResult) {
+	extra := models.ConnectionExtraConfig{}
+	if req.ExtraConfig != nil {
+		extra = *req.ExtraConfig
+	}
+	extra.TenantID = strings.TrimSpace(extra.TenantID)
+	extra.Workspace = validation.SanitizeInput(extra.Workspace)
+	extra.Lakehouse = validation.SanitizeInput(extra.Lakehouse)
+	extra.Throttle = normalizeExtractionThrottle(extra.Throttle)
+	extra.ReadIsolation = strings.ToLower(strings.TrimSpace(extra.ReadIsolation))
+
+	result := validator.ValidateAzureWarehouse(req.DBType, req.Host, req.Username, req.Password, extra.TenantID, extra.Workspace)
+	validateExtractionThrottle(result, extra.Throttle)
+	if extra.ReadReplica && !dbtest.SupportsReadReplica(req.DBType) {
+		result.AddError("extra_config.read_replica", "Read replicas are supported for SQL Server and PostgreSQL connections")
+	}
+	if extra.ReadIsolation != dbtest.ReadIsolationDefault {
+		if !dbtest.SupportsReadIsolation(req.DBType) {
+			result.AddError("extra_config.read_isolation", "Read isolation can only be chosen for SQL Server connections")
+		} else if !slices.Contains(dbtest.ReadIsolations, extra.ReadIsolation) {
+			result.AddError("extra_config.read_isolation", "Read isolation must be snapshot or read_uncommitted")
+		}
+	}
+	if !result.Valid || extra == (models.ConnectionExtraConfig{}) {
+		return nil, result
+	}
+
+	data, err := json.Marshal(extra)
+	if err != nil {
+		result.AddError("extra_config", "Invalid warehouse settings")
+		return nil, result
+	}
+	value := string(data)
+	return &value, result
+}
+
+// parseExtraConfig reads a stored extra_config, returning empty settings if unset or invalid
+func parseExtraConfig(value *string) models.ConnectionExtraConfig {
+	var extra models.ConnectionExtraConfig
+	if value != nil {
+		if err := json.Unmarshal([]byte(*value), &extra); err != nil {
+			log.Printf("Invalid extra_config on connection: %v", err)
+		}
+	}
+	return extra
 }
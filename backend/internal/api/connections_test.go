@@ -0,0 +1,301 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+var connectionColumns = []string{
+	"id", "name", "db_type", "host", "port", "database_name", "username", "is_source", "user_id", "created_at", "updated_at",
+}
+
+func validConnectionRequest() map[string]interface{} {
+	return map[string]interface{}{
+		"name":          "AdventureWorks",
+		"db_type":       "mssql",
+		"host":          "203.0.113.10",
+		"port":          1433,
+		"database_name": "AdventureWorksLite",
+		"username":      "migrator",
+		"password":      "S3cure-Passw0rd",
+		"is_source":     true,
+	}
+}
+
+func TestConnectionsGetAll(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`FROM database_connections\s+WHERE user_id = \$1`).
+		WithArgs(testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(connectionColumns).
+			AddRow(1, "AdventureWorks", "mssql", "203.0.113.10", 1433, "AdventureWorksLite", "migrator", true, testUserID, now, now))
+
+	status, body := serve(t, "GET", "/connections", "/connections", nil, NewConnectionsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var connections []models.DatabaseConnection
+	if err := json.Unmarshal(body, &connections); err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 1 || connections[0].DatabaseName != "AdventureWorksLite" {
+		t.Errorf("connections = %+v", connections)
+	}
+}
+
+func TestConnectionsGetOneNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections\s+WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	status, body := serve(t, "GET", "/connections/:id", "/connections/4", nil, NewConnectionsHandler(store).GetOne)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+// A connection the user created in another organization isn't found from the active one
+func TestConnectionsOtherOrganizationNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections\s+WHERE id = \$1 AND user_id = \$2 AND COALESCE\(organization_id, 0\) = \$3`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`FROM database_connections\s+WHERE id = \$1 AND user_id = \$2 AND COALESCE\(organization_id, 0\) = \$3`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	h := NewConnectionsHandler(store)
+	status, body := serve(t, "GET", "/connections/:id", "/connections/4", nil, h.GetOne)
+	expectStatus(t, status, http.StatusNotFound, body)
+	status, body = serve(t, "POST", "/connections/:id/test", "/connections/4/test", nil, h.Test)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestConnectionsCreate(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`INSERT INTO database_connections`).
+		WithArgs("AdventureWorks", "mssql", "203.0.113.10", 1433, "AdventureWorksLite", "migrator", "S3cure-Passw0rd", false, true, testUserID, testOrgID, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`FROM database_connections WHERE id = \$1`).
+		WithArgs(int64(11)).
+		WillReturnRows(sqlmock.NewRows(connectionColumns).
+			AddRow(11, "AdventureWorks", "mssql", "203.0.113.10", 1433, "AdventureWorksLite", "migrator", true, testUserID, now, now))
+
+	status, body := serve(t, "POST", "/connections", "/connections", validConnectionRequest(), NewConnectionsHandler(store).Create)
+	expectStatus(t, status, http.StatusCreated, body)
+
+	var connection models.DatabaseConnection
+	if err := json.Unmarshal(body, &connection); err != nil {
+		t.Fatal(err)
+	}
+	if connection.ID != 11 {
+		t.Errorf("connection = %+v", connection)
+	}
+	if containsPassword(body) {
+		t.Error("response exposes the connection password")
+	}
+}
+
+func TestConnectionsCreateRejectsMetadataHost(t *testing.T) {
+	store, _ := newMockDB(t)
+	req := validConnectionRequest()
+	req["host"] = "169.254.169.254"
+
+	status, body := serve(t, "POST", "/connections", "/connections", req, NewConnectionsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestConnectionsCreateValidation(t *testing.T) {
+	store, _ := newMockDB(t)
+	req := validConnectionRequest()
+	req["port"] = 70000
+
+	status, body := serve(t, "POST", "/connections", "/connections", req, NewConnectionsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if msg := errorMessage(t, body); msg != "Validation failed" {
+		t.Errorf("error = %q", msg)
+	}
+}
+
+func TestConnectionsUpdateNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE database_connections`).
+		WithArgs("AdventureWorks", "mssql", "203.0.113.10", 1433, "AdventureWorksLite", "migrator", "S3cure-Passw0rd", false, true, nil, int64(4), testUserID, nil, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	status, body := serve(t, "PUT", "/connections/:id", "/connections/4", validConnectionRequest(), NewConnectionsHandler(store).Update)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+var dependentColumns = []string{"id", "name", "status", "as_source", "as_target", "created_at"}
+
+func expectOwnedConnection(mock sqlmock.Sqlmock, id int64, name string) {
+	mock.ExpectQuery(`FROM database_connections WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(id, testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id"}).AddRow(id, name, testOrgID))
+}
+
+func TestConnectionsDelete(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectOwnedConnection(mock, 4, "AdventureWorks")
+	mock.ExpectQuery(`FROM migrations`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(dependentColumns).AddRow(9, "Nightly", "completed", true, false, time.Now()))
+	mock.ExpectExec(`DELETE FROM database_connections WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body := serve(t, "DELETE", "/connections/:id", "/connections/4", nil, NewConnectionsHandler(store).Delete)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestConnectionsDeleteNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	status, body := serve(t, "DELETE", "/connections/:id", "/connections/4", nil, NewConnectionsHandler(store).Delete)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestConnectionsDeleteBlockedByPendingMigration(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectOwnedConnection(mock, 4, "AdventureWorks")
+	mock.ExpectQuery(`FROM migrations`).
+		WillReturnRows(sqlmock.NewRows(dependentColumns).AddRow(9, "Nightly", "pending", true, false, time.Now()))
+
+	status, body := serve(t, "DELETE", "/connections/:id", "/connections/4", nil, NewConnectionsHandler(store).Delete)
+	expectStatus(t, status, http.StatusConflict, body)
+
+	var resp struct {
+		Dependents []models.ConnectionDependent `json:"dependents"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Dependents) != 1 || resp.Dependents[0].ID != 9 {
+		t.Errorf("dependents = %+v", resp.Dependents)
+	}
+}
+
+func TestConnectionsDeleteForceRequiresRepoint(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectOwnedConnection(mock, 4, "AdventureWorks")
+	mock.ExpectQuery(`FROM migrations`).
+		WillReturnRows(sqlmock.NewRows(dependentColumns).AddRow(9, "Nightly", "pending", true, false, time.Now()))
+
+	status, body := serve(t, "DELETE", "/connections/:id", "/connections/4?force=true", nil, NewConnectionsHandler(store).Delete)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestConnectionsDeleteRunningMigrationBlocksForce(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectOwnedConnection(mock, 4, "AdventureWorks")
+	mock.ExpectQuery(`FROM migrations`).
+		WillReturnRows(sqlmock.NewRows(dependentColumns).AddRow(9, "Nightly", "running", true, false, time.Now()))
+
+	status, body := serve(t, "DELETE", "/connections/:id", "/connections/4?force=true&repoint_to=5", nil, NewConnectionsHandler(store).Delete)
+	expectStatus(t, status, http.StatusConflict, body)
+}
+
+func TestConnectionsDeleteForceRepoints(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectOwnedConnection(mock, 4, "AdventureWorks")
+	mock.ExpectQuery(`FROM migrations`).
+		WillReturnRows(sqlmock.NewRows(dependentColumns).AddRow(9, "Nightly", "pending", true, false, time.Now()))
+	expectOwnedConnection(mock, 5, "AdventureWorksReplica")
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE migrations SET connection_id = \$1, source_database = \$2`).
+		WithArgs(int64(5), "AdventureWorksReplica", int64(4), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE migrations SET config = jsonb_set`).
+		WithArgs(int64(5), int64(4), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM database_connections`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	status, body := serve(t, "DELETE", "/connections/:id", "/connections/4?force=true&repoint_to=5", nil, NewConnectionsHandler(store).Delete)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestConnectionsTestNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	status, body := serve(t, "POST", "/connections/:id/test", "/connections/4/test", nil, NewConnectionsHandler(store).Test)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+// containsPassword reports whether a JSON object has a password field
+func containsPassword(body json.RawMessage) bool {
+	var fields map[string]interface{}
+	json.Unmarshal(body, &fields)
+	_, ok := fields["password"]
+	return ok
+}
+
+func TestConnectionsCreateFabricRequiresServicePrincipal(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	req := validConnectionRequest()
+	req["db_type"] = "fabric"
+	req["host"] = "abc123.datawarehouse.fabric.microsoft.com"
+	req["extra_config"] = map[string]string{"workspace": "Analytics"}
+
+	status, body := serve(t, "POST", "/connections", "/connections", req, NewConnectionsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	for _, field := range []string{"extra_config.tenant_id", "client ID"} {
+		if !strings.Contains(string(body), field) {
+			t.Errorf("body = %s, want an error mentioning %s", body, field)
+		}
+	}
+}
+
+func TestConnectionsCreateValidatesThrottle(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	req := validConnectionRequest()
+	req["extra_config"] = map[string]interface{}{
+		"throttle": map[string]interface{}{
+			"fetch_size": 10,
+			"timezone":   "Mars/Olympus",
+			"off_peak_windows": []map[string]interface{}{
+				{"days": []string{"sat", "funday"}, "start": "22:00", "end": "25:00"},
+			},
+		},
+	}
+
+	status, body := serve(t, "POST", "/connections", "/connections", req, NewConnectionsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	for _, field := range []string{"throttle.fetch_size", "throttle.timezone", "off_peak_windows[0].end", "off_peak_windows[0].days"} {
+		if !strings.Contains(string(body), field) {
+			t.Errorf("body = %s, want an error for %s", body, field)
+		}
+	}
+}
+
+func TestConnectionsCreateValidatesReadIsolation(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	req := validConnectionRequest()
+	req["db_type"] = "postgresql"
+	req["port"] = 5432
+	req["extra_config"] = map[string]interface{}{"read_isolation": "snapshot"}
+
+	status, body := serve(t, "POST", "/connections", "/connections", req, NewConnectionsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if !strings.Contains(string(body), "extra_config.read_isolation") {
+		t.Errorf("body = %s, want an error for extra_config.read_isolation", body)
+	}
+}
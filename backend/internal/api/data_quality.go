@@ -0,0 +1,157 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// ScanDataQualityRequest selects what to profile; every field is optional
+type ScanDataQualityRequest struct {
+	Tables     []string `json:"tables"`
+	SampleSize int      `json:"sample_size"`
+}
+
+// ScanDataQuality profiles a saved connection for data quality issues
+// @Summary Scan a connection for data quality issues
+// @Description Profile the source database of an MSSQL connection: NULL rates, duplicates, foreign key violations and low-cardinality columns, with a 0-100 score. Profiled values are masked per the organization's PII policy.
+// @Tags connections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Connection ID"
+// @Param request body ScanDataQualityRequest false "Tables to scan and sample size"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /connections/{id}/data-quality/scan [post]
+func (h *ConnectionsHandler) ScanDataQuality(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid connection ID"})
+		return
+	}
+
+	var req ScanDataQualityRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.SampleSize < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sample_size must not be negative"})
+		return
+	}
+
+	connection, ok := h.metadataConnection(c, id, userID)
+	if !ok {
+		return
+	}
+	if connection.DBType != "mssql" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data quality scans are only available for MSSQL connections"})
+		return
+	}
+
+	aiClient, err := organizationAIClient(h.db, orgID)
+	if err != nil {
+		aiClientError(c, err)
+		return
+	}
+	if aiClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not available"})
+		return
+	}
+
+	// The profiler masks with the policy; the report is masked again here in case it didn't
+	masker, err := security.LoadPIIMasker(orgID)
+	if err != nil {
+		log.Printf("Failed to load PII policy for data quality scan of connection %d: %v", id, err)
+	}
+
+	params := h.metadataParams(connection)
+	report, err := aiClient.ScanDataQuality(aiservice.DataQualityScanRequest{
+		Host:           params.Host,
+		Port:           params.Port,
+		Database:       params.Database,
+		Username:       params.Username,
+		Password:       params.Password,
+		UseWindowsAuth: params.UseWindowsAuth,
+		Tables:         req.Tables,
+		SampleSize:     req.SampleSize,
+		PIIPolicy:      masker.Policy(),
+	})
+	if err != nil {
+		log.Printf("Data quality scan of connection %d failed: %v", id, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Data quality scan failed"})
+		return
+	}
+
+	maskQualityReport(masker, report)
+	c.JSON(http.StatusOK, report)
+}
+
+// maskQualityReport masks the values a data quality report carries from the source: the
+// min and max of each column profile and the sample values of each issue
+func maskQualityReport(masker *security.PIIMasker, report map[string]interface{}) {
+	tables, _ := report["tables"].([]interface{})
+	for _, t := range tables {
+		table, _ := t.(map[string]interface{})
+		for _, col := range objects(table["columns"]) {
+			column, _ := col["column_name"].(string)
+			for _, key := range []string{"min_value", "max_value"} {
+				if col[key] == nil {
+					continue
+				}
+				rows := []map[string]interface{}{{column: col[key]}}
+				masker.MaskSampleRows(rows)
+				col[key] = rows[0][column]
+			}
+		}
+		maskIssueSamples(masker, objects(table["issues"]))
+	}
+
+	bySeverity, _ := report["issues_by_severity"].(map[string]interface{})
+	for _, issues := range bySeverity {
+		maskIssueSamples(masker, objects(issues))
+	}
+}
+
+func maskIssueSamples(masker *security.PIIMasker, issues []map[string]interface{}) {
+	for _, issue := range issues {
+		samples, _ := issue["sample_values"].([]interface{})
+		if len(samples) == 0 {
+			continue
+		}
+		column, _ := issue["column_name"].(string)
+		rows := make([]map[string]interface{}, len(samples))
+		for i, v := range samples {
+			rows[i] = map[string]interface{}{column: v}
+		}
+		masker.MaskSampleRows(rows)
+		for i := range samples {
+			samples[i] = rows[i][column]
+		}
+	}
+}
+
+// objects returns the JSON objects of a decoded JSON array
+func objects(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
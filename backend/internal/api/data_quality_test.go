@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/security"
+)
+
+func TestMaskQualityReport(t *testing.T) {
+	masker := security.NewPIIMasker([]security.PIIRule{
+		{Name: "emails", RuleType: security.PIIRuleTypeColumn, Pattern: "email", MaskStrategy: security.PIIMaskRedact, IsActive: true},
+	})
+
+	var report map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"tables": [{
+			"columns": [
+				{"column_name": "EmailAddress", "min_value": "aaron@contoso.com", "max_value": "zoe@contoso.com"},
+				{"column_name": "OrderDate", "min_value": "2024-01-02", "max_value": null}
+			],
+			"issues": [{"column_name": "Notes", "sample_values": ["call 123-45-6789", "fine"]}]
+		}],
+		"issues_by_severity": {
+			"warning": [{"column_name": "EmailAddress", "sample_values": ["dup@contoso.com"]}]
+		}
+	}`), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maskQualityReport(masker, report)
+
+	table := objects(report["tables"])[0]
+	columns := objects(table["columns"])
+	if columns[0]["min_value"] != "[PII]" || columns[0]["max_value"] != "[PII]" {
+		t.Errorf("sensitive column not masked: %v", columns[0])
+	}
+	if columns[1]["min_value"] != "2024-01-02" || columns[1]["max_value"] != nil {
+		t.Errorf("other column changed: %v", columns[1])
+	}
+	samples := objects(table["issues"])[0]["sample_values"].([]interface{})
+	if samples[0] != "call [PII]" || samples[1] != "fine" {
+		t.Errorf("issue samples = %v", samples)
+	}
+	warning := objects(report["issues_by_severity"].(map[string]interface{})["warning"])[0]
+	if got := warning["sample_values"].([]interface{})[0]; got != "[PII]" {
+		t.Errorf("warning sample = %v", got)
+	}
+}
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type DataResidencyHandler struct {
+	db db.Querier
+}
+
+func NewDataResidencyHandler(store db.Querier) *DataResidencyHandler {
+	return &DataResidencyHandler{db: store}
+}
+
+// SetDataRegionRequest pins an organization to a data region; empty unpins it
+type SetDataRegionRequest struct {
+	DataRegion string `json:"data_region"`
+}
+
+// DataResidencyOrganization is one pinned organization in the data residency report
+type DataResidencyOrganization struct {
+	OrganizationID   int64                    `json:"organization_id"`
+	OrganizationName string                   `json:"organization_name"`
+	DataRegion       string                   `json:"data_region"`
+	RegionAvailable  bool                     `json:"region_available"` // An AI service instance is configured for the region
+	ArtifactBucket   string                   `json:"artifact_bucket,omitempty"`
+	Migrations       int                      `json:"migrations"` // Migrations that have run
+	InRegion         int                      `json:"in_region"`
+	OutOfRegion      []DataResidencyMigration `json:"out_of_region"`
+}
+
+// DataResidencyMigration is a migration whose last run was generated or stored outside
+// its organization's data region
+type DataResidencyMigration struct {
+	MigrationID   int64  `db:"id" json:"migration_id"`
+	Name          string `db:"name" json:"name"`
+	DataRegion    string `db:"data_region" json:"data_region"` // Region the run was generated in; "" for the default instance
+	ArtifactsPath string `db:"artifacts_path" json:"artifacts_path,omitempty"`
+	Reason        string `db:"-" json:"reason"` // generated_out_of_region or artifacts_out_of_region
+}
+
+// residencyRow is a migration of a pinned organization, as read for the report
+type residencyRow struct {
+	OrganizationID   int64  `db:"organization_id"`
+	OrganizationName string `db:"organization_name"`
+	OrgRegion        string `db:"org_region"`
+	DataResidencyMigration
+}
+
+// migrationAIClient returns the AI service instance a migration was generated on: the
+// one of the data region stamped on it when it started. Its files are read from there.
+func migrationAIClient(store db.Querier, migrationID int64) (*aiservice.Client, error) {
+	var region string
+	err := store.Get(&region, "SELECT COALESCE(data_region, '') FROM migrations WHERE id = $1", migrationID)
+	if err != nil {
+		return nil, err
+	}
+	return aiservice.ForRegion(region)
+}
+
+// organizationAIClient returns the AI service instance of an organization's data region,
+// which new migrations, seeds and embeddings of the organization must use
+func organizationAIClient(store db.Querier, orgID int64) (*aiservice.Client, error) {
+	if orgID == 0 {
+		return aiservice.GetClient(), nil
+	}
+	var region string
+	err := store.Get(&region, "SELECT COALESCE(data_region, '') FROM organizations WHERE id = $1", orgID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return aiservice.ForRegion(region)
+}
+
+// aiClientError responds to a failed AI service lookup. A region without an instance is
+// reported as unavailable rather than served from another region.
+func aiClientError(c *gin.Context, err error) {
+	if errors.Is(err, aiservice.ErrRegionUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+}
+
+// SetOrganizationRegion pins an organization's migrations to a data region (admin only)
+// @Summary Set organization data region
+// @Description Pin an organization to the eu or us data region, or unpin it with an empty data_region. Its migrations then run only on that region's AI service instance and store their projects in its bucket. Refused while any of its migrations are running or queued.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Organization ID"
+// @Param request body SetDataRegionRequest true "Data region"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/organizations/{id}/data-region [put]
+func (h *DataResidencyHandler) SetOrganizationRegion(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var req SetDataRegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.DataRegion != "" {
+		if !aiservice.IsValidRegion(req.DataRegion) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "data_region must be eu, us or empty"})
+			return
+		}
+		if !aiservice.RegionAvailable(req.DataRegion) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No AI service instance is configured for region " + req.DataRegion})
+			return
+		}
+	}
+
+	// Runs in flight stay where they started, so the region can't change under them
+	result, err := h.db.Exec(`
+		UPDATE organizations SET data_region = NULLIF($2, ''), updated_at = NOW()
+		WHERE id = $1 AND NOT EXISTS (
+			SELECT 1 FROM migrations WHERE organization_id = $1 AND status IN ('running', 'queued')
+		)
+	`, orgID, req.DataRegion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update data region"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var exists bool
+		if err := h.db.Get(&exists, "SELECT EXISTS (SELECT 1 FROM organizations WHERE id = $1)", orgID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update data region"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "The organization has migrations running or queued; change the region once they finish"})
+		return
+	}
+	log.Printf("Data region of organization %d set to %q by user %d", orgID, req.DataRegion, middleware.GetUserID(c))
+
+	c.JSON(http.StatusOK, gin.H{
+		"organization_id": orgID,
+		"data_region":     req.DataRegion,
+		"artifact_bucket": aiservice.ArtifactBucket(req.DataRegion),
+	})
+}
+
+// Report lists the pinned organizations and the migrations of theirs whose projects
+// were generated or stored outside their region (admin only)
+// @Summary Data residency report
+// @Description For each organization pinned to a data region: whether the region's AI service is configured, its artifact bucket, how many migrations ran in the region and which didn't — generated on another instance (e.g. before the organization was pinned) or stored outside the region's bucket.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param region query string false "Only organizations pinned to this region (eu, us)"
+// @Success 200 {array} DataResidencyOrganization
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/data-residency [get]
+func (h *DataResidencyHandler) Report(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+	region := c.Query("region")
+	if region != "" && !aiservice.IsValidRegion(region) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "region must be eu or us"})
+		return
+	}
+
+	var rows []residencyRow
+	err := h.db.Select(&rows, `
+		SELECT o.id AS organization_id, o.name AS organization_name, o.data_region AS org_region,
+		       COALESCE(m.id, 0) AS id, COALESCE(m.name, '') AS name,
+		       COALESCE(m.data_region, '') AS data_region, COALESCE(r.artifacts_path, '') AS artifacts_path
+		FROM organizations o
+		LEFT JOIN migrations m ON m.organization_id = o.id AND m.current_run_id IS NOT NULL
+		LEFT JOIN migration_runs r ON r.id = m.current_run_id
+		WHERE o.data_region IS NOT NULL AND ($1 = '' OR o.data_region = $1)
+		ORDER BY o.id, m.id
+	`, region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build data residency report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResidencyReport(rows))
+}
+
+// dataResidencyReport groups the migrations of pinned organizations by organization and
+// picks out those generated on another region's instance or stored outside the bucket
+func dataResidencyReport(rows []residencyRow) []DataResidencyOrganization {
+	report := []DataResidencyOrganization{}
+	for _, row := range rows {
+		if len(report) == 0 || report[len(report)-1].OrganizationID != row.OrganizationID {
+			report = append(report, DataResidencyOrganization{
+				OrganizationID:   row.OrganizationID,
+				OrganizationName: row.OrganizationName,
+				DataRegion:       row.OrgRegion,
+				RegionAvailable:  aiservice.RegionAvailable(row.OrgRegion),
+				ArtifactBucket:   aiservice.ArtifactBucket(row.OrgRegion),
+				OutOfRegion:      []DataResidencyMigration{},
+			})
+		}
+		org := &report[len(report)-1]
+		if row.MigrationID == 0 {
+			continue
+		}
+
+		org.Migrations++
+		migration := row.DataResidencyMigration
+		switch {
+		case migration.DataRegion != org.DataRegion:
+			migration.Reason = "generated_out_of_region"
+		case org.ArtifactBucket != "" && migration.ArtifactsPath != "" && !aiservice.InBucket(migration.ArtifactsPath, org.ArtifactBucket):
+			migration.Reason = "artifacts_out_of_region"
+		default:
+			org.InRegion++
+			continue
+		}
+		org.OutOfRegion = append(org.OutOfRegion, migration)
+	}
+	return report
+}
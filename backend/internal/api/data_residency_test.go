@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+)
+
+func TestDataResidencyReport(t *testing.T) {
+	aiservice.InitRegions(map[string]aiservice.RegionConfig{
+		aiservice.RegionEU: {URL: "https://ai-eu.internal", ArtifactBucket: "artifacts-eu"},
+	})
+	t.Cleanup(func() { aiservice.InitRegions(nil) })
+
+	migration := func(id int64, region, path string) DataResidencyMigration {
+		return DataResidencyMigration{MigrationID: id, Name: "m", DataRegion: region, ArtifactsPath: path}
+	}
+	report := dataResidencyReport([]residencyRow{
+		{OrganizationID: 3, OrganizationName: "Contoso", OrgRegion: "eu", DataResidencyMigration: migration(1, "eu", "s3://artifacts-eu/1")},
+		{OrganizationID: 3, OrganizationName: "Contoso", OrgRegion: "eu", DataResidencyMigration: migration(2, "", "/data/projects/2")},
+		{OrganizationID: 3, OrganizationName: "Contoso", OrgRegion: "eu", DataResidencyMigration: migration(3, "eu", "s3://artifacts-us/3")},
+		{OrganizationID: 5, OrganizationName: "Fabrikam", OrgRegion: "us"},
+	})
+
+	if len(report) != 2 {
+		t.Fatalf("report has %d organizations, want 2: %+v", len(report), report)
+	}
+	contoso := report[0]
+	if contoso.Migrations != 3 || contoso.InRegion != 1 || !contoso.RegionAvailable || contoso.ArtifactBucket != "artifacts-eu" {
+		t.Errorf("Contoso = %+v", contoso)
+	}
+	if len(contoso.OutOfRegion) != 2 ||
+		contoso.OutOfRegion[0].Reason != "generated_out_of_region" ||
+		contoso.OutOfRegion[1].Reason != "artifacts_out_of_region" {
+		t.Errorf("Contoso out of region = %+v", contoso.OutOfRegion)
+	}
+	fabrikam := report[1]
+	if fabrikam.Migrations != 0 || fabrikam.RegionAvailable || len(fabrikam.OutOfRegion) != 0 {
+		t.Errorf("Fabrikam = %+v", fabrikam)
+	}
+}
+
+func TestDataResidencyRequiresAdmin(t *testing.T) {
+	store, _ := newMockDB(t)
+	handler := NewDataResidencyHandler(store)
+
+	status, body := serve(t, "GET", "/admin/data-residency", "/admin/data-residency", nil, handler.Report)
+	expectStatus(t, status, http.StatusForbidden, body)
+
+	status, body = serve(t, "PUT", "/admin/organizations/:id/data-region", "/admin/organizations/3/data-region",
+		map[string]string{"data_region": "eu"}, handler.SetOrganizationRegion)
+	expectStatus(t, status, http.StatusForbidden, body)
+}
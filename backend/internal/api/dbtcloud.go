@@ -0,0 +1,436 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtcloud"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// dbtCloudRunsToSync is how many recent job runs are pulled into warehouse_deployments
+const dbtCloudRunsToSync = 20
+
+// cronFieldRegex matches one field of a 5-field cron expression
+var cronFieldRegex = regexp.MustCompile(`^[0-9*,/\-]+$`)
+
+// DbtCloudHandler provisions dbt Cloud jobs for completed migrations and syncs their
+// runs into the migration's deployments
+type DbtCloudHandler struct {
+	db db.Querier
+}
+
+func NewDbtCloudHandler(store db.Querier) *DbtCloudHandler {
+	return &DbtCloudHandler{db: store}
+}
+
+// ownedMigration loads the migration's status and target project if the user owns it.
+// On failure it writes the error response and returns false.
+func (h *DbtCloudHandler) ownedMigration(c *gin.Context) (int64, string, string, bool) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return 0, "", "", false
+	}
+
+	var migration struct {
+		Status        string `db:"status"`
+		TargetProject string `db:"target_project"`
+	}
+	err = h.db.Get(&migration, "SELECT status, target_project FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return 0, "", "", false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return 0, "", "", false
+	}
+	return id, migration.Status, migration.TargetProject, true
+}
+
+// Create provisions a dbt Cloud project, repository, environment and job
+// @Summary Create dbt Cloud job
+// @Description Create a dbt Cloud project linked to the repository holding the generated dbt project, a production environment and a job. With the deploy_key strategy, add the returned public key to the repository.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.CreateDbtCloudJobRequest true "dbt Cloud settings"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /migrations/{id}/dbt-cloud [post]
+func (h *DbtCloudHandler) Create(c *gin.Context) {
+	var req models.CreateDbtCloudJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := normalizeDbtCloudRequest(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	client := dbtcloud.GetClient()
+	if client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "dbt Cloud integration is not configured"})
+		return
+	}
+
+	id, status, targetProject, ok := h.ownedMigration(c)
+	if !ok {
+		return
+	}
+	if status != "completed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Migration is not completed yet"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM dbt_cloud_jobs WHERE migration_id = $1)", id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check dbt Cloud job"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "Migration already has a dbt Cloud job"})
+		return
+	}
+
+	if req.ProjectName == "" {
+		req.ProjectName = targetProject
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	project, err := client.CreateProject(ctx, req.ProjectName)
+	if err != nil {
+		dbtCloudError(c, id, "create project", err)
+		return
+	}
+	// Later steps name the project ID so a half-provisioned project can be found and removed
+	repo, err := client.CreateRepository(ctx, project.ID, req.RepositoryURL, req.GitCloneStrategy, req.GithubInstallationID)
+	if err != nil {
+		dbtCloudError(c, id, fmt.Sprintf("link repository to project %d", project.ID), err)
+		return
+	}
+	env, err := client.CreateEnvironment(ctx, project.ID, req.EnvironmentName, req.DbtVersion, req.CredentialsID)
+	if err != nil {
+		dbtCloudError(c, id, fmt.Sprintf("create environment in project %d", project.ID), err)
+		return
+	}
+	job, err := client.CreateJob(ctx, project.ID, env.ID, req.ProjectName+" - "+req.EnvironmentName, req.ExecuteSteps, req.ScheduleCron)
+	if err != nil {
+		dbtCloudError(c, id, fmt.Sprintf("create job in project %d", project.ID), err)
+		return
+	}
+
+	var schedule *string
+	if req.ScheduleCron != "" {
+		schedule = &req.ScheduleCron
+	}
+	var saved models.DbtCloudJob
+	err = h.db.Get(&saved, `
+		INSERT INTO dbt_cloud_jobs (migration_id, account_id, project_id, repository_id, environment_id, job_id,
+		                            repository_url, schedule_cron, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, migration_id, account_id, project_id, repository_id, environment_id, job_id,
+		          repository_url, schedule_cron, created_at, updated_at
+	`, id, client.AccountID(), project.ID, repo.ID, env.ID, job.ID, req.RepositoryURL, schedule, middleware.GetUserID(c))
+	if err != nil {
+		log.Printf("Failed to save dbt Cloud job %d for migration %d: %v", job.ID, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "dbt Cloud job was created but could not be saved", "job_id": job.ID})
+		return
+	}
+
+	response := gin.H{"job": saved}
+	if repo.DeployKey != nil && repo.DeployKey.PublicKey != "" {
+		response["deploy_key"] = repo.DeployKey.PublicKey
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// normalizeDbtCloudRequest fills in defaults and returns a message if the request is invalid
+func normalizeDbtCloudRequest(req *models.CreateDbtCloudJobRequest) string {
+	if req.GitCloneStrategy == "" {
+		req.GitCloneStrategy = "deploy_key"
+	}
+	switch req.GitCloneStrategy {
+	case "deploy_key":
+	case "github_app":
+		if req.GithubInstallationID == 0 {
+			return "github_installation_id is required for the github_app strategy"
+		}
+	default:
+		return "git_clone_strategy must be deploy_key or github_app"
+	}
+	if req.EnvironmentName == "" {
+		req.EnvironmentName = "Production"
+	}
+	if req.DbtVersion == "" {
+		req.DbtVersion = "latest"
+	}
+	if len(req.ExecuteSteps) == 0 {
+		req.ExecuteSteps = []string{"dbt build"}
+	}
+	for _, step := range req.ExecuteSteps {
+		if !strings.HasPrefix(step, "dbt ") {
+			return "execute_steps must be dbt commands"
+		}
+	}
+	if req.ScheduleCron != "" {
+		fields := strings.Fields(req.ScheduleCron)
+		if len(fields) != 5 {
+			return "schedule_cron must have 5 fields"
+		}
+		for _, field := range fields {
+			if !cronFieldRegex.MatchString(field) {
+				return "schedule_cron has an invalid field: " + field
+			}
+		}
+		req.ScheduleCron = strings.Join(fields, " ")
+	}
+	return ""
+}
+
+func dbtCloudError(c *gin.Context, migrationID int64, step string, err error) {
+	log.Printf("dbt Cloud: failed to %s for migration %d: %v", step, migrationID, err)
+	c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to " + step + " in dbt Cloud", "details": err.Error()})
+}
+
+// Get returns the migration's dbt Cloud job
+// @Summary Get dbt Cloud job
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} models.DbtCloudJob
+// @Failure 404 {object} map[string]string
+// @Router /migrations/{id}/dbt-cloud [get]
+func (h *DbtCloudHandler) Get(c *gin.Context) {
+	id, _, _, ok := h.ownedMigration(c)
+	if !ok {
+		return
+	}
+
+	job, err := getDbtCloudJob(h.db, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration has no dbt Cloud job"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dbt Cloud job"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// Run triggers the migration's dbt Cloud job
+// @Summary Run dbt Cloud job
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 202 {object} models.WarehouseDeployment
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /migrations/{id}/dbt-cloud/run [post]
+func (h *DbtCloudHandler) Run(c *gin.Context) {
+	client := dbtcloud.GetClient()
+	if client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "dbt Cloud integration is not configured"})
+		return
+	}
+
+	id, _, _, ok := h.ownedMigration(c)
+	if !ok {
+		return
+	}
+	job, err := getDbtCloudJob(h.db, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration has no dbt Cloud job"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dbt Cloud job"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	run, err := client.TriggerRun(c.Request.Context(), job.JobID, fmt.Sprintf("Triggered from DataMigrate AI by user %d", userID))
+	if err != nil {
+		dbtCloudError(c, id, "trigger run", err)
+		return
+	}
+
+	deployment, err := upsertDbtCloudRun(h.db, id, userID, *run)
+	if err != nil {
+		log.Printf("Failed to record dbt Cloud run %d for migration %d: %v", run.ID, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Run was triggered but could not be recorded", "run_id": run.ID})
+		return
+	}
+	c.JSON(http.StatusAccepted, deployment)
+}
+
+// GetDeployments lists the migration's deployments, syncing recent dbt Cloud runs first
+// @Summary List deployments
+// @Description List warehouse deployments for a migration, including runs of its dbt Cloud job
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /migrations/{id}/deployments [get]
+func (h *DbtCloudHandler) GetDeployments(c *gin.Context) {
+	id, _, _, ok := h.ownedMigration(c)
+	if !ok {
+		return
+	}
+
+	// A dbt Cloud outage shouldn't hide the deployments already recorded
+	var syncErr string
+	if client := dbtcloud.GetClient(); client != nil {
+		if job, err := getDbtCloudJob(h.db, id); err == nil {
+			if err := h.syncRuns(c.Request.Context(), client, id, job); err != nil {
+				log.Printf("Failed to sync dbt Cloud runs for migration %d: %v", id, err)
+				syncErr = "Could not refresh dbt Cloud runs"
+			}
+		}
+	}
+
+	deployments := []models.WarehouseDeployment{}
+	err := h.db.Select(&deployments, `
+		SELECT id, migration_id, COALESCE(connection_id, 0) as connection_id, status, dbt_run_status, dbt_test_status,
+		       tables_created, tests_passed, tests_failed, dbt_run_output, dbt_test_output, error,
+		       dbt_cloud_run_id, dbt_cloud_run_url, retry_of, COALESCE(user_id, 0) as user_id, created_at, completed_at
+		FROM warehouse_deployments
+		WHERE migration_id = $1
+		ORDER BY created_at DESC
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployments"})
+		return
+	}
+	if err := loadReconciliations(h.db, id, deployments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployments"})
+		return
+	}
+
+	response := gin.H{"migration_id": id, "deployments": deployments}
+	if syncErr != "" {
+		response["warning"] = syncErr
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *DbtCloudHandler) syncRuns(ctx context.Context, client *dbtcloud.Client, migrationID int64, job *models.DbtCloudJob) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	runs, err := client.ListRuns(ctx, job.JobID, dbtCloudRunsToSync)
+	if err != nil {
+		return err
+	}
+
+	// Scheduled runs have no triggering user; attribute them to whoever created the job
+	var createdBy sql.NullInt64
+	if err := h.db.Get(&createdBy, "SELECT created_by FROM dbt_cloud_jobs WHERE id = $1", job.ID); err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if _, err := upsertDbtCloudRun(h.db, migrationID, createdBy.Int64, run); err != nil {
+			return err
+		}
+	}
+	return reconcileRetries(ctx, h.db, client, migrationID)
+}
+
+func getDbtCloudJob(store db.Querier, migrationID int64) (*models.DbtCloudJob, error) {
+	var job models.DbtCloudJob
+	err := store.Get(&job, `
+		SELECT id, migration_id, account_id, project_id, repository_id, environment_id, job_id,
+		       repository_url, schedule_cron, created_at, updated_at
+		FROM dbt_cloud_jobs
+		WHERE migration_id = $1
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// upsertDbtCloudRun records a dbt Cloud run as a deployment, updating it as the run progresses
+func upsertDbtCloudRun(store db.Querier, migrationID, userID int64, run dbtcloud.Run) (models.WarehouseDeployment, error) {
+	status := run.DeploymentStatus()
+	var runError *string
+	if status == "failed" && run.StatusMessage != nil {
+		runError = run.StatusMessage
+	}
+	runStatus := strings.ToLower(run.StatusHumanized)
+	var user *int64
+	if userID != 0 {
+		user = &userID
+	}
+
+	deployment := models.WarehouseDeployment{
+		MigrationID:    migrationID,
+		Status:         status,
+		DbtRunStatus:   &runStatus,
+		Error:          runError,
+		DbtCloudRunID:  &run.ID,
+		DbtCloudRunURL: &run.Href,
+		UserID:         userID,
+		CompletedAt:    parseDbtCloudTime(run.FinishedAt),
+	}
+	createdAt := time.Now()
+	if t := parseDbtCloudTime(&run.CreatedAt); t != nil {
+		createdAt = *t
+	}
+
+	err := store.QueryRow(`
+		INSERT INTO warehouse_deployments (migration_id, status, dbt_run_status, error, dbt_cloud_run_id, dbt_cloud_run_url,
+		                                   user_id, created_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (dbt_cloud_run_id) DO UPDATE
+		SET status = CASE WHEN warehouse_deployments.retries_passed_at IS NULL THEN EXCLUDED.status ELSE warehouse_deployments.status END,
+		    dbt_run_status = EXCLUDED.dbt_run_status,
+		    error = CASE WHEN warehouse_deployments.retries_passed_at IS NULL THEN EXCLUDED.error END,
+		    dbt_cloud_run_url = EXCLUDED.dbt_cloud_run_url, completed_at = EXCLUDED.completed_at
+		RETURNING id, created_at
+	`, migrationID, status, runStatus, runError, run.ID, run.Href, user, createdAt, deployment.CompletedAt).
+		Scan(&deployment.ID, &deployment.CreatedAt)
+	return deployment, err
+}
+
+// parseDbtCloudTime parses API timestamps such as "2024-05-01 09:30:12.345678+00:00"
+func parseDbtCloudTime(value *string) *time.Time {
+	if value == nil || *value == "" {
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05.999999-07:00", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, *value); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
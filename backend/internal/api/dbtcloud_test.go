@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDbtCloudCreateRejectsInvalidSchedule(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "POST", "/migrations/:id/dbt-cloud", "/migrations/7/dbt-cloud", map[string]interface{}{
+		"repository_url": "git@github.com:acme/sales_dbt.git",
+		"schedule_cron":  "every hour",
+	}, NewDbtCloudHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if got := errorMessage(t, body); got != "schedule_cron must have 5 fields" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestParseDbtCloudTime(t *testing.T) {
+	value := "2024-05-01 09:30:12.345678+02:00"
+	got := parseDbtCloudTime(&value)
+	want := time.Date(2024, 5, 1, 7, 30, 12, 345678000, time.UTC)
+	if got == nil || !got.Equal(want) {
+		t.Errorf("parseDbtCloudTime(%q) = %v, want %v", value, got, want)
+	}
+
+	if got := parseDbtCloudTime(nil); got != nil {
+		t.Errorf("parseDbtCloudTime(nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtlog"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSuggestedFixes is how many knowledge base articles are suggested per error
+const maxSuggestedFixes = 3
+
+// fixSearchTerms are the knowledge base search terms for each error category, as a
+// Postgres tsquery. Categories without terms get no suggestions.
+var fixSearchTerms = map[string]string{
+	dbtlog.CategoryMissingRelation:   "source | ref | relation | schema",
+	dbtlog.CategoryMissingColumn:     "column | rename | alias | staging",
+	dbtlog.CategoryMissingRef:        "ref | dependency | dag | model",
+	dbtlog.CategorySyntax:            "syntax | jinja | sql",
+	dbtlog.CategoryTypeMismatch:      "cast | type | conversion",
+	dbtlog.CategoryPermission:        "grant | permission | role",
+	dbtlog.CategoryTimeout:           "performance | incremental | materialization",
+	dbtlog.CategoryNotNullTest:       "null | test",
+	dbtlog.CategoryUniqueTest:        "unique | duplicate | grain",
+	dbtlog.CategoryRelationshipsTest: "relationship | foreign | orphan",
+	dbtlog.CategoryAcceptedValues:    "accepted | value | test",
+	dbtlog.CategoryDataTest:          "test",
+}
+
+// failedDeployment is what triaging a deployment needs of it
+type failedDeployment struct {
+	Status        string  `db:"status"`
+	TestsFailed   int     `db:"tests_failed"`
+	DbtRunOutput  *string `db:"dbt_run_output"`
+	DbtTestOutput *string `db:"dbt_test_output"`
+	Error         *string `db:"error"`
+	Parsed        bool    `db:"parsed"`
+}
+
+// GetDeploymentErrors lists a deployment's failed models and tests with suggested fixes
+// @Summary Triage deployment errors
+// @Description The failed models and tests of a deployment, parsed from its dbt run and test output into the node, error class and SQL line, each with suggested fixes from the knowledge base. Output is parsed the first time a failed deployment's errors are requested.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Deployment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /deployments/{id}/errors [get]
+func (h *DbtCloudHandler) GetDeploymentErrors(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deployment ID"})
+		return
+	}
+
+	var deployment failedDeployment
+	err = h.db.Get(&deployment, `
+		SELECT d.status, d.tests_failed, d.dbt_run_output, d.dbt_test_output, d.error,
+		       d.errors_parsed_at IS NOT NULL AS parsed
+		FROM warehouse_deployments d
+		JOIN migrations m ON m.id = d.migration_id
+		WHERE d.id = $1 AND m.user_id = $2 AND COALESCE(m.organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployment"})
+		return
+	}
+
+	errs := []models.DeploymentError{}
+	if deployment.Status == "failed" || deployment.TestsFailed > 0 {
+		if !deployment.Parsed {
+			if err := parseDeploymentErrors(h.db, id, deployment); err != nil {
+				log.Printf("Failed to parse errors of deployment %d: %v", id, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse deployment errors"})
+				return
+			}
+		}
+		err = h.db.Select(&errs, `
+			SELECT id, phase, resource_type, resource, file_path, error_class, category, line, message
+			FROM deployment_errors
+			WHERE deployment_id = $1
+			ORDER BY id
+		`, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployment errors"})
+			return
+		}
+		suggestFixes(h.db, errs)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deployment_id": id, "status": deployment.Status, "errors": errs})
+}
+
+// parseDeploymentErrors stores the errors in a failed deployment's dbt output. Claiming
+// the deployment first keeps concurrent requests from storing them twice.
+func parseDeploymentErrors(store db.Querier, id int64, deployment failedDeployment) error {
+	result, err := store.Exec("UPDATE warehouse_deployments SET errors_parsed_at = NOW() WHERE id = $1 AND errors_parsed_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+
+	var output []string
+	for _, o := range []*string{deployment.DbtRunOutput, deployment.DbtTestOutput} {
+		if o != nil {
+			output = append(output, *o)
+		}
+	}
+	errs := dbtlog.Parse(strings.Join(output, "\n"))
+	if len(errs) == 0 && deployment.Error != nil && strings.TrimSpace(*deployment.Error) != "" {
+		// dbt Cloud runs and failures before dbt started only have an error message
+		if errs = dbtlog.Parse(*deployment.Error); len(errs) == 0 {
+			errs = []dbtlog.Error{dbtlog.FromMessage(*deployment.Error)}
+		}
+	}
+
+	for _, e := range errs {
+		_, err := store.Exec(`
+			INSERT INTO deployment_errors (deployment_id, phase, resource_type, resource, file_path, error_class, category, line, message)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, NULLIF($8, 0), $9)
+		`, id, e.Phase, e.ResourceType, e.Resource, e.FilePath, e.Class, e.Category, e.Line, e.Message)
+		if err != nil {
+			// Let the next request parse the output again
+			if _, resetErr := store.Exec("DELETE FROM deployment_errors WHERE deployment_id = $1", id); resetErr == nil {
+				store.Exec("UPDATE warehouse_deployments SET errors_parsed_at = NULL WHERE id = $1", id)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// suggestFixes attaches knowledge base articles matching each error's category. The
+// knowledge base is part of the optional RAG schema, so errors get no suggestions when
+// it isn't available.
+func suggestFixes(store db.Querier, errs []models.DeploymentError) {
+	articles := map[string][]models.KnowledgeArticle{}
+	available := true
+	for i := range errs {
+		category := errs[i].Category
+		found, ok := articles[category]
+		if !ok {
+			found = []models.KnowledgeArticle{}
+			if terms := fixSearchTerms[category]; terms != "" && available {
+				err := store.Select(&found, `
+					SELECT id, category, title, content, source
+					FROM knowledge_embeddings
+					WHERE to_tsvector('english', title || ' ' || content) @@ to_tsquery('english', $1)
+					ORDER BY ts_rank(to_tsvector('english', title || ' ' || content), to_tsquery('english', $1)) DESC, id
+					LIMIT $2
+				`, terms, maxSuggestedFixes)
+				if err != nil {
+					log.Printf("Failed to search the knowledge base for %s fixes: %v", category, err)
+					found = []models.KnowledgeArticle{}
+					available = false
+				}
+			}
+			articles[category] = found
+		}
+		errs[i].SuggestedFixes = found
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func TestGetDeploymentErrorsParsesFailedDeployment(t *testing.T) {
+	store, mock := newMockDB(t)
+	runOutput := "10:00:01  Database Error in model stg_orders (models/staging/stg_orders.sql)\n" +
+		"10:00:01    column \"order_dt\" does not exist\n" +
+		"10:00:01    LINE 12:     order_dt as ordered_at\n"
+
+	mock.ExpectQuery(`FROM warehouse_deployments d\s+JOIN migrations m`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "tests_failed", "dbt_run_output", "dbt_test_output", "error", "parsed"}).
+			AddRow("failed", 0, runOutput, nil, "dbt run failed", false))
+	mock.ExpectExec(`UPDATE warehouse_deployments SET errors_parsed_at = NOW\(\)`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO deployment_errors`).
+		WithArgs(int64(5), "run", "model", "stg_orders", "models/staging/stg_orders.sql", "database_error", "missing_column", 12,
+			"column \"order_dt\" does not exist\nLINE 12:     order_dt as ordered_at").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`FROM deployment_errors`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "phase", "resource_type", "resource", "file_path", "error_class", "category", "line", "message"}).
+			AddRow(1, "run", "model", "stg_orders", "models/staging/stg_orders.sql", "database_error", "missing_column", 12, `column "order_dt" does not exist`))
+	mock.ExpectQuery(`FROM knowledge_embeddings`).
+		WithArgs("column | rename | alias | staging", maxSuggestedFixes).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "category", "title", "content", "source"}).
+			AddRow(9, "naming", "Rename columns in staging models", "Alias source columns once, in staging.", "dbt_docs"))
+
+	status, body := serve(t, "GET", "/deployments/:id/errors", "/deployments/5/errors", nil, NewDbtCloudHandler(store).GetDeploymentErrors)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Errors []models.DeploymentError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response is not JSON: %s", body)
+	}
+	if len(resp.Errors) != 1 {
+		t.Fatalf("errors = %s", body)
+	}
+	e := resp.Errors[0]
+	if e.Line == nil || *e.Line != 12 || e.Category != "missing_column" {
+		t.Errorf("error = %+v", e)
+	}
+	if len(e.SuggestedFixes) != 1 || e.SuggestedFixes[0].Title != "Rename columns in staging models" {
+		t.Errorf("suggested_fixes = %+v", e.SuggestedFixes)
+	}
+}
+
+func TestGetDeploymentErrorsWithoutKnowledgeBase(t *testing.T) {
+	store, mock := newMockDB(t)
+
+	mock.ExpectQuery(`FROM warehouse_deployments d`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "tests_failed", "dbt_run_output", "dbt_test_output", "error", "parsed"}).
+			AddRow("completed", 2, nil, nil, nil, true))
+	mock.ExpectQuery(`FROM deployment_errors`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "phase", "resource_type", "resource", "file_path", "error_class", "category", "line", "message"}).
+			AddRow(1, "test", "test", "unique_stg_orders_order_id", nil, "test_failure", "unique_test", nil, "Got 2 results").
+			AddRow(2, "test", "test", "not_null_stg_orders_customer_id", nil, "test_failure", "not_null_test", nil, "Got 1 result"))
+	// The RAG schema isn't installed; the knowledge base is searched once
+	mock.ExpectQuery(`FROM knowledge_embeddings`).
+		WillReturnError(fmt.Errorf(`relation "knowledge_embeddings" does not exist`))
+
+	status, body := serve(t, "GET", "/deployments/:id/errors", "/deployments/5/errors", nil, NewDbtCloudHandler(store).GetDeploymentErrors)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Errors []struct {
+			SuggestedFixes []models.KnowledgeArticle `json:"suggested_fixes"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Errors) != 2 {
+		t.Fatalf("errors = %s", body)
+	}
+	for _, e := range resp.Errors {
+		if e.SuggestedFixes == nil || len(e.SuggestedFixes) != 0 {
+			t.Errorf("suggested_fixes = %v, want []", e.SuggestedFixes)
+		}
+	}
+}
+
+func TestGetDeploymentErrorsSucceededDeployment(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM warehouse_deployments d`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "tests_failed", "dbt_run_output", "dbt_test_output", "error", "parsed"}).
+			AddRow("completed", 0, "Done. PASS=4", nil, nil, false))
+
+	status, body := serve(t, "GET", "/deployments/:id/errors", "/deployments/5/errors", nil, NewDbtCloudHandler(store).GetDeploymentErrors)
+	expectStatus(t, status, http.StatusOK, body)
+	if want := `{"deployment_id":5,"errors":[],"status":"completed"}`; string(body) != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
+
+func TestGetDeploymentErrorsNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM warehouse_deployments d`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+
+	status, body := serve(t, "GET", "/deployments/:id/errors", "/deployments/5/errors", nil, NewDbtCloudHandler(store).GetDeploymentErrors)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
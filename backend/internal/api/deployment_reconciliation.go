@@ -0,0 +1,372 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+const (
+	// defaultReconciliationSampleSize is how many rows per table are compared when a
+	// migration doesn't choose
+	defaultReconciliationSampleSize = 1000
+	maxReconciliationSampleSize     = 10000
+	// reconciliationSweepInterval is how often newly completed deployments are looked for
+	reconciliationSweepInterval = time.Minute
+	// reconciliationTimeout bounds sampling all tables of one deployment
+	reconciliationTimeout = 5 * time.Minute
+)
+
+// validateReconciliation checks a migration's reconciliation config. If tables is
+// non-empty, only selected tables can be reconciled.
+func validateReconciliation(result *validation.ValidationResult, cfg *models.ReconciliationConfig, tables []string) {
+	if cfg == nil {
+		return
+	}
+	if cfg.SampleSize < 0 || cfg.SampleSize > maxReconciliationSampleSize {
+		result.AddError("reconciliation.sample_size", fmt.Sprintf("must be between 1 and %d, or 0 for %d",
+			maxReconciliationSampleSize, defaultReconciliationSampleSize))
+	}
+	if len(cfg.Tables) == 0 {
+		result.AddError("reconciliation.tables", "At least one table is required")
+	}
+
+	selected := map[string]bool{}
+	for _, table := range tables {
+		selected[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+	seen := map[string]bool{}
+	for i := range cfg.Tables {
+		t := &cfg.Tables[i]
+		field := fmt.Sprintf("reconciliation.tables[%d]", i)
+		t.Table = strings.TrimSpace(t.Table)
+		t.Model = strings.TrimSpace(t.Model)
+		t.KeyColumns = trimNonEmpty(t.KeyColumns)
+		t.Columns = trimNonEmpty(t.Columns)
+
+		key := strings.ToLower(t.Table)
+		switch {
+		case t.Table == "":
+			result.AddError(field+".table", "is required")
+		case len(selected) > 0 && !selected[key]:
+			result.AddError(field+".table", fmt.Sprintf("%s is not one of the selected tables", t.Table))
+		case seen[key]:
+			result.AddError(field+".table", fmt.Sprintf("%s is listed more than once", t.Table))
+		}
+		seen[key] = true
+		if len(t.KeyColumns) == 0 {
+			result.AddError(field+".key_columns", "at least one column is required")
+		}
+	}
+}
+
+func trimNonEmpty(values []string) []string {
+	var trimmed []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
+
+// pendingReconciliation is a completed deployment of a migration that enables
+// reconciliation
+type pendingReconciliation struct {
+	ID             int64          `db:"id"`
+	MigrationID    int64          `db:"migration_id"`
+	OrganizationID int64          `db:"organization_id"`
+	SourceID       sql.NullInt64  `db:"source_id"`
+	TargetID       sql.NullInt64  `db:"target_id"`
+	Config         sql.NullString `db:"config"`
+}
+
+// reconcileDeployment samples the configured tables of a completed deployment and stores
+// the comparison. A deployment is reconciled once; connection problems are stored as the
+// tables' errors rather than returned.
+func reconcileDeployment(ctx context.Context, store db.Querier, d pendingReconciliation) error {
+	result, err := store.Exec("UPDATE warehouse_deployments SET reconciled_at = NOW() WHERE id = $1 AND reconciled_at IS NULL", d.ID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+
+	config, failure := loadMigrationConfig(d.MigrationID, d.Config)
+	if failure != nil {
+		return fmt.Errorf("%v", failure.Body["error"])
+	}
+	if config.Reconciliation == nil || len(config.Reconciliation.Tables) == 0 {
+		return nil
+	}
+
+	for _, r := range reconcileTables(ctx, store, d, *config.Reconciliation) {
+		_, err := store.Exec(`
+			INSERT INTO deployment_reconciliations (deployment_id, source_table, target_relation, rows_sampled,
+			                                        rows_missing, rows_mismatched, mismatch_pct, error)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, d.ID, r.SourceTable, r.TargetRelation, r.RowsSampled, r.RowsMissing, r.RowsMismatched, r.MismatchPct, r.Error)
+		if err != nil {
+			// Let the next sweep sample the deployment again
+			if _, resetErr := store.Exec("DELETE FROM deployment_reconciliations WHERE deployment_id = $1", d.ID); resetErr == nil {
+				store.Exec("UPDATE warehouse_deployments SET reconciled_at = NULL WHERE id = $1", d.ID)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileTables compares each configured table with its relation in the warehouse
+func reconcileTables(ctx context.Context, store db.Querier, d pendingReconciliation, cfg models.ReconciliationConfig) []models.DeploymentReconciliation {
+	results := make([]models.DeploymentReconciliation, len(cfg.Tables))
+	fail := func(message string) []models.DeploymentReconciliation {
+		for i, t := range cfg.Tables {
+			results[i] = models.DeploymentReconciliation{SourceTable: t.Table, Error: &message}
+		}
+		return results
+	}
+
+	source, err := loadConnectionByID(store, d.SourceID)
+	if err != nil {
+		return fail("Failed to load the source connection: " + err.Error())
+	}
+	target, err := loadConnectionByID(store, d.TargetID)
+	if err != nil {
+		return fail("Failed to load the warehouse connection: " + err.Error())
+	}
+	for _, c := range []*sourceConnection{source, target} {
+		if !dbtest.SupportsTableColumns(c.DBType) {
+			return fail(fmt.Sprintf("Reconciliation isn't supported for %s databases", c.DBType))
+		}
+	}
+
+	naming := models.DefaultNamingConventions
+	if d.OrganizationID != 0 {
+		defaults, err := loadOrganizationDefaults(store, d.OrganizationID)
+		if err != nil {
+			log.Printf("Failed to load organization defaults for migration %d: %v", d.MigrationID, err)
+		}
+		naming = defaults.NamingConventions
+	}
+	sourceNames := make([]string, len(cfg.Tables))
+	relations := make([]string, len(cfg.Tables))
+	for i, t := range cfg.Tables {
+		_, sourceNames[i] = splitTableName(t.Table)
+		relations[i] = t.Model
+		if relations[i] == "" {
+			relations[i] = dbtgen.ModelName(naming.StagingPrefix, t.Table)
+		}
+	}
+	sourceColumns, err := dbtest.TableColumns(ctx, source.params(), sourceNames)
+	if err != nil {
+		return fail("Failed to read the source schema: " + err.Error())
+	}
+	targetColumns, err := dbtest.TableColumns(ctx, target.params(), relations)
+	if err != nil {
+		return fail("Failed to read the warehouse schema: " + err.Error())
+	}
+	sourceTables, _ := groupWarehouseColumns(sourceColumns)
+	targetTables, deployed := groupWarehouseColumns(targetColumns)
+
+	size := cfg.SampleSize
+	if size == 0 {
+		size = defaultReconciliationSampleSize
+	}
+	for i, t := range cfg.Tables {
+		results[i] = reconcileTable(ctx, source, target, t, size,
+			sourceTables[strings.ToLower(sourceNames[i])], targetTables[strings.ToLower(relations[i])], deployed)
+	}
+	return results
+}
+
+// reconcileTable samples a source table and looks the sampled keys up in the warehouse.
+// sourceCandidates and targetCandidates are the columns of the table and relation by
+// schema.
+func reconcileTable(ctx context.Context, source, target *sourceConnection, t models.ReconciliationTable, size int,
+	sourceCandidates, targetCandidates map[string][]dbtest.WarehouseColumn, deployed string) models.DeploymentReconciliation {
+	result := models.DeploymentReconciliation{SourceTable: t.Table}
+	fail := func(format string, args ...interface{}) models.DeploymentReconciliation {
+		message := fmt.Sprintf(format, args...)
+		result.Error = &message
+		return result
+	}
+
+	sourceSchema := ""
+	if schema, _ := splitTableName(t.Table); schema == "" {
+		sourceSchema = pickDriftSchema(sourceCandidates, "", "")
+	} else {
+		for s := range sourceCandidates {
+			if strings.EqualFold(s, schema) {
+				sourceSchema = s
+			}
+		}
+	}
+	if sourceSchema == "" {
+		return fail("%s was not found in the source database", t.Table)
+	}
+	targetSchema := pickDriftSchema(targetCandidates, "", deployed)
+	if targetSchema == "" {
+		return fail("The table's model was not found in the warehouse")
+	}
+	relation := targetSchema + "." + targetCandidates[targetSchema][0].Table
+	result.TargetRelation = &relation
+
+	sourceTable, err := resolveSampleTable(sourceCandidates[sourceSchema], t)
+	if err != nil {
+		return fail("%s: %v", t.Table, err)
+	}
+	targetTable, err := resolveSampleTable(targetCandidates[targetSchema], t)
+	if err != nil {
+		return fail("%s: %v", relation, err)
+	}
+
+	sampled, err := dbtest.SampleRows(ctx, source.params(), sourceTable, size)
+	if err != nil {
+		return fail("Failed to sample the source table: %v", err)
+	}
+	keys := make([][]interface{}, len(sampled))
+	for i, r := range sampled {
+		keys[i] = r.KeyValues
+	}
+	found, err := dbtest.LookupRows(ctx, target.params(), targetTable, keys)
+	if err != nil {
+		return fail("Failed to read the sampled rows from the warehouse: %v", err)
+	}
+
+	comparison := dbtest.CompareSamples(sampled, found)
+	pct := math.Round(comparison.MismatchPct()*100) / 100
+	result.RowsSampled = comparison.Sampled
+	result.RowsMissing = comparison.Missing
+	result.RowsMismatched = comparison.Mismatched
+	result.MismatchPct = &pct
+	return result
+}
+
+// resolveSampleTable spells the configured key and compared columns as they are in the
+// database, matching them case-insensitively
+func resolveSampleTable(columns []dbtest.WarehouseColumn, t models.ReconciliationTable) (dbtest.SampleTable, error) {
+	actual := map[string]string{}
+	for _, c := range columns {
+		actual[strings.ToLower(c.Column)] = c.Column
+	}
+	resolve := func(names []string) ([]string, error) {
+		resolved := make([]string, len(names))
+		for i, name := range names {
+			column, ok := actual[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("column %s does not exist", name)
+			}
+			resolved[i] = column
+		}
+		return resolved, nil
+	}
+
+	table := dbtest.SampleTable{Schema: columns[0].Schema, Table: columns[0].Table}
+	var err error
+	if table.Keys, err = resolve(t.KeyColumns); err != nil {
+		return table, err
+	}
+	table.Columns, err = resolve(t.Columns)
+	return table, err
+}
+
+// splitTableName splits a possibly schema-qualified table name
+func splitTableName(table string) (schema, name string) {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
+
+// loadConnectionByID loads a connection for a background job, which acts on behalf of
+// the migration's owner
+func loadConnectionByID(store db.Querier, id sql.NullInt64) (*sourceConnection, error) {
+	if !id.Valid {
+		return nil, fmt.Errorf("no connection is set")
+	}
+	var connection sourceConnection
+	err := store.Get(&connection, `
+		SELECT id, name, db_type, host, port, database_name, username, password,
+		       COALESCE(use_windows_auth, false) AS use_windows_auth, extra_config
+		FROM database_connections
+		WHERE id = $1
+	`, id.Int64)
+	if err != nil {
+		return nil, err
+	}
+	return &connection, nil
+}
+
+// loadReconciliations attaches the reconciliation results of a migration's deployments
+func loadReconciliations(store db.Querier, migrationID int64, deployments []models.WarehouseDeployment) error {
+	var rows []struct {
+		DeploymentID int64 `db:"deployment_id"`
+		models.DeploymentReconciliation
+	}
+	err := store.Select(&rows, `
+		SELECT r.deployment_id, r.source_table, r.target_relation, r.rows_sampled, r.rows_missing,
+		       r.rows_mismatched, r.mismatch_pct, r.error
+		FROM deployment_reconciliations r
+		JOIN warehouse_deployments d ON d.id = r.deployment_id
+		WHERE d.migration_id = $1
+		ORDER BY r.deployment_id, r.id
+	`, migrationID)
+	if err != nil {
+		return err
+	}
+	byDeployment := map[int64][]models.DeploymentReconciliation{}
+	for _, r := range rows {
+		byDeployment[r.DeploymentID] = append(byDeployment[r.DeploymentID], r.DeploymentReconciliation)
+	}
+	for i := range deployments {
+		deployments[i].Reconciliation = byDeployment[deployments[i].ID]
+	}
+	return nil
+}
+
+// StartReconciliation periodically samples the tables of newly completed deployments of
+// migrations that enable reconciliation. Deployments completed more than a day ago are
+// left alone, so enabling it doesn't sample a migration's whole history.
+func StartReconciliation() {
+	go func() {
+		ticker := time.NewTicker(reconciliationSweepInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			var pending []pendingReconciliation
+			// dbt Cloud runs have no connection; they build into the migration's target
+			err := db.DB.Select(&pending, `
+				SELECT d.id, d.migration_id, COALESCE(m.organization_id, 0) AS organization_id, m.connection_id AS source_id,
+				       COALESCE(d.connection_id, (m.config->>'target_connection_id')::int) AS target_id, m.config
+				FROM warehouse_deployments d
+				JOIN migrations m ON m.id = d.migration_id
+				WHERE d.status = 'completed' AND d.reconciled_at IS NULL AND d.retry_of IS NULL
+				  AND d.completed_at > NOW() - INTERVAL '1 day'
+				  AND m.config->'reconciliation' IS NOT NULL
+				ORDER BY d.completed_at
+			`)
+			if err != nil {
+				log.Printf("Failed to fetch deployments to reconcile: %v", err)
+				continue
+			}
+			for _, d := range pending {
+				ctx, cancel := context.WithTimeout(context.Background(), reconciliationTimeout)
+				if err := reconcileDeployment(ctx, db.DB, d); err != nil {
+					log.Printf("Failed to reconcile deployment %d of migration %d: %v", d.ID, d.MigrationID, err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+func TestValidateReconciliation(t *testing.T) {
+	cfg := &models.ReconciliationConfig{
+		SampleSize: 20000,
+		Tables: []models.ReconciliationTable{
+			{Table: " Sales.Customer ", KeyColumns: []string{" CustomerID ", ""}, Columns: []string{"Name"}},
+			{Table: "sales.customer", KeyColumns: []string{"CustomerID"}},
+			{Table: "HR.Employee"},
+		},
+	}
+	result := validation.NewValidationResult()
+	validateReconciliation(result, cfg, []string{"Sales.Customer"})
+
+	var fields []string
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	want := []string{
+		"reconciliation.sample_size",
+		"reconciliation.tables[1].table",
+		"reconciliation.tables[2].table",
+		"reconciliation.tables[2].key_columns",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("errors on %q, want %q", fields, want)
+	}
+	if got := cfg.Tables[0]; got.Table != "Sales.Customer" || !reflect.DeepEqual(got.KeyColumns, []string{"CustomerID"}) {
+		t.Errorf("table was not normalized: %+v", got)
+	}
+}
+
+func TestMigrationsCreateRejectsInvalidReconciliation(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":            "Sales migration",
+		"source_database": "AdventureWorks",
+		"target_project":  "sales_dbt",
+		"reconciliation": map[string]interface{}{
+			"tables": []map[string]interface{}{{"table": "Sales.Customer"}},
+		},
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if !strings.Contains(string(body), "key_columns") {
+		t.Errorf("body = %s, want a key_columns error", body)
+	}
+}
+
+func TestReconcileDeploymentStoresUnsupportedWarehouse(t *testing.T) {
+	store, mock := newMockDB(t)
+	connectionColumns := []string{"id", "name", "db_type", "host", "port", "database_name", "username", "password", "use_windows_auth", "extra_config"}
+
+	mock.ExpectExec(`SET reconciled_at = NOW\(\)`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM database_connections`).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows(connectionColumns).AddRow(2, "erp", "mssql", "erp.local", 1433, "erp", "sa", "", false, nil))
+	mock.ExpectQuery(`FROM database_connections`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(connectionColumns).AddRow(4, "warehouse", "snowflake", "acme.snowflakecomputing.com", 443, "analytics", "dbt", "", false, nil))
+	mock.ExpectExec(`INSERT INTO deployment_reconciliations`).
+		WithArgs(int64(5), "Sales.Customer", nil, 0, 0, 0, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := reconcileDeployment(context.Background(), store, pendingReconciliation{
+		ID:          5,
+		MigrationID: 8,
+		SourceID:    sql.NullInt64{Int64: 2, Valid: true},
+		TargetID:    sql.NullInt64{Int64: 4, Valid: true},
+		Config:      sql.NullString{String: `{"reconciliation": {"tables": [{"table": "Sales.Customer", "key_columns": ["CustomerID"]}]}}`, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("reconcileDeployment: %v", err)
+	}
+}
+
+func TestReconcileDeploymentAlreadyClaimed(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`SET reconciled_at = NOW\(\)`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := reconcileDeployment(context.Background(), store, pendingReconciliation{ID: 5, MigrationID: 8}); err != nil {
+		t.Fatalf("reconcileDeployment: %v", err)
+	}
+}
+
+func TestLoadReconciliations(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM deployment_reconciliations r`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"deployment_id", "source_table", "target_relation", "rows_sampled",
+			"rows_missing", "rows_mismatched", "mismatch_pct", "error"}).
+			AddRow(5, "Sales.Customer", "analytics.stg_customer", 1000, 2, 3, "0.50", nil))
+
+	deployments := []models.WarehouseDeployment{{ID: 6}, {ID: 5}}
+	if err := loadReconciliations(store, 8, deployments); err != nil {
+		t.Fatalf("loadReconciliations: %v", err)
+	}
+	if deployments[0].Reconciliation != nil {
+		t.Errorf("deployment 6 = %+v, want no reconciliation", deployments[0].Reconciliation)
+	}
+	got := deployments[1].Reconciliation
+	if len(got) != 1 || got[0].RowsMismatched != 3 || got[0].MismatchPct == nil || *got[0].MismatchPct != 0.5 {
+		t.Errorf("deployment 5 = %+v", got)
+	}
+}
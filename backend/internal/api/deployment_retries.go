@@ -0,0 +1,311 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtcloud"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// maxRetryAttempts is how many times a failed node is retried before it needs a fix
+const maxRetryAttempts = 3
+
+// retriedDeployment is what retrying a deployment needs of it
+type retriedDeployment struct {
+	MigrationID   int64  `db:"migration_id"`
+	Status        string `db:"status"`
+	TestsFailed   int    `db:"tests_failed"`
+	DbtCloudRunID *int64 `db:"dbt_cloud_run_id"`
+	RetryOf       *int64 `db:"retry_of"`
+}
+
+// RetryFailed re-runs only the failed nodes of a dbt Cloud deployment
+// @Summary Retry failed models
+// @Description Trigger a dbt Cloud run that builds only the models, tests, seeds and snapshots that errored, failed or were skipped in the deployment, like dbt retry. Each node is retried at most 3 times; the deployment is marked completed once all of them pass.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Deployment ID"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /deployments/{id}/retry-failed [post]
+func (h *DbtCloudHandler) RetryFailed(c *gin.Context) {
+	client := dbtcloud.GetClient()
+	if client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "dbt Cloud integration is not configured"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deployment ID"})
+		return
+	}
+	deployment, ok := h.retriedDeployment(c, id, userID)
+	if !ok {
+		return
+	}
+	if deployment.DbtCloudRunID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only dbt Cloud deployments can be retried"})
+		return
+	}
+	if deployment.RetryOf != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Retry the original deployment rather than one of its retries"})
+		return
+	}
+	job, err := getDbtCloudJob(h.db, deployment.MigrationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration has no dbt Cloud job"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dbt Cloud job"})
+		return
+	}
+
+	// Pick up retries that finished since the last sync, which may have completed the deployment
+	if err := h.syncRuns(c.Request.Context(), client, deployment.MigrationID, job); err != nil {
+		dbtCloudError(c, deployment.MigrationID, "sync runs", err)
+		return
+	}
+	if deployment, ok = h.retriedDeployment(c, id, userID); !ok {
+		return
+	}
+	switch {
+	case deployment.Status == "pending" || deployment.Status == "running":
+		c.JSON(http.StatusConflict, gin.H{"error": "Deployment is still running"})
+		return
+	case deployment.Status != "failed" && deployment.TestsFailed == 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Deployment has no failures to retry"})
+		return
+	}
+
+	var running bool
+	err = h.db.Get(&running, "SELECT EXISTS(SELECT 1 FROM warehouse_deployments WHERE retry_of = $1 AND status IN ('pending', 'running'))", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check retries"})
+		return
+	}
+	if running {
+		c.JSON(http.StatusConflict, gin.H{"error": "A retry of this deployment is already running"})
+		return
+	}
+
+	nodes, err := h.retryNodes(c.Request.Context(), client, id, *deployment.DbtCloudRunID)
+	if err != nil {
+		dbtCloudError(c, deployment.MigrationID, "fetch run results", err)
+		return
+	}
+	var uniqueIDs []string
+	failed := 0
+	for _, n := range nodes {
+		if n.Status == "failed" {
+			failed++
+			if n.Attempts < maxRetryAttempts {
+				uniqueIDs = append(uniqueIDs, n.UniqueID)
+			}
+		}
+	}
+	switch {
+	case failed == 0:
+		c.JSON(http.StatusConflict, gin.H{"error": "dbt Cloud has no failed nodes for this run; trigger a full run instead"})
+		return
+	case len(uniqueIDs) == 0:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Failed nodes have been retried %d times; fix them and trigger a full run", maxRetryAttempts)})
+		return
+	}
+
+	step := "dbt build --select " + strings.Join(nodeSelectors(uniqueIDs), " ")
+	cause := fmt.Sprintf("Retry of failed nodes of run %d by user %d", *deployment.DbtCloudRunID, userID)
+	run, err := client.TriggerRun(c.Request.Context(), job.JobID, cause, step)
+	if err != nil {
+		dbtCloudError(c, deployment.MigrationID, "trigger retry", err)
+		return
+	}
+
+	retry, err := upsertDbtCloudRun(h.db, deployment.MigrationID, userID, *run)
+	if err == nil {
+		retry.RetryOf = &id
+		_, err = h.db.Exec("UPDATE warehouse_deployments SET retry_of = $1 WHERE id = $2", id, retry.ID)
+	}
+	if err == nil {
+		_, err = h.db.Exec(`
+			UPDATE deployment_retry_nodes SET attempts = attempts + 1, last_run_id = $2, updated_at = NOW()
+			WHERE deployment_id = $1 AND unique_id = ANY($3)
+		`, id, run.ID, pq.StringArray(uniqueIDs))
+	}
+	if err != nil {
+		log.Printf("Failed to record dbt Cloud retry run %d of deployment %d: %v", run.ID, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Retry was triggered but could not be recorded", "run_id": run.ID})
+		return
+	}
+
+	retried := map[string]bool{}
+	for _, uid := range uniqueIDs {
+		retried[uid] = true
+	}
+	for i := range nodes {
+		if retried[nodes[i].UniqueID] {
+			nodes[i].Attempts++
+			nodes[i].LastRunID = &run.ID
+		}
+	}
+	c.JSON(http.StatusAccepted, gin.H{"deployment_id": id, "retry": retry, "nodes": nodes})
+}
+
+// retriedDeployment loads the deployment if the user owns its migration. On failure it
+// writes the error response and returns false.
+func (h *DbtCloudHandler) retriedDeployment(c *gin.Context, id, userID int64) (retriedDeployment, bool) {
+	var deployment retriedDeployment
+	err := h.db.Get(&deployment, `
+		SELECT d.migration_id, d.status, d.tests_failed, d.dbt_cloud_run_id, d.retry_of
+		FROM warehouse_deployments d
+		JOIN migrations m ON m.id = d.migration_id
+		WHERE d.id = $1 AND m.user_id = $2 AND COALESCE(m.organization_id, 0) = $3
+	`, id, userID, middleware.GetOrganizationID(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found"})
+			return deployment, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployment"})
+		return deployment, false
+	}
+	return deployment, true
+}
+
+// retryNodes returns the deployment's failed nodes, recording them from the run's results
+// on its first retry
+func (h *DbtCloudHandler) retryNodes(ctx context.Context, client *dbtcloud.Client, id, runID int64) ([]models.DeploymentRetryNode, error) {
+	nodes := []models.DeploymentRetryNode{}
+	query := `
+		SELECT unique_id, status, attempts, last_run_id, updated_at
+		FROM deployment_retry_nodes
+		WHERE deployment_id = $1
+		ORDER BY unique_id
+	`
+	if err := h.db.Select(&nodes, query, id); err != nil || len(nodes) > 0 {
+		return nodes, err
+	}
+
+	results, err := client.RunResults(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if r.Passed() {
+			continue
+		}
+		_, err := h.db.Exec(`
+			INSERT INTO deployment_retry_nodes (deployment_id, unique_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, id, r.UniqueID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = h.db.Select(&nodes, query, id)
+	return nodes, err
+}
+
+// reconcileRetries records the outcome of the migration's finished retry runs, and
+// completes each deployment whose failed nodes have all passed
+func reconcileRetries(ctx context.Context, store db.Querier, client *dbtcloud.Client, migrationID int64) error {
+	var retries []struct {
+		ID      int64  `db:"id"`
+		RetryOf int64  `db:"retry_of"`
+		RunID   int64  `db:"dbt_cloud_run_id"`
+		Status  string `db:"status"`
+	}
+	err := store.Select(&retries, `
+		SELECT id, retry_of, dbt_cloud_run_id, status
+		FROM warehouse_deployments
+		WHERE migration_id = $1 AND retry_of IS NOT NULL AND retry_checked_at IS NULL
+		  AND status IN ('completed', 'failed')
+		ORDER BY id
+	`, migrationID)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range retries {
+		if r.Status == "completed" {
+			// Every node the run selected passed
+			_, err = store.Exec(`
+				UPDATE deployment_retry_nodes SET status = 'passed', updated_at = NOW()
+				WHERE deployment_id = $1 AND last_run_id = $2
+			`, r.RetryOf, r.RunID)
+		} else {
+			// A cancelled run may have no results, in which case its nodes stay failed
+			results, resultsErr := client.RunResults(ctx, r.RunID)
+			if resultsErr != nil {
+				log.Printf("Failed to fetch results of dbt Cloud retry run %d: %v", r.RunID, resultsErr)
+			}
+			var passed []string
+			for _, result := range results {
+				if result.Passed() {
+					passed = append(passed, result.UniqueID)
+				}
+			}
+			_, err = store.Exec(`
+				UPDATE deployment_retry_nodes SET status = 'passed', updated_at = NOW()
+				WHERE deployment_id = $1 AND last_run_id = $2 AND unique_id = ANY($3)
+			`, r.RetryOf, r.RunID, pq.StringArray(passed))
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := store.Exec("UPDATE warehouse_deployments SET retry_checked_at = NOW() WHERE id = $1", r.ID); err != nil {
+			return err
+		}
+		_, err = store.Exec(`
+			UPDATE warehouse_deployments
+			SET status = 'completed', error = NULL, tests_failed = 0, retries_passed_at = NOW()
+			WHERE id = $1 AND retries_passed_at IS NULL
+			  AND NOT EXISTS (SELECT 1 FROM deployment_retry_nodes WHERE deployment_id = $1 AND status = 'failed')
+		`, r.RetryOf)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeSelectors turns unique IDs into dbt node selectors: model.shop.stg_orders selects
+// stg_orders and source.shop.raw.orders selects source:raw.orders
+func nodeSelectors(uniqueIDs []string) []string {
+	seen := map[string]bool{}
+	var selectors []string
+	for _, uid := range uniqueIDs {
+		parts := strings.Split(uid, ".")
+		if len(parts) < 3 {
+			continue
+		}
+		selector := parts[2]
+		if parts[0] == "source" && len(parts) >= 4 {
+			selector = "source:" + parts[2] + "." + parts[3]
+		}
+		if !seen[selector] {
+			seen[selector] = true
+			selectors = append(selectors, selector)
+		}
+	}
+	sort.Strings(selectors)
+	return selectors
+}
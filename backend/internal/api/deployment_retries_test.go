@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/dbtcloud"
+)
+
+// fakeDbtCloud serves the dbt Cloud endpoints a retry calls and records the steps it was
+// triggered with
+func fakeDbtCloud(t *testing.T, steps *[]string) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/accounts/1/runs/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": []}`))
+	})
+	mux.HandleFunc("/api/v2/accounts/1/runs/500/artifacts/run_results.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": [
+			{"unique_id": "model.shop.stg_orders", "status": "error"},
+			{"unique_id": "model.shop.fct_orders", "status": "skipped"},
+			{"unique_id": "model.shop.dim_customers", "status": "success"},
+			{"unique_id": "test.shop.not_null_stg_orders_id.5d2a", "status": "fail"}
+		]}`))
+	})
+	mux.HandleFunc("/api/v2/accounts/1/jobs/77/run/", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			StepsOverride []string `json:"steps_override"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		*steps = body.StepsOverride
+		w.Write([]byte(`{"data": {"id": 501, "job_definition_id": 77, "status": 1, "status_humanized": "Queued",
+			"href": "https://cloud.getdbt.com/runs/501", "created_at": "2026-10-15 09:00:00.000000+00:00"}}`))
+	})
+	server := httptest.NewServer(mux)
+	dbtcloud.Init(server.URL, 1, "token")
+	t.Cleanup(func() {
+		dbtcloud.Init("", 0, "")
+		server.Close()
+	})
+}
+
+func TestRetryFailedRerunsOnlyFailedNodes(t *testing.T) {
+	store, mock := newMockDB(t)
+	var steps []string
+	fakeDbtCloud(t, &steps)
+	now := time.Now()
+	deploymentColumns := []string{"migration_id", "status", "tests_failed", "dbt_cloud_run_id", "retry_of"}
+	nodeColumns := []string{"unique_id", "status", "attempts", "last_run_id", "updated_at"}
+
+	mock.ExpectQuery(`FROM warehouse_deployments d\s+JOIN migrations m`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(deploymentColumns).AddRow(8, "failed", 0, 500, nil))
+	mock.ExpectQuery(`FROM dbt_cloud_jobs\s+WHERE migration_id`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "migration_id", "account_id", "project_id", "repository_id", "environment_id",
+			"job_id", "repository_url", "schedule_cron", "created_at", "updated_at"}).
+			AddRow(3, 8, 1, 10, 11, 12, 77, "git@github.com:acme/shop.git", nil, now, now))
+	mock.ExpectQuery(`SELECT created_by FROM dbt_cloud_jobs`).
+		WillReturnRows(sqlmock.NewRows([]string{"created_by"}).AddRow(testUserID))
+	mock.ExpectQuery(`retry_of IS NOT NULL AND retry_checked_at IS NULL`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "retry_of", "dbt_cloud_run_id", "status"}))
+	mock.ExpectQuery(`FROM warehouse_deployments d\s+JOIN migrations m`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(deploymentColumns).AddRow(8, "failed", 0, 500, nil))
+	mock.ExpectQuery(`WHERE retry_of = \$1 AND status IN`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`FROM deployment_retry_nodes`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(nodeColumns))
+	for _, uid := range []string{"model.shop.stg_orders", "model.shop.fct_orders", "test.shop.not_null_stg_orders_id.5d2a"} {
+		mock.ExpectExec(`INSERT INTO deployment_retry_nodes`).
+			WithArgs(int64(5), uid).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectQuery(`FROM deployment_retry_nodes`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(nodeColumns).
+			AddRow("model.shop.fct_orders", "failed", 0, nil, now).
+			AddRow("model.shop.stg_orders", "failed", 0, nil, now).
+			AddRow("test.shop.not_null_stg_orders_id.5d2a", "failed", 0, nil, now))
+	mock.ExpectQuery(`INSERT INTO warehouse_deployments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(6, now))
+	mock.ExpectExec(`UPDATE warehouse_deployments SET retry_of = \$1`).
+		WithArgs(int64(5), int64(6)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE deployment_retry_nodes SET attempts = attempts \+ 1`).
+		WithArgs(int64(5), int64(501), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	status, body := serve(t, "POST", "/deployments/:id/retry-failed", "/deployments/5/retry-failed", nil, NewDbtCloudHandler(store).RetryFailed)
+	expectStatus(t, status, http.StatusAccepted, body)
+
+	if want := []string{"dbt build --select fct_orders not_null_stg_orders_id stg_orders"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("steps_override = %q, want %q", steps, want)
+	}
+	var resp struct {
+		Retry struct {
+			ID      int64 `json:"id"`
+			RetryOf int64 `json:"retry_of"`
+		} `json:"retry"`
+		Nodes []struct {
+			Attempts  int   `json:"attempts"`
+			LastRunID int64 `json:"last_run_id"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response is not JSON: %s", body)
+	}
+	if resp.Retry.ID != 6 || resp.Retry.RetryOf != 5 {
+		t.Errorf("retry = %+v", resp.Retry)
+	}
+	for _, n := range resp.Nodes {
+		if n.Attempts != 1 || n.LastRunID != 501 {
+			t.Errorf("node = %+v, want 1 attempt in run 501", n)
+		}
+	}
+}
+
+func TestRetryFailedRejectsDirectDeployments(t *testing.T) {
+	store, mock := newMockDB(t)
+	var steps []string
+	fakeDbtCloud(t, &steps)
+	mock.ExpectQuery(`FROM warehouse_deployments d`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"migration_id", "status", "tests_failed", "dbt_cloud_run_id", "retry_of"}).
+			AddRow(8, "failed", 0, nil, nil))
+
+	status, body := serve(t, "POST", "/deployments/:id/retry-failed", "/deployments/5/retry-failed", nil, NewDbtCloudHandler(store).RetryFailed)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if got := errorMessage(t, body); got != "Only dbt Cloud deployments can be retried" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestReconcileRetriesCompletesDeployment(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`retry_of IS NOT NULL AND retry_checked_at IS NULL`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "retry_of", "dbt_cloud_run_id", "status"}).AddRow(6, 5, 501, "completed"))
+	mock.ExpectExec(`UPDATE deployment_retry_nodes SET status = 'passed'`).
+		WithArgs(int64(5), int64(501)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`SET retry_checked_at = NOW\(\)`).
+		WithArgs(int64(6)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SET status = 'completed'.+retries_passed_at = NOW\(\)`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// A completed retry needs no results from dbt Cloud
+	if err := reconcileRetries(context.Background(), store, nil, 8); err != nil {
+		t.Fatalf("reconcileRetries: %v", err)
+	}
+}
+
+func TestNodeSelectors(t *testing.T) {
+	got := nodeSelectors([]string{
+		"model.shop.stg_orders",
+		"test.shop.unique_stg_orders_id.a1b2",
+		"source.shop.raw.orders",
+		"model.shop.stg_orders",
+		"invalid",
+	})
+	want := []string{"source:raw.orders", "stg_orders", "unique_stg_orders_id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeSelectors = %q, want %q", got, want)
+	}
+}
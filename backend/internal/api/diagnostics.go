@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+type DiagnosticsHandler struct {
+	db db.Querier
+}
+
+func NewDiagnosticsHandler(store db.Querier) *DiagnosticsHandler {
+	return &DiagnosticsHandler{db: store}
+}
+
+// PasswordHashing reports the parameters new password hashes are made with and how
+// many stored hashes use each algorithm (admin only). Hashes made with other settings
+// are rehashed as their users sign in.
+// @Summary Password hashing diagnostics
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/diagnostics/password-hashing [get]
+func (h *DiagnosticsHandler) PasswordHashing(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	var stored struct {
+		Bcrypt   int `db:"bcrypt" json:"bcrypt"`
+		Argon2id int `db:"argon2id" json:"argon2id"`
+	}
+	err := h.db.Get(&stored, `
+		SELECT COUNT(*) FILTER (WHERE password LIKE '$2%') AS bcrypt,
+		       COUNT(*) FILTER (WHERE password LIKE '$argon2id$%') AS argon2id
+		FROM users
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count password hashes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"current":       security.CurrentPasswordHashing(),
+		"stored_hashes": stored,
+	})
+}
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPasswordHashingDiagnosticsRequiresAdmin(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/admin/diagnostics/password-hashing", "/admin/diagnostics/password-hashing", nil, NewDiagnosticsHandler(store).PasswordHashing)
+	expectStatus(t, status, http.StatusForbidden, body)
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailsHandler struct{}
+
+func NewEmailsHandler() *EmailsHandler {
+	return &EmailsHandler{}
+}
+
+// GetAll lists outbox emails so operators can inspect delivery failures (admin only)
+// @Summary List outbound emails
+// @Description List queued, sent, failed, and bounced emails with delivery attempts and errors. Bodies are never returned.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Status (pending, sending, sent, failed, bounced)"
+// @Param to query string false "Recipient address"
+// @Param since query string false "RFC3339 start time"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/emails [get]
+func (h *EmailsHandler) GetAll(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	filter := email.OutboxFilter{
+		Status:    c.Query("status"),
+		ToAddress: c.Query("to"),
+	}
+	switch filter.Status {
+	case "", email.OutboxPending, email.OutboxSending, email.OutboxSent, email.OutboxFailed, email.OutboxBounced:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of: pending, sending, sent, failed, bounced"})
+		return
+	}
+	if s := c.Query("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC3339 timestamp"})
+			return
+		}
+		filter.Since = &t
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	emails, err := email.ListOutbox(filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve emails"})
+		return
+	}
+
+	counts, err := email.OutboxCounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve email counts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"emails": emails,
+		"counts": counts,
+	})
+}
+
+// Retry requeues a failed or bounced email (admin only)
+// @Summary Retry an outbound email
+// @Description Reset a failed or bounced email's attempts and queue it for immediate delivery
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Email ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/emails/{id}/retry [post]
+func (h *EmailsHandler) Retry(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email ID"})
+		return
+	}
+
+	requeued, err := email.RetryOutboxEmail(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to requeue email"})
+		return
+	}
+	if !requeued {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No failed or bounced email with that ID"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email queued for delivery"})
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// Trigger event types
+const (
+	EventMigrationCompleted = "migration.completed"
+	EventMigrationFailed    = "migration.failed"
+	EventDeploymentFailed   = "deployment.failed"
+)
+
+// eventHistory is how far back events can be polled
+const eventHistory = 30 * 24 * time.Hour
+
+var sampleEventTime = time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC)
+
+// TriggerEventTypes are the events pollers can subscribe to, in the order they're offered
+var TriggerEventTypes = []models.TriggerEventType{
+	{
+		Type:        EventMigrationCompleted,
+		Label:       "Migration completed",
+		Description: "A migration run finished generating its dbt project. A re-run that completes is a new event.",
+		Sample: models.TriggerEvent{
+			ID:         EventMigrationCompleted + "-128",
+			Type:       EventMigrationCompleted,
+			OccurredAt: sampleEventTime,
+			Migration:  models.TriggerMigration{ID: 42, Name: "Sales migration", RunNumber: 2, TablesCount: 18, ModelsGenerated: 18, URL: "https://app.datamigrate.ai/migrations/42"},
+		},
+	},
+	{
+		Type:        EventMigrationFailed,
+		Label:       "Migration failed",
+		Description: "A migration run stopped with an error. Cancelled runs aren't failures.",
+		Sample: models.TriggerEvent{
+			ID:         EventMigrationFailed + "-129",
+			Type:       EventMigrationFailed,
+			OccurredAt: sampleEventTime,
+			Migration:  models.TriggerMigration{ID: 43, Name: "HR migration", RunNumber: 1, TablesCount: 6, Error: "Metadata extraction failed: login timeout", URL: "https://app.datamigrate.ai/migrations/43"},
+		},
+	},
+	{
+		Type:        EventDeploymentFailed,
+		Label:       "Deployment failed",
+		Description: "A deployment of a migration's dbt project to the warehouse failed.",
+		Sample: models.TriggerEvent{
+			ID:         EventDeploymentFailed + "-311",
+			Type:       EventDeploymentFailed,
+			OccurredAt: sampleEventTime,
+			Migration:  models.TriggerMigration{ID: 42, Name: "Sales migration", TablesCount: 18, ModelsGenerated: 18, URL: "https://app.datamigrate.ai/migrations/42"},
+			Deployment: &models.TriggerDeployment{ID: 311, Status: "failed", TestsFailed: 2, Error: "2 tests failed", DbtCloudRunURL: "https://cloud.getdbt.com/deploy/1/projects/2/runs/3"},
+		},
+	},
+}
+
+// EventsHandler serves migration lifecycle events to pollers
+type EventsHandler struct {
+	db db.Querier
+}
+
+func NewEventsHandler(store db.Querier) *EventsHandler {
+	return &EventsHandler{db: store}
+}
+
+// GetTypes lists the events that can be polled, each with a sample
+// @Summary List trigger event types
+// @Description The event types of GET /events, each with a sample event in the trigger payload format. Automation tools can call it to test an API key.
+// @Tags events
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /events/types [get]
+func (h *EventsHandler) GetTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"types": TriggerEventTypes})
+}
+
+// GetAll lists the current user's recent migration lifecycle events
+// @Summary Poll migration events
+// @Description The user's migration lifecycle events in the organization, newest first, for polling triggers in Zapier, Make and similar tools. Each event has an id that is unique per occurrence, to deduplicate on. Events go back 30 days.
+// @Tags events
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Comma-separated event types (default all)"
+// @Param since query string false "Only events after this RFC 3339 time"
+// @Param limit query int false "Max events (1-100, default 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /events [get]
+func (h *EventsHandler) GetAll(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+
+	types := map[string]bool{}
+	if t := c.Query("type"); t != "" {
+		for _, name := range strings.Split(t, ",") {
+			name = strings.TrimSpace(name)
+			if !isTriggerEventType(name) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown event type %q", name)})
+				return
+			}
+			types[name] = true
+		}
+	} else {
+		for _, t := range TriggerEventTypes {
+			types[t.Type] = true
+		}
+	}
+
+	since := time.Now().Add(-eventHistory)
+	if s := c.Query("since"); s != "" {
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+			return
+		}
+		if parsed.After(since) {
+			since = parsed
+		}
+	}
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = parsed
+	}
+
+	events := []models.TriggerEvent{}
+	var runStatuses []string
+	if types[EventMigrationCompleted] {
+		runStatuses = append(runStatuses, "completed")
+	}
+	if types[EventMigrationFailed] {
+		runStatuses = append(runStatuses, "failed")
+	}
+	if len(runStatuses) > 0 {
+		runEvents, err := loadRunEvents(h.db, userID, orgID, runStatuses, since, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration events"})
+			return
+		}
+		events = append(events, runEvents...)
+	}
+	if types[EventDeploymentFailed] {
+		deploymentEvents, err := loadDeploymentFailedEvents(h.db, userID, orgID, since, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployment events"})
+			return
+		}
+		events = append(events, deploymentEvents...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].OccurredAt.After(events[j].OccurredAt) })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events, "count": len(events)})
+}
+
+func isTriggerEventType(name string) bool {
+	for _, t := range TriggerEventTypes {
+		if t.Type == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadRunEvents returns migration.<status> events of the runs that finished since, newest
+// first. Each run is one event, so a re-run fires again.
+func loadRunEvents(store db.Querier, userID, orgID int64, statuses []string, since time.Time, limit int) ([]models.TriggerEvent, error) {
+	var rows []struct {
+		RunID           int64     `db:"run_id"`
+		RunNumber       int       `db:"run_number"`
+		Status          string    `db:"status"`
+		Error           string    `db:"error"`
+		CompletedAt     time.Time `db:"completed_at"`
+		MigrationID     int64     `db:"migration_id"`
+		Name            string    `db:"name"`
+		TablesCount     int       `db:"tables_count"`
+		ModelsGenerated int       `db:"models_generated"`
+		OrganizationID  int64     `db:"organization_id"`
+	}
+	err := store.Select(&rows, `
+		SELECT r.id AS run_id, r.run_number, r.status, COALESCE(r.error, '') AS error, r.completed_at,
+		       m.id AS migration_id, m.name, COALESCE(m.tables_count, 0) AS tables_count,
+		       COALESCE(m.models_generated, 0) AS models_generated, COALESCE(m.organization_id, 0) AS organization_id
+		FROM migration_runs r
+		JOIN migrations m ON m.id = r.migration_id
+		WHERE m.user_id = $1 AND COALESCE(m.organization_id, 0) = $2
+		  AND r.status = ANY($3) AND r.completed_at > $4
+		ORDER BY r.completed_at DESC, r.id DESC
+		LIMIT $5
+	`, userID, orgID, pq.StringArray(statuses), since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.TriggerEvent, 0, len(rows))
+	for _, row := range rows {
+		eventType := "migration." + row.Status
+		events = append(events, models.TriggerEvent{
+			ID:         fmt.Sprintf("%s-%d", eventType, row.RunID),
+			Type:       eventType,
+			OccurredAt: row.CompletedAt.UTC(),
+			Migration: models.TriggerMigration{
+				ID:              row.MigrationID,
+				Name:            row.Name,
+				RunNumber:       row.RunNumber,
+				TablesCount:     row.TablesCount,
+				ModelsGenerated: row.ModelsGenerated,
+				Error:           row.Error,
+				URL:             migrationPageURL(row.OrganizationID, row.MigrationID),
+			},
+		})
+	}
+	return events, nil
+}
+
+// loadDeploymentFailedEvents returns deployment.failed events since, newest first
+func loadDeploymentFailedEvents(store db.Querier, userID, orgID int64, since time.Time, limit int) ([]models.TriggerEvent, error) {
+	var rows []struct {
+		ID              int64     `db:"id"`
+		Status          string    `db:"status"`
+		TestsFailed     int       `db:"tests_failed"`
+		Error           string    `db:"error"`
+		RunURL          string    `db:"dbt_cloud_run_url"`
+		OccurredAt      time.Time `db:"occurred_at"`
+		MigrationID     int64     `db:"migration_id"`
+		Name            string    `db:"name"`
+		TablesCount     int       `db:"tables_count"`
+		ModelsGenerated int       `db:"models_generated"`
+		OrganizationID  int64     `db:"organization_id"`
+	}
+	err := store.Select(&rows, `
+		SELECT d.id, d.status, COALESCE(d.tests_failed, 0) AS tests_failed, COALESCE(d.error, '') AS error,
+		       COALESCE(d.dbt_cloud_run_url, '') AS dbt_cloud_run_url,
+		       COALESCE(d.completed_at, d.created_at) AS occurred_at,
+		       m.id AS migration_id, m.name, COALESCE(m.tables_count, 0) AS tables_count,
+		       COALESCE(m.models_generated, 0) AS models_generated, COALESCE(m.organization_id, 0) AS organization_id
+		FROM warehouse_deployments d
+		JOIN migrations m ON m.id = d.migration_id
+		WHERE m.user_id = $1 AND COALESCE(m.organization_id, 0) = $2
+		  AND d.status = 'failed' AND COALESCE(d.completed_at, d.created_at) > $3
+		ORDER BY occurred_at DESC, d.id DESC
+		LIMIT $4
+	`, userID, orgID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.TriggerEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, models.TriggerEvent{
+			ID:         fmt.Sprintf("%s-%d", EventDeploymentFailed, row.ID),
+			Type:       EventDeploymentFailed,
+			OccurredAt: row.OccurredAt.UTC(),
+			Migration: models.TriggerMigration{
+				ID:              row.MigrationID,
+				Name:            row.Name,
+				TablesCount:     row.TablesCount,
+				ModelsGenerated: row.ModelsGenerated,
+				URL:             migrationPageURL(row.OrganizationID, row.MigrationID),
+			},
+			Deployment: &models.TriggerDeployment{
+				ID:             row.ID,
+				Status:         row.Status,
+				TestsFailed:    row.TestsFailed,
+				Error:          row.Error,
+				DbtCloudRunURL: row.RunURL,
+			},
+		})
+	}
+	return events, nil
+}
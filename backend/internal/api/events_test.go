@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+func TestEventsGetAll(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`FROM migration_runs r\s+JOIN migrations m .+ r.status = ANY\(\$3\) AND r.completed_at > \$4`).
+		WithArgs(testUserID, testOrgID, pq.StringArray{"completed", "failed"}, sqlmock.AnyArg(), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"run_id", "run_number", "status", "error", "completed_at", "migration_id", "name", "tables_count", "models_generated", "organization_id"}).
+			AddRow(12, 2, "completed", "", now.Add(-time.Hour), 7, "Sales", 4, 4, testOrgID).
+			AddRow(10, 1, "failed", "Login failed", now.Add(-3*time.Hour), 7, "Sales", 4, 0, testOrgID))
+	mock.ExpectQuery(`FROM warehouse_deployments d\s+JOIN migrations m .+ d.status = 'failed'`).
+		WithArgs(testUserID, testOrgID, sqlmock.AnyArg(), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "tests_failed", "error", "dbt_cloud_run_url", "occurred_at", "migration_id", "name", "tables_count", "models_generated", "organization_id"}).
+			AddRow(31, "failed", 2, "2 tests failed", "", now.Add(-2*time.Hour), 7, "Sales", 4, 4, testOrgID))
+
+	status, body := serve(t, "GET", "/events", "/events?limit=2", nil, NewEventsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Events []models.TriggerEvent `json:"events"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"migration.completed-12", "deployment.failed-31"}
+	if len(resp.Events) != len(want) {
+		t.Fatalf("events = %s, want %v", body, want)
+	}
+	for i, e := range resp.Events {
+		if e.ID != want[i] {
+			t.Errorf("events[%d] = %s, want %s", i, e.ID, want[i])
+		}
+	}
+	if d := resp.Events[1].Deployment; d == nil || d.TestsFailed != 2 || resp.Events[1].Migration.URL == "" {
+		t.Errorf("deployment event = %+v", resp.Events[1])
+	}
+}
+
+func TestEventsGetAllOfType(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migration_runs r`).
+		WithArgs(testUserID, testOrgID, pq.StringArray{"failed"}, sqlmock.AnyArg(), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"run_id"}))
+
+	status, body := serve(t, "GET", "/events", "/events?type=migration.failed&since=2026-01-01T00:00:00Z", nil, NewEventsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestEventsGetAllUnknownType(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/events", "/events?type=migration.started", nil, NewEventsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
@@ -0,0 +1,427 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/powerbi"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// ExposuresHandler manages the dashboards and reports downstream of a migration,
+// which are rendered into the dbt project's exposures.yml
+type ExposuresHandler struct {
+	db db.Querier
+}
+
+func NewExposuresHandler(store db.Querier) *ExposuresHandler {
+	return &ExposuresHandler{db: store}
+}
+
+const exposureColumns = `id, migration_id, name, label, type, tool, url, description, owner_name, owner_email,
+	maturity, depends_on, external_id, created_at, updated_at`
+
+// migrationID parses the migration ID and checks the user owns it. On failure it
+// writes the error response and returns false.
+func (h *ExposuresHandler) migrationID(c *gin.Context) (int64, bool) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return 0, false
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3)", id, userID, orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return 0, false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+		return 0, false
+	}
+	return id, true
+}
+
+// GetAll lists a migration's exposures
+// @Summary List exposures
+// @Description List the BI dashboards, reports and applications registered as exposures of a migration
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {array} models.Exposure
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/exposures [get]
+func (h *ExposuresHandler) GetAll(c *gin.Context) {
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	exposures, err := listExposures(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exposures"})
+		return
+	}
+	c.JSON(http.StatusOK, exposures)
+}
+
+// Create registers an exposure
+// @Summary Create exposure
+// @Description Register a downstream BI artifact that depends on migrated tables
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.ExposureRequest true "Exposure"
+// @Success 201 {object} models.Exposure
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /migrations/{id}/exposures [post]
+func (h *ExposuresHandler) Create(c *gin.Context) {
+	var req models.ExposureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if result := dbtgen.ValidateExposure(&req); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	exposure, err := insertExposure(h.db, id, middleware.GetUserID(c), req)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An exposure named " + req.Name + " already exists for this migration"})
+			return
+		}
+		log.Printf("Failed to create exposure for migration %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create exposure"})
+		return
+	}
+	c.JSON(http.StatusCreated, exposure)
+}
+
+// Update replaces an exposure
+// @Summary Update exposure
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param exposureId path int true "Exposure ID"
+// @Param request body models.ExposureRequest true "Exposure"
+// @Success 200 {object} models.Exposure
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /migrations/{id}/exposures/{exposureId} [put]
+func (h *ExposuresHandler) Update(c *gin.Context) {
+	exposureID, err := strconv.ParseInt(c.Param("exposureId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exposure ID"})
+		return
+	}
+
+	var req models.ExposureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if result := dbtgen.ValidateExposure(&req); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	var exposure models.Exposure
+	err = h.db.Get(&exposure, `
+		UPDATE migration_exposures
+		SET name = $1, label = $2, type = $3, tool = $4, url = $5, description = $6, owner_name = $7,
+		    owner_email = $8, maturity = $9, depends_on = $10, external_id = $11, updated_at = NOW()
+		WHERE id = $12 AND migration_id = $13
+		RETURNING `+exposureColumns,
+		req.Name, req.Label, req.Type, req.Tool, req.URL, req.Description, req.OwnerName,
+		req.OwnerEmail, req.Maturity, pq.StringArray(req.DependsOn), req.ExternalID, exposureID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exposure not found"})
+			return
+		}
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An exposure named " + req.Name + " already exists for this migration"})
+			return
+		}
+		log.Printf("Failed to update exposure %d: %v", exposureID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update exposure"})
+		return
+	}
+	c.JSON(http.StatusOK, exposure)
+}
+
+// Delete removes an exposure
+// @Summary Delete exposure
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param exposureId path int true "Exposure ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /migrations/{id}/exposures/{exposureId} [delete]
+func (h *ExposuresHandler) Delete(c *gin.Context) {
+	exposureID, err := strconv.ParseInt(c.Param("exposureId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exposure ID"})
+		return
+	}
+
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM migration_exposures WHERE id = $1 AND migration_id = $2", exposureID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete exposure"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exposure not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Exposure deleted"})
+}
+
+// GetYAML renders the migration's exposures.yml
+// @Summary Preview exposures.yml
+// @Description Render the exposures as the dbt exposures.yml written into the project. Seeded tables are ref()'d by seed name.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /migrations/{id}/exposures/yaml [get]
+func (h *ExposuresHandler) GetYAML(c *gin.Context) {
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	exposures, err := listExposures(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exposures"})
+		return
+	}
+	seeds, err := listSeeds(h.db, id, SeedExported)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seeds"})
+		return
+	}
+
+	yamlText, err := dbtgen.ExposuresYAML(exposures, seedNames(seeds))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render exposures"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"path":      "models/exposures.yml",
+		"yaml":      yamlText,
+		"exposures": len(exposures),
+	})
+}
+
+// Discover finds Power BI datasets that read from the migration's source database
+// @Summary Discover Power BI exposures
+// @Description Scan the Power BI workspaces readable by the configured service principal for datasets using the source database. With register=true, new datasets are saved as exposures depending on the given tables.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.DiscoverExposuresRequest false "Registration options"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /migrations/{id}/exposures/discover [post]
+func (h *ExposuresHandler) Discover(c *gin.Context) {
+	var req models.DiscoverExposuresRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Register && (len(req.DependsOn) == 0 || (isBlankString(req.OwnerName) && isBlankString(req.OwnerEmail))) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "depends_on and owner_name or owner_email are required to register datasets"})
+		return
+	}
+
+	client := powerbi.GetClient()
+	if client == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Power BI integration is not configured"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var source struct {
+		Host     string `db:"host"`
+		Database string `db:"database_name"`
+	}
+	err = h.db.Get(&source, `
+		SELECT dc.host, dc.database_name
+		FROM migrations m
+		JOIN database_connections dc ON dc.id = m.connection_id AND dc.user_id = m.user_id
+		WHERE m.id = $1 AND m.user_id = $2 AND COALESCE(m.organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration or source database connection not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+	datasets, err := client.DatasetsUsing(ctx, source.Host, source.Database)
+	if err != nil {
+		log.Printf("Power BI discovery for migration %d failed: %v", id, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to query Power BI", "details": err.Error()})
+		return
+	}
+
+	registered := []models.Exposure{}
+	if req.Register {
+		for _, dataset := range datasets {
+			exposure, ok := h.registerDataset(id, userID, req, dataset)
+			if ok {
+				registered = append(registered, exposure)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"datasets":   datasets,
+		"registered": registered,
+	})
+}
+
+// registerDataset saves a discovered dataset as an exposure, skipping datasets that
+// are already registered or whose name is taken
+func (h *ExposuresHandler) registerDataset(migrationID, userID int64, req models.DiscoverExposuresRequest, dataset powerbi.Dataset) (models.Exposure, bool) {
+	var exists bool
+	if err := h.db.Get(&exists, `
+		SELECT EXISTS(SELECT 1 FROM migration_exposures WHERE migration_id = $1 AND tool = 'powerbi' AND external_id = $2)
+	`, migrationID, dataset.ID); err != nil || exists {
+		return models.Exposure{}, false
+	}
+
+	label := dataset.Name
+	description := "Power BI dataset in workspace " + dataset.WorkspaceName
+	exposure := models.ExposureRequest{
+		Name:        dbtgen.ExposureName(dataset.Name),
+		Label:       &label,
+		Type:        "dashboard",
+		Tool:        "powerbi",
+		Description: &description,
+		OwnerName:   req.OwnerName,
+		OwnerEmail:  req.OwnerEmail,
+		DependsOn:   req.DependsOn,
+		ExternalID:  &dataset.ID,
+	}
+	if dataset.WebURL != "" {
+		exposure.URL = &dataset.WebURL
+	}
+	if result := dbtgen.ValidateExposure(&exposure); !result.Valid {
+		log.Printf("Skipping Power BI dataset %s for migration %d: invalid exposure", dataset.ID, migrationID)
+		return models.Exposure{}, false
+	}
+
+	saved, err := insertExposure(h.db, migrationID, userID, exposure)
+	if err != nil {
+		log.Printf("Failed to register Power BI dataset %s for migration %d: %v", dataset.ID, migrationID, err)
+		return models.Exposure{}, false
+	}
+	return saved, true
+}
+
+func insertExposure(store db.Querier, migrationID, userID int64, req models.ExposureRequest) (models.Exposure, error) {
+	var exposure models.Exposure
+	err := store.Get(&exposure, `
+		INSERT INTO migration_exposures (migration_id, name, label, type, tool, url, description, owner_name,
+		                                 owner_email, maturity, depends_on, external_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING `+exposureColumns,
+		migrationID, req.Name, req.Label, req.Type, req.Tool, req.URL, req.Description, req.OwnerName,
+		req.OwnerEmail, req.Maturity, pq.StringArray(req.DependsOn), req.ExternalID, userID)
+	return exposure, err
+}
+
+// listExposures returns a migration's exposures ordered by name
+func listExposures(store db.Querier, migrationID int64) ([]models.Exposure, error) {
+	exposures := []models.Exposure{}
+	err := store.Select(&exposures, `
+		SELECT `+exposureColumns+`
+		FROM migration_exposures
+		WHERE migration_id = $1
+		ORDER BY name
+	`, migrationID)
+	return exposures, err
+}
+
+// seedNames maps seeded source tables to their seed names
+func seedNames(seeds []models.MigrationSeed) map[string]string {
+	names := make(map[string]string, len(seeds))
+	for _, seed := range seeds {
+		names[seed.SourceTable] = seed.SeedName
+	}
+	return names
+}
+
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+func isBlankString(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
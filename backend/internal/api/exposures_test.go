@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestExposuresCreateRequiresOwner(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "POST", "/migrations/:id/exposures", "/migrations/7/exposures", map[string]interface{}{
+		"name":       "sales_overview",
+		"type":       "dashboard",
+		"depends_on": []string{"Sales.Customer"},
+	}, NewExposuresHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if !strings.Contains(string(body), "owner_name or owner_email") {
+		t.Errorf("body = %s, want an owner error", body)
+	}
+}
+
+func TestExposuresGetYAMLRefsSeeds(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("FROM migration_exposures").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "migration_id", "name", "label", "type", "tool", "url", "description", "owner_name",
+			"owner_email", "maturity", "depends_on", "external_id", "created_at", "updated_at",
+		}).AddRow(1, 7, "sales_overview", "Sales Overview", "dashboard", "powerbi", nil, nil, nil,
+			"bi@example.com", "high", "{Sales.Customer,Sales.OrderStatus}", "ds-1", now, now))
+	mock.ExpectQuery("FROM migration_seeds").
+		WithArgs(int64(7), SeedExported).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "migration_id", "source_table", "seed_name", "status", "row_count", "error", "created_at", "updated_at",
+		}).AddRow(1, 7, "Sales.OrderStatus", "orderstatus", SeedExported, 5, nil, now, now))
+
+	status, body := serve(t, "GET", "/migrations/:id/exposures/yaml", "/migrations/7/exposures/yaml", nil,
+		NewExposuresHandler(store).GetYAML)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		YAML string `json:"yaml"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- ref('stg_customer')", "- ref('orderstatus')", "email: bi@example.com", "external_id: ds-1"} {
+		if !strings.Contains(resp.YAML, want) {
+			t.Errorf("yaml missing %q:\n%s", want, resp.YAML)
+		}
+	}
+}
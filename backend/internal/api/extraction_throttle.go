@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+// Bounds of the extraction throttle settings
+const (
+	maxThrottleParallelQueries = 32
+	minThrottleFetchSize       = 100
+	maxThrottleFetchSize       = 100000
+)
+
+// normalizeExtractionThrottle trims the throttle's settings, returning nil when none are
+// set
+func normalizeExtractionThrottle(throttle *models.ExtractionThrottle) *models.ExtractionThrottle {
+	if throttle == nil {
+		return nil
+	}
+	throttle.Timezone = strings.TrimSpace(throttle.Timezone)
+	normalizeTimeWindows(throttle.OffPeakWindows)
+	if throttle.MaxParallelQueries == 0 && throttle.FetchSize == 0 && throttle.Timezone == "" && len(throttle.OffPeakWindows) == 0 {
+		return nil
+	}
+	return throttle
+}
+
+// validateExtractionThrottle adds an error to result for each throttle setting that is
+// out of range
+func validateExtractionThrottle(result *validation.ValidationResult, throttle *models.ExtractionThrottle) {
+	if throttle == nil {
+		return
+	}
+	if throttle.MaxParallelQueries < 0 || throttle.MaxParallelQueries > maxThrottleParallelQueries {
+		result.AddError("extra_config.throttle.max_parallel_queries", fmt.Sprintf("Max parallel queries must be between 1 and %d", maxThrottleParallelQueries))
+	}
+	if throttle.FetchSize != 0 && (throttle.FetchSize < minThrottleFetchSize || throttle.FetchSize > maxThrottleFetchSize) {
+		result.AddError("extra_config.throttle.fetch_size", fmt.Sprintf("Fetch size must be between %d and %d rows", minThrottleFetchSize, maxThrottleFetchSize))
+	}
+	validateTimezone(result, "extra_config.throttle.timezone", throttle.Timezone)
+	validateTimeWindows(result, "extra_config.throttle.off_peak_windows", throttle.OffPeakWindows)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+func TestNormalizeExtractionThrottle(t *testing.T) {
+	if got := normalizeExtractionThrottle(&models.ExtractionThrottle{Timezone: " "}); got != nil {
+		t.Errorf("empty throttle = %+v, want nil", got)
+	}
+
+	throttle := normalizeExtractionThrottle(&models.ExtractionThrottle{
+		MaxParallelQueries: 2,
+		OffPeakWindows:     []models.TimeWindow{{Days: []string{" Sat", "SUN"}, Start: "22:00 ", End: "06:00"}},
+	})
+	result := validation.NewValidationResult()
+	validateExtractionThrottle(result, throttle)
+	if !result.Valid {
+		t.Errorf("errors = %v", result.Errors)
+	}
+	if days := throttle.OffPeakWindows[0].Days; days[0] != "sat" || days[1] != "sun" {
+		t.Errorf("days = %v", days)
+	}
+}
+
+func TestValidateExtractionThrottleBounds(t *testing.T) {
+	for _, throttle := range []models.ExtractionThrottle{
+		{MaxParallelQueries: 33},
+		{MaxParallelQueries: -1},
+		{FetchSize: 99},
+		{FetchSize: 100001},
+		{OffPeakWindows: []models.TimeWindow{{Start: "01:00", End: "01:00"}}},
+		{OffPeakWindows: []models.TimeWindow{{Start: "1am", End: "05:00"}}},
+	} {
+		result := validation.NewValidationResult()
+		validateExtractionThrottle(result, &throttle)
+		if result.Valid {
+			t.Errorf("throttle %+v passed validation", throttle)
+		}
+	}
+}
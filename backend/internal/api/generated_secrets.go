@@ -0,0 +1,65 @@
+package api
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// scanArchiveSecrets scans every file of a project archive for embedded credentials.
+// Scanning the archive itself, rather than the files it was built from, means what is
+// handed out is exactly what was checked.
+func scanArchiveSecrets(archive *zip.Reader) ([]security.SecretFinding, error) {
+	findings := []security.SecretFinding{}
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		findings = append(findings, security.ScanSecrets(f.Name, string(content))...)
+	}
+	return findings, nil
+}
+
+// logGeneratedSecrets records secrets found in a migration's generated files in the
+// security audit log
+func logGeneratedSecrets(c *gin.Context, eventType string, migrationID int64, findings []security.SecretFinding, blocked bool) {
+	userID := middleware.GetUserID(c)
+	event := &security.SecurityEvent{
+		EventType:      eventType,
+		Severity:       "medium",
+		UserID:         &userID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusOK,
+		Metadata: map[string]interface{}{
+			"migration_id": migrationID,
+			"findings":     findings,
+		},
+	}
+	if orgID := middleware.GetOrganizationID(c); orgID != 0 {
+		event.OrganizationID = &orgID
+	}
+	if blocked {
+		event.Severity = "high"
+		event.ResponseStatus = http.StatusUnprocessableEntity
+		event.Blocked = true
+		event.BlockReason = "Generated project contains embedded credentials"
+	}
+	security.GetGuardian().LogSecurityEvent(event)
+}
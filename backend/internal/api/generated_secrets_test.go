@@ -0,0 +1,40 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func TestScanArchiveSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"models/staging/stg_orders.sql": "SELECT order_id FROM {{ source('mssql', 'orders') }}",
+		"profiles.yml":                  "sales:\n  outputs:\n    dev:\n      password: hunter2\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if _, err := zw.Create("models/marts/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := scanArchiveSecrets(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Path != "profiles.yml" || findings[0].Line != 4 {
+		t.Errorf("findings = %+v", findings)
+	}
+}
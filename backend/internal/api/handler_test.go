@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	testUserID int64 = 42
+	testOrgID  int64 = 3
+)
+
+// newMockDB returns a sqlx handle backed by go-sqlmock and fails the test on unmet expectations
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet database expectations: %v", err)
+		}
+		mockDB.Close()
+	})
+	return sqlx.NewDb(mockDB, "sqlmock"), mock
+}
+
+// serve runs a single handler as the authenticated test user, acting in the test
+// organization, and decodes the JSON response
+func serve(t *testing.T, method, route, path string, body interface{}, handler gin.HandlerFunc) (int, json.RawMessage) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Set("organization_id", testOrgID)
+		c.Set("is_admin", false)
+	}, handler)
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec.Code, rec.Body.Bytes()
+}
+
+// errorMessage extracts the "error" field of a JSON error response
+func errorMessage(t *testing.T, body json.RawMessage) string {
+	t.Helper()
+
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response is not JSON: %s", body)
+	}
+	return resp.Error
+}
+
+func expectStatus(t *testing.T, got, want int, body json.RawMessage) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("status = %d, want %d (body: %s)", got, want, body)
+	}
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultImpersonationDuration = 30 * time.Minute
+	maxImpersonationDuration     = 60 * time.Minute
+)
+
+const impersonationSessionQuery = `
+	SELECT s.id, s.admin_id, s.admin_email, s.user_id, u.email AS user_email, s.organization_id, s.reason,
+	       s.started_at, s.expires_at, s.ended_at, s.action_count, s.last_action_at
+	FROM impersonation_sessions s
+	JOIN users u ON u.id = s.user_id
+`
+
+// ImpersonationHandler lets platform admins act as a user for support
+type ImpersonationHandler struct{}
+
+// NewImpersonationHandler creates a new impersonation handler
+func NewImpersonationHandler() *ImpersonationHandler {
+	return &ImpersonationHandler{}
+}
+
+// Start issues a short-lived token acting as another user
+// @Summary Impersonate a user
+// @Description Issue a token that acts as the user for up to 60 minutes (default 30). The token carries the admin and reason for the frontend banner, has no admin rights, and every request made with it is audited. The user can see the session in their impersonation history.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.StartImpersonationRequest true "User and reason"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/impersonate [post]
+func (h *ImpersonationHandler) Start(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	var req models.StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	duration := defaultImpersonationDuration
+	if req.DurationMinutes != 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+		if duration < time.Minute || duration > maxImpersonationDuration {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "duration_minutes must be between 1 and 60"})
+			return
+		}
+	}
+
+	adminID := middleware.GetUserID(c)
+	if req.UserID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't impersonate yourself"})
+		return
+	}
+
+	var user models.User
+	err := db.DB.Get(&user, `
+		SELECT id, email, first_name, last_name, job_title, phone,
+		       organization_id, role, is_admin, is_active, last_login_at, created_at, updated_at
+		FROM users WHERE id = $1 AND COALESCE(account_type, 'human') = 'human'`, req.UserID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !user.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is deactivated"})
+		return
+	}
+	if user.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admins can't be impersonated"})
+		return
+	}
+
+	orgID, err := security.ResolveUserOrganization(user.ID, req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if req.OrganizationID != 0 && orgID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of that organization"})
+		return
+	}
+	loadUserOrganization(&user, orgID)
+
+	var adminEmail string
+	if err := db.DB.Get(&adminEmail, "SELECT email FROM users WHERE id = $1", adminID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var session models.ImpersonationSession
+	err = db.DB.Get(&session, `
+		WITH s AS (
+			INSERT INTO impersonation_sessions (admin_id, admin_email, user_id, organization_id, reason, ip_address, user_agent, expires_at)
+			VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7, $8)
+			RETURNING *
+		)
+		SELECT s.id, s.admin_id, s.admin_email, s.user_id, $9::text AS user_email, s.organization_id, s.reason,
+		       s.started_at, s.expires_at, s.ended_at, s.action_count, s.last_action_at
+		FROM s
+	`, adminID, adminEmail, user.ID, orgID, req.Reason, c.ClientIP(), c.Request.UserAgent(),
+		time.Now().Add(duration), user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start impersonation"})
+		return
+	}
+
+	token, err := middleware.GenerateImpersonationToken(user.ID, user.Email, userOrgContext(user), middleware.Impersonation{
+		SessionID:  session.ID,
+		AdminID:    adminID,
+		AdminEmail: adminEmail,
+		Reason:     req.Reason,
+	}, session.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	security.GetGuardian().LogSecurityEvent(&security.SecurityEvent{
+		EventType:      "impersonation_started",
+		Severity:       "high",
+		UserID:         &adminID,
+		OrganizationID: user.OrganizationID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusCreated,
+		Metadata: map[string]interface{}{
+			"impersonation_session_id": session.ID,
+			"impersonated_user_id":     user.ID,
+			"impersonated_email":       user.Email,
+			"reason":                   req.Reason,
+			"expires_at":               session.ExpiresAt,
+		},
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"access_token": token,
+		"session":      session,
+		"user":         user,
+	})
+}
+
+// End stops the impersonation session of the current token
+// @Summary End impersonation
+// @Description End the impersonation session the current token belongs to. The token stops working immediately.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/impersonation/end [post]
+func (h *ImpersonationHandler) End(c *gin.Context) {
+	impersonation := middleware.GetImpersonation(c)
+	if impersonation == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This token isn't impersonating a user"})
+		return
+	}
+
+	_, err := db.DB.Exec("UPDATE impersonation_sessions SET ended_at = NOW() WHERE id = $1 AND ended_at IS NULL", impersonation.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end impersonation"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	security.GetGuardian().LogSecurityEvent(&security.SecurityEvent{
+		EventType:      "impersonation_ended",
+		Severity:       "info",
+		UserID:         &impersonation.AdminID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusOK,
+		Metadata: map[string]interface{}{
+			"impersonation_session_id": impersonation.SessionID,
+			"impersonated_user_id":     userID,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Impersonation ended"})
+}
+
+// GetAll lists impersonation sessions for admins
+// @Summary List impersonation sessions
+// @Description List impersonation sessions, most recent first. Filter by user_id or admin_id.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Impersonated user"
+// @Param admin_id query int false "Admin who impersonated"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /admin/impersonations [get]
+func (h *ImpersonationHandler) GetAll(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	query := impersonationSessionQuery + " WHERE 1=1"
+	args := []interface{}{}
+	for _, filter := range []struct{ param, column string }{{"user_id", "s.user_id"}, {"admin_id", "s.admin_id"}} {
+		value := c.Query(filter.param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + filter.param})
+			return
+		}
+		args = append(args, id)
+		query += " AND " + filter.column + " = $" + strconv.Itoa(len(args))
+	}
+
+	sessions := []models.ImpersonationSession{}
+	if err := db.DB.Select(&sessions, query+" ORDER BY s.started_at DESC LIMIT 200", args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch impersonation sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// GetMine lists the times support staff acted as the current user
+// @Summary List my impersonation sessions
+// @Description List the sessions in which an admin acted as the current user, with the reason they gave
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /auth/impersonations [get]
+func (h *ImpersonationHandler) GetMine(c *gin.Context) {
+	sessions := []models.ImpersonationSession{}
+	err := db.DB.Select(&sessions, impersonationSessionQuery+" WHERE s.user_id = $1 ORDER BY s.started_at DESC", middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch impersonation sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestImpersonationStartRequiresAdmin(t *testing.T) {
+	h := NewImpersonationHandler()
+	status, body := serve(t, http.MethodPost, "/admin/impersonate", "/admin/impersonate",
+		map[string]interface{}{"user_id": 7, "reason": "Ticket 1234"}, h.Start)
+	expectStatus(t, status, http.StatusForbidden, body)
+}
+
+func TestImpersonationEndWithoutImpersonation(t *testing.T) {
+	h := NewImpersonationHandler()
+	status, body := serve(t, http.MethodPost, "/auth/impersonation/end", "/auth/impersonation/end", nil, h.End)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if msg := errorMessage(t, body); msg != "This token isn't impersonating a user" {
+		t.Errorf("error = %q", msg)
+	}
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/sqllint"
+	"github.com/gin-gonic/gin"
+)
+
+// maxLintSQLLength bounds pasted SQL; generated files are linted whatever their size
+const maxLintSQLLength = 200000
+
+// LintHandler lints generated models and pasted SQL for the in-browser editor
+type LintHandler struct {
+	db db.Querier
+}
+
+func NewLintHandler(store db.Querier) *LintHandler {
+	return &LintHandler{db: store}
+}
+
+// Lint checks SQL against the target dialect and the organization's style rules
+// @Summary Lint SQL
+// @Description Lint pasted SQL, or a generated file of a migration's current run (migration_id and path), against the target dialect and the organization's SQL style. The dialect defaults to the migration's target connection, then the organization's default warehouse. Violations carry 1-based line and column positions for the editor.
+// @Tags lint
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.LintRequest true "SQL or generated file to lint"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /lint [post]
+func (h *LintHandler) Lint(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+
+	var req models.LintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Path = strings.TrimPrefix(strings.TrimSpace(req.Path), "/")
+	req.TargetDialect = strings.ToLower(strings.TrimSpace(req.TargetDialect))
+	req.Format = strings.ToLower(strings.TrimSpace(req.Format))
+
+	sqlText := req.SQL
+	switch {
+	case req.MigrationID != nil:
+		if req.Path == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "path is required with migration_id"})
+			return
+		}
+		if path.Ext(req.Path) != ".sql" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only .sql files can be linted"})
+			return
+		}
+		var file struct {
+			Content    sql.NullString `db:"content"`
+			TargetType string         `db:"target_type"`
+		}
+		err := h.db.Get(&file, `
+			SELECT f.content, COALESCE(t.db_type, '') AS target_type
+			FROM migrations m
+			JOIN migration_run_files f ON f.run_id = m.current_run_id AND f.path = $2
+			LEFT JOIN database_connections t ON t.id::text = m.config->>'target_connection_id'
+			WHERE m.id = $1 AND m.user_id = $3 AND COALESCE(m.organization_id, 0) = $4
+		`, *req.MigrationID, req.Path, userID, orgID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch file"})
+			return
+		}
+		if !file.Content.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File is too large to lint; download the project and lint it locally"})
+			return
+		}
+		sqlText = file.Content.String
+		if req.TargetDialect == "" {
+			req.TargetDialect = file.TargetType
+		}
+		// Every file of a generated project is compiled by dbt
+		req.Format = "dbt"
+	case strings.TrimSpace(req.SQL) == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sql or migration_id and path is required"})
+		return
+	case len(req.SQL) > maxLintSQLLength:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("sql must be at most %d characters", maxLintSQLLength)})
+		return
+	}
+	if req.Format == "" {
+		req.Format = "dbt"
+	}
+	if req.Format != "dbt" && req.Format != "sql" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be dbt or sql"})
+		return
+	}
+
+	var style models.SQLStyle
+	if orgID != 0 {
+		defaults, err := loadOrganizationDefaults(h.db, orgID)
+		if err != nil {
+			log.Printf("Failed to load organization defaults for lint in org %d: %v", orgID, err)
+		}
+		style = defaults.SQLStyle
+		if req.TargetDialect == "" {
+			req.TargetDialect = defaults.DefaultWarehouse
+		}
+	}
+	if req.TargetDialect == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_dialect is required when there is no target connection or default warehouse"})
+		return
+	}
+	if _, ok := dbtAdapters[req.TargetDialect]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported target_dialect: " + req.TargetDialect})
+		return
+	}
+
+	violations := sqllint.Lint(sqlText, sqllint.Options{
+		Dialect: req.TargetDialect,
+		TSQL:    isTSQLTarget(req.TargetDialect),
+		DBT:     req.Format == "dbt",
+		Style: sqllint.Style{
+			KeywordCase:   style.KeywordCase,
+			MaxLineLength: style.MaxLineLength,
+			Disabled:      style.DisabledRules,
+		},
+	})
+	errors, warnings := 0, 0
+	for _, v := range violations {
+		if v.Severity == sqllint.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"path":           req.Path,
+		"target_dialect": req.TargetDialect,
+		"format":         req.Format,
+		"violations":     violations,
+		"errors":         errors,
+		"warnings":       warnings,
+	})
+}
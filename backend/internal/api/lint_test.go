@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/sqllint"
+)
+
+func TestLintGeneratedModel(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migrations m\s+JOIN migration_run_files f`).
+		WithArgs(int64(7), "models/staging/stg_orders.sql", testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"content", "target_type"}).
+			AddRow("select top 10 order_id from {{ source('sales', 'orders') }}", "snowflake"))
+	mock.ExpectQuery(`SELECT settings FROM organizations`).
+		WithArgs(testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).
+			AddRow(`{"default_warehouse":"fabric","sql_style":{"keyword_case":"upper"}}`))
+
+	status, body := serve(t, "POST", "/lint", "/lint", map[string]interface{}{
+		"migration_id": 7,
+		"path":         "/models/staging/stg_orders.sql",
+	}, NewLintHandler(store).Lint)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		TargetDialect string              `json:"target_dialect"`
+		Format        string              `json:"format"`
+		Violations    []sqllint.Violation `json:"violations"`
+		Errors        int                 `json:"errors"`
+		Warnings      int                 `json:"warnings"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response is not JSON: %s", body)
+	}
+	// The target connection wins over the default warehouse
+	if resp.TargetDialect != "snowflake" || resp.Format != "dbt" {
+		t.Errorf("target_dialect = %q, format = %q", resp.TargetDialect, resp.Format)
+	}
+	// Lowercase select, top and from; TOP is T-SQL
+	if resp.Errors != 1 || resp.Warnings != 3 {
+		t.Errorf("errors = %d, warnings = %d: %+v", resp.Errors, resp.Warnings, resp.Violations)
+	}
+}
+
+func TestLintValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body map[string]interface{}
+		want string
+	}{
+		{"nothing to lint", map[string]interface{}{"sql": " ", "target_dialect": "snowflake"}, "sql or migration_id and path is required"},
+		{"file without path", map[string]interface{}{"migration_id": 7}, "path is required with migration_id"},
+		{"not a sql file", map[string]interface{}{"migration_id": 7, "path": "models/schema.yml"}, "Only .sql files can be linted"},
+		{"unknown format", map[string]interface{}{"sql": "select 1", "format": "yaml"}, "format must be dbt or sql"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, _ := newMockDB(t)
+			status, body := serve(t, "POST", "/lint", "/lint", tc.body, NewLintHandler(store).Lint)
+			expectStatus(t, status, http.StatusBadRequest, body)
+			if got := errorMessage(t, body); got != tc.want {
+				t.Errorf("error = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLintRequiresDialect(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT settings FROM organizations`).
+		WithArgs(testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow(`{}`))
+
+	status, body := serve(t, "POST", "/lint", "/lint", map[string]interface{}{"sql": "select 1"}, NewLintHandler(store).Lint)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
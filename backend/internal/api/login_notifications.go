@@ -0,0 +1,191 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// loginReportTTL is how long the "This wasn't me" link in a new sign-in email works
+const loginReportTTL = 7 * 24 * time.Hour
+
+// deviceIDHeader carries the random device ID the frontend keeps in local storage
+const deviceIDHeader = "X-Device-ID"
+
+func hashLoginReportToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// notifyNewLogin records the device of a successful login and emails the user when the
+// device or country is new to them. Failures are logged; they never block the login.
+func notifyNewLogin(c *gin.Context, user models.User) {
+	country := security.ClientCountry(c)
+	check, err := security.RecordLoginDevice(user.ID, c.Request.UserAgent(), c.GetHeader(deviceIDHeader), c.ClientIP(), country)
+	if err != nil {
+		log.Printf("Failed to record login device for user %d: %v", user.ID, err)
+		return
+	}
+	if !check.Notify() {
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return
+	}
+	token := hex.EncodeToString(b)
+	_, err = db.DB.Exec(`
+		UPDATE login_devices SET report_token_hash = $1, report_expires_at = $2 WHERE id = $3
+	`, hashLoginReportToken(token), time.Now().Add(loginReportTTL), check.DeviceID)
+	if err != nil {
+		log.Printf("Failed to store login report token for user %d: %v", user.ID, err)
+		return
+	}
+
+	security.GetGuardian().LogSecurityEvent(&security.SecurityEvent{
+		EventType:      "new_device_login",
+		Severity:       "medium",
+		UserID:         &user.ID,
+		OrganizationID: user.OrganizationID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusOK,
+		Metadata: map[string]interface{}{
+			"device_id":   check.DeviceID,
+			"new_device":  check.NewDevice,
+			"new_country": check.NewCountry,
+			"country":     country,
+		},
+	})
+
+	var prefs struct {
+		Language string `db:"preferred_language"`
+		Timezone string `db:"timezone"`
+	}
+	db.DB.Get(&prefs, "SELECT COALESCE(preferred_language, 'en') AS preferred_language, COALESCE(timezone, 'UTC') AS timezone FROM users WHERE id = $1", user.ID)
+	lang := prefs.Language
+	firstName := ""
+	if user.FirstName != nil {
+		firstName = *user.FirstName
+	}
+	login := email.LoginDetails{
+		Device:     security.DescribeUserAgent(c.Request.UserAgent()),
+		Location:   country,
+		IPAddress:  c.ClientIP(),
+		Time:       email.FormatTime(time.Now(), prefs.Timezone, lang),
+		NewCountry: check.NewCountry && !check.NewDevice,
+	}
+
+	emailService := email.NewService().WithFrontendURL(domains.RequestBaseURL(c.Request))
+	if !emailService.IsConfigured() {
+		email.NewMockService().SendNewLoginEmail(user.Email, firstName, login, token, lang)
+		return
+	}
+	if err := emailService.SendNewLoginEmail(user.Email, firstName, login, token, lang); err != nil {
+		log.Printf("Failed to send new sign-in email to %s: %v", user.Email, err)
+	}
+}
+
+// ReportLogin locks the account from the "This wasn't me" link in a new sign-in email
+// @Summary Report a sign-in
+// @Description Lock the account after its owner reports a sign-in from the notification email. Nobody can sign in until the password is reset.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ReportLoginRequest true "Token from the email"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/report-login [post]
+func (h *AuthHandler) ReportLogin(c *gin.Context) {
+	var req models.ReportLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var device struct {
+		ID        int64          `db:"id"`
+		UserID    int64          `db:"user_id"`
+		IPAddress sql.NullString `db:"ip_address"`
+	}
+	err = tx.Get(&device, `
+		UPDATE login_devices SET reported_at = NOW(), report_token_hash = NULL
+		WHERE report_token_hash = $1 AND report_expires_at > NOW()
+		RETURNING id, user_id, ip_address
+	`, hashLoginReportToken(req.Token))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if _, err := tx.Exec("UPDATE users SET locked_at = NOW(), updated_at = NOW() WHERE id = $1", device.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock account"})
+		return
+	}
+	revokeRefreshTokens(tx, device.UserID)
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock account"})
+		return
+	}
+
+	security.GetGuardian().LogSecurityEvent(&security.SecurityEvent{
+		EventType:      "login_reported",
+		Severity:       "high",
+		UserID:         &device.UserID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusOK,
+		Metadata: map[string]interface{}{
+			"device_id":         device.ID,
+			"reported_login_ip": device.IPAddress.String,
+		},
+	})
+	log.Printf("Account %d locked after its owner reported a sign-in from %s", device.UserID, device.IPAddress.String)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Your account is locked. Reset your password to unlock it."})
+}
+
+// GetLoginDevices lists the devices the current user has signed in from
+// @Summary List sign-in devices
+// @Description List the devices the current user has signed in from, most recent first
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /auth/devices [get]
+func (h *AuthHandler) GetLoginDevices(c *gin.Context) {
+	devices, err := security.ListLoginDevices(middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch devices"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"devices": devices})
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type MaskingHandler struct {
+	db db.Querier
+}
+
+func NewMaskingHandler(store db.Querier) *MaskingHandler {
+	return &MaskingHandler{db: store}
+}
+
+// loadMaskingPolicy reads an organization's column masking rules in the order they were
+// saved
+func loadMaskingPolicy(store db.Querier, orgID int64) (models.MaskingPolicy, error) {
+	policy := models.MaskingPolicy{Rules: []models.ColumnMask{}}
+	err := store.Select(&policy.Rules, `
+		SELECT table_name, column_name, action
+		FROM column_masking_rules
+		WHERE organization_id = $1
+		ORDER BY id
+	`, orgID)
+	if err != nil {
+		return policy, err
+	}
+	if len(policy.Rules) > 0 {
+		err = store.Get(&policy.UpdatedAt, "SELECT MAX(created_at) FROM column_masking_rules WHERE organization_id = $1", orgID)
+	}
+	return policy, err
+}
+
+// replaceMaskingRules swaps an organization's masking rules for a new set
+func replaceMaskingRules(store db.Querier, orgID, userID int64, rules []models.ColumnMask) error {
+	tx, err := store.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM column_masking_rules WHERE organization_id = $1", orgID); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		_, err := tx.Exec(`
+			INSERT INTO column_masking_rules (organization_id, table_name, column_name, action, updated_by)
+			VALUES ($1, $2, $3, $4, $5)
+		`, orgID, rule.Table, rule.Column, rule.Action, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPolicy returns the organization's column masking rules
+// @Summary Get column masking policy
+// @Description The columns the organization's migrations mask (NULL), hash (SHA-256) or drop in their staging models. Every member can read the policy.
+// @Tags masking
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.MaskingPolicy
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /masking/policy [get]
+func (h *MaskingHandler) GetPolicy(c *gin.Context) {
+	orgID := middleware.GetOrganizationID(c)
+	if orgID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User does not belong to an organization"})
+		return
+	}
+
+	policy, err := loadMaskingPolicy(h.db, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch masking policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdatePolicy replaces the organization's column masking rules
+// @Summary Update column masking policy
+// @Description Replace the organization's masking rules. Migrations started afterwards apply them; an empty list clears the policy.
+// @Tags masking
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateMaskingPolicyRequest true "Masking rules"
+// @Success 200 {object} models.MaskingPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /masking/policy [put]
+func (h *MaskingHandler) UpdatePolicy(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMaskingPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Rules = dbtgen.NormalizeColumnMasks(req.Rules)
+	if result := dbtgen.ValidateColumnMasks(req.Rules); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	if err := replaceMaskingRules(h.db, orgID, middleware.GetUserID(c), req.Rules); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save masking policy"})
+		return
+	}
+
+	policy, err := loadMaskingPolicy(h.db, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch masking policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
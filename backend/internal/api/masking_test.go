@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func TestMaskingGetPolicy(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM column_masking_rules`).
+		WithArgs(testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "action"}).
+			AddRow("", "Email", "hash").
+			AddRow("Sales.Customer", "SSN", "drop"))
+	mock.ExpectQuery(`SELECT MAX\(created_at\)`).
+		WithArgs(testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)))
+
+	status, body := serve(t, "GET", "/masking/policy", "/masking/policy", nil, NewMaskingHandler(store).GetPolicy)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var policy models.MaskingPolicy
+	if err := json.Unmarshal(body, &policy); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []models.ColumnMask{
+		{Column: "Email", Action: "hash"},
+		{Table: "Sales.Customer", Column: "SSN", Action: "drop"},
+	}
+	if !reflect.DeepEqual(policy.Rules, want) || policy.UpdatedAt == nil {
+		t.Errorf("policy = %+v", policy)
+	}
+}
+
+func TestMaskingGetPolicyEmpty(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM column_masking_rules`).
+		WithArgs(testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "action"}))
+
+	status, body := serve(t, "GET", "/masking/policy", "/masking/policy", nil, NewMaskingHandler(store).GetPolicy)
+	expectStatus(t, status, http.StatusOK, body)
+	if string(body) != `{"rules":[]}` {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestReplaceMaskingRules(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM column_masking_rules`).
+		WithArgs(testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO column_masking_rules`).
+		WithArgs(testOrgID, "", "Email", "hash", testUserID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := replaceMaskingRules(store, testOrgID, testUserID, []models.ColumnMask{{Column: "Email", Action: "hash"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateColumnMasks(t *testing.T) {
+	masks := dbtgen.NormalizeColumnMasks([]models.ColumnMask{
+		{Column: " Email ", Action: "HASH"},
+		{Table: "Sales.Customer", Column: "Email", Action: "drop"},
+		{Table: "Sales.Customer", Column: "email", Action: "mask"},
+		{Table: "Sales.Customer; DROP", Column: "SSN", Action: "mask"},
+		{Column: "Phone", Action: "redact"},
+	})
+	if masks[0].Column != "Email" || masks[0].Action != "hash" {
+		t.Errorf("normalized = %+v", masks[0])
+	}
+
+	result := dbtgen.ValidateColumnMasks(masks)
+	fields := []string{}
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"rules[2]", "rules[3].table", "rules[4].action"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("errors = %+v, want fields %v", result.Errors, want)
+	}
+}
+
+func TestMasksForTables(t *testing.T) {
+	masks := []models.ColumnMask{
+		{Column: "Email", Action: "hash"},
+		{Table: "Sales.Customer", Column: "SSN", Action: "drop"},
+		{Table: "Employee", Column: "Salary", Action: "mask"},
+		{Table: "Sales.Order", Column: "CardNumber", Action: "mask"},
+	}
+
+	got := dbtgen.MasksForTables(masks, []string{"sales.customer", "HumanResources.Employee"})
+	if !reflect.DeepEqual(got, masks[:3]) {
+		t.Errorf("masks = %+v", got)
+	}
+	if got := dbtgen.MasksForTables(masks, nil); len(got) != len(masks) {
+		t.Errorf("masks with every table = %+v", got)
+	}
+}
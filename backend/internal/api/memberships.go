@@ -0,0 +1,154 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// loginOrganizationID picks the organization a new session starts in: the user's default
+// organization if they are still a member of it, else the one they joined first (0 if none)
+func loginOrganizationID(userID int64) (int64, error) {
+	var orgID int64
+	err := db.DB.Get(&orgID, `
+		SELECT m.organization_id
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.user_id = $1
+		ORDER BY (m.organization_id = u.organization_id) IS TRUE DESC, m.created_at, m.organization_id
+		LIMIT 1
+	`, userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return orgID, err
+}
+
+// loadUserOrganization sets the user's organization and role to those of the organization
+// the request acts in, or clears them when there is none
+func loadUserOrganization(user *models.User, orgID int64) {
+	user.OrganizationID = nil
+	user.Organization = nil
+	if orgID == 0 {
+		return
+	}
+
+	var membership struct {
+		models.Organization
+		Role string `db:"role"`
+	}
+	err := db.DB.Get(&membership, `
+		SELECT o.id, o.name, o.slug, o.plan, o.max_users, o.max_migrations, m.role
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE m.user_id = $1 AND m.organization_id = $2
+	`, user.ID, orgID)
+	if err != nil {
+		return
+	}
+
+	user.OrganizationID = &membership.ID
+	user.Organization = &membership.Organization
+	user.Role = membership.Role
+}
+
+// GetOrganizations lists the organizations the current user belongs to
+// @Summary List my organizations
+// @Description List the organizations the current user is a member of, with their role in each. The organization the current token acts in is marked active.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.OrganizationMembership
+// @Failure 500 {object} map[string]string
+// @Router /auth/organizations [get]
+func (h *AuthHandler) GetOrganizations(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	activeOrgID := middleware.GetOrganizationID(c)
+
+	memberships := []models.OrganizationMembership{}
+	err := db.DB.Select(&memberships, `
+		SELECT m.organization_id, o.name, o.slug, COALESCE(o.plan, 'free') AS plan, m.role, m.created_at
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE m.user_id = $1
+		ORDER BY o.name
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organizations"})
+		return
+	}
+
+	for i := range memberships {
+		memberships[i].Active = memberships[i].OrganizationID == activeOrgID
+	}
+
+	c.JSON(http.StatusOK, memberships)
+}
+
+// SwitchOrganization issues a token that acts in another of the user's organizations
+// @Summary Switch organization
+// @Description Issue a new token scoped to another organization the user belongs to. The organization also becomes the one the user's next login starts in. Migrations and connections are scoped to the token's organization; those in another organization answer 404 until the user switches to it.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SwitchOrganizationRequest true "Organization to switch to"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/switch-organization [post]
+func (h *AuthHandler) SwitchOrganization(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.SwitchOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var isMember bool
+	err := db.DB.Get(&isMember, `
+		SELECT EXISTS(SELECT 1 FROM organization_members WHERE user_id = $1 AND organization_id = $2)
+	`, userID, req.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check membership"})
+		return
+	}
+	if !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this organization"})
+		return
+	}
+
+	if _, err := db.DB.Exec("UPDATE users SET organization_id = $1, updated_at = NOW() WHERE id = $2", req.OrganizationID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to switch organization"})
+		return
+	}
+	security.ForgetUserOrganizations(userID)
+
+	var user models.User
+	err = db.DB.Get(&user, `
+		SELECT id, email, first_name, last_name, job_title, phone,
+		       organization_id, role, is_admin, is_active, COALESCE(preferred_language, 'en') as preferred_language,
+		       COALESCE(timezone, 'UTC') as timezone, last_login_at, created_at, updated_at
+		FROM users WHERE id = $1`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	loadUserOrganization(&user, req.OrganizationID)
+
+	// Only unrestricted sessions reach this route
+	response, err := h.issueSession(user, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
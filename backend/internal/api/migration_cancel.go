@@ -0,0 +1,43 @@
+package api
+
+import (
+	"log"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// cancelledByUser is the error recorded on migrations the user stopped
+const cancelledByUser = "Cancelled by user"
+
+// stopGeneration asks the AI service to stop generating a cancelled migration and keeps
+// what it generated before it stopped: the progress of each table, the number of models
+// and, once the run has stopped, a snapshot of its files. It returns nil when no AI
+// service is configured.
+func stopGeneration(store db.Querier, migrationID int64) (*aiservice.StopMigrationResponse, error) {
+	aiClient, err := migrationAIClient(store, migrationID)
+	if err != nil || aiClient == nil {
+		return nil, err
+	}
+	ack, err := aiClient.StopMigration(migrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ack.Tables) > 0 {
+		if err := recordTableProgress(store, migrationID, ack.Tables); err != nil {
+			log.Printf("Failed to record table progress of cancelled migration %d: %v", migrationID, err)
+		}
+	}
+	if ack.ModelsGenerated > 0 {
+		_, err := store.Exec("UPDATE migrations SET models_generated = $2 WHERE id = $1", migrationID, ack.ModelsGenerated)
+		if err != nil {
+			log.Printf("Failed to record models of cancelled migration %d: %v", migrationID, err)
+		}
+	}
+	// A run still winding down may write more files, so it is only snapshotted once stopped
+	if ack.Stopped() {
+		go snapshotRunFiles(store, migrationID)
+	}
+	return ack, nil
+}
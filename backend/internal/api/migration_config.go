@@ -0,0 +1,177 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/datamigrate-ai/backend/internal/crypto"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+// sealedConfigFields are the migration config fields stored encrypted. Object fields are
+// sealed value by value so their keys stay visible. They're only unsealed when the
+// migration starts, and masked wherever the config is shown.
+var sealedConfigFields = []string{"secrets"}
+
+// sealedKey marks a config value replaced by its envelope
+const sealedKey = "$sealed"
+
+const maskedConfigValue = "********"
+
+var secretNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+var errConfigEncryptionNotConfigured = errors.New("encryption key not configured")
+
+type sealedValue struct {
+	Sealed *crypto.Envelope `json:"$sealed"`
+}
+
+// validateMigrationSecrets checks secrets are named like environment variables
+func validateMigrationSecrets(secrets map[string]string) []string {
+	var details []string
+	for name := range secrets {
+		if !secretNameRegex.MatchString(name) {
+			details = append(details, fmt.Sprintf("secrets: %q is not a valid environment variable name", name))
+		}
+	}
+	return details
+}
+
+// sealMigrationConfig encrypts the sealed fields of a marshaled config
+func sealMigrationConfig(enc *crypto.EncryptionService, config []byte) ([]byte, error) {
+	return transformSealedFields(config, func(raw json.RawMessage) (json.RawMessage, error) {
+		if !enc.IsKeySet() {
+			return nil, errConfigEncryptionNotConfigured
+		}
+		envelope, err := enc.Seal(raw)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(sealedValue{Sealed: envelope})
+	})
+}
+
+// unsealMigrationConfig decrypts the sealed fields of a stored config
+func unsealMigrationConfig(enc *crypto.EncryptionService, config []byte) ([]byte, error) {
+	return transformSealedFields(config, func(raw json.RawMessage) (json.RawMessage, error) {
+		envelope, ok := parseSealedValue(raw)
+		if !ok {
+			return raw, nil // Written before the field was sealed
+		}
+		return enc.Unseal(envelope)
+	})
+}
+
+// maskMigrationConfig replaces the sealed values of a stored config with a placeholder
+func maskMigrationConfig(config string) string {
+	masked, err := transformSealedFields([]byte(config), func(json.RawMessage) (json.RawMessage, error) {
+		return json.Marshal(maskedConfigValue)
+	})
+	if err != nil {
+		return "{}"
+	}
+	return string(masked)
+}
+
+func parseSealedValue(raw json.RawMessage) (*crypto.Envelope, bool) {
+	var value sealedValue
+	if err := json.Unmarshal(raw, &value); err != nil || value.Sealed == nil {
+		return nil, false
+	}
+	return value.Sealed, true
+}
+
+// transformSealedFields applies transform to each value of the config's sealed fields:
+// to every value of an object field, or to the field itself otherwise
+func transformSealedFields(config []byte, transform func(json.RawMessage) (json.RawMessage, error)) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for _, name := range sealedConfigFields {
+		raw, ok := fields[name]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+
+		var values map[string]json.RawMessage
+		if _, sealed := parseSealedValue(raw); !sealed && json.Unmarshal(raw, &values) == nil {
+			for key, value := range values {
+				transformed, err := transform(value)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", name, key, err)
+				}
+				values[key] = transformed
+			}
+			transformed, err := json.Marshal(values)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = transformed
+		} else {
+			transformed, err := transform(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			fields[name] = transformed
+		}
+		changed = true
+	}
+
+	if !changed {
+		return config, nil
+	}
+	return json.Marshal(fields)
+}
+
+// migrationSettings are the parts of a stored migration config that can change after
+// the migration is created. Sealed fields are left alone.
+type migrationSettings struct {
+	Tables       []string                   `json:"tables,omitempty"`
+	TestCoverage *models.TestCoverageConfig `json:"test_coverage,omitempty"`
+	Scaffolding  *models.ProjectScaffolding `json:"scaffolding,omitempty"`
+}
+
+// loadMigrationSettings reads the status and changeable settings of one of the user's
+// migrations in the organization
+func loadMigrationSettings(store db.Querier, id, userID, orgID int64) (string, migrationSettings, error) {
+	var row struct {
+		Status string         `db:"status"`
+		Config sql.NullString `db:"config"`
+	}
+	var settings migrationSettings
+	if err := store.Get(&row, "SELECT status, config FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID); err != nil {
+		return "", settings, err
+	}
+	if row.Config.Valid {
+		if err := json.Unmarshal([]byte(row.Config.String), &settings); err != nil {
+			log.Printf("Invalid config for migration %d: %v", id, err)
+		}
+	}
+	return row.Status, settings, nil
+}
+
+// updateMigrationSetting replaces one field of a migration's config. It reports false
+// when the migration is running or queued, and so uses the config it has.
+func updateMigrationSetting(store db.Querier, id, userID, orgID int64, field string, value interface{}) (bool, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	result, err := store.Exec(`
+		UPDATE migrations SET config = jsonb_set(COALESCE(config, '{}'::jsonb), ARRAY[$1], $2::jsonb), updated_at = NOW()
+		WHERE id = $3 AND user_id = $4 AND COALESCE(organization_id, 0) = $5 AND status NOT IN ('running', 'queued')
+	`, field, string(raw), id, userID, orgID)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/crypto"
+)
+
+func TestSealMigrationConfigRoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := crypto.NewEncryptionService(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := `{"secrets":{"SALESFORCE_TOKEN":"s3cret"},"tables":["Sales.Customer"]}`
+	sealed, err := sealMigrationConfig(enc, []byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "s3cret") {
+		t.Fatalf("sealed config contains the secret: %s", sealed)
+	}
+	if !strings.Contains(string(sealed), "SALESFORCE_TOKEN") || !strings.Contains(string(sealed), "Sales.Customer") {
+		t.Errorf("sealed config lost unsealed fields: %s", sealed)
+	}
+
+	masked := maskMigrationConfig(string(sealed))
+	if want := `"secrets":{"SALESFORCE_TOKEN":"********"}`; !strings.Contains(masked, want) {
+		t.Errorf("masked = %s, want %s", masked, want)
+	}
+
+	unsealed, err := unsealMigrationConfig(enc, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"secrets":{"SALESFORCE_TOKEN":"s3cret"}`; !strings.Contains(string(unsealed), want) {
+		t.Errorf("unsealed = %s, want %s", unsealed, want)
+	}
+}
+
+func TestSealMigrationConfigWithoutSecrets(t *testing.T) {
+	config := `{"tables":["Sales.Customer"]}`
+	sealed, err := sealMigrationConfig(&crypto.EncryptionService{}, []byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(sealed) != config {
+		t.Errorf("sealed = %s, want the config unchanged", sealed)
+	}
+}
+
+func TestSealMigrationConfigRequiresKey(t *testing.T) {
+	_, err := sealMigrationConfig(&crypto.EncryptionService{}, []byte(`{"secrets":{"API_KEY":"x"}}`))
+	if !errors.Is(err, errConfigEncryptionNotConfigured) {
+		t.Errorf("err = %v, want errConfigEncryptionNotConfigured", err)
+	}
+}
+
+func TestValidateMigrationSecrets(t *testing.T) {
+	if details := validateMigrationSecrets(map[string]string{"DBT_ENV_SECRET_TOKEN": "x"}); len(details) != 0 {
+		t.Errorf("details = %v, want none", details)
+	}
+	if details := validateMigrationSecrets(map[string]string{"not-a-name": "x"}); len(details) != 1 {
+		t.Errorf("details = %v, want one", details)
+	}
+}
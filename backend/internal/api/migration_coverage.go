@@ -0,0 +1,117 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func migrationTestCoverage(cfg *models.TestCoverageConfig) models.MigrationTestCoverage {
+	coverage := models.MigrationTestCoverage{Effective: dbtgen.ResolveTestCoverage(cfg)}
+	if cfg != nil {
+		coverage.TestCoverage = *cfg
+	}
+	return coverage
+}
+
+// GetTestCoverage returns the dbt tests a migration generates
+// @Summary Get generated test coverage
+// @Description The migration's test coverage settings as set, and as they apply with defaults filled in. By default not_null, unique and relationships tests are generated and accepted_values tests aren't.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} models.MigrationTestCoverage
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/test-coverage [get]
+func (h *MigrationsHandler) GetTestCoverage(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	_, config, err := loadMigrationSettings(h.db, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, migrationTestCoverage(config.TestCoverage))
+}
+
+// UpdateTestCoverage replaces the dbt tests a migration generates
+// @Summary Update generated test coverage
+// @Description Replace the migration's test coverage: defaults for every model and overrides per selected table. The next start or rerun uses it. Not allowed while the migration runs or is queued.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.TestCoverageConfig true "Test coverage"
+// @Success 200 {object} models.MigrationTestCoverage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/test-coverage [put]
+func (h *MigrationsHandler) UpdateTestCoverage(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var req models.TestCoverageConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, config, err := loadMigrationSettings(h.db, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return
+	}
+	if status == "running" || status == "queued" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Test coverage can't change while the migration is " + status})
+		return
+	}
+
+	dbtgen.NormalizeTestCoverage(&req)
+	if result := dbtgen.ValidateTestCoverage(&req, config.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	updated, err := updateMigrationSetting(h.db, id, userID, orgID, "test_coverage", req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update test coverage"})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Migration started while updating its test coverage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, migrationTestCoverage(&req))
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func expectMigrationSettings(mock sqlmock.Sqlmock, status, config string) {
+	mock.ExpectQuery(`SELECT status, config FROM migrations`).
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "config"}).AddRow(status, config))
+}
+
+func TestMigrationsGetTestCoverage(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectMigrationSettings(mock, "completed", `{"tables":["Sales.Order"],"test_coverage":{"relationships":false,"accepted_values":{"enabled":true},"tables":[{"table":"Sales.Order","relationships":true}]}}`)
+
+	status, body := serve(t, "GET", "/migrations/:id/test-coverage", "/migrations/7/test-coverage", nil, NewMigrationsHandler(store).GetTestCoverage)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var coverage models.MigrationTestCoverage
+	if err := json.Unmarshal(body, &coverage); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if coverage.TestCoverage.NotNull != nil || *coverage.TestCoverage.Relationships {
+		t.Errorf("test_coverage = %+v, want the settings as stored", coverage.TestCoverage)
+	}
+	effective := coverage.Effective
+	if !*effective.NotNull || *effective.Relationships || effective.AcceptedValues.MaxDistinct != 10 {
+		t.Errorf("effective = %+v", effective.TestCoverage)
+	}
+	if len(effective.Tables) != 1 || !*effective.Tables[0].Relationships || !effective.Tables[0].AcceptedValues.Enabled {
+		t.Errorf("effective tables = %+v", effective.Tables)
+	}
+}
+
+func TestMigrationsUpdateTestCoverage(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectMigrationSettings(mock, "pending", `{"tables":["Sales.Order"]}`)
+	mock.ExpectExec(`UPDATE migrations SET config = jsonb_set`).
+		WithArgs("test_coverage", `{"unique":false,"tables":[{"table":"Sales.Order","accepted_values":{"enabled":true,"max_distinct":25}}]}`, int64(7), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	body := map[string]interface{}{
+		"unique": false,
+		"tables": []map[string]interface{}{
+			{"table": " Sales.Order ", "accepted_values": map[string]interface{}{"enabled": true, "max_distinct": 25}},
+		},
+	}
+	status, resp := serve(t, "PUT", "/migrations/:id/test-coverage", "/migrations/7/test-coverage", body, NewMigrationsHandler(store).UpdateTestCoverage)
+	expectStatus(t, status, http.StatusOK, resp)
+}
+
+func TestMigrationsUpdateTestCoverageValidation(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectMigrationSettings(mock, "pending", `{"tables":["Sales.Order"]}`)
+
+	body := map[string]interface{}{
+		"accepted_values": map[string]interface{}{"enabled": true, "max_distinct": 500},
+		"tables": []map[string]interface{}{
+			{"table": "Sales.Customer", "not_null": false},
+			{"table": "Sales.Order"},
+		},
+	}
+	status, resp := serve(t, "PUT", "/migrations/:id/test-coverage", "/migrations/7/test-coverage", body, NewMigrationsHandler(store).UpdateTestCoverage)
+	expectStatus(t, status, http.StatusBadRequest, resp)
+
+	var result struct {
+		Details []struct {
+			Field string `json:"field"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []string{"test_coverage.accepted_values.max_distinct", "test_coverage.tables[0].table", "test_coverage.tables[1]"}
+	if len(result.Details) != len(want) {
+		t.Fatalf("details = %s", resp)
+	}
+	for i, d := range result.Details {
+		if d.Field != want[i] {
+			t.Errorf("details[%d] = %s, want %s", i, d.Field, want[i])
+		}
+	}
+}
+
+func TestMigrationsUpdateTestCoverageWhileRunning(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectMigrationSettings(mock, "running", `{}`)
+
+	status, resp := serve(t, "PUT", "/migrations/:id/test-coverage", "/migrations/7/test-coverage", map[string]bool{"not_null": false}, NewMigrationsHandler(store).UpdateTestCoverage)
+	expectStatus(t, status, http.StatusConflict, resp)
+}
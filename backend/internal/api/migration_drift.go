@@ -0,0 +1,408 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// driftCheckInterval is how often deployed migrations are checked for schema drift
+	driftCheckInterval = 6 * time.Hour
+	// driftCheckTimeout bounds reading one migration's tables from its warehouse
+	driftCheckTimeout = 2 * time.Minute
+)
+
+// errNoDeployment is returned when a migration was never deployed to a connection
+var errNoDeployment = errors.New("migration has no completed warehouse deployment")
+
+var typeParamsRegex = regexp.MustCompile(`\s*\(.*\)\s*$`)
+
+// typeAliases maps warehouse type names onto the names dbt projects usually declare
+var typeAliases = map[string]string{
+	"character varying":           "varchar",
+	"character":                   "char",
+	"int4":                        "integer",
+	"int":                         "integer",
+	"int8":                        "bigint",
+	"int2":                        "smallint",
+	"bool":                        "boolean",
+	"bit":                         "boolean",
+	"float8":                      "double precision",
+	"float":                       "double precision",
+	"timestamp without time zone": "timestamp",
+	"datetime2":                   "timestamp",
+	"datetime":                    "timestamp",
+	"timestamp with time zone":    "timestamptz",
+	"datetimeoffset":              "timestamptz",
+	"nvarchar":                    "varchar",
+	"decimal":                     "numeric",
+}
+
+// GetDrift reports differences between a migration's generated models and their tables
+// in the warehouse it was last deployed to
+// @Summary Get schema drift report
+// @Description Compare the columns documented for the generated models with the tables and views in the warehouse of the latest completed deployment: missing tables, missing or unexpected columns and changed data types. Checks run every 6 hours; refresh=true checks now.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param refresh query bool false "Check the warehouse now instead of returning the last check"
+// @Success 200 {object} models.MigrationDrift
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/drift [get]
+func (h *MigrationsHandler) GetDrift(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3)", id, userID, orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+		return
+	}
+
+	drift, err := loadDrift(h.db, id)
+	if err == sql.ErrNoRows || (err == nil && c.Query("refresh") == "true") {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), driftCheckTimeout)
+		defer cancel()
+		drift, err = checkDrift(ctx, h.db, id)
+	}
+	if err != nil {
+		if err == errNoDeployment {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration has no completed warehouse deployment"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check schema drift"})
+		return
+	}
+	c.JSON(http.StatusOK, drift)
+}
+
+func loadDrift(store db.Querier, migrationID int64) (*models.MigrationDrift, error) {
+	var row struct {
+		models.MigrationDrift
+		Findings string `db:"findings"`
+	}
+	err := store.Get(&row, `
+		SELECT migration_id, connection_id, status, findings, models_checked, error, drift_since, checked_at
+		FROM migration_drift
+		WHERE migration_id = $1
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+	drift := row.MigrationDrift
+	drift.Findings = []models.DriftFinding{}
+	if err := json.Unmarshal([]byte(row.Findings), &drift.Findings); err != nil {
+		return nil, err
+	}
+	return &drift, nil
+}
+
+// checkDrift compares the migration's documented models with the warehouse of its latest
+// completed deployment, stores the result and notifies the organization of new drift.
+// Warehouse and project problems are stored as a failed check rather than returned.
+func checkDrift(ctx context.Context, store db.Querier, migrationID int64) (*models.MigrationDrift, error) {
+	var target sourceConnection
+	err := store.Get(&target, `
+		SELECT dc.id, dc.name, dc.db_type, dc.host, dc.port, dc.database_name, dc.username, dc.password,
+		       COALESCE(dc.use_windows_auth, false) AS use_windows_auth, dc.extra_config
+		FROM warehouse_deployments d
+		JOIN database_connections dc ON dc.id = d.connection_id
+		WHERE d.migration_id = $1 AND d.status = 'completed'
+		ORDER BY d.created_at DESC
+		LIMIT 1
+	`, migrationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errNoDeployment
+		}
+		return nil, err
+	}
+
+	expected, err := documentedModels(store, migrationID)
+	if err != nil {
+		return nil, err
+	}
+	var findings []models.DriftFinding
+	checkErr := ""
+	switch {
+	case len(expected) == 0:
+		checkErr = "The generated project documents no model columns to compare"
+	case !dbtest.SupportsTableColumns(target.DBType):
+		checkErr = fmt.Sprintf("Drift detection isn't supported for %s warehouses", target.DBType)
+	default:
+		relations := make([]string, len(expected))
+		for i, m := range expected {
+			relations[i] = m.Relation()
+		}
+		columns, err := dbtest.TableColumns(ctx, target.params(), relations)
+		if err != nil {
+			checkErr = "Failed to read the warehouse schema: " + err.Error()
+		} else {
+			findings = compareModelSchemas(expected, columns)
+		}
+	}
+
+	if checkErr != "" {
+		// Keep the last findings so a warehouse outage doesn't reset drift_since
+		_, err = store.Exec(`
+			INSERT INTO migration_drift (migration_id, connection_id, status, models_checked, error, checked_at)
+			VALUES ($1, $2, 'error', $3, $4, NOW())
+			ON CONFLICT (migration_id) DO UPDATE
+			SET connection_id = EXCLUDED.connection_id, status = 'error', models_checked = EXCLUDED.models_checked,
+			    error = EXCLUDED.error, checked_at = NOW()
+		`, migrationID, target.ID, len(expected), checkErr)
+		if err != nil {
+			return nil, err
+		}
+		return loadDrift(store, migrationID)
+	}
+
+	if findings == nil {
+		findings = []models.DriftFinding{}
+	}
+	encoded, err := json.Marshal(findings)
+	if err != nil {
+		return nil, err
+	}
+	status := "ok"
+	if len(findings) > 0 {
+		status = "drift"
+	}
+
+	// drift_since moves only when the findings change, which is also when to notify
+	var changed bool
+	err = store.QueryRow(`
+		WITH previous AS (SELECT findings FROM migration_drift WHERE migration_id = $1)
+		INSERT INTO migration_drift (migration_id, connection_id, status, findings, models_checked, error, drift_since, checked_at)
+		VALUES ($1, $2, $3, $4, $5, NULL, CASE WHEN $3 = 'drift' THEN NOW() END, NOW())
+		ON CONFLICT (migration_id) DO UPDATE
+		SET connection_id = EXCLUDED.connection_id, status = EXCLUDED.status, findings = EXCLUDED.findings,
+		    models_checked = EXCLUDED.models_checked, error = NULL, checked_at = NOW(),
+		    drift_since = CASE
+		        WHEN EXCLUDED.status <> 'drift' THEN NULL
+		        WHEN migration_drift.findings = EXCLUDED.findings THEN migration_drift.drift_since
+		        ELSE NOW()
+		    END
+		RETURNING NOT EXISTS (SELECT 1 FROM previous WHERE previous.findings = $4::jsonb)
+	`, migrationID, target.ID, status, string(encoded), len(expected)).Scan(&changed)
+	if err != nil {
+		return nil, err
+	}
+	if changed && status == "drift" {
+		go notifyDrift(store, migrationID, findings)
+	}
+	return loadDrift(store, migrationID)
+}
+
+// documentedModels reads the models with documented columns from the properties files
+// of the migration's current run
+func documentedModels(store db.Querier, migrationID int64) ([]dbtgen.ModelSchema, error) {
+	var files []struct {
+		Path    string `db:"path"`
+		Content string `db:"content"`
+	}
+	err := store.Select(&files, `
+		SELECT f.path, f.content
+		FROM migration_run_files f
+		JOIN migrations m ON m.current_run_id = f.run_id
+		WHERE m.id = $1 AND f.content IS NOT NULL
+		ORDER BY f.path
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []dbtgen.ModelSchema
+	for _, f := range files {
+		if !dbtgen.IsPropertiesFile(f.Path) {
+			continue
+		}
+		parsed, err := dbtgen.ParseModelSchemas(f.Content)
+		if err != nil {
+			log.Printf("Skipping unparseable %s of migration %d: %v", f.Path, migrationID, err)
+			continue
+		}
+		schemas = append(schemas, parsed...)
+	}
+	sort.SliceStable(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	return schemas, nil
+}
+
+// compareModelSchemas finds the differences between documented models and the warehouse
+// columns of their relations. When a relation exists in several schemas, the schema
+// holding most of the project's relations is taken to be the deployed one.
+func compareModelSchemas(expected []dbtgen.ModelSchema, columns []dbtest.WarehouseColumn) []models.DriftFinding {
+	tables, deployed := groupWarehouseColumns(columns)
+
+	findings := []models.DriftFinding{}
+	for _, m := range expected {
+		candidates := tables[strings.ToLower(m.Relation())]
+		schema := pickDriftSchema(candidates, m.Schema, deployed)
+		if schema == "" {
+			findings = append(findings, models.DriftFinding{Model: m.Name, Kind: "missing_table"})
+			continue
+		}
+		relation := schema + "." + candidates[schema][0].Table
+
+		actual := map[string]dbtest.WarehouseColumn{}
+		for _, c := range candidates[schema] {
+			actual[strings.ToLower(c.Column)] = c
+		}
+		documented := map[string]bool{}
+		for _, col := range m.Columns {
+			key := strings.ToLower(col.Name)
+			documented[key] = true
+			c, ok := actual[key]
+			switch {
+			case !ok:
+				findings = append(findings, models.DriftFinding{Model: m.Name, Relation: relation, Kind: "missing_column", Column: col.Name})
+			case col.DataType != "" && normalizeDataType(col.DataType) != normalizeDataType(c.DataType):
+				findings = append(findings, models.DriftFinding{Model: m.Name, Relation: relation, Kind: "type_changed",
+					Column: col.Name, Expected: col.DataType, Actual: c.DataType})
+			}
+		}
+		for _, c := range candidates[schema] {
+			if !documented[strings.ToLower(c.Column)] {
+				findings = append(findings, models.DriftFinding{Model: m.Name, Relation: relation, Kind: "unexpected_column", Column: c.Column})
+			}
+		}
+	}
+	return findings
+}
+
+// groupWarehouseColumns groups columns by lowercased relation name and schema, and finds
+// the schema holding most of the relations
+func groupWarehouseColumns(columns []dbtest.WarehouseColumn) (map[string]map[string][]dbtest.WarehouseColumn, string) {
+	tables := map[string]map[string][]dbtest.WarehouseColumn{}
+	relationsPerSchema := map[string]int{}
+	for _, c := range columns {
+		name := strings.ToLower(c.Table)
+		if tables[name] == nil {
+			tables[name] = map[string][]dbtest.WarehouseColumn{}
+		}
+		if len(tables[name][c.Schema]) == 0 {
+			relationsPerSchema[c.Schema]++
+		}
+		tables[name][c.Schema] = append(tables[name][c.Schema], c)
+	}
+	deployed := ""
+	for schema, n := range relationsPerSchema {
+		if n > relationsPerSchema[deployed] || (n == relationsPerSchema[deployed] && schema < deployed) {
+			deployed = schema
+		}
+	}
+	return tables, deployed
+}
+
+// pickDriftSchema chooses which of the schemas holding a relation the model was deployed
+// to: the one matching its custom schema, the project's deployed schema, or the only one
+func pickDriftSchema(candidates map[string][]dbtest.WarehouseColumn, custom, deployed string) string {
+	if custom != "" {
+		custom = strings.ToLower(custom)
+		for schema := range candidates {
+			if s := strings.ToLower(schema); s == custom || strings.HasSuffix(s, "_"+custom) {
+				return schema
+			}
+		}
+	}
+	if _, ok := candidates[deployed]; ok {
+		return deployed
+	}
+	schemas := make([]string, 0, len(candidates))
+	for schema := range candidates {
+		schemas = append(schemas, schema)
+	}
+	if len(schemas) == 0 {
+		return ""
+	}
+	sort.Strings(schemas)
+	return schemas[0]
+}
+
+// normalizeDataType drops length and precision and maps aliases, so that varchar(50)
+// matches character varying
+func normalizeDataType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(typeParamsRegex.ReplaceAllString(t, "")))
+	if alias, ok := typeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// driftSummary counts findings by kind, e.g. "2 missing columns, 1 unexpected column"
+func driftSummary(findings []models.DriftFinding) string {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Kind]++
+	}
+	var parts []string
+	for _, kind := range []string{"missing_table", "missing_column", "unexpected_column", "type_changed"} {
+		if n := counts[kind]; n > 0 {
+			label := strings.ReplaceAll(kind, "_", " ")
+			if kind == "type_changed" {
+				label = "changed column type"
+			}
+			if n > 1 {
+				label += "s"
+			}
+			parts = append(parts, fmt.Sprintf("%d %s", n, label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StartDriftDetection periodically checks the warehouses of deployed migrations for
+// schema drift
+func StartDriftDetection() {
+	go func() {
+		ticker := time.NewTicker(driftCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			var migrationIDs []int64
+			err := db.DB.Select(&migrationIDs, `
+				SELECT DISTINCT migration_id FROM warehouse_deployments
+				WHERE status = 'completed' AND connection_id IS NOT NULL
+			`)
+			if err != nil {
+				log.Printf("Failed to fetch deployed migrations for drift detection: %v", err)
+				continue
+			}
+			for _, id := range migrationIDs {
+				ctx, cancel := context.WithTimeout(context.Background(), driftCheckTimeout)
+				if _, err := checkDrift(ctx, db.DB, id); err != nil && err != errNoDeployment {
+					log.Printf("Failed to check schema drift of migration %d: %v", id, err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+const driftProperties = `
+version: 2
+models:
+  - name: stg_orders
+    columns:
+      - name: order_id
+        data_type: integer
+      - name: ordered_at
+      - name: amount
+        data_type: numeric(18,2)
+  - name: dim_customers
+    config:
+      schema: marts
+    columns:
+      - name: customer_id
+  - name: fct_payments
+    columns:
+      - name: payment_id
+  - name: int_order_items
+    config:
+      materialized: ephemeral
+    columns:
+      - name: order_item_id
+`
+
+func TestCompareModelSchemas(t *testing.T) {
+	expected, err := dbtgen.ParseModelSchemas(driftProperties)
+	if err != nil {
+		t.Fatalf("ParseModelSchemas: %v", err)
+	}
+	columns := []dbtest.WarehouseColumn{
+		{Schema: "analytics", Table: "stg_orders", Column: "order_id", DataType: "bigint"},
+		{Schema: "analytics", Table: "stg_orders", Column: "amount", DataType: "numeric"},
+		{Schema: "analytics", Table: "stg_orders", Column: "discount", DataType: "numeric"},
+		// A stale copy in a dev schema is ignored
+		{Schema: "dbt_dev", Table: "stg_orders", Column: "order_id", DataType: "integer"},
+		{Schema: "analytics_marts", Table: "DIM_CUSTOMERS", Column: "CUSTOMER_ID", DataType: "integer"},
+	}
+
+	got := compareModelSchemas(expected, columns)
+	want := []models.DriftFinding{
+		{Model: "stg_orders", Relation: "analytics.stg_orders", Kind: "type_changed", Column: "order_id", Expected: "integer", Actual: "bigint"},
+		{Model: "stg_orders", Relation: "analytics.stg_orders", Kind: "missing_column", Column: "ordered_at"},
+		{Model: "stg_orders", Relation: "analytics.stg_orders", Kind: "unexpected_column", Column: "discount"},
+		{Model: "fct_payments", Kind: "missing_table"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compareModelSchemas =\n%+v\nwant\n%+v", got, want)
+	}
+	if summary := driftSummary(got); summary != "1 missing table, 1 missing column, 1 unexpected column, 1 changed column type" {
+		t.Errorf("driftSummary = %q", summary)
+	}
+}
+
+func TestNormalizeDataType(t *testing.T) {
+	for in, want := range map[string]string{
+		"character varying(255)":      "varchar",
+		"NVARCHAR(MAX)":               "varchar",
+		"timestamp without time zone": "timestamp",
+		"datetime2":                   "timestamp",
+		"numeric(18, 2)":              "numeric",
+		"int":                         "integer",
+	} {
+		if got := normalizeDataType(in); got != want {
+			t.Errorf("normalizeDataType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMigrationsGetDriftReturnsLastCheck(t *testing.T) {
+	store, mock := newMockDB(t)
+	checked := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM migrations`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM migration_drift`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"migration_id", "connection_id", "status", "findings", "models_checked", "error", "drift_since", "checked_at"}).
+			AddRow(8, 4, "drift", `[{"model":"fct_payments","kind":"missing_table"}]`, 3, nil, checked, checked))
+
+	status, body := serve(t, "GET", "/migrations/:id/drift", "/migrations/8/drift", nil, NewMigrationsHandler(store).GetDrift)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var drift models.MigrationDrift
+	if err := json.Unmarshal(body, &drift); err != nil {
+		t.Fatalf("response is not JSON: %s", body)
+	}
+	if drift.Status != "drift" || len(drift.Findings) != 1 || drift.Findings[0].Kind != "missing_table" {
+		t.Errorf("drift = %+v", drift)
+	}
+}
+
+func TestMigrationsGetDriftWithoutDeployment(t *testing.T) {
+	store, mock := newMockDB(t)
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM migrations`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM migration_drift`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"migration_id"}))
+	mock.ExpectQuery(`FROM warehouse_deployments d\s+JOIN database_connections dc`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	status, body := serve(t, "GET", "/migrations/:id/drift", "/migrations/8/drift", nil, NewMigrationsHandler(store).GetDrift)
+	expectStatus(t, status, http.StatusNotFound, body)
+	if got := errorMessage(t, body); got != "Migration has no completed warehouse deployment" {
+		t.Errorf("error = %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+// duplicateMigrationWindow is how far back a new migration is checked against existing ones
+const duplicateMigrationWindow = 24 * time.Hour
+
+// findDuplicateMigration returns the newest migration created in the duplicate window
+// with the same name, source and tables as a new one, or nil. Names match ignoring case
+// and tables ignoring case and order; no tables means every table.
+func findDuplicateMigration(store db.Querier, name string, source ownedConnection, tables []string, userID, orgID int64) (*models.DuplicateMigration, error) {
+	var candidates []struct {
+		models.DuplicateMigration
+		Tables string `db:"tables"`
+	}
+	err := store.Select(&candidates, `
+		SELECT id, name, status, created_at, COALESCE(config->'tables', '[]'::jsonb)::text AS tables
+		FROM migrations
+		WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2
+		  AND connection_id = $3 AND LOWER(TRIM(name)) = LOWER(TRIM($4)) AND created_at > $5
+		ORDER BY created_at DESC
+	`, userID, orgID, source.ID, name, time.Now().Add(-duplicateMigrationWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	want := tableSet(tables)
+	for _, candidate := range candidates {
+		var existing []string
+		if err := json.Unmarshal([]byte(candidate.Tables), &existing); err != nil {
+			log.Printf("Invalid tables in config of migration %d: %v", candidate.ID, err)
+			continue
+		}
+		if sameTableSet(want, tableSet(existing)) {
+			duplicate := candidate.DuplicateMigration
+			return &duplicate, nil
+		}
+	}
+	return nil, nil
+}
+
+func tableSet(tables []string) map[string]bool {
+	set := map[string]bool{}
+	for _, table := range tables {
+		set[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+	return set
+}
+
+func sameTableSet(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for table := range a {
+		if !b[table] {
+			return false
+		}
+	}
+	return true
+}
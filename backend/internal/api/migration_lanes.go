@@ -0,0 +1,293 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/quota"
+	"github.com/datamigrate-ai/backend/internal/settings"
+)
+
+// Priority lanes: when an AI service instance is limited to a number of concurrent
+// migrations (the ai_migration_slots setting, or the slots it reports), migrations waiting for one of its slots start by plan
+// priority (enterprise first, free last) instead of oldest first. Two fairness caps keep
+// free-tier migrations moving: a waiting migration moves up a lane every
+// laneAgingInterval, and one organization holds at most 1/laneOrganizationShare of an
+// instance's slots while other organizations wait.
+const (
+	laneAgingInterval     = 15 * time.Minute
+	laneOrganizationShare = 2
+)
+
+// aiServiceSlots is how many migrations the region's AI service instance runs at once,
+// 0 for no limit, and whether it last reported being saturated. The ai_migration_slots
+// setting takes precedence over the slots the instance reports.
+func aiServiceSlots(region string) (int, bool) {
+	slots := settings.Current().AIMigrationSlots
+	client, err := aiservice.ForRegion(region)
+	if err != nil || client == nil {
+		return slots, false
+	}
+	capacity := client.Capacity()
+	if capacity == nil {
+		return slots, false
+	}
+	if slots == 0 {
+		slots = capacity.Slots
+	}
+	return slots, capacity.Saturated
+}
+
+// aiLaneLockKey is the advisory lock that serializes AI slot claims across connections
+const aiLaneLockKey = 3450
+
+// laneQuerier is what lane lookups need, satisfied by both db.Querier and *sqlx.Tx
+type laneQuerier interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+}
+
+// laneEntry is a migration waiting for (or about to claim) an AI service slot
+type laneEntry struct {
+	ID             int64     `db:"id"`
+	UserID         int64     `db:"user_id"`
+	OrganizationID int64     `db:"organization_id"` // 0 without an organization
+	Plan           string    `db:"plan"`
+	QueuedAt       time.Time `db:"queued_at"`
+}
+
+// owner is who the organization cap counts slots for: the organization, or the user
+// for migrations outside one
+func (e laneEntry) owner() int64 {
+	if e.OrganizationID > 0 {
+		return e.OrganizationID
+	}
+	return -e.UserID
+}
+
+// priority is the entry's lane: its plan's priority, raised for every aging interval it
+// has waited
+func (e laneEntry) priority(now time.Time) int {
+	return min(quota.PlanPriority[e.Plan]+int(now.Sub(e.QueuedAt)/laneAgingInterval), quota.TopPriority)
+}
+
+// orderLane sorts migrations in the order they get AI slots: higher lanes first, then
+// the longest waiting
+func orderLane(entries []laneEntry, now time.Time) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		pi, pj := entries[i].priority(now), entries[j].priority(now)
+		if pi != pj {
+			return pi > pj
+		}
+		if !entries[i].QueuedAt.Equal(entries[j].QueuedAt) {
+			return entries[i].QueuedAt.Before(entries[j].QueuedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
+
+// organizationSlotCap is how many of an instance's slots one owner may hold while
+// others wait
+func organizationSlotCap(slots int) int {
+	return max(slots/laneOrganizationShare, 1)
+}
+
+// grantAISlot reports whether self gets one of the instance's free slots, given the
+// migrations already waiting and the slots each owner holds. Free slots go down the
+// lane order, skipping owners at their cap while someone else waits.
+func grantAISlot(self laneEntry, waiting []laneEntry, running map[int64]int, slots int, now time.Time) bool {
+	free := slots
+	for _, n := range running {
+		free -= n
+	}
+	if free <= 0 {
+		return false
+	}
+
+	entries := append([]laneEntry{self}, waiting...)
+	orderLane(entries, now)
+	owners := map[int64]int{}
+	for _, e := range entries {
+		owners[e.owner()]++
+	}
+
+	held := maps.Clone(running)
+	limit := organizationSlotCap(slots)
+	for _, e := range entries {
+		if free == 0 {
+			break
+		}
+		if held[e.owner()] >= limit && len(owners) > 1 {
+			continue
+		}
+		if e.ID == self.ID {
+			return true
+		}
+		free--
+		held[e.owner()]++
+	}
+	return false
+}
+
+const laneEntryColumns = `m.id, m.user_id, COALESCE(m.organization_id, 0) AS organization_id,
+	COALESCE(o.plan, 'free') AS plan, COALESCE(m.queued_at, NOW()) AS queued_at`
+
+// waitingForAISlot lists the migrations queued for a slot of the region's instance,
+// other than exclude
+func waitingForAISlot(store laneQuerier, region string, exclude int64) ([]laneEntry, error) {
+	var waiting []laneEntry
+	err := store.Select(&waiting, `
+		SELECT `+laneEntryColumns+`
+		FROM migrations m
+		LEFT JOIN organizations o ON o.id = m.organization_id
+		WHERE m.status = 'queued' AND m.queue_reason = $1 AND COALESCE(m.data_region, '') = $2 AND m.id <> $3
+	`, queueReasonAIServiceBusy, region, exclude)
+	return waiting, err
+}
+
+// claimAISlot reports whether the migration may take a slot of the region's AI service
+// instance now. It runs in the slot claim's transaction.
+func claimAISlot(store laneQuerier, migrationID int64, region string, slots int) (bool, error) {
+	var locked string
+	if err := store.Get(&locked, "SELECT pg_advisory_xact_lock($1)::text", aiLaneLockKey); err != nil {
+		return false, err
+	}
+
+	var self laneEntry
+	err := store.Get(&self, `
+		SELECT `+laneEntryColumns+`
+		FROM migrations m
+		LEFT JOIN organizations o ON o.id = m.organization_id
+		WHERE m.id = $1
+	`, migrationID)
+	if err != nil {
+		return false, err
+	}
+
+	waiting, err := waitingForAISlot(store, region, migrationID)
+	if err != nil {
+		return false, err
+	}
+
+	var held []struct {
+		Owner int64 `db:"owner"`
+		Count int   `db:"count"`
+	}
+	err = store.Select(&held, `
+		SELECT COALESCE(organization_id, -user_id) AS owner, COUNT(*) AS count
+		FROM migrations
+		WHERE status = 'running' AND COALESCE(data_region, '') = $1
+		GROUP BY 1
+	`, region)
+	if err != nil {
+		return false, err
+	}
+	running := make(map[int64]int, len(held))
+	for _, h := range held {
+		running[h.Owner] = h.Count
+	}
+
+	return grantAISlot(self, waiting, running, slots, time.Now()), nil
+}
+
+// queuePosition is a queued migration's place in the queue it waits in, starting at 1.
+// Migrations waiting for their run window have none.
+func queuePosition(store db.Querier, migration *models.Migration) (*int, error) {
+	if migration.Status != "queued" || migration.QueueReason == nil {
+		return nil, nil
+	}
+
+	switch *migration.QueueReason {
+	case queueReasonConnectionBusy:
+		var position int
+		err := store.Get(&position, `
+			SELECT COUNT(*) + 1 FROM migrations q, migrations m
+			WHERE m.id = $1 AND q.connection_id = m.connection_id AND q.id <> m.id
+			AND q.status = 'queued' AND q.queue_reason = $2
+			AND (q.queued_at, q.id) < (m.queued_at, m.id)
+		`, migration.ID, queueReasonConnectionBusy)
+		if err != nil {
+			return nil, err
+		}
+		return &position, nil
+
+	case queueReasonAIServiceBusy:
+		var region sql.NullString
+		if err := store.Get(&region, "SELECT data_region FROM migrations WHERE id = $1", migration.ID); err != nil {
+			return nil, err
+		}
+		waiting, err := waitingForAISlot(store, region.String, 0)
+		if err != nil {
+			return nil, err
+		}
+		orderLane(waiting, time.Now())
+		for i, e := range waiting {
+			if e.ID == migration.ID {
+				position := i + 1
+				return &position, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// dispatchAILanes offers freed AI service slots to the migrations waiting for one, on
+// every connection they're queued on. Claims only start the migration the lanes put
+// first, so the order connections are tried in doesn't matter.
+func (h *MigrationsHandler) dispatchAILanes(skipConnectionID int64) {
+	var connectionIDs []int64
+	err := h.db.Select(&connectionIDs, `
+		SELECT DISTINCT connection_id FROM migrations
+		WHERE status = 'queued' AND queue_reason = $1 AND connection_id IS NOT NULL
+	`, queueReasonAIServiceBusy)
+	if err != nil {
+		log.Printf("Failed to fetch connections with migrations waiting for the AI service: %v", err)
+		return
+	}
+	for _, id := range connectionIDs {
+		if id != skipConnectionID {
+			h.dispatchQueued(id)
+		}
+	}
+}
+
+// DispatchAIServiceQueue starts migrations waiting for an AI service slot, for when an
+// instance reports room again
+func DispatchAIServiceQueue(region string) {
+	NewMigrationsHandler(db.DB).dispatchAILanes(0)
+}
+
+// aiServiceCapacity reports the last polled load of each AI service instance, with the
+// migrations queued for its slots
+func (h *MigrationsHandler) aiServiceCapacity() []models.AIServiceCapacity {
+	var capacities []models.AIServiceCapacity
+	for _, client := range aiservice.Clients() {
+		capacity := client.Capacity()
+		if capacity == nil {
+			continue
+		}
+		report := models.AIServiceCapacity{
+			Region:     capacity.Region,
+			Slots:      capacity.Slots,
+			Active:     capacity.Active,
+			QueueDepth: capacity.QueueDepth,
+			Saturated:  capacity.Saturated,
+			CheckedAt:  capacity.CheckedAt,
+		}
+		err := h.db.Get(&report.Queued, `
+			SELECT COUNT(*) FROM migrations
+			WHERE status = 'queued' AND queue_reason = $1 AND COALESCE(data_region, '') = $2
+		`, queueReasonAIServiceBusy, capacity.Region)
+		if err != nil {
+			log.Printf("Failed to count migrations queued for the AI service: %v", err)
+		}
+		capacities = append(capacities, report)
+	}
+	return capacities
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func TestGrantAISlotByPlanPriority(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	free := laneEntry{ID: 1, UserID: 10, OrganizationID: 100, Plan: "free", QueuedAt: now.Add(-5 * time.Minute)}
+	enterprise := laneEntry{ID: 2, UserID: 20, OrganizationID: 200, Plan: "enterprise", QueuedAt: now}
+
+	// One free slot goes to the enterprise migration though the free one waited longer
+	if !grantAISlot(enterprise, []laneEntry{free}, map[int64]int{300: 3}, 4, now) {
+		t.Error("enterprise migration didn't get the free slot")
+	}
+	if grantAISlot(free, []laneEntry{enterprise}, map[int64]int{300: 3}, 4, now) {
+		t.Error("free migration took the slot ahead of an enterprise one")
+	}
+	// With two free slots both start
+	if !grantAISlot(free, []laneEntry{enterprise}, map[int64]int{300: 2}, 4, now) {
+		t.Error("free migration didn't get the second free slot")
+	}
+	// No slot is free
+	if grantAISlot(enterprise, nil, map[int64]int{300: 4}, 4, now) {
+		t.Error("migration started on a full instance")
+	}
+}
+
+func TestGrantAISlotAging(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	// Three aging intervals lift a free migration to the enterprise lane, where it's older
+	free := laneEntry{ID: 1, UserID: 10, OrganizationID: 100, Plan: "free", QueuedAt: now.Add(-3 * laneAgingInterval)}
+	enterprise := laneEntry{ID: 2, UserID: 20, OrganizationID: 200, Plan: "enterprise", QueuedAt: now.Add(-time.Minute)}
+
+	if !grantAISlot(free, []laneEntry{enterprise}, map[int64]int{300: 3}, 4, now) {
+		t.Error("aged free migration didn't get the slot")
+	}
+}
+
+func TestGrantAISlotOrganizationCap(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	enterprise := laneEntry{ID: 2, UserID: 20, OrganizationID: 200, Plan: "enterprise", QueuedAt: now}
+	starter := laneEntry{ID: 3, UserID: 30, OrganizationID: 300, Plan: "starter", QueuedAt: now}
+
+	// The enterprise organization holds half of the slots, so the starter one goes first
+	if grantAISlot(enterprise, []laneEntry{starter}, map[int64]int{200: 2}, 4, now) {
+		t.Error("organization went past its share while another waited")
+	}
+	if !grantAISlot(starter, []laneEntry{enterprise}, map[int64]int{200: 2}, 4, now) {
+		t.Error("starter migration didn't get the slot the capped organization skipped")
+	}
+	// Nobody else waits, so the cap doesn't apply
+	if !grantAISlot(enterprise, nil, map[int64]int{200: 2}, 4, now) {
+		t.Error("cap applied with nobody else waiting")
+	}
+}
+
+func TestQueuePositionConnectionBusy(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) \+ 1 FROM migrations q, migrations m`).
+		WithArgs(int64(8), queueReasonConnectionBusy).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(3))
+
+	reason := queueReasonConnectionBusy
+	position, err := queuePosition(store, &models.Migration{ID: 8, Status: "queued", QueueReason: &reason})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position == nil || *position != 3 {
+		t.Errorf("position = %v, want 3", position)
+	}
+}
+
+func TestMigrationsGetOneReportsAILanePosition(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	columns := []string{"id", "name", "status", "progress", "connection_id", "source_database", "target_project",
+		"tables_count", "views_count", "foreign_keys_count", "models_generated", "user_id", "error", "queue_reason",
+		"ticket_key", "ticket_url", "config", "created_at", "completed_at", "updated_at"}
+	mock.ExpectQuery(`FROM migrations\s+WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(8, "Sales", "queued", 0, 4, "Contoso", "sales", 12, 0, 0, 0,
+			testUserID, nil, queueReasonAIServiceBusy, nil, nil, nil, now, nil, now))
+	mock.ExpectQuery(`SELECT data_region FROM migrations WHERE id = \$1`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"data_region"}).AddRow("eu"))
+	mock.ExpectQuery(`WHERE m.status = 'queued' AND m.queue_reason = \$1`).
+		WithArgs(queueReasonAIServiceBusy, "eu", int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "organization_id", "plan", "queued_at"}).
+			AddRow(8, testUserID, testOrgID, "starter", now.Add(-time.Minute)).
+			AddRow(9, 50, 5, "enterprise", now).
+			AddRow(7, 60, 6, "free", now.Add(-2*time.Minute)))
+
+	status, body := serve(t, "GET", "/migrations/:id", "/migrations/8", nil, NewMigrationsHandler(store).GetOne)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var migration models.Migration
+	if err := json.Unmarshal(body, &migration); err != nil {
+		t.Fatal(err)
+	}
+	if migration.QueuePosition == nil || *migration.QueuePosition != 2 {
+		t.Errorf("queue_position = %v, want 2", migration.QueuePosition)
+	}
+}
+
+func TestStartedResponseQueuePosition(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) \+ 1 FROM migrations q, migrations m`).
+		WithArgs(int64(8), queueReasonConnectionBusy).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(2))
+
+	response := startedResponse(store, 8, queueReasonConnectionBusy)
+	if response["queue_position"] != 2 || response["message"] != "Migration queued, position 2; it starts once its connection has a free slot" {
+		t.Errorf("response = %v", response)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+// manifestDigest hashes a file list in sha256sum format ("<sha256>  <path>" per line,
+// sorted by path), so the digest only changes when a file is added, removed or changed
+func manifestDigest(paths, checksums []string) string {
+	order := make([]int, len(paths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return paths[order[i]] < paths[order[j]] })
+
+	var sb strings.Builder
+	for _, i := range order {
+		fmt.Fprintf(&sb, "%s  %s\n", checksums[i], paths[i])
+	}
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadFileManifest returns the checksum manifest of a migration's current run without
+// its files, and the run's ID. The manifest is nil when the run hasn't completed with a
+// file snapshot.
+func loadFileManifest(store db.Querier, migrationID int64) (*models.FileManifest, int64, error) {
+	var run struct {
+		ID            int64          `db:"id"`
+		RunNumber     int            `db:"run_number"`
+		ArtifactsPath sql.NullString `db:"artifacts_path"`
+		SHA256        string         `db:"manifest_sha256"`
+	}
+	err := store.Get(&run, `
+		SELECT r.id, r.run_number, r.artifacts_path, r.manifest_sha256
+		FROM migration_runs r
+		JOIN migrations m ON m.current_run_id = r.id
+		WHERE m.id = $1 AND r.manifest_sha256 IS NOT NULL
+	`, migrationID)
+	if err == sql.ErrNoRows {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &models.FileManifest{
+		MigrationID: migrationID,
+		ProjectPath: run.ArtifactsPath.String,
+		RunNumber:   run.RunNumber,
+		SHA256:      run.SHA256,
+		Files:       []models.ManifestFile{},
+	}, run.ID, nil
+}
+
+// loadManifestFiles lists the files of a run's checksum manifest
+func loadManifestFiles(store db.Querier, runID int64) ([]models.ManifestFile, error) {
+	files := []models.ManifestFile{}
+	err := store.Select(&files, `
+		SELECT path, COALESCE(size, 0) AS size, COALESCE(file_type, '') AS file_type, checksum
+		FROM migration_run_files
+		WHERE run_id = $1
+		ORDER BY path
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		files[i].Name = path.Base(files[i].Path)
+	}
+	return files, nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const testManifestSHA = "5f1e0c2b7d9a4e3f8c6b1a0d2e4f6a8b9c7d5e3f1a2b4c6d8e0f1a3b5c7d9e1f"
+
+func TestManifestDigestIgnoresOrder(t *testing.T) {
+	a := manifestDigest([]string{"models/a.sql", "models/b.sql"}, []string{"aa", "bb"})
+	b := manifestDigest([]string{"models/b.sql", "models/a.sql"}, []string{"bb", "aa"})
+	if a != b {
+		t.Error("the digest depends on file order")
+	}
+	if c := manifestDigest([]string{"models/a.sql", "models/b.sql"}, []string{"aa", "bc"}); c == a {
+		t.Error("a changed checksum didn't change the digest")
+	}
+}
+
+func expectFileManifest(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT id FROM migrations WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery(`FROM migration_runs r\s+JOIN migrations m ON m.current_run_id = r.id`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "run_number", "artifacts_path", "manifest_sha256"}).
+			AddRow(12, 2, "/projects/7", testManifestSHA))
+}
+
+func TestMigrationsGetFilesManifest(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectFileManifest(mock)
+	mock.ExpectQuery(`FROM migration_run_files`).
+		WithArgs(int64(12)).
+		WillReturnRows(sqlmock.NewRows([]string{"path", "size", "file_type", "checksum"}).
+			AddRow("models/staging/stg_orders.sql", 140, "sql", "ab12"))
+
+	status, body := serve(t, "GET", "/migrations/:id/files", "/migrations/7/files", nil, NewMigrationsHandler(store).GetFiles)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var manifest models.FileManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.SHA256 != testManifestSHA || manifest.RunNumber != 2 || len(manifest.Files) != 1 ||
+		manifest.Files[0].Name != "stg_orders.sql" || manifest.Files[0].SHA256 != "ab12" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+}
+
+func TestMigrationsGetFilesNotModified(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectFileManifest(mock)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Set("organization_id", testOrgID)
+		c.Next()
+	})
+	router.GET("/migrations/:id/files", NewMigrationsHandler(store).GetFiles)
+	req := httptest.NewRequest(http.MethodGet, "/migrations/7/files", nil)
+	req.Header.Set("If-None-Match", `"`+testManifestSHA+`"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", w.Code)
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+var phaseNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// recordPhaseMetrics stores the usage reported for migration phases, replacing earlier
+// reports for the same phase so retried status updates aren't counted twice
+func recordPhaseMetrics(store db.Querier, migrationID int64, metrics []models.PhaseMetrics) error {
+	for _, m := range metrics {
+		_, err := store.Exec(`
+			INSERT INTO migration_metrics (migration_id, phase, duration_ms, prompt_tokens, completion_tokens, tables_processed, lines_generated)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (migration_id, phase) DO UPDATE
+			SET duration_ms = EXCLUDED.duration_ms, prompt_tokens = EXCLUDED.prompt_tokens,
+			    completion_tokens = EXCLUDED.completion_tokens, tables_processed = EXCLUDED.tables_processed,
+			    lines_generated = EXCLUDED.lines_generated, updated_at = NOW()
+		`, migrationID, m.Phase, m.DurationMs, m.PromptTokens, m.CompletionTokens, m.TablesProcessed, m.LinesGenerated)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMetrics returns a migration's resource usage
+// @Summary Get migration resource usage
+// @Description Duration, AI tokens, throughput and generated lines of code per phase, with totals
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} models.MigrationMetrics
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/metrics [get]
+func (h *MigrationsHandler) GetMetrics(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var migration struct {
+		Status      string `db:"status"`
+		TablesCount int    `db:"tables_count"`
+	}
+	err = h.db.Get(&migration, `
+		SELECT status, COALESCE(tables_count, 0) AS tables_count
+		FROM migrations
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	phases := []models.PhaseMetrics{}
+	err = h.db.Select(&phases, `
+		SELECT phase, duration_ms, prompt_tokens, completion_tokens, tables_processed, lines_generated, updated_at
+		FROM migration_metrics
+		WHERE migration_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch metrics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summarizeMetrics(id, migration.Status, migration.TablesCount, phases))
+}
+
+func summarizeMetrics(migrationID int64, status string, tablesCount int, phases []models.PhaseMetrics) models.MigrationMetrics {
+	metrics := models.MigrationMetrics{
+		MigrationID: migrationID,
+		Status:      status,
+		Phases:      phases,
+		TablesCount: tablesCount,
+	}
+	for _, p := range phases {
+		metrics.DurationMs += p.DurationMs
+		metrics.PromptTokens += p.PromptTokens
+		metrics.CompletionTokens += p.CompletionTokens
+		metrics.LinesGenerated += p.LinesGenerated
+	}
+	metrics.TotalTokens = metrics.PromptTokens + metrics.CompletionTokens
+	if metrics.DurationMs > 0 {
+		metrics.TablesPerSecond = float64(tablesCount) / (float64(metrics.DurationMs) / 1000)
+	}
+	return metrics
+}
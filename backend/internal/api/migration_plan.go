@@ -0,0 +1,396 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/quota"
+	"github.com/gin-gonic/gin"
+)
+
+// planExtractTimeout bounds the schema read of a dry run, which the caller waits on
+const planExtractTimeout = 2 * time.Minute
+
+// unportableColumnTypes are SQL Server column types dbt targets commonly can't hold as
+// they are; models over them need a cast or the column dropped
+var unportableColumnTypes = map[string]bool{
+	"xml": true, "geography": true, "geometry": true, "hierarchyid": true, "sql_variant": true,
+	"image": true, "text": true, "ntext": true, "timestamp": true, "rowversion": true,
+}
+
+// Plan previews what a migration would generate
+// @Summary Plan a migration
+// @Description Read the source schema and return the models, sources and tests generation would produce: names, folders, build order and dependencies, without generating SQL. Tables, views, scaffolding and test coverage in the body replace the migration's own for this plan only, so a selection can be tried before it's saved. The migration stays as it is and no plan quota is used.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.PlanMigrationRequest false "Settings to plan with instead of the migration's"
+// @Success 200 {object} models.MigrationPlan
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/plan [post]
+func (h *MigrationsHandler) Plan(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var req *models.PlanMigrationRequest
+	if c.Request.ContentLength > 0 {
+		req = &models.PlanMigrationRequest{}
+		if err := c.ShouldBindJSON(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	plan, failure := h.planMigration(id, userID, orgID, req)
+	if failure != nil {
+		c.JSON(failure.Status, failure.Body)
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// planMigration is the dry run of a migration: it reads the source schema and plans the
+// models generation would produce, with overrides in place of the migration's settings
+// when set. Nothing is generated, the migration's status is left alone and no plan
+// quota is used.
+func (h *MigrationsHandler) planMigration(id, userID, orgID int64, overrides *models.PlanMigrationRequest) (*models.MigrationPlan, *actionError) {
+	var migration struct {
+		ConnectionID   sql.NullInt64  `db:"connection_id"`
+		Config         sql.NullString `db:"config"`
+		OrganizationID int64          `db:"organization_id"`
+	}
+	err := h.db.Get(&migration, `
+		SELECT connection_id, config, COALESCE(organization_id, 0) as organization_id
+		FROM migrations
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newActionError(http.StatusNotFound, "Migration not found")
+		}
+		return nil, newActionError(http.StatusInternalServerError, "Failed to fetch migration")
+	}
+
+	config, failure := loadMigrationConfig(id, migration.Config)
+	if failure != nil {
+		return nil, failure
+	}
+	if overrides != nil {
+		if failure := applyPlanOverrides(&config, overrides); failure != nil {
+			return nil, failure
+		}
+	}
+	connection, failure := h.loadSourceConnection(migration.ConnectionID, userID, migration.OrganizationID)
+	if failure != nil {
+		return nil, failure
+	}
+
+	metadata := dbtest.ExtractMetadataContext(context.Background(), connection.params(), dbtest.ExtractOptions{
+		Timeout:                 planExtractTimeout,
+		IncludeColumns:          true,
+		IncludeForeignKeys:      true,
+		IncludeViewDependencies: true,
+	})
+	if !metadata.Success {
+		return nil, newActionError(http.StatusBadRequest, "Failed to read the source database: "+metadata.Error)
+	}
+
+	seeds, err := listSeeds(h.db, id, SeedExported)
+	if err != nil {
+		log.Printf("Failed to list seeds for migration %d: %v", id, err)
+	}
+	naming := models.DefaultNamingConventions
+	if migration.OrganizationID != 0 {
+		defaults, err := loadOrganizationDefaults(h.db, migration.OrganizationID)
+		if err != nil {
+			log.Printf("Failed to load organization defaults for migration %d: %v", id, err)
+		}
+		naming = defaults.NamingConventions
+	}
+
+	plan := buildMigrationPlan(metadata, config, seedNames(seeds), naming)
+	plan.MigrationID = id
+
+	// Quotas are only checked, so a demo can show what starting would run into
+	if migration.OrganizationID != 0 {
+		for _, check := range []struct {
+			metric string
+			amount int64
+		}{
+			{quota.MetricMigrationsRun, 1},
+			{quota.MetricTablesMigrated, int64(plan.Counts.Tables)},
+		} {
+			if exceeded, ok := quota.Check(migration.OrganizationID, check.metric, check.amount).(*quota.ExceededError); ok {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+					"Starting would exceed the monthly %s quota of the %s plan (%d of %d used)",
+					strings.ReplaceAll(exceeded.Metric, "_", " "), exceeded.Plan, exceeded.Used, exceeded.Limit))
+			}
+		}
+	}
+	return &plan, nil
+}
+
+// applyPlanOverrides replaces a migration's settings with a plan request's, then checks
+// the settings that depend on the table selection against the planned one
+func applyPlanOverrides(config *models.MigrationConfig, req *models.PlanMigrationRequest) *actionError {
+	if req.Tables != nil {
+		config.Tables = make([]string, len(req.Tables))
+		for i, table := range req.Tables {
+			config.Tables[i] = strings.TrimSpace(table)
+		}
+	}
+	if req.IncludeViews != nil {
+		config.IncludeViews = *req.IncludeViews
+	}
+	if req.Scaffolding != nil {
+		dbtgen.NormalizeScaffolding(req.Scaffolding)
+		config.Scaffolding = req.Scaffolding
+	}
+	if req.TestCoverage != nil {
+		dbtgen.NormalizeTestCoverage(req.TestCoverage)
+		config.TestCoverage = req.TestCoverage
+	}
+
+	details := dbtgen.ValidateTestCoverage(config.TestCoverage, config.Tables).Errors
+	details = append(details, dbtgen.ValidateScaffolding(config.Scaffolding, config.Tables).Errors...)
+	if len(details) > 0 {
+		return &actionError{Status: http.StatusBadRequest, Body: gin.H{"error": "Validation failed", "details": details}}
+	}
+	return nil
+}
+
+// buildMigrationPlan plans the models for the configured tables (all tables when none
+// are picked) and, with include_views, the views, in dependency order. Staging models
+// are named and placed by the migration's scaffolding, and get the tests its test
+// coverage asks for.
+func buildMigrationPlan(metadata dbtest.MetadataResult, config models.MigrationConfig, seeds map[string]string, naming models.NamingConventions) models.MigrationPlan {
+	scaffolding := dbtgen.ResolveScaffolding(config.Scaffolding)
+	plan := models.MigrationPlan{
+		DryRun:      true,
+		Scaffolding: scaffolding,
+		Models:      []models.PlannedModel{},
+		Sources:     []models.PlannedSource{},
+		Tests:       []models.PlannedTest{},
+		Warnings:    []string{},
+	}
+	for _, w := range metadata.Warnings {
+		plan.Warnings = append(plan.Warnings, "Schema extraction was partial: "+w)
+	}
+
+	tables := make(map[string]dbtest.TableInfo, len(metadata.Tables))
+	for _, t := range metadata.Tables {
+		tables[strings.ToLower(t.Schema+"."+t.Name)] = t
+	}
+	selected := map[string]bool{}
+	if len(config.Tables) == 0 {
+		for id := range tables {
+			selected[id] = true
+		}
+	}
+	for _, name := range config.Tables {
+		if _, ok := tables[strings.ToLower(name)]; !ok {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("Table %s was not found in the source database", name))
+			continue
+		}
+		selected[strings.ToLower(name)] = true
+	}
+	if config.IncludeViews {
+		for _, v := range metadata.Views {
+			selected[strings.ToLower(v.Schema+"."+v.Name)] = true
+		}
+	}
+
+	graph := dbtest.BuildDependencyGraph(metadata)
+	names := map[string]string{}   // Node ID -> model name
+	paths := map[string]string{}   // Node ID -> staging model file
+	sources := map[string]string{} // Node ID -> table or view name
+	for _, node := range graph.Nodes {
+		key := strings.ToLower(node.ID)
+		if !selected[key] {
+			continue
+		}
+		if seed, ok := seeds[node.ID]; ok {
+			names[node.ID] = seed
+		} else {
+			staged := dbtgen.ScaffoldStagingModel(scaffolding, naming.StagingPrefix, node.ID)
+			names[node.ID], paths[node.ID] = staged.Name, staged.Path
+		}
+		sources[node.ID] = node.Name
+
+		if node.Type == "view" {
+			plan.Counts.Views++
+			continue
+		}
+		table := tables[key]
+		plan.Counts.Tables++
+		plan.Counts.Columns += len(table.Columns)
+		plan.Counts.Rows += table.RowCount
+
+		var unportable []string
+		for _, col := range table.Columns {
+			if dataType := strings.ToLower(col.DataType); unportableColumnTypes[dataType] && !slices.Contains(unportable, dataType) {
+				unportable = append(unportable, dataType)
+			}
+		}
+		if len(unportable) > 0 {
+			sort.Strings(unportable)
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("Table %s has columns of types the target may not support: %s", node.ID, strings.Join(unportable, ", ")))
+		}
+	}
+
+	dependsOn := map[string][]string{}
+	for _, edge := range graph.Edges {
+		from, to := names[edge.From], names[edge.To]
+		if from == "" || to == "" || edge.From == edge.To {
+			continue
+		}
+		if edge.Kind == dbtest.DependencyForeignKey {
+			plan.Counts.ForeignKeys++
+		}
+		if !slices.Contains(dependsOn[edge.From], to) {
+			dependsOn[edge.From] = append(dependsOn[edge.From], to)
+		}
+	}
+
+	for _, id := range graph.Order {
+		name, ok := names[id]
+		if !ok {
+			continue
+		}
+		model := models.PlannedModel{Name: name, Source: id, Type: "staging", Path: paths[id], DependsOn: dependsOn[id]}
+		if _, ok := seeds[id]; ok {
+			model.Type = "seed"
+			model.DependsOn = nil
+			plan.Counts.Seeds++
+			plan.Models = append(plan.Models, model)
+			continue
+		}
+		plan.Models = append(plan.Models, model)
+		plan.Sources = append(plan.Sources, models.PlannedSource{Source: dbtgen.SourceName, Name: sources[id], Table: id})
+	}
+	plan.Counts.Models = len(plan.Models)
+	plan.Counts.Sources = len(plan.Sources)
+
+	var cyclic []string
+	for _, id := range graph.Cycles {
+		if _, ok := names[id]; ok {
+			cyclic = append(cyclic, id)
+		}
+	}
+	if len(cyclic) > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+			"Tables in or downstream of a circular dependency can't all ref() each other and are built in alphabetical order: %s", strings.Join(cyclic, ", ")))
+	}
+
+	// Excluded columns must exist, or the filter was likely meant for another column
+	for _, f := range dbtgen.NormalizeTableFilters(config.TableFilters) {
+		table, ok := tables[strings.ToLower(f.Table)]
+		if !ok || len(table.Columns) == 0 {
+			continue
+		}
+		for _, column := range f.ExcludeColumns {
+			if !slices.ContainsFunc(table.Columns, func(c dbtest.ColumnInfo) bool { return strings.EqualFold(c.Name, column) }) {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("Table %s has no column %s to exclude", f.Table, column))
+			}
+		}
+	}
+
+	plan.Tests = planTests(metadata, config, tables, names, seeds, graph.Order)
+	plan.Counts.Tests = len(plan.Tests)
+	coverage := dbtgen.ResolveTestCoverage(config.TestCoverage)
+	if coverage.AcceptedValues.Enabled || slices.ContainsFunc(coverage.Tables, func(t models.TableTestCoverage) bool { return t.AcceptedValues.Enabled }) {
+		plan.Warnings = append(plan.Warnings, "accepted_values tests depend on the values profiling finds, so they aren't in the plan")
+	}
+
+	for _, s := range dbtgen.NormalizeSnapshots(config.Snapshots) {
+		plan.Models = append(plan.Models, models.PlannedModel{Name: dbtgen.SnapshotName(s.Table), Source: s.Table, Type: "snapshot"})
+		plan.Counts.Snapshots++
+	}
+	return plan
+}
+
+// planTests lists the tests the test coverage generates on the planned staging models
+// of tables, in build order: not_null on NOT NULL columns, unique on single-column
+// primary keys and relationships on foreign keys to other planned models. Excluded
+// columns get none.
+func planTests(metadata dbtest.MetadataResult, config models.MigrationConfig, tables map[string]dbtest.TableInfo, names, seeds map[string]string, order []string) []models.PlannedTest {
+	coverage := dbtgen.ResolveTestCoverage(config.TestCoverage)
+	excluded := map[string][]string{}
+	for _, f := range dbtgen.NormalizeTableFilters(config.TableFilters) {
+		excluded[strings.ToLower(f.Table)] = f.ExcludeColumns
+	}
+
+	type reference struct{ to, field string }
+	references := map[string]map[string][]reference{} // Table -> column -> referenced models
+	for _, fk := range metadata.ForeignKeys {
+		table := strings.ToLower(fk.Schema + "." + fk.Table)
+		to := names[fk.ReferencedSchema+"."+fk.ReferencedTable]
+		if to == "" || strings.EqualFold(fk.Schema+"."+fk.Table, fk.ReferencedSchema+"."+fk.ReferencedTable) {
+			continue
+		}
+		if references[table] == nil {
+			references[table] = map[string][]reference{}
+		}
+		for i, column := range fk.Columns {
+			if i < len(fk.ReferencedColumns) {
+				key := strings.ToLower(column)
+				references[table][key] = append(references[table][key], reference{to, strings.ToLower(fk.ReferencedColumns[i])})
+			}
+		}
+	}
+
+	tests := []models.PlannedTest{}
+	for _, id := range order {
+		model, ok := names[id]
+		table, isTable := tables[strings.ToLower(id)]
+		if _, isSeed := seeds[id]; !ok || !isTable || isSeed {
+			continue
+		}
+		tc := dbtgen.TableCoverage(coverage, id)
+		keys := 0
+		for _, col := range table.Columns {
+			if col.IsPrimaryKey {
+				keys++
+			}
+		}
+		for _, col := range table.Columns {
+			if slices.ContainsFunc(excluded[strings.ToLower(id)], func(c string) bool { return strings.EqualFold(c, col.Name) }) {
+				continue
+			}
+			column := strings.ToLower(col.Name)
+			if *tc.NotNull && !col.IsNullable {
+				tests = append(tests, models.PlannedTest{Model: model, Column: column, Test: "not_null"})
+			}
+			if *tc.Unique && col.IsPrimaryKey && keys == 1 {
+				tests = append(tests, models.PlannedTest{Model: model, Column: column, Test: "unique"})
+			}
+			if *tc.Relationships {
+				for _, ref := range references[strings.ToLower(id)][column] {
+					tests = append(tests, models.PlannedTest{Model: model, Column: column, Test: "relationships", To: ref.to, Field: ref.field})
+				}
+			}
+		}
+	}
+	return tests
+}
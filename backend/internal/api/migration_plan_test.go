@@ -0,0 +1,175 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func planMetadata() dbtest.MetadataResult {
+	return dbtest.MetadataResult{
+		Success: true,
+		Tables: []dbtest.TableInfo{
+			{Schema: "Sales", Name: "Customer", RowCount: 1200, Columns: []dbtest.ColumnInfo{
+				{Name: "CustomerID", DataType: "int"}, {Name: "Demographics", DataType: "xml"},
+			}},
+			{Schema: "Sales", Name: "Order", RowCount: 50000, Columns: []dbtest.ColumnInfo{
+				{Name: "OrderID", DataType: "int"}, {Name: "CustomerID", DataType: "int"}, {Name: "Notes", DataType: "ntext"},
+			}},
+			{Schema: "Sales", Name: "Region", RowCount: 12, Columns: []dbtest.ColumnInfo{{Name: "RegionID", DataType: "int"}}},
+			{Schema: "dbo", Name: "AuditLog", RowCount: 9, Columns: []dbtest.ColumnInfo{{Name: "ID", DataType: "int"}}},
+		},
+		Views: []dbtest.ViewInfo{{Schema: "Sales", Name: "vOrders"}},
+		ForeignKeys: []dbtest.ForeignKeyInfo{
+			{Name: "FK_Order_Customer", Schema: "Sales", Table: "Order", ReferencedSchema: "Sales", ReferencedTable: "Customer"},
+			{Name: "FK_Customer_Region", Schema: "Sales", Table: "Customer", ReferencedSchema: "Sales", ReferencedTable: "Region"},
+		},
+	}
+}
+
+func TestBuildMigrationPlan(t *testing.T) {
+	config := models.MigrationConfig{
+		Tables:    []string{"Sales.Customer", "Sales.Order", "Sales.Region", "Sales.Missing"},
+		Snapshots: []models.SnapshotConfig{{Table: "Sales.Customer", UniqueKey: []string{"CustomerID"}, Strategy: "check", CheckCols: []string{"all"}}},
+	}
+	naming := models.DefaultNamingConventions
+	naming.StagingPrefix = "src_"
+
+	plan := buildMigrationPlan(planMetadata(), config, map[string]string{"Sales.Region": "region"}, naming)
+
+	var names []string
+	for _, m := range plan.Models {
+		names = append(names, m.Type+":"+m.Name)
+	}
+	// Region before Customer before Order; the seed replaces Region's model
+	if got := strings.Join(names, " "); got != "seed:region staging:src_customer staging:src_order snapshot:customer_snapshot" {
+		t.Errorf("models = %s", got)
+	}
+	if deps := plan.Models[2].DependsOn; len(deps) != 1 || deps[0] != "src_customer" {
+		t.Errorf("src_order depends on %v", deps)
+	}
+
+	want := models.MigrationPlanCounts{Tables: 3, Columns: 6, ForeignKeys: 2, Rows: 51212, Models: 3, Seeds: 1, Snapshots: 1, Sources: 2, Tests: 5}
+	if plan.Counts != want {
+		t.Errorf("counts = %+v, want %+v", plan.Counts, want)
+	}
+
+	warnings := strings.Join(plan.Warnings, "\n")
+	for _, expected := range []string{
+		"Table Sales.Missing was not found",
+		"Table Sales.Customer has columns of types the target may not support: xml",
+		"Table Sales.Order has columns of types the target may not support: ntext",
+	} {
+		if !strings.Contains(warnings, expected) {
+			t.Errorf("warnings = %q, missing %q", warnings, expected)
+		}
+	}
+}
+
+func TestBuildMigrationPlanAllTablesAndViews(t *testing.T) {
+	plan := buildMigrationPlan(planMetadata(), models.MigrationConfig{IncludeViews: true}, nil, models.DefaultNamingConventions)
+	if plan.Counts.Tables != 4 || plan.Counts.Views != 1 || plan.Counts.Models != 5 || !plan.DryRun {
+		t.Errorf("plan = %+v", plan)
+	}
+}
+
+func TestBuildMigrationPlanWarnsAboutMissingExcludedColumns(t *testing.T) {
+	config := models.MigrationConfig{
+		Tables:       []string{"Sales.Customer"},
+		TableFilters: []models.TableFilter{{Table: "Sales.Customer", ExcludeColumns: []string{"demographics", "SSN"}}},
+	}
+	plan := buildMigrationPlan(planMetadata(), config, nil, models.DefaultNamingConventions)
+
+	warnings := strings.Join(plan.Warnings, "\n")
+	if !strings.Contains(warnings, "Table Sales.Customer has no column SSN to exclude") || strings.Contains(warnings, "demographics") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestBuildMigrationPlanScaffoldingAndTests(t *testing.T) {
+	metadata := dbtest.MetadataResult{
+		Success: true,
+		Tables: []dbtest.TableInfo{
+			{Schema: "Sales", Name: "Customers", Columns: []dbtest.ColumnInfo{
+				{Name: "CustomerID", DataType: "int", IsPrimaryKey: true},
+				{Name: "Email", DataType: "nvarchar", IsNullable: true},
+			}},
+			{Schema: "Sales", Name: "OrderLines", Columns: []dbtest.ColumnInfo{
+				{Name: "OrderID", DataType: "int", IsPrimaryKey: true},
+				{Name: "LineNo", DataType: "int", IsPrimaryKey: true},
+				{Name: "CustomerID", DataType: "int"},
+				{Name: "SSN", DataType: "char"},
+			}},
+		},
+		ForeignKeys: []dbtest.ForeignKeyInfo{{
+			Name: "FK_OrderLines_Customers", Schema: "Sales", Table: "OrderLines", Columns: []string{"CustomerID"},
+			ReferencedSchema: "Sales", ReferencedTable: "Customers", ReferencedColumns: []string{"CustomerID"},
+		}},
+	}
+	config := models.MigrationConfig{
+		Tables:       []string{"Sales.Customers", "Sales.OrderLines"},
+		Scaffolding:  &models.ProjectScaffolding{Folders: "schema", Naming: "singular"},
+		TableFilters: []models.TableFilter{{Table: "Sales.OrderLines", ExcludeColumns: []string{"ssn"}}},
+		TestCoverage: &models.TestCoverageConfig{Tables: []models.TableTestCoverage{
+			{Table: "Sales.Customers", TestCoverage: models.TestCoverage{AcceptedValues: &models.AcceptedValuesCoverage{Enabled: true}}},
+		}},
+	}
+
+	plan := buildMigrationPlan(metadata, config, nil, models.DefaultNamingConventions)
+
+	if plan.Scaffolding.Layers != "layered" || len(plan.Models) != 2 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if m := plan.Models[1]; m.Name != "stg_orderline" || m.Path != "models/staging/sales/stg_orderline.sql" || len(m.DependsOn) != 1 || m.DependsOn[0] != "stg_customer" {
+		t.Errorf("models[1] = %+v", m)
+	}
+	if len(plan.Sources) != 2 || plan.Sources[0] != (models.PlannedSource{Source: "mssql_source", Name: "Customers", Table: "Sales.Customers"}) {
+		t.Errorf("sources = %+v", plan.Sources)
+	}
+
+	var tests []string
+	for _, test := range plan.Tests {
+		tests = append(tests, test.Model+"."+test.Column+":"+test.Test+test.To)
+	}
+	// No unique on the composite key, nothing on the excluded column
+	want := "stg_customer.customerid:not_null stg_customer.customerid:unique stg_orderline.orderid:not_null " +
+		"stg_orderline.lineno:not_null stg_orderline.customerid:not_null stg_orderline.customerid:relationshipsstg_customer"
+	if got := strings.Join(tests, " "); got != want {
+		t.Errorf("tests = %s, want %s", got, want)
+	}
+	if plan.Counts.Tests != 6 || !strings.Contains(strings.Join(plan.Warnings, "\n"), "accepted_values") {
+		t.Errorf("counts = %+v, warnings = %v", plan.Counts, plan.Warnings)
+	}
+}
+
+func TestMigrationsPlanValidatesOverrides(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT connection_id, config, COALESCE\(organization_id, 0\)`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"connection_id", "config", "organization_id"}).AddRow(1, `{"tables":["Sales.Order"]}`, 0))
+
+	body := map[string]interface{}{
+		"tables":      []string{"Sales.Customer"},
+		"scaffolding": map[string]interface{}{"folders": "domain", "domains": []map[string]interface{}{{"name": "sales", "tables": []string{"Sales.Order"}}}},
+	}
+	status, resp := serve(t, "POST", "/migrations/:id/plan", "/migrations/5/plan", body, NewMigrationsHandler(store).Plan)
+	expectStatus(t, status, http.StatusBadRequest, resp)
+	if !strings.Contains(string(resp), "scaffolding.domains[0].tables") {
+		t.Errorf("body = %s", resp)
+	}
+}
+
+func TestMigrationsStartDryRunNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT connection_id, config, COALESCE\(organization_id, 0\)`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"connection_id", "config", "organization_id"}))
+
+	status, body := serve(t, "POST", "/migrations/:id/start", "/migrations/5/start",
+		map[string]interface{}{"dry_run": true}, NewMigrationsHandler(store).Start)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
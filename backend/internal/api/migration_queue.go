@@ -0,0 +1,233 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// migrationQueueSweepInterval is how often queued migrations are looked at again, so a
+// queue whose slot was freed while the server was down still moves
+const migrationQueueSweepInterval = time.Minute
+
+// Why a migration waits in its connection's queue
+const (
+	queueReasonConnectionBusy = "connection_busy"    // Its connection has no free extraction slot
+	queueReasonRunWindow      = "outside_run_window" // It may only run in its run window
+	queueReasonAIServiceBusy  = "ai_service_busy"    // Its AI service instance has no free slot
+)
+
+// queueReasonText describes a queue reason for messages and notifications
+func queueReasonText(reason string) string {
+	switch reason {
+	case queueReasonRunWindow:
+		return "it starts when its run window opens"
+	case queueReasonAIServiceBusy:
+		return "it starts once the AI service has capacity"
+	}
+	return "it starts once its connection has a free slot"
+}
+
+// errNotStartable means the migration left pending (or queued) status while it was
+// being started
+var errNotStartable = errors.New("migration is not pending")
+
+// claimExtractionSlot moves a pending or queued migration to running when its connection
+// has a free extraction slot, and otherwise queues it; it returns why the migration was
+// queued, or "" once it runs. Migrations queued earlier for a slot get a freed slot
+// first. The connection row is locked so two starts can't take the same slot. A migration
+// outside its run window is queued without taking part in the slot count. When the
+// region's AI service instance has a slot limit (the ai_migration_slots setting or the
+// slots it reports), a migration with a connection slot also needs one of the
+// instance's slots, which go out by plan priority. A saturated instance queues it too.
+func claimExtractionSlot(store db.Querier, migrationID, connectionID int64, resumeRunID sql.NullInt64, region string, outsideRunWindow bool) (string, error) {
+	tx, err := store.Beginx()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	reason := queueReasonRunWindow
+	if !outsideRunWindow {
+		var limit int
+		err = tx.Get(&limit, "SELECT max_concurrent_migrations FROM database_connections WHERE id = $1 FOR UPDATE", connectionID)
+		if err != nil {
+			return "", err
+		}
+		var taken int
+		err = tx.Get(&taken, `
+			SELECT COUNT(*) FROM migrations
+			WHERE connection_id = $1 AND id <> $2
+			AND (status = 'running' OR (status = 'queued' AND queue_reason = 'connection_busy'
+			     AND queued_at < COALESCE((SELECT queued_at FROM migrations WHERE id = $2), NOW())))
+		`, connectionID, migrationID)
+		if err != nil {
+			return "", err
+		}
+		reason = ""
+		if taken >= limit {
+			reason = queueReasonConnectionBusy
+		}
+	}
+	if reason == "" {
+		// A saturated instance takes no new migrations, whatever the slot count says
+		if slots, saturated := aiServiceSlots(region); saturated {
+			reason = queueReasonAIServiceBusy
+		} else if slots > 0 {
+			granted, err := claimAISlot(tx, migrationID, region, slots)
+			if err != nil {
+				return "", err
+			}
+			if !granted {
+				reason = queueReasonAIServiceBusy
+			}
+		}
+	}
+
+	var result sql.Result
+	if reason == "" {
+		result, err = tx.Exec(`
+			UPDATE migrations SET status = 'running', progress = 0, queued_at = NULL, queue_reason = NULL, resume_run_id = NULL,
+			       data_region = NULLIF($2, ''), updated_at = NOW()
+			WHERE id = $1 AND status IN ('pending', 'queued')
+		`, migrationID, region)
+	} else {
+		result, err = tx.Exec(`
+			UPDATE migrations SET status = 'queued', queued_at = COALESCE(queued_at, NOW()), queue_reason = $2, resume_run_id = $3,
+			       data_region = NULLIF($4, ''), updated_at = NOW()
+			WHERE id = $1 AND status IN ('pending', 'queued')
+		`, migrationID, reason, resumeRunID, region)
+	}
+	if err != nil {
+		return "", err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return "", errNotStartable
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	publishMigrationStatus(migrationID)
+	return reason, nil
+}
+
+// startedResponse is the response to starting a migration, which may have been queued
+// at a position in its queue
+func startedResponse(store db.Querier, id int64, queueReason string) gin.H {
+	if queueReason != "" {
+		response := gin.H{
+			"message":      "Migration queued; " + queueReasonText(queueReason),
+			"migration_id": id,
+			"status":       "queued",
+			"queue_reason": queueReason,
+		}
+		position, err := queuePosition(store, &models.Migration{ID: id, Status: "queued", QueueReason: &queueReason})
+		if err != nil {
+			log.Printf("Failed to find the queue position of migration %d: %v", id, err)
+		} else if position != nil {
+			response["message"] = fmt.Sprintf("Migration queued, position %d; %s", *position, queueReasonText(queueReason))
+			response["queue_position"] = *position
+		}
+		return response
+	}
+	return gin.H{"message": "Migration started", "migration_id": id, "status": "running"}
+}
+
+// dispatchAfter starts the migrations queued on the connection of a migration that just
+// stopped extracting, then offers its AI service slot to the priority lanes
+func (h *MigrationsHandler) dispatchAfter(migrationID int64) {
+	var connectionID sql.NullInt64
+	if err := h.db.Get(&connectionID, "SELECT connection_id FROM migrations WHERE id = $1", migrationID); err != nil {
+		log.Printf("Failed to look up the connection of migration %d: %v", migrationID, err)
+		return
+	}
+	if connectionID.Valid {
+		h.dispatchQueued(connectionID.Int64)
+	}
+	h.dispatchAILanes(connectionID.Int64)
+}
+
+// dispatchQueued starts queued migrations of a connection, oldest first, until its slots
+// are taken. Migrations outside their run window stay queued without holding up the
+// ones behind them. A queued migration that can no longer start (its quota ran out, its
+// connection was moved) fails so the ones behind it aren't held up either.
+func (h *MigrationsHandler) dispatchQueued(connectionID int64) {
+	var queue []struct {
+		ID             int64         `db:"id"`
+		UserID         int64         `db:"user_id"`
+		OrganizationID int64         `db:"organization_id"`
+		ResumeRunID    sql.NullInt64 `db:"resume_run_id"`
+	}
+	err := h.db.Select(&queue, `
+		SELECT id, user_id, COALESCE(organization_id, 0) AS organization_id, resume_run_id FROM migrations
+		WHERE connection_id = $1 AND status = 'queued'
+		ORDER BY queued_at, id
+	`, connectionID)
+	if err != nil {
+		log.Printf("Failed to fetch queued migrations of connection %d: %v", connectionID, err)
+		return
+	}
+
+	for _, next := range queue {
+		var resume *runResume
+		if next.ResumeRunID.Valid {
+			resume, err = loadRunResume(h.db, next.ResumeRunID.Int64)
+			if err != nil {
+				log.Printf("Failed to load the resumed run of migration %d: %v", next.ID, err)
+			}
+		}
+
+		reason, failure := h.startMigration(next.ID, next.UserID, next.OrganizationID, resume)
+		if failure == nil {
+			if reason == queueReasonConnectionBusy {
+				return
+			}
+			continue
+		}
+
+		message, _ := failure.Body["error"].(string)
+		errMsg := "Failed to start from the queue: " + message
+		result, err := h.db.Exec(`
+			UPDATE migrations SET status = 'failed', error = $1, queued_at = NULL, queue_reason = NULL, resume_run_id = NULL, updated_at = NOW()
+			WHERE id = $2 AND status = 'queued'
+		`, errMsg, next.ID)
+		if err != nil {
+			log.Printf("Failed to fail queued migration %d: %v", next.ID, err)
+			return
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+			log.Printf("Queued migration %d could not start: %s", next.ID, message)
+			publishMigrationStatus(next.ID)
+		}
+	}
+}
+
+// StartMigrationQueue periodically starts queued migrations whose connection has a free
+// slot or whose run window has opened
+func StartMigrationQueue() {
+	h := NewMigrationsHandler(db.DB)
+	go func() {
+		ticker := time.NewTicker(migrationQueueSweepInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			var connectionIDs []int64
+			err := h.db.Select(&connectionIDs, `
+				SELECT DISTINCT connection_id FROM migrations
+				WHERE status = 'queued' AND connection_id IS NOT NULL
+			`)
+			if err != nil {
+				log.Printf("Failed to fetch connections with queued migrations: %v", err)
+				continue
+			}
+			for _, id := range connectionIDs {
+				h.dispatchQueued(id)
+			}
+		}
+	}()
+}
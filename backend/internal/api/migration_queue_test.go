@@ -0,0 +1,91 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectSlotCount(mock sqlmock.Sqlmock, limit, taken int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT max_concurrent_migrations FROM database_connections WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"max_concurrent_migrations"}).AddRow(limit))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM migrations`).
+		WithArgs(int64(4), int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(taken))
+}
+
+func TestClaimExtractionSlotFree(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectSlotCount(mock, 2, 1)
+	mock.ExpectExec(`UPDATE migrations SET status = 'running'`).
+		WithArgs(int64(8), "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	reason, err := claimExtractionSlot(store, 8, 4, sql.NullInt64{}, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason != "" {
+		t.Errorf("migration was queued with a free slot: %s", reason)
+	}
+}
+
+func TestClaimExtractionSlotQueues(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectSlotCount(mock, 1, 1)
+	mock.ExpectExec(`UPDATE migrations SET status = 'queued', queued_at = COALESCE\(queued_at, NOW\(\)\)`).
+		WithArgs(int64(8), queueReasonConnectionBusy, sql.NullInt64{Int64: 21, Valid: true}, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	reason, err := claimExtractionSlot(store, 8, 4, sql.NullInt64{Int64: 21, Valid: true}, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason != queueReasonConnectionBusy {
+		t.Errorf("reason = %q, want %q", reason, queueReasonConnectionBusy)
+	}
+}
+
+func TestClaimExtractionSlotOutsideRunWindow(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE migrations SET status = 'queued'`).
+		WithArgs(int64(8), queueReasonRunWindow, sql.NullInt64{}, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	reason, err := claimExtractionSlot(store, 8, 4, sql.NullInt64{}, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason != queueReasonRunWindow {
+		t.Errorf("reason = %q, want %q", reason, queueReasonRunWindow)
+	}
+}
+
+func TestClaimExtractionSlotNotPending(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectSlotCount(mock, 1, 0)
+	mock.ExpectExec(`UPDATE migrations SET status = 'running'`).
+		WithArgs(int64(8), "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if _, err := claimExtractionSlot(store, 8, 4, sql.NullInt64{}, "", false); err != errNotStartable {
+		t.Errorf("err = %v, want errNotStartable", err)
+	}
+}
+
+func TestDispatchQueuedEmptyQueue(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT id, user_id, COALESCE\(organization_id, 0\) AS organization_id, resume_run_id FROM migrations\s+WHERE connection_id = \$1 AND status = 'queued'`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "resume_run_id"}))
+
+	NewMigrationsHandler(store).dispatchQueued(4)
+}
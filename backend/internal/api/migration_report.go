@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/export"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// reportTimeFormat is how times are shown in a migration report
+const reportTimeFormat = "2006-01-02 15:04 UTC"
+
+// reportMigration is what the summary report shows of a migration, its current run and
+// its organization
+type reportMigration struct {
+	docsMigration
+	TargetProject    string     `db:"target_project"`
+	Status           string     `db:"status"`
+	ForeignKeysCount int        `db:"foreign_keys_count"`
+	ModelsGenerated  int        `db:"models_generated"`
+	Error            *string    `db:"error"`
+	Config           *string    `db:"config"`
+	RunNumber        int        `db:"run_number"`
+	RunStartedAt     *time.Time `db:"run_started_at"`
+	RunCompletedAt   *time.Time `db:"run_completed_at"`
+}
+
+// reportDeployment is the test outcome of a migration's latest warehouse deployment
+type reportDeployment struct {
+	Status      string    `db:"status"`
+	TestsPassed int       `db:"tests_passed"`
+	TestsFailed int       `db:"tests_failed"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// migrationReport is everything the summary report is rendered from
+type migrationReport struct {
+	Migration  reportMigration
+	Tables     []models.MigrationTable
+	Phases     []models.PhaseMetrics
+	Deployment *reportDeployment
+	Generated  time.Time
+}
+
+// GetReportPDF renders a migration's executive summary as a PDF
+// @Summary Download migration summary report
+// @Description A PDF executive summary of the migration's current run for change-control tickets: scope, duration, models generated, test results and outstanding issues. Colored in the organization's branding.
+// @Tags migrations
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/report.pdf [get]
+func (h *MigrationsHandler) GetReportPDF(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	report := migrationReport{Generated: time.Now()}
+	err = h.db.Get(&report.Migration, `
+		SELECT m.name, COALESCE(m.source_database, '') AS source_database,
+		       COALESCE(m.tables_count, 0) AS tables_count, COALESCE(m.views_count, 0) AS views_count,
+		       COALESCE(m.foreign_keys_count, 0) AS foreign_keys_count, COALESCE(m.models_generated, 0) AS models_generated,
+		       COALESCE(m.target_project, '') AS target_project, m.status, m.error, m.config,
+		       COALESCE(r.run_number, 0) AS run_number, r.started_at AS run_started_at, r.completed_at AS run_completed_at,
+		       o.id AS organization_id, o.name AS organization_name, o.settings
+		FROM migrations m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN migration_runs r ON r.id = m.current_run_id
+		LEFT JOIN organizations o ON o.id = COALESCE(m.organization_id, u.organization_id)
+		WHERE m.id = $1 AND m.user_id = $2 AND COALESCE(m.organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	err = h.db.Select(&report.Tables, `
+		SELECT t.table_name, t.status, t.error, t.updated_at
+		FROM migration_tables t
+		JOIN migrations m ON m.current_run_id = t.run_id
+		WHERE m.id = $1
+		ORDER BY t.table_name
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch table progress"})
+		return
+	}
+	err = h.db.Select(&report.Phases, `
+		SELECT phase, duration_ms, prompt_tokens, completion_tokens, tables_processed, lines_generated, updated_at
+		FROM migration_metrics
+		WHERE migration_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch metrics"})
+		return
+	}
+	var deployment reportDeployment
+	err = h.db.Get(&deployment, `
+		SELECT status, tests_passed, tests_failed, created_at
+		FROM warehouse_deployments
+		WHERE migration_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, id)
+	switch {
+	case err == nil:
+		report.Deployment = &deployment
+	case err != sql.ErrNoRows:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployments"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err := report.render().WriteTo(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render report"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=migration-%d-report.pdf", id))
+	c.Data(http.StatusOK, export.PDFContentType, buf.Bytes())
+}
+
+// render lays out the report: scope, duration, models generated, tests and outstanding
+// issues
+func (r migrationReport) render() *export.Document {
+	m := r.Migration
+	doc := export.NewDocument(m.Name + " - migration summary")
+
+	subtitle := "Migration summary"
+	if b := emailBranding(m.OrganizationID, m.OrgName, m.Settings); b != nil {
+		doc.SetAccent(b.PrimaryColor)
+	}
+	if m.OrgName.Valid {
+		subtitle += " · " + m.OrgName.String
+	}
+	doc.Title(m.Name)
+	doc.Subtitle(subtitle + " · generated " + r.Generated.UTC().Format(reportTimeFormat))
+
+	counts := map[string]int{}
+	for _, t := range r.Tables {
+		counts[t.Status]++
+	}
+
+	doc.Heading("Scope")
+	doc.Field("Status", m.Status)
+	if m.RunNumber > 0 {
+		doc.Field("Run", "#"+strconv.Itoa(m.RunNumber))
+	}
+	doc.Field("Source database", orDash(m.SourceDatabase))
+	doc.Field("Target dbt project", orDash(m.TargetProject))
+	doc.Field("Source objects", fmt.Sprintf("%d tables, %d views, %d foreign keys", m.TablesCount, m.ViewsCount, m.ForeignKeysCount))
+	selected := "All tables"
+	if m.Config != nil {
+		var config models.MigrationConfig
+		if json.Unmarshal([]byte(*m.Config), &config) == nil && len(config.Tables) > 0 {
+			selected = fmt.Sprintf("%d: %s", len(config.Tables), strings.Join(config.Tables, ", "))
+		}
+	}
+	doc.Field("Selected tables", selected)
+
+	doc.Heading("Duration")
+	switch started, completed := m.RunStartedAt, m.RunCompletedAt; {
+	case started == nil:
+		doc.Field("Started", "Not started")
+	case completed == nil:
+		doc.Field("Started", started.UTC().Format(reportTimeFormat))
+		doc.Field("Completed", "Not completed")
+	default:
+		doc.Field("Started", started.UTC().Format(reportTimeFormat))
+		doc.Field("Completed", completed.UTC().Format(reportTimeFormat))
+		doc.Field("Elapsed", reportDuration(completed.Sub(*started)))
+	}
+	if len(r.Phases) > 0 {
+		rows := make([][]string, 0, len(r.Phases))
+		var total time.Duration
+		for _, p := range r.Phases {
+			d := time.Duration(p.DurationMs) * time.Millisecond
+			total += d
+			rows = append(rows, []string{p.Phase, reportDuration(d), strconv.Itoa(p.TablesProcessed), strconv.Itoa(p.LinesGenerated)})
+		}
+		doc.Field("Processing time", reportDuration(total))
+		doc.Table([]string{"Phase", "Duration", "Tables", "Lines generated"}, []float64{3, 2, 1, 2}, rows)
+	}
+
+	doc.Heading("Models generated")
+	doc.Field("dbt models", strconv.Itoa(m.ModelsGenerated))
+	if len(r.Tables) > 0 {
+		doc.Field("Tables", fmt.Sprintf("%d of %d generated, %d pending, %d failed", counts["generated"]+counts["tested"],
+			len(r.Tables), counts["pending"]+counts["extracting"], counts["failed"]))
+	}
+
+	doc.Heading("Tests")
+	if built := counts["generated"] + counts["tested"]; built > 0 {
+		doc.Field("Tables tested", fmt.Sprintf("%d of %d generated tables passed their tests", counts["tested"], built))
+	}
+	if d := r.Deployment; d != nil {
+		doc.Field("Last deployment", fmt.Sprintf("%s (%s)", d.CreatedAt.UTC().Format(reportTimeFormat), d.Status))
+		doc.Field("dbt tests", fmt.Sprintf("%d passed, %d failed", d.TestsPassed, d.TestsFailed))
+	} else {
+		doc.Paragraph("The project hasn't been deployed to a warehouse, so no dbt tests have run against it.")
+	}
+
+	doc.Heading("Outstanding issues")
+	issues := 0
+	if m.Error != nil && *m.Error != "" {
+		doc.Field("Migration error", *m.Error)
+		issues++
+	}
+	if d := r.Deployment; d != nil && d.TestsFailed > 0 {
+		doc.Field("Failing dbt tests", strconv.Itoa(d.TestsFailed))
+		issues++
+	}
+	var failed [][]string
+	for _, t := range r.Tables {
+		if t.Status == "failed" {
+			reason := ""
+			if t.Error != nil {
+				reason = strings.TrimSpace(*t.Error)
+			}
+			failed = append(failed, []string{t.Name, orDash(reason)})
+		}
+	}
+	if len(failed) > 0 {
+		doc.Paragraph(fmt.Sprintf("%d tables failed:", len(failed)))
+		doc.Table([]string{"Table", "Error"}, []float64{1, 2}, failed)
+		issues++
+	}
+	if issues == 0 {
+		doc.Paragraph("None.")
+	}
+	return doc
+}
+
+// reportDuration shows a duration to the second, e.g. 1h 4m 12s
+func reportDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh %dm %ds", h, m, s)
+	case m > 0:
+		return fmt.Sprintf("%dm %ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
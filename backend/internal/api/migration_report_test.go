@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMigrationsGetReportPDF(t *testing.T) {
+	store, mock := newMockDB(t)
+	started := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM migrations m\s+JOIN users u`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"name", "source_database", "tables_count", "views_count", "foreign_keys_count", "models_generated",
+			"target_project", "status", "error", "config", "run_number", "run_started_at", "run_completed_at",
+			"organization_id", "organization_name", "settings",
+		}).AddRow("Sales (EU)", "AdventureWorks", 3, 1, 2, 4, "sales_dbt", "completed", nil,
+			`{"tables":["Sales.Customer","Sales.Order","Sales.Product"]}`, 2, started, started.Add(75*time.Minute),
+			testOrgID, "Contoso", `{"branding":{"primary_color":"#0f766e"}}`))
+	mock.ExpectQuery(`FROM migration_tables t`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "status", "error", "updated_at"}).
+			AddRow("Sales.Customer", "tested", nil, started).
+			AddRow("Sales.Order", "failed", "column type xml is not supported", started).
+			AddRow("Sales.Product", "generated", nil, started))
+	mock.ExpectQuery(`FROM migration_metrics`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"phase", "duration_ms", "prompt_tokens", "completion_tokens", "tables_processed", "lines_generated", "updated_at",
+		}).AddRow("generation", 3723000, 1000, 500, 3, 420, started))
+	mock.ExpectQuery(`FROM warehouse_deployments`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "tests_passed", "tests_failed", "created_at"}).
+			AddRow("completed", 11, 1, started.Add(2*time.Hour)))
+
+	status, body := serve(t, "GET", "/migrations/:id/report.pdf", "/migrations/8/report.pdf", nil, NewMigrationsHandler(store).GetReportPDF)
+	expectStatus(t, status, http.StatusOK, body)
+	pdf := string(body)
+	if !strings.HasPrefix(pdf, "%PDF-") {
+		t.Fatalf("body is not a PDF: %.40q", pdf)
+	}
+	for _, want := range []string{
+		"(Sales \\(EU\\)) Tj",
+		"(3: Sales.Customer, Sales.Order, Sales.Product) Tj",
+		"(1h 15m 0s) Tj",
+		"(1h 2m 3s) Tj",
+		"(2 of 3 generated, 0 pending, 1 failed) Tj",
+		"(1 of 2 generated tables passed their tests) Tj",
+		"(11 passed, 1 failed) Tj",
+		"(column type xml is not supported) Tj",
+		"0.059 0.463 0.431 rg", // The organization's brand color
+	} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+}
+
+func TestMigrationsGetReportPDFNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migrations m`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+	status, body := serve(t, "GET", "/migrations/:id/report.pdf", "/migrations/8/report.pdf", nil, NewMigrationsHandler(store).GetReportPDF)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestReportDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		4 * time.Second:                       "4s",
+		90*time.Second + 400*time.Millisecond: "1m 30s",
+		26*time.Hour + 5*time.Second:          "26h 0m 5s",
+	} {
+		if got := reportDuration(d); got != want {
+			t.Errorf("reportDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
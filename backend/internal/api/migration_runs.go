@@ -0,0 +1,454 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/textdiff"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// maxRunFileContent is the largest generated file whose content is kept in a run
+// snapshot. Larger files are compared by checksum only.
+const maxRunFileContent = 1 << 20
+
+const (
+	defaultDiffContext = 3
+	maxDiffContext     = 100
+)
+
+const migrationRunQuery = `
+	SELECT id, migration_id, run_number, status, progress, error, started_by, artifacts_path,
+	       COALESCE(files_count, 0) AS files_count, started_at, completed_at
+	FROM migration_runs
+`
+
+// startRun records a new run of a migration that just moved to running and makes it the
+// migration's current run
+func startRun(store db.Querier, migrationID, userID int64) error {
+	_, err := store.Exec(`
+		WITH run AS (
+			INSERT INTO migration_runs (migration_id, run_number, started_by, config)
+			SELECT id, COALESCE((SELECT MAX(run_number) FROM migration_runs WHERE migration_id = $1), 0) + 1, $2, config
+			FROM migrations WHERE id = $1
+			RETURNING id
+		)
+		UPDATE migrations SET current_run_id = (SELECT id FROM run) WHERE id = $1
+	`, migrationID, userID)
+	return err
+}
+
+// updateCurrentRun mirrors a status change of a migration onto its current run
+func updateCurrentRun(store db.Querier, migrationID int64, status string, progress int, errMsg *string) {
+	_, err := store.Exec(`
+		UPDATE migration_runs
+		SET status = $2, progress = $3, error = COALESCE($4, error),
+		    completed_at = CASE WHEN $2 IN ('completed', 'failed', 'cancelled') THEN NOW() ELSE completed_at END
+		WHERE id = (SELECT current_run_id FROM migrations WHERE id = $1)
+	`, migrationID, status, progress, errMsg)
+	if err != nil {
+		log.Printf("Failed to update current run of migration %d: %v", migrationID, err)
+	}
+}
+
+// recordRunLogs stores progress messages reported by the AI service against the current run
+func recordRunLogs(store db.Querier, migrationID int64, logs []models.MigrationLog) error {
+	for _, l := range logs {
+		_, err := store.Exec(`
+			INSERT INTO migration_logs (migration_id, run_id, level, message)
+			SELECT id, current_run_id, $2, $3 FROM migrations WHERE id = $1
+		`, migrationID, l.Level, l.Message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotRunFiles copies the generated project of a completed or cancelled migration from the AI
+// service into its current run, so a later run can't overwrite it, and stores the run's
+// checksum manifest
+func snapshotRunFiles(store db.Querier, migrationID int64) {
+	aiClient, err := migrationAIClient(store, migrationID)
+	if err != nil {
+		log.Printf("Failed to snapshot run files of migration %d: %v", migrationID, err)
+		return
+	}
+	if aiClient == nil {
+		return
+	}
+	files, err := aiClient.GetMigrationFiles(migrationID)
+	if err != nil {
+		log.Printf("Failed to list files for run snapshot of migration %d: %v", migrationID, err)
+		return
+	}
+
+	var paths, types, checksums []string
+	var sizes []int64
+	var contents []sql.NullString
+	for _, f := range files.Files {
+		file, err := aiClient.GetMigrationFileContent(migrationID, f.Path)
+		if err != nil {
+			log.Printf("Failed to fetch %s for run snapshot of migration %d: %v", f.Path, migrationID, err)
+			return
+		}
+		sum := sha256.Sum256([]byte(file.Content))
+		paths = append(paths, f.Path)
+		types = append(types, f.Type)
+		sizes = append(sizes, int64(len(file.Content)))
+		checksums = append(checksums, hex.EncodeToString(sum[:]))
+		contents = append(contents, sql.NullString{String: file.Content, Valid: len(file.Content) <= maxRunFileContent})
+	}
+
+	tx, err := store.Beginx()
+	if err != nil {
+		log.Printf("Failed to snapshot run files of migration %d: %v", migrationID, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var runID int64
+	err = tx.Get(&runID, `
+		UPDATE migration_runs SET artifacts_path = $2, files_count = $3, manifest_sha256 = $4
+		WHERE id = (SELECT current_run_id FROM migrations WHERE id = $1)
+		RETURNING id
+	`, migrationID, files.ProjectPath, len(paths), manifestDigest(paths, checksums))
+	if err != nil {
+		log.Printf("Failed to snapshot run files of migration %d: %v", migrationID, err)
+		return
+	}
+	if _, err := tx.Exec("DELETE FROM migration_run_files WHERE run_id = $1", runID); err != nil {
+		log.Printf("Failed to snapshot run files of migration %d: %v", migrationID, err)
+		return
+	}
+	if len(paths) > 0 {
+		_, err = tx.Exec(`
+			INSERT INTO migration_run_files (run_id, path, file_type, size, checksum, content)
+			SELECT $1, f.path, f.file_type, f.size, f.checksum, f.content
+			FROM unnest($2::text[], $3::text[], $4::bigint[], $5::text[], $6::text[]) AS f(path, file_type, size, checksum, content)
+		`, runID, pq.StringArray(paths), pq.StringArray(types), pq.Array(sizes), pq.StringArray(checksums), pq.Array(contents))
+		if err != nil {
+			log.Printf("Failed to snapshot run files of migration %d: %v", migrationID, err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to snapshot run files of migration %d: %v", migrationID, err)
+	}
+}
+
+// diffRunFiles lists the files added, removed or modified between two run snapshots
+func diffRunFiles(from, to []models.MigrationRunFile) models.MigrationRunDiff {
+	diff := models.MigrationRunDiff{Files: []models.RunFileChange{}}
+	before := make(map[string]string, len(from))
+	for _, f := range from {
+		before[f.Path] = f.Checksum
+	}
+	for _, f := range to {
+		checksum, ok := before[f.Path]
+		delete(before, f.Path)
+		switch {
+		case !ok:
+			diff.Added++
+			diff.Files = append(diff.Files, models.RunFileChange{Path: f.Path, Change: "added"})
+		case checksum != f.Checksum:
+			diff.Modified++
+			diff.Files = append(diff.Files, models.RunFileChange{Path: f.Path, Change: "modified"})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for path := range before {
+		diff.Removed++
+		diff.Files = append(diff.Files, models.RunFileChange{Path: path, Change: "removed"})
+	}
+	sort.Slice(diff.Files, func(i, j int) bool { return diff.Files[i].Path < diff.Files[j].Path })
+	return diff
+}
+
+// runFileContents loads the kept content of some of a run's files. Files whose content
+// was too large to keep are missing from the result.
+func (h *MigrationsHandler) runFileContents(runID int64, paths []string) (map[string]string, error) {
+	var rows []struct {
+		Path    string `db:"path"`
+		Content string `db:"content"`
+	}
+	err := h.db.Select(&rows, `
+		SELECT path, content FROM migration_run_files
+		WHERE run_id = $1 AND path = ANY($2) AND content IS NOT NULL
+	`, runID, pq.StringArray(paths))
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[string]string, len(rows))
+	for _, row := range rows {
+		contents[row.Path] = row.Content
+	}
+	return contents, nil
+}
+
+// renderRunDiffs fills in the unified diff of each changed file
+func (h *MigrationsHandler) renderRunDiffs(diff *models.MigrationRunDiff, fromRunID, toRunID int64, context int) error {
+	var paths []string
+	for _, f := range diff.Files {
+		paths = append(paths, f.Path)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	before, err := h.runFileContents(fromRunID, paths)
+	if err != nil {
+		return err
+	}
+	after, err := h.runFileContents(toRunID, paths)
+	if err != nil {
+		return err
+	}
+
+	for i := range diff.Files {
+		f := &diff.Files[i]
+		fromName, toName := "a/"+f.Path, "b/"+f.Path
+		a, aOK := before[f.Path]
+		b, bOK := after[f.Path]
+		switch f.Change {
+		case "added":
+			fromName, aOK = "", true
+		case "removed":
+			toName, bOK = "", true
+		}
+		if !aOK || !bOK {
+			continue
+		}
+		var stats textdiff.Stats
+		f.Diff, stats = textdiff.Unified(fromName, toName, a, b, context)
+		f.Additions, f.Deletions = stats.Additions, stats.Deletions
+	}
+	return nil
+}
+
+// Rerun starts a finished migration again as a new run
+// @Summary Re-run a migration
+// @Description Start a completed, failed or cancelled migration again. The new run gets its own status, logs and generated files; earlier runs stay available for comparison.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/rerun [post]
+func (h *MigrationsHandler) Rerun(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE migrations SET status = 'pending', progress = 0, error = NULL, completed_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3 AND status IN ('completed', 'failed', 'cancelled')
+	`, id, userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-run migration"})
+		return
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Migration not found or not finished"})
+		return
+	}
+
+	queueReason, failure := h.startMigration(id, userID, orgID, nil)
+	if failure != nil {
+		c.JSON(failure.Status, failure.Body)
+		return
+	}
+
+	c.JSON(http.StatusOK, startedResponse(h.db, id, queueReason))
+}
+
+// findMigrationRun loads a run of one of the user's migrations, writing the error
+// response when it can't
+func (h *MigrationsHandler) findMigrationRun(c *gin.Context, migrationID int64, param string) (*models.MigrationRun, bool) {
+	runNumber, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run number"})
+		return nil, false
+	}
+
+	var run models.MigrationRun
+	err = h.db.Get(&run, migrationRunQuery+`
+		WHERE migration_id = $1 AND run_number = $2
+		  AND migration_id IN (SELECT id FROM migrations WHERE user_id = $3 AND COALESCE(organization_id, 0) = $4)
+	`, migrationID, runNumber, middleware.GetUserID(c), middleware.GetOrganizationID(c))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	return &run, true
+}
+
+func (h *MigrationsHandler) listRunFiles(runID int64) ([]models.MigrationRunFile, error) {
+	files := []models.MigrationRunFile{}
+	err := h.db.Select(&files, `
+		SELECT path, COALESCE(file_type, '') AS file_type, COALESCE(size, 0) AS size, checksum
+		FROM migration_run_files
+		WHERE run_id = $1
+		ORDER BY path
+	`, runID)
+	return files, err
+}
+
+// GetRuns lists the runs of a migration
+// @Summary List migration runs
+// @Description List every start of a migration, most recent first
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {array} models.MigrationRun
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /migrations/{id}/runs [get]
+func (h *MigrationsHandler) GetRuns(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var migrationID int64
+	err = h.db.Get(&migrationID, "SELECT id FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	runs := []models.MigrationRun{}
+	if err := h.db.Select(&runs, migrationRunQuery+" WHERE migration_id = $1 ORDER BY run_number DESC", id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch runs"})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetRun returns one run of a migration with its logs and generated files
+// @Summary Get a migration run
+// @Description Status, logs and the generated file snapshot of one run
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param run path int true "Run number"
+// @Success 200 {object} models.MigrationRunDetail
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /migrations/{id}/runs/{run} [get]
+func (h *MigrationsHandler) GetRun(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+	run, ok := h.findMigrationRun(c, id, "run")
+	if !ok {
+		return
+	}
+
+	detail := models.MigrationRunDetail{MigrationRun: *run, Logs: []models.MigrationLog{}}
+	err = h.db.Select(&detail.Logs, `
+		SELECT level, message, created_at FROM migration_logs WHERE run_id = $1 ORDER BY created_at, id
+	`, run.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch run logs"})
+		return
+	}
+	if detail.Files, err = h.listRunFiles(run.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch run files"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// DiffRuns compares the generated projects of two runs
+// @Summary Compare two migration runs
+// @Description List the generated files added, removed or modified between two runs of a migration, with a unified diff of each. Files too large to keep in a run snapshot are listed without a diff.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param run path int true "Run to compare from"
+// @Param other path int true "Run to compare to"
+// @Param context query int false "Unchanged lines around each change (default 3, max 100)"
+// @Success 200 {object} models.MigrationRunDiff
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /migrations/{id}/runs/{run}/diff/{other} [get]
+func (h *MigrationsHandler) DiffRuns(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+	context := defaultDiffContext
+	if value := c.Query("context"); value != "" {
+		context, err = strconv.Atoi(value)
+		if err != nil || context < 0 || context > maxDiffContext {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "context must be between 0 and 100"})
+			return
+		}
+	}
+
+	from, ok := h.findMigrationRun(c, id, "run")
+	if !ok {
+		return
+	}
+	to, ok := h.findMigrationRun(c, id, "other")
+	if !ok {
+		return
+	}
+
+	fromFiles, err := h.listRunFiles(from.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch run files"})
+		return
+	}
+	toFiles, err := h.listRunFiles(to.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch run files"})
+		return
+	}
+
+	diff := diffRunFiles(fromFiles, toFiles)
+	if err := h.renderRunDiffs(&diff, from.ID, to.ID, context); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch run files"})
+		return
+	}
+	diff.MigrationID = id
+	diff.FromRun = from.RunNumber
+	diff.ToRun = to.RunNumber
+	c.JSON(http.StatusOK, diff)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+var migrationRunColumns = []string{
+	"id", "migration_id", "run_number", "status", "progress", "error", "started_by", "artifacts_path",
+	"files_count", "started_at", "completed_at",
+}
+
+func TestDiffRunFiles(t *testing.T) {
+	from := []models.MigrationRunFile{
+		{Path: "models/staging/stg_customers.sql", Checksum: "a"},
+		{Path: "models/staging/stg_orders.sql", Checksum: "b"},
+		{Path: "models/staging/sources.yml", Checksum: "c"},
+	}
+	to := []models.MigrationRunFile{
+		{Path: "models/staging/stg_customers.sql", Checksum: "a"},
+		{Path: "models/staging/stg_orders.sql", Checksum: "b2"},
+		{Path: "models/staging/stg_products.sql", Checksum: "d"},
+	}
+
+	diff := diffRunFiles(from, to)
+	if diff.Added != 1 || diff.Removed != 1 || diff.Modified != 1 || diff.Unchanged != 1 {
+		t.Fatalf("diff = %+v", diff)
+	}
+	want := []models.RunFileChange{
+		{Path: "models/staging/sources.yml", Change: "removed"},
+		{Path: "models/staging/stg_orders.sql", Change: "modified"},
+		{Path: "models/staging/stg_products.sql", Change: "added"},
+	}
+	for i, change := range want {
+		if diff.Files[i] != change {
+			t.Errorf("files[%d] = %+v, want %+v", i, diff.Files[i], change)
+		}
+	}
+}
+
+func TestMigrationsGetRuns(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id FROM migrations WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery(`FROM migration_runs\s+WHERE migration_id = \$1 ORDER BY run_number DESC`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(migrationRunColumns).
+			AddRow(12, 7, 2, "running", 40, nil, testUserID, nil, 0, now, nil).
+			AddRow(9, 7, 1, "completed", 100, nil, testUserID, "/projects/7", 14, now, now))
+
+	status, body := serve(t, "GET", "/migrations/:id/runs", "/migrations/7/runs", nil, NewMigrationsHandler(store).GetRuns)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var runs []models.MigrationRun
+	if err := json.Unmarshal(body, &runs); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].RunNumber != 2 || runs[1].FilesCount != 14 {
+		t.Errorf("runs = %+v", runs)
+	}
+}
+
+func TestMigrationsDiffRuns(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	fileColumns := []string{"path", "file_type", "size", "checksum"}
+	mock.ExpectQuery(`FROM migration_runs\s+WHERE migration_id = \$1 AND run_number = \$2`).
+		WithArgs(int64(7), 1, testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(migrationRunColumns).AddRow(9, 7, 1, "completed", 100, nil, testUserID, nil, 1, now, now))
+	mock.ExpectQuery(`FROM migration_runs\s+WHERE migration_id = \$1 AND run_number = \$2`).
+		WithArgs(int64(7), 2, testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(migrationRunColumns).AddRow(12, 7, 2, "completed", 100, nil, testUserID, nil, 1, now, now))
+	mock.ExpectQuery(`FROM migration_run_files`).
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows(fileColumns).AddRow("models/stg_orders.sql", "sql", 120, "a"))
+	mock.ExpectQuery(`FROM migration_run_files`).
+		WithArgs(int64(12)).
+		WillReturnRows(sqlmock.NewRows(fileColumns).AddRow("models/stg_orders.sql", "sql", 140, "b"))
+	mock.ExpectQuery(`SELECT path, content FROM migration_run_files`).
+		WithArgs(int64(9), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"path", "content"}).AddRow("models/stg_orders.sql", "select id\nfrom orders\n"))
+	mock.ExpectQuery(`SELECT path, content FROM migration_run_files`).
+		WithArgs(int64(12), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"path", "content"}).AddRow("models/stg_orders.sql", "select id, total\nfrom orders\n"))
+
+	status, body := serve(t, "GET", "/migrations/:id/runs/:run/diff/:other", "/migrations/7/runs/1/diff/2", nil, NewMigrationsHandler(store).DiffRuns)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var diff models.MigrationRunDiff
+	if err := json.Unmarshal(body, &diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff.FromRun != 1 || diff.ToRun != 2 || diff.Modified != 1 || len(diff.Files) != 1 {
+		t.Fatalf("diff = %+v", diff)
+	}
+	want := "--- a/models/stg_orders.sql\n+++ b/models/stg_orders.sql\n@@ -1,2 +1,2 @@\n-select id\n+select id, total\n from orders\n"
+	if f := diff.Files[0]; f.Diff != want || f.Additions != 1 || f.Deletions != 1 {
+		t.Errorf("file = %+v", f)
+	}
+}
+
+func TestMigrationsGetRunNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migration_runs`).
+		WithArgs(int64(7), 3, testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(migrationRunColumns))
+
+	status, body := serve(t, "GET", "/migrations/:id/runs/:run", "/migrations/7/runs/3", nil, NewMigrationsHandler(store).GetRun)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestMigrationsRerunNotFinished(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE migrations SET status = 'pending'.+status IN \('completed', 'failed', 'cancelled'\)`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	status, body := serve(t, "POST", "/migrations/:id/rerun", "/migrations/5/rerun", nil, NewMigrationsHandler(store).Rerun)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if msg := errorMessage(t, body); msg != "Migration not found or not finished" {
+		t.Errorf("error = %q", msg)
+	}
+}
+
+func TestMigrationsDiffRunsRejectsContext(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/migrations/:id/runs/:run/diff/:other", "/migrations/7/runs/1/diff/2?context=500", nil, NewMigrationsHandler(store).DiffRuns)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
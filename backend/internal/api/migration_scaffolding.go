@@ -0,0 +1,165 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// stagingPrefix is the staging model prefix of an organization's naming conventions
+func stagingPrefix(store db.Querier, orgID int64) string {
+	if orgID == 0 {
+		return models.DefaultNamingConventions.StagingPrefix
+	}
+	defaults, err := loadOrganizationDefaults(store, orgID)
+	if err != nil {
+		log.Printf("Failed to load organization defaults of organization %d: %v", orgID, err)
+	}
+	return defaults.NamingConventions.StagingPrefix
+}
+
+// GetScaffoldingOptions returns the questions of the project scaffolding wizard
+// @Summary Get project scaffolding options
+// @Description The wizard's questions about the generated project's structure, in order, each with its choices and default
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /migrations/scaffolding/options [get]
+func (h *MigrationsHandler) GetScaffoldingOptions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"steps": dbtgen.ScaffoldingSteps})
+}
+
+// PreviewScaffolding lays out the models of a table selection
+// @Summary Preview project scaffolding
+// @Description Validate scaffolding choices and return the folders and staging model paths they give the selected tables, named with the organization's staging prefix
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ScaffoldingPreviewRequest true "Scaffolding choices and selected tables"
+// @Success 200 {object} models.ScaffoldingPreview
+// @Failure 400 {object} map[string]interface{}
+// @Router /migrations/scaffolding/preview [post]
+func (h *MigrationsHandler) PreviewScaffolding(c *gin.Context) {
+	var req models.ScaffoldingPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dbtgen.NormalizeScaffolding(&req.Scaffolding)
+	if result := dbtgen.ValidateScaffolding(&req.Scaffolding, req.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	prefix := stagingPrefix(h.db, middleware.GetOrganizationID(c))
+	c.JSON(http.StatusOK, dbtgen.PreviewScaffolding(dbtgen.ResolveScaffolding(&req.Scaffolding), prefix, req.Tables))
+}
+
+// GetScaffolding returns the structure of a migration's generated project
+// @Summary Get project scaffolding
+// @Description The migration's scaffolding with defaults filled in, and the folders and staging model paths it gives the selected tables
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} models.ScaffoldingPreview
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/scaffolding [get]
+func (h *MigrationsHandler) GetScaffolding(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	_, settings, err := loadMigrationSettings(h.db, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return
+	}
+
+	prefix := stagingPrefix(h.db, middleware.GetOrganizationID(c))
+	c.JSON(http.StatusOK, dbtgen.PreviewScaffolding(dbtgen.ResolveScaffolding(settings.Scaffolding), prefix, settings.Tables))
+}
+
+// UpdateScaffolding replaces the structure of a migration's generated project
+// @Summary Update project scaffolding
+// @Description Replace the migration's scaffolding choices. The next start or rerun uses them. Not allowed while the migration runs or is queued.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.ProjectScaffolding true "Scaffolding choices"
+// @Success 200 {object} models.ScaffoldingPreview
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/scaffolding [put]
+func (h *MigrationsHandler) UpdateScaffolding(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var req models.ProjectScaffolding
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, settings, err := loadMigrationSettings(h.db, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return
+	}
+	if status == "running" || status == "queued" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Scaffolding can't change while the migration is " + status})
+		return
+	}
+
+	dbtgen.NormalizeScaffolding(&req)
+	if result := dbtgen.ValidateScaffolding(&req, settings.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	updated, err := updateMigrationSetting(h.db, id, userID, orgID, "scaffolding", req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update scaffolding"})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Migration started while updating its scaffolding"})
+		return
+	}
+
+	prefix := stagingPrefix(h.db, middleware.GetOrganizationID(c))
+	c.JSON(http.StatusOK, dbtgen.PreviewScaffolding(dbtgen.ResolveScaffolding(&req), prefix, settings.Tables))
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func expectOrganizationSettings(mock sqlmock.Sqlmock, settings string) {
+	mock.ExpectQuery(`SELECT settings FROM organizations`).
+		WithArgs(testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow(settings))
+}
+
+func TestMigrationsPreviewScaffolding(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectOrganizationSettings(mock, `{"naming_conventions":{"staging_prefix":"base_"}}`)
+
+	body := map[string]interface{}{
+		"scaffolding": map[string]interface{}{"folders": "Schema", "naming": "singular"},
+		"tables":      []string{"Sales.Customers", "Person.Address", "Orders"},
+	}
+	status, resp := serve(t, "POST", "/migrations/scaffolding/preview", "/migrations/scaffolding/preview", body, NewMigrationsHandler(store).PreviewScaffolding)
+	expectStatus(t, status, http.StatusOK, resp)
+
+	var preview models.ScaffoldingPreview
+	if err := json.Unmarshal(resp, &preview); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if preview.Scaffolding.Layers != "layered" || preview.Scaffolding.Folders != "schema" {
+		t.Errorf("scaffolding = %+v, want the defaults filled in", preview.Scaffolding)
+	}
+	want := []models.ScaffoldedModel{
+		{Source: "Sales.Customers", Name: "base_customer", Path: "models/staging/sales/base_customer.sql"},
+		{Source: "Person.Address", Name: "base_address", Path: "models/staging/person/base_address.sql"},
+		{Source: "Orders", Name: "base_order", Path: "models/staging/dbo/base_order.sql"},
+	}
+	if len(preview.Models) != len(want) {
+		t.Fatalf("models = %+v", preview.Models)
+	}
+	for i, m := range preview.Models {
+		if m != want[i] {
+			t.Errorf("models[%d] = %+v, want %+v", i, m, want[i])
+		}
+	}
+	folders := []string{"models/intermediate", "models/marts", "models/staging", "models/staging/dbo", "models/staging/person", "models/staging/sales"}
+	if len(preview.Folders) != len(folders) {
+		t.Fatalf("folders = %v, want %v", preview.Folders, folders)
+	}
+	for i, f := range preview.Folders {
+		if f != folders[i] {
+			t.Errorf("folders[%d] = %s, want %s", i, f, folders[i])
+		}
+	}
+}
+
+func TestMigrationsPreviewScaffoldingValidation(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	body := map[string]interface{}{
+		"scaffolding": map[string]interface{}{
+			"layers":  "nested",
+			"folders": "domain",
+			"domains": []map[string]interface{}{
+				{"name": "finance", "tables": []string{"Sales.Invoice"}},
+				{"name": "Sales", "tables": []string{"Sales.Invoice", "Sales.Customer"}},
+			},
+		},
+		"tables": []string{"Sales.Invoice"},
+	}
+	status, resp := serve(t, "POST", "/migrations/scaffolding/preview", "/migrations/scaffolding/preview", body, NewMigrationsHandler(store).PreviewScaffolding)
+	expectStatus(t, status, http.StatusBadRequest, resp)
+
+	var result struct {
+		Details []struct {
+			Field string `json:"field"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []string{"scaffolding.layers", "scaffolding.domains[1].name", "scaffolding.domains[1].tables", "scaffolding.domains[1].tables"}
+	if len(result.Details) != len(want) {
+		t.Fatalf("details = %s", resp)
+	}
+	for i, d := range result.Details {
+		if d.Field != want[i] {
+			t.Errorf("details[%d] = %s, want %s", i, d.Field, want[i])
+		}
+	}
+}
+
+func TestMigrationsUpdateScaffolding(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectMigrationSettings(mock, "failed", `{"tables":["Sales.Invoice","Sales.Customer"]}`)
+	mock.ExpectExec(`UPDATE migrations SET config = jsonb_set`).
+		WithArgs("scaffolding", `{"layers":"simple","folders":"domain","domains":[{"name":"finance","tables":["Sales.Invoice"]}]}`, int64(7), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectOrganizationSettings(mock, `{}`)
+
+	body := map[string]interface{}{
+		"layers":  "simple",
+		"folders": "domain",
+		"domains": []map[string]interface{}{{"name": " finance ", "tables": []string{"Sales.Invoice"}}},
+	}
+	status, resp := serve(t, "PUT", "/migrations/:id/scaffolding", "/migrations/7/scaffolding", body, NewMigrationsHandler(store).UpdateScaffolding)
+	expectStatus(t, status, http.StatusOK, resp)
+
+	var preview models.ScaffoldingPreview
+	if err := json.Unmarshal(resp, &preview); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(preview.Models) != 2 || preview.Models[0].Path != "models/finance/stg_invoice.sql" || preview.Models[1].Path != "models/shared/stg_customer.sql" {
+		t.Errorf("models = %+v", preview.Models)
+	}
+}
+
+func TestMigrationsUpdateScaffoldingWhileQueued(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectMigrationSettings(mock, "queued", `{}`)
+
+	status, resp := serve(t, "PUT", "/migrations/:id/scaffolding", "/migrations/7/scaffolding", map[string]string{"naming": "plural"}, NewMigrationsHandler(store).UpdateScaffolding)
+	expectStatus(t, status, http.StatusConflict, resp)
+}
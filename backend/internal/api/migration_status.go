@@ -0,0 +1,155 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/pubsub"
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatusWait caps how long a status request is held, below common proxy timeouts
+const maxStatusWait = 60 * time.Second
+
+// statusRecheckInterval is how often a held status request re-reads the migration.
+// Changes made on this server wake it at once; the re-read picks up those that reached
+// another server.
+var statusRecheckInterval = 5 * time.Second
+
+// migrationTopic is the pub/sub topic of a migration's status changes
+func migrationTopic(id int64) string {
+	return "migration/" + strconv.FormatInt(id, 10)
+}
+
+// publishMigrationStatus wakes the status requests waiting on the migration
+func publishMigrationStatus(id int64) {
+	pubsub.Publish(migrationTopic(id))
+}
+
+// statusVersion identifies a migration's state for change detection. Every status
+// change moves updated_at.
+func statusVersion(status *models.MigrationStatus) string {
+	return strconv.FormatInt(status.UpdatedAt.UnixMicro(), 10)
+}
+
+func (h *MigrationsHandler) loadStatus(id, userID, orgID int64) (*models.MigrationStatus, error) {
+	var status models.MigrationStatus
+	err := h.db.Get(&status, `
+		SELECT id, status, progress, error, queue_reason, updated_at
+		FROM migrations
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	status.Version = statusVersion(&status)
+	return &status, nil
+}
+
+// GetStatus returns a migration's progress, waiting for it to change
+// @Summary Wait for a migration's status
+// @Description Long-polling alternative to WebSockets. With wait, the request is held until the migration's status or progress changes or the wait elapses (at most 60s). Pass the version of the previous response as since so changes between requests aren't missed; without it the request waits for a change from the current state. changed is false when the wait timed out.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param wait query string false "How long to wait for a change, e.g. 30s (default 0, return at once)"
+// @Param since query string false "version from the previous response"
+// @Success 200 {object} models.MigrationStatus
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/status [get]
+func (h *MigrationsHandler) GetStatus(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var wait time.Duration
+	if w := c.Query("wait"); w != "" {
+		wait, err = time.ParseDuration(w)
+		if err != nil {
+			// Plain numbers are seconds
+			seconds, convErr := strconv.Atoi(w)
+			if convErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "wait must be a duration such as 30s"})
+				return
+			}
+			wait = time.Duration(seconds) * time.Second
+		}
+		if wait < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "wait must not be negative"})
+			return
+		}
+		wait = min(wait, maxStatusWait)
+	}
+
+	// Subscribe before the first read so a change right after it isn't missed
+	changes, unsubscribe := pubsub.Subscribe(migrationTopic(id))
+	defer unsubscribe()
+
+	status, err := h.loadStatus(id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration status"})
+		return
+	}
+
+	since := c.Query("since")
+	if since == "" {
+		since = status.Version
+	}
+	status.Changed = status.Version != since
+
+	if !status.Changed && wait > 0 {
+		timeout := time.NewTimer(wait)
+		defer timeout.Stop()
+		recheck := time.NewTicker(statusRecheckInterval)
+		defer recheck.Stop()
+
+	waiting:
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				// The client went away
+				return
+			case <-timeout.C:
+				break waiting
+			case <-changes:
+			case <-recheck.C:
+			}
+			current, err := h.loadStatus(id, userID, orgID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration status"})
+				return
+			}
+			status = current
+			if status.Changed = status.Version != since; status.Changed {
+				break waiting
+			}
+		}
+	}
+
+	migration := models.Migration{ID: status.ID, Status: status.Status, QueueReason: status.QueueReason}
+	if status.QueuePosition, err = queuePosition(h.db, &migration); err != nil {
+		log.Printf("Failed to find the queue position of migration %d: %v", id, err)
+	}
+
+	c.JSON(http.StatusOK, status)
+}
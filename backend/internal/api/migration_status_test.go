@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/pubsub"
+)
+
+var statusColumns = []string{"id", "status", "progress", "error", "queue_reason", "updated_at"}
+
+func statusRow(progress int, updatedAt time.Time) *sqlmock.Rows {
+	return sqlmock.NewRows(statusColumns).AddRow(7, "running", progress, nil, nil, updatedAt)
+}
+
+func decodeStatus(t *testing.T, body json.RawMessage) models.MigrationStatus {
+	t.Helper()
+	var status models.MigrationStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return status
+}
+
+func TestGetStatusReturnsChangeSinceVersion(t *testing.T) {
+	sqlxDB, mock := newMockDB(t)
+	updated := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM migrations`).WithArgs(int64(7), testUserID, testOrgID).WillReturnRows(statusRow(40, updated))
+
+	h := NewMigrationsHandler(sqlxDB)
+	code, body := serve(t, http.MethodGet, "/migrations/:id/status", "/migrations/7/status?wait=30s&since=1", nil, h.GetStatus)
+	expectStatus(t, code, http.StatusOK, body)
+
+	status := decodeStatus(t, body)
+	if !status.Changed || status.Progress != 40 {
+		t.Errorf("status = %+v, want changed at progress 40", status)
+	}
+	if want := "1792054800000000"; status.Version != want {
+		t.Errorf("version = %q, want %q", status.Version, want)
+	}
+}
+
+func TestGetStatusWaitsForPublishedChange(t *testing.T) {
+	sqlxDB, mock := newMockDB(t)
+	updated := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM migrations`).WithArgs(int64(7), testUserID, testOrgID).WillReturnRows(statusRow(40, updated))
+	mock.ExpectQuery(`FROM migrations`).WithArgs(int64(7), testUserID, testOrgID).WillReturnRows(statusRow(55, updated.Add(time.Second)))
+
+	go func() {
+		for pubsub.Subscribers(migrationTopic(7)) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		publishMigrationStatus(7)
+	}()
+
+	h := NewMigrationsHandler(sqlxDB)
+	code, body := serve(t, http.MethodGet, "/migrations/:id/status", "/migrations/7/status?wait=30s", nil, h.GetStatus)
+	expectStatus(t, code, http.StatusOK, body)
+
+	status := decodeStatus(t, body)
+	if !status.Changed || status.Progress != 55 {
+		t.Errorf("status = %+v, want changed at progress 55", status)
+	}
+	if pubsub.Subscribers(migrationTopic(7)) != 0 {
+		t.Error("subscription outlived the request")
+	}
+}
+
+func TestGetStatusTimesOutUnchanged(t *testing.T) {
+	sqlxDB, mock := newMockDB(t)
+	updated := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM migrations`).WithArgs(int64(7), testUserID, testOrgID).WillReturnRows(statusRow(40, updated))
+
+	h := NewMigrationsHandler(sqlxDB)
+	code, body := serve(t, http.MethodGet, "/migrations/:id/status", "/migrations/7/status?wait=20ms", nil, h.GetStatus)
+	expectStatus(t, code, http.StatusOK, body)
+
+	if status := decodeStatus(t, body); status.Changed {
+		t.Errorf("status = %+v, want unchanged", status)
+	}
+}
+
+func TestGetStatusRejectsInvalidWait(t *testing.T) {
+	sqlxDB, _ := newMockDB(t)
+	h := NewMigrationsHandler(sqlxDB)
+
+	for _, wait := range []string{"soon", "-5s"} {
+		code, body := serve(t, http.MethodGet, "/migrations/:id/status", "/migrations/7/status?wait="+wait, nil, h.GetStatus)
+		expectStatus(t, code, http.StatusBadRequest, body)
+	}
+}
+
+func TestGetStatusNotFound(t *testing.T) {
+	sqlxDB, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migrations`).WithArgs(int64(7), testUserID, testOrgID).WillReturnRows(sqlmock.NewRows(statusColumns))
+
+	h := NewMigrationsHandler(sqlxDB)
+	code, body := serve(t, http.MethodGet, "/migrations/:id/status", "/migrations/7/status", nil, h.GetStatus)
+	expectStatus(t, code, http.StatusNotFound, body)
+}
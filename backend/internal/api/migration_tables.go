@@ -0,0 +1,239 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// tableStatuses are the statuses the AI service reports for a table
+var tableStatuses = []string{"pending", "extracting", "generated", "tested", "failed"}
+
+// runResume is the failed or cancelled run a new run resumes, and the tables it already generated
+type runResume struct {
+	RunID     int64
+	RunNumber int
+	Tables    []string
+}
+
+// recordTableProgress stores the status reported for tables of the current run. A table
+// reported again keeps one row with its latest status.
+func recordTableProgress(store db.Querier, migrationID int64, tables []models.MigrationTable) error {
+	for _, t := range tables {
+		_, err := store.Exec(`
+			INSERT INTO migration_tables (migration_id, run_id, table_name, status, error)
+			SELECT id, current_run_id, $2, $3, $4 FROM migrations WHERE id = $1 AND current_run_id IS NOT NULL
+			ON CONFLICT (run_id, table_name) DO UPDATE
+			SET status = EXCLUDED.status, error = EXCLUDED.error, updated_at = NOW()
+		`, migrationID, t.Name, t.Status, t.Error)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTables returns the progress of each table in a migration's current run
+// @Summary Get per-table progress
+// @Description Status of each source table in the current run (pending, extracting, generated, tested or failed) with when it last changed, and how many tables are in each status
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param status query string false "Only tables in this status"
+// @Success 200 {object} models.MigrationTables
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/tables [get]
+func (h *MigrationsHandler) GetTables(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+	statusFilter := c.Query("status")
+	if statusFilter != "" && !slices.Contains(tableStatuses, statusFilter) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter"})
+		return
+	}
+
+	var runNumber int
+	err = h.db.Get(&runNumber, `
+		SELECT COALESCE(r.run_number, 0)
+		FROM migrations m
+		LEFT JOIN migration_runs r ON r.id = m.current_run_id
+		WHERE m.id = $1 AND m.user_id = $2 AND COALESCE(m.organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	tables := []models.MigrationTable{}
+	err = h.db.Select(&tables, `
+		SELECT t.table_name, t.status, t.error, t.updated_at
+		FROM migration_tables t
+		JOIN migrations m ON m.current_run_id = t.run_id
+		WHERE m.id = $1
+		ORDER BY t.table_name
+	`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch table progress"})
+		return
+	}
+
+	result := models.MigrationTables{
+		MigrationID: id,
+		RunNumber:   runNumber,
+		Counts:      make(map[string]int, len(tableStatuses)),
+		Tables:      []models.MigrationTable{},
+	}
+	for _, s := range tableStatuses {
+		result.Counts[s] = 0
+	}
+	for _, t := range tables {
+		result.Counts[t.Status]++
+		if statusFilter == "" || t.Status == statusFilter {
+			result.Tables = append(result.Tables, t)
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// loadRunResume loads a failed or cancelled run with the tables it generated, for a new run to resume
+func loadRunResume(store db.Querier, runID int64) (*runResume, error) {
+	resume := &runResume{RunID: runID}
+	if err := store.Get(&resume.RunNumber, "SELECT run_number FROM migration_runs WHERE id = $1", runID); err != nil {
+		return nil, err
+	}
+	err := store.Select(&resume.Tables, `
+		SELECT table_name FROM migration_tables
+		WHERE run_id = $1 AND status IN ('generated', 'tested')
+		ORDER BY table_name
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+// carryOverTables copies the generated tables of a resumed run into the migration's new
+// current run, so its table progress covers the whole migration
+func carryOverTables(store db.Querier, migrationID int64, resume *runResume) {
+	_, err := store.Exec(`
+		INSERT INTO migration_tables (migration_id, run_id, table_name, status)
+		SELECT t.migration_id, m.current_run_id, t.table_name, t.status
+		FROM migration_tables t
+		JOIN migrations m ON m.id = t.migration_id
+		WHERE m.id = $1 AND t.run_id = $2 AND t.table_name = ANY($3)
+		ON CONFLICT (run_id, table_name) DO NOTHING
+	`, migrationID, resume.RunID, pq.StringArray(resume.Tables))
+	if err != nil {
+		log.Printf("Failed to carry table progress over to the resumed run of migration %d: %v", migrationID, err)
+	}
+	message := fmt.Sprintf("Resumed from run %d; skipping %d tables it already generated", resume.RunNumber, len(resume.Tables))
+	if err := recordRunLogs(store, migrationID, []models.MigrationLog{{Level: "info", Message: message}}); err != nil {
+		log.Printf("Failed to record logs for migration %d: %v", migrationID, err)
+	}
+}
+
+// Resume continues a failed or cancelled migration from where its run stopped
+// @Summary Resume a failed or cancelled migration
+// @Description Start a failed or cancelled migration again as a new run that keeps the models its last run already generated (tables in generated or tested status) and only generates the remaining tables. Requires per-table progress from the stopped run; without it, re-run the migration instead.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/resume [post]
+func (h *MigrationsHandler) Resume(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var migration struct {
+		Status string        `db:"status"`
+		RunID  sql.NullInt64 `db:"current_run_id"`
+	}
+	err = h.db.Get(&migration, "SELECT status, current_run_id FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if (migration.Status != "failed" && migration.Status != "cancelled") || !migration.RunID.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only failed or cancelled migrations can be resumed"})
+		return
+	}
+
+	resume, err := loadRunResume(h.db, migration.RunID.Int64)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch table progress"})
+		return
+	}
+	if len(resume.Tables) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The last run generated no tables to keep; re-run the migration instead"})
+		return
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE migrations SET status = 'pending', progress = 0, error = NULL, completed_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3 AND status IN ('failed', 'cancelled')
+	`, id, userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume migration"})
+		return
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only failed or cancelled migrations can be resumed"})
+		return
+	}
+
+	queueReason, failure := h.startMigration(id, userID, orgID, resume)
+	if failure != nil {
+		c.JSON(failure.Status, failure.Body)
+		return
+	}
+
+	message := "Migration resumed"
+	if queueReason != "" {
+		message = "Migration queued; " + queueReasonText(queueReason)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":          message,
+		"migration_id":     id,
+		"queued":           queueReason != "",
+		"resumed_from_run": resume.RunNumber,
+		"skipped_tables":   len(resume.Tables),
+	})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func TestMigrationsUpdateStatusRecordsTables(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE migrations SET status = \$1, progress = \$2`).
+		WithArgs("running", 45, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE migration_runs`).
+		WithArgs(int64(8), "running", 45, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO migration_tables .+ ON CONFLICT \(run_id, table_name\) DO UPDATE`).
+		WithArgs(int64(8), "Sales.Customer", "generated", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO migration_tables`).
+		WithArgs(int64(8), "Sales.Order", "failed", "column type xml is not supported").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":   "running",
+		"progress": 45,
+		"tables": []map[string]interface{}{
+			{"name": "Sales.Customer", "status": "generated"},
+			{"name": "Sales.Order", "status": "failed", "error": "column type xml is not supported"},
+		},
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestMigrationsUpdateStatusRejectsBadTableStatus(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":   "running",
+		"progress": 45,
+		"tables":   []map[string]interface{}{{"name": "Sales.Customer", "status": "done"}},
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestMigrationsGetTables(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`SELECT COALESCE\(r.run_number, 0\)`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"run_number"}).AddRow(2))
+	mock.ExpectQuery(`FROM migration_tables t\s+JOIN migrations m ON m.current_run_id = t.run_id`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "status", "error", "updated_at"}).
+			AddRow("Sales.Customer", "tested", nil, now).
+			AddRow("Sales.Order", "extracting", nil, now).
+			AddRow("Sales.Product", "tested", nil, now))
+
+	status, body := serve(t, "GET", "/migrations/:id/tables", "/migrations/8/tables?status=extracting", nil, NewMigrationsHandler(store).GetTables)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var tables models.MigrationTables
+	if err := json.Unmarshal(body, &tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables.RunNumber != 2 || tables.Counts["tested"] != 2 || tables.Counts["extracting"] != 1 || tables.Counts["failed"] != 0 {
+		t.Errorf("tables = %+v", tables)
+	}
+	if len(tables.Tables) != 1 || tables.Tables[0].Name != "Sales.Order" {
+		t.Errorf("filtered tables = %+v", tables.Tables)
+	}
+}
+
+func TestMigrationsGetTablesNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT COALESCE\(r.run_number, 0\)`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"run_number"}))
+
+	status, body := serve(t, "GET", "/migrations/:id/tables", "/migrations/8/tables", nil, NewMigrationsHandler(store).GetTables)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestMigrationsResumeNotFailed(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT status, current_run_id FROM migrations`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "current_run_id"}).AddRow("running", 21))
+
+	status, body := serve(t, "POST", "/migrations/:id/resume", "/migrations/8/resume", nil, NewMigrationsHandler(store).Resume)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if msg := errorMessage(t, body); msg != "Only failed or cancelled migrations can be resumed" {
+		t.Errorf("error = %q", msg)
+	}
+}
+
+func TestMigrationsResumeNothingGenerated(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT status, current_run_id FROM migrations`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "current_run_id"}).AddRow("failed", 21))
+	mock.ExpectQuery(`SELECT run_number FROM migration_runs`).
+		WithArgs(int64(21)).
+		WillReturnRows(sqlmock.NewRows([]string{"run_number"}).AddRow(2))
+	mock.ExpectQuery(`SELECT table_name FROM migration_tables\s+WHERE run_id = \$1 AND status IN \('generated', 'tested'\)`).
+		WithArgs(int64(21)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+
+	status, body := serve(t, "POST", "/migrations/:id/resume", "/migrations/8/resume", nil, NewMigrationsHandler(store).Resume)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestCarryOverTables(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`INSERT INTO migration_tables .+ t.run_id = \$2 AND t.table_name = ANY\(\$3\)`).
+		WithArgs(int64(8), int64(21), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO migration_logs`).
+		WithArgs(int64(8), "info", "Resumed from run 2; skipping 2 tables it already generated").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	carryOverTables(store, 8, &runResume{RunID: 21, RunNumber: 2, Tables: []string{"Sales.Customer", "Sales.Product"}})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,27 +1,43 @@
 package api
 
 import (
+	"archive/zip"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/crypto"
 	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/domains"
 	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/metrics"
 	"github.com/datamigrate-ai/backend/internal/middleware"
 	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/quota"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/datamigrate-ai/backend/internal/ticketing"
+	"github.com/datamigrate-ai/backend/internal/validation"
 	"github.com/gin-gonic/gin"
 )
 
-type MigrationsHandler struct{}
+type MigrationsHandler struct {
+	db db.Querier
+}
 
-func NewMigrationsHandler() *MigrationsHandler {
-	return &MigrationsHandler{}
+func NewMigrationsHandler(store db.Querier) *MigrationsHandler {
+	return &MigrationsHandler{db: store}
 }
 
 // GetAll returns all migrations for the current user
@@ -36,18 +52,19 @@ func NewMigrationsHandler() *MigrationsHandler {
 // @Router /migrations [get]
 func (h *MigrationsHandler) GetAll(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 
 	var migrations []models.Migration
-	err := db.DB.Select(&migrations, `
-		SELECT id, name, status, progress, source_database, target_project,
+	err := h.db.Select(&migrations, `
+		SELECT id, name, status, progress, connection_id, source_database, target_project,
 		       tables_count, COALESCE(views_count, 0) as views_count,
 		       COALESCE(foreign_keys_count, 0) as foreign_keys_count,
 		       COALESCE(models_generated, 0) as models_generated,
-		       user_id, error, created_at, completed_at, updated_at
+		       user_id, error, queue_reason, ticket_key, ticket_url, created_at, completed_at, updated_at
 		FROM migrations
-		WHERE user_id = $1
+		WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2
 		ORDER BY created_at DESC
-	`, userID)
+	`, userID, orgID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migrations"})
@@ -76,6 +93,7 @@ func (h *MigrationsHandler) GetAll(c *gin.Context) {
 // @Router /migrations/{id} [get]
 func (h *MigrationsHandler) GetOne(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
@@ -83,15 +101,15 @@ func (h *MigrationsHandler) GetOne(c *gin.Context) {
 	}
 
 	var migration models.Migration
-	err = db.DB.Get(&migration, `
-		SELECT id, name, status, progress, source_database, target_project,
+	err = h.db.Get(&migration, `
+		SELECT id, name, status, progress, connection_id, source_database, target_project,
 		       tables_count, COALESCE(views_count, 0) as views_count,
 		       COALESCE(foreign_keys_count, 0) as foreign_keys_count,
 		       COALESCE(models_generated, 0) as models_generated,
-		       user_id, error, config, created_at, completed_at, updated_at
+		       user_id, error, queue_reason, ticket_key, ticket_url, config, created_at, completed_at, updated_at
 		FROM migrations
-		WHERE id = $1 AND user_id = $2
-	`, id, userID)
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -101,24 +119,35 @@ func (h *MigrationsHandler) GetOne(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
 		return
 	}
+	if migration.Config != nil {
+		masked := maskMigrationConfig(*migration.Config)
+		migration.Config = &masked
+	}
+	if migration.QueuePosition, err = queuePosition(h.db, &migration); err != nil {
+		log.Printf("Failed to find the queue position of migration %d: %v", id, err)
+	}
 
 	c.JSON(http.StatusOK, migration)
 }
 
 // Create creates a new migration
 // @Summary Create a new migration
-// @Description Create a new migration project
+// @Description Create a new migration project. A migration with the same name, source and tables created in the last 24 hours is reported as a conflict with a reference to it, unless force is set.
 // @Tags migrations
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.CreateMigrationRequest true "Migration configuration"
+// @Param force query bool false "Create the migration even if it duplicates a recent one"
 // @Success 201 {object} models.Migration
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /migrations [post]
 func (h *MigrationsHandler) Create(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	force := c.Query("force") == "true"
 
 	var req models.CreateMigrationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -126,18 +155,119 @@ func (h *MigrationsHandler) Create(c *gin.Context) {
 		return
 	}
 
+	req.Snapshots = dbtgen.NormalizeSnapshots(req.Snapshots)
+	if result := dbtgen.ValidateSnapshots(req.Snapshots, req.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": result.Errors,
+		})
+		return
+	}
+
+	req.TableFilters = dbtgen.NormalizeTableFilters(req.TableFilters)
+	if result := dbtgen.ValidateTableFilters(req.TableFilters, req.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	dbtgen.NormalizeTestCoverage(req.TestCoverage)
+	if result := dbtgen.ValidateTestCoverage(req.TestCoverage, req.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	dbtgen.NormalizeScaffolding(req.Scaffolding)
+	if result := dbtgen.ValidateScaffolding(req.Scaffolding, req.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	if details := validateMigrationSecrets(req.Secrets); len(details) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": details})
+		return
+	}
+
+	result := validation.NewValidationResult()
+	validateRunWindow(result, req.RunWindow)
+	if validateReconciliation(result, req.Reconciliation, req.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": result.Errors})
+		return
+	}
+
+	if req.ConnectionID == nil && req.SourceDatabase == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "connection_id is required"})
+		return
+	}
+	source, err := resolveSourceConnection(h.db, req.ConnectionID, req.SourceDatabase, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Source connection not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch source connection"})
+		return
+	}
+
+	if req.TargetConnectionID != nil {
+		var exists bool
+		if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM database_connections WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3)", *req.TargetConnectionID, userID, orgID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch target connection"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Target connection not found"})
+			return
+		}
+	}
+
+	if !force {
+		duplicate, err := findDuplicateMigration(h.db, req.Name, source, req.Tables, userID, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for duplicate migrations"})
+			return
+		}
+		if duplicate != nil {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":        "A migration with the same name, source and tables was created in the last 24 hours; pass force=true to create another",
+				"duplicate_of": duplicate,
+			})
+			return
+		}
+	}
+
 	tablesCount := len(req.Tables)
 	if tablesCount == 0 {
 		tablesCount = 1 // Default if no tables specified
 	}
 
-	var migrationID int64
-	err := db.DB.QueryRow(`
-		INSERT INTO migrations (name, source_database, target_project, tables_count, user_id, status, progress)
-		VALUES ($1, $2, $3, $4, $5, 'pending', 0)
-		RETURNING id
-	`, req.Name, req.SourceDatabase, req.TargetProject, tablesCount, userID).Scan(&migrationID)
+	config, err := json.Marshal(models.MigrationConfig{
+		Tables:       req.Tables,
+		IncludeViews: req.IncludeViews,
+		Snapshots:    req.Snapshots,
+
+		TargetConnectionID: req.TargetConnectionID,
+		Secrets:            req.Secrets,
+		RunWindow:          req.RunWindow,
+		Reconciliation:     req.Reconciliation,
+		TableFilters:       req.TableFilters,
+		TestCoverage:       req.TestCoverage,
+		Scaffolding:        req.Scaffolding,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create migration"})
+		return
+	}
+	config, err = sealMigrationConfig(crypto.GetEncryptionService(), config)
+	if err != nil {
+		if errors.Is(err, errConfigEncryptionNotConfigured) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Secrets can't be stored: encryption is not configured on this server"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encrypt migration secrets"})
+		return
+	}
 
+	migrationID, err := insertPendingMigration(h.db, req.Name, source, req.TargetProject, tablesCount, userID, orgID, config)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create migration"})
 		return
@@ -145,8 +275,8 @@ func (h *MigrationsHandler) Create(c *gin.Context) {
 
 	// Fetch the created migration
 	var migration models.Migration
-	db.DB.Get(&migration, `
-		SELECT id, name, status, progress, source_database, target_project,
+	h.db.Get(&migration, `
+		SELECT id, name, status, progress, connection_id, source_database, target_project,
 		       tables_count, user_id, created_at, updated_at
 		FROM migrations WHERE id = $1
 	`, migrationID)
@@ -154,6 +284,57 @@ func (h *MigrationsHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, migration)
 }
 
+// insertPendingMigration stores a new migration that waits to be started
+func insertPendingMigration(store db.Querier, name string, source ownedConnection, targetProject string, tablesCount int, userID, orgID int64, config []byte) (int64, error) {
+	var migrationID int64
+	err := store.QueryRow(`
+		INSERT INTO migrations (name, connection_id, source_database, target_project, tables_count, user_id, organization_id, status, progress, config)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), 'pending', 0, $8)
+		RETURNING id
+	`, name, source.ID, source.Name, targetProject, tablesCount, userID, orgID, string(config)).Scan(&migrationID)
+	return migrationID, err
+}
+
+// PreviewSnapshots renders the dbt snapshot YAML for a table selection
+// @Summary Preview snapshot YAML
+// @Description Validate slowly changing dimension (SCD2) settings and render the dbt snapshots YAML that generation will include
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SnapshotPreviewRequest true "Selected tables and snapshot settings"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /migrations/snapshots/preview [post]
+func (h *MigrationsHandler) PreviewSnapshots(c *gin.Context) {
+	var req models.SnapshotPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshots := dbtgen.NormalizeSnapshots(req.Snapshots)
+	if result := dbtgen.ValidateSnapshots(snapshots, req.Tables); !result.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": result.Errors,
+		})
+		return
+	}
+
+	yml, err := dbtgen.SnapshotsYAML(snapshots)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"path":      "snapshots/snapshots.yml",
+		"yaml":      yml,
+		"snapshots": snapshots,
+	})
+}
+
 // Delete deletes a migration
 // @Summary Delete a migration
 // @Description Delete a migration project (cannot delete running migrations)
@@ -169,146 +350,232 @@ func (h *MigrationsHandler) Create(c *gin.Context) {
 // @Router /migrations/{id} [delete]
 func (h *MigrationsHandler) Delete(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
 		return
 	}
 
+	if failure := h.deleteMigration(id, userID, orgID); failure != nil {
+		c.JSON(failure.Status, failure.Body)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Migration deleted"})
+}
+
+// deleteMigration deletes one of the user's migrations in the organization unless it is running
+func (h *MigrationsHandler) deleteMigration(id, userID, orgID int64) *actionError {
 	// Check ownership and status
 	var migration models.Migration
-	err = db.DB.Get(&migration, "SELECT status FROM migrations WHERE id = $1 AND user_id = $2", id, userID)
+	err := h.db.Get(&migration, "SELECT status FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
-			return
+			return newActionError(http.StatusNotFound, "Migration not found")
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
+		return newActionError(http.StatusInternalServerError, "Database error")
 	}
 
 	if migration.Status == "running" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete a running migration"})
-		return
+		return newActionError(http.StatusBadRequest, "Cannot delete a running migration")
 	}
 
-	_, err = db.DB.Exec("DELETE FROM migrations WHERE id = $1 AND user_id = $2", id, userID)
+	_, err = h.db.Exec("DELETE FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete migration"})
-		return
+		return newActionError(http.StatusInternalServerError, "Failed to delete migration")
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Migration deleted"})
+	return nil
 }
 
 // Start starts a pending migration
 // @Summary Start a migration
-// @Description Start a pending migration to begin extracting metadata and generating dbt project
+// @Description Start a pending migration to begin extracting metadata and generating dbt project. With dry_run the source schema is read and the planned models, estimated counts and warnings are returned instead; nothing is generated, the migration stays as it is and no plan quota is used.
 // @Tags migrations
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Migration ID"
+// @Param request body models.StartMigrationRequest false "Start options"
 // @Success 200 {object} map[string]string
+// @Success 200 {object} models.MigrationPlan "Dry run"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /migrations/{id}/start [post]
 func (h *MigrationsHandler) Start(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
 		return
 	}
 
-	// First, get the migration details including source_database
+	var req models.StartMigrationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.DryRun {
+		plan, failure := h.planMigration(id, userID, orgID, nil)
+		if failure != nil {
+			c.JSON(failure.Status, failure.Body)
+			return
+		}
+		c.JSON(http.StatusOK, plan)
+		return
+	}
+
+	queueReason, failure := h.startMigration(id, userID, orgID, nil)
+	if failure != nil {
+		c.JSON(failure.Status, failure.Body)
+		return
+	}
+
+	c.JSON(http.StatusOK, startedResponse(h.db, id, queueReason))
+}
+
+// startMigration moves one of the user's pending migrations in the organization to running and hands it to
+// the AI service. With resume set, the tables the resumed run generated are carried into
+// the new run and skipped. When the source connection already has as many migrations
+// extracting as it allows, or the migration is outside its run window, it is queued
+// instead and the reason is returned.
+func (h *MigrationsHandler) startMigration(id, userID, orgID int64, resume *runResume) (string, *actionError) {
+	// First, get the migration details including its source connection
 	var migration struct {
 		ID             int64          `db:"id"`
+		ConnectionID   sql.NullInt64  `db:"connection_id"`
 		SourceDatabase string         `db:"source_database"`
 		TargetProject  string         `db:"target_project"`
 		Config         sql.NullString `db:"config"`
 		Status         string         `db:"status"`
+		TablesCount    int            `db:"tables_count"`
+		OrganizationID int64          `db:"organization_id"`
 	}
 
-	err = db.DB.Get(&migration, `
-		SELECT id, source_database, target_project, config, status
+	err := h.db.Get(&migration, `
+		SELECT id, connection_id, source_database, target_project, config, status, COALESCE(tables_count, 0) as tables_count,
+		       COALESCE(organization_id, 0) as organization_id
 		FROM migrations
-		WHERE id = $1 AND user_id = $2
-	`, id, userID)
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, id, userID, orgID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
-			return
+			return "", newActionError(http.StatusNotFound, "Migration not found")
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
-		return
+		return "", newActionError(http.StatusInternalServerError, "Failed to fetch migration")
 	}
 
-	if migration.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Migration is not in pending status"})
-		return
+	if migration.Status != "pending" && migration.Status != "queued" {
+		return "", newActionError(http.StatusBadRequest, "Migration is not in pending status")
 	}
 
-	// Get the source database connection details
-	var connection struct {
-		ID             int64  `db:"id"`
-		Name           string `db:"name"`
-		DBType         string `db:"db_type"`
-		Host           string `db:"host"`
-		Port           int    `db:"port"`
-		Database       string `db:"database_name"`
-		Username       string `db:"username"`
-		Password       string `db:"password"`
-		UseWindowsAuth bool   `db:"use_windows_auth"`
-	}
-
-	err = db.DB.Get(&connection, `
-		SELECT id, name, db_type, host, port, database_name, username, password, COALESCE(use_windows_auth, false) as use_windows_auth
-		FROM database_connections
-		WHERE name = $1 AND user_id = $2
-	`, migration.SourceDatabase, userID)
+	config, failure := loadMigrationConfig(id, migration.Config)
+	if failure != nil {
+		return "", failure
+	}
+	// Checked again before dispatch, so the AI service only gets settings the backend accepts
+	if result := dbtgen.ValidateTestCoverage(config.TestCoverage, config.Tables); !result.Valid {
+		return "", &actionError{Status: http.StatusBadRequest, Body: gin.H{"error": "Test coverage is invalid; update it before starting", "details": result.Errors}}
+	}
+	if result := dbtgen.ValidateScaffolding(config.Scaffolding, config.Tables); !result.Valid {
+		return "", &actionError{Status: http.StatusBadRequest, Body: gin.H{"error": "Scaffolding is invalid; update it before starting", "details": result.Errors}}
+	}
 
+	// An organization pinned to a data region runs only on that region's AI service
+	aiClient, err := organizationAIClient(h.db, orgID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Source database connection not found"})
-			return
+		if errors.Is(err, aiservice.ErrRegionUnavailable) {
+			return "", newActionError(http.StatusServiceUnavailable, err.Error())
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch database connection"})
-		return
+		return "", newActionError(http.StatusInternalServerError, "Failed to fetch organization")
 	}
 
-	// Update status to running
-	result, err := db.DB.Exec(`
-		UPDATE migrations SET status = 'running', progress = 0, updated_at = NOW()
-		WHERE id = $1 AND user_id = $2 AND status = 'pending'
-	`, id, userID)
+	// Monthly plan quotas of the migration's organization: runs, tables, and the AI tokens
+	// used for generation. A resumed run only migrates the tables that are left.
+	tablesToMigrate := int64(migration.TablesCount)
+	if resume != nil {
+		tablesToMigrate = max(tablesToMigrate-int64(len(resume.Tables)), 0)
+	}
+	for _, check := range []struct {
+		metric string
+		amount int64
+	}{
+		{quota.MetricMigrationsRun, 1},
+		{quota.MetricTablesMigrated, tablesToMigrate},
+		{quota.MetricAITokens, 0},
+	} {
+		if exceeded := quotaExceeded(orgID, check.metric, check.amount); exceeded != nil {
+			return "", &actionError{Status: http.StatusPaymentRequired, Body: exceeded}
+		}
+	}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start migration"})
-		return
+	connection, failure := h.loadSourceConnection(migration.ConnectionID, userID, orgID)
+	if failure != nil {
+		return "", failure
+	}
+	if failure := h.preflightSourceConnection(connection); failure != nil {
+		return "", failure
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Migration not found or not in pending status"})
-		return
+	// The organization's masking policy hides sensitive columns in the generated models;
+	// without it, a migration would copy them as they are
+	var masking models.MaskingPolicy
+	if orgID != 0 {
+		if masking, err = loadMaskingPolicy(h.db, orgID); err != nil {
+			log.Printf("Failed to load masking policy for migration %d: %v", id, err)
+			return "", newActionError(http.StatusInternalServerError, "Failed to load masking policy")
+		}
 	}
 
-	// Parse tables from config if available
-	var tables []string
-	if migration.Config.Valid {
-		var config struct {
-			Tables []string `json:"tables"`
+	// Take one of the connection's extraction slots, or wait in its queue. The migration
+	// is stamped with its region: its files are read back from the instance that
+	// generates them.
+	var resumeRunID sql.NullInt64
+	if resume != nil {
+		resumeRunID = sql.NullInt64{Int64: resume.RunID, Valid: true}
+	}
+	var region string
+	if aiClient != nil {
+		region = aiClient.Region()
+	}
+	outsideRunWindow := !runWindowOpen(config.RunWindow, time.Now())
+	queueReason, err := claimExtractionSlot(h.db, id, connection.ID, resumeRunID, region, outsideRunWindow)
+	if err != nil {
+		if err == errNotStartable {
+			return "", newActionError(http.StatusBadRequest, "Migration not found or not in pending status")
 		}
-		if err := json.Unmarshal([]byte(migration.Config.String), &config); err == nil {
-			tables = config.Tables
+		log.Printf("Failed to claim an extraction slot for migration %d: %v", id, err)
+		return "", newActionError(http.StatusInternalServerError, "Failed to start migration")
+	}
+	// The organization hears when a migration starts waiting and when it stops
+	wasQueued := migration.Status == "queued"
+	if queueReason != "" {
+		if !wasQueued {
+			go notifyQueueChange(h.db, id, queueReason)
 		}
+		return queueReason, nil
+	}
+	if wasQueued {
+		go notifyQueueChange(h.db, id, "")
 	}
 
+	if err := startRun(h.db, id, userID); err != nil {
+		log.Printf("Failed to record run of migration %d: %v", id, err)
+	} else if resume != nil {
+		carryOverTables(h.db, id, resume)
+	}
+	go syncMigrationTicket(h.db, crypto.GetEncryptionService(), id, ticketing.EventStarted)
+
+	recordUsage(orgID, quota.MetricMigrationsRun, 1)
+	recordUsage(orgID, quota.MetricTablesMigrated, tablesToMigrate)
+
 	// Trigger AI service to process the migration
-	aiClient := aiservice.GetClient()
 	if aiClient != nil {
 		req := aiservice.MigrationRequest{
 			MigrationID: id,
@@ -322,66 +589,259 @@ func (h *MigrationsHandler) Start(c *gin.Context) {
 				"use_windows_auth": connection.UseWindowsAuth,
 			},
 			TargetProject: migration.TargetProject,
-			Tables:        tables,
-			IncludeViews:  false,
+			Tables:        config.Tables,
+			IncludeViews:  config.IncludeViews,
+			Snapshots:     config.Snapshots,
+			TableFilters:  config.TableFilters,
+			TestCoverage:  dbtgen.ResolveTestCoverage(config.TestCoverage),
+			Scaffolding:   dbtgen.ResolveScaffolding(config.Scaffolding),
+			Secrets:       config.Secrets,
+			// The instance's own region and bucket, so projects are stored where they're generated
+			DataRegion:     aiClient.Region(),
+			ArtifactBucket: aiClient.ArtifactBucket(),
+		}
+		if resume != nil {
+			req.SkipTables = resume.Tables
+		}
+		// The connection's throttle and read settings protect the workload running on the source
+		extra := parseExtraConfig(connection.ExtraConfig)
+		req.Throttle = extra.Throttle
+		req.SourceConnection["read_replica"] = extra.ReadReplica
+		if extra.ReadIsolation != dbtest.ReadIsolationDefault {
+			req.SourceConnection["read_isolation"] = extra.ReadIsolation
 		}
 
+		// Tables already exported as seeds are ref()'d instead of read from the source
+		seeds, err := listSeeds(h.db, id, SeedExported)
+		if err != nil {
+			log.Printf("Failed to list seeds for migration %d: %v", id, err)
+		}
+		for _, seed := range seeds {
+			req.Seeds = append(req.Seeds, aiservice.SeedRef{Table: seed.SourceTable, Name: seed.SeedName})
+		}
+
+		if config.TargetConnectionID != nil {
+			target, err := loadTargetAdapter(h.db, *config.TargetConnectionID, userID, orgID)
+			if err != nil {
+				log.Printf("Failed to load target connection for migration %d: %v", id, err)
+			}
+			req.Target = target
+		}
+
+		// Organization defaults: the warehouse to target when no connection is picked,
+		// and model naming prefixes
+		if orgID != 0 {
+			defaults, err := loadOrganizationDefaults(h.db, orgID)
+			if err != nil {
+				log.Printf("Failed to load organization defaults for migration %d: %v", id, err)
+			}
+			if req.Target == nil && defaults.DefaultWarehouse != "" {
+				req.Target = &aiservice.TargetAdapter{
+					Type:    defaults.DefaultWarehouse,
+					Adapter: dbtAdapters[defaults.DefaultWarehouse],
+					TSQL:    isTSQLTarget(defaults.DefaultWarehouse),
+				}
+			}
+			req.Naming = &defaults.NamingConventions
+		}
+
+		// Downstream dashboards and reports become exposures.yml
+		if exposures, err := listExposures(h.db, id); err != nil {
+			log.Printf("Failed to list exposures for migration %d: %v", id, err)
+		} else if len(exposures) > 0 {
+			if req.ExposuresYAML, err = dbtgen.ExposuresYAML(exposures, seedNames(seeds)); err != nil {
+				log.Printf("Failed to render exposures for migration %d: %v", id, err)
+			}
+		}
+
+		// Sample values must be masked per the organization's PII policy before leaving the profiler
+		masker, err := security.LoadPIIMasker(orgID)
+		if err != nil {
+			log.Printf("Failed to load PII policy for migration %d: %v", id, err)
+		}
+		req.PIIPolicy = masker.Policy()
+		req.ColumnMasks = dbtgen.MasksForTables(masking.Rules, config.Tables)
+
+		params := connection.params()
+
 		go func() {
+			req.Dependencies = storeDependencyGraph(h.db, id, params)
+
 			// Call AI service in background
-			_, err := aiClient.StartMigration(req)
+			start := time.Now()
+			resp, err := aiClient.StartMigration(req)
+			recordGenerationInteraction(userID, orgID, id, req, resp, time.Since(start), err)
 			if err != nil {
 				log.Printf("Failed to trigger AI service for migration %d: %v", id, err)
 				// Update migration status to failed
-				db.DB.Exec(`
+				errMsg := "Failed to connect to AI service: " + err.Error()
+				h.db.Exec(`
 					UPDATE migrations SET status = 'failed', error = $1, updated_at = NOW()
 					WHERE id = $2
-				`, "Failed to connect to AI service: "+err.Error(), id)
+				`, errMsg, id)
+				updateCurrentRun(h.db, id, "failed", 0, &errMsg)
+				publishMigrationStatus(id)
+				h.dispatchAfter(id)
 			}
 		}()
 	} else {
 		log.Printf("AI service client not initialized, migration %d started in manual mode", id)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Migration started", "migration_id": id})
+	return "", nil
+}
+
+// loadMigrationConfig parses a migration's stored config. Secrets are decrypted only
+// here; a migration whose secrets can't be decrypted isn't started without them.
+func loadMigrationConfig(id int64, stored sql.NullString) (models.MigrationConfig, *actionError) {
+	var config models.MigrationConfig
+	if !stored.Valid {
+		return config, nil
+	}
+	raw, err := unsealMigrationConfig(crypto.GetEncryptionService(), []byte(stored.String))
+	if err != nil {
+		log.Printf("Failed to decrypt config of migration %d: %v", id, err)
+		return config, newActionError(http.StatusInternalServerError, "Failed to decrypt migration secrets")
+	}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		log.Printf("Invalid config for migration %d: %v", id, err)
+	}
+	return config, nil
+}
+
+// sourceConnection is the source database connection of a migration, with the password
+// still encrypted
+type sourceConnection struct {
+	ID             int64   `db:"id"`
+	Name           string  `db:"name"`
+	DBType         string  `db:"db_type"`
+	Host           string  `db:"host"`
+	Port           int     `db:"port"`
+	Database       string  `db:"database_name"`
+	Username       string  `db:"username"`
+	Password       string  `db:"password"`
+	UseWindowsAuth bool    `db:"use_windows_auth"`
+	ExtraConfig    *string `db:"extra_config"`
+}
+
+// loadSourceConnection loads one of the user's connections in the migration's organization
+func (h *MigrationsHandler) loadSourceConnection(connectionID sql.NullInt64, userID, orgID int64) (*sourceConnection, *actionError) {
+	var connection sourceConnection
+	err := h.db.Get(&connection, `
+		SELECT id, name, db_type, host, port, database_name, username, password, COALESCE(use_windows_auth, false) as use_windows_auth,
+		       extra_config
+		FROM database_connections
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, connectionID, userID, orgID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newActionError(http.StatusBadRequest, "Source database connection not found")
+		}
+		return nil, newActionError(http.StatusInternalServerError, "Failed to fetch database connection")
+	}
+	return &connection, nil
+}
+
+// params are the connection's parameters for reading the source database directly
+func (c *sourceConnection) params() dbtest.ConnectionParams {
+	extra := parseExtraConfig(c.ExtraConfig)
+	return dbtest.ConnectionParams{
+		DBType:         c.DBType,
+		Host:           c.Host,
+		Port:           c.Port,
+		Database:       c.Database,
+		Username:       c.Username,
+		Password:       decryptConnectionPassword(crypto.GetEncryptionService(), c.Password),
+		UseWindowsAuth: c.UseWindowsAuth,
+		TenantID:       extra.TenantID,
+		ReadReplica:    extra.ReadReplica,
+		ReadIsolation:  extra.ReadIsolation,
+	}
 }
 
-// Stop stops a running migration
+// Stop cancels a running migration
 // @Summary Stop a migration
-// @Description Stop a running migration
+// @Description Cancel a running migration, or take a queued one out of its connection's queue (it goes back to pending). A running migration is marked cancelled, and the AI service is told to stop generating. The response waits for the AI service to acknowledge, then reports what the run generated before it stopped; those tables and files are kept.
 // @Tags migrations
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Migration ID"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /migrations/{id}/stop [post]
 func (h *MigrationsHandler) Stop(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
 		return
 	}
 
-	result, err := db.DB.Exec(`
-		UPDATE migrations SET status = 'failed', error = 'Stopped by user', updated_at = NOW()
-		WHERE id = $1 AND user_id = $2 AND status = 'running'
-	`, id, userID)
-
-	if err != nil {
+	// Cancelled before the AI service is told, so status updates it sends while
+	// stopping can't bring the migration back
+	var progress int
+	err = h.db.Get(&progress, `
+		UPDATE migrations SET status = 'cancelled', error = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $4 AND status = 'running'
+		RETURNING progress
+	`, id, userID, cancelledByUser, orgID)
+	if err != nil && err != sql.ErrNoRows {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop migration"})
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Migration not found or not running"})
+	if err == sql.ErrNoRows {
+		// A queued migration hasn't started, so it just leaves the queue
+		result, err := h.db.Exec(`
+			UPDATE migrations SET status = 'pending', queued_at = NULL, queue_reason = NULL, resume_run_id = NULL, updated_at = NOW()
+			WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3 AND status = 'queued'
+		`, id, userID, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop migration"})
+			return
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Migration not found or not running"})
+			return
+		}
+		publishMigrationStatus(id)
+		c.JSON(http.StatusOK, gin.H{"message": "Migration removed from the queue"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Migration stopped"})
+	ack, err := stopGeneration(h.db, id)
+	if err != nil {
+		log.Printf("Failed to stop migration %d on the AI service: %v", id, err)
+	}
+	cancelled := cancelledByUser
+	updateCurrentRun(h.db, id, "cancelled", progress, &cancelled)
+	metrics.RecordMigrationOutcome("cancelled")
+	publishMigrationStatus(id)
+	go h.dispatchAfter(id)
+
+	response := gin.H{"message": "Migration cancelled", "status": "cancelled", "acknowledged": false}
+	switch {
+	case ack == nil:
+		response["message"] = "Migration cancelled; the AI service did not confirm it stopped generating"
+	case !ack.Stopped():
+		response["message"] = "Migration cancelled; the AI service is still stopping generation"
+	}
+	if ack != nil {
+		kept := 0
+		for _, t := range ack.Tables {
+			if t.Status == "generated" || t.Status == "tested" {
+				kept++
+			}
+		}
+		response["acknowledged"] = true
+		response["models_generated"] = ack.ModelsGenerated
+		response["tables_kept"] = kept
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetStats returns dashboard statistics
@@ -395,37 +855,43 @@ func (h *MigrationsHandler) Stop(c *gin.Context) {
 // @Router /stats [get]
 func (h *MigrationsHandler) GetStats(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 
 	var stats models.DashboardStats
 
 	// Get counts
-	db.DB.Get(&stats.TotalMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1", userID)
-	db.DB.Get(&stats.CompletedMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND status = 'completed'", userID)
-	db.DB.Get(&stats.RunningMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND status = 'running'", userID)
-	db.DB.Get(&stats.FailedMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND status = 'failed'", userID)
+	h.db.Get(&stats.TotalMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2", userID, orgID)
+	h.db.Get(&stats.CompletedMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2 AND status = 'completed'", userID, orgID)
+	h.db.Get(&stats.RunningMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2 AND status = 'running'", userID, orgID)
+	h.db.Get(&stats.FailedMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2 AND status = 'failed'", userID, orgID)
+	h.db.Get(&stats.CancelledMigrations, "SELECT COUNT(*) FROM migrations WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2 AND status = 'cancelled'", userID, orgID)
 
 	if stats.TotalMigrations > 0 {
 		stats.SuccessRate = float64(stats.CompletedMigrations) / float64(stats.TotalMigrations) * 100
 	}
+	stats.AIService = h.aiServiceCapacity()
 
 	c.JSON(http.StatusOK, stats)
 }
 
 // GetFiles returns the list of generated dbt files for a migration
 // @Summary Get migration files
-// @Description Get list of generated dbt files for a migration
+// @Description Get list of generated dbt files for a migration. Once the migration has completed this is its checksum manifest: each file's size and SHA-256, and a digest of the whole manifest that is also the ETag.
 // @Tags migrations
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Migration ID"
-// @Success 200 {object} map[string]interface{}
+// @Param If-None-Match header string false "ETag of a manifest the client already has"
+// @Success 200 {object} models.FileManifest
+// @Success 304 "Manifest unchanged"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 503 {object} map[string]string
 // @Router /migrations/{id}/files [get]
 func (h *MigrationsHandler) GetFiles(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
@@ -434,7 +900,7 @@ func (h *MigrationsHandler) GetFiles(c *gin.Context) {
 
 	// Verify user owns this migration
 	var migration models.Migration
-	err = db.DB.Get(&migration, "SELECT id FROM migrations WHERE id = $1 AND user_id = $2", id, userID)
+	err = h.db.Get(&migration, "SELECT id FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
@@ -444,8 +910,32 @@ func (h *MigrationsHandler) GetFiles(c *gin.Context) {
 		return
 	}
 
-	// Get files from AI service
-	aiClient := aiservice.GetClient()
+	manifest, runID, err := loadFileManifest(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch file manifest"})
+		return
+	}
+	if manifest != nil {
+		etag := `"` + manifest.SHA256 + `"`
+		c.Header("ETag", etag)
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		if manifest.Files, err = loadManifestFiles(h.db, runID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch file manifest"})
+			return
+		}
+		c.JSON(http.StatusOK, manifest)
+		return
+	}
+
+	// Not completed yet: list the files the AI service has generated so far
+	aiClient, err := migrationAIClient(h.db, id)
+	if err != nil {
+		aiClientError(c, err)
+		return
+	}
 	if aiClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not available"})
 		return
@@ -462,7 +952,7 @@ func (h *MigrationsHandler) GetFiles(c *gin.Context) {
 
 // GetFileContent returns the content of a specific dbt file
 // @Summary Get file content
-// @Description Get the content of a specific generated dbt file
+// @Description Get the content of a specific generated dbt file. Embedded credentials, API keys and connection strings are replaced with [REDACTED] and listed in secrets_redacted.
 // @Tags migrations
 // @Accept json
 // @Produce json
@@ -476,6 +966,7 @@ func (h *MigrationsHandler) GetFiles(c *gin.Context) {
 // @Router /migrations/{id}/files/{filepath} [get]
 func (h *MigrationsHandler) GetFileContent(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
@@ -492,7 +983,7 @@ func (h *MigrationsHandler) GetFileContent(c *gin.Context) {
 
 	// Verify user owns this migration
 	var migration models.Migration
-	err = db.DB.Get(&migration, "SELECT id FROM migrations WHERE id = $1 AND user_id = $2", id, userID)
+	err = h.db.Get(&migration, "SELECT id FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
@@ -503,7 +994,11 @@ func (h *MigrationsHandler) GetFileContent(c *gin.Context) {
 	}
 
 	// Get file content from AI service
-	aiClient := aiservice.GetClient()
+	aiClient, err := migrationAIClient(h.db, id)
+	if err != nil {
+		aiClientError(c, err)
+		return
+	}
 	if aiClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not available"})
 		return
@@ -515,24 +1010,38 @@ func (h *MigrationsHandler) GetFileContent(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, content)
+	redacted, findings := security.RedactSecrets(content.Path, content.Content)
+	if len(findings) > 0 {
+		logGeneratedSecrets(c, "generated_secret_redacted", id, findings, false)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"path":             content.Path,
+		"content":          redacted,
+		"size":             len(redacted),
+		"secrets_redacted": findings,
+	})
 }
 
-// DownloadProject returns the URL to download the dbt project
+// DownloadProject streams the dbt project as a ZIP archive
 // @Summary Download dbt project
-// @Description Get download URL for the completed dbt project as ZIP
+// @Description Download the completed dbt project as ZIP. The archive is scanned for embedded credentials, API keys and connection strings first; if any are found the download is refused with 422 and a report of where they are.
 // @Tags migrations
 // @Accept json
-// @Produce json
+// @Produce application/zip
 // @Security BearerAuth
 // @Param id path int true "Migration ID"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {file} binary
+// @Header 200 {string} X-Manifest-SHA256 "Checksum of the run's file manifest"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Failure 502 {object} map[string]string
 // @Failure 503 {object} map[string]string
 // @Router /migrations/{id}/download [get]
 func (h *MigrationsHandler) DownloadProject(c *gin.Context) {
 	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
@@ -541,7 +1050,7 @@ func (h *MigrationsHandler) DownloadProject(c *gin.Context) {
 
 	// Verify user owns this migration
 	var migration models.Migration
-	err = db.DB.Get(&migration, "SELECT id, status FROM migrations WHERE id = $1 AND user_id = $2", id, userID)
+	err = h.db.Get(&migration, "SELECT id, status FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
@@ -556,21 +1065,167 @@ func (h *MigrationsHandler) DownloadProject(c *gin.Context) {
 		return
 	}
 
-	// Get download URL from AI service
-	aiClient := aiservice.GetClient()
+	aiClient, err := migrationAIClient(h.db, id)
+	if err != nil {
+		aiClientError(c, err)
+		return
+	}
 	if aiClient == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not available"})
 		return
 	}
 
-	downloadURL := aiClient.GetMigrationDownloadURL(id)
+	manifest, _, err := loadFileManifest(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// The archive is spooled to disk so it can be scanned before any of it is sent
+	archive, size, err := spoolProjectArchive(aiClient, id)
+	if err != nil {
+		log.Printf("Failed to fetch project archive of migration %d: %v", id, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch the project archive"})
+		return
+	}
+	defer func() {
+		archive.Close()
+		os.Remove(archive.Name())
+	}()
+
+	zr, err := zip.NewReader(archive, size)
+	if err != nil {
+		log.Printf("Project archive of migration %d is not a valid ZIP: %v", id, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch the project archive"})
+		return
+	}
+
+	// The archive can't be redacted, so a project with embedded credentials isn't handed out
+	findings, err := scanArchiveSecrets(zr)
+	if err != nil {
+		log.Printf("Failed to scan migration %d for secrets: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan project files for secrets"})
+		return
+	}
+	if len(findings) > 0 {
+		logGeneratedSecrets(c, "generated_secret_blocked", id, findings, true)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "The generated project contains embedded credentials. Replace them with env_var() references before downloading.",
+			"secrets": findings,
+		})
+		return
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read the project archive"})
+		return
+	}
+	headers := map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=migration-%d.zip", id),
+	}
+	// Lets the client check the extracted files against GET /migrations/:id/files
+	if manifest != nil {
+		headers["X-Manifest-SHA256"] = manifest.SHA256
+	}
+	c.DataFromReader(http.StatusOK, size, "application/zip", archive, headers)
+}
+
+// spoolProjectArchive copies a migration's project archive from the AI service to a
+// temporary file; the caller closes and removes it
+func spoolProjectArchive(aiClient *aiservice.Client, migrationID int64) (*os.File, int64, error) {
+	body, err := aiClient.DownloadMigrationProject(migrationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer body.Close()
+
+	f, err := os.CreateTemp("", fmt.Sprintf("migration-%d-*.zip", migrationID))
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(f, body)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, err
+	}
+	return f, size, nil
+}
+
+// GetDocsOverview renders the landing page of the migration's dbt docs site
+// @Summary Get dbt docs landing page
+// @Description Render the __overview__ docs block for models/overview.md, branded with the organization's logo and colors
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/docs/overview [get]
+func (h *MigrationsHandler) GetDocsOverview(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var migration docsMigration
+	err = h.db.Get(&migration, `
+		SELECT m.name, COALESCE(m.source_database, '') AS source_database,
+		       COALESCE(m.tables_count, 0) AS tables_count, COALESCE(m.views_count, 0) AS views_count,
+		       o.id AS organization_id, o.name AS organization_name, o.settings
+		FROM migrations m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN organizations o ON o.id = COALESCE(m.organization_id, u.organization_id)
+		WHERE m.id = $1 AND m.user_id = $2 AND COALESCE(m.organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"download_url": downloadURL,
-		"migration_id": id,
+		"path":     "models/overview.md",
+		"markdown": dbtgen.OverviewDocs(migration.overview()),
 	})
 }
 
+// docsMigration is what the docs landing page shows of a migration and its organization
+type docsMigration struct {
+	Name           string         `db:"name"`
+	SourceDatabase string         `db:"source_database"`
+	TablesCount    int            `db:"tables_count"`
+	ViewsCount     int            `db:"views_count"`
+	OrganizationID sql.NullInt64  `db:"organization_id"`
+	OrgName        sql.NullString `db:"organization_name"`
+	Settings       sql.NullString `db:"settings"`
+}
+
+// overview describes the docs landing page in the organization's branding
+func (m docsMigration) overview() dbtgen.DocsOverview {
+	overview := dbtgen.DocsOverview{
+		MigrationName:  m.Name,
+		SourceDatabase: m.SourceDatabase,
+		TablesCount:    m.TablesCount,
+		ViewsCount:     m.ViewsCount,
+	}
+	if b := emailBranding(m.OrganizationID, m.OrgName, m.Settings); b != nil {
+		overview.OrganizationName = b.Name
+		overview.LogoURL = b.LogoURL
+		overview.PrimaryColor = b.PrimaryColor
+		overview.AccentColor = b.AccentColor
+	}
+	return overview
+}
+
 // UpdateStatus updates migration status (internal endpoint for AI service)
 // @Summary Update migration status (Internal)
 // @Description Internal endpoint for AI service to update migration status
@@ -579,9 +1234,10 @@ func (h *MigrationsHandler) DownloadProject(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Migration ID"
 // @Param request body object true "Status update"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /internal/migrations/{id}/status [patch]
 func (h *MigrationsHandler) UpdateStatus(c *gin.Context) {
@@ -599,12 +1255,24 @@ func (h *MigrationsHandler) UpdateStatus(c *gin.Context) {
 		ViewsCount       *int    `json:"views_count,omitempty"`
 		ForeignKeysCount *int    `json:"foreign_keys_count,omitempty"`
 		ModelsGenerated  *int    `json:"models_generated,omitempty"`
+		// Resource usage of the phases finished since the last update
+		Metrics []models.PhaseMetrics `json:"metrics,omitempty" binding:"dive"`
+		// Progress messages for the run's log
+		Logs []models.MigrationLog `json:"logs,omitempty" binding:"dive"`
+		// Tables whose status changed since the last update
+		Tables []models.MigrationTable `json:"tables,omitempty" binding:"dive"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	for _, m := range req.Metrics {
+		if !phaseNameRegex.MatchString(m.Phase) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid metrics phase: " + m.Phase})
+			return
+		}
+	}
 
 	// Build the update query based on what's provided
 	query := "UPDATE migrations SET status = $1, progress = $2, updated_at = NOW()"
@@ -645,10 +1313,11 @@ func (h *MigrationsHandler) UpdateStatus(c *gin.Context) {
 		query += ", completed_at = NOW()"
 	}
 
-	query += " WHERE id = $" + strconv.Itoa(argIndex)
+	// A cancelled migration keeps its status: the run may report once more while stopping
+	query += " WHERE id = $" + strconv.Itoa(argIndex) + " AND status <> 'cancelled'"
 	args = append(args, id)
 
-	result, err := db.DB.Exec(query, args...)
+	result, err := h.db.Exec(query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update migration status"})
 		return
@@ -656,105 +1325,261 @@ func (h *MigrationsHandler) UpdateStatus(c *gin.Context) {
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
+		var status string
+		if err := h.db.Get(&status, "SELECT status FROM migrations WHERE id = $1", id); err == nil && status == "cancelled" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Migration was cancelled", "cancelled": true})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
 		return
 	}
 
+	if len(req.Metrics) > 0 {
+		if err := recordPhaseMetrics(h.db, id, req.Metrics); err != nil {
+			log.Printf("Failed to record metrics for migration %d: %v", id, err)
+		}
+	}
+	budgetExceeded := false
+	if hasTokens(req.Metrics) {
+		budgetExceeded, err = recordMigrationTokens(h.db, id, req.Metrics)
+		if err != nil {
+			log.Printf("Failed to record AI tokens for migration %d: %v", id, err)
+		}
+	}
+	updateCurrentRun(h.db, id, req.Status, req.Progress, req.Error)
+	if len(req.Logs) > 0 {
+		if err := recordRunLogs(h.db, id, req.Logs); err != nil {
+			log.Printf("Failed to record logs for migration %d: %v", id, err)
+		}
+	}
+	if len(req.Tables) > 0 {
+		if err := recordTableProgress(h.db, id, req.Tables); err != nil {
+			log.Printf("Failed to record table progress for migration %d: %v", id, err)
+		}
+	}
+	publishMigrationStatus(id)
+
 	// Send email notification for completed or failed migrations
 	if req.Status == "completed" || req.Status == "failed" {
-		go sendMigrationEmail(id, req.Status, req.Error)
+		metrics.RecordMigrationOutcome(req.Status)
+		queueMigrationEmail(h.db, id, req.Status, req.Error)
+		go notifyOrganizationChannel(h.db, id, req.Status, req.Error)
+		go syncMigrationTicket(h.db, crypto.GetEncryptionService(), id, req.Status)
+		// Its extraction slot is free for the next queued migration
+		go h.dispatchAfter(id)
+	}
+	if req.Status == "completed" {
+		go indexMigrationFiles(h.db, id)
+		go snapshotRunFiles(h.db, id)
 	}
 
+	if budgetExceeded {
+		// The AI service stops generating once the plan's monthly AI tokens are used up
+		c.JSON(http.StatusOK, gin.H{"message": "Status updated", "token_budget_exceeded": true})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Status updated"})
 }
 
-// sendMigrationEmail sends email notification when migration completes or fails
-func sendMigrationEmail(migrationID int64, status string, errorMsg *string) {
-	// Get migration details and user info
+// queueMigrationEmail queues the completed or failed notification for the migration's
+// owner. Delivery is left to the email outbox worker, so it's cheap enough to run in the
+// status callback.
+func queueMigrationEmail(store db.Querier, migrationID int64, status string, errorMsg *string) {
 	var migration struct {
-		Name        string         `db:"name"`
-		TablesCount int            `db:"tables_count"`
-		CreatedAt   time.Time      `db:"created_at"`
-		CompletedAt sql.NullTime   `db:"completed_at"`
-		UserID      int64          `db:"user_id"`
+		Name        string       `db:"name"`
+		TablesCount int          `db:"tables_count"`
+		CreatedAt   time.Time    `db:"created_at"`
+		CompletedAt sql.NullTime `db:"completed_at"`
+		Email       string       `db:"email"`
+		FirstName   string       `db:"first_name"`
+		Language    string       `db:"preferred_language"`
+		Timezone    string       `db:"timezone"`
+
+		OrganizationID sql.NullInt64  `db:"organization_id"`
+		OrgName        sql.NullString `db:"organization_name"`
+		Settings       sql.NullString `db:"settings"`
 	}
 
-	err := db.DB.Get(&migration, `
-		SELECT name, tables_count, created_at, completed_at, user_id
-		FROM migrations WHERE id = $1
+	err := store.Get(&migration, `
+		SELECT m.name, COALESCE(m.tables_count, 0) AS tables_count, m.created_at, m.completed_at,
+		       u.email, COALESCE(u.first_name, '') AS first_name,
+		       COALESCE(u.preferred_language, 'en') AS preferred_language,
+		       COALESCE(u.timezone, 'UTC') AS timezone,
+		       o.id AS organization_id, o.name AS organization_name, o.settings
+		FROM migrations m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN organizations o ON o.id = COALESCE(m.organization_id, u.organization_id)
+		WHERE m.id = $1
 	`, migrationID)
 	if err != nil {
-		log.Printf("Failed to fetch migration for email notification: %v", err)
+		log.Printf("Failed to fetch migration %d for email notification: %v", migrationID, err)
 		return
 	}
 
-	// Get user info
-	var user struct {
-		Email     string `db:"email"`
-		FirstName string `db:"first_name"`
+	duration, completedAt := "N/A", "N/A"
+	if migration.CompletedAt.Valid {
+		duration = email.FormatDuration(migration.CompletedAt.Time.Sub(migration.CreatedAt), migration.Language)
+		completedAt = email.FormatTime(migration.CompletedAt.Time, migration.Timezone, migration.Language)
 	}
-
-	err = db.DB.Get(&user, `
-		SELECT email, first_name FROM users WHERE id = $1
-	`, migration.UserID)
-	if err != nil {
-		log.Printf("Failed to fetch user for email notification: %v", err)
-		return
+	errMessage := "Unknown error"
+	if errorMsg != nil {
+		errMessage = *errorMsg
 	}
 
-	// Initialize email service
 	emailService := email.NewService()
 	if !emailService.IsConfigured() {
 		// Use mock service for development logging
 		mockService := email.NewMockService()
 		if status == "completed" {
-			duration := "N/A"
-			if migration.CompletedAt.Valid {
-				duration = formatDuration(migration.CompletedAt.Time.Sub(migration.CreatedAt))
-			}
-			mockService.SendMigrationCompleteEmail(user.Email, user.FirstName, migration.Name, migration.TablesCount, duration)
+			mockService.SendMigrationCompleteEmail(migration.Email, migration.FirstName, migration.Name, migration.TablesCount, duration, completedAt, migration.Language)
 		} else {
-			errMessage := "Unknown error"
-			if errorMsg != nil {
-				errMessage = *errorMsg
-			}
-			mockService.SendMigrationFailedEmail(user.Email, user.FirstName, migration.Name, errMessage)
+			mockService.SendMigrationFailedEmail(migration.Email, migration.FirstName, migration.Name, errMessage, migration.Language)
 		}
 		return
 	}
+	emailService = emailService.WithBranding(emailBranding(migration.OrganizationID, migration.OrgName, migration.Settings)).
+		WithFrontendURL(domains.BaseURL(migration.OrganizationID.Int64))
 
-	// Send actual email
 	if status == "completed" {
-		duration := "N/A"
-		if migration.CompletedAt.Valid {
-			duration = formatDuration(migration.CompletedAt.Time.Sub(migration.CreatedAt))
-		}
-		err = emailService.SendMigrationCompleteEmail(user.Email, user.FirstName, migration.Name, migration.TablesCount, duration)
+		err = emailService.SendMigrationCompleteEmail(migration.Email, migration.FirstName, migration.Name, migration.TablesCount, duration, completedAt, migration.Language)
 	} else {
-		errMessage := "Unknown error"
-		if errorMsg != nil {
-			errMessage = *errorMsg
-		}
-		err = emailService.SendMigrationFailedEmail(user.Email, user.FirstName, migration.Name, errMessage)
+		err = emailService.SendMigrationFailedEmail(migration.Email, migration.FirstName, migration.Name, errMessage, migration.Language)
 	}
 
 	if err != nil {
-		log.Printf("Failed to send migration %s email: %v", status, err)
+		log.Printf("Failed to queue migration %s email: %v", status, err)
 	} else {
-		log.Printf("Sent migration %s email to %s for migration '%s'", status, user.Email, migration.Name)
+		log.Printf("Queued migration %s email for %s for migration '%s'", status, migration.Email, migration.Name)
+	}
+}
+
+// storeDependencyGraph extracts the source database's foreign keys and view
+// dependencies and stores the graph on the migration. Generation goes ahead without
+// it (nil) if extraction fails.
+func storeDependencyGraph(store db.Querier, migrationID int64, params dbtest.ConnectionParams) *dbtest.DependencyGraph {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	graph, metadata := dbtest.ExtractDependencies(ctx, params)
+	if !metadata.Success {
+		log.Printf("Failed to extract dependencies for migration %d: %s", migrationID, metadata.Error)
+		return nil
+	}
+	if metadata.Partial {
+		log.Printf("Dependency extraction for migration %d was partial: %v", migrationID, metadata.Warnings)
+	}
+
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return &graph
 	}
+	if _, err := store.Exec(`
+		UPDATE migrations SET dependency_graph = $1, foreign_keys_count = $2, updated_at = NOW()
+		WHERE id = $3
+	`, string(data), len(metadata.ForeignKeys), migrationID); err != nil {
+		log.Printf("Failed to store dependency graph for migration %d: %v", migrationID, err)
+	}
+	return &graph
 }
 
-// formatDuration formats a duration into a human-readable string
-func formatDuration(d time.Duration) string {
-	if d < time.Minute {
-		return fmt.Sprintf("%d seconds", int(d.Seconds()))
-	} else if d < time.Hour {
-		mins := int(d.Minutes())
-		secs := int(d.Seconds()) % 60
-		return fmt.Sprintf("%d min %d sec", mins, secs)
-	}
-	hours := int(d.Hours())
-	mins := int(d.Minutes()) % 60
-	return fmt.Sprintf("%d hr %d min", hours, mins)
+// recordGenerationInteraction writes a dbt generation request to the AI interaction audit trail.
+// Connection credentials are never included in the recorded prompt.
+func recordGenerationInteraction(userID, orgID, migrationID int64, req aiservice.MigrationRequest, resp *aiservice.MigrationResponse, latency time.Duration, callErr error) {
+	// The payload digest records what was sent without the credentials in it
+	digest, err := req.Digest()
+	if err != nil {
+		log.Printf("Failed to digest AI payload of migration %d: %v", migrationID, err)
+	}
+	promptBody, _ := json.Marshal(map[string]interface{}{
+		"migration_id":   req.MigrationID,
+		"source_type":    req.SourceConnection["type"],
+		"source_db":      req.SourceConnection["database"],
+		"target_project": req.TargetProject,
+		"tables":         req.Tables,
+		"include_views":  req.IncludeViews,
+		"payload":        digest,
+	})
+	prompt := string(promptBody)
+
+	interaction := &security.AIInteraction{
+		InteractionType: "generation",
+		UserID:          &userID,
+		MigrationID:     &migrationID,
+		Prompt:          prompt,
+		LatencyMs:       latency.Milliseconds(),
+		Status:          "success",
+		DataRegion:      req.DataRegion,
+	}
+
+	if orgID > 0 {
+		interaction.OrganizationID = &orgID
+	}
+
+	if resp != nil {
+		interaction.Response = resp.Message
+	}
+
+	if resp != nil && resp.Usage != nil {
+		interaction.PromptTokens = resp.Usage.PromptTokens
+		interaction.CompletionTokens = resp.Usage.CompletionTokens
+	} else {
+		interaction.PromptTokens = security.EstimateTokens(prompt)
+		interaction.CompletionTokens = security.EstimateTokens(interaction.Response)
+		interaction.TokensEstimated = true
+	}
+
+	if callErr != nil {
+		errMsg := callErr.Error()
+		interaction.Status = "error"
+		interaction.Error = &errMsg
+	} else if interaction.OrganizationID != nil {
+		recordUsage(*interaction.OrganizationID, quota.MetricAITokens, int64(interaction.PromptTokens+interaction.CompletionTokens))
+	}
+
+	security.GetAIAuditor().Record(interaction)
+}
+
+// dbtAdapters maps connection types to the dbt adapter generating for them
+var dbtAdapters = map[string]string{
+	"fabric":     "dbt-fabric",
+	"synapse":    "dbt-synapse",
+	"mssql":      "dbt-sqlserver",
+	"postgresql": "dbt-postgres",
+	"snowflake":  "dbt-snowflake",
+	"bigquery":   "dbt-bigquery",
+	"databricks": "dbt-databricks",
+	"redshift":   "dbt-redshift",
+	"spark":      "dbt-spark",
+	"mysql":      "dbt-mysql",
+}
+
+// isTSQLTarget reports whether a warehouse type needs T-SQL-compatible models
+func isTSQLTarget(dbType string) bool {
+	return dbType == "fabric" || dbType == "synapse" || dbType == "mssql"
+}
+
+// loadTargetAdapter describes the target connection for generation
+func loadTargetAdapter(store db.Querier, connectionID, userID, orgID int64) (*aiservice.TargetAdapter, error) {
+	var connection struct {
+		DBType      string  `db:"db_type"`
+		Database    string  `db:"database_name"`
+		ExtraConfig *string `db:"extra_config"`
+	}
+	err := store.Get(&connection, `
+		SELECT db_type, database_name, extra_config FROM database_connections
+		WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3
+	`, connectionID, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	extra := parseExtraConfig(connection.ExtraConfig)
+	return &aiservice.TargetAdapter{
+		Type:      connection.DBType,
+		Adapter:   dbtAdapters[connection.DBType],
+		Database:  connection.Database,
+		TSQL:      isTSQLTarget(connection.DBType),
+		Workspace: extra.Workspace,
+		Lakehouse: extra.Lakehouse,
+	}, nil
 }
@@ -0,0 +1,628 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+var migrationColumns = []string{
+	"id", "name", "status", "progress", "source_database", "target_project", "tables_count",
+	"views_count", "foreign_keys_count", "models_generated", "user_id", "error", "created_at",
+	"completed_at", "updated_at",
+}
+
+func TestMigrationsGetAll(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`SELECT .+ FROM migrations\s+WHERE user_id = \$1 AND COALESCE\(organization_id, 0\) = \$2\s+ORDER BY created_at DESC`).
+		WithArgs(testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(migrationColumns).
+			AddRow(2, "Sales", "running", 40, "AdventureWorks", "sales_dbt", 3, 0, 2, 0, testUserID, nil, now, nil, now).
+			AddRow(1, "Catalog", "completed", 100, "AdventureWorks", "catalog_dbt", 2, 1, 1, 4, testUserID, nil, now, now, now))
+
+	status, body := serve(t, "GET", "/migrations", "/migrations", nil, NewMigrationsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var migrations []models.Migration
+	if err := json.Unmarshal(body, &migrations); err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "Sales" || migrations[1].ModelsGenerated != 4 {
+		t.Errorf("migrations = %+v", migrations)
+	}
+}
+
+func TestMigrationsGetAllEmpty(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migrations`).WithArgs(testUserID, testOrgID).WillReturnRows(sqlmock.NewRows(migrationColumns))
+
+	status, body := serve(t, "GET", "/migrations", "/migrations", nil, NewMigrationsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusOK, body)
+	if string(body) != "[]" {
+		t.Errorf("body = %s, want []", body)
+	}
+}
+
+func TestMigrationsGetAllDatabaseError(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migrations`).WithArgs(testUserID, testOrgID).WillReturnError(errors.New("connection reset"))
+
+	status, body := serve(t, "GET", "/migrations", "/migrations", nil, NewMigrationsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusInternalServerError, body)
+	if msg := errorMessage(t, body); msg != "Failed to fetch migrations" {
+		t.Errorf("error = %q", msg)
+	}
+}
+
+func TestMigrationsGetOne(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`FROM migrations\s+WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows(append(migrationColumns, "config")).
+			AddRow(7, "Sales", "failed", 30, "AdventureWorks", "sales_dbt", 3, 0, 2, 0, testUserID, "login timeout", now, nil, now, `{"tables":["Sales.Customer"]}`))
+
+	status, body := serve(t, "GET", "/migrations/:id", "/migrations/7", nil, NewMigrationsHandler(store).GetOne)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var migration models.Migration
+	if err := json.Unmarshal(body, &migration); err != nil {
+		t.Fatal(err)
+	}
+	if migration.ID != 7 || migration.Error == nil || *migration.Error != "login timeout" {
+		t.Errorf("migration = %+v", migration)
+	}
+}
+
+func TestMigrationsGetOneNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migrations`).WithArgs(int64(7), testUserID, testOrgID).WillReturnError(sql.ErrNoRows)
+
+	status, body := serve(t, "GET", "/migrations/:id", "/migrations/7", nil, NewMigrationsHandler(store).GetOne)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+// A migration the user created in another organization isn't found from the active one
+func TestMigrationsOtherOrganizationNotFound(t *testing.T) {
+	for _, tc := range []struct {
+		method, route, path string
+		handler             func(*MigrationsHandler, *gin.Context)
+	}{
+		{"GET", "/migrations/:id", "/migrations/7", (*MigrationsHandler).GetOne},
+		{"DELETE", "/migrations/:id", "/migrations/7", (*MigrationsHandler).Delete},
+		{"POST", "/migrations/:id/start", "/migrations/7/start", (*MigrationsHandler).Start},
+		{"GET", "/migrations/:id/files", "/migrations/7/files", (*MigrationsHandler).GetFiles},
+		{"GET", "/migrations/:id/status", "/migrations/7/status", (*MigrationsHandler).GetStatus},
+		{"GET", "/migrations/:id/runs", "/migrations/7/runs", (*MigrationsHandler).GetRuns},
+	} {
+		t.Run(tc.method+" "+tc.route, func(t *testing.T) {
+			store, mock := newMockDB(t)
+			mock.ExpectQuery(`FROM migrations\s+WHERE id = \$1 AND user_id = \$2 AND COALESCE\(organization_id, 0\) = \$3`).
+				WithArgs(int64(7), testUserID, testOrgID).
+				WillReturnError(sql.ErrNoRows)
+
+			h := NewMigrationsHandler(store)
+			status, body := serve(t, tc.method, tc.route, tc.path, nil, func(c *gin.Context) { tc.handler(h, c) })
+			expectStatus(t, status, http.StatusNotFound, body)
+		})
+	}
+}
+
+func TestMigrationsGetOneInvalidID(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/migrations/:id", "/migrations/abc", nil, NewMigrationsHandler(store).GetOne)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestMigrationsCreate(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	expectSourceConnection(mock, "AdventureWorks", 4)
+	expectNoDuplicateMigration(mock, 4)
+	mock.ExpectQuery(`INSERT INTO migrations`).
+		WithArgs("Sales migration", int64(4), "AdventureWorks", "sales_dbt", 2, testUserID, testOrgID, `{"tables":["Sales.Customer","Sales.SalesOrderHeader"],"snapshots":[{"table":"Sales.Customer","unique_key":["CustomerID"],"strategy":"timestamp","updated_at":"ModifiedDate"}]}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectQuery(`FROM migrations WHERE id = \$1`).
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "progress", "source_database", "target_project", "tables_count", "user_id", "created_at", "updated_at"}).
+			AddRow(9, "Sales migration", "pending", 0, "AdventureWorks", "sales_dbt", 2, testUserID, now, now))
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":            "Sales migration",
+		"source_database": "AdventureWorks",
+		"target_project":  "sales_dbt",
+		"tables":          []string{"Sales.Customer", "Sales.SalesOrderHeader"},
+		"snapshots": []map[string]interface{}{
+			{"table": "Sales.Customer", "unique_key": []string{"CustomerID"}, "updated_at": "ModifiedDate"},
+		},
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusCreated, body)
+
+	var migration models.Migration
+	if err := json.Unmarshal(body, &migration); err != nil {
+		t.Fatal(err)
+	}
+	if migration.ID != 9 || migration.Status != "pending" {
+		t.Errorf("migration = %+v", migration)
+	}
+}
+
+func TestMigrationsCreateDefaultsTableCount(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectSourceConnection(mock, "AdventureWorks", 4)
+	expectNoDuplicateMigration(mock, 4)
+	mock.ExpectQuery(`INSERT INTO migrations`).
+		WithArgs("All tables", int64(4), "AdventureWorks", "aw_dbt", 1, testUserID, testOrgID, `{}`).
+		WillReturnError(errors.New("insert failed"))
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":            "All tables",
+		"source_database": "AdventureWorks",
+		"target_project":  "aw_dbt",
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusInternalServerError, body)
+}
+
+func expectSourceConnection(mock sqlmock.Sqlmock, name string, id int64) {
+	mock.ExpectQuery(`FROM database_connections WHERE name = \$1`).
+		WithArgs(name, testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id"}).AddRow(id, name, testOrgID))
+}
+
+func expectNoDuplicateMigration(mock sqlmock.Sqlmock, connectionID int64) {
+	mock.ExpectQuery(`SELECT id, name, status, created_at, COALESCE\(config->'tables'`).
+		WithArgs(testUserID, testOrgID, connectionID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "created_at", "tables"}))
+}
+
+func TestMigrationsCreateDuplicate(t *testing.T) {
+	store, mock := newMockDB(t)
+	created := time.Now().Add(-time.Hour)
+	expectSourceConnection(mock, "AdventureWorks", 4)
+	mock.ExpectQuery(`SELECT id, name, status, created_at, COALESCE\(config->'tables'`).
+		WithArgs(testUserID, testOrgID, int64(4), "Sales migration", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "created_at", "tables"}).
+			AddRow(8, "Sales Migration", "completed", created, `["Sales.Customer"]`).
+			AddRow(6, "sales migration", "running", created, `["sales.salesorderheader", "Sales.Customer"]`))
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":            "Sales migration",
+		"source_database": "AdventureWorks",
+		"target_project":  "sales_dbt",
+		"tables":          []string{"Sales.Customer", "Sales.SalesOrderHeader"},
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusConflict, body)
+
+	var resp struct {
+		DuplicateOf models.DuplicateMigration `json:"duplicate_of"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.DuplicateOf.ID != 6 || resp.DuplicateOf.Status != "running" {
+		t.Errorf("duplicate_of = %+v, want migration 6 with the same tables", resp.DuplicateOf)
+	}
+}
+
+func TestMigrationsCreateForceSkipsDuplicateCheck(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectSourceConnection(mock, "AdventureWorks", 4)
+	mock.ExpectQuery(`INSERT INTO migrations`).
+		WithArgs("All tables", int64(4), "AdventureWorks", "aw_dbt", 1, testUserID, testOrgID, `{}`).
+		WillReturnError(errors.New("insert failed"))
+
+	status, body := serve(t, "POST", "/migrations", "/migrations?force=true", map[string]interface{}{
+		"name":            "All tables",
+		"source_database": "AdventureWorks",
+		"target_project":  "aw_dbt",
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusInternalServerError, body)
+}
+
+func TestMigrationsCreateByConnectionID(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections WHERE id = \$1`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id"}).AddRow(4, "AdventureWorks", testOrgID))
+	expectNoDuplicateMigration(mock, 4)
+	mock.ExpectQuery(`INSERT INTO migrations`).
+		WithArgs("All tables", int64(4), "AdventureWorks", "aw_dbt", 1, testUserID, testOrgID, `{}`).
+		WillReturnError(errors.New("insert failed"))
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":           "All tables",
+		"connection_id":  4,
+		"target_project": "aw_dbt",
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusInternalServerError, body)
+}
+
+func TestMigrationsCreateUnknownConnection(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM database_connections WHERE id = \$1`).
+		WithArgs(int64(4), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":           "All tables",
+		"connection_id":  4,
+		"target_project": "aw_dbt",
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestMigrationsCreateValidation(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name": "ab",
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestMigrationsCreateRejectsInvalidSnapshot(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":            "Sales migration",
+		"source_database": "AdventureWorks",
+		"target_project":  "sales_dbt",
+		"tables":          []string{"Sales.Customer"},
+		"snapshots": []map[string]interface{}{
+			{"table": "Sales.Customer", "unique_key": []string{"CustomerID"}},
+		},
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if !strings.Contains(string(body), "updated_at") {
+		t.Errorf("body = %s, want an updated_at error", body)
+	}
+}
+
+func TestMigrationsCreateWithTableFilters(t *testing.T) {
+	store, mock := newMockDB(t)
+	expectSourceConnection(mock, "AdventureWorks", 4)
+	expectNoDuplicateMigration(mock, 4)
+	mock.ExpectQuery(`INSERT INTO migrations`).
+		WithArgs("Sales migration", int64(4), "AdventureWorks", "sales_dbt", 1, testUserID, testOrgID, `{"tables":["Sales.Customer"],"table_filters":[{"table":"Sales.Customer","where":"IsDeleted = 0 AND [LastUpdate] \u003e= '2020-01-01'","exclude_columns":["SSN"]}]}`).
+		WillReturnError(errors.New("insert failed"))
+
+	status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+		"name":            "Sales migration",
+		"source_database": "AdventureWorks",
+		"target_project":  "sales_dbt",
+		"tables":          []string{"Sales.Customer"},
+		"table_filters": []map[string]interface{}{
+			{"table": " Sales.Customer ", "where": " IsDeleted = 0 AND [LastUpdate] >= '2020-01-01' ", "exclude_columns": []string{"SSN", " "}},
+		},
+	}, NewMigrationsHandler(store).Create)
+	expectStatus(t, status, http.StatusInternalServerError, body)
+}
+
+func TestMigrationsCreateRejectsUnsafeTableFilters(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	for _, tc := range []struct {
+		filter map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"table": "Sales.Customer", "where": "1 = 1; DROP TABLE Sales.Customer"}, "table_filters[0].where"},
+		{map[string]interface{}{"table": "Sales.Customer", "where": "IsDeleted = 0 OR 1=1"}, "injection"},
+		{map[string]interface{}{"table": "Sales.Customer", "where": "Name = '{{ var(\"x\") }}'"}, "{{"},
+		{map[string]interface{}{"table": "Sales.Customer", "where": "Name = 'O''Brien"}, "unterminated"},
+		{map[string]interface{}{"table": "Sales.Customer", "where": "(IsDeleted = 0"}, "unclosed parenthesis"},
+		{map[string]interface{}{"table": "Sales.Order", "where": "IsDeleted = 0"}, "not one of the selected tables"},
+		{map[string]interface{}{"table": "Sales.Customer", "exclude_columns": []string{"SSN;"}}, "not a valid column name"},
+		{map[string]interface{}{"table": "Sales.Customer"}, "needs a where clause or columns to exclude"},
+	} {
+		status, body := serve(t, "POST", "/migrations", "/migrations", map[string]interface{}{
+			"name":            "Sales migration",
+			"source_database": "AdventureWorks",
+			"target_project":  "sales_dbt",
+			"tables":          []string{"Sales.Customer"},
+			"table_filters":   []map[string]interface{}{tc.filter},
+		}, NewMigrationsHandler(store).Create)
+		expectStatus(t, status, http.StatusBadRequest, body)
+		if !strings.Contains(string(body), tc.want) {
+			t.Errorf("filter %v: body = %s, want %q", tc.filter, body, tc.want)
+		}
+	}
+}
+
+func TestMigrationsPreviewSnapshots(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "POST", "/migrations/snapshots/preview", "/migrations/snapshots/preview", map[string]interface{}{
+		"tables": []string{"Sales.Customer", "Sales.SalesOrderDetail"},
+		"snapshots": []map[string]interface{}{
+			{"table": "Sales.Customer", "unique_key": []string{"CustomerID"}, "updated_at": "ModifiedDate", "hard_deletes": "invalidate"},
+			{"table": "Sales.SalesOrderDetail", "unique_key": []string{"SalesOrderID", "SalesOrderDetailID"}, "strategy": "check", "check_cols": []string{"all"}},
+		},
+	}, NewMigrationsHandler(store).PreviewSnapshots)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		YAML string `json:"yaml"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := `snapshots:
+  - name: customer_snapshot
+    relation: source('mssql_source', 'Customer')
+    config:
+      schema: snapshots
+      unique_key: CustomerID
+      strategy: timestamp
+      updated_at: ModifiedDate
+      hard_deletes: invalidate
+  - name: salesorderdetail_snapshot
+    relation: source('mssql_source', 'SalesOrderDetail')
+    config:
+      schema: snapshots
+      unique_key:
+        - SalesOrderID
+        - SalesOrderDetailID
+      strategy: check
+      check_cols: all
+`
+	if resp.YAML != want {
+		t.Errorf("yaml =\n%s\nwant\n%s", resp.YAML, want)
+	}
+}
+
+func TestMigrationsDelete(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT status FROM migrations`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("completed"))
+	mock.ExpectExec(`DELETE FROM migrations WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body := serve(t, "DELETE", "/migrations/:id", "/migrations/3", nil, NewMigrationsHandler(store).Delete)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestMigrationsDeleteRunning(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT status FROM migrations`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("running"))
+
+	status, body := serve(t, "DELETE", "/migrations/:id", "/migrations/3", nil, NewMigrationsHandler(store).Delete)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if msg := errorMessage(t, body); msg != "Cannot delete a running migration" {
+		t.Errorf("error = %q", msg)
+	}
+}
+
+func TestMigrationsStop(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`UPDATE migrations SET status = 'cancelled'.+AND status = 'running'\s+RETURNING progress`).
+		WithArgs(int64(5), testUserID, "Cancelled by user", testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"progress"}).AddRow(40))
+	mock.ExpectQuery(`SELECT COALESCE\(data_region, ''\) FROM migrations`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"data_region"}).AddRow(""))
+	mock.ExpectExec(`UPDATE migration_runs`).
+		WithArgs(int64(5), "cancelled", 40, "Cancelled by user").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body := serve(t, "POST", "/migrations/:id/stop", "/migrations/5/stop", nil, NewMigrationsHandler(store).Stop)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Status       string `json:"status"`
+		Acknowledged bool   `json:"acknowledged"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "cancelled" || resp.Acknowledged {
+		t.Errorf("response = %s, want cancelled without an acknowledgment from a missing AI service", body)
+	}
+}
+
+func TestMigrationsStopNotRunning(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`UPDATE migrations SET status = 'cancelled'`).
+		WithArgs(int64(5), testUserID, "Cancelled by user", testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"progress"}))
+	mock.ExpectExec(`UPDATE migrations SET status = 'pending', queued_at = NULL, queue_reason = NULL`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	status, body := serve(t, "POST", "/migrations/:id/stop", "/migrations/5/stop", nil, NewMigrationsHandler(store).Stop)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestMigrationsStopQueued(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`UPDATE migrations SET status = 'cancelled'`).
+		WithArgs(int64(5), testUserID, "Cancelled by user", testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"progress"}))
+	mock.ExpectExec(`UPDATE migrations SET status = 'pending', queued_at = NULL, queue_reason = NULL`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body := serve(t, "POST", "/migrations/:id/stop", "/migrations/5/stop", nil, NewMigrationsHandler(store).Stop)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestMigrationsStartNotPending(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT id, connection_id, source_database, target_project, config, status`).
+		WithArgs(int64(5), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "source_database", "target_project", "config", "status", "tables_count"}).
+			AddRow(5, "AdventureWorks", "aw_dbt", nil, "running", 3))
+
+	status, body := serve(t, "POST", "/migrations/:id/start", "/migrations/5/start", nil, NewMigrationsHandler(store).Start)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if msg := errorMessage(t, body); msg != "Migration is not in pending status" {
+		t.Errorf("error = %q", msg)
+	}
+}
+
+func TestMigrationsGetStats(t *testing.T) {
+	store, mock := newMockDB(t)
+	for _, count := range []int{12, 9, 2, 1, 0} {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM migrations WHERE user_id = \$1`).
+			WithArgs(testUserID, testOrgID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+	}
+
+	status, body := serve(t, "GET", "/stats", "/stats", nil, NewMigrationsHandler(store).GetStats)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var stats models.DashboardStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalMigrations != 12 || stats.CompletedMigrations != 9 || stats.RunningMigrations != 2 || stats.FailedMigrations != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestMigrationsUpdateStatus(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE migrations SET status = \$1, progress = \$2, updated_at = NOW\(\), models_generated = \$3 WHERE id = \$4 AND status <> 'cancelled'`).
+		WithArgs("running", 60, 3, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE migration_runs\s+SET status = \$2, progress = \$3`).
+		WithArgs(int64(8), "running", 60, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":           "running",
+		"progress":         60,
+		"models_generated": 3,
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestMigrationsUpdateStatusNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE migrations SET status = \$1, progress = \$2`).
+		WithArgs("running", 10, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM migrations WHERE id = \$1`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":   "running",
+		"progress": 10,
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestInternalRoutesRequireServiceToken(t *testing.T) {
+	store, _ := newMockDB(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerInternalRoutes(router.Group("/api/v1/internal"), NewMigrationsHandler(store), security.RequireServiceToken("s3cret"))
+
+	for _, route := range []struct{ method, path string }{
+		{"PATCH", "/api/v1/internal/migrations/8/status"},
+		{"POST", "/api/v1/internal/ai-usage"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, strings.NewReader(`{"status":"completed","progress":100}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without the service token: status %d, want %d", route.method, route.path, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestMigrationsUpdateStatusAfterCancel(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE migrations SET status = \$1, progress = \$2, updated_at = NOW\(\), completed_at = NOW\(\) WHERE id = \$3 AND status <> 'cancelled'`).
+		WithArgs("completed", 100, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT status FROM migrations WHERE id = \$1`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("cancelled"))
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":   "completed",
+		"progress": 100,
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusConflict, body)
+}
+
+func TestMigrationsUpdateStatusRecordsMetricsAndLogs(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`UPDATE migrations SET status = \$1, progress = \$2`).
+		WithArgs("running", 30, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO migration_metrics`).
+		WithArgs(int64(8), "extracting_metadata", int64(4200), int64(0), int64(0), 12, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE migration_runs`).
+		WithArgs(int64(8), "running", 30, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO migration_logs`).
+		WithArgs(int64(8), "info", "Extracted 12 tables").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":   "running",
+		"progress": 30,
+		"metrics": []map[string]interface{}{
+			{"phase": "extracting_metadata", "duration_ms": 4200, "tables_processed": 12},
+		},
+		"logs": []map[string]interface{}{
+			{"level": "info", "message": "Extracted 12 tables"},
+		},
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusOK, body)
+}
+
+func TestMigrationsUpdateStatusRejectsBadPhase(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "PATCH", "/internal/migrations/:id/status", "/internal/migrations/8/status", map[string]interface{}{
+		"status":   "running",
+		"progress": 30,
+		"metrics":  []map[string]interface{}{{"phase": "Extract; DROP"}},
+	}, NewMigrationsHandler(store).UpdateStatus)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
+
+func TestMigrationsGetMetrics(t *testing.T) {
+	store, mock := newMockDB(t)
+	now := time.Now()
+	mock.ExpectQuery(`SELECT status, COALESCE\(tables_count, 0\)`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "tables_count"}).AddRow("completed", 40))
+	mock.ExpectQuery(`FROM migration_metrics`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"phase", "duration_ms", "prompt_tokens", "completion_tokens", "tables_processed", "lines_generated", "updated_at"}).
+			AddRow("extracting_metadata", 4000, 0, 0, 40, 0, now).
+			AddRow("generating_dbt_project", 16000, 12000, 3000, 40, 2150, now))
+
+	status, body := serve(t, "GET", "/migrations/:id/metrics", "/migrations/8/metrics", nil, NewMigrationsHandler(store).GetMetrics)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var metrics models.MigrationMetrics
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		t.Fatal(err)
+	}
+	if metrics.DurationMs != 20000 || metrics.TotalTokens != 15000 || metrics.LinesGenerated != 2150 || metrics.TablesPerSecond != 2 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+var notificationClient = &http.Client{Timeout: 10 * time.Second}
+
+// migrationNotification is the payload posted to generic webhook channels
+type migrationNotification struct {
+	Event         string                `json:"event"` // migration.completed, migration.failed, migration.queued, migration.started or migration.drift
+	MigrationID   int64                 `json:"migration_id"`
+	MigrationName string                `json:"migration_name"`
+	Status        string                `json:"status"`
+	TablesCount   int                   `json:"tables_count"`
+	Error         string                `json:"error,omitempty"`
+	QueueReason   string                `json:"queue_reason,omitempty"` // Why a queued migration waits
+	Drift         []models.DriftFinding `json:"drift,omitempty"`        // Differences between the models and the warehouse
+	CompletedAt   *time.Time            `json:"completed_at,omitempty"`
+}
+
+// notificationTarget is a migration with the channel its organization is notified on
+type notificationTarget struct {
+	Name           string         `db:"name"`
+	TablesCount    int            `db:"tables_count"`
+	CreatedAt      time.Time      `db:"created_at"`
+	CompletedAt    sql.NullTime   `db:"completed_at"`
+	OrganizationID sql.NullInt64  `db:"organization_id"`
+	OrgName        sql.NullString `db:"organization_name"`
+	Settings       sql.NullString `db:"settings"`
+
+	channel *models.NotificationChannel
+}
+
+// loadNotificationTarget loads a migration for notifying its organization, returning
+// nil when the organization has no notification channel
+func loadNotificationTarget(store db.Querier, migrationID int64) *notificationTarget {
+	var migration notificationTarget
+	err := store.Get(&migration, `
+		SELECT m.name, COALESCE(m.tables_count, 0) AS tables_count, m.created_at, m.completed_at,
+		       o.id AS organization_id, o.name AS organization_name, o.settings
+		FROM migrations m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN organizations o ON o.id = COALESCE(m.organization_id, u.organization_id)
+		WHERE m.id = $1
+	`, migrationID)
+	if err != nil {
+		log.Printf("Failed to fetch migration %d for notification: %v", migrationID, err)
+		return nil
+	}
+	if !migration.OrganizationID.Valid {
+		return nil
+	}
+	migration.channel = parseOrganizationDefaults(migration.Settings).NotificationChannel
+	if migration.channel == nil {
+		return nil
+	}
+	return &migration
+}
+
+// notifyOrganizationChannel tells the migration's organization about a completed or
+// failed migration on the channel configured in its settings
+func notifyOrganizationChannel(store db.Querier, migrationID int64, status string, errorMsg *string) {
+	migration := loadNotificationTarget(store, migrationID)
+	if migration == nil {
+		return
+	}
+	channel := migration.channel
+	var err error
+
+	errMessage := "Unknown error"
+	if errorMsg != nil {
+		errMessage = *errorMsg
+	}
+	duration, completedAt := "N/A", "N/A"
+	if migration.CompletedAt.Valid {
+		duration = email.FormatDuration(migration.CompletedAt.Time.Sub(migration.CreatedAt), email.DefaultLanguage)
+		completedAt = email.FormatTime(migration.CompletedAt.Time, email.DefaultTimezone, email.DefaultLanguage)
+	}
+
+	switch channel.Type {
+	case "email":
+		emailService := email.NewService()
+		if !emailService.IsConfigured() {
+			log.Printf("Email not configured, skipping organization notification for migration %d", migrationID)
+			return
+		}
+		emailService = emailService.WithBranding(emailBranding(migration.OrganizationID, migration.OrgName, migration.Settings)).
+			WithFrontendURL(domains.BaseURL(migration.OrganizationID.Int64))
+		if status == "completed" {
+			err = emailService.SendMigrationCompleteEmail(channel.Target, migration.OrgName.String, migration.Name, migration.TablesCount, duration, completedAt, "en")
+		} else {
+			err = emailService.SendMigrationFailedEmail(channel.Target, migration.OrgName.String, migration.Name, errMessage, "en")
+		}
+	case "slack", "teams":
+		// Both incoming webhook types accept a plain text message
+		text := fmt.Sprintf("Migration *%s* completed: %d tables in %s", migration.Name, migration.TablesCount, duration)
+		if status != "completed" {
+			text = fmt.Sprintf("Migration *%s* failed: %s", migration.Name, errMessage)
+		}
+		err = postNotification(channel, map[string]string{"text": text})
+	default:
+		notification := migrationNotification{
+			Event:         "migration." + status,
+			MigrationID:   migrationID,
+			MigrationName: migration.Name,
+			Status:        status,
+			TablesCount:   migration.TablesCount,
+		}
+		if status != "completed" {
+			notification.Error = errMessage
+		}
+		if migration.CompletedAt.Valid {
+			notification.CompletedAt = &migration.CompletedAt.Time
+		}
+		err = postNotification(channel, notification)
+	}
+
+	if err != nil {
+		log.Printf("Failed to send %s notification for migration %d: %v", channel.Type, migrationID, err)
+	}
+}
+
+// notifyQueueChange tells the migration's organization that a migration was queued, with
+// why, or that a queued migration started. Email channels only get completed and failed
+// migrations.
+func notifyQueueChange(store db.Querier, migrationID int64, reason string) {
+	migration := loadNotificationTarget(store, migrationID)
+	if migration == nil {
+		return
+	}
+	channel := migration.channel
+
+	var err error
+	switch channel.Type {
+	case "email":
+		return
+	case "slack", "teams":
+		text := fmt.Sprintf("Queued migration *%s* started", migration.Name)
+		if reason != "" {
+			text = fmt.Sprintf("Migration *%s* is queued: %s", migration.Name, queueReasonText(reason))
+		}
+		err = postNotification(channel, map[string]string{"text": text})
+	default:
+		notification := migrationNotification{
+			Event:         "migration.started",
+			MigrationID:   migrationID,
+			MigrationName: migration.Name,
+			Status:        "running",
+			TablesCount:   migration.TablesCount,
+		}
+		if reason != "" {
+			notification.Event = "migration.queued"
+			notification.Status = "queued"
+			notification.QueueReason = reason
+		}
+		err = postNotification(channel, notification)
+	}
+
+	if err != nil {
+		log.Printf("Failed to send %s notification for migration %d: %v", channel.Type, migrationID, err)
+	}
+}
+
+// notifyDrift tells the migration's organization that its deployed tables no longer match
+// the generated models. Email channels only get migration results.
+func notifyDrift(store db.Querier, migrationID int64, findings []models.DriftFinding) {
+	migration := loadNotificationTarget(store, migrationID)
+	if migration == nil {
+		return
+	}
+	channel := migration.channel
+
+	var err error
+	switch channel.Type {
+	case "email":
+		return
+	case "slack", "teams":
+		text := fmt.Sprintf("Schema drift in the warehouse of migration *%s*: %s. %s", migration.Name, driftSummary(findings),
+			migrationPageURL(migration.OrganizationID.Int64, migrationID))
+		err = postNotification(channel, map[string]string{"text": text})
+	default:
+		err = postNotification(channel, migrationNotification{
+			Event:         "migration.drift",
+			MigrationID:   migrationID,
+			MigrationName: migration.Name,
+			Status:        "drift",
+			TablesCount:   migration.TablesCount,
+			Drift:         findings,
+		})
+	}
+
+	if err != nil {
+		log.Printf("Failed to send %s drift notification for migration %d: %v", channel.Type, migrationID, err)
+	}
+}
+
+func postNotification(channel *models.NotificationChannel, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := notificationClient.Post(channel.Target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
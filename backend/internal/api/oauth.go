@@ -0,0 +1,355 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/oauth"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// oauthStateTTL is how long a user has to finish signing in at the provider
+const oauthStateTTL = 10 * time.Minute
+
+func hashOAuthState(state string) string {
+	hash := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(hash[:])
+}
+
+// oauthProvider returns the provider named in the route, or responds 404
+func oauthProvider(c *gin.Context) *oauth.Provider {
+	provider := oauth.Get(c.Param("provider"))
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sign-in provider not found or not enabled"})
+	}
+	return provider
+}
+
+// startOAuth stores a new state and PKCE verifier and responds with the provider's
+// authorization URL. linkUserID is set when a signed-in user links an account.
+func startOAuth(c *gin.Context, provider *oauth.Provider, linkUserID *int64) {
+	state, err := oauth.NewState()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+	verifier, err := oauth.NewCodeVerifier()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	// Abandoned sign-ins are removed as new ones start
+	db.DB.Exec("DELETE FROM oauth_states WHERE expires_at < NOW()")
+
+	// Sign-ins started on an organization's custom domain return to it
+	redirectBase := domains.RequestBaseURL(c.Request)
+	if redirectBase != "" {
+		provider = provider.WithRedirectBase(redirectBase)
+	}
+
+	_, err = db.DB.Exec(`
+		INSERT INTO oauth_states (state_hash, provider, code_verifier, link_user_id, redirect_base, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+	`, hashOAuthState(state), provider.Name, verifier, linkUserID, redirectBase, time.Now().Add(oauthStateTTL))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"provider":          provider.Name,
+		"authorization_url": provider.AuthCodeURL(state, oauth.CodeChallenge(verifier)),
+		"expires_in":        int(oauthStateTTL.Seconds()),
+	})
+}
+
+// logIdentityEvent records linking and unlinking of sign-in accounts
+func logIdentityEvent(c *gin.Context, eventType string, userID int64, metadata map[string]interface{}) {
+	security.GetGuardian().LogSecurityEvent(&security.SecurityEvent{
+		EventType:      eventType,
+		Severity:       "info",
+		UserID:         &userID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusOK,
+		Metadata:       metadata,
+	})
+}
+
+// linkIdentity links a provider account to a user. It responds and returns false when the
+// account belongs to another user or the user already linked a different one.
+func linkIdentity(c *gin.Context, userID int64, provider *oauth.Provider, profile *oauth.Profile) bool {
+	_, err := db.DB.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+	`, userID, provider.Name, profile.Subject, profile.Email)
+	if err == nil {
+		return true
+	}
+	if !isUniqueViolation(err) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link account"})
+		return false
+	}
+
+	var ownerID int64
+	err = db.DB.Get(&ownerID, "SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2", provider.Name, profile.Subject)
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusConflict, gin.H{"error": "A different " + provider.DisplayName + " account is already linked. Unlink it first."})
+		return false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link account"})
+		return false
+	case ownerID != userID:
+		c.JSON(http.StatusConflict, gin.H{"error": "This " + provider.DisplayName + " account is linked to another user"})
+		return false
+	}
+	return true
+}
+
+// GetOAuthProviders lists the enabled social login providers
+// @Summary List social login providers
+// @Description List the OAuth providers users can sign in with
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /auth/oauth/providers [get]
+func (h *AuthHandler) GetOAuthProviders(c *gin.Context) {
+	providers := []gin.H{}
+	for _, p := range oauth.Enabled() {
+		providers = append(providers, gin.H{"name": p.Name, "display_name": p.DisplayName})
+	}
+	c.JSON(http.StatusOK, gin.H{"providers": providers})
+}
+
+// StartOAuthLogin begins signing in with a provider
+// @Summary Start social login
+// @Description Create a state and PKCE challenge and return the provider's authorization URL. The provider redirects back to {OAUTH_REDIRECT_BASE_URL}/auth/callback/{provider}.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "google, github or azuread"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /auth/oauth/{provider}/authorize [get]
+func (h *AuthHandler) StartOAuthLogin(c *gin.Context) {
+	provider := oauthProvider(c)
+	if provider == nil {
+		return
+	}
+	startOAuth(c, provider, nil)
+}
+
+// StartOAuthLink begins linking a provider account to the current user
+// @Summary Link a social login account
+// @Description Return the provider's authorization URL. Completing the callback links the account to the current user instead of signing in.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "google, github or azuread"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /auth/oauth/{provider}/link [post]
+func (h *AuthHandler) StartOAuthLink(c *gin.Context) {
+	provider := oauthProvider(c)
+	if provider == nil {
+		return
+	}
+	userID := middleware.GetUserID(c)
+	startOAuth(c, provider, &userID)
+}
+
+// OAuthCallback completes a sign-in or link started with StartOAuthLogin or StartOAuthLink
+// @Summary Complete social login
+// @Description Exchange the authorization code. A known account signs in; otherwise the account is linked to the user with the same verified email. Linking flows respond with the linked provider instead of a token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "google, github or azuread"
+// @Param request body models.OAuthCallbackRequest true "Code and state from the redirect"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /auth/oauth/{provider}/callback [post]
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	provider := oauthProvider(c)
+	if provider == nil {
+		return
+	}
+
+	var req models.OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Each state is used once, by the provider it was created for
+	var pending struct {
+		CodeVerifier string         `db:"code_verifier"`
+		LinkUserID   sql.NullInt64  `db:"link_user_id"`
+		RedirectBase sql.NullString `db:"redirect_base"`
+		ExpiresAt    time.Time      `db:"expires_at"`
+	}
+	err := db.DB.Get(&pending, `
+		DELETE FROM oauth_states WHERE state_hash = $1 AND provider = $2
+		RETURNING code_verifier, link_user_id, redirect_base, expires_at
+	`, hashOAuthState(req.State), provider.Name)
+	if err != nil || time.Now().After(pending.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sign-in link is invalid or has expired. Please try again."})
+		return
+	}
+	// The code is only redeemed with the redirect URI it was issued for
+	if pending.RedirectBase.Valid {
+		provider = provider.WithRedirectBase(pending.RedirectBase.String)
+	}
+
+	profile, err := provider.Exchange(c.Request.Context(), req.Code, pending.CodeVerifier)
+	if err != nil {
+		log.Printf("OAuth %s sign-in failed: %v", provider.Name, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign-in with " + provider.DisplayName + " failed"})
+		return
+	}
+
+	if pending.LinkUserID.Valid {
+		if !linkIdentity(c, pending.LinkUserID.Int64, provider, profile) {
+			return
+		}
+		logIdentityEvent(c, "oauth_identity_linked", pending.LinkUserID.Int64, map[string]interface{}{
+			"provider": provider.Name,
+			"email":    profile.Email,
+		})
+		c.JSON(http.StatusOK, gin.H{"message": provider.DisplayName + " account linked", "provider": provider.Name, "linked": true})
+		return
+	}
+
+	var userID int64
+	err = db.DB.Get(&userID, "SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2", provider.Name, profile.Subject)
+	if err == sql.ErrNoRows {
+		// First sign-in with this account: link it to the user who owns the verified email
+		if !profile.EmailVerified || profile.Email == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": provider.DisplayName + " did not confirm your email address. Sign in with your password and link " + provider.DisplayName + " from your profile."})
+			return
+		}
+		err = db.DB.Get(&userID, "SELECT id FROM users WHERE LOWER(email) = $1 AND COALESCE(account_type, 'human') = 'human'", profile.Email)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "No account uses " + profile.Email + ". Register or accept an invitation first."})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if !linkIdentity(c, userID, provider, profile) {
+			return
+		}
+		logIdentityEvent(c, "oauth_identity_linked", userID, map[string]interface{}{
+			"provider":      provider.Name,
+			"email":         profile.Email,
+			"matched_email": true,
+		})
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var user models.User
+	err = db.DB.Get(&user, `
+		SELECT id, email, password, first_name, last_name, job_title, phone,
+		       organization_id, role, is_admin, is_active, last_login_at, created_at, updated_at
+		FROM users WHERE id = $1 AND COALESCE(account_type, 'human') = 'human'`, userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+	if !user.IsActive {
+		log.Printf("OAuth login attempt for deactivated account: %s from IP: %s", user.Email, c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+		return
+	}
+
+	// A locked account stays locked whichever way the user signs in
+	lockoutStatus := security.GetAccountLockout().IsLocked(user.Email)
+	if lockoutStatus.Locked && lockoutClearedByAdmin(user.Email) {
+		lockoutStatus = security.GetAccountLockout().IsLocked(user.Email)
+	}
+	if lockoutStatus.Locked {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":           lockoutStatus.Message,
+			"locked":          true,
+			"retry_after_sec": int(lockoutStatus.RemainingTime.Seconds()),
+		})
+		return
+	}
+
+	db.DB.Exec(`
+		UPDATE user_identities SET last_login_at = NOW(), email = COALESCE(NULLIF($3, ''), email)
+		WHERE provider = $1 AND subject = $2
+	`, provider.Name, profile.Subject, profile.Email)
+
+	log.Printf("Successful %s login: %s from IP: %s", provider.Name, user.Email, c.ClientIP())
+	h.completeLogin(c, user, provider.Name)
+}
+
+// GetIdentities lists the sign-in accounts linked to the current user
+// @Summary List linked sign-in accounts
+// @Description List the Google, GitHub and Azure AD accounts linked to the current user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /auth/identities [get]
+func (h *AuthHandler) GetIdentities(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	identities := []models.UserIdentity{}
+	err := db.DB.Select(&identities, `
+		SELECT provider, email, created_at, last_login_at
+		FROM user_identities WHERE user_id = $1
+		ORDER BY provider
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch linked accounts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+// UnlinkIdentity removes a linked sign-in account from the current user
+// @Summary Unlink a sign-in account
+// @Description Stop signing in with a provider. The password keeps working.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "google, github or azuread"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /auth/identities/{provider} [delete]
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	provider := c.Param("provider")
+
+	result, err := db.DB.Exec("DELETE FROM user_identities WHERE user_id = $1 AND provider = $2", userID, provider)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink account"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No linked account for this provider"})
+		return
+	}
+
+	logIdentityEvent(c, "oauth_identity_unlinked", userID, map[string]interface{}{"provider": provider})
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlinked"})
+}
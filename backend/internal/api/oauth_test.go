@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/oauth"
+)
+
+func TestGetOAuthProviders(t *testing.T) {
+	oauth.Init(oauth.Settings{
+		RedirectBaseURL:    "https://app.example.com",
+		GoogleClientID:     "google-client",
+		GoogleClientSecret: "google-secret",
+	})
+	defer oauth.Init(oauth.Settings{})
+
+	h := &AuthHandler{}
+	status, body := serve(t, http.MethodGet, "/auth/oauth/providers", "/auth/oauth/providers", nil, h.GetOAuthProviders)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Providers []struct {
+			Name        string `json:"name"`
+			DisplayName string `json:"display_name"`
+		} `json:"providers"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Providers) != 1 || resp.Providers[0].Name != oauth.Google {
+		t.Errorf("providers = %+v", resp.Providers)
+	}
+}
+
+func TestOAuthDisabledProvider(t *testing.T) {
+	oauth.Init(oauth.Settings{})
+
+	h := &AuthHandler{}
+	status, body := serve(t, http.MethodGet, "/auth/oauth/:provider/authorize", "/auth/oauth/github/authorize", nil, h.StartOAuthLogin)
+	expectStatus(t, status, http.StatusNotFound, body)
+
+	status, body = serve(t, http.MethodPost, "/auth/oauth/:provider/callback", "/auth/oauth/github/callback",
+		map[string]string{"code": "abc", "state": "xyz"}, h.OAuthCallback)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
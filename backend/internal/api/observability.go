@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/metrics"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type ObservabilityHandler struct{}
+
+func NewObservabilityHandler() *ObservabilityHandler {
+	return &ObservabilityHandler{}
+}
+
+// GetDashboards returns Grafana dashboards built from the registered metrics (admin only)
+// @Summary Grafana dashboards
+// @Description Ready-to-import Grafana dashboards: the SLOs against their targets, and a panel for every metric the backend exports. With uid, only that dashboard's JSON is returned. The SLO targets can be overridden as for GET /metrics/alerts.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param uid query string false "Return only this dashboard (datamigrate-slo or datamigrate-metrics)"
+// @Param latency_p95 query number false "p95 latency target in seconds"
+// @Param migration_success query number false "Migration success ratio target"
+// @Param ai_error_ratio query number false "AI service error ratio target"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/observability/dashboards [get]
+func (h *ObservabilityHandler) GetDashboards(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	targets, err := metrics.ParseSLOTargets(c.Request.URL.Query(), metrics.DefaultSLOTargets)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dashboards := metrics.Dashboards(targets)
+
+	if uid := c.Query("uid"); uid != "" {
+		for _, d := range dashboards {
+			if d.UID == uid {
+				c.JSON(http.StatusOK, d)
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"dashboards": dashboards})
+}
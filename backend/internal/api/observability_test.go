@@ -0,0 +1,11 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestObservabilityDashboardsRequiresAdmin(t *testing.T) {
+	status, body := serve(t, "GET", "/admin/observability/dashboards", "/admin/observability/dashboards", nil, NewObservabilityHandler().GetDashboards)
+	expectStatus(t, status, http.StatusForbidden, body)
+}
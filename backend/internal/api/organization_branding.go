@@ -0,0 +1,226 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// maxLogoSize is the largest logo an organization can upload
+const maxLogoSize = 512 * 1024
+
+// logoUploadConfig accepts the raster images email clients display. SVG is left out: it
+// can carry scripts and many clients don't render it.
+var logoUploadConfig = security.FileUploadConfig{
+	MaxFileSize:        maxLogoSize,
+	AllowedExtensions:  []string{".png", ".jpg", ".jpeg", ".gif"},
+	AllowedMIMETypes:   []string{"image/png", "image/jpeg", "image/gif"},
+	ValidateMagicBytes: true,
+	BlockExecutables:   true,
+	SanitizeFilenames:  true,
+}
+
+// brandingLogoURL is the public URL of an organization's logo, or "" without one. The
+// version makes clients fetch a replaced logo instead of a cached one.
+func brandingLogoURL(orgID int64, version string) string {
+	if version == "" {
+		return ""
+	}
+	return email.PublicURL(fmt.Sprintf("/api/v1/branding/logos/%d?v=%s", orgID, version))
+}
+
+// emailBranding is the look of the emails sent about an organization's migrations, or
+// nil when it hasn't set a logo or colors
+func emailBranding(orgID sql.NullInt64, orgName sql.NullString, settings sql.NullString) *email.Branding {
+	if !orgID.Valid {
+		return nil
+	}
+	b := parseOrganizationDefaults(settings).Branding
+	if b.PrimaryColor == "" && b.LogoVersion == "" {
+		return nil
+	}
+	return &email.Branding{
+		Name:         orgName.String,
+		LogoURL:      brandingLogoURL(orgID.Int64, b.LogoVersion),
+		PrimaryColor: b.PrimaryColor,
+		AccentColor:  b.AccentColor,
+	}
+}
+
+// UploadLogo sets the organization's logo
+// @Summary Upload organization logo
+// @Description Upload the logo shown in the organization's notification emails and dbt docs landing page. PNG, JPEG or GIF, up to 512 KB.
+// @Tags organizations
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param logo formData file true "Logo image"
+// @Success 200 {object} models.OrganizationSettings
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/settings/logo [put]
+func (h *OrganizationsHandler) UploadLogo(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "logo file is required"})
+		return
+	}
+	result, err := security.NewFileUploadValidator(logoUploadConfig).ValidateFile(file)
+	if err != nil || !result.Valid {
+		details := []string{"logo could not be read"}
+		if result != nil && len(result.Errors) > 0 {
+			details = result.Errors
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid logo", "details": details})
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "logo could not be read"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxLogoSize+1))
+	if err != nil || len(data) > maxLogoSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "logo could not be read"})
+		return
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save logo"})
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO organization_logos (organization_id, content_type, data, checksum)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET content_type = EXCLUDED.content_type, data = EXCLUDED.data, checksum = EXCLUDED.checksum, updated_at = NOW()
+	`, orgID, result.MIMEType, data, checksum)
+	if err == nil {
+		_, err = tx.Exec(`
+			UPDATE organizations
+			SET settings = COALESCE(settings, '{}'::jsonb) || jsonb_build_object('branding',
+			        COALESCE(settings->'branding', '{}'::jsonb) || jsonb_build_object('logo_version', $1::text)),
+			    updated_at = NOW()
+			WHERE id = $2
+		`, checksum[:12], orgID)
+	}
+	if err != nil || tx.Commit() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save logo"})
+		return
+	}
+
+	settings, err := getOrganizationSettings(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization settings"})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// DeleteLogo removes the organization's logo
+// @Summary Remove organization logo
+// @Description Remove the logo; emails show the organization's name instead
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.OrganizationSettings
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/settings/logo [delete]
+func (h *OrganizationsHandler) DeleteLogo(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove logo"})
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM organization_logos WHERE organization_id = $1", orgID)
+	if err == nil {
+		_, err = tx.Exec(`
+			UPDATE organizations SET settings = settings #- '{branding,logo_version}', updated_at = NOW()
+			WHERE id = $1
+		`, orgID)
+	}
+	if err != nil || tx.Commit() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove logo"})
+		return
+	}
+
+	settings, err := getOrganizationSettings(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization settings"})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetLogo serves an organization's logo. It's public so email clients and dbt docs
+// sites can load it.
+// @Summary Get organization logo
+// @Tags organizations
+// @Produce image/png,image/jpeg,image/gif
+// @Param id path int true "Organization ID"
+// @Success 200 {file} binary
+// @Success 304 "Not modified"
+// @Failure 404 {object} map[string]string
+// @Router /branding/logos/{id} [get]
+func (h *OrganizationsHandler) GetLogo(c *gin.Context) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Logo not found"})
+		return
+	}
+
+	var logo struct {
+		ContentType string `db:"content_type"`
+		Data        []byte `db:"data"`
+		Checksum    string `db:"checksum"`
+	}
+	err = db.DB.Get(&logo, "SELECT content_type, data, checksum FROM organization_logos WHERE organization_id = $1", orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Logo not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch logo"})
+		return
+	}
+
+	etag := `"` + logo.Checksum + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("X-Content-Type-Options", "nosniff")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, logo.ContentType, logo.Data)
+}
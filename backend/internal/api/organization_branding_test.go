@@ -0,0 +1,64 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEmailBranding(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://app.example.com/")
+	settings := sql.NullString{String: `{"branding":{"primary_color":"#0f766e","logo_version":"abc123"}}`, Valid: true}
+
+	b := emailBranding(sql.NullInt64{Int64: 3, Valid: true}, sql.NullString{String: "Contoso", Valid: true}, settings)
+	if b == nil {
+		t.Fatal("branding = nil")
+	}
+	if b.LogoURL != "https://app.example.com/api/v1/branding/logos/3?v=abc123" || b.PrimaryColor != "#0f766e" || b.Name != "Contoso" {
+		t.Errorf("branding = %+v", b)
+	}
+
+	if b := emailBranding(sql.NullInt64{Int64: 3, Valid: true}, sql.NullString{String: "Contoso", Valid: true}, sql.NullString{}); b != nil {
+		t.Errorf("branding without settings = %+v, want nil", b)
+	}
+}
+
+func TestMigrationsGetDocsOverviewBranded(t *testing.T) {
+	store, mock := newMockDB(t)
+
+	mock.ExpectQuery("FROM migrations m").
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"name", "source_database", "tables_count", "views_count", "organization_id", "organization_name", "settings",
+		}).AddRow("Sales {{ warehouse }}", "AdventureWorks", 12, 3, testOrgID, "Contoso <Data>",
+			`{"branding":{"primary_color":"#0f766e","accent_color":"#f59e0b"}}`))
+
+	status, body := serve(t, "GET", "/migrations/:id/docs/overview", "/migrations/7/docs/overview", nil,
+		NewMigrationsHandler(store).GetDocsOverview)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Path     string `json:"path"`
+		Markdown string `json:"markdown"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Path != "models/overview.md" {
+		t.Errorf("path = %q", resp.Path)
+	}
+	for _, want := range []string{
+		"{% docs __overview__ %}",
+		"linear-gradient(135deg, #0f766e 0%, #f59e0b 100%)",
+		"Contoso &lt;Data&gt; · Sales &#123;&#123; warehouse &#125;&#125;",
+		"12 tables and 3 views",
+	} {
+		if !strings.Contains(resp.Markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, resp.Markdown)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// domainVerifyTimeout bounds the DNS lookup of a domain verification
+const domainVerifyTimeout = 10 * time.Second
+
+// GetDomains lists the organization's custom domains
+// @Summary List custom domains
+// @Description List the custom domains the organization hosts the product under, with the TXT record that verifies each
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.OrganizationDomain
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/domains [get]
+func (h *OrganizationsHandler) GetDomains(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	list := []models.OrganizationDomain{}
+	err := db.DB.Select(&list, `
+		SELECT id, domain, verification_token, verified_at, created_at
+		FROM organization_domains WHERE organization_id = $1
+		ORDER BY created_at, id
+	`, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domains"})
+		return
+	}
+	for i := range list {
+		list[i].VerificationRecord = domains.VerificationRecord(list[i].Domain)
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// AddDomain claims a custom domain for the organization
+// @Summary Add custom domain
+// @Description Claim a domain such as app.example.com. Publish the returned token as a TXT record at verification_record, then verify the domain.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AddOrganizationDomainRequest true "Domain"
+// @Success 201 {object} models.OrganizationDomain
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/domains [post]
+func (h *OrganizationsHandler) AddDomain(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddOrganizationDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	domain := domains.Normalize(req.Domain)
+	if err := domains.Validate(domain); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if owner, ok := domains.Organization(domain); ok && owner != orgID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Domain is already in use by another organization"})
+		return
+	}
+
+	token, err := domains.NewVerificationToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add domain"})
+		return
+	}
+	entry := models.OrganizationDomain{Domain: domain, VerificationToken: token}
+	err = db.DB.QueryRow(`
+		INSERT INTO organization_domains (organization_id, domain, verification_token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, domain) DO NOTHING
+		RETURNING id, created_at
+	`, orgID, domain, token).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain was already added"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add domain"})
+		return
+	}
+	entry.VerificationRecord = domains.VerificationRecord(domain)
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// VerifyDomain checks a domain's TXT record and starts serving the organization on it
+// @Summary Verify custom domain
+// @Description Look up the domain's verification TXT record. Once verified, the domain serves the organization's sign-in, CORS is allowed from it and a certificate is issued for it.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Success 200 {object} models.OrganizationDomain
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/domains/{id}/verify [post]
+func (h *OrganizationsHandler) VerifyDomain(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	var entry models.OrganizationDomain
+	err = db.DB.Get(&entry, `
+		SELECT id, domain, verification_token, verified_at, created_at
+		FROM organization_domains WHERE id = $1 AND organization_id = $2
+	`, id, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain"})
+		return
+	}
+	entry.VerificationRecord = domains.VerificationRecord(entry.Domain)
+	if entry.VerifiedAt != nil {
+		c.JSON(http.StatusOK, entry)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), domainVerifyTimeout)
+	defer cancel()
+	if err := domains.CheckVerification(ctx, entry.Domain, entry.VerificationToken); err != nil {
+		if !errors.Is(err, domains.ErrVerificationFailed) {
+			log.Printf("Failed to look up verification record of %s: %v", entry.Domain, err)
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "No TXT record with the verification token was found. DNS changes can take a while to propagate.",
+			"record": entry.VerificationRecord,
+			"token":  entry.VerificationToken,
+		})
+		return
+	}
+
+	err = db.DB.Get(&entry.VerifiedAt, `
+		UPDATE organization_domains SET verified_at = NOW()
+		WHERE id = $1 AND organization_id = $2
+		RETURNING verified_at
+	`, id, orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is already in use by another organization"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify domain"})
+		return
+	}
+	if err := domains.Reload(); err != nil {
+		log.Printf("Failed to reload custom domains: %v", err)
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteDomain removes a custom domain
+// @Summary Delete custom domain
+// @Description Stop serving the organization on a domain. Sessions on it stop working.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/domains/{id} [delete]
+func (h *OrganizationsHandler) DeleteDomain(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain ID"})
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM organization_domains WHERE id = $1 AND organization_id = $2", id, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domain"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+	if err := domains.Reload(); err != nil {
+		log.Printf("Failed to reload custom domains: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Domain deleted"})
+}
+
+// GetSiteBranding returns the organization a custom domain belongs to, so the frontend
+// can brand its sign-in page
+// @Summary Get site organization
+// @Description Resolve the custom domain the request comes from (its Origin, else its Host) to the organization's name and branding. 404 on the product's own domain.
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /site [get]
+func (h *OrganizationsHandler) GetSiteBranding(c *gin.Context) {
+	domain, orgID, ok := domains.RequestDomain(c.Request)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not a custom domain"})
+		return
+	}
+
+	var org struct {
+		Name     string         `db:"name"`
+		Settings sql.NullString `db:"settings"`
+	}
+	if err := db.DB.Get(&org, "SELECT name, settings FROM organizations WHERE id = $1", orgID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not a custom domain"})
+		return
+	}
+	branding := parseOrganizationDefaults(org.Settings).Branding
+	branding.LogoURL = brandingLogoURL(orgID, branding.LogoVersion)
+
+	c.JSON(http.StatusOK, gin.H{
+		"domain":            domain,
+		"organization_id":   orgID,
+		"organization_name": org.Name,
+		"branding":          branding,
+	})
+}
@@ -0,0 +1,330 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/sqllint"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	organizationSlugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	namingPrefixRegex     = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+)
+
+// loadOrganizationDefaults reads an organization's stored defaults, filling in the
+// standard naming conventions for prefixes that aren't set
+func loadOrganizationDefaults(store db.Querier, orgID int64) (models.OrganizationDefaults, error) {
+	var raw sql.NullString
+	if err := store.Get(&raw, "SELECT settings FROM organizations WHERE id = $1", orgID); err != nil {
+		return models.OrganizationDefaults{NamingConventions: models.DefaultNamingConventions}, err
+	}
+	return parseOrganizationDefaults(raw), nil
+}
+
+func parseOrganizationDefaults(raw sql.NullString) models.OrganizationDefaults {
+	var defaults models.OrganizationDefaults
+	if raw.Valid {
+		if err := json.Unmarshal([]byte(raw.String), &defaults); err != nil {
+			log.Printf("Invalid organization settings, using defaults: %v", err)
+			defaults = models.OrganizationDefaults{}
+		}
+	}
+
+	naming := &defaults.NamingConventions
+	for _, p := range []struct {
+		value    *string
+		fallback string
+	}{
+		{&naming.StagingPrefix, models.DefaultNamingConventions.StagingPrefix},
+		{&naming.IntermediatePrefix, models.DefaultNamingConventions.IntermediatePrefix},
+		{&naming.FactPrefix, models.DefaultNamingConventions.FactPrefix},
+		{&naming.DimensionPrefix, models.DefaultNamingConventions.DimensionPrefix},
+	} {
+		if *p.value == "" {
+			*p.value = p.fallback
+		}
+	}
+	return defaults
+}
+
+// validateOrganizationSettings checks the fields of an update that are set
+func validateOrganizationSettings(req *models.UpdateOrganizationSettingsRequest) []string {
+	var details []string
+
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if len(*req.Name) < 2 || len(*req.Name) > 255 {
+			details = append(details, "name must be 2-255 characters")
+		}
+	}
+	if req.Slug != nil {
+		*req.Slug = strings.ToLower(strings.TrimSpace(*req.Slug))
+		if len(*req.Slug) < 2 || len(*req.Slug) > 63 || !organizationSlugRegex.MatchString(*req.Slug) {
+			details = append(details, "slug must be 2-63 lowercase letters, digits and single hyphens")
+		}
+	}
+	if req.DefaultWarehouse != nil && *req.DefaultWarehouse != "" {
+		if _, ok := dbtAdapters[*req.DefaultWarehouse]; !ok {
+			details = append(details, fmt.Sprintf("default_warehouse %q is not a supported warehouse", *req.DefaultWarehouse))
+		}
+	}
+	if n := req.NamingConventions; n != nil {
+		for _, p := range []struct{ field, prefix string }{
+			{"staging_prefix", n.StagingPrefix},
+			{"intermediate_prefix", n.IntermediatePrefix},
+			{"fact_prefix", n.FactPrefix},
+			{"dimension_prefix", n.DimensionPrefix},
+		} {
+			if p.prefix != "" && !namingPrefixRegex.MatchString(p.prefix) {
+				details = append(details, "naming_conventions."+p.field+" must start with a letter and contain only lowercase letters, digits and underscores")
+			}
+		}
+	}
+	if ch := req.NotificationChannel; ch != nil {
+		if err := validateNotificationChannel(ch); err != nil {
+			details = append(details, "notification_channel "+err.Error())
+		}
+	}
+	if b := req.Branding; b != nil {
+		for _, c := range []struct {
+			field string
+			color *string
+		}{
+			{"primary_color", &b.PrimaryColor},
+			{"accent_color", &b.AccentColor},
+		} {
+			*c.color = strings.ToLower(strings.TrimSpace(*c.color))
+			if *c.color != "" && !email.IsBrandColor(*c.color) {
+				details = append(details, "branding."+c.field+" must be a #rrggbb color")
+			}
+		}
+	}
+	if st := req.SQLStyle; st != nil {
+		st.KeywordCase = strings.ToLower(strings.TrimSpace(st.KeywordCase))
+		if st.KeywordCase != "" && st.KeywordCase != "upper" && st.KeywordCase != "lower" {
+			details = append(details, "sql_style.keyword_case must be upper or lower")
+		}
+		if st.MaxLineLength != 0 && (st.MaxLineLength < 40 || st.MaxLineLength > 400) {
+			details = append(details, "sql_style.max_line_length must be between 40 and 400")
+		}
+		for i, code := range st.DisabledRules {
+			st.DisabledRules[i] = strings.ToUpper(strings.TrimSpace(code))
+			if _, ok := sqllint.Rules[st.DisabledRules[i]]; !ok {
+				details = append(details, fmt.Sprintf("sql_style.disabled_rules: %q is not a lint rule", code))
+			}
+		}
+	}
+	return details
+}
+
+func validateNotificationChannel(ch *models.NotificationChannel) error {
+	ch.Target = strings.TrimSpace(ch.Target)
+	switch ch.Type {
+	case "email":
+		if addr, err := mail.ParseAddress(ch.Target); err != nil || addr.Address != ch.Target {
+			return fmt.Errorf("target must be an email address")
+		}
+		return nil
+	case "slack", "teams", "webhook":
+		u, err := url.Parse(ch.Target)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("target must be an https URL")
+		}
+		if ch.Type == "slack" && u.Hostname() != "hooks.slack.com" {
+			return fmt.Errorf("target must be a Slack incoming webhook (https://hooks.slack.com/...)")
+		}
+		return nil
+	}
+	return fmt.Errorf("type must be email, slack, teams or webhook")
+}
+
+// GetSettings returns the organization's settings
+// @Summary Get organization settings
+// @Description Get the organization's name, slug, default warehouse, naming conventions, notification channel, branding, SQL style and whether chat is grounded in its resources
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.OrganizationSettings
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/settings [get]
+func (h *OrganizationsHandler) GetSettings(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	settings, err := getOrganizationSettings(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings changes the organization's settings
+// @Summary Update organization settings
+// @Description Rename the organization, change its slug, set migration defaults or brand colors, or turn chat grounding (ai_context_enabled) on or off. Only the fields sent are changed.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateOrganizationSettingsRequest true "Settings to change"
+// @Success 200 {object} models.OrganizationSettings
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/settings [put]
+func (h *OrganizationsHandler) UpdateSettings(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateOrganizationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if details := validateOrganizationSettings(&req); len(details) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": details})
+		return
+	}
+
+	current, err := getOrganizationSettings(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization settings"})
+		return
+	}
+
+	if req.Slug != nil && *req.Slug != current.Slug {
+		suggestion, err := uniqueOrganizationSlug(*req.Slug, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check slug"})
+			return
+		}
+		if suggestion != *req.Slug {
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug is already taken", "suggestion": suggestion})
+			return
+		}
+	}
+
+	name, slug := current.Name, current.Slug
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Slug != nil {
+		slug = *req.Slug
+	}
+	defaults := models.OrganizationDefaults{
+		DefaultWarehouse:    current.DefaultWarehouse,
+		NamingConventions:   current.NamingConventions,
+		NotificationChannel: current.NotificationChannel,
+		Branding:            current.Branding,
+		SQLStyle:            current.SQLStyle,
+	}
+	defaults.Branding.LogoURL = ""
+	if req.DefaultWarehouse != nil {
+		defaults.DefaultWarehouse = *req.DefaultWarehouse
+	}
+	if req.NamingConventions != nil {
+		defaults.NamingConventions = *req.NamingConventions
+	}
+	if req.NotificationChannel != nil {
+		defaults.NotificationChannel = req.NotificationChannel
+	}
+	if req.ClearNotificationChannel {
+		defaults.NotificationChannel = nil
+	}
+	if req.Branding != nil {
+		defaults.Branding.PrimaryColor = req.Branding.PrimaryColor
+		defaults.Branding.AccentColor = req.Branding.AccentColor
+	}
+	if req.SQLStyle != nil {
+		defaults.SQLStyle = *req.SQLStyle
+	}
+
+	aiContext := current.AIContextEnabled
+	if req.AIContextEnabled != nil {
+		aiContext = *req.AIContextEnabled
+	}
+
+	defaultsJSON, _ := json.Marshal(defaults)
+	_, err = db.DB.Exec(`
+		UPDATE organizations SET name = $1, slug = $2, settings = $3, ai_context_enabled = $4, updated_at = NOW()
+		WHERE id = $5
+	`, name, slug, string(defaultsJSON), aiContext, orgID)
+	if err != nil {
+		// Another organization took the slug since the check above
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug is already taken"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization settings"})
+		return
+	}
+
+	settings, err := getOrganizationSettings(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func getOrganizationSettings(orgID int64) (models.OrganizationSettings, error) {
+	var org struct {
+		ID         int64          `db:"id"`
+		Name       string         `db:"name"`
+		Slug       string         `db:"slug"`
+		Plan       string         `db:"plan"`
+		OwnerID    *int64         `db:"owner_id"`
+		Settings   sql.NullString `db:"settings"`
+		DataRegion string         `db:"data_region"`
+		AIContext  bool           `db:"ai_context_enabled"`
+		UpdatedAt  time.Time      `db:"updated_at"`
+	}
+	err := db.DB.Get(&org, `
+		SELECT id, name, slug, COALESCE(plan, 'free') AS plan, owner_id, settings,
+		       COALESCE(data_region, '') AS data_region, COALESCE(ai_context_enabled, false) AS ai_context_enabled,
+		       updated_at
+		FROM organizations WHERE id = $1
+	`, orgID)
+	if err != nil {
+		return models.OrganizationSettings{}, err
+	}
+
+	defaults := parseOrganizationDefaults(org.Settings)
+	defaults.Branding.LogoURL = brandingLogoURL(org.ID, defaults.Branding.LogoVersion)
+	return models.OrganizationSettings{
+		ID:                  org.ID,
+		Name:                org.Name,
+		Slug:                org.Slug,
+		Plan:                org.Plan,
+		OwnerID:             org.OwnerID,
+		DefaultWarehouse:    defaults.DefaultWarehouse,
+		NamingConventions:   defaults.NamingConventions,
+		NotificationChannel: defaults.NotificationChannel,
+		Branding:            defaults.Branding,
+		SQLStyle:            defaults.SQLStyle,
+		DataRegion:          org.DataRegion,
+		AIContextEnabled:    org.AIContext,
+		UpdatedAt:           org.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestValidateOrganizationSettings(t *testing.T) {
+	req := models.UpdateOrganizationSettingsRequest{
+		Name:             strPtr("  Contoso Analytics "),
+		Slug:             strPtr(" Contoso-Analytics"),
+		DefaultWarehouse: strPtr("fabric"),
+		NamingConventions: &models.NamingConventions{
+			StagingPrefix: "src_",
+			FactPrefix:    "fact_",
+		},
+		NotificationChannel: &models.NotificationChannel{Type: "slack", Target: "https://hooks.slack.com/services/T000/B000/XXXX"},
+	}
+	if details := validateOrganizationSettings(&req); len(details) > 0 {
+		t.Fatalf("details = %v", details)
+	}
+	if *req.Name != "Contoso Analytics" || *req.Slug != "contoso-analytics" {
+		t.Errorf("name = %q, slug = %q", *req.Name, *req.Slug)
+	}
+}
+
+func TestValidateOrganizationSettingsRejects(t *testing.T) {
+	req := models.UpdateOrganizationSettingsRequest{
+		Name:                strPtr("x"),
+		Slug:                strPtr("contoso--analytics"),
+		DefaultWarehouse:    strPtr("oracle"),
+		NamingConventions:   &models.NamingConventions{StagingPrefix: "Stg-"},
+		NotificationChannel: &models.NotificationChannel{Type: "slack", Target: "https://example.com/hook"},
+		SQLStyle:            &models.SQLStyle{KeywordCase: "Title", MaxLineLength: 10, DisabledRules: []string{"lt05", "XX99"}},
+	}
+	want := []string{
+		"name must be 2-255 characters",
+		"slug must be 2-63 lowercase letters, digits and single hyphens",
+		`default_warehouse "oracle" is not a supported warehouse`,
+		"naming_conventions.staging_prefix must start with a letter and contain only lowercase letters, digits and underscores",
+		"notification_channel target must be a Slack incoming webhook (https://hooks.slack.com/...)",
+		"sql_style.keyword_case must be upper or lower",
+		"sql_style.max_line_length must be between 40 and 400",
+		`sql_style.disabled_rules: "XX99" is not a lint rule`,
+	}
+	if details := validateOrganizationSettings(&req); !reflect.DeepEqual(details, want) {
+		t.Errorf("details =\n%q\nwant\n%q", details, want)
+	}
+	if req.SQLStyle.DisabledRules[0] != "LT05" {
+		t.Errorf("disabled_rules = %v", req.SQLStyle.DisabledRules)
+	}
+}
+
+func TestValidateNotificationChannel(t *testing.T) {
+	tests := []struct {
+		channel models.NotificationChannel
+		valid   bool
+	}{
+		{models.NotificationChannel{Type: "email", Target: "data-team@contoso.com"}, true},
+		{models.NotificationChannel{Type: "email", Target: "Data Team <data-team@contoso.com>"}, false},
+		{models.NotificationChannel{Type: "teams", Target: "https://contoso.webhook.office.com/webhookb2/abc"}, true},
+		{models.NotificationChannel{Type: "webhook", Target: "http://internal.contoso.com/hook"}, false},
+		{models.NotificationChannel{Type: "sms", Target: "+4512345678"}, false},
+	}
+	for _, tt := range tests {
+		if err := validateNotificationChannel(&tt.channel); (err == nil) != tt.valid {
+			t.Errorf("validateNotificationChannel(%+v) = %v, want valid %v", tt.channel, err, tt.valid)
+		}
+	}
+}
+
+func TestParseOrganizationDefaultsFillsNaming(t *testing.T) {
+	defaults := parseOrganizationDefaults(sql.NullString{
+		String: `{"default_warehouse":"snowflake","naming_conventions":{"staging_prefix":"src_"}}`,
+		Valid:  true,
+	})
+	want := models.NamingConventions{StagingPrefix: "src_", IntermediatePrefix: "int_", FactPrefix: "fct_", DimensionPrefix: "dim_"}
+	if defaults.DefaultWarehouse != "snowflake" || defaults.NamingConventions != want {
+		t.Errorf("defaults = %+v", defaults)
+	}
+
+	if got := parseOrganizationDefaults(sql.NullString{}).NamingConventions; got != models.DefaultNamingConventions {
+		t.Errorf("naming without settings = %+v", got)
+	}
+}
+
+func TestValidateOrganizationSettingsBranding(t *testing.T) {
+	req := models.UpdateOrganizationSettingsRequest{
+		Branding: &models.OrganizationBranding{PrimaryColor: " #0F766E ", AccentColor: "teal"},
+	}
+	want := []string{"branding.accent_color must be a #rrggbb color"}
+	if details := validateOrganizationSettings(&req); !reflect.DeepEqual(details, want) {
+		t.Errorf("details = %q, want %q", details, want)
+	}
+	if req.Branding.PrimaryColor != "#0f766e" {
+		t.Errorf("primary_color = %q, want it trimmed and lowercased", req.Branding.PrimaryColor)
+	}
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/quota"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationsHandler struct{}
+
+func NewOrganizationsHandler() *OrganizationsHandler {
+	return &OrganizationsHandler{}
+}
+
+// GetPasswordPolicy returns the organization's password policy
+// @Summary Get password policy
+// @Description Get the password policy enforced for members of the caller's organization
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} security.PasswordPolicy
+// @Failure 403 {object} map[string]string
+// @Router /organizations/password-policy [get]
+func (h *OrganizationsHandler) GetPasswordPolicy(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, security.LoadPasswordPolicy(orgID))
+}
+
+// UpdatePasswordPolicy replaces the organization's password policy
+// @Summary Update password policy
+// @Description Configure length, complexity, breach check, history, and max age rules
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body security.PasswordPolicy true "Password policy"
+// @Success 200 {object} security.PasswordPolicy
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/password-policy [put]
+func (h *OrganizationsHandler) UpdatePasswordPolicy(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var policy security.PasswordPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := policy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policyJSON, _ := json.Marshal(policy)
+	_, err := db.DB.Exec(`
+		UPDATE organizations SET password_policy = $1, updated_at = NOW()
+		WHERE id = $2
+	`, string(policyJSON), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// IPAllowlistRequest is the body for adding an allowlist entry
+type IPAllowlistRequest struct {
+	CIDR        string  `json:"cidr" binding:"required"` // CIDR range or single IP address
+	Description *string `json:"description"`
+}
+
+// GetIPAllowlist returns the organization's IP allowlist
+// @Summary Get IP allowlist
+// @Description List the CIDR ranges allowed to access the app (empty means all IPs are allowed)
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} security.IPAllowlistEntry
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ip-allowlist [get]
+func (h *OrganizationsHandler) GetIPAllowlist(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var entries []security.IPAllowlistEntry
+	err := db.DB.Select(&entries, `
+		SELECT id, organization_id, cidr, description, created_by, created_at
+		FROM organization_ip_allowlists
+		WHERE organization_id = $1
+		ORDER BY created_at
+	`, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch IP allowlist"})
+		return
+	}
+
+	if entries == nil {
+		entries = []security.IPAllowlistEntry{}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// AddIPAllowlistEntry adds a CIDR range to the organization's allowlist
+// @Summary Add IP allowlist entry
+// @Description Allow a CIDR range. The first entry must include the caller's own IP to prevent lockout.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body IPAllowlistRequest true "Allowlist entry"
+// @Success 201 {object} security.IPAllowlistEntry
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ip-allowlist [post]
+func (h *OrganizationsHandler) AddIPAllowlistEntry(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req IPAllowlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cidr, err := security.NormalizeCIDR(req.CIDR)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Don't let an admin lock themselves out by enabling the allowlist without their own IP
+	var existing []string
+	if err := db.DB.Select(&existing, "SELECT cidr FROM organization_ip_allowlists WHERE organization_id = $1", orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch IP allowlist"})
+		return
+	}
+	if !security.CIDRContains(append(existing, cidr), c.ClientIP()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "The allowlist must include your current IP address",
+			"client_ip": c.ClientIP(),
+		})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	entry := security.IPAllowlistEntry{
+		OrganizationID: orgID,
+		CIDR:           cidr,
+		Description:    req.Description,
+		CreatedBy:      &userID,
+	}
+	err = db.DB.QueryRow(`
+		INSERT INTO organization_ip_allowlists (organization_id, cidr, description, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id, cidr) DO NOTHING
+		RETURNING id, created_at
+	`, orgID, cidr, req.Description, userID).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "CIDR is already in the allowlist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add IP allowlist entry"})
+		return
+	}
+
+	security.GetIPAllowlist().Invalidate(orgID)
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// DeleteIPAllowlistEntry removes a CIDR range from the organization's allowlist
+// @Summary Delete IP allowlist entry
+// @Description Remove a CIDR range. Removing the last entry allows all IPs again.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Entry ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ip-allowlist/{id} [delete]
+func (h *OrganizationsHandler) DeleteIPAllowlistEntry(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	// Removing an entry must not cut off the caller's own access
+	var remaining []string
+	if err := db.DB.Select(&remaining, "SELECT cidr FROM organization_ip_allowlists WHERE organization_id = $1 AND id <> $2", orgID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch IP allowlist"})
+		return
+	}
+	if len(remaining) > 0 && !security.CIDRContains(remaining, c.ClientIP()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Removing this entry would block your current IP address",
+			"client_ip": c.ClientIP(),
+		})
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM organization_ip_allowlists WHERE id = $1 AND organization_id = $2", id, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete IP allowlist entry"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "IP allowlist entry not found"})
+		return
+	}
+
+	security.GetIPAllowlist().Invalidate(orgID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "IP allowlist entry deleted"})
+}
+
+// GetUsage returns the organization's monthly quota usage
+// @Summary Get quota usage
+// @Description Usage of migrations run, tables migrated, chat messages, and AI tokens against the plan's monthly quotas
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param months query int false "Months of history to include (default 6, max 24)"
+// @Success 200 {object} quota.UsageReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/usage [get]
+func (h *OrganizationsHandler) GetUsage(c *gin.Context) {
+	orgID := middleware.GetOrganizationID(c)
+	if orgID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User does not belong to an organization"})
+		return
+	}
+
+	months := 6
+	if m := c.Query("months"); m != "" {
+		if parsed, err := strconv.Atoi(m); err == nil && parsed >= 0 && parsed <= 24 {
+			months = parsed
+		}
+	}
+
+	report, err := quota.GetUsage(orgID, months)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// enforceQuota checks a monthly quota for the caller's organization and writes a 402
+// explaining the limit when it would be exceeded. Users without an organization and
+// quota lookup failures are let through.
+func enforceQuota(c *gin.Context, orgID int64, metric string, amount int64) bool {
+	if exceeded := quotaExceeded(orgID, metric, amount); exceeded != nil {
+		c.JSON(http.StatusPaymentRequired, exceeded)
+		return false
+	}
+	return true
+}
+
+// quotaExceeded is enforceQuota without the response: it returns the 402 body when the
+// quota would be exceeded, or nil
+func quotaExceeded(orgID int64, metric string, amount int64) gin.H {
+	if orgID == 0 {
+		return nil
+	}
+
+	err := quota.Check(orgID, metric, amount)
+	exceeded, ok := err.(*quota.ExceededError)
+	if !ok {
+		if err != nil {
+			log.Printf("Quota check failed for organization %d (%s): %v", orgID, metric, err)
+		}
+		return nil
+	}
+
+	return gin.H{
+		"error":     "Monthly " + strings.ReplaceAll(metric, "_", " ") + " quota exceeded",
+		"metric":    exceeded.Metric,
+		"plan":      exceeded.Plan,
+		"used":      exceeded.Used,
+		"limit":     exceeded.Limit,
+		"requested": exceeded.Requested,
+		"resets_at": exceeded.ResetsAt,
+		"message":   "Upgrade your plan or wait until the quota resets. Current usage is available at /api/v1/organizations/usage.",
+	}
+}
+
+// recordUsage adds to an organization's monthly usage counters
+func recordUsage(orgID int64, metric string, amount int64) {
+	if orgID == 0 {
+		return
+	}
+	if err := quota.Record(orgID, metric, amount); err != nil {
+		log.Printf("Failed to record %s usage for organization %d: %v", metric, orgID, err)
+	}
+}
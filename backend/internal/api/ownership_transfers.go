@@ -0,0 +1,470 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// ownershipTransferTTL is how long both parties have to confirm a transfer
+const ownershipTransferTTL = 72 * time.Hour
+
+const ownershipTransferQuery = `
+	SELECT t.id, t.organization_id, t.from_user_id, fu.email AS from_email, t.to_user_id, tu.email AS to_email,
+	       t.requested_by, t.from_confirmed_at, t.to_confirmed_at, t.status, t.expires_at, t.created_at, t.completed_at
+	FROM organization_ownership_transfers t
+	LEFT JOIN users fu ON fu.id = t.from_user_id
+	JOIN users tu ON tu.id = t.to_user_id
+`
+
+// transferParty is a user who has to confirm an ownership transfer
+type transferParty struct {
+	Email       string         `db:"email"`
+	FirstName   sql.NullString `db:"first_name"`
+	LastName    sql.NullString `db:"last_name"`
+	Language    string         `db:"preferred_language"`
+	IsActive    bool           `db:"is_active"`
+	AccountType string         `db:"account_type"`
+}
+
+func (p transferParty) displayName() string {
+	name := p.FirstName.String
+	if p.LastName.String != "" {
+		name += " " + p.LastName.String
+	}
+	if name == "" {
+		return p.Email
+	}
+	return name
+}
+
+func loadTransferParty(userID int64) (transferParty, error) {
+	var party transferParty
+	err := db.DB.Get(&party, `
+		SELECT email, first_name, last_name, COALESCE(preferred_language, 'en') AS preferred_language,
+		       COALESCE(is_active, true) AS is_active, COALESCE(account_type, 'human') AS account_type
+		FROM users WHERE id = $1
+	`, userID)
+	return party, err
+}
+
+// newTransferToken returns a confirmation token and the hash stored for it
+func newTransferToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(b)
+	return token, hashTransferToken(token), nil
+}
+
+func hashTransferToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func getOwnershipTransfer(id int64) (models.OwnershipTransfer, error) {
+	var transfer models.OwnershipTransfer
+	err := db.DB.Get(&transfer, ownershipTransferQuery+" WHERE t.id = $1", id)
+	return transfer, err
+}
+
+// expireOwnershipTransfers marks the organization's unconfirmed transfers past their
+// deadline as expired so a new one can be started
+func expireOwnershipTransfers(orgID int64) {
+	_, err := db.DB.Exec(`
+		UPDATE organization_ownership_transfers SET status = 'expired'
+		WHERE organization_id = $1 AND status = 'pending' AND expires_at <= NOW()
+	`, orgID)
+	if err != nil {
+		log.Printf("Failed to expire ownership transfers of organization %d: %v", orgID, err)
+	}
+}
+
+// auditOwnershipTransfer records a step of an ownership transfer in the security audit log
+func auditOwnershipTransfer(c *gin.Context, eventType, severity string, transfer models.OwnershipTransfer) {
+	userID := middleware.GetUserID(c)
+	orgID := transfer.OrganizationID
+	metadata := map[string]interface{}{
+		"transfer_id": transfer.ID,
+		"to_user_id":  transfer.ToUserID,
+	}
+	if transfer.FromUserID != nil {
+		metadata["from_user_id"] = *transfer.FromUserID
+	}
+	security.GetGuardian().LogSecurityEvent(&security.SecurityEvent{
+		EventType:      eventType,
+		Severity:       severity,
+		UserID:         &userID,
+		OrganizationID: &orgID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: http.StatusOK,
+		Metadata:       metadata,
+	})
+}
+
+// sendOwnershipTransferEmail asks one party to confirm a transfer, logging instead of
+// sending when email isn't configured
+func sendOwnershipTransferEmail(to transferParty, otherName, organizationName, token string, incoming bool) {
+	emailService := email.NewService()
+	if !emailService.IsConfigured() {
+		email.NewMockService().SendOwnershipTransferEmail(to.Email, otherName, organizationName, token, incoming, to.Language)
+		return
+	}
+	if err := emailService.SendOwnershipTransferEmail(to.Email, otherName, organizationName, token, incoming, to.Language); err != nil {
+		log.Printf("Failed to send ownership transfer email to %s: %v", to.Email, err)
+	}
+}
+
+// GetOwnershipTransfer returns the organization's pending ownership transfer
+// @Summary Get pending ownership transfer
+// @Description Get the organization's ownership transfer that is waiting for confirmation
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.OwnershipTransfer
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/ownership-transfer [get]
+func (h *OrganizationsHandler) GetOwnershipTransfer(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+	expireOwnershipTransfers(orgID)
+
+	var transfer models.OwnershipTransfer
+	err := db.DB.Get(&transfer, ownershipTransferQuery+" WHERE t.organization_id = $1 AND t.status = 'pending'", orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No pending ownership transfer"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ownership transfer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// RequestOwnershipTransfer starts handing the organization to another member
+// @Summary Transfer organization ownership
+// @Description Start transferring ownership to another member. Both the current and the new owner get an email and must confirm within 72 hours; the new owner then becomes an admin. Only the owner can start a transfer, or a platform admin when the owner's account is gone or deactivated, in which case only the new owner confirms.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateOwnershipTransferRequest true "New owner"
+// @Success 201 {object} models.OwnershipTransfer
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ownership-transfer [post]
+func (h *OrganizationsHandler) RequestOwnershipTransfer(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+
+	var req models.CreateOwnershipTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var org struct {
+		Name    string        `db:"name"`
+		OwnerID sql.NullInt64 `db:"owner_id"`
+	}
+	if err := db.DB.Get(&org, "SELECT name, owner_id FROM organizations WHERE id = $1", orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch organization"})
+		return
+	}
+
+	// The owner confirms by email unless there is no one left to confirm
+	var owner transferParty
+	ownerConfirms := org.OwnerID.Valid
+	if org.OwnerID.Valid {
+		var err error
+		if owner, err = loadTransferParty(org.OwnerID.Int64); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch owner"})
+			return
+		}
+		ownerConfirms = owner.IsActive
+	}
+	isOwner := org.OwnerID.Valid && org.OwnerID.Int64 == userID
+	if !isOwner && (ownerConfirms || !middleware.IsAdmin(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organization owner can transfer ownership"})
+		return
+	}
+
+	if org.OwnerID.Valid && req.UserID == org.OwnerID.Int64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User already owns the organization"})
+		return
+	}
+	var isMember bool
+	err := db.DB.Get(&isMember, `
+		SELECT EXISTS(SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2)
+	`, orgID, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check membership"})
+		return
+	}
+	if !isMember {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The new owner must be a member of the organization"})
+		return
+	}
+	newOwner, err := loadTransferParty(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch new owner"})
+		return
+	}
+	if !newOwner.IsActive || newOwner.AccountType != "human" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The new owner must be an active user account, not a service account"})
+		return
+	}
+
+	fromToken, fromHash, err := newTransferToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start ownership transfer"})
+		return
+	}
+	toToken, toHash, err := newTransferToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start ownership transfer"})
+		return
+	}
+
+	expireOwnershipTransfers(orgID)
+
+	var transferID int64
+	err = db.DB.QueryRow(`
+		INSERT INTO organization_ownership_transfers
+			(organization_id, from_user_id, to_user_id, requested_by, from_token_hash, to_token_hash, from_confirmed_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $7 THEN NULL ELSE NOW() END, $8)
+		RETURNING id
+	`, orgID, org.OwnerID, req.UserID, userID, fromHash, toHash, ownerConfirms, time.Now().Add(ownershipTransferTTL)).Scan(&transferID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An ownership transfer is already pending; cancel it first"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start ownership transfer"})
+		return
+	}
+
+	transfer, err := getOwnershipTransfer(transferID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ownership transfer"})
+		return
+	}
+
+	go func() {
+		if ownerConfirms {
+			sendOwnershipTransferEmail(owner, newOwner.displayName(), org.Name, fromToken, false)
+		}
+		ownerName := "DataMigrate AI support"
+		if org.OwnerID.Valid {
+			ownerName = owner.displayName()
+		}
+		sendOwnershipTransferEmail(newOwner, ownerName, org.Name, toToken, true)
+	}()
+
+	auditOwnershipTransfer(c, "ownership_transfer_requested", "medium", transfer)
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// ConfirmOwnershipTransfer records one party's confirmation and completes the transfer
+// once both have confirmed
+// @Summary Confirm ownership transfer
+// @Description Confirm an ownership transfer with the token from the confirmation email. The token only works for the user it was sent to.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ConfirmOwnershipTransferRequest true "Confirmation token"
+// @Success 200 {object} models.OwnershipTransfer
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ownership-transfer/confirm [post]
+func (h *OrganizationsHandler) ConfirmOwnershipTransfer(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.ConfirmOwnershipTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokenHash := hashTransferToken(req.Token)
+	var record struct {
+		ID            int64         `db:"id"`
+		FromUserID    sql.NullInt64 `db:"from_user_id"`
+		ToUserID      int64         `db:"to_user_id"`
+		FromTokenHash string        `db:"from_token_hash"`
+		Status        string        `db:"status"`
+		ExpiresAt     time.Time     `db:"expires_at"`
+	}
+	err := db.DB.Get(&record, `
+		SELECT id, from_user_id, to_user_id, from_token_hash, status, expires_at
+		FROM organization_ownership_transfers
+		WHERE from_token_hash = $1 OR to_token_hash = $1
+	`, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invalid confirmation token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ownership transfer"})
+		return
+	}
+	if record.Status != "pending" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ownership transfer is " + record.Status})
+		return
+	}
+	if time.Now().After(record.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Confirmation token has expired"})
+		return
+	}
+
+	confirmColumn, partyID := "to_confirmed_at", record.ToUserID
+	if tokenHash == record.FromTokenHash {
+		confirmColumn, partyID = "from_confirmed_at", record.FromUserID.Int64
+	}
+	if partyID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This confirmation was sent to another user"})
+		return
+	}
+
+	// confirmColumn is one of two fixed column names
+	_, err = db.DB.Exec(`
+		UPDATE organization_ownership_transfers SET `+confirmColumn+` = COALESCE(`+confirmColumn+`, NOW())
+		WHERE id = $1 AND status = 'pending'
+	`, record.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm ownership transfer"})
+		return
+	}
+
+	transfer, err := getOwnershipTransfer(record.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ownership transfer"})
+		return
+	}
+	auditOwnershipTransfer(c, "ownership_transfer_confirmed", "info", transfer)
+
+	if transfer.FromConfirmedAt != nil && transfer.ToConfirmedAt != nil {
+		if failure := completeOwnershipTransfer(transfer); failure != nil {
+			c.JSON(failure.Status, failure.Body)
+			return
+		}
+		if transfer, err = getOwnershipTransfer(record.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ownership transfer"})
+			return
+		}
+		auditOwnershipTransfer(c, "ownership_transfer_completed", "high", transfer)
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// completeOwnershipTransfer makes the new owner the organization's owner and an admin.
+// The previous owner stays an admin.
+func completeOwnershipTransfer(transfer models.OwnershipTransfer) *actionError {
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		return newActionError(http.StatusInternalServerError, "Failed to complete ownership transfer")
+	}
+	defer tx.Rollback()
+
+	var isMember bool
+	err = tx.Get(&isMember, `
+		SELECT EXISTS(SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2)
+	`, transfer.OrganizationID, transfer.ToUserID)
+	if err != nil {
+		return newActionError(http.StatusInternalServerError, "Failed to complete ownership transfer")
+	}
+	if !isMember {
+		return newActionError(http.StatusConflict, "The new owner is no longer a member of the organization")
+	}
+
+	for _, stmt := range []string{
+		"UPDATE organizations SET owner_id = $2, updated_at = NOW() WHERE id = $1",
+		"UPDATE organization_members SET role = 'admin' WHERE organization_id = $1 AND user_id = $2",
+		// users.role mirrors the role in the user's default organization
+		"UPDATE users SET role = 'admin', updated_at = NOW() WHERE id = $2 AND organization_id = $1",
+	} {
+		if _, err := tx.Exec(stmt, transfer.OrganizationID, transfer.ToUserID); err != nil {
+			return newActionError(http.StatusInternalServerError, "Failed to complete ownership transfer")
+		}
+	}
+	_, err = tx.Exec(`
+		UPDATE organization_ownership_transfers SET status = 'completed', completed_at = NOW()
+		WHERE id = $1
+	`, transfer.ID)
+	if err != nil {
+		return newActionError(http.StatusInternalServerError, "Failed to complete ownership transfer")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return newActionError(http.StatusInternalServerError, "Failed to complete ownership transfer")
+	}
+	return nil
+}
+
+// CancelOwnershipTransfer cancels the organization's pending ownership transfer
+// @Summary Cancel ownership transfer
+// @Description Cancel the pending ownership transfer; its confirmation links stop working
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/ownership-transfer [delete]
+func (h *OrganizationsHandler) CancelOwnershipTransfer(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var transferID int64
+	err := db.DB.Get(&transferID, `
+		UPDATE organization_ownership_transfers SET status = 'cancelled'
+		WHERE organization_id = $1 AND status = 'pending'
+		RETURNING id
+	`, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No pending ownership transfer"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel ownership transfer"})
+		return
+	}
+
+	if transfer, err := getOwnershipTransfer(transferID); err == nil {
+		auditOwnershipTransfer(c, "ownership_transfer_cancelled", "info", transfer)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transfer cancelled"})
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// PatternExemptionRequest whitelists a pattern on a route, either explicitly or from a
+// recorded detection (detection_id)
+type PatternExemptionRequest struct {
+	DetectionID    *int64 `json:"detection_id"`
+	OrganizationID *int64 `json:"organization_id"`
+	Route          string `json:"route"`
+	Method         string `json:"method"`
+	PatternType    string `json:"pattern_type"`
+	Pattern        string `json:"pattern"`
+	Reason         string `json:"reason"`
+}
+
+// GetPatternDetections lists blocked and shadow-mode requests for review (admin only)
+func (h *SecurityHandler) GetPatternDetections(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	mode := c.Query("mode")
+	if mode != "" && mode != "blocked" && mode != "shadow" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be blocked or shadow"})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	var orgID *int64
+	if o := c.Query("organization_id"); o != "" {
+		id, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization_id"})
+			return
+		}
+		orgID = &id
+	}
+
+	detections, err := security.ListPatternDetections(mode, orgID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pattern detections"})
+		return
+	}
+
+	if detections == nil {
+		detections = []security.PatternDetection{}
+	}
+
+	c.JSON(http.StatusOK, detections)
+}
+
+// GetPatternExemptions lists whitelisted patterns (admin only)
+func (h *SecurityHandler) GetPatternExemptions(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	exemptions, err := security.ListPatternExemptions(nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pattern exemptions"})
+		return
+	}
+
+	if exemptions == nil {
+		exemptions = []security.PatternExemption{}
+	}
+
+	c.JSON(http.StatusOK, exemptions)
+}
+
+// CreatePatternExemption whitelists a pattern on a route (admin only)
+func (h *SecurityHandler) CreatePatternExemption(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	var req PatternExemptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exemption := security.PatternExemption{
+		OrganizationID: req.OrganizationID,
+		Route:          req.Route,
+		Method:         req.Method,
+		PatternType:    req.PatternType,
+		Pattern:        req.Pattern,
+		Reason:         req.Reason,
+	}
+
+	// Whitelisting a recorded detection copies its route and pattern
+	if req.DetectionID != nil {
+		detection, err := security.GetPatternDetection(*req.DetectionID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Detection not found"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch detection"})
+			return
+		}
+		if exemption.Route == "" {
+			exemption.Route = detection.Endpoint
+		}
+		if exemption.Method == "" {
+			exemption.Method = detection.Method
+		}
+		if exemption.PatternType == "" {
+			exemption.PatternType = detection.PatternType
+		}
+		if exemption.Pattern == "" {
+			exemption.Pattern = detection.Pattern
+		}
+		if exemption.OrganizationID == nil {
+			exemption.OrganizationID = detection.OrganizationID
+		}
+	}
+
+	if err := security.ValidatePatternExemption(&exemption); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	exemption.CreatedBy = &userID
+	if err := security.CreatePatternExemption(&exemption); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pattern exemption"})
+		return
+	}
+
+	h.guardian.ReloadPolicies()
+	c.JSON(http.StatusCreated, exemption)
+}
+
+// DeletePatternExemption removes a whitelisted pattern (admin only)
+func (h *SecurityHandler) DeletePatternExemption(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exemption ID"})
+		return
+	}
+
+	if err := security.DeletePatternExemption(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exemption not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete pattern exemption"})
+		return
+	}
+
+	h.guardian.ReloadPolicies()
+	c.JSON(http.StatusOK, gin.H{"message": "Pattern exemption deleted"})
+}
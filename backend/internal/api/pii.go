@@ -0,0 +1,204 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+type PIIHandler struct{}
+
+func NewPIIHandler() *PIIHandler {
+	return &PIIHandler{}
+}
+
+// PIIRuleRequest is the body for creating or updating a PII rule
+type PIIRuleRequest struct {
+	Name         string `json:"name" binding:"required"`
+	RuleType     string `json:"rule_type" binding:"required"` // column, pattern
+	Pattern      string `json:"pattern" binding:"required"`
+	MaskStrategy string `json:"mask_strategy"` // redact, hash, partial
+	IsActive     *bool  `json:"is_active"`
+}
+
+// PIIPreviewRequest is the body for previewing how a value would be masked
+type PIIPreviewRequest struct {
+	Column string `json:"column"`
+	Value  string `json:"value" binding:"required"`
+}
+
+// GetRules returns the organization's PII rules
+func (h *PIIHandler) GetRules(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var rules []security.PIIRule
+	err := db.DB.Select(&rules, `
+		SELECT id, organization_id, name, rule_type, pattern, mask_strategy, is_active, created_at, updated_at
+		FROM pii_rules
+		WHERE organization_id = $1
+		ORDER BY created_at
+	`, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch PII rules"})
+		return
+	}
+
+	if rules == nil {
+		rules = []security.PIIRule{}
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule adds a PII rule to the organization's policy
+func (h *PIIHandler) CreateRule(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req PIIRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := security.PIIRule{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		RuleType:       req.RuleType,
+		Pattern:        req.Pattern,
+		MaskStrategy:   req.MaskStrategy,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+	}
+	if err := security.ValidatePIIRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.DB.QueryRow(`
+		INSERT INTO pii_rules (organization_id, name, rule_type, pattern, mask_strategy, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, rule.OrganizationID, rule.Name, rule.RuleType, rule.Pattern, rule.MaskStrategy, rule.IsActive).
+		Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create PII rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule replaces a PII rule
+func (h *PIIHandler) UpdateRule(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	var req PIIRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := security.PIIRule{
+		ID:             id,
+		OrganizationID: orgID,
+		Name:           req.Name,
+		RuleType:       req.RuleType,
+		Pattern:        req.Pattern,
+		MaskStrategy:   req.MaskStrategy,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+	}
+	if err := security.ValidatePIIRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = db.DB.QueryRow(`
+		UPDATE pii_rules
+		SET name = $1, rule_type = $2, pattern = $3, mask_strategy = $4, is_active = $5, updated_at = NOW()
+		WHERE id = $6 AND organization_id = $7
+		RETURNING created_at, updated_at
+	`, rule.Name, rule.RuleType, rule.Pattern, rule.MaskStrategy, rule.IsActive, id, orgID).
+		Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "PII rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update PII rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule removes a PII rule
+func (h *PIIHandler) DeleteRule(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM pii_rules WHERE id = $1 AND organization_id = $2", id, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete PII rule"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "PII rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PII rule deleted"})
+}
+
+// Preview shows how a sample value would be masked under the organization's current policy
+func (h *PIIHandler) Preview(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req PIIPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	masker, err := security.LoadPIIMasker(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load PII policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"column":           req.Column,
+		"masked":           masker.MaskValue(req.Column, req.Value),
+		"sensitive_column": req.Column != "" && masker.IsSensitiveColumn(req.Column),
+	})
+}
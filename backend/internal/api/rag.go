@@ -0,0 +1,133 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type RAGHandler struct{}
+
+func NewRAGHandler() *RAGHandler {
+	return &RAGHandler{}
+}
+
+// formatVector renders an embedding in pgvector's text input format ('[0.1,0.2,...]')
+func formatVector(embedding []float64) string {
+	parts := make([]string, len(embedding))
+	for i, v := range embedding {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// Search runs a pgvector cosine-similarity search over schema and transformation embeddings
+// @Summary RAG similarity search
+// @Description Embed a query via the AI service and return the most similar schema and transformation patterns from past migrations
+// @Tags rag
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param type query string false "Restrict to 'schema' or 'transformation'"
+// @Param limit query int false "Maximum matches per type (default 10, max 50)"
+// @Success 200 {object} models.RAGSearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /rag/search [get]
+func (h *RAGHandler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+
+	searchType := c.DefaultQuery("type", "all")
+	if searchType != "all" && searchType != "schema" && searchType != "transformation" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of: all, schema, transformation"})
+		return
+	}
+
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 50 {
+			limit = parsed
+		}
+	}
+
+	orgID := middleware.GetOrganizationID(c)
+
+	// The query text goes only to the organization's data region
+	aiClient, err := organizationAIClient(db.DB, orgID)
+	if err != nil {
+		aiClientError(c, err)
+		return
+	}
+	if aiClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not available"})
+		return
+	}
+
+	embedding, err := aiClient.Embed(query)
+	if err != nil {
+		log.Printf("[RAG] Failed to embed search query: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to embed search query"})
+		return
+	}
+	vector := formatVector(embedding.Embedding)
+
+	response := models.RAGSearchResponse{
+		Query:           query,
+		Schemas:         []models.SchemaMatch{},
+		Transformations: []models.TransformationMatch{},
+	}
+
+	// Results are scoped to the caller's organization so patterns never leak across tenants
+	if searchType == "all" || searchType == "schema" {
+		err = db.DB.Select(&response.Schemas, `
+			SELECT id, source_type, source_name, migration_id,
+			       1 - (embedding <=> $1::vector) AS similarity
+			FROM schema_embeddings
+			WHERE organization_id = $2 AND embedding IS NOT NULL
+			ORDER BY embedding <=> $1::vector
+			LIMIT $3
+		`, vector, orgID, limit)
+		if err != nil {
+			log.Printf("[RAG] Schema similarity search failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "RAG search is not available"})
+			return
+		}
+	}
+
+	if searchType == "all" || searchType == "transformation" {
+		err = db.DB.Select(&response.Transformations, `
+			SELECT id, source_sql, target_sql, transformation_type,
+			       COALESCE(quality_score, 0) AS quality_score, migration_id,
+			       1 - (embedding <=> $1::vector) AS similarity
+			FROM transformation_embeddings
+			WHERE organization_id = $2 AND embedding IS NOT NULL
+			ORDER BY embedding <=> $1::vector
+			LIMIT $3
+		`, vector, orgID, limit)
+		if err != nil {
+			log.Printf("[RAG] Transformation similarity search failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "RAG search is not available"})
+			return
+		}
+	}
+
+	if response.Schemas == nil {
+		response.Schemas = []models.SchemaMatch{}
+	}
+	if response.Transformations == nil {
+		response.Transformations = []models.TransformationMatch{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -3,17 +3,20 @@ package api
 import (
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
 	"github.com/datamigrate-ai/backend/internal/metrics"
 	"github.com/datamigrate-ai/backend/internal/middleware"
 	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/datamigrate-ai/backend/internal/settings"
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func SetupRouter(cfg *config.Config) *gin.Engine {
@@ -32,38 +35,35 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	// Prometheus metrics middleware (before other middleware)
 	router.Use(metrics.PrometheusMiddleware())
 
+	// gzip responses and request bodies; before the Guardian so it scans decompressed bodies
+	router.Use(middleware.Compression())
+
 	// Initialize Guardian Agent (Security Layer)
 	guardian := security.GetGuardian()
 	router.Use(guardian.Middleware())
 
+	// Runtime settings: ALLOWED_ORIGINS is the default, admins can override it in server_settings
+	settings.Init(settings.Values{
+		AllowedOrigins:        cfg.AllowedOrigins,
+		AllowedOriginSuffixes: []string{".railway.app"},
+		CORSMaxAge:            86400,
+		AIMigrationSlots:      cfg.AIServiceMigrationSlots,
+	})
+	// Verified custom domains; the product's own hosts can't be claimed
+	domains.Init(reservedHosts(cfg))
+
 	// CORS middleware
 	router.Use(func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
+		runtime := settings.Current()
 
-		// Check if origin is allowed
-		allowed := false
-		for _, o := range cfg.AllowedOrigins {
-			if o == origin {
-				allowed = true
-				break
-			}
-		}
-
-		// Also allow Railway domains and same-origin requests
-		if !allowed && origin != "" {
-			// Allow *.railway.app domains
-			if strings.HasSuffix(origin, ".railway.app") ||
-				strings.HasSuffix(origin, ".up.railway.app") {
-				allowed = true
-			}
-		}
-
-		if allowed {
+		if runtime.OriginAllowed(origin) || domains.OriginAllowed(origin) {
 			c.Header("Access-Control-Allow-Origin", origin)
 			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, Accept")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Encoding, Authorization, Accept, X-Device-ID, If-None-Match")
+			c.Header("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After, ETag")
 			c.Header("Access-Control-Allow-Credentials", "true")
-			c.Header("Access-Control-Max-Age", "86400")
+			c.Header("Access-Control-Max-Age", strconv.Itoa(runtime.CORSMaxAge))
 		}
 
 		if c.Request.Method == http.MethodOptions {
@@ -81,17 +81,24 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 	// Prometheus metrics endpoint
 	router.GET("/metrics", metrics.Handler())
+	// Prometheus alerting rules for the SLO metrics, with thresholds from the query
+	router.GET("/metrics/alerts", metrics.AlertsHandler(metrics.DefaultSLOTargets))
 
-	// Swagger documentation endpoint
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Swagger documentation endpoint (SWAGGER_MODE), without the internal routes
+	registerSwagger(router, cfg)
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 
 	// Create handlers
 	authHandler := NewAuthHandler(cfg)
-	migrationsHandler := NewMigrationsHandler()
-	connectionsHandler := NewConnectionsHandler()
+	migrationsHandler := NewMigrationsHandler(db.DB)
+	connectionsHandler := NewConnectionsHandler(db.DB)
+	seedsHandler := NewSeedsHandler(db.DB, cfg.SeedMaxRows)
+	exposuresHandler := NewExposuresHandler(db.DB)
+	dbtCloudHandler := NewDbtCloudHandler(db.DB)
+	commentsHandler := NewCommentsHandler(db.DB)
+	shareLinksHandler := NewShareLinksHandler(db.DB)
 	apiKeysHandler := NewAPIKeysHandler()
 	securityHandler := NewSecurityHandler()
 
@@ -99,43 +106,140 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	auth := v1.Group("/auth")
 	auth.POST("/register", authHandler.Register)
 	auth.POST("/login", authHandler.Login)
+	auth.POST("/refresh", authHandler.Refresh)
 	auth.POST("/forgot-password", authHandler.ForgotPassword)
 	auth.POST("/reset-password", authHandler.ResetPassword)
+	auth.GET("/captcha-config", authHandler.GetCaptchaConfig)
+	auth.GET("/oauth/providers", authHandler.GetOAuthProviders)
+	auth.GET("/oauth/:provider/authorize", authHandler.StartOAuthLogin)
+	auth.POST("/oauth/:provider/callback", authHandler.OAuthCallback)
+	auth.POST("/report-login", authHandler.ReportLogin)
+
+	// Organization logos (public, loaded by email clients and dbt docs sites)
+	v1.GET("/branding/logos/:id", NewOrganizationsHandler().GetLogo)
+	// The organization a custom domain belongs to, for its sign-in page
+	v1.GET("/site", NewOrganizationsHandler().GetSiteBranding)
+	// Read-only migration summaries and docs behind share links
+	v1.GET("/shared/:token", shareLinksHandler.GetShared)
+	v1.GET("/shared/:token/files/*filepath", shareLinksHandler.GetSharedFile)
 
 	// Protected routes
 	protected := v1.Group("")
+	protected.Use(security.APIKeyAuthMiddleware())
 	protected.Use(middleware.AuthMiddleware())
+	protected.Use(security.OrganizationMiddleware())
+	protected.Use(guardian.IPAllowlistMiddleware())
+	protected.Use(security.ImpersonationMiddleware())
 
 	// Auth (protected)
 	protected.GET("/auth/me", authHandler.GetCurrentUser)
 	protected.POST("/auth/logout", authHandler.Logout)
 	protected.PUT("/auth/profile", authHandler.UpdateProfile)
 	protected.PUT("/auth/password", authHandler.ChangePassword)
+	protected.GET("/auth/organizations", authHandler.GetOrganizations)
+	protected.POST("/auth/switch-organization", authHandler.SwitchOrganization)
+	protected.GET("/auth/identities", authHandler.GetIdentities)
+	protected.GET("/auth/devices", authHandler.GetLoginDevices)
+
+	// Impersonation by support staff
+	impersonationHandler := NewImpersonationHandler()
+	protected.GET("/auth/impersonations", impersonationHandler.GetMine)
+	protected.POST("/auth/impersonation/end", impersonationHandler.End)
+	protected.DELETE("/auth/identities/:provider", authHandler.UnlinkIdentity)
+	protected.POST("/auth/oauth/:provider/link", authHandler.StartOAuthLink)
 
 	// Migrations
 	migrations := protected.Group("/migrations")
-	migrations.GET("", migrationsHandler.GetAll)
+	migrations.GET("", middleware.ETag(), migrationsHandler.GetAll)
 	migrations.GET("/:id", migrationsHandler.GetOne)
+	migrations.GET("/:id/status", migrationsHandler.GetStatus)
 	migrations.POST("", migrationsHandler.Create)
+	migrations.POST("/snapshots/preview", migrationsHandler.PreviewSnapshots)
+	migrations.GET("/scaffolding/options", migrationsHandler.GetScaffoldingOptions)
+	migrations.POST("/scaffolding/preview", migrationsHandler.PreviewScaffolding)
+	migrations.POST("/bulk/delete", migrationsHandler.BulkDelete)
+	migrations.POST("/bulk/start", migrationsHandler.BulkStart)
 	migrations.DELETE("/:id", migrationsHandler.Delete)
 	migrations.POST("/:id/start", migrationsHandler.Start)
+	migrations.POST("/:id/plan", migrationsHandler.Plan)
 	migrations.POST("/:id/stop", migrationsHandler.Stop)
-	migrations.GET("/:id/files", migrationsHandler.GetFiles)
-	migrations.GET("/:id/files/*filepath", migrationsHandler.GetFileContent)
+	migrations.POST("/:id/rerun", migrationsHandler.Rerun)
+	migrations.POST("/:id/resume", migrationsHandler.Resume)
+	migrations.GET("/:id/runs", middleware.ETag(), migrationsHandler.GetRuns)
+	migrations.GET("/:id/runs/:run", middleware.ETag(), migrationsHandler.GetRun)
+	migrations.GET("/:id/runs/:run/diff/:other", middleware.ETag(), migrationsHandler.DiffRuns)
+	migrations.GET("/:id/files", middleware.ETag(), migrationsHandler.GetFiles)
+	migrations.GET("/:id/files/*filepath", middleware.ETag(), migrationsHandler.GetFileContent)
 	migrations.GET("/:id/download", migrationsHandler.DownloadProject)
+	migrations.GET("/:id/docs/overview", migrationsHandler.GetDocsOverview)
+	migrations.GET("/:id/share-links", shareLinksHandler.GetAll)
+	migrations.POST("/:id/share-links", shareLinksHandler.Create)
+	migrations.DELETE("/:id/share-links/:linkId", shareLinksHandler.Revoke)
+	migrations.GET("/:id/seeds", seedsHandler.GetAll)
+	migrations.GET("/:id/seeds/candidates", seedsHandler.GetCandidates)
+	migrations.POST("/:id/seeds", seedsHandler.Export)
+	migrations.GET("/:id/exposures", exposuresHandler.GetAll)
+	migrations.POST("/:id/exposures", exposuresHandler.Create)
+	migrations.GET("/:id/exposures/yaml", exposuresHandler.GetYAML)
+	migrations.POST("/:id/exposures/discover", exposuresHandler.Discover)
+	migrations.PUT("/:id/exposures/:exposureId", exposuresHandler.Update)
+	migrations.DELETE("/:id/exposures/:exposureId", exposuresHandler.Delete)
+	migrations.GET("/:id/dbt-cloud", dbtCloudHandler.Get)
+	migrations.POST("/:id/dbt-cloud", dbtCloudHandler.Create)
+	migrations.POST("/:id/dbt-cloud/run", dbtCloudHandler.Run)
+	migrations.GET("/:id/deployments", dbtCloudHandler.GetDeployments)
+	migrations.GET("/:id/metrics", migrationsHandler.GetMetrics)
+	migrations.GET("/:id/tables", migrationsHandler.GetTables)
+	migrations.GET("/:id/test-coverage", migrationsHandler.GetTestCoverage)
+	migrations.PUT("/:id/test-coverage", migrationsHandler.UpdateTestCoverage)
+	migrations.GET("/:id/scaffolding", migrationsHandler.GetScaffolding)
+	migrations.PUT("/:id/scaffolding", migrationsHandler.UpdateScaffolding)
+	migrations.GET("/:id/report.pdf", migrationsHandler.GetReportPDF)
+	migrations.GET("/:id/drift", migrationsHandler.GetDrift)
+	migrations.GET("/:id/comments", commentsHandler.GetAll)
+	migrations.POST("/:id/comments", commentsHandler.Create)
+	migrations.DELETE("/:id/comments/:commentId", commentsHandler.Delete)
+
+	// Deployments
+	protected.GET("/deployments/:id/errors", dbtCloudHandler.GetDeploymentErrors)
+	protected.POST("/deployments/:id/retry-failed", dbtCloudHandler.RetryFailed)
 
 	// Stats
 	protected.GET("/stats", migrationsHandler.GetStats)
+	protected.GET("/stats/export", migrationsHandler.ExportStats)
 
 	// Database connections
 	connections := protected.Group("/connections")
-	connections.GET("", connectionsHandler.GetAll)
+	connections.GET("", middleware.ETag(), connectionsHandler.GetAll)
 	connections.GET("/:id", connectionsHandler.GetOne)
 	connections.POST("", connectionsHandler.Create)
 	connections.PUT("/:id", connectionsHandler.Update)
 	connections.DELETE("/:id", connectionsHandler.Delete)
+	connections.GET("/:id/dependents", connectionsHandler.GetDependents)
 	connections.POST("/:id/test", connectionsHandler.Test)
+	connections.GET("/:id/tests", connectionsHandler.GetTests)
+	connections.POST("/bulk/test", connectionsHandler.BulkTest)
 	connections.GET("/:id/metadata", connectionsHandler.GetMetadata)
+	connections.GET("/:id/metadata/dependencies", connectionsHandler.GetDependencies)
+	connections.POST("/:id/data-quality/scan", connectionsHandler.ScanDataQuality)
+
+	// Global search
+	searchHandler := NewSearchHandler(db.DB)
+	protected.GET("/search", searchHandler.Search)
+
+	// Tags on migrations and connections
+	tagsHandler := NewTagsHandler(db.DB)
+	protected.GET("/tags", tagsHandler.GetAll)
+	protected.POST("/tags/bulk", tagsHandler.Bulk)
+
+	// The user's own API requests, from the audit log
+	accountHandler := NewAccountHandler(db.DB)
+	protected.GET("/account/api-activity", accountHandler.GetAPIActivity)
+
+	// Migration lifecycle events for polling triggers (Zapier, Make)
+	eventsHandler := NewEventsHandler(db.DB)
+	protected.GET("/events", eventsHandler.GetAll)
+	protected.GET("/events/types", eventsHandler.GetTypes)
 
 	// API Keys
 	apiKeys := protected.Group("/api-keys")
@@ -147,6 +251,69 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	// AI Chat (proxies to Python AI service)
 	chatHandler := NewChatHandler(cfg)
 	protected.POST("/chat", chatHandler.Chat)
+	chatActionsHandler := NewChatActionsHandler(db.DB)
+	protected.POST("/chat/actions/:id/confirm", chatActionsHandler.Confirm)
+	protected.POST("/chat/actions/:id/cancel", chatActionsHandler.Cancel)
+
+	// Query translation playground (proxies to Python AI service)
+	translateHandler := NewTranslateHandler(db.DB)
+	protected.POST("/translate", translateHandler.Translate)
+	protected.GET("/translate/history", translateHandler.GetHistory)
+	protected.DELETE("/translate/history/:id", translateHandler.DeleteHistory)
+
+	// SQL lint for the in-browser editor
+	lintHandler := NewLintHandler(db.DB)
+	protected.POST("/lint", lintHandler.Lint)
+
+	// RAG similarity search (pgvector)
+	ragHandler := NewRAGHandler()
+	protected.GET("/rag/search", ragHandler.Search)
+
+	// Organization settings (organization admins)
+	organizationsHandler := NewOrganizationsHandler()
+	organizations := protected.Group("/organizations")
+	organizations.GET("/settings", organizationsHandler.GetSettings)
+	organizations.PUT("/settings", organizationsHandler.UpdateSettings)
+	organizations.PUT("/settings/logo", organizationsHandler.UploadLogo)
+	organizations.DELETE("/settings/logo", organizationsHandler.DeleteLogo)
+	organizations.GET("/domains", organizationsHandler.GetDomains)
+	organizations.POST("/domains", organizationsHandler.AddDomain)
+	organizations.POST("/domains/:id/verify", organizationsHandler.VerifyDomain)
+	organizations.DELETE("/domains/:id", organizationsHandler.DeleteDomain)
+	organizations.GET("/ticketing", organizationsHandler.GetTicketing)
+	organizations.PUT("/ticketing", organizationsHandler.UpdateTicketing)
+	organizations.DELETE("/ticketing", organizationsHandler.DeleteTicketing)
+	organizations.GET("/ownership-transfer", organizationsHandler.GetOwnershipTransfer)
+	organizations.POST("/ownership-transfer", organizationsHandler.RequestOwnershipTransfer)
+	organizations.DELETE("/ownership-transfer", organizationsHandler.CancelOwnershipTransfer)
+	organizations.POST("/ownership-transfer/confirm", organizationsHandler.ConfirmOwnershipTransfer)
+	organizations.GET("/password-policy", organizationsHandler.GetPasswordPolicy)
+	organizations.PUT("/password-policy", organizationsHandler.UpdatePasswordPolicy)
+	organizations.GET("/ip-allowlist", organizationsHandler.GetIPAllowlist)
+	organizations.POST("/ip-allowlist", organizationsHandler.AddIPAllowlistEntry)
+	organizations.DELETE("/ip-allowlist/:id", organizationsHandler.DeleteIPAllowlistEntry)
+	organizations.GET("/usage", organizationsHandler.GetUsage)
+	organizations.GET("/usage/ai-tokens", organizationsHandler.GetAITokenUsage)
+	organizations.GET("/service-accounts", organizationsHandler.GetServiceAccounts)
+	organizations.POST("/service-accounts", organizationsHandler.CreateServiceAccount)
+	organizations.DELETE("/service-accounts/:id", organizationsHandler.DeleteServiceAccount)
+	organizations.GET("/service-accounts/:id/api-keys", organizationsHandler.GetServiceAccountKeys)
+	organizations.POST("/service-accounts/:id/api-keys", organizationsHandler.CreateServiceAccountKey)
+	organizations.DELETE("/service-accounts/:id/api-keys/:keyId", organizationsHandler.DeleteServiceAccountKey)
+
+	// PII masking policy (organization admins)
+	piiHandler := NewPIIHandler()
+	pii := protected.Group("/pii")
+	pii.GET("/rules", piiHandler.GetRules)
+	pii.POST("/rules", piiHandler.CreateRule)
+	pii.PUT("/rules/:id", piiHandler.UpdateRule)
+	pii.DELETE("/rules/:id", piiHandler.DeleteRule)
+	pii.POST("/preview", piiHandler.Preview)
+
+	// Column masking policy (read by members, edited by organization admins)
+	maskingHandler := NewMaskingHandler(db.DB)
+	protected.GET("/masking/policy", maskingHandler.GetPolicy)
+	protected.PUT("/masking/policy", maskingHandler.UpdatePolicy)
 
 	// Security routes (admin only)
 	securityRoutes := protected.Group("/security")
@@ -156,10 +323,62 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	securityRoutes.POST("/validate", securityHandler.ValidateInput)
 	securityRoutes.GET("/rate-limit", securityHandler.GetRateLimitStatus)
 	securityRoutes.POST("/reload-policies", securityHandler.ReloadPolicies)
-
-	// Internal routes (for AI service communication - no auth required)
-	internal := v1.Group("/internal")
-	internal.PATCH("/migrations/:id/status", migrationsHandler.UpdateStatus)
+	securityRoutes.GET("/policies", securityHandler.GetPolicies)
+	securityRoutes.POST("/policies", securityHandler.CreatePolicy)
+	securityRoutes.PUT("/policies/:id", securityHandler.UpdatePolicy)
+	securityRoutes.DELETE("/policies/:id", securityHandler.DeletePolicy)
+	securityRoutes.GET("/pattern-detections", securityHandler.GetPatternDetections)
+	securityRoutes.GET("/pattern-exemptions", securityHandler.GetPatternExemptions)
+	securityRoutes.POST("/pattern-exemptions", securityHandler.CreatePatternExemption)
+	securityRoutes.DELETE("/pattern-exemptions/:id", securityHandler.DeletePatternExemption)
+	securityRoutes.GET("/alerts", securityHandler.GetAlerts)
+	securityRoutes.POST("/alerts/:id/acknowledge", securityHandler.AcknowledgeAlert)
+	securityRoutes.POST("/alerts/:id/resolve", securityHandler.ResolveAlert)
+
+	// Admin routes (admin only)
+	aiInteractionsHandler := NewAIInteractionsHandler()
+	adminRoutes := protected.Group("/admin")
+	adminRoutes.GET("/ai-interactions", aiInteractionsHandler.GetAll)
+	adminRoutes.GET("/ai-interactions/export", aiInteractionsHandler.Export)
+
+	// Email delivery (outbox)
+	emailsHandler := NewEmailsHandler()
+	adminRoutes.GET("/emails", emailsHandler.GetAll)
+	adminRoutes.POST("/emails/:id/retry", emailsHandler.Retry)
+
+	// Runtime server settings (CORS origins and other hot-reloadable settings)
+	settingsHandler := NewSettingsHandler()
+	adminRoutes.GET("/settings", settingsHandler.GetAll)
+	adminRoutes.PUT("/settings/:key", settingsHandler.Update)
+	adminRoutes.DELETE("/settings/:key", settingsHandler.Reset)
+	adminRoutes.POST("/settings/reload", settingsHandler.Reload)
+
+	// Platform-wide KPI export
+	adminRoutes.GET("/stats/export", migrationsHandler.ExportAdminStats)
+
+	// Server diagnostics
+	diagnosticsHandler := NewDiagnosticsHandler(db.DB)
+	adminRoutes.GET("/diagnostics/password-hashing", diagnosticsHandler.PasswordHashing)
+
+	// Grafana dashboards built from the Prometheus metrics
+	observabilityHandler := NewObservabilityHandler()
+	adminRoutes.GET("/observability/dashboards", observabilityHandler.GetDashboards)
+
+	// Data residency: pin organizations to a region and report what ran outside it
+	dataResidencyHandler := NewDataResidencyHandler(db.DB)
+	adminRoutes.PUT("/organizations/:id/data-region", dataResidencyHandler.SetOrganizationRegion)
+	adminRoutes.GET("/data-residency", dataResidencyHandler.Report)
+
+	// Act as a user for support
+	adminRoutes.POST("/impersonate", impersonationHandler.Start)
+	adminRoutes.GET("/impersonations", impersonationHandler.GetAll)
+
+	// Internal routes (for AI service communication). With internal TLS configured
+	// they're only served by SetupInternalRouter; on the public port they need the
+	// service token.
+	if !cfg.InternalTLSEnabled() {
+		registerInternalRoutes(v1.Group("/internal"), migrationsHandler, security.RequireServiceToken(cfg.InternalServiceToken))
+	}
 
 	// Serve static frontend files if STATIC_DIR is configured
 	if cfg.StaticDir != "" {
@@ -194,3 +413,38 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 	return router
 }
+
+// reservedHosts are the product's own hosts, which organizations can't claim as custom
+// domains
+func reservedHosts(cfg *config.Config) []string {
+	hosts := append([]string{}, cfg.TLSAutocertDomains...)
+	for _, origin := range append([]string{cfg.OAuthRedirectBaseURL}, cfg.AllowedOrigins...) {
+		if u, err := url.Parse(origin); err == nil && u.Hostname() != "" {
+			hosts = append(hosts, u.Hostname())
+		}
+	}
+	return hosts
+}
+
+// SetupInternalRouter serves the internal routes to AI service clients that present a
+// certificate accepted by security.RequireClientCertificate. The listener itself must
+// require verified client certificates (security.MutualTLSServerConfig).
+func SetupInternalRouter(cfg *config.Config) *gin.Engine {
+	router := gin.Default()
+	router.Use(security.RequireClientCertificate(cfg.InternalTLSClientNames))
+
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok", "service": "datamigrate-api-internal"})
+	})
+	registerInternalRoutes(router.Group("/api/v1/internal"), NewMigrationsHandler(db.DB))
+
+	return router
+}
+
+// registerInternalRoutes adds the internal routes behind serviceAuth, which the public
+// port needs and the mutual TLS listener already enforces
+func registerInternalRoutes(internal *gin.RouterGroup, migrationsHandler *MigrationsHandler, serviceAuth ...gin.HandlerFunc) {
+	internal.Use(serviceAuth...)
+	internal.PATCH("/migrations/:id/status", migrationsHandler.UpdateStatus)
+	internal.POST("/ai-usage", migrationsHandler.ReportAIUsage)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 25
+	maxSearchTerms     = 10
+)
+
+// searchGroups are the result groups of GET /search and the query for each. Every
+// query takes the user ID ($1), the prefix tsquery ($2), the limit ($3) and the active
+// organization ($4), and only matches resources the user owns in that organization.
+var searchGroups = []struct {
+	name  string
+	query string
+}{
+	{"migrations", `
+		SELECT 'migration' AS type, m.id, NULL::bigint AS migration_id, m.name AS title,
+		       m.status || ' · ' || coalesce(m.source_database, '') AS snippet,
+		       ts_rank(m.search_vector, q) AS rank
+		FROM migrations m, to_tsquery('simple', $2) q
+		WHERE m.user_id = $1 AND COALESCE(m.organization_id, 0) = $4 AND m.search_vector @@ q
+		ORDER BY rank DESC, m.updated_at DESC
+		LIMIT $3`},
+	{"connections", `
+		SELECT 'connection' AS type, dc.id, NULL::bigint AS migration_id, dc.name AS title,
+		       dc.db_type || ' · ' || dc.host || '/' || dc.database_name AS snippet,
+		       ts_rank(dc.search_vector, q) AS rank
+		FROM database_connections dc, to_tsquery('simple', $2) q
+		WHERE dc.user_id = $1 AND COALESCE(dc.organization_id, 0) = $4 AND dc.search_vector @@ q
+		ORDER BY rank DESC, dc.updated_at DESC
+		LIMIT $3`},
+	{"files", `
+		SELECT 'file' AS type, f.id, f.migration_id, f.path AS title, m.name AS snippet,
+		       ts_rank(f.search_vector, q) AS rank
+		FROM migration_files f
+		JOIN migrations m ON m.id = f.migration_id, to_tsquery('simple', $2) q
+		WHERE m.user_id = $1 AND COALESCE(m.organization_id, 0) = $4 AND f.search_vector @@ q
+		ORDER BY rank DESC, f.path
+		LIMIT $3`},
+	{"comments", `
+		SELECT 'comment' AS type, mc.id, mc.migration_id, m.name AS title,
+		       ts_headline('english', mc.body, q, 'MaxFragments=1, MaxWords=20, MinWords=5') AS snippet,
+		       ts_rank(mc.search_vector, q) AS rank
+		FROM migration_comments mc
+		JOIN migrations m ON m.id = mc.migration_id, to_tsquery('english', $2) q
+		WHERE m.user_id = $1 AND COALESCE(m.organization_id, 0) = $4 AND mc.search_vector @@ q
+		ORDER BY rank DESC, mc.created_at DESC
+		LIMIT $3`},
+}
+
+type SearchHandler struct {
+	db db.Querier
+}
+
+func NewSearchHandler(store db.Querier) *SearchHandler {
+	return &SearchHandler{db: store}
+}
+
+// Search runs a full-text search across the user's resources
+// @Summary Search
+// @Description Full-text search across migrations, connections, generated file names and comments. Each word matches as a prefix, so "stg cust" finds stg_customers.sql.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param types query string false "Groups to search, comma-separated: migrations, connections, files, comments (default all)"
+// @Param limit query int false "Results per group (default 5, max 25)"
+// @Success 200 {object} models.SearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+
+	tsquery := prefixTSQuery(c.Query("q"))
+	if tsquery == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must contain at least one letter or digit"})
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxSearchLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	wanted := map[string]bool{}
+	if types := c.Query("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			wanted[strings.TrimSpace(t)] = true
+		}
+	}
+
+	resp := models.SearchResponse{Query: c.Query("q"), Results: map[string][]models.SearchResult{}}
+	for _, group := range searchGroups {
+		if len(wanted) > 0 && !wanted[group.name] {
+			continue
+		}
+		results := []models.SearchResult{}
+		if err := h.db.Select(&results, group.query, userID, tsquery, limit, orgID); err != nil {
+			log.Printf("Search of %s failed: %v", group.name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+			return
+		}
+		resp.Results[group.name] = results
+		resp.Total += len(results)
+	}
+	if len(resp.Results) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "types must include migrations, connections, files or comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// prefixTSQuery turns free text into a tsquery matching every word as a prefix. Only
+// letters and digits are kept, so the result is safe to pass to to_tsquery.
+func prefixTSQuery(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// indexMigrationFiles records a completed migration's generated file paths so they
+// can be searched without asking the AI service
+func indexMigrationFiles(store db.Querier, migrationID int64) {
+	aiClient, err := migrationAIClient(store, migrationID)
+	if err != nil {
+		log.Printf("Failed to index files of migration %d: %v", migrationID, err)
+		return
+	}
+	if aiClient == nil {
+		return
+	}
+	files, err := aiClient.GetMigrationFiles(migrationID)
+	if err != nil {
+		log.Printf("Failed to list files for migration %d: %v", migrationID, err)
+		return
+	}
+
+	var paths, types []string
+	var sizes []int64
+	for _, f := range files.Files {
+		paths = append(paths, f.Path)
+		types = append(types, f.Type)
+		sizes = append(sizes, f.Size)
+	}
+
+	if _, err := store.Exec(
+		"DELETE FROM migration_files WHERE migration_id = $1 AND NOT (path = ANY($2))",
+		migrationID, pq.StringArray(paths),
+	); err != nil {
+		log.Printf("Failed to prune file index for migration %d: %v", migrationID, err)
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	_, err = store.Exec(`
+		INSERT INTO migration_files (migration_id, path, file_type, size)
+		SELECT $1, f.path, f.file_type, f.size
+		FROM unnest($2::text[], $3::text[], $4::bigint[]) AS f(path, file_type, size)
+		ON CONFLICT (migration_id, path) DO UPDATE
+		SET file_type = EXCLUDED.file_type, size = EXCLUDED.size, indexed_at = NOW()
+	`, migrationID, pq.StringArray(paths), pq.StringArray(types), pq.Array(sizes))
+	if err != nil {
+		log.Printf("Failed to index files for migration %d: %v", migrationID, err)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+var searchColumns = []string{"type", "id", "migration_id", "title", "snippet", "rank"}
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"stg_customers":        "stg:* & customers:*",
+		"  Sales  Orders ":     "sales:* & orders:*",
+		"o'brien & !(x | y)":   "o:* & brien:* & x:* & y:*",
+		"models/staging/*.sql": "models:* & staging:* & sql:*",
+		"---":                  "",
+	}
+	for q, want := range tests {
+		if got := prefixTSQuery(q); got != want {
+			t.Errorf("prefixTSQuery(%q) = %q, want %q", q, got, want)
+		}
+	}
+}
+
+func TestSearchGroupsResults(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`FROM migrations m`).
+		WithArgs(testUserID, "cust:*", 5, testOrgID).
+		WillReturnRows(sqlmock.NewRows(searchColumns).AddRow("migration", 3, nil, "Customers", "completed · AdventureWorks", 0.6))
+	mock.ExpectQuery(`FROM migration_files f`).
+		WithArgs(testUserID, "cust:*", 5, testOrgID).
+		WillReturnRows(sqlmock.NewRows(searchColumns).AddRow("file", 9, 3, "models/staging/stg_customers.sql", "Customers", 0.3))
+
+	status, body := serve(t, "GET", "/search", "/search?q=cust&types=migrations,files", nil, NewSearchHandler(store).Search)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp models.SearchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 2 || len(resp.Results) != 2 {
+		t.Fatalf("response = %+v", resp)
+	}
+	file := resp.Results["files"][0]
+	if file.MigrationID == nil || *file.MigrationID != 3 || file.Title != "models/staging/stg_customers.sql" {
+		t.Errorf("file result = %+v", file)
+	}
+}
+
+func TestSearchRequiresQuery(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/search", "/search?q=%20", nil, NewSearchHandler(store).Search)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
 	"strconv"
 	"time"
@@ -37,6 +38,9 @@ func (h *SecurityHandler) GetAuditLogs(c *gin.Context) {
 	if severity := c.Query("severity"); severity != "" {
 		filters["severity"] = severity
 	}
+	if actorType := c.Query("actor_type"); actorType != "" {
+		filters["actor_type"] = actorType
+	}
 	if blocked := c.Query("blocked"); blocked != "" {
 		filters["blocked"] = blocked == "true"
 	}
@@ -48,6 +52,17 @@ func (h *SecurityHandler) GetAuditLogs(c *gin.Context) {
 		}
 	}
 
+	// Page with the cursor from the previous response; offset is still accepted but
+	// gets slower the deeper it goes
+	if cursor := c.Query("cursor"); cursor != "" {
+		before, err := security.ParseAuditCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		filters["before"] = before
+	}
+
 	offset := 0
 	if o := c.Query("offset"); o != "" {
 		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
@@ -61,7 +76,12 @@ func (h *SecurityHandler) GetAuditLogs(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	response := gin.H{"logs": logs, "count": len(logs)}
+	if len(logs) == limit {
+		last := logs[len(logs)-1]
+		response["next_cursor"] = security.AuditCursor{CreatedAt: last.Timestamp, ID: last.ID}.String()
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetSecurityStats returns security statistics
@@ -177,7 +197,19 @@ func (h *SecurityHandler) ReloadPolicies(c *gin.Context) {
 	})
 }
 
-// GetSecurityDashboard returns a comprehensive security dashboard
+// dashboardWindows maps the supported dashboard periods to their chart bucket size
+var dashboardWindows = map[string]struct {
+	period time.Duration
+	bucket time.Duration
+}{
+	"1h":  {time.Hour, 5 * time.Minute},
+	"6h":  {6 * time.Hour, 15 * time.Minute},
+	"24h": {24 * time.Hour, time.Hour},
+	"7d":  {7 * 24 * time.Hour, 6 * time.Hour},
+	"30d": {30 * 24 * time.Hour, 24 * time.Hour},
+}
+
+// GetSecurityDashboard returns time-bucketed security series for charts
 func (h *SecurityHandler) GetSecurityDashboard(c *gin.Context) {
 	// Only admins can view security dashboard
 	if !middleware.IsAdmin(c) {
@@ -185,21 +217,110 @@ func (h *SecurityHandler) GetSecurityDashboard(c *gin.Context) {
 		return
 	}
 
-	stats := h.guardian.GetSecurityStats()
+	window, ok := dashboardWindows[c.DefaultQuery("period", "24h")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be one of: 1h, 6h, 24h, 7d, 30d"})
+		return
+	}
 
-	// Get recent blocked events
-	blockedFilters := map[string]interface{}{"blocked": true}
-	blockedEvents, _ := h.guardian.GetAuditLogs(blockedFilters, 10, 0)
+	var orgID *int64
+	if o := c.Query("organization_id"); o != "" {
+		id, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization_id"})
+			return
+		}
+		orgID = &id
+	}
 
-	// Get recent critical events
-	criticalFilters := map[string]interface{}{"severity": "critical"}
-	criticalEvents, _ := h.guardian.GetAuditLogs(criticalFilters, 10, 0)
+	dashboard, err := h.guardian.GetDashboard(orgID, window.period, window.bucket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build security dashboard"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"stats":                stats,
-		"recent_blocked":       blockedEvents,
-		"recent_critical":      criticalEvents,
-		"guardian_status":      "active",
-		"last_updated":         time.Now().Format(time.RFC3339),
+		"stats":           h.guardian.GetSecurityStats(),
+		"dashboard":       dashboard,
+		"guardian_status": "active",
+		"last_updated":    dashboard.GeneratedAt.UTC().Format(time.RFC3339),
 	})
 }
+
+// GetAlerts returns anomaly alerts (admin only)
+func (h *SecurityHandler) GetAlerts(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	var orgID *int64
+	if o := c.Query("organization_id"); o != "" {
+		id, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization_id"})
+			return
+		}
+		orgID = &id
+	}
+
+	alerts, err := security.ListAlerts(c.Query("status"), c.Query("severity"), orgID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve security alerts"})
+		return
+	}
+
+	if alerts == nil {
+		alerts = []security.SecurityAlert{}
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// AcknowledgeAlert marks an open alert as acknowledged (admin only)
+func (h *SecurityHandler) AcknowledgeAlert(c *gin.Context) {
+	h.updateAlertStatus(c, "acknowledged")
+}
+
+// ResolveAlert marks an alert as resolved (admin only)
+func (h *SecurityHandler) ResolveAlert(c *gin.Context) {
+	h.updateAlertStatus(c, "resolved")
+}
+
+func (h *SecurityHandler) updateAlertStatus(c *gin.Context, status string) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	if err := security.UpdateAlertStatus(id, middleware.GetUserID(c), status); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found or already " + status})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert " + status, "id": id, "status": status})
+}
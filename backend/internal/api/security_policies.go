@@ -0,0 +1,201 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityPolicyRequest is the payload for creating or updating a Guardian policy
+type SecurityPolicyRequest struct {
+	Name           string                 `json:"name" binding:"required"`
+	Description    string                 `json:"description"`
+	PolicyType     string                 `json:"policy_type"`
+	Rules          map[string]interface{} `json:"rules" binding:"required"`
+	IsActive       *bool                  `json:"is_active"`
+	OrganizationID *int64                 `json:"organization_id"`
+}
+
+// policyScope resolves which organization the caller may manage. Platform admins
+// manage global policies (nil) or any organization; organization admins only their own.
+func policyScope(c *gin.Context, requested *int64) (*int64, bool, bool) {
+	if middleware.IsAdmin(c) {
+		return requested, true, true
+	}
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return nil, false, false
+	}
+	return &orgID, false, true
+}
+
+// canManagePolicy checks that a policy belongs to the caller's scope
+func canManagePolicy(c *gin.Context, policy *security.SecurityPolicy) bool {
+	if middleware.IsAdmin(c) {
+		return true
+	}
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return false
+	}
+	if policy.OrganizationID == nil || *policy.OrganizationID != orgID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+		return false
+	}
+	return true
+}
+
+// GetPolicies lists Guardian policies visible to the caller
+func (h *SecurityHandler) GetPolicies(c *gin.Context) {
+	var requested *int64
+	if o := c.Query("organization_id"); o != "" {
+		id, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization_id"})
+			return
+		}
+		requested = &id
+	}
+
+	orgID, _, ok := policyScope(c, requested)
+	if !ok {
+		return
+	}
+
+	policies, err := security.ListPolicies(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve security policies"})
+		return
+	}
+
+	if policies == nil {
+		policies = []security.SecurityPolicy{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies":  policies,
+		"effective": h.guardian.EffectivePolicyFor(orgID),
+	})
+}
+
+// CreatePolicy adds a Guardian policy for an organization or globally
+func (h *SecurityHandler) CreatePolicy(c *gin.Context) {
+	var req SecurityPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orgID, platformAdmin, ok := policyScope(c, req.OrganizationID)
+	if !ok {
+		return
+	}
+
+	if err := security.ValidatePolicyRules(req.PolicyType, req.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !platformAdmin && h.guardian.LoosensGlobalPolicy(req.PolicyType, req.Rules) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Organization policies may only tighten the global policy"})
+		return
+	}
+
+	policy := security.SecurityPolicy{
+		Name:           req.Name,
+		Description:    req.Description,
+		PolicyType:     req.PolicyType,
+		Rules:          req.Rules,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+		OrganizationID: orgID,
+	}
+	if err := security.SavePolicy(&policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create security policy"})
+		return
+	}
+
+	h.guardian.ReloadPolicies()
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdatePolicy changes a Guardian policy's rules, name or active flag
+func (h *SecurityHandler) UpdatePolicy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	var req SecurityPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := security.GetPolicy(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch security policy"})
+		return
+	}
+	if !canManagePolicy(c, policy) {
+		return
+	}
+
+	if err := security.ValidatePolicyRules(policy.PolicyType, req.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !middleware.IsAdmin(c) && h.guardian.LoosensGlobalPolicy(policy.PolicyType, req.Rules) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Organization policies may only tighten the global policy"})
+		return
+	}
+
+	policy.Name = req.Name
+	policy.Description = req.Description
+	policy.Rules = req.Rules
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+	if err := security.SavePolicy(policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update security policy"})
+		return
+	}
+
+	h.guardian.ReloadPolicies()
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeletePolicy removes a Guardian policy
+func (h *SecurityHandler) DeletePolicy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	policy, err := security.GetPolicy(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch security policy"})
+		return
+	}
+	if !canManagePolicy(c, policy) {
+		return
+	}
+
+	if err := security.DeletePolicy(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete security policy"})
+		return
+	}
+
+	h.guardian.ReloadPolicies()
+	c.JSON(http.StatusOK, gin.H{"message": "Security policy deleted"})
+}
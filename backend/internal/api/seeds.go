@@ -0,0 +1,284 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Seed statuses
+const (
+	SeedExported = "exported"
+	SeedFailed   = "failed"
+)
+
+// SeedsHandler offers small lookup tables as dbt seeds and exports them into the
+// migration's project
+type SeedsHandler struct {
+	db          db.Querier
+	connections *ConnectionsHandler // Connection loading, SSRF checks and password decryption
+	maxRows     int
+}
+
+func NewSeedsHandler(store db.Querier, maxRows int) *SeedsHandler {
+	return &SeedsHandler{
+		db:          store,
+		connections: NewConnectionsHandler(store),
+		maxRows:     maxRows,
+	}
+}
+
+// migrationSource loads the migration and its source connection. On failure it writes
+// the error response and returns false.
+func (h *SeedsHandler) migrationSource(c *gin.Context) (int64, *metadataConnection, bool) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return 0, nil, false
+	}
+
+	var connectionID int64
+	err = h.db.Get(&connectionID, `
+		SELECT dc.id
+		FROM migrations m
+		JOIN database_connections dc ON dc.id = m.connection_id AND dc.user_id = m.user_id
+		WHERE m.id = $1 AND m.user_id = $2 AND COALESCE(m.organization_id, 0) = $3
+	`, id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Migration or source database connection not found"})
+			return 0, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return 0, nil, false
+	}
+
+	connection, ok := h.connections.metadataConnection(c, connectionID, userID)
+	return id, connection, ok
+}
+
+// GetCandidates lists small tables that look like static lookup data
+// @Summary List seed candidates
+// @Description Find source tables at or below the row threshold that are referenced by foreign keys or named like lookup tables
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param max_rows query int false "Row threshold (defaults to, and is capped at, SEED_MAX_ROWS)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /migrations/{id}/seeds/candidates [get]
+func (h *SeedsHandler) GetCandidates(c *gin.Context) {
+	id, connection, ok := h.migrationSource(c)
+	if !ok {
+		return
+	}
+
+	maxRows := h.maxRows
+	if v, err := strconv.Atoi(c.Query("max_rows")); err == nil && v > 0 && v < maxRows {
+		maxRows = v
+	}
+
+	metadata := dbtest.ExtractMetadataContext(c.Request.Context(), h.connections.metadataParams(connection), dbtest.ExtractOptions{
+		IncludeForeignKeys: true,
+	})
+	if !metadata.Success {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read source metadata", "details": metadata.Error})
+		return
+	}
+
+	seeded := []string{}
+	if err := h.db.Select(&seeded, `
+		SELECT source_table FROM migration_seeds WHERE migration_id = $1 AND status = $2
+	`, id, SeedExported); err != nil {
+		log.Printf("Failed to list seeds for migration %d: %v", id, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"max_rows":   maxRows,
+		"candidates": dbtest.FindSeedCandidates(metadata, int64(maxRows)),
+		"seeded":     seeded,
+		"warnings":   metadata.Warnings,
+	})
+}
+
+// Export streams tables from the source into the project as seed CSVs
+// @Summary Export tables as dbt seeds
+// @Description Stream each table from the source database to the AI service as seeds/<name>.csv. Tables over SEED_MAX_ROWS are rejected.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.ExportSeedsRequest true "Tables to export"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /migrations/{id}/seeds [post]
+func (h *SeedsHandler) Export(c *gin.Context) {
+	var req models.ExportSeedsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Seeds share the ref() namespace, so two tables mustn't map to one name
+	names := map[string]string{}
+	for _, table := range req.Tables {
+		if !strings.Contains(table, ".") || dbtgen.SeedName(table) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tables must be schema-qualified, e.g. dbo.OrderStatus"})
+			return
+		}
+		name := dbtgen.SeedName(table)
+		if other, ok := names[name]; ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tables " + other + " and " + table + " would both be seeded as " + name})
+			return
+		}
+		names[name] = table
+	}
+
+	id, connection, ok := h.migrationSource(c)
+	if !ok {
+		return
+	}
+
+	// Seeds go to the instance the migration will run on, in the organization's region
+	aiClient, err := organizationAIClient(h.db, middleware.GetOrganizationID(c))
+	if err != nil {
+		aiClientError(c, err)
+		return
+	}
+	if aiClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service is not available"})
+		return
+	}
+
+	params := h.connections.metadataParams(connection)
+	userID := middleware.GetUserID(c)
+	results := []models.MigrationSeed{}
+	for _, table := range req.Tables {
+		seed := h.exportSeed(c.Request.Context(), aiClient, id, userID, params, table)
+		results = append(results, seed)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"seeds": results})
+}
+
+// exportSeed streams one table to the AI service and records the outcome
+func (h *SeedsHandler) exportSeed(ctx context.Context, aiClient *aiservice.Client, migrationID, userID int64, params dbtest.ConnectionParams, table string) models.MigrationSeed {
+	schema, name, _ := strings.Cut(table, ".")
+	seedName := dbtgen.SeedName(table)
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	// The CSV is piped straight from the source query into the upload; an export
+	// error aborts the upload so a partial CSV is never stored
+	reader, writer := io.Pipe()
+	rowsCh := make(chan int, 1)
+	go func() {
+		rows, err := dbtest.ExportTableCSV(ctx, params, schema, name, h.maxRows, writer)
+		rowsCh <- rows
+		writer.CloseWithError(err)
+	}()
+
+	uploadErr := aiClient.UploadSeed(migrationID, seedName, reader)
+	reader.CloseWithError(io.ErrClosedPipe) // Unblock the exporter if the upload gave up early
+	rows := <-rowsCh
+
+	status := SeedExported
+	var errMsg *string
+	if uploadErr != nil {
+		status = SeedFailed
+		rows = 0
+		msg := uploadErr.Error()
+		if strings.Contains(msg, dbtest.ErrTooManyRows.Error()) {
+			msg = "Table has more than " + strconv.Itoa(h.maxRows) + " rows, the seed limit"
+		}
+		errMsg = &msg
+		log.Printf("Seed export of %s for migration %d failed: %v", table, migrationID, uploadErr)
+	}
+
+	seed := models.MigrationSeed{MigrationID: migrationID, SourceTable: table, SeedName: seedName, Status: status, RowCount: rows, Error: errMsg}
+	err := h.db.QueryRow(`
+		INSERT INTO migration_seeds (migration_id, source_table, seed_name, status, row_count, error, exported_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (migration_id, source_table) DO UPDATE
+		SET seed_name = EXCLUDED.seed_name, status = EXCLUDED.status, row_count = EXCLUDED.row_count,
+		    error = EXCLUDED.error, exported_by = EXCLUDED.exported_by, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`, migrationID, table, seedName, status, rows, errMsg, userID).Scan(&seed.ID, &seed.CreatedAt, &seed.UpdatedAt)
+	if err != nil {
+		log.Printf("Failed to record seed %s for migration %d: %v", table, migrationID, err)
+	}
+	return seed
+}
+
+// GetAll lists the tables seeded for a migration
+// @Summary List migration seeds
+// @Description List tables exported as dbt seeds for a migration, including failed exports
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {array} models.MigrationSeed
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/seeds [get]
+func (h *SeedsHandler) GetAll(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3)", id, userID, orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+		return
+	}
+
+	seeds, err := listSeeds(h.db, id, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seeds"})
+		return
+	}
+	c.JSON(http.StatusOK, seeds)
+}
+
+// listSeeds returns a migration's seeds, optionally only those with status
+func listSeeds(store db.Querier, migrationID int64, status string) ([]models.MigrationSeed, error) {
+	seeds := []models.MigrationSeed{}
+	err := store.Select(&seeds, `
+		SELECT id, migration_id, source_table, seed_name, status, row_count, error, created_at, updated_at
+		FROM migration_seeds
+		WHERE migration_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY source_table
+	`, migrationID, status)
+	return seeds, err
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAccount is a non-human organization member that authenticates only with API keys
+type ServiceAccount struct {
+	ID          int64      `db:"id" json:"id"`
+	Name        string     `db:"name" json:"name"`
+	Description string     `db:"description" json:"description,omitempty"`
+	Email       string     `db:"email" json:"email"`
+	IsActive    bool       `db:"is_active" json:"is_active"`
+	CreatedBy   *int64     `db:"created_by" json:"created_by,omitempty"`
+	KeyCount    int        `db:"key_count" json:"key_count"`
+	LastUsedAt  *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// CreateServiceAccountRequest is the payload for creating a service account
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=100"`
+}
+
+// GetServiceAccounts lists the organization's service accounts
+// @Summary List service accounts
+// @Description List non-human accounts used by CI/CD pipelines
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} ServiceAccount
+// @Failure 403 {object} map[string]string
+// @Router /organizations/service-accounts [get]
+func (h *OrganizationsHandler) GetServiceAccounts(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	accounts := []ServiceAccount{}
+	err := db.DB.Select(&accounts, `
+		SELECT u.id, COALESCE(u.first_name, '') as name, COALESCE(u.job_title, '') as description,
+		       u.email, u.is_active, u.created_by, u.created_at,
+		       COUNT(k.id) as key_count, MAX(k.last_used_at) as last_used_at
+		FROM users u
+		LEFT JOIN api_keys k ON k.user_id = u.id
+		WHERE u.organization_id = $1 AND u.account_type = 'service'
+		GROUP BY u.id
+		ORDER BY u.created_at DESC
+	`, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// CreateServiceAccount adds a service account to the organization
+// @Summary Create service account
+// @Description Create a non-human account that owns API keys and cannot log in interactively
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateServiceAccountRequest true "Service account"
+// @Success 201 {object} ServiceAccount
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /organizations/service-accounts [post]
+func (h *OrganizationsHandler) CreateServiceAccount(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account"})
+		return
+	}
+
+	// Service accounts get a reserved, undeliverable address and no usable password
+	// (not a bcrypt hash), so neither login nor password reset can ever succeed
+	account := ServiceAccount{
+		Name:        req.Name,
+		Description: req.Description,
+		Email:       "svc-" + hex.EncodeToString(suffix) + "@service-accounts.invalid",
+		IsActive:    true,
+	}
+	createdBy := middleware.GetUserID(c)
+	account.CreatedBy = &createdBy
+
+	// The name and description live in first_name and job_title
+	err := db.DB.QueryRow(`
+		WITH account AS (
+			INSERT INTO users (email, password, first_name, job_title, organization_id, role, account_type, created_by)
+			VALUES ($1, '!', $2, NULLIF($3, ''), $4, 'member', 'service', $5)
+			RETURNING id, created_at
+		), membership AS (
+			INSERT INTO organization_members (organization_id, user_id, role)
+			SELECT $4, id, 'member' FROM account
+		)
+		SELECT id, created_at FROM account
+	`, account.Email, account.Name, account.Description, orgID, createdBy).Scan(&account.ID, &account.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// DeleteServiceAccount removes a service account and revokes its API keys
+// @Summary Delete service account
+// @Description Delete a service account; its API keys stop working immediately
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Service account ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/service-accounts/{id} [delete]
+func (h *OrganizationsHandler) DeleteServiceAccount(c *gin.Context) {
+	accountID, ok := h.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	// api_keys cascade on user delete; migrations the account created are kept
+	if _, err := db.DB.Exec("DELETE FROM users WHERE id = $1 AND account_type = 'service'", accountID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deleted"})
+}
+
+// GetServiceAccountKeys lists a service account's API keys
+// @Summary List service account API keys
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Service account ID"
+// @Success 200 {array} models.APIKey
+// @Router /organizations/service-accounts/{id}/api-keys [get]
+func (h *OrganizationsHandler) GetServiceAccountKeys(c *gin.Context) {
+	accountID, ok := h.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := fetchAPIKeys(accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateServiceAccountKey issues an API key owned by a service account
+// @Summary Create service account API key
+// @Description Issue a scoped API key for a pipeline; the full key is returned once
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Service account ID"
+// @Param request body models.CreateAPIKeyRequest true "API key"
+// @Success 201 {object} map[string]interface{}
+// @Router /organizations/service-accounts/{id}/api-keys [post]
+func (h *OrganizationsHandler) CreateServiceAccountKey(c *gin.Context) {
+	accountID, ok := h.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	issueAPIKey(c, accountID)
+}
+
+// DeleteServiceAccountKey revokes one of a service account's API keys
+// @Summary Revoke service account API key
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Service account ID"
+// @Param keyId path int true "API key ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /organizations/service-accounts/{id}/api-keys/{keyId} [delete]
+func (h *OrganizationsHandler) DeleteServiceAccountKey(c *gin.Context) {
+	accountID, ok := h.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.ParseInt(c.Param("keyId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM api_keys WHERE id = $1 AND user_id = $2", keyID, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete API key"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key deleted"})
+}
+
+// serviceAccountID resolves the :id path parameter to a service account in the
+// caller's organization. It writes the error response itself and returns ok=false.
+func (h *OrganizationsHandler) serviceAccountID(c *gin.Context) (int64, bool) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return 0, false
+	}
+
+	var accountID int64
+	err = db.DB.Get(&accountID, `
+		SELECT id FROM users WHERE id = $1 AND organization_id = $2 AND account_type = 'service'
+	`, id, orgID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return 0, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service account"})
+		return 0, false
+	}
+
+	return accountID, true
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// userOrgContext is the organization loaded on a user by loadUserOrganization, for
+// access token claims
+func userOrgContext(user models.User) middleware.OrgContext {
+	if user.OrganizationID == nil {
+		return middleware.OrgContext{}
+	}
+	org := middleware.OrgContext{ID: *user.OrganizationID, Role: user.Role}
+	if user.Organization != nil {
+		org.Plan = user.Organization.Plan
+	}
+	return org
+}
+
+// issueSession signs a short-lived access token for the user in the organization loaded
+// on them and stores a refresh token that renews it for JWT_EXPIRATION_HOURS. A session
+// with an expired password can only change it until it's refreshed after the change.
+func (h *AuthHandler) issueSession(user models.User, passwordExpired bool) (models.LoginResponse, error) {
+	org := userOrgContext(user)
+	ttl := time.Duration(h.cfg.JWTAccessTTLMinutes) * time.Minute
+	var accessToken string
+	var err error
+	if passwordExpired {
+		accessToken, err = middleware.GeneratePasswordExpiredToken(user.ID, user.Email, org, ttl)
+	} else {
+		accessToken, err = middleware.GenerateToken(user.ID, user.Email, user.IsAdmin, org, ttl)
+	}
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return models.LoginResponse{}, err
+	}
+	refreshToken := hex.EncodeToString(b)
+	_, err = db.DB.Exec(`
+		INSERT INTO refresh_tokens (user_id, organization_id, token_hash, expires_at, password_expired)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5)
+	`, user.ID, org.ID, hashRefreshToken(refreshToken), time.Now().Add(time.Duration(h.cfg.JWTExpiration)*time.Hour), passwordExpired)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		AccessToken:     accessToken,
+		RefreshToken:    refreshToken,
+		ExpiresIn:       int(ttl.Seconds()),
+		User:            user,
+		PasswordExpired: passwordExpired,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+// @Summary Refresh session
+// @Description Exchange a refresh token for a new access token. The refresh token is single-use: the response carries its replacement, and reusing a spent token signs out the whole session. The new access token reflects the user's current role and organization plan.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var stored struct {
+		ID              int64         `db:"id"`
+		UserID          int64         `db:"user_id"`
+		OrganizationID  sql.NullInt64 `db:"organization_id"`
+		ExpiresAt       time.Time     `db:"expires_at"`
+		PasswordExpired bool          `db:"password_expired"`
+	}
+	err := db.DB.Get(&stored, `
+		SELECT id, user_id, organization_id, expires_at, password_expired
+		FROM refresh_tokens WHERE token_hash = $1
+	`, hashRefreshToken(req.RefreshToken))
+	if err == sql.ErrNoRows || (err == nil && stored.ExpiresAt.Before(time.Now())) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Spend the token; a token that was already spent has been copied, so end the session
+	result, err := db.DB.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", stored.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		revokeRefreshTokens(db.DB, stored.UserID)
+		security.GetGuardian().LogSecurityEvent(&security.SecurityEvent{
+			EventType:      "refresh_token_reuse",
+			Severity:       "high",
+			UserID:         &stored.UserID,
+			IPAddress:      c.ClientIP(),
+			UserAgent:      c.Request.UserAgent(),
+			Endpoint:       c.Request.URL.Path,
+			Method:         c.Request.Method,
+			ResponseStatus: http.StatusUnauthorized,
+			Metadata:       map[string]interface{}{"refresh_token_id": stored.ID},
+		})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+
+	var user models.User
+	err = db.DB.Get(&user, `
+		SELECT id, email, first_name, last_name, job_title, phone,
+		       organization_id, role, is_admin, is_active, COALESCE(preferred_language, 'en') as preferred_language,
+		       COALESCE(timezone, 'UTC') as timezone, last_login_at, created_at, updated_at
+		FROM users WHERE id = $1`, stored.UserID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !user.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+		return
+	}
+	if lockedAt, err := security.AccountLockedAt(user.ID); err == nil && lockedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":  "This account was locked after a sign-in was reported. Reset your password to unlock it.",
+			"locked": true,
+		})
+		return
+	}
+
+	// Stay in the session's organization while the user is still a member of it
+	orgID, err := security.ResolveUserOrganization(user.ID, stored.OrganizationID.Int64)
+	if err == nil && orgID == 0 && stored.OrganizationID.Valid {
+		orgID, err = loginOrganizationID(user.ID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	loadUserOrganization(&user, orgID)
+
+	// A restricted session is released once the password has been changed
+	passwordExpired := stored.PasswordExpired && security.IsPasswordExpired(user.ID, orgID)
+
+	response, err := h.issueSession(user, passwordExpired)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// revokeRefreshTokens ends every session of a user; their access tokens stop working
+// when they expire
+func revokeRefreshTokens(exec sqlx.Execer, userID int64) {
+	if _, err := exec.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+		log.Printf("Failed to revoke refresh tokens of user %d: %v", userID, err)
+	}
+}
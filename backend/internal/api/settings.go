@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/settings"
+	"github.com/gin-gonic/gin"
+)
+
+type SettingsHandler struct{}
+
+func NewSettingsHandler() *SettingsHandler {
+	return &SettingsHandler{}
+}
+
+// UpdateSettingRequest sets a runtime setting
+type UpdateSettingRequest struct {
+	Value json.RawMessage `json:"value" binding:"required"`
+}
+
+// GetAll lists runtime settings (admin only)
+// @Summary List server settings
+// @Description List runtime-tunable settings with their effective values and environment defaults
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /admin/settings [get]
+func (h *SettingsHandler) GetAll(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings.List()})
+}
+
+// Update overrides a setting; it applies on this instance immediately and on others
+// within a minute (admin only)
+// @Summary Update server setting
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Setting key, e.g. allowed_origins"
+// @Param request body UpdateSettingRequest true "New value"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/settings/{key} [put]
+func (h *SettingsHandler) Update(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	var req UpdateSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key := c.Param("key")
+	if err := settings.Set(key, req.Value, middleware.GetUserID(c)); err != nil {
+		settingError(c, key, err)
+		return
+	}
+	log.Printf("Server setting %s updated by user %d", key, middleware.GetUserID(c))
+	c.JSON(http.StatusOK, gin.H{"settings": settings.List()})
+}
+
+// Reset removes an override so the environment default applies (admin only)
+// @Summary Reset server setting
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Setting key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/settings/{key} [delete]
+func (h *SettingsHandler) Reset(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+
+	key := c.Param("key")
+	if err := settings.Reset(key); err != nil {
+		settingError(c, key, err)
+		return
+	}
+	log.Printf("Server setting %s reset by user %d", key, middleware.GetUserID(c))
+	c.JSON(http.StatusOK, gin.H{"settings": settings.List()})
+}
+
+// Reload re-reads settings from the database without waiting for the refresh interval (admin only)
+// @Summary Reload server settings
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/settings/reload [post]
+func (h *SettingsHandler) Reload(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+	if err := settings.Reload(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings.List()})
+}
+
+func settingError(c *gin.Context, key string, err error) {
+	var validationErr *settings.ValidationError
+	switch {
+	case errors.Is(err, settings.ErrUnknownSetting):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown setting: " + key})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	default:
+		log.Printf("Failed to save server setting %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save setting"})
+	}
+}
@@ -0,0 +1,411 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtgen"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultShareLinkTTL is how long a share link works when no expiry is asked for
+const defaultShareLinkTTL = 7 * 24 * time.Hour
+
+// shareTokenRegex matches the tokens newShareToken makes, so other input never reaches
+// the database
+var shareTokenRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ShareLinksHandler manages read-only links that show a migration's summary and
+// generated docs to people without an account
+type ShareLinksHandler struct {
+	db db.Querier
+}
+
+func NewShareLinksHandler(store db.Querier) *ShareLinksHandler {
+	return &ShareLinksHandler{db: store}
+}
+
+const shareLinkColumns = `id, migration_id, label, created_by, expires_at, revoked_at, view_count, last_viewed_at, created_at`
+
+// newShareToken returns a link token and the hash stored for it
+func newShareToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(b)
+	return token, hashShareToken(token), nil
+}
+
+func hashShareToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// isSharedDocsFile reports whether a generated file is documentation a share link may
+// show: markdown docs and YAML with model and column descriptions. SQL and connection
+// profiles stay private.
+func isSharedDocsFile(p string) bool {
+	switch path.Ext(p) {
+	case ".md", ".yml", ".yaml":
+		return path.Base(p) != "profiles.yml"
+	}
+	return false
+}
+
+// migrationID parses the migration ID and checks the user owns it. On failure it
+// writes the error response and returns false.
+func (h *ShareLinksHandler) migrationID(c *gin.Context) (int64, bool) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration ID"})
+		return 0, false
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM migrations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3)", id, userID, orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch migration"})
+		return 0, false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration not found"})
+		return 0, false
+	}
+	return id, true
+}
+
+// GetAll lists a migration's share links
+// @Summary List share links
+// @Description List the read-only links to the migration's summary and docs, with how often each was viewed. Revoked and expired links are included.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Success 200 {array} models.ShareLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/share-links [get]
+func (h *ShareLinksHandler) GetAll(c *gin.Context) {
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	links := []models.ShareLink{}
+	err := h.db.Select(&links, `SELECT `+shareLinkColumns+` FROM migration_share_links WHERE migration_id = $1 ORDER BY created_at DESC, id DESC`, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch share links"})
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+// Create makes a share link
+// @Summary Create share link
+// @Description Create a read-only link to the migration's summary and generated docs for people without an account. The URL is only returned here. Links expire after 7 days unless expires_in_hours (at most 90 days) is set.
+// @Tags migrations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param request body models.CreateShareLinkRequest false "Label and expiry"
+// @Success 201 {object} models.ShareLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/share-links [post]
+func (h *ShareLinksHandler) Create(c *gin.Context) {
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateShareLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ttl := defaultShareLinkTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	token, tokenHash, err := newShareToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+	userID := middleware.GetUserID(c)
+	var link models.ShareLink
+	err = h.db.Get(&link, `
+		INSERT INTO migration_share_links (migration_id, created_by, token_hash, label, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+shareLinkColumns, id, userID, tokenHash, strings.TrimSpace(req.Label), time.Now().Add(ttl))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+
+	// Links made on an organization's custom domain point back at it
+	link.URL = email.PublicURL("/shared/" + token)
+	if base := domains.RequestBaseURL(c.Request); base != "" {
+		link.URL = base + "/shared/" + token
+	}
+	auditShareLink(c, "share_link_created", http.StatusCreated, link)
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// Revoke stops a share link from working
+// @Summary Revoke share link
+// @Description Revoke a share link. Anyone opening it afterwards gets 404. The link stays listed with its view count.
+// @Tags migrations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Migration ID"
+// @Param linkId path int true "Share link ID"
+// @Success 200 {object} models.ShareLink
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /migrations/{id}/share-links/{linkId} [delete]
+func (h *ShareLinksHandler) Revoke(c *gin.Context) {
+	id, ok := h.migrationID(c)
+	if !ok {
+		return
+	}
+	linkID, err := strconv.ParseInt(c.Param("linkId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+		return
+	}
+
+	var link models.ShareLink
+	err = h.db.Get(&link, `
+		UPDATE migration_share_links SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND migration_id = $2
+		RETURNING `+shareLinkColumns, linkID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+		return
+	}
+	auditShareLink(c, "share_link_revoked", http.StatusOK, link)
+
+	c.JSON(http.StatusOK, link)
+}
+
+// auditShareLink records the creation or revocation of a share link in the security
+// audit log, since it exposes a migration outside the organization
+func auditShareLink(c *gin.Context, eventType string, status int, link models.ShareLink) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	event := &security.SecurityEvent{
+		EventType:      eventType,
+		Severity:       "info",
+		UserID:         &userID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Endpoint:       c.Request.URL.Path,
+		Method:         c.Request.Method,
+		ResponseStatus: status,
+		Metadata: map[string]interface{}{
+			"migration_id":  link.MigrationID,
+			"share_link_id": link.ID,
+			"expires_at":    link.ExpiresAt,
+		},
+	}
+	if orgID > 0 {
+		event.OrganizationID = &orgID
+	}
+	security.GetGuardian().LogSecurityEvent(event)
+}
+
+// sharedLink resolves the token in the URL to the migration it shows and when the link
+// expires. Opening the summary counts as a view. Unknown, revoked and expired links all
+// get the same 404.
+func (h *ShareLinksHandler) sharedLink(c *gin.Context, countView bool) (int64, time.Time, bool) {
+	var link struct {
+		MigrationID int64     `db:"migration_id"`
+		ExpiresAt   time.Time `db:"expires_at"`
+	}
+	token := c.Param("token")
+	if !shareTokenRegex.MatchString(token) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found or expired"})
+		return 0, time.Time{}, false
+	}
+
+	query := `
+		SELECT migration_id, expires_at FROM migration_share_links
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	`
+	if countView {
+		query = `
+			UPDATE migration_share_links SET view_count = view_count + 1, last_viewed_at = NOW()
+			WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			RETURNING migration_id, expires_at
+		`
+	}
+	if err := h.db.Get(&link, query, hashShareToken(token)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found or expired"})
+			return 0, time.Time{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open link"})
+		return 0, time.Time{}, false
+	}
+
+	// Shared pages are per-recipient and shouldn't outlive a revocation in a cache
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	return link.MigrationID, link.ExpiresAt, true
+}
+
+// GetShared returns what a share link shows
+// @Summary Open share link
+// @Description Read-only summary of a migration, its branded docs landing page and the list of its documentation files. No account is needed; each call counts as a view of the link.
+// @Tags shared
+// @Produce json
+// @Param token path string true "Token from the share link"
+// @Success 200 {object} models.SharedMigration
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/{token} [get]
+func (h *ShareLinksHandler) GetShared(c *gin.Context) {
+	migrationID, expiresAt, ok := h.sharedLink(c, true)
+	if !ok {
+		return
+	}
+
+	var migration struct {
+		models.SharedMigration
+		OrganizationID sql.NullInt64  `db:"organization_id"`
+		OrgName        sql.NullString `db:"organization_name"`
+		Settings       sql.NullString `db:"settings"`
+	}
+	err := h.db.Get(&migration, `
+		SELECT m.name, m.status, COALESCE(m.source_database, '') AS source_database, m.target_project,
+		       COALESCE(m.tables_count, 0) AS tables_count, COALESCE(m.views_count, 0) AS views_count,
+		       COALESCE(m.models_generated, 0) AS models_generated, m.created_at, m.completed_at,
+		       o.id AS organization_id, o.name AS organization_name, o.settings
+		FROM migrations m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN organizations o ON o.id = COALESCE(m.organization_id, u.organization_id)
+		WHERE m.id = $1
+	`, migrationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open link"})
+		return
+	}
+
+	overview := docsMigration{
+		Name:           migration.Name,
+		SourceDatabase: migration.SourceDatabase,
+		TablesCount:    migration.TablesCount,
+		ViewsCount:     migration.ViewsCount,
+		OrganizationID: migration.OrganizationID,
+		OrgName:        migration.OrgName,
+		Settings:       migration.Settings,
+	}.overview()
+	shared := migration.SharedMigration
+	shared.OrganizationName = migration.OrgName.String
+	shared.LogoURL = overview.LogoURL
+	shared.DocsOverview = dbtgen.OverviewDocs(overview)
+	shared.ExpiresAt = expiresAt
+	shared.DocsFiles = []models.ManifestFile{}
+
+	_, runID, err := loadFileManifest(h.db, migrationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open link"})
+		return
+	}
+	if runID != 0 {
+		files, err := loadManifestFiles(h.db, runID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open link"})
+			return
+		}
+		for _, f := range files {
+			if isSharedDocsFile(f.Path) {
+				shared.DocsFiles = append(shared.DocsFiles, f)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
+
+// GetSharedFile returns a documentation file through a share link
+// @Summary Get shared docs file
+// @Description Content of one of the documentation files listed by the share link, from the migration's completed run. Embedded credentials are replaced with [REDACTED]. Views of files aren't counted.
+// @Tags shared
+// @Produce json
+// @Param token path string true "Token from the share link"
+// @Param filepath path string true "File path within the project"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/{token}/files/{filepath} [get]
+func (h *ShareLinksHandler) GetSharedFile(c *gin.Context) {
+	migrationID, _, ok := h.sharedLink(c, false)
+	if !ok {
+		return
+	}
+	filePath := strings.TrimPrefix(c.Param("filepath"), "/")
+	if !isSharedDocsFile(filePath) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	var content sql.NullString
+	err := h.db.Get(&content, `
+		SELECT f.content FROM migration_run_files f
+		JOIN migrations m ON m.current_run_id = f.run_id
+		WHERE m.id = $1 AND f.path = $2
+	`, migrationID, filePath)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch file"})
+		return
+	}
+	if !content.Valid {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File is too large to view through a share link"})
+		return
+	}
+
+	redacted, _ := security.RedactSecrets(filePath, content.String)
+	c.JSON(http.StatusOK, gin.H{
+		"path":    filePath,
+		"content": redacted,
+		"size":    len(redacted),
+	})
+}
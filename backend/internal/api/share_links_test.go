@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+const testShareToken = "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0"
+
+func TestIsSharedDocsFile(t *testing.T) {
+	cases := map[string]bool{
+		"models/overview.md":            true,
+		"models/staging/schema.yml":     true,
+		"models/marts/_models.yaml":     true,
+		"dbt_project.yml":               true,
+		"profiles.yml":                  false,
+		"models/staging/stg_orders.sql": false,
+		"seeds/order_status.csv":        false,
+	}
+	for p, want := range cases {
+		if got := isSharedDocsFile(p); got != want {
+			t.Errorf("isSharedDocsFile(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestGetSharedCountsViewAndListsDocs(t *testing.T) {
+	store, mock := newMockDB(t)
+	expires := time.Now().Add(48 * time.Hour)
+	mock.ExpectQuery(`UPDATE migration_share_links SET view_count = view_count \+ 1`).
+		WithArgs(hashShareToken(testShareToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"migration_id", "expires_at"}).AddRow(7, expires))
+	mock.ExpectQuery(`FROM migrations m`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"name", "status", "source_database", "target_project", "tables_count", "views_count",
+			"models_generated", "created_at", "completed_at", "organization_id", "organization_name", "settings",
+		}).AddRow("Sales", "completed", "AdventureWorks", "sales_dbt", 12, 3, 15, time.Now(), time.Now(), testOrgID, "Contoso", nil))
+	mock.ExpectQuery(`FROM migration_runs r\s+JOIN migrations m ON m.current_run_id = r.id`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "run_number", "artifacts_path", "manifest_sha256"}).
+			AddRow(12, 2, "/projects/7", testManifestSHA))
+	mock.ExpectQuery(`FROM migration_run_files`).
+		WithArgs(int64(12)).
+		WillReturnRows(sqlmock.NewRows([]string{"path", "size", "file_type", "checksum"}).
+			AddRow("models/staging/schema.yml", 800, "yml", "cd34").
+			AddRow("models/staging/stg_orders.sql", 140, "sql", "ab12").
+			AddRow("profiles.yml", 90, "yml", "ef56"))
+
+	status, body := serve(t, "GET", "/shared/:token", "/shared/"+testShareToken, nil, NewShareLinksHandler(store).GetShared)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var shared models.SharedMigration
+	if err := json.Unmarshal(body, &shared); err != nil {
+		t.Fatal(err)
+	}
+	if shared.Name != "Sales" || shared.ModelsGenerated != 15 || shared.OrganizationName != "Contoso" {
+		t.Errorf("shared = %+v", shared)
+	}
+	if !strings.Contains(shared.DocsOverview, "{% docs __overview__ %}") {
+		t.Errorf("docs overview = %q", shared.DocsOverview)
+	}
+	if len(shared.DocsFiles) != 1 || shared.DocsFiles[0].Path != "models/staging/schema.yml" {
+		t.Errorf("docs files = %+v", shared.DocsFiles)
+	}
+}
+
+func TestGetSharedExpiredOrUnknown(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`UPDATE migration_share_links`).
+		WithArgs(hashShareToken(testShareToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"migration_id", "expires_at"}))
+
+	status, body := serve(t, "GET", "/shared/:token", "/shared/"+testShareToken, nil, NewShareLinksHandler(store).GetShared)
+	expectStatus(t, status, http.StatusNotFound, body)
+
+	// Malformed tokens never reach the database
+	status, body = serve(t, "GET", "/shared/:token", "/shared/not-a-token", nil, NewShareLinksHandler(store).GetShared)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestGetSharedFileOnlyServesDocs(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT migration_id, expires_at FROM migration_share_links`).
+		WithArgs(hashShareToken(testShareToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"migration_id", "expires_at"}).AddRow(7, time.Now().Add(time.Hour)))
+
+	status, body := serve(t, "GET", "/shared/:token/files/*filepath", "/shared/"+testShareToken+"/files/models/staging/stg_orders.sql", nil,
+		NewShareLinksHandler(store).GetSharedFile)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
+
+func TestShareLinksRevoke(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM migrations WHERE id = \$1 AND user_id = \$2 AND COALESCE\(organization_id, 0\) = \$3\)`).
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`UPDATE migration_share_links SET revoked_at`).
+		WithArgs(int64(5), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	status, body := serve(t, "DELETE", "/migrations/:id/share-links/:linkId", "/migrations/7/share-links/5", nil,
+		NewShareLinksHandler(store).Revoke)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/export"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxExportDays bounds the date range of a stats export
+const maxExportDays = 366
+
+// statsExport is a validated export request
+type statsExport struct {
+	format export.Format
+	from   time.Time
+	until  time.Time // Exclusive: the day after the requested to date
+}
+
+// parseStatsExport reads format (csv or xlsx) and from/to (YYYY-MM-DD, both inclusive).
+// The default range is the last 30 days.
+func parseStatsExport(c *gin.Context) (statsExport, error) {
+	format, err := export.ParseFormat(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		return statsExport{}, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to := today.AddDate(0, 0, -29), today
+	if f := c.Query("from"); f != "" {
+		if from, err = time.Parse("2006-01-02", f); err != nil {
+			return statsExport{}, fmt.Errorf("from must be a date like 2026-01-31")
+		}
+	}
+	if t := c.Query("to"); t != "" {
+		if to, err = time.Parse("2006-01-02", t); err != nil {
+			return statsExport{}, fmt.Errorf("to must be a date like 2026-01-31")
+		}
+	}
+	if to.Before(from) {
+		return statsExport{}, fmt.Errorf("to must not be before from")
+	}
+	if to.Sub(from) >= maxExportDays*24*time.Hour {
+		return statsExport{}, fmt.Errorf("the date range can span at most %d days", maxExportDays)
+	}
+	return statsExport{format: format, from: from, until: to.AddDate(0, 0, 1)}, nil
+}
+
+// stream writes the download headers, the header row and then each row of the query.
+// The response is committed once the first byte is written, so a failure part way is
+// logged and leaves a truncated file.
+func (e statsExport) stream(c *gin.Context, name string, header []string, rows *sqlx.Rows) {
+	defer rows.Close()
+
+	filename := fmt.Sprintf("%s-%s-%s.%s", name, e.from.Format("20060102"), e.until.AddDate(0, 0, -1).Format("20060102"), e.format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Content-Type", e.format.ContentType())
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(c.Writer, e.format, name)
+	if err != nil {
+		log.Printf("Failed to start %s export: %v", name, err)
+		return
+	}
+
+	values := make([]interface{}, len(header))
+	for i, h := range header {
+		values[i] = h
+	}
+	if err := w.WriteRow(values...); err != nil {
+		log.Printf("Failed to write %s export: %v", name, err)
+		return
+	}
+	for rows.Next() {
+		row, err := rows.SliceScan()
+		if err != nil {
+			log.Printf("Failed to read %s export row: %v", name, err)
+			return
+		}
+		for i, v := range row {
+			// Text and numeric columns can come back as []byte
+			if b, ok := v.([]byte); ok {
+				row[i] = string(b)
+			}
+		}
+		if err := w.WriteRow(row...); err != nil {
+			log.Printf("Failed to write %s export: %v", name, err)
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Failed to read %s export: %v", name, err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Failed to finish %s export: %v", name, err)
+	}
+}
+
+var migrationStatsHeader = []string{
+	"id", "name", "status", "source_database", "target_project", "tables_count", "views_count",
+	"foreign_keys_count", "models_generated", "created_at", "completed_at", "duration_ms",
+	"ai_tokens", "lines_generated",
+}
+
+// ExportStats exports the user's migration KPIs
+// @Summary Export dashboard statistics
+// @Description Export one row of KPIs per migration created in the date range in the active organization, as CSV or XLSX
+// @Tags stats
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "csv (default) or xlsx"
+// @Param from query string false "First day, YYYY-MM-DD (default 30 days ago)"
+// @Param to query string false "Last day, YYYY-MM-DD (default today)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/export [get]
+func (h *MigrationsHandler) ExportStats(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	req, err := parseStatsExport(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.db.Queryx(`
+		SELECT m.id, m.name, m.status, m.source_database, m.target_project,
+		       COALESCE(m.tables_count, 0), COALESCE(m.views_count, 0),
+		       COALESCE(m.foreign_keys_count, 0), COALESCE(m.models_generated, 0),
+		       m.created_at, m.completed_at,
+		       COALESCE(mm.duration_ms, 0), COALESCE(mm.ai_tokens, 0), COALESCE(mm.lines_generated, 0)
+		FROM migrations m
+		LEFT JOIN (
+			SELECT migration_id, SUM(duration_ms)::bigint AS duration_ms,
+			       SUM(prompt_tokens + completion_tokens)::bigint AS ai_tokens, SUM(lines_generated) AS lines_generated
+			FROM migration_metrics
+			GROUP BY migration_id
+		) mm ON mm.migration_id = m.id
+		WHERE m.user_id = $1 AND COALESCE(m.organization_id, 0) = $4 AND m.created_at >= $2 AND m.created_at < $3
+		ORDER BY m.created_at
+	`, userID, req.from, req.until, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export statistics"})
+		return
+	}
+
+	req.stream(c, "migration-stats", migrationStatsHeader, rows)
+}
+
+var adminStatsHeader = []string{
+	"date", "organization_id", "organization_name", "migrations_created", "migrations_completed",
+	"migrations_failed", "tables_migrated", "active_users", "ai_tokens",
+}
+
+// ExportAdminStats exports platform-wide KPIs per day and organization (admin only)
+// @Summary Export admin statistics
+// @Description Export daily migration and AI usage KPIs per organization, as CSV or XLSX. Users without an organization are reported under organization 0.
+// @Tags admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "csv (default) or xlsx"
+// @Param from query string false "First day, YYYY-MM-DD (default 30 days ago)"
+// @Param to query string false "Last day, YYYY-MM-DD (default today)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/stats/export [get]
+func (h *MigrationsHandler) ExportAdminStats(c *gin.Context) {
+	if !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return
+	}
+	req, err := parseStatsExport(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.db.Queryx(`
+		WITH m AS (
+			SELECT created_at::date AS day, COALESCE(organization_id, 0) AS organization_id,
+			       COUNT(*) AS created,
+			       COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			       COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+			       COALESCE(SUM(tables_count) FILTER (WHERE status = 'completed'), 0) AS tables_migrated,
+			       COUNT(DISTINCT user_id) AS active_users
+			FROM migrations
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY 1, 2
+		), a AS (
+			SELECT created_at::date AS day, COALESCE(organization_id, 0) AS organization_id,
+			       SUM(prompt_tokens + completion_tokens) AS ai_tokens
+			FROM ai_interactions
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY 1, 2
+		)
+		SELECT to_char(COALESCE(m.day, a.day), 'YYYY-MM-DD') AS day,
+		       COALESCE(m.organization_id, a.organization_id) AS organization_id,
+		       COALESCE(o.name, '') AS organization_name,
+		       COALESCE(m.created, 0), COALESCE(m.completed, 0), COALESCE(m.failed, 0),
+		       COALESCE(m.tables_migrated, 0), COALESCE(m.active_users, 0), COALESCE(a.ai_tokens, 0)
+		FROM m
+		FULL OUTER JOIN a ON a.day = m.day AND a.organization_id = m.organization_id
+		LEFT JOIN organizations o ON o.id = COALESCE(m.organization_id, a.organization_id)
+		ORDER BY 1, 2
+	`, req.from, req.until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export statistics"})
+		return
+	}
+
+	req.stream(c, "admin-stats", adminStatsHeader, rows)
+}
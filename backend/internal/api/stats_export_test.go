@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestExportStatsCSV(t *testing.T) {
+	store, mock := newMockDB(t)
+	created := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	completed := created.Add(90 * time.Second)
+	mock.ExpectQuery(`FROM migrations m\s+LEFT JOIN`).
+		WithArgs(testUserID, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), testOrgID).
+		WillReturnRows(sqlmock.NewRows(migrationStatsHeader).
+			AddRow(3, "Sales", "completed", "AdventureWorks", "aw_dbt", 12, 2, 9, 14, created, completed, 81000, 15000, 2150))
+
+	status, body := serve(t, "GET", "/stats/export", "/stats/export?from=2026-03-01&to=2026-03-31", nil, NewMigrationsHandler(store).ExportStats)
+	expectStatus(t, status, http.StatusOK, body)
+
+	want := "id,name,status,source_database,target_project,tables_count,views_count,foreign_keys_count,models_generated,created_at,completed_at,duration_ms,ai_tokens,lines_generated\n" +
+		"3,Sales,completed,AdventureWorks,aw_dbt,12,2,9,14,2026-03-02T09:30:00Z,2026-03-02T09:31:30Z,81000,15000,2150\n"
+	if string(body) != want {
+		t.Errorf("csv =\n%s\nwant\n%s", body, want)
+	}
+}
+
+func TestExportStatsRejectsBadRange(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	for _, query := range []string{"format=pdf", "from=2026-03-31&to=2026-03-01", "from=2025-01-01&to=2026-03-01", "from=03/01/2026"} {
+		status, body := serve(t, "GET", "/stats/export", "/stats/export?"+query, nil, NewMigrationsHandler(store).ExportStats)
+		expectStatus(t, status, http.StatusBadRequest, body)
+	}
+}
+
+func TestExportAdminStatsRequiresAdmin(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/admin/stats/export", "/admin/stats/export", nil, NewMigrationsHandler(store).ExportAdminStats)
+	expectStatus(t, status, http.StatusForbidden, body)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+)
+
+// registerSwagger serves the API documentation at /swagger as SWAGGER_MODE allows
+func registerSwagger(router *gin.Engine, cfg *config.Config) {
+	var guards []gin.HandlerFunc
+	switch cfg.SwaggerMode {
+	case "public":
+	case "basic":
+		if cfg.SwaggerUsername == "" || cfg.SwaggerPassword == "" {
+			return
+		}
+		guards = append(guards, gin.BasicAuthForRealm(gin.Accounts{cfg.SwaggerUsername: cfg.SwaggerPassword}, "API documentation"))
+	case "admin":
+		guards = append(guards, security.APIKeyAuthMiddleware(), middleware.AuthMiddleware(), func(c *gin.Context) {
+			if !middleware.IsAdmin(c) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			}
+		})
+	default:
+		return
+	}
+
+	ui := ginSwagger.WrapHandler(swaggerFiles.Handler)
+	router.GET("/swagger/*any", append(guards, func(c *gin.Context) {
+		if c.Param("any") != "/doc.json" {
+			ui(c)
+			return
+		}
+		doc, err := swag.ReadDoc()
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API documentation is not available"})
+			return
+		}
+		published, err := stripInternalRoutes([]byte(doc))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid API documentation"})
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", published)
+	})...)
+}
+
+// stripInternalRoutes removes the AI service callbacks (/internal paths and operations
+// tagged internal) from an OpenAPI document
+func stripInternalRoutes(doc []byte) ([]byte, error) {
+	var spec map[string]interface{}
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, err
+	}
+
+	paths, _ := spec["paths"].(map[string]interface{})
+	for path, item := range paths {
+		if strings.HasPrefix(path, "/internal/") {
+			delete(paths, path)
+			continue
+		}
+		operations, _ := item.(map[string]interface{})
+		for method, op := range operations {
+			if operation, ok := op.(map[string]interface{}); ok && hasTag(operation, "internal") {
+				delete(operations, method)
+			}
+		}
+		if len(operations) == 0 {
+			delete(paths, path)
+		}
+	}
+
+	if tags, ok := spec["tags"].([]interface{}); ok {
+		kept := tags[:0]
+		for _, tag := range tags {
+			if t, ok := tag.(map[string]interface{}); !ok || t["name"] != "internal" {
+				kept = append(kept, tag)
+			}
+		}
+		spec["tags"] = kept
+	}
+
+	return json.Marshal(spec)
+}
+
+func hasTag(operation map[string]interface{}, name string) bool {
+	tags, _ := operation["tags"].([]interface{})
+	for _, tag := range tags {
+		if tag == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStripInternalRoutes(t *testing.T) {
+	doc := `{
+		"swagger": "2.0",
+		"tags": [{"name": "migrations"}, {"name": "internal"}],
+		"paths": {
+			"/migrations": {"get": {"tags": ["migrations"]}},
+			"/internal/migrations/{id}/status": {"patch": {"tags": ["internal"]}},
+			"/migrations/{id}/callback": {"post": {"tags": ["internal"]}, "get": {"tags": ["migrations"]}}
+		}
+	}`
+
+	published, err := stripInternalRoutes([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Tags  []map[string]string                   `json:"tags"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(published, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := spec.Paths["/internal/migrations/{id}/status"]; ok {
+		t.Error("internal path is published")
+	}
+	if _, ok := spec.Paths["/migrations/{id}/callback"]["post"]; ok {
+		t.Error("internal operation is published")
+	}
+	if _, ok := spec.Paths["/migrations/{id}/callback"]["get"]; !ok {
+		t.Error("public operation on a path with an internal one was removed")
+	}
+	if len(spec.Tags) != 1 || spec.Tags[0]["name"] != "migrations" {
+		t.Errorf("tags = %v", spec.Tags)
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const (
+	maxTagLength      = 50
+	maxTagsPerRequest = 20
+)
+
+// taggable describes where a resource type and its tags are stored
+type taggable struct {
+	table     string // Resource table, owned through user_id
+	tagTable  string
+	tagColumn string // Column in tagTable referencing the resource
+	notFound  string
+}
+
+var taggables = map[string]taggable{
+	"migration":  {table: "migrations", tagTable: "migration_tags", tagColumn: "migration_id", notFound: "Migration not found"},
+	"connection": {table: "database_connections", tagTable: "connection_tags", tagColumn: "connection_id", notFound: "Connection not found"},
+}
+
+type TagsHandler struct {
+	db db.Querier
+}
+
+func NewTagsHandler(store db.Querier) *TagsHandler {
+	return &TagsHandler{db: store}
+}
+
+// GetAll lists the tags on the user's migrations and connections
+// @Summary List tags
+// @Description List tags on the current user's migrations and connections in the active organization
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param resource_type query string false "migration or connection"
+// @Success 200 {array} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags [get]
+func (h *TagsHandler) GetAll(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+
+	resourceTypes := []string{"connection", "migration"}
+	if resourceType := c.Query("resource_type"); resourceType != "" {
+		if _, ok := taggables[resourceType]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "resource_type must be migration or connection"})
+			return
+		}
+		resourceTypes = []string{resourceType}
+	}
+
+	queries := make([]string, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		t := taggables[resourceType]
+		queries = append(queries, fmt.Sprintf(`
+			SELECT '%s' AS resource_type, t.%s AS resource_id, t.tag
+			FROM %s t
+			JOIN %s r ON r.id = t.%s
+			WHERE r.user_id = $1 AND COALESCE(r.organization_id, 0) = $2`, resourceType, t.tagColumn, t.tagTable, t.table, t.tagColumn))
+	}
+
+	tags := []models.Tag{}
+	err := h.db.Select(&tags, strings.Join(queries, " UNION ALL ")+" ORDER BY resource_type, resource_id, tag", userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// Bulk adds and removes tags on several resources
+// @Summary Tag multiple resources
+// @Description Add and remove tags on up to 100 migrations or connections in one request, with a result per resource
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkTagRequest true "Resources and tags"
+// @Success 200 {object} models.BulkResponse
+// @Failure 400 {object} map[string]string
+// @Router /tags/bulk [post]
+func (h *TagsHandler) Bulk(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+
+	var req models.BulkTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	add, addErr := normalizeTags(req.Add)
+	remove, removeErr := normalizeTags(req.Remove)
+	var details []string
+	for _, err := range []error{addErr, removeErr} {
+		if err != nil {
+			details = append(details, err.Error())
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		details = append(details, "add or remove must list at least one tag")
+	}
+	if len(details) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": details})
+		return
+	}
+
+	t := taggables[req.ResourceType]
+	ids := uniqueIDs(req.IDs)
+	results := make([]models.BulkItemResult, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, itemResult(id, h.tagResource(t, id, userID, orgID, add, remove)))
+	}
+	c.JSON(http.StatusOK, bulkResponse(results))
+}
+
+// tagResource applies tag changes to one resource the user owns in the organization
+func (h *TagsHandler) tagResource(t taggable, id, userID, orgID int64, add, remove []string) *actionError {
+	var owned int64
+	err := h.db.Get(&owned, fmt.Sprintf("SELECT id FROM %s WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", t.table), id, userID, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return newActionError(http.StatusNotFound, t.notFound)
+		}
+		return newActionError(http.StatusInternalServerError, "Database error")
+	}
+
+	if len(add) > 0 {
+		_, err = h.db.Exec(fmt.Sprintf(`
+			INSERT INTO %s (%s, tag)
+			SELECT $1, unnest($2::text[])
+			ON CONFLICT DO NOTHING
+		`, t.tagTable, t.tagColumn), id, pq.StringArray(add))
+		if err != nil {
+			return newActionError(http.StatusInternalServerError, "Failed to add tags")
+		}
+	}
+	if len(remove) > 0 {
+		_, err = h.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND tag = ANY($2)", t.tagTable, t.tagColumn),
+			id, pq.StringArray(remove))
+		if err != nil {
+			return newActionError(http.StatusInternalServerError, "Failed to remove tags")
+		}
+	}
+	return nil
+}
+
+// normalizeTags trims tags and drops duplicates
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTagsPerRequest {
+		return nil, fmt.Errorf("at most %d tags can be added or removed at once", maxTagsPerRequest)
+	}
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tags must be 1-%d characters", maxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
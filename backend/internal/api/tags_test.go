@@ -0,0 +1,58 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestTagsBulk(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT id FROM database_connections WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(7), testUserID, testOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec(`INSERT INTO connection_tags \(connection_id, tag\)`).
+		WithArgs(int64(7), pq.StringArray{"prod", "finance"}).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM connection_tags WHERE connection_id = \$1 AND tag = ANY\(\$2\)`).
+		WithArgs(int64(7), pq.StringArray{"staging"}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id FROM database_connections`).
+		WithArgs(int64(8), testUserID, testOrgID).
+		WillReturnError(sql.ErrNoRows)
+
+	req := map[string]interface{}{
+		"resource_type": "connection",
+		"ids":           []int64{7, 8},
+		"add":           []string{" prod", "finance", "prod"},
+		"remove":        []string{"staging"},
+	}
+	status, body := serve(t, "POST", "/tags/bulk", "/tags/bulk", req, NewTagsHandler(store).Bulk)
+	expectStatus(t, status, http.StatusOK, body)
+
+	resp := decodeBulkResponse(t, body)
+	if resp.Succeeded != 1 || resp.Failed != 1 || resp.Results[1].Error != "Connection not found" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestTagsBulkValidation(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	req := map[string]interface{}{"resource_type": "migration", "ids": []int64{1}, "add": []string{"  "}}
+	status, body := serve(t, "POST", "/tags/bulk", "/tags/bulk", req, NewTagsHandler(store).Bulk)
+	expectStatus(t, status, http.StatusBadRequest, body)
+	if msg := errorMessage(t, body); msg != "Validation failed" {
+		t.Errorf("error = %q", msg)
+	}
+}
+
+func TestTagsGetAllRejectsUnknownType(t *testing.T) {
+	store, _ := newMockDB(t)
+
+	status, body := serve(t, "GET", "/tags", "/tags?resource_type=user", nil, NewTagsHandler(store).GetAll)
+	expectStatus(t, status, http.StatusBadRequest, body)
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/crypto"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"github.com/datamigrate-ai/backend/internal/email"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/ticketing"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// ticketSyncTimeout bounds the calls that open or update a migration's ticket
+const ticketSyncTimeout = 30 * time.Second
+
+// maxTicketSummary fits the shortest summary field, ServiceNow's short_description
+const maxTicketSummary = 160
+
+// ticketTarget is a migration with the ticketing integration of its organization
+type ticketTarget struct {
+	Name            string         `db:"name"`
+	SourceDatabase  string         `db:"source_database"`
+	TablesCount     int            `db:"tables_count"`
+	ModelsGenerated int            `db:"models_generated"`
+	Error           *string        `db:"error"`
+	TicketKey       sql.NullString `db:"ticket_key"`
+	TicketID        sql.NullString `db:"ticket_id"`
+	TicketURL       sql.NullString `db:"ticket_url"`
+	RunNumber       int            `db:"run_number"`
+	OrganizationID  int64          `db:"organization_id"`
+	Provider        string         `db:"provider"`
+	BaseURL         string         `db:"base_url"`
+	Username        string         `db:"username"`
+	APIToken        string         `db:"api_token"`
+	Project         string         `db:"project"`
+	IssueType       string         `db:"issue_type"`
+	Events          pq.StringArray `db:"events"`
+}
+
+// syncMigrationTicket opens the ticket tracking a migration, or adds the event to it,
+// when the migration's organization has linked Jira or ServiceNow for the event. The
+// ticket is stored on the migration and links back to it. Failures are recorded on the
+// integration for its admins to see.
+func syncMigrationTicket(store db.Querier, enc *crypto.EncryptionService, migrationID int64, event string) {
+	var target ticketTarget
+	err := store.Get(&target, `
+		SELECT m.name, COALESCE(m.source_database, '') AS source_database, COALESCE(m.tables_count, 0) AS tables_count,
+		       COALESCE(m.models_generated, 0) AS models_generated, m.error, m.ticket_key, m.ticket_id, m.ticket_url,
+		       COALESCE(r.run_number, 0) AS run_number,
+		       t.organization_id, t.provider, t.base_url, t.username, t.api_token, t.project,
+		       COALESCE(t.issue_type, '') AS issue_type, t.events
+		FROM migrations m
+		JOIN users u ON u.id = m.user_id
+		JOIN organization_ticketing t ON t.organization_id = COALESCE(m.organization_id, u.organization_id)
+		LEFT JOIN migration_runs r ON r.id = m.current_run_id
+		WHERE m.id = $1
+	`, migrationID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Failed to fetch migration %d for its ticket: %v", migrationID, err)
+		}
+		return
+	}
+	if !slices.Contains(target.Events, event) {
+		return
+	}
+
+	if err := updateMigrationTicket(store, enc, migrationID, event, target); err != nil {
+		log.Printf("Failed to update %s ticket of migration %d: %v", target.Provider, migrationID, err)
+		if _, err := store.Exec("UPDATE organization_ticketing SET last_error = $2 WHERE organization_id = $1",
+			target.OrganizationID, err.Error()); err != nil {
+			log.Printf("Failed to record ticketing error of organization %d: %v", target.OrganizationID, err)
+		}
+		return
+	}
+	if _, err := store.Exec("UPDATE organization_ticketing SET last_error = NULL, last_synced_at = NOW() WHERE organization_id = $1",
+		target.OrganizationID); err != nil {
+		log.Printf("Failed to record ticket sync of organization %d: %v", target.OrganizationID, err)
+	}
+}
+
+func updateMigrationTicket(store db.Querier, enc *crypto.EncryptionService, migrationID int64, event string, target ticketTarget) error {
+	token, err := enc.Decrypt(target.APIToken)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt API token: %w", err)
+	}
+	tracker, err := ticketing.New(ticketing.Config{
+		Provider:  target.Provider,
+		BaseURL:   target.BaseURL,
+		Username:  target.Username,
+		APIToken:  token,
+		Project:   target.Project,
+		IssueType: target.IssueType,
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ticketSyncTimeout)
+	defer cancel()
+
+	update := ticketUpdate(migrationID, event, target)
+	if target.TicketKey.Valid {
+		ref := ticketing.Reference{Key: target.TicketKey.String, ID: target.TicketID.String, URL: target.TicketURL.String}
+		return tracker.Comment(ctx, ref, update)
+	}
+
+	summary := "Data migration: " + target.Name
+	if len(summary) > maxTicketSummary {
+		summary = strings.ToValidUTF8(summary[:maxTicketSummary-3], "") + "..."
+	}
+	ref, err := tracker.Create(ctx, ticketing.Ticket{
+		Summary:     summary,
+		Description: fmt.Sprintf("%s\n\nSource database: %s\nTables: %d", update, orDash(target.SourceDatabase), target.TablesCount),
+	})
+	if err != nil {
+		return err
+	}
+	// A ticket another event opened meanwhile is kept
+	_, err = store.Exec(`
+		UPDATE migrations SET ticket_key = $2, ticket_id = $3, ticket_url = $4
+		WHERE id = $1 AND ticket_key IS NULL
+	`, migrationID, ref.Key, ref.ID, ref.URL)
+	return err
+}
+
+// ticketUpdate describes a migration event for its ticket, with a link to the migration
+func ticketUpdate(migrationID int64, event string, target ticketTarget) string {
+	var text string
+	switch event {
+	case ticketing.EventStarted:
+		text = fmt.Sprintf("Migration %s started", target.Name)
+		if target.RunNumber > 1 {
+			text += fmt.Sprintf(" (run #%d)", target.RunNumber)
+		}
+		text += "."
+	case ticketing.EventCompleted:
+		text = fmt.Sprintf("Migration %s completed: %d tables migrated, %d dbt models generated.",
+			target.Name, target.TablesCount, target.ModelsGenerated)
+	default:
+		reason := "Unknown error"
+		if target.Error != nil && *target.Error != "" {
+			reason = *target.Error
+		}
+		text = fmt.Sprintf("Migration %s failed: %s", target.Name, reason)
+	}
+	return text + "\n" + migrationPageURL(target.OrganizationID, migrationID)
+}
+
+// migrationPageURL links to a migration in the frontend, on the organization's custom
+// domain when it has one
+func migrationPageURL(orgID, migrationID int64) string {
+	path := fmt.Sprintf("/migrations/%d", migrationID)
+	if base := domains.BaseURL(orgID); base != "" {
+		return base + path
+	}
+	return email.PublicURL(path)
+}
+
+// validateTicketingRequest normalizes a ticketing request and checks it, returning the
+// config to store and one message per problem
+func validateTicketingRequest(req *models.UpdateTicketingRequest) (ticketing.Config, []string) {
+	cfg := ticketing.Config{
+		Provider:  req.Provider,
+		BaseURL:   req.BaseURL,
+		Username:  req.Username,
+		Project:   req.Project,
+		IssueType: req.IssueType,
+	}
+	cfg.Normalize()
+	details := cfg.Validate()
+
+	if len(req.Events) == 0 {
+		req.Events = ticketing.Events
+	}
+	for _, e := range req.Events {
+		if !slices.Contains(ticketing.Events, e) {
+			details = append(details, fmt.Sprintf("events: %q must be started, completed or failed", e))
+		}
+	}
+	return cfg, details
+}
+
+// GetTicketing returns the organization's Jira or ServiceNow integration
+// @Summary Get ticketing integration
+// @Description The Jira or ServiceNow instance migrations open change tickets in, which events update them, and the last error. The API token isn't returned.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TicketingIntegration
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ticketing [get]
+func (h *OrganizationsHandler) GetTicketing(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var integration models.TicketingIntegration
+	err := db.DB.Get(&integration, `
+		SELECT provider, base_url, username, project, COALESCE(issue_type, '') AS issue_type, events,
+		       last_error, last_synced_at, updated_at
+		FROM organization_ticketing WHERE organization_id = $1
+	`, orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No ticketing integration is linked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticketing integration"})
+		return
+	}
+	c.JSON(http.StatusOK, integration)
+}
+
+// UpdateTicketing links a Jira or ServiceNow instance to the organization
+// @Summary Link ticketing integration
+// @Description Link Jira (project key and issue type) or ServiceNow (table, change_request by default). A ticket is opened on a migration's first configured event (started, completed or failed) and later events are added to it as comments or work notes. The api_token may be left out to keep the stored one, unless the provider or base_url changes.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateTicketingRequest true "Integration"
+// @Success 200 {object} models.TicketingIntegration
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ticketing [put]
+func (h *OrganizationsHandler) UpdateTicketing(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTicketingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cfg, details := validateTicketingRequest(&req)
+	if len(details) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticketing integration", "details": details})
+		return
+	}
+
+	var integration models.TicketingIntegration
+	const returning = `RETURNING provider, base_url, username, project, COALESCE(issue_type, '') AS issue_type, events,
+		last_error, last_synced_at, updated_at`
+	userID := middleware.GetUserID(c)
+	if req.APIToken == "" {
+		// The stored token is only kept for the instance it was issued by
+		err := db.DB.Get(&integration, `
+			UPDATE organization_ticketing
+			SET username = $4, project = $5, issue_type = NULLIF($6, ''), events = $7, updated_by = $8,
+			    last_error = NULL, updated_at = NOW()
+			WHERE organization_id = $1 AND provider = $2 AND base_url = $3
+			`+returning,
+			orgID, cfg.Provider, cfg.BaseURL, cfg.Username, cfg.Project, cfg.IssueType, pq.Array(req.Events), userID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "api_token is required to link a new instance"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save ticketing integration"})
+			return
+		}
+		c.JSON(http.StatusOK, integration)
+		return
+	}
+
+	enc := crypto.GetEncryptionService()
+	if !enc.IsKeySet() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "API tokens can't be stored: encryption is not configured on this server"})
+		return
+	}
+	token, err := enc.Encrypt(req.APIToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save ticketing integration"})
+		return
+	}
+	err = db.DB.Get(&integration, `
+		INSERT INTO organization_ticketing (organization_id, provider, base_url, username, api_token, project, issue_type, events, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET provider = EXCLUDED.provider, base_url = EXCLUDED.base_url, username = EXCLUDED.username,
+		    api_token = EXCLUDED.api_token, project = EXCLUDED.project, issue_type = EXCLUDED.issue_type,
+		    events = EXCLUDED.events, updated_by = EXCLUDED.updated_by, last_error = NULL, updated_at = NOW()
+		`+returning,
+		orgID, cfg.Provider, cfg.BaseURL, cfg.Username, token, cfg.Project, cfg.IssueType, pq.Array(req.Events), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save ticketing integration"})
+		return
+	}
+	c.JSON(http.StatusOK, integration)
+}
+
+// DeleteTicketing unlinks the organization's ticketing integration
+// @Summary Unlink ticketing integration
+// @Description Stop opening and updating tickets. Migrations keep the tickets they link to.
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/ticketing [delete]
+func (h *OrganizationsHandler) DeleteTicketing(c *gin.Context) {
+	orgID, ok := requireOrgAdmin(c)
+	if !ok {
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM organization_ticketing WHERE organization_id = $1", orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink ticketing integration"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No ticketing integration is linked"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ticketing integration unlinked"})
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/crypto"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+var ticketTargetColumns = []string{
+	"name", "source_database", "tables_count", "models_generated", "error", "ticket_key", "ticket_id", "ticket_url",
+	"run_number", "organization_id", "provider", "base_url", "username", "api_token", "project", "issue_type", "events",
+}
+
+func TestValidateTicketingRequest(t *testing.T) {
+	req := models.UpdateTicketingRequest{Provider: "jira", BaseURL: "https://contoso.atlassian.net/", Username: "ops@contoso.com", Project: "data"}
+	cfg, details := validateTicketingRequest(&req)
+	if len(details) > 0 {
+		t.Fatalf("details = %v", details)
+	}
+	if cfg.Project != "DATA" || cfg.IssueType != "Task" || !reflect.DeepEqual(req.Events, []string{"started", "completed", "failed"}) {
+		t.Errorf("cfg = %+v, events = %v", cfg, req.Events)
+	}
+
+	req = models.UpdateTicketingRequest{Provider: "github", BaseURL: "https://github.com", Username: "ops", Events: []string{"deleted"}}
+	_, details = validateTicketingRequest(&req)
+	want := []string{"provider must be jira or servicenow", `events: "deleted" must be started, completed or failed`}
+	if !reflect.DeepEqual(details, want) {
+		t.Errorf("details = %q", details)
+	}
+}
+
+func TestSyncMigrationTicketCreates(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://app.example.com")
+	var created map[string]map[string]interface{}
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); r.URL.Path != "/rest/api/2/issue" || user != "ops@contoso.com" || pass != "jira-token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		w.Write([]byte(`{"id":"10042","key":"DATA-7"}`))
+	}))
+	defer jira.Close()
+
+	enc, _ := crypto.NewEncryptionService([]byte("0123456789abcdef0123456789abcdef"))
+	token, _ := enc.Encrypt("jira-token")
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`JOIN organization_ticketing t`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows(ticketTargetColumns).AddRow(
+			"Sales", "AdventureWorks", 12, 0, nil, nil, nil, nil, 1, testOrgID,
+			"jira", jira.URL, "ops@contoso.com", token, "DATA", "Task", "{started,failed}"))
+	mock.ExpectExec(`UPDATE migrations SET ticket_key = \$2, ticket_id = \$3, ticket_url = \$4\s+WHERE id = \$1 AND ticket_key IS NULL`).
+		WithArgs(int64(8), "DATA-7", "10042", jira.URL+"/browse/DATA-7").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE organization_ticketing SET last_error = NULL, last_synced_at = NOW\(\)`).
+		WithArgs(testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	syncMigrationTicket(store, enc, 8, "started")
+
+	fields := created["fields"]
+	if fields["summary"] != "Data migration: Sales" {
+		t.Errorf("summary = %v", fields["summary"])
+	}
+	description, _ := fields["description"].(string)
+	if !strings.Contains(description, "Migration Sales started.\nhttps://app.example.com/migrations/8") ||
+		!strings.Contains(description, "Source database: AdventureWorks") {
+		t.Errorf("description = %q", description)
+	}
+}
+
+func TestSyncMigrationTicketRecordsFailure(t *testing.T) {
+	servicenow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"User Not Authenticated"}}`, http.StatusUnauthorized)
+	}))
+	defer servicenow.Close()
+
+	enc, _ := crypto.NewEncryptionService([]byte("0123456789abcdef0123456789abcdef"))
+	token, _ := enc.Encrypt("wrong")
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`JOIN organization_ticketing t`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows(ticketTargetColumns).AddRow(
+			"Sales", "AdventureWorks", 12, 9, "column type xml is not supported", "CHG0030001", "a1b2", "https://x", 2, testOrgID,
+			"servicenow", servicenow.URL, "svc", token, "change_request", "", "{started,completed,failed}"))
+	mock.ExpectExec(`UPDATE organization_ticketing SET last_error = \$2`).
+		WithArgs(testOrgID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	syncMigrationTicket(store, enc, 8, "failed")
+}
+
+func TestSyncMigrationTicketSkipsUnconfiguredEvent(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectQuery(`JOIN organization_ticketing t`).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows(ticketTargetColumns).AddRow(
+			"Sales", "AdventureWorks", 12, 9, nil, nil, nil, nil, 1, testOrgID,
+			"jira", "https://contoso.atlassian.net", "ops", "token", "DATA", "Task", "{failed}"))
+
+	// No request is sent and nothing is recorded
+	syncMigrationTicket(store, &crypto.EncryptionService{}, 8, "completed")
+}
+
+func TestTicketUpdate(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://app.example.com")
+	target := ticketTarget{Name: "Sales", TablesCount: 12, ModelsGenerated: 30, RunNumber: 2, OrganizationID: testOrgID}
+	for event, want := range map[string]string{
+		"started":   "Migration Sales started (run #2).\nhttps://app.example.com/migrations/8",
+		"completed": "Migration Sales completed: 12 tables migrated, 30 dbt models generated.\nhttps://app.example.com/migrations/8",
+		"failed":    "Migration Sales failed: Unknown error\nhttps://app.example.com/migrations/8",
+	} {
+		if got := ticketUpdate(8, event, target); got != want {
+			t.Errorf("ticketUpdate(%s) = %q, want %q", event, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+// maxTimeWindows bounds the windows of one schedule
+const maxTimeWindows = 14
+
+// windowDays are the day names a time window may be limited to, indexed by time.Weekday
+var windowDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// normalizeTimeWindows trims the windows' times and lowercases their days
+func normalizeTimeWindows(windows []models.TimeWindow) {
+	for i := range windows {
+		w := &windows[i]
+		w.Start = strings.TrimSpace(w.Start)
+		w.End = strings.TrimSpace(w.End)
+		for j, day := range w.Days {
+			w.Days[j] = strings.ToLower(strings.TrimSpace(day))
+		}
+	}
+}
+
+// validateTimezone adds an error to result unless timezone is empty or an IANA time zone
+func validateTimezone(result *validation.ValidationResult, field, timezone string) {
+	if timezone == "" {
+		return
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		result.AddError(field, "Timezone must be an IANA time zone, e.g. Europe/Copenhagen")
+	}
+}
+
+// validateTimeWindows adds an error to result for each window that isn't a valid HH:MM
+// range. field names the list in the errors.
+func validateTimeWindows(result *validation.ValidationResult, field string, windows []models.TimeWindow) {
+	if len(windows) > maxTimeWindows {
+		result.AddError(field, fmt.Sprintf("At most %d windows are allowed", maxTimeWindows))
+		return
+	}
+	for i, w := range windows {
+		windowField := fmt.Sprintf("%s[%d]", field, i)
+		start, startErr := time.Parse("15:04", w.Start)
+		end, endErr := time.Parse("15:04", w.End)
+		if startErr != nil {
+			result.AddError(windowField+".start", "Start must be a time of day as HH:MM")
+		}
+		if endErr != nil {
+			result.AddError(windowField+".end", "End must be a time of day as HH:MM")
+		}
+		if startErr == nil && endErr == nil && start.Equal(end) {
+			result.AddError(windowField, "Start and end must differ")
+		}
+		for _, day := range w.Days {
+			if !slices.Contains(windowDays, day) {
+				result.AddError(windowField+".days", "Days must be mon, tue, wed, thu, fri, sat or sun")
+				break
+			}
+		}
+	}
+}
+
+// timeWindowsOpen reports whether now falls in one of the windows. A window running past
+// midnight belongs to the day it starts on. An unknown time zone is read as UTC.
+func timeWindowsOpen(timezone string, windows []models.TimeWindow, now time.Time) bool {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	minute := now.Hour()*60 + now.Minute()
+	today := windowDays[now.Weekday()]
+	yesterday := windowDays[(now.Weekday()+6)%7]
+
+	onDay := func(w models.TimeWindow, day string) bool {
+		return len(w.Days) == 0 || slices.Contains(w.Days, day)
+	}
+	for _, w := range windows {
+		start, err := time.Parse("15:04", w.Start)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("15:04", w.End)
+		if err != nil {
+			continue
+		}
+		from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+		if from < to {
+			if onDay(w, today) && minute >= from && minute < to {
+				return true
+			}
+			continue
+		}
+		if (onDay(w, today) && minute >= from) || (onDay(w, yesterday) && minute < to) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWindowOpen reports whether a migration with the run window may run now
+func runWindowOpen(window *models.RunWindow, now time.Time) bool {
+	return window == nil || timeWindowsOpen(window.Timezone, window.Windows, now)
+}
+
+// validateRunWindow normalizes a migration's run window and adds an error to result for
+// each invalid setting
+func validateRunWindow(result *validation.ValidationResult, window *models.RunWindow) {
+	if window == nil {
+		return
+	}
+	window.Timezone = strings.TrimSpace(window.Timezone)
+	normalizeTimeWindows(window.Windows)
+	if len(window.Windows) == 0 {
+		result.AddError("run_window.windows", "At least one window is required")
+	}
+	validateTimezone(result, "run_window.timezone", window.Timezone)
+	validateTimeWindows(result, "run_window.windows", window.Windows)
+}
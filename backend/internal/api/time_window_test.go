@@ -0,0 +1,60 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+func TestTimeWindowsOpen(t *testing.T) {
+	nightly := []models.TimeWindow{{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "22:00", End: "06:00"}}
+	copenhagen, err := time.LoadLocation("Europe/Copenhagen")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	for _, tc := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 12, 23, 0, 0, 0, copenhagen), true},  // Monday night
+		{time.Date(2026, 10, 13, 5, 59, 0, 0, copenhagen), true},  // Tuesday morning, Monday's window
+		{time.Date(2026, 10, 13, 6, 0, 0, 0, copenhagen), false},  // Window closed
+		{time.Date(2026, 10, 12, 12, 0, 0, 0, copenhagen), false}, // Monday midday
+		{time.Date(2026, 10, 12, 3, 0, 0, 0, copenhagen), false},  // Monday morning, Sunday had no window
+		{time.Date(2026, 10, 17, 2, 0, 0, 0, copenhagen), true},   // Saturday morning, Friday's window
+		{time.Date(2026, 10, 12, 21, 30, 0, 0, time.UTC), true},   // 23:30 in Copenhagen
+	} {
+		if got := timeWindowsOpen("Europe/Copenhagen", nightly, tc.at); got != tc.want {
+			t.Errorf("timeWindowsOpen(%s) = %v, want %v", tc.at, got, tc.want)
+		}
+	}
+
+	daytime := []models.TimeWindow{{Start: "09:00", End: "17:00"}}
+	if !timeWindowsOpen("", daytime, time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)) {
+		t.Error("window without days isn't open on a Sunday")
+	}
+	if !runWindowOpen(nil, time.Now()) {
+		t.Error("migration without a run window may not run")
+	}
+}
+
+func TestValidateRunWindow(t *testing.T) {
+	result := validation.NewValidationResult()
+	validateRunWindow(result, &models.RunWindow{Timezone: "Nowhere/City", Windows: []models.TimeWindow{{Days: []string{"Mon"}, Start: "22:00", End: "6am"}}})
+	fields := map[string]bool{}
+	for _, e := range result.Errors {
+		fields[e.Field] = true
+	}
+	if !fields["run_window.timezone"] || !fields["run_window.windows[0].end"] || fields["run_window.windows[0].days"] {
+		t.Errorf("errors = %v", result.Errors)
+	}
+
+	result = validation.NewValidationResult()
+	validateRunWindow(result, &models.RunWindow{})
+	if result.Valid {
+		t.Error("run window without windows passed validation")
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/quota"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// maxTranslateSQLLength bounds a playground snippet; whole projects go through migrations
+const maxTranslateSQLLength = 50000
+
+// TranslateHandler translates ad-hoc T-SQL snippets in the query playground
+type TranslateHandler struct {
+	db db.Querier
+}
+
+func NewTranslateHandler(store db.Querier) *TranslateHandler {
+	return &TranslateHandler{db: store}
+}
+
+// Translate converts a T-SQL snippet for a target warehouse and saves it to the user's
+// history
+// @Summary Translate a T-SQL snippet
+// @Description Translate an ad-hoc T-SQL snippet to a dbt model (format dbt, the default) or plain SQL (format sql) for the target warehouse, using the organization's AI service. The translation is saved to the user's playground history.
+// @Tags translate
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TranslateRequest true "Snippet and target"
+// @Success 201 {object} models.SQLTranslation
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]interface{}
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /translate [post]
+func (h *TranslateHandler) Translate(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+
+	var req models.TranslateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.SQL = strings.TrimSpace(req.SQL)
+	req.TargetDialect = strings.ToLower(strings.TrimSpace(req.TargetDialect))
+	req.Format = strings.ToLower(strings.TrimSpace(req.Format))
+	if req.Format == "" {
+		req.Format = "dbt"
+	}
+	adapter, ok := dbtAdapters[req.TargetDialect]
+	switch {
+	case req.SQL == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sql is required"})
+		return
+	case len(req.SQL) > maxTranslateSQLLength:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("sql must be at most %d characters", maxTranslateSQLLength)})
+		return
+	case !ok:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported target_dialect: " + req.TargetDialect})
+		return
+	case req.Format != "dbt" && req.Format != "sql":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be dbt or sql"})
+		return
+	}
+
+	if !enforceQuota(c, orgID, quota.MetricAITokens, 0) {
+		return
+	}
+	// The snippet goes only to the organization's data region
+	aiClient, err := organizationAIClient(h.db, orgID)
+	if err != nil {
+		aiClientError(c, err)
+		return
+	}
+	if aiClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI service not available"})
+		return
+	}
+
+	start := time.Now()
+	resp, err := aiClient.Translate(aiservice.TranslateRequest{
+		SQL:    req.SQL,
+		Target: aiservice.TargetAdapter{Type: req.TargetDialect, Adapter: adapter, TSQL: isTSQLTarget(req.TargetDialect)},
+		Format: req.Format,
+	})
+	recordTranslationInteraction(userID, orgID, aiClient.Region(), req.SQL, resp, time.Since(start), err)
+	if err != nil {
+		log.Printf("[Translate] AI service error for user %d: %v", userID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to translate the snippet"})
+		return
+	}
+
+	warnings := resp.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	translation := models.SQLTranslation{
+		TargetDialect: req.TargetDialect,
+		Format:        req.Format,
+		SourceSQL:     req.SQL,
+		TranslatedSQL: resp.SQL,
+		Warnings:      warnings,
+	}
+	err = h.db.QueryRow(`
+		INSERT INTO sql_translations (user_id, organization_id, target_dialect, format, source_sql, translated_sql, warnings)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, userID, orgID, req.TargetDialect, req.Format, req.SQL, resp.SQL, pq.StringArray(warnings)).Scan(&translation.ID, &translation.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save translation"})
+		return
+	}
+	c.JSON(http.StatusCreated, translation)
+}
+
+// GetHistory lists the user's translations in the active organization, newest first
+// @Summary List translation history
+// @Description List the current user's playground translations in the active organization, newest first
+// @Tags translate
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /translate/history [get]
+func (h *TranslateHandler) GetHistory(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	translations := []models.SQLTranslation{}
+	err := h.db.Select(&translations, `
+		SELECT id, target_dialect, format, source_sql, translated_sql, warnings, created_at
+		FROM sql_translations
+		WHERE user_id = $1 AND COALESCE(organization_id, 0) = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, userID, orgID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch translation history"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"translations": translations, "limit": limit, "offset": offset})
+}
+
+// DeleteHistory removes a translation from the user's history
+// @Summary Delete a translation
+// @Tags translate
+// @Security BearerAuth
+// @Param id path int true "Translation ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /translate/history/{id} [delete]
+func (h *TranslateHandler) DeleteHistory(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	orgID := middleware.GetOrganizationID(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid translation ID"})
+		return
+	}
+
+	result, err := h.db.Exec("DELETE FROM sql_translations WHERE id = $1 AND user_id = $2 AND COALESCE(organization_id, 0) = $3", id, userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete translation"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Translation not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// recordTranslationInteraction writes a translation to the AI interaction audit trail and
+// counts its tokens against the organization's quota
+func recordTranslationInteraction(userID, orgID int64, region, prompt string, resp *aiservice.TranslateResponse, latency time.Duration, callErr error) {
+	interaction := &security.AIInteraction{
+		InteractionType: "translation",
+		UserID:          &userID,
+		Prompt:          prompt,
+		LatencyMs:       latency.Milliseconds(),
+		Status:          "success",
+		DataRegion:      region,
+	}
+	if orgID > 0 {
+		interaction.OrganizationID = &orgID
+	}
+	if resp != nil {
+		interaction.Response = resp.SQL
+	}
+
+	if resp != nil && resp.Usage != nil {
+		interaction.PromptTokens = resp.Usage.PromptTokens
+		interaction.CompletionTokens = resp.Usage.CompletionTokens
+	} else {
+		interaction.PromptTokens = security.EstimateTokens(prompt)
+		interaction.CompletionTokens = security.EstimateTokens(interaction.Response)
+		interaction.TokensEstimated = true
+	}
+
+	if callErr != nil {
+		errMsg := callErr.Error()
+		interaction.Status = "error"
+		interaction.Error = &errMsg
+	} else if interaction.OrganizationID != nil {
+		recordUsage(*interaction.OrganizationID, quota.MetricAITokens, int64(interaction.PromptTokens+interaction.CompletionTokens))
+	}
+
+	security.GetAIAuditor().Record(interaction)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/datamigrate-ai/backend/internal/models"
+)
+
+func TestTranslateValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body map[string]interface{}
+		want string
+	}{
+		{"blank sql", map[string]interface{}{"sql": "  ", "target_dialect": "snowflake"}, "sql is required"},
+		{"unknown dialect", map[string]interface{}{"sql": "SELECT TOP 10 * FROM dbo.Orders", "target_dialect": "oracle"}, "Unsupported target_dialect: oracle"},
+		{"unknown format", map[string]interface{}{"sql": "SELECT 1", "target_dialect": "Snowflake", "format": "yaml"}, "format must be dbt or sql"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, _ := newMockDB(t)
+			status, body := serve(t, "POST", "/translate", "/translate", tc.body, NewTranslateHandler(store).Translate)
+			expectStatus(t, status, http.StatusBadRequest, body)
+			if got := errorMessage(t, body); got != tc.want {
+				t.Errorf("error = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTranslateGetHistory(t *testing.T) {
+	store, mock := newMockDB(t)
+	created := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM sql_translations\s+WHERE user_id = \$1 AND COALESCE\(organization_id, 0\) = \$2`).
+		WithArgs(testUserID, testOrgID, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "target_dialect", "format", "source_sql", "translated_sql", "warnings", "created_at"}).
+			AddRow(3, "snowflake", "sql", "SELECT TOP 10 * FROM dbo.Orders", "SELECT * FROM orders LIMIT 10", "{}", created))
+
+	status, body := serve(t, "GET", "/translate/history", "/translate/history?limit=10", nil, NewTranslateHandler(store).GetHistory)
+	expectStatus(t, status, http.StatusOK, body)
+
+	var resp struct {
+		Translations []models.SQLTranslation `json:"translations"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response is not JSON: %s", body)
+	}
+	if len(resp.Translations) != 1 || resp.Translations[0].TranslatedSQL != "SELECT * FROM orders LIMIT 10" {
+		t.Errorf("translations = %+v", resp.Translations)
+	}
+}
+
+func TestTranslateDeleteHistoryNotFound(t *testing.T) {
+	store, mock := newMockDB(t)
+	mock.ExpectExec(`DELETE FROM sql_translations`).
+		WithArgs(int64(3), testUserID, testOrgID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	status, body := serve(t, "DELETE", "/translate/history/:id", "/translate/history/3", nil, NewTranslateHandler(store).DeleteHistory)
+	expectStatus(t, status, http.StatusNotFound, body)
+}
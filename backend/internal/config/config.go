@@ -3,15 +3,31 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// Development defaults that must be overridden in production (see Preflight)
+const (
+	defaultJWTSecret  = "your-super-secret-jwt-key-change-in-production"
+	defaultDBPassword = "datamigrate123"
+)
+
 type Config struct {
 	// Server
 	ServerPort string
 	ServerHost string
 
+	// Native TLS for deployments without a TLS-terminating proxy: certificate files, or
+	// certificates from Let's Encrypt for TLSAutocertDomains. HTTPS is served with HTTP/2.
+	TLSCert             string
+	TLSKey              string
+	TLSAutocertDomains  []string
+	TLSAutocertEmail    string // Let's Encrypt account contact (optional)
+	TLSAutocertCacheDir string // Where issued certificates are kept across restarts
+	HTTPRedirectPort    string // Plain HTTP port redirecting to HTTPS and answering ACME challenges; empty disables
+
 	// Database
 	DBHost     string
 	DBPort     string
@@ -22,7 +38,18 @@ type Config struct {
 
 	// JWT
 	JWTSecret     string
-	JWTExpiration int // hours
+	JWTExpiration int // hours; how long a session can be refreshed without signing in again
+	// JWTAccessTTLMinutes is the lifetime of access tokens. Clients get new ones from
+	// /auth/refresh, which also picks up role and plan changes.
+	JWTAccessTTLMinutes int
+
+	// Password hashing: bcrypt or argon2id. Passwords hashed with other settings are
+	// rehashed when their user signs in.
+	PasswordHashAlgorithm string
+	BcryptCost            int
+	Argon2MemoryKiB       int
+	Argon2Iterations      int
+	Argon2Parallelism     int
 
 	// Encryption - for encrypting sensitive data like database passwords
 	EncryptionKey string // 32-byte key for AES-256, base64 encoded or raw 32 chars
@@ -32,10 +59,89 @@ type Config struct {
 
 	// AI Service
 	AIServiceURL string
+	// Mutual TLS to the AI service: the CA its certificate must chain to, and the client
+	// certificate the backend presents (PEM file paths)
+	AIServiceTLSCA         string
+	AIServiceTLSCert       string
+	AIServiceTLSKey        string
+	AIServiceTLSServerName string // Name in the AI service's certificate, if not the URL host
+	// Regional AI service instances and artifact buckets for organizations pinned to a
+	// data region
+	AIServiceURLEU   string
+	AIServiceURLUS   string
+	ArtifactBucketEU string
+	ArtifactBucketUS string
+	// Migrations each AI service instance runs at once (0 for no limit); the default of
+	// the ai_migration_slots runtime setting
+	AIServiceMigrationSlots int
+
+	// Internal routes (AI service callbacks). With a certificate and client CA set they're
+	// served only on InternalPort over mutual TLS instead of on the public port.
+	InternalPort           string
+	InternalTLSCert        string
+	InternalTLSKey         string
+	InternalTLSClientCA    string
+	InternalTLSClientNames []string // Client certificate names allowed, e.g. ai-service; empty allows any
+	// Shared secret the AI service sends as X-Service-Token when internal routes are on
+	// the public port
+	InternalServiceToken string
 
 	// Static files (frontend)
 	StaticDir string
 
+	// API documentation at /swagger: public, basic (SwaggerUsername/SwaggerPassword),
+	// admin (admin JWT or API key) or disabled. Disabled by default in production.
+	SwaggerMode     string
+	SwaggerUsername string
+	SwaggerPassword string
+
+	// CAPTCHA (hCaptcha or Cloudflare Turnstile) on public auth endpoints
+	CaptchaProvider      string // hcaptcha, turnstile, or empty to disable
+	CaptchaSiteKey       string
+	CaptchaSecret        string
+	CaptchaLoginFailures int // Failed logins (per email or IP) before login requires a CAPTCHA
+
+	// SIEM export of security audit events
+	SIEMProvider     string // splunk, datadog, http, syslog, or empty to disable
+	SIEMEndpoint     string
+	SIEMToken        string
+	SIEMBatchSize    int
+	SIEMFlushSeconds int
+	SIEMMaxRetries   int
+	SIEMFieldMapping string // e.g. "ip_address=src_ip,user_id=uid"
+
+	// Metadata cache for source database schema scans
+	RedisURL                string // Optional; the cache uses Postgres when empty
+	MetadataCacheTTLMinutes int
+
+	// dbt seeds: tables at or below this many rows can be exported as seed CSVs
+	SeedMaxRows int
+
+	// Power BI service principal for discovering exposures (optional)
+	PowerBITenantID     string
+	PowerBIClientID     string
+	PowerBIClientSecret string
+
+	// dbt Cloud account for provisioning jobs from completed migrations (optional)
+	DbtCloudAPIURL    string
+	DbtCloudAccountID int
+	DbtCloudAPIToken  string
+
+	// Social login (OAuth 2.0). A provider is enabled when its client ID and secret are set.
+	OAuthRedirectBaseURL    string // Frontend URL the providers redirect back to
+	OAuthGoogleClientID     string
+	OAuthGoogleClientSecret string
+	OAuthGitHubClientID     string
+	OAuthGitHubClientSecret string
+	OAuthAzureTenantID      string
+	OAuthAzureClientID      string
+	OAuthAzureClientSecret  string
+
+	// Demo data (trials and E2E tests)
+	SeedDemoData     bool   // Create the demo organization on startup if it doesn't exist
+	SeedDemoPassword string // Password for the demo users
+	SeedFixtureHost  string // SQL Server hosting the AdventureWorksLite fixture
+
 	// Environment
 	Environment string // development, staging, production
 }
@@ -49,39 +155,121 @@ func Load() (*Config, error) {
 		ServerPort: getEnv("SERVER_PORT", "8080"),
 		ServerHost: getEnv("SERVER_HOST", "0.0.0.0"),
 
+		// Native TLS (plain HTTP unless TLS_CERT or TLS_AUTOCERT_DOMAINS is set)
+		TLSCert:             getEnv("TLS_CERT", ""),
+		TLSKey:              getEnv("TLS_KEY", ""),
+		TLSAutocertDomains:  getEnvList("TLS_AUTOCERT_DOMAINS", nil),
+		TLSAutocertEmail:    getEnv("TLS_AUTOCERT_EMAIL", ""),
+		TLSAutocertCacheDir: getEnv("TLS_AUTOCERT_CACHE_DIR", "autocert-cache"),
+		HTTPRedirectPort:    getEnv("HTTP_REDIRECT_PORT", ""),
+
 		// Database defaults
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "datamigrate"),
-		DBPassword: getEnv("DB_PASSWORD", "datamigrate123"),
-		DBName:     getEnv("DB_NAME", "datamigrate"),
-		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
+		DBHost:    getEnv("DB_HOST", "localhost"),
+		DBPort:    getEnv("DB_PORT", "5432"),
+		DBUser:    getEnv("DB_USER", "datamigrate"),
+		DBName:    getEnv("DB_NAME", "datamigrate"),
+		DBSSLMode: getEnv("DB_SSL_MODE", "disable"),
 
 		// JWT defaults
-		JWTSecret:     getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
-		JWTExpiration: getEnvInt("JWT_EXPIRATION_HOURS", 24),
+		JWTExpiration:       getEnvInt("JWT_EXPIRATION_HOURS", 24),
+		JWTAccessTTLMinutes: getEnvInt("JWT_ACCESS_TTL_MINUTES", 15),
 
-		// CORS
-		AllowedOrigins: []string{
+		// Password hashing (bcrypt at its default cost unless set)
+		PasswordHashAlgorithm: getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt"),
+		BcryptCost:            getEnvInt("BCRYPT_COST", 10),
+		Argon2MemoryKiB:       getEnvInt("ARGON2_MEMORY_KIB", 64*1024),
+		Argon2Iterations:      getEnvInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism:     getEnvInt("ARGON2_PARALLELISM", 2),
+
+		// CORS (comma-separated list of exact origins)
+		AllowedOrigins: getEnvList("ALLOWED_ORIGINS", []string{
 			"http://localhost:5173",
 			"http://localhost:5174",
 			"http://localhost:3000",
-		},
+		}),
 
 		// AI Service (Python FastAPI microservice)
-		AIServiceURL: getEnv("AI_SERVICE_URL", "http://localhost:8081"),
+		AIServiceURL:           getEnv("AI_SERVICE_URL", "http://localhost:8081"),
+		AIServiceTLSCA:         getEnv("AI_SERVICE_TLS_CA", ""),
+		AIServiceTLSCert:       getEnv("AI_SERVICE_TLS_CERT", ""),
+		AIServiceTLSKey:        getEnv("AI_SERVICE_TLS_KEY", ""),
+		AIServiceTLSServerName: getEnv("AI_SERVICE_TLS_SERVER_NAME", ""),
+		AIServiceURLEU:         getEnv("AI_SERVICE_URL_EU", ""),
+		AIServiceURLUS:         getEnv("AI_SERVICE_URL_US", ""),
+		ArtifactBucketEU:       getEnv("ARTIFACT_BUCKET_EU", ""),
+		ArtifactBucketUS:       getEnv("ARTIFACT_BUCKET_US", ""),
+
+		AIServiceMigrationSlots: getEnvInt("AI_SERVICE_MIGRATION_SLOTS", 0),
+
+		// Internal routes (public port unless INTERNAL_TLS_CERT and INTERNAL_TLS_CLIENT_CA are set)
+		InternalPort:           getEnv("INTERNAL_PORT", "8443"),
+		InternalTLSCert:        getEnv("INTERNAL_TLS_CERT", ""),
+		InternalTLSKey:         getEnv("INTERNAL_TLS_KEY", ""),
+		InternalTLSClientCA:    getEnv("INTERNAL_TLS_CLIENT_CA", ""),
+		InternalTLSClientNames: getEnvList("INTERNAL_TLS_CLIENT_NAMES", []string{"ai-service"}),
+		InternalServiceToken:   getEnv("INTERNAL_SERVICE_TOKEN", ""),
 
 		// Static files directory (frontend build output)
 		StaticDir: getEnv("STATIC_DIR", ""),
 
-		// Encryption key for database credentials (REQUIRED in production)
-		// Generate with: openssl rand -base64 32
-		EncryptionKey: getEnv("ENCRYPTION_KEY", ""),
+		// API documentation (public in development, disabled in production unless set)
+		SwaggerMode:     getEnv("SWAGGER_MODE", ""),
+		SwaggerUsername: getEnv("SWAGGER_USERNAME", "docs"),
+
+		// CAPTCHA (disabled unless a provider and secret are set)
+		CaptchaProvider:      getEnv("CAPTCHA_PROVIDER", ""),
+		CaptchaSiteKey:       getEnv("CAPTCHA_SITE_KEY", ""),
+		CaptchaLoginFailures: getEnvInt("CAPTCHA_LOGIN_FAILURES", 3),
+
+		// SIEM export (disabled unless a provider is set)
+		SIEMProvider:     getEnv("SIEM_PROVIDER", ""),
+		SIEMEndpoint:     getEnv("SIEM_ENDPOINT", ""),
+		SIEMBatchSize:    getEnvInt("SIEM_BATCH_SIZE", 100),
+		SIEMFlushSeconds: getEnvInt("SIEM_FLUSH_INTERVAL_SECONDS", 5),
+		SIEMMaxRetries:   getEnvInt("SIEM_MAX_RETRIES", 3),
+		SIEMFieldMapping: getEnv("SIEM_FIELD_MAPPING", ""),
+
+		// Metadata cache (Postgres unless REDIS_URL is set)
+		RedisURL:                getEnv("REDIS_URL", ""),
+		MetadataCacheTTLMinutes: getEnvInt("METADATA_CACHE_TTL_MINUTES", 60),
+
+		SeedMaxRows: getEnvInt("SEED_MAX_ROWS", 1000),
+
+		PowerBITenantID: getEnv("POWERBI_TENANT_ID", ""),
+		PowerBIClientID: getEnv("POWERBI_CLIENT_ID", ""),
+
+		DbtCloudAPIURL:    getEnv("DBT_CLOUD_API_URL", "https://cloud.getdbt.com"),
+		DbtCloudAccountID: getEnvInt("DBT_CLOUD_ACCOUNT_ID", 0),
+
+		OAuthRedirectBaseURL: getEnv("OAUTH_REDIRECT_BASE_URL", getEnv("FRONTEND_URL", "http://localhost:5173")),
+		OAuthGoogleClientID:  getEnv("OAUTH_GOOGLE_CLIENT_ID", ""),
+		OAuthGitHubClientID:  getEnv("OAUTH_GITHUB_CLIENT_ID", ""),
+		OAuthAzureTenantID:   getEnv("OAUTH_AZURE_TENANT_ID", "organizations"),
+		OAuthAzureClientID:   getEnv("OAUTH_AZURE_CLIENT_ID", ""),
+
+		// Demo data (disabled by default)
+		SeedDemoData:     getEnvBool("SEED_DEMO_DATA", false),
+		SeedDemoPassword: getEnv("SEED_DEMO_PASSWORD", ""),
+		SeedFixtureHost:  getEnv("SEED_FIXTURE_HOST", ""),
 
 		// Environment
 		Environment: getEnv("ENVIRONMENT", "development"),
 	}
 
+	// Secrets may come from the environment or from files (X_FILE), see secrets.go
+	secrets, err := LoadSecrets()
+	if err != nil {
+		return nil, err
+	}
+	cfg.ApplySecrets(secrets)
+
+	if cfg.SwaggerMode == "" {
+		cfg.SwaggerMode = "public"
+		if cfg.IsProduction() {
+			cfg.SwaggerMode = "disabled"
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -90,6 +278,21 @@ func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
 
+// TLSEnabled returns true if the public port serves HTTPS itself
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCert != "" || c.TLSKey != "" || len(c.TLSAutocertDomains) > 0
+}
+
+// AIServiceTLSEnabled returns true if calls to the AI service use mutual TLS
+func (c *Config) AIServiceTLSEnabled() bool {
+	return c.AIServiceTLSCA != "" || c.AIServiceTLSCert != "" || c.AIServiceTLSKey != ""
+}
+
+// InternalTLSEnabled returns true if internal routes are served over mutual TLS on their own port
+func (c *Config) InternalTLSEnabled() bool {
+	return c.InternalTLSCert != "" || c.InternalTLSClientCA != ""
+}
+
 // IsDevelopment returns true if running in development environment
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development" || c.Environment == ""
@@ -101,7 +304,9 @@ func (c *Config) GetDSN() string {
 		" user=" + c.DBUser +
 		" password=" + c.DBPassword +
 		" dbname=" + c.DBName +
-		" sslmode=" + c.DBSSLMode
+		" sslmode=" + c.DBSSLMode +
+		// Sessions work in UTC, so timestamptz values come back as UTC
+		" timezone=UTC"
 }
 
 func getEnv(key, defaultValue string) string {
@@ -119,3 +324,26 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
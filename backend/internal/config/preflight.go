@@ -0,0 +1,416 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/crypto"
+)
+
+// CheckStatus is the outcome of a single preflight check
+type CheckStatus string
+
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// PreflightCheck is one line of the startup report
+type PreflightCheck struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+// PreflightReport collects the results of validating the configuration at startup
+type PreflightReport struct {
+	Environment string
+	Checks      []PreflightCheck
+}
+
+// minJWTSecretLength is the shortest JWT secret accepted in production (256 bits of hex/base64 text)
+const minJWTSecretLength = 32
+
+// Preflight validates the configuration. Insecure defaults are warnings in development
+// and failures in production. pingDB checks that the database is reachable; pass nil
+// to skip that check.
+func (c *Config) Preflight(pingDB func() error) *PreflightReport {
+	r := &PreflightReport{Environment: c.Environment}
+
+	// severe is the status for insecure settings: fatal only in production
+	severe := CheckWarn
+	if c.IsProduction() {
+		severe = CheckFail
+	}
+
+	switch c.Environment {
+	case "development", "staging", "production":
+		r.add("Environment", CheckOK, c.Environment)
+	default:
+		r.add("Environment", CheckFail, fmt.Sprintf("ENVIRONMENT=%q must be development, staging, or production", c.Environment))
+	}
+
+	if port, err := strconv.Atoi(c.ServerPort); err != nil || port < 1 || port > 65535 {
+		r.add("Server port", CheckFail, fmt.Sprintf("SERVER_PORT=%q is not a valid port", c.ServerPort))
+	} else {
+		r.add("Server port", CheckOK, c.ServerHost+":"+c.ServerPort)
+	}
+
+	switch {
+	case c.JWTSecret == defaultJWTSecret:
+		r.add("JWT secret", severe, "JWT_SECRET is the built-in default; generate one with: openssl rand -hex 32")
+	case len(c.JWTSecret) < minJWTSecretLength:
+		r.add("JWT secret", severe, fmt.Sprintf("JWT_SECRET is %d characters; use at least %d", len(c.JWTSecret), minJWTSecretLength))
+	default:
+		r.add("JWT secret", CheckOK, fmt.Sprintf("%d characters", len(c.JWTSecret)))
+	}
+
+	if c.JWTExpiration <= 0 {
+		r.add("JWT expiration", CheckFail, fmt.Sprintf("JWT_EXPIRATION_HOURS=%d must be positive", c.JWTExpiration))
+	} else if c.JWTExpiration > 24*7 {
+		r.add("JWT expiration", CheckWarn, fmt.Sprintf("tokens are valid for %d hours", c.JWTExpiration))
+	} else {
+		r.add("JWT expiration", CheckOK, fmt.Sprintf("%d hours", c.JWTExpiration))
+	}
+
+	if c.EncryptionKey == "" {
+		r.add("Encryption key", severe, "ENCRYPTION_KEY is not set; connection passwords would be stored in plaintext")
+	} else if _, err := crypto.ParseKeyString(c.EncryptionKey); err != nil {
+		// A configured but unusable key is always fatal: data encrypted with it couldn't be read back
+		r.add("Encryption key", CheckFail, "ENCRYPTION_KEY is invalid: "+err.Error()+" (generate one with: openssl rand -base64 32)")
+	} else {
+		r.add("Encryption key", CheckOK, "AES-256 key configured")
+	}
+
+	r.checkPasswordHashing(c)
+
+	if c.DBPassword == defaultDBPassword {
+		r.add("Database password", severe, "DB_PASSWORD is the built-in default")
+	} else if c.DBPassword == "" {
+		r.add("Database password", CheckWarn, "DB_PASSWORD is empty")
+	} else {
+		r.add("Database password", CheckOK, "set")
+	}
+
+	if c.DBSSLMode == "disable" && c.IsProduction() {
+		r.add("Database TLS", CheckWarn, "DB_SSL_MODE=disable; traffic to PostgreSQL is unencrypted")
+	} else {
+		r.add("Database TLS", CheckOK, "sslmode="+c.DBSSLMode)
+	}
+
+	if pingDB != nil {
+		if err := pingDB(); err != nil {
+			r.add("Database", CheckFail, fmt.Sprintf("cannot reach %s:%s/%s: %v", c.DBHost, c.DBPort, c.DBName, err))
+		} else {
+			r.add("Database", CheckOK, fmt.Sprintf("reachable at %s:%s/%s", c.DBHost, c.DBPort, c.DBName))
+		}
+	}
+
+	r.checkAllowedOrigins(c, severe)
+
+	if c.CaptchaProvider != "" {
+		switch {
+		case c.CaptchaProvider != "hcaptcha" && c.CaptchaProvider != "turnstile":
+			r.add("CAPTCHA", CheckFail, fmt.Sprintf("CAPTCHA_PROVIDER=%q must be hcaptcha or turnstile", c.CaptchaProvider))
+		case c.CaptchaSecret == "" || c.CaptchaSiteKey == "":
+			r.add("CAPTCHA", CheckFail, "CAPTCHA_SECRET and CAPTCHA_SITE_KEY are required when CAPTCHA_PROVIDER is set")
+		default:
+			r.add("CAPTCHA", CheckOK, c.CaptchaProvider)
+		}
+	}
+
+	r.checkServerTLS(c)
+	r.checkSwagger(c)
+	r.checkAIServiceTLS(c)
+	r.checkDataRegions(c)
+	r.checkInternalTLS(c)
+	r.checkOAuth(c)
+
+	if c.SeedDemoData && c.IsProduction() {
+		r.add("Demo data", CheckWarn, "SEED_DEMO_DATA is ignored in production")
+	}
+
+	return r
+}
+
+// checkPasswordHashing validates the password hash settings. The ranges are those of
+// the security package, which checks them again when it's configured.
+func (r *PreflightReport) checkPasswordHashing(c *Config) {
+	switch c.PasswordHashAlgorithm {
+	case "bcrypt":
+		if c.BcryptCost < 4 || c.BcryptCost > 31 {
+			r.add("Password hashing", CheckFail, fmt.Sprintf("BCRYPT_COST=%d must be between 4 and 31", c.BcryptCost))
+		} else if c.BcryptCost < 10 {
+			r.add("Password hashing", CheckWarn, fmt.Sprintf("BCRYPT_COST=%d is below the default of 10", c.BcryptCost))
+		} else {
+			r.add("Password hashing", CheckOK, fmt.Sprintf("bcrypt, cost %d", c.BcryptCost))
+		}
+	case "argon2id":
+		if c.Argon2Iterations < 1 || c.Argon2Parallelism < 1 || c.Argon2Parallelism > 255 || c.Argon2MemoryKiB < 8*c.Argon2Parallelism {
+			r.add("Password hashing", CheckFail, "ARGON2_ITERATIONS and ARGON2_PARALLELISM (up to 255) must be at least 1, and ARGON2_MEMORY_KIB at least 8 per thread")
+		} else {
+			r.add("Password hashing", CheckOK, fmt.Sprintf("argon2id, %d KiB, %d iterations, %d threads", c.Argon2MemoryKiB, c.Argon2Iterations, c.Argon2Parallelism))
+		}
+	default:
+		r.add("Password hashing", CheckFail, fmt.Sprintf("PASSWORD_HASH_ALGORITHM=%q must be bcrypt or argon2id", c.PasswordHashAlgorithm))
+	}
+}
+
+// checkServerTLS validates native HTTPS on the public port
+func (r *PreflightReport) checkServerTLS(c *Config) {
+	if !c.TLSEnabled() {
+		if c.HTTPRedirectPort != "" {
+			r.add("HTTPS", CheckFail, "HTTP_REDIRECT_PORT needs TLS_CERT or TLS_AUTOCERT_DOMAINS")
+		}
+		return
+	}
+
+	switch {
+	case c.TLSCert != "" && len(c.TLSAutocertDomains) > 0:
+		r.add("HTTPS", CheckFail, "set either TLS_CERT or TLS_AUTOCERT_DOMAINS, not both")
+	case len(c.TLSAutocertDomains) == 0 && (c.TLSCert == "" || c.TLSKey == ""):
+		r.add("HTTPS", CheckFail, "TLS_CERT and TLS_KEY must be set together")
+	case c.HTTPRedirectPort != "" && (c.HTTPRedirectPort == c.ServerPort || c.InternalTLSEnabled() && c.HTTPRedirectPort == c.InternalPort):
+		r.add("HTTPS", CheckFail, fmt.Sprintf("HTTP_REDIRECT_PORT=%s is already in use", c.HTTPRedirectPort))
+	case len(c.TLSAutocertDomains) > 0 && c.HTTPRedirectPort != "80" && c.ServerPort != "443":
+		r.add("HTTPS", CheckFail, "Let's Encrypt validates on port 80 or 443: set HTTP_REDIRECT_PORT=80 or SERVER_PORT=443")
+	case len(c.TLSAutocertDomains) > 0:
+		r.add("HTTPS", CheckOK, "Let's Encrypt certificates for "+strings.Join(c.TLSAutocertDomains, ", "))
+	default:
+		r.add("HTTPS", CheckOK, "certificate "+c.TLSCert)
+	}
+}
+
+// checkSwagger validates access to the API documentation
+func (r *PreflightReport) checkSwagger(c *Config) {
+	switch c.SwaggerMode {
+	case "disabled":
+		r.add("API docs", CheckOK, "disabled")
+	case "public":
+		if c.IsProduction() {
+			r.add("API docs", CheckWarn, "SWAGGER_MODE=public publishes the API documentation to anyone")
+		} else {
+			r.add("API docs", CheckOK, "public")
+		}
+	case "basic":
+		if c.SwaggerUsername == "" || c.SwaggerPassword == "" {
+			r.add("API docs", CheckFail, "SWAGGER_USERNAME and SWAGGER_PASSWORD are required when SWAGGER_MODE=basic")
+		} else {
+			r.add("API docs", CheckOK, "basic authentication")
+		}
+	case "admin":
+		r.add("API docs", CheckOK, "admins only")
+	default:
+		r.add("API docs", CheckFail, fmt.Sprintf("SWAGGER_MODE=%q must be public, basic, admin or disabled", c.SwaggerMode))
+	}
+}
+
+// checkAIServiceTLS validates the mutual TLS settings for calls to the AI service
+func (r *PreflightReport) checkAIServiceTLS(c *Config) {
+	if !c.AIServiceTLSEnabled() {
+		if c.IsProduction() {
+			r.add("AI service TLS", CheckWarn, "AI_SERVICE_TLS_CA is not set; traffic to the AI service is not mutually authenticated")
+		}
+		return
+	}
+
+	u, err := url.Parse(c.AIServiceURL)
+	switch {
+	case err != nil || u.Scheme != "https":
+		r.add("AI service TLS", CheckFail, fmt.Sprintf("AI_SERVICE_URL=%q must be an https URL when AI service TLS is configured", c.AIServiceURL))
+	case c.AIServiceTLSCA == "":
+		r.add("AI service TLS", CheckFail, "AI_SERVICE_TLS_CA is required to verify the AI service")
+	case (c.AIServiceTLSCert == "") != (c.AIServiceTLSKey == ""):
+		r.add("AI service TLS", CheckFail, "AI_SERVICE_TLS_CERT and AI_SERVICE_TLS_KEY must be set together")
+	case c.AIServiceTLSCert == "":
+		r.add("AI service TLS", CheckWarn, "server verified against AI_SERVICE_TLS_CA, but no client certificate is presented")
+	default:
+		r.add("AI service TLS", CheckOK, "mutual TLS")
+	}
+}
+
+// checkDataRegions validates the regional AI service instances organizations can be
+// pinned to
+func (r *PreflightReport) checkDataRegions(c *Config) {
+	regions := []struct{ name, url, bucket string }{
+		{"EU", c.AIServiceURLEU, c.ArtifactBucketEU},
+		{"US", c.AIServiceURLUS, c.ArtifactBucketUS},
+	}
+	var configured []string
+	for _, region := range regions {
+		if region.url == "" {
+			if region.bucket != "" {
+				r.add("Data regions", CheckFail, fmt.Sprintf("ARTIFACT_BUCKET_%s is set without AI_SERVICE_URL_%s", region.name, region.name))
+				return
+			}
+			continue
+		}
+		if u, err := url.Parse(region.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			r.add("Data regions", CheckFail, fmt.Sprintf("AI_SERVICE_URL_%s=%q is not a valid URL", region.name, region.url))
+			return
+		}
+		if region.bucket == "" {
+			r.add("Data regions", CheckWarn, fmt.Sprintf("ARTIFACT_BUCKET_%s is not set; the %s instance stores projects in its default bucket", region.name, region.name))
+			return
+		}
+		configured = append(configured, region.name)
+	}
+	if len(configured) > 0 {
+		r.add("Data regions", CheckOK, strings.Join(configured, ", "))
+	}
+}
+
+// checkInternalTLS validates the mutual TLS listener for internal routes
+func (r *PreflightReport) checkInternalTLS(c *Config) {
+	if !c.InternalTLSEnabled() {
+		if c.InternalServiceToken == "" {
+			r.add("Internal routes", CheckWarn, "neither INTERNAL_TLS_CERT nor INTERNAL_SERVICE_TOKEN is set; the AI service callbacks on /internal are refused")
+		} else if c.IsProduction() {
+			r.add("Internal routes", CheckWarn, "INTERNAL_TLS_CERT is not set; AI service callbacks are served on the public port")
+		}
+		return
+	}
+
+	switch {
+	case c.InternalTLSCert == "" || c.InternalTLSKey == "" || c.InternalTLSClientCA == "":
+		r.add("Internal routes", CheckFail, "INTERNAL_TLS_CERT, INTERNAL_TLS_KEY and INTERNAL_TLS_CLIENT_CA must all be set")
+	case c.InternalPort == c.ServerPort:
+		r.add("Internal routes", CheckFail, fmt.Sprintf("INTERNAL_PORT=%s must differ from SERVER_PORT", c.InternalPort))
+	default:
+		if port, err := strconv.Atoi(c.InternalPort); err != nil || port < 1 || port > 65535 {
+			r.add("Internal routes", CheckFail, fmt.Sprintf("INTERNAL_PORT=%q is not a valid port", c.InternalPort))
+			return
+		}
+		r.add("Internal routes", CheckOK, "mutual TLS on port "+c.InternalPort)
+	}
+}
+
+// checkOAuth validates the social login providers
+func (r *PreflightReport) checkOAuth(c *Config) {
+	providers := []struct{ name, id, secret, env string }{
+		{"Google", c.OAuthGoogleClientID, c.OAuthGoogleClientSecret, "OAUTH_GOOGLE"},
+		{"GitHub", c.OAuthGitHubClientID, c.OAuthGitHubClientSecret, "OAUTH_GITHUB"},
+		{"Azure AD", c.OAuthAzureClientID, c.OAuthAzureClientSecret, "OAUTH_AZURE"},
+	}
+
+	var enabled []string
+	for _, p := range providers {
+		if (p.id == "") != (p.secret == "") {
+			r.add("Social login", CheckFail, fmt.Sprintf("%s_CLIENT_ID and %s_CLIENT_SECRET must be set together", p.env, p.env))
+			return
+		}
+		if p.id != "" {
+			enabled = append(enabled, p.name)
+		}
+	}
+	if len(enabled) == 0 {
+		return
+	}
+
+	u, err := url.Parse(c.OAuthRedirectBaseURL)
+	switch {
+	case err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http"):
+		r.add("Social login", CheckFail, fmt.Sprintf("OAUTH_REDIRECT_BASE_URL=%q is not an absolute URL", c.OAuthRedirectBaseURL))
+	case u.Scheme != "https" && c.IsProduction():
+		r.add("Social login", CheckFail, "OAUTH_REDIRECT_BASE_URL must use https in production")
+	default:
+		r.add("Social login", CheckOK, strings.Join(enabled, ", "))
+	}
+}
+
+// checkAllowedOrigins validates ALLOWED_ORIGINS entries as scheme://host[:port] origins
+func (r *PreflightReport) checkAllowedOrigins(c *Config, severe CheckStatus) {
+	if len(c.AllowedOrigins) == 0 {
+		r.add("Allowed origins", CheckWarn, "ALLOWED_ORIGINS is empty; browsers can only reach the API from Railway domains")
+		return
+	}
+
+	var problems []string
+	status := CheckOK
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			problems = append(problems, "\"*\" is not supported (credentials are allowed); list origins explicitly")
+			status = worst(status, CheckFail)
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			problems = append(problems, fmt.Sprintf("%q is not an origin (expected scheme://host[:port])", origin))
+			status = worst(status, CheckFail)
+			continue
+		}
+		if strings.HasSuffix(origin, "/") {
+			// The Origin header never has a trailing slash, so this entry would never match
+			problems = append(problems, fmt.Sprintf("%q has a trailing slash and will never match", origin))
+			status = worst(status, CheckFail)
+			continue
+		}
+
+		local := u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1"
+		if u.Scheme == "http" && !local {
+			problems = append(problems, fmt.Sprintf("%q is not HTTPS", origin))
+			status = worst(status, severe)
+		}
+		if local && c.IsProduction() {
+			problems = append(problems, fmt.Sprintf("%q is a local development origin", origin))
+			status = worst(status, CheckWarn)
+		}
+	}
+
+	if len(problems) == 0 {
+		r.add("Allowed origins", CheckOK, strings.Join(c.AllowedOrigins, ", "))
+		return
+	}
+	r.add("Allowed origins", status, strings.Join(problems, "; "))
+}
+
+func worst(a, b CheckStatus) CheckStatus {
+	rank := map[CheckStatus]int{CheckOK: 0, CheckWarn: 1, CheckFail: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func (r *PreflightReport) add(name string, status CheckStatus, message string) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Message: message})
+}
+
+// Failed returns the checks that prevent startup
+func (r *PreflightReport) Failed() []PreflightCheck {
+	var failed []PreflightCheck
+	for _, check := range r.Checks {
+		if check.Status == CheckFail {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// Err summarizes failed checks, or returns nil when the configuration is usable
+func (r *PreflightReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	names := make([]string, len(failed))
+	for i, check := range failed {
+		names[i] = check.Name
+	}
+	return fmt.Errorf("configuration preflight failed: %s", strings.Join(names, ", "))
+}
+
+// Write prints the report as an aligned table
+func (r *PreflightReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "Startup preflight (environment: %s)\n", r.Environment)
+	for _, check := range r.Checks {
+		label := map[CheckStatus]string{CheckOK: " OK ", CheckWarn: "WARN", CheckFail: "FAIL"}[check.Status]
+		fmt.Fprintf(w, "  [%s] %-18s %s\n", label, check.Name, check.Message)
+	}
+}
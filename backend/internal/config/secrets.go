@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Secrets are the configuration values that can be supplied through files instead of
+// the environment. For each variable X, setting X_FILE reads the value from that path
+// (Docker and Kubernetes secrets), which keeps it out of `env` and /proc listings.
+type Secrets struct {
+	DBPassword    string
+	JWTSecret     string
+	EncryptionKey string // Generate with: openssl rand -base64 32
+	CaptchaSecret string
+	SIEMToken     string
+
+	PowerBIClientSecret string
+	DbtCloudAPIToken    string
+
+	OAuthGoogleClientSecret string
+	OAuthGitHubClientSecret string
+	OAuthAzureClientSecret  string
+
+	SwaggerPassword string
+}
+
+// LoadSecrets reads all secrets. It's called by Load and again on SIGHUP so rotated
+// secret files are picked up without a restart.
+func LoadSecrets() (Secrets, error) {
+	var s Secrets
+	var err error
+
+	if s.DBPassword, err = getSecret("DB_PASSWORD", defaultDBPassword); err != nil {
+		return s, err
+	}
+	if s.JWTSecret, err = getSecret("JWT_SECRET", defaultJWTSecret); err != nil {
+		return s, err
+	}
+	if s.EncryptionKey, err = getSecret("ENCRYPTION_KEY", ""); err != nil {
+		return s, err
+	}
+	if s.CaptchaSecret, err = getSecret("CAPTCHA_SECRET", ""); err != nil {
+		return s, err
+	}
+	if s.SIEMToken, err = getSecret("SIEM_TOKEN", ""); err != nil {
+		return s, err
+	}
+	if s.PowerBIClientSecret, err = getSecret("POWERBI_CLIENT_SECRET", ""); err != nil {
+		return s, err
+	}
+	if s.DbtCloudAPIToken, err = getSecret("DBT_CLOUD_API_TOKEN", ""); err != nil {
+		return s, err
+	}
+	if s.OAuthGoogleClientSecret, err = getSecret("OAUTH_GOOGLE_CLIENT_SECRET", ""); err != nil {
+		return s, err
+	}
+	if s.OAuthGitHubClientSecret, err = getSecret("OAUTH_GITHUB_CLIENT_SECRET", ""); err != nil {
+		return s, err
+	}
+	if s.OAuthAzureClientSecret, err = getSecret("OAUTH_AZURE_CLIENT_SECRET", ""); err != nil {
+		return s, err
+	}
+	if s.SwaggerPassword, err = getSecret("SWAGGER_PASSWORD", ""); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// ApplySecrets copies secrets into the configuration
+func (c *Config) ApplySecrets(s Secrets) {
+	c.DBPassword = s.DBPassword
+	c.JWTSecret = s.JWTSecret
+	c.EncryptionKey = s.EncryptionKey
+	c.CaptchaSecret = s.CaptchaSecret
+	c.SIEMToken = s.SIEMToken
+	c.PowerBIClientSecret = s.PowerBIClientSecret
+	c.DbtCloudAPIToken = s.DbtCloudAPIToken
+	c.OAuthGoogleClientSecret = s.OAuthGoogleClientSecret
+	c.OAuthGitHubClientSecret = s.OAuthGitHubClientSecret
+	c.OAuthAzureClientSecret = s.OAuthAzureClientSecret
+	c.SwaggerPassword = s.SwaggerPassword
+}
+
+// getSecret returns the contents of the file named by key_FILE, or the key variable
+// itself. Setting both is an error rather than silently preferring one.
+func getSecret(key, defaultValue string) (string, error) {
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return getEnv(key, defaultValue), nil
+	}
+	if os.Getenv(key) != "" {
+		return "", fmt.Errorf("both %s and %s_FILE are set", key, key)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
+	}
+	// Secret files usually end with a newline (echo, kubectl create secret --from-file)
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("%s_FILE %s is empty", key, path)
+	}
+	return value, nil
+}
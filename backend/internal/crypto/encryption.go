@@ -17,6 +17,10 @@ type EncryptionService struct {
 	mu     sync.RWMutex
 	gcm    cipher.AEAD
 	keySet bool
+
+	// previous is the key replaced by the last RotateKey; it's only used to decrypt
+	// values written before the rotation
+	previous cipher.AEAD
 }
 
 var (
@@ -80,17 +84,48 @@ func (s *EncryptionService) SetKey(key []byte) error {
 	return nil
 }
 
+// RotateKey replaces the encryption key while keeping the old one for decryption, so
+// values encrypted before the rotation stay readable until they're re-encrypted
+func (s *EncryptionService) RotateKey(key []byte) error {
+	s.mu.RLock()
+	previous, hadKey := s.gcm, s.keySet
+	s.mu.RUnlock()
+
+	if err := s.SetKey(key); err != nil {
+		return err
+	}
+
+	if hadKey {
+		s.mu.Lock()
+		s.previous = previous
+		s.mu.Unlock()
+	}
+	return nil
+}
+
 // SetKeyFromString sets the encryption key from a base64-encoded string
 func (s *EncryptionService) SetKeyFromString(keyStr string) error {
+	key, err := ParseKeyString(keyStr)
+	if err != nil {
+		return err
+	}
+	return s.SetKey(key)
+}
+
+// ParseKeyString decodes a base64 key (or a raw 32-character key) and checks its length
+func ParseKeyString(keyStr string) ([]byte, error) {
 	key, err := base64.StdEncoding.DecodeString(keyStr)
 	if err != nil {
 		// Try using the string directly if it's exactly 32 bytes
 		if len(keyStr) == 32 {
-			return s.SetKey([]byte(keyStr))
+			return []byte(keyStr), nil
 		}
-		return fmt.Errorf("invalid base64 key: %w", err)
+		return nil, fmt.Errorf("invalid base64 key: %w", err)
 	}
-	return s.SetKey(key)
+	if len(key) != 32 {
+		return nil, ErrInvalidKeyLength
+	}
+	return key, nil
 }
 
 // IsKeySet returns whether an encryption key has been configured
@@ -148,9 +183,9 @@ func (s *EncryptionService) Decrypt(ciphertextBase64 string) (string, error) {
 	nonce, encryptedData := ciphertext[:nonceSize], ciphertext[nonceSize:]
 
 	// Decrypt and verify authentication
-	plaintext, err := s.gcm.Open(nil, nonce, encryptedData, nil)
+	plaintext, err := s.open(nonce, encryptedData)
 	if err != nil {
-		return "", ErrDecryptionFailed
+		return "", err
 	}
 
 	return string(plaintext), nil
@@ -189,14 +224,28 @@ func (s *EncryptionService) DecryptBytes(ciphertext []byte) ([]byte, error) {
 
 	nonce, encryptedData := ciphertext[:nonceSize], ciphertext[nonceSize:]
 
-	plaintext, err := s.gcm.Open(nil, nonce, encryptedData, nil)
+	plaintext, err := s.open(nonce, encryptedData)
 	if err != nil {
-		return nil, ErrDecryptionFailed
+		return nil, err
 	}
 
 	return plaintext, nil
 }
 
+// open decrypts with the current key, falling back to the pre-rotation key.
+// Callers must hold s.mu.
+func (s *EncryptionService) open(nonce, encryptedData []byte) ([]byte, error) {
+	if plaintext, err := s.gcm.Open(nil, nonce, encryptedData, nil); err == nil {
+		return plaintext, nil
+	}
+	if s.previous != nil {
+		if plaintext, err := s.previous.Open(nil, nonce, encryptedData, nil); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrDecryptionFailed
+}
+
 // GenerateKey generates a cryptographically secure 32-byte key for AES-256
 func GenerateKey() ([]byte, error) {
 	key := make([]byte, 32)
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// Envelope is a value encrypted with its own random data key. Only the data key is
+// encrypted with the service key, so the service key never touches the value itself.
+type Envelope struct {
+	DataKey    string `json:"data_key"`   // Data key encrypted with the service key, base64
+	Ciphertext string `json:"ciphertext"` // Value encrypted with the data key, base64
+}
+
+// Seal encrypts plaintext with a fresh data key and wraps the data key with the service key
+func (s *EncryptionService) Seal(plaintext []byte) (*Envelope, error) {
+	dataKey, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	wrappedKey, err := s.EncryptBytes(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		DataKey:    base64.StdEncoding.EncodeToString(wrappedKey),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// Unseal unwraps the envelope's data key with the service key and decrypts the value
+func (s *EncryptionService) Unseal(envelope *Envelope) ([]byte, error) {
+	wrappedKey, err := base64.StdEncoding.DecodeString(envelope.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+
+	dataKey, err := s.DecryptBytes(wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(ciphertext) < nonceSize+gcm.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := gcm.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKeyLength
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
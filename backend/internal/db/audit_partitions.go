@@ -0,0 +1,216 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// auditPartitionMonthsAhead is how many months past the current one have a
+// security_audit_logs partition ready
+const auditPartitionMonthsAhead = 3
+
+// auditLogIndexes are created on the partitioned security_audit_logs table and inherited
+// by every partition. Each one leads with a GetLogs filter and ends with the
+// (created_at, id) keyset order; the INCLUDE columns let the dashboard and stats
+// queries count by type, severity and blocked without visiting the table.
+var auditLogIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_security_audit_logs_time ON security_audit_logs(created_at DESC, id DESC) INCLUDE (event_type, severity, blocked, ip_address)",
+	"CREATE INDEX IF NOT EXISTS idx_security_audit_logs_org_time ON security_audit_logs(organization_id, created_at DESC, id DESC) INCLUDE (event_type, severity, blocked)",
+	"CREATE INDEX IF NOT EXISTS idx_security_audit_logs_event_time ON security_audit_logs(event_type, created_at DESC, id DESC) INCLUDE (severity, blocked)",
+	"CREATE INDEX IF NOT EXISTS idx_security_audit_logs_user_time ON security_audit_logs(user_id, created_at DESC, id DESC)",
+}
+
+// partitionAuditLogs moves a security_audit_logs table created before partitioning
+// into the monthly partitioned layout, then makes sure upcoming months have a
+// partition and the indexes exist
+func partitionAuditLogs() error {
+	var legacy bool
+	err := DB.Get(&legacy, "SELECT relkind = 'r' FROM pg_class WHERE oid = 'security_audit_logs'::regclass")
+	if err != nil {
+		return fmt.Errorf("failed to inspect security_audit_logs: %w", err)
+	}
+	if legacy {
+		if err := convertAuditLogs(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := DB.Exec("CREATE TABLE IF NOT EXISTS security_audit_logs_default PARTITION OF security_audit_logs DEFAULT"); err != nil {
+		return err
+	}
+	if err := EnsureAuditLogPartitions(time.Now()); err != nil {
+		return err
+	}
+	for _, stmt := range auditLogIndexes {
+		if _, err := DB.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to index security_audit_logs: %w", err)
+		}
+	}
+	return nil
+}
+
+// convertAuditLogs copies an unpartitioned security_audit_logs table into a partitioned
+// one in a single transaction. IDs keep their sequence, so existing references and
+// cursors stay valid.
+func convertAuditLogs() error {
+	tx, err := DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	setup := []string{
+		"ALTER TABLE security_audit_logs RENAME TO security_audit_logs_legacy",
+		"ALTER TABLE security_audit_logs_legacy RENAME CONSTRAINT security_audit_logs_pkey TO security_audit_logs_legacy_pkey",
+		`CREATE TABLE security_audit_logs (
+			id INTEGER NOT NULL DEFAULT nextval('security_audit_logs_id_seq'),
+			event_type VARCHAR(50) NOT NULL,
+			severity VARCHAR(20) NOT NULL,
+			user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+			organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL,
+			ip_address VARCHAR(45),
+			user_agent TEXT,
+			endpoint VARCHAR(255),
+			method VARCHAR(10),
+			request_body TEXT,
+			response_status INTEGER,
+			blocked BOOLEAN DEFAULT FALSE,
+			block_reason TEXT,
+			metadata JSONB,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id, created_at)
+		) PARTITION BY RANGE (created_at)`,
+		"ALTER SEQUENCE security_audit_logs_id_seq OWNED BY security_audit_logs.id",
+		"CREATE TABLE security_audit_logs_default PARTITION OF security_audit_logs DEFAULT",
+	}
+	for _, stmt := range setup {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to partition security_audit_logs: %w", err)
+		}
+	}
+
+	// One partition per month from the oldest row, so nothing lands in the default partition
+	var oldest *time.Time
+	if err := tx.Get(&oldest, "SELECT MIN(created_at) FROM security_audit_logs_legacy"); err != nil {
+		return err
+	}
+	if oldest != nil {
+		for month := monthStart(*oldest); !month.After(monthStart(time.Now())); month = month.AddDate(0, 1, 0) {
+			if _, err := tx.Exec(createAuditPartition(month)); err != nil {
+				return fmt.Errorf("failed to create audit log partition: %w", err)
+			}
+		}
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO security_audit_logs
+		(id, event_type, severity, user_id, organization_id, ip_address, user_agent,
+		 endpoint, method, request_body, response_status, blocked, block_reason, metadata, created_at)
+		SELECT id, event_type, severity, user_id, organization_id, ip_address, user_agent,
+		       endpoint, method, request_body, response_status, blocked, block_reason, metadata,
+		       COALESCE(created_at, CURRENT_TIMESTAMP)
+		FROM security_audit_logs_legacy
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to copy audit logs: %w", err)
+	}
+	if _, err := tx.Exec("DROP TABLE security_audit_logs_legacy"); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	moved, _ := result.RowsAffected()
+	log.Printf("Moved %d audit log rows into monthly partitions", moved)
+	return nil
+}
+
+// EnsureAuditLogPartitions creates the security_audit_logs partitions for the month of
+// now and the months after it. Rows already written to the default partition for one
+// of those months are moved into the new partition.
+func EnsureAuditLogPartitions(now time.Time) error {
+	current := monthStart(now)
+	for i := 0; i <= auditPartitionMonthsAhead; i++ {
+		month := current.AddDate(0, i, 0)
+		var exists bool
+		if err := DB.Get(&exists, "SELECT to_regclass($1) IS NOT NULL", auditPartitionName(month)); err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := addAuditPartition(month); err != nil {
+			return fmt.Errorf("failed to create audit log partition %s: %w", auditPartitionName(month), err)
+		}
+	}
+	return nil
+}
+
+func addAuditPartition(month time.Time) error {
+	from, to := month.Format("2006-01-02"), month.AddDate(0, 1, 0).Format("2006-01-02")
+
+	var stranded bool
+	err := DB.Get(&stranded, `
+		SELECT EXISTS (SELECT 1 FROM security_audit_logs_default WHERE created_at >= $1 AND created_at < $2)
+	`, from, to)
+	if err != nil {
+		return err
+	}
+	if !stranded {
+		_, err := DB.Exec(createAuditPartition(month))
+		return err
+	}
+
+	// PostgreSQL refuses a partition whose range has rows in the default partition
+	tx, err := DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	moves := []string{
+		"ALTER TABLE security_audit_logs DETACH PARTITION security_audit_logs_default",
+		createAuditPartition(month),
+		fmt.Sprintf("INSERT INTO security_audit_logs SELECT * FROM security_audit_logs_default WHERE created_at >= '%s' AND created_at < '%s'", from, to),
+		fmt.Sprintf("DELETE FROM security_audit_logs_default WHERE created_at >= '%s' AND created_at < '%s'", from, to),
+		"ALTER TABLE security_audit_logs ATTACH PARTITION security_audit_logs_default DEFAULT",
+	}
+	for _, stmt := range moves {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// StartAuditLogPartitionMaintenance creates upcoming audit log partitions once a day
+func StartAuditLogPartitionMaintenance() {
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			if err := EnsureAuditLogPartitions(now); err != nil {
+				log.Printf("Audit log partition maintenance failed: %v", err)
+			}
+		}
+	}()
+}
+
+// createAuditPartition is the DDL for the partition holding one month of audit logs
+func createAuditPartition(month time.Time) string {
+	return fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF security_audit_logs FOR VALUES FROM ('%s') TO ('%s')",
+		auditPartitionName(month), month.Format("2006-01-02"), month.AddDate(0, 1, 0).Format("2006-01-02"),
+	)
+}
+
+// auditPartitionName names the partition of a month, e.g. security_audit_logs_y2026m01
+func auditPartitionName(month time.Time) string {
+	return fmt.Sprintf("security_audit_logs_y%04dm%02d", month.Year(), int(month.Month()))
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
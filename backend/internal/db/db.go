@@ -1,24 +1,49 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/datamigrate-ai/backend/internal/config"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 var DB *sqlx.DB
 
-func Connect(cfg *config.Config) error {
-	dsn := cfg.GetDSN()
+// connector opens new pool connections with the current DSN, so credentials can be
+// rotated (see UpdateCredentials) without replacing DB, which handlers hold on to
+type connector struct {
+	mu  sync.RWMutex
+	dsn string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.RLock()
+	dsn := c.dsn
+	c.mu.RUnlock()
 
-	var err error
-	DB, err = sqlx.Connect("postgres", dsn)
+	pqConnector, err := pq.NewConnector(dsn)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
+	return pqConnector.Connect(ctx)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+var pool *connector
+
+func Connect(cfg *config.Config) error {
+	pool = &connector{dsn: cfg.GetDSN()}
+	DB = sqlx.NewDb(sql.OpenDB(pool), "postgres")
 
 	// Test connection
 	if err := DB.Ping(); err != nil {
@@ -29,6 +54,36 @@ func Connect(cfg *config.Config) error {
 	return nil
 }
 
+// Ping checks that the configured database is reachable without keeping a connection open
+func Ping(cfg *config.Config, timeout time.Duration) error {
+	conn, err := sqlx.Open("postgres", cfg.GetDSN())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return conn.PingContext(ctx)
+}
+
+// UpdateCredentials points new pool connections at cfg's DSN after checking that it
+// works. Open connections keep their session; PostgreSQL only checks the password
+// when a connection is established.
+func UpdateCredentials(cfg *config.Config) error {
+	if pool == nil {
+		return fmt.Errorf("database not connected")
+	}
+	if err := Ping(cfg, 5*time.Second); err != nil {
+		return fmt.Errorf("new credentials rejected: %w", err)
+	}
+
+	pool.mu.Lock()
+	pool.dsn = cfg.GetDSN()
+	pool.mu.Unlock()
+	return nil
+}
+
 func Close() {
 	if DB != nil {
 		DB.Close()
@@ -46,8 +101,8 @@ func RunMigrations() error {
 		plan VARCHAR(50) DEFAULT 'free',
 		max_users INTEGER DEFAULT 5,
 		max_migrations INTEGER DEFAULT 10,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Users table
@@ -63,9 +118,9 @@ func RunMigrations() error {
 		role VARCHAR(50) DEFAULT 'member',
 		is_admin BOOLEAN DEFAULT FALSE,
 		is_active BOOLEAN DEFAULT TRUE,
-		last_login_at TIMESTAMP,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		last_login_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Organization invitations table
@@ -76,9 +131,9 @@ func RunMigrations() error {
 		role VARCHAR(50) DEFAULT 'member',
 		token VARCHAR(255) UNIQUE NOT NULL,
 		invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
-		expires_at TIMESTAMP NOT NULL,
-		accepted_at TIMESTAMP,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		expires_at TIMESTAMPTZ NOT NULL,
+		accepted_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Migrations table (organization_id added via ALTER TABLE for existing tables)
@@ -96,9 +151,9 @@ func RunMigrations() error {
 		user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
 		error TEXT,
 		config JSONB,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		completed_at TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		completed_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Database connections table (organization_id added via ALTER TABLE for existing tables)
@@ -114,8 +169,8 @@ func RunMigrations() error {
 		use_windows_auth BOOLEAN DEFAULT FALSE,
 		is_source BOOLEAN DEFAULT TRUE,
 		user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- API keys table
@@ -126,8 +181,8 @@ func RunMigrations() error {
 		is_active BOOLEAN DEFAULT TRUE,
 		rate_limit INTEGER DEFAULT 1000,
 		user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-		last_used_at TIMESTAMP,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		last_used_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Migration logs table (for detailed progress tracking)
@@ -136,7 +191,7 @@ func RunMigrations() error {
 		migration_id INTEGER REFERENCES migrations(id) ON DELETE CASCADE,
 		level VARCHAR(20) NOT NULL,
 		message TEXT NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Warehouse deployments table (for tracking dbt deployments)
@@ -154,13 +209,14 @@ func RunMigrations() error {
 		dbt_test_output TEXT,
 		error TEXT,
 		user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		completed_at TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		completed_at TIMESTAMPTZ
 	);
 
-	-- Security audit logs table (Guardian Agent)
+	-- Security audit logs table (Guardian Agent), partitioned by month of created_at.
+	-- Partitions and indexes are managed in audit_partitions.go.
 	CREATE TABLE IF NOT EXISTS security_audit_logs (
-		id SERIAL PRIMARY KEY,
+		id SERIAL,
 		event_type VARCHAR(50) NOT NULL,
 		severity VARCHAR(20) NOT NULL,
 		user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
@@ -174,8 +230,9 @@ func RunMigrations() error {
 		blocked BOOLEAN DEFAULT FALSE,
 		block_reason TEXT,
 		metadata JSONB,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id, created_at)
+	) PARTITION BY RANGE (created_at);
 
 	-- Rate limiting table
 	CREATE TABLE IF NOT EXISTS rate_limits (
@@ -183,7 +240,7 @@ func RunMigrations() error {
 		identifier VARCHAR(255) NOT NULL,
 		endpoint VARCHAR(255) NOT NULL,
 		request_count INTEGER DEFAULT 1,
-		window_start TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		window_start TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
 		UNIQUE(identifier, endpoint)
 	);
 
@@ -196,8 +253,8 @@ func RunMigrations() error {
 		rules JSONB NOT NULL,
 		is_active BOOLEAN DEFAULT TRUE,
 		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Blocked patterns table (for suspicious input detection)
@@ -208,19 +265,568 @@ func RunMigrations() error {
 		description TEXT,
 		severity VARCHAR(20) DEFAULT 'medium',
 		is_active BOOLEAN DEFAULT TRUE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Password reset tokens table
 	CREATE TABLE IF NOT EXISTS password_reset_tokens (
 		id SERIAL PRIMARY KEY,
 		user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
-		token VARCHAR(255) UNIQUE NOT NULL,
-		expires_at TIMESTAMP NOT NULL,
-		used_at TIMESTAMP,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA-256 of the emailed token
+		expires_at TIMESTAMPTZ NOT NULL,
+		used_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- AI interactions table (compliance audit of prompts and outputs)
+	CREATE TABLE IF NOT EXISTS ai_interactions (
+		id SERIAL PRIMARY KEY,
+		interaction_type VARCHAR(50) NOT NULL,
+		user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL,
+		migration_id INTEGER REFERENCES migrations(id) ON DELETE SET NULL,
+		prompt TEXT NOT NULL,
+		response TEXT NOT NULL DEFAULT '',
+		was_filtered BOOLEAN DEFAULT FALSE,
+		contains_injection BOOLEAN DEFAULT FALSE,
+		prompt_tokens INTEGER DEFAULT 0,
+		completion_tokens INTEGER DEFAULT 0,
+		tokens_estimated BOOLEAN DEFAULT FALSE,
+		latency_ms INTEGER DEFAULT 0,
+		status VARCHAR(20) NOT NULL DEFAULT 'success',
+		error TEXT,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Password history table (previous hashes for reuse checks)
+	CREATE TABLE IF NOT EXISTS password_history (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		password_hash VARCHAR(255) NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Organization IP allowlists (CIDR ranges permitted to access the app)
+	CREATE TABLE IF NOT EXISTS organization_ip_allowlists (
+		id SERIAL PRIMARY KEY,
+		organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		cidr VARCHAR(50) NOT NULL,
+		description TEXT,
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(organization_id, cidr)
+	);
+
+	-- Security alerts table (anomalies flagged from the audit log)
+	CREATE TABLE IF NOT EXISTS security_alerts (
+		id SERIAL PRIMARY KEY,
+		alert_type VARCHAR(50) NOT NULL,
+		severity VARCHAR(20) NOT NULL,
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+		user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		ip_address VARCHAR(45),
+		title VARCHAR(255) NOT NULL,
+		details JSONB,
+		dedupe_key VARCHAR(255),
+		status VARCHAR(20) NOT NULL DEFAULT 'open',
+		acknowledged_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		acknowledged_at TIMESTAMPTZ,
+		resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		resolved_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Pattern exemptions (whitelisted Guardian detections per route)
+	CREATE TABLE IF NOT EXISTS pattern_exemptions (
+		id SERIAL PRIMARY KEY,
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+		route VARCHAR(255) NOT NULL,
+		method VARCHAR(10),
+		pattern_type VARCHAR(50),
+		pattern VARCHAR(255),
+		reason TEXT,
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Organization usage table (monthly quota counters)
+	CREATE TABLE IF NOT EXISTS organization_usage (
+		organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		period DATE NOT NULL,
+		metric VARCHAR(50) NOT NULL,
+		amount BIGINT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (organization_id, period, metric)
+	);
+
+	-- Email outbox (queued emails, delivered with retries by the outbox worker)
+	CREATE TABLE IF NOT EXISTS email_outbox (
+		id SERIAL PRIMARY KEY,
+		to_address VARCHAR(255) NOT NULL,
+		subject VARCHAR(500) NOT NULL,
+		html_body TEXT NOT NULL DEFAULT '',
+		text_body TEXT NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL DEFAULT 5,
+		next_attempt_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		last_error TEXT,
+		smtp_code INTEGER,
+		sent_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Metadata cache (schema metadata extracted from source databases)
+	CREATE TABLE IF NOT EXISTS metadata_cache (
+		cache_key VARCHAR(64) PRIMARY KEY,          -- SHA-256 of type/host/port/database/user
+		payload JSONB NOT NULL,
+		cached_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMPTZ NOT NULL
+	);
+
+	-- dbt seeds exported from small source lookup tables
+	CREATE TABLE IF NOT EXISTS migration_seeds (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		source_table VARCHAR(255) NOT NULL,         -- schema.table
+		seed_name VARCHAR(255) NOT NULL,
+		status VARCHAR(20) NOT NULL,                -- exported, failed
+		row_count INTEGER DEFAULT 0,
+		error TEXT,
+		exported_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (migration_id, source_table)
+	);
+
+	-- Downstream BI artifacts rendered into the project's exposures.yml
+	CREATE TABLE IF NOT EXISTS migration_exposures (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		name VARCHAR(128) NOT NULL,
+		label VARCHAR(255),
+		type VARCHAR(20) NOT NULL,                  -- dashboard, notebook, analysis, ml, application
+		tool VARCHAR(20) NOT NULL DEFAULT 'other',  -- powerbi, tableau, other
+		url TEXT,
+		description TEXT,
+		owner_name VARCHAR(255),
+		owner_email VARCHAR(255),
+		maturity VARCHAR(10),
+		depends_on TEXT[] NOT NULL DEFAULT '{}',    -- source tables (schema.table)
+		external_id VARCHAR(255),                   -- e.g. Power BI dataset ID
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (migration_id, name)
+	);
+
+	-- dbt Cloud project and job provisioned for a completed migration
+	CREATE TABLE IF NOT EXISTS dbt_cloud_jobs (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL UNIQUE REFERENCES migrations(id) ON DELETE CASCADE,
+		account_id BIGINT NOT NULL,
+		project_id BIGINT NOT NULL,
+		repository_id BIGINT NOT NULL,
+		environment_id BIGINT NOT NULL,
+		job_id BIGINT NOT NULL,
+		repository_url TEXT NOT NULL,
+		schedule_cron VARCHAR(100),                 -- NULL when the job only runs on demand
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Runtime-tunable server settings overriding environment defaults (CORS origins etc.)
+	CREATE TABLE IF NOT EXISTS server_settings (
+		key VARCHAR(100) PRIMARY KEY,
+		value JSONB NOT NULL,
+		updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- User-defined labels on migrations and connections; removed with the resource
+	CREATE TABLE IF NOT EXISTS migration_tags (
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		tag VARCHAR(50) NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (migration_id, tag)
+	);
+
+	CREATE TABLE IF NOT EXISTS connection_tags (
+		connection_id INTEGER NOT NULL REFERENCES database_connections(id) ON DELETE CASCADE,
+		tag VARCHAR(50) NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (connection_id, tag)
+	);
+
+	-- Generated dbt file paths, indexed from the AI service when a migration completes
+	CREATE TABLE IF NOT EXISTS migration_files (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		path TEXT NOT NULL,
+		file_type VARCHAR(50),
+		size BIGINT,
+		indexed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		search_vector tsvector GENERATED ALWAYS AS (
+			to_tsvector('simple', regexp_replace(path, '[^[:alnum:]]+', ' ', 'g'))
+		) STORED,
+		UNIQUE (migration_id, path)
+	);
+
+	-- Comments left by users on a migration
+	CREATE TABLE IF NOT EXISTS migration_comments (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', body)) STORED
+	);
+
+	-- Resource usage per migration phase, reported through the internal status API
+	CREATE TABLE IF NOT EXISTS migration_metrics (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		phase VARCHAR(50) NOT NULL,                  -- e.g. extracting_metadata, generating_dbt_project
+		duration_ms BIGINT NOT NULL DEFAULT 0,
+		prompt_tokens BIGINT NOT NULL DEFAULT 0,
+		completion_tokens BIGINT NOT NULL DEFAULT 0,
+		tables_processed INTEGER NOT NULL DEFAULT 0,
+		lines_generated INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (migration_id, phase)
+	);
+
+	-- Organization memberships. A user can belong to several organizations with a role
+	-- in each; users.organization_id is the one a login starts in.
+	CREATE TABLE IF NOT EXISTS organization_members (
+		organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role VARCHAR(50) NOT NULL DEFAULT 'member',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (organization_id, user_id)
+	);
+
+	-- Ownership transfers need both the current and the new owner to confirm by email.
+	-- Only hashes of the confirmation tokens are stored.
+	CREATE TABLE IF NOT EXISTS organization_ownership_transfers (
+		id SERIAL PRIMARY KEY,
+		organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		from_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		to_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		requested_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		from_token_hash VARCHAR(64) NOT NULL UNIQUE,
+		to_token_hash VARCHAR(64) NOT NULL UNIQUE,
+		from_confirmed_at TIMESTAMPTZ,
+		to_confirmed_at TIMESTAMPTZ,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, completed, cancelled, expired
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		completed_at TIMESTAMPTZ
+	);
+
+	-- External sign-in accounts (Google, GitHub, Azure AD) linked to users
+	CREATE TABLE IF NOT EXISTS user_identities (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		provider VARCHAR(20) NOT NULL, -- google, github, azuread
+		subject VARCHAR(255) NOT NULL,
+		email VARCHAR(255),
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		last_login_at TIMESTAMPTZ,
+		UNIQUE(provider, subject),
+		UNIQUE(user_id, provider)
+	);
+
+	-- Pending OAuth sign-ins; each state is consumed by its callback
+	CREATE TABLE IF NOT EXISTS oauth_states (
+		state_hash VARCHAR(64) PRIMARY KEY,
+		provider VARCHAR(20) NOT NULL,
+		code_verifier VARCHAR(128) NOT NULL,
+		link_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE, -- Set when a signed-in user links an account
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Devices users have signed in from, for new sign-in notifications
+	CREATE TABLE IF NOT EXISTS login_devices (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		fingerprint VARCHAR(64) NOT NULL,
+		user_agent TEXT,
+		ip_address VARCHAR(45),
+		country VARCHAR(2),
+		first_seen_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		last_seen_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		report_token_hash VARCHAR(64) UNIQUE, -- "This wasn't me" link from the notification
+		report_expires_at TIMESTAMPTZ,
+		reported_at TIMESTAMPTZ,
+		UNIQUE(user_id, fingerprint)
+	);
+
+	-- Support staff acting as a user. admin_email is kept if the admin is deleted.
+	CREATE TABLE IF NOT EXISTS impersonation_sessions (
+		id SERIAL PRIMARY KEY,
+		admin_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		admin_email VARCHAR(255) NOT NULL,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL,
+		reason TEXT NOT NULL,
+		ip_address VARCHAR(45),
+		user_agent TEXT,
+		started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMPTZ NOT NULL,
+		ended_at TIMESTAMPTZ,
+		action_count INTEGER NOT NULL DEFAULT 0,
+		last_action_at TIMESTAMPTZ
+	);
+
+	-- One row per start of a migration. The migration row mirrors its current run.
+	CREATE TABLE IF NOT EXISTS migration_runs (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		run_number INTEGER NOT NULL,
+		status VARCHAR(50) NOT NULL DEFAULT 'running',
+		progress INTEGER DEFAULT 0,
+		error TEXT,
+		started_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		config JSONB, -- Migration config as it was when the run started
+		artifacts_path TEXT, -- Project directory reported by the AI service
+		files_count INTEGER DEFAULT 0,
+		started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		completed_at TIMESTAMPTZ,
+		UNIQUE (migration_id, run_number)
+	);
+
+	-- Generated dbt files of a completed run, kept so runs can be compared after a re-run
+	CREATE TABLE IF NOT EXISTS migration_run_files (
+		id SERIAL PRIMARY KEY,
+		run_id INTEGER NOT NULL REFERENCES migration_runs(id) ON DELETE CASCADE,
+		path TEXT NOT NULL,
+		file_type VARCHAR(50),
+		size BIGINT,
+		checksum VARCHAR(64) NOT NULL, -- SHA-256 of the content
+		content TEXT, -- NULL for files too large to keep
+		UNIQUE (run_id, path)
+	);
+
+	-- Progress of each source table in a run, reported by the AI service
+	CREATE TABLE IF NOT EXISTS migration_tables (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		run_id INTEGER NOT NULL REFERENCES migration_runs(id) ON DELETE CASCADE,
+		table_name VARCHAR(255) NOT NULL, -- e.g. Sales.Customer
+		status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, extracting, generated, tested, failed
+		error TEXT,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (run_id, table_name)
+	);
+
+	-- Refresh tokens of signed-in sessions (SHA-256 of the token). Each refresh
+	-- revokes the token it used; presenting a revoked token revokes the whole session.
+	CREATE TABLE IF NOT EXISTS refresh_tokens (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL,
+		token_hash VARCHAR(64) UNIQUE NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		revoked_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- PII rules table (per-organization masking policy)
+	CREATE TABLE IF NOT EXISTS pii_rules (
+		id SERIAL PRIMARY KEY,
+		organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		name VARCHAR(255) NOT NULL,
+		rule_type VARCHAR(20) NOT NULL,
+		pattern TEXT NOT NULL,
+		mask_strategy VARCHAR(20) NOT NULL DEFAULT 'redact',
+		is_active BOOLEAN DEFAULT TRUE,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Column masking rules (per-organization), applied by generated staging models
+	CREATE TABLE IF NOT EXISTS column_masking_rules (
+		id SERIAL PRIMARY KEY,
+		organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		table_name VARCHAR(255) NOT NULL DEFAULT '',
+		column_name VARCHAR(128) NOT NULL,
+		action VARCHAR(10) NOT NULL,
+		updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Organization logos for branded emails and dbt docs, served publicly by organization
+	CREATE TABLE IF NOT EXISTS organization_logos (
+		organization_id INTEGER PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+		content_type VARCHAR(50) NOT NULL,
+		data BYTEA NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Custom domains organizations host the product under, once proven with a DNS TXT record
+	CREATE TABLE IF NOT EXISTS organization_domains (
+		id SERIAL PRIMARY KEY,
+		organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		domain VARCHAR(253) NOT NULL,
+		verification_token VARCHAR(64) NOT NULL,
+		verified_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(organization_id, domain)
+	);
+
+	-- Read-only links to a migration's summary and docs for people without an account
+	CREATE TABLE IF NOT EXISTS migration_share_links (
+		id SERIAL PRIMARY KEY,
+		migration_id INTEGER NOT NULL REFERENCES migrations(id) ON DELETE CASCADE,
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA-256 of the token in the link
+		label VARCHAR(100) NOT NULL DEFAULT '',
+		expires_at TIMESTAMPTZ NOT NULL,
+		revoked_at TIMESTAMPTZ,
+		view_count INTEGER NOT NULL DEFAULT 0,
+		last_viewed_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_migration_share_links_migration_id ON migration_share_links(migration_id);
+
+	-- Jira or ServiceNow instance an organization's migrations open change tickets in
+	CREATE TABLE IF NOT EXISTS organization_ticketing (
+		organization_id INTEGER PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+		provider VARCHAR(20) NOT NULL,              -- jira, servicenow
+		base_url TEXT NOT NULL,
+		username VARCHAR(255) NOT NULL,
+		api_token TEXT NOT NULL,                    -- Encrypted
+		project VARCHAR(100) NOT NULL,              -- Jira project key or ServiceNow table
+		issue_type VARCHAR(50),                     -- Jira only
+		events TEXT[] NOT NULL DEFAULT '{started,completed,failed}',
+		last_error TEXT,                            -- Last failed ticket update, cleared by the next success
+		last_synced_at TIMESTAMPTZ,
+		updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Failed models and tests parsed from a deployment's dbt output
+	CREATE TABLE IF NOT EXISTS deployment_errors (
+		id SERIAL PRIMARY KEY,
+		deployment_id INTEGER NOT NULL REFERENCES warehouse_deployments(id) ON DELETE CASCADE,
+		phase VARCHAR(10) NOT NULL,                 -- run, test
+		resource_type VARCHAR(20),                  -- model, test, seed, snapshot...
+		resource VARCHAR(255),
+		file_path TEXT,
+		error_class VARCHAR(30) NOT NULL,           -- database_error, compilation_error, test_failure...
+		category VARCHAR(30) NOT NULL,              -- missing_column, not_null_test...
+		line INTEGER,                               -- Line of the compiled SQL
+		message TEXT NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_deployment_errors_deployment_id ON deployment_errors(deployment_id);
+
+	-- Failed nodes of a dbt Cloud deployment and their retries (POST /deployments/:id/retry-failed)
+	CREATE TABLE IF NOT EXISTS deployment_retry_nodes (
+		deployment_id INTEGER NOT NULL REFERENCES warehouse_deployments(id) ON DELETE CASCADE,
+		unique_id VARCHAR(512) NOT NULL,            -- e.g. model.shop.stg_orders
+		status VARCHAR(20) NOT NULL DEFAULT 'failed', -- failed, passed
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_run_id BIGINT,                         -- dbt Cloud run of the latest attempt
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (deployment_id, unique_id)
+	);
+
+	-- Latest schema drift check of a migration's deployed warehouse tables
+	CREATE TABLE IF NOT EXISTS migration_drift (
+		migration_id INTEGER PRIMARY KEY REFERENCES migrations(id) ON DELETE CASCADE,
+		connection_id INTEGER REFERENCES database_connections(id) ON DELETE SET NULL,
+		status VARCHAR(20) NOT NULL,                -- ok, drift, error
+		findings JSONB NOT NULL DEFAULT '[]',
+		models_checked INTEGER NOT NULL DEFAULT 0,
+		error TEXT,
+		drift_since TIMESTAMPTZ,                    -- When the current findings were first seen
+		checked_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Rows sampled from a source table and compared with its model after a deployment
+	CREATE TABLE IF NOT EXISTS deployment_reconciliations (
+		id SERIAL PRIMARY KEY,
+		deployment_id INTEGER NOT NULL REFERENCES warehouse_deployments(id) ON DELETE CASCADE,
+		source_table VARCHAR(255) NOT NULL,         -- schema.table
+		target_relation VARCHAR(255),               -- schema.relation in the warehouse
+		rows_sampled INTEGER NOT NULL DEFAULT 0,
+		rows_missing INTEGER NOT NULL DEFAULT 0,    -- Sampled keys not found in the target
+		rows_mismatched INTEGER NOT NULL DEFAULT 0, -- Found with different column checksums
+		mismatch_pct NUMERIC(5,2),
+		error TEXT,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_deployment_reconciliations_deployment_id ON deployment_reconciliations(deployment_id);
+
+	-- Ad-hoc T-SQL snippets translated in the playground, kept as each user's history
+	CREATE TABLE IF NOT EXISTS sql_translations (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+		target_dialect VARCHAR(50) NOT NULL,        -- Warehouse type, e.g. snowflake
+		format VARCHAR(10) NOT NULL,                -- dbt, sql
+		source_sql TEXT NOT NULL,
+		translated_sql TEXT NOT NULL,
+		warnings TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_sql_translations_user_id ON sql_translations(user_id, created_at DESC);
+
+	-- Actions the chat assistant proposed through tool calls; nothing runs until the user
+	-- confirms one
+	CREATE TABLE IF NOT EXISTS chat_actions (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+		tool VARCHAR(50) NOT NULL,                  -- create_draft_migration
+		arguments JSONB NOT NULL,                   -- Resolved arguments the action runs with
+		summary TEXT NOT NULL,                      -- What confirming does, shown to the user
+		status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, confirmed, cancelled, failed
+		migration_id INTEGER REFERENCES migrations(id) ON DELETE SET NULL,
+		error TEXT,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMPTZ NOT NULL,
+		resolved_at TIMESTAMPTZ
+	);
+	CREATE INDEX IF NOT EXISTS idx_chat_actions_user_id ON chat_actions(user_id, created_at DESC);
+
+	-- AI tokens per migration phase and chat message, reported through the internal API.
+	-- A report sent again under the same key replaces the earlier figures.
+	CREATE TABLE IF NOT EXISTS ai_token_usage (
+		id SERIAL PRIMARY KEY,
+		usage_key VARCHAR(150) UNIQUE NOT NULL,     -- migration/<id>/<phase> or chat/<message id>
+		source VARCHAR(20) NOT NULL,                -- migration, chat
+		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+		user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		migration_id INTEGER REFERENCES migrations(id) ON DELETE SET NULL,
+		period DATE NOT NULL,                       -- Month the tokens count against
+		prompt_tokens BIGINT NOT NULL DEFAULT 0,
+		completion_tokens BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_ai_token_usage_org_period ON ai_token_usage(organization_id, period);
+
+	-- Every connection test with its latency, to show whether a source degrades over time
+	CREATE TABLE IF NOT EXISTS connection_test_history (
+		id BIGSERIAL PRIMARY KEY,
+		connection_id INTEGER NOT NULL REFERENCES database_connections(id) ON DELETE CASCADE,
+		success BOOLEAN NOT NULL,
+		latency_ms INTEGER NOT NULL,
+		message TEXT,
+		tested_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_connection_test_history_connection ON connection_test_history(connection_id, tested_at DESC);
+
 	-- Create indexes (indexes for organization_id columns created after ALTER TABLE)
 	CREATE INDEX IF NOT EXISTS idx_organizations_slug ON organizations(slug);
 	CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
@@ -231,14 +837,31 @@ func RunMigrations() error {
 	CREATE INDEX IF NOT EXISTS idx_database_connections_user_id ON database_connections(user_id);
 	CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
 	CREATE INDEX IF NOT EXISTS idx_migration_logs_migration_id ON migration_logs(migration_id);
-	CREATE INDEX IF NOT EXISTS idx_security_audit_logs_user_id ON security_audit_logs(user_id);
-	CREATE INDEX IF NOT EXISTS idx_security_audit_logs_org_id ON security_audit_logs(organization_id);
-	CREATE INDEX IF NOT EXISTS idx_security_audit_logs_event_type ON security_audit_logs(event_type);
-	CREATE INDEX IF NOT EXISTS idx_security_audit_logs_created_at ON security_audit_logs(created_at);
 	CREATE INDEX IF NOT EXISTS idx_rate_limits_identifier ON rate_limits(identifier);
 	CREATE INDEX IF NOT EXISTS idx_blocked_patterns_type ON blocked_patterns(pattern_type);
-	CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_token ON password_reset_tokens(token);
 	CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+	CREATE INDEX IF NOT EXISTS idx_ai_interactions_created_at ON ai_interactions(created_at);
+	CREATE INDEX IF NOT EXISTS idx_ai_interactions_user_id ON ai_interactions(user_id);
+	CREATE INDEX IF NOT EXISTS idx_ai_interactions_org_id ON ai_interactions(organization_id);
+	CREATE INDEX IF NOT EXISTS idx_pii_rules_org_id ON pii_rules(organization_id);
+	CREATE INDEX IF NOT EXISTS idx_column_masking_rules_org_id ON column_masking_rules(organization_id);
+	CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS idx_email_outbox_created_at ON email_outbox(created_at);
+	CREATE INDEX IF NOT EXISTS idx_security_alerts_status ON security_alerts(status, created_at);
+	CREATE INDEX IF NOT EXISTS idx_security_alerts_dedupe_key ON security_alerts(dedupe_key);
+	CREATE INDEX IF NOT EXISTS idx_pattern_exemptions_org_id ON pattern_exemptions(organization_id);
+	CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id);
+	CREATE INDEX IF NOT EXISTS idx_migration_files_search ON migration_files USING GIN (search_vector);
+	CREATE INDEX IF NOT EXISTS idx_migration_comments_migration_id ON migration_comments(migration_id);
+	CREATE INDEX IF NOT EXISTS idx_migration_comments_search ON migration_comments USING GIN (search_vector);
+	CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_ownership_transfers_pending ON organization_ownership_transfers(organization_id) WHERE status = 'pending';
+	-- Any organization can claim a domain, but only one can verify it
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_domains_verified ON organization_domains(domain) WHERE verified_at IS NOT NULL;
+	CREATE INDEX IF NOT EXISTS idx_oauth_states_expires_at ON oauth_states(expires_at);
+	CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_user_id ON impersonation_sessions(user_id);
+	CREATE INDEX IF NOT EXISTS idx_migration_runs_migration_id ON migration_runs(migration_id);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
 	`
 
 	_, err := DB.Exec(schema)
@@ -251,6 +874,14 @@ func RunMigrations() error {
 		log.Printf("Warning: Some ALTER TABLE migrations failed (may already exist): %v", err)
 	}
 
+	if err := partitionAuditLogs(); err != nil {
+		log.Printf("Warning: Audit log partitioning failed: %v", err)
+	}
+
+	if err := convertTimestampColumns(); err != nil {
+		log.Printf("Warning: Converting timestamps to timestamptz failed: %v", err)
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
@@ -266,7 +897,7 @@ func runAlterTableMigrations() error {
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL",
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(50) DEFAULT 'member'",
 		"ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN DEFAULT TRUE",
-		"ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ",
 
 		// Add organization_id to migrations table
 		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE",
@@ -289,11 +920,120 @@ func runAlterTableMigrations() error {
 		// Add extra_config for warehouse-specific settings (Snowflake, BigQuery, Databricks)
 		"ALTER TABLE database_connections ADD COLUMN IF NOT EXISTS extra_config JSONB",
 
+		// Per-organization privacy setting for grounding the AI chat in user resources; opt-in.
+		// Databases that added the column with DEFAULT TRUE get the new default too.
+		"ALTER TABLE organizations ADD COLUMN IF NOT EXISTS ai_context_enabled BOOLEAN DEFAULT FALSE",
+		"ALTER TABLE organizations ALTER COLUMN ai_context_enabled SET DEFAULT FALSE",
+
+		// Password policy (per organization) and password age tracking
+		"ALTER TABLE organizations ADD COLUMN IF NOT EXISTS password_policy JSONB",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP",
+		// Sessions signed in with an expired password stay restricted until it's changed
+		"ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS password_expired BOOLEAN NOT NULL DEFAULT FALSE",
+
+		// Organization defaults: warehouse, naming conventions, notification channel
+		"ALTER TABLE organizations ADD COLUMN IF NOT EXISTS settings JSONB",
+
+		// Service accounts are non-human users that own API keys and can't log in
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS account_type VARCHAR(20) DEFAULT 'human'",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS created_by INTEGER REFERENCES users(id) ON DELETE SET NULL",
+
+		// API key scopes, expiry, IP restrictions, and hashed storage
+		"ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] DEFAULT '{}'",
+		"ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ",
+		"ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_ips TEXT[] DEFAULT '{}'",
+		"ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS key_hash VARCHAR(64)",
+		"UPDATE api_keys SET key_hash = encode(sha256(key::bytea), 'hex') WHERE key_hash IS NULL AND key LIKE 'dm\\_%' AND key NOT LIKE '%...%'",
+		"UPDATE api_keys SET key = left(key, 8) || '...' || right(key, 4) WHERE key_hash IS NOT NULL AND key NOT LIKE '%...%'",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash)",
+
+		// Set by the admin CLI to clear the API server's in-memory login lockout
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS lockout_cleared_at TIMESTAMPTZ",
+		// Language for emails (en, da, es, pt, no, sv, de)
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(10) DEFAULT 'en'",
+		// IANA time zone that times in a user's emails are shown in; UTC when unset
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64)",
+		// Source table/view dependency graph captured when a migration starts
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS dependency_graph JSONB",
+		// dbt Cloud job runs are synced into warehouse_deployments alongside direct deployments
+		"ALTER TABLE warehouse_deployments ADD COLUMN IF NOT EXISTS dbt_cloud_run_id BIGINT",
+		"ALTER TABLE warehouse_deployments ADD COLUMN IF NOT EXISTS dbt_cloud_run_url TEXT",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouse_deployments_dbt_cloud_run_id ON warehouse_deployments(dbt_cloud_run_id)",
+		// Full-text search (GET /search). Names are split on punctuation so stg_customers
+		// matches "customers".
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', regexp_replace(coalesce(name, '') || ' ' || coalesce(source_database, '') || ' ' || coalesce(target_project, ''), '[^[:alnum:]]+', ' ', 'g'))) STORED",
+		"ALTER TABLE database_connections ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', regexp_replace(name || ' ' || host || ' ' || database_name, '[^[:alnum:]]+', ' ', 'g'))) STORED",
+		"CREATE INDEX IF NOT EXISTS idx_migrations_search ON migrations USING GIN (search_vector)",
+		"CREATE INDEX IF NOT EXISTS idx_database_connections_search ON database_connections USING GIN (search_vector)",
+		// Memberships replace users.organization_id: carry over existing members, and stamp
+		// resources created before they were scoped by organization
+		"INSERT INTO organization_members (organization_id, user_id, role) SELECT organization_id, id, COALESCE(role, 'member') FROM users WHERE organization_id IS NOT NULL ON CONFLICT DO NOTHING",
+		"UPDATE migrations m SET organization_id = u.organization_id FROM users u WHERE u.id = m.user_id AND m.organization_id IS NULL AND u.organization_id IS NOT NULL",
+		"UPDATE database_connections dc SET organization_id = u.organization_id FROM users u WHERE u.id = dc.user_id AND dc.organization_id IS NULL AND u.organization_id IS NOT NULL",
+		// Each organization has one owner; existing organizations are owned by their first admin
+		"ALTER TABLE organizations ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL",
+		"UPDATE organizations o SET owner_id = (SELECT m.user_id FROM organization_members m WHERE m.organization_id = o.id AND m.role = 'admin' ORDER BY m.created_at, m.user_id LIMIT 1) WHERE owner_id IS NULL",
+		// Migrations reference their source connection by ID; source_database keeps the name
+		// for display. Existing migrations are matched on the name they were created with.
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS connection_id INTEGER REFERENCES database_connections(id) ON DELETE SET NULL",
+		"UPDATE migrations m SET connection_id = (SELECT dc.id FROM database_connections dc WHERE dc.name = m.source_database AND dc.user_id = m.user_id AND COALESCE(dc.organization_id, 0) = COALESCE(m.organization_id, 0) ORDER BY dc.id LIMIT 1) WHERE m.connection_id IS NULL",
+		// Set when the user reports a sign-in from the notification email; cleared by a password reset
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ",
+		// Run history: migrations point at their latest run and logs belong to a run.
+		// Migrations started before runs were tracked get their last start as run 1.
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS current_run_id INTEGER REFERENCES migration_runs(id) ON DELETE SET NULL",
+		"ALTER TABLE migration_logs ADD COLUMN IF NOT EXISTS run_id INTEGER REFERENCES migration_runs(id) ON DELETE CASCADE",
+		"INSERT INTO migration_runs (migration_id, run_number, status, progress, error, started_by, config, started_at, completed_at) SELECT id, 1, status, progress, error, user_id, config, updated_at, completed_at FROM migrations WHERE status <> 'pending' AND current_run_id IS NULL ON CONFLICT (migration_id, run_number) DO NOTHING",
+		"UPDATE migrations m SET current_run_id = r.id FROM migration_runs r WHERE r.migration_id = m.id AND r.run_number = 1 AND m.current_run_id IS NULL",
+		// SHA-256 over the checksum manifest of a run's files (GET /migrations/:id/files ETag)
+		"ALTER TABLE migration_runs ADD COLUMN IF NOT EXISTS manifest_sha256 VARCHAR(64)",
+		// Reset tokens were stored in plain text; keep only their SHA-256 so outstanding
+		// links still work but a copy of the table can't reset passwords
+		"ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64) UNIQUE",
+		`DO $$ BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'password_reset_tokens' AND column_name = 'token') THEN
+				UPDATE password_reset_tokens SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex') WHERE token_hash IS NULL;
+				ALTER TABLE password_reset_tokens DROP COLUMN token;
+			END IF;
+		END $$`,
+		"ALTER TABLE password_reset_tokens ALTER COLUMN token_hash SET NOT NULL",
+		// Per-connection extraction limit; migrations started past it wait in status
+		// 'queued', oldest first, with the failed run they resume if any
+		"ALTER TABLE database_connections ADD COLUMN IF NOT EXISTS max_concurrent_migrations INTEGER NOT NULL DEFAULT 1",
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS queued_at TIMESTAMPTZ",
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS resume_run_id INTEGER REFERENCES migration_runs(id) ON DELETE SET NULL",
+		// Why a queued migration waits: connection_busy or outside_run_window
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS queue_reason VARCHAR(30)",
+		// The custom domain a social sign-in started on, which the provider redirects back to
+		"ALTER TABLE oauth_states ADD COLUMN IF NOT EXISTS redirect_base VARCHAR(300)",
+		// Data residency: the region (eu, us) whose AI service instance and artifact bucket
+		// an organization's migrations use, stamped on each migration when it starts
+		"ALTER TABLE organizations ADD COLUMN IF NOT EXISTS data_region VARCHAR(10)",
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS data_region VARCHAR(10)",
+		"ALTER TABLE ai_interactions ADD COLUMN IF NOT EXISTS data_region VARCHAR(10)",
+		// The Jira issue or ServiceNow record tracking a migration
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS ticket_key VARCHAR(100)",
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS ticket_id VARCHAR(100)",
+		"ALTER TABLE migrations ADD COLUMN IF NOT EXISTS ticket_url TEXT",
+		// Set once a failed deployment's dbt output has been parsed into deployment_errors
+		"ALTER TABLE warehouse_deployments ADD COLUMN IF NOT EXISTS errors_parsed_at TIMESTAMPTZ",
+		// Retry runs point at the deployment whose failed nodes they rebuild, which is
+		// completed once all of them pass
+		"ALTER TABLE warehouse_deployments ADD COLUMN IF NOT EXISTS retry_of INTEGER REFERENCES warehouse_deployments(id) ON DELETE CASCADE",
+		"ALTER TABLE warehouse_deployments ADD COLUMN IF NOT EXISTS retry_checked_at TIMESTAMPTZ",
+		"ALTER TABLE warehouse_deployments ADD COLUMN IF NOT EXISTS retries_passed_at TIMESTAMPTZ",
+		"CREATE INDEX IF NOT EXISTS idx_warehouse_deployments_retry_of ON warehouse_deployments(retry_of)",
+		// Set once a completed deployment's tables have been sampled against the source
+		"ALTER TABLE warehouse_deployments ADD COLUMN IF NOT EXISTS reconciled_at TIMESTAMPTZ",
+
 		// Create indexes for organization_id columns
 		"CREATE INDEX IF NOT EXISTS idx_users_organization_id ON users(organization_id)",
 		"CREATE INDEX IF NOT EXISTS idx_migrations_organization_id ON migrations(organization_id)",
 		"CREATE INDEX IF NOT EXISTS idx_database_connections_organization_id ON database_connections(organization_id)",
 		"CREATE INDEX IF NOT EXISTS idx_warehouse_deployments_migration_id ON warehouse_deployments(migration_id)",
+		"CREATE INDEX IF NOT EXISTS idx_migrations_connection_id ON migrations(connection_id)",
+		"CREATE INDEX IF NOT EXISTS idx_migration_logs_run_id ON migration_logs(run_id)",
+		"CREATE INDEX IF NOT EXISTS idx_migrations_connection_status ON migrations(connection_id, status)",
 	}
 
 	for _, stmt := range alterStatements {
@@ -328,7 +1068,7 @@ func RunRAGMigrations() error {
 		metadata JSONB,                             -- Additional context
 		migration_id INTEGER REFERENCES migrations(id) ON DELETE SET NULL,
 		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- SQL transformation patterns (successful transformations)
@@ -342,7 +1082,7 @@ func RunRAGMigrations() error {
 		metadata JSONB,                             -- Transformation context
 		migration_id INTEGER REFERENCES migrations(id) ON DELETE SET NULL,
 		organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- dbt best practices knowledge base
@@ -353,8 +1093,8 @@ func RunRAGMigrations() error {
 		content TEXT NOT NULL,                      -- The actual knowledge/best practice
 		embedding vector(1536),
 		source VARCHAR(255),                        -- 'dbt_docs', 'community', 'internal'
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- RAG query cache (for performance)
@@ -364,8 +1104,8 @@ func RunRAGMigrations() error {
 		query_text TEXT NOT NULL,
 		results JSONB NOT NULL,                     -- Cached results
 		hit_count INTEGER DEFAULT 1,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		expires_at TIMESTAMP NOT NULL
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMPTZ NOT NULL
 	);
 
 	-- Create vector similarity search indexes (IVFFlat for performance)
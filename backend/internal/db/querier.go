@@ -0,0 +1,29 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Querier is the subset of *sqlx.DB that handlers use. Handlers that take a Querier
+// in their constructor can be unit tested against go-sqlmock (wrapped with sqlx.NewDb)
+// instead of a live database.
+type Querier interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
+	Beginx() (*sqlx.Tx, error)
+}
+
+var _ Querier = (*sqlx.DB)(nil)
+
+// Default returns the package-level connection as a Querier.
+//
+// Deprecated: compatibility shim for code that still relies on the DB global;
+// pass a Querier into handler constructors instead.
+func Default() Querier {
+	return DB
+}
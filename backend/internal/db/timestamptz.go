@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+// convertTimestampColumns turns the TIMESTAMP (without time zone) columns of databases
+// created before the schema used TIMESTAMPTZ into TIMESTAMPTZ. The stored values were
+// written in the server's time zone, which is UTC in the postgres images we deploy, so
+// they are read as UTC. A partition key can't change type, so the created_at of an
+// already partitioned security_audit_logs stays TIMESTAMP; sessions run in UTC, which
+// keeps it consistent with the other columns.
+func convertTimestampColumns() error {
+	var columns []struct {
+		Table  string `db:"table_name"`
+		Column string `db:"column_name"`
+	}
+	err := DB.Select(&columns, `
+		SELECT c.relname AS table_name, a.attname AS column_name
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		LEFT JOIN pg_partitioned_table p ON p.partrelid = c.oid
+		WHERE c.relnamespace = current_schema()::regnamespace
+		AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+		AND a.attnum > 0 AND NOT a.attisdropped
+		AND a.atttypid = 'timestamp'::regtype
+		AND (p.partrelid IS NULL OR NOT a.attnum = ANY(p.partattrs::int2[]))
+		ORDER BY c.relname, a.attnum
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to find timestamp columns: %w", err)
+	}
+
+	for _, col := range columns {
+		table, column := pq.QuoteIdentifier(col.Table), pq.QuoteIdentifier(col.Column)
+		stmt := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE TIMESTAMPTZ USING %s AT TIME ZONE 'UTC'", table, column, column)
+		if _, err := DB.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to convert %s.%s: %w", col.Table, col.Column, err)
+		}
+	}
+	if len(columns) > 0 {
+		log.Printf("Converted %d timestamp columns to timestamptz", len(columns))
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+// Package dbtcloud provisions projects and jobs through the dbt Cloud Administrative API
+// and reads back job runs
+package dbtcloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the multi-tenant US instance; single-tenant and regional accounts
+// (emea.dbt.com, au.dbt.com) set DBT_CLOUD_API_URL
+const DefaultBaseURL = "https://cloud.getdbt.com"
+
+// Run statuses as returned by the API
+const (
+	RunQueued    = 1
+	RunStarting  = 2
+	RunRunning   = 3
+	RunSuccess   = 10
+	RunError     = 20
+	RunCancelled = 30
+)
+
+// Client calls the dbt Cloud API with a service token for one account
+type Client struct {
+	baseURL    string
+	accountID  int64
+	token      string
+	httpClient *http.Client
+}
+
+var client *Client
+
+// Init configures the client. The integration is disabled unless the account and token are set.
+func Init(baseURL string, accountID int64, token string) {
+	if accountID == 0 || token == "" {
+		client = nil
+		return
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	client = &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		accountID:  accountID,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetClient returns the configured client, or nil if dbt Cloud isn't configured
+func GetClient() *Client {
+	return client
+}
+
+// AccountID is the dbt Cloud account the client provisions into
+func (c *Client) AccountID() int64 {
+	return c.accountID
+}
+
+// Project is a dbt Cloud project
+type Project struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Repository is a git repository linked to a project
+type Repository struct {
+	ID        int64  `json:"id"`
+	RemoteURL string `json:"remote_url"`
+	DeployKey *struct {
+		PublicKey string `json:"public_key"`
+	} `json:"deploy_key,omitempty"`
+}
+
+// Environment is a dbt Cloud deployment environment
+type Environment struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Job is a dbt Cloud job
+type Job struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Run is a single job run
+type Run struct {
+	ID              int64   `json:"id"`
+	JobID           int64   `json:"job_definition_id"`
+	Status          int     `json:"status"`
+	StatusHumanized string  `json:"status_humanized"`
+	StatusMessage   *string `json:"status_message"`
+	GitSHA          *string `json:"git_sha"`
+	Href            string  `json:"href"`
+	CreatedAt       string  `json:"created_at"`
+	FinishedAt      *string `json:"finished_at"`
+	InProgress      bool    `json:"in_progress"`
+	IsComplete      bool    `json:"is_complete"`
+	IsSuccess       bool    `json:"is_success"`
+	IsError         bool    `json:"is_error"`
+	IsCancelled     bool    `json:"is_cancelled"`
+}
+
+// CreateProject creates an empty project
+func (c *Client) CreateProject(ctx context.Context, name string) (*Project, error) {
+	var project Project
+	err := c.do(ctx, http.MethodPost, c.v3("/projects/"), map[string]interface{}{
+		"name":       name,
+		"account_id": c.accountID,
+	}, &project)
+	return &project, err
+}
+
+// CreateRepository links a git remote to a project. With the "deploy_key" strategy dbt
+// Cloud generates a key pair; the returned public key must be added to the git host.
+func (c *Client) CreateRepository(ctx context.Context, projectID int64, remoteURL, cloneStrategy string, githubInstallationID int64) (*Repository, error) {
+	body := map[string]interface{}{
+		"account_id":         c.accountID,
+		"project_id":         projectID,
+		"remote_url":         remoteURL,
+		"git_clone_strategy": cloneStrategy,
+	}
+	if githubInstallationID != 0 {
+		body["github_installation_id"] = githubInstallationID
+	}
+
+	var repo Repository
+	if err := c.do(ctx, http.MethodPost, c.v3(fmt.Sprintf("/projects/%d/repositories/", projectID)), body, &repo); err != nil {
+		return nil, err
+	}
+
+	// A repository only takes effect once the project points at it
+	err := c.do(ctx, http.MethodPost, c.v3(fmt.Sprintf("/projects/%d/", projectID)), map[string]interface{}{
+		"id":            projectID,
+		"account_id":    c.accountID,
+		"repository_id": repo.ID,
+	}, nil)
+	return &repo, err
+}
+
+// CreateEnvironment creates a production deployment environment. credentialsID links
+// existing warehouse credentials; without them jobs fail until credentials are added in dbt Cloud.
+func (c *Client) CreateEnvironment(ctx context.Context, projectID int64, name, dbtVersion string, credentialsID int64) (*Environment, error) {
+	body := map[string]interface{}{
+		"account_id":        c.accountID,
+		"project_id":        projectID,
+		"name":              name,
+		"type":              "deployment",
+		"deployment_type":   "production",
+		"dbt_version":       dbtVersion,
+		"use_custom_branch": false,
+	}
+	if credentialsID != 0 {
+		body["credentials_id"] = credentialsID
+	}
+
+	var env Environment
+	err := c.do(ctx, http.MethodPost, c.v3(fmt.Sprintf("/projects/%d/environments/", projectID)), body, &env)
+	return &env, err
+}
+
+// CreateJob creates a job running steps in the environment. An empty cron creates an
+// unscheduled job that only runs when triggered.
+func (c *Client) CreateJob(ctx context.Context, projectID, environmentID int64, name string, steps []string, cron string) (*Job, error) {
+	schedule := map[string]interface{}{
+		"cron": "0 * * * *",
+		"date": map[string]interface{}{"type": "every_day"},
+		"time": map[string]interface{}{"type": "every_hour", "interval": 1},
+	}
+	if cron != "" {
+		schedule = map[string]interface{}{
+			"cron": cron,
+			"date": map[string]interface{}{"type": "custom_cron", "cron": cron},
+			"time": map[string]interface{}{"type": "every_hour", "interval": 1},
+		}
+	}
+
+	var job Job
+	err := c.do(ctx, http.MethodPost, c.v2("/jobs/"), map[string]interface{}{
+		"account_id":     c.accountID,
+		"project_id":     projectID,
+		"environment_id": environmentID,
+		"name":           name,
+		"execute_steps":  steps,
+		"triggers": map[string]interface{}{
+			"github_webhook":       false,
+			"git_provider_webhook": false,
+			"schedule":             cron != "",
+		},
+		"settings": map[string]interface{}{"threads": 4, "target_name": "default"},
+		"schedule": schedule,
+		"state":    1,
+	}, &job)
+	return &job, err
+}
+
+// TriggerRun queues a run of the job. Steps, when given, replace the job's commands for
+// this run only.
+func (c *Client) TriggerRun(ctx context.Context, jobID int64, cause string, steps ...string) (*Run, error) {
+	body := map[string]interface{}{"cause": cause}
+	if len(steps) > 0 {
+		body["steps_override"] = steps
+	}
+	var run Run
+	err := c.do(ctx, http.MethodPost, c.v2(fmt.Sprintf("/jobs/%d/run/", jobID)), body, &run)
+	return &run, err
+}
+
+// NodeResult is the outcome of one node in a run, from its run_results.json artifact
+type NodeResult struct {
+	UniqueID string `json:"unique_id"` // e.g. model.shop.stg_orders
+	Status   string `json:"status"`    // success, error, skipped, pass, fail, warn
+	Message  string `json:"message"`
+}
+
+// Passed reports whether the node built or its test passed
+func (r NodeResult) Passed() bool {
+	return r.Status == "success" || r.Status == "pass" || r.Status == "warn"
+}
+
+// RunResults returns the node results of a run's last step
+func (c *Client) RunResults(ctx context.Context, runID int64) ([]NodeResult, error) {
+	var artifact struct {
+		Results []NodeResult `json:"results"`
+	}
+	err := c.getArtifact(ctx, c.v2(fmt.Sprintf("/runs/%d/artifacts/run_results.json", runID)), &artifact)
+	return artifact.Results, err
+}
+
+// ListRuns returns the job's most recent runs, newest first
+func (c *Client) ListRuns(ctx context.Context, jobID int64, limit int) ([]Run, error) {
+	query := url.Values{
+		"job_definition_id": {fmt.Sprint(jobID)},
+		"order_by":          {"-id"},
+		"limit":             {fmt.Sprint(limit)},
+	}
+	runs := []Run{}
+	err := c.do(ctx, http.MethodGet, c.v2("/runs/?"+query.Encode()), nil, &runs)
+	return runs, err
+}
+
+func (c *Client) v2(path string) string {
+	return fmt.Sprintf("%s/api/v2/accounts/%d%s", c.baseURL, c.accountID, path)
+}
+
+func (c *Client) v3(path string) string {
+	return fmt.Sprintf("%s/api/v3/accounts/%d%s", c.baseURL, c.accountID, path)
+}
+
+// do sends a request and decodes the "data" field of the response envelope into out
+func (c *Client) do(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("dbt Cloud request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Status struct {
+			UserMessage string `json:"user_message"`
+		} `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to decode dbt Cloud response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if envelope.Status.UserMessage != "" {
+			return fmt.Errorf("dbt Cloud API error (status %d): %s", resp.StatusCode, envelope.Status.UserMessage)
+		}
+		return fmt.Errorf("dbt Cloud API error (status %d)", resp.StatusCode)
+	}
+
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// getArtifact downloads a run artifact, which unlike other responses isn't wrapped in
+// the data envelope
+func (c *Client) getArtifact(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("dbt Cloud request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("dbt Cloud API error (status %d)", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode dbt Cloud artifact: %w", err)
+	}
+	return nil
+}
+
+// DeploymentStatus maps a run status onto the deployment statuses used by
+// warehouse_deployments (pending, running, completed, failed)
+func (r Run) DeploymentStatus() string {
+	switch r.Status {
+	case RunSuccess:
+		return "completed"
+	case RunError, RunCancelled:
+		return "failed"
+	case RunStarting, RunRunning:
+		return "running"
+	default:
+		return "pending"
+	}
+}
@@ -0,0 +1,77 @@
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// WarehouseColumn is a column of a table or view in a deployed warehouse
+type WarehouseColumn struct {
+	Schema   string
+	Table    string
+	Column   string
+	DataType string
+}
+
+// SupportsTableColumns reports whether the deployed schema of a database type can be read
+// for drift detection
+func SupportsTableColumns(dbType string) bool {
+	switch dbType {
+	case "mssql", "sqlserver", "postgresql", "postgres":
+		return true
+	}
+	return false
+}
+
+// TableColumns lists the columns of the named tables and views, in every schema they
+// exist in. Names are matched case-insensitively.
+func TableColumns(ctx context.Context, params ConnectionParams, tables []string) ([]WarehouseColumn, error) {
+	if !SupportsTableColumns(params.DBType) {
+		return nil, fmt.Errorf("Unsupported database type: %s", params.DBType)
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	db, err := openSource(params)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = strings.ToLower(t)
+	}
+	// Model names are identifiers, so a comma-separated list is unambiguous
+	query := `
+		SELECT table_schema, table_name, column_name, data_type
+		FROM information_schema.columns
+		WHERE lower(table_name) = ANY(string_to_array($1, ','))
+		  AND table_schema NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY table_schema, table_name, ordinal_position`
+	if params.DBType == "mssql" || params.DBType == "sqlserver" {
+		query = `
+		SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, DATA_TYPE
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE LOWER(TABLE_NAME) IN (SELECT value FROM STRING_SPLIT(@p1, ','))
+		ORDER BY TABLE_SCHEMA, TABLE_NAME, ORDINAL_POSITION`
+	}
+
+	rows, err := db.QueryContext(ctx, isolatedQuery(params, query), strings.Join(names, ","))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []WarehouseColumn
+	for rows.Next() {
+		var c WarehouseColumn
+		if err := rows.Scan(&c.Schema, &c.Table, &c.Column, &c.DataType); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,21 @@
+package dbtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuickTestUnsupportedType(t *testing.T) {
+	if CanTest("snowflake") {
+		t.Fatal("CanTest(snowflake) = true")
+	}
+	result := QuickTest(ConnectionParams{DBType: "snowflake"}, time.Second)
+	if result.Success || result.Message != "Unsupported database type: snowflake" {
+		t.Errorf("QuickTest = %+v, want unsupported type failure", result)
+	}
+	for _, dbType := range []string{"mssql", "sqlserver", "synapse", "fabric", "postgresql", "postgres"} {
+		if !CanTest(dbType) {
+			t.Errorf("CanTest(%s) = false", dbType)
+		}
+	}
+}
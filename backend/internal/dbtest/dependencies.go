@@ -0,0 +1,155 @@
+package dbtest
+
+import (
+	"context"
+	"sort"
+)
+
+// Dependency edge kinds
+const (
+	DependencyForeignKey = "foreign_key" // From has a foreign key to To
+	DependencyView       = "view"        // View From selects from To
+)
+
+// DependencyNode is a table or view, identified as "schema.name"
+type DependencyNode struct {
+	ID     string `json:"id"`
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Type   string `json:"type"` // "table" or "view"
+}
+
+// DependencyEdge means From depends on To, so To's dbt model must be built first and
+// From's model should ref() it
+type DependencyEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Kind       string `json:"kind"`
+	Constraint string `json:"constraint,omitempty"` // Foreign key name
+}
+
+// DependencyGraph is the table and view dependency graph of a database
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+	// Order lists node IDs with every node after the nodes it depends on
+	Order []string `json:"order"`
+	// Cycles holds the nodes in, or downstream of, a dependency loop (e.g. mutual
+	// foreign keys). They come last in Order, alphabetically, as no order satisfies them.
+	Cycles []string `json:"cycles,omitempty"`
+}
+
+// ExtractDependencies extracts foreign keys and view dependencies and builds the graph
+func ExtractDependencies(ctx context.Context, params ConnectionParams) (DependencyGraph, MetadataResult) {
+	metadata := ExtractMetadataContext(ctx, params, ExtractOptions{
+		IncludeForeignKeys:      true,
+		IncludeViewDependencies: true,
+	})
+	if !metadata.Success {
+		return DependencyGraph{Nodes: []DependencyNode{}, Edges: []DependencyEdge{}, Order: []string{}}, metadata
+	}
+	return BuildDependencyGraph(metadata), metadata
+}
+
+// BuildDependencyGraph builds the graph from extracted tables, views, foreign keys and
+// view dependencies. Self-referencing foreign keys are kept as edges but don't affect
+// the order.
+func BuildDependencyGraph(metadata MetadataResult) DependencyGraph {
+	graph := DependencyGraph{
+		Nodes: []DependencyNode{},
+		Edges: []DependencyEdge{},
+		Order: []string{},
+	}
+
+	seen := map[string]bool{}
+	addNode := func(schema, name, nodeType string) string {
+		id := schema + "." + name
+		if !seen[id] {
+			seen[id] = true
+			graph.Nodes = append(graph.Nodes, DependencyNode{ID: id, Schema: schema, Name: name, Type: nodeType})
+		}
+		return id
+	}
+
+	for _, table := range metadata.Tables {
+		addNode(table.Schema, table.Name, "table")
+	}
+	for _, view := range metadata.Views {
+		addNode(view.Schema, view.Name, "view")
+	}
+
+	edgeSeen := map[DependencyEdge]bool{}
+	addEdge := func(edge DependencyEdge) {
+		if !edgeSeen[edge] {
+			edgeSeen[edge] = true
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+	for _, fk := range metadata.ForeignKeys {
+		addEdge(DependencyEdge{
+			From:       addNode(fk.Schema, fk.Table, "table"),
+			To:         addNode(fk.ReferencedSchema, fk.ReferencedTable, "table"),
+			Kind:       DependencyForeignKey,
+			Constraint: fk.Name,
+		})
+	}
+	for _, dep := range metadata.ViewDependencies {
+		addEdge(DependencyEdge{
+			From: addNode(dep.Schema, dep.View, "view"),
+			To:   addNode(dep.ReferencedSchema, dep.ReferencedName, dep.ReferencedType),
+			Kind: DependencyView,
+		})
+	}
+
+	graph.Order, graph.Cycles = dependencyOrder(graph)
+	return graph
+}
+
+// dependencyOrder sorts nodes topologically (Kahn's algorithm), taking ready nodes
+// alphabetically so the order is stable between scans
+func dependencyOrder(graph DependencyGraph) (order []string, cycles []string) {
+	pending := map[string]int{}         // Unplaced dependencies per node
+	dependents := map[string][]string{} // Nodes waiting on each node
+	for _, node := range graph.Nodes {
+		pending[node.ID] = 0
+	}
+
+	counted := map[[2]string]bool{}
+	for _, edge := range graph.Edges {
+		key := [2]string{edge.From, edge.To}
+		if edge.From == edge.To || counted[key] {
+			continue
+		}
+		counted[key] = true
+		pending[edge.From]++
+		dependents[edge.To] = append(dependents[edge.To], edge.From)
+	}
+
+	ready := []string{}
+	for id, n := range pending {
+		if n == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	order = []string{}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, dependent := range dependents[id] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+		delete(pending, id)
+	}
+
+	for id := range pending {
+		cycles = append(cycles, id)
+	}
+	sort.Strings(cycles)
+	return append(order, cycles...), cycles
+}
@@ -0,0 +1,60 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildDependencyGraphOrder(t *testing.T) {
+	metadata := MetadataResult{
+		Tables: []TableInfo{
+			{Schema: "Sales", Name: "OrderDetail"},
+			{Schema: "Sales", Name: "OrderHeader"},
+			{Schema: "Sales", Name: "Customer"},
+			{Schema: "HR", Name: "Employee"},
+		},
+		Views: []ViewInfo{{Schema: "Sales", Name: "vSummary"}},
+		ForeignKeys: []ForeignKeyInfo{
+			{Name: "FK_Detail_Header", Schema: "Sales", Table: "OrderDetail", ReferencedSchema: "Sales", ReferencedTable: "OrderHeader"},
+			{Name: "FK_Header_Customer", Schema: "Sales", Table: "OrderHeader", ReferencedSchema: "Sales", ReferencedTable: "Customer"},
+			{Name: "FK_Employee_Manager", Schema: "HR", Table: "Employee", ReferencedSchema: "HR", ReferencedTable: "Employee"},
+		},
+		ViewDependencies: []ViewDependency{
+			{Schema: "Sales", View: "vSummary", ReferencedSchema: "Sales", ReferencedName: "OrderDetail", ReferencedType: "table"},
+			{Schema: "Sales", View: "vSummary", ReferencedSchema: "Sales", ReferencedName: "Customer", ReferencedType: "table"},
+		},
+	}
+
+	graph := BuildDependencyGraph(metadata)
+
+	want := []string{"HR.Employee", "Sales.Customer", "Sales.OrderHeader", "Sales.OrderDetail", "Sales.vSummary"}
+	if !reflect.DeepEqual(graph.Order, want) {
+		t.Errorf("Order = %v, want %v", graph.Order, want)
+	}
+	if len(graph.Cycles) != 0 {
+		t.Errorf("Cycles = %v, want none (self-references are ignored)", graph.Cycles)
+	}
+	if len(graph.Edges) != 5 {
+		t.Errorf("got %d edges, want 5: %+v", len(graph.Edges), graph.Edges)
+	}
+}
+
+func TestBuildDependencyGraphCycle(t *testing.T) {
+	metadata := MetadataResult{
+		Tables: []TableInfo{{Schema: "dbo", Name: "A"}, {Schema: "dbo", Name: "B"}, {Schema: "dbo", Name: "C"}, {Schema: "dbo", Name: "D"}},
+		ForeignKeys: []ForeignKeyInfo{
+			{Name: "FK_A_B", Schema: "dbo", Table: "A", ReferencedSchema: "dbo", ReferencedTable: "B"},
+			{Name: "FK_B_A", Schema: "dbo", Table: "B", ReferencedSchema: "dbo", ReferencedTable: "A"},
+			{Name: "FK_C_A", Schema: "dbo", Table: "C", ReferencedSchema: "dbo", ReferencedTable: "A"},
+		},
+	}
+
+	graph := BuildDependencyGraph(metadata)
+
+	if want := []string{"dbo.A", "dbo.B", "dbo.C"}; !reflect.DeepEqual(graph.Cycles, want) {
+		t.Errorf("Cycles = %v, want %v", graph.Cycles, want)
+	}
+	if want := []string{"dbo.D", "dbo.A", "dbo.B", "dbo.C"}; !reflect.DeepEqual(graph.Order, want) {
+		t.Errorf("Order = %v, want %v", graph.Order, want)
+	}
+}
@@ -0,0 +1,79 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+)
+
+// fabricTokenScope is the Entra ID resource for Azure SQL, Synapse and Fabric SQL endpoints
+const fabricTokenScope = "https://database.windows.net/.default"
+
+// entraLoginURL is a variable so tests can point token requests at a local server
+var entraLoginURL = "https://login.microsoftonline.com"
+
+// openFabric connects to a Fabric warehouse or lakehouse SQL endpoint. Fabric doesn't
+// accept SQL logins, so the connection authenticates with a service principal token:
+// Username is the client ID and Password the client secret.
+func openFabric(params ConnectionParams, timeoutSeconds int) (*sql.DB, error) {
+	if params.TenantID == "" {
+		return nil, fmt.Errorf("Fabric connections need a tenant ID")
+	}
+
+	dsn := fmt.Sprintf(
+		"server=%s;port=%d;database=%s;encrypt=true;connection timeout=%d",
+		params.Host, params.Port, params.Database, timeoutSeconds,
+	)
+	httpClient := &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
+	connector, err := mssql.NewAccessTokenConnector(dsn, func() (string, error) {
+		return servicePrincipalToken(httpClient, params.TenantID, params.Username, params.Password)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// servicePrincipalToken gets an Azure SQL access token with the client credentials flow
+func servicePrincipalToken(httpClient *http.Client, tenantID, clientID, clientSecret string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"scope":         {fabricTokenScope},
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", entraLoginURL, url.PathEscape(tenantID))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Entra ID token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode Entra ID token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		// The description starts with an AADSTS code that identifies the problem
+		// (unknown tenant, wrong secret, expired secret)
+		description, _, _ := strings.Cut(result.ErrorDescription, "\r\n")
+		return "", fmt.Errorf("Entra ID token request failed (status %d): %s", resp.StatusCode, description)
+	}
+	return result.AccessToken, nil
+}
@@ -0,0 +1,43 @@
+package dbtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServicePrincipalToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tenant-1/oauth2/v2.0/token" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("scope") != fabricTokenScope || r.Form.Get("grant_type") != "client_credentials" {
+			t.Errorf("form = %v", r.Form)
+		}
+		if r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided.\r\nTrace ID: 1"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"token-1","expires_in":3599}`))
+	}))
+	defer server.Close()
+
+	previous := entraLoginURL
+	entraLoginURL = server.URL
+	defer func() { entraLoginURL = previous }()
+
+	token, err := servicePrincipalToken(server.Client(), "tenant-1", "client-1", "secret")
+	if err != nil || token != "token-1" {
+		t.Fatalf("token = %q, err = %v", token, err)
+	}
+
+	_, err = servicePrincipalToken(server.Client(), "tenant-1", "client-1", "wrong")
+	if err == nil || !strings.Contains(err.Error(), "AADSTS7000215") || strings.Contains(err.Error(), "Trace ID") {
+		t.Errorf("err = %v, want the AADSTS description without the trace", err)
+	}
+}
@@ -0,0 +1,58 @@
+package dbtest
+
+// Read isolation levels for queries against a source database
+const (
+	ReadIsolationDefault     = ""                 // The server's default, usually READ COMMITTED
+	ReadIsolationSnapshot    = "snapshot"         // Reads row versions; the database must allow snapshot isolation
+	ReadIsolationUncommitted = "read_uncommitted" // NOLOCK: takes no shared locks, may read uncommitted rows
+)
+
+// ReadIsolations are the isolation levels a connection can choose
+var ReadIsolations = []string{ReadIsolationSnapshot, ReadIsolationUncommitted}
+
+// SupportsReadIsolation reports whether queries to a database type can run at a chosen
+// isolation level. PostgreSQL readers don't block writers, so it has nothing to choose.
+func SupportsReadIsolation(dbType string) bool {
+	return dbType == "mssql" || dbType == "sqlserver"
+}
+
+// SupportsReadReplica reports whether connections to a database type can ask to be
+// routed to a read-only replica
+func SupportsReadReplica(dbType string) bool {
+	switch dbType {
+	case "mssql", "sqlserver", "postgresql", "postgres":
+		return true
+	}
+	return false
+}
+
+// isolatedQuery prefixes a query so it runs at the connection's read isolation. The level
+// is set in the same batch, so it holds whichever pooled connection runs the query.
+func isolatedQuery(params ConnectionParams, query string) string {
+	if !SupportsReadIsolation(params.DBType) {
+		return query
+	}
+	switch params.ReadIsolation {
+	case ReadIsolationSnapshot:
+		return "SET TRANSACTION ISOLATION LEVEL SNAPSHOT;\n" + query
+	case ReadIsolationUncommitted:
+		return "SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED;\n" + query
+	}
+	return query
+}
+
+// readReplicaDSN adds the settings that keep a read replica connection read-only: SQL
+// Server's read-only application intent, which availability group listeners route to a
+// readable secondary, or a read-only PostgreSQL session
+func readReplicaDSN(params ConnectionParams, dsn string) string {
+	if !params.ReadReplica {
+		return dsn
+	}
+	switch params.DBType {
+	case "mssql", "sqlserver":
+		return dsn + ";applicationintent=ReadOnly"
+	case "postgresql", "postgres":
+		return dsn + " default_transaction_read_only=on"
+	}
+	return dsn
+}
@@ -0,0 +1,35 @@
+package dbtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsolatedQuery(t *testing.T) {
+	const query = "SELECT 1"
+	for _, tc := range []struct {
+		params ConnectionParams
+		want   string
+	}{
+		{ConnectionParams{DBType: "mssql"}, query},
+		{ConnectionParams{DBType: "mssql", ReadIsolation: ReadIsolationSnapshot}, "SET TRANSACTION ISOLATION LEVEL SNAPSHOT;\n" + query},
+		{ConnectionParams{DBType: "sqlserver", ReadIsolation: ReadIsolationUncommitted}, "SET TRANSACTION ISOLATION LEVEL READ UNCOMMITTED;\n" + query},
+		{ConnectionParams{DBType: "postgres", ReadIsolation: ReadIsolationUncommitted}, query},
+	} {
+		if got := isolatedQuery(tc.params, query); got != tc.want {
+			t.Errorf("isolatedQuery(%+v) = %q, want %q", tc.params, got, tc.want)
+		}
+	}
+}
+
+func TestReadReplicaDSN(t *testing.T) {
+	if got := readReplicaDSN(ConnectionParams{DBType: "mssql"}, "server=db"); got != "server=db" {
+		t.Errorf("primary DSN = %q", got)
+	}
+	if got := readReplicaDSN(ConnectionParams{DBType: "mssql", ReadReplica: true}, "server=db"); !strings.HasSuffix(got, ";applicationintent=ReadOnly") {
+		t.Errorf("SQL Server replica DSN = %q", got)
+	}
+	if got := readReplicaDSN(ConnectionParams{DBType: "postgresql", ReadReplica: true}, "host=db"); !strings.HasSuffix(got, " default_transaction_read_only=on") {
+		t.Errorf("PostgreSQL replica DSN = %q", got)
+	}
+}
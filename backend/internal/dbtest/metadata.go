@@ -0,0 +1,606 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TableInfo holds information about a database table
+type TableInfo struct {
+	Name     string       `json:"name"`
+	Schema   string       `json:"schema"`
+	RowCount int64        `json:"row_count"`
+	Columns  []ColumnInfo `json:"columns,omitempty"`
+}
+
+// ColumnInfo holds information about a column
+type ColumnInfo struct {
+	Name       string `json:"name"`
+	DataType   string `json:"data_type"`
+	IsNullable bool   `json:"is_nullable"`
+	MaxLength  int    `json:"max_length,omitempty"`
+	// IsPrimaryKey is set on every column of the table's primary key
+	IsPrimaryKey bool `json:"is_primary_key,omitempty"`
+}
+
+// ViewInfo holds information about a database view
+type ViewInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// ForeignKeyInfo holds a foreign key constraint. Columns and ReferencedColumns are
+// in constraint order, so Columns[i] references ReferencedColumns[i].
+type ForeignKeyInfo struct {
+	Name              string   `json:"name"`
+	Schema            string   `json:"schema"`
+	Table             string   `json:"table"`
+	Columns           []string `json:"columns"`
+	ReferencedSchema  string   `json:"referenced_schema"`
+	ReferencedTable   string   `json:"referenced_table"`
+	ReferencedColumns []string `json:"referenced_columns"`
+}
+
+// ViewDependency records that a view selects from a table or another view
+type ViewDependency struct {
+	Schema           string `json:"schema"`
+	View             string `json:"view"`
+	ReferencedSchema string `json:"referenced_schema"`
+	ReferencedName   string `json:"referenced_name"`
+	ReferencedType   string `json:"referenced_type"` // "table" or "view"
+}
+
+// MetadataResult holds all extracted metadata from a database
+type MetadataResult struct {
+	Database    string           `json:"database"`
+	Tables      []TableInfo      `json:"tables"`
+	Views       []ViewInfo       `json:"views"`
+	ForeignKeys []ForeignKeyInfo `json:"foreign_keys,omitempty"`
+	// ViewDependencies lists the tables and views each view selects from
+	ViewDependencies []ViewDependency `json:"view_dependencies,omitempty"`
+	Success          bool             `json:"success"`
+	Partial          bool             `json:"partial,omitempty"`  // Some stages failed or were cut off
+	Warnings         []string         `json:"warnings,omitempty"` // Why the result is partial
+	Error            string           `json:"error,omitempty"`
+}
+
+// Extraction stages reported to progress callbacks
+const (
+	StageTables           = "tables"
+	StageViews            = "views"
+	StageForeignKeys      = "foreign_keys"
+	StageViewDependencies = "view_dependencies"
+	StageColumns          = "columns"
+)
+
+// MetadataProgress reports that Completed of Total items in Stage are done
+type MetadataProgress struct {
+	Stage     string
+	Completed int
+	Total     int
+}
+
+// ExtractOptions tunes ExtractMetadataContext
+type ExtractOptions struct {
+	Workers            int           // Concurrent queries (and pool connections); defaults to 4
+	Timeout            time.Duration // Overall deadline; defaults to 60s
+	IncludeColumns     bool          // Fetch columns for every table
+	IncludeForeignKeys bool
+	// IncludeViewDependencies reads which tables and views each view selects from
+	IncludeViewDependencies bool
+	// OnProgress is called as stages advance. Calls are serialized, but come from
+	// worker goroutines, so it must not block for long.
+	OnProgress func(MetadataProgress)
+}
+
+const (
+	defaultExtractWorkers = 4
+	maxExtractWorkers     = 16
+	defaultExtractTimeout = 60 * time.Second
+)
+
+// metadataQueries holds the catalog queries for one database dialect
+type metadataQueries struct {
+	tables           string
+	views            string
+	foreignKeys      string
+	viewDependencies string
+	columns          string // Parameters: schema, table
+	byCatalog        bool   // tables and views take the database name as their only parameter
+}
+
+var mssqlMetadataQueries = metadataQueries{
+	tables: `
+		SELECT
+			t.TABLE_SCHEMA,
+			t.TABLE_NAME,
+			ISNULL(p.rows, 0) as row_count
+		FROM INFORMATION_SCHEMA.TABLES t
+		LEFT JOIN sys.tables st ON st.name = t.TABLE_NAME
+		LEFT JOIN sys.partitions p ON st.object_id = p.object_id AND p.index_id IN (0, 1)
+		WHERE t.TABLE_TYPE = 'BASE TABLE'
+		AND t.TABLE_CATALOG = @p1
+		ORDER BY t.TABLE_SCHEMA, t.TABLE_NAME
+	`,
+	views: `
+		SELECT TABLE_SCHEMA, TABLE_NAME
+		FROM INFORMATION_SCHEMA.VIEWS
+		WHERE TABLE_CATALOG = @p1
+		ORDER BY TABLE_SCHEMA, TABLE_NAME
+	`,
+	foreignKeys: `
+		SELECT
+			fk.name,
+			SCHEMA_NAME(pt.schema_id),
+			pt.name,
+			pc.name,
+			SCHEMA_NAME(rt.schema_id),
+			rt.name,
+			rc.name
+		FROM sys.foreign_keys fk
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.tables pt ON pt.object_id = fk.parent_object_id
+		JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+		JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		ORDER BY SCHEMA_NAME(pt.schema_id), pt.name, fk.name, fkc.constraint_column_id
+	`,
+	// Only references resolved to a table or view in this database; cross-database
+	// and unresolved (deferred name resolution) references have no referenced_id
+	viewDependencies: `
+		SELECT DISTINCT
+			SCHEMA_NAME(v.schema_id),
+			v.name,
+			SCHEMA_NAME(o.schema_id),
+			o.name,
+			CASE o.type WHEN 'V' THEN 'view' ELSE 'table' END
+		FROM sys.sql_expression_dependencies d
+		JOIN sys.views v ON v.object_id = d.referencing_id
+		JOIN sys.objects o ON o.object_id = d.referenced_id AND o.type IN ('U', 'V')
+		WHERE o.object_id <> v.object_id
+		ORDER BY 1, 2, 3, 4
+	`,
+	columns: `
+		SELECT c.COLUMN_NAME, c.DATA_TYPE, c.IS_NULLABLE, ISNULL(c.CHARACTER_MAXIMUM_LENGTH, 0),
+			CASE WHEN EXISTS (
+				SELECT 1
+				FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+				JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+					ON k.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND k.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+				WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+				AND tc.TABLE_SCHEMA = c.TABLE_SCHEMA AND tc.TABLE_NAME = c.TABLE_NAME
+				AND k.COLUMN_NAME = c.COLUMN_NAME
+			) THEN 1 ELSE 0 END
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		WHERE c.TABLE_SCHEMA = @p1 AND c.TABLE_NAME = @p2
+		ORDER BY c.ORDINAL_POSITION
+	`,
+	byCatalog: true,
+}
+
+var postgresMetadataQueries = metadataQueries{
+	// Row counts are estimates
+	tables: `
+		SELECT
+			schemaname,
+			tablename,
+			COALESCE(n_live_tup, 0) as row_count
+		FROM pg_stat_user_tables
+		ORDER BY schemaname, tablename
+	`,
+	views: `
+		SELECT table_schema, table_name
+		FROM information_schema.views
+		WHERE table_schema NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY table_schema, table_name
+	`,
+	foreignKeys: `
+		SELECT
+			con.conname,
+			ns.nspname,
+			cl.relname,
+			a.attname,
+			rns.nspname,
+			rcl.relname,
+			ra.attname
+		FROM pg_constraint con
+		JOIN pg_class cl ON cl.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = cl.relnamespace
+		JOIN pg_class rcl ON rcl.oid = con.confrelid
+		JOIN pg_namespace rns ON rns.oid = rcl.relnamespace
+		CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+		WHERE con.contype = 'f'
+		AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY ns.nspname, cl.relname, con.conname, k.ord
+	`,
+	// A view's dependencies are recorded against its rewrite rule
+	viewDependencies: `
+		SELECT DISTINCT
+			vn.nspname,
+			v.relname,
+			rn.nspname,
+			r.relname,
+			CASE WHEN r.relkind IN ('v', 'm') THEN 'view' ELSE 'table' END
+		FROM pg_depend d
+		JOIN pg_rewrite rw ON rw.oid = d.objid
+		JOIN pg_class v ON v.oid = rw.ev_class
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		JOIN pg_class r ON r.oid = d.refobjid
+		JOIN pg_namespace rn ON rn.oid = r.relnamespace
+		WHERE d.classid = 'pg_rewrite'::regclass
+		AND d.refclassid = 'pg_class'::regclass
+		AND v.relkind IN ('v', 'm')
+		AND r.relkind IN ('r', 'p', 'f', 'v', 'm')
+		AND r.oid <> v.oid
+		AND vn.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY 1, 2, 3, 4
+	`,
+	columns: `
+		SELECT c.column_name, c.data_type, c.is_nullable, COALESCE(c.character_maximum_length, 0),
+			EXISTS (
+				SELECT 1
+				FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage k
+					ON k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name
+				WHERE tc.constraint_type = 'PRIMARY KEY'
+				AND tc.table_schema = c.table_schema AND tc.table_name = c.table_name
+				AND k.column_name = c.column_name
+			)
+		FROM information_schema.columns c
+		WHERE c.table_schema = $1 AND c.table_name = $2
+		ORDER BY c.ordinal_position
+	`,
+}
+
+// ExtractMetadata extracts tables and views from a database
+func ExtractMetadata(params ConnectionParams) MetadataResult {
+	return ExtractMetadataContext(context.Background(), params, ExtractOptions{})
+}
+
+// ExtractMetadataContext extracts metadata with the catalog-wide queries (tables, views,
+// foreign keys, view dependencies) run concurrently, then columns fetched per table by a bounded pool of workers.
+//
+// If a stage fails, or ctx is cancelled or times out, whatever was extracted so far
+// is returned with Partial set and the reasons in Warnings. Success is false only if
+// the database couldn't be reached or the table list couldn't be read.
+func ExtractMetadataContext(ctx context.Context, params ConnectionParams, opts ExtractOptions) MetadataResult {
+	result := MetadataResult{
+		Database: params.Database,
+		Tables:   []TableInfo{},
+		Views:    []ViewInfo{},
+		Success:  false,
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultExtractWorkers
+	}
+	if workers > maxExtractWorkers {
+		workers = maxExtractWorkers
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultExtractTimeout
+	}
+
+	var queries metadataQueries
+	switch params.DBType {
+	case "mssql", "sqlserver":
+		queries = mssqlMetadataQueries
+	case "postgresql", "postgres":
+		queries = postgresMetadataQueries
+	}
+
+	db, err := openSource(params)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer db.Close()
+
+	// One connection per worker, so the pool is the concurrency bound
+	db.SetMaxOpenConns(workers)
+	db.SetMaxIdleConns(workers)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Ping the database
+	if err := db.PingContext(ctx); err != nil {
+		result.Error = fmt.Sprintf("Connection failed: %v", err)
+		return result
+	}
+
+	x := &extraction{db: db, params: params, queries: queries, database: params.Database, onProgress: opts.OnProgress}
+
+	// Stage 1: the catalog-wide queries run side by side
+	var wg sync.WaitGroup
+	var tablesErr error
+	sem := make(chan struct{}, workers)
+	run := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn()
+		}()
+	}
+
+	run(func() { result.Tables, tablesErr = x.tables(ctx) })
+	run(func() {
+		views, err := x.views(ctx)
+		result.Views = views
+		if err != nil {
+			x.warn("views: %v", err)
+		}
+	})
+	if opts.IncludeForeignKeys {
+		run(func() {
+			fks, err := x.foreignKeys(ctx)
+			result.ForeignKeys = fks
+			if err != nil {
+				x.warn("foreign keys: %v", err)
+			}
+		})
+	}
+	if opts.IncludeViewDependencies {
+		run(func() {
+			deps, err := x.viewDependencies(ctx)
+			result.ViewDependencies = deps
+			if err != nil {
+				x.warn("view dependencies: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if tablesErr != nil && len(result.Tables) == 0 {
+		result.Error = fmt.Sprintf("Failed to list tables: %v", tablesErr)
+		return result
+	}
+	if tablesErr != nil {
+		x.warn("tables: %v", tablesErr)
+	}
+
+	// Stage 2: columns, one query per table, spread over the workers
+	if opts.IncludeColumns {
+		x.columns(ctx, result.Tables, workers)
+	}
+
+	result.Success = true
+	result.Warnings = x.warnings
+	result.Partial = len(x.warnings) > 0
+	return result
+}
+
+// openSource opens a connection pool to a source database
+func openSource(params ConnectionParams) (*sql.DB, error) {
+	// Build connection string based on database type
+	var dsn string
+	var driver string
+
+	switch params.DBType {
+	case "mssql", "sqlserver":
+		driver = "sqlserver"
+		if params.UseWindowsAuth {
+			// Windows Authentication (Trusted Connection)
+			dsn = fmt.Sprintf(
+				"server=%s;port=%d;database=%s;trusted_connection=yes;connection timeout=30",
+				params.Host, params.Port, params.Database,
+			)
+		} else {
+			// SQL Server Authentication
+			dsn = fmt.Sprintf(
+				"server=%s;port=%d;database=%s;user id=%s;password=%s;connection timeout=30",
+				params.Host, params.Port, params.Database, params.Username, params.Password,
+			)
+		}
+	case "postgresql", "postgres":
+		driver = "postgres"
+		dsn = fmt.Sprintf(
+			"host=%s port=%d dbname=%s user=%s password=%s sslmode=disable connect_timeout=30",
+			params.Host, params.Port, params.Database, params.Username, params.Password,
+		)
+	default:
+		return nil, fmt.Errorf("Unsupported database type: %s", params.DBType)
+	}
+
+	db, err := sql.Open(driver, readReplicaDSN(params, dsn))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create connection: %v", err)
+	}
+	return db, nil
+}
+
+// extraction is the state shared by the goroutines of one ExtractMetadataContext call
+type extraction struct {
+	db         *sql.DB
+	params     ConnectionParams
+	queries    metadataQueries
+	database   string
+	onProgress func(MetadataProgress)
+
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (x *extraction) warn(format string, args ...interface{}) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.warnings = append(x.warnings, fmt.Sprintf(format, args...))
+}
+
+func (x *extraction) progress(stage string, completed, total int) {
+	if x.onProgress == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.onProgress(MetadataProgress{Stage: stage, Completed: completed, Total: total})
+}
+
+// catalogArgs returns the parameters for the table and view queries
+func (x *extraction) catalogArgs() []interface{} {
+	if x.queries.byCatalog {
+		return []interface{}{x.database}
+	}
+	return nil
+}
+
+func (x *extraction) tables(ctx context.Context) ([]TableInfo, error) {
+	tables := []TableInfo{}
+	rows, err := x.db.QueryContext(ctx, isolatedQuery(x.params, x.queries.tables), x.catalogArgs()...)
+	if err != nil {
+		return tables, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table TableInfo
+		if err := rows.Scan(&table.Schema, &table.Name, &table.RowCount); err == nil {
+			tables = append(tables, table)
+		}
+	}
+	x.progress(StageTables, len(tables), len(tables))
+	return tables, rows.Err()
+}
+
+func (x *extraction) views(ctx context.Context) ([]ViewInfo, error) {
+	views := []ViewInfo{}
+	rows, err := x.db.QueryContext(ctx, isolatedQuery(x.params, x.queries.views), x.catalogArgs()...)
+	if err != nil {
+		return views, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var view ViewInfo
+		if err := rows.Scan(&view.Schema, &view.Name); err == nil {
+			views = append(views, view)
+		}
+	}
+	x.progress(StageViews, len(views), len(views))
+	return views, rows.Err()
+}
+
+// foreignKeys reads one row per constraint column and folds them into constraints
+func (x *extraction) foreignKeys(ctx context.Context) ([]ForeignKeyInfo, error) {
+	fks := []ForeignKeyInfo{}
+	rows, err := x.db.QueryContext(ctx, isolatedQuery(x.params, x.queries.foreignKeys))
+	if err != nil {
+		return fks, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fk ForeignKeyInfo
+		var column, refColumn string
+		if err := rows.Scan(&fk.Name, &fk.Schema, &fk.Table, &column, &fk.ReferencedSchema, &fk.ReferencedTable, &refColumn); err != nil {
+			continue
+		}
+		if n := len(fks); n > 0 && fks[n-1].Name == fk.Name && fks[n-1].Schema == fk.Schema && fks[n-1].Table == fk.Table {
+			fks[n-1].Columns = append(fks[n-1].Columns, column)
+			fks[n-1].ReferencedColumns = append(fks[n-1].ReferencedColumns, refColumn)
+			continue
+		}
+		fk.Columns = []string{column}
+		fk.ReferencedColumns = []string{refColumn}
+		fks = append(fks, fk)
+	}
+	x.progress(StageForeignKeys, len(fks), len(fks))
+	return fks, rows.Err()
+}
+
+func (x *extraction) viewDependencies(ctx context.Context) ([]ViewDependency, error) {
+	deps := []ViewDependency{}
+	rows, err := x.db.QueryContext(ctx, isolatedQuery(x.params, x.queries.viewDependencies))
+	if err != nil {
+		return deps, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dep ViewDependency
+		if err := rows.Scan(&dep.Schema, &dep.View, &dep.ReferencedSchema, &dep.ReferencedName, &dep.ReferencedType); err == nil {
+			deps = append(deps, dep)
+		}
+	}
+	x.progress(StageViewDependencies, len(deps), len(deps))
+	return deps, rows.Err()
+}
+
+// columns fills in tables[i].Columns. Tables aren't handed out once ctx is done;
+// those never reached are left without columns and reported in one warning.
+func (x *extraction) columns(ctx context.Context, tables []TableInfo, workers int) {
+	total := len(tables)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var completed int
+	var countMu sync.Mutex
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				columns, err := x.tableColumns(ctx, tables[i].Schema, tables[i].Name)
+				if err != nil {
+					if ctx.Err() == nil {
+						x.warn("columns for %s.%s: %v", tables[i].Schema, tables[i].Name, err)
+					}
+					continue
+				}
+				// Each worker writes only its own index, so no lock is needed
+				tables[i].Columns = columns
+
+				countMu.Lock()
+				completed++
+				done := completed
+				countMu.Unlock()
+				x.progress(StageColumns, done, total)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range tables {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		x.warn("columns: stopped after %d of %d tables: %v", completed, total, err)
+	}
+}
+
+func (x *extraction) tableColumns(ctx context.Context, schema, table string) ([]ColumnInfo, error) {
+	rows, err := x.db.QueryContext(ctx, isolatedQuery(x.params, x.queries.columns), schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := []ColumnInfo{}
+	for rows.Next() {
+		var column ColumnInfo
+		var nullable string
+		if err := rows.Scan(&column.Name, &column.DataType, &nullable, &column.MaxLength, &column.IsPrimaryKey); err != nil {
+			return nil, err
+		}
+		column.IsNullable = nullable == "YES"
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,257 @@
+package dbtest
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupBatchParams bounds the parameters of one target lookup, well below SQL Server's
+// limit of 2100
+const lookupBatchParams = 1000
+
+var decimalRegex = regexp.MustCompile(`^-?\d+\.\d+$`)
+
+// SampleTable is a table or view sampled for reconciliation. Keys and Columns are column
+// names as they are spelled in the database.
+type SampleTable struct {
+	Schema  string
+	Table   string
+	Keys    []string // Column(s) identifying a row
+	Columns []string // Columns whose values are checksummed
+}
+
+// SampledRow is a row's key and a checksum of its compared values. Both are normalized,
+// so rows read from different database types can be compared.
+type SampledRow struct {
+	Key       string
+	KeyValues []interface{}
+	Checksum  string
+}
+
+// SampleComparison counts how many sampled source rows differ in the target
+type SampleComparison struct {
+	Sampled    int
+	Missing    int // Keys not found in the target
+	Mismatched int // Found with a different checksum
+}
+
+// MismatchPct is the percentage of sampled rows that are missing or different
+func (c SampleComparison) MismatchPct() float64 {
+	if c.Sampled == 0 {
+		return 0
+	}
+	return float64(c.Missing+c.Mismatched) * 100 / float64(c.Sampled)
+}
+
+// SampleRows reads up to n randomly chosen rows of a table
+func SampleRows(ctx context.Context, params ConnectionParams, table SampleTable, n int) ([]SampledRow, error) {
+	if !SupportsTableColumns(params.DBType) {
+		return nil, fmt.Errorf("Unsupported database type: %s", params.DBType)
+	}
+	db, err := openSource(params)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	rows, err := db.QueryContext(ctx, isolatedQuery(params, sampleQuery(params.DBType, table, n)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanSampledRows(rows, len(table.Keys))
+}
+
+// LookupRows reads the rows of a table with the given keys, e.g. those sampled from the
+// source, and returns their checksums by normalized key
+func LookupRows(ctx context.Context, params ConnectionParams, table SampleTable, keys [][]interface{}) (map[string]string, error) {
+	if !SupportsTableColumns(params.DBType) {
+		return nil, fmt.Errorf("Unsupported database type: %s", params.DBType)
+	}
+	found := map[string]string{}
+	if len(keys) == 0 {
+		return found, nil
+	}
+	db, err := openSource(params)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	batch := lookupBatchParams / len(table.Keys)
+	for start := 0; start < len(keys); start += batch {
+		end := start + batch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		query, args := lookupQuery(params.DBType, table, keys[start:end])
+		rows, err := db.QueryContext(ctx, isolatedQuery(params, query), args...)
+		if err != nil {
+			return nil, err
+		}
+		sampled, err := scanSampledRows(rows, len(table.Keys))
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range sampled {
+			found[r.Key] = r.Checksum
+		}
+	}
+	return found, nil
+}
+
+// CompareSamples matches sampled source rows with the checksums found in the target
+func CompareSamples(source []SampledRow, target map[string]string) SampleComparison {
+	c := SampleComparison{Sampled: len(source)}
+	for _, r := range source {
+		checksum, ok := target[r.Key]
+		switch {
+		case !ok:
+			c.Missing++
+		case checksum != r.Checksum:
+			c.Mismatched++
+		}
+	}
+	return c
+}
+
+func sampleQuery(dbType string, table SampleTable, n int) string {
+	quote := quotePostgres
+	if dbType == "mssql" || dbType == "sqlserver" {
+		quote = quoteMSSQL
+	}
+	columns := selectList(table, quote)
+	relation := quote(table.Schema) + "." + quote(table.Table)
+	if dbType == "mssql" || dbType == "sqlserver" {
+		return fmt.Sprintf("SELECT TOP (%d) %s FROM %s ORDER BY NEWID()", n, columns, relation)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s ORDER BY random() LIMIT %d", columns, relation, n)
+}
+
+// lookupQuery selects the rows with the given keys: key IN (...) for a single key column,
+// and a disjunction of the key values for composite keys
+func lookupQuery(dbType string, table SampleTable, keys [][]interface{}) (string, []interface{}) {
+	quote := quotePostgres
+	placeholder := func(i int) string { return "$" + strconv.Itoa(i) }
+	if dbType == "mssql" || dbType == "sqlserver" {
+		quote = quoteMSSQL
+		placeholder = func(i int) string { return "@p" + strconv.Itoa(i) }
+	}
+
+	var where string
+	args := make([]interface{}, 0, len(keys)*len(table.Keys))
+	if len(table.Keys) == 1 {
+		placeholders := make([]string, len(keys))
+		for i, key := range keys {
+			args = append(args, key[0])
+			placeholders[i] = placeholder(len(args))
+		}
+		where = fmt.Sprintf("%s IN (%s)", quote(table.Keys[0]), strings.Join(placeholders, ", "))
+	} else {
+		conditions := make([]string, len(keys))
+		for i, key := range keys {
+			parts := make([]string, len(table.Keys))
+			for j, column := range table.Keys {
+				args = append(args, key[j])
+				parts[j] = quote(column) + " = " + placeholder(len(args))
+			}
+			conditions[i] = "(" + strings.Join(parts, " AND ") + ")"
+		}
+		where = strings.Join(conditions, " OR ")
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE %s",
+		selectList(table, quote), quote(table.Schema), quote(table.Table), where)
+	return query, args
+}
+
+func selectList(table SampleTable, quote func(string) string) string {
+	columns := make([]string, 0, len(table.Keys)+len(table.Columns))
+	for _, c := range table.Keys {
+		columns = append(columns, quote(c))
+	}
+	for _, c := range table.Columns {
+		columns = append(columns, quote(c))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// scanSampledRows reads rows of key columns followed by compared columns
+func scanSampledRows(rows *sql.Rows, keyCount int) ([]SampledRow, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var sampled []SampledRow
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		sampled = append(sampled, newSampledRow(values, keyCount))
+	}
+	return sampled, rows.Err()
+}
+
+func newSampledRow(values []interface{}, keyCount int) SampledRow {
+	normalized := make([]string, len(values))
+	for i, v := range values {
+		normalized[i] = normalizeValue(v)
+	}
+	// Drivers return decimals as bytes, which would be sent back as binary parameters
+	keys := make([]interface{}, keyCount)
+	for i, v := range values[:keyCount] {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		keys[i] = v
+	}
+	sum := sha256.Sum256([]byte(strings.Join(normalized[keyCount:], "\x1f")))
+	return SampledRow{
+		Key:       strings.Join(normalized[:keyCount], "\x1f"),
+		KeyValues: keys,
+		Checksum:  hex.EncodeToString(sum[:16]),
+	}
+}
+
+// normalizeValue formats a value the same way whichever driver read it: times in UTC,
+// decimals without trailing zeros and fixed-width strings without their padding
+func normalizeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "\x00"
+	case []byte:
+		return normalizeString(string(v))
+	case string:
+		return normalizeString(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func normalizeString(s string) string {
+	s = strings.TrimRight(s, " ")
+	if decimalRegex.MatchString(s) {
+		s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
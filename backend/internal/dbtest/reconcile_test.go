@@ -0,0 +1,66 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLookupQuery(t *testing.T) {
+	single := SampleTable{Schema: "analytics", Table: "stg_orders", Keys: []string{"order_id"}, Columns: []string{"amount"}}
+	query, args := lookupQuery("postgres", single, [][]interface{}{{int64(1)}, {int64(2)}})
+	if want := `SELECT "order_id", "amount" FROM "analytics"."stg_orders" WHERE "order_id" IN ($1, $2)`; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{int64(1), int64(2)}) {
+		t.Errorf("args = %v", args)
+	}
+
+	composite := SampleTable{Schema: "dbo", Table: "OrderLines", Keys: []string{"OrderID", "LineNo"}}
+	query, args = lookupQuery("mssql", composite, [][]interface{}{{int64(1), int64(1)}, {int64(1), int64(2)}})
+	if want := `SELECT [OrderID], [LineNo] FROM [dbo].[OrderLines] WHERE ([OrderID] = @p1 AND [LineNo] = @p2) OR ([OrderID] = @p3 AND [LineNo] = @p4)`; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if len(args) != 4 {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestSampleQuery(t *testing.T) {
+	table := SampleTable{Schema: "Sales", Table: "Customer", Keys: []string{"CustomerID"}, Columns: []string{"Name"}}
+	if got, want := sampleQuery("mssql", table, 500), "SELECT TOP (500) [CustomerID], [Name] FROM [Sales].[Customer] ORDER BY NEWID()"; got != want {
+		t.Errorf("mssql = %q, want %q", got, want)
+	}
+	if got, want := sampleQuery("postgres", table, 500), `SELECT "CustomerID", "Name" FROM "Sales"."Customer" ORDER BY random() LIMIT 500`; got != want {
+		t.Errorf("postgres = %q, want %q", got, want)
+	}
+}
+
+func TestSampledRowsMatchAcrossDrivers(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	// SQL Server pads char columns and keeps the declared decimal scale
+	source := newSampledRow([]interface{}{int64(7), []byte("12.50"), "ACME      ", at, nil}, 1)
+	target := newSampledRow([]interface{}{"7", []byte("12.5"), "ACME", at.In(time.FixedZone("CEST", 7200)), nil}, 1)
+	if source.Key != target.Key || source.Checksum != target.Checksum {
+		t.Errorf("rows differ: %+v and %+v", source, target)
+	}
+	if changed := newSampledRow([]interface{}{int64(7), []byte("12.51"), "ACME", at, nil}, 1); changed.Checksum == source.Checksum {
+		t.Error("a changed value has the same checksum")
+	}
+}
+
+func TestCompareSamples(t *testing.T) {
+	source := []SampledRow{
+		{Key: "1", Checksum: "a"},
+		{Key: "2", Checksum: "b"},
+		{Key: "3", Checksum: "c"},
+		{Key: "4", Checksum: "d"},
+	}
+	got := CompareSamples(source, map[string]string{"1": "a", "2": "x", "4": "d"})
+	if want := (SampleComparison{Sampled: 4, Missing: 1, Mismatched: 1}); got != want {
+		t.Errorf("CompareSamples = %+v, want %+v", got, want)
+	}
+	if pct := got.MismatchPct(); pct != 50 {
+		t.Errorf("MismatchPct = %v, want 50", pct)
+	}
+}
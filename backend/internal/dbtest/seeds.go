@@ -0,0 +1,186 @@
+package dbtest
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+)
+
+// ErrTooManyRows is returned by ExportTableCSV when a table has grown past the row limit
+var ErrTooManyRows = errors.New("table has more rows than the seed limit")
+
+// SeedCandidate is a small table that looks like static lookup data
+type SeedCandidate struct {
+	Schema   string   `json:"schema"`
+	Name     string   `json:"name"`
+	RowCount int64    `json:"row_count"`
+	Reasons  []string `json:"reasons"`
+}
+
+// lookupNameRegex matches names like OrderStatus, ref_country or lkp_currency
+var lookupNameRegex = regexp.MustCompile(`(?i)(^(lkp|lu|ref|lookup)_|(type|status|statuses|category|categories|code|codes|lookup|territory|territories|country|countries|currency|currencies|region|regions|unit|units|reason|reasons)$)`)
+
+// FindSeedCandidates returns tables with at most maxRows rows that are referenced by
+// foreign keys or are named like lookup tables. metadata should include foreign keys.
+func FindSeedCandidates(metadata MetadataResult, maxRows int64) []SeedCandidate {
+	referencedBy := map[string]int{}
+	for _, fk := range metadata.ForeignKeys {
+		if fk.Schema != fk.ReferencedSchema || fk.Table != fk.ReferencedTable {
+			referencedBy[fk.ReferencedSchema+"."+fk.ReferencedTable]++
+		}
+	}
+
+	candidates := []SeedCandidate{}
+	for _, table := range metadata.Tables {
+		if table.RowCount <= 0 || table.RowCount > maxRows {
+			continue
+		}
+
+		var reasons []string
+		if n := referencedBy[table.Schema+"."+table.Name]; n > 0 {
+			reasons = append(reasons, fmt.Sprintf("referenced by %d foreign key(s)", n))
+		}
+		if lookupNameRegex.MatchString(table.Name) {
+			reasons = append(reasons, "named like a lookup table")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		candidates = append(candidates, SeedCandidate{
+			Schema:   table.Schema,
+			Name:     table.Name,
+			RowCount: table.RowCount,
+			Reasons:  reasons,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Schema != candidates[j].Schema {
+			return candidates[i].Schema < candidates[j].Schema
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates
+}
+
+// ExportTableCSV streams a table to w as CSV, header row first, and returns the number
+// of data rows written. It reads at most maxRows+1 rows; if the table has more than
+// maxRows it stops with ErrTooManyRows rather than writing a truncated seed, so callers
+// should discard w's output on any error.
+func ExportTableCSV(ctx context.Context, params ConnectionParams, schema, table string, maxRows int, w io.Writer) (int, error) {
+	var query string
+	switch params.DBType {
+	case "mssql", "sqlserver":
+		query = fmt.Sprintf("SELECT TOP (@p1) * FROM %s.%s", quoteMSSQL(schema), quoteMSSQL(table))
+	case "postgresql", "postgres":
+		query = fmt.Sprintf("SELECT * FROM %s.%s LIMIT $1", quotePostgres(schema), quotePostgres(table))
+	default:
+		return 0, fmt.Errorf("Unsupported database type: %s", params.DBType)
+	}
+
+	db, err := openSource(params)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, isolatedQuery(params, query), maxRows+1)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name()
+	}
+	if err := writer.Write(header); err != nil {
+		return 0, err
+	}
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	count := 0
+	for rows.Next() {
+		if count == maxRows {
+			return count, ErrTooManyRows
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return count, err
+		}
+		for i, value := range values {
+			record[i] = csvValue(value, columns[i].DatabaseTypeName())
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// csvValue formats a scanned value the way dbt seeds expect: NULL as an empty field,
+// timestamps without a zone suffix, binary as hex
+func csvValue(value interface{}, dbType string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		switch strings.ToUpper(dbType) {
+		case "UNIQUEIDENTIFIER":
+			var id mssql.UniqueIdentifier
+			if err := id.Scan(v); err == nil {
+				return id.String()
+			}
+		case "BINARY", "VARBINARY", "IMAGE", "BYTEA":
+			return "0x" + hex.EncodeToString(v)
+		}
+		return string(v)
+	case time.Time:
+		if strings.ToUpper(dbType) == "DATE" {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04:05.999999")
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func quoteMSSQL(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func quotePostgres(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
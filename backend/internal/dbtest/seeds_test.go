@@ -0,0 +1,34 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindSeedCandidates(t *testing.T) {
+	metadata := MetadataResult{
+		Tables: []TableInfo{
+			{Schema: "Sales", Name: "SalesTerritory", RowCount: 10}, // Referenced and lookup-named
+			{Schema: "Sales", Name: "Customer", RowCount: 800},      // Referenced, but too big
+			{Schema: "dbo", Name: "OrderStatus", RowCount: 5},       // Lookup-named
+			{Schema: "dbo", Name: "AuditLog", RowCount: 3},          // Small, but nothing suggests a lookup
+			{Schema: "dbo", Name: "CurrencyCodes", RowCount: 0},     // Empty
+			{Schema: "HR", Name: "Employee", RowCount: 12},          // Only references itself
+		},
+		ForeignKeys: []ForeignKeyInfo{
+			{Schema: "Sales", Table: "Customer", ReferencedSchema: "Sales", ReferencedTable: "SalesTerritory"},
+			{Schema: "Sales", Table: "SalesOrderHeader", ReferencedSchema: "Sales", ReferencedTable: "SalesTerritory"},
+			{Schema: "Sales", Table: "SalesOrderHeader", ReferencedSchema: "Sales", ReferencedTable: "Customer"},
+			{Schema: "HR", Table: "Employee", ReferencedSchema: "HR", ReferencedTable: "Employee"},
+		},
+	}
+
+	got := FindSeedCandidates(metadata, 100)
+	want := []SeedCandidate{
+		{Schema: "Sales", Name: "SalesTerritory", RowCount: 10, Reasons: []string{"referenced by 2 foreign key(s)", "named like a lookup table"}},
+		{Schema: "dbo", Name: "OrderStatus", RowCount: 5, Reasons: []string{"named like a lookup table"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindSeedCandidates() = %+v, want %+v", got, want)
+	}
+}
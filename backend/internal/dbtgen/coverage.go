@@ -0,0 +1,133 @@
+package dbtgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+// accepted_values tests go on columns with at most this many distinct values
+const (
+	DefaultAcceptedValuesMax = 10
+	maxAcceptedValuesMax     = 100
+)
+
+// NormalizeTestCoverage trims the table names of a coverage config's overrides
+func NormalizeTestCoverage(cfg *models.TestCoverageConfig) {
+	if cfg == nil {
+		return
+	}
+	for i := range cfg.Tables {
+		cfg.Tables[i].Table = strings.TrimSpace(cfg.Tables[i].Table)
+	}
+}
+
+// ValidateTestCoverage checks a normalized coverage config. If tables is non-empty,
+// every override must be for one of the selected tables.
+func ValidateTestCoverage(cfg *models.TestCoverageConfig, tables []string) *validation.ValidationResult {
+	result := validation.NewValidationResult()
+	if cfg == nil {
+		return result
+	}
+
+	validateAcceptedValues(result, "test_coverage.accepted_values", cfg.AcceptedValues)
+
+	selected := map[string]bool{}
+	for _, table := range tables {
+		selected[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+	seen := map[string]bool{}
+
+	for i, t := range cfg.Tables {
+		field := fmt.Sprintf("test_coverage.tables[%d]", i)
+
+		key := strings.ToLower(t.Table)
+		switch {
+		case !tableNameRegex.MatchString(t.Table):
+			result.AddError(field+".table", "must be a table name, optionally schema-qualified")
+		case len(selected) > 0 && !selected[key]:
+			result.AddError(field+".table", fmt.Sprintf("%s is not one of the selected tables", t.Table))
+		case seen[key]:
+			result.AddError(field+".table", fmt.Sprintf("%s is listed more than once", t.Table))
+		}
+		seen[key] = true
+
+		if t.NotNull == nil && t.Unique == nil && t.Relationships == nil && t.AcceptedValues == nil {
+			result.AddError(field, "must set at least one test")
+		}
+		validateAcceptedValues(result, field+".accepted_values", t.AcceptedValues)
+	}
+
+	return result
+}
+
+func validateAcceptedValues(result *validation.ValidationResult, field string, av *models.AcceptedValuesCoverage) {
+	if av != nil && (av.MaxDistinct < 0 || av.MaxDistinct > maxAcceptedValuesMax) {
+		result.AddError(field+".max_distinct", fmt.Sprintf("must be between 1 and %d, or 0 for %d", maxAcceptedValuesMax, DefaultAcceptedValuesMax))
+	}
+}
+
+// ResolveTestCoverage fills in every field of a coverage config: the defaults from the
+// standard set, each table's override from the defaults. A nil config resolves to the
+// standard set.
+func ResolveTestCoverage(cfg *models.TestCoverageConfig) models.TestCoverageConfig {
+	standard := models.TestCoverage{
+		NotNull:        boolPtr(true),
+		Unique:         boolPtr(true),
+		Relationships:  boolPtr(true),
+		AcceptedValues: &models.AcceptedValuesCoverage{MaxDistinct: DefaultAcceptedValuesMax},
+	}
+	if cfg == nil {
+		return models.TestCoverageConfig{TestCoverage: standard}
+	}
+
+	resolved := models.TestCoverageConfig{TestCoverage: inheritCoverage(cfg.TestCoverage, standard)}
+	for _, t := range cfg.Tables {
+		resolved.Tables = append(resolved.Tables, models.TableTestCoverage{
+			Table:        t.Table,
+			TestCoverage: inheritCoverage(t.TestCoverage, resolved.TestCoverage),
+		})
+	}
+	return resolved
+}
+
+// inheritCoverage fills the unset fields of coverage from parent, which has every
+// field set
+func inheritCoverage(coverage, parent models.TestCoverage) models.TestCoverage {
+	if coverage.NotNull == nil {
+		coverage.NotNull = parent.NotNull
+	}
+	if coverage.Unique == nil {
+		coverage.Unique = parent.Unique
+	}
+	if coverage.Relationships == nil {
+		coverage.Relationships = parent.Relationships
+	}
+	if coverage.AcceptedValues == nil {
+		coverage.AcceptedValues = parent.AcceptedValues
+	} else if coverage.AcceptedValues.MaxDistinct == 0 {
+		av := *coverage.AcceptedValues
+		av.MaxDistinct = DefaultAcceptedValuesMax
+		coverage.AcceptedValues = &av
+	}
+	return coverage
+}
+
+// TableCoverage is the tests a resolved coverage config generates for a table: its
+// override, matched on schema.table or the bare table name, or the defaults
+func TableCoverage(resolved models.TestCoverageConfig, table string) models.TestCoverage {
+	bare := table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		bare = table[i+1:]
+	}
+	for _, override := range resolved.Tables {
+		if strings.EqualFold(override.Table, table) || strings.EqualFold(override.Table, bare) {
+			return override.TestCoverage
+		}
+	}
+	return resolved.TestCoverage
+}
+
+func boolPtr(b bool) *bool { return &b }
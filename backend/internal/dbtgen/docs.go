@@ -0,0 +1,69 @@
+package dbtgen
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// DefaultBannerColor is the landing page banner color of organizations without branding
+const DefaultBannerColor = "#667eea"
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// DocsOverview describes the landing page of a migration's dbt docs site
+type DocsOverview struct {
+	MigrationName  string
+	SourceDatabase string
+	TablesCount    int
+	ViewsCount     int
+
+	// Organization branding; colors that aren't #rrggbb are ignored
+	OrganizationName string
+	LogoURL          string
+	PrimaryColor     string
+	AccentColor      string
+}
+
+// OverviewDocs renders the __overview__ docs block dbt shows on the docs site's home
+// page, with a banner in the organization's logo and colors
+func OverviewDocs(o DocsOverview) string {
+	from, to := DefaultBannerColor, "#764ba2"
+	if hexColorRegex.MatchString(o.PrimaryColor) {
+		from, to = o.PrimaryColor, o.PrimaryColor
+		if hexColorRegex.MatchString(o.AccentColor) {
+			to = o.AccentColor
+		}
+	}
+
+	title := docsText(o.MigrationName)
+	if o.OrganizationName != "" {
+		title = docsText(o.OrganizationName) + " · " + title
+	}
+	header := fmt.Sprintf(`<h1 style="color: white; margin: 0;">%s</h1>`, title)
+	if o.LogoURL != "" {
+		header = fmt.Sprintf(`<img src="%s" alt="%s" style="max-height: 48px; max-width: 240px;"><h2 style="color: white; margin: 12px 0 0;">%s</h2>`,
+			docsText(o.LogoURL), docsText(o.OrganizationName), docsText(o.MigrationName))
+	}
+
+	var b strings.Builder
+	b.WriteString("{% docs __overview__ %}\n")
+	fmt.Fprintf(&b, `<div style="background: linear-gradient(135deg, %s 0%%, %s 100%%); padding: 24px; border-radius: 8px;">%s</div>`, from, to, header)
+	b.WriteString("\n\n")
+	if o.SourceDatabase != "" {
+		fmt.Fprintf(&b, "This project was migrated from **%s**: %d tables and %d views.\n\n",
+			docsText(o.SourceDatabase), o.TablesCount, o.ViewsCount)
+	} else {
+		fmt.Fprintf(&b, "This project was migrated from SQL Server: %d tables and %d views.\n\n", o.TablesCount, o.ViewsCount)
+	}
+	b.WriteString("Staging models read the source tables as they are; the marts build on them. Use the lineage graph to see how a model is derived.\n")
+	b.WriteString("{% enddocs %}\n")
+	return b.String()
+}
+
+// docsText escapes text for the HTML and Markdown of a docs block, and its braces so
+// dbt doesn't read them as Jinja
+func docsText(s string) string {
+	return strings.NewReplacer("{", "&#123;", "}", "&#125;").Replace(html.EscapeString(s))
+}
@@ -0,0 +1,155 @@
+package dbtgen
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	exposureNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,127}$`)
+
+	validExposureTypes    = map[string]bool{"dashboard": true, "notebook": true, "analysis": true, "ml": true, "application": true}
+	validExposureTools    = map[string]bool{"powerbi": true, "tableau": true, "other": true}
+	validExposureMaturity = map[string]bool{"low": true, "medium": true, "high": true}
+)
+
+// ExposureName turns a BI artifact's display name into a dbt exposure name, e.g.
+// "Sales Overview (FY24)" -> "sales_overview_fy24"
+func ExposureName(label string) string {
+	name := strings.Trim(nameCharsRegex.ReplaceAllString(strings.ToLower(label), "_"), "_")
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		name = "exposure_" + name
+	}
+	if len(name) > 128 {
+		name = name[:128]
+	}
+	return strings.TrimRight(name, "_")
+}
+
+// StagingModelName is the staging model the AI service generates for a source table
+func StagingModelName(table string) string {
+	return ModelName(models.DefaultNamingConventions.StagingPrefix, table)
+}
+
+// ModelName is the model for a source table with an organization's layer prefix, e.g.
+// ("stg_", "Sales.Customer") -> "stg_customer"
+func ModelName(prefix, table string) string {
+	return prefix + resourceName(table)
+}
+
+// ValidateExposure checks an exposure request, defaulting Tool to "other"
+func ValidateExposure(req *models.ExposureRequest) *validation.ValidationResult {
+	result := validation.NewValidationResult()
+
+	if !exposureNameRegex.MatchString(req.Name) {
+		result.AddError("name", "must be snake_case: lowercase letters, digits and underscores, starting with a letter")
+	}
+	if !validExposureTypes[req.Type] {
+		result.AddError("type", "must be dashboard, notebook, analysis, ml or application")
+	}
+	if req.Tool == "" {
+		req.Tool = "other"
+	}
+	if !validExposureTools[req.Tool] {
+		result.AddError("tool", "must be powerbi, tableau or other")
+	}
+	if req.Maturity != nil && !validExposureMaturity[*req.Maturity] {
+		result.AddError("maturity", "must be low, medium or high")
+	}
+	if req.URL != nil {
+		if u, err := url.Parse(*req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			result.AddError("url", "must be an http(s) URL")
+		}
+	}
+	// dbt requires an owner with a name or email
+	if isBlank(req.OwnerName) && isBlank(req.OwnerEmail) {
+		result.AddError("owner", "owner_name or owner_email is required")
+	}
+	for _, table := range req.DependsOn {
+		if !tableNameRegex.MatchString(table) {
+			result.AddError("depends_on", fmt.Sprintf("%q is not a table name", table))
+		}
+	}
+
+	return result
+}
+
+func isBlank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
+
+type exposuresFile struct {
+	Version   int             `yaml:"version"`
+	Exposures []exposureEntry `yaml:"exposures"`
+}
+
+type exposureEntry struct {
+	Name        string            `yaml:"name"`
+	Label       string            `yaml:"label,omitempty"`
+	Type        string            `yaml:"type"`
+	Maturity    string            `yaml:"maturity,omitempty"`
+	URL         string            `yaml:"url,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	DependsOn   []string          `yaml:"depends_on"`
+	Owner       exposureOwner     `yaml:"owner"`
+	Meta        map[string]string `yaml:"meta,omitempty"`
+}
+
+type exposureOwner struct {
+	Name  string `yaml:"name,omitempty"`
+	Email string `yaml:"email,omitempty"`
+}
+
+// ExposuresYAML renders exposures as a dbt exposures.yml. seeds maps source tables
+// (schema.table) to seed names; those are ref()'d instead of their staging model.
+func ExposuresYAML(exposures []models.Exposure, seeds map[string]string) (string, error) {
+	file := exposuresFile{Version: 2, Exposures: []exposureEntry{}}
+	for _, e := range exposures {
+		entry := exposureEntry{
+			Name:        e.Name,
+			Label:       deref(e.Label),
+			Type:        e.Type,
+			Maturity:    deref(e.Maturity),
+			URL:         deref(e.URL),
+			Description: deref(e.Description),
+			DependsOn:   []string{},
+			Owner:       exposureOwner{Name: deref(e.OwnerName), Email: deref(e.OwnerEmail)},
+			Meta:        map[string]string{"tool": e.Tool},
+		}
+		if e.ExternalID != nil {
+			entry.Meta["external_id"] = *e.ExternalID
+		}
+		for _, table := range e.DependsOn {
+			model := StagingModelName(table)
+			if seed, ok := seeds[table]; ok {
+				model = seed
+			}
+			entry.DependsOn = append(entry.DependsOn, fmt.Sprintf("ref('%s')", model))
+		}
+		file.Exposures = append(file.Exposures, entry)
+	}
+
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,110 @@
+package dbtgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+// maxRowFilterLength bounds a table's WHERE clause
+const maxRowFilterLength = 1000
+
+// NormalizeTableFilters trims table names, filters and excluded columns
+func NormalizeTableFilters(filters []models.TableFilter) []models.TableFilter {
+	normalized := make([]models.TableFilter, len(filters))
+	for i, f := range filters {
+		f.Table = strings.TrimSpace(f.Table)
+		f.Where = strings.TrimSpace(f.Where)
+		f.ExcludeColumns = trimAll(f.ExcludeColumns)
+		normalized[i] = f
+	}
+	return normalized
+}
+
+// ValidateTableFilters checks normalized table filters. If tables is non-empty, every
+// filter must be for one of the selected tables.
+func ValidateTableFilters(filters []models.TableFilter, tables []string) *validation.ValidationResult {
+	result := validation.NewValidationResult()
+
+	selected := map[string]bool{}
+	for _, table := range tables {
+		selected[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+	seen := map[string]bool{}
+
+	for i, f := range filters {
+		field := fmt.Sprintf("table_filters[%d]", i)
+
+		key := strings.ToLower(f.Table)
+		switch {
+		case !tableNameRegex.MatchString(f.Table):
+			result.AddError(field+".table", "must be a table name, optionally schema-qualified")
+		case len(selected) > 0 && !selected[key]:
+			result.AddError(field+".table", fmt.Sprintf("%s is not one of the selected tables", f.Table))
+		case seen[key]:
+			result.AddError(field+".table", fmt.Sprintf("%s is listed more than once", f.Table))
+		}
+		seen[key] = true
+
+		if f.Where == "" && len(f.ExcludeColumns) == 0 {
+			result.AddError(field, "needs a where clause or columns to exclude")
+		}
+		if f.Where != "" {
+			if problem := rowFilterProblem(f.Where); problem != "" {
+				result.AddError(field+".where", problem)
+			}
+		}
+		validateColumns(result, field+".exclude_columns", f.ExcludeColumns)
+	}
+
+	return result
+}
+
+// rowFilterProblem explains why a WHERE clause can't go into a staging model, or returns
+// "". The clause must be a single boolean expression: no statements, comments, Jinja or
+// unterminated quotes and brackets.
+func rowFilterProblem(where string) string {
+	if len(where) > maxRowFilterLength {
+		return fmt.Sprintf("must be at most %d characters", maxRowFilterLength)
+	}
+	if validation.ContainsSQLInjection(where) {
+		return "contains a SQL statement or injection pattern; quote column names that end in a keyword, e.g. [LastUpdate]"
+	}
+	for _, token := range []string{";", "--", "/*", "*/", "{{", "}}", "{%", "%}", "{#", "\x00"} {
+		if strings.Contains(where, token) {
+			return fmt.Sprintf("must not contain %q", token)
+		}
+	}
+
+	depth := 0
+	var quote rune // The quote or closing bracket of the literal or identifier being read
+	for _, r := range where {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '\'', '"', '`':
+			quote = r
+		case '[':
+			quote = ']'
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return "has an unmatched closing parenthesis"
+			}
+		}
+	}
+	if quote != 0 {
+		return "has an unterminated quote or bracket"
+	}
+	if depth != 0 {
+		return "has an unclosed parenthesis"
+	}
+	return ""
+}
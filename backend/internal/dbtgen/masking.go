@@ -0,0 +1,93 @@
+package dbtgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+// Column mask actions, applied by the staging models
+const (
+	MaskActionMask = "mask" // Replace every value with NULL
+	MaskActionHash = "hash" // Replace every value with its SHA-256 digest, in hex (keeps joinability)
+	MaskActionDrop = "drop" // Leave the column out of the model
+)
+
+// maxColumnMasks bounds an organization's masking policy
+const maxColumnMasks = 500
+
+// NormalizeColumnMasks trims table and column names and lowercases actions
+func NormalizeColumnMasks(masks []models.ColumnMask) []models.ColumnMask {
+	normalized := make([]models.ColumnMask, len(masks))
+	for i, m := range masks {
+		m.Table = strings.TrimSpace(m.Table)
+		m.Column = strings.TrimSpace(m.Column)
+		m.Action = strings.ToLower(strings.TrimSpace(m.Action))
+		normalized[i] = m
+	}
+	return normalized
+}
+
+// ValidateColumnMasks checks normalized masking rules. A column may have one rule per
+// table, and one for every table.
+func ValidateColumnMasks(masks []models.ColumnMask) *validation.ValidationResult {
+	result := validation.NewValidationResult()
+	if len(masks) > maxColumnMasks {
+		result.AddError("rules", fmt.Sprintf("must have at most %d rules", maxColumnMasks))
+		return result
+	}
+
+	seen := map[string]bool{}
+	for i, m := range masks {
+		field := fmt.Sprintf("rules[%d]", i)
+
+		if m.Table != "" && !tableNameRegex.MatchString(m.Table) {
+			result.AddError(field+".table", "must be a table name, optionally schema-qualified, or empty for every table")
+		}
+		if !columnNameRegex.MatchString(m.Column) {
+			result.AddError(field+".column", fmt.Sprintf("%q is not a valid column name", m.Column))
+		}
+		switch m.Action {
+		case MaskActionMask, MaskActionHash, MaskActionDrop:
+		default:
+			result.AddError(field+".action", "must be mask, hash or drop")
+		}
+
+		key := strings.ToLower(m.Table + "\x00" + m.Column)
+		if seen[key] {
+			target := "every table"
+			if m.Table != "" {
+				target = m.Table
+			}
+			result.AddError(field, fmt.Sprintf("%s already has a rule for %s", m.Column, target))
+		}
+		seen[key] = true
+	}
+
+	return result
+}
+
+// MasksForTables returns the rules that apply to a migration of the given tables: rules
+// for every table, and rules for one of the tables. With no tables selected, every
+// table is migrated and every rule applies. A rule for an unqualified table applies to
+// the table in any schema.
+func MasksForTables(masks []models.ColumnMask, tables []string) []models.ColumnMask {
+	if len(tables) == 0 {
+		return masks
+	}
+	selected := map[string]bool{}
+	for _, table := range tables {
+		table = strings.ToLower(strings.TrimSpace(table))
+		selected[table] = true
+		selected[table[strings.LastIndex(table, ".")+1:]] = true
+	}
+	var applied []models.ColumnMask
+	for _, m := range masks {
+		if m.Table == "" || selected[strings.ToLower(m.Table)] {
+			applied = append(applied, m)
+		}
+	}
+	return applied
+}
@@ -0,0 +1,304 @@
+package dbtgen
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+)
+
+// Project scaffolding choices
+const (
+	LayersLayered = "layered" // models/staging, models/intermediate, models/marts
+	LayersSimple  = "simple"  // Every model in models/
+
+	FoldersFlat   = "flat"   // No subfolders
+	FoldersSchema = "schema" // A subfolder per source schema
+	FoldersDomain = "domain" // A subfolder per business domain
+
+	NamingSource   = "source"   // Model names follow the source tables
+	NamingSingular = "singular" // stg_customer
+	NamingPlural   = "plural"   // stg_customers
+)
+
+const (
+	// sharedDomain is the folder of tables that aren't in any domain
+	sharedDomain = "shared"
+	// defaultSchema is the schema of unqualified SQL Server tables
+	defaultSchema = "dbo"
+)
+
+var domainNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// ScaffoldingSteps are the questions of the project scaffolding wizard, in order
+var ScaffoldingSteps = []models.ScaffoldingStep{
+	{
+		Field:    "layers",
+		Question: "How should models be layered?",
+		Default:  LayersLayered,
+		Choices: []models.ScaffoldingChoice{
+			{Value: LayersLayered, Label: "Staging, intermediate and marts", Description: "The dbt style guide layout: source-conformed staging models, business logic in intermediate models, and marts for consumers."},
+			{Value: LayersSimple, Label: "Simple", Description: "Every model in one models folder. Suits small projects and one-off migrations."},
+		},
+	},
+	{
+		Field:    "folders",
+		Question: "How should models be grouped into folders?",
+		Default:  FoldersFlat,
+		Choices: []models.ScaffoldingChoice{
+			{Value: FoldersFlat, Label: "No subfolders", Description: "All models of a layer side by side."},
+			{Value: FoldersSchema, Label: "Folder per source schema", Description: "e.g. staging/sales and staging/humanresources, mirroring the source database."},
+			{Value: FoldersDomain, Label: "Folder per domain", Description: "Folders for business domains you assign tables to. Tables outside every domain go to shared."},
+		},
+	},
+	{
+		Field:    "naming",
+		Question: "How should models be named?",
+		Default:  NamingSource,
+		Choices: []models.ScaffoldingChoice{
+			{Value: NamingSource, Label: "As in the source", Description: "Sales.Customers becomes stg_customers, Sales.Order becomes stg_order."},
+			{Value: NamingSingular, Label: "Singular", Description: "Sales.Customers becomes stg_customer."},
+			{Value: NamingPlural, Label: "Plural", Description: "Sales.Order becomes stg_orders."},
+		},
+	},
+}
+
+// NormalizeScaffolding lowercases choices and trims domain names and tables
+func NormalizeScaffolding(s *models.ProjectScaffolding) {
+	if s == nil {
+		return
+	}
+	s.Layers = strings.ToLower(strings.TrimSpace(s.Layers))
+	s.Folders = strings.ToLower(strings.TrimSpace(s.Folders))
+	s.Naming = strings.ToLower(strings.TrimSpace(s.Naming))
+	for i := range s.Domains {
+		s.Domains[i].Name = strings.TrimSpace(s.Domains[i].Name)
+		s.Domains[i].Tables = trimAll(s.Domains[i].Tables)
+	}
+}
+
+// ValidateScaffolding checks normalized scaffolding choices. If tables is non-empty,
+// domains may only hold selected tables.
+func ValidateScaffolding(s *models.ProjectScaffolding, tables []string) *validation.ValidationResult {
+	result := validation.NewValidationResult()
+	if s == nil {
+		return result
+	}
+
+	for _, choice := range []struct {
+		field, value string
+	}{
+		{"layers", s.Layers},
+		{"folders", s.Folders},
+		{"naming", s.Naming},
+	} {
+		if choice.value == "" {
+			continue
+		}
+		var values []string
+		for _, step := range ScaffoldingSteps {
+			if step.Field != choice.field {
+				continue
+			}
+			for _, c := range step.Choices {
+				values = append(values, c.Value)
+			}
+		}
+		if !slices.Contains(values, choice.value) {
+			result.AddError("scaffolding."+choice.field, "must be one of: "+strings.Join(values, ", "))
+		}
+	}
+
+	if s.Folders != FoldersDomain {
+		if len(s.Domains) > 0 {
+			result.AddError("scaffolding.domains", "are only used with folders set to domain")
+		}
+		return result
+	}
+	if len(s.Domains) == 0 {
+		result.AddError("scaffolding.domains", "are required with folders set to domain")
+	}
+
+	selected := map[string]bool{}
+	for _, table := range tables {
+		selected[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+	names := map[string]bool{}
+	assigned := map[string]string{}
+	for i, d := range s.Domains {
+		field := fmt.Sprintf("scaffolding.domains[%d]", i)
+		switch {
+		case !domainNameRegex.MatchString(d.Name):
+			result.AddError(field+".name", "must be snake_case: lowercase letters, digits and underscores, starting with a letter")
+		case d.Name == sharedDomain:
+			result.AddError(field+".name", "shared is the folder of tables outside every domain")
+		case names[d.Name]:
+			result.AddError(field+".name", fmt.Sprintf("%s is listed more than once", d.Name))
+		}
+		names[d.Name] = true
+
+		if len(d.Tables) == 0 {
+			result.AddError(field+".tables", "must list at least one table")
+		}
+		for _, table := range d.Tables {
+			key := strings.ToLower(table)
+			switch {
+			case !tableNameRegex.MatchString(table):
+				result.AddError(field+".tables", fmt.Sprintf("%q is not a table name", table))
+			case len(selected) > 0 && !selected[key]:
+				result.AddError(field+".tables", fmt.Sprintf("%s is not one of the selected tables", table))
+			case assigned[key] != "":
+				result.AddError(field+".tables", fmt.Sprintf("%s is already in %s", table, assigned[key]))
+			default:
+				assigned[key] = d.Name
+			}
+		}
+	}
+
+	return result
+}
+
+// ResolveScaffolding fills in the defaults of unset choices. A nil scaffolding resolves
+// to the default layout.
+func ResolveScaffolding(s *models.ProjectScaffolding) models.ProjectScaffolding {
+	var resolved models.ProjectScaffolding
+	if s != nil {
+		resolved = *s
+	}
+	for _, step := range ScaffoldingSteps {
+		switch step.Field {
+		case "layers":
+			resolved.Layers = defaultString(resolved.Layers, step.Default)
+		case "folders":
+			resolved.Folders = defaultString(resolved.Folders, step.Default)
+		case "naming":
+			resolved.Naming = defaultString(resolved.Naming, step.Default)
+		}
+	}
+	return resolved
+}
+
+// ScaffoldStagingModel is the name and path of a table's staging model in a resolved
+// scaffolding, e.g. ("stg_", "Sales.Customers") -> stg_customer in
+// models/staging/sales/stg_customer.sql
+func ScaffoldStagingModel(s models.ProjectScaffolding, prefix, table string) models.ScaffoldedModel {
+	name := prefix + InflectModelName(resourceName(table), s.Naming)
+	return models.ScaffoldedModel{
+		Source: table,
+		Name:   name,
+		Path:   path.Join(scaffoldFolder(s, table), name+".sql"),
+	}
+}
+
+// PreviewScaffolding lays out the staging models of a table selection
+func PreviewScaffolding(s models.ProjectScaffolding, prefix string, tables []string) models.ScaffoldingPreview {
+	preview := models.ScaffoldingPreview{Scaffolding: s, Folders: []string{}, Models: []models.ScaffoldedModel{}}
+	folders := map[string]bool{}
+	if s.Layers == LayersLayered {
+		for _, layer := range []string{"models/staging", "models/intermediate", "models/marts"} {
+			folders[layer] = true
+		}
+	} else {
+		folders["models"] = true
+	}
+	for _, table := range tables {
+		model := ScaffoldStagingModel(s, prefix, table)
+		folders[path.Dir(model.Path)] = true
+		preview.Models = append(preview.Models, model)
+	}
+	for folder := range folders {
+		preview.Folders = append(preview.Folders, folder)
+	}
+	sort.Strings(preview.Folders)
+	return preview
+}
+
+// scaffoldFolder is the folder of a table's staging model
+func scaffoldFolder(s models.ProjectScaffolding, table string) string {
+	folder := "models"
+	if s.Layers == LayersLayered {
+		folder = "models/staging"
+	}
+	switch s.Folders {
+	case FoldersSchema:
+		schema := defaultSchema
+		if i := strings.LastIndex(table, "."); i >= 0 {
+			schema = table[:i]
+		}
+		folder = path.Join(folder, resourceName(schema))
+	case FoldersDomain:
+		domain := sharedDomain
+		for _, d := range s.Domains {
+			if slices.ContainsFunc(d.Tables, func(t string) bool { return strings.EqualFold(t, table) }) {
+				domain = d.Name
+				break
+			}
+		}
+		folder = path.Join(folder, domain)
+	}
+	return folder
+}
+
+// InflectModelName makes the last word of a snake_case name singular or plural, e.g.
+// ("order_details", singular) -> "order_detail". It follows the English rules for
+// regular nouns; other namings leave the name as it is.
+func InflectModelName(name, naming string) string {
+	head, word := "", name
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		head, word = name[:i+1], name[i+1:]
+	}
+	switch naming {
+	case NamingSingular:
+		word = singular(word)
+	case NamingPlural:
+		word = plural(word)
+	}
+	return head + word
+}
+
+func singular(word string) string {
+	switch {
+	case len(word) > 3 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case hasAnySuffix(word, "sses", "xes", "zes", "ches", "shes"):
+		return word[:len(word)-2]
+	case hasAnySuffix(word, "ss", "us", "is") || !strings.HasSuffix(word, "s") || len(word) < 3:
+		return word
+	}
+	return word[:len(word)-1]
+}
+
+func plural(word string) string {
+	switch {
+	case word == "" || singular(word) != word:
+		return word // Already plural
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "is"):
+		return word[:len(word)-2] + "es"
+	case hasAnySuffix(word, "s", "x", "z", "ch", "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
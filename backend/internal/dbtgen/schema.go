@@ -0,0 +1,73 @@
+package dbtgen
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelSchema is a model's documented columns, from the properties (schema.yml) files of
+// a dbt project
+type ModelSchema struct {
+	Name    string
+	Alias   string // Relation name when it differs from the model name
+	Schema  string // Custom schema, appended to the target schema by default
+	Columns []ModelColumn
+}
+
+// ModelColumn is a documented column of a model
+type ModelColumn struct {
+	Name     string
+	DataType string // Only set for models with contracts or declared types
+}
+
+// Relation is the name of the table or view the model builds
+func (m ModelSchema) Relation() string {
+	if m.Alias != "" {
+		return m.Alias
+	}
+	return m.Name
+}
+
+type propertiesFile struct {
+	Models []struct {
+		Name   string `yaml:"name"`
+		Config struct {
+			Alias        string `yaml:"alias"`
+			Schema       string `yaml:"schema"`
+			Materialized string `yaml:"materialized"`
+		} `yaml:"config"`
+		Columns []struct {
+			Name     string `yaml:"name"`
+			DataType string `yaml:"data_type"`
+		} `yaml:"columns"`
+	} `yaml:"models"`
+}
+
+// ParseModelSchemas reads the models of a properties file. Ephemeral models and models
+// without documented columns are skipped, since there is nothing to compare them with.
+func ParseModelSchemas(content string) ([]ModelSchema, error) {
+	var file propertiesFile
+	if err := yaml.Unmarshal([]byte(content), &file); err != nil {
+		return nil, err
+	}
+	var schemas []ModelSchema
+	for _, m := range file.Models {
+		if m.Name == "" || len(m.Columns) == 0 || strings.EqualFold(m.Config.Materialized, "ephemeral") {
+			continue
+		}
+		schema := ModelSchema{Name: m.Name, Alias: m.Config.Alias, Schema: m.Config.Schema}
+		for _, c := range m.Columns {
+			if c.Name != "" {
+				schema.Columns = append(schema.Columns, ModelColumn{Name: c.Name, DataType: c.DataType})
+			}
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+// IsPropertiesFile reports whether a project file may declare model properties
+func IsPropertiesFile(path string) bool {
+	return strings.HasPrefix(path, "models/") && (strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml"))
+}
@@ -0,0 +1,7 @@
+package dbtgen
+
+// SeedName is the dbt seed name for a table, and its CSV file name without the
+// extension, e.g. "Sales.SalesTerritory" -> "salesterritory"
+func SeedName(table string) string {
+	return resourceName(table)
+}
@@ -0,0 +1,209 @@
+// Package dbtgen renders dbt project files that the backend controls directly, rather
+// than leaving them to the AI service
+package dbtgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/datamigrate-ai/backend/internal/models"
+	"github.com/datamigrate-ai/backend/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceName is the dbt source the generated staging models read from
+const SourceName = "mssql_source"
+
+// SnapshotSchema is the target schema snapshots are written to
+const SnapshotSchema = "snapshots"
+
+// Snapshot strategies
+const (
+	StrategyTimestamp = "timestamp"
+	StrategyCheck     = "check"
+)
+
+var (
+	// Table and column names as SQL Server allows them unquoted, plus spaces
+	columnNameRegex = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_@$# ]{0,127}$`)
+	tableNameRegex  = regexp.MustCompile(`^([\p{L}_][\p{L}\p{N}_@$# ]{0,127}\.)?[\p{L}_][\p{L}\p{N}_@$# ]{0,127}$`)
+
+	nameCharsRegex = regexp.MustCompile(`[^a-z0-9_]+`)
+
+	validHardDeletes = map[string]bool{"": true, "ignore": true, "invalidate": true, "new_record": true}
+)
+
+// NormalizeSnapshots fills in defaults (the timestamp strategy) and trims names
+func NormalizeSnapshots(snapshots []models.SnapshotConfig) []models.SnapshotConfig {
+	normalized := make([]models.SnapshotConfig, len(snapshots))
+	for i, s := range snapshots {
+		s.Table = strings.TrimSpace(s.Table)
+		s.Strategy = strings.ToLower(strings.TrimSpace(s.Strategy))
+		if s.Strategy == "" {
+			s.Strategy = StrategyTimestamp
+		}
+		s.UpdatedAt = strings.TrimSpace(s.UpdatedAt)
+		s.HardDeletes = strings.ToLower(strings.TrimSpace(s.HardDeletes))
+		s.UniqueKey = trimAll(s.UniqueKey)
+		s.CheckCols = trimAll(s.CheckCols)
+		normalized[i] = s
+	}
+	return normalized
+}
+
+// ValidateSnapshots checks normalized snapshot configs. If tables is non-empty, every
+// snapshot must be for one of the selected tables.
+func ValidateSnapshots(snapshots []models.SnapshotConfig, tables []string) *validation.ValidationResult {
+	result := validation.NewValidationResult()
+
+	selected := map[string]bool{}
+	for _, table := range tables {
+		selected[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+	seen := map[string]bool{}
+
+	for i, s := range snapshots {
+		field := fmt.Sprintf("snapshots[%d]", i)
+
+		switch {
+		case !tableNameRegex.MatchString(s.Table):
+			result.AddError(field+".table", "must be a table name, optionally schema-qualified")
+		case len(selected) > 0 && !selected[strings.ToLower(s.Table)]:
+			result.AddError(field+".table", fmt.Sprintf("%s is not one of the selected tables", s.Table))
+		case seen[SnapshotName(s.Table)]:
+			result.AddError(field+".table", fmt.Sprintf("%s would reuse the snapshot name %s", s.Table, SnapshotName(s.Table)))
+		}
+		seen[SnapshotName(s.Table)] = true
+
+		if len(s.UniqueKey) == 0 {
+			result.AddError(field+".unique_key", "at least one column is required")
+		}
+		validateColumns(result, field+".unique_key", s.UniqueKey)
+
+		switch s.Strategy {
+		case StrategyTimestamp:
+			if s.UpdatedAt == "" {
+				result.AddError(field+".updated_at", "is required for the timestamp strategy")
+			} else if !columnNameRegex.MatchString(s.UpdatedAt) {
+				result.AddError(field+".updated_at", "is not a valid column name")
+			}
+		case StrategyCheck:
+			if len(s.CheckCols) == 0 {
+				result.AddError(field+".check_cols", `is required for the check strategy (use ["all"] for every column)`)
+			} else if !(len(s.CheckCols) == 1 && s.CheckCols[0] == "all") {
+				validateColumns(result, field+".check_cols", s.CheckCols)
+			}
+		default:
+			result.AddError(field+".strategy", "must be timestamp or check")
+		}
+
+		if !validHardDeletes[s.HardDeletes] {
+			result.AddError(field+".hard_deletes", "must be ignore, invalidate or new_record")
+		}
+	}
+
+	return result
+}
+
+func validateColumns(result *validation.ValidationResult, field string, columns []string) {
+	for _, column := range columns {
+		if !columnNameRegex.MatchString(column) {
+			result.AddError(field, fmt.Sprintf("%q is not a valid column name", column))
+		}
+	}
+}
+
+// snapshotFile is the snapshots/*.yml layout introduced in dbt 1.9
+type snapshotFile struct {
+	Snapshots []snapshotEntry `yaml:"snapshots"`
+}
+
+type snapshotEntry struct {
+	Name     string         `yaml:"name"`
+	Relation string         `yaml:"relation"`
+	Config   snapshotConfig `yaml:"config"`
+}
+
+type snapshotConfig struct {
+	Schema      string      `yaml:"schema"`
+	UniqueKey   interface{} `yaml:"unique_key"` // A column, or a list for composite keys
+	Strategy    string      `yaml:"strategy"`
+	UpdatedAt   string      `yaml:"updated_at,omitempty"`
+	CheckCols   interface{} `yaml:"check_cols,omitempty"` // "all" or a list
+	HardDeletes string      `yaml:"hard_deletes,omitempty"`
+}
+
+// SnapshotName is the dbt snapshot name for a table, e.g. "Sales.Customer" ->
+// "customer_snapshot"
+func SnapshotName(table string) string {
+	return resourceName(table) + "_snapshot"
+}
+
+// resourceName turns a possibly schema-qualified table into a dbt resource name: the
+// table name, lowercased, with anything but letters, digits and underscores replaced
+func resourceName(table string) string {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	return strings.Trim(nameCharsRegex.ReplaceAllString(strings.ToLower(table), "_"), "_")
+}
+
+// SnapshotsYAML renders validated snapshot configs as a dbt snapshots YAML file
+func SnapshotsYAML(snapshots []models.SnapshotConfig) (string, error) {
+	file := snapshotFile{Snapshots: []snapshotEntry{}}
+	for _, s := range snapshots {
+		table := s.Table
+		if i := strings.LastIndex(table, "."); i >= 0 {
+			table = table[i+1:]
+		}
+
+		config := snapshotConfig{
+			Schema:      SnapshotSchema,
+			Strategy:    s.Strategy,
+			HardDeletes: s.HardDeletes,
+		}
+		if len(s.UniqueKey) == 1 {
+			config.UniqueKey = s.UniqueKey[0]
+		} else {
+			config.UniqueKey = s.UniqueKey
+		}
+		switch s.Strategy {
+		case StrategyTimestamp:
+			config.UpdatedAt = s.UpdatedAt
+		case StrategyCheck:
+			if len(s.CheckCols) == 1 && s.CheckCols[0] == "all" {
+				config.CheckCols = "all"
+			} else {
+				config.CheckCols = s.CheckCols
+			}
+		}
+
+		file.Snapshots = append(file.Snapshots, snapshotEntry{
+			Name:     SnapshotName(s.Table),
+			Relation: fmt.Sprintf("source('%s', '%s')", SourceName, table),
+			Config:   config,
+		})
+	}
+
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func trimAll(values []string) []string {
+	trimmed := []string{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
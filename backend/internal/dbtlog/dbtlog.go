@@ -0,0 +1,193 @@
+// Package dbtlog parses the console output of dbt run, test and build into structured
+// errors: which node failed, the class of error and the offending SQL line
+package dbtlog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Error classes, from the header dbt prints above each error
+const (
+	ClassDatabase    = "database_error"
+	ClassCompilation = "compilation_error"
+	ClassRuntime     = "runtime_error"
+	ClassDependency  = "dependency_error"
+	ClassParsing     = "parsing_error"
+	ClassTestFailure = "test_failure"
+)
+
+// Categories narrow a class down to the likely cause, so that fixes can be suggested
+const (
+	CategoryMissingRelation   = "missing_relation"
+	CategoryMissingColumn     = "missing_column"
+	CategoryMissingRef        = "missing_ref"
+	CategorySyntax            = "syntax_error"
+	CategoryTypeMismatch      = "type_mismatch"
+	CategoryPermission        = "permission_denied"
+	CategoryTimeout           = "timeout"
+	CategoryNotNullTest       = "not_null_test"
+	CategoryUniqueTest        = "unique_test"
+	CategoryRelationshipsTest = "relationships_test"
+	CategoryAcceptedValues    = "accepted_values_test"
+	CategoryDataTest          = "data_test"
+	CategoryOther             = "other"
+)
+
+// Error is one failed node in dbt output
+type Error struct {
+	Phase        string // run or test
+	ResourceType string // model, test, seed, snapshot...; empty when dbt didn't name the node
+	Resource     string // e.g. stg_orders or not_null_stg_orders_order_id
+	FilePath     string // e.g. models/staging/stg_orders.sql
+	Class        string
+	Category     string
+	Line         int // Line of the compiled SQL, 0 when unknown
+	Message      string
+}
+
+var classes = map[string]string{
+	"Database Error":    ClassDatabase,
+	"Compilation Error": ClassCompilation,
+	"Runtime Error":     ClassRuntime,
+	"Dependency Error":  ClassDependency,
+	"Parsing Error":     ClassParsing,
+	"Failure":           ClassTestFailure,
+}
+
+var (
+	// Log lines are prefixed with the time and two spaces, e.g. "12:01:33  "; any further
+	// indentation belongs to the line
+	timestampRegex = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(?:\.\d+)?  `)
+	// "Database Error in model stg_orders (models/staging/stg_orders.sql)"
+	headerRegex = regexp.MustCompile(`^(Database Error|Compilation Error|Runtime Error|Dependency Error|Parsing Error|Failure) in (\w+) ([\w.\-]+)(?: \(([^)]+)\))?\s*$`)
+	// The same headers without a node, which then is named in the message
+	bareHeaderRegex = regexp.MustCompile(`^(Database Error|Compilation Error|Runtime Error|Dependency Error|Parsing Error)\s*$`)
+	// "Model 'model.shop.fct_orders' (models/marts/fct_orders.sql) depends on a node named..."
+	nodeRegex = regexp.MustCompile(`(?i)\b(model|test|seed|snapshot|source)\s+'[\w]+\.[\w]+\.([\w.]+)'(?:\s+\(([^)]+)\))?`)
+	// Postgres "LINE 12:", Snowflake "error line 12 at position 4", SQL Server "Line 12",
+	// BigQuery "at [12:5]"
+	lineRegex        = regexp.MustCompile(`(?i)\bline\s+(\d+)\b`)
+	bracketLineRegex = regexp.MustCompile(`\bat \[(\d+):\d+\]`)
+	// Lines that point at artifacts rather than describing the error
+	noiseRegex = regexp.MustCompile(`(?i)^(compiled (sql|code) at|see test failures:|select \* from|-{3,})`)
+
+	categories = []struct {
+		category string
+		pattern  *regexp.Regexp
+	}{
+		{CategoryMissingRef, regexp.MustCompile(`(?i)depends on a node named|which was not found|node .* not found|macro .* not found`)},
+		{CategoryMissingColumn, regexp.MustCompile(`(?i)column .*(does not exist|not found)|invalid column name|invalid identifier|unrecognized name|no such column`)},
+		{CategoryMissingRelation, regexp.MustCompile(`(?i)(relation|object|table|view|schema) .*(does not exist|not found)|invalid object name|does not exist or not authorized`)},
+		{CategoryPermission, regexp.MustCompile(`(?i)permission denied|insufficient privileges|access denied|not authorized`)},
+		{CategoryTypeMismatch, regexp.MustCompile(`(?i)invalid input syntax for type|conversion failed|cannot be cast|is not recognized|operator does not exist|type mismatch|numeric value .* out of range`)},
+		{CategorySyntax, regexp.MustCompile(`(?i)syntax error|incorrect syntax|unexpected|parse error`)},
+		{CategoryTimeout, regexp.MustCompile(`(?i)time(d)? ?out|statement cancel|query exceeded|canceling statement`)},
+	}
+)
+
+// Parse extracts the errors from dbt output, which may mix runs and tests as dbt build
+// does. dbt repeats errors in its summary, so each failed node is reported once.
+func Parse(output string) []Error {
+	var errs []Error
+	seen := map[string]bool{}
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripTimestamp(lines[i]))
+		var e Error
+		if m := headerRegex.FindStringSubmatch(line); m != nil {
+			e = Error{Class: classes[m[1]], ResourceType: m[2], Resource: m[3], FilePath: m[4]}
+		} else if m := bareHeaderRegex.FindStringSubmatch(line); m != nil {
+			e = Error{Class: classes[m[1]]}
+		} else {
+			continue
+		}
+
+		// The message is the indented block below the header
+		var message []string
+		for i+1 < len(lines) {
+			next := stripTimestamp(lines[i+1])
+			if strings.TrimSpace(next) == "" || !strings.HasPrefix(next, " ") {
+				break
+			}
+			i++
+			if text := strings.TrimSpace(next); !noiseRegex.MatchString(text) {
+				message = append(message, text)
+			}
+		}
+		e.Message = strings.Join(message, "\n")
+
+		if e.Resource == "" {
+			if m := nodeRegex.FindStringSubmatch(e.Message); m != nil {
+				e.ResourceType, e.Resource, e.FilePath = strings.ToLower(m[1]), m[2], m[3]
+			}
+		}
+		e.Phase = "run"
+		if e.ResourceType == "test" || e.ResourceType == "unit_test" || e.Class == ClassTestFailure {
+			e.Phase = "test"
+		}
+		e.Line = errorLine(e.Message)
+		e.Category = categorize(e)
+
+		key := e.Phase + "\x00" + e.Resource + "\x00" + e.Message
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// FromMessage classifies a failure reported without dbt's output, such as the status
+// message of a dbt Cloud run
+func FromMessage(message string) Error {
+	e := Error{Phase: "run", Class: ClassRuntime, Message: strings.TrimSpace(message)}
+	if m := nodeRegex.FindStringSubmatch(e.Message); m != nil {
+		e.ResourceType, e.Resource, e.FilePath = strings.ToLower(m[1]), m[2], m[3]
+	}
+	e.Line = errorLine(e.Message)
+	e.Category = categorize(e)
+	return e
+}
+
+func stripTimestamp(line string) string {
+	return timestampRegex.ReplaceAllString(line, "")
+}
+
+func errorLine(message string) int {
+	m := lineRegex.FindStringSubmatch(message)
+	if m == nil {
+		m = bracketLineRegex.FindStringSubmatch(message)
+	}
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+func categorize(e Error) string {
+	if e.Class == ClassTestFailure {
+		// Generic tests are named after the test, e.g. unique_stg_orders_order_id
+		switch name := e.Resource; {
+		case strings.HasPrefix(name, "not_null_"):
+			return CategoryNotNullTest
+		case strings.HasPrefix(name, "unique_"):
+			return CategoryUniqueTest
+		case strings.HasPrefix(name, "relationships_"):
+			return CategoryRelationshipsTest
+		case strings.HasPrefix(name, "accepted_values_"):
+			return CategoryAcceptedValues
+		}
+		return CategoryDataTest
+	}
+	for _, c := range categories {
+		if c.pattern.MatchString(e.Message) {
+			return c.category
+		}
+	}
+	return CategoryOther
+}
@@ -0,0 +1,80 @@
+package dbtlog
+
+import "testing"
+
+const buildOutput = `12:01:30  Running with dbt=1.7.4
+12:01:31  1 of 4 START sql view model analytics.stg_orders ........................ [RUN]
+12:01:32  1 of 4 ERROR creating sql view model analytics.stg_orders ............... [ERROR in 0.05s]
+12:01:32  2 of 4 START test not_null_stg_customers_customer_id .................... [RUN]
+12:01:33  2 of 4 FAIL 3 not_null_stg_customers_customer_id ........................ [FAIL 3 in 0.04s]
+12:01:34
+12:01:34  Completed with 3 errors and 0 warnings:
+12:01:34
+12:01:34  Database Error in model stg_orders (models/staging/stg_orders.sql)
+12:01:34    column "order_dt" does not exist
+12:01:34    LINE 12:     order_dt as ordered_at
+12:01:34                 ^
+12:01:34    compiled Code at target/run/shop/models/staging/stg_orders.sql
+12:01:34
+12:01:34  Failure in test not_null_stg_customers_customer_id (models/staging/schema.yml)
+12:01:34    Got 3 results, configured to fail if != 0
+12:01:34
+12:01:34    compiled Code at target/compiled/shop/models/staging/schema.yml/not_null_stg_customers_customer_id.sql
+12:01:34
+12:01:34  Compilation Error
+12:01:34    Model 'model.shop.fct_orders' (models/marts/fct_orders.sql) depends on a node named 'stg_payments' which was not found
+12:01:34
+12:01:34  Database Error in model stg_orders (models/staging/stg_orders.sql)
+12:01:34    column "order_dt" does not exist
+12:01:34    LINE 12:     order_dt as ordered_at
+12:01:34                 ^
+12:01:34
+12:01:34  Done. PASS=2 WARN=0 ERROR=2 SKIP=0 TOTAL=4
+`
+
+func TestParse(t *testing.T) {
+	errs := Parse(buildOutput)
+	want := []Error{
+		{Phase: "run", ResourceType: "model", Resource: "stg_orders", FilePath: "models/staging/stg_orders.sql",
+			Class: ClassDatabase, Category: CategoryMissingColumn, Line: 12,
+			Message: "column \"order_dt\" does not exist\nLINE 12:     order_dt as ordered_at\n^"},
+		{Phase: "test", ResourceType: "test", Resource: "not_null_stg_customers_customer_id", FilePath: "models/staging/schema.yml",
+			Class: ClassTestFailure, Category: CategoryNotNullTest,
+			Message: "Got 3 results, configured to fail if != 0"},
+		{Phase: "run", ResourceType: "model", Resource: "fct_orders", FilePath: "models/marts/fct_orders.sql",
+			Class: ClassCompilation, Category: CategoryMissingRef,
+			Message: "Model 'model.shop.fct_orders' (models/marts/fct_orders.sql) depends on a node named 'stg_payments' which was not found"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Parse returned %d errors, want %d: %+v", len(errs), len(want), errs)
+	}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Errorf("error %d = %+v\nwant %+v", i, errs[i], want[i])
+		}
+	}
+}
+
+func TestParseWithoutTimestamps(t *testing.T) {
+	errs := Parse("Database Error in model dim_customers (models/marts/dim_customers.sql)\n" +
+		"  Syntax error: Unexpected keyword FROM at [4:3]\n")
+	if len(errs) != 1 {
+		t.Fatalf("Parse returned %d errors, want 1", len(errs))
+	}
+	if e := errs[0]; e.Resource != "dim_customers" || e.Category != CategorySyntax || e.Line != 4 {
+		t.Errorf("error = %+v", e)
+	}
+}
+
+func TestParseNoErrors(t *testing.T) {
+	if errs := Parse("12:00:00  Done. PASS=4 WARN=0 ERROR=0 SKIP=0 TOTAL=4\n"); len(errs) != 0 {
+		t.Errorf("Parse returned %+v, want none", errs)
+	}
+}
+
+func TestFromMessage(t *testing.T) {
+	e := FromMessage("Database Error: permission denied for schema raw\n")
+	if e.Class != ClassRuntime || e.Category != CategoryPermission || e.Message != "Database Error: permission denied for schema raw" {
+		t.Errorf("FromMessage = %+v", e)
+	}
+}
@@ -0,0 +1,246 @@
+// Package domains maps the custom domains organizations host the product under, such as
+// app.customer.com, to those organizations. Verified domains are cached and reloaded
+// periodically so every instance picks up a newly verified domain without a redeploy.
+package domains
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// VerificationPrefix is the DNS label the verification TXT record is published under
+const VerificationPrefix = "_datamigrate-verification"
+
+// refreshInterval controls how often verified domains are reloaded, so a domain verified
+// through one instance reaches the others
+const refreshInterval = time.Minute
+
+var (
+	// A hostname of at least two labels, without a trailing dot
+	domainRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9\-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9\-]{0,61}[a-z0-9])?$`)
+
+	// ErrVerificationFailed is returned when the TXT record is missing or doesn't match
+	ErrVerificationFailed = errors.New("verification record not found")
+
+	// lookupTXT resolves TXT records; replaced in tests
+	lookupTXT = net.DefaultResolver.LookupTXT
+)
+
+type registry struct {
+	mu       sync.RWMutex
+	reserved map[string]bool  // The product's own hosts, which can't be claimed
+	byDomain map[string]int64 // Verified domain -> organization
+	primary  map[int64]string // Organization -> first verified domain, for links
+}
+
+var (
+	domains     = &registry{reserved: map[string]bool{}, byDomain: map[string]int64{}, primary: map[int64]string{}}
+	domainsOnce sync.Once
+)
+
+// Init sets the hosts that can't be claimed, loads verified domains and starts the
+// refresh loop. Later calls are no-ops.
+func Init(reserved []string) {
+	domainsOnce.Do(func() {
+		domains.mu.Lock()
+		for _, host := range reserved {
+			if host = Normalize(host); host != "" {
+				domains.reserved[host] = true
+			}
+		}
+		domains.mu.Unlock()
+
+		if err := Reload(); err != nil {
+			log.Printf("Failed to load custom domains: %v", err)
+		}
+		go refreshLoop()
+	})
+}
+
+// Reload re-reads the verified domains
+func Reload() error {
+	var rows []struct {
+		Domain         string `db:"domain"`
+		OrganizationID int64  `db:"organization_id"`
+	}
+	err := db.DB.Select(&rows, `
+		SELECT domain, organization_id FROM organization_domains
+		WHERE verified_at IS NOT NULL
+		ORDER BY verified_at, id
+	`)
+	if err != nil {
+		return err
+	}
+
+	byDomain := make(map[string]int64, len(rows))
+	primary := map[int64]string{}
+	for _, r := range rows {
+		byDomain[r.Domain] = r.OrganizationID
+		if _, ok := primary[r.OrganizationID]; !ok {
+			primary[r.OrganizationID] = r.Domain
+		}
+	}
+	domains.set(byDomain, primary)
+	return nil
+}
+
+func (r *registry) set(byDomain map[string]int64, primary map[int64]string) {
+	r.mu.Lock()
+	r.byDomain = byDomain
+	r.primary = primary
+	r.mu.Unlock()
+}
+
+func refreshLoop() {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := Reload(); err != nil {
+			log.Printf("Failed to reload custom domains: %v", err)
+		}
+	}
+}
+
+// Normalize lowercases a host and strips its port and trailing dot
+func Normalize(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// Validate checks that a normalized domain can be claimed by an organization
+func Validate(domain string) error {
+	if len(domain) > 253 || !domainRegex.MatchString(domain) {
+		return fmt.Errorf("%q is not a domain name like app.example.com", domain)
+	}
+	domains.mu.RLock()
+	defer domains.mu.RUnlock()
+	for reserved := range domains.reserved {
+		if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
+			return fmt.Errorf("%q belongs to this service and can't be used as a custom domain", domain)
+		}
+	}
+	return nil
+}
+
+// Organization returns the organization a verified domain belongs to
+func Organization(host string) (int64, bool) {
+	domains.mu.RLock()
+	defer domains.mu.RUnlock()
+	orgID, ok := domains.byDomain[Normalize(host)]
+	return orgID, ok
+}
+
+// OriginAllowed reports whether origin is an https origin on a verified domain
+func OriginAllowed(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != "https" || u.Path != "" {
+		return false
+	}
+	_, ok := Organization(u.Host)
+	return ok
+}
+
+// RequestDomain returns the verified domain a browser request was made from: the host
+// of its Origin, or its Host when the domain is routed to the API directly
+func RequestDomain(r *http.Request) (string, int64, bool) {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err == nil && u.Scheme == "https" {
+			if orgID, ok := Organization(u.Host); ok {
+				return Normalize(u.Host), orgID, true
+			}
+		}
+		return "", 0, false
+	}
+	if orgID, ok := Organization(r.Host); ok {
+		return Normalize(r.Host), orgID, true
+	}
+	return "", 0, false
+}
+
+// RequestOrganization returns the organization whose domain a request was made from
+func RequestOrganization(r *http.Request) (int64, bool) {
+	_, orgID, ok := RequestDomain(r)
+	return orgID, ok
+}
+
+// BaseURL returns the frontend URL of an organization's first verified domain, or ""
+// when it has none
+func BaseURL(orgID int64) string {
+	domains.mu.RLock()
+	defer domains.mu.RUnlock()
+	if domain, ok := domains.primary[orgID]; ok {
+		return "https://" + domain
+	}
+	return ""
+}
+
+// RequestBaseURL returns the frontend URL of the verified domain a request was made
+// from, or "" for the product's own domain
+func RequestBaseURL(r *http.Request) string {
+	if domain, _, ok := RequestDomain(r); ok {
+		return "https://" + domain
+	}
+	return ""
+}
+
+// HostPolicy lets Let's Encrypt issue certificates for the configured hosts and every
+// verified custom domain
+func HostPolicy(hosts []string) autocert.HostPolicy {
+	static := autocert.HostWhitelist(hosts...)
+	return func(ctx context.Context, host string) error {
+		if _, ok := Organization(host); ok {
+			return nil
+		}
+		return static(ctx, host)
+	}
+}
+
+// NewVerificationToken returns a random token for a domain's TXT record
+func NewVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// VerificationRecord is the name of the TXT record that proves control of a domain
+func VerificationRecord(domain string) string {
+	return VerificationPrefix + "." + domain
+}
+
+// CheckVerification looks up a domain's verification TXT record and compares it with
+// the token
+func CheckVerification(ctx context.Context, domain, token string) error {
+	records, err := lookupTXT(ctx, VerificationRecord(domain))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return ErrVerificationFailed
+		}
+		return err
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == token {
+			return nil
+		}
+	}
+	return ErrVerificationFailed
+}
@@ -0,0 +1,143 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+// useDomains replaces the registry for one test
+func useDomains(t *testing.T, reserved []string, byDomain map[string]int64) {
+	t.Helper()
+	reservedBefore, byDomainBefore, primaryBefore := domains.reserved, domains.byDomain, domains.primary
+	t.Cleanup(func() {
+		domains.reserved = reservedBefore
+		domains.set(byDomainBefore, primaryBefore)
+	})
+
+	domains.reserved = map[string]bool{}
+	for _, host := range reserved {
+		domains.reserved[host] = true
+	}
+	primary := map[int64]string{}
+	for domain, orgID := range byDomain {
+		primary[orgID] = domain
+	}
+	domains.set(byDomain, primary)
+}
+
+func TestNormalizeAndValidate(t *testing.T) {
+	useDomains(t, []string{"app.datamigrate.ai"}, nil)
+
+	if got := Normalize(" App.Contoso.com.:443 "); got != "app.contoso.com" {
+		t.Errorf("Normalize = %q", got)
+	}
+	cases := map[string]bool{
+		"app.contoso.com":            true,
+		"data.eu.contoso.co.uk":      true,
+		"contoso":                    false,
+		"-app.contoso.com":           false,
+		"app_1.contoso.com":          false,
+		"10.0.0.1":                   false,
+		"app.datamigrate.ai":         false,
+		"contoso.app.datamigrate.ai": false,
+	}
+	for domain, valid := range cases {
+		if err := Validate(domain); (err == nil) != valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", domain, err, valid)
+		}
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	useDomains(t, nil, map[string]int64{"app.contoso.com": 3})
+
+	cases := map[string]bool{
+		"https://app.contoso.com":          true,
+		"https://APP.contoso.com:8443":     true,
+		"http://app.contoso.com":           false,
+		"https://app.contoso.com.evil.com": false,
+		"https://evil.com":                 false,
+		"":                                 false,
+	}
+	for origin, want := range cases {
+		if got := OriginAllowed(origin); got != want {
+			t.Errorf("OriginAllowed(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
+
+func TestRequestDomain(t *testing.T) {
+	useDomains(t, nil, map[string]int64{"app.contoso.com": 3})
+
+	r := httptest.NewRequest("GET", "https://api.datamigrate.ai/api/v1/site", nil)
+	r.Header.Set("Origin", "https://app.contoso.com")
+	if domain, orgID, ok := RequestDomain(r); !ok || domain != "app.contoso.com" || orgID != 3 {
+		t.Errorf("RequestDomain by Origin = %q, %d, %v", domain, orgID, ok)
+	}
+	if got := RequestBaseURL(r); got != "https://app.contoso.com" {
+		t.Errorf("RequestBaseURL = %q", got)
+	}
+
+	// The Origin decides when there is one, so another site can't borrow the Host
+	r.Header.Set("Origin", "https://evil.com")
+	r.Host = "app.contoso.com"
+	if _, _, ok := RequestDomain(r); ok {
+		t.Error("RequestDomain matched the Host of a cross-origin request")
+	}
+
+	r.Header.Del("Origin")
+	if _, orgID, ok := RequestDomain(r); !ok || orgID != 3 {
+		t.Errorf("RequestDomain by Host = %d, %v", orgID, ok)
+	}
+	if got := BaseURL(3); got != "https://app.contoso.com" {
+		t.Errorf("BaseURL = %q", got)
+	}
+	if got := BaseURL(4); got != "" {
+		t.Errorf("BaseURL without a domain = %q", got)
+	}
+}
+
+func TestHostPolicy(t *testing.T) {
+	useDomains(t, nil, map[string]int64{"app.contoso.com": 3})
+	policy := HostPolicy([]string{"api.datamigrate.ai"})
+
+	for host, allowed := range map[string]bool{
+		"api.datamigrate.ai": true,
+		"app.contoso.com":    true,
+		"app.fabrikam.com":   false,
+	} {
+		if err := policy(context.Background(), host); (err == nil) != allowed {
+			t.Errorf("HostPolicy(%q) = %v, want allowed %v", host, err, allowed)
+		}
+	}
+}
+
+func TestCheckVerification(t *testing.T) {
+	saved := lookupTXT
+	t.Cleanup(func() { lookupTXT = saved })
+
+	var looked string
+	lookupTXT = func(_ context.Context, name string) ([]string, error) {
+		looked = name
+		return []string{"v=spf1 -all", " token-1 "}, nil
+	}
+	if err := CheckVerification(context.Background(), "app.contoso.com", "token-1"); err != nil {
+		t.Errorf("CheckVerification = %v", err)
+	}
+	if looked != "_datamigrate-verification.app.contoso.com" {
+		t.Errorf("looked up %q", looked)
+	}
+	if err := CheckVerification(context.Background(), "app.contoso.com", "token-2"); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("CheckVerification with another token = %v", err)
+	}
+
+	lookupTXT = func(context.Context, string) ([]string, error) {
+		return nil, &net.DNSError{Err: "no such host", IsNotFound: true}
+	}
+	if err := CheckVerification(context.Background(), "app.contoso.com", "token-1"); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("CheckVerification without a record = %v", err)
+	}
+}
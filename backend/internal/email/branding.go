@@ -0,0 +1,69 @@
+package email
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var brandColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Branding is the look of the emails sent on an organization's behalf. Colors that
+// aren't #rrggbb are ignored.
+type Branding struct {
+	Name         string // Shown in place of DataMigrate AI when there's no logo
+	LogoURL      string
+	PrimaryColor string
+	AccentColor  string
+}
+
+// IsBrandColor reports whether color is a #rrggbb brand color
+func IsBrandColor(color string) bool {
+	return brandColorRegex.MatchString(color)
+}
+
+// PublicURL is the absolute URL of a path on the app's public origin, for links and
+// images in emails and generated files
+func PublicURL(path string) string {
+	return strings.TrimRight(getEnv("FRONTEND_URL", "http://localhost:5173"), "/") + path
+}
+
+// WithBranding returns a copy of the service whose notification emails use an
+// organization's logo and colors; nil keeps the DataMigrate AI look
+func (s *Service) WithBranding(b *Branding) *Service {
+	branded := *s
+	branded.branding = b
+	return &branded
+}
+
+// WithFrontendURL returns a copy of the service whose links point at another frontend,
+// such as an organization's custom domain; "" keeps FRONTEND_URL
+func (s *Service) WithFrontendURL(url string) *Service {
+	if url == "" {
+		return s
+	}
+	redirected := *s
+	redirected.config.FrontendURL = url
+	return &redirected
+}
+
+// brandGradient is the banner background: the brand's primary to accent color, or the
+// template's own colors without branding. A brand with one color uses it throughout.
+func brandGradient(b *Branding, from, to string) template.CSS {
+	if b != nil && IsBrandColor(b.PrimaryColor) {
+		from, to = b.PrimaryColor, b.PrimaryColor
+		if IsBrandColor(b.AccentColor) {
+			to = b.AccentColor
+		}
+	}
+	return template.CSS("linear-gradient(135deg, " + from + " 0%, " + to + " 100%)")
+}
+
+// brandHeader renders a banner's title: the organization's logo or name, or
+// DataMigrate AI without branding
+const brandHeader = `{{define "brandHeader"}}
+        {{- if and . .LogoURL}}<img src="{{.LogoURL}}" alt="{{.Name}}" style="max-height: 48px; max-width: 240px;">
+        {{- else if and . .Name}}<h1 style="color: white; margin: 0; font-size: 28px;">{{.Name}}</h1>
+        {{- else}}<h1 style="color: white; margin: 0; font-size: 28px;">DataMigrate AI</h1>
+        {{- end}}
+{{- end}}`
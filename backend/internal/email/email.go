@@ -2,11 +2,18 @@ package email
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"html/template"
+	"net"
 	"net/smtp"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
 )
 
 // Config holds email service configuration
@@ -18,11 +25,13 @@ type Config struct {
 	FromEmail    string
 	FromName     string
 	FrontendURL  string
+	Timeout      time.Duration // Limit on one SMTP delivery, from connecting to QUIT
 }
 
 // Service handles email sending
 type Service struct {
-	config Config
+	config   Config
+	branding *Branding // Organization look of notification emails, see WithBranding
 }
 
 // NewService creates a new email service
@@ -36,10 +45,18 @@ func NewService() *Service {
 			FromEmail:    getEnv("SMTP_FROM_EMAIL", "noreply@datamigrate.ai"),
 			FromName:     getEnv("SMTP_FROM_NAME", "DataMigrate AI"),
 			FrontendURL:  getEnv("FRONTEND_URL", "http://localhost:5173"),
+			Timeout:      time.Duration(getEnvInt("SMTP_TIMEOUT_SECONDS", 30)) * time.Second,
 		},
 	}
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -52,15 +69,21 @@ func (s *Service) IsConfigured() bool {
 	return s.config.SMTPUser != "" && s.config.SMTPPassword != ""
 }
 
-// SendEmail sends an email using SMTP
+// SendEmail sends an email immediately using SMTP, giving up after SMTP_TIMEOUT_SECONDS.
+// The Send*Email helpers go through the outbox instead, which retries failed deliveries.
 func (s *Service) SendEmail(to, subject, htmlBody, textBody string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	return s.SendEmailContext(ctx, to, subject, htmlBody, textBody)
+}
+
+// SendEmailContext sends an email immediately using SMTP. The whole exchange is
+// abandoned when ctx is done, so a slow or unresponsive server can't hold up the caller.
+func (s *Service) SendEmailContext(ctx context.Context, to, subject, htmlBody, textBody string) error {
 	if !s.IsConfigured() {
 		return fmt.Errorf("email service is not configured - please set SMTP_USER and SMTP_PASSWORD environment variables")
 	}
 
-	// Setup authentication
-	auth := smtp.PlainAuth("", s.config.SMTPUser, s.config.SMTPPassword, s.config.SMTPHost)
-
 	// Build email headers
 	headers := make(map[string]string)
 	headers["From"] = fmt.Sprintf("%s <%s>", s.config.FromName, s.config.FromEmail)
@@ -89,456 +112,714 @@ func (s *Service) SendEmail(to, subject, htmlBody, textBody string) error {
 	msg.WriteString("\r\n")
 	msg.WriteString("--boundary--")
 
-	// Send email
-	addr := fmt.Sprintf("%s:%s", s.config.SMTPHost, s.config.SMTPPort)
-	err := smtp.SendMail(addr, auth, s.config.FromEmail, []string{to}, msg.Bytes())
-	if err != nil {
+	if err := s.sendMail(ctx, to, msg.Bytes()); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
 	return nil
 }
 
-// SendPasswordResetEmail sends a password reset email
-func (s *Service) SendPasswordResetEmail(to, firstName, resetToken string) error {
+// sendMail does what smtp.SendMail does (STARTTLS when offered, PLAIN auth) on a
+// connection bounded by ctx
+func (s *Service) sendMail(ctx context.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(s.config.SMTPHost, s.config.SMTPPort)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Unblock reads and writes if ctx is cancelled before the deadline
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, s.config.SMTPHost)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.config.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	auth := smtp.PlainAuth("", s.config.SMTPUser, s.config.SMTPPassword, s.config.SMTPHost)
+	if ok, _ := client.Extension("AUTH"); ok {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.config.FromEmail); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// deliver queues an email in the outbox so it's sent, and retried, by the worker rather
+// than on the caller's request. Without a database it falls back to sending immediately.
+func (s *Service) deliver(to, subject, htmlBody, textBody string) error {
+	if db.DB == nil {
+		return s.SendEmail(to, subject, htmlBody, textBody)
+	}
+	_, err := GetOutbox().Enqueue(to, subject, htmlBody, textBody)
+	return err
+}
+
+// SendPasswordResetEmail sends a password reset email in the user's preferred language
+func (s *Service) SendPasswordResetEmail(to, firstName, resetToken, lang string) error {
 	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.config.FrontendURL, resetToken)
 
-	htmlBody := s.getPasswordResetHTML(firstName, resetURL)
-	textBody := s.getPasswordResetText(firstName, resetURL)
+	htmlBody := s.getPasswordResetHTML(lang, firstName, resetURL)
+	textBody := s.getPasswordResetText(lang, firstName, resetURL)
 
-	return s.SendEmail(to, "Reset Your DataMigrate AI Password", htmlBody, textBody)
+	return s.deliver(to, messagesFor(lang).ResetSubject, htmlBody, textBody)
 }
 
 // SendWelcomeEmail sends a welcome email to new users
-func (s *Service) SendWelcomeEmail(to, firstName, organizationName string) error {
+func (s *Service) SendWelcomeEmail(to, firstName, organizationName, lang string) error {
 	loginURL := fmt.Sprintf("%s/login", s.config.FrontendURL)
 
-	htmlBody := s.getWelcomeHTML(firstName, organizationName, loginURL)
-	textBody := s.getWelcomeText(firstName, organizationName, loginURL)
+	htmlBody := s.getWelcomeHTML(lang, firstName, organizationName, loginURL)
+	textBody := s.getWelcomeText(lang, firstName, organizationName, loginURL)
 
-	return s.SendEmail(to, "Welcome to DataMigrate AI!", htmlBody, textBody)
+	return s.deliver(to, messagesFor(lang).WelcomeSubject, htmlBody, textBody)
 }
 
 // SendInvitationEmail sends an organization invitation email
-func (s *Service) SendInvitationEmail(to, inviterName, organizationName, inviteToken string) error {
+func (s *Service) SendInvitationEmail(to, inviterName, organizationName, inviteToken, lang string) error {
 	inviteURL := fmt.Sprintf("%s/accept-invite?token=%s", s.config.FrontendURL, inviteToken)
 
-	htmlBody := s.getInvitationHTML(inviterName, organizationName, inviteURL)
-	textBody := s.getInvitationText(inviterName, organizationName, inviteURL)
+	htmlBody := s.getInvitationHTML(lang, inviterName, organizationName, inviteURL)
+	textBody := s.getInvitationText(lang, inviterName, organizationName, inviteURL)
+
+	return s.deliver(to, fmt.Sprintf(messagesFor(lang).InviteSubject, organizationName), htmlBody, textBody)
+}
+
+// SendOwnershipTransferEmail asks one party of an organization ownership transfer to
+// confirm it. incoming is true for the member becoming owner; otherName is the other party.
+func (s *Service) SendOwnershipTransferEmail(to, otherName, organizationName, confirmToken string, incoming bool, lang string) error {
+	confirmURL := fmt.Sprintf("%s/confirm-ownership-transfer?token=%s", s.config.FrontendURL, confirmToken)
+
+	htmlBody := s.getOwnershipTransferHTML(lang, otherName, organizationName, confirmURL, incoming)
+	textBody := s.getOwnershipTransferText(lang, otherName, organizationName, confirmURL, incoming)
+
+	return s.deliver(to, fmt.Sprintf(messagesFor(lang).TransferSubject, organizationName), htmlBody, textBody)
+}
+
+// LoginDetails describe a sign-in for the new sign-in notification
+type LoginDetails struct {
+	Device     string // e.g. "Chrome on Windows"
+	Location   string // Country code, if known
+	IPAddress  string
+	Time       string // Formatted for the recipient (see FormatTime)
+	NewCountry bool   // The country is new rather than the device
+}
+
+// SendNewLoginEmail tells a user about a sign-in from a new device or country, with a
+// link that locks the account if it wasn't them
+func (s *Service) SendNewLoginEmail(to, firstName string, login LoginDetails, reportToken, lang string) error {
+	reportURL := fmt.Sprintf("%s/report-login?token=%s", s.config.FrontendURL, reportToken)
+
+	htmlBody := s.getNewLoginHTML(lang, firstName, login, reportURL)
+	textBody := s.getNewLoginText(lang, firstName, login, reportURL)
 
-	return s.SendEmail(to, fmt.Sprintf("You've been invited to join %s on DataMigrate AI", organizationName), htmlBody, textBody)
+	return s.deliver(to, messagesFor(lang).NewLoginSubject, htmlBody, textBody)
 }
 
-// SendMigrationCompleteEmail sends a notification when a migration completes successfully
-func (s *Service) SendMigrationCompleteEmail(to, firstName, migrationName string, tableCount int, duration string) error {
+// SendMigrationCompleteEmail sends a notification when a migration completes successfully.
+// duration and completedAt are formatted for the recipient (see FormatDuration and
+// FormatTime).
+func (s *Service) SendMigrationCompleteEmail(to, firstName, migrationName string, tableCount int, duration, completedAt, lang string) error {
 	dashboardURL := fmt.Sprintf("%s/migrations", s.config.FrontendURL)
 
-	htmlBody := s.getMigrationCompleteHTML(firstName, migrationName, tableCount, duration, dashboardURL)
-	textBody := s.getMigrationCompleteText(firstName, migrationName, tableCount, duration, dashboardURL)
+	htmlBody := s.getMigrationCompleteHTML(lang, firstName, migrationName, tableCount, duration, completedAt, dashboardURL)
+	textBody := s.getMigrationCompleteText(lang, firstName, migrationName, tableCount, duration, completedAt, dashboardURL)
 
-	return s.SendEmail(to, fmt.Sprintf("Migration Complete: %s", migrationName), htmlBody, textBody)
+	return s.deliver(to, fmt.Sprintf(messagesFor(lang).CompleteSubject, migrationName), htmlBody, textBody)
 }
 
 // SendMigrationFailedEmail sends a notification when a migration fails
-func (s *Service) SendMigrationFailedEmail(to, firstName, migrationName, errorMessage string) error {
+func (s *Service) SendMigrationFailedEmail(to, firstName, migrationName, errorMessage, lang string) error {
 	dashboardURL := fmt.Sprintf("%s/migrations", s.config.FrontendURL)
 
-	htmlBody := s.getMigrationFailedHTML(firstName, migrationName, errorMessage, dashboardURL)
-	textBody := s.getMigrationFailedText(firstName, migrationName, errorMessage, dashboardURL)
+	htmlBody := s.getMigrationFailedHTML(lang, firstName, migrationName, errorMessage, dashboardURL)
+	textBody := s.getMigrationFailedText(lang, firstName, migrationName, errorMessage, dashboardURL)
 
-	return s.SendEmail(to, fmt.Sprintf("Migration Failed: %s", migrationName), htmlBody, textBody)
+	return s.deliver(to, fmt.Sprintf(messagesFor(lang).FailedSubject, migrationName), htmlBody, textBody)
 }
 
 // Email templates
+//
+// Templates hold the layout only; all copy comes from the language's messages (.T).
 
-func (s *Service) getPasswordResetHTML(firstName, resetURL string) string {
+func (s *Service) getPasswordResetHTML(lang, firstName, resetURL string) string {
 	tmpl := `
 <!DOCTYPE html>
-<html>
+<html lang="{{.Lang}}">
 <head>
     <meta charset="utf-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Reset Your Password</title>
+    <title>{{.T.ResetTitle}}</title>
 </head>
 <body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
     <div style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); padding: 30px; text-align: center; border-radius: 10px 10px 0 0;">
         <h1 style="color: white; margin: 0; font-size: 28px;">DataMigrate AI</h1>
     </div>
     <div style="background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; border-radius: 0 0 10px 10px;">
-        <h2 style="color: #333; margin-top: 0;">Reset Your Password</h2>
-        <p>Hi {{.FirstName}},</p>
-        <p>We received a request to reset your password for your DataMigrate AI account. Click the button below to create a new password:</p>
+        <h2 style="color: #333; margin-top: 0;">{{.T.ResetTitle}}</h2>
+        <p>{{printf .T.Greeting .FirstName}}</p>
+        <p>{{.T.ResetIntro}}</p>
         <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.ResetURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">Reset Password</a>
+            <a href="{{.ResetURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">{{.T.ResetButton}}</a>
         </div>
-        <p style="color: #666; font-size: 14px;">This link will expire in 1 hour for security reasons.</p>
-        <p style="color: #666; font-size: 14px;">If you didn't request a password reset, you can safely ignore this email. Your password will not be changed.</p>
+        <p style="color: #666; font-size: 14px;">{{.T.ResetExpiry}}</p>
+        <p style="color: #666; font-size: 14px;">{{.T.ResetIgnore}}</p>
         <hr style="border: none; border-top: 1px solid #e0e0e0; margin: 30px 0;">
         <p style="color: #999; font-size: 12px; text-align: center;">
-            DataMigrate AI - MSSQL to dbt Migration Platform<br>
-            This is an automated message, please do not reply.
+            {{.T.Tagline}}<br>
+            {{.T.AutomatedMessage}}
         </p>
     </div>
 </body>
 </html>
 `
-	data := map[string]string{
+	data := map[string]interface{}{
 		"FirstName": firstName,
 		"ResetURL":  resetURL,
 	}
-	return executeTemplate(tmpl, data)
+	return executeTemplate(tmpl, lang, data)
 }
 
-func (s *Service) getPasswordResetText(firstName, resetURL string) string {
-	return fmt.Sprintf(`Hi %s,
+func (s *Service) getPasswordResetText(lang, firstName, resetURL string) string {
+	m := messagesFor(lang)
+	return fmt.Sprintf(`%s
 
-We received a request to reset your password for your DataMigrate AI account.
+%s
 
-Click this link to reset your password:
+%s
 %s
 
-This link will expire in 1 hour for security reasons.
+%s
 
-If you didn't request a password reset, you can safely ignore this email. Your password will not be changed.
+%s
 
 --
-DataMigrate AI - MSSQL to dbt Migration Platform
-`, firstName, resetURL)
+%s
+`, fmt.Sprintf(m.Greeting, firstName), m.ResetIntro, m.ResetLinkText, resetURL, m.ResetExpiry, m.ResetIgnore, m.Tagline)
 }
 
-func (s *Service) getWelcomeHTML(firstName, organizationName, loginURL string) string {
+func (s *Service) getWelcomeHTML(lang, firstName, organizationName, loginURL string) string {
 	dashboardURL := strings.Replace(loginURL, "/login", "/dashboard", 1)
 	tmpl := `
 <!DOCTYPE html>
-<html>
+<html lang="{{.Lang}}">
 <head>
     <meta charset="utf-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Welcome to DataMigrate AI</title>
+    <title>{{.T.WelcomeTitle}}</title>
 </head>
 <body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; background-color: #f5f5f5;">
     <div style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); padding: 40px 30px; text-align: center; border-radius: 10px 10px 0 0;">
-        <h1 style="color: white; margin: 0; font-size: 32px;">Welcome to DataMigrate AI!</h1>
-        <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0 0; font-size: 16px;">Your AI-Powered Migration Journey Starts Now</p>
+        <h1 style="color: white; margin: 0; font-size: 32px;">{{.T.WelcomeTitle}}</h1>
+        <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0 0; font-size: 16px;">{{.T.WelcomeTagline}}</p>
     </div>
     <div style="background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none;">
-        <h2 style="color: #333; margin-top: 0;">Hi {{.FirstName}}! 🎉</h2>
-        <p>Thank you for joining DataMigrate AI! Your account for <strong>{{.OrganizationName}}</strong> is ready.</p>
+        <h2 style="color: #333; margin-top: 0;">{{printf .T.WelcomeGreeting .FirstName}} 🎉</h2>
+        <p>{{bold .T.WelcomeReady .OrganizationName}}</p>
 
         <div style="background: linear-gradient(135deg, #f5f7fa 0%, #e4e8eb 100%); padding: 20px; border-radius: 8px; margin: 20px 0;">
-            <h3 style="color: #667eea; margin-top: 0;">🚀 Why Teams Choose Us</h3>
+            <h3 style="color: #667eea; margin-top: 0;">🚀 {{.T.WelcomeWhyTitle}}</h3>
             <ul style="margin: 0; padding-left: 20px;">
-                <li><strong>90% Faster Migrations</strong> - What takes weeks now takes hours</li>
-                <li><strong>AI-Powered Accuracy</strong> - 8 specialized agents handle complex transformations</li>
-                <li><strong>Enterprise Security</strong> - Your data never leaves your infrastructure</li>
-                <li><strong>Multi-Warehouse Support</strong> - Deploy to Snowflake, Databricks, Fabric & more</li>
+                {{- range .T.WelcomeBenefits}}
+                <li><strong>{{.Title}}</strong> - {{.Text}}</li>
+                {{- end}}
             </ul>
         </div>
 
-        <h3 style="color: #667eea;">📋 Get Started in 3 Steps:</h3>
+        <h3 style="color: #667eea;">📋 {{.T.WelcomeStepsTitle}}</h3>
         <div style="margin: 15px 0;">
+            {{- range $i, $step := .T.WelcomeSteps}}
             <div style="display: flex; align-items: center; margin-bottom: 12px;">
-                <span style="background: #667eea; color: white; width: 28px; height: 28px; border-radius: 50%; display: inline-flex; align-items: center; justify-content: center; font-weight: bold; margin-right: 12px;">1</span>
-                <span><strong>Connect</strong> - Add your MSSQL database connection</span>
-            </div>
-            <div style="display: flex; align-items: center; margin-bottom: 12px;">
-                <span style="background: #667eea; color: white; width: 28px; height: 28px; border-radius: 50%; display: inline-flex; align-items: center; justify-content: center; font-weight: bold; margin-right: 12px;">2</span>
-                <span><strong>Select</strong> - Choose tables and views to migrate</span>
-            </div>
-            <div style="display: flex; align-items: center; margin-bottom: 12px;">
-                <span style="background: #667eea; color: white; width: 28px; height: 28px; border-radius: 50%; display: inline-flex; align-items: center; justify-content: center; font-weight: bold; margin-right: 12px;">3</span>
-                <span><strong>Deploy</strong> - One-click deployment to your data warehouse</span>
+                <span style="background: #667eea; color: white; width: 28px; height: 28px; border-radius: 50%; display: inline-flex; align-items: center; justify-content: center; font-weight: bold; margin-right: 12px;">{{inc $i}}</span>
+                <span><strong>{{$step.Title}}</strong> - {{$step.Text}}</span>
             </div>
+            {{- end}}
         </div>
 
         <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.DashboardURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 16px 40px; text-decoration: none; border-radius: 8px; font-weight: bold; display: inline-block; font-size: 16px; box-shadow: 0 4px 15px rgba(102, 126, 234, 0.4);">Start Your First Migration</a>
+            <a href="{{.DashboardURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 16px 40px; text-decoration: none; border-radius: 8px; font-weight: bold; display: inline-block; font-size: 16px; box-shadow: 0 4px 15px rgba(102, 126, 234, 0.4);">{{.T.WelcomeButton}}</a>
         </div>
 
         <div style="background: #fff3cd; border: 1px solid #ffc107; padding: 15px; border-radius: 8px; margin: 20px 0;">
-            <p style="margin: 0; color: #856404;"><strong>💡 Pro Tip:</strong> Start with a small table to see the magic! Our AI will analyze your schema, detect relationships, and generate production-ready dbt models.</p>
+            <p style="margin: 0; color: #856404;"><strong>💡 {{.T.WelcomeProTipLabel}}</strong> {{.T.WelcomeProTip}}</p>
         </div>
     </div>
     <div style="background: #f8f9fa; padding: 20px 30px; border: 1px solid #e0e0e0; border-top: none; border-radius: 0 0 10px 10px;">
         <p style="color: #666; font-size: 13px; margin: 0 0 10px 0; text-align: center;">
-            Need help? Our AI support assistant is available 24/7 in the app.
+            {{.T.WelcomeHelp}}
         </p>
         <p style="color: #999; font-size: 12px; text-align: center; margin: 0;">
-            DataMigrate AI - MSSQL to dbt Migration Platform<br>
+            {{.T.Tagline}}<br>
             © 2025 OKO Investments. All rights reserved.
         </p>
     </div>
 </body>
 </html>
 `
-	data := map[string]string{
+	data := map[string]interface{}{
 		"FirstName":        firstName,
 		"OrganizationName": organizationName,
 		"LoginURL":         loginURL,
 		"DashboardURL":     dashboardURL,
 	}
-	return executeTemplate(tmpl, data)
+	return executeTemplate(tmpl, lang, data)
 }
 
-func (s *Service) getWelcomeText(firstName, organizationName, loginURL string) string {
+func (s *Service) getWelcomeText(lang, firstName, organizationName, loginURL string) string {
 	dashboardURL := strings.Replace(loginURL, "/login", "/dashboard", 1)
-	return fmt.Sprintf(`Hi %s!
+	m := messagesFor(lang)
 
-Welcome to DataMigrate AI! 🎉
+	var benefits, steps strings.Builder
+	for _, item := range m.WelcomeBenefits {
+		fmt.Fprintf(&benefits, "• %s - %s\n", item.Title, item.Text)
+	}
+	for i, item := range m.WelcomeSteps {
+		fmt.Fprintf(&steps, "%d. %s - %s\n", i+1, item.Title, item.Text)
+	}
 
-Thank you for joining us! Your account for %s is ready.
+	return fmt.Sprintf(`%s
 
-WHY TEAMS CHOOSE US:
-• 90%% Faster Migrations - What takes weeks now takes hours
-• AI-Powered Accuracy - 8 specialized agents handle complex transformations
-• Enterprise Security - Your data never leaves your infrastructure
-• Multi-Warehouse Support - Deploy to Snowflake, Databricks, Fabric & more
+%s 🎉
 
-GET STARTED IN 3 STEPS:
-1. Connect - Add your MSSQL database connection
-2. Select - Choose tables and views to migrate
-3. Deploy - One-click deployment to your data warehouse
+%s
 
-Start your first migration: %s
+%s:
+%s
+%s
+%s
+%s %s
 
-Pro Tip: Start with a small table to see the magic! Our AI will analyze your schema, detect relationships, and generate production-ready dbt models.
+%s %s
 
-Need help? Our AI support assistant is available 24/7 in the app.
+%s
 
 --
-DataMigrate AI - MSSQL to dbt Migration Platform
+%s
 © 2025 OKO Investments. All rights reserved.
-`, firstName, organizationName, dashboardURL)
+`, fmt.Sprintf(m.WelcomeGreeting, firstName), m.WelcomeTitle, fmt.Sprintf(m.WelcomeReady, organizationName),
+		strings.ToUpper(m.WelcomeWhyTitle), benefits.String(), strings.ToUpper(m.WelcomeStepsTitle), steps.String(),
+		m.WelcomeLinkText, dashboardURL, m.WelcomeProTipLabel, m.WelcomeProTip, m.WelcomeHelp, m.Tagline)
 }
 
-func (s *Service) getInvitationHTML(inviterName, organizationName, inviteURL string) string {
+func (s *Service) getInvitationHTML(lang, inviterName, organizationName, inviteURL string) string {
 	tmpl := `
 <!DOCTYPE html>
-<html>
+<html lang="{{.Lang}}">
 <head>
     <meta charset="utf-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>You're Invited!</title>
+    <title>{{.T.InviteTitle}}</title>
 </head>
 <body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
     <div style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); padding: 30px; text-align: center; border-radius: 10px 10px 0 0;">
         <h1 style="color: white; margin: 0; font-size: 28px;">DataMigrate AI</h1>
     </div>
     <div style="background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; border-radius: 0 0 10px 10px;">
-        <h2 style="color: #333; margin-top: 0;">You've Been Invited!</h2>
-        <p><strong>{{.InviterName}}</strong> has invited you to join <strong>{{.OrganizationName}}</strong> on DataMigrate AI.</p>
-        <p>DataMigrate AI helps teams migrate MSSQL databases to modern dbt projects with AI-powered transformation.</p>
+        <h2 style="color: #333; margin-top: 0;">{{.T.InviteTitle}}</h2>
+        <p>{{bold .T.InviteIntro .InviterName .OrganizationName}}</p>
+        <p>{{.T.InviteAbout}}</p>
         <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.InviteURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">Accept Invitation</a>
+            <a href="{{.InviteURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">{{.T.InviteButton}}</a>
         </div>
-        <p style="color: #666; font-size: 14px;">This invitation will expire in 7 days.</p>
+        <p style="color: #666; font-size: 14px;">{{.T.InviteExpiry}}</p>
         <hr style="border: none; border-top: 1px solid #e0e0e0; margin: 30px 0;">
         <p style="color: #999; font-size: 12px; text-align: center;">
-            DataMigrate AI - MSSQL to dbt Migration Platform<br>
-            This is an automated message, please do not reply.
+            {{.T.Tagline}}<br>
+            {{.T.AutomatedMessage}}
         </p>
     </div>
 </body>
 </html>
 `
-	data := map[string]string{
+	data := map[string]interface{}{
 		"InviterName":      inviterName,
 		"OrganizationName": organizationName,
 		"InviteURL":        inviteURL,
 	}
-	return executeTemplate(tmpl, data)
+	return executeTemplate(tmpl, lang, data)
 }
 
-func (s *Service) getInvitationText(inviterName, organizationName, inviteURL string) string {
-	return fmt.Sprintf(`You've Been Invited!
+func (s *Service) getInvitationText(lang, inviterName, organizationName, inviteURL string) string {
+	m := messagesFor(lang)
+	return fmt.Sprintf(`%s
 
-%s has invited you to join %s on DataMigrate AI.
+%s
 
-DataMigrate AI helps teams migrate MSSQL databases to modern dbt projects with AI-powered transformation.
+%s
 
-Accept your invitation: %s
+%s %s
 
-This invitation will expire in 7 days.
+%s
 
 --
-DataMigrate AI - MSSQL to dbt Migration Platform
-`, inviterName, organizationName, inviteURL)
+%s
+`, m.InviteTitle, fmt.Sprintf(m.InviteIntro, inviterName, organizationName), m.InviteAbout,
+		m.InviteLinkText, inviteURL, m.InviteExpiry, m.Tagline)
 }
 
-func (s *Service) getMigrationCompleteHTML(firstName, migrationName string, tableCount int, duration, dashboardURL string) string {
+func (s *Service) getOwnershipTransferHTML(lang, otherName, organizationName, confirmURL string, incoming bool) string {
 	tmpl := `
 <!DOCTYPE html>
-<html>
+<html lang="{{.Lang}}">
 <head>
     <meta charset="utf-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Migration Complete</title>
+    <title>{{.T.TransferTitle}}</title>
 </head>
 <body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-    <div style="background: linear-gradient(135deg, #10B981 0%, #059669 100%); padding: 30px; text-align: center; border-radius: 10px 10px 0 0;">
+    <div style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); padding: 30px; text-align: center; border-radius: 10px 10px 0 0;">
         <h1 style="color: white; margin: 0; font-size: 28px;">DataMigrate AI</h1>
-        <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0 0; font-size: 16px;">Migration Successful!</p>
     </div>
     <div style="background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; border-radius: 0 0 10px 10px;">
-        <h2 style="color: #333; margin-top: 0;">Great News, {{.FirstName}}!</h2>
-        <p>Your migration <strong>"{{.MigrationName}}"</strong> has completed successfully.</p>
+        <h2 style="color: #333; margin-top: 0;">{{.T.TransferTitle}}</h2>
+        <p>{{if .Incoming}}{{bold .T.TransferIncoming .OtherName .OrganizationName}}{{else}}{{bold .T.TransferOutgoing .OrganizationName .OtherName}}{{end}}</p>
+        <p>{{.T.TransferAbout}}</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="{{.ConfirmURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">{{.T.TransferButton}}</a>
+        </div>
+        <p style="color: #666; font-size: 14px;">{{.T.TransferExpiry}}</p>
+        <hr style="border: none; border-top: 1px solid #e0e0e0; margin: 30px 0;">
+        <p style="color: #999; font-size: 12px; text-align: center;">
+            {{.T.Tagline}}<br>
+            {{.T.AutomatedMessage}}
+        </p>
+    </div>
+</body>
+</html>
+`
+	data := map[string]interface{}{
+		"OtherName":        otherName,
+		"OrganizationName": organizationName,
+		"ConfirmURL":       confirmURL,
+		"Incoming":         incoming,
+	}
+	return executeTemplate(tmpl, lang, data)
+}
+
+func (s *Service) getOwnershipTransferText(lang, otherName, organizationName, confirmURL string, incoming bool) string {
+	m := messagesFor(lang)
+	intro := fmt.Sprintf(m.TransferOutgoing, organizationName, otherName)
+	if incoming {
+		intro = fmt.Sprintf(m.TransferIncoming, otherName, organizationName)
+	}
+	return fmt.Sprintf(`%s
+
+%s
+
+%s
+
+%s %s
+
+%s
+
+--
+%s
+`, m.TransferTitle, intro, m.TransferAbout, m.TransferLinkText, confirmURL, m.TransferExpiry, m.Tagline)
+}
+
+func (s *Service) getNewLoginHTML(lang, firstName string, login LoginDetails, reportURL string) string {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.T.NewLoginTitle}}</title>
+</head>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); padding: 30px; text-align: center; border-radius: 10px 10px 0 0;">
+        <h1 style="color: white; margin: 0; font-size: 28px;">DataMigrate AI</h1>
+    </div>
+    <div style="background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; border-radius: 0 0 10px 10px;">
+        <h2 style="color: #333; margin-top: 0;">{{.T.NewLoginTitle}}</h2>
+        <p>{{printf .T.Greeting .FirstName}}</p>
+        <p>{{if .Login.NewCountry}}{{.T.NewLoginCountry}}{{else}}{{.T.NewLoginDevice}}{{end}}</p>
+        <table style="width: 100%; border-collapse: collapse; margin: 20px 0;">
+            <tr><td style="padding: 6px 0; color: #666;">{{.T.NewLoginDeviceLabel}}</td><td style="padding: 6px 0;"><strong>{{.Login.Device}}</strong></td></tr>
+            {{if .Login.Location}}<tr><td style="padding: 6px 0; color: #666;">{{.T.NewLoginLocation}}</td><td style="padding: 6px 0;"><strong>{{.Login.Location}}</strong></td></tr>{{end}}
+            <tr><td style="padding: 6px 0; color: #666;">{{.T.NewLoginIPAddress}}</td><td style="padding: 6px 0;"><strong>{{.Login.IPAddress}}</strong></td></tr>
+            <tr><td style="padding: 6px 0; color: #666;">{{.T.NewLoginTime}}</td><td style="padding: 6px 0;"><strong>{{.Login.Time}}</strong></td></tr>
+        </table>
+        <p>{{.T.NewLoginIfYou}}</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="{{.ReportURL}}" style="background: #dc3545; color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">{{.T.NewLoginButton}}</a>
+        </div>
+        <p style="color: #666; font-size: 14px;">{{.T.NewLoginLockInfo}}</p>
+        <hr style="border: none; border-top: 1px solid #e0e0e0; margin: 30px 0;">
+        <p style="color: #999; font-size: 12px; text-align: center;">
+            {{.T.Tagline}}<br>
+            {{.T.AutomatedMessage}}
+        </p>
+    </div>
+</body>
+</html>
+`
+	data := map[string]interface{}{
+		"FirstName": firstName,
+		"Login":     login,
+		"ReportURL": reportURL,
+	}
+	return executeTemplate(tmpl, lang, data)
+}
+
+func (s *Service) getNewLoginText(lang, firstName string, login LoginDetails, reportURL string) string {
+	m := messagesFor(lang)
+	intro := m.NewLoginDevice
+	if login.NewCountry {
+		intro = m.NewLoginCountry
+	}
+	details := fmt.Sprintf("%s: %s\n", m.NewLoginDeviceLabel, login.Device)
+	if login.Location != "" {
+		details += fmt.Sprintf("%s: %s\n", m.NewLoginLocation, login.Location)
+	}
+	details += fmt.Sprintf("%s: %s\n%s: %s", m.NewLoginIPAddress, login.IPAddress, m.NewLoginTime, login.Time)
+
+	return fmt.Sprintf(`%s
+
+%s
+
+%s
+
+%s
+
+%s
+%s
+
+%s
+
+--
+%s
+`, fmt.Sprintf(m.Greeting, firstName), intro, details, m.NewLoginIfYou, m.NewLoginLinkText, reportURL, m.NewLoginLockInfo, m.Tagline)
+}
+
+func (s *Service) getMigrationCompleteHTML(lang, firstName, migrationName string, tableCount int, duration, completedAt, dashboardURL string) string {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.T.CompleteTitle}}</title>
+</head>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background: {{brandGradient .Brand "#10B981" "#059669"}}; padding: 30px; text-align: center; border-radius: 10px 10px 0 0;">
+        {{template "brandHeader" .Brand}}
+        <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0 0; font-size: 16px;">{{.T.CompleteBanner}}</p>
+    </div>
+    <div style="background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; border-radius: 0 0 10px 10px;">
+        <h2 style="color: #333; margin-top: 0;">{{printf .T.CompleteHeading .FirstName}}</h2>
+        <p>{{bold .T.CompleteIntro .MigrationName}}</p>
 
         <div style="background: #f0fdf4; border: 1px solid #bbf7d0; border-radius: 8px; padding: 20px; margin: 20px 0;">
-            <h3 style="color: #166534; margin: 0 0 15px 0; font-size: 16px;">Migration Summary</h3>
+            <h3 style="color: #166534; margin: 0 0 15px 0; font-size: 16px;">{{.T.CompleteSummary}}</h3>
             <table style="width: 100%; font-size: 14px;">
                 <tr>
-                    <td style="padding: 8px 0; color: #666;">Tables Migrated:</td>
+                    <td style="padding: 8px 0; color: #666;">{{.T.CompleteTables}}:</td>
                     <td style="padding: 8px 0; text-align: right; font-weight: bold; color: #166534;">{{.TableCount}}</td>
                 </tr>
                 <tr>
-                    <td style="padding: 8px 0; color: #666;">Duration:</td>
+                    <td style="padding: 8px 0; color: #666;">{{.T.CompleteDuration}}:</td>
                     <td style="padding: 8px 0; text-align: right; font-weight: bold; color: #166534;">{{.Duration}}</td>
                 </tr>
                 <tr>
-                    <td style="padding: 8px 0; color: #666;">Status:</td>
-                    <td style="padding: 8px 0; text-align: right; font-weight: bold; color: #166534;">✓ Complete</td>
+                    <td style="padding: 8px 0; color: #666;">{{.T.CompleteFinished}}:</td>
+                    <td style="padding: 8px 0; text-align: right; font-weight: bold; color: #166534;">{{.CompletedAt}}</td>
+                </tr>
+                <tr>
+                    <td style="padding: 8px 0; color: #666;">{{.T.CompleteStatus}}:</td>
+                    <td style="padding: 8px 0; text-align: right; font-weight: bold; color: #166534;">✓ {{.T.CompleteStatusOK}}</td>
                 </tr>
             </table>
         </div>
 
-        <p>Your dbt models are ready for deployment. You can now:</p>
+        <p>{{.T.CompleteNextSteps}}</p>
         <ul style="color: #666; padding-left: 20px;">
-            <li>Review the generated dbt models</li>
-            <li>Download the project files</li>
-            <li>Deploy directly to your data warehouse</li>
+            {{- range .T.CompleteActions}}
+            <li>{{.}}</li>
+            {{- end}}
         </ul>
 
         <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.DashboardURL}}" style="background: linear-gradient(135deg, #10B981 0%, #059669 100%); color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">View Migration Details</a>
+            <a href="{{.DashboardURL}}" style="background: {{brandGradient .Brand "#10B981" "#059669"}}; color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">{{.T.DetailsButton}}</a>
         </div>
 
         <hr style="border: none; border-top: 1px solid #e0e0e0; margin: 30px 0;">
         <p style="color: #999; font-size: 12px; text-align: center;">
-            DataMigrate AI - MSSQL to dbt Migration Platform<br>
+            {{.T.Tagline}}<br>
             © 2025 OKO Investments. All rights reserved.
         </p>
     </div>
 </body>
 </html>
 `
-	data := map[string]string{
+	data := map[string]interface{}{
 		"FirstName":     firstName,
 		"MigrationName": migrationName,
-		"TableCount":    fmt.Sprintf("%d", tableCount),
+		"TableCount":    tableCount,
 		"Duration":      duration,
+		"CompletedAt":   completedAt,
+		"Brand":         s.branding,
 		"DashboardURL":  dashboardURL,
 	}
-	return executeTemplate(tmpl, data)
+	return executeTemplate(tmpl, lang, data)
 }
 
-func (s *Service) getMigrationCompleteText(firstName, migrationName string, tableCount int, duration, dashboardURL string) string {
-	return fmt.Sprintf(`Migration Complete!
+func (s *Service) getMigrationCompleteText(lang, firstName, migrationName string, tableCount int, duration, completedAt, dashboardURL string) string {
+	m := messagesFor(lang)
+	return fmt.Sprintf(`%s
 
-Hi %s,
+%s
 
-Great news! Your migration "%s" has completed successfully.
+%s
 
-MIGRATION SUMMARY:
-• Tables Migrated: %d
-• Duration: %s
-• Status: Complete
+%s:
+• %s: %d
+• %s: %s
+• %s: %s
+• %s: %s
 
-Your dbt models are ready for deployment. You can now:
-- Review the generated dbt models
-- Download the project files
-- Deploy directly to your data warehouse
+%s
+- %s
 
-View migration details: %s
+%s %s
 
 --
-DataMigrate AI - MSSQL to dbt Migration Platform
+%s
 © 2025 OKO Investments. All rights reserved.
-`, firstName, migrationName, tableCount, duration, dashboardURL)
+`, m.CompleteTitle, fmt.Sprintf(m.CompleteHeading, firstName), fmt.Sprintf(m.CompleteIntro, migrationName),
+		strings.ToUpper(m.CompleteSummary), m.CompleteTables, tableCount, m.CompleteDuration, duration,
+		m.CompleteFinished, completedAt,
+		m.CompleteStatus, m.CompleteStatusOK, m.CompleteNextSteps, strings.Join(m.CompleteActions, "\n- "),
+		m.DetailsLinkText, dashboardURL, m.Tagline)
 }
 
-func (s *Service) getMigrationFailedHTML(firstName, migrationName, errorMessage, dashboardURL string) string {
+func (s *Service) getMigrationFailedHTML(lang, firstName, migrationName, errorMessage, dashboardURL string) string {
 	tmpl := `
 <!DOCTYPE html>
-<html>
+<html lang="{{.Lang}}">
 <head>
     <meta charset="utf-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Migration Failed</title>
+    <title>{{.T.FailedBanner}}</title>
 </head>
 <body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
     <div style="background: linear-gradient(135deg, #EF4444 0%, #DC2626 100%); padding: 30px; text-align: center; border-radius: 10px 10px 0 0;">
-        <h1 style="color: white; margin: 0; font-size: 28px;">DataMigrate AI</h1>
-        <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0 0; font-size: 16px;">Migration Issue</p>
+        {{template "brandHeader" .Brand}}
+        <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0 0; font-size: 16px;">{{.T.FailedBanner}}</p>
     </div>
     <div style="background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; border-radius: 0 0 10px 10px;">
-        <h2 style="color: #333; margin-top: 0;">Hi {{.FirstName}},</h2>
-        <p>Unfortunately, your migration <strong>"{{.MigrationName}}"</strong> encountered an issue.</p>
+        <h2 style="color: #333; margin-top: 0;">{{printf .T.Greeting .FirstName}}</h2>
+        <p>{{bold .T.FailedIntro .MigrationName}}</p>
 
         <div style="background: #fef2f2; border: 1px solid #fecaca; border-radius: 8px; padding: 20px; margin: 20px 0;">
-            <h3 style="color: #991b1b; margin: 0 0 10px 0; font-size: 16px;">Error Details</h3>
+            <h3 style="color: #991b1b; margin: 0 0 10px 0; font-size: 16px;">{{.T.FailedDetails}}</h3>
             <p style="color: #7f1d1d; margin: 0; font-family: monospace; font-size: 13px; word-break: break-word;">{{.ErrorMessage}}</p>
         </div>
 
-        <p>Here's what you can do:</p>
+        <p>{{.T.FailedWhatNow}}</p>
         <ul style="color: #666; padding-left: 20px;">
-            <li>Check your database connection settings</li>
-            <li>Verify table permissions and access</li>
-            <li>Review the error message above</li>
-            <li>Try running the migration again</li>
+            {{- range .T.FailedActions}}
+            <li>{{.}}</li>
+            {{- end}}
         </ul>
 
         <div style="text-align: center; margin: 30px 0;">
-            <a href="{{.DashboardURL}}" style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">View Migration Details</a>
+            <a href="{{.DashboardURL}}" style="background: {{brandGradient .Brand "#667eea" "#764ba2"}}; color: white; padding: 14px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; display: inline-block;">{{.T.DetailsButton}}</a>
         </div>
 
-        <p style="color: #666; font-size: 14px;">Need help? Contact our support team or use the AI assistant in the app.</p>
+        <p style="color: #666; font-size: 14px;">{{.T.HelpContact}}</p>
 
         <hr style="border: none; border-top: 1px solid #e0e0e0; margin: 30px 0;">
         <p style="color: #999; font-size: 12px; text-align: center;">
-            DataMigrate AI - MSSQL to dbt Migration Platform<br>
+            {{.T.Tagline}}<br>
             © 2025 OKO Investments. All rights reserved.
         </p>
     </div>
 </body>
 </html>
 `
-	data := map[string]string{
+	data := map[string]interface{}{
 		"FirstName":     firstName,
 		"MigrationName": migrationName,
 		"ErrorMessage":  errorMessage,
 		"DashboardURL":  dashboardURL,
+		"Brand":         s.branding,
 	}
-	return executeTemplate(tmpl, data)
+	return executeTemplate(tmpl, lang, data)
 }
 
-func (s *Service) getMigrationFailedText(firstName, migrationName, errorMessage, dashboardURL string) string {
-	return fmt.Sprintf(`Migration Issue
+func (s *Service) getMigrationFailedText(lang, firstName, migrationName, errorMessage, dashboardURL string) string {
+	m := messagesFor(lang)
+	return fmt.Sprintf(`%s
 
-Hi %s,
+%s
 
-Unfortunately, your migration "%s" encountered an issue.
+%s
 
-ERROR DETAILS:
+%s:
 %s
 
-Here's what you can do:
-- Check your database connection settings
-- Verify table permissions and access
-- Review the error message above
-- Try running the migration again
+%s
+- %s
 
-View migration details: %s
+%s %s
 
-Need help? Contact our support team or use the AI assistant in the app.
+%s
 
 --
-DataMigrate AI - MSSQL to dbt Migration Platform
+%s
 © 2025 OKO Investments. All rights reserved.
-`, firstName, migrationName, errorMessage, dashboardURL)
+`, m.FailedBanner, fmt.Sprintf(m.Greeting, firstName), fmt.Sprintf(m.FailedIntro, migrationName),
+		strings.ToUpper(m.FailedDetails), errorMessage, m.FailedWhatNow, strings.Join(m.FailedActions, "\n- "),
+		m.DetailsLinkText, dashboardURL, m.HelpContact, m.Tagline)
+}
+
+var templateFuncs = template.FuncMap{
+	// bold formats a translated string, escaping it and rendering its arguments in <strong>
+	"bold": func(format string, args ...string) template.HTML {
+		escaped := make([]interface{}, len(args))
+		for i, arg := range args {
+			escaped[i] = "<strong>" + template.HTMLEscapeString(arg) + "</strong>"
+		}
+		return template.HTML(fmt.Sprintf(template.HTMLEscapeString(format), escaped...))
+	},
+	"inc":           func(i int) int { return i + 1 },
+	"brandGradient": brandGradient,
 }
 
-func executeTemplate(tmplStr string, data map[string]string) string {
-	tmpl, err := template.New("email").Parse(tmplStr)
+// executeTemplate renders an HTML email with the messages for lang available as .T
+func executeTemplate(tmplStr, lang string, data map[string]interface{}) string {
+	data["T"] = messagesFor(lang)
+	data["Lang"] = NormalizeLanguage(lang)
+
+	tmpl, err := template.New("email").Funcs(templateFuncs).Parse(brandHeader + tmplStr)
 	if err != nil {
 		return tmplStr
 	}
@@ -576,28 +857,31 @@ func (s *MockService) SendEmail(to, subject, htmlBody, textBody string) error {
 	return nil
 }
 
-func (s *MockService) SendPasswordResetEmail(to, firstName, resetToken string) error {
+func (s *MockService) SendPasswordResetEmail(to, firstName, resetToken, lang string) error {
 	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.config.FrontendURL, resetToken)
 	fmt.Printf("\n=== MOCK PASSWORD RESET EMAIL ===\n")
 	fmt.Printf("To: %s\n", to)
+	fmt.Printf("Language: %s\n", NormalizeLanguage(lang))
 	fmt.Printf("Reset URL: %s\n", resetURL)
 	fmt.Printf("%s\n", strings.Repeat("=", 40))
 	return nil
 }
 
-func (s *MockService) SendWelcomeEmail(to, firstName, organizationName string) error {
+func (s *MockService) SendWelcomeEmail(to, firstName, organizationName, lang string) error {
 	fmt.Printf("\n=== MOCK WELCOME EMAIL ===\n")
 	fmt.Printf("To: %s\n", to)
+	fmt.Printf("Language: %s\n", NormalizeLanguage(lang))
 	fmt.Printf("Name: %s\n", firstName)
 	fmt.Printf("Organization: %s\n", organizationName)
 	fmt.Printf("%s\n", strings.Repeat("=", 40))
 	return nil
 }
 
-func (s *MockService) SendInvitationEmail(to, inviterName, organizationName, inviteToken string) error {
+func (s *MockService) SendInvitationEmail(to, inviterName, organizationName, inviteToken, lang string) error {
 	inviteURL := fmt.Sprintf("%s/accept-invite?token=%s", s.config.FrontendURL, inviteToken)
 	fmt.Printf("\n=== MOCK INVITATION EMAIL ===\n")
 	fmt.Printf("To: %s\n", to)
+	fmt.Printf("Language: %s\n", NormalizeLanguage(lang))
 	fmt.Printf("From: %s\n", inviterName)
 	fmt.Printf("Organization: %s\n", organizationName)
 	fmt.Printf("Invite URL: %s\n", inviteURL)
@@ -605,21 +889,51 @@ func (s *MockService) SendInvitationEmail(to, inviterName, organizationName, inv
 	return nil
 }
 
-func (s *MockService) SendMigrationCompleteEmail(to, firstName, migrationName string, tableCount int, duration string) error {
+func (s *MockService) SendOwnershipTransferEmail(to, otherName, organizationName, confirmToken string, incoming bool, lang string) error {
+	confirmURL := fmt.Sprintf("%s/confirm-ownership-transfer?token=%s", s.config.FrontendURL, confirmToken)
+	fmt.Printf("\n=== MOCK OWNERSHIP TRANSFER EMAIL ===\n")
+	fmt.Printf("To: %s\n", to)
+	fmt.Printf("Language: %s\n", NormalizeLanguage(lang))
+	fmt.Printf("Organization: %s\n", organizationName)
+	fmt.Printf("Other party: %s\n", otherName)
+	fmt.Printf("Incoming: %t\n", incoming)
+	fmt.Printf("Confirm URL: %s\n", confirmURL)
+	fmt.Printf("%s\n", strings.Repeat("=", 40))
+	return nil
+}
+
+func (s *MockService) SendNewLoginEmail(to, firstName string, login LoginDetails, reportToken, lang string) error {
+	reportURL := fmt.Sprintf("%s/report-login?token=%s", s.config.FrontendURL, reportToken)
+	fmt.Printf("\n=== MOCK NEW SIGN-IN EMAIL ===\n")
+	fmt.Printf("To: %s\n", to)
+	fmt.Printf("Language: %s\n", NormalizeLanguage(lang))
+	fmt.Printf("Device: %s\n", login.Device)
+	fmt.Printf("Location: %s\n", login.Location)
+	fmt.Printf("IP Address: %s\n", login.IPAddress)
+	fmt.Printf("New Country: %t\n", login.NewCountry)
+	fmt.Printf("Report URL: %s\n", reportURL)
+	fmt.Printf("%s\n", strings.Repeat("=", 40))
+	return nil
+}
+
+func (s *MockService) SendMigrationCompleteEmail(to, firstName, migrationName string, tableCount int, duration, completedAt, lang string) error {
 	fmt.Printf("\n=== MOCK MIGRATION COMPLETE EMAIL ===\n")
 	fmt.Printf("To: %s\n", to)
+	fmt.Printf("Language: %s\n", NormalizeLanguage(lang))
 	fmt.Printf("Name: %s\n", firstName)
 	fmt.Printf("Migration: %s\n", migrationName)
 	fmt.Printf("Tables Migrated: %d\n", tableCount)
 	fmt.Printf("Duration: %s\n", duration)
+	fmt.Printf("Completed: %s\n", completedAt)
 	fmt.Printf("Status: SUCCESS\n")
 	fmt.Printf("%s\n", strings.Repeat("=", 40))
 	return nil
 }
 
-func (s *MockService) SendMigrationFailedEmail(to, firstName, migrationName, errorMessage string) error {
+func (s *MockService) SendMigrationFailedEmail(to, firstName, migrationName, errorMessage, lang string) error {
 	fmt.Printf("\n=== MOCK MIGRATION FAILED EMAIL ===\n")
 	fmt.Printf("To: %s\n", to)
+	fmt.Printf("Language: %s\n", NormalizeLanguage(lang))
 	fmt.Printf("Name: %s\n", firstName)
 	fmt.Printf("Migration: %s\n", migrationName)
 	fmt.Printf("Error: %s\n", errorMessage)
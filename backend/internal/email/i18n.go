@@ -0,0 +1,192 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultLanguage is used when a user has no preference or an unsupported one
+const DefaultLanguage = "en"
+
+// SupportedLanguages lists the email languages; it matches the languages the AI chat supports
+var SupportedLanguages = []string{"en", "da", "es", "pt", "no", "sv", "de"}
+
+// listItem is a bolded title followed by a description
+type listItem struct {
+	Title string
+	Text  string
+}
+
+// messages holds the translatable strings for every email. Strings containing %s are
+// formats; in HTML emails their arguments are rendered in bold.
+type messages struct {
+	Tagline          string // Footer: product description
+	AutomatedMessage string
+	Greeting         string // "Hi %s,"
+	HelpContact      string
+
+	DurationSeconds string // "%d seconds"
+	DurationMinutes string // "%d min %d sec"
+	DurationHours   string // "%d hr %d min"
+	DateTimeLayout  string // time.Format layout of dates and times
+
+	ResetSubject  string
+	ResetTitle    string
+	ResetIntro    string
+	ResetButton   string
+	ResetLinkText string
+	ResetExpiry   string
+	ResetIgnore   string
+
+	WelcomeSubject     string
+	WelcomeTitle       string
+	WelcomeTagline     string
+	WelcomeGreeting    string // "Hi %s!"
+	WelcomeReady       string // "... Your account for %s is ready."
+	WelcomeWhyTitle    string
+	WelcomeBenefits    []listItem
+	WelcomeStepsTitle  string
+	WelcomeSteps       []listItem
+	WelcomeButton      string
+	WelcomeLinkText    string
+	WelcomeProTipLabel string
+	WelcomeProTip      string
+	WelcomeHelp        string
+
+	InviteSubject  string // "... join %s ..."
+	InviteTitle    string
+	InviteIntro    string // "%s has invited you to join %s ..."
+	InviteAbout    string
+	InviteButton   string
+	InviteLinkText string
+	InviteExpiry   string
+
+	TransferSubject  string // "Confirm the ownership transfer of %s"
+	TransferTitle    string
+	TransferOutgoing string // "You asked to transfer ownership of %s to %s."
+	TransferIncoming string // "%s wants to make you the owner of %s."
+	TransferAbout    string
+	TransferButton   string
+	TransferLinkText string
+	TransferExpiry   string
+
+	NewLoginSubject     string
+	NewLoginTitle       string
+	NewLoginDevice      string // Intro for a sign-in from a new device
+	NewLoginCountry     string // Intro for a sign-in from a new country
+	NewLoginDeviceLabel string
+	NewLoginLocation    string
+	NewLoginIPAddress   string
+	NewLoginTime        string
+	NewLoginIfYou       string
+	NewLoginButton      string
+	NewLoginLinkText    string
+	NewLoginLockInfo    string
+
+	CompleteSubject   string // "Migration Complete: %s"
+	CompleteTitle     string
+	CompleteBanner    string
+	CompleteHeading   string // "Great News, %s!"
+	CompleteIntro     string // "Your migration \"%s\" ..."
+	CompleteSummary   string
+	CompleteTables    string
+	CompleteDuration  string
+	CompleteFinished  string // When the migration completed
+	CompleteStatus    string
+	CompleteStatusOK  string
+	CompleteNextSteps string
+	CompleteActions   []string
+
+	DetailsButton   string
+	DetailsLinkText string
+
+	FailedSubject string // "Migration Failed: %s"
+	FailedBanner  string
+	FailedIntro   string // "Unfortunately, your migration \"%s\" ..."
+	FailedDetails string
+	FailedWhatNow string
+	FailedActions []string
+}
+
+var catalog = map[string]*messages{
+	"en": &messagesEN,
+	"da": &messagesDA,
+	"es": &messagesES,
+	"pt": &messagesPT,
+	"no": &messagesNO,
+	"sv": &messagesSV,
+	"de": &messagesDE,
+}
+
+// NormalizeLanguage maps a language tag such as "da-DK" or "nb" to a supported email
+// language, falling back to English
+func NormalizeLanguage(lang string) string {
+	if base := baseLanguage(lang); catalog[base] != nil {
+		return base
+	}
+	return DefaultLanguage
+}
+
+// IsSupportedLanguage reports whether lang (ignoring region) has its own templates
+func IsSupportedLanguage(lang string) bool {
+	return catalog[baseLanguage(lang)] != nil
+}
+
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	// Norwegian Bokmål and Nynorsk share the Norwegian templates
+	if lang == "nb" || lang == "nn" {
+		lang = "no"
+	}
+	return lang
+}
+
+// DefaultTimezone is used when a user has no time zone preference or an unknown one
+const DefaultTimezone = "UTC"
+
+// IsValidTimezone reports whether tz is an IANA time zone such as "Europe/Copenhagen"
+func IsValidTimezone(tz string) bool {
+	_, err := time.LoadLocation(tz)
+	return tz != "" && err == nil
+}
+
+// FormatTime formats t in the time zone tz (DefaultTimezone if it's empty or unknown)
+// with lang's date format
+func FormatTime(t time.Time, tz, lang string) string {
+	location, err := time.LoadLocation(tz)
+	if tz == "" || err != nil {
+		location = time.UTC
+	}
+	return t.In(location).Format(messagesFor(lang).DateTimeLayout)
+}
+
+// FormatDuration formats how long something took in lang, e.g. "3 min 12 sec"
+func FormatDuration(d time.Duration, lang string) string {
+	m := messagesFor(lang)
+	if d < time.Minute {
+		return fmt.Sprintf(m.DurationSeconds, int(d.Seconds()))
+	} else if d < time.Hour {
+		return fmt.Sprintf(m.DurationMinutes, int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf(m.DurationHours, int(d.Hours()), int(d.Minutes())%60)
+}
+
+// LanguageFromAcceptLanguage picks the first supported language from an
+// Accept-Language header, or DefaultLanguage
+func LanguageFromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if IsSupportedLanguage(tag) {
+			return NormalizeLanguage(tag)
+		}
+	}
+	return DefaultLanguage
+}
+
+func messagesFor(lang string) *messages {
+	return catalog[NormalizeLanguage(lang)]
+}
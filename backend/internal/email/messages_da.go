@@ -0,0 +1,108 @@
+package email
+
+var messagesDA = messages{
+	Tagline:          "DataMigrate AI - Migreringsplatform fra MSSQL til dbt",
+	AutomatedMessage: "Dette er en automatisk besked. Du kan ikke svare på den.",
+	Greeting:         "Hej %s,",
+	HelpContact:      "Brug for hjælp? Kontakt vores supportteam, eller brug AI-assistenten i appen.",
+
+	DurationSeconds: "%d sekunder",
+	DurationMinutes: "%d min. %d sek.",
+	DurationHours:   "%d t. %d min.",
+	DateTimeLayout:  "02.01.2006 15:04 MST",
+
+	ResetSubject:  "Nulstil din adgangskode til DataMigrate AI",
+	ResetTitle:    "Nulstil din adgangskode",
+	ResetIntro:    "Vi har modtaget en anmodning om at nulstille adgangskoden til din DataMigrate AI-konto. Klik på knappen nedenfor for at oprette en ny adgangskode:",
+	ResetButton:   "Nulstil adgangskode",
+	ResetLinkText: "Klik på dette link for at nulstille din adgangskode:",
+	ResetExpiry:   "Af sikkerhedshensyn udløber linket om 1 time.",
+	ResetIgnore:   "Hvis du ikke har bedt om at nulstille din adgangskode, kan du blot ignorere denne e-mail. Din adgangskode bliver ikke ændret.",
+
+	WelcomeSubject:  "Velkommen til DataMigrate AI!",
+	WelcomeTitle:    "Velkommen til DataMigrate AI!",
+	WelcomeTagline:  "Din AI-drevne migreringsrejse starter nu",
+	WelcomeGreeting: "Hej %s!",
+	WelcomeReady:    "Tak, fordi du har tilmeldt dig DataMigrate AI! Din konto for %s er klar.",
+	WelcomeWhyTitle: "Derfor vælger teams os",
+	WelcomeBenefits: []listItem{
+		{"90 % hurtigere migreringer", "Det, der før tog uger, tager nu timer"},
+		{"AI-drevet præcision", "8 specialiserede agenter håndterer komplekse transformationer"},
+		{"Sikkerhed i virksomhedsklassen", "Dine data forlader aldrig din infrastruktur"},
+		{"Understøttelse af flere datavarehuse", "Udrul til Snowflake, Databricks, Fabric m.fl."},
+	},
+	WelcomeStepsTitle: "Kom i gang i 3 trin:",
+	WelcomeSteps: []listItem{
+		{"Forbind", "Tilføj din MSSQL-databaseforbindelse"},
+		{"Vælg", "Vælg de tabeller og views, der skal migreres"},
+		{"Udrul", "Udrul til dit datavarehus med ét klik"},
+	},
+	WelcomeButton:      "Start din første migrering",
+	WelcomeLinkText:    "Start din første migrering:",
+	WelcomeProTipLabel: "Tip:",
+	WelcomeProTip:      "Start med en lille tabel, og se magien! Vores AI analyserer dit skema, finder relationer og genererer produktionsklare dbt-modeller.",
+	WelcomeHelp:        "Brug for hjælp? Vores AI-supportassistent er tilgængelig døgnet rundt i appen.",
+
+	InviteSubject:  "Du er inviteret til at blive medlem af %s på DataMigrate AI",
+	InviteTitle:    "Du er blevet inviteret!",
+	InviteIntro:    "%s har inviteret dig til at blive medlem af %s på DataMigrate AI.",
+	InviteAbout:    "DataMigrate AI hjælper teams med at migrere MSSQL-databaser til moderne dbt-projekter med AI-drevne transformationer.",
+	InviteButton:   "Accepter invitation",
+	InviteLinkText: "Accepter din invitation:",
+	InviteExpiry:   "Invitationen udløber om 7 dage.",
+
+	TransferSubject:  "Bekræft overdragelsen af ejerskabet af %s",
+	TransferTitle:    "Bekræft overdragelse af ejerskab",
+	TransferOutgoing: "Du har bedt om at overdrage ejerskabet af %s til %s.",
+	TransferIncoming: "%s vil gøre dig til ejer af %s.",
+	TransferAbout:    "Overdragelsen gennemføres, når I begge har bekræftet. Ejeren er ansvarlig for organisationens abonnement, indstillinger og medlemmer.",
+	TransferButton:   "Bekræft overdragelse",
+	TransferLinkText: "Bekræft overdragelsen:",
+	TransferExpiry:   "Linket udløber om 72 timer. Hvis du ikke kender til anmodningen, kan du ignorere denne e-mail, så ændres intet.",
+
+	NewLoginSubject:     "Nyt login på din DataMigrate AI-konto",
+	NewLoginTitle:       "Nyt login registreret",
+	NewLoginDevice:      "Vi har registreret et login på din konto fra en enhed, vi ikke har set før.",
+	NewLoginCountry:     "Vi har registreret et login på din konto fra et land, du ikke har logget ind fra før.",
+	NewLoginDeviceLabel: "Enhed",
+	NewLoginLocation:    "Placering",
+	NewLoginIPAddress:   "IP-adresse",
+	NewLoginTime:        "Tidspunkt",
+	NewLoginIfYou:       "Hvis det var dig, behøver du ikke gøre noget.",
+	NewLoginButton:      "Det var ikke mig",
+	NewLoginLinkText:    "Hvis det ikke var dig, så lås din konto:",
+	NewLoginLockInfo:    "Når kontoen er låst, kan ingen logge ind, før du nulstiller din adgangskode. Linket udløber om 7 dage.",
+
+	CompleteSubject:   "Migrering fuldført: %s",
+	CompleteTitle:     "Migrering fuldført!",
+	CompleteBanner:    "Migreringen lykkedes!",
+	CompleteHeading:   "Gode nyheder, %s!",
+	CompleteIntro:     "Din migrering \"%s\" er gennemført.",
+	CompleteSummary:   "Oversigt over migreringen",
+	CompleteTables:    "Migrerede tabeller",
+	CompleteDuration:  "Varighed",
+	CompleteFinished:  "Afsluttet",
+	CompleteStatus:    "Status",
+	CompleteStatusOK:  "Fuldført",
+	CompleteNextSteps: "Dine dbt-modeller er klar til udrulning. Du kan nu:",
+	CompleteActions: []string{
+		"Gennemgå de genererede dbt-modeller",
+		"Downloade projektfilerne",
+		"Udrulle direkte til dit datavarehus",
+	},
+
+	DetailsButton:   "Se detaljer om migreringen",
+	DetailsLinkText: "Se detaljer om migreringen:",
+
+	FailedSubject: "Migrering mislykkedes: %s",
+	FailedBanner:  "Problem med migreringen",
+	FailedIntro:   "Desværre opstod der et problem med din migrering \"%s\".",
+	FailedDetails: "Fejldetaljer",
+	FailedWhatNow: "Det kan du gøre:",
+	FailedActions: []string{
+		"Kontrollér indstillingerne for din databaseforbindelse",
+		"Kontrollér rettigheder og adgang til tabellerne",
+		"Læs fejlmeddelelsen ovenfor",
+		"Prøv at køre migreringen igen",
+	},
+}
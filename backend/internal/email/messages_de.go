@@ -0,0 +1,108 @@
+package email
+
+var messagesDE = messages{
+	Tagline:          "DataMigrate AI - Migrationsplattform von MSSQL zu dbt",
+	AutomatedMessage: "Dies ist eine automatisch generierte Nachricht, bitte antworten Sie nicht.",
+	Greeting:         "Hallo %s,",
+	HelpContact:      "Brauchen Sie Hilfe? Wenden Sie sich an unser Support-Team oder nutzen Sie den KI-Assistenten in der App.",
+
+	DurationSeconds: "%d Sekunden",
+	DurationMinutes: "%d Min. %d Sek.",
+	DurationHours:   "%d Std. %d Min.",
+	DateTimeLayout:  "02.01.2006 15:04 MST",
+
+	ResetSubject:  "Setzen Sie Ihr DataMigrate AI-Passwort zurück",
+	ResetTitle:    "Passwort zurücksetzen",
+	ResetIntro:    "Wir haben eine Anfrage zum Zurücksetzen des Passworts für Ihr DataMigrate AI-Konto erhalten. Klicken Sie auf die Schaltfläche unten, um ein neues Passwort festzulegen:",
+	ResetButton:   "Passwort zurücksetzen",
+	ResetLinkText: "Klicken Sie auf diesen Link, um Ihr Passwort zurückzusetzen:",
+	ResetExpiry:   "Aus Sicherheitsgründen ist dieser Link 1 Stunde lang gültig.",
+	ResetIgnore:   "Wenn Sie kein Zurücksetzen des Passworts angefordert haben, können Sie diese E-Mail ignorieren. Ihr Passwort bleibt unverändert.",
+
+	WelcomeSubject:  "Willkommen bei DataMigrate AI!",
+	WelcomeTitle:    "Willkommen bei DataMigrate AI!",
+	WelcomeTagline:  "Ihre KI-gestützte Migration beginnt jetzt",
+	WelcomeGreeting: "Hallo %s!",
+	WelcomeReady:    "Vielen Dank für Ihre Anmeldung bei DataMigrate AI! Ihr Konto für %s ist bereit.",
+	WelcomeWhyTitle: "Warum Teams uns wählen",
+	WelcomeBenefits: []listItem{
+		{"90 % schnellere Migrationen", "Was Wochen dauerte, dauert jetzt Stunden"},
+		{"KI-gestützte Genauigkeit", "8 spezialisierte Agenten übernehmen komplexe Transformationen"},
+		{"Sicherheit auf Unternehmensniveau", "Ihre Daten verlassen niemals Ihre Infrastruktur"},
+		{"Unterstützung mehrerer Data Warehouses", "Bereitstellung in Snowflake, Databricks, Fabric und mehr"},
+	},
+	WelcomeStepsTitle: "In 3 Schritten loslegen:",
+	WelcomeSteps: []listItem{
+		{"Verbinden", "Fügen Sie Ihre MSSQL-Datenbankverbindung hinzu"},
+		{"Auswählen", "Wählen Sie die zu migrierenden Tabellen und Views"},
+		{"Bereitstellen", "Bereitstellung in Ihrem Data Warehouse mit einem Klick"},
+	},
+	WelcomeButton:      "Erste Migration starten",
+	WelcomeLinkText:    "Starten Sie Ihre erste Migration:",
+	WelcomeProTipLabel: "Tipp:",
+	WelcomeProTip:      "Beginnen Sie mit einer kleinen Tabelle und erleben Sie die Magie! Unsere KI analysiert Ihr Schema, erkennt Beziehungen und erzeugt produktionsreife dbt-Modelle.",
+	WelcomeHelp:        "Brauchen Sie Hilfe? Unser KI-Support-Assistent steht Ihnen rund um die Uhr in der App zur Verfügung.",
+
+	InviteSubject:  "Sie wurden eingeladen, %s auf DataMigrate AI beizutreten",
+	InviteTitle:    "Sie wurden eingeladen!",
+	InviteIntro:    "%s hat Sie eingeladen, %s auf DataMigrate AI beizutreten.",
+	InviteAbout:    "DataMigrate AI hilft Teams, MSSQL-Datenbanken mit KI-gestützten Transformationen in moderne dbt-Projekte zu migrieren.",
+	InviteButton:   "Einladung annehmen",
+	InviteLinkText: "Nehmen Sie Ihre Einladung an:",
+	InviteExpiry:   "Diese Einladung läuft in 7 Tagen ab.",
+
+	TransferSubject:  "Bestätigen Sie die Eigentumsübertragung von %s",
+	TransferTitle:    "Eigentumsübertragung bestätigen",
+	TransferOutgoing: "Sie haben beantragt, das Eigentum an %s auf %s zu übertragen.",
+	TransferIncoming: "%s möchte Sie zum Eigentümer von %s machen.",
+	TransferAbout:    "Die Übertragung ist abgeschlossen, sobald Sie beide bestätigt haben. Der Eigentümer ist für Tarif, Einstellungen und Mitglieder der Organisation verantwortlich.",
+	TransferButton:   "Übertragung bestätigen",
+	TransferLinkText: "Bestätigen Sie die Übertragung:",
+	TransferExpiry:   "Dieser Link läuft in 72 Stunden ab. Wenn Sie diese Anfrage nicht kennen, ignorieren Sie diese E-Mail und es wird nichts geändert.",
+
+	NewLoginSubject:     "Neue Anmeldung bei Ihrem DataMigrate AI-Konto",
+	NewLoginTitle:       "Neue Anmeldung erkannt",
+	NewLoginDevice:      "Wir haben eine Anmeldung bei Ihrem Konto von einem Gerät bemerkt, das wir noch nicht kennen.",
+	NewLoginCountry:     "Wir haben eine Anmeldung bei Ihrem Konto aus einem Land bemerkt, aus dem Sie sich bisher nicht angemeldet haben.",
+	NewLoginDeviceLabel: "Gerät",
+	NewLoginLocation:    "Standort",
+	NewLoginIPAddress:   "IP-Adresse",
+	NewLoginTime:        "Zeitpunkt",
+	NewLoginIfYou:       "Wenn Sie das waren, müssen Sie nichts tun.",
+	NewLoginButton:      "Das war ich nicht",
+	NewLoginLinkText:    "Wenn Sie das nicht waren, sperren Sie Ihr Konto:",
+	NewLoginLockInfo:    "Bei einem gesperrten Konto kann sich niemand anmelden, bis Sie Ihr Passwort zurücksetzen. Dieser Link läuft in 7 Tagen ab.",
+
+	CompleteSubject:   "Migration abgeschlossen: %s",
+	CompleteTitle:     "Migration abgeschlossen!",
+	CompleteBanner:    "Migration erfolgreich!",
+	CompleteHeading:   "Gute Nachrichten, %s!",
+	CompleteIntro:     "Ihre Migration \"%s\" wurde erfolgreich abgeschlossen.",
+	CompleteSummary:   "Zusammenfassung der Migration",
+	CompleteTables:    "Migrierte Tabellen",
+	CompleteDuration:  "Dauer",
+	CompleteFinished:  "Abgeschlossen am",
+	CompleteStatus:    "Status",
+	CompleteStatusOK:  "Abgeschlossen",
+	CompleteNextSteps: "Ihre dbt-Modelle sind bereit für die Bereitstellung. Sie können jetzt:",
+	CompleteActions: []string{
+		"Die generierten dbt-Modelle prüfen",
+		"Die Projektdateien herunterladen",
+		"Direkt in Ihrem Data Warehouse bereitstellen",
+	},
+
+	DetailsButton:   "Migrationsdetails anzeigen",
+	DetailsLinkText: "Migrationsdetails anzeigen:",
+
+	FailedSubject: "Migration fehlgeschlagen: %s",
+	FailedBanner:  "Problem bei der Migration",
+	FailedIntro:   "Leider ist bei Ihrer Migration \"%s\" ein Problem aufgetreten.",
+	FailedDetails: "Fehlerdetails",
+	FailedWhatNow: "Das können Sie tun:",
+	FailedActions: []string{
+		"Überprüfen Sie die Einstellungen Ihrer Datenbankverbindung",
+		"Überprüfen Sie Tabellenberechtigungen und Zugriff",
+		"Lesen Sie die obige Fehlermeldung",
+		"Führen Sie die Migration erneut aus",
+	},
+}
@@ -0,0 +1,108 @@
+package email
+
+var messagesEN = messages{
+	Tagline:          "DataMigrate AI - MSSQL to dbt Migration Platform",
+	AutomatedMessage: "This is an automated message, please do not reply.",
+	Greeting:         "Hi %s,",
+	HelpContact:      "Need help? Contact our support team or use the AI assistant in the app.",
+
+	DurationSeconds: "%d seconds",
+	DurationMinutes: "%d min %d sec",
+	DurationHours:   "%d hr %d min",
+	DateTimeLayout:  "Jan 2, 2006 15:04 MST",
+
+	ResetSubject:  "Reset Your DataMigrate AI Password",
+	ResetTitle:    "Reset Your Password",
+	ResetIntro:    "We received a request to reset your password for your DataMigrate AI account. Click the button below to create a new password:",
+	ResetButton:   "Reset Password",
+	ResetLinkText: "Click this link to reset your password:",
+	ResetExpiry:   "This link will expire in 1 hour for security reasons.",
+	ResetIgnore:   "If you didn't request a password reset, you can safely ignore this email. Your password will not be changed.",
+
+	WelcomeSubject:  "Welcome to DataMigrate AI!",
+	WelcomeTitle:    "Welcome to DataMigrate AI!",
+	WelcomeTagline:  "Your AI-Powered Migration Journey Starts Now",
+	WelcomeGreeting: "Hi %s!",
+	WelcomeReady:    "Thank you for joining DataMigrate AI! Your account for %s is ready.",
+	WelcomeWhyTitle: "Why Teams Choose Us",
+	WelcomeBenefits: []listItem{
+		{"90% Faster Migrations", "What takes weeks now takes hours"},
+		{"AI-Powered Accuracy", "8 specialized agents handle complex transformations"},
+		{"Enterprise Security", "Your data never leaves your infrastructure"},
+		{"Multi-Warehouse Support", "Deploy to Snowflake, Databricks, Fabric & more"},
+	},
+	WelcomeStepsTitle: "Get Started in 3 Steps:",
+	WelcomeSteps: []listItem{
+		{"Connect", "Add your MSSQL database connection"},
+		{"Select", "Choose tables and views to migrate"},
+		{"Deploy", "One-click deployment to your data warehouse"},
+	},
+	WelcomeButton:      "Start Your First Migration",
+	WelcomeLinkText:    "Start your first migration:",
+	WelcomeProTipLabel: "Pro Tip:",
+	WelcomeProTip:      "Start with a small table to see the magic! Our AI will analyze your schema, detect relationships, and generate production-ready dbt models.",
+	WelcomeHelp:        "Need help? Our AI support assistant is available 24/7 in the app.",
+
+	InviteSubject:  "You've been invited to join %s on DataMigrate AI",
+	InviteTitle:    "You've Been Invited!",
+	InviteIntro:    "%s has invited you to join %s on DataMigrate AI.",
+	InviteAbout:    "DataMigrate AI helps teams migrate MSSQL databases to modern dbt projects with AI-powered transformation.",
+	InviteButton:   "Accept Invitation",
+	InviteLinkText: "Accept your invitation:",
+	InviteExpiry:   "This invitation will expire in 7 days.",
+
+	TransferSubject:  "Confirm the ownership transfer of %s",
+	TransferTitle:    "Confirm Ownership Transfer",
+	TransferOutgoing: "You asked to transfer ownership of %s to %s.",
+	TransferIncoming: "%s wants to make you the owner of %s.",
+	TransferAbout:    "The transfer completes once both of you have confirmed. The owner is responsible for the organization's plan, settings and members.",
+	TransferButton:   "Confirm Transfer",
+	TransferLinkText: "Confirm the transfer:",
+	TransferExpiry:   "This link expires in 72 hours. If you don't recognize this request, ignore this email and nothing will change.",
+
+	NewLoginSubject:     "New sign-in to your DataMigrate AI account",
+	NewLoginTitle:       "New Sign-In Detected",
+	NewLoginDevice:      "We noticed a sign-in to your account from a device we haven't seen before.",
+	NewLoginCountry:     "We noticed a sign-in to your account from a country you haven't signed in from before.",
+	NewLoginDeviceLabel: "Device",
+	NewLoginLocation:    "Location",
+	NewLoginIPAddress:   "IP address",
+	NewLoginTime:        "Time",
+	NewLoginIfYou:       "If this was you, you don't need to do anything.",
+	NewLoginButton:      "This Wasn't Me",
+	NewLoginLinkText:    "If this wasn't you, lock your account:",
+	NewLoginLockInfo:    "Locking your account stops anyone from signing in until you reset your password. This link expires in 7 days.",
+
+	CompleteSubject:   "Migration Complete: %s",
+	CompleteTitle:     "Migration Complete!",
+	CompleteBanner:    "Migration Successful!",
+	CompleteHeading:   "Great News, %s!",
+	CompleteIntro:     "Your migration \"%s\" has completed successfully.",
+	CompleteSummary:   "Migration Summary",
+	CompleteTables:    "Tables Migrated",
+	CompleteDuration:  "Duration",
+	CompleteFinished:  "Completed",
+	CompleteStatus:    "Status",
+	CompleteStatusOK:  "Complete",
+	CompleteNextSteps: "Your dbt models are ready for deployment. You can now:",
+	CompleteActions: []string{
+		"Review the generated dbt models",
+		"Download the project files",
+		"Deploy directly to your data warehouse",
+	},
+
+	DetailsButton:   "View Migration Details",
+	DetailsLinkText: "View migration details:",
+
+	FailedSubject: "Migration Failed: %s",
+	FailedBanner:  "Migration Issue",
+	FailedIntro:   "Unfortunately, your migration \"%s\" encountered an issue.",
+	FailedDetails: "Error Details",
+	FailedWhatNow: "Here's what you can do:",
+	FailedActions: []string{
+		"Check your database connection settings",
+		"Verify table permissions and access",
+		"Review the error message above",
+		"Try running the migration again",
+	},
+}
@@ -0,0 +1,108 @@
+package email
+
+var messagesES = messages{
+	Tagline:          "DataMigrate AI - Plataforma de migración de MSSQL a dbt",
+	AutomatedMessage: "Este es un mensaje automático, por favor no respondas.",
+	Greeting:         "Hola %s:",
+	HelpContact:      "¿Necesitas ayuda? Contacta con nuestro equipo de soporte o usa el asistente de IA en la aplicación.",
+
+	DurationSeconds: "%d segundos",
+	DurationMinutes: "%d min %d s",
+	DurationHours:   "%d h %d min",
+	DateTimeLayout:  "02/01/2006 15:04 MST",
+
+	ResetSubject:  "Restablece tu contraseña de DataMigrate AI",
+	ResetTitle:    "Restablece tu contraseña",
+	ResetIntro:    "Hemos recibido una solicitud para restablecer la contraseña de tu cuenta de DataMigrate AI. Haz clic en el botón de abajo para crear una nueva contraseña:",
+	ResetButton:   "Restablecer contraseña",
+	ResetLinkText: "Haz clic en este enlace para restablecer tu contraseña:",
+	ResetExpiry:   "Por motivos de seguridad, este enlace caducará en 1 hora.",
+	ResetIgnore:   "Si no has solicitado restablecer tu contraseña, puedes ignorar este correo. Tu contraseña no se modificará.",
+
+	WelcomeSubject:  "¡Bienvenido a DataMigrate AI!",
+	WelcomeTitle:    "¡Bienvenido a DataMigrate AI!",
+	WelcomeTagline:  "Tu migración impulsada por IA empieza ahora",
+	WelcomeGreeting: "¡Hola %s!",
+	WelcomeReady:    "¡Gracias por unirte a DataMigrate AI! Tu cuenta para %s ya está lista.",
+	WelcomeWhyTitle: "Por qué nos eligen los equipos",
+	WelcomeBenefits: []listItem{
+		{"Migraciones un 90 % más rápidas", "Lo que llevaba semanas ahora lleva horas"},
+		{"Precisión impulsada por IA", "8 agentes especializados gestionan transformaciones complejas"},
+		{"Seguridad empresarial", "Tus datos nunca salen de tu infraestructura"},
+		{"Compatibilidad con varios almacenes de datos", "Despliega en Snowflake, Databricks, Fabric y más"},
+	},
+	WelcomeStepsTitle: "Empieza en 3 pasos:",
+	WelcomeSteps: []listItem{
+		{"Conecta", "Añade la conexión a tu base de datos MSSQL"},
+		{"Selecciona", "Elige las tablas y vistas que quieres migrar"},
+		{"Despliega", "Despliegue en tu almacén de datos con un solo clic"},
+	},
+	WelcomeButton:      "Inicia tu primera migración",
+	WelcomeLinkText:    "Inicia tu primera migración:",
+	WelcomeProTipLabel: "Consejo:",
+	WelcomeProTip:      "¡Empieza con una tabla pequeña para ver la magia! Nuestra IA analizará tu esquema, detectará relaciones y generará modelos dbt listos para producción.",
+	WelcomeHelp:        "¿Necesitas ayuda? Nuestro asistente de soporte con IA está disponible 24/7 en la aplicación.",
+
+	InviteSubject:  "Te han invitado a unirte a %s en DataMigrate AI",
+	InviteTitle:    "¡Has recibido una invitación!",
+	InviteIntro:    "%s te ha invitado a unirte a %s en DataMigrate AI.",
+	InviteAbout:    "DataMigrate AI ayuda a los equipos a migrar bases de datos MSSQL a proyectos dbt modernos con transformaciones impulsadas por IA.",
+	InviteButton:   "Aceptar invitación",
+	InviteLinkText: "Acepta tu invitación:",
+	InviteExpiry:   "Esta invitación caducará en 7 días.",
+
+	TransferSubject:  "Confirma la transferencia de propiedad de %s",
+	TransferTitle:    "Confirma la transferencia de propiedad",
+	TransferOutgoing: "Has solicitado transferir la propiedad de %s a %s.",
+	TransferIncoming: "%s quiere convertirte en propietario de %s.",
+	TransferAbout:    "La transferencia se completa cuando ambos la hayáis confirmado. El propietario es responsable del plan, la configuración y los miembros de la organización.",
+	TransferButton:   "Confirmar transferencia",
+	TransferLinkText: "Confirma la transferencia:",
+	TransferExpiry:   "Este enlace caduca en 72 horas. Si no reconoces esta solicitud, ignora este correo y no cambiará nada.",
+
+	NewLoginSubject:     "Nuevo inicio de sesión en tu cuenta de DataMigrate AI",
+	NewLoginTitle:       "Nuevo inicio de sesión detectado",
+	NewLoginDevice:      "Hemos detectado un inicio de sesión en tu cuenta desde un dispositivo que no habíamos visto antes.",
+	NewLoginCountry:     "Hemos detectado un inicio de sesión en tu cuenta desde un país desde el que no habías iniciado sesión antes.",
+	NewLoginDeviceLabel: "Dispositivo",
+	NewLoginLocation:    "Ubicación",
+	NewLoginIPAddress:   "Dirección IP",
+	NewLoginTime:        "Hora",
+	NewLoginIfYou:       "Si fuiste tú, no tienes que hacer nada.",
+	NewLoginButton:      "No he sido yo",
+	NewLoginLinkText:    "Si no fuiste tú, bloquea tu cuenta:",
+	NewLoginLockInfo:    "Al bloquear tu cuenta nadie podrá iniciar sesión hasta que restablezcas tu contraseña. Este enlace caduca en 7 días.",
+
+	CompleteSubject:   "Migración completada: %s",
+	CompleteTitle:     "¡Migración completada!",
+	CompleteBanner:    "¡Migración realizada con éxito!",
+	CompleteHeading:   "¡Buenas noticias, %s!",
+	CompleteIntro:     "Tu migración \"%s\" se ha completado correctamente.",
+	CompleteSummary:   "Resumen de la migración",
+	CompleteTables:    "Tablas migradas",
+	CompleteDuration:  "Duración",
+	CompleteFinished:  "Finalizada",
+	CompleteStatus:    "Estado",
+	CompleteStatusOK:  "Completada",
+	CompleteNextSteps: "Tus modelos dbt están listos para desplegarse. Ahora puedes:",
+	CompleteActions: []string{
+		"Revisar los modelos dbt generados",
+		"Descargar los archivos del proyecto",
+		"Desplegar directamente en tu almacén de datos",
+	},
+
+	DetailsButton:   "Ver detalles de la migración",
+	DetailsLinkText: "Ver detalles de la migración:",
+
+	FailedSubject: "Error en la migración: %s",
+	FailedBanner:  "Problema con la migración",
+	FailedIntro:   "Lamentablemente, tu migración \"%s\" ha encontrado un problema.",
+	FailedDetails: "Detalles del error",
+	FailedWhatNow: "Esto es lo que puedes hacer:",
+	FailedActions: []string{
+		"Comprueba la configuración de la conexión a la base de datos",
+		"Verifica los permisos y el acceso a las tablas",
+		"Revisa el mensaje de error anterior",
+		"Vuelve a ejecutar la migración",
+	},
+}
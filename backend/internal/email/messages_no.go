@@ -0,0 +1,108 @@
+package email
+
+var messagesNO = messages{
+	Tagline:          "DataMigrate AI - Migreringsplattform fra MSSQL til dbt",
+	AutomatedMessage: "Dette er en automatisk melding. Vennligst ikke svar.",
+	Greeting:         "Hei %s,",
+	HelpContact:      "Trenger du hjelp? Kontakt supportteamet vårt eller bruk AI-assistenten i appen.",
+
+	DurationSeconds: "%d sekunder",
+	DurationMinutes: "%d min %d sek",
+	DurationHours:   "%d t %d min",
+	DateTimeLayout:  "02.01.2006 15:04 MST",
+
+	ResetSubject:  "Tilbakestill passordet ditt for DataMigrate AI",
+	ResetTitle:    "Tilbakestill passordet ditt",
+	ResetIntro:    "Vi har mottatt en forespørsel om å tilbakestille passordet for DataMigrate AI-kontoen din. Klikk på knappen nedenfor for å opprette et nytt passord:",
+	ResetButton:   "Tilbakestill passord",
+	ResetLinkText: "Klikk på denne lenken for å tilbakestille passordet ditt:",
+	ResetExpiry:   "Av sikkerhetshensyn utløper lenken om 1 time.",
+	ResetIgnore:   "Hvis du ikke har bedt om å tilbakestille passordet, kan du se bort fra denne e-posten. Passordet ditt blir ikke endret.",
+
+	WelcomeSubject:  "Velkommen til DataMigrate AI!",
+	WelcomeTitle:    "Velkommen til DataMigrate AI!",
+	WelcomeTagline:  "Din AI-drevne migreringsreise starter nå",
+	WelcomeGreeting: "Hei %s!",
+	WelcomeReady:    "Takk for at du ble med i DataMigrate AI! Kontoen din for %s er klar.",
+	WelcomeWhyTitle: "Derfor velger team oss",
+	WelcomeBenefits: []listItem{
+		{"90 % raskere migreringer", "Det som tok uker, tar nå timer"},
+		{"AI-drevet presisjon", "8 spesialiserte agenter håndterer komplekse transformasjoner"},
+		{"Sikkerhet på bedriftsnivå", "Dataene dine forlater aldri infrastrukturen din"},
+		{"Støtte for flere datavarehus", "Distribuer til Snowflake, Databricks, Fabric med flere"},
+	},
+	WelcomeStepsTitle: "Kom i gang på 3 trinn:",
+	WelcomeSteps: []listItem{
+		{"Koble til", "Legg til MSSQL-databasetilkoblingen din"},
+		{"Velg", "Velg tabeller og visninger som skal migreres"},
+		{"Distribuer", "Distribusjon til datavarehuset ditt med ett klikk"},
+	},
+	WelcomeButton:      "Start din første migrering",
+	WelcomeLinkText:    "Start din første migrering:",
+	WelcomeProTipLabel: "Tips:",
+	WelcomeProTip:      "Start med en liten tabell og se magien! AI-en vår analyserer skjemaet ditt, finner relasjoner og genererer produksjonsklare dbt-modeller.",
+	WelcomeHelp:        "Trenger du hjelp? AI-supportassistenten vår er tilgjengelig døgnet rundt i appen.",
+
+	InviteSubject:  "Du er invitert til å bli med i %s på DataMigrate AI",
+	InviteTitle:    "Du er invitert!",
+	InviteIntro:    "%s har invitert deg til å bli med i %s på DataMigrate AI.",
+	InviteAbout:    "DataMigrate AI hjelper team med å migrere MSSQL-databaser til moderne dbt-prosjekter med AI-drevne transformasjoner.",
+	InviteButton:   "Godta invitasjonen",
+	InviteLinkText: "Godta invitasjonen din:",
+	InviteExpiry:   "Invitasjonen utløper om 7 dager.",
+
+	TransferSubject:  "Bekreft overføringen av eierskapet til %s",
+	TransferTitle:    "Bekreft overføring av eierskap",
+	TransferOutgoing: "Du har bedt om å overføre eierskapet til %s til %s.",
+	TransferIncoming: "%s vil gjøre deg til eier av %s.",
+	TransferAbout:    "Overføringen fullføres når dere begge har bekreftet. Eieren er ansvarlig for organisasjonens abonnement, innstillinger og medlemmer.",
+	TransferButton:   "Bekreft overføringen",
+	TransferLinkText: "Bekreft overføringen:",
+	TransferExpiry:   "Lenken utløper om 72 timer. Hvis du ikke kjenner igjen forespørselen, kan du ignorere denne e-posten, og ingenting endres.",
+
+	NewLoginSubject:     "Ny pålogging på DataMigrate AI-kontoen din",
+	NewLoginTitle:       "Ny pålogging oppdaget",
+	NewLoginDevice:      "Vi har registrert en pålogging på kontoen din fra en enhet vi ikke har sett før.",
+	NewLoginCountry:     "Vi har registrert en pålogging på kontoen din fra et land du ikke har logget på fra før.",
+	NewLoginDeviceLabel: "Enhet",
+	NewLoginLocation:    "Sted",
+	NewLoginIPAddress:   "IP-adresse",
+	NewLoginTime:        "Tidspunkt",
+	NewLoginIfYou:       "Hvis det var deg, trenger du ikke gjøre noe.",
+	NewLoginButton:      "Det var ikke meg",
+	NewLoginLinkText:    "Hvis det ikke var deg, lås kontoen din:",
+	NewLoginLockInfo:    "Når kontoen er låst, kan ingen logge på før du tilbakestiller passordet. Lenken utløper om 7 dager.",
+
+	CompleteSubject:   "Migrering fullført: %s",
+	CompleteTitle:     "Migrering fullført!",
+	CompleteBanner:    "Migreringen var vellykket!",
+	CompleteHeading:   "Gode nyheter, %s!",
+	CompleteIntro:     "Migreringen din \"%s\" er fullført.",
+	CompleteSummary:   "Sammendrag av migreringen",
+	CompleteTables:    "Migrerte tabeller",
+	CompleteDuration:  "Varighet",
+	CompleteFinished:  "Fullført",
+	CompleteStatus:    "Status",
+	CompleteStatusOK:  "Fullført",
+	CompleteNextSteps: "dbt-modellene dine er klare til distribusjon. Nå kan du:",
+	CompleteActions: []string{
+		"Gå gjennom de genererte dbt-modellene",
+		"Laste ned prosjektfilene",
+		"Distribuere direkte til datavarehuset ditt",
+	},
+
+	DetailsButton:   "Se detaljer om migreringen",
+	DetailsLinkText: "Se detaljer om migreringen:",
+
+	FailedSubject: "Migrering mislyktes: %s",
+	FailedBanner:  "Problem med migreringen",
+	FailedIntro:   "Dessverre oppstod det et problem med migreringen din \"%s\".",
+	FailedDetails: "Feildetaljer",
+	FailedWhatNow: "Dette kan du gjøre:",
+	FailedActions: []string{
+		"Kontroller innstillingene for databasetilkoblingen",
+		"Kontroller tillatelser og tilgang til tabellene",
+		"Les feilmeldingen ovenfor",
+		"Prøv å kjøre migreringen på nytt",
+	},
+}
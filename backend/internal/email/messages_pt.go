@@ -0,0 +1,108 @@
+package email
+
+var messagesPT = messages{
+	Tagline:          "DataMigrate AI - Plataforma de migração de MSSQL para dbt",
+	AutomatedMessage: "Esta é uma mensagem automática, por favor não responda.",
+	Greeting:         "Olá %s,",
+	HelpContact:      "Precisa de ajuda? Entre em contato com nossa equipe de suporte ou use o assistente de IA no aplicativo.",
+
+	DurationSeconds: "%d segundos",
+	DurationMinutes: "%d min %d s",
+	DurationHours:   "%d h %d min",
+	DateTimeLayout:  "02/01/2006 15:04 MST",
+
+	ResetSubject:  "Redefina sua senha do DataMigrate AI",
+	ResetTitle:    "Redefina sua senha",
+	ResetIntro:    "Recebemos uma solicitação para redefinir a senha da sua conta DataMigrate AI. Clique no botão abaixo para criar uma nova senha:",
+	ResetButton:   "Redefinir senha",
+	ResetLinkText: "Clique neste link para redefinir sua senha:",
+	ResetExpiry:   "Por motivos de segurança, este link expira em 1 hora.",
+	ResetIgnore:   "Se você não solicitou a redefinição de senha, pode ignorar este e-mail. Sua senha não será alterada.",
+
+	WelcomeSubject:  "Bem-vindo ao DataMigrate AI!",
+	WelcomeTitle:    "Bem-vindo ao DataMigrate AI!",
+	WelcomeTagline:  "Sua jornada de migração com IA começa agora",
+	WelcomeGreeting: "Olá %s!",
+	WelcomeReady:    "Obrigado por se juntar ao DataMigrate AI! Sua conta para %s está pronta.",
+	WelcomeWhyTitle: "Por que as equipes nos escolhem",
+	WelcomeBenefits: []listItem{
+		{"Migrações 90% mais rápidas", "O que levava semanas agora leva horas"},
+		{"Precisão com IA", "8 agentes especializados cuidam de transformações complexas"},
+		{"Segurança corporativa", "Seus dados nunca saem da sua infraestrutura"},
+		{"Suporte a vários data warehouses", "Implante no Snowflake, Databricks, Fabric e mais"},
+	},
+	WelcomeStepsTitle: "Comece em 3 passos:",
+	WelcomeSteps: []listItem{
+		{"Conecte", "Adicione a conexão com seu banco de dados MSSQL"},
+		{"Selecione", "Escolha as tabelas e views para migrar"},
+		{"Implante", "Implantação no seu data warehouse com um clique"},
+	},
+	WelcomeButton:      "Inicie sua primeira migração",
+	WelcomeLinkText:    "Inicie sua primeira migração:",
+	WelcomeProTipLabel: "Dica:",
+	WelcomeProTip:      "Comece com uma tabela pequena para ver a mágica! Nossa IA vai analisar seu esquema, detectar relacionamentos e gerar modelos dbt prontos para produção.",
+	WelcomeHelp:        "Precisa de ajuda? Nosso assistente de suporte com IA está disponível 24 horas por dia no aplicativo.",
+
+	InviteSubject:  "Você foi convidado para participar de %s no DataMigrate AI",
+	InviteTitle:    "Você foi convidado!",
+	InviteIntro:    "%s convidou você para participar de %s no DataMigrate AI.",
+	InviteAbout:    "O DataMigrate AI ajuda equipes a migrar bancos de dados MSSQL para projetos dbt modernos com transformações baseadas em IA.",
+	InviteButton:   "Aceitar convite",
+	InviteLinkText: "Aceite seu convite:",
+	InviteExpiry:   "Este convite expira em 7 dias.",
+
+	TransferSubject:  "Confirme a transferência de propriedade de %s",
+	TransferTitle:    "Confirme a transferência de propriedade",
+	TransferOutgoing: "Você solicitou a transferência da propriedade de %s para %s.",
+	TransferIncoming: "%s quer tornar você o proprietário de %s.",
+	TransferAbout:    "A transferência é concluída quando ambos confirmarem. O proprietário é responsável pelo plano, pelas configurações e pelos membros da organização.",
+	TransferButton:   "Confirmar transferência",
+	TransferLinkText: "Confirme a transferência:",
+	TransferExpiry:   "Este link expira em 72 horas. Se você não reconhece esta solicitação, ignore este e-mail e nada será alterado.",
+
+	NewLoginSubject:     "Novo acesso à sua conta do DataMigrate AI",
+	NewLoginTitle:       "Novo acesso detectado",
+	NewLoginDevice:      "Detectamos um acesso à sua conta a partir de um dispositivo que não conhecíamos.",
+	NewLoginCountry:     "Detectamos um acesso à sua conta a partir de um país de onde você nunca havia entrado.",
+	NewLoginDeviceLabel: "Dispositivo",
+	NewLoginLocation:    "Localização",
+	NewLoginIPAddress:   "Endereço IP",
+	NewLoginTime:        "Horário",
+	NewLoginIfYou:       "Se foi você, não precisa fazer nada.",
+	NewLoginButton:      "Não fui eu",
+	NewLoginLinkText:    "Se não foi você, bloqueie sua conta:",
+	NewLoginLockInfo:    "Com a conta bloqueada, ninguém consegue entrar até que você redefina sua senha. Este link expira em 7 dias.",
+
+	CompleteSubject:   "Migração concluída: %s",
+	CompleteTitle:     "Migração concluída!",
+	CompleteBanner:    "Migração bem-sucedida!",
+	CompleteHeading:   "Boas notícias, %s!",
+	CompleteIntro:     "Sua migração \"%s\" foi concluída com sucesso.",
+	CompleteSummary:   "Resumo da migração",
+	CompleteTables:    "Tabelas migradas",
+	CompleteDuration:  "Duração",
+	CompleteFinished:  "Concluída em",
+	CompleteStatus:    "Status",
+	CompleteStatusOK:  "Concluída",
+	CompleteNextSteps: "Seus modelos dbt estão prontos para implantação. Agora você pode:",
+	CompleteActions: []string{
+		"Revisar os modelos dbt gerados",
+		"Baixar os arquivos do projeto",
+		"Implantar diretamente no seu data warehouse",
+	},
+
+	DetailsButton:   "Ver detalhes da migração",
+	DetailsLinkText: "Ver detalhes da migração:",
+
+	FailedSubject: "Falha na migração: %s",
+	FailedBanner:  "Problema na migração",
+	FailedIntro:   "Infelizmente, sua migração \"%s\" encontrou um problema.",
+	FailedDetails: "Detalhes do erro",
+	FailedWhatNow: "Veja o que você pode fazer:",
+	FailedActions: []string{
+		"Verifique as configurações de conexão do banco de dados",
+		"Confira as permissões e o acesso às tabelas",
+		"Analise a mensagem de erro acima",
+		"Tente executar a migração novamente",
+	},
+}
@@ -0,0 +1,108 @@
+package email
+
+var messagesSV = messages{
+	Tagline:          "DataMigrate AI - Migreringsplattform från MSSQL till dbt",
+	AutomatedMessage: "Detta är ett automatiskt meddelande, vänligen svara inte.",
+	Greeting:         "Hej %s,",
+	HelpContact:      "Behöver du hjälp? Kontakta vårt supportteam eller använd AI-assistenten i appen.",
+
+	DurationSeconds: "%d sekunder",
+	DurationMinutes: "%d min %d s",
+	DurationHours:   "%d tim %d min",
+	DateTimeLayout:  "2006-01-02 15:04 MST",
+
+	ResetSubject:  "Återställ ditt lösenord för DataMigrate AI",
+	ResetTitle:    "Återställ ditt lösenord",
+	ResetIntro:    "Vi har fått en begäran om att återställa lösenordet för ditt DataMigrate AI-konto. Klicka på knappen nedan för att skapa ett nytt lösenord:",
+	ResetButton:   "Återställ lösenord",
+	ResetLinkText: "Klicka på den här länken för att återställa ditt lösenord:",
+	ResetExpiry:   "Av säkerhetsskäl upphör länken att gälla om 1 timme.",
+	ResetIgnore:   "Om du inte har begärt att återställa lösenordet kan du bortse från detta mejl. Ditt lösenord ändras inte.",
+
+	WelcomeSubject:  "Välkommen till DataMigrate AI!",
+	WelcomeTitle:    "Välkommen till DataMigrate AI!",
+	WelcomeTagline:  "Din AI-drivna migreringsresa börjar nu",
+	WelcomeGreeting: "Hej %s!",
+	WelcomeReady:    "Tack för att du gick med i DataMigrate AI! Ditt konto för %s är klart.",
+	WelcomeWhyTitle: "Därför väljer team oss",
+	WelcomeBenefits: []listItem{
+		{"90 % snabbare migreringar", "Det som tog veckor tar nu timmar"},
+		{"AI-driven precision", "8 specialiserade agenter hanterar komplexa transformationer"},
+		{"Säkerhet i företagsklass", "Dina data lämnar aldrig din infrastruktur"},
+		{"Stöd för flera datalager", "Distribuera till Snowflake, Databricks, Fabric med flera"},
+	},
+	WelcomeStepsTitle: "Kom igång i 3 steg:",
+	WelcomeSteps: []listItem{
+		{"Anslut", "Lägg till din MSSQL-databasanslutning"},
+		{"Välj", "Välj tabeller och vyer att migrera"},
+		{"Distribuera", "Distribution till ditt datalager med ett klick"},
+	},
+	WelcomeButton:      "Starta din första migrering",
+	WelcomeLinkText:    "Starta din första migrering:",
+	WelcomeProTipLabel: "Tips:",
+	WelcomeProTip:      "Börja med en liten tabell och se magin! Vår AI analyserar ditt schema, hittar relationer och genererar produktionsklara dbt-modeller.",
+	WelcomeHelp:        "Behöver du hjälp? Vår AI-supportassistent finns tillgänglig dygnet runt i appen.",
+
+	InviteSubject:  "Du har bjudits in till %s på DataMigrate AI",
+	InviteTitle:    "Du har blivit inbjuden!",
+	InviteIntro:    "%s har bjudit in dig till %s på DataMigrate AI.",
+	InviteAbout:    "DataMigrate AI hjälper team att migrera MSSQL-databaser till moderna dbt-projekt med AI-drivna transformationer.",
+	InviteButton:   "Acceptera inbjudan",
+	InviteLinkText: "Acceptera din inbjudan:",
+	InviteExpiry:   "Inbjudan upphör att gälla om 7 dagar.",
+
+	TransferSubject:  "Bekräfta överlåtelsen av ägarskapet för %s",
+	TransferTitle:    "Bekräfta överlåtelse av ägarskap",
+	TransferOutgoing: "Du har begärt att överlåta ägarskapet för %s till %s.",
+	TransferIncoming: "%s vill göra dig till ägare av %s.",
+	TransferAbout:    "Överlåtelsen genomförs när ni båda har bekräftat. Ägaren ansvarar för organisationens abonnemang, inställningar och medlemmar.",
+	TransferButton:   "Bekräfta överlåtelsen",
+	TransferLinkText: "Bekräfta överlåtelsen:",
+	TransferExpiry:   "Länken upphör att gälla om 72 timmar. Om du inte känner igen begäran kan du ignorera det här mejlet, så ändras ingenting.",
+
+	NewLoginSubject:     "Ny inloggning på ditt DataMigrate AI-konto",
+	NewLoginTitle:       "Ny inloggning upptäckt",
+	NewLoginDevice:      "Vi har upptäckt en inloggning på ditt konto från en enhet som vi inte har sett tidigare.",
+	NewLoginCountry:     "Vi har upptäckt en inloggning på ditt konto från ett land som du inte har loggat in från tidigare.",
+	NewLoginDeviceLabel: "Enhet",
+	NewLoginLocation:    "Plats",
+	NewLoginIPAddress:   "IP-adress",
+	NewLoginTime:        "Tid",
+	NewLoginIfYou:       "Om det var du behöver du inte göra något.",
+	NewLoginButton:      "Det var inte jag",
+	NewLoginLinkText:    "Om det inte var du, lås ditt konto:",
+	NewLoginLockInfo:    "När kontot är låst kan ingen logga in förrän du återställer ditt lösenord. Länken upphör att gälla om 7 dagar.",
+
+	CompleteSubject:   "Migrering slutförd: %s",
+	CompleteTitle:     "Migrering slutförd!",
+	CompleteBanner:    "Migreringen lyckades!",
+	CompleteHeading:   "Goda nyheter, %s!",
+	CompleteIntro:     "Din migrering \"%s\" har slutförts.",
+	CompleteSummary:   "Sammanfattning av migreringen",
+	CompleteTables:    "Migrerade tabeller",
+	CompleteDuration:  "Varaktighet",
+	CompleteFinished:  "Slutförd",
+	CompleteStatus:    "Status",
+	CompleteStatusOK:  "Slutförd",
+	CompleteNextSteps: "Dina dbt-modeller är redo att distribueras. Nu kan du:",
+	CompleteActions: []string{
+		"Granska de genererade dbt-modellerna",
+		"Ladda ner projektfilerna",
+		"Distribuera direkt till ditt datalager",
+	},
+
+	DetailsButton:   "Visa migreringsdetaljer",
+	DetailsLinkText: "Visa migreringsdetaljer:",
+
+	FailedSubject: "Migrering misslyckades: %s",
+	FailedBanner:  "Problem med migreringen",
+	FailedIntro:   "Tyvärr uppstod ett problem med din migrering \"%s\".",
+	FailedDetails: "Felinformation",
+	FailedWhatNow: "Det här kan du göra:",
+	FailedActions: []string{
+		"Kontrollera inställningarna för databasanslutningen",
+		"Kontrollera behörigheter och åtkomst till tabellerna",
+		"Läs felmeddelandet ovan",
+		"Försök köra migreringen igen",
+	},
+}
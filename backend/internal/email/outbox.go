@@ -0,0 +1,327 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/metrics"
+)
+
+// Outbox statuses
+const (
+	OutboxPending = "pending" // Waiting for (another) delivery attempt
+	OutboxSending = "sending" // Claimed by the worker
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"  // Gave up after max_attempts transient errors
+	OutboxBounced = "bounced" // Rejected permanently by the SMTP server (5xx)
+)
+
+// OutboxEmail is a queued email. Bodies are never returned by the API since they can
+// contain password reset links.
+type OutboxEmail struct {
+	ID            int64      `db:"id" json:"id"`
+	ToAddress     string     `db:"to_address" json:"to_address"`
+	Subject       string     `db:"subject" json:"subject"`
+	HTMLBody      string     `db:"html_body" json:"-"`
+	TextBody      string     `db:"text_body" json:"-"`
+	Status        string     `db:"status" json:"status"`
+	Attempts      int        `db:"attempts" json:"attempts"`
+	MaxAttempts   int        `db:"max_attempts" json:"max_attempts"`
+	NextAttemptAt *time.Time `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastError     *string    `db:"last_error" json:"last_error,omitempty"`
+	SMTPCode      *int       `db:"smtp_code" json:"smtp_code,omitempty"`
+	SentAt        *time.Time `db:"sent_at" json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// OutboxConfig tunes the delivery worker
+type OutboxConfig struct {
+	PollInterval time.Duration // How often the worker looks for due emails
+	BatchSize    int           // Emails claimed per poll
+	MaxAttempts  int           // Attempts before an email is marked failed
+	BaseBackoff  time.Duration // Delay after the first failure; doubles per attempt
+	MaxBackoff   time.Duration
+	StaleAfter   time.Duration // Emails stuck in "sending" this long (worker crashed) are retried
+	SendTimeout  time.Duration // Limit on one delivery attempt; a timeout counts as a transient failure
+}
+
+// DefaultOutboxConfig returns sensible defaults
+func DefaultOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		PollInterval: 10 * time.Second,
+		BatchSize:    20,
+		MaxAttempts:  5,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   time.Hour,
+		StaleAfter:   10 * time.Minute,
+		SendTimeout:  time.Duration(getEnvInt("SMTP_TIMEOUT_SECONDS", 30)) * time.Second,
+	}
+}
+
+// Outbox queues emails in the email_outbox table and delivers them in the background
+type Outbox struct {
+	config   OutboxConfig
+	sender   func(ctx context.Context, to, subject, htmlBody, textBody string) error
+	stopChan chan struct{}
+	started  bool
+	mu       sync.Mutex
+}
+
+var outbox *Outbox
+var outboxOnce sync.Once
+
+// GetOutbox returns the singleton Outbox instance
+func GetOutbox() *Outbox {
+	outboxOnce.Do(func() {
+		outbox = &Outbox{
+			config:   DefaultOutboxConfig(),
+			sender:   NewService().SendEmailContext,
+			stopChan: make(chan struct{}),
+		}
+	})
+	return outbox
+}
+
+// Enqueue stores an email for delivery by the worker
+func (o *Outbox) Enqueue(to, subject, htmlBody, textBody string) (int64, error) {
+	var id int64
+	err := db.DB.QueryRow(`
+		INSERT INTO email_outbox (to_address, subject, html_body, text_body, max_attempts)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, to, subject, htmlBody, textBody, o.config.MaxAttempts).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue email: %w", err)
+	}
+	metrics.EmailsQueuedTotal.Inc()
+	return id, nil
+}
+
+// Start launches the delivery worker
+func (o *Outbox) Start() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.started {
+		return
+	}
+	o.started = true
+
+	go func() {
+		ticker := time.NewTicker(o.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				o.ProcessDue()
+			case <-o.stopChan:
+				return
+			}
+		}
+	}()
+	log.Printf("Email outbox worker started (interval: %s)", o.config.PollInterval)
+}
+
+// Stop stops the delivery worker
+func (o *Outbox) Stop() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.started {
+		return
+	}
+	close(o.stopChan)
+	o.started = false
+}
+
+// ProcessDue claims a batch of due emails and attempts delivery
+func (o *Outbox) ProcessDue() {
+	if !NewService().IsConfigured() {
+		// Leave emails queued until SMTP is configured
+		return
+	}
+
+	// Requeue emails claimed by a worker that died mid-send
+	db.DB.Exec(`
+		UPDATE email_outbox SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND updated_at < $3
+	`, OutboxPending, OutboxSending, time.Now().Add(-o.config.StaleAfter))
+
+	// SKIP LOCKED lets several API replicas run the worker without double-sending
+	var batch []OutboxEmail
+	err := db.DB.Select(&batch, `
+		UPDATE email_outbox SET status = $1, attempts = attempts + 1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = $2 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, to_address, subject, html_body, text_body, status, attempts, max_attempts,
+		          next_attempt_at, last_error, smtp_code, sent_at, created_at, updated_at
+	`, OutboxSending, OutboxPending, o.config.BatchSize)
+	if err != nil {
+		log.Printf("Email outbox: failed to claim emails: %v", err)
+		return
+	}
+
+	for _, msg := range batch {
+		o.deliver(msg)
+	}
+}
+
+func (o *Outbox) deliver(msg OutboxEmail) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.SendTimeout)
+	start := time.Now()
+	err := o.sender(ctx, msg.ToAddress, msg.Subject, msg.HTMLBody, msg.TextBody)
+	elapsed := time.Since(start)
+	cancel()
+	if err == nil {
+		metrics.RecordEmailSend(OutboxSent, elapsed)
+		// Drop the bodies once delivered; they may hold single-use links
+		db.DB.Exec(`
+			UPDATE email_outbox
+			SET status = $1, sent_at = NOW(), last_error = NULL, smtp_code = NULL,
+			    html_body = '', text_body = '', updated_at = NOW()
+			WHERE id = $2
+		`, OutboxSent, msg.ID)
+		return
+	}
+
+	code := smtpCode(err)
+	status := OutboxPending
+	switch {
+	case code >= 500:
+		status = OutboxBounced
+	case msg.Attempts >= msg.MaxAttempts:
+		status = OutboxFailed
+	}
+	result := status
+	if status == OutboxPending {
+		result = "retry"
+	}
+	metrics.RecordEmailSend(result, elapsed)
+
+	var smtpCodeArg interface{}
+	if code > 0 {
+		smtpCodeArg = code
+	}
+	db.DB.Exec(`
+		UPDATE email_outbox
+		SET status = $1, last_error = $2, smtp_code = $3, next_attempt_at = $4, updated_at = NOW()
+		WHERE id = $5
+	`, status, err.Error(), smtpCodeArg, time.Now().Add(o.backoff(msg.Attempts)), msg.ID)
+
+	if status != OutboxPending {
+		log.Printf("Email outbox: email %d to %s %s after %d attempt(s): %v", msg.ID, msg.ToAddress, status, msg.Attempts, err)
+	}
+}
+
+// backoff returns the delay before the next attempt: BaseBackoff, doubled per failed attempt
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.config.BaseBackoff
+	for i := 1; i < attempts && delay < o.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > o.config.MaxBackoff {
+		delay = o.config.MaxBackoff
+	}
+	return delay
+}
+
+// smtpCode extracts the SMTP reply code from a send error, or 0
+func smtpCode(err error) int {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code
+	}
+	return 0
+}
+
+// OutboxFilter narrows ListOutbox
+type OutboxFilter struct {
+	Status    string
+	ToAddress string
+	Since     *time.Time
+}
+
+// ListOutbox returns queued and delivered emails, newest first
+func ListOutbox(filter OutboxFilter, limit, offset int) ([]OutboxEmail, error) {
+	query := `
+		SELECT id, to_address, subject, status, attempts, max_attempts, next_attempt_at,
+		       last_error, smtp_code, sent_at, created_at, updated_at
+		FROM email_outbox
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 0
+
+	if filter.Status != "" {
+		argCount++
+		query += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, filter.Status)
+	}
+
+	if filter.ToAddress != "" {
+		argCount++
+		query += fmt.Sprintf(" AND LOWER(to_address) = LOWER($%d)", argCount)
+		args = append(args, filter.ToAddress)
+	}
+
+	if filter.Since != nil {
+		argCount++
+		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *filter.Since)
+	}
+
+	query += " ORDER BY created_at DESC"
+	argCount++
+	query += fmt.Sprintf(" LIMIT $%d", argCount)
+	args = append(args, limit)
+	argCount++
+	query += fmt.Sprintf(" OFFSET $%d", argCount)
+	args = append(args, offset)
+
+	emails := []OutboxEmail{}
+	if err := db.DB.Select(&emails, query, args...); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// OutboxCounts returns the number of emails per status
+func OutboxCounts() (map[string]int, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	if err := db.DB.Select(&rows, "SELECT status, COUNT(*) as count FROM email_outbox GROUP BY status"); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{OutboxPending: 0, OutboxSending: 0, OutboxSent: 0, OutboxFailed: 0, OutboxBounced: 0}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// RetryOutboxEmail requeues a failed or bounced email for immediate delivery
+func RetryOutboxEmail(id int64) (bool, error) {
+	result, err := db.DB.Exec(`
+		UPDATE email_outbox
+		SET status = $1, attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND status IN ($3, $4)
+	`, OutboxPending, id, OutboxFailed, OutboxBounced)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
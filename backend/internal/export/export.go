@@ -0,0 +1,90 @@
+// Package export writes tabular reports as CSV or XLSX, streaming rows as they are
+// produced so large exports don't have to be buffered, and summary reports as PDF.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ParseFormat validates a format query parameter
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case CSV, XLSX:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("format must be csv or xlsx")
+}
+
+// ContentType is the MIME type to serve the format with
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// Writer writes rows of a report. Values may be strings, integers, floats, bools,
+// times (or pointers to them) and nil.
+type Writer interface {
+	WriteRow(values ...interface{}) error
+	// Close finishes the file; for XLSX nothing is readable until it is called
+	Close() error
+}
+
+// NewWriter returns a writer for the format. sheet names the XLSX worksheet.
+func NewWriter(w io.Writer, format Format, sheet string) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w), nil
+	case XLSX:
+		return newXLSXWriter(w, sheet)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// formatValue renders a value as text; numeric reports whether it is a number
+func formatValue(v interface{}) (text string, numeric bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, false
+	case *string:
+		if v == nil {
+			return "", false
+		}
+		return *v, false
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case *int64:
+		if v == nil {
+			return "", false
+		}
+		return strconv.FormatInt(*v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), false
+	case time.Time:
+		return v.UTC().Format(time.RFC3339), false
+	case *time.Time:
+		if v == nil {
+			return "", false
+		}
+		return v.UTC().Format(time.RFC3339), false
+	}
+	return fmt.Sprint(v), false
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, CSV, "ignored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	w.WriteRow("name", "tables", "completed_at")
+	w.WriteRow("Sales, EU", 42, &completed)
+	w.WriteRow("Pending", int64(0), (*time.Time)(nil))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "name,tables,completed_at\n\"Sales, EU\",42,2026-03-01T12:00:00Z\nPending,0,\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, XLSX, "Migrations: 2026/03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteRow("name", "tables")
+	w.WriteRow("<Sales & Marketing>", 42)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(r)
+		parts[f.Name] = string(body)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Migrations_ 2026_03"`) {
+		t.Errorf("workbook = %s", parts["xl/workbook.xml"])
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, cell := range []string{
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">&lt;Sales &amp; Marketing&gt;</t></is></c>`,
+		`<c r="B2"><v>42</v></c>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("sheet is missing %s:\n%s", cell, sheet)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+	doc := NewDocument("Sales (EU) – summary")
+	doc.SetAccent("#0f766e")
+	doc.Title("Sales (EU)")
+	doc.Heading("Outstanding issues")
+	rows := make([][]string, 80)
+	for i := range rows {
+		rows[i] = []string{"dbo.orders", "failed", strings.Repeat("Conversion failed ", 10)}
+	}
+	doc.Table([]string{"Table", "Status", "Error"}, []float64{2, 1, 4}, rows)
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("not a PDF file:\n%.200s", out)
+	}
+	for _, want := range []string{
+		"/Title (Sales \\(EU\\) \x96 summary)",
+		"0.059 0.463 0.431 rg",
+		"(Outstanding issues) Tj",
+		"/BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PDF is missing %q", want)
+		}
+	}
+
+	// The table runs onto more pages, each numbered and with the header repeated
+	pages := strings.Count(out, "/Type /Page ")
+	if pages < 2 {
+		t.Fatalf("pages = %d, want the table to overflow", pages)
+	}
+	if got := strings.Count(out, "(Status) Tj"); got != pages {
+		t.Errorf("header drawn %d times on %d pages", got, pages)
+	}
+	if !strings.Contains(out, fmt.Sprintf("(Page %d of %d) Tj", pages, pages)) {
+		t.Errorf("last page footer missing")
+	}
+
+	// The xref table points at each object
+	xref := out[strings.LastIndex(out, "\nxref\n")+1:]
+	for i, line := range strings.Split(xref, "\n")[3 : 3+5+2*pages] {
+		var off int
+		fmt.Sscanf(line, "%d", &off)
+		if !strings.HasPrefix(out[off:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("xref entry %d points at %.20q", i+1, out[off:])
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("a short line\n\nsupercalifragilisticexpialidocious", fontRegular, 10, 60)
+	want := []string{"a short line", "", "supercalifragi", "listicexpialido", "cious"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q", lines)
+	}
+}
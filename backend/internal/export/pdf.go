@@ -0,0 +1,377 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A4 in points, and the layout of a report page
+const (
+	pdfPageWidth    = 595.28
+	pdfPageHeight   = 841.89
+	pdfMargin       = 50.0
+	pdfBottom       = 70.0 // Content stops here, above the footer
+	pdfFooterY      = 35.0
+	pdfContentWidth = pdfPageWidth - 2*pdfMargin
+	pdfLabelWidth   = 150.0 // Label column of Field
+	pdfCellPadding  = 4.0
+)
+
+// PDFContentType is the MIME type to serve a PDF document with
+const PDFContentType = "application/pdf"
+
+var pdfColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// The standard Helvetica fonts, which every PDF reader has, so none are embedded
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+)
+
+// Advance widths of the printable ASCII characters (32-126) in 1/1000 em, from the
+// Adobe font metrics. Other characters are measured as wide as a digit.
+var pdfFontWidths = [2][95]int{
+	{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// Characters outside Latin-1 that WinAnsiEncoding has a code for
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‰': 0x89, '‹': 0x8B, '›': 0x9B,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+type pdfColor struct{ r, g, b float64 }
+
+var (
+	pdfBlack    = pdfColor{0, 0, 0}
+	pdfWhite    = pdfColor{1, 1, 1}
+	pdfGray     = pdfColor{0.4, 0.4, 0.4}
+	pdfRuleGray = pdfColor{0.85, 0.85, 0.85}
+	pdfIndigo   = pdfColor{0.31, 0.27, 0.9} // #4f46e5, the product's indigo
+)
+
+// Document is a single-column A4 report, laid out top to bottom. Pages are added as the
+// content overflows; the file is written by WriteTo once the page count the footers
+// show is known.
+type Document struct {
+	title   string
+	created time.Time
+	accent  pdfColor
+	pages   []*bytes.Buffer
+	y       float64 // Top of the next block, in points from the bottom of the page
+}
+
+// NewDocument starts a document. The title is its metadata title and footer.
+func NewDocument(title string) *Document {
+	d := &Document{title: title, created: time.Now(), accent: pdfIndigo}
+	d.newPage()
+	return d
+}
+
+// SetAccent colors the title, headings and table headers with a #rrggbb color, such as
+// an organization's brand color. Other values are ignored.
+func (d *Document) SetAccent(hex string) {
+	if !pdfColorRegex.MatchString(hex) {
+		return
+	}
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+	d.accent = pdfColor{float64(v>>16) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255}
+}
+
+// Title writes the document's title line
+func (d *Document) Title(text string) {
+	d.lines(text, fontBold, 20, d.accent, pdfMargin, pdfContentWidth)
+	d.y -= 4
+}
+
+// Subtitle writes a line of secondary text, such as when the report was generated
+func (d *Document) Subtitle(text string) {
+	d.lines(text, fontRegular, 10, pdfGray, pdfMargin, pdfContentWidth)
+}
+
+// Heading starts a section. It moves to the next page rather than end one.
+func (d *Document) Heading(text string) {
+	d.y -= 14
+	d.ensure(lineHeight(13) + 3*lineHeight(10))
+	d.lines(text, fontBold, 13, d.accent, pdfMargin, pdfContentWidth)
+	d.rule(pdfMargin, d.y+2, pdfMargin+pdfContentWidth, pdfRuleGray)
+	d.y -= 6
+}
+
+// Paragraph writes wrapped text; newlines start new lines
+func (d *Document) Paragraph(text string) {
+	d.lines(text, fontRegular, 10, pdfBlack, pdfMargin, pdfContentWidth)
+	d.y -= 4
+}
+
+// Field writes a bold label with its value wrapped beside it. Labels are short and
+// aren't wrapped.
+func (d *Document) Field(label, value string) {
+	d.ensure(lineHeight(10))
+	d.page().WriteString(pdfText(label, fontBold, 10, pdfBlack, pdfMargin, d.y-10))
+	d.lines(value, fontRegular, 10, pdfBlack, pdfMargin+pdfLabelWidth, pdfContentWidth-pdfLabelWidth)
+	d.y -= 2
+}
+
+// Table writes rows under a header row, which is repeated on each page the table runs
+// onto. widths are the columns' shares of the page width; cells wrap within them.
+func (d *Document) Table(columns []string, widths []float64, rows [][]string) {
+	var total float64
+	for _, w := range widths {
+		total += w
+	}
+	cols := make([]float64, len(columns))
+	for i := range cols {
+		cols[i] = pdfContentWidth / float64(len(columns))
+		if len(widths) == len(columns) && total > 0 {
+			cols[i] = pdfContentWidth * widths[i] / total
+		}
+	}
+
+	header := wrapRow(columns, cols, fontBold)
+	d.ensure(header.height + lineHeight(9) + 2*pdfCellPadding)
+	d.drawRow(header, cols, fontBold, true)
+	for _, values := range rows {
+		row := wrapRow(values, cols, fontRegular)
+		if d.y-row.height < pdfBottom {
+			d.newPage()
+			d.drawRow(header, cols, fontBold, true)
+		}
+		d.drawRow(row, cols, fontRegular, false)
+	}
+	d.y -= 8
+}
+
+// WriteTo writes the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// A comment with bytes above 127 marks the file as binary for transfer tools
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title %s /Producer (DataMigrate AI) /CreationDate (D:%s) >>",
+		pdfString(d.title), d.created.UTC().Format("20060102150405Z")))
+
+	for i, page := range d.pages {
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		content := page.String() +
+			pdfText(d.title, fontRegular, 8, pdfGray, pdfMargin, pdfFooterY) +
+			pdfText(footer, fontRegular, 8, pdfGray, pdfMargin+pdfContentWidth-textWidth(footer, fontRegular, 8), pdfFooterY)
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, len(offsets)+2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure starts a new page unless height fits above the footer
+func (d *Document) ensure(height float64) {
+	if d.y-height < pdfBottom {
+		d.newPage()
+	}
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// lines writes text wrapped to width from x, moving to the next page as needed
+func (d *Document) lines(text string, font pdfFont, size float64, color pdfColor, x, width float64) {
+	lh := lineHeight(size)
+	for _, line := range wrapText(text, font, size, width) {
+		d.ensure(lh)
+		d.page().WriteString(pdfText(line, font, size, color, x, d.y-size))
+		d.y -= lh
+	}
+}
+
+func (d *Document) rule(x1, y, x2 float64, color pdfColor) {
+	fmt.Fprintf(d.page(), "%.3f %.3f %.3f RG 0.5 w %.2f %.2f m %.2f %.2f l S\n", color.r, color.g, color.b, x1, y, x2, y)
+}
+
+// pdfRow is a table row wrapped to its columns
+type pdfRow struct {
+	cells  [][]string
+	height float64
+}
+
+// wrapRow wraps the cells of a row. A row never grows beyond a page; cells too long for
+// one are cut short.
+func wrapRow(values []string, cols []float64, font pdfFont) pdfRow {
+	lh := lineHeight(9)
+	maxLines := int((pdfPageHeight - pdfMargin - pdfBottom - 4*lh) / lh)
+	row := pdfRow{cells: make([][]string, len(cols))}
+	lines := 1
+	for i := range cols {
+		if i < len(values) {
+			row.cells[i] = wrapText(values[i], font, 9, cols[i]-2*pdfCellPadding)
+		}
+		if len(row.cells[i]) > maxLines {
+			row.cells[i] = append(row.cells[i][:maxLines-1], "…")
+		}
+		lines = max(lines, len(row.cells[i]))
+	}
+	row.height = float64(lines)*lh + 2*pdfCellPadding
+	return row
+}
+
+func (d *Document) drawRow(row pdfRow, cols []float64, font pdfFont, header bool) {
+	color := pdfBlack
+	if header {
+		fmt.Fprintf(d.page(), "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n",
+			d.accent.r, d.accent.g, d.accent.b, pdfMargin, d.y-row.height, pdfContentWidth, row.height)
+		color = pdfWhite
+	}
+	x := pdfMargin
+	for i, lines := range row.cells {
+		y := d.y - pdfCellPadding - 9
+		for _, line := range lines {
+			d.page().WriteString(pdfText(line, font, 9, color, x+pdfCellPadding, y))
+			y -= lineHeight(9)
+		}
+		x += cols[i]
+	}
+	d.y -= row.height
+	if !header {
+		d.rule(pdfMargin, d.y, pdfMargin+pdfContentWidth, pdfRuleGray)
+	}
+}
+
+func lineHeight(size float64) float64 {
+	return size * 1.3
+}
+
+// pdfText is the content stream operators that draw a line of text with its baseline at y
+func pdfText(text string, font pdfFont, size float64, color pdfColor, x, y float64) string {
+	return fmt.Sprintf("BT /F%d %.1f Tf %.3f %.3f %.3f rg %.2f %.2f Td %s Tj ET\n",
+		int(font)+1, size, color.r, color.g, color.b, x, y, pdfString(text))
+}
+
+// pdfString encodes text as a PDF string literal in WinAnsiEncoding
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, c := range winAnsi(text) {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// winAnsi encodes text in the fonts' encoding. Control characters become spaces and
+// characters the fonts don't have become question marks.
+func winAnsi(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			out = append(out, ' ')
+		case r < 0x7f || (r >= 0xa0 && r <= 0xff):
+			out = append(out, byte(r))
+		default:
+			if c, ok := winAnsiExtra[r]; ok {
+				out = append(out, c)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// textWidth measures text in points
+func textWidth(text string, font pdfFont, size float64) float64 {
+	var units int
+	for _, c := range winAnsi(text) {
+		if c >= 32 && c <= 126 {
+			units += pdfFontWidths[font][c-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// wrapText breaks text into lines no wider than width, at spaces where it can and within
+// words longer than a line
+func wrapText(text string, font pdfFont, size, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, font, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = ""
+			for _, r := range word {
+				if line != "" && textWidth(line+string(r), font, size) > width {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,198 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+)
+
+func TestConnectionMSSQL(t *testing.T) {
+	result := dbtest.TestConnection(env.mssql)
+	if !result.Success {
+		t.Fatalf("TestConnection failed: %s", result.Message)
+	}
+	if !strings.Contains(result.ServerInfo, "Microsoft SQL Server") {
+		t.Errorf("ServerInfo = %q, want a SQL Server version string", result.ServerInfo)
+	}
+	if result.TableCount != 8 {
+		t.Errorf("TableCount = %d, want 8", result.TableCount)
+	}
+}
+
+func TestConnectionPostgres(t *testing.T) {
+	result := dbtest.TestConnection(env.postgres)
+	if !result.Success {
+		t.Fatalf("TestConnection failed: %s", result.Message)
+	}
+	if !strings.Contains(result.ServerInfo, "PostgreSQL") {
+		t.Errorf("ServerInfo = %q, want a PostgreSQL version string", result.ServerInfo)
+	}
+	if result.TableCount == 0 {
+		t.Error("TableCount = 0, want the application tables")
+	}
+}
+
+func TestConnectionBadCredentials(t *testing.T) {
+	params := env.mssql
+	params.Password = "wrong-password"
+
+	result := dbtest.TestConnection(params)
+	if result.Success {
+		t.Fatal("TestConnection succeeded with a wrong password")
+	}
+	if !strings.Contains(result.Message, "Connection failed") {
+		t.Errorf("Message = %q, want a connection failure", result.Message)
+	}
+}
+
+func TestConnectionUnsupportedType(t *testing.T) {
+	params := env.mssql
+	params.DBType = "oracle"
+
+	if result := dbtest.TestConnection(params); result.Success {
+		t.Fatal("TestConnection succeeded for an unsupported database type")
+	}
+}
+
+func TestExtractMetadataMSSQL(t *testing.T) {
+	result := dbtest.ExtractMetadata(env.mssql)
+	if !result.Success {
+		t.Fatalf("ExtractMetadata failed: %s", result.Error)
+	}
+
+	tables := map[string]int64{}
+	for _, table := range result.Tables {
+		tables[table.Schema+"."+table.Name] = table.RowCount
+	}
+	want := map[string]int64{
+		"Person.Person":              8,
+		"Person.Address":             8,
+		"Production.ProductCategory": 4,
+		"Production.Product":         10,
+		"Sales.SalesTerritory":       5,
+		"Sales.Customer":             8,
+		"Sales.SalesOrderHeader":     6,
+		"Sales.SalesOrderDetail":     9,
+	}
+	if len(tables) != len(want) {
+		t.Errorf("got %d tables, want %d: %v", len(tables), len(want), tables)
+	}
+	for name, rows := range want {
+		got, ok := tables[name]
+		if !ok {
+			t.Errorf("table %s missing", name)
+		} else if got != rows {
+			t.Errorf("table %s has %d rows, want %d", name, got, rows)
+		}
+	}
+
+	if len(result.Views) != 1 || result.Views[0].Schema != "Sales" || result.Views[0].Name != "vSalesSummary" {
+		t.Errorf("Views = %+v, want Sales.vSalesSummary", result.Views)
+	}
+}
+
+func TestExtractMetadataPostgres(t *testing.T) {
+	result := dbtest.ExtractMetadata(env.postgres)
+	if !result.Success {
+		t.Fatalf("ExtractMetadata failed: %s", result.Error)
+	}
+
+	found := false
+	for _, table := range result.Tables {
+		if table.Schema == "public" && table.Name == "migrations" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("public.migrations not in extracted tables: %+v", result.Tables)
+	}
+}
+
+func TestExtractMetadataColumnsAndForeignKeys(t *testing.T) {
+	var progressed int
+	result := dbtest.ExtractMetadataContext(context.Background(), env.mssql, dbtest.ExtractOptions{
+		Workers:            3,
+		IncludeColumns:     true,
+		IncludeForeignKeys: true,
+		OnProgress: func(p dbtest.MetadataProgress) {
+			if p.Stage == dbtest.StageColumns {
+				progressed = p.Completed
+			}
+		},
+	})
+	if !result.Success || result.Partial {
+		t.Fatalf("ExtractMetadataContext: success=%v partial=%v error=%q warnings=%v", result.Success, result.Partial, result.Error, result.Warnings)
+	}
+	if progressed != len(result.Tables) {
+		t.Errorf("column progress reached %d, want %d", progressed, len(result.Tables))
+	}
+
+	for _, table := range result.Tables {
+		if len(table.Columns) == 0 {
+			t.Errorf("table %s.%s has no columns", table.Schema, table.Name)
+		}
+		if table.Schema == "Sales" && table.Name == "SalesOrderDetail" {
+			if table.Columns[0].Name != "SalesOrderID" || table.Columns[0].IsNullable {
+				t.Errorf("first SalesOrderDetail column = %+v, want non-nullable SalesOrderID", table.Columns[0])
+			}
+		}
+	}
+
+	if len(result.ForeignKeys) != 8 {
+		t.Errorf("got %d foreign keys, want 8: %+v", len(result.ForeignKeys), result.ForeignKeys)
+	}
+	found := false
+	for _, fk := range result.ForeignKeys {
+		if fk.Table == "SalesOrderDetail" && fk.ReferencedTable == "Product" {
+			found = fk.Columns[0] == "ProductID" && fk.ReferencedSchema == "Production"
+		}
+	}
+	if !found {
+		t.Errorf("SalesOrderDetail.ProductID -> Production.Product not found: %+v", result.ForeignKeys)
+	}
+}
+
+func TestExtractMetadataCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if result := dbtest.ExtractMetadataContext(ctx, env.mssql, dbtest.ExtractOptions{}); result.Success {
+		t.Fatal("ExtractMetadataContext succeeded with a cancelled context")
+	}
+}
+
+func TestExtractDependenciesMSSQL(t *testing.T) {
+	graph, metadata := dbtest.ExtractDependencies(context.Background(), env.mssql)
+	if !metadata.Success || metadata.Partial {
+		t.Fatalf("ExtractDependencies: success=%v partial=%v error=%q warnings=%v", metadata.Success, metadata.Partial, metadata.Error, metadata.Warnings)
+	}
+
+	position := map[string]int{}
+	for i, id := range graph.Order {
+		position[id] = i
+	}
+	if len(graph.Cycles) != 0 {
+		t.Errorf("Cycles = %v, want none", graph.Cycles)
+	}
+	for _, edge := range graph.Edges {
+		if position[edge.From] < position[edge.To] {
+			t.Errorf("%s is ordered before its dependency %s", edge.From, edge.To)
+		}
+	}
+
+	viewDeps := map[string]bool{}
+	for _, edge := range graph.Edges {
+		if edge.Kind == dbtest.DependencyView && edge.From == "Sales.vSalesSummary" {
+			viewDeps[edge.To] = true
+		}
+	}
+	for _, want := range []string{"Sales.SalesOrderHeader", "Sales.SalesOrderDetail", "Sales.SalesTerritory"} {
+		if !viewDeps[want] {
+			t.Errorf("Sales.vSalesSummary dependency on %s missing: %v", want, viewDeps)
+		}
+	}
+}
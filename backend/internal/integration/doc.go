@@ -0,0 +1,7 @@
+// Package integration holds end-to-end tests that run against real SQL Server and
+// PostgreSQL containers (via testcontainers-go). They need Docker and are built only
+// with the "integration" tag:
+//
+//	make test-integration
+//	go test -tags integration -v ./internal/integration/...
+package integration
@@ -0,0 +1,194 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/dbtest"
+	"github.com/datamigrate-ai/backend/internal/seed"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mssql"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	_ "github.com/denisenkom/go-mssqldb"
+)
+
+const (
+	mssqlImage    = "mcr.microsoft.com/mssql/server:2022-latest"
+	postgresImage = "postgres:16-alpine"
+
+	mssqlPassword    = "Integration_Passw0rd"
+	postgresUser     = "datamigrate"
+	postgresPassword = "datamigrate123"
+	fixtureDatabase  = "AdventureWorksLite"
+)
+
+// env holds the containers shared by every test in the package
+var env struct {
+	mssql    dbtest.ConnectionParams // AdventureWorksLite source database
+	postgres dbtest.ConnectionParams // application database (schema from db.RunMigrations)
+	cfg      *config.Config
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := dockerAvailable(ctx); err != nil {
+		log.Printf("Integration tests need a Docker daemon: %v", err)
+		return 1
+	}
+
+	mssqlContainer, err := mssql.Run(ctx, mssqlImage, mssql.WithAcceptEULA(), mssql.WithPassword(mssqlPassword))
+	defer terminate(mssqlContainer)
+	if err != nil {
+		log.Printf("Failed to start SQL Server container (is Docker running?): %v", err)
+		return 1
+	}
+
+	postgresContainer, err := postgres.Run(ctx, postgresImage,
+		postgres.WithDatabase("datamigrate"),
+		postgres.WithUsername(postgresUser),
+		postgres.WithPassword(postgresPassword),
+		postgres.BasicWaitStrategies(),
+	)
+	defer terminate(postgresContainer)
+	if err != nil {
+		log.Printf("Failed to start PostgreSQL container: %v", err)
+		return 1
+	}
+
+	env.mssql, err = connectionParams(ctx, mssqlContainer, "1433/tcp", "mssql", fixtureDatabase, "sa", mssqlPassword)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	env.postgres, err = connectionParams(ctx, postgresContainer, "5432/tcp", "postgresql", "datamigrate", postgresUser, postgresPassword)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	if err := loadFixture(ctx, env.mssql); err != nil {
+		log.Printf("Failed to load AdventureWorksLite fixture: %v", err)
+		return 1
+	}
+
+	// Point the application at the Postgres container and create its schema
+	env.cfg = &config.Config{
+		DBHost:        env.postgres.Host,
+		DBPort:        strconv.Itoa(env.postgres.Port),
+		DBUser:        postgresUser,
+		DBPassword:    postgresPassword,
+		DBName:        env.postgres.Database,
+		DBSSLMode:     "disable",
+		JWTSecret:     "integration-test-secret",
+		JWTExpiration: 1,
+		Environment:   "development",
+	}
+	if err := db.Connect(env.cfg); err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer db.Close()
+	if err := db.RunMigrations(); err != nil {
+		log.Printf("Failed to run migrations: %v", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+// dockerAvailable checks for a reachable Docker daemon (testcontainers panics without one)
+func dockerAvailable(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	client, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	_, err = client.Ping(ctx)
+	return err
+}
+
+func terminate(container testcontainers.Container) {
+	if err := testcontainers.TerminateContainer(container); err != nil {
+		log.Printf("Failed to terminate container: %v", err)
+	}
+}
+
+// connectionParams builds dbtest parameters for a container's mapped port
+func connectionParams(ctx context.Context, container testcontainers.Container, port, dbType, database, user, password string) (dbtest.ConnectionParams, error) {
+	host, err := container.Host(ctx)
+	if err != nil {
+		return dbtest.ConnectionParams{}, fmt.Errorf("container host: %w", err)
+	}
+	mapped, err := container.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		return dbtest.ConnectionParams{}, fmt.Errorf("container port: %w", err)
+	}
+	return dbtest.ConnectionParams{
+		DBType:   dbType,
+		Host:     host,
+		Port:     mapped.Int(),
+		Database: database,
+		Username: user,
+		Password: password,
+	}, nil
+}
+
+var (
+	// batchSeparator matches sqlcmd's GO batch separator lines
+	batchSeparator = regexp.MustCompile(`(?m)^\s*GO\s*$`)
+	// commentLine matches blank and comment-only lines
+	commentLine = regexp.MustCompile(`(?m)^\s*(--.*)?$`)
+)
+
+// loadFixture runs the seed fixture batch by batch on a single session, as sqlcmd would
+func loadFixture(ctx context.Context, params dbtest.ConnectionParams) error {
+	dsn := fmt.Sprintf("server=%s;port=%d;database=master;user id=%s;password=%s;connection timeout=30",
+		params.Host, params.Port, params.Username, params.Password)
+	sqlDB, err := sql.Open("sqlserver", dsn)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, batch := range batchSeparator.Split(seed.FixtureSQL, -1) {
+		if strings.TrimSpace(commentLine.ReplaceAllString(batch, "")) == "" {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, batch); err != nil {
+			return fmt.Errorf("%w in batch:\n%s", err, batch)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/datamigrate-ai/backend/internal/aiservice"
+	"github.com/datamigrate-ai/backend/internal/api"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAIService records the migrations the API asks the Python AI service to start
+type fakeAIService struct {
+	mu       sync.Mutex
+	requests []aiservice.MigrationRequest
+}
+
+func (f *fakeAIService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/migrations/start" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req aiservice.MigrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"detail":"bad request"}`, http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(aiservice.MigrationResponse{Message: "Migration started", MigrationID: req.MigrationID})
+}
+
+func (f *fakeAIService) started() []aiservice.MigrationRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]aiservice.MigrationRequest(nil), f.requests...)
+}
+
+// apiCall sends a JSON request through the router and decodes the JSON response
+func apiCall(t *testing.T, router http.Handler, method, path, token string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, "/api/v1"+path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	decoded := map[string]interface{}{}
+	json.Unmarshal(rec.Body.Bytes(), &decoded)
+	return rec.Code, decoded
+}
+
+func TestMigrationStartFlow(t *testing.T) {
+	ai := &fakeAIService{}
+	aiServer := httptest.NewServer(ai)
+	defer aiServer.Close()
+	aiservice.Init(aiServer.URL)
+
+	gin.SetMode(gin.TestMode)
+	router := api.SetupRouter(env.cfg)
+
+	// Register a new organization owner
+	status, body := apiCall(t, router, "POST", "/auth/register", "", map[string]interface{}{
+		"email":             "integration@example.com",
+		"password":          "Integration-Test-Passw0rd!",
+		"first_name":        "Integration",
+		"last_name":         "Test",
+		"organization_name": "Integration Org",
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("register: status %d: %v", status, body)
+	}
+	token, _ := body["access_token"].(string)
+	if token == "" {
+		t.Fatalf("register: no access token in %v", body)
+	}
+
+	// Register the AdventureWorksLite container as a source connection
+	status, body = apiCall(t, router, "POST", "/connections", token, map[string]interface{}{
+		"name":          "AdventureWorks",
+		"db_type":       "mssql",
+		"host":          env.mssql.Host,
+		"port":          env.mssql.Port,
+		"database_name": env.mssql.Database,
+		"username":      env.mssql.Username,
+		"password":      env.mssql.Password,
+		"is_source":     true,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create connection: status %d: %v", status, body)
+	}
+	connectionID := int64(body["id"].(float64))
+
+	status, body = apiCall(t, router, "POST", fmt.Sprintf("/connections/%d/test", connectionID), token, nil)
+	if status != http.StatusOK || body["success"] != true {
+		t.Fatalf("test connection: status %d: %v", status, body)
+	}
+
+	status, body = apiCall(t, router, "GET", fmt.Sprintf("/connections/%d/metadata", connectionID), token, nil)
+	if status != http.StatusOK || body["success"] != true {
+		t.Fatalf("metadata: status %d: %v", status, body)
+	}
+	if tables, _ := body["tables"].([]interface{}); len(tables) != 8 {
+		t.Errorf("metadata: got %d tables, want 8", len(tables))
+	}
+
+	// Create and start a migration from that connection
+	status, body = apiCall(t, router, "POST", "/migrations", token, map[string]interface{}{
+		"name":           "AdventureWorks integration",
+		"connection_id":  connectionID,
+		"target_project": "adventureworks_dbt",
+		"tables":         []string{"Sales.Customer", "Sales.SalesOrderHeader"},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create migration: status %d: %v", status, body)
+	}
+	migrationID := int64(body["id"].(float64))
+
+	status, body = apiCall(t, router, "POST", fmt.Sprintf("/migrations/%d/start", migrationID), token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("start migration: status %d: %v", status, body)
+	}
+
+	started := ai.started()
+	if len(started) != 1 {
+		t.Fatalf("AI service received %d start requests, want 1", len(started))
+	}
+	req := started[0]
+	if req.MigrationID != migrationID || req.TargetProject != "adventureworks_dbt" {
+		t.Errorf("AI request = %+v, want migration %d for adventureworks_dbt", req, migrationID)
+	}
+	if req.SourceConnection["host"] != env.mssql.Host || req.SourceConnection["database"] != fixtureDatabase {
+		t.Errorf("AI request source connection = %v", req.SourceConnection)
+	}
+
+	status, body = apiCall(t, router, "GET", fmt.Sprintf("/migrations/%d", migrationID), token, nil)
+	if status != http.StatusOK || body["status"] != "running" {
+		t.Fatalf("get migration: status %d: %v, want running", status, body)
+	}
+
+	// A second start is rejected while the migration is running
+	status, _ = apiCall(t, router, "POST", fmt.Sprintf("/migrations/%d/start", migrationID), token, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("second start: status %d, want 400", status)
+	}
+
+	// The AI service reports completion through the internal status endpoint
+	status, body = apiCall(t, router, "PATCH", fmt.Sprintf("/internal/migrations/%d/status", migrationID), "", map[string]interface{}{
+		"status":           "completed",
+		"progress":         100,
+		"models_generated": 4,
+	})
+	if status != http.StatusOK {
+		t.Fatalf("update status: status %d: %v", status, body)
+	}
+
+	status, body = apiCall(t, router, "GET", fmt.Sprintf("/migrations/%d", migrationID), token, nil)
+	if status != http.StatusOK || body["status"] != "completed" || body["models_generated"] != float64(4) {
+		t.Fatalf("get migration: status %d: %v, want completed with 4 models", status, body)
+	}
+
+	// The run counts against the organization's monthly quota
+	status, body = apiCall(t, router, "GET", "/organizations/usage", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("usage: status %d: %v", status, body)
+	}
+	if !usageRecorded(body, "migrations_run", 1) {
+		t.Errorf("usage = %v, want 1 migration run", body)
+	}
+}
+
+// usageRecorded looks for a metric's used count in the usage report
+func usageRecorded(report map[string]interface{}, metric string, want float64) bool {
+	metrics, _ := report["metrics"].([]interface{})
+	for _, m := range metrics {
+		entry, _ := m.(map[string]interface{})
+		if entry["metric"] == metric {
+			return entry["used"] == want
+		}
+	}
+	return false
+}
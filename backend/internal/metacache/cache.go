@@ -0,0 +1,180 @@
+// Package metacache caches schema metadata extracted from source databases, so large
+// databases aren't rescanned on every metadata request. Entries are stored in Postgres
+// by default, or in Redis when REDIS_URL is set.
+package metacache
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultTTL is used when no TTL is configured
+const DefaultTTL = time.Hour
+
+// Entry is a cached payload and when it was stored
+type Entry struct {
+	Payload  json.RawMessage
+	CachedAt time.Time
+}
+
+// Cache stores metadata payloads by key
+type Cache interface {
+	// Get returns the entry for key, or nil if it's missing or expired
+	Get(key string) (*Entry, error)
+	Set(key string, payload interface{}) error
+}
+
+// Key identifies a source database. It hashes the location and login (never the
+// password), so two connections to the same database share an entry. variant
+// separates scans of the same database that extracted different detail.
+func Key(dbType, host string, port int, database, username, variant string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(fmt.Sprintf("%s|%s|%d|%s|%s|%s", dbType, host, port, database, username, variant))))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	settingsMu  sync.RWMutex
+	ttl         = DefaultTTL
+	redisClient *redis.Client
+)
+
+// Configure sets the TTL and, if redisURL is non-empty, switches storage to Redis
+func Configure(redisURL string, entryTTL time.Duration) error {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	if entryTTL > 0 {
+		ttl = entryTTL
+	}
+	if redisURL == "" {
+		return nil
+	}
+
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return fmt.Errorf("redis unreachable: %w", err)
+	}
+
+	redisClient = client
+	log.Printf("Metadata cache using Redis at %s (TTL %s)", opts.Addr, ttl)
+	return nil
+}
+
+// New returns the configured cache: Redis if Configure enabled it, otherwise the
+// metadata_cache table reached through store
+func New(store db.Querier) Cache {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+
+	if redisClient != nil {
+		return &redisCache{client: redisClient, ttl: ttl}
+	}
+	return &postgresCache{db: store, ttl: ttl}
+}
+
+// postgresCache stores entries in the metadata_cache table
+type postgresCache struct {
+	db  db.Querier
+	ttl time.Duration
+}
+
+func (c *postgresCache) Get(key string) (*Entry, error) {
+	var row struct {
+		Payload  []byte    `db:"payload"`
+		CachedAt time.Time `db:"cached_at"`
+	}
+	err := c.db.Get(&row, `
+		SELECT payload, cached_at FROM metadata_cache
+		WHERE cache_key = $1 AND expires_at > NOW()
+	`, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{Payload: row.Payload, CachedAt: row.CachedAt}, nil
+}
+
+func (c *postgresCache) Set(key string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.Exec(`
+		INSERT INTO metadata_cache (cache_key, payload, cached_at, expires_at)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (cache_key) DO UPDATE
+		SET payload = EXCLUDED.payload, cached_at = EXCLUDED.cached_at, expires_at = EXCLUDED.expires_at
+	`, key, string(data), time.Now().Add(c.ttl))
+	return err
+}
+
+// redisCache stores entries as JSON under metacache:<key> with a Redis TTL
+type redisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+type redisEntry struct {
+	Payload  json.RawMessage `json:"payload"`
+	CachedAt time.Time       `json:"cached_at"`
+}
+
+func redisKey(key string) string {
+	return "metacache:" + key
+}
+
+func (c *redisCache) Get(key string) (*Entry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, redisKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry redisEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &Entry{Payload: entry.Payload, CachedAt: entry.CachedAt}, nil
+}
+
+func (c *redisCache) Set(key string, payload interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(redisEntry{Payload: raw, CachedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return c.client.Set(ctx, redisKey(key), data, c.ttl).Err()
+}
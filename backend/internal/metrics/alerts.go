@@ -0,0 +1,218 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// SLOTargets are the objectives the generated alerting rules check
+type SLOTargets struct {
+	LatencyP95 float64 // Seconds, per route group
+	// LatencyExclude are route groups left out of the latency alert. chat and translate
+	// wait on the LLM; their failures show up in the AI error ratio instead.
+	LatencyExclude   []string
+	MigrationSuccess float64 // Share of finished migrations that complete rather than fail
+	AIErrorRatio     float64 // Share of AI service calls that fail
+}
+
+// DefaultSLOTargets are served by /metrics/alerts unless the query overrides them
+var DefaultSLOTargets = SLOTargets{
+	LatencyP95:       1,
+	LatencyExclude:   []string{"chat", "translate"},
+	MigrationSuccess: 0.9,
+	AIErrorRatio:     0.05,
+}
+
+// minMigrationsForSLO keeps a single failure on a quiet day from firing the success alert
+const minMigrationsForSLO = 5
+
+// routeGroupRegex matches route groups, which end up in a PromQL regex
+var routeGroupRegex = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Recording rules the alerts are built on; dashboards can use them too
+const (
+	RouteGroupLatencyP95 = "datamigrate:http_route_group_duration_seconds:p95_5m"
+	MigrationSuccessRate = "datamigrate:migration_success_ratio:6h"
+	AIErrorRate          = "datamigrate:ai_request_error_ratio:5m"
+)
+
+// SLO expressions of the recording rules, also charted by the SLO dashboard
+const (
+	routeGroupLatencyP95Expr = "histogram_quantile(0.95, sum by (group, le) (rate(datamigrate_http_route_group_duration_seconds_bucket[5m])))"
+	migrationsFinishedExpr   = `sum(increase(datamigrate_migrations_total{status=~"completed|failed"}[6h]))`
+	migrationSuccessExpr     = `(sum(increase(datamigrate_migrations_total{status="completed"}[6h])) or vector(0)) / ` + migrationsFinishedExpr
+	aiErrorRatioExpr         = `(sum(rate(datamigrate_ai_requests_total{status="failure"}[5m])) or vector(0)) / sum(rate(datamigrate_ai_requests_total[5m]))`
+)
+
+// RuleFile is a Prometheus rule file
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of rules evaluated together
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a recording rule (Record set) or an alerting rule (Alert set)
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// YAML renders the rule file as Prometheus loads it
+func (f RuleFile) YAML() (string, error) {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// AlertRules builds the SLO recording and alerting rules for the targets
+func AlertRules(t SLOTargets) RuleFile {
+	latencyExpr := RouteGroupLatencyP95
+	if len(t.LatencyExclude) > 0 {
+		latencyExpr += fmt.Sprintf(`{group!~"%s"}`, strings.Join(t.LatencyExclude, "|"))
+	}
+
+	return RuleFile{Groups: []RuleGroup{
+		{
+			Name: "datamigrate-slo",
+			Rules: []Rule{
+				{
+					Record: RouteGroupLatencyP95,
+					Expr:   routeGroupLatencyP95Expr,
+				},
+				{
+					Record: MigrationSuccessRate,
+					Expr:   migrationSuccessExpr,
+				},
+				{
+					Record: AIErrorRate,
+					Expr:   aiErrorRatioExpr,
+				},
+			},
+		},
+		{
+			Name: "datamigrate-slo-alerts",
+			Rules: []Rule{
+				{
+					Alert:  "DataMigrateHighLatency",
+					Expr:   latencyExpr + " > " + formatTarget(t.LatencyP95),
+					For:    "10m",
+					Labels: map[string]string{"severity": "warning", "slo": "latency"},
+					Annotations: map[string]string{
+						"summary":     "p95 latency of {{ $labels.group }} routes is above " + formatTarget(t.LatencyP95) + "s",
+						"description": "The 95th percentile of {{ $labels.group }} requests has taken {{ $value | humanizeDuration }} for 10 minutes.",
+					},
+				},
+				{
+					Alert:  "DataMigrateMigrationSuccessLow",
+					Expr:   fmt.Sprintf("%s < %s and %s >= %d", MigrationSuccessRate, formatTarget(t.MigrationSuccess), migrationsFinishedExpr, minMigrationsForSLO),
+					For:    "30m",
+					Labels: map[string]string{"severity": "critical", "slo": "migration_success"},
+					Annotations: map[string]string{
+						"summary":     "Fewer than " + formatPercent(t.MigrationSuccess) + " of migrations are completing",
+						"description": "{{ $value | humanizePercentage }} of the migrations finished in the last 6 hours completed; the rest failed.",
+					},
+				},
+				{
+					Alert:  "DataMigrateAIServiceErrors",
+					Expr:   AIErrorRate + " > " + formatTarget(t.AIErrorRatio),
+					For:    "10m",
+					Labels: map[string]string{"severity": "critical", "slo": "ai_errors"},
+					Annotations: map[string]string{
+						"summary":     "More than " + formatPercent(t.AIErrorRatio) + " of AI service calls are failing",
+						"description": "{{ $value | humanizePercentage }} of calls to the AI service failed to connect or got a 5xx over the last 5 minutes.",
+					},
+				},
+			},
+		},
+	}}
+}
+
+// ParseSLOTargets applies the query's overrides to the default targets: latency_p95
+// (seconds), latency_exclude (comma-separated route groups, empty for none),
+// migration_success and ai_error_ratio (0 to 1)
+func ParseSLOTargets(query url.Values, defaults SLOTargets) (SLOTargets, error) {
+	targets := defaults
+	for _, p := range []struct {
+		name   string
+		target *float64
+		max    float64
+	}{
+		{"latency_p95", &targets.LatencyP95, 300},
+		{"migration_success", &targets.MigrationSuccess, 1},
+		{"ai_error_ratio", &targets.AIErrorRatio, 1},
+	} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 || parsed > p.max {
+			return targets, fmt.Errorf("%s must be a number above 0 and up to %s", p.name, formatTarget(p.max))
+		}
+		*p.target = parsed
+	}
+	if query.Has("latency_exclude") {
+		targets.LatencyExclude = nil
+		for _, group := range strings.Split(query.Get("latency_exclude"), ",") {
+			group = strings.TrimSpace(group)
+			if group == "" {
+				continue
+			}
+			if !routeGroupRegex.MatchString(group) {
+				return targets, fmt.Errorf("invalid route group %q", group)
+			}
+			targets.LatencyExclude = append(targets.LatencyExclude, group)
+		}
+	}
+	return targets, nil
+}
+
+// AlertsHandler serves the SLO rules as a Prometheus rule file, with the targets
+// overridden by the query (see ParseSLOTargets)
+func AlertsHandler(defaults SLOTargets) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targets, err := ParseSLOTargets(c.Request.URL.Query(), defaults)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		out, err := AlertRules(targets).YAML()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build alerting rules"})
+			return
+		}
+		c.Data(http.StatusOK, "application/yaml; charset=utf-8", []byte(out))
+	}
+}
+
+func formatTarget(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatPercent(ratio float64) string {
+	return strconv.FormatFloat(math.Round(ratio*10000)/100, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+func serveAlerts(t *testing.T, query string) (int, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics/alerts", AlertsHandler(DefaultSLOTargets))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics/alerts"+query, nil))
+	return w.Code, w.Body.String()
+}
+
+func TestRouteGroup(t *testing.T) {
+	cases := map[string]string{
+		"/api/v1/migrations/:id/files/*filepath": "migrations",
+		"/api/v1/search":                         "search",
+		"/health":                                "other",
+		"":                                       "unknown",
+	}
+	for path, want := range cases {
+		if got := RouteGroup(path); got != want {
+			t.Errorf("RouteGroup(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestAlertsHandler(t *testing.T) {
+	status, body := serveAlerts(t, "?latency_p95=0.5&latency_exclude=chat,&ai_error_ratio=0.01")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d: %s", status, body)
+	}
+
+	var file RuleFile
+	if err := yaml.Unmarshal([]byte(body), &file); err != nil {
+		t.Fatalf("rules aren't YAML: %v", err)
+	}
+	exprs := map[string]string{}
+	for _, g := range file.Groups {
+		for _, r := range g.Rules {
+			exprs[r.Alert] = r.Expr
+		}
+	}
+	if want := RouteGroupLatencyP95 + `{group!~"chat"} > 0.5`; exprs["DataMigrateHighLatency"] != want {
+		t.Errorf("latency alert = %q, want %q", exprs["DataMigrateHighLatency"], want)
+	}
+	if !strings.HasPrefix(exprs["DataMigrateMigrationSuccessLow"], MigrationSuccessRate+" < 0.9 and") {
+		t.Errorf("migration success alert = %q, want the default target", exprs["DataMigrateMigrationSuccessLow"])
+	}
+	if exprs["DataMigrateAIServiceErrors"] != AIErrorRate+" > 0.01" {
+		t.Errorf("AI error alert = %q", exprs["DataMigrateAIServiceErrors"])
+	}
+}
+
+func TestAlertsHandlerValidation(t *testing.T) {
+	for _, query := range []string{"?migration_success=1.5", "?latency_p95=fast", `?latency_exclude=chat"}`} {
+		if status, body := serveAlerts(t, query); status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400: %s", query, status, body)
+		}
+	}
+}
+
+func TestAlertRulesOnlyQueryRegisteredMetrics(t *testing.T) {
+	for _, g := range AlertRules(DefaultSLOTargets).Groups {
+		for _, r := range g.Rules {
+			for _, name := range metricNameRegex.FindAllString(r.Expr, -1) {
+				if !IsRegistered(name) {
+					t.Errorf("%s%s queries %s, which isn't registered", r.Record, r.Alert, name)
+				}
+			}
+		}
+	}
+}
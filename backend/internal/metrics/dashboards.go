@@ -0,0 +1,298 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dashboard UIDs, stable so re-importing a dashboard replaces it
+const (
+	SLODashboardUID     = "datamigrate-slo"
+	MetricsDashboardUID = "datamigrate-metrics"
+)
+
+// Dashboard is a Grafana dashboard in the JSON model Grafana imports
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Editable      bool       `json:"editable"`
+	Refresh       string     `json:"refresh"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []TemplateVariable `json:"list"`
+}
+
+// TemplateVariable is a dashboard variable; the dashboards have one picking the
+// Prometheus data source, so they import into any Grafana
+type TemplateVariable struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+// Panel is a row or a time series panel
+type Panel struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	GridPos     GridPos        `json:"gridPos"`
+	Datasource  *DatasourceRef `json:"datasource,omitempty"`
+	Targets     []Target       `json:"targets,omitempty"`
+	FieldConfig *FieldConfig   `json:"fieldConfig,omitempty"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type DatasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Target is a PromQL query of a panel
+type Target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+type FieldConfig struct {
+	Defaults  FieldDefaults `json:"defaults"`
+	Overrides []struct{}    `json:"overrides"`
+}
+
+type FieldDefaults struct {
+	Unit       string                 `json:"unit,omitempty"`
+	Min        *float64               `json:"min,omitempty"`
+	Max        *float64               `json:"max,omitempty"`
+	Thresholds *Thresholds            `json:"thresholds,omitempty"`
+	Custom     map[string]interface{} `json:"custom,omitempty"`
+}
+
+// Thresholds color values from Value up; the first step's Value is nil (-Inf)
+type Thresholds struct {
+	Mode  string          `json:"mode"`
+	Steps []ThresholdStep `json:"steps"`
+}
+
+type ThresholdStep struct {
+	Color string   `json:"color"`
+	Value *float64 `json:"value"`
+}
+
+// Panel layout: two panels side by side under full-width rows
+const (
+	panelWidth  = 12
+	panelHeight = 8
+	gridWidth   = 24
+)
+
+var prometheusDatasource = &DatasourceRef{Type: "prometheus", UID: "${datasource}"}
+
+// Dashboards builds the SLO dashboard for the targets and a dashboard with a panel for
+// every metric this package registers
+func Dashboards(t SLOTargets) []Dashboard {
+	return []Dashboard{SLODashboard(t), MetricsDashboard()}
+}
+
+// SLODashboard charts the SLO expressions of the alerting rules against their targets
+func SLODashboard(t SLOTargets) Dashboard {
+	zero, one := 0.0, 1.0
+	latency := Panel{
+		Type:        "timeseries",
+		Title:       "p95 latency by route group",
+		Description: "DataMigrateHighLatency fires after 10 minutes above the line",
+		Targets:     []Target{{Expr: routeGroupLatencyP95Expr, LegendFormat: "{{group}}"}},
+		FieldConfig: thresholdConfig("s", nil, nil, "green", "red", t.LatencyP95),
+	}
+	success := Panel{
+		Type:        "timeseries",
+		Title:       "Migration success ratio (6h)",
+		Description: "Completed out of completed and failed migrations. DataMigrateMigrationSuccessLow fires after 30 minutes below the line, once " + fmt.Sprint(minMigrationsForSLO) + " migrations have finished.",
+		Targets: []Target{
+			{Expr: migrationSuccessExpr, LegendFormat: "success ratio"},
+		},
+		FieldConfig: thresholdConfig("percentunit", &zero, &one, "red", "green", t.MigrationSuccess),
+	}
+	finished := Panel{
+		Type:        "timeseries",
+		Title:       "Migrations finished (6h)",
+		Description: "Cancelled migrations don't count toward the success ratio",
+		Targets: []Target{
+			{Expr: `sum by (status) (increase(datamigrate_migrations_total{status=~"completed|failed|cancelled"}[6h]))`, LegendFormat: "{{status}}"},
+		},
+		FieldConfig: &FieldConfig{Defaults: FieldDefaults{Unit: "short", Min: &zero}, Overrides: []struct{}{}},
+	}
+	aiErrors := Panel{
+		Type:        "timeseries",
+		Title:       "AI service error ratio (5m)",
+		Description: "Calls that fail to connect or get a 5xx. DataMigrateAIServiceErrors fires after 10 minutes above the line.",
+		Targets:     []Target{{Expr: aiErrorRatioExpr, LegendFormat: "error ratio"}},
+		FieldConfig: thresholdConfig("percentunit", &zero, nil, "green", "red", t.AIErrorRatio),
+	}
+
+	return newDashboard(SLODashboardUID, "DataMigrate AI - SLOs",
+		"Latency, migration success and AI service errors against the targets of GET /metrics/alerts",
+		layout([]Panel{latency, success, finished, aiErrors}))
+}
+
+// MetricsDashboard has a row per section and a panel per metric registered in this
+// package, so it follows the metrics as they're added
+func MetricsDashboard() Dashboard {
+	var sections []string
+	bySection := map[string][]MetricInfo{}
+	for _, m := range registered {
+		if _, ok := bySection[m.Section]; !ok {
+			sections = append(sections, m.Section)
+		}
+		bySection[m.Section] = append(bySection[m.Section], m)
+	}
+
+	var panels []Panel
+	for _, section := range sections {
+		panels = append(panels, Panel{Type: "row", Title: section})
+		for _, m := range bySection[section] {
+			panels = append(panels, metricPanel(m))
+		}
+	}
+	return newDashboard(MetricsDashboardUID, "DataMigrate AI - Metrics",
+		"Every metric the DataMigrate AI backend exports, by section", layout(panels))
+}
+
+// metricPanel charts a metric: counters as a rate, gauges as they are and histograms
+// as p50 and p95
+func metricPanel(m MetricInfo) Panel {
+	panel := Panel{
+		Type:        "timeseries",
+		Title:       metricTitle(m),
+		Description: m.Help + " (" + m.Name + ")",
+		FieldConfig: &FieldConfig{Defaults: FieldDefaults{Unit: "short"}, Overrides: []struct{}{}},
+	}
+	by, legend := "", metricLegend(m)
+	if len(m.Labels) > 0 {
+		by = " by (" + strings.Join(m.Labels, ", ") + ")"
+	}
+
+	switch m.Type {
+	case TypeCounter:
+		panel.Targets = []Target{{Expr: fmt.Sprintf("sum%s (rate(%s[$__rate_interval]))", by, m.Name), LegendFormat: legend}}
+		panel.FieldConfig.Defaults.Unit = "ops"
+	case TypeGauge:
+		panel.Targets = []Target{{Expr: fmt.Sprintf("sum%s (%s)", by, m.Name), LegendFormat: legend}}
+	case TypeHistogram:
+		labels := append([]string{"le"}, m.Labels...)
+		for _, q := range []struct{ quantile, name string }{{"0.5", "p50"}, {"0.95", "p95"}} {
+			panel.Targets = append(panel.Targets, Target{
+				Expr:         fmt.Sprintf("histogram_quantile(%s, sum by (%s) (rate(%s_bucket[$__rate_interval])))", q.quantile, strings.Join(labels, ", "), m.Name),
+				LegendFormat: q.name + " " + legend,
+			})
+		}
+		if strings.HasSuffix(m.Name, "_seconds") {
+			panel.FieldConfig.Defaults.Unit = "s"
+		}
+	}
+	return panel
+}
+
+// metricTitle is the panel title of a metric, from its help text
+func metricTitle(m MetricInfo) string {
+	title := strings.TrimPrefix(m.Help, "Total number of ")
+	title = strings.ToUpper(title[:1]) + title[1:]
+	if m.Type == TypeCounter {
+		title += " per second"
+	}
+	return title
+}
+
+// metricLegend names a metric's series by its labels
+func metricLegend(m MetricInfo) string {
+	if len(m.Labels) == 0 {
+		return strings.TrimPrefix(m.Name, "datamigrate_")
+	}
+	parts := make([]string, len(m.Labels))
+	for i, l := range m.Labels {
+		parts[i] = "{{" + l + "}}"
+	}
+	return strings.Join(parts, " ")
+}
+
+func thresholdConfig(unit string, min, max *float64, below, above string, target float64) *FieldConfig {
+	return &FieldConfig{
+		Defaults: FieldDefaults{
+			Unit: unit,
+			Min:  min,
+			Max:  max,
+			Thresholds: &Thresholds{
+				Mode:  "absolute",
+				Steps: []ThresholdStep{{Color: below}, {Color: above, Value: &target}},
+			},
+			Custom: map[string]interface{}{"thresholdsStyle": map[string]string{"mode": "line"}},
+		},
+		Overrides: []struct{}{},
+	}
+}
+
+func newDashboard(uid, title, description string, panels []Panel) Dashboard {
+	return Dashboard{
+		UID:           uid,
+		Title:         title,
+		Description:   description,
+		Tags:          []string{"datamigrate"},
+		Timezone:      "browser",
+		SchemaVersion: 39,
+		Editable:      true,
+		Refresh:       "30s",
+		Time:          TimeRange{From: "now-6h", To: "now"},
+		Templating: Templating{List: []TemplateVariable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+		}},
+		Panels: panels,
+	}
+}
+
+// layout numbers the panels, points them at the data source and places them on the
+// grid: rows span the width and the panels under them go two to a line
+func layout(panels []Panel) []Panel {
+	x, y := 0, 0
+	for i := range panels {
+		p := &panels[i]
+		p.ID = i + 1
+		if p.Type == "row" {
+			if x > 0 {
+				x, y = 0, y+panelHeight
+			}
+			p.GridPos = GridPos{H: 1, W: gridWidth, X: 0, Y: y}
+			y++
+			continue
+		}
+		p.Datasource = prometheusDatasource
+		for j := range p.Targets {
+			p.Targets[j].RefID = string(rune('A' + j))
+		}
+		p.GridPos = GridPos{H: panelHeight, W: panelWidth, X: x, Y: y}
+		x += panelWidth
+		if x >= gridWidth {
+			x, y = 0, y+panelHeight
+		}
+	}
+	return panels
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"regexp"
+	"testing"
+)
+
+var metricNameRegex = regexp.MustCompile(`\bdatamigrate_[a-z_]+`)
+
+func TestMetricsDashboardCoversRegisteredMetrics(t *testing.T) {
+	charted := map[string]bool{}
+	rows := map[string]int{}
+	for _, p := range MetricsDashboard().Panels {
+		if p.Type == "row" {
+			rows[p.Title]++
+			continue
+		}
+		for _, target := range p.Targets {
+			for _, name := range metricNameRegex.FindAllString(target.Expr, -1) {
+				charted[name] = true
+			}
+		}
+	}
+
+	for _, m := range Registered() {
+		name := m.Name
+		if m.Type == TypeHistogram {
+			name += "_bucket"
+		}
+		if !charted[name] {
+			t.Errorf("%s has no panel", m.Name)
+		}
+		if rows[m.Section] != 1 {
+			t.Errorf("section %s has %d rows, want 1", m.Section, rows[m.Section])
+		}
+	}
+}
+
+func TestDashboardsOnlyQueryRegisteredMetrics(t *testing.T) {
+	for _, d := range Dashboards(DefaultSLOTargets) {
+		ids := map[int]bool{}
+		for _, p := range d.Panels {
+			if ids[p.ID] {
+				t.Errorf("%s: panel ID %d is used twice", d.UID, p.ID)
+			}
+			ids[p.ID] = true
+			for _, target := range p.Targets {
+				for _, name := range metricNameRegex.FindAllString(target.Expr, -1) {
+					if !IsRegistered(name) {
+						t.Errorf("%s: panel %q queries %s, which isn't registered", d.UID, p.Title, name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSLODashboardTargets(t *testing.T) {
+	targets := DefaultSLOTargets
+	targets.LatencyP95 = 2.5
+
+	latency := SLODashboard(targets).Panels[0]
+	steps := latency.FieldConfig.Defaults.Thresholds.Steps
+	if latency.Targets[0].Expr != routeGroupLatencyP95Expr || len(steps) != 2 || *steps[1].Value != 2.5 {
+		t.Errorf("latency panel = %+v, want the recording rule's expression with a threshold at 2.5", latency)
+	}
+}
@@ -2,17 +2,17 @@ package metrics
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
 	// HTTP request metrics
-	httpRequestsTotal = promauto.NewCounterVec(
+	httpRequestsTotal = newCounterVec("HTTP",
 		prometheus.CounterOpts{
 			Name: "datamigrate_http_requests_total",
 			Help: "Total number of HTTP requests",
@@ -20,7 +20,7 @@ var (
 		[]string{"method", "endpoint", "status"},
 	)
 
-	httpRequestDuration = promauto.NewHistogramVec(
+	httpRequestDuration = newHistogramVec("HTTP",
 		prometheus.HistogramOpts{
 			Name:    "datamigrate_http_request_duration_seconds",
 			Help:    "HTTP request duration in seconds",
@@ -29,7 +29,17 @@ var (
 		[]string{"method", "endpoint"},
 	)
 
-	httpRequestsInFlight = promauto.NewGauge(
+	// Latency SLO: one series per route group, so p95 stays cheap to compute
+	httpRouteGroupDuration = newHistogramVec("HTTP",
+		prometheus.HistogramOpts{
+			Name:    "datamigrate_http_route_group_duration_seconds",
+			Help:    "HTTP request duration in seconds by route group",
+			Buckets: []float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"group"},
+	)
+
+	httpRequestsInFlight = newGauge("HTTP",
 		prometheus.GaugeOpts{
 			Name: "datamigrate_http_requests_in_flight",
 			Help: "Number of HTTP requests currently being processed",
@@ -37,7 +47,7 @@ var (
 	)
 
 	// Migration metrics
-	MigrationsTotal = promauto.NewCounterVec(
+	MigrationsTotal = newCounterVec("Migrations",
 		prometheus.CounterOpts{
 			Name: "datamigrate_migrations_total",
 			Help: "Total number of migrations by status",
@@ -45,14 +55,14 @@ var (
 		[]string{"status"},
 	)
 
-	MigrationsInProgress = promauto.NewGauge(
+	MigrationsInProgress = newGauge("Migrations",
 		prometheus.GaugeOpts{
 			Name: "datamigrate_migrations_in_progress",
 			Help: "Number of migrations currently in progress",
 		},
 	)
 
-	MigrationDuration = promauto.NewHistogramVec(
+	MigrationDuration = newHistogramVec("Migrations",
 		prometheus.HistogramOpts{
 			Name:    "datamigrate_migration_duration_seconds",
 			Help:    "Migration duration in seconds",
@@ -62,14 +72,14 @@ var (
 	)
 
 	// Database metrics
-	DBConnectionsActive = promauto.NewGauge(
+	DBConnectionsActive = newGauge("Database",
 		prometheus.GaugeOpts{
 			Name: "datamigrate_db_connections_active",
 			Help: "Number of active database connections",
 		},
 	)
 
-	DBQueryDuration = promauto.NewHistogramVec(
+	DBQueryDuration = newHistogramVec("Database",
 		prometheus.HistogramOpts{
 			Name:    "datamigrate_db_query_duration_seconds",
 			Help:    "Database query duration in seconds",
@@ -79,7 +89,7 @@ var (
 	)
 
 	// AI Service metrics
-	AIRequestsTotal = promauto.NewCounterVec(
+	AIRequestsTotal = newCounterVec("AI service",
 		prometheus.CounterOpts{
 			Name: "datamigrate_ai_requests_total",
 			Help: "Total number of AI service requests",
@@ -87,7 +97,7 @@ var (
 		[]string{"operation", "status"},
 	)
 
-	AIRequestDuration = promauto.NewHistogramVec(
+	AIRequestDuration = newHistogramVec("AI service",
 		prometheus.HistogramOpts{
 			Name:    "datamigrate_ai_request_duration_seconds",
 			Help:    "AI service request duration in seconds",
@@ -97,7 +107,7 @@ var (
 	)
 
 	// Security metrics
-	SecurityEventsTotal = promauto.NewCounterVec(
+	SecurityEventsTotal = newCounterVec("Security",
 		prometheus.CounterOpts{
 			Name: "datamigrate_security_events_total",
 			Help: "Total number of security events",
@@ -105,7 +115,24 @@ var (
 		[]string{"event_type", "severity"},
 	)
 
-	AuthAttemptsTotal = promauto.NewCounterVec(
+	// Email metrics
+	EmailsQueuedTotal = newCounter("Email",
+		prometheus.CounterOpts{
+			Name: "datamigrate_emails_queued_total",
+			Help: "Total number of emails added to the outbox",
+		},
+	)
+
+	EmailSendDuration = newHistogramVec("Email",
+		prometheus.HistogramOpts{
+			Name:    "datamigrate_email_send_duration_seconds",
+			Help:    "SMTP delivery attempt duration in seconds by outcome",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60},
+		},
+		[]string{"result"},
+	)
+
+	AuthAttemptsTotal = newCounterVec("Security",
 		prometheus.CounterOpts{
 			Name: "datamigrate_auth_attempts_total",
 			Help: "Total number of authentication attempts",
@@ -139,7 +166,23 @@ func PrometheusMiddleware() gin.HandlerFunc {
 
 		httpRequestsTotal.WithLabelValues(c.Request.Method, endpoint, status).Inc()
 		httpRequestDuration.WithLabelValues(c.Request.Method, endpoint).Observe(duration)
+		httpRouteGroupDuration.WithLabelValues(RouteGroup(c.FullPath())).Observe(duration)
+	}
+}
+
+// RouteGroup is the group of a route for latency SLOs: the first segment after
+// /api/v1, e.g. /api/v1/migrations/:id -> migrations. Routes outside the API are
+// "other", and unmatched requests "unknown".
+func RouteGroup(fullPath string) string {
+	if fullPath == "" {
+		return "unknown"
 	}
+	rest, ok := strings.CutPrefix(fullPath, "/api/v1/")
+	if !ok || rest == "" {
+		return "other"
+	}
+	group, _, _ := strings.Cut(rest, "/")
+	return group
 }
 
 // Handler returns the Prometheus HTTP handler for the /metrics endpoint
@@ -169,6 +212,12 @@ func RecordMigrationFailed() {
 	MigrationsTotal.WithLabelValues("failed").Inc()
 }
 
+// RecordMigrationOutcome counts a migration that finished as completed, failed or
+// cancelled. The migration success SLO is completed out of completed and failed.
+func RecordMigrationOutcome(status string) {
+	MigrationsTotal.WithLabelValues(status).Inc()
+}
+
 // RecordAuthAttempt records an authentication attempt
 func RecordAuthAttempt(success bool) {
 	if success {
@@ -192,3 +241,9 @@ func RecordAIRequest(operation string, success bool, duration time.Duration) {
 	AIRequestsTotal.WithLabelValues(operation, status).Inc()
 	AIRequestDuration.WithLabelValues(operation).Observe(duration.Seconds())
 }
+
+// RecordEmailSend records an SMTP delivery attempt by the outbox worker. result is
+// the outbox status it left the email in, or "retry".
+func RecordEmailSend(result string, duration time.Duration) {
+	EmailSendDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metric types
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// MetricInfo describes a metric registered by this package
+type MetricInfo struct {
+	Name    string   `json:"name"`
+	Help    string   `json:"help"`
+	Type    string   `json:"type"`
+	Labels  []string `json:"labels,omitempty"`
+	Section string   `json:"section"` // Dashboard row, e.g. HTTP
+}
+
+// registered lists the metrics of this package in the order they're defined. Metrics
+// are defined with the helpers below so dashboards pick up new ones on their own.
+var registered []MetricInfo
+
+// Registered returns the metrics of this package in the order they're defined
+func Registered() []MetricInfo {
+	return slices.Clone(registered)
+}
+
+// IsRegistered reports whether this package registers a metric. Histograms also count
+// by the names of their _bucket, _sum and _count series.
+func IsRegistered(name string) bool {
+	for _, m := range registered {
+		if m.Name == name {
+			return true
+		}
+		if m.Type == TypeHistogram && slices.Contains([]string{m.Name + "_bucket", m.Name + "_sum", m.Name + "_count"}, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func register(section, metricType, name, help string, labels []string) {
+	registered = append(registered, MetricInfo{Name: name, Help: help, Type: metricType, Labels: labels, Section: section})
+}
+
+func newCounter(section string, opts prometheus.CounterOpts) prometheus.Counter {
+	register(section, TypeCounter, opts.Name, opts.Help, nil)
+	return promauto.NewCounter(opts)
+}
+
+func newCounterVec(section string, opts prometheus.CounterOpts, labels []string) *prometheus.CounterVec {
+	register(section, TypeCounter, opts.Name, opts.Help, labels)
+	return promauto.NewCounterVec(opts, labels)
+}
+
+func newGauge(section string, opts prometheus.GaugeOpts) prometheus.Gauge {
+	register(section, TypeGauge, opts.Name, opts.Help, nil)
+	return promauto.NewGauge(opts)
+}
+
+func newHistogramVec(section string, opts prometheus.HistogramOpts, labels []string) *prometheus.HistogramVec {
+	register(section, TypeHistogram, opts.Name, opts.Help, labels)
+	return promauto.NewHistogramVec(opts, labels)
+}
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/datamigrate-ai/backend/internal/config"
@@ -14,37 +16,150 @@ type Claims struct {
 	UserID  int64  `json:"user_id"`
 	Email   string `json:"email"`
 	IsAdmin bool   `json:"is_admin"`
+	// OrganizationID is the organization the token acts in (0 for users without one)
+	OrganizationID int64 `json:"organization_id,omitempty"`
+	// Role and Plan describe that organization when the token was issued: the user's
+	// role in it and its plan. Access tokens are short-lived, so they are never stale
+	// for long.
+	Role string `json:"role,omitempty"`
+	Plan string `json:"plan,omitempty"`
+	// Impersonation is set on tokens an admin uses to act as the user; the frontend shows
+	// it in a banner
+	Impersonation *Impersonation `json:"impersonation,omitempty"`
+	// PasswordExpired is set on tokens of a password login whose password exceeded the
+	// organization's max age; they only reach passwordExpiredRoutes
+	PasswordExpired bool `json:"password_expired,omitempty"`
 	jwt.RegisteredClaims
 }
 
-var jwtSecret []byte
+// OrgContext is the organization a request acts in
+type OrgContext struct {
+	ID   int64  // 0 for users without an organization
+	Role string // The user's role in it; empty when the token doesn't say (API keys)
+	Plan string
+}
+
+// Impersonation identifies the admin acting as a user and why
+type Impersonation struct {
+	SessionID  int64  `json:"session_id"`
+	AdminID    int64  `json:"admin_id"`
+	AdminEmail string `json:"admin_email"`
+	Reason     string `json:"reason"`
+}
+
+var (
+	jwtMu     sync.RWMutex
+	jwtSecret []byte
+	// previousJWTSecret still validates tokens issued before the last RotateJWTSecret
+	previousJWTSecret []byte
+)
+
+// passwordExpiredRoutes are the routes a token with an expired password can reach: enough
+// to show the user who they are, change the password or sign out
+var passwordExpiredRoutes = map[string]bool{
+	"GET /api/v1/auth/me":       true,
+	"PUT /api/v1/auth/password": true,
+	"POST /api/v1/auth/logout":  true,
+}
 
 func InitJWT(cfg *config.Config) {
+	jwtMu.Lock()
+	defer jwtMu.Unlock()
 	jwtSecret = []byte(cfg.JWTSecret)
+	previousJWTSecret = nil
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(userID int64, email string, isAdmin bool, expirationHours int) (string, error) {
+// RotateJWTSecret signs new tokens with secret. Tokens signed with the old secret
+// remain valid until they expire (or until the next rotation).
+func RotateJWTSecret(secret string) {
+	jwtMu.Lock()
+	defer jwtMu.Unlock()
+	previousJWTSecret = jwtSecret
+	jwtSecret = []byte(secret)
+}
+
+func jwtSecrets() (current, previous []byte) {
+	jwtMu.RLock()
+	defer jwtMu.RUnlock()
+	return jwtSecret, previousJWTSecret
+}
+
+// GenerateToken creates a new JWT access token for a user, scoped to one of their organizations
+func GenerateToken(userID int64, email string, isAdmin bool, org OrgContext, ttl time.Duration) (string, error) {
 	claims := &Claims{
-		UserID:  userID,
-		Email:   email,
-		IsAdmin: isAdmin,
+		UserID:         userID,
+		Email:          email,
+		IsAdmin:        isAdmin,
+		OrganizationID: org.ID,
+		Role:           org.Role,
+		Plan:           org.Plan,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expirationHours) * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "datamigrate-ai",
 		},
 	}
 
+	secret, _ := jwtSecrets()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(secret)
+}
+
+// GeneratePasswordExpiredToken creates an access token for a user whose password has
+// expired. It never carries admin rights and only reaches passwordExpiredRoutes.
+func GeneratePasswordExpiredToken(userID int64, email string, org OrgContext, ttl time.Duration) (string, error) {
+	claims := &Claims{
+		UserID:          userID,
+		Email:           email,
+		OrganizationID:  org.ID,
+		Role:            org.Role,
+		Plan:            org.Plan,
+		PasswordExpired: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "datamigrate-ai",
+		},
+	}
+
+	secret, _ := jwtSecrets()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secret)
+}
+
+// GenerateImpersonationToken creates a token that acts as a user on behalf of an admin.
+// It never carries admin rights and expires at expiresAt.
+func GenerateImpersonationToken(userID int64, email string, org OrgContext, impersonation Impersonation, expiresAt time.Time) (string, error) {
+	claims := &Claims{
+		UserID:         userID,
+		Email:          email,
+		OrganizationID: org.ID,
+		Role:           org.Role,
+		Plan:           org.Plan,
+		Impersonation:  &impersonation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "datamigrate-ai",
+		},
+	}
+
+	secret, _ := jwtSecrets()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secret)
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString string) (*Claims, error) {
+	secret, previous := jwtSecrets()
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		return secret, nil
 	})
+	if errors.Is(err, jwt.ErrTokenSignatureInvalid) && previous != nil {
+		token, err = jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+			return previous, nil
+		})
+	}
 
 	if err != nil {
 		return nil, err
@@ -60,6 +175,12 @@ func ValidateToken(tokenString string) (*Claims, error) {
 // AuthMiddleware is a Gin middleware that validates JWT tokens
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Already authenticated by the API key middleware
+		if _, ok := c.Get("api_key_id"); ok {
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
@@ -83,10 +204,26 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if claims.PasswordExpired && !passwordExpiredRoutes[c.Request.Method+" "+c.FullPath()] {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":            "Your password has expired. Change it to continue.",
+				"password_expired": true,
+			})
+			c.Abort()
+			return
+		}
+
 		// Store user info in context
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("is_admin", claims.IsAdmin)
+		if claims.OrganizationID != 0 {
+			c.Set("organization_id", claims.OrganizationID)
+			c.Set("token_organization", OrgContext{ID: claims.OrganizationID, Role: claims.Role, Plan: claims.Plan})
+		}
+		if claims.Impersonation != nil {
+			c.Set("impersonation", claims.Impersonation)
+		}
 
 		c.Next()
 	}
@@ -131,3 +268,25 @@ func GetOrganizationID(c *gin.Context) int64 {
 	}
 	return 0
 }
+
+// GetOrg returns the organization the request acts in, with the role and plan from the
+// token when it was issued for that organization. Handlers fall back to the database
+// when Role is empty.
+func GetOrg(c *gin.Context) OrgContext {
+	org := OrgContext{ID: GetOrganizationID(c)}
+	if claimed, exists := c.Get("token_organization"); exists {
+		if fromToken := claimed.(OrgContext); fromToken.ID == org.ID {
+			return fromToken
+		}
+	}
+	return org
+}
+
+// GetImpersonation returns the impersonation the request's token acts under, or nil
+func GetImpersonation(c *gin.Context) *Impersonation {
+	impersonation, exists := c.Get("impersonation")
+	if !exists {
+		return nil
+	}
+	return impersonation.(*Impersonation)
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTokenCarriesOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	InitJWT(&config.Config{JWTSecret: "test-secret"})
+
+	token, err := GenerateToken(42, "ana@example.com", false, OrgContext{ID: 3, Role: "admin", Plan: "professional"}, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got OrgContext
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(), func(c *gin.Context) {
+		got = GetOrg(c)
+		c.Status(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if want := (OrgContext{ID: 3, Role: "admin", Plan: "professional"}); got != want {
+		t.Errorf("GetOrg = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetOrgIgnoresClaimsForAnotherOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("token_organization", OrgContext{ID: 3, Role: "admin", Plan: "professional"})
+	c.Set("organization_id", int64(5))
+
+	if got := GetOrg(c); got != (OrgContext{ID: 5}) {
+		t.Errorf("GetOrg = %+v, want only the organization ID", got)
+	}
+}
+
+func TestExpiredTokenIsRejected(t *testing.T) {
+	InitJWT(&config.Config{JWTSecret: "test-secret"})
+
+	token, err := GenerateToken(42, "ana@example.com", false, OrgContext{}, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateToken(token); err == nil {
+		t.Error("expired token validated")
+	}
+}
+
+func TestPasswordExpiredTokenOnlyChangesPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	InitJWT(&config.Config{JWTSecret: "test-secret"})
+
+	token, err := GeneratePasswordExpiredToken(42, "ana@example.com", OrgContext{ID: 3, Role: "admin"}, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	v1 := router.Group("/api/v1", AuthMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	v1.PUT("/auth/password", ok)
+	v1.GET("/auth/me", ok)
+	v1.GET("/migrations", ok)
+	v1.PUT("/auth/profile", ok)
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPut, "/api/v1/auth/password", http.StatusNoContent},
+		{http.MethodGet, "/api/v1/auth/me", http.StatusNoContent},
+		{http.MethodGet, "/api/v1/migrations", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/auth/profile", http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: status = %d, want %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+	}
+}
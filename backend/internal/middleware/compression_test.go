@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func compressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Compression())
+	router.GET("/json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"files": strings.Repeat("models/stg_orders.sql ", 100)})
+	})
+	router.GET("/zip", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/zip", []byte("PK"))
+	})
+	router.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	})
+	return router
+}
+
+func TestCompressionGzipsJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/json", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := httptest.NewRecorder()
+	compressionRouter().ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q", rec.Header().Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(gz)
+	if !strings.Contains(string(body), "stg_orders.sql") {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestCompressionSkips(t *testing.T) {
+	tests := []struct{ path, accept string }{
+		{"/zip", "gzip"},
+		{"/json", "gzip;q=0"},
+		{"/json", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Accept-Encoding", tt.accept)
+		rec := httptest.NewRecorder()
+		compressionRouter().ServeHTTP(rec, req)
+		if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+			t.Errorf("%s with Accept-Encoding %q: Content-Encoding = %q", tt.path, tt.accept, enc)
+		}
+	}
+}
+
+func TestCompressionDecompressesRequests(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`{"name":"Sales"}`))
+	gz.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	compressionRouter().ServeHTTP(rec, req)
+	if rec.Body.String() != `{"name":"Sales"}` {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("x"))
+	req.Header.Set("Content-Encoding", "br")
+	rec = httptest.NewRecorder()
+	compressionRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want 415", rec.Code)
+	}
+}
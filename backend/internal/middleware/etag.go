@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag buffers successful GET responses, tags them with a hash of the body and answers
+// 304 Not Modified when the client already has that version. Handlers that set their
+// own ETag are passed through. Use it on large list and file responses.
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.Status() != http.StatusOK || writer.Header().Get("ETag") != "" {
+			writer.ResponseWriter.Write(writer.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(writer.body.Bytes())
+		// Weak, since Compression changes the bytes on the wire but not the content
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		writer.Header().Set("ETag", etag)
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			writer.Header().Del("Content-Type")
+			writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+			writer.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		writer.ResponseWriter.Write(writer.body.Bytes())
+	}
+}
+
+// etagMatches applies the weak comparison of If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponseWriter holds the response body until the handler has finished
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
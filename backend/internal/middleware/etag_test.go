@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Compression())
+	router.GET("/migrations", ETag(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, []string{"Sales", "Catalog"})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/migrations", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("status = %d, ETag = %q, Content-Encoding = %q", rec.Code, etag, rec.Header().Get("Content-Encoding"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/migrations", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("status = %d, body = %q, want an empty 304", rec.Code, rec.Body.String())
+	}
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Organization represents a company or team
@@ -16,22 +19,195 @@ type Organization struct {
 	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// OrganizationMembership is one of the organizations a user belongs to
+type OrganizationMembership struct {
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	Slug           string    `db:"slug" json:"slug"`
+	Plan           string    `db:"plan" json:"plan"`
+	Role           string    `db:"role" json:"role"`
+	Active         bool      `db:"-" json:"active"` // The organization the current token acts in
+	JoinedAt       time.Time `db:"created_at" json:"joined_at"`
+}
+
+// OwnershipTransfer hands an organization to another member once both the current and
+// the new owner confirm it from their email
+type OwnershipTransfer struct {
+	ID              int64      `db:"id" json:"id"`
+	OrganizationID  int64      `db:"organization_id" json:"organization_id"`
+	FromUserID      *int64     `db:"from_user_id" json:"from_user_id"` // nil for an organization without an owner
+	FromEmail       *string    `db:"from_email" json:"from_email,omitempty"`
+	ToUserID        int64      `db:"to_user_id" json:"to_user_id"`
+	ToEmail         string     `db:"to_email" json:"to_email"`
+	RequestedBy     *int64     `db:"requested_by" json:"requested_by"`
+	FromConfirmedAt *time.Time `db:"from_confirmed_at" json:"from_confirmed_at"`
+	ToConfirmedAt   *time.Time `db:"to_confirmed_at" json:"to_confirmed_at"`
+	Status          string     `db:"status" json:"status"` // pending, completed, cancelled, expired
+	ExpiresAt       time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	CompletedAt     *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// CreateOwnershipTransferRequest names the member who should become owner
+type CreateOwnershipTransferRequest struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
+
+// ConfirmOwnershipTransferRequest carries the token from a confirmation email
+type ConfirmOwnershipTransferRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SwitchOrganizationRequest selects the organization a new token acts in
+type SwitchOrganizationRequest struct {
+	OrganizationID int64 `json:"organization_id" binding:"required"`
+}
+
+// OrganizationSettings are an organization's name, slug and the defaults applied to its
+// migrations
+type OrganizationSettings struct {
+	ID                  int64                `json:"id"`
+	Name                string               `json:"name"`
+	Slug                string               `json:"slug"`
+	Plan                string               `json:"plan"`
+	OwnerID             *int64               `json:"owner_id"`
+	DefaultWarehouse    string               `json:"default_warehouse"` // Target type used when a migration has no target connection, e.g. snowflake
+	NamingConventions   NamingConventions    `json:"naming_conventions"`
+	NotificationChannel *NotificationChannel `json:"notification_channel"` // nil sends migration emails to the owner only
+	Branding            OrganizationBranding `json:"branding"`
+	SQLStyle            SQLStyle             `json:"sql_style"`
+	DataRegion          string               `json:"data_region"` // eu or us when pinned by a platform admin; read-only here
+	// AIContextEnabled shares members' migration and connection summaries with the chat
+	// assistant so it can ground its answers in them. Off until an admin turns it on.
+	AIContextEnabled bool      `json:"ai_context_enabled"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// OrganizationDefaults is the part of OrganizationSettings stored in organizations.settings
+type OrganizationDefaults struct {
+	DefaultWarehouse    string               `json:"default_warehouse,omitempty"`
+	NamingConventions   NamingConventions    `json:"naming_conventions"`
+	NotificationChannel *NotificationChannel `json:"notification_channel,omitempty"`
+	Branding            OrganizationBranding `json:"branding"`
+	SQLStyle            SQLStyle             `json:"sql_style"`
+}
+
+// SQLStyle is an organization's SQL style rules, checked when linting models
+type SQLStyle struct {
+	KeywordCase   string   `json:"keyword_case,omitempty"`    // upper or lower; consistent within a file when empty
+	MaxLineLength int      `json:"max_line_length,omitempty"` // 120 when 0
+	DisabledRules []string `json:"disabled_rules,omitempty"`  // Lint rule codes to skip, e.g. LT05
+}
+
+// NamingConventions are the model name prefixes generated projects use
+type NamingConventions struct {
+	StagingPrefix      string `json:"staging_prefix"`
+	IntermediatePrefix string `json:"intermediate_prefix"`
+	FactPrefix         string `json:"fact_prefix"`
+	DimensionPrefix    string `json:"dimension_prefix"`
+}
+
+// DefaultNamingConventions follows the dbt style guide
+var DefaultNamingConventions = NamingConventions{
+	StagingPrefix:      "stg_",
+	IntermediatePrefix: "int_",
+	FactPrefix:         "fct_",
+	DimensionPrefix:    "dim_",
+}
+
+// OrganizationBranding is the logo and colors of an organization's notification emails
+// and the landing page of its generated dbt docs
+type OrganizationBranding struct {
+	PrimaryColor string `json:"primary_color,omitempty"` // #rrggbb; banners and buttons
+	AccentColor  string `json:"accent_color,omitempty"`  // #rrggbb; end of the banner gradient
+	LogoVersion  string `json:"logo_version,omitempty"`  // Checksum prefix of the uploaded logo, empty without one
+	LogoURL      string `json:"logo_url,omitempty"`      // Public URL of the logo; not stored
+}
+
+// OrganizationDomain is a custom domain an organization hosts the product under, such as
+// app.customer.com. It serves the organization once its TXT record is verified.
+type OrganizationDomain struct {
+	ID                 int64      `db:"id" json:"id"`
+	Domain             string     `db:"domain" json:"domain"`
+	VerificationRecord string     `db:"-" json:"verification_record"` // TXT record name to publish the token under
+	VerificationToken  string     `db:"verification_token" json:"verification_token"`
+	VerifiedAt         *time.Time `db:"verified_at" json:"verified_at"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+}
+
+// AddOrganizationDomainRequest claims a custom domain
+type AddOrganizationDomainRequest struct {
+	Domain string `json:"domain" binding:"required"`
+}
+
+// TicketingIntegration is the Jira or ServiceNow instance an organization's migrations
+// open change tickets in. The API token is never returned.
+type TicketingIntegration struct {
+	Provider     string         `db:"provider" json:"provider"` // jira or servicenow
+	BaseURL      string         `db:"base_url" json:"base_url"`
+	Username     string         `db:"username" json:"username"`
+	Project      string         `db:"project" json:"project"`       // Jira project key or ServiceNow table
+	IssueType    string         `db:"issue_type" json:"issue_type"` // Jira only
+	Events       pq.StringArray `db:"events" json:"events"`         // started, completed, failed
+	LastError    *string        `db:"last_error" json:"last_error,omitempty"`
+	LastSyncedAt *time.Time     `db:"last_synced_at" json:"last_synced_at,omitempty"`
+	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// UpdateTicketingRequest links a Jira or ServiceNow instance. An empty api_token keeps
+// the stored one.
+type UpdateTicketingRequest struct {
+	Provider  string   `json:"provider" binding:"required"`
+	BaseURL   string   `json:"base_url" binding:"required"`
+	Username  string   `json:"username" binding:"required,max=255"`
+	APIToken  string   `json:"api_token" binding:"max=1000"`
+	Project   string   `json:"project" binding:"max=100"`
+	IssueType string   `json:"issue_type" binding:"max=50"`
+	Events    []string `json:"events"` // Defaults to started, completed and failed
+}
+
+// NotificationChannel is where an organization is told about finished migrations
+type NotificationChannel struct {
+	Type   string `json:"type"`   // email, slack, teams or webhook
+	Target string `json:"target"` // Email address or webhook URL
+}
+
+// UpdateOrganizationSettingsRequest changes the fields that are set
+type UpdateOrganizationSettingsRequest struct {
+	Name                *string              `json:"name"`
+	Slug                *string              `json:"slug"`
+	DefaultWarehouse    *string              `json:"default_warehouse"` // Empty clears the default
+	NamingConventions   *NamingConventions   `json:"naming_conventions"`
+	NotificationChannel *NotificationChannel `json:"notification_channel"`
+	// Branding sets the brand colors; an empty color resets it. The logo is uploaded with
+	// PUT /organizations/settings/logo.
+	Branding *OrganizationBranding `json:"branding"`
+	// SQLStyle replaces the style rules generated and pasted SQL is linted against
+	SQLStyle *SQLStyle `json:"sql_style"`
+	// AIContextEnabled turns chat grounding in members' migrations and connections on or off
+	AIContextEnabled *bool `json:"ai_context_enabled"`
+	// ClearNotificationChannel removes the channel so emails go to migration owners only
+	ClearNotificationChannel bool `json:"clear_notification_channel"`
+}
+
 // User represents a user in the system
 type User struct {
-	ID             int64      `db:"id" json:"id"`
-	Email          string     `db:"email" json:"email"`
-	Password       string     `db:"password" json:"-"` // Never expose password
-	FirstName      *string    `db:"first_name" json:"first_name,omitempty"`
-	LastName       *string    `db:"last_name" json:"last_name,omitempty"`
-	JobTitle       *string    `db:"job_title" json:"job_title,omitempty"`
-	Phone          *string    `db:"phone" json:"phone,omitempty"`
-	OrganizationID *int64     `db:"organization_id" json:"organization_id,omitempty"`
-	Role           string     `db:"role" json:"role"` // admin, member, viewer
-	IsAdmin        bool       `db:"is_admin" json:"is_admin"`
-	IsActive       bool       `db:"is_active" json:"is_active"`
-	LastLoginAt    *time.Time `db:"last_login_at" json:"last_login_at,omitempty"`
-	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+	ID                int64      `db:"id" json:"id"`
+	Email             string     `db:"email" json:"email"`
+	Password          string     `db:"password" json:"-"` // Never expose password
+	FirstName         *string    `db:"first_name" json:"first_name,omitempty"`
+	LastName          *string    `db:"last_name" json:"last_name,omitempty"`
+	JobTitle          *string    `db:"job_title" json:"job_title,omitempty"`
+	Phone             *string    `db:"phone" json:"phone,omitempty"`
+	OrganizationID    *int64     `db:"organization_id" json:"organization_id,omitempty"`
+	Role              string     `db:"role" json:"role"` // admin, member, viewer
+	IsAdmin           bool       `db:"is_admin" json:"is_admin"`
+	IsActive          bool       `db:"is_active" json:"is_active"`
+	PreferredLanguage string     `db:"preferred_language" json:"preferred_language,omitempty"` // Email language: en, da, es, pt, no, sv, de
+	Timezone          string     `db:"timezone" json:"timezone,omitempty"`                     // IANA time zone of times in emails, e.g. Europe/Copenhagen
+	LastLoginAt       *time.Time `db:"last_login_at" json:"last_login_at,omitempty"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at" json:"updated_at"`
 	// Virtual field (joined from organization)
 	Organization *Organization `db:"-" json:"organization,omitempty"`
 }
@@ -53,9 +229,10 @@ type OrganizationInvitation struct {
 type Migration struct {
 	ID               int64      `db:"id" json:"id"`
 	Name             string     `db:"name" json:"name"`
-	Status           string     `db:"status" json:"status"` // pending, running, completed, failed
+	Status           string     `db:"status" json:"status"` // pending, queued, running, completed, failed, cancelled
 	Progress         int        `db:"progress" json:"progress"`
-	SourceDatabase   string     `db:"source_database" json:"source_database"`
+	ConnectionID     *int64     `db:"connection_id" json:"connection_id"`     // Source connection; nil once it's deleted
+	SourceDatabase   string     `db:"source_database" json:"source_database"` // Source connection name, for display
 	TargetProject    string     `db:"target_project" json:"target_project"`
 	TablesCount      int        `db:"tables_count" json:"tables_count"`
 	ViewsCount       int        `db:"views_count" json:"views_count"`
@@ -63,12 +240,29 @@ type Migration struct {
 	ModelsGenerated  int        `db:"models_generated" json:"models_generated"`
 	UserID           int64      `db:"user_id" json:"user_id"`
 	Error            *string    `db:"error" json:"error,omitempty"`
-	Config           *string    `db:"config" json:"config,omitempty"` // JSON config
+	QueueReason      *string    `db:"queue_reason" json:"queue_reason,omitempty"` // While queued: connection_busy, ai_service_busy or outside_run_window
+	QueuePosition    *int       `db:"-" json:"queue_position,omitempty"`          // Place in its queue, from 1; not while waiting for a run window
+	TicketKey        *string    `db:"ticket_key" json:"ticket_key,omitempty"`     // Jira issue or ServiceNow record tracking the migration
+	TicketURL        *string    `db:"ticket_url" json:"ticket_url,omitempty"`     // Where the ticket is viewed
+	Config           *string    `db:"config" json:"config,omitempty"`             // JSON config
 	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
 	CompletedAt      *time.Time `db:"completed_at" json:"completed_at,omitempty"`
 	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at"`
 }
 
+// MigrationStatus is a migration's progress, as long-polled by clients without WebSockets
+type MigrationStatus struct {
+	ID            int64     `db:"id" json:"id"`
+	Status        string    `db:"status" json:"status"`
+	Progress      int       `db:"progress" json:"progress"`
+	Error         *string   `db:"error" json:"error,omitempty"`
+	QueueReason   *string   `db:"queue_reason" json:"queue_reason,omitempty"`
+	QueuePosition *int      `db:"-" json:"queue_position,omitempty"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	Version       string    `db:"-" json:"version"` // Pass as since to wait for the next change
+	Changed       bool      `db:"-" json:"changed"` // Differs from since; false when the wait timed out
+}
+
 // DatabaseConnection represents a saved database connection
 type DatabaseConnection struct {
 	ID             int64     `db:"id" json:"id"`
@@ -81,13 +275,63 @@ type DatabaseConnection struct {
 	Password       string    `db:"password" json:"-"` // Encrypted, never expose
 	UseWindowsAuth bool      `db:"use_windows_auth" json:"use_windows_auth"`
 	IsSource       bool      `db:"is_source" json:"is_source"`
-	UserID         int64     `db:"user_id" json:"user_id"`
+	// Migrations that may extract from the connection at once; the rest are queued
+	MaxConcurrentMigrations int   `db:"max_concurrent_migrations" json:"max_concurrent_migrations"`
+	UserID                  int64 `db:"user_id" json:"user_id"`
 	// Warehouse-specific fields (JSON stored in extra_config)
 	ExtraConfig *string   `db:"extra_config" json:"extra_config,omitempty"` // JSON for warehouse-specific settings
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// DuplicateMigration is a recent migration with the same name, source and tables as one
+// being created
+type DuplicateMigration struct {
+	ID        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Status    string    `db:"status" json:"status"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ConnectionDependent is a migration that reads from or generates for a connection
+type ConnectionDependent struct {
+	ID        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Status    string    `db:"status" json:"status"`
+	AsSource  bool      `db:"as_source" json:"as_source"` // Migration reads from the connection
+	AsTarget  bool      `db:"as_target" json:"as_target"` // Migration generates for the connection's warehouse
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ConnectionTest is the result of one connection test
+type ConnectionTest struct {
+	ID        int64     `db:"id" json:"id"`
+	Success   bool      `db:"success" json:"success"`
+	LatencyMs int       `db:"latency_ms" json:"latency_ms"`
+	Message   *string   `db:"message" json:"message,omitempty"`
+	TestedAt  time.Time `db:"tested_at" json:"tested_at"`
+}
+
+// ConnectionTestStats summarizes connection tests. Latency figures cover successful
+// tests only, since a failed test's latency is how long it took to fail.
+type ConnectionTestStats struct {
+	Day          *time.Time `db:"day" json:"day,omitempty"` // UTC day, in the daily trend
+	Tests        int        `db:"tests" json:"tests"`
+	Failures     int        `db:"failures" json:"failures"`
+	AvgLatencyMs *float64   `db:"avg_latency_ms" json:"avg_latency_ms"` // nil without a successful test
+	P95LatencyMs *float64   `db:"p95_latency_ms" json:"p95_latency_ms"`
+	MaxLatencyMs *int       `db:"max_latency_ms" json:"max_latency_ms"`
+}
+
+// ConnectionTestHistory is a connection's recent tests with its latency trend
+type ConnectionTestHistory struct {
+	ConnectionID int64                 `json:"connection_id"`
+	Days         int                   `json:"days"`    // Period the summary and trend cover
+	Summary      ConnectionTestStats   `json:"summary"` // Over the whole period
+	Daily        []ConnectionTestStats `json:"daily"`   // Days with tests, oldest first
+	Tests        []ConnectionTest      `json:"tests"`   // Newest first
+}
+
 // WarehouseDeployment represents a deployment of dbt project to a warehouse
 type WarehouseDeployment struct {
 	ID               int64      `db:"id" json:"id"`
@@ -102,64 +346,606 @@ type WarehouseDeployment struct {
 	DbtRunOutput     *string    `db:"dbt_run_output" json:"dbt_run_output,omitempty"`
 	DbtTestOutput    *string    `db:"dbt_test_output" json:"dbt_test_output,omitempty"`
 	Error            *string    `db:"error" json:"error,omitempty"`
+	DbtCloudRunID    *int64     `db:"dbt_cloud_run_id" json:"dbt_cloud_run_id,omitempty"`
+	DbtCloudRunURL   *string    `db:"dbt_cloud_run_url" json:"dbt_cloud_run_url,omitempty"`
+	RetryOf          *int64     `db:"retry_of" json:"retry_of,omitempty"` // Deployment whose failed nodes this run retries
 	UserID           int64      `db:"user_id" json:"user_id"`
 	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
 	CompletedAt      *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+	// Reconciliation compares sampled source rows with the deployed tables, for
+	// migrations that enable it
+	Reconciliation []DeploymentReconciliation `db:"-" json:"reconciliation,omitempty"`
+}
+
+// DeploymentReconciliation is the result of sampling a source table's rows and comparing
+// them with the model it was deployed to
+type DeploymentReconciliation struct {
+	SourceTable    string   `db:"source_table" json:"source_table"`
+	TargetRelation *string  `db:"target_relation" json:"target_relation,omitempty"`
+	RowsSampled    int      `db:"rows_sampled" json:"rows_sampled"`
+	RowsMissing    int      `db:"rows_missing" json:"rows_missing"`       // Sampled keys not found in the target
+	RowsMismatched int      `db:"rows_mismatched" json:"rows_mismatched"` // Found with different column values
+	MismatchPct    *float64 `db:"mismatch_pct" json:"mismatch_pct,omitempty"`
+	Error          *string  `db:"error" json:"error,omitempty"`
+}
+
+// DeploymentError is a failed model or test parsed from a deployment's dbt output
+type DeploymentError struct {
+	ID             int64              `db:"id" json:"id"`
+	Phase          string             `db:"phase" json:"phase"`                           // run, test
+	ResourceType   *string            `db:"resource_type" json:"resource_type,omitempty"` // model, test, seed, snapshot...
+	Resource       *string            `db:"resource" json:"resource,omitempty"`           // e.g. stg_orders
+	FilePath       *string            `db:"file_path" json:"file_path,omitempty"`
+	ErrorClass     string             `db:"error_class" json:"error_class"`
+	Category       string             `db:"category" json:"category"`
+	Line           *int               `db:"line" json:"line,omitempty"`
+	Message        string             `db:"message" json:"message"`
+	SuggestedFixes []KnowledgeArticle `db:"-" json:"suggested_fixes"`
+}
+
+// DeploymentRetryNode is a failed node of a dbt Cloud deployment and its retries
+type DeploymentRetryNode struct {
+	UniqueID  string    `db:"unique_id" json:"unique_id"` // e.g. model.shop.stg_orders
+	Status    string    `db:"status" json:"status"`       // failed, passed
+	Attempts  int       `db:"attempts" json:"attempts"`
+	LastRunID *int64    `db:"last_run_id" json:"last_run_id,omitempty"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DriftFinding is a difference between a generated model and its table in the warehouse
+type DriftFinding struct {
+	Model    string `json:"model"`
+	Relation string `json:"relation,omitempty"` // schema.table in the warehouse; empty when it's missing
+	Kind     string `json:"kind"`               // missing_table, missing_column, unexpected_column, type_changed
+	Column   string `json:"column,omitempty"`
+	Expected string `json:"expected,omitempty"` // Declared data type, for type_changed
+	Actual   string `json:"actual,omitempty"`
+}
+
+// MigrationDrift is the latest schema drift check of a migration's deployed models
+type MigrationDrift struct {
+	MigrationID   int64          `db:"migration_id" json:"migration_id"`
+	ConnectionID  *int64         `db:"connection_id" json:"connection_id"`
+	Status        string         `db:"status" json:"status"` // ok, drift, error
+	Findings      []DriftFinding `db:"-" json:"findings"`
+	ModelsChecked int            `db:"models_checked" json:"models_checked"`
+	Error         *string        `db:"error" json:"error,omitempty"`
+	DriftSince    *time.Time     `db:"drift_since" json:"drift_since,omitempty"`
+	CheckedAt     time.Time      `db:"checked_at" json:"checked_at"`
+}
+
+// KnowledgeArticle is a dbt best practice from the knowledge base
+type KnowledgeArticle struct {
+	ID       int64   `db:"id" json:"id"`
+	Category string  `db:"category" json:"category"`
+	Title    string  `db:"title" json:"title"`
+	Content  string  `db:"content" json:"content"`
+	Source   *string `db:"source" json:"source,omitempty"`
 }
 
 // APIKey represents an API key for programmatic access
 type APIKey struct {
+	ID         int64          `db:"id" json:"id"`
+	Name       string         `db:"name" json:"name"`
+	Key        string         `db:"key" json:"key"` // Masked; only the hash is stored
+	IsActive   bool           `db:"is_active" json:"is_active"`
+	RateLimit  int            `db:"rate_limit" json:"rate_limit"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"`
+	AllowedIPs pq.StringArray `db:"allowed_ips" json:"allowed_ips"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at,omitempty"`
+	UserID     int64          `db:"user_id" json:"user_id"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at,omitempty"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// APIActivity is one of a user's API requests, as recorded in the security audit log
+type APIActivity struct {
 	ID         int64      `db:"id" json:"id"`
-	Name       string     `db:"name" json:"name"`
-	Key        string     `db:"key" json:"key"`
-	IsActive   bool       `db:"is_active" json:"is_active"`
-	RateLimit  int        `db:"rate_limit" json:"rate_limit"`
-	UserID     int64      `db:"user_id" json:"user_id"`
-	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	Method     string     `db:"method" json:"method"`
+	Endpoint   string     `db:"endpoint" json:"endpoint"`
+	Status     int        `db:"response_status" json:"status"`
+	DurationMS *int64     `db:"duration_ms" json:"duration_ms,omitempty"`
+	IPAddress  string     `db:"ip_address" json:"ip_address"`
+	Blocked    bool       `db:"blocked" json:"blocked"`
+	Reason     string     `db:"block_reason" json:"block_reason,omitempty"`
+	ActorType  string     `db:"actor_type" json:"actor_type"` // user, api_key or service_account
+	APIKey     *APIKeyRef `db:"-" json:"api_key,omitempty"`
 	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
 }
 
+// APIKeyRef identifies the API key a request was made with. Name and Key are empty when
+// the key has since been deleted.
+type APIKeyRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name,omitempty"`
+	Key  string `json:"key,omitempty"` // Masked
+}
+
+// TriggerEvent is a migration lifecycle event in the payload format polled by no-code
+// automation tools such as Zapier and Make
+type TriggerEvent struct {
+	ID         string             `json:"id"` // Unique per occurrence; pollers deduplicate on it
+	Type       string             `json:"type"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Migration  TriggerMigration   `json:"migration"`
+	Deployment *TriggerDeployment `json:"deployment,omitempty"` // deployment.* events only
+}
+
+// TriggerMigration is the migration a trigger event is about
+type TriggerMigration struct {
+	ID              int64  `json:"id"`
+	Name            string `json:"name"`
+	RunNumber       int    `json:"run_number,omitempty"`
+	TablesCount     int    `json:"tables_count"`
+	ModelsGenerated int    `json:"models_generated"`
+	Error           string `json:"error,omitempty"`
+	URL             string `json:"url"`
+}
+
+// TriggerDeployment is the deployment a deployment.* trigger event is about
+type TriggerDeployment struct {
+	ID             int64  `json:"id"`
+	Status         string `json:"status"`
+	TestsFailed    int    `json:"tests_failed"`
+	Error          string `json:"error,omitempty"`
+	DbtCloudRunURL string `json:"dbt_cloud_run_url,omitempty"`
+}
+
+// TriggerEventType describes an event type with a sample event, for setting up a trigger
+type TriggerEventType struct {
+	Type        string       `json:"type"`
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Sample      TriggerEvent `json:"sample"`
+}
+
 // Request/Response DTOs
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Email        string `json:"email" binding:"required,email"`
+	Password     string `json:"password" binding:"required,min=6"`
+	CaptchaToken string `json:"captcha_token,omitempty"` // Required after repeated failed logins when CAPTCHA is enabled
 }
 
 type LoginResponse struct {
-	AccessToken string `json:"access_token"`
-	User        User   `json:"user"`
+	AccessToken     string `json:"access_token"`
+	RefreshToken    string `json:"refresh_token,omitempty"` // Exchange at /auth/refresh for a new access token
+	ExpiresIn       int    `json:"expires_in,omitempty"`    // Seconds until the access token expires
+	User            User   `json:"user"`
+	PasswordExpired bool   `json:"password_expired,omitempty"` // Password exceeded the organization's max age and must be changed
+}
+
+// RefreshTokenRequest exchanges a refresh token for a new session
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// OAuthCallbackRequest carries the parameters the provider redirected the browser back with
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// UserIdentity is an external sign-in account (Google, GitHub or Azure AD) linked to a user
+type UserIdentity struct {
+	Provider    string     `db:"provider" json:"provider"`
+	Email       *string    `db:"email" json:"email,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"linked_at"`
+	LastLoginAt *time.Time `db:"last_login_at" json:"last_login_at,omitempty"`
 }
 
 type RegisterRequest struct {
-	Email            string  `json:"email" binding:"required,email"`
-	Password         string  `json:"password" binding:"required,min=6"`
-	FirstName        string  `json:"first_name" binding:"required,min=1"`
-	LastName         string  `json:"last_name" binding:"required,min=1"`
-	OrganizationName string  `json:"organization_name" binding:"required,min=2"`
-	JobTitle         *string `json:"job_title"`
-	Phone            *string `json:"phone"`
+	Email             string  `json:"email" binding:"required,email"`
+	Password          string  `json:"password" binding:"required"` // Strength enforced by the password policy
+	FirstName         string  `json:"first_name" binding:"required,min=1"`
+	LastName          string  `json:"last_name" binding:"required,min=1"`
+	OrganizationName  string  `json:"organization_name" binding:"required,min=2"`
+	JobTitle          *string `json:"job_title"`
+	Phone             *string `json:"phone"`
+	CaptchaToken      string  `json:"captcha_token,omitempty"` // Required when CAPTCHA is enabled
+	PreferredLanguage *string `json:"preferred_language"`      // Email language; defaults to the best Accept-Language match
 }
 
 type UpdateProfileRequest struct {
-	FirstName *string `json:"first_name"`
-	LastName  *string `json:"last_name"`
-	Email     *string `json:"email"`
-	JobTitle  *string `json:"job_title"`
-	Phone     *string `json:"phone"`
+	FirstName         *string `json:"first_name"`
+	LastName          *string `json:"last_name"`
+	Email             *string `json:"email"`
+	JobTitle          *string `json:"job_title"`
+	Phone             *string `json:"phone"`
+	PreferredLanguage *string `json:"preferred_language"`
+	Timezone          *string `json:"timezone"` // IANA time zone; "" resets it to UTC
 }
 
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required,min=6"`
-	NewPassword     string `json:"new_password" binding:"required,min=6"`
+	NewPassword     string `json:"new_password" binding:"required"`
 }
 
 type CreateMigrationRequest struct {
-	Name           string   `json:"name" binding:"required,min=3"`
-	SourceDatabase string   `json:"source_database" binding:"required"`
-	TargetProject  string   `json:"target_project" binding:"required"`
-	Tables         []string `json:"tables"`
-	IncludeViews   bool     `json:"include_views"`
+	Name           string           `json:"name" binding:"required,min=3"`
+	ConnectionID   *int64           `json:"connection_id"`   // Source connection
+	SourceDatabase string           `json:"source_database"` // Source connection name, when connection_id isn't set
+	TargetProject  string           `json:"target_project" binding:"required"`
+	Tables         []string         `json:"tables"`
+	IncludeViews   bool             `json:"include_views"`
+	Snapshots      []SnapshotConfig `json:"snapshots"` // Tables to track as slowly changing dimensions
+	// TargetConnectionID is the warehouse the project is generated for; Fabric and Synapse
+	// targets switch generation to T-SQL-compatible dbt adapters
+	TargetConnectionID *int64 `json:"target_connection_id"`
+	// Secrets are env_var() values the generated project needs, e.g. an API token of a
+	// source system, keyed by environment variable name. They're stored encrypted.
+	Secrets map[string]string `json:"secrets"`
+	// RunWindow limits when the migration may run, e.g. only 22:00-06:00 source local time
+	RunWindow *RunWindow `json:"run_window,omitempty"`
+	// Reconciliation samples rows of the source tables after each completed deployment and
+	// compares them with the deployed models
+	Reconciliation *ReconciliationConfig `json:"reconciliation,omitempty"`
+	// TableFilters narrow the rows and columns staging models read from selected tables
+	TableFilters []TableFilter `json:"table_filters,omitempty"`
+	// TestCoverage chooses the dbt tests generated for the models; the standard set when nil
+	TestCoverage *TestCoverageConfig `json:"test_coverage,omitempty"`
+	// Scaffolding chooses the generated project's layers, folders and model naming
+	Scaffolding *ProjectScaffolding `json:"scaffolding,omitempty"`
+}
+
+// TableFilter narrows what the staging model of a source table reads from it
+type TableFilter struct {
+	Table          string   `json:"table"`                     // schema.table, as in Tables
+	Where          string   `json:"where,omitempty"`           // Row filter in the source's SQL, e.g. IsDeleted = 0
+	ExcludeColumns []string `json:"exclude_columns,omitempty"` // Columns left out of the model, e.g. SSN
+}
+
+// TestCoverage chooses the dbt tests generated for a model's columns. Unset fields
+// inherit: a table's from the migration's defaults, the defaults from the standard set
+// (not_null, unique and relationships on; accepted_values off).
+type TestCoverage struct {
+	NotNull        *bool                   `json:"not_null,omitempty"`      // not_null on columns declared NOT NULL
+	Unique         *bool                   `json:"unique,omitempty"`        // unique on primary key columns
+	Relationships  *bool                   `json:"relationships,omitempty"` // relationships on foreign key columns
+	AcceptedValues *AcceptedValuesCoverage `json:"accepted_values,omitempty"`
+}
+
+// AcceptedValuesCoverage generates accepted_values tests for columns with few distinct
+// values in the profiled sample
+type AcceptedValuesCoverage struct {
+	Enabled     bool `json:"enabled"`
+	MaxDistinct int  `json:"max_distinct,omitempty"` // Most distinct values a tested column may have; 10 when 0
+}
+
+// TableTestCoverage overrides the migration's test coverage for one table
+type TableTestCoverage struct {
+	Table string `json:"table"` // schema.table, as in Tables
+	TestCoverage
+}
+
+// TestCoverageConfig is a migration's generated test coverage: defaults for every
+// model, and overrides per table
+type TestCoverageConfig struct {
+	TestCoverage
+	Tables []TableTestCoverage `json:"tables,omitempty"`
+}
+
+// MigrationTestCoverage is a migration's test coverage as set, and as it applies with
+// the defaults filled in
+type MigrationTestCoverage struct {
+	TestCoverage TestCoverageConfig `json:"test_coverage"`
+	Effective    TestCoverageConfig `json:"effective"`
+}
+
+// ProjectScaffolding chooses the structure of a generated dbt project. Empty fields take
+// the defaults: layered, flat folders, model names as the source tables are named.
+type ProjectScaffolding struct {
+	Layers  string              `json:"layers,omitempty"`  // layered (staging, intermediate, marts) or simple (one models folder)
+	Folders string              `json:"folders,omitempty"` // flat, schema (a folder per source schema) or domain
+	Naming  string              `json:"naming,omitempty"`  // source, singular or plural model names
+	Domains []ScaffoldingDomain `json:"domains,omitempty"` // The tables in each domain folder
+}
+
+// ScaffoldingDomain is a folder of models for a business domain
+type ScaffoldingDomain struct {
+	Name   string   `json:"name"`   // Folder name, snake_case
+	Tables []string `json:"tables"` // schema.table, as in Tables
+}
+
+// ScaffoldingChoice is one answer to a question of the project scaffolding wizard
+type ScaffoldingChoice struct {
+	Value       string `json:"value"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// ScaffoldingStep is one question of the project scaffolding wizard
+type ScaffoldingStep struct {
+	Field    string              `json:"field"` // The ProjectScaffolding field it sets
+	Question string              `json:"question"`
+	Default  string              `json:"default"`
+	Choices  []ScaffoldingChoice `json:"choices"`
+}
+
+// ScaffoldingPreviewRequest asks where the models of a table selection would go
+type ScaffoldingPreviewRequest struct {
+	Scaffolding ProjectScaffolding `json:"scaffolding"`
+	Tables      []string           `json:"tables" binding:"required,min=1"`
+}
+
+// ScaffoldingPreview is the layout a scaffolding choice gives a table selection
+type ScaffoldingPreview struct {
+	Scaffolding ProjectScaffolding `json:"scaffolding"` // With the defaults filled in
+	Folders     []string           `json:"folders"`
+	Models      []ScaffoldedModel  `json:"models"`
+}
+
+// ScaffoldedModel is where a table's staging model goes
+type ScaffoldedModel struct {
+	Source string `json:"source"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+}
+
+// ColumnMask hides a sensitive column in the staging models generated from its table
+type ColumnMask struct {
+	Table  string `db:"table_name" json:"table,omitempty"` // schema.table; empty for the column in every table
+	Column string `db:"column_name" json:"column"`
+	Action string `db:"action" json:"action"` // mask (NULL), hash (SHA-256) or drop
+}
+
+// MaskingPolicy is an organization's column masking rules, applied by every migration
+// its members start
+type MaskingPolicy struct {
+	Rules     []ColumnMask `json:"rules"`
+	UpdatedAt *time.Time   `json:"updated_at,omitempty"`
+}
+
+// UpdateMaskingPolicyRequest replaces an organization's masking rules; an empty list
+// clears them
+type UpdateMaskingPolicyRequest struct {
+	Rules []ColumnMask `json:"rules" binding:"required"`
+}
+
+// SnapshotConfig marks a source table as a slowly changing dimension, generated as a
+// dbt snapshot (SCD type 2)
+type SnapshotConfig struct {
+	Table       string   `json:"table"`                  // schema.table, as in Tables
+	UniqueKey   []string `json:"unique_key"`             // Column(s) identifying a row
+	Strategy    string   `json:"strategy,omitempty"`     // timestamp (default) or check
+	UpdatedAt   string   `json:"updated_at,omitempty"`   // Last-modified column, for the timestamp strategy
+	CheckCols   []string `json:"check_cols,omitempty"`   // Columns compared by the check strategy; ["all"] for every column
+	HardDeletes string   `json:"hard_deletes,omitempty"` // ignore (default), invalidate or new_record
+}
+
+// SnapshotPreviewRequest previews the snapshot YAML for a selection before the
+// migration is created
+type SnapshotPreviewRequest struct {
+	Tables    []string         `json:"tables"`
+	Snapshots []SnapshotConfig `json:"snapshots" binding:"required,min=1"`
+}
+
+// MigrationSeed tracks a source table exported as a dbt seed
+type MigrationSeed struct {
+	ID          int64     `db:"id" json:"id"`
+	MigrationID int64     `db:"migration_id" json:"migration_id"`
+	SourceTable string    `db:"source_table" json:"source_table"`
+	SeedName    string    `db:"seed_name" json:"seed_name"`
+	Status      string    `db:"status" json:"status"` // exported, failed
+	RowCount    int       `db:"row_count" json:"row_count"`
+	Error       *string   `db:"error" json:"error,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// ExportSeedsRequest lists the tables to export as dbt seeds
+type ExportSeedsRequest struct {
+	Tables []string `json:"tables" binding:"required,min=1,max=50"`
+}
+
+// Exposure is a downstream use of a migration's models (a Power BI dataset, a Tableau
+// workbook), generated into exposures.yml
+type Exposure struct {
+	ID          int64          `db:"id" json:"id"`
+	MigrationID int64          `db:"migration_id" json:"migration_id"`
+	Name        string         `db:"name" json:"name"` // dbt exposure name (snake_case)
+	Label       *string        `db:"label" json:"label,omitempty"`
+	Type        string         `db:"type" json:"type"` // dashboard, notebook, analysis, ml, application
+	Tool        string         `db:"tool" json:"tool"` // powerbi, tableau, other
+	URL         *string        `db:"url" json:"url,omitempty"`
+	Description *string        `db:"description" json:"description,omitempty"`
+	OwnerName   *string        `db:"owner_name" json:"owner_name,omitempty"`
+	OwnerEmail  *string        `db:"owner_email" json:"owner_email,omitempty"`
+	Maturity    *string        `db:"maturity" json:"maturity,omitempty"`       // low, medium, high
+	DependsOn   pq.StringArray `db:"depends_on" json:"depends_on"`             // Source tables (schema.table) whose models it uses
+	ExternalID  *string        `db:"external_id" json:"external_id,omitempty"` // e.g. Power BI dataset ID
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// ExposureRequest creates or replaces an exposure
+type ExposureRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Label       *string  `json:"label"`
+	Type        string   `json:"type" binding:"required"`
+	Tool        string   `json:"tool"`
+	URL         *string  `json:"url"`
+	Description *string  `json:"description"`
+	OwnerName   *string  `json:"owner_name"`
+	OwnerEmail  *string  `json:"owner_email"`
+	Maturity    *string  `json:"maturity"`
+	DependsOn   []string `json:"depends_on" binding:"required,min=1"`
+	ExternalID  *string  `json:"external_id"`
+}
+
+// DiscoverExposuresRequest optionally registers discovered Power BI datasets as exposures
+type DiscoverExposuresRequest struct {
+	Register   bool     `json:"register"`
+	DependsOn  []string `json:"depends_on"` // Source tables the registered exposures depend on
+	OwnerName  *string  `json:"owner_name"`
+	OwnerEmail *string  `json:"owner_email"`
+}
+
+// MigrationConfig is stored in migrations.config and read when the migration starts
+type MigrationConfig struct {
+	Tables       []string         `json:"tables,omitempty"`
+	IncludeViews bool             `json:"include_views,omitempty"`
+	Snapshots    []SnapshotConfig `json:"snapshots,omitempty"`
+	// TargetConnectionID is the warehouse connection generation targets
+	TargetConnectionID *int64 `json:"target_connection_id,omitempty"`
+	// Secrets are stored encrypted and only decrypted when the migration starts
+	Secrets map[string]string `json:"secrets,omitempty"`
+	// RunWindow holds the migration in the queue outside the hours it may run
+	RunWindow *RunWindow `json:"run_window,omitempty"`
+	// Reconciliation validates completed deployments against the source
+	Reconciliation *ReconciliationConfig `json:"reconciliation,omitempty"`
+	TableFilters   []TableFilter         `json:"table_filters,omitempty"`
+	TestCoverage   *TestCoverageConfig   `json:"test_coverage,omitempty"`
+	Scaffolding    *ProjectScaffolding   `json:"scaffolding,omitempty"`
+}
+
+// ReconciliationConfig chooses the source tables whose rows are sampled after a deployment
+// and compared with the deployed models: rows are matched on their keys and the values of
+// the compared columns checksummed on both sides
+type ReconciliationConfig struct {
+	SampleSize int                   `json:"sample_size,omitempty"` // Rows sampled per table; 1000 when 0
+	Tables     []ReconciliationTable `json:"tables"`
+}
+
+// ReconciliationTable is a source table compared with the relation it was migrated to.
+// Column names are matched case-insensitively in both databases.
+type ReconciliationTable struct {
+	Table      string   `json:"table"`             // schema.table in the source, as in Tables
+	Model      string   `json:"model,omitempty"`   // Relation in the warehouse; the table's staging model when empty
+	KeyColumns []string `json:"key_columns"`       // Column(s) identifying a row in both
+	Columns    []string `json:"columns,omitempty"` // Columns whose values are compared; only the keys when empty
+}
+
+// RunWindow limits when a migration may run. Started outside its windows, it waits in
+// the queue until one opens.
+type RunWindow struct {
+	Timezone string       `json:"timezone,omitempty"` // IANA time zone of the windows, e.g. the source's; UTC when empty
+	Windows  []TimeWindow `json:"windows"`
+}
+
+// TranslateRequest is an ad-hoc T-SQL snippet to translate in the playground
+type TranslateRequest struct {
+	SQL           string `json:"sql" binding:"required"`
+	TargetDialect string `json:"target_dialect" binding:"required"` // Warehouse type, e.g. snowflake
+	Format        string `json:"format"`                            // dbt (default) for a dbt model, sql for plain SQL
+}
+
+// LintRequest is SQL to lint: pasted SQL, or a generated file of a migration
+type LintRequest struct {
+	SQL         string `json:"sql"`
+	MigrationID *int64 `json:"migration_id"` // With Path, lints the file from the migration's current run
+	Path        string `json:"path"`         // e.g. models/staging/stg_orders.sql
+	// TargetDialect is the warehouse type the SQL must run on; it defaults to the
+	// migration's target connection or the organization's default warehouse
+	TargetDialect string `json:"target_dialect"`
+	Format        string `json:"format"` // dbt (default) for a dbt model, sql for plain SQL
+}
+
+// SQLTranslation is a translated snippet in a user's playground history
+type SQLTranslation struct {
+	ID            int64          `db:"id" json:"id"`
+	TargetDialect string         `db:"target_dialect" json:"target_dialect"`
+	Format        string         `db:"format" json:"format"`
+	SourceSQL     string         `db:"source_sql" json:"source_sql"`
+	TranslatedSQL string         `db:"translated_sql" json:"translated_sql"`
+	Warnings      pq.StringArray `db:"warnings" json:"warnings"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+}
+
+// ChatAction is an action the chat assistant proposed. It runs only when the user
+// confirms it, and can be confirmed once until it expires.
+type ChatAction struct {
+	ID          int64           `db:"id" json:"id"`
+	Tool        string          `db:"tool" json:"tool"`           // e.g. create_draft_migration
+	Arguments   json.RawMessage `db:"arguments" json:"arguments"` // e.g. a DraftMigration
+	Summary     string          `db:"summary" json:"summary"`     // What confirming does
+	Status      string          `db:"status" json:"status"`       // pending, confirmed, cancelled, failed
+	MigrationID *int64          `db:"migration_id" json:"migration_id,omitempty"`
+	Error       *string         `db:"error" json:"error,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time       `db:"expires_at" json:"expires_at"`
+}
+
+// DraftMigration is a pending migration the chat assistant proposed, with its
+// connections resolved. Tables empty with Schema set means every table in the schema,
+// listed from the source when the action is confirmed.
+type DraftMigration struct {
+	Name               string   `json:"name"`
+	TargetProject      string   `json:"target_project"`
+	SourceConnectionID int64    `json:"source_connection_id"`
+	SourceConnection   string   `json:"source_connection"`
+	TargetConnectionID *int64   `json:"target_connection_id,omitempty"`
+	TargetConnection   string   `json:"target_connection,omitempty"`
+	Schema             string   `json:"schema,omitempty"`
+	Tables             []string `json:"tables,omitempty"`
+}
+
+// StartMigrationRequest is the optional body of POST /migrations/:id/start
+type StartMigrationRequest struct {
+	// DryRun reads the source schema and plans the models without generating them
+	DryRun bool `json:"dry_run"`
+}
+
+// PlanMigrationRequest is the optional body of POST /migrations/:id/plan. Set fields
+// replace the migration's own settings in the plan, and aren't saved.
+type PlanMigrationRequest struct {
+	Tables       []string            `json:"tables,omitempty"`
+	IncludeViews *bool               `json:"include_views,omitempty"`
+	Scaffolding  *ProjectScaffolding `json:"scaffolding,omitempty"`
+	TestCoverage *TestCoverageConfig `json:"test_coverage,omitempty"`
+}
+
+// PlannedModel is a dbt model a migration would generate
+type PlannedModel struct {
+	Name      string   `json:"name"`
+	Source    string   `json:"source"`               // Source table or view, e.g. Sales.Customer
+	Type      string   `json:"type"`                 // staging, seed or snapshot
+	Path      string   `json:"path,omitempty"`       // Staging models' file in the project
+	DependsOn []string `json:"depends_on,omitempty"` // Models it would ref()
+}
+
+// PlannedSource is a source table or view the planned models would select from
+type PlannedSource struct {
+	Source string `json:"source"` // dbt source name
+	Name   string `json:"name"`   // Table name within the source
+	Table  string `json:"table"`  // e.g. Sales.Customer
+}
+
+// PlannedTest is a dbt test a migration would generate on a model's column
+type PlannedTest struct {
+	Model  string `json:"model"`
+	Column string `json:"column"`
+	Test   string `json:"test"`            // not_null, unique or relationships
+	To     string `json:"to,omitempty"`    // Model a relationships test refers to
+	Field  string `json:"field,omitempty"` // Column of To a relationships test refers to
+}
+
+// MigrationPlanCounts estimates the size of a migration
+type MigrationPlanCounts struct {
+	Tables      int   `json:"tables"`
+	Views       int   `json:"views"`
+	Columns     int   `json:"columns"`
+	ForeignKeys int   `json:"foreign_keys"`
+	Rows        int64 `json:"rows"` // Approximate, from catalog statistics
+	Models      int   `json:"models"`
+	Seeds       int   `json:"seeds"`
+	Snapshots   int   `json:"snapshots"`
+	Sources     int   `json:"sources"`
+	Tests       int   `json:"tests"`
+}
+
+// MigrationPlan is the outcome of a dry run: the models generation would produce, in
+// build order, the sources and tests that come with them, and anything likely to need
+// attention
+type MigrationPlan struct {
+	MigrationID int64               `json:"migration_id"`
+	DryRun      bool                `json:"dry_run"`
+	Scaffolding ProjectScaffolding  `json:"scaffolding"` // With every default filled in
+	Models      []PlannedModel      `json:"models"`
+	Sources     []PlannedSource     `json:"sources"`
+	Tests       []PlannedTest       `json:"tests"`
+	Counts      MigrationPlanCounts `json:"counts"`
+	Warnings    []string            `json:"warnings"`
 }
 
 type CreateConnectionRequest struct {
@@ -172,22 +958,96 @@ type CreateConnectionRequest struct {
 	Password       string `json:"password"`
 	UseWindowsAuth bool   `json:"use_windows_auth"`
 	IsSource       bool   `json:"is_source"`
+	// Migrations that may extract from the connection at once (default 1; unchanged on
+	// update when omitted)
+	MaxConcurrentMigrations *int `json:"max_concurrent_migrations,omitempty" binding:"omitempty,min=1,max=20"`
+	// Warehouse settings stored in extra_config; required for fabric
+	ExtraConfig *ConnectionExtraConfig `json:"extra_config,omitempty"`
+}
+
+// ConnectionExtraConfig holds warehouse settings that don't fit the common connection
+// columns. For Microsoft Fabric the username and password are a service principal's
+// client ID and secret.
+type ConnectionExtraConfig struct {
+	TenantID  string `json:"tenant_id,omitempty"` // Entra ID tenant of the service principal
+	Workspace string `json:"workspace,omitempty"` // Fabric workspace name or ID
+	Lakehouse string `json:"lakehouse,omitempty"` // Lakehouse staging models read from, if any
+	// Throttle limits the load extraction puts on a source database
+	Throttle *ExtractionThrottle `json:"throttle,omitempty"`
+	// ReadReplica marks the host as a read-only replica; connections to it ask for
+	// read-only access (SQL Server and PostgreSQL)
+	ReadReplica bool `json:"read_replica,omitempty"`
+	// ReadIsolation runs metadata and profiling queries at snapshot or read_uncommitted
+	// (NOLOCK) isolation so they don't block production transactions (SQL Server only)
+	ReadIsolation string `json:"read_isolation,omitempty"`
+}
+
+// ExtractionThrottle limits how hard extraction queries a source database, so a
+// migration doesn't slow down the OLTP workload it reads from. Zero values leave the AI
+// service's defaults.
+type ExtractionThrottle struct {
+	MaxParallelQueries int    `json:"max_parallel_queries,omitempty"` // Queries run against the source at once
+	FetchSize          int    `json:"fetch_size,omitempty"`           // Rows fetched per round trip
+	Timezone           string `json:"timezone,omitempty"`             // IANA time zone of the windows; UTC when empty
+	// OffPeakWindows are when extraction may run; it pauses outside them. Always when empty.
+	OffPeakWindows []TimeWindow `json:"off_peak_windows,omitempty"`
+}
+
+// TimeWindow is a daily time range, e.g. when extraction or a migration may run. A window
+// whose end is before its start runs past midnight.
+type TimeWindow struct {
+	Days  []string `json:"days,omitempty"` // mon..sun; every day when empty
+	Start string   `json:"start"`          // HH:MM
+	End   string   `json:"end"`            // HH:MM
 }
 
 type CreateAPIKeyRequest struct {
-	Name      string `json:"name" binding:"required"`
-	RateLimit int    `json:"rate_limit"`
+	Name          string   `json:"name" binding:"required"`
+	RateLimit     int      `json:"rate_limit"`
+	Scopes        []string `json:"scopes" binding:"required"`  // read:migrations, write:migrations, read:connections, deploy
+	ExpiresInDays *int     `json:"expires_in_days,omitempty"` // Defaults to 90; 0 for no expiry
+	AllowedIPs    []string `json:"allowed_ips,omitempty"`     // IPs or CIDR ranges
 }
 
 // ForgotPasswordRequest is used to request a password reset email
 type ForgotPasswordRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	Email        string `json:"email" binding:"required,email"`
+	CaptchaToken string `json:"captcha_token,omitempty"` // Required when CAPTCHA is enabled
 }
 
 // ResetPasswordRequest is used to reset password with a token
 type ResetPasswordRequest struct {
 	Token       string `json:"token" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ImpersonationSession is a period in which an admin acted as a user
+type ImpersonationSession struct {
+	ID             int64      `db:"id" json:"id"`
+	AdminID        *int64     `db:"admin_id" json:"admin_id,omitempty"`
+	AdminEmail     string     `db:"admin_email" json:"admin_email"`
+	UserID         int64      `db:"user_id" json:"user_id"`
+	UserEmail      string     `db:"user_email" json:"user_email"`
+	OrganizationID *int64     `db:"organization_id" json:"organization_id,omitempty"`
+	Reason         string     `db:"reason" json:"reason"`
+	StartedAt      time.Time  `db:"started_at" json:"started_at"`
+	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
+	EndedAt        *time.Time `db:"ended_at" json:"ended_at,omitempty"`
+	ActionCount    int        `db:"action_count" json:"action_count"`
+	LastActionAt   *time.Time `db:"last_action_at" json:"last_action_at,omitempty"`
+}
+
+// StartImpersonationRequest names the user to act as and why
+type StartImpersonationRequest struct {
+	UserID          int64  `json:"user_id" binding:"required"`
+	Reason          string `json:"reason" binding:"required"` // Shown to the user in their impersonation history
+	OrganizationID  int64  `json:"organization_id,omitempty"` // Defaults to the user's default organization
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+}
+
+// ReportLoginRequest carries the token from the "This wasn't me" link in a new sign-in email
+type ReportLoginRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
 type DashboardStats struct {
@@ -195,7 +1055,21 @@ type DashboardStats struct {
 	CompletedMigrations int     `json:"completed_migrations"`
 	RunningMigrations   int     `json:"running_migrations"`
 	FailedMigrations    int     `json:"failed_migrations"`
+	CancelledMigrations int     `json:"cancelled_migrations"`
 	SuccessRate         float64 `json:"success_rate"`
+	// AIService is the load of each AI service instance, when it reports one
+	AIService []AIServiceCapacity `json:"ai_service,omitempty"`
+}
+
+// AIServiceCapacity is an AI service instance's load and the migrations waiting for it
+type AIServiceCapacity struct {
+	Region     string    `json:"region,omitempty"` // Empty for the default instance
+	Slots      int       `json:"slots"`            // Migrations it runs at once
+	Active     int       `json:"active"`           // Migrations it's running
+	QueueDepth int       `json:"queue_depth"`      // Work waiting inside the instance
+	Saturated  bool      `json:"saturated"`
+	Queued     int       `json:"queued"` // Migrations queued for one of its slots
+	CheckedAt  time.Time `json:"checked_at"`
 }
 
 // CreateWarehouseConnectionRequest for adding warehouse connections
@@ -225,6 +1099,266 @@ type DeployToWarehouseRequest struct {
 	FullRefresh  bool  `json:"full_refresh"`
 }
 
+// DbtCloudJob links a migration to the dbt Cloud project and job created for it
+type DbtCloudJob struct {
+	ID            int64     `db:"id" json:"id"`
+	MigrationID   int64     `db:"migration_id" json:"migration_id"`
+	AccountID     int64     `db:"account_id" json:"account_id"`
+	ProjectID     int64     `db:"project_id" json:"project_id"`
+	RepositoryID  int64     `db:"repository_id" json:"repository_id"`
+	EnvironmentID int64     `db:"environment_id" json:"environment_id"`
+	JobID         int64     `db:"job_id" json:"job_id"`
+	RepositoryURL string    `db:"repository_url" json:"repository_url"`
+	ScheduleCron  *string   `db:"schedule_cron" json:"schedule_cron,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
+
+// CreateDbtCloudJobRequest provisions a dbt Cloud project, environment and job for a migration.
+// The generated project must already be pushed to RepositoryURL.
+type CreateDbtCloudJobRequest struct {
+	ProjectName          string   `json:"project_name"`                      // Defaults to the migration's target project
+	RepositoryURL        string   `json:"repository_url" binding:"required"` // e.g. git@github.com:acme/sales_dbt.git
+	GitCloneStrategy     string   `json:"git_clone_strategy"`                // deploy_key (default) or github_app
+	GithubInstallationID int64    `json:"github_installation_id"`            // Required for github_app
+	EnvironmentName      string   `json:"environment_name"`                  // Defaults to Production
+	DbtVersion           string   `json:"dbt_version"`                       // Defaults to latest
+	CredentialsID        int64    `json:"credentials_id"`                    // Existing warehouse credentials in dbt Cloud
+	ScheduleCron         string   `json:"schedule_cron"`                     // Empty for an on-demand job
+	ExecuteSteps         []string `json:"execute_steps"`                     // Defaults to dbt build
+}
+
+// BulkRequest lists the resources a batch endpoint acts on
+type BulkRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1,max=100"`
+}
+
+// BulkTagRequest adds and removes tags on several migrations or connections
+type BulkTagRequest struct {
+	ResourceType string   `json:"resource_type" binding:"required,oneof=migration connection"`
+	IDs          []int64  `json:"ids" binding:"required,min=1,max=100"`
+	Add          []string `json:"add"`
+	Remove       []string `json:"remove"`
+}
+
+// BulkItemResult is the outcome for one resource in a batch request. Status is the
+// HTTP status the single-resource endpoint would have returned.
+type BulkItemResult struct {
+	ID      int64       `json:"id"`
+	Success bool        `json:"success"`
+	Status  int         `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// BulkResponse reports per-item results for a batch request
+type BulkResponse struct {
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+}
+
+// Tag is a label on a migration or connection
+type Tag struct {
+	ResourceType string `db:"resource_type" json:"resource_type"`
+	ResourceID   int64  `db:"resource_id" json:"resource_id"`
+	Tag          string `db:"tag" json:"tag"`
+}
+
+// MigrationComment is a note left on a migration
+type MigrationComment struct {
+	ID          int64     `db:"id" json:"id"`
+	MigrationID int64     `db:"migration_id" json:"migration_id"`
+	UserID      *int64    `db:"user_id" json:"user_id,omitempty"`
+	Body        string    `db:"body" json:"body"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// CreateCommentRequest adds a comment to a migration
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+// PhaseMetrics is the resource usage of one migration phase. The AI service reports it
+// in the metrics list of the internal status update; a phase reported again replaces
+// the earlier figures.
+type PhaseMetrics struct {
+	Phase            string    `db:"phase" json:"phase" binding:"required,max=50"`
+	DurationMs       int64     `db:"duration_ms" json:"duration_ms" binding:"min=0"`
+	PromptTokens     int64     `db:"prompt_tokens" json:"prompt_tokens" binding:"min=0"`
+	CompletionTokens int64     `db:"completion_tokens" json:"completion_tokens" binding:"min=0"`
+	TablesProcessed  int       `db:"tables_processed" json:"tables_processed" binding:"min=0"`
+	LinesGenerated   int       `db:"lines_generated" json:"lines_generated" binding:"min=0"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// MigrationMetrics reports a migration's resource usage for capacity planning and billing
+type MigrationMetrics struct {
+	MigrationID      int64          `json:"migration_id"`
+	Status           string         `json:"status"`
+	Phases           []PhaseMetrics `json:"phases"`
+	DurationMs       int64          `json:"duration_ms"` // Sum of the phase durations
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	TotalTokens      int64          `json:"total_tokens"`
+	TablesCount      int            `json:"tables_count"`
+	TablesPerSecond  float64        `json:"tables_per_second"`
+	LinesGenerated   int            `json:"lines_generated"`
+}
+
+// MigrationRun is one start of a migration. Re-running a migration adds a run; earlier
+// runs keep their status, logs and generated files.
+type MigrationRun struct {
+	ID            int64      `db:"id" json:"id"`
+	MigrationID   int64      `db:"migration_id" json:"migration_id"`
+	RunNumber     int        `db:"run_number" json:"run_number"`
+	Status        string     `db:"status" json:"status"`
+	Progress      int        `db:"progress" json:"progress"`
+	Error         *string    `db:"error" json:"error,omitempty"`
+	StartedBy     *int64     `db:"started_by" json:"started_by,omitempty"`
+	ArtifactsPath *string    `db:"artifacts_path" json:"artifacts_path,omitempty"`
+	FilesCount    int        `db:"files_count" json:"files_count"`
+	StartedAt     time.Time  `db:"started_at" json:"started_at"`
+	CompletedAt   *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// MigrationRunFile is a generated file as it was at the end of a run
+type MigrationRunFile struct {
+	Path     string `db:"path" json:"path"`
+	FileType string `db:"file_type" json:"file_type"`
+	Size     int64  `db:"size" json:"size"`
+	Checksum string `db:"checksum" json:"checksum"`
+}
+
+// ManifestFile is a generated file in a migration's checksum manifest
+type ManifestFile struct {
+	Path   string `db:"path" json:"path"`
+	Name   string `json:"name"`
+	Size   int64  `db:"size" json:"size"`
+	Type   string `db:"file_type" json:"type"`
+	SHA256 string `db:"checksum" json:"sha256"`
+}
+
+// FileManifest lists the generated files of a migration's current run with their
+// checksums, so clients can verify a download. SHA256 covers the whole manifest.
+type FileManifest struct {
+	MigrationID int64          `json:"migration_id"`
+	ProjectPath string         `json:"project_path"`
+	RunNumber   int            `json:"run_number"`
+	SHA256      string         `json:"manifest_sha256"`
+	Files       []ManifestFile `json:"files"`
+}
+
+// ShareLink is a read-only link to a migration's summary and docs for people without
+// an account. Only a hash of its token is stored, so the URL is returned once.
+type ShareLink struct {
+	ID           int64      `db:"id" json:"id"`
+	MigrationID  int64      `db:"migration_id" json:"migration_id"`
+	Label        string     `db:"label" json:"label"`
+	CreatedBy    *int64     `db:"created_by" json:"created_by,omitempty"`
+	ExpiresAt    time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt    *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	ViewCount    int        `db:"view_count" json:"view_count"`
+	LastViewedAt *time.Time `db:"last_viewed_at" json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	URL          string     `db:"-" json:"url,omitempty"` // Only when the link is created
+}
+
+// CreateShareLinkRequest creates a share link; it expires after a week by default
+type CreateShareLinkRequest struct {
+	Label          string `json:"label" binding:"max=100"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1,max=2160"`
+}
+
+// SharedMigration is what a share link shows: the migration's summary, its docs landing
+// page and the documentation files of its current run
+type SharedMigration struct {
+	Name             string         `db:"name" json:"name"`
+	Status           string         `db:"status" json:"status"`
+	SourceDatabase   string         `db:"source_database" json:"source_database"`
+	TargetProject    string         `db:"target_project" json:"target_project"`
+	TablesCount      int            `db:"tables_count" json:"tables_count"`
+	ViewsCount       int            `db:"views_count" json:"views_count"`
+	ModelsGenerated  int            `db:"models_generated" json:"models_generated"`
+	CreatedAt        time.Time      `db:"created_at" json:"created_at"`
+	CompletedAt      *time.Time     `db:"completed_at" json:"completed_at,omitempty"`
+	OrganizationName string         `db:"-" json:"organization_name,omitempty"`
+	LogoURL          string         `db:"-" json:"logo_url,omitempty"`
+	DocsOverview     string         `db:"-" json:"docs_overview"` // Markdown of the __overview__ docs block
+	DocsFiles        []ManifestFile `db:"-" json:"docs_files"`
+	ExpiresAt        time.Time      `db:"-" json:"expires_at"` // When the link stops working
+}
+
+// MigrationTable is the progress of one source table in a migration's current run
+type MigrationTable struct {
+	Name      string    `db:"table_name" json:"name" binding:"required,max=255"`
+	Status    string    `db:"status" json:"status" binding:"required,oneof=pending extracting generated tested failed"`
+	Error     *string   `db:"error" json:"error,omitempty" binding:"omitempty,max=10000"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// MigrationTables lists the tables of a migration's current run, with how many are in
+// each status
+type MigrationTables struct {
+	MigrationID int64            `json:"migration_id"`
+	RunNumber   int              `json:"run_number"`
+	Counts      map[string]int   `json:"counts"`
+	Tables      []MigrationTable `json:"tables"`
+}
+
+// MigrationLog is a progress message the AI service reported during a run
+type MigrationLog struct {
+	Level     string    `db:"level" json:"level" binding:"required,oneof=debug info warning error"`
+	Message   string    `db:"message" json:"message" binding:"required,max=10000"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// MigrationRunDetail is a run with its logs and file snapshot
+type MigrationRunDetail struct {
+	MigrationRun
+	Logs  []MigrationLog     `json:"logs"`
+	Files []MigrationRunFile `json:"files"`
+}
+
+// RunFileChange is a generated file that differs between two runs
+type RunFileChange struct {
+	Path      string `json:"path"`
+	Change    string `json:"change"`         // added, removed or modified
+	Diff      string `json:"diff,omitempty"` // Unified diff; empty when a snapshot kept only the checksum
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// MigrationRunDiff compares the generated projects of two runs of a migration
+type MigrationRunDiff struct {
+	MigrationID int64           `json:"migration_id"`
+	FromRun     int             `json:"from_run"`
+	ToRun       int             `json:"to_run"`
+	Added       int             `json:"added"`
+	Removed     int             `json:"removed"`
+	Modified    int             `json:"modified"`
+	Unchanged   int             `json:"unchanged"`
+	Files       []RunFileChange `json:"files"`
+}
+
+// SearchResult is one match from GET /search
+type SearchResult struct {
+	Type        string  `db:"type" json:"type"` // migration, connection, file or comment
+	ID          int64   `db:"id" json:"id"`
+	MigrationID *int64  `db:"migration_id" json:"migration_id,omitempty"` // Set for files and comments
+	Title       string  `db:"title" json:"title"`
+	Snippet     string  `db:"snippet" json:"snippet,omitempty"`
+	Rank        float64 `db:"rank" json:"rank"`
+}
+
+// SearchResponse groups search matches by resource type
+type SearchResponse struct {
+	Query   string                    `json:"query"`
+	Results map[string][]SearchResult `json:"results"`
+	Total   int                       `json:"total"`
+}
+
 // DeploymentStatusResponse returns the status of a deployment
 type DeploymentStatusResponse struct {
 	ID            int64      `json:"id"`
@@ -239,3 +1373,30 @@ type DeploymentStatusResponse struct {
 	CreatedAt     time.Time  `json:"created_at"`
 	CompletedAt   *time.Time `json:"completed_at,omitempty"`
 }
+
+// SchemaMatch is a schema embedding returned by RAG similarity search
+type SchemaMatch struct {
+	ID          int64   `db:"id" json:"id"`
+	SourceType  string  `db:"source_type" json:"source_type"`
+	SourceName  string  `db:"source_name" json:"source_name"`
+	MigrationID *int64  `db:"migration_id" json:"migration_id,omitempty"`
+	Similarity  float64 `db:"similarity" json:"similarity"`
+}
+
+// TransformationMatch is a transformation embedding returned by RAG similarity search
+type TransformationMatch struct {
+	ID                 int64   `db:"id" json:"id"`
+	SourceSQL          string  `db:"source_sql" json:"source_sql"`
+	TargetSQL          string  `db:"target_sql" json:"target_sql"`
+	TransformationType string  `db:"transformation_type" json:"transformation_type"`
+	QualityScore       float64 `db:"quality_score" json:"quality_score"`
+	MigrationID        *int64  `db:"migration_id" json:"migration_id,omitempty"`
+	Similarity         float64 `db:"similarity" json:"similarity"`
+}
+
+// RAGSearchResponse groups RAG similarity matches by source table
+type RAGSearchResponse struct {
+	Query           string                `json:"query"`
+	Schemas         []SchemaMatch         `json:"schemas"`
+	Transformations []TransformationMatch `json:"transformations"`
+}
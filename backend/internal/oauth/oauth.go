@@ -0,0 +1,253 @@
+// Package oauth signs users in with Google, GitHub and Azure AD using the OAuth 2.0
+// authorization code flow with PKCE
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Provider names, also stored in user_identities.provider
+const (
+	Google  = "google"
+	GitHub  = "github"
+	AzureAD = "azuread"
+)
+
+// Settings configure the providers. A provider is enabled when its client ID and
+// secret are both set.
+type Settings struct {
+	RedirectBaseURL string // The frontend; providers redirect to {RedirectBaseURL}/auth/callback/{provider}
+
+	GoogleClientID     string
+	GoogleClientSecret string
+
+	GitHubClientID     string
+	GitHubClientSecret string
+
+	AzureTenantID     string // A tenant ID, or "organizations" for any work account
+	AzureClientID     string
+	AzureClientSecret string
+}
+
+// Profile is the account a provider signed the user in as
+type Profile struct {
+	Subject       string // Stable account ID at the provider
+	Email         string
+	EmailVerified bool // The provider vouches that the user owns Email
+	FirstName     string
+	LastName      string
+}
+
+// Provider is an OAuth 2.0 identity provider
+type Provider struct {
+	Name        string
+	DisplayName string
+
+	clientID     string
+	clientSecret string
+	authURL      string
+	tokenURL     string
+	scopes       []string
+	redirectURI  string
+	httpClient   *http.Client
+
+	// profile fetches the signed-in account with an access token
+	profile func(ctx context.Context, p *Provider, accessToken string) (*Profile, error)
+}
+
+var providers = map[string]*Provider{}
+
+// Init configures the providers from settings, replacing any previous configuration
+func Init(s Settings) {
+	providers = map[string]*Provider{}
+	base := strings.TrimRight(s.RedirectBaseURL, "/")
+
+	add := func(p *Provider) {
+		if p.clientID == "" || p.clientSecret == "" {
+			return
+		}
+		p.redirectURI = base + "/auth/callback/" + p.Name
+		p.httpClient = &http.Client{Timeout: 30 * time.Second}
+		providers[p.Name] = p
+	}
+
+	add(&Provider{
+		Name:         Google,
+		DisplayName:  "Google",
+		clientID:     s.GoogleClientID,
+		clientSecret: s.GoogleClientSecret,
+		authURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		tokenURL:     "https://oauth2.googleapis.com/token",
+		scopes:       []string{"openid", "email", "profile"},
+		profile:      googleProfile,
+	})
+	add(&Provider{
+		Name:         GitHub,
+		DisplayName:  "GitHub",
+		clientID:     s.GitHubClientID,
+		clientSecret: s.GitHubClientSecret,
+		authURL:      "https://github.com/login/oauth/authorize",
+		tokenURL:     "https://github.com/login/oauth/access_token",
+		scopes:       []string{"read:user", "user:email"},
+		profile:      githubProfile,
+	})
+
+	tenant := s.AzureTenantID
+	if tenant == "" {
+		tenant = "organizations"
+	}
+	add(&Provider{
+		Name:         AzureAD,
+		DisplayName:  "Microsoft",
+		clientID:     s.AzureClientID,
+		clientSecret: s.AzureClientSecret,
+		authURL:      "https://login.microsoftonline.com/" + url.PathEscape(tenant) + "/oauth2/v2.0/authorize",
+		tokenURL:     "https://login.microsoftonline.com/" + url.PathEscape(tenant) + "/oauth2/v2.0/token",
+		scopes:       []string{"openid", "email", "profile", "User.Read"},
+		profile:      azureProfile(tenant),
+	})
+}
+
+// Get returns the named provider, or nil if it isn't configured
+func Get(name string) *Provider {
+	return providers[name]
+}
+
+// Enabled returns the configured providers, sorted by name
+func Enabled() []*Provider {
+	list := make([]*Provider, 0, len(providers))
+	for _, p := range providers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// WithRedirectBase returns a copy of the provider that redirects to another frontend,
+// such as an organization's custom domain. The callback URL must also be registered
+// with the provider.
+func (p *Provider) WithRedirectBase(base string) *Provider {
+	redirected := *p
+	redirected.redirectURI = strings.TrimRight(base, "/") + "/auth/callback/" + p.Name
+	return &redirected
+}
+
+// AuthCodeURL returns the URL that starts sign-in at the provider
+func (p *Provider) AuthCodeURL(state, codeChallenge string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURI},
+		"scope":                 {strings.Join(p.scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	if p.Name == Google || p.Name == AzureAD {
+		// Let users pick an account instead of silently reusing the browser session
+		params.Set("prompt", "select_account")
+	}
+	return p.authURL + "?" + params.Encode()
+}
+
+// Exchange trades an authorization code for the account it was issued for
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Profile, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURI},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub answers with a form-encoded body unless asked for JSON
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s token request failed: %w", p.DisplayName, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode %s token response: %w", p.DisplayName, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return nil, fmt.Errorf("%s token request failed (status %d): %s %s", p.DisplayName, resp.StatusCode, result.Error, result.ErrorDescription)
+	}
+
+	profile, err := p.profile(ctx, p, result.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	profile.Email = strings.ToLower(strings.TrimSpace(profile.Email))
+	if profile.Subject == "" {
+		return nil, fmt.Errorf("%s did not return an account ID", p.DisplayName)
+	}
+	return profile, nil
+}
+
+// getJSON calls a provider API with the user's access token
+func (p *Provider) getJSON(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s API request failed: %w", p.DisplayName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s API error (status %d)", p.DisplayName, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// NewState returns a random value for the state parameter
+func NewState() (string, error) {
+	return randomString(32)
+}
+
+// NewCodeVerifier returns a random PKCE code verifier (RFC 7636, 43 characters)
+func NewCodeVerifier() (string, error) {
+	return randomString(32)
+}
+
+// CodeChallenge returns the S256 challenge for a code verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,65 @@
+package oauth
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	got := CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallenge = %q, want %q", got, want)
+	}
+
+	verifier, err := NewCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Errorf("verifier length %d is outside 43-128", len(verifier))
+	}
+}
+
+func TestInitAndAuthCodeURL(t *testing.T) {
+	Init(Settings{
+		RedirectBaseURL:    "https://app.example.com/",
+		GitHubClientID:     "gh-client",
+		GitHubClientSecret: "gh-secret",
+		GoogleClientID:     "google-client", // No secret, so disabled
+	})
+	defer Init(Settings{})
+
+	if Get(Google) != nil {
+		t.Error("Google is enabled without a client secret")
+	}
+	p := Get(GitHub)
+	if p == nil || len(Enabled()) != 1 {
+		t.Fatalf("enabled providers = %v", Enabled())
+	}
+
+	u, err := url.Parse(p.AuthCodeURL("state-1", "challenge-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("redirect_uri") != "https://app.example.com/auth/callback/github" {
+		t.Errorf("redirect_uri = %q", q.Get("redirect_uri"))
+	}
+	if q.Get("state") != "state-1" || q.Get("code_challenge") != "challenge-1" || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("auth URL query = %v", q)
+	}
+	if q.Get("client_secret") != "" {
+		t.Error("auth URL contains the client secret")
+	}
+
+	// A custom domain's sign-in returns to it, without changing the configured provider
+	u, _ = url.Parse(p.WithRedirectBase("https://app.contoso.com").AuthCodeURL("state-2", "challenge-2"))
+	if got := u.Query().Get("redirect_uri"); got != "https://app.contoso.com/auth/callback/github" {
+		t.Errorf("custom domain redirect_uri = %q", got)
+	}
+	u, _ = url.Parse(Get(GitHub).AuthCodeURL("state-3", "challenge-3"))
+	if got := u.Query().Get("redirect_uri"); got != "https://app.example.com/auth/callback/github" {
+		t.Errorf("redirect_uri after WithRedirectBase = %q", got)
+	}
+}
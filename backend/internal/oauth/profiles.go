@@ -0,0 +1,92 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+func googleProfile(ctx context.Context, p *Provider, accessToken string) (*Profile, error) {
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+	}
+	if err := p.getJSON(ctx, "https://openidconnect.googleapis.com/v1/userinfo", accessToken, &info); err != nil {
+		return nil, err
+	}
+	return &Profile{
+		Subject:       info.Sub,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		FirstName:     info.GivenName,
+		LastName:      info.FamilyName,
+	}, nil
+}
+
+func githubProfile(ctx context.Context, p *Provider, accessToken string) (*Profile, error) {
+	var user struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := p.getJSON(ctx, "https://api.github.com/user", accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("GitHub did not return an account ID")
+	}
+
+	// The public profile email is optional and unverified; use the primary verified address
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, "https://api.github.com/user/emails", accessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to read GitHub email addresses: %w", err)
+	}
+
+	profile := &Profile{Subject: strconv.FormatInt(user.ID, 10), FirstName: user.Name}
+	for _, e := range emails {
+		if e.Primary {
+			profile.Email = e.Email
+			profile.EmailVerified = e.Verified
+			break
+		}
+	}
+	return profile, nil
+}
+
+// azureProfile reads the signed-in user from Microsoft Graph. Azure AD doesn't verify
+// the mail attribute, which any tenant admin can set, so the email is only trusted when
+// the app is restricted to a single tenant.
+func azureProfile(tenant string) func(context.Context, *Provider, string) (*Profile, error) {
+	singleTenant := tenant != "common" && tenant != "organizations" && tenant != "consumers"
+
+	return func(ctx context.Context, p *Provider, accessToken string) (*Profile, error) {
+		var me struct {
+			ID                string `json:"id"`
+			Mail              string `json:"mail"`
+			UserPrincipalName string `json:"userPrincipalName"`
+			GivenName         string `json:"givenName"`
+			Surname           string `json:"surname"`
+		}
+		if err := p.getJSON(ctx, "https://graph.microsoft.com/v1.0/me", accessToken, &me); err != nil {
+			return nil, err
+		}
+
+		email := me.Mail
+		if email == "" {
+			email = me.UserPrincipalName
+		}
+		return &Profile{
+			Subject:       me.ID,
+			Email:         email,
+			EmailVerified: singleTenant && email != "",
+			FirstName:     me.GivenName,
+			LastName:      me.Surname,
+		}, nil
+	}
+}
@@ -0,0 +1,229 @@
+// Package powerbi queries the Power BI REST API with a service principal to find
+// datasets that read from a given SQL Server database
+package powerbi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiBaseURL   = "https://api.powerbi.com/v1.0/myorg"
+	loginBaseURL = "https://login.microsoftonline.com"
+	apiScope     = "https://analysis.windows.net/powerbi/api/.default"
+)
+
+// Client calls the Power BI REST API. The service principal needs read access to the
+// workspaces to scan (add it to them, and allow service principals in the tenant settings).
+type Client struct {
+	tenantID     string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+var client *Client
+
+// Init configures the client. Discovery is disabled unless all three are set.
+func Init(tenantID, clientID, clientSecret string) {
+	if tenantID == "" || clientID == "" || clientSecret == "" {
+		client = nil
+		return
+	}
+	client = &Client{
+		tenantID:     tenantID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetClient returns the configured client, or nil if Power BI isn't configured
+func GetClient() *Client {
+	return client
+}
+
+// Dataset is a Power BI dataset (semantic model)
+type Dataset struct {
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	WebURL        string       `json:"web_url,omitempty"`
+	ConfiguredBy  string       `json:"configured_by,omitempty"`
+	WorkspaceID   string       `json:"workspace_id"`
+	WorkspaceName string       `json:"workspace_name"`
+	Datasources   []Datasource `json:"datasources"`
+}
+
+// Datasource is a database a dataset reads from
+type Datasource struct {
+	Type     string `json:"type"`
+	Server   string `json:"server"`
+	Database string `json:"database"`
+}
+
+// DatasetsUsing scans every workspace the service principal can read and returns the
+// datasets with a SQL datasource on server/database
+func (c *Client) DatasetsUsing(ctx context.Context, server, database string) ([]Dataset, error) {
+	var workspaces struct {
+		Value []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"value"`
+	}
+	if err := c.get(ctx, "/groups", &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	matches := []Dataset{}
+	for _, ws := range workspaces.Value {
+		var datasets struct {
+			Value []struct {
+				ID           string `json:"id"`
+				Name         string `json:"name"`
+				WebURL       string `json:"webUrl"`
+				ConfiguredBy string `json:"configuredBy"`
+			} `json:"value"`
+		}
+		if err := c.get(ctx, "/groups/"+url.PathEscape(ws.ID)+"/datasets", &datasets); err != nil {
+			return nil, fmt.Errorf("failed to list datasets in workspace %s: %w", ws.Name, err)
+		}
+
+		for _, ds := range datasets.Value {
+			var sources struct {
+				Value []struct {
+					DatasourceType    string `json:"datasourceType"`
+					ConnectionDetails struct {
+						Server   string `json:"server"`
+						Database string `json:"database"`
+					} `json:"connectionDetails"`
+				} `json:"value"`
+			}
+			path := "/groups/" + url.PathEscape(ws.ID) + "/datasets/" + url.PathEscape(ds.ID) + "/datasources"
+			if err := c.get(ctx, path, &sources); err != nil {
+				// Some dataset kinds (push, streaming) have no datasources endpoint
+				continue
+			}
+
+			dataset := Dataset{
+				ID:            ds.ID,
+				Name:          ds.Name,
+				WebURL:        ds.WebURL,
+				ConfiguredBy:  ds.ConfiguredBy,
+				WorkspaceID:   ws.ID,
+				WorkspaceName: ws.Name,
+			}
+			matched := false
+			for _, src := range sources.Value {
+				dataset.Datasources = append(dataset.Datasources, Datasource{
+					Type:     src.DatasourceType,
+					Server:   src.ConnectionDetails.Server,
+					Database: src.ConnectionDetails.Database,
+				})
+				if strings.EqualFold(src.DatasourceType, "Sql") &&
+					SameServer(src.ConnectionDetails.Server, server) &&
+					strings.EqualFold(src.ConnectionDetails.Database, database) {
+					matched = true
+				}
+			}
+			if matched {
+				matches = append(matches, dataset)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// SameServer compares SQL Server addresses, ignoring case, a "tcp:" prefix and the port
+// ("host,1433" or "host:1433"). Named instances must match.
+func SameServer(a, b string) bool {
+	return normalizeServer(a) == normalizeServer(b)
+}
+
+func normalizeServer(server string) string {
+	server = strings.ToLower(strings.TrimSpace(server))
+	server = strings.TrimPrefix(server, "tcp:")
+	if i := strings.IndexAny(server, ",:"); i >= 0 {
+		server = server[:i]
+	}
+	return server
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Power BI API error (status %d)", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// accessToken returns a cached client-credentials token, fetching a new one shortly
+// before it expires
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+		"scope":         {apiScope},
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginBaseURL, url.PathEscape(c.tenantID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Power BI token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode Power BI token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return "", fmt.Errorf("Power BI token request failed (status %d): %s", resp.StatusCode, result.ErrorDescription)
+	}
+
+	c.token = result.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return c.token, nil
+}
@@ -0,0 +1,53 @@
+// Package pubsub tells waiting requests in this process that something they watch
+// changed. Messages carry no payload: subscribers re-read what they watch, so a missed
+// or coalesced notification only delays them.
+package pubsub
+
+import "sync"
+
+var (
+	mu          sync.Mutex
+	subscribers = map[string]map[chan struct{}]struct{}{}
+)
+
+// Subscribe returns a channel that receives when topic is published, and a function
+// that ends the subscription. Notifications published while the last one is unread are
+// coalesced into it.
+func Subscribe(topic string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	mu.Lock()
+	if subscribers[topic] == nil {
+		subscribers[topic] = map[chan struct{}]struct{}{}
+	}
+	subscribers[topic][ch] = struct{}{}
+	mu.Unlock()
+
+	return ch, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(subscribers[topic], ch)
+		if len(subscribers[topic]) == 0 {
+			delete(subscribers, topic)
+		}
+	}
+}
+
+// Publish notifies topic's subscribers without blocking
+func Publish(topic string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for ch := range subscribers[topic] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribers is how many subscriptions topic has
+func Subscribers(topic string) int {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(subscribers[topic])
+}
@@ -0,0 +1,51 @@
+package pubsub
+
+import "testing"
+
+func TestPublishNotifiesSubscribers(t *testing.T) {
+	a, cancelA := Subscribe("migration/1")
+	defer cancelA()
+	b, cancelB := Subscribe("migration/1")
+	defer cancelB()
+	other, cancelOther := Subscribe("migration/2")
+	defer cancelOther()
+
+	Publish("migration/1")
+	Publish("migration/1") // Coalesced into the unread notification
+
+	for name, ch := range map[string]<-chan struct{}{"a": a, "b": b} {
+		select {
+		case <-ch:
+		default:
+			t.Fatalf("subscriber %s wasn't notified", name)
+		}
+		select {
+		case <-ch:
+			t.Fatalf("subscriber %s got the coalesced notification twice", name)
+		default:
+		}
+	}
+	select {
+	case <-other:
+		t.Fatal("subscriber of another topic was notified")
+	default:
+	}
+}
+
+func TestCancelRemovesSubscription(t *testing.T) {
+	ch, cancel := Subscribe("migration/3")
+	if got := Subscribers("migration/3"); got != 1 {
+		t.Fatalf("Subscribers = %d, want 1", got)
+	}
+	cancel()
+	if got := Subscribers("migration/3"); got != 0 {
+		t.Fatalf("Subscribers = %d after cancel, want 0", got)
+	}
+
+	Publish("migration/3")
+	select {
+	case <-ch:
+		t.Fatal("cancelled subscription was notified")
+	default:
+	}
+}
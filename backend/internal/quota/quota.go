@@ -0,0 +1,249 @@
+package quota
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// Metrics tracked against monthly plan quotas
+const (
+	MetricMigrationsRun  = "migrations_run"
+	MetricTablesMigrated = "tables_migrated"
+	MetricChatMessages   = "chat_messages"
+	MetricAITokens       = "ai_tokens"
+)
+
+// Metrics lists every quota metric in display order
+var Metrics = []string{MetricMigrationsRun, MetricTablesMigrated, MetricChatMessages, MetricAITokens}
+
+// Unlimited marks a metric without a quota on a plan
+const Unlimited int64 = -1
+
+// PlanLimits are the monthly quotas for each organization plan
+var PlanLimits = map[string]map[string]int64{
+	"free": {
+		MetricMigrationsRun:  10,
+		MetricTablesMigrated: 100,
+		MetricChatMessages:   200,
+		MetricAITokens:       500000,
+	},
+	"starter": {
+		MetricMigrationsRun:  50,
+		MetricTablesMigrated: 1000,
+		MetricChatMessages:   2000,
+		MetricAITokens:       5000000,
+	},
+	"professional": {
+		MetricMigrationsRun:  250,
+		MetricTablesMigrated: 10000,
+		MetricChatMessages:   10000,
+		MetricAITokens:       25000000,
+	},
+	"enterprise": {
+		MetricMigrationsRun:  Unlimited,
+		MetricTablesMigrated: Unlimited,
+		MetricChatMessages:   Unlimited,
+		MetricAITokens:       Unlimited,
+	},
+}
+
+// PlanPriority is each plan's lane when AI service capacity is constrained: queued
+// migrations of a higher lane start first
+var PlanPriority = map[string]int{
+	"free":         0,
+	"starter":      1,
+	"professional": 2,
+	"enterprise":   3,
+}
+
+// TopPriority is the highest plan lane
+const TopPriority = 3
+
+// MetricUsage is one metric's usage against its quota for the current period
+type MetricUsage struct {
+	Metric    string  `json:"metric"`
+	Used      int64   `json:"used"`
+	Limit     int64   `json:"limit"`     // -1 when unlimited
+	Remaining int64   `json:"remaining"` // -1 when unlimited
+	Percent   float64 `json:"percent"`
+}
+
+// PeriodUsage is the usage recorded for a past month
+type PeriodUsage struct {
+	Period string           `json:"period"` // YYYY-MM
+	Usage  map[string]int64 `json:"usage"`
+}
+
+// UsageReport is an organization's quota usage breakdown
+type UsageReport struct {
+	OrganizationID int64         `json:"organization_id"`
+	Plan           string        `json:"plan"`
+	PeriodStart    time.Time     `json:"period_start"`
+	PeriodEnd      time.Time     `json:"period_end"`
+	Metrics        []MetricUsage `json:"metrics"`
+	History        []PeriodUsage `json:"history"`
+}
+
+// ExceededError reports a request that would exceed a quota
+type ExceededError struct {
+	Metric    string
+	Plan      string
+	Used      int64
+	Limit     int64
+	Requested int64
+	ResetsAt  time.Time
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("monthly %s quota exceeded: %d of %d used on the %s plan", e.Metric, e.Used, e.Limit, e.Plan)
+}
+
+// periodStart returns the first day of the month containing t (UTC)
+func periodStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// limitsFor returns the quotas for a plan, falling back to the free plan
+func limitsFor(plan string) map[string]int64 {
+	if limits, ok := PlanLimits[plan]; ok {
+		return limits
+	}
+	return PlanLimits["free"]
+}
+
+func organizationPlan(orgID int64) (string, error) {
+	var plan string
+	err := db.DB.Get(&plan, "SELECT COALESCE(plan, 'free') FROM organizations WHERE id = $1", orgID)
+	return plan, err
+}
+
+func currentUsage(orgID int64, metric string, period time.Time) (int64, error) {
+	var used int64
+	err := db.DB.Get(&used, `
+		SELECT COALESCE(SUM(amount), 0) FROM organization_usage
+		WHERE organization_id = $1 AND period = $2 AND metric = $3
+	`, orgID, period, metric)
+	return used, err
+}
+
+// Check verifies that using amount more of a metric stays within the organization's
+// monthly quota. An amount of 0 only checks that the quota isn't already used up.
+// It returns *ExceededError when the quota would be exceeded.
+func Check(orgID int64, metric string, amount int64) error {
+	plan, err := organizationPlan(orgID)
+	if err != nil {
+		return err
+	}
+
+	limit, ok := limitsFor(plan)[metric]
+	if !ok || limit == Unlimited {
+		return nil
+	}
+
+	period := periodStart(time.Now())
+	used, err := currentUsage(orgID, metric, period)
+	if err != nil {
+		return err
+	}
+
+	if used+amount > limit || (amount == 0 && used >= limit) {
+		return &ExceededError{
+			Metric:    metric,
+			Plan:      plan,
+			Used:      used,
+			Limit:     limit,
+			Requested: amount,
+			ResetsAt:  period.AddDate(0, 1, 0),
+		}
+	}
+	return nil
+}
+
+// Record adds usage for the current month
+func Record(orgID int64, metric string, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+	_, err := db.DB.Exec(`
+		INSERT INTO organization_usage (organization_id, period, metric, amount, updated_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (organization_id, period, metric)
+		DO UPDATE SET amount = organization_usage.amount + EXCLUDED.amount, updated_at = CURRENT_TIMESTAMP
+	`, orgID, periodStart(time.Now()), metric, amount)
+	return err
+}
+
+// GetUsage builds the usage breakdown for the current month plus the previous months
+func GetUsage(orgID int64, historyMonths int) (*UsageReport, error) {
+	plan, err := organizationPlan(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	period := periodStart(time.Now())
+	since := period.AddDate(0, -historyMonths, 0)
+
+	rows, err := db.DB.Query(`
+		SELECT period, metric, amount FROM organization_usage
+		WHERE organization_id = $1 AND period >= $2
+		ORDER BY period DESC
+	`, orgID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byPeriod := make(map[string]map[string]int64)
+	for rows.Next() {
+		var p time.Time
+		var metric string
+		var amount int64
+		if err := rows.Scan(&p, &metric, &amount); err != nil {
+			return nil, err
+		}
+		key := p.Format("2006-01")
+		if byPeriod[key] == nil {
+			byPeriod[key] = make(map[string]int64)
+		}
+		byPeriod[key][metric] += amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{
+		OrganizationID: orgID,
+		Plan:           plan,
+		PeriodStart:    period,
+		PeriodEnd:      period.AddDate(0, 1, 0),
+		Metrics:        make([]MetricUsage, 0, len(Metrics)),
+		History:        make([]PeriodUsage, 0, historyMonths),
+	}
+
+	limits := limitsFor(plan)
+	current := byPeriod[period.Format("2006-01")]
+	for _, metric := range Metrics {
+		usage := MetricUsage{Metric: metric, Used: current[metric], Limit: limits[metric], Remaining: Unlimited}
+		if usage.Limit != Unlimited {
+			usage.Remaining = max(usage.Limit-usage.Used, 0)
+			if usage.Limit > 0 {
+				usage.Percent = float64(usage.Used) * 100 / float64(usage.Limit)
+			}
+		}
+		report.Metrics = append(report.Metrics, usage)
+	}
+
+	for i := 1; i <= historyMonths; i++ {
+		key := period.AddDate(0, -i, 0).Format("2006-01")
+		usage := byPeriod[key]
+		if usage == nil {
+			usage = map[string]int64{}
+		}
+		report.History = append(report.History, PeriodUsage{Period: key, Usage: usage})
+	}
+
+	return report, nil
+}
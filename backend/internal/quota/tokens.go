@@ -0,0 +1,215 @@
+package quota
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// Sources of AI token reports in the ledger
+const (
+	TokenSourceMigration = "migration"
+	TokenSourceChat      = "chat"
+)
+
+// TokenReport is the AI token usage of one migration phase or chat message. Key
+// identifies what used the tokens; a report sent again under the same key replaces
+// the earlier figures, so retried reports aren't counted twice.
+type TokenReport struct {
+	Key              string // migration/<id>/<phase> or chat/<message id>
+	Source           string
+	OrganizationID   *int64
+	UserID           *int64
+	MigrationID      *int64
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// TokenRecord is the outcome of recording a token report
+type TokenRecord struct {
+	Added     int64 // Change to the organization's monthly counter; negative for a lowered report
+	Used      int64 // AI tokens used this month after the report
+	Limit     int64 // -1 when unlimited
+	Exhausted bool  // The plan's monthly AI token budget is used up
+}
+
+// MigrationTokenKey is the ledger key of a migration phase's tokens
+func MigrationTokenKey(migrationID int64, phase string) string {
+	return "migration/" + strconv.FormatInt(migrationID, 10) + "/" + phase
+}
+
+// ChatTokenKey is the ledger key of a chat message's tokens
+func ChatTokenKey(messageID string) string {
+	return "chat/" + messageID
+}
+
+// RecordTokens stores a token report in the ledger and moves the organization's monthly
+// ai_tokens counter by the difference to the key's earlier report. The tokens count
+// against the month the key was first reported in.
+func RecordTokens(store db.Querier, report TokenReport) (*TokenRecord, error) {
+	var entry struct {
+		OrganizationID *int64    `db:"organization_id"`
+		Period         time.Time `db:"period"`
+		Added          int64     `db:"added"`
+	}
+	err := store.Get(&entry, `
+		WITH previous AS (
+			SELECT prompt_tokens + completion_tokens AS total FROM ai_token_usage WHERE usage_key = $1 FOR UPDATE
+		)
+		INSERT INTO ai_token_usage (usage_key, source, organization_id, user_id, migration_id, period, prompt_tokens, completion_tokens)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (usage_key) DO UPDATE
+		SET prompt_tokens = EXCLUDED.prompt_tokens, completion_tokens = EXCLUDED.completion_tokens, updated_at = NOW()
+		RETURNING organization_id, period,
+		          prompt_tokens + completion_tokens - COALESCE((SELECT total FROM previous), 0) AS added
+	`, report.Key, report.Source, report.OrganizationID, report.UserID, report.MigrationID,
+		periodStart(time.Now()), report.PromptTokens, report.CompletionTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &TokenRecord{Added: entry.Added, Limit: Unlimited}
+	if entry.OrganizationID == nil {
+		return record, nil
+	}
+
+	err = store.Get(&record.Used, `
+		INSERT INTO organization_usage (organization_id, period, metric, amount, updated_at)
+		VALUES ($1, $2, $3, GREATEST($4::bigint, 0), CURRENT_TIMESTAMP)
+		ON CONFLICT (organization_id, period, metric)
+		DO UPDATE SET amount = GREATEST(organization_usage.amount + $4::bigint, 0), updated_at = CURRENT_TIMESTAMP
+		RETURNING amount
+	`, *entry.OrganizationID, entry.Period, MetricAITokens, entry.Added)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan string
+	if err := store.Get(&plan, "SELECT COALESCE(plan, 'free') FROM organizations WHERE id = $1", *entry.OrganizationID); err != nil {
+		return nil, err
+	}
+	if limit, ok := limitsFor(plan)[MetricAITokens]; ok {
+		record.Limit = limit
+		record.Exhausted = limit != Unlimited && record.Used >= limit
+	}
+	return record, nil
+}
+
+// SourceTokens is the AI tokens used by one source in a month
+type SourceTokens struct {
+	Source           string `db:"source" json:"source"` // migration, chat, other
+	PromptTokens     int64  `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64  `db:"completion_tokens" json:"completion_tokens"`
+	TotalTokens      int64  `db:"total_tokens" json:"total_tokens"`
+}
+
+// MigrationTokens is the AI tokens a migration used in a month
+type MigrationTokens struct {
+	MigrationID int64  `db:"migration_id" json:"migration_id"`
+	Name        string `db:"name" json:"name"`
+	TotalTokens int64  `db:"total_tokens" json:"total_tokens"`
+}
+
+// UserTokens is the AI tokens a member used in a month
+type UserTokens struct {
+	UserID      int64  `db:"user_id" json:"user_id"`
+	Email       string `db:"email" json:"email"`
+	TotalTokens int64  `db:"total_tokens" json:"total_tokens"`
+	ChatTokens  int64  `db:"chat_tokens" json:"chat_tokens"`
+}
+
+// TokenUsageReport breaks down an organization's AI token usage for a month
+type TokenUsageReport struct {
+	OrganizationID int64             `json:"organization_id"`
+	Plan           string            `json:"plan"`
+	Period         string            `json:"period"` // YYYY-MM
+	Used           int64             `json:"used"`
+	Limit          int64             `json:"limit"`     // -1 when unlimited
+	Remaining      int64             `json:"remaining"` // -1 when unlimited
+	Sources        []SourceTokens    `json:"sources"`
+	Migrations     []MigrationTokens `json:"migrations"` // Top migrations by tokens
+	Users          []UserTokens      `json:"users"`
+}
+
+// topTokenConsumers caps the migrations and users listed in a token usage report
+const topTokenConsumers = 20
+
+// GetTokenUsage builds the AI token breakdown of the month starting at period. Tokens
+// counted without a ledger entry (e.g. translations and migration starts) are listed
+// as the "other" source.
+func GetTokenUsage(store db.Querier, orgID int64, period time.Time) (*TokenUsageReport, error) {
+	period = periodStart(period)
+	report := &TokenUsageReport{
+		OrganizationID: orgID,
+		Period:         period.Format("2006-01"),
+		Remaining:      Unlimited,
+		Sources:        []SourceTokens{},
+		Migrations:     []MigrationTokens{},
+		Users:          []UserTokens{},
+	}
+
+	if err := store.Get(&report.Plan, "SELECT COALESCE(plan, 'free') FROM organizations WHERE id = $1", orgID); err != nil {
+		return nil, err
+	}
+	report.Limit = limitsFor(report.Plan)[MetricAITokens]
+
+	err := store.Get(&report.Used, `
+		SELECT COALESCE(SUM(amount), 0) FROM organization_usage
+		WHERE organization_id = $1 AND period = $2 AND metric = $3
+	`, orgID, period, MetricAITokens)
+	if err != nil {
+		return nil, err
+	}
+	if report.Limit != Unlimited {
+		report.Remaining = max(report.Limit-report.Used, 0)
+	}
+
+	err = store.Select(&report.Sources, `
+		SELECT source, SUM(prompt_tokens)::bigint AS prompt_tokens, SUM(completion_tokens)::bigint AS completion_tokens,
+		       SUM(prompt_tokens + completion_tokens)::bigint AS total_tokens
+		FROM ai_token_usage
+		WHERE organization_id = $1 AND period = $2
+		GROUP BY source
+		ORDER BY source
+	`, orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	var ledgered int64
+	for _, s := range report.Sources {
+		ledgered += s.TotalTokens
+	}
+	if other := report.Used - ledgered; other > 0 {
+		report.Sources = append(report.Sources, SourceTokens{Source: "other", TotalTokens: other})
+	}
+
+	err = store.Select(&report.Migrations, `
+		SELECT t.migration_id, m.name, SUM(t.prompt_tokens + t.completion_tokens)::bigint AS total_tokens
+		FROM ai_token_usage t
+		JOIN migrations m ON m.id = t.migration_id
+		WHERE t.organization_id = $1 AND t.period = $2
+		GROUP BY t.migration_id, m.name
+		ORDER BY total_tokens DESC, t.migration_id
+		LIMIT $3
+	`, orgID, period, topTokenConsumers)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.Select(&report.Users, `
+		SELECT t.user_id, u.email, SUM(t.prompt_tokens + t.completion_tokens)::bigint AS total_tokens,
+		       SUM(CASE WHEN t.source = 'chat' THEN t.prompt_tokens + t.completion_tokens ELSE 0 END)::bigint AS chat_tokens
+		FROM ai_token_usage t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.organization_id = $1 AND t.period = $2
+		GROUP BY t.user_id, u.email
+		ORDER BY total_tokens DESC, t.user_id
+		LIMIT $3
+	`, orgID, period, topTokenConsumers)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
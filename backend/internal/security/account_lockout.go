@@ -213,6 +213,11 @@ func (al *AccountLockout) CheckIPBlocked(ipAddress string) bool {
 		return false
 	}
 
+	return al.IPFailedAttempts(ipAddress) >= al.config.IPMaxAttempts
+}
+
+// IPFailedAttempts returns the failed login attempts from an IP across all accounts
+func (al *AccountLockout) IPFailedAttempts(ipAddress string) int {
 	al.mu.RLock()
 	defer al.mu.RUnlock()
 
@@ -223,7 +228,18 @@ func (al *AccountLockout) CheckIPBlocked(ipAddress string) bool {
 		}
 	}
 
-	return totalAttempts >= al.config.IPMaxAttempts
+	return totalAttempts
+}
+
+// RequiresCaptcha reports whether recent failures for the email or IP reach the CAPTCHA threshold
+func (al *AccountLockout) RequiresCaptcha(email, ipAddress string, threshold int) bool {
+	if threshold <= 0 {
+		return true
+	}
+	if info := al.GetAttemptInfo(email); info != nil && info.FailedCount >= threshold {
+		return true
+	}
+	return al.IPFailedAttempts(ipAddress) >= threshold
 }
 
 // UnlockAccount manually unlocks an account (admin action)
@@ -237,6 +253,21 @@ func (al *AccountLockout) UnlockAccount(email string) {
 	}
 }
 
+// UnlockIfClearedSince unlocks an account whose lock started before clearedAt.
+// It lets out-of-process tools (the admin CLI) clear a lockout through the database.
+func (al *AccountLockout) UnlockIfClearedSince(email string, clearedAt time.Time) bool {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	attempts, exists := al.attempts[email]
+	if !exists || attempts.LockedUntil == nil || clearedAt.Before(attempts.LastAttempt) {
+		return false
+	}
+	attempts.LockedUntil = nil
+	attempts.FailedCount = 0
+	return true
+}
+
 // GetAttemptInfo returns information about login attempts for an email
 func (al *AccountLockout) GetAttemptInfo(email string) *LoginAttemptInfo {
 	al.mu.RLock()
@@ -0,0 +1,189 @@
+package security
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// AIInteraction is a single prompt/response exchange with the AI service
+type AIInteraction struct {
+	ID                int64     `db:"id" json:"id"`
+	InteractionType   string    `db:"interaction_type" json:"interaction_type"` // chat, generation, translation
+	UserID            *int64    `db:"user_id" json:"user_id,omitempty"`
+	OrganizationID    *int64    `db:"organization_id" json:"organization_id,omitempty"`
+	MigrationID       *int64    `db:"migration_id" json:"migration_id,omitempty"`
+	Prompt            string    `db:"prompt" json:"prompt"`
+	Response          string    `db:"response" json:"response"`
+	WasFiltered       bool      `db:"was_filtered" json:"was_filtered"`
+	ContainsInjection bool      `db:"contains_injection" json:"contains_injection"`
+	PromptTokens      int       `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens  int       `db:"completion_tokens" json:"completion_tokens"`
+	TokensEstimated   bool      `db:"tokens_estimated" json:"tokens_estimated"`
+	LatencyMs         int64     `db:"latency_ms" json:"latency_ms"`
+	Status            string    `db:"status" json:"status"` // success, error
+	Error             *string   `db:"error" json:"error,omitempty"`
+	DataRegion        string    `db:"data_region" json:"data_region,omitempty"` // Region of the AI service instance used; empty for the default
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+}
+
+// AIInteractionFilter narrows AI interaction queries
+type AIInteractionFilter struct {
+	InteractionType string
+	UserID          int64
+	OrganizationID  int64
+	DataRegion      string
+	Since           *time.Time
+	Until           *time.Time
+}
+
+// AIAuditor records sanitized AI prompts and filtered responses for compliance review
+type AIAuditor struct {
+	filter *AIOutputFilter
+}
+
+var aiAuditor *AIAuditor
+var aiAuditorOnce sync.Once
+
+// GetAIAuditor returns the singleton AIAuditor instance
+func GetAIAuditor() *AIAuditor {
+	aiAuditorOnce.Do(func() {
+		aiAuditor = &AIAuditor{
+			filter: NewAIOutputFilter(DefaultAIOutputFilterConfig()),
+		}
+	})
+	return aiAuditor
+}
+
+// EstimateTokens approximates a token count (~4 characters per token) when the AI service doesn't report usage
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// Record sanitizes and persists an AI interaction asynchronously
+func (a *AIAuditor) Record(interaction *AIInteraction) {
+	promptResult := a.filter.FilterOutput(interaction.Prompt)
+	responseResult := a.filter.FilterOutput(interaction.Response)
+	inputResult := a.filter.FilterInput(interaction.Prompt)
+
+	interaction.Prompt = promptResult.Filtered
+	interaction.Response = responseResult.Filtered
+	interaction.WasFiltered = promptResult.WasFiltered || responseResult.WasFiltered
+	interaction.ContainsInjection = inputResult.ContainsInjection || responseResult.ContainsInjection
+	if interaction.Error != nil {
+		sanitized := a.filter.FilterOutput(*interaction.Error).Filtered
+		interaction.Error = &sanitized
+	}
+	if interaction.Status == "" {
+		interaction.Status = "success"
+	}
+	if interaction.CreatedAt.IsZero() {
+		interaction.CreatedAt = time.Now()
+	}
+
+	go func() {
+		// Apply the organization's PII policy so sample values never land in the audit trail
+		if interaction.OrganizationID != nil {
+			if masker, err := LoadPIIMasker(*interaction.OrganizationID); err == nil {
+				interaction.Prompt = masker.MaskText(interaction.Prompt)
+				interaction.Response = masker.MaskText(interaction.Response)
+			}
+		}
+
+		_, err := db.DB.Exec(`
+			INSERT INTO ai_interactions
+			(interaction_type, user_id, organization_id, migration_id, prompt, response,
+			 was_filtered, contains_injection, prompt_tokens, completion_tokens, tokens_estimated,
+			 latency_ms, status, error, data_region, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), $16)
+		`,
+			interaction.InteractionType,
+			interaction.UserID,
+			interaction.OrganizationID,
+			interaction.MigrationID,
+			interaction.Prompt,
+			interaction.Response,
+			interaction.WasFiltered,
+			interaction.ContainsInjection,
+			interaction.PromptTokens,
+			interaction.CompletionTokens,
+			interaction.TokensEstimated,
+			interaction.LatencyMs,
+			interaction.Status,
+			interaction.Error,
+			interaction.DataRegion,
+			interaction.CreatedAt,
+		)
+		if err != nil {
+			log.Printf("Error writing AI interaction to database: %v", err)
+		}
+	}()
+}
+
+// List retrieves AI interactions matching the filter, newest first
+func (a *AIAuditor) List(filter AIInteractionFilter, limit, offset int) ([]AIInteraction, error) {
+	query := `
+		SELECT id, interaction_type, user_id, organization_id, migration_id, prompt, response,
+		       was_filtered, contains_injection, prompt_tokens, completion_tokens, tokens_estimated,
+		       latency_ms, status, error, COALESCE(data_region, '') AS data_region, created_at
+		FROM ai_interactions
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 0
+
+	if filter.InteractionType != "" {
+		argCount++
+		query += fmt.Sprintf(" AND interaction_type = $%d", argCount)
+		args = append(args, filter.InteractionType)
+	}
+
+	if filter.UserID > 0 {
+		argCount++
+		query += fmt.Sprintf(" AND user_id = $%d", argCount)
+		args = append(args, filter.UserID)
+	}
+
+	if filter.OrganizationID > 0 {
+		argCount++
+		query += fmt.Sprintf(" AND organization_id = $%d", argCount)
+		args = append(args, filter.OrganizationID)
+	}
+
+	if filter.DataRegion != "" {
+		argCount++
+		query += fmt.Sprintf(" AND data_region = $%d", argCount)
+		args = append(args, filter.DataRegion)
+	}
+
+	if filter.Since != nil {
+		argCount++
+		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *filter.Since)
+	}
+
+	if filter.Until != nil {
+		argCount++
+		query += fmt.Sprintf(" AND created_at <= $%d", argCount)
+		args = append(args, *filter.Until)
+	}
+
+	query += " ORDER BY created_at DESC"
+	argCount++
+	query += fmt.Sprintf(" LIMIT $%d", argCount)
+	args = append(args, limit)
+	argCount++
+	query += fmt.Sprintf(" OFFSET $%d", argCount)
+	args = append(args, offset)
+
+	var interactions []AIInteraction
+	if err := db.DB.Select(&interactions, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to query AI interactions: %w", err)
+	}
+
+	return interactions, nil
+}
@@ -35,6 +35,31 @@ func DefaultAIOutputFilterConfig() AIOutputFilterConfig {
 	}
 }
 
+// credentialPatterns match passwords and secrets assigned in common formats
+var credentialPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)password\s*[:=]\s*['"]?[^\s'"]+['"]?`),
+	regexp.MustCompile(`(?i)passwd\s*[:=]\s*['"]?[^\s'"]+['"]?`),
+	regexp.MustCompile(`(?i)pwd\s*[:=]\s*['"]?[^\s'"]+['"]?`),
+	regexp.MustCompile(`(?i)secret\s*[:=]\s*['"]?[^\s'"]+['"]?`),
+}
+
+// apiKeyPatterns match API keys and tokens
+var apiKeyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)api[_-]?key\s*[:=]\s*['"]?[A-Za-z0-9_\-]{20,}['"]?`),
+	regexp.MustCompile(`(?i)access[_-]?token\s*[:=]\s*['"]?[A-Za-z0-9_\-\.]{20,}['"]?`),
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9_\-\.]{20,}`),
+	// AWS keys
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	// Azure keys
+	regexp.MustCompile(`[a-zA-Z0-9+/]{86}==`),
+	// Anthropic API keys
+	regexp.MustCompile(`sk-ant-[a-zA-Z0-9\-_]{80,}`),
+	// OpenAI API keys
+	regexp.MustCompile(`sk-[a-zA-Z0-9]{48}`),
+	// Generic API key pattern
+	regexp.MustCompile(`dm_[a-f0-9]{64}`),
+}
+
 // NewAIOutputFilter creates a new AI output filter
 func NewAIOutputFilter(config AIOutputFilterConfig) *AIOutputFilter {
 	filter := &AIOutputFilter{
@@ -52,32 +77,11 @@ func NewAIOutputFilter(config AIOutputFilterConfig) *AIOutputFilter {
 
 	// Build regex patterns based on config
 	if config.FilterCredentials {
-		filter.sensitivePatterns = append(filter.sensitivePatterns,
-			// Passwords in common formats
-			regexp.MustCompile(`(?i)password\s*[:=]\s*['"]?[^\s'"]+['"]?`),
-			regexp.MustCompile(`(?i)passwd\s*[:=]\s*['"]?[^\s'"]+['"]?`),
-			regexp.MustCompile(`(?i)pwd\s*[:=]\s*['"]?[^\s'"]+['"]?`),
-			regexp.MustCompile(`(?i)secret\s*[:=]\s*['"]?[^\s'"]+['"]?`),
-		)
+		filter.sensitivePatterns = append(filter.sensitivePatterns, credentialPatterns...)
 	}
 
 	if config.FilterAPIKeys {
-		filter.sensitivePatterns = append(filter.sensitivePatterns,
-			// API keys patterns
-			regexp.MustCompile(`(?i)api[_-]?key\s*[:=]\s*['"]?[A-Za-z0-9_\-]{20,}['"]?`),
-			regexp.MustCompile(`(?i)access[_-]?token\s*[:=]\s*['"]?[A-Za-z0-9_\-\.]{20,}['"]?`),
-			regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9_\-\.]{20,}`),
-			// AWS keys
-			regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
-			// Azure keys
-			regexp.MustCompile(`[a-zA-Z0-9+/]{86}==`),
-			// Anthropic API keys
-			regexp.MustCompile(`sk-ant-[a-zA-Z0-9\-_]{80,}`),
-			// OpenAI API keys
-			regexp.MustCompile(`sk-[a-zA-Z0-9]{48}`),
-			// Generic API key pattern
-			regexp.MustCompile(`dm_[a-f0-9]{64}`),
-		)
+		filter.sensitivePatterns = append(filter.sensitivePatterns, apiKeyPatterns...)
 	}
 
 	if config.FilterInternalURLs {
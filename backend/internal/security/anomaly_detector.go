@@ -0,0 +1,392 @@
+package security
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityAlert is an anomaly flagged by the AnomalyDetector
+type SecurityAlert struct {
+	ID             int64      `db:"id" json:"id"`
+	AlertType      string     `db:"alert_type" json:"alert_type"`
+	Severity       string     `db:"severity" json:"severity"`
+	OrganizationID *int64     `db:"organization_id" json:"organization_id,omitempty"`
+	UserID         *int64     `db:"user_id" json:"user_id,omitempty"`
+	IPAddress      *string    `db:"ip_address" json:"ip_address,omitempty"`
+	Title          string     `db:"title" json:"title"`
+	Details        string     `db:"details" json:"details"`
+	Status         string     `db:"status" json:"status"` // open, acknowledged, resolved
+	AcknowledgedBy *int64     `db:"acknowledged_by" json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `db:"acknowledged_at" json:"acknowledged_at,omitempty"`
+	ResolvedBy     *int64     `db:"resolved_by" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
+
+// AnomalyDetectorConfig tunes the anomaly rules
+type AnomalyDetectorConfig struct {
+	Interval             time.Duration // How often the analyzer runs (also the analysis window)
+	CountryLookback      time.Duration // History used to decide whether a login country is new
+	FailedRequestMinimum int           // Failed requests from one IP in a window before a spike is considered
+	DataAccessMinimum    int           // Data reads by one user in a window before volume is considered
+	SpikeMultiplier      float64       // How far above the per-window baseline counts as a spike
+	BaselineWindow       time.Duration // History used to compute baselines
+}
+
+// DefaultAnomalyDetectorConfig returns sensible defaults
+func DefaultAnomalyDetectorConfig() AnomalyDetectorConfig {
+	return AnomalyDetectorConfig{
+		Interval:             5 * time.Minute,
+		CountryLookback:      90 * 24 * time.Hour,
+		FailedRequestMinimum: 50,
+		DataAccessMinimum:    200,
+		SpikeMultiplier:      5,
+		BaselineWindow:       7 * 24 * time.Hour,
+	}
+}
+
+// dataAccessEndpoints are request paths that read customer data or generated artifacts
+var dataAccessEndpoints = []string{
+	"/api/v1/connections/%/metadata%",
+	"/api/v1/migrations/%/files%",
+	"/api/v1/migrations/%/download",
+	"%/export%",
+}
+
+// AnomalyDetector periodically analyzes security_audit_logs and records alerts
+type AnomalyDetector struct {
+	config   AnomalyDetectorConfig
+	stopChan chan struct{}
+	started  bool
+	mu       sync.Mutex
+}
+
+var anomalyDetector *AnomalyDetector
+var anomalyDetectorOnce sync.Once
+
+// GetAnomalyDetector returns the singleton AnomalyDetector instance
+func GetAnomalyDetector() *AnomalyDetector {
+	anomalyDetectorOnce.Do(func() {
+		anomalyDetector = &AnomalyDetector{
+			config:   DefaultAnomalyDetectorConfig(),
+			stopChan: make(chan struct{}),
+		}
+	})
+	return anomalyDetector
+}
+
+// Start launches the background analyzer
+func (d *AnomalyDetector) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started {
+		return
+	}
+	d.started = true
+
+	go func() {
+		ticker := time.NewTicker(d.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.Analyze()
+			case <-d.stopChan:
+				return
+			}
+		}
+	}()
+	log.Printf("Anomaly detector started (interval: %s)", d.config.Interval)
+}
+
+// Stop stops the background analyzer
+func (d *AnomalyDetector) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started {
+		close(d.stopChan)
+		d.started = false
+	}
+}
+
+// Analyze runs every anomaly rule over the most recent window
+func (d *AnomalyDetector) Analyze() {
+	since := time.Now().Add(-d.config.Interval)
+
+	if err := d.detectNewCountryLogins(since); err != nil {
+		log.Printf("Anomaly detector (new country): %v", err)
+	}
+	if err := d.detectFailedRequestSpikes(since); err != nil {
+		log.Printf("Anomaly detector (failed requests): %v", err)
+	}
+	if err := d.detectDataAccessVolume(since); err != nil {
+		log.Printf("Anomaly detector (data access): %v", err)
+	}
+}
+
+// detectNewCountryLogins flags successful logins from a country the user hasn't logged in from recently
+func (d *AnomalyDetector) detectNewCountryLogins(since time.Time) error {
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT l.user_id, l.organization_id, l.ip_address, l.metadata->>'country' AS country
+		FROM security_audit_logs l
+		WHERE l.event_type = 'login_success'
+		  AND l.created_at >= $1
+		  AND l.user_id IS NOT NULL
+		  AND COALESCE(l.metadata->>'country', '') <> ''
+		  AND EXISTS (
+			SELECT 1 FROM security_audit_logs p
+			WHERE p.event_type = 'login_success' AND p.user_id = l.user_id
+			  AND p.created_at < $1 AND p.created_at >= $2
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM security_audit_logs p
+			WHERE p.event_type = 'login_success' AND p.user_id = l.user_id
+			  AND p.created_at < $1 AND p.created_at >= $2
+			  AND p.metadata->>'country' = l.metadata->>'country'
+		  )
+	`, since, since.Add(-d.config.CountryLookback))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var orgID sql.NullInt64
+		var ip, country string
+		if err := rows.Scan(&userID, &orgID, &ip, &country); err != nil {
+			continue
+		}
+		d.raise(&SecurityAlert{
+			AlertType:      "new_country_login",
+			Severity:       "high",
+			OrganizationID: nullableID(orgID),
+			UserID:         &userID,
+			IPAddress:      &ip,
+			Title:          fmt.Sprintf("Login from new country: %s", country),
+		}, map[string]interface{}{"country": country}, fmt.Sprintf("new_country:%d:%s", userID, country))
+	}
+	return rows.Err()
+}
+
+// detectFailedRequestSpikes flags IPs whose failed requests far exceed their usual rate
+func (d *AnomalyDetector) detectFailedRequestSpikes(since time.Time) error {
+	windows := d.config.BaselineWindow.Seconds() / d.config.Interval.Seconds()
+
+	rows, err := db.DB.Query(`
+		WITH recent AS (
+			SELECT ip_address, COUNT(*) AS failures
+			FROM security_audit_logs
+			WHERE created_at >= $1 AND (response_status >= 400 OR blocked = true)
+			GROUP BY ip_address
+			HAVING COUNT(*) >= $2
+		), baseline AS (
+			SELECT ip_address, COUNT(*) / $4::float AS per_window
+			FROM security_audit_logs
+			WHERE created_at < $1 AND created_at >= $3 AND (response_status >= 400 OR blocked = true)
+			  AND ip_address IN (SELECT ip_address FROM recent)
+			GROUP BY ip_address
+		)
+		SELECT r.ip_address, r.failures, COALESCE(b.per_window, 0)
+		FROM recent r
+		LEFT JOIN baseline b ON b.ip_address = r.ip_address
+		WHERE r.failures >= GREATEST(COALESCE(b.per_window, 0) * $5, $2)
+	`, since, d.config.FailedRequestMinimum, since.Add(-d.config.BaselineWindow), windows, d.config.SpikeMultiplier)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ip string
+		var failures int
+		var baseline float64
+		if err := rows.Scan(&ip, &failures, &baseline); err != nil {
+			continue
+		}
+		d.raise(&SecurityAlert{
+			AlertType: "failed_request_spike",
+			Severity:  "high",
+			IPAddress: &ip,
+			Title:     fmt.Sprintf("Spike of %d failed requests from %s", failures, ip),
+		}, map[string]interface{}{
+			"failures":       failures,
+			"baseline":       baseline,
+			"window_minutes": d.config.Interval.Minutes(),
+		}, "failed_spike:"+ip)
+	}
+	return rows.Err()
+}
+
+// detectDataAccessVolume flags users reading far more data than usual
+func (d *AnomalyDetector) detectDataAccessVolume(since time.Time) error {
+	windows := d.config.BaselineWindow.Seconds() / d.config.Interval.Seconds()
+	patterns, _ := json.Marshal(dataAccessEndpoints)
+
+	rows, err := db.DB.Query(`
+		WITH patterns AS (
+			SELECT jsonb_array_elements_text($6::jsonb) AS pattern
+		), reads AS (
+			SELECT l.user_id, l.organization_id, l.created_at
+			FROM security_audit_logs l
+			WHERE l.user_id IS NOT NULL AND l.method = 'GET' AND l.created_at >= $3
+			  AND EXISTS (SELECT 1 FROM patterns p WHERE l.endpoint LIKE p.pattern)
+		), recent AS (
+			SELECT user_id, MAX(organization_id) AS organization_id, COUNT(*) AS reads
+			FROM reads WHERE created_at >= $1
+			GROUP BY user_id
+			HAVING COUNT(*) >= $2
+		), baseline AS (
+			SELECT user_id, COUNT(*) / $4::float AS per_window
+			FROM reads WHERE created_at < $1
+			GROUP BY user_id
+		)
+		SELECT r.user_id, r.organization_id, r.reads, COALESCE(b.per_window, 0)
+		FROM recent r
+		LEFT JOIN baseline b ON b.user_id = r.user_id
+		WHERE r.reads >= GREATEST(COALESCE(b.per_window, 0) * $5, $2)
+	`, since, d.config.DataAccessMinimum, since.Add(-d.config.BaselineWindow), windows, d.config.SpikeMultiplier, string(patterns))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var orgID sql.NullInt64
+		var reads int
+		var baseline float64
+		if err := rows.Scan(&userID, &orgID, &reads, &baseline); err != nil {
+			continue
+		}
+		d.raise(&SecurityAlert{
+			AlertType:      "unusual_data_access",
+			Severity:       "medium",
+			OrganizationID: nullableID(orgID),
+			UserID:         &userID,
+			Title:          fmt.Sprintf("Unusual data access volume: %d reads", reads),
+		}, map[string]interface{}{
+			"reads":          reads,
+			"baseline":       baseline,
+			"window_minutes": d.config.Interval.Minutes(),
+		}, fmt.Sprintf("data_access:%d", userID))
+	}
+	return rows.Err()
+}
+
+// raise records an alert unless an unresolved alert with the same dedupe key exists from the last day
+func (d *AnomalyDetector) raise(alert *SecurityAlert, details map[string]interface{}, dedupeKey string) {
+	detailsJSON, _ := json.Marshal(details)
+
+	result, err := db.DB.Exec(`
+		INSERT INTO security_alerts
+		(alert_type, severity, organization_id, user_id, ip_address, title, details, dedupe_key)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8
+		WHERE NOT EXISTS (
+			SELECT 1 FROM security_alerts
+			WHERE dedupe_key = $8 AND status <> 'resolved' AND created_at >= NOW() - INTERVAL '24 hours'
+		)
+	`, alert.AlertType, alert.Severity, alert.OrganizationID, alert.UserID, alert.IPAddress,
+		alert.Title, string(detailsJSON), dedupeKey)
+	if err != nil {
+		log.Printf("Failed to record security alert: %v", err)
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("[SECURITY ALERT] %s: %s", alert.Severity, alert.Title)
+	}
+}
+
+// ListAlerts returns alerts filtered by status and severity, newest first
+func ListAlerts(status, severity string, orgID *int64, limit, offset int) ([]SecurityAlert, error) {
+	query := `
+		SELECT id, alert_type, severity, organization_id, user_id, ip_address, title,
+		       COALESCE(details::text, '{}') AS details, status, acknowledged_by, acknowledged_at,
+		       resolved_by, resolved_at, created_at
+		FROM security_alerts
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 0
+
+	if status != "" {
+		argCount++
+		query += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, status)
+	}
+
+	if severity != "" {
+		argCount++
+		query += fmt.Sprintf(" AND severity = $%d", argCount)
+		args = append(args, severity)
+	}
+
+	if orgID != nil {
+		argCount++
+		query += fmt.Sprintf(" AND organization_id = $%d", argCount)
+		args = append(args, *orgID)
+	}
+
+	query += " ORDER BY created_at DESC"
+	argCount++
+	query += fmt.Sprintf(" LIMIT $%d", argCount)
+	args = append(args, limit)
+	argCount++
+	query += fmt.Sprintf(" OFFSET $%d", argCount)
+	args = append(args, offset)
+
+	var alerts []SecurityAlert
+	if err := db.DB.Select(&alerts, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to query security alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// UpdateAlertStatus acknowledges or resolves an alert
+func UpdateAlertStatus(alertID, userID int64, status string) error {
+	var query string
+	switch status {
+	case "acknowledged":
+		query = `UPDATE security_alerts SET status = 'acknowledged', acknowledged_by = $1, acknowledged_at = NOW()
+		         WHERE id = $2 AND status = 'open'`
+	case "resolved":
+		query = `UPDATE security_alerts SET status = 'resolved', resolved_by = $1, resolved_at = NOW()
+		         WHERE id = $2 AND status <> 'resolved'`
+	default:
+		return fmt.Errorf("invalid alert status: %s", status)
+	}
+
+	result, err := db.DB.Exec(query, userID, alertID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ClientCountry returns the ISO country code supplied by the CDN or load balancer, if any
+func ClientCountry(c *gin.Context) string {
+	for _, header := range []string{"CF-IPCountry", "CloudFront-Viewer-Country", "X-Country-Code"} {
+		if country := c.GetHeader(header); country != "" && country != "XX" {
+			return country
+		}
+	}
+	return ""
+}
+
+func nullableID(id sql.NullInt64) *int64 {
+	if !id.Valid {
+		return nil
+	}
+	return &id.Int64
+}
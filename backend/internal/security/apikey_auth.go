@@ -0,0 +1,192 @@
+package security
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// API key scopes
+const (
+	ScopeReadMigrations  = "read:migrations"
+	ScopeWriteMigrations = "write:migrations"
+	ScopeReadConnections = "read:connections"
+	ScopeDeploy          = "deploy"
+	// ScopeReadEvents lets no-code automation tools poll migration lifecycle events
+	ScopeReadEvents = "read:events"
+)
+
+// Account types (users.account_type) and the actor types recorded in audit metadata
+const (
+	AccountTypeHuman   = "human"
+	AccountTypeService = "service"
+
+	ActorTypeUser           = "user"
+	ActorTypeAPIKey         = "api_key"
+	ActorTypeServiceAccount = "service_account"
+)
+
+// APIKeyScopes lists every scope that can be granted to a key
+var APIKeyScopes = []string{ScopeReadMigrations, ScopeWriteMigrations, ScopeReadConnections, ScopeDeploy, ScopeReadEvents}
+
+// apiKeyRouteScopes maps "METHOD /route" to the scope an API key needs. Routes not
+// listed here are only reachable with an interactive session.
+var apiKeyRouteScopes = map[string]string{
+	"GET /api/v1/migrations":                     ScopeReadMigrations,
+	"GET /api/v1/migrations/:id":                 ScopeReadMigrations,
+	"GET /api/v1/migrations/:id/files":           ScopeReadMigrations,
+	"GET /api/v1/migrations/:id/files/*filepath": ScopeReadMigrations,
+	"GET /api/v1/migrations/:id/download":        ScopeReadMigrations,
+	"GET /api/v1/stats":                          ScopeReadMigrations,
+	"POST /api/v1/migrations":                    ScopeWriteMigrations,
+	"DELETE /api/v1/migrations/:id":              ScopeWriteMigrations,
+	"POST /api/v1/migrations/:id/start":          ScopeWriteMigrations,
+	"POST /api/v1/migrations/:id/stop":           ScopeWriteMigrations,
+	"POST /api/v1/migrations/:id/dbt-cloud/run":  ScopeDeploy,
+	"GET /api/v1/migrations/:id/deployments":     ScopeDeploy,
+	"GET /api/v1/deployments/:id/errors":         ScopeDeploy,
+	"POST /api/v1/deployments/:id/retry-failed":  ScopeDeploy,
+	"GET /api/v1/connections":                    ScopeReadConnections,
+	"GET /api/v1/connections/:id":                ScopeReadConnections,
+	"GET /api/v1/connections/:id/metadata":       ScopeReadConnections,
+	"GET /api/v1/events":                         ScopeReadEvents,
+	"GET /api/v1/events/types":                   ScopeReadEvents,
+}
+
+// ValidateScopes checks requested scopes against the known set
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		known := false
+		for _, s := range APIKeyScopes {
+			if s == scope {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown scope '%s' (allowed: %s)", scope, strings.Join(APIKeyScopes, ", "))
+		}
+	}
+	return nil
+}
+
+// apiKeyRecord is the stored key looked up during authentication
+type apiKeyRecord struct {
+	ID             int64          `db:"id"`
+	UserID         int64          `db:"user_id"`
+	Email          string         `db:"email"`
+	OrganizationID *int64         `db:"organization_id"`
+	AccountType    string         `db:"account_type"`
+	OwnerActive    bool           `db:"owner_active"`
+	IsActive       bool           `db:"is_active"`
+	Scopes         pq.StringArray `db:"scopes"`
+	AllowedIPs     pq.StringArray `db:"allowed_ips"`
+	ExpiresAt      *time.Time     `db:"expires_at"`
+}
+
+// APIKeyAuthMiddleware authenticates requests that present an API key (X-API-Key header
+// or "Bearer dm_..."), checking expiry, allowed IPs, and the scope required by the route.
+// Requests without an API key pass through to the JWT AuthMiddleware.
+func APIKeyAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := requestAPIKey(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		var record apiKeyRecord
+		err := db.DB.Get(&record, `
+			SELECT k.id, k.user_id, u.email, u.organization_id, COALESCE(u.account_type, 'human') as account_type,
+			       COALESCE(u.is_active, true) as owner_active, k.is_active, COALESCE(k.scopes, '{}') as scopes,
+			       COALESCE(k.allowed_ips, '{}') as allowed_ips, k.expires_at
+			FROM api_keys k
+			JOIN users u ON u.id = k.user_id
+			WHERE k.key_hash = $1
+		`, HashAPIKey(key))
+		if err == sql.ErrNoRows || (err == nil && (!record.IsActive || !record.OwnerActive)) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate API key"})
+			c.Abort()
+			return
+		}
+
+		validation := GetAPIKeyValidator().ValidateKey(key, c.ClientIP(), record.AllowedIPs, record.ExpiresAt)
+		if !validation.Valid {
+			status := http.StatusUnauthorized
+			if validation.IPBlocked {
+				status = http.StatusForbidden
+			} else if validation.RateLimited {
+				status = http.StatusTooManyRequests
+			}
+			c.JSON(status, gin.H{"error": validation.Error})
+			c.Abort()
+			return
+		}
+
+		required, ok := apiKeyRouteScope(c.Request.Method, c.FullPath())
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint is not available to API keys"})
+			c.Abort()
+			return
+		}
+		if !hasScope(record.Scopes, required) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":          "API key is missing the required scope",
+				"required_scope": required,
+			})
+			c.Abort()
+			return
+		}
+
+		if warning := FormatExpirationWarning(record.ExpiresAt); warning != "" {
+			c.Header("X-API-Key-Warning", warning)
+		}
+
+		go db.DB.Exec("UPDATE api_keys SET last_used_at = NOW() WHERE id = $1", record.ID)
+
+		// API keys act as their owner but never with platform admin rights
+		c.Set("user_id", record.UserID)
+		c.Set("email", record.Email)
+		c.Set("is_admin", false)
+		c.Set("api_key_id", record.ID)
+		c.Set("api_key_scopes", []string(record.Scopes))
+		c.Set("actor_type", ActorTypeAPIKey)
+		if record.AccountType == AccountTypeService {
+			c.Set("actor_type", ActorTypeServiceAccount)
+		}
+		if record.OrganizationID != nil {
+			c.Set("organization_id", *record.OrganizationID)
+		}
+
+		c.Next()
+	}
+}
+
+// apiKeyRouteScope returns the scope a key needs for a route, and false for routes API
+// keys can't use
+func apiKeyRouteScope(method, route string) (string, bool) {
+	scope, ok := apiKeyRouteScopes[method+" "+route]
+	return scope, ok
+}
+
+func hasScope(scopes []string, required string) bool {
+	for _, s := range scopes {
+		if s == required {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeployScopeRoutes(t *testing.T) {
+	deployOnly := []string{ScopeDeploy}
+
+	for _, route := range []string{
+		"POST /api/v1/migrations/:id/dbt-cloud/run",
+		"POST /api/v1/deployments/:id/retry-failed",
+		"GET /api/v1/migrations/:id/deployments",
+	} {
+		method, path, _ := strings.Cut(route, " ")
+		required, ok := apiKeyRouteScope(method, path)
+		if !ok {
+			t.Errorf("%s is not available to API keys", route)
+			continue
+		}
+		if !hasScope(deployOnly, required) {
+			t.Errorf("a deploy key can't reach %s (requires %s)", route, required)
+		}
+	}
+
+	writeRoutes := 0
+	for route, required := range apiKeyRouteScopes {
+		if required != ScopeWriteMigrations {
+			continue
+		}
+		writeRoutes++
+		if hasScope(deployOnly, required) {
+			t.Errorf("a deploy key can reach %s", route)
+		}
+	}
+	if writeRoutes == 0 {
+		t.Error("no routes require write:migrations")
+	}
+}
+
+func TestAPIKeyRouteScopeUnlisted(t *testing.T) {
+	if _, ok := apiKeyRouteScope("PUT", "/api/v1/organizations/settings"); ok {
+		t.Error("an unlisted route is available to API keys")
+	}
+}
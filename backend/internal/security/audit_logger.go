@@ -1,9 +1,13 @@
 package security
 
 import (
+	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -98,6 +102,11 @@ func (al *AuditLogger) Flush() {
 	for _, event := range events {
 		al.writeToDatabase(event)
 	}
+
+	// Stream to the external SIEM, if configured
+	if exporter := GetSIEMExporter(); exporter != nil {
+		exporter.Enqueue(events)
+	}
 }
 
 // writeToDatabase persists a security event to the database
@@ -133,10 +142,65 @@ func (al *AuditLogger) writeToDatabase(event *SecurityEvent) {
 	}
 }
 
-// GetLogs retrieves audit logs with optional filtering
+// AuditCursor is a position in the audit log, which is read newest first. GetLogs
+// returns the rows after it.
+type AuditCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// String encodes the cursor for use in a query string
+func (c AuditCursor) String() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + "." + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseAuditCursor decodes a cursor produced by AuditCursor.String
+func ParseAuditCursor(s string) (AuditCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return AuditCursor{}, fmt.Errorf("invalid audit log cursor")
+	}
+	micros, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return AuditCursor{}, fmt.Errorf("invalid audit log cursor")
+	}
+	t, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return AuditCursor{}, fmt.Errorf("invalid audit log cursor")
+	}
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return AuditCursor{}, fmt.Errorf("invalid audit log cursor")
+	}
+	return AuditCursor{CreatedAt: time.UnixMicro(t).UTC(), ID: n}, nil
+}
+
+// auditLogRow is a security_audit_logs row as read by GetLogs
+type auditLogRow struct {
+	ID             int64          `db:"id"`
+	EventType      string         `db:"event_type"`
+	Severity       string         `db:"severity"`
+	UserID         *int64         `db:"user_id"`
+	OrganizationID *int64         `db:"organization_id"`
+	IPAddress      sql.NullString `db:"ip_address"`
+	UserAgent      sql.NullString `db:"user_agent"`
+	Endpoint       sql.NullString `db:"endpoint"`
+	Method         sql.NullString `db:"method"`
+	RequestBody    sql.NullString `db:"request_body"`
+	ResponseStatus sql.NullInt64  `db:"response_status"`
+	Blocked        sql.NullBool   `db:"blocked"`
+	BlockReason    sql.NullString `db:"block_reason"`
+	Metadata       []byte         `db:"metadata"`
+	CreatedAt      time.Time      `db:"created_at"`
+}
+
+// GetLogs retrieves audit logs newest first. Pass an AuditCursor as filters["before"]
+// to page through the log with a keyset; offset is applied after it and is only
+// kept for older clients, since it still reads every skipped row.
 func (al *AuditLogger) GetLogs(filters map[string]interface{}, limit, offset int) ([]SecurityEvent, error) {
 	query := `
-		SELECT event_type, severity, user_id, organization_id, ip_address, user_agent,
+		SELECT id, event_type, severity, user_id, organization_id, ip_address, user_agent,
 		       endpoint, method, request_body, response_status, blocked, block_reason,
 		       metadata, created_at
 		FROM security_audit_logs
@@ -170,12 +234,19 @@ func (al *AuditLogger) GetLogs(filters map[string]interface{}, limit, offset int
 		args = append(args, orgID)
 	}
 
+	if actorType, ok := filters["actor_type"].(string); ok && actorType != "" {
+		argCount++
+		query += fmt.Sprintf(" AND metadata->>'actor_type' = $%d", argCount)
+		args = append(args, actorType)
+	}
+
 	if blocked, ok := filters["blocked"].(bool); ok {
 		argCount++
 		query += fmt.Sprintf(" AND blocked = $%d", argCount)
 		args = append(args, blocked)
 	}
 
+	// Date bounds also limit the partitions scanned
 	if startDate, ok := filters["start_date"].(time.Time); ok {
 		argCount++
 		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
@@ -188,8 +259,14 @@ func (al *AuditLogger) GetLogs(filters map[string]interface{}, limit, offset int
 		args = append(args, endDate)
 	}
 
-	// Order and pagination
-	query += " ORDER BY created_at DESC"
+	if cursor, ok := filters["before"].(AuditCursor); ok {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argCount+1, argCount+2)
+		argCount += 2
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+
+	// Order and pagination, matching the (created_at, id) keyset indexes
+	query += " ORDER BY created_at DESC, id DESC"
 	argCount++
 	query += fmt.Sprintf(" LIMIT $%d", argCount)
 	args = append(args, limit)
@@ -197,45 +274,34 @@ func (al *AuditLogger) GetLogs(filters map[string]interface{}, limit, offset int
 	query += fmt.Sprintf(" OFFSET $%d", argCount)
 	args = append(args, offset)
 
-	rows, err := db.DB.Query(query, args...)
-	if err != nil {
+	var rows []auditLogRow
+	if err := db.DB.Select(&rows, query, args...); err != nil {
 		return nil, fmt.Errorf("failed to query audit logs: %w", err)
 	}
-	defer rows.Close()
-
-	var events []SecurityEvent
-	for rows.Next() {
-		var event SecurityEvent
-		var metadataJSON string
-		var userID, orgID *int64
-
-		err := rows.Scan(
-			&event.EventType,
-			&event.Severity,
-			&userID,
-			&orgID,
-			&event.IPAddress,
-			&event.UserAgent,
-			&event.Endpoint,
-			&event.Method,
-			&event.RequestBody,
-			&event.ResponseStatus,
-			&event.Blocked,
-			&event.BlockReason,
-			&metadataJSON,
-			&event.Timestamp,
-		)
-		if err != nil {
-			continue
-		}
-
-		event.UserID = userID
-		event.OrganizationID = orgID
 
-		if metadataJSON != "" {
-			json.Unmarshal([]byte(metadataJSON), &event.Metadata)
+	events := make([]SecurityEvent, 0, len(rows))
+	for _, row := range rows {
+		event := SecurityEvent{
+			ID:             row.ID,
+			EventType:      row.EventType,
+			Severity:       row.Severity,
+			UserID:         row.UserID,
+			OrganizationID: row.OrganizationID,
+			IPAddress:      row.IPAddress.String,
+			UserAgent:      row.UserAgent.String,
+			Endpoint:       row.Endpoint.String,
+			Method:         row.Method.String,
+			RequestBody:    row.RequestBody.String,
+			ResponseStatus: int(row.ResponseStatus.Int64),
+			Blocked:        row.Blocked.Bool,
+			BlockReason:    row.BlockReason.String,
+			Timestamp:      row.CreatedAt,
+		}
+		if len(row.Metadata) > 0 {
+			if err := json.Unmarshal(row.Metadata, &event.Metadata); err != nil {
+				log.Printf("Invalid metadata on audit log %d: %v", row.ID, err)
+			}
 		}
-
 		events = append(events, event)
 	}
 
@@ -0,0 +1,26 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditCursorRoundTrip(t *testing.T) {
+	cursor := AuditCursor{CreatedAt: time.Date(2026, 3, 14, 9, 26, 53, 589793000, time.UTC), ID: 2718}
+
+	parsed, err := ParseAuditCursor(cursor.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.CreatedAt.Equal(cursor.CreatedAt) || parsed.ID != cursor.ID {
+		t.Errorf("parsed = %+v, want %+v", parsed, cursor)
+	}
+}
+
+func TestParseAuditCursorRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "not base64!", "MTIz", "YWJjLjQ1"} {
+		if _, err := ParseAuditCursor(s); err == nil {
+			t.Errorf("ParseAuditCursor(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Supported CAPTCHA providers
+const (
+	CaptchaProviderHCaptcha  = "hcaptcha"
+	CaptchaProviderTurnstile = "turnstile"
+)
+
+var captchaVerifyURLs = map[string]string{
+	CaptchaProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	CaptchaProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// CaptchaVerifier verifies hCaptcha or Cloudflare Turnstile tokens server-side
+type CaptchaVerifier struct {
+	provider   string
+	secret     string
+	verifyURL  string
+	httpClient *http.Client
+}
+
+// NewCaptchaVerifier creates a verifier; an empty or unknown provider disables verification
+func NewCaptchaVerifier(provider, secret string) *CaptchaVerifier {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	return &CaptchaVerifier{
+		provider:  provider,
+		secret:    secret,
+		verifyURL: captchaVerifyURLs[provider],
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// Enabled reports whether CAPTCHA verification is configured
+func (v *CaptchaVerifier) Enabled() bool {
+	return v.verifyURL != "" && v.secret != ""
+}
+
+// Provider returns the configured provider name
+func (v *CaptchaVerifier) Provider() string {
+	return v.provider
+}
+
+// Verify checks a client token with the provider. It returns nil when verification is disabled.
+func (v *CaptchaVerifier) Verify(token, remoteIP string) error {
+	if !v.Enabled() {
+		return nil
+	}
+
+	if token == "" {
+		return fmt.Errorf("CAPTCHA token is required")
+	}
+
+	form := url.Values{}
+	form.Set("secret", v.secret)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	resp, err := v.httpClient.PostForm(v.verifyURL, form)
+	if err != nil {
+		return fmt.Errorf("CAPTCHA verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid CAPTCHA verification response: %w", err)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("CAPTCHA verification failed: %s", strings.Join(result.ErrorCodes, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package security
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// DashboardBucket is one time slot of the security event series
+type DashboardBucket struct {
+	Start  time.Time `json:"start"`
+	Events int       `json:"events"`
+	Blocks int       `json:"blocks"`
+}
+
+// DashboardCount is a ranked entry (endpoint or IP) with its event and block counts
+type DashboardCount struct {
+	Key    string `json:"key"`
+	Events int    `json:"events"`
+	Blocks int    `json:"blocks"`
+}
+
+// SecurityDashboard is the chart-ready aggregation of security audit events
+type SecurityDashboard struct {
+	Period       string            `json:"period"`
+	BucketSize   string            `json:"bucket_size"`
+	TotalEvents  int               `json:"total_events"`
+	TotalBlocks  int               `json:"total_blocks"`
+	Series       []DashboardBucket `json:"series"`
+	TopEndpoints []DashboardCount  `json:"top_endpoints"`
+	TopIPs       []DashboardCount  `json:"top_ips"`
+	GeneratedAt  time.Time         `json:"generated_at"`
+}
+
+const dashboardCacheTTL = 30 * time.Second
+
+type dashboardCacheEntry struct {
+	dashboard *SecurityDashboard
+	expiresAt time.Time
+}
+
+var dashboardCache = struct {
+	mu      sync.Mutex
+	entries map[string]dashboardCacheEntry
+}{entries: make(map[string]dashboardCacheEntry)}
+
+// GetDashboard returns time-bucketed event and block series plus top endpoints and IPs.
+// Results are cached briefly so dashboards polling every few seconds don't hit the database.
+func (al *AuditLogger) GetDashboard(orgID *int64, period, bucket time.Duration) (*SecurityDashboard, error) {
+	cacheKey := fmt.Sprintf("all|%s|%s", period, bucket)
+	if orgID != nil {
+		cacheKey = fmt.Sprintf("%d|%s|%s", *orgID, period, bucket)
+	}
+
+	dashboardCache.mu.Lock()
+	if entry, ok := dashboardCache.entries[cacheKey]; ok && time.Now().Before(entry.expiresAt) {
+		dashboardCache.mu.Unlock()
+		return entry.dashboard, nil
+	}
+	dashboardCache.mu.Unlock()
+
+	bucketSeconds := int64(bucket.Seconds())
+	since := time.Now().Add(-period).Truncate(bucket)
+
+	filter := ""
+	args := []interface{}{since, bucketSeconds}
+	if orgID != nil {
+		filter = " AND organization_id = $3"
+		args = append(args, *orgID)
+	}
+
+	// One pass over the window: GROUPING SETS produces the time series, per-endpoint, and per-IP
+	// aggregates together, and ROW_NUMBER ranks endpoints and IPs so only the top 10 come back.
+	query := `
+		SELECT kind, bucket, key, events, blocks FROM (
+			SELECT
+				CASE GROUPING(bucket, endpoint, ip_address)
+					WHEN 3 THEN 'bucket'
+					WHEN 5 THEN 'endpoint'
+					ELSE 'ip'
+				END AS kind,
+				bucket,
+				COALESCE(CASE GROUPING(bucket, endpoint, ip_address)
+					WHEN 5 THEN endpoint
+					WHEN 6 THEN ip_address
+				END, '') AS key,
+				COUNT(*) AS events,
+				COUNT(*) FILTER (WHERE blocked = true) AS blocks,
+				ROW_NUMBER() OVER (
+					PARTITION BY GROUPING(bucket, endpoint, ip_address)
+					ORDER BY COUNT(*) DESC
+				) AS rank
+			FROM (
+				SELECT
+					to_timestamp(floor(extract(epoch FROM created_at) / $2) * $2) AS bucket,
+					endpoint, ip_address, blocked
+				FROM security_audit_logs
+				WHERE created_at >= $1` + filter + `
+			) e
+			GROUP BY GROUPING SETS ((bucket), (endpoint), (ip_address))
+		) ranked
+		WHERE kind = 'bucket' OR rank <= 10
+		ORDER BY kind, bucket, events DESC
+	`
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate security dashboard: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]DashboardBucket)
+	dashboard := &SecurityDashboard{
+		Period:       period.String(),
+		BucketSize:   bucket.String(),
+		TopEndpoints: []DashboardCount{},
+		TopIPs:       []DashboardCount{},
+		GeneratedAt:  time.Now(),
+	}
+
+	for rows.Next() {
+		var kind, key string
+		var bucketStart *time.Time
+		var events, blocks int
+		if err := rows.Scan(&kind, &bucketStart, &key, &events, &blocks); err != nil {
+			return nil, fmt.Errorf("failed to scan security dashboard row: %w", err)
+		}
+
+		switch kind {
+		case "bucket":
+			if bucketStart != nil {
+				counts[bucketStart.Unix()] = DashboardBucket{Start: *bucketStart, Events: events, Blocks: blocks}
+			}
+			dashboard.TotalEvents += events
+			dashboard.TotalBlocks += blocks
+		case "endpoint":
+			dashboard.TopEndpoints = append(dashboard.TopEndpoints, DashboardCount{Key: key, Events: events, Blocks: blocks})
+		case "ip":
+			dashboard.TopIPs = append(dashboard.TopIPs, DashboardCount{Key: key, Events: events, Blocks: blocks})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read security dashboard rows: %w", err)
+	}
+
+	// Zero-fill empty buckets so charts get a continuous series
+	for t := since; !t.After(time.Now()); t = t.Add(bucket) {
+		entry, ok := counts[t.Unix()]
+		if !ok {
+			entry = DashboardBucket{Start: t.UTC()}
+		}
+		dashboard.Series = append(dashboard.Series, entry)
+	}
+
+	dashboardCache.mu.Lock()
+	for key, entry := range dashboardCache.entries {
+		if time.Now().After(entry.expiresAt) {
+			delete(dashboardCache.entries, key)
+		}
+	}
+	dashboardCache.entries[cacheKey] = dashboardCacheEntry{dashboard: dashboard, expiresAt: time.Now().Add(dashboardCacheTTL)}
+	dashboardCache.mu.Unlock()
+
+	return dashboard, nil
+}
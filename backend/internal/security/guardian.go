@@ -21,10 +21,14 @@ type GuardianAgent struct {
 	patternDetector *PatternDetector
 	auditLogger     *AuditLogger
 	policies        []SecurityPolicy
+	globalPolicy    EffectivePolicy
+	orgPolicies     map[int64]EffectivePolicy
+	exemptions      []PatternExemption
 }
 
 // SecurityEvent represents a security-related event
 type SecurityEvent struct {
+	ID             int64                  `json:"id,omitempty"`
 	EventType      string                 `json:"event_type"`
 	Severity       string                 `json:"severity"`
 	UserID         *int64                 `json:"user_id,omitempty"`
@@ -76,37 +80,45 @@ func GetGuardian() *GuardianAgent {
 		}
 		guardian.loadPolicies()
 		guardian.loadBlockedPatterns()
+		go guardian.refreshPoliciesLoop()
 		log.Println("Guardian Agent initialized - Security monitoring active")
 	})
 	return guardian
 }
 
-// loadPolicies loads security policies from the database
+// loadPolicies loads security policies from the database, merging organization overrides
+// onto the global policy
 func (g *GuardianAgent) loadPolicies() {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+	policies, err := fetchPolicies()
+	if err != nil {
+		log.Printf("Warning: Could not load security policies from DB: %v", err)
+	}
+
+	hasGlobal := false
+	for _, p := range policies {
+		if p.OrganizationID == nil {
+			hasGlobal = true
+			break
+		}
+	}
+	if !hasGlobal {
+		policies = append(defaultPolicies(), policies...)
+	}
+
+	global, byOrg := buildEffectivePolicies(policies, g.rateLimiter.Config())
 
-	// For now, use default policies. Can be extended to load from DB
-	g.policies = []SecurityPolicy{
-		{
-			Name:       "rate_limit_default",
-			PolicyType: "rate_limit",
-			Rules: map[string]interface{}{
-				"requests_per_minute": 60,
-				"requests_per_hour":   1000,
-			},
-			IsActive: true,
-		},
-		{
-			Name:       "input_validation",
-			PolicyType: "input_validation",
-			Rules: map[string]interface{}{
-				"max_body_size":    1048576, // 1MB
-				"max_field_length": 10000,
-			},
-			IsActive: true,
-		},
+	exemptions, err := ListPatternExemptions(nil)
+	if err != nil {
+		log.Printf("Warning: Could not load pattern exemptions from DB: %v", err)
 	}
+	exemptions = append(append([]PatternExemption{}, builtinPatternExemptions...), exemptions...)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.policies = policies
+	g.globalPolicy = global
+	g.orgPolicies = byOrg
+	g.exemptions = exemptions
 }
 
 // loadBlockedPatterns loads suspicious patterns from the database
@@ -122,7 +134,7 @@ func (g *GuardianAgent) loadBlockedPatterns() {
 	}
 
 	for _, p := range patterns {
-		g.patternDetector.AddPattern(p.Pattern, p.PatternType, p.Severity)
+		g.patternDetector.AddDescribedPattern(p.Pattern, p.PatternType, p.Severity, p.Description)
 	}
 }
 
@@ -149,56 +161,71 @@ func (g *GuardianAgent) Middleware() gin.HandlerFunc {
 			Metadata:  make(map[string]interface{}),
 		}
 
-		// 1. Rate limiting check
-		if blocked, reason := g.rateLimiter.Check(clientIP, endpoint); blocked {
+		// Resolve the organization's policy overrides (falls back to the global policy)
+		orgID := requestOrganization(c)
+		policy := g.EffectivePolicyFor(orgID)
+		event.OrganizationID = orgID
+
+		// 1. Rate limiting check (route group limits, per API key for API-key clients)
+		identifier, bucket, limits := rateLimitBucket(c, policy)
+		result := g.rateLimiter.CheckDetailed(identifier, bucket, limits)
+		setRateLimitHeaders(c, result)
+		if result.Blocked {
 			event.EventType = "rate_limit_exceeded"
 			event.Severity = "warning"
 			event.Blocked = true
-			event.BlockReason = reason
+			event.BlockReason = result.Reason
+			event.Metadata["rate_limit_bucket"] = bucket
 			g.auditLogger.Log(event)
 
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
-				"retry_after": 60,
+				"retry_after": result.Reset,
 			})
 			c.Abort()
 			return
 		}
 
 		// 2. Read and validate request body
+		exemptions := g.exemptionsFor(c, orgID)
 		if c.Request.Body != nil && method != "GET" {
-			bodyBytes, err := io.ReadAll(c.Request.Body)
-			if err == nil {
-				// Check body size
-				if len(bodyBytes) > 1048576 { // 1MB limit
-					event.EventType = "oversized_request"
-					event.Severity = "warning"
-					event.Blocked = true
-					event.BlockReason = "Request body too large"
-					g.auditLogger.Log(event)
+			maxBodySize := int64(policy.MaxBodySize)
 
-					c.JSON(http.StatusRequestEntityTooLarge, gin.H{
-						"error": "Request body too large",
-					})
-					c.Abort()
+			// Reject declared oversized bodies before reading anything
+			if c.Request.ContentLength > maxBodySize {
+				g.rejectOversized(c, event)
+				return
+			}
+
+			if !policy.PatternDetection || scanOptedOut(exemptions) {
+				// Routes opted out of scanning stream the body straight to the handler,
+				// still capped at the policy limit
+				c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize)
+			} else if bodyBytes, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize+1)); err == nil {
+				// Check body size (chunked bodies have no declared length)
+				if int64(len(bodyBytes)) > maxBodySize {
+					g.rejectOversized(c, event)
 					return
 				}
 
 				// 3. Pattern detection for suspicious content
 				bodyString := string(bodyBytes)
-				if detected, patternType, severity := g.patternDetector.Detect(bodyString); detected {
-					event.EventType = "suspicious_pattern"
-					event.Severity = severity
-					event.Blocked = true
-					event.BlockReason = "Suspicious pattern detected: " + patternType
-					event.RequestBody = g.sanitizeForLog(bodyString)
-					g.auditLogger.Log(event)
-
-					c.JSON(http.StatusBadRequest, gin.H{
-						"error": "Invalid request content",
-					})
-					c.Abort()
-					return
+				if match := g.detect(policy, exemptions, bodyString); match != nil {
+					recordPatternMatch(event, match, "body", policy.ShadowMode)
+					if !policy.ShadowMode {
+						event.EventType = "suspicious_pattern"
+						event.Severity = match.Severity
+						event.Blocked = true
+						event.BlockReason = "Suspicious pattern detected: " + match.PatternType
+						event.RequestBody = g.sanitizeForLog(bodyString)
+						g.auditLogger.Log(event)
+
+						c.JSON(http.StatusBadRequest, gin.H{
+							"error": "Invalid request content",
+						})
+						c.Abort()
+						return
+					}
 				}
 
 				// Restore body for downstream handlers
@@ -212,11 +239,15 @@ func (g *GuardianAgent) Middleware() gin.HandlerFunc {
 		// 4. Check URL parameters for suspicious patterns
 		for key, values := range c.Request.URL.Query() {
 			for _, value := range values {
-				if detected, patternType, severity := g.patternDetector.Detect(value); detected {
+				if match := g.detect(policy, exemptions, value); match != nil {
+					recordPatternMatch(event, match, "query:"+key, policy.ShadowMode)
+					if policy.ShadowMode {
+						continue
+					}
 					event.EventType = "suspicious_query_param"
-					event.Severity = severity
+					event.Severity = match.Severity
 					event.Blocked = true
-					event.BlockReason = "Suspicious query parameter: " + key + " (" + patternType + ")"
+					event.BlockReason = "Suspicious query parameter: " + key + " (" + match.PatternType + ")"
 					g.auditLogger.Log(event)
 
 					c.JSON(http.StatusBadRequest, gin.H{
@@ -241,6 +272,16 @@ func (g *GuardianAgent) Middleware() gin.HandlerFunc {
 			}
 		}
 
+		// Record who made the request so API keys and service accounts stand out in audit logs
+		if actorType, exists := c.Get("actor_type"); exists {
+			event.Metadata["actor_type"] = actorType
+			if keyID, ok := c.Get("api_key_id"); ok {
+				event.Metadata["api_key_id"] = keyID
+			}
+		} else if event.UserID != nil {
+			event.Metadata["actor_type"] = ActorTypeUser
+		}
+
 		// Get organization ID if available
 		if orgID, exists := c.Get("organization_id"); exists {
 			if id, ok := orgID.(int64); ok {
@@ -257,12 +298,49 @@ func (g *GuardianAgent) Middleware() gin.HandlerFunc {
 
 		// Add timing metadata
 		event.Metadata["duration_ms"] = time.Since(startTime).Milliseconds()
+		if country := ClientCountry(c); country != "" {
+			event.Metadata["country"] = country
+		}
 
 		// Log the event
 		g.auditLogger.Log(event)
 	}
 }
 
+// detect runs pattern detection as allowed by the effective policy, skipping
+// pattern types disabled for the organization and exemptions for the route
+func (g *GuardianAgent) detect(policy EffectivePolicy, exemptions []PatternExemption, input string) *DetectionPattern {
+	if !policy.PatternDetection {
+		return nil
+	}
+
+	return g.patternDetector.Match(input, func(p DetectionPattern) bool {
+		if policy.DisabledPatternTypes[p.PatternType] {
+			return true
+		}
+		for _, e := range exemptions {
+			if e.Covers(p) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// rejectOversized aborts a request whose body exceeds the policy limit
+func (g *GuardianAgent) rejectOversized(c *gin.Context, event *SecurityEvent) {
+	event.EventType = "oversized_request"
+	event.Severity = "warning"
+	event.Blocked = true
+	event.BlockReason = "Request body too large"
+	g.auditLogger.Log(event)
+
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error": "Request body too large",
+	})
+	c.Abort()
+}
+
 // sanitizeForLog removes sensitive data before logging
 func (g *GuardianAgent) sanitizeForLog(body string) string {
 	// Parse as JSON and redact sensitive fields
@@ -297,6 +375,11 @@ func (g *GuardianAgent) GetAuditLogs(filters map[string]interface{}, limit, offs
 	return g.auditLogger.GetLogs(filters, limit, offset)
 }
 
+// GetDashboard retrieves the time-bucketed security dashboard
+func (g *GuardianAgent) GetDashboard(orgID *int64, period, bucket time.Duration) (*SecurityDashboard, error) {
+	return g.auditLogger.GetDashboard(orgID, period, bucket)
+}
+
 // ReloadPolicies reloads security policies from the database
 func (g *GuardianAgent) ReloadPolicies() {
 	g.loadPolicies()
@@ -0,0 +1,80 @@
+package security
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// impersonationBlockedRoutes can't be used while impersonating: they change the user's
+// credentials or ownership, or would issue a token without the impersonation claim
+var impersonationBlockedRoutes = map[string]bool{
+	"PUT /api/v1/auth/password":                                true,
+	"POST /api/v1/auth/switch-organization":                    true,
+	"POST /api/v1/auth/oauth/:provider/link":                   true,
+	"DELETE /api/v1/auth/identities/:provider":                 true,
+	"POST /api/v1/api-keys":                                    true,
+	"POST /api/v1/organizations/service-accounts/:id/api-keys": true,
+	"POST /api/v1/organizations/ownership-transfer":            true,
+	"POST /api/v1/organizations/ownership-transfer/confirm":    true,
+	"POST /api/v1/admin/impersonate":                           true,
+}
+
+// ImpersonationMiddleware rejects impersonation tokens whose session has ended and
+// records every request made with one in the security audit log
+func ImpersonationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		impersonation := middleware.GetImpersonation(c)
+		if impersonation == nil {
+			c.Next()
+			return
+		}
+
+		var active bool
+		err := db.DB.Get(&active, `
+			SELECT ended_at IS NULL AND expires_at > NOW() FROM impersonation_sessions WHERE id = $1
+		`, impersonation.SessionID)
+		if err != nil || !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Impersonation session has ended"})
+			return
+		}
+
+		blocked := impersonationBlockedRoutes[c.Request.Method+" "+c.FullPath()]
+		if blocked {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This action isn't available while impersonating a user"})
+		} else {
+			c.Next()
+		}
+
+		if _, err := db.DB.Exec(`
+			UPDATE impersonation_sessions SET action_count = action_count + 1, last_action_at = NOW() WHERE id = $1
+		`, impersonation.SessionID); err != nil {
+			log.Printf("Failed to record impersonated request for session %d: %v", impersonation.SessionID, err)
+		}
+
+		userID := middleware.GetUserID(c)
+		event := &SecurityEvent{
+			EventType:      "impersonated_request",
+			Severity:       "info",
+			UserID:         &userID,
+			IPAddress:      c.ClientIP(),
+			UserAgent:      c.Request.UserAgent(),
+			Endpoint:       c.Request.URL.Path,
+			Method:         c.Request.Method,
+			ResponseStatus: c.Writer.Status(),
+			Blocked:        blocked,
+			Metadata: map[string]interface{}{
+				"impersonation_session_id": impersonation.SessionID,
+				"impersonator_id":          impersonation.AdminID,
+				"impersonator_email":       impersonation.AdminEmail,
+			},
+		}
+		if orgID := middleware.GetOrganizationID(c); orgID != 0 {
+			event.OrganizationID = &orgID
+		}
+		GetGuardian().LogSecurityEvent(event)
+	}
+}
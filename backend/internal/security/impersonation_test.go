@@ -0,0 +1,22 @@
+package security
+
+import "testing"
+
+func TestImpersonationBlocksCredentialRoutes(t *testing.T) {
+	for _, route := range []string{
+		"PUT /api/v1/auth/password",
+		"POST /api/v1/api-keys",
+		"POST /api/v1/organizations/service-accounts/:id/api-keys",
+		"POST /api/v1/admin/impersonate",
+	} {
+		if !impersonationBlockedRoutes[route] {
+			t.Errorf("%s is available while impersonating", route)
+		}
+	}
+}
+
+func TestImpersonationAllowsReads(t *testing.T) {
+	if impersonationBlockedRoutes["GET /api/v1/organizations/service-accounts/:id/api-keys"] {
+		t.Error("listing a service account's keys is blocked while impersonating")
+	}
+}
@@ -0,0 +1,193 @@
+package security
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// IPAllowlistEntry is a CIDR range an organization permits app access from
+type IPAllowlistEntry struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	CIDR           string    `db:"cidr" json:"cidr"`
+	Description    *string   `db:"description" json:"description,omitempty"`
+	CreatedBy      *int64    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+type cachedAllowlist struct {
+	networks  []*net.IPNet
+	expiresAt time.Time
+}
+
+// IPAllowlist enforces per-organization CIDR allowlists
+type IPAllowlist struct {
+	mu       sync.RWMutex
+	byOrg    map[int64]cachedAllowlist
+	cacheTTL time.Duration
+}
+
+var ipAllowlist *IPAllowlist
+var ipAllowlistOnce sync.Once
+
+// GetIPAllowlist returns the singleton IPAllowlist instance
+func GetIPAllowlist() *IPAllowlist {
+	ipAllowlistOnce.Do(func() {
+		ipAllowlist = &IPAllowlist{
+			byOrg:    make(map[int64]cachedAllowlist),
+			cacheTTL: time.Minute,
+		}
+	})
+	return ipAllowlist
+}
+
+// NormalizeCIDR parses a CIDR or bare IP (treated as a single host) into canonical CIDR form
+func NormalizeCIDR(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", fmt.Errorf("invalid IP address or CIDR: %s", value)
+		}
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR: %s", value)
+	}
+	return network.String(), nil
+}
+
+// CIDRContains reports whether ip falls inside any of the given CIDRs
+func CIDRContains(cidrs []string, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Invalidate drops the cached allowlist for an organization after it changes
+func (a *IPAllowlist) Invalidate(orgID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.byOrg, orgID)
+}
+
+// IsAllowed reports whether the IP may access the organization. Organizations without entries allow all IPs.
+func (a *IPAllowlist) IsAllowed(orgID int64, ip string) (bool, error) {
+	networks, err := a.networksFor(orgID)
+	if err != nil {
+		return false, err
+	}
+	if len(networks) == 0 {
+		return true, nil
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false, nil
+	}
+	for _, network := range networks {
+		if network.Contains(parsed) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *IPAllowlist) networksFor(orgID int64) ([]*net.IPNet, error) {
+	a.mu.RLock()
+	cached, ok := a.byOrg[orgID]
+	a.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.networks, nil
+	}
+
+	var cidrs []string
+	if err := db.DB.Select(&cidrs, "SELECT cidr FROM organization_ip_allowlists WHERE organization_id = $1", orgID); err != nil {
+		return nil, fmt.Errorf("failed to load IP allowlist: %w", err)
+	}
+
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			networks = append(networks, network)
+		}
+	}
+
+	a.mu.Lock()
+	a.byOrg[orgID] = cachedAllowlist{networks: networks, expiresAt: time.Now().Add(a.cacheTTL)}
+	a.mu.Unlock()
+
+	return networks, nil
+}
+
+// IPAllowlistMiddleware blocks authenticated requests from IPs outside the user's organization allowlist.
+// It must run after OrganizationMiddleware. Violations are recorded through the Guardian audit pipeline.
+func (g *GuardianAgent) IPAllowlistMiddleware() gin.HandlerFunc {
+	allowlist := GetIPAllowlist()
+
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+		if userID == 0 {
+			c.Next()
+			return
+		}
+
+		orgID := middleware.GetOrganizationID(c)
+		if orgID == 0 {
+			c.Next()
+			return
+		}
+
+		clientIP := c.ClientIP()
+		allowed, err := allowlist.IsAllowed(orgID, clientIP)
+		if err != nil {
+			// Fail open on lookup errors so a database blip doesn't lock every organization out
+			log.Printf("IP allowlist check failed for organization %d: %v", orgID, err)
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			g.auditLogger.Log(&SecurityEvent{
+				EventType:      "ip_not_allowed",
+				Severity:       "high",
+				UserID:         &userID,
+				OrganizationID: &orgID,
+				IPAddress:      clientIP,
+				UserAgent:      c.Request.UserAgent(),
+				Endpoint:       c.Request.URL.Path,
+				Method:         c.Request.Method,
+				ResponseStatus: http.StatusForbidden,
+				Blocked:        true,
+				BlockReason:    "IP address not in organization allowlist",
+			})
+
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access from this IP address is not allowed by your organization"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
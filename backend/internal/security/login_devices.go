@@ -0,0 +1,175 @@
+package security
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// versionPattern matches version numbers in a user agent, which change with every
+// browser update and would otherwise make each update look like a new device
+var versionPattern = regexp.MustCompile(`\d+([._]\d+)*`)
+
+// DeviceFingerprint identifies the device a login came from. Browsers that send a
+// device ID (a random value the frontend keeps in local storage) are identified by it;
+// otherwise the user agent without version numbers is used.
+func DeviceFingerprint(userAgent, deviceID string) string {
+	source := "ua:" + versionPattern.ReplaceAllString(strings.ToLower(userAgent), "")
+	if deviceID = strings.TrimSpace(deviceID); deviceID != "" {
+		source = "id:" + deviceID
+	}
+	hash := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(hash[:])
+}
+
+// DescribeUserAgent returns a short description such as "Chrome on Windows"
+func DescribeUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/") || strings.Contains(ua, "python") || strings.Contains(ua, "go-http-client"):
+		browser = "Script"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "mac os"):
+		os = "macOS"
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	case userAgent == "":
+		return "Unknown device"
+	}
+	if len(userAgent) > 60 {
+		return userAgent[:60] + "..."
+	}
+	return userAgent
+}
+
+// LoginDevice is a device a user has signed in from
+type LoginDevice struct {
+	ID          int64      `db:"id" json:"id"`
+	UserAgent   *string    `db:"user_agent" json:"user_agent,omitempty"`
+	Device      string     `db:"-" json:"device"`
+	IPAddress   *string    `db:"ip_address" json:"ip_address,omitempty"`
+	Country     *string    `db:"country" json:"country,omitempty"`
+	FirstSeenAt time.Time  `db:"first_seen_at" json:"first_seen_at"`
+	LastSeenAt  time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	ReportedAt  *time.Time `db:"reported_at" json:"reported_at,omitempty"`
+}
+
+// LoginDeviceCheck is the result of recording a successful login
+type LoginDeviceCheck struct {
+	DeviceID   int64
+	NewDevice  bool // First login from this device
+	NewCountry bool // First login from this country on any device
+}
+
+// Notify reports whether the user should be told about the login
+func (c LoginDeviceCheck) Notify() bool {
+	return c.NewDevice || c.NewCountry
+}
+
+// RecordLoginDevice stores the device of a successful login and reports whether the
+// device or country is new for the user. A user's first recorded login is never new,
+// so existing users aren't notified about the device they already use.
+func RecordLoginDevice(userID int64, userAgent, deviceID, ipAddress, country string) (LoginDeviceCheck, error) {
+	var check LoginDeviceCheck
+	fingerprint := DeviceFingerprint(userAgent, deviceID)
+
+	var known struct {
+		Devices         int  `db:"devices"`
+		CountrySeen     bool `db:"country_seen"`
+		FingerprintSeen bool `db:"fingerprint_seen"`
+	}
+	// Devices the user reported don't count as known, so they're reported again
+	err := db.DB.Get(&known, `
+		SELECT COUNT(*) AS devices,
+		       COALESCE(BOOL_OR(country = $2 AND reported_at IS NULL), false) AS country_seen,
+		       COALESCE(BOOL_OR(fingerprint = $3 AND reported_at IS NULL), false) AS fingerprint_seen
+		FROM login_devices WHERE user_id = $1
+	`, userID, country, fingerprint)
+	if err != nil {
+		return check, err
+	}
+
+	err = db.DB.Get(&check.DeviceID, `
+		INSERT INTO login_devices (user_id, fingerprint, user_agent, ip_address, country)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (user_id, fingerprint) DO UPDATE
+		SET user_agent = EXCLUDED.user_agent, ip_address = EXCLUDED.ip_address,
+		    country = COALESCE(EXCLUDED.country, login_devices.country), last_seen_at = NOW()
+		RETURNING id
+	`, userID, fingerprint, userAgent, ipAddress, country)
+	if err != nil {
+		return check, err
+	}
+
+	if known.Devices > 0 {
+		check.NewDevice = !known.FingerprintSeen
+		check.NewCountry = country != "" && !known.CountrySeen
+	}
+	return check, nil
+}
+
+// ListLoginDevices returns the devices a user has signed in from, most recent first
+func ListLoginDevices(userID int64) ([]LoginDevice, error) {
+	devices := []LoginDevice{}
+	err := db.DB.Select(&devices, `
+		SELECT id, user_agent, ip_address, country, first_seen_at, last_seen_at, reported_at
+		FROM login_devices WHERE user_id = $1
+		ORDER BY last_seen_at DESC
+	`, userID)
+	for i := range devices {
+		ua := ""
+		if devices[i].UserAgent != nil {
+			ua = *devices[i].UserAgent
+		}
+		devices[i].Device = DescribeUserAgent(ua)
+	}
+	return devices, err
+}
+
+// AccountLockedAt returns when the user locked their account from a new sign-in
+// notification, or nil if it isn't locked
+func AccountLockedAt(userID int64) (*time.Time, error) {
+	var lockedAt sql.NullTime
+	if err := db.DB.Get(&lockedAt, "SELECT locked_at FROM users WHERE id = $1", userID); err != nil {
+		return nil, err
+	}
+	if !lockedAt.Valid {
+		return nil, nil
+	}
+	return &lockedAt.Time, nil
+}
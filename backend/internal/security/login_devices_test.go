@@ -0,0 +1,39 @@
+package security
+
+import "testing"
+
+const (
+	chromeWindows120 = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.130 Safari/537.36"
+	chromeWindows121 = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.6167.85 Safari/537.36"
+	safariIPhone     = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1"
+)
+
+func TestDeviceFingerprint(t *testing.T) {
+	if DeviceFingerprint(chromeWindows120, "") != DeviceFingerprint(chromeWindows121, "") {
+		t.Error("a browser update changed the fingerprint")
+	}
+	if DeviceFingerprint(chromeWindows120, "") == DeviceFingerprint(safariIPhone, "") {
+		t.Error("different browsers have the same fingerprint")
+	}
+	if DeviceFingerprint(chromeWindows120, "device-a") == DeviceFingerprint(chromeWindows120, "device-b") {
+		t.Error("device IDs are ignored")
+	}
+	if DeviceFingerprint(chromeWindows120, "device-a") != DeviceFingerprint(safariIPhone, "device-a") {
+		t.Error("the user agent changed the fingerprint of a device with an ID")
+	}
+}
+
+func TestDescribeUserAgent(t *testing.T) {
+	tests := map[string]string{
+		chromeWindows120: "Chrome on Windows",
+		safariIPhone:     "Safari on iOS",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91": "Edge on Windows",
+		"curl/8.4.0": "Script",
+		"":           "Unknown device",
+	}
+	for ua, want := range tests {
+		if got := DescribeUserAgent(ua); got != want {
+			t.Errorf("DescribeUserAgent(%q) = %q, want %q", ua, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireServiceToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tc := range []struct {
+		name       string
+		configured string
+		sent       string
+		want       int
+	}{
+		{"not configured", "", "", http.StatusForbidden},
+		{"not configured, token sent", "", "anything", http.StatusForbidden},
+		{"missing", "s3cret", "", http.StatusUnauthorized},
+		{"wrong", "s3cret", "guess", http.StatusUnauthorized},
+		{"valid", "s3cret", "s3cret", http.StatusOK},
+	} {
+		router := gin.New()
+		router.POST("/internal/ai-usage", RequireServiceToken(tc.configured), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("POST", "/internal/ai-usage", nil)
+		if tc.sent != "" {
+			req.Header.Set("X-Service-Token", tc.sent)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package security
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/domains"
+	"github.com/datamigrate-ai/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const userOrgCacheTTL = time.Minute
+
+type cachedUserOrg struct {
+	defaultOrgID int64
+	memberOf     map[int64]bool
+	expiresAt    time.Time
+}
+
+var userOrgCache = struct {
+	mu      sync.RWMutex
+	entries map[int64]cachedUserOrg
+}{entries: make(map[int64]cachedUserOrg)}
+
+// userOrganizations returns the user's default organization and the organizations they
+// are a member of, cached briefly because security middleware needs them on every request
+func userOrganizations(userID int64) (cachedUserOrg, error) {
+	userOrgCache.mu.RLock()
+	cached, ok := userOrgCache.entries[userID]
+	userOrgCache.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	var defaultOrgID sql.NullInt64
+	if err := db.DB.Get(&defaultOrgID, "SELECT organization_id FROM users WHERE id = $1", userID); err != nil {
+		return cachedUserOrg{}, err
+	}
+	var orgIDs []int64
+	if err := db.DB.Select(&orgIDs, "SELECT organization_id FROM organization_members WHERE user_id = $1", userID); err != nil {
+		return cachedUserOrg{}, err
+	}
+
+	cached = cachedUserOrg{
+		defaultOrgID: defaultOrgID.Int64,
+		memberOf:     make(map[int64]bool, len(orgIDs)),
+		expiresAt:    time.Now().Add(userOrgCacheTTL),
+	}
+	for _, id := range orgIDs {
+		cached.memberOf[id] = true
+	}
+
+	userOrgCache.mu.Lock()
+	userOrgCache.entries[userID] = cached
+	userOrgCache.mu.Unlock()
+
+	return cached, nil
+}
+
+// ResolveUserOrganization returns the organization a user's requests act in: requested
+// (the organization their token was issued for) if they are still a member of it, or
+// their default organization for tokens without one. It returns 0 if there is none.
+func ResolveUserOrganization(userID, requested int64) (int64, error) {
+	orgs, err := userOrganizations(userID)
+	if err != nil {
+		return 0, err
+	}
+	if requested != 0 {
+		if !orgs.memberOf[requested] {
+			return 0, nil
+		}
+		return requested, nil
+	}
+	return orgs.defaultOrgID, nil
+}
+
+// ForgetUserOrganizations drops the cached memberships of a user whose memberships or
+// default organization changed
+func ForgetUserOrganizations(userID int64) {
+	userOrgCache.mu.Lock()
+	delete(userOrgCache.entries, userID)
+	userOrgCache.mu.Unlock()
+}
+
+// OrganizationMiddleware sets the organization an authenticated request acts in, so
+// handlers can scope their queries with middleware.GetOrganizationID. Tokens issued for
+// an organization the user has since left are rejected. It must run after AuthMiddleware.
+func OrganizationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+		// API keys act in their owner's default organization, set when the key is validated
+		if _, ok := c.Get("api_key_id"); ok || userID == 0 {
+			c.Next()
+			return
+		}
+
+		requested := middleware.GetOrganizationID(c)
+		orgID, err := ResolveUserOrganization(userID, requested)
+		if err != nil {
+			log.Printf("Failed to resolve organization of user %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve organization"})
+			c.Abort()
+			return
+		}
+		if requested != 0 && orgID == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are no longer a member of this organization"})
+			c.Abort()
+			return
+		}
+		// A custom domain only serves its own organization
+		if domainOrgID, ok := domains.RequestOrganization(c.Request); ok && orgID != domainOrgID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This session belongs to another organization than this site"})
+			c.Abort()
+			return
+		}
+		if orgID != 0 {
+			c.Set("organization_id", orgID)
+		}
+
+		c.Next()
+	}
+}
+
+// requestOrganization resolves the organization of a request's bearer token before
+// AuthMiddleware has run. It returns nil for anonymous or invalid tokens.
+func requestOrganization(c *gin.Context) *int64 {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil
+	}
+
+	claims, err := middleware.ValidateToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		return nil
+	}
+
+	orgID, err := ResolveUserOrganization(claims.UserID, claims.OrganizationID)
+	if err != nil || orgID == 0 {
+		return nil
+	}
+	return &orgID
+}
@@ -0,0 +1,158 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hash algorithms
+const (
+	HashBcrypt   = "bcrypt"
+	HashArgon2id = "argon2id"
+)
+
+// argon2 salt and key lengths, as recommended by RFC 9106
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// PasswordHashing holds the parameters new password hashes are made with. Hashes made
+// with other parameters still verify and are replaced when their user signs in.
+type PasswordHashing struct {
+	Algorithm         string `json:"algorithm"` // bcrypt or argon2id
+	BcryptCost        int    `json:"bcrypt_cost,omitempty"`
+	Argon2Memory      uint32 `json:"argon2_memory_kib,omitempty"`
+	Argon2Iterations  uint32 `json:"argon2_iterations,omitempty"`
+	Argon2Parallelism uint8  `json:"argon2_parallelism,omitempty"`
+}
+
+// DefaultPasswordHashing is bcrypt at its default cost, what every hash was made with
+// before hashing was configurable
+func DefaultPasswordHashing() PasswordHashing {
+	return PasswordHashing{Algorithm: HashBcrypt, BcryptCost: bcrypt.DefaultCost}
+}
+
+// Validate checks that the parameters are usable
+func (p PasswordHashing) Validate() error {
+	switch p.Algorithm {
+	case HashBcrypt:
+		if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost %d must be between %d and %d", p.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case HashArgon2id:
+		if p.Argon2Memory < 8*uint32(p.Argon2Parallelism) || p.Argon2Iterations < 1 || p.Argon2Parallelism < 1 {
+			return fmt.Errorf("argon2id needs at least 1 iteration, 1 thread and 8 KiB of memory per thread")
+		}
+	default:
+		return fmt.Errorf("password hash algorithm %q must be %s or %s", p.Algorithm, HashBcrypt, HashArgon2id)
+	}
+	return nil
+}
+
+var (
+	passwordHashing   = DefaultPasswordHashing()
+	passwordHashingMu sync.RWMutex
+)
+
+// ConfigurePasswordHashing sets the parameters new password hashes are made with
+func ConfigurePasswordHashing(p PasswordHashing) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	passwordHashingMu.Lock()
+	passwordHashing = p
+	passwordHashingMu.Unlock()
+	return nil
+}
+
+// CurrentPasswordHashing returns the parameters new password hashes are made with
+func CurrentPasswordHashing() PasswordHashing {
+	passwordHashingMu.RLock()
+	defer passwordHashingMu.RUnlock()
+	return passwordHashing
+}
+
+// HashPassword hashes a password with the configured parameters
+func HashPassword(password string) (string, error) {
+	p := CurrentPasswordHashing()
+	if p.Algorithm == HashArgon2id {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, p.Argon2Iterations, p.Argon2Memory, p.Argon2Parallelism, argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+			p.Argon2Memory, p.Argon2Iterations, p.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.BcryptCost)
+	return string(hash), err
+}
+
+// VerifyPassword reports whether password matches a bcrypt or argon2id hash
+func VerifyPassword(hash, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := parseArgon2Hash(hash)
+		if err != nil {
+			return false
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(candidate, key) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// PasswordNeedsRehash reports whether a hash was made with an algorithm or parameters
+// other than the configured ones
+func PasswordNeedsRehash(hash string) bool {
+	current := CurrentPasswordHashing()
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, _, _, err := parseArgon2Hash(hash)
+		return err != nil || current.Algorithm != HashArgon2id ||
+			params.Argon2Memory != current.Argon2Memory ||
+			params.Argon2Iterations != current.Argon2Iterations ||
+			params.Argon2Parallelism != current.Argon2Parallelism
+	}
+	if current.Algorithm != HashBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != current.BcryptCost
+}
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// parseArgon2Hash splits a hash in the PHC string format:
+// $argon2id$v=19$m=<KiB>,t=<iterations>,p=<threads>$<salt>$<key>
+func parseArgon2Hash(hash string) (PasswordHashing, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != HashArgon2id {
+		return PasswordHashing{}, nil, nil, errInvalidArgon2Hash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return PasswordHashing{}, nil, nil, errInvalidArgon2Hash
+	}
+	params := PasswordHashing{Algorithm: HashArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Iterations, &params.Argon2Parallelism); err != nil || params.Validate() != nil {
+		return PasswordHashing{}, nil, nil, errInvalidArgon2Hash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return PasswordHashing{}, nil, nil, errInvalidArgon2Hash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return PasswordHashing{}, nil, nil, errInvalidArgon2Hash
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,104 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// usePasswordHashing configures hashing for one test
+func usePasswordHashing(t *testing.T, p PasswordHashing) {
+	t.Helper()
+	if err := ConfigurePasswordHashing(p); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ConfigurePasswordHashing(DefaultPasswordHashing()) })
+}
+
+func TestHashPasswordBcrypt(t *testing.T) {
+	usePasswordHashing(t, PasswordHashing{Algorithm: HashBcrypt, BcryptCost: bcrypt.MinCost})
+
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost, _ := bcrypt.Cost([]byte(hash)); cost != bcrypt.MinCost {
+		t.Errorf("cost = %d, want %d", cost, bcrypt.MinCost)
+	}
+	if !VerifyPassword(hash, "correct horse") || VerifyPassword(hash, "battery staple") {
+		t.Error("bcrypt hash didn't verify only its own password")
+	}
+	if PasswordNeedsRehash(hash) {
+		t.Error("a hash made with the current settings needs a rehash")
+	}
+}
+
+func TestHashPasswordArgon2id(t *testing.T) {
+	usePasswordHashing(t, PasswordHashing{Algorithm: HashArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1})
+
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("hash = %s", hash)
+	}
+	if !VerifyPassword(hash, "correct horse") || VerifyPassword(hash, "battery staple") {
+		t.Error("argon2id hash didn't verify only its own password")
+	}
+	if PasswordNeedsRehash(hash) {
+		t.Error("a hash made with the current settings needs a rehash")
+	}
+}
+
+func TestPasswordNeedsRehashWhenSettingsChange(t *testing.T) {
+	usePasswordHashing(t, PasswordHashing{Algorithm: HashBcrypt, BcryptCost: bcrypt.MinCost})
+	bcryptHash, _ := HashPassword("correct horse")
+
+	usePasswordHashing(t, PasswordHashing{Algorithm: HashBcrypt, BcryptCost: bcrypt.MinCost + 1})
+	if !PasswordNeedsRehash(bcryptHash) {
+		t.Error("a bcrypt hash with another cost doesn't need a rehash")
+	}
+
+	usePasswordHashing(t, PasswordHashing{Algorithm: HashArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1})
+	if !PasswordNeedsRehash(bcryptHash) {
+		t.Error("a bcrypt hash doesn't need a rehash after switching to argon2id")
+	}
+	if !VerifyPassword(bcryptHash, "correct horse") {
+		t.Error("bcrypt hashes must still verify after switching to argon2id")
+	}
+	argonHash, _ := HashPassword("correct horse")
+
+	usePasswordHashing(t, PasswordHashing{Algorithm: HashArgon2id, Argon2Memory: 128, Argon2Iterations: 1, Argon2Parallelism: 1})
+	if !PasswordNeedsRehash(argonHash) {
+		t.Error("an argon2id hash with other parameters doesn't need a rehash")
+	}
+}
+
+func TestVerifyPasswordRejectsMalformedArgon2Hash(t *testing.T) {
+	for _, hash := range []string{
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ",
+	} {
+		if VerifyPassword(hash, "") {
+			t.Errorf("%s verified", hash)
+		}
+	}
+}
+
+func TestConfigurePasswordHashingValidates(t *testing.T) {
+	for _, p := range []PasswordHashing{
+		{Algorithm: HashBcrypt, BcryptCost: 3},
+		{Algorithm: HashArgon2id, Argon2Memory: 64, Argon2Iterations: 0, Argon2Parallelism: 1},
+		{Algorithm: "scrypt"},
+	} {
+		if err := ConfigurePasswordHashing(p); err == nil {
+			t.Errorf("%+v was accepted", p)
+		}
+	}
+	if CurrentPasswordHashing() != DefaultPasswordHashing() {
+		t.Error("rejected settings were applied")
+	}
+}
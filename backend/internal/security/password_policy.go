@@ -0,0 +1,274 @@
+package security
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// PasswordPolicy defines the password requirements for an organization
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+	CheckBreached    bool `json:"check_breached"` // HaveIBeenPwned k-anonymity range lookup
+	HistoryCount     int  `json:"history_count"`  // Number of previous passwords that can't be reused (0 disables)
+	MaxAgeDays       int  `json:"max_age_days"`   // Days before a password must be changed (0 disables)
+}
+
+// PasswordPolicyViolation describes a single failed password rule
+type PasswordPolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// DefaultPasswordPolicy returns the policy used when an organization hasn't configured one
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    false,
+		CheckBreached:    true,
+		HistoryCount:     5,
+		MaxAgeDays:       0,
+	}
+}
+
+// Validate checks that the policy settings themselves are sensible
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < 6 || p.MinLength > 128 {
+		return fmt.Errorf("min_length must be between 6 and 128")
+	}
+	if p.HistoryCount < 0 || p.HistoryCount > 24 {
+		return fmt.Errorf("history_count must be between 0 and 24")
+	}
+	if p.MaxAgeDays < 0 || p.MaxAgeDays > 3650 {
+		return fmt.Errorf("max_age_days must be between 0 and 3650")
+	}
+	return nil
+}
+
+// LoadPasswordPolicy returns the organization's policy, falling back to the default
+func LoadPasswordPolicy(orgID int64) PasswordPolicy {
+	policy := DefaultPasswordPolicy()
+	if orgID == 0 {
+		return policy
+	}
+
+	var raw sql.NullString
+	err := db.DB.Get(&raw, "SELECT password_policy FROM organizations WHERE id = $1", orgID)
+	if err != nil || !raw.Valid || raw.String == "" {
+		return policy
+	}
+
+	if err := json.Unmarshal([]byte(raw.String), &policy); err != nil {
+		log.Printf("Invalid password policy for organization %d, using default: %v", orgID, err)
+		return DefaultPasswordPolicy()
+	}
+
+	return policy
+}
+
+// CheckPassword evaluates a new password against the policy, returning every violated rule.
+// userID may be 0 for new accounts, in which case the history rule is skipped.
+func (p PasswordPolicy) CheckPassword(password string, userID int64) []PasswordPolicyViolation {
+	violations := []PasswordPolicyViolation{}
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, PasswordPolicyViolation{
+			Rule:    "min_length",
+			Message: fmt.Sprintf("Password must be at least %d characters long", p.MinLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, PasswordPolicyViolation{Rule: "require_uppercase", Message: "Password must contain an uppercase letter"})
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, PasswordPolicyViolation{Rule: "require_lowercase", Message: "Password must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, PasswordPolicyViolation{Rule: "require_digit", Message: "Password must contain a number"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, PasswordPolicyViolation{Rule: "require_symbol", Message: "Password must contain a symbol"})
+	}
+
+	if userID > 0 && p.HistoryCount > 0 && IsPasswordReused(userID, password, p.HistoryCount) {
+		violations = append(violations, PasswordPolicyViolation{
+			Rule:    "history",
+			Message: fmt.Sprintf("Password must not match any of your last %d passwords", p.HistoryCount),
+		})
+	}
+
+	// The breach check needs a network round-trip, so only run it once the cheap rules pass
+	if p.CheckBreached && len(violations) == 0 {
+		if breached, err := GetBreachChecker().IsBreached(password); err != nil {
+			log.Printf("Password breach check unavailable: %v", err)
+		} else if breached {
+			violations = append(violations, PasswordPolicyViolation{
+				Rule:    "breached",
+				Message: "This password has appeared in a known data breach; please choose a different one",
+			})
+		}
+	}
+
+	return violations
+}
+
+// IsExpired reports whether a password changed at the given time has exceeded the max age
+func (p PasswordPolicy) IsExpired(changedAt *time.Time) bool {
+	if p.MaxAgeDays <= 0 || changedAt == nil {
+		return false
+	}
+	return time.Since(*changedAt) > time.Duration(p.MaxAgeDays)*24*time.Hour
+}
+
+// IsPasswordExpired reports whether a user's password has exceeded the max age of the
+// organization's policy. Accounts without a recorded change count from their creation.
+func IsPasswordExpired(userID, orgID int64) bool {
+	policy := LoadPasswordPolicy(orgID)
+	if policy.MaxAgeDays <= 0 {
+		return false
+	}
+
+	var changedAt *time.Time
+	err := db.DB.Get(&changedAt, "SELECT COALESCE(password_changed_at, created_at) FROM users WHERE id = $1", userID)
+	if err != nil {
+		log.Printf("Failed to load password age for user %d: %v", userID, err)
+		return false
+	}
+	return policy.IsExpired(changedAt)
+}
+
+// IsPasswordReused compares a candidate against the user's current and recent password hashes
+func IsPasswordReused(userID int64, password string, historyCount int) bool {
+	var hashes []string
+	err := db.DB.Select(&hashes, `
+		SELECT password FROM users WHERE id = $1
+		UNION ALL
+		(SELECT password_hash FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2)
+	`, userID, historyCount)
+	if err != nil {
+		log.Printf("Failed to load password history for user %d: %v", userID, err)
+		return false
+	}
+
+	for _, hash := range hashes {
+		if VerifyPassword(hash, password) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordPasswordHistory stores a user's outgoing password hash and trims old entries
+func RecordPasswordHistory(execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}, userID int64, oldHash string) error {
+	if _, err := execer.Exec(
+		"INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)",
+		userID, oldHash,
+	); err != nil {
+		return err
+	}
+
+	// Keep at most 24 entries (the largest history_count a policy can set)
+	_, err := execer.Exec(`
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT 24
+		)
+	`, userID)
+	return err
+}
+
+// BreachChecker checks passwords against the HaveIBeenPwned range API.
+// Only the first 5 characters of the SHA-1 hash leave the server (k-anonymity).
+type BreachChecker struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+var breachChecker *BreachChecker
+var breachCheckerOnce sync.Once
+
+// GetBreachChecker returns the singleton BreachChecker instance
+func GetBreachChecker() *BreachChecker {
+	breachCheckerOnce.Do(func() {
+		breachChecker = &BreachChecker{
+			baseURL: "https://api.pwnedpasswords.com/range/",
+			httpClient: &http.Client{
+				Timeout: 3 * time.Second,
+			},
+		}
+	})
+	return breachChecker
+}
+
+// IsBreached reports whether the password appears in the breach corpus
+func (b *BreachChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequest("GET", b.baseURL+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	// Padding hides the real response size from network observers
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "DataMigrate-AI")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("breach check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("breach check returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		// Lines look like "SUFFIX:COUNT"; padded entries have a count of 0
+		line := scanner.Text()
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || parts[0] != suffix {
+			continue
+		}
+		return strings.TrimSpace(parts[1]) != "0", nil
+	}
+
+	return false, scanner.Err()
+}
@@ -8,8 +8,9 @@ import (
 
 // PatternDetector detects suspicious patterns in input
 type PatternDetector struct {
-	mu       sync.RWMutex
-	patterns []DetectionPattern
+	mu        sync.RWMutex
+	patterns  []DetectionPattern
+	prefilter *keywordPrefilter
 }
 
 // DetectionPattern represents a pattern to detect
@@ -32,6 +33,9 @@ func (pd *PatternDetector) LoadDefaultPatterns() {
 	pd.mu.Lock()
 	defer pd.mu.Unlock()
 
+	// Start from a clean slate so reloading doesn't duplicate patterns
+	pd.patterns = make([]DetectionPattern, 0)
+
 	// SQL Injection patterns
 	sqlInjectionPatterns := []struct {
 		pattern     string
@@ -143,6 +147,8 @@ func (pd *PatternDetector) LoadDefaultPatterns() {
 	for _, p := range sensitiveDataPatterns {
 		pd.addPatternInternal(p.pattern, "sensitive_data", "medium", p.description)
 	}
+
+	pd.prefilter = newKeywordPrefilter(pd.patterns)
 }
 
 // addPatternInternal adds a pattern without locking (internal use)
@@ -162,6 +168,11 @@ func (pd *PatternDetector) addPatternInternal(pattern, patternType, severity, de
 
 // AddPattern adds a custom pattern to the detector
 func (pd *PatternDetector) AddPattern(pattern, patternType, severity string) error {
+	return pd.AddDescribedPattern(pattern, patternType, severity, "")
+}
+
+// AddDescribedPattern adds a custom pattern with a description used for review and exemptions
+func (pd *PatternDetector) AddDescribedPattern(pattern, patternType, severity, description string) error {
 	pd.mu.Lock()
 	defer pd.mu.Unlock()
 
@@ -174,26 +185,66 @@ func (pd *PatternDetector) AddPattern(pattern, patternType, severity string) err
 		Pattern:     compiled,
 		PatternType: patternType,
 		Severity:    severity,
+		Description: description,
 	})
+	pd.prefilter = newKeywordPrefilter(pd.patterns)
 
 	return nil
 }
 
+// Name identifies the pattern for exemptions: its description, or the expression itself
+func (p DetectionPattern) Name() string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return p.Pattern.String()
+}
+
 // Detect checks input for suspicious patterns
 func (pd *PatternDetector) Detect(input string) (detected bool, patternType string, severity string) {
+	return pd.DetectExcluding(input, nil)
+}
+
+// DetectExcluding checks input against every pattern whose type isn't excluded
+func (pd *PatternDetector) DetectExcluding(input string, excluded map[string]bool) (detected bool, patternType string, severity string) {
+	match := pd.Match(input, func(p DetectionPattern) bool {
+		return excluded[p.PatternType]
+	})
+	if match == nil {
+		return false, "", ""
+	}
+	return true, match.PatternType, match.Severity
+}
+
+// Match returns the first pattern matching input, ignoring patterns for which skip returns true
+func (pd *PatternDetector) Match(input string, skip func(DetectionPattern) bool) *DetectionPattern {
 	pd.mu.RLock()
 	defer pd.mu.RUnlock()
 
 	// Normalize input
 	normalizedInput := strings.ToLower(input)
 
-	for _, p := range pd.patterns {
+	// Find every pattern keyword in one pass; patterns whose keywords are all
+	// absent can't match and skip their regex
+	var found []bool
+	if pd.prefilter != nil && prefilterable(normalizedInput) {
+		found = pd.prefilter.scan(normalizedInput)
+	}
+
+	for i, p := range pd.patterns {
+		if skip != nil && skip(p) {
+			continue
+		}
+		if found != nil && !pd.prefilter.candidate(i, found) {
+			continue
+		}
 		if p.Pattern.MatchString(normalizedInput) || p.Pattern.MatchString(input) {
-			return true, p.PatternType, p.Severity
+			match := p
+			return &match
 		}
 	}
 
-	return false, "", ""
+	return nil
 }
 
 // DetectAll returns all matching patterns
@@ -204,7 +255,15 @@ func (pd *PatternDetector) DetectAll(input string) []DetectionPattern {
 	var matches []DetectionPattern
 	normalizedInput := strings.ToLower(input)
 
-	for _, p := range pd.patterns {
+	var found []bool
+	if pd.prefilter != nil && prefilterable(normalizedInput) {
+		found = pd.prefilter.scan(normalizedInput)
+	}
+
+	for i, p := range pd.patterns {
+		if found != nil && !pd.prefilter.candidate(i, found) {
+			continue
+		}
 		if p.Pattern.MatchString(normalizedInput) || p.Pattern.MatchString(input) {
 			matches = append(matches, p)
 		}
@@ -0,0 +1,260 @@
+package security
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// Pattern detection modes for the pattern_detection policy
+const (
+	PatternModeEnforce = "enforce" // block matching requests
+	PatternModeShadow  = "shadow"  // log matches as pattern_shadow_match and let the request through
+)
+
+// PatternExemption whitelists a detection pattern on a route. Empty PatternType or
+// Pattern act as wildcards, so a route-only exemption skips scanning entirely.
+type PatternExemption struct {
+	ID             int64     `json:"id"`
+	OrganizationID *int64    `json:"organization_id,omitempty"`
+	Route          string    `json:"route"`
+	Method         string    `json:"method,omitempty"`
+	PatternType    string    `json:"pattern_type,omitempty"`
+	Pattern        string    `json:"pattern,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	CreatedBy      *int64    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// builtinPatternExemptions cover routes whose payloads legitimately contain SQL or
+// attack strings. They always apply and can't be deleted through the API.
+var builtinPatternExemptions = []PatternExemption{
+	{Route: "/api/v1/chat", Method: "POST", PatternType: "sql_injection", Pattern: "SQL statement in input", Reason: "Chat messages discuss SQL"},
+	{Route: "/api/v1/security/validate", Method: "POST", Reason: "Validation endpoint scans the payload itself"},
+}
+
+// AppliesTo reports whether the exemption matches a request. Route matches either the
+// registered route template (e.g. /api/v1/migrations/:id/start) or a path prefix.
+func (e PatternExemption) AppliesTo(method, path, fullPath string, orgID *int64) bool {
+	if e.OrganizationID != nil && (orgID == nil || *orgID != *e.OrganizationID) {
+		return false
+	}
+	if e.Method != "" && !strings.EqualFold(e.Method, method) {
+		return false
+	}
+	return e.Route == fullPath || strings.HasPrefix(path, e.Route)
+}
+
+// Covers reports whether the exemption whitelists a detection pattern
+func (e PatternExemption) Covers(p DetectionPattern) bool {
+	if e.PatternType != "" && e.PatternType != p.PatternType {
+		return false
+	}
+	return e.Pattern == "" || e.Pattern == p.Name()
+}
+
+// exemptionsFor returns the loaded exemptions that apply to the current request
+func (g *GuardianAgent) exemptionsFor(c *gin.Context, orgID *int64) []PatternExemption {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var applicable []PatternExemption
+	for _, e := range g.exemptions {
+		if e.AppliesTo(c.Request.Method, c.Request.URL.Path, c.FullPath(), orgID) {
+			applicable = append(applicable, e)
+		}
+	}
+	return applicable
+}
+
+// scanOptedOut reports whether a route-wide exemption (no pattern type or pattern)
+// applies, in which case the body isn't read or scanned at all
+func scanOptedOut(exemptions []PatternExemption) bool {
+	for _, e := range exemptions {
+		if e.PatternType == "" && e.Pattern == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// recordPatternMatch stores the matched pattern on the event so admins can review it
+// and whitelist it from the detections API
+func recordPatternMatch(event *SecurityEvent, match *DetectionPattern, location string, shadow bool) {
+	event.Metadata["pattern_type"] = match.PatternType
+	event.Metadata["pattern"] = match.Name()
+	event.Metadata["pattern_severity"] = match.Severity
+	event.Metadata["pattern_location"] = location
+	if shadow {
+		event.EventType = "pattern_shadow_match"
+	}
+}
+
+// ValidatePatternExemption checks an exemption before it's stored
+func ValidatePatternExemption(e *PatternExemption) error {
+	e.Route = strings.TrimSpace(e.Route)
+	if !strings.HasPrefix(e.Route, "/") {
+		return fmt.Errorf("route must start with /")
+	}
+	if e.Route == "/" && e.PatternType == "" && e.Pattern == "" {
+		return fmt.Errorf("exempting every route from every pattern is not allowed; disable pattern detection instead")
+	}
+	if e.PatternType != "" && !isPatternType(e.PatternType) {
+		return fmt.Errorf("unknown pattern type: %s", e.PatternType)
+	}
+	e.Method = strings.ToUpper(strings.TrimSpace(e.Method))
+	return nil
+}
+
+// ListPatternExemptions returns exemptions for an organization plus global ones.
+// A nil orgID returns every exemption.
+func ListPatternExemptions(orgID *int64) ([]PatternExemption, error) {
+	query := `
+		SELECT id, organization_id, route, COALESCE(method, ''), COALESCE(pattern_type, ''),
+		       COALESCE(pattern, ''), COALESCE(reason, ''), created_by, created_at
+		FROM pattern_exemptions`
+	args := []interface{}{}
+	if orgID != nil {
+		query += ` WHERE organization_id IS NULL OR organization_id = $1`
+		args = append(args, *orgID)
+	}
+	query += ` ORDER BY route, id`
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exemptions []PatternExemption
+	for rows.Next() {
+		var e PatternExemption
+		if err := rows.Scan(&e.ID, &e.OrganizationID, &e.Route, &e.Method, &e.PatternType, &e.Pattern, &e.Reason, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		exemptions = append(exemptions, e)
+	}
+	return exemptions, rows.Err()
+}
+
+// CreatePatternExemption stores a new exemption
+func CreatePatternExemption(e *PatternExemption) error {
+	if err := ValidatePatternExemption(e); err != nil {
+		return err
+	}
+	return db.DB.QueryRow(`
+		INSERT INTO pattern_exemptions (organization_id, route, method, pattern_type, pattern, reason, created_by)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7)
+		RETURNING id, created_at
+	`, e.OrganizationID, e.Route, e.Method, e.PatternType, e.Pattern, e.Reason, e.CreatedBy).Scan(&e.ID, &e.CreatedAt)
+}
+
+// DeletePatternExemption removes an exemption
+func DeletePatternExemption(id int64) error {
+	result, err := db.DB.Exec("DELETE FROM pattern_exemptions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PatternDetection is a blocked (or shadow-mode) request recorded by the Guardian
+type PatternDetection struct {
+	ID             int64     `json:"id"`
+	EventType      string    `json:"event_type"`
+	Severity       string    `json:"severity"`
+	OrganizationID *int64    `json:"organization_id,omitempty"`
+	UserID         *int64    `json:"user_id,omitempty"`
+	IPAddress      string    `json:"ip_address"`
+	Endpoint       string    `json:"endpoint"`
+	Method         string    `json:"method"`
+	Blocked        bool      `json:"blocked"`
+	BlockReason    string    `json:"block_reason,omitempty"`
+	PatternType    string    `json:"pattern_type,omitempty"`
+	Pattern        string    `json:"pattern,omitempty"`
+	Location       string    `json:"location,omitempty"`
+	RequestBody    string    `json:"request_body,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// patternDetectionEvents are the audit event types produced by pattern detection
+var patternDetectionEvents = []string{"suspicious_pattern", "suspicious_query_param", "pattern_shadow_match"}
+
+// ListPatternDetections returns recent pattern detections for review. mode filters to
+// "blocked" or "shadow" detections; empty returns both.
+func ListPatternDetections(mode string, orgID *int64, limit, offset int) ([]PatternDetection, error) {
+	eventTypes := patternDetectionEvents
+	switch mode {
+	case "blocked":
+		eventTypes = patternDetectionEvents[:2]
+	case "shadow":
+		eventTypes = patternDetectionEvents[2:]
+	}
+
+	query := `
+		SELECT id, event_type, severity, organization_id, user_id, COALESCE(ip_address, ''),
+		       COALESCE(endpoint, ''), COALESCE(method, ''), COALESCE(blocked, false),
+		       COALESCE(block_reason, ''), COALESCE(request_body, ''), metadata, created_at
+		FROM security_audit_logs
+		WHERE event_type = ANY($1)`
+	args := []interface{}{pq.Array(eventTypes)}
+	if orgID != nil {
+		args = append(args, *orgID)
+		query += fmt.Sprintf(" AND organization_id = $%d", len(args))
+	}
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var detections []PatternDetection
+	for rows.Next() {
+		var d PatternDetection
+		var metadataJSON []byte
+		if err := rows.Scan(&d.ID, &d.EventType, &d.Severity, &d.OrganizationID, &d.UserID, &d.IPAddress,
+			&d.Endpoint, &d.Method, &d.Blocked, &d.BlockReason, &d.RequestBody, &metadataJSON, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		var metadata map[string]interface{}
+		if json.Unmarshal(metadataJSON, &metadata) == nil {
+			d.PatternType, _ = metadata["pattern_type"].(string)
+			d.Pattern, _ = metadata["pattern"].(string)
+			d.Location, _ = metadata["pattern_location"].(string)
+		}
+		detections = append(detections, d)
+	}
+	return detections, rows.Err()
+}
+
+// GetPatternDetection returns a single detection, used to whitelist it
+func GetPatternDetection(id int64) (*PatternDetection, error) {
+	var d PatternDetection
+	var metadataJSON []byte
+	err := db.DB.QueryRow(`
+		SELECT id, event_type, organization_id, COALESCE(endpoint, ''), COALESCE(method, ''), metadata
+		FROM security_audit_logs
+		WHERE id = $1 AND event_type = ANY($2)
+	`, id, pq.Array(patternDetectionEvents)).Scan(&d.ID, &d.EventType, &d.OrganizationID, &d.Endpoint, &d.Method, &metadataJSON)
+	if err != nil {
+		return nil, err
+	}
+	var metadata map[string]interface{}
+	if json.Unmarshal(metadataJSON, &metadata) == nil {
+		d.PatternType, _ = metadata["pattern_type"].(string)
+		d.Pattern, _ = metadata["pattern"].(string)
+	}
+	return &d, nil
+}
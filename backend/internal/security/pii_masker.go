@@ -0,0 +1,216 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// PII rule types
+const (
+	PIIRuleTypeColumn  = "column"  // Pattern matches column names; every value in the column is masked
+	PIIRuleTypePattern = "pattern" // Pattern matches values; only the matching substrings are masked
+)
+
+// PII mask strategies
+const (
+	PIIMaskRedact  = "redact"  // Replace with a fixed placeholder
+	PIIMaskHash    = "hash"    // Replace with a short, stable SHA-256 digest (keeps joinability)
+	PIIMaskPartial = "partial" // Keep the last 4 characters
+)
+
+// PIIRule is an organization-defined rule for detecting sensitive data
+type PIIRule struct {
+	ID             int64     `db:"id" json:"id"`
+	OrganizationID int64     `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	RuleType       string    `db:"rule_type" json:"rule_type"`
+	Pattern        string    `db:"pattern" json:"pattern"`
+	MaskStrategy   string    `db:"mask_strategy" json:"mask_strategy"`
+	IsActive       bool      `db:"is_active" json:"is_active"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// PIIPolicy is the serializable form of an organization's rules, shared with the AI service
+type PIIPolicy struct {
+	SensitiveColumns []string `json:"sensitive_columns"`
+	ValuePatterns    []string `json:"value_patterns"`
+	DefaultStrategy  string   `json:"default_strategy"`
+}
+
+type compiledPIIRule struct {
+	name     string
+	re       *regexp.Regexp
+	strategy string
+}
+
+// PIIMasker masks sensitive sample values before they leave the backend
+type PIIMasker struct {
+	columnRules []compiledPIIRule
+	valueRules  []compiledPIIRule
+}
+
+// builtinPIIPatterns are always applied to values, regardless of organization rules
+var builtinPIIPatterns = []compiledPIIRule{
+	{name: "email", re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), strategy: PIIMaskRedact},
+	{name: "ssn", re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), strategy: PIIMaskRedact},
+	{name: "credit_card", re: regexp.MustCompile(`\b(?:\d{4}[- ]?){3}\d{4}\b`), strategy: PIIMaskPartial},
+	{name: "iban", re: regexp.MustCompile(`\b[A-Z]{2}\d{2}[A-Z0-9]{11,30}\b`), strategy: PIIMaskPartial},
+	{name: "phone", re: regexp.MustCompile(`\+\d{1,3}[\s-]?\(?\d{1,4}\)?[\s-]?\d{3,4}[\s-]?\d{3,4}\b`), strategy: PIIMaskRedact},
+}
+
+// ValidatePIIRule checks a rule's type, strategy, and pattern
+func ValidatePIIRule(rule *PIIRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rule.RuleType != PIIRuleTypeColumn && rule.RuleType != PIIRuleTypePattern {
+		return fmt.Errorf("rule_type must be '%s' or '%s'", PIIRuleTypeColumn, PIIRuleTypePattern)
+	}
+	switch rule.MaskStrategy {
+	case "":
+		rule.MaskStrategy = PIIMaskRedact
+	case PIIMaskRedact, PIIMaskHash, PIIMaskPartial:
+	default:
+		return fmt.Errorf("mask_strategy must be one of: redact, hash, partial")
+	}
+	if _, err := regexp.Compile(rule.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+	return nil
+}
+
+// NewPIIMasker compiles a set of rules; invalid or inactive rules are skipped
+func NewPIIMasker(rules []PIIRule) *PIIMasker {
+	m := &PIIMasker{}
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+		pattern := rule.Pattern
+		if rule.RuleType == PIIRuleTypeColumn {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		compiled := compiledPIIRule{name: rule.Name, re: re, strategy: rule.MaskStrategy}
+		if rule.RuleType == PIIRuleTypeColumn {
+			m.columnRules = append(m.columnRules, compiled)
+		} else {
+			m.valueRules = append(m.valueRules, compiled)
+		}
+	}
+	return m
+}
+
+// LoadPIIMasker builds a masker from an organization's active rules
+func LoadPIIMasker(orgID int64) (*PIIMasker, error) {
+	if orgID == 0 {
+		return NewPIIMasker(nil), nil
+	}
+
+	var rules []PIIRule
+	err := db.DB.Select(&rules, `
+		SELECT id, organization_id, name, rule_type, pattern, mask_strategy, is_active, created_at, updated_at
+		FROM pii_rules
+		WHERE organization_id = $1 AND is_active = true
+	`, orgID)
+	if err != nil {
+		return NewPIIMasker(nil), fmt.Errorf("failed to load PII rules: %w", err)
+	}
+
+	return NewPIIMasker(rules), nil
+}
+
+// IsSensitiveColumn reports whether a column is covered by a column rule
+func (m *PIIMasker) IsSensitiveColumn(column string) bool {
+	return m.columnRule(column) != nil
+}
+
+func (m *PIIMasker) columnRule(column string) *compiledPIIRule {
+	for i := range m.columnRules {
+		if m.columnRules[i].re.MatchString(column) {
+			return &m.columnRules[i]
+		}
+	}
+	return nil
+}
+
+// MaskValue masks a single sample value from the given column
+func (m *PIIMasker) MaskValue(column, value string) string {
+	if value == "" {
+		return value
+	}
+	if rule := m.columnRule(column); rule != nil {
+		return maskWith(rule.strategy, value)
+	}
+	return m.MaskText(value)
+}
+
+// MaskText masks value-pattern matches (organization and built-in) within free text
+func (m *PIIMasker) MaskText(text string) string {
+	for _, rules := range [][]compiledPIIRule{m.valueRules, builtinPIIPatterns} {
+		for _, rule := range rules {
+			strategy := rule.strategy
+			text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+				return maskWith(strategy, match)
+			})
+		}
+	}
+	return text
+}
+
+// MaskSampleRows masks every value of a set of sample rows in place
+func (m *PIIMasker) MaskSampleRows(rows []map[string]interface{}) {
+	for _, row := range rows {
+		for column, value := range row {
+			if value == nil {
+				continue
+			}
+			row[column] = m.MaskValue(column, fmt.Sprintf("%v", value))
+		}
+	}
+}
+
+// Policy returns the rules in a form the AI service can apply while profiling source data
+func (m *PIIMasker) Policy() *PIIPolicy {
+	policy := &PIIPolicy{
+		SensitiveColumns: []string{},
+		ValuePatterns:    []string{},
+		DefaultStrategy:  PIIMaskRedact,
+	}
+	for _, rule := range m.columnRules {
+		policy.SensitiveColumns = append(policy.SensitiveColumns, rule.re.String())
+	}
+	for _, rules := range [][]compiledPIIRule{m.valueRules, builtinPIIPatterns} {
+		for _, rule := range rules {
+			policy.ValuePatterns = append(policy.ValuePatterns, rule.re.String())
+		}
+	}
+	return policy
+}
+
+// maskWith applies a mask strategy to a value
+func maskWith(strategy, value string) string {
+	switch strategy {
+	case PIIMaskHash:
+		sum := sha256.Sum256([]byte(value))
+		return "pii_" + hex.EncodeToString(sum[:])[:12]
+	case PIIMaskPartial:
+		runes := []rune(value)
+		if len(runes) <= 4 {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+	default:
+		return "[PII]"
+	}
+}
@@ -0,0 +1,395 @@
+package security
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// Security policy types stored in security_policies
+const (
+	PolicyTypeRateLimit        = "rate_limit"        // rules: requests_per_minute, requests_per_hour, burst_limit (default for unmatched routes)
+	PolicyTypeInputValidation  = "input_validation"  // rules: max_body_size (bytes)
+	PolicyTypePatternDetection = "pattern_detection" // rules: enabled, mode, disabled_types
+)
+
+// PatternTypes are the detector categories that can be toggled per organization
+var PatternTypes = []string{"sql_injection", "xss", "prompt_injection", "command_injection", "path_traversal", "sensitive_data"}
+
+// Default input validation limit when no policy sets one
+const defaultMaxBodySize = 1048576 // 1MB
+
+// policyRefreshInterval controls how often policies are reloaded so changes made on
+// other instances take effect without a restart
+const policyRefreshInterval = time.Minute
+
+// EffectivePolicy is the merged global + organization policy applied to a request
+type EffectivePolicy struct {
+	RateLimit            RateLimitConfig `json:"rate_limit"`
+	MaxBodySize          int             `json:"max_body_size"`
+	PatternDetection     bool            `json:"pattern_detection"`
+	ShadowMode           bool            `json:"shadow_mode"`
+	DisabledPatternTypes map[string]bool `json:"disabled_pattern_types,omitempty"`
+	RouteLimits          []RouteRateLimit `json:"route_limits"`
+}
+
+// ValidatePolicyRules checks a policy's rules against its type
+func ValidatePolicyRules(policyType string, rules map[string]interface{}) error {
+	switch policyType {
+	case PolicyTypeRateLimit:
+		return validateRateLimitRules(rules)
+	case PolicyTypeRouteRateLimit:
+		return validateRouteRateLimitRules(rules)
+	case PolicyTypeInputValidation:
+		if value, ok := rules["max_body_size"]; ok {
+			n, ok := value.(float64)
+			if !ok || n < 1024 || n > 100*1048576 {
+				return fmt.Errorf("max_body_size must be between 1024 and %d bytes", 100*1048576)
+			}
+		}
+	case PolicyTypePatternDetection:
+		if value, ok := rules["enabled"]; ok {
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("enabled must be a boolean")
+			}
+		}
+		if value, ok := rules["mode"]; ok && value != PatternModeEnforce && value != PatternModeShadow {
+			return fmt.Errorf("mode must be %s or %s", PatternModeEnforce, PatternModeShadow)
+		}
+		if value, ok := rules["disabled_types"]; ok {
+			types, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("disabled_types must be a list")
+			}
+			for _, t := range types {
+				if !isPatternType(t) {
+					return fmt.Errorf("unknown pattern type: %v", t)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("policy_type must be one of: %s, %s, %s, %s", PolicyTypeRateLimit, PolicyTypeRouteRateLimit, PolicyTypeInputValidation, PolicyTypePatternDetection)
+	}
+	return nil
+}
+
+// validateRateLimitRules checks the numeric thresholds shared by rate limit policies
+func validateRateLimitRules(rules map[string]interface{}) error {
+	for _, key := range []string{"requests_per_minute", "requests_per_hour", "burst_limit"} {
+		if value, ok := rules[key]; ok {
+			n, ok := value.(float64)
+			if !ok || n < 1 || n > 1000000 {
+				return fmt.Errorf("%s must be a number between 1 and 1000000", key)
+			}
+		}
+	}
+	return nil
+}
+
+// applyRateLimitRules overrides thresholds present in a policy's rules
+func applyRateLimitRules(config *RateLimitConfig, rules map[string]interface{}) {
+	if n, ok := rules["requests_per_minute"].(float64); ok {
+		config.RequestsPerMinute = int(n)
+	}
+	if n, ok := rules["requests_per_hour"].(float64); ok {
+		config.RequestsPerHour = int(n)
+	}
+	if n, ok := rules["burst_limit"].(float64); ok {
+		config.BurstLimit = int(n)
+	}
+}
+
+func isPatternType(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, t := range PatternTypes {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultPolicies are used when the database has no global policies
+func defaultPolicies() []SecurityPolicy {
+	return []SecurityPolicy{
+		{
+			Name:       "rate_limit_default",
+			PolicyType: PolicyTypeRateLimit,
+			Rules: map[string]interface{}{
+				"requests_per_minute": float64(300),
+				"requests_per_hour":   float64(3000),
+				"burst_limit":         float64(50),
+			},
+			IsActive: true,
+		},
+		{
+			Name:       "input_validation",
+			PolicyType: PolicyTypeInputValidation,
+			Rules: map[string]interface{}{
+				"max_body_size": float64(defaultMaxBodySize),
+			},
+			IsActive: true,
+		},
+	}
+}
+
+// fetchPolicies loads active policies from the database
+func fetchPolicies() ([]SecurityPolicy, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, name, COALESCE(description, ''), policy_type, rules, is_active, organization_id
+		FROM security_policies
+		WHERE is_active = true
+		ORDER BY organization_id NULLS FIRST, updated_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []SecurityPolicy
+	for rows.Next() {
+		var p SecurityPolicy
+		var rulesJSON []byte
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.PolicyType, &rulesJSON, &p.IsActive, &p.OrganizationID); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(rulesJSON, &p.Rules); err != nil {
+			log.Printf("Guardian Agent: skipping policy %d with invalid rules: %v", p.ID, err)
+			continue
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// applyPolicy merges a policy's rules onto an effective policy
+func applyPolicy(effective *EffectivePolicy, p SecurityPolicy) {
+	number := func(key string) (int, bool) {
+		if v, ok := p.Rules[key].(float64); ok {
+			return int(v), true
+		}
+		return 0, false
+	}
+
+	switch p.PolicyType {
+	case PolicyTypeRateLimit:
+		applyRateLimitRules(&effective.RateLimit, p.Rules)
+	case PolicyTypeRouteRateLimit:
+		applyRouteRateLimit(effective, p)
+	case PolicyTypeInputValidation:
+		if n, ok := number("max_body_size"); ok {
+			effective.MaxBodySize = n
+		}
+	case PolicyTypePatternDetection:
+		if enabled, ok := p.Rules["enabled"].(bool); ok {
+			effective.PatternDetection = enabled
+		}
+		if mode, ok := p.Rules["mode"].(string); ok {
+			effective.ShadowMode = mode == PatternModeShadow
+		}
+		if types, ok := p.Rules["disabled_types"].([]interface{}); ok {
+			effective.DisabledPatternTypes = make(map[string]bool)
+			for _, t := range types {
+				if s, ok := t.(string); ok {
+					effective.DisabledPatternTypes[s] = true
+				}
+			}
+		}
+	}
+}
+
+// buildEffectivePolicies computes the global policy and one merged policy per organization
+func buildEffectivePolicies(policies []SecurityPolicy, base RateLimitConfig) (EffectivePolicy, map[int64]EffectivePolicy) {
+	global := EffectivePolicy{
+		RateLimit:        base,
+		MaxBodySize:      defaultMaxBodySize,
+		PatternDetection: true,
+		RouteLimits:      defaultRouteLimits(base),
+	}
+	for _, p := range policies {
+		if p.OrganizationID == nil {
+			applyPolicy(&global, p)
+		}
+	}
+
+	byOrg := make(map[int64]EffectivePolicy)
+	for _, p := range policies {
+		if p.OrganizationID == nil {
+			continue
+		}
+		effective, ok := byOrg[*p.OrganizationID]
+		if !ok {
+			effective = global
+		}
+		applyPolicy(&effective, p)
+		byOrg[*p.OrganizationID] = effective
+	}
+
+	return global, byOrg
+}
+
+// EffectivePolicyFor returns the policy for an organization, falling back to the global policy
+func (g *GuardianAgent) EffectivePolicyFor(orgID *int64) EffectivePolicy {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if orgID != nil {
+		if policy, ok := g.orgPolicies[*orgID]; ok {
+			return policy
+		}
+	}
+	return g.globalPolicy
+}
+
+// refreshPoliciesLoop periodically reloads policies from the database
+func (g *GuardianAgent) refreshPoliciesLoop() {
+	ticker := time.NewTicker(policyRefreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		g.loadPolicies()
+	}
+}
+
+// ListPolicies returns policies visible to an organization (its own plus global ones).
+// A nil orgID returns every policy.
+func ListPolicies(orgID *int64) ([]SecurityPolicy, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), policy_type, rules, is_active, organization_id
+		FROM security_policies`
+	args := []interface{}{}
+	if orgID != nil {
+		query += ` WHERE organization_id IS NULL OR organization_id = $1`
+		args = append(args, *orgID)
+	}
+	query += ` ORDER BY organization_id NULLS FIRST, policy_type, id`
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []SecurityPolicy
+	for rows.Next() {
+		var p SecurityPolicy
+		var rulesJSON []byte
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.PolicyType, &rulesJSON, &p.IsActive, &p.OrganizationID); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(rulesJSON, &p.Rules)
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// GetPolicy returns a single policy by ID
+func GetPolicy(id int64) (*SecurityPolicy, error) {
+	var p SecurityPolicy
+	var rulesJSON []byte
+	err := db.DB.QueryRow(`
+		SELECT id, name, COALESCE(description, ''), policy_type, rules, is_active, organization_id
+		FROM security_policies WHERE id = $1
+	`, id).Scan(&p.ID, &p.Name, &p.Description, &p.PolicyType, &rulesJSON, &p.IsActive, &p.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(rulesJSON, &p.Rules)
+	return &p, nil
+}
+
+// SavePolicy inserts a new policy or updates an existing one (when ID is set)
+func SavePolicy(p *SecurityPolicy) error {
+	if err := ValidatePolicyRules(p.PolicyType, p.Rules); err != nil {
+		return err
+	}
+	rulesJSON, err := json.Marshal(p.Rules)
+	if err != nil {
+		return err
+	}
+
+	if p.ID == 0 {
+		return db.DB.QueryRow(`
+			INSERT INTO security_policies (name, description, policy_type, rules, is_active, organization_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, p.Name, p.Description, p.PolicyType, rulesJSON, p.IsActive, p.OrganizationID).Scan(&p.ID)
+	}
+
+	result, err := db.DB.Exec(`
+		UPDATE security_policies
+		SET name = $1, description = $2, rules = $3, is_active = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`, p.Name, p.Description, rulesJSON, p.IsActive, p.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeletePolicy removes a policy
+func DeletePolicy(id int64) error {
+	result, err := db.DB.Exec("DELETE FROM security_policies WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// LoosensGlobalPolicy reports whether an organization override would relax the
+// global policy. Organization admins may only tighten limits.
+func (g *GuardianAgent) LoosensGlobalPolicy(policyType string, rules map[string]interface{}) bool {
+	global := g.EffectivePolicyFor(nil)
+	exceeds := func(key string, limit int) bool {
+		v, ok := rules[key].(float64)
+		return ok && int(v) > limit
+	}
+
+	switch policyType {
+	case PolicyTypeRateLimit:
+		return exceeds("requests_per_minute", global.RateLimit.RequestsPerMinute) ||
+			exceeds("requests_per_hour", global.RateLimit.RequestsPerHour) ||
+			exceeds("burst_limit", global.RateLimit.BurstLimit)
+	case PolicyTypeRouteRateLimit:
+		route, _ := rules["route"].(string)
+		method, _ := rules["method"].(string)
+		client, _ := rules["client"].(string)
+		limit := global.routeLimitFor(method, route, client == RouteClientAPIKey)
+		config := global.RateLimit
+		if limit != nil {
+			config = limit.Config
+		}
+		return exceeds("requests_per_minute", config.RequestsPerMinute) ||
+			exceeds("requests_per_hour", config.RequestsPerHour) ||
+			exceeds("burst_limit", config.BurstLimit)
+	case PolicyTypeInputValidation:
+		return exceeds("max_body_size", global.MaxBodySize)
+	case PolicyTypePatternDetection:
+		if enabled, ok := rules["enabled"].(bool); ok && !enabled && global.PatternDetection {
+			return true
+		}
+		if mode, ok := rules["mode"].(string); ok && mode == PatternModeShadow && !global.ShadowMode {
+			return true
+		}
+		if types, ok := rules["disabled_types"].([]interface{}); ok {
+			for _, t := range types {
+				if s, ok := t.(string); ok && !global.DisabledPatternTypes[s] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
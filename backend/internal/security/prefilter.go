@@ -0,0 +1,212 @@
+package security
+
+import (
+	"regexp/syntax"
+	"strings"
+)
+
+// keywordPrefilter is an Aho-Corasick automaton over the literal keywords each
+// detection pattern requires. One pass over the input finds every keyword, so only
+// patterns whose keywords appear (or that have none) need their regex evaluated.
+//
+// Transitions are a dense table with failure links folded in, so scanning is one
+// lookup per byte. Bytes are mapped to classes first: only bytes that occur in some
+// keyword get their own column, and every other byte shares class 0.
+type keywordPrefilter struct {
+	classes  [256]uint8 // byte -> column of delta
+	stride   int        // columns per state
+	delta    []int32    // next state for state*stride + class
+	outputs  [][]int32  // keyword IDs ending at each state (including via failure links)
+	keywords int
+	// patternKeywords maps each pattern index to its keyword IDs. nil means the
+	// pattern has no extractable keywords and must always be evaluated.
+	patternKeywords [][]int32
+}
+
+// newKeywordPrefilter builds the automaton for a set of compiled patterns
+func newKeywordPrefilter(patterns []DetectionPattern) *keywordPrefilter {
+	pf := &keywordPrefilter{patternKeywords: make([][]int32, len(patterns))}
+
+	var keywords []string
+	ids := make(map[string]int32)
+	for i, p := range patterns {
+		for _, kw := range requiredKeywords(p.Pattern.String()) {
+			id, ok := ids[kw]
+			if !ok {
+				id = int32(len(keywords))
+				ids[kw] = id
+				keywords = append(keywords, kw)
+			}
+			pf.patternKeywords[i] = append(pf.patternKeywords[i], id)
+		}
+	}
+	pf.keywords = len(keywords)
+
+	pf.stride = 1
+	for _, kw := range keywords {
+		for i := 0; i < len(kw); i++ {
+			if pf.classes[kw[i]] == 0 {
+				pf.classes[kw[i]] = uint8(pf.stride)
+				pf.stride++
+			}
+		}
+	}
+
+	pf.addState()
+	for id, kw := range keywords {
+		pf.insert(kw, int32(id))
+	}
+	pf.buildFailureLinks()
+	return pf
+}
+
+// addState appends a state with no transitions yet (-1) and returns it
+func (pf *keywordPrefilter) addState() int32 {
+	state := int32(len(pf.outputs))
+	for i := 0; i < pf.stride; i++ {
+		pf.delta = append(pf.delta, -1)
+	}
+	pf.outputs = append(pf.outputs, nil)
+	return state
+}
+
+func (pf *keywordPrefilter) insert(keyword string, id int32) {
+	state := int32(0)
+	for i := 0; i < len(keyword); i++ {
+		at := int(state)*pf.stride + int(pf.classes[keyword[i]])
+		if pf.delta[at] < 0 {
+			pf.delta[at] = pf.addState()
+		}
+		state = pf.delta[at]
+	}
+	pf.outputs[state] = append(pf.outputs[state], id)
+}
+
+// buildFailureLinks computes failure links breadth-first, replacing each missing trie
+// transition with the one its failure state takes, and merges outputs
+func (pf *keywordPrefilter) buildFailureLinks() {
+	fail := make([]int32, len(pf.outputs))
+	queue := make([]int32, 0, len(pf.outputs))
+	for class := 0; class < pf.stride; class++ {
+		if next := pf.delta[class]; next > 0 {
+			queue = append(queue, next)
+		} else {
+			pf.delta[class] = 0
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		row := int(state) * pf.stride
+		failRow := int(fail[state]) * pf.stride
+		for class := 0; class < pf.stride; class++ {
+			next := pf.delta[row+class]
+			if next < 0 {
+				// Failure states are shallower, so their rows are already complete
+				pf.delta[row+class] = pf.delta[failRow+class]
+				continue
+			}
+			fail[next] = pf.delta[failRow+class]
+			pf.outputs[next] = append(pf.outputs[next], pf.outputs[fail[next]]...)
+			queue = append(queue, next)
+		}
+	}
+}
+
+// scan returns which keywords occur in the (already lower-cased) input
+func (pf *keywordPrefilter) scan(input string) []bool {
+	found := make([]bool, pf.keywords)
+	state := int32(0)
+	for i := 0; i < len(input); i++ {
+		state = pf.delta[int(state)*pf.stride+int(pf.classes[input[i]])]
+		for _, id := range pf.outputs[state] {
+			found[id] = true
+		}
+	}
+	return found
+}
+
+// prefilterable reports whether input can be safely prefiltered. Case-insensitive
+// regexes fold some non-ASCII runes onto ASCII letters (e.g. U+017F onto "s"), which
+// byte-level keyword matching would miss, so such input runs every regex.
+func prefilterable(input string) bool {
+	for i := 0; i < len(input); i++ {
+		if input[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// candidate reports whether pattern i could match given the keywords found
+func (pf *keywordPrefilter) candidate(i int, found []bool) bool {
+	kws := pf.patternKeywords[i]
+	if kws == nil {
+		return true
+	}
+	for _, id := range kws {
+		if found[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredKeywords extracts lower-cased literals of which at least one must appear in
+// any match of the expression. It returns nil when no such set can be derived.
+func requiredKeywords(expr string) []string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return keywordsOf(re.Simplify())
+}
+
+func keywordsOf(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{strings.ToLower(string(re.Rune))}
+	case syntax.OpCapture, syntax.OpPlus:
+		return keywordsOf(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return keywordsOf(re.Sub[0])
+		}
+		return nil
+	case syntax.OpAlternate:
+		var all []string
+		for _, sub := range re.Sub {
+			kws := keywordsOf(sub)
+			if kws == nil {
+				return nil
+			}
+			all = append(all, kws...)
+		}
+		return all
+	case syntax.OpConcat:
+		// Any child's required set is sufficient; prefer the most selective one
+		var best []string
+		for _, sub := range re.Sub {
+			kws := keywordsOf(sub)
+			if kws != nil && shortestLen(kws) > shortestLen(best) {
+				best = kws
+			}
+		}
+		return best
+	default:
+		return nil
+	}
+}
+
+func shortestLen(kws []string) int {
+	if len(kws) == 0 {
+		return 0
+	}
+	n := len(kws[0])
+	for _, kw := range kws[1:] {
+		if len(kw) < n {
+			n = len(kw)
+		}
+	}
+	return n
+}
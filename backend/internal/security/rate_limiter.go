@@ -23,11 +23,20 @@ type RateLimitEntry struct {
 
 // RateLimitConfig defines rate limiting thresholds
 type RateLimitConfig struct {
-	RequestsPerMinute int
-	RequestsPerHour   int
-	BurstLimit        int
-	BlockDuration     time.Duration
-	CleanupInterval   time.Duration
+	RequestsPerMinute int           `json:"requests_per_minute"`
+	RequestsPerHour   int           `json:"requests_per_hour"`
+	BurstLimit        int           `json:"burst_limit"`
+	BlockDuration     time.Duration `json:"-"`
+	CleanupInterval   time.Duration `json:"-"`
+}
+
+// RateLimitResult describes a rate limit decision, used for RateLimit-* response headers
+type RateLimitResult struct {
+	Blocked   bool
+	Reason    string
+	Limit     int // requests allowed per minute
+	Remaining int // requests left in the current minute window
+	Reset     int // seconds until the minute window frees up a request
 }
 
 // NewRateLimiter creates a new rate limiter with default settings
@@ -52,6 +61,24 @@ func NewRateLimiter() *RateLimiter {
 
 // Check verifies if a request should be allowed
 func (rl *RateLimiter) Check(identifier, endpoint string) (blocked bool, reason string) {
+	return rl.CheckWithConfig(identifier, endpoint, rl.Config())
+}
+
+// Config returns the limiter's default configuration
+func (rl *RateLimiter) Config() RateLimitConfig {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.config
+}
+
+// CheckWithConfig verifies a request against specific thresholds (e.g. an organization override)
+func (rl *RateLimiter) CheckWithConfig(identifier, endpoint string, config RateLimitConfig) (blocked bool, reason string) {
+	result := rl.CheckDetailed(identifier, endpoint, config)
+	return result.Blocked, result.Reason
+}
+
+// CheckDetailed verifies a request and reports the remaining per-minute allowance
+func (rl *RateLimiter) CheckDetailed(identifier, endpoint string, config RateLimitConfig) RateLimitResult {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -66,10 +93,15 @@ func (rl *RateLimiter) Check(identifier, endpoint string) (blocked bool, reason
 		rl.entries[key] = entry
 	}
 
+	result := RateLimitResult{Limit: config.RequestsPerMinute, Reset: 60}
+
 	// Check if currently blocked
 	if entry.BlockedAt != nil {
-		if now.Before(entry.BlockedAt.Add(rl.config.BlockDuration)) {
-			return true, "IP temporarily blocked due to rate limit violations"
+		if until := entry.BlockedAt.Add(config.BlockDuration); now.Before(until) {
+			result.Blocked = true
+			result.Reason = "IP temporarily blocked due to rate limit violations"
+			result.Reset = int(until.Sub(now).Seconds()) + 1
+			return result
 		}
 		// Block expired, reset
 		entry.BlockedAt = nil
@@ -91,48 +123,62 @@ func (rl *RateLimiter) Check(identifier, endpoint string) (blocked bool, reason
 	oneSecondAgo := now.Add(-time.Second)
 
 	var countPerMinute, countPerSecond, countPerHour int
+	var oldestInMinute time.Time
 	for _, t := range entry.Requests {
 		countPerHour++
 		if t.After(oneMinuteAgo) {
+			if countPerMinute == 0 {
+				oldestInMinute = t
+			}
 			countPerMinute++
 		}
 		if t.After(oneSecondAgo) {
 			countPerSecond++
 		}
 	}
+	if countPerMinute > 0 {
+		result.Reset = int(oldestInMinute.Add(time.Minute).Sub(now).Seconds()) + 1
+	}
 
 	// Check burst limit (per second)
-	if countPerSecond >= rl.config.BurstLimit {
+	if countPerSecond >= config.BurstLimit {
 		entry.BlockCount++
 		if entry.BlockCount >= 3 {
 			blockTime := now
 			entry.BlockedAt = &blockTime
-			return true, "Burst limit exceeded, temporarily blocked"
+			result.Blocked, result.Reason = true, "Burst limit exceeded, temporarily blocked"
+			return result
 		}
-		return true, "Too many requests per second"
+		result.Blocked, result.Reason, result.Reset = true, "Too many requests per second", 1
+		return result
 	}
 
 	// Check per minute limit
-	if countPerMinute >= rl.config.RequestsPerMinute {
+	if countPerMinute >= config.RequestsPerMinute {
 		entry.BlockCount++
 		if entry.BlockCount >= 5 {
 			blockTime := now
 			entry.BlockedAt = &blockTime
-			return true, "Rate limit exceeded, temporarily blocked"
+			result.Blocked, result.Reason = true, "Rate limit exceeded, temporarily blocked"
+			return result
 		}
-		return true, "Rate limit exceeded (per minute)"
+		result.Blocked, result.Reason = true, "Rate limit exceeded (per minute)"
+		return result
 	}
 
 	// Check per hour limit
-	if countPerHour >= rl.config.RequestsPerHour {
-		return true, "Rate limit exceeded (per hour)"
+	if countPerHour >= config.RequestsPerHour {
+		result.Blocked, result.Reason = true, "Rate limit exceeded (per hour)"
+		result.Reset = int(entry.Requests[0].Add(time.Hour).Sub(now).Seconds()) + 1
+		return result
 	}
 
 	// Allow request
 	entry.Requests = append(entry.Requests, now)
 	entry.LastRequest = now
 
-	return false, ""
+	result.Remaining = config.RequestsPerMinute - countPerMinute - 1
+	return result
 }
 
 // CheckGlobal checks global rate limits (for unauthenticated endpoints)
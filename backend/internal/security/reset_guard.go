@@ -0,0 +1,87 @@
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// ResetTokenGuard counts invalid password reset tokens per IP address. Reset tokens are
+// random, so repeated misses from one address can only be guessing; once an address
+// reaches the limit its reset attempts are refused until the window has passed.
+type ResetTokenGuard struct {
+	mu          sync.Mutex
+	failures    map[string][]time.Time
+	maxFailures int
+	window      time.Duration
+}
+
+var resetTokenGuard *ResetTokenGuard
+var resetTokenGuardOnce sync.Once
+
+// GetResetTokenGuard returns the singleton ResetTokenGuard instance: 10 invalid tokens
+// per IP address per hour
+func GetResetTokenGuard() *ResetTokenGuard {
+	resetTokenGuardOnce.Do(func() {
+		resetTokenGuard = NewResetTokenGuard(10, time.Hour)
+	})
+	return resetTokenGuard
+}
+
+// NewResetTokenGuard creates a guard allowing maxFailures invalid tokens per window
+func NewResetTokenGuard(maxFailures int, window time.Duration) *ResetTokenGuard {
+	return &ResetTokenGuard{
+		failures:    make(map[string][]time.Time),
+		maxFailures: maxFailures,
+		window:      window,
+	}
+}
+
+// Blocked reports whether an IP address has used up its invalid tokens, and how long
+// until it may try again
+func (g *ResetTokenGuard) Blocked(ipAddress string) (bool, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	recent := g.recent(ipAddress, time.Now())
+	if len(recent) < g.maxFailures {
+		return false, 0
+	}
+	return true, time.Until(recent[0].Add(g.window))
+}
+
+// RecordFailure counts an invalid token from an IP address and reports whether the
+// address is now blocked
+func (g *ResetTokenGuard) RecordFailure(ipAddress string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	recent := append(g.recent(ipAddress, now), now)
+	g.failures[ipAddress] = recent
+
+	// Keep the map from growing with addresses that stopped trying
+	if len(g.failures) > 10000 {
+		for ip := range g.failures {
+			g.recent(ip, now)
+		}
+	}
+	return len(recent) >= g.maxFailures
+}
+
+// recent drops an address's failures older than the window and returns the rest,
+// oldest first. The caller holds mu.
+func (g *ResetTokenGuard) recent(ipAddress string, now time.Time) []time.Time {
+	times := g.failures[ipAddress]
+	cutoff := now.Add(-g.window)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	if i == len(times) {
+		delete(g.failures, ipAddress)
+		return nil
+	}
+	times = times[i:]
+	g.failures[ipAddress] = times
+	return times
+}
@@ -0,0 +1,39 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetTokenGuardBlocksAfterLimit(t *testing.T) {
+	guard := NewResetTokenGuard(3, time.Hour)
+
+	for i := 1; i <= 3; i++ {
+		if blocked, _ := guard.Blocked("203.0.113.7"); blocked {
+			t.Fatalf("blocked before failure %d", i)
+		}
+		if blocked := guard.RecordFailure("203.0.113.7"); blocked != (i == 3) {
+			t.Fatalf("RecordFailure #%d blocked = %v", i, blocked)
+		}
+	}
+
+	blocked, retryAfter := guard.Blocked("203.0.113.7")
+	if !blocked || retryAfter <= 0 || retryAfter > time.Hour {
+		t.Errorf("Blocked = %v, %s", blocked, retryAfter)
+	}
+	if blocked, _ := guard.Blocked("198.51.100.2"); blocked {
+		t.Error("other addresses must not be blocked")
+	}
+}
+
+func TestResetTokenGuardForgetsOldFailures(t *testing.T) {
+	guard := NewResetTokenGuard(2, time.Minute)
+	guard.failures["203.0.113.7"] = []time.Time{time.Now().Add(-2 * time.Minute)}
+
+	if guard.RecordFailure("203.0.113.7") {
+		t.Error("a failure outside the window counted towards the limit")
+	}
+	if n := len(guard.failures["203.0.113.7"]); n != 1 {
+		t.Errorf("kept %d failures, want 1", n)
+	}
+}
@@ -0,0 +1,326 @@
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported SIEM providers
+const (
+	SIEMProviderSplunk  = "splunk"  // Splunk HTTP Event Collector
+	SIEMProviderDatadog = "datadog" // Datadog Logs intake API
+	SIEMProviderHTTP    = "http"    // Generic JSON webhook
+	SIEMProviderSyslog  = "syslog"  // RFC 5424 over udp:// or tcp://
+)
+
+// SIEMConfig configures the SIEM exporter for a deployment
+type SIEMConfig struct {
+	Provider      string
+	Endpoint      string            // Full URL (splunk, datadog, http) or udp://host:port / tcp://host:port (syslog)
+	Token         string            // HEC token, Datadog API key, or bearer token
+	BatchSize     int               // Max events per request
+	FlushInterval time.Duration     // Max time an event waits before being shipped
+	MaxRetries    int               // Retries per batch before it is dropped
+	FieldMapping  map[string]string // Rename output fields, e.g. ip_address -> src_ip
+	QueueSize     int               // Events buffered in memory before new ones are dropped
+}
+
+// ParseSIEMFieldMapping parses "from=to,from2=to2" into a field mapping
+func ParseSIEMFieldMapping(value string) map[string]string {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return mapping
+}
+
+// SIEMExporter ships security audit events to an external SIEM
+type SIEMExporter struct {
+	config     SIEMConfig
+	queue      chan *SecurityEvent
+	httpClient *http.Client
+	hostname   string
+	dropped    int64
+	mu         sync.Mutex
+}
+
+var siemExporter *SIEMExporter
+var siemExporterMu sync.RWMutex
+
+// InitSIEMExporter starts the exporter; an empty provider leaves SIEM export disabled
+func InitSIEMExporter(config SIEMConfig) error {
+	if config.Provider == "" {
+		return nil
+	}
+
+	switch config.Provider {
+	case SIEMProviderSplunk, SIEMProviderDatadog, SIEMProviderHTTP:
+		if _, err := url.ParseRequestURI(config.Endpoint); err != nil {
+			return fmt.Errorf("invalid SIEM endpoint: %w", err)
+		}
+	case SIEMProviderSyslog:
+		if !strings.HasPrefix(config.Endpoint, "udp://") && !strings.HasPrefix(config.Endpoint, "tcp://") {
+			return fmt.Errorf("syslog endpoint must start with udp:// or tcp://")
+		}
+	default:
+		return fmt.Errorf("unsupported SIEM provider: %s", config.Provider)
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 10000
+	}
+
+	hostname, _ := os.Hostname()
+	exporter := &SIEMExporter{
+		config:   config,
+		queue:    make(chan *SecurityEvent, config.QueueSize),
+		hostname: hostname,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+	go exporter.run()
+
+	siemExporterMu.Lock()
+	siemExporter = exporter
+	siemExporterMu.Unlock()
+
+	log.Printf("SIEM export enabled (%s, batch %d, flush %s)", config.Provider, config.BatchSize, config.FlushInterval)
+	return nil
+}
+
+// GetSIEMExporter returns the configured exporter, or nil when SIEM export is disabled
+func GetSIEMExporter() *SIEMExporter {
+	siemExporterMu.RLock()
+	defer siemExporterMu.RUnlock()
+	return siemExporter
+}
+
+// Enqueue queues events for export without blocking the audit pipeline
+func (e *SIEMExporter) Enqueue(events []*SecurityEvent) {
+	for _, event := range events {
+		select {
+		case e.queue <- event:
+		default:
+			e.mu.Lock()
+			e.dropped++
+			if e.dropped%1000 == 1 {
+				log.Printf("SIEM export queue full, dropped %d events so far", e.dropped)
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// run batches queued events by size or interval
+func (e *SIEMExporter) run() {
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*SecurityEvent, 0, e.config.BatchSize)
+	for {
+		select {
+		case event := <-e.queue:
+			batch = append(batch, event)
+			if len(batch) >= e.config.BatchSize {
+				e.ship(batch)
+				batch = make([]*SecurityEvent, 0, e.config.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				e.ship(batch)
+				batch = make([]*SecurityEvent, 0, e.config.BatchSize)
+			}
+		}
+	}
+}
+
+// ship sends a batch with exponential backoff retries
+func (e *SIEMExporter) ship(batch []*SecurityEvent) {
+	records := make([]map[string]interface{}, len(batch))
+	for i, event := range batch {
+		records[i] = e.mapFields(event)
+	}
+
+	backoff := time.Second
+	var err error
+	for attempt := 0; attempt <= e.config.MaxRetries; attempt++ {
+		if err = e.send(records); err == nil {
+			return
+		}
+		if attempt < e.config.MaxRetries {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	log.Printf("SIEM export failed, dropped %d events: %v", len(batch), err)
+}
+
+// mapFields converts an event into the output record, applying the configured field mapping
+func (e *SIEMExporter) mapFields(event *SecurityEvent) map[string]interface{} {
+	record := map[string]interface{}{
+		"timestamp":       event.Timestamp.UTC().Format(time.RFC3339Nano),
+		"event_type":      event.EventType,
+		"severity":        event.Severity,
+		"ip_address":      event.IPAddress,
+		"user_agent":      event.UserAgent,
+		"endpoint":        event.Endpoint,
+		"method":          event.Method,
+		"response_status": event.ResponseStatus,
+		"blocked":         event.Blocked,
+		"product":         "datamigrate",
+	}
+	if event.UserID != nil {
+		record["user_id"] = *event.UserID
+	}
+	if event.OrganizationID != nil {
+		record["organization_id"] = *event.OrganizationID
+	}
+	if event.BlockReason != "" {
+		record["block_reason"] = event.BlockReason
+	}
+	if len(event.Metadata) > 0 {
+		record["metadata"] = event.Metadata
+	}
+
+	for from, to := range e.config.FieldMapping {
+		if value, ok := record[from]; ok {
+			delete(record, from)
+			record[to] = value
+		}
+	}
+	return record
+}
+
+// send delivers records in the provider's wire format
+func (e *SIEMExporter) send(records []map[string]interface{}) error {
+	switch e.config.Provider {
+	case SIEMProviderSplunk:
+		// HEC accepts concatenated event objects in a single request
+		var body bytes.Buffer
+		for _, record := range records {
+			payload, _ := json.Marshal(map[string]interface{}{
+				"host":       e.hostname,
+				"source":     "datamigrate",
+				"sourcetype": "datamigrate:security",
+				"event":      record,
+			})
+			body.Write(payload)
+			body.WriteByte('\n')
+		}
+		return e.post(body.Bytes(), map[string]string{"Authorization": "Splunk " + e.config.Token})
+
+	case SIEMProviderDatadog:
+		entries := make([]map[string]interface{}, len(records))
+		for i, record := range records {
+			entry := map[string]interface{}{
+				"ddsource": "datamigrate",
+				"service":  "datamigrate-api",
+				"hostname": e.hostname,
+				"ddtags":   "source:guardian",
+			}
+			for k, v := range record {
+				entry[k] = v
+			}
+			entries[i] = entry
+		}
+		body, _ := json.Marshal(entries)
+		return e.post(body, map[string]string{"DD-API-KEY": e.config.Token})
+
+	case SIEMProviderHTTP:
+		body, _ := json.Marshal(records)
+		headers := map[string]string{}
+		if e.config.Token != "" {
+			headers["Authorization"] = "Bearer " + e.config.Token
+		}
+		return e.post(body, headers)
+
+	case SIEMProviderSyslog:
+		return e.sendSyslog(records)
+	}
+	return fmt.Errorf("unsupported SIEM provider: %s", e.config.Provider)
+}
+
+func (e *SIEMExporter) post(body []byte, headers map[string]string) error {
+	req, err := http.NewRequest("POST", e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("SIEM endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendSyslog writes one RFC 5424 message per record with a JSON payload
+func (e *SIEMExporter) sendSyslog(records []map[string]interface{}) error {
+	network, address, _ := strings.Cut(e.config.Endpoint, "://")
+	conn, err := net.DialTimeout(network, address, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+
+	for _, record := range records {
+		payload, _ := json.Marshal(record)
+		// Facility 10 (security/authorization); severity mapped from the event
+		priority := 10*8 + syslogSeverity(fmt.Sprint(record["severity"]))
+		message := fmt.Sprintf("<%d>1 %s %s datamigrate - guardian - %s",
+			priority, time.Now().UTC().Format(time.RFC3339), e.hostname, payload)
+		if network == "tcp" {
+			// Octet counting framing (RFC 6587)
+			message = fmt.Sprintf("%d %s", len(message), message)
+		}
+		if _, err := conn.Write([]byte(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func syslogSeverity(severity string) int {
+	switch severity {
+	case "critical":
+		return 2
+	case "error", "high":
+		return 3
+	case "warning", "medium":
+		return 4
+	case "info", "low":
+		return 6
+	}
+	return 5
+}
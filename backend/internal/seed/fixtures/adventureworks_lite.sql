@@ -0,0 +1,186 @@
+-- AdventureWorks Lite: a small AdventureWorks-style SQL Server database used by the
+-- demo seed data. The demo "AdventureWorks (demo)" connection points at a database
+-- created from this script, e.g.:
+--
+--   go run ./cmd/seed fixture > adventureworks_lite.sql
+--   sqlcmd -S localhost,1433 -U sa -P "$MSSQL_SA_PASSWORD" -C -i adventureworks_lite.sql
+
+IF DB_ID('AdventureWorksLite') IS NULL CREATE DATABASE AdventureWorksLite;
+GO
+USE AdventureWorksLite;
+GO
+
+IF SCHEMA_ID('Person') IS NULL EXEC('CREATE SCHEMA Person');
+IF SCHEMA_ID('Production') IS NULL EXEC('CREATE SCHEMA Production');
+IF SCHEMA_ID('Sales') IS NULL EXEC('CREATE SCHEMA Sales');
+GO
+
+DROP VIEW IF EXISTS Sales.vSalesSummary;
+DROP TABLE IF EXISTS Sales.SalesOrderDetail;
+DROP TABLE IF EXISTS Sales.SalesOrderHeader;
+DROP TABLE IF EXISTS Sales.Customer;
+DROP TABLE IF EXISTS Sales.SalesTerritory;
+DROP TABLE IF EXISTS Production.Product;
+DROP TABLE IF EXISTS Production.ProductCategory;
+DROP TABLE IF EXISTS Person.Address;
+DROP TABLE IF EXISTS Person.Person;
+GO
+
+CREATE TABLE Person.Person (
+    BusinessEntityID INT IDENTITY(1,1) PRIMARY KEY,
+    PersonType NCHAR(2) NOT NULL,
+    FirstName NVARCHAR(50) NOT NULL,
+    LastName NVARCHAR(50) NOT NULL,
+    EmailAddress NVARCHAR(100) NULL,
+    Phone NVARCHAR(25) NULL,
+    ModifiedDate DATETIME NOT NULL DEFAULT GETDATE()
+);
+
+CREATE TABLE Person.Address (
+    AddressID INT IDENTITY(1,1) PRIMARY KEY,
+    BusinessEntityID INT NOT NULL REFERENCES Person.Person(BusinessEntityID),
+    AddressLine1 NVARCHAR(60) NOT NULL,
+    City NVARCHAR(30) NOT NULL,
+    StateProvince NVARCHAR(50) NOT NULL,
+    PostalCode NVARCHAR(15) NOT NULL,
+    CountryRegion NVARCHAR(50) NOT NULL,
+    ModifiedDate DATETIME NOT NULL DEFAULT GETDATE()
+);
+
+CREATE TABLE Production.ProductCategory (
+    ProductCategoryID INT IDENTITY(1,1) PRIMARY KEY,
+    Name NVARCHAR(50) NOT NULL UNIQUE,
+    ModifiedDate DATETIME NOT NULL DEFAULT GETDATE()
+);
+
+CREATE TABLE Production.Product (
+    ProductID INT IDENTITY(1,1) PRIMARY KEY,
+    Name NVARCHAR(50) NOT NULL,
+    ProductNumber NVARCHAR(25) NOT NULL UNIQUE,
+    ProductCategoryID INT NOT NULL REFERENCES Production.ProductCategory(ProductCategoryID),
+    Color NVARCHAR(15) NULL,
+    StandardCost MONEY NOT NULL,
+    ListPrice MONEY NOT NULL,
+    SellStartDate DATETIME NOT NULL,
+    SellEndDate DATETIME NULL,
+    ModifiedDate DATETIME NOT NULL DEFAULT GETDATE()
+);
+
+CREATE TABLE Sales.SalesTerritory (
+    TerritoryID INT IDENTITY(1,1) PRIMARY KEY,
+    Name NVARCHAR(50) NOT NULL,
+    CountryRegionCode NVARCHAR(3) NOT NULL,
+    [Group] NVARCHAR(50) NOT NULL,
+    SalesYTD MONEY NOT NULL DEFAULT 0
+);
+
+CREATE TABLE Sales.Customer (
+    CustomerID INT IDENTITY(1,1) PRIMARY KEY,
+    PersonID INT NULL REFERENCES Person.Person(BusinessEntityID),
+    TerritoryID INT NULL REFERENCES Sales.SalesTerritory(TerritoryID),
+    AccountNumber AS ('AW' + RIGHT('00000000' + CAST(CustomerID AS VARCHAR(8)), 8)),
+    ModifiedDate DATETIME NOT NULL DEFAULT GETDATE()
+);
+
+CREATE TABLE Sales.SalesOrderHeader (
+    SalesOrderID INT IDENTITY(43659,1) PRIMARY KEY,
+    OrderDate DATETIME NOT NULL,
+    DueDate DATETIME NOT NULL,
+    ShipDate DATETIME NULL,
+    Status TINYINT NOT NULL DEFAULT 1,
+    CustomerID INT NOT NULL REFERENCES Sales.Customer(CustomerID),
+    TerritoryID INT NULL REFERENCES Sales.SalesTerritory(TerritoryID),
+    SubTotal MONEY NOT NULL DEFAULT 0,
+    TaxAmt MONEY NOT NULL DEFAULT 0,
+    Freight MONEY NOT NULL DEFAULT 0,
+    TotalDue AS (SubTotal + TaxAmt + Freight),
+    ModifiedDate DATETIME NOT NULL DEFAULT GETDATE()
+);
+
+CREATE TABLE Sales.SalesOrderDetail (
+    SalesOrderID INT NOT NULL REFERENCES Sales.SalesOrderHeader(SalesOrderID),
+    SalesOrderDetailID INT IDENTITY(1,1) NOT NULL,
+    OrderQty SMALLINT NOT NULL,
+    ProductID INT NOT NULL REFERENCES Production.Product(ProductID),
+    UnitPrice MONEY NOT NULL,
+    UnitPriceDiscount MONEY NOT NULL DEFAULT 0,
+    LineTotal AS (UnitPrice * (1.0 - UnitPriceDiscount) * OrderQty),
+    ModifiedDate DATETIME NOT NULL DEFAULT GETDATE(),
+    PRIMARY KEY (SalesOrderID, SalesOrderDetailID)
+);
+GO
+
+INSERT INTO Person.Person (PersonType, FirstName, LastName, EmailAddress, Phone) VALUES
+    ('IN', N'Ken', N'Sánchez', N'ken0@adventure-works.com', N'697-555-0142'),
+    ('IN', N'Terri', N'Duffy', N'terri0@adventure-works.com', N'819-555-0175'),
+    ('IN', N'Roberto', N'Tamburello', N'roberto0@adventure-works.com', N'212-555-0187'),
+    ('IN', N'Rob', N'Walters', N'rob0@adventure-works.com', N'612-555-0100'),
+    ('SC', N'Gail', N'Erickson', N'gail0@adventure-works.com', N'849-555-0139'),
+    ('SC', N'Jossef', N'Goldberg', N'jossef0@adventure-works.com', N'122-555-0189'),
+    ('IN', N'Dylan', N'Miller', N'dylan0@adventure-works.com', N'181-555-0156'),
+    ('IN', N'Diane', N'Margheim', N'diane1@adventure-works.com', N'815-555-0138');
+
+INSERT INTO Person.Address (BusinessEntityID, AddressLine1, City, StateProvince, PostalCode, CountryRegion) VALUES
+    (1, N'4350 Minute Dr.', N'Newport Hills', N'Washington', N'98006', N'United States'),
+    (2, N'7559 Worth Ct.', N'Renton', N'Washington', N'98055', N'United States'),
+    (3, N'2137 Birchwood Dr', N'Redmond', N'Washington', N'98052', N'United States'),
+    (4, N'5678 Lakeview Blvd.', N'Minneapolis', N'Minnesota', N'55402', N'United States'),
+    (5, N'9833 Mt. Dias Blv.', N'Bothell', N'Washington', N'98011', N'United States'),
+    (6, N'1 Smiling Tree Court', N'Calgary', N'Alberta', N'T2P 2G8', N'Canada'),
+    (7, N'7 Garden Lane', N'London', N'England', N'SW8 1XD', N'United Kingdom'),
+    (8, N'475 Santa Maria', N'Sydney', N'New South Wales', N'2000', N'Australia');
+
+INSERT INTO Production.ProductCategory (Name) VALUES
+    (N'Bikes'), (N'Components'), (N'Clothing'), (N'Accessories');
+
+INSERT INTO Production.Product (Name, ProductNumber, ProductCategoryID, Color, StandardCost, ListPrice, SellStartDate, SellEndDate) VALUES
+    (N'Road-150 Red, 62', N'BK-R93R-62', 1, N'Red', 2171.29, 3578.27, '2011-05-31', NULL),
+    (N'Mountain-200 Black, 38', N'BK-M68B-38', 1, N'Black', 1251.98, 2294.99, '2012-05-30', NULL),
+    (N'Touring-1000 Blue, 46', N'BK-T79U-46', 1, N'Blue', 1481.94, 2384.07, '2013-05-30', NULL),
+    (N'HL Road Frame - Black, 58', N'FR-R92B-58', 2, N'Black', 868.63, 1431.50, '2008-04-30', '2012-05-29'),
+    (N'ML Crankset', N'CS-6583', 2, NULL, 113.88, 256.49, '2012-05-30', NULL),
+    (N'Long-Sleeve Logo Jersey, L', N'LJ-0192-L', 3, N'Multi', 38.49, 49.99, '2011-05-31', NULL),
+    (N'Classic Vest, M', N'VE-C304-M', 3, N'Blue', 23.75, 63.50, '2013-05-30', NULL),
+    (N'Sport-100 Helmet, Red', N'HL-U509-R', 4, N'Red', 13.09, 34.99, '2011-05-31', NULL),
+    (N'Water Bottle - 30 oz.', N'WB-H098', 4, NULL, 1.87, 4.99, '2013-05-30', NULL),
+    (N'Patch Kit/8 Patches', N'PK-7098', 4, NULL, 0.86, 2.29, '2011-05-31', NULL);
+
+INSERT INTO Sales.SalesTerritory (Name, CountryRegionCode, [Group], SalesYTD) VALUES
+    (N'Northwest', N'US', N'North America', 7887186.79),
+    (N'Central', N'US', N'North America', 3072175.12),
+    (N'Canada', N'CA', N'North America', 6771829.14),
+    (N'United Kingdom', N'GB', N'Europe', 5012905.37),
+    (N'Australia', N'AU', N'Pacific', 5977814.92);
+
+INSERT INTO Sales.Customer (PersonID, TerritoryID) VALUES
+    (1, 1), (2, 1), (3, 1), (4, 2), (5, 1), (6, 3), (7, 4), (8, 5);
+
+INSERT INTO Sales.SalesOrderHeader (OrderDate, DueDate, ShipDate, Status, CustomerID, TerritoryID, SubTotal, TaxAmt, Freight) VALUES
+    ('2024-01-05', '2024-01-17', '2024-01-12', 5, 1, 1, 3578.27, 286.26, 89.46),
+    ('2024-01-09', '2024-01-21', '2024-01-16', 5, 6, 3, 4589.98, 367.20, 114.75),
+    ('2024-02-14', '2024-02-26', '2024-02-21', 5, 7, 4, 112.99, 9.04, 2.82),
+    ('2024-03-02', '2024-03-14', NULL, 1, 8, 5, 2384.07, 190.73, 59.60),
+    ('2024-03-18', '2024-03-30', '2024-03-25', 5, 4, 2, 290.47, 23.24, 7.26),
+    ('2024-04-01', '2024-04-13', NULL, 4, 2, 1, 1431.50, 114.52, 35.79);
+
+INSERT INTO Sales.SalesOrderDetail (SalesOrderID, OrderQty, ProductID, UnitPrice, UnitPriceDiscount) VALUES
+    (43659, 1, 1, 3578.27, 0),
+    (43660, 2, 2, 2294.99, 0),
+    (43661, 1, 6, 49.99, 0),
+    (43661, 1, 7, 63.50, 0.02),
+    (43662, 1, 3, 2384.07, 0),
+    (43663, 1, 5, 256.49, 0),
+    (43663, 2, 8, 34.99, 0),
+    (43663, 3, 9, 4.99, 0.10),
+    (43664, 1, 4, 1431.50, 0);
+GO
+
+CREATE VIEW Sales.vSalesSummary AS
+SELECT t.Name AS Territory,
+       COUNT(DISTINCT h.SalesOrderID) AS Orders,
+       SUM(d.LineTotal) AS Revenue
+FROM Sales.SalesOrderHeader h
+JOIN Sales.SalesOrderDetail d ON d.SalesOrderID = h.SalesOrderID
+JOIN Sales.SalesTerritory t ON t.TerritoryID = h.TerritoryID
+GROUP BY t.Name;
+GO
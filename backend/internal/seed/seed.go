@@ -0,0 +1,292 @@
+// Package seed creates demo data for trials and end-to-end tests: a demo
+// organization with users, connections to the bundled AdventureWorks Lite
+// fixture, and migrations in each lifecycle state.
+package seed
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/crypto"
+	"github.com/datamigrate-ai/backend/internal/db"
+	"github.com/datamigrate-ai/backend/internal/security"
+	"github.com/jmoiron/sqlx"
+)
+
+// FixtureSQL is the T-SQL script that builds the AdventureWorksLite source database
+//
+//go:embed fixtures/adventureworks_lite.sql
+var FixtureSQL string
+
+// DemoOrganizationSlug identifies the seeded organization
+const DemoOrganizationSlug = "demo-company"
+
+// DefaultDemoPassword is used for the demo users unless Options.Password is set
+const DefaultDemoPassword = "DemoPassword123!"
+
+// Options controls how demo data is created
+type Options struct {
+	Password    string // Password for every demo user
+	FixtureHost string // Host of the SQL Server running the fixture database
+	FixturePort int
+	FixtureUser string
+	FixturePass string
+	Reset       bool // Delete and recreate existing demo data
+}
+
+// DefaultOptions matches the SQL Server started by the "demo" docker-compose profile
+func DefaultOptions() Options {
+	return Options{
+		Password:    DefaultDemoPassword,
+		FixtureHost: "mssql-demo",
+		FixturePort: 1433,
+		FixtureUser: "sa",
+		FixturePass: "Demo_Passw0rd",
+	}
+}
+
+// DemoUser is a seeded login
+type DemoUser struct {
+	Email     string
+	FirstName string
+	LastName  string
+	JobTitle  string
+	Role      string
+}
+
+// DemoUsers are the accounts created in the demo organization
+var DemoUsers = []DemoUser{
+	{Email: "admin@demo.datamigrate.ai", FirstName: "Alex", LastName: "Admin", JobTitle: "Data Platform Lead", Role: "admin"},
+	{Email: "engineer@demo.datamigrate.ai", FirstName: "Erin", LastName: "Engineer", JobTitle: "Data Engineer", Role: "member"},
+	{Email: "analyst@demo.datamigrate.ai", FirstName: "Sam", LastName: "Analyst", JobTitle: "Analytics Engineer", Role: "member"},
+}
+
+// fixtureTables are the tables in the AdventureWorksLite fixture
+var fixtureTables = []string{
+	"Person.Person", "Person.Address",
+	"Production.ProductCategory", "Production.Product",
+	"Sales.SalesTerritory", "Sales.Customer", "Sales.SalesOrderHeader", "Sales.SalesOrderDetail",
+}
+
+// demoMigration describes a seeded migration
+type demoMigration struct {
+	name         string
+	status       string
+	progress     int
+	tables       []string
+	views        int
+	foreignKeys  int
+	models       int
+	errorMessage string
+	age          time.Duration
+	completedIn  time.Duration
+}
+
+var demoMigrations = []demoMigration{
+	{
+		name: "AdventureWorks full migration", status: "completed", progress: 100,
+		tables: fixtureTables, views: 1, foreignKeys: 8, models: 17,
+		age: 72 * time.Hour, completedIn: 14 * time.Minute,
+	},
+	{
+		name: "Sales schema refresh", status: "running", progress: 45,
+		tables: fixtureTables[4:], foreignKeys: 5,
+		age: 20 * time.Minute,
+	},
+	{
+		name: "Product catalog", status: "failed", progress: 30,
+		tables: fixtureTables[2:4], foreignKeys: 1,
+		errorMessage: "Metadata extraction failed: login timeout expired for server mssql-demo,1433",
+		age:          26 * time.Hour,
+	},
+	{
+		name: "Person and address", status: "pending", progress: 0,
+		tables: fixtureTables[:2], foreignKeys: 1,
+		age: 5 * time.Minute,
+	},
+}
+
+// Summary reports what Seed created
+type Summary struct {
+	OrganizationID int64
+	Created        bool // false when demo data already existed
+	Users          []string
+	Connections    int
+	Migrations     int
+}
+
+// Seed creates the demo organization and its data. It is idempotent: when the demo
+// organization already exists nothing is changed unless opts.Reset is set.
+func Seed(opts Options) (*Summary, error) {
+	if opts.Password == "" {
+		opts.Password = DefaultDemoPassword
+	}
+
+	var existingID int64
+	err := db.DB.Get(&existingID, "SELECT id FROM organizations WHERE slug = $1", DemoOrganizationSlug)
+	if err == nil {
+		if !opts.Reset {
+			return &Summary{OrganizationID: existingID}, nil
+		}
+		if err := Reset(); err != nil {
+			return nil, err
+		}
+	}
+
+	hashedPassword, err := security.HashPassword(opts.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash demo password: %w", err)
+	}
+
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	summary := &Summary{Created: true}
+	err = tx.QueryRow(`
+		INSERT INTO organizations (name, slug, plan, max_users, max_migrations)
+		VALUES ('Demo Company', $1, 'professional', 25, 100)
+		RETURNING id
+	`, DemoOrganizationSlug).Scan(&summary.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create demo organization: %w", err)
+	}
+
+	var ownerID int64
+	for i, user := range DemoUsers {
+		var userID int64
+		err := tx.QueryRow(`
+			INSERT INTO users (email, password, first_name, last_name, job_title, organization_id, role, is_admin, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, false, true)
+			RETURNING id
+		`, user.Email, hashedPassword, user.FirstName, user.LastName, user.JobTitle, summary.OrganizationID, user.Role).Scan(&userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create demo user %s: %w", user.Email, err)
+		}
+		_, err = tx.Exec(`
+			INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)
+		`, summary.OrganizationID, userID, user.Role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add demo user %s to the organization: %w", user.Email, err)
+		}
+		if i == 0 {
+			ownerID = userID
+		}
+		summary.Users = append(summary.Users, user.Email)
+	}
+
+	if _, err := tx.Exec("UPDATE organizations SET owner_id = $1 WHERE id = $2", ownerID, summary.OrganizationID); err != nil {
+		return nil, fmt.Errorf("failed to set demo organization owner: %w", err)
+	}
+
+	if err := seedConnections(tx, opts, ownerID, summary); err != nil {
+		return nil, err
+	}
+	if err := seedMigrations(tx, ownerID, summary); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func seedConnections(tx *sqlx.Tx, opts Options, userID int64, summary *Summary) error {
+	connections := []struct {
+		name, dbType, host, database, username, password string
+		port                                             int
+		isSource                                         bool
+	}{
+		{"AdventureWorks (demo)", "mssql", opts.FixtureHost, "AdventureWorksLite", opts.FixtureUser, opts.FixturePass, opts.FixturePort, true},
+		{"Analytics warehouse (demo)", "postgresql", "postgres", "analytics", "datamigrate", "datamigrate123", 5432, false},
+	}
+
+	for _, conn := range connections {
+		_, err := tx.Exec(`
+			INSERT INTO database_connections (name, db_type, host, port, database_name, username, password, use_windows_auth, is_source, user_id, organization_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, false, $8, $9, $10)
+		`, conn.name, conn.dbType, conn.host, conn.port, conn.database, conn.username, encryptPassword(conn.password),
+			conn.isSource, userID, summary.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to create demo connection %s: %w", conn.name, err)
+		}
+		summary.Connections++
+	}
+	return nil
+}
+
+func seedMigrations(tx *sqlx.Tx, userID int64, summary *Summary) error {
+	now := time.Now()
+	for _, m := range demoMigrations {
+		config, _ := json.Marshal(map[string]interface{}{
+			"tables":        m.tables,
+			"include_views": m.views > 0,
+		})
+
+		createdAt := now.Add(-m.age)
+		var completedAt *time.Time
+		if m.completedIn > 0 {
+			t := createdAt.Add(m.completedIn)
+			completedAt = &t
+		}
+		var errorMessage *string
+		if m.errorMessage != "" {
+			errorMessage = &m.errorMessage
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO migrations (name, status, progress, connection_id, source_database, target_project, tables_count, views_count,
+			                        foreign_keys_count, models_generated, user_id, organization_id, error, config,
+			                        created_at, completed_at, updated_at)
+			SELECT $1, $2, $3, id, name, 'adventureworks_dbt', $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $12
+			FROM database_connections WHERE user_id = $8 AND is_source ORDER BY id LIMIT 1
+		`, m.name, m.status, m.progress, len(m.tables), m.views, m.foreignKeys, m.models, userID,
+			summary.OrganizationID, errorMessage, string(config), createdAt, completedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create demo migration %s: %w", m.name, err)
+		}
+		summary.Migrations++
+	}
+	return nil
+}
+
+// Reset deletes the demo organization along with its users and their data
+func Reset() error {
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Migrations and connections cascade from their owning users
+	statements := []string{
+		"DELETE FROM users WHERE organization_id = (SELECT id FROM organizations WHERE slug = $1)",
+		"DELETE FROM organizations WHERE slug = $1",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, DemoOrganizationSlug); err != nil {
+			return fmt.Errorf("failed to remove demo data: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// encryptPassword encrypts connection passwords the same way the API does when a key is configured
+func encryptPassword(password string) string {
+	encService := crypto.GetEncryptionService()
+	if !encService.IsKeySet() {
+		return password
+	}
+	encrypted, err := encService.Encrypt(password)
+	if err != nil {
+		log.Printf("Warning: Failed to encrypt demo connection password: %v", err)
+		return password
+	}
+	return encrypted
+}
@@ -0,0 +1,334 @@
+// Package settings holds server settings that admins can change at runtime. Defaults
+// come from the environment; overrides are stored in server_settings and reloaded
+// periodically so every instance picks them up without a redeploy.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datamigrate-ai/backend/internal/db"
+)
+
+// Setting keys
+const (
+	KeyAllowedOrigins        = "allowed_origins"
+	KeyAllowedOriginSuffixes = "allowed_origin_suffixes"
+	KeyCORSMaxAge            = "cors_max_age_seconds"
+	KeyAIMigrationSlots      = "ai_migration_slots"
+)
+
+// refreshInterval controls how often overrides are reloaded, so a change made through
+// one instance reaches the others
+const refreshInterval = time.Minute
+
+// ErrUnknownSetting is returned for keys that aren't runtime-tunable
+var ErrUnknownSetting = errors.New("unknown setting")
+
+// Values is a snapshot of the effective settings
+type Values struct {
+	AllowedOrigins        []string // Exact origins allowed by CORS
+	AllowedOriginSuffixes []string // Origins ending in one of these are also allowed, e.g. ".railway.app"
+	CORSMaxAge            int      // Seconds browsers may cache preflight responses
+	AIMigrationSlots      int      // Migrations each AI service instance runs at once; 0 is unlimited
+}
+
+// Setting describes one setting for the admin API
+type Setting struct {
+	Key         string      `json:"key"`
+	Description string      `json:"description"`
+	Value       interface{} `json:"value"`
+	Default     interface{} `json:"default"`
+	Overridden  bool        `json:"overridden"`
+	UpdatedBy   *int64      `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time  `json:"updated_at,omitempty"`
+}
+
+// definition parses and validates a stored value onto Values, and reads it back
+type definition struct {
+	key         string
+	description string
+	apply       func(v *Values, raw json.RawMessage) error
+	get         func(v Values) interface{}
+}
+
+var (
+	hostSuffixRegex = regexp.MustCompile(`^\.[a-z0-9]([a-z0-9\-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9\-]*[a-z0-9])?)+$`)
+
+	definitions = []definition{
+		{
+			key:         KeyAllowedOrigins,
+			description: "Origins allowed to call the API from a browser (replaces ALLOWED_ORIGINS)",
+			apply: func(v *Values, raw json.RawMessage) error {
+				var origins []string
+				if err := json.Unmarshal(raw, &origins); err != nil {
+					return errors.New("must be a list of origins")
+				}
+				for _, origin := range origins {
+					if err := ValidateOrigin(origin); err != nil {
+						return err
+					}
+				}
+				v.AllowedOrigins = origins
+				return nil
+			},
+			get: func(v Values) interface{} { return v.AllowedOrigins },
+		},
+		{
+			key:         KeyAllowedOriginSuffixes,
+			description: "Host suffixes whose origins are allowed, e.g. .railway.app for preview deployments",
+			apply: func(v *Values, raw json.RawMessage) error {
+				var suffixes []string
+				if err := json.Unmarshal(raw, &suffixes); err != nil {
+					return errors.New("must be a list of host suffixes")
+				}
+				for _, suffix := range suffixes {
+					if !hostSuffixRegex.MatchString(suffix) {
+						return fmt.Errorf("%q is not a host suffix like .example.com", suffix)
+					}
+				}
+				v.AllowedOriginSuffixes = suffixes
+				return nil
+			},
+			get: func(v Values) interface{} { return v.AllowedOriginSuffixes },
+		},
+		{
+			key:         KeyCORSMaxAge,
+			description: "Seconds browsers may cache CORS preflight responses",
+			apply: func(v *Values, raw json.RawMessage) error {
+				var seconds int
+				if err := json.Unmarshal(raw, &seconds); err != nil || seconds < 0 || seconds > 86400 {
+					return errors.New("must be a number of seconds between 0 and 86400")
+				}
+				v.CORSMaxAge = seconds
+				return nil
+			},
+			get: func(v Values) interface{} { return v.CORSMaxAge },
+		},
+		{
+			key:         KeyAIMigrationSlots,
+			description: "Migrations each AI service instance runs at once (0 for no limit); the rest queue by plan priority",
+			apply: func(v *Values, raw json.RawMessage) error {
+				var slots int
+				if err := json.Unmarshal(raw, &slots); err != nil || slots < 0 || slots > 1000 {
+					return errors.New("must be a number of migrations between 0 and 1000")
+				}
+				v.AIMigrationSlots = slots
+				return nil
+			},
+			get: func(v Values) interface{} { return v.AIMigrationSlots },
+		},
+	}
+)
+
+// ValidateOrigin checks that an entry is a scheme://host[:port] origin that can match
+// a browser's Origin header
+func ValidateOrigin(origin string) error {
+	if origin == "*" {
+		return errors.New("\"*\" is not supported (credentials are allowed); list origins explicitly")
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("%q is not an origin (expected scheme://host[:port] without a trailing slash)", origin)
+	}
+	return nil
+}
+
+func lookup(key string) (definition, bool) {
+	for _, d := range definitions {
+		if d.key == key {
+			return d, true
+		}
+	}
+	return definition{}, false
+}
+
+type override struct {
+	raw       json.RawMessage
+	updatedBy *int64
+	updatedAt time.Time
+}
+
+type store struct {
+	mu        sync.RWMutex
+	defaults  Values
+	current   Values
+	overrides map[string]override
+}
+
+var (
+	settings     = &store{}
+	settingsOnce sync.Once
+)
+
+// Init sets the defaults, loads overrides and starts the refresh loop. Later calls are no-ops.
+func Init(defaults Values) {
+	settingsOnce.Do(func() {
+		settings.mu.Lock()
+		settings.defaults = defaults
+		settings.current = defaults
+		settings.mu.Unlock()
+
+		if err := Reload(); err != nil {
+			log.Printf("Failed to load server settings, using defaults: %v", err)
+		}
+		go refreshLoop()
+	})
+}
+
+// Current returns the effective settings
+func Current() Values {
+	settings.mu.RLock()
+	defer settings.mu.RUnlock()
+	return settings.current
+}
+
+// Reload re-reads overrides from the database. Invalid stored values are logged and
+// their defaults kept.
+func Reload() error {
+	rows, err := db.DB.Query("SELECT key, value, updated_by, updated_at FROM server_settings")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	overrides := map[string]override{}
+	for rows.Next() {
+		var key string
+		var o override
+		if err := rows.Scan(&key, &o.raw, &o.updatedBy, &o.updatedAt); err != nil {
+			return err
+		}
+		overrides[key] = o
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	settings.mu.Lock()
+	defer settings.mu.Unlock()
+	current := settings.defaults
+	for key, o := range overrides {
+		d, ok := lookup(key)
+		if !ok {
+			log.Printf("Ignoring unknown server setting %q", key)
+			continue
+		}
+		if err := d.apply(&current, o.raw); err != nil {
+			log.Printf("Ignoring invalid server setting %q: %v", key, err)
+		}
+	}
+	settings.current = current
+	settings.overrides = overrides
+	return nil
+}
+
+func refreshLoop() {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := Reload(); err != nil {
+			log.Printf("Failed to reload server settings: %v", err)
+		}
+	}
+}
+
+// List returns every setting with its effective and default values
+func List() []Setting {
+	settings.mu.RLock()
+	defer settings.mu.RUnlock()
+
+	list := make([]Setting, 0, len(definitions))
+	for _, d := range definitions {
+		s := Setting{
+			Key:         d.key,
+			Description: d.description,
+			Value:       d.get(settings.current),
+			Default:     d.get(settings.defaults),
+		}
+		if o, ok := settings.overrides[d.key]; ok {
+			s.Overridden = true
+			s.UpdatedBy = o.updatedBy
+			updatedAt := o.updatedAt
+			s.UpdatedAt = &updatedAt
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// ValidationError is returned by Set when the value is invalid for the setting
+type ValidationError struct {
+	Key string
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Key + " " + e.Err.Error()
+}
+
+// Set validates and stores an override, then reloads
+func Set(key string, raw json.RawMessage, userID int64) error {
+	d, ok := lookup(key)
+	if !ok {
+		return ErrUnknownSetting
+	}
+	var scratch Values
+	if err := d.apply(&scratch, raw); err != nil {
+		return &ValidationError{Key: key, Err: err}
+	}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO server_settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, key, string(raw), userID)
+	if err != nil {
+		return err
+	}
+	return Reload()
+}
+
+// Reset removes an override so the environment default applies again
+func Reset(key string) error {
+	if _, ok := lookup(key); !ok {
+		return ErrUnknownSetting
+	}
+	if _, err := db.DB.Exec("DELETE FROM server_settings WHERE key = $1", key); err != nil {
+		return err
+	}
+	return Reload()
+}
+
+// OriginAllowed reports whether CORS should allow a request from origin
+func (v Values) OriginAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, o := range v.AllowedOrigins {
+		if o == origin {
+			return true
+		}
+	}
+	// Suffixes are matched against the host so a port or scheme can't sneak past
+	if u, err := url.Parse(origin); err == nil && u.Hostname() != "" {
+		host := strings.ToLower(u.Hostname())
+		for _, suffix := range v.AllowedOriginSuffixes {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package settings
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	v := Values{
+		AllowedOrigins:        []string{"https://app.datamigrate.ai"},
+		AllowedOriginSuffixes: []string{".railway.app"},
+	}
+	cases := map[string]bool{
+		"https://app.datamigrate.ai":          true,
+		"https://pr-12.up.railway.app":        true,
+		"https://pr-12.up.railway.app:8443":   true,
+		"https://evil.com/.railway.app":       false,
+		"https://railway.app.evil.com":        false,
+		"https://app.datamigrate.ai.evil.com": false,
+		"":                                    false,
+	}
+	for origin, want := range cases {
+		if got := v.OriginAllowed(origin); got != want {
+			t.Errorf("OriginAllowed(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
+
+func TestDefinitionsValidate(t *testing.T) {
+	cases := []struct {
+		key   string
+		value string
+		valid bool
+	}{
+		{KeyAllowedOrigins, `["https://app.example.com", "http://localhost:5173"]`, true},
+		{KeyAllowedOrigins, `["https://app.example.com/"]`, false},
+		{KeyAllowedOrigins, `["*"]`, false},
+		{KeyAllowedOrigins, `"https://app.example.com"`, false},
+		{KeyAllowedOriginSuffixes, `[".vercel.app"]`, true},
+		{KeyAllowedOriginSuffixes, `["vercel.app"]`, false},
+		{KeyCORSMaxAge, `600`, true},
+		{KeyCORSMaxAge, `-1`, false},
+		{KeyAIMigrationSlots, `0`, true},
+		{KeyAIMigrationSlots, `12`, true},
+		{KeyAIMigrationSlots, `-3`, false},
+	}
+	for _, tc := range cases {
+		d, ok := lookup(tc.key)
+		if !ok {
+			t.Fatalf("no definition for %s", tc.key)
+		}
+		var v Values
+		err := d.apply(&v, json.RawMessage(tc.value))
+		if (err == nil) != tc.valid {
+			t.Errorf("%s = %s: err = %v, want valid %v", tc.key, tc.value, err, tc.valid)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package sqllint checks SQL and dbt models against layout, capitalisation and dialect
+// rules, in the spirit of sqlfluff. Rule codes follow sqlfluff where a rule has an
+// equivalent there.
+package sqllint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Severities of violations. Errors are SQL the target won't run; warnings are style.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// DefaultMaxLineLength is the line length limit when the style doesn't set one
+const DefaultMaxLineLength = 120
+
+// Rules lists every rule by code, with its name
+var Rules = map[string]string{
+	"LT01": "layout.trailing_whitespace",
+	"LT02": "layout.indent",
+	"LT05": "layout.long_lines",
+	"CP01": "capitalisation.keywords",
+	"AM04": "ambiguous.column_count",
+	"AL01": "aliasing.table",
+	"CV06": "convention.terminator",
+	"RF01": "references.hardcoded",
+	"DL01": "dialect.tsql_only",
+	"DL02": "dialect.not_tsql",
+}
+
+// Options choose what a file is linted against
+type Options struct {
+	Dialect string // Target warehouse type, e.g. snowflake
+	TSQL    bool   // The target runs T-SQL (SQL Server, Fabric, Synapse)
+	DBT     bool   // The SQL is a dbt model, compiled by dbt before it runs
+	Style   Style
+}
+
+// Style is an organization's SQL style
+type Style struct {
+	KeywordCase   string   // upper or lower; keywords only need to be consistent when empty
+	MaxLineLength int      // DefaultMaxLineLength when 0
+	Disabled      []string // Rule codes not to check
+}
+
+// Violation is a rule broken at a position in the SQL
+type Violation struct {
+	Code     string `json:"code"` // e.g. LT05
+	Rule     string `json:"rule"` // e.g. layout.long_lines
+	Severity string `json:"severity"`
+	Line     int    `json:"line"`   // 1-based
+	Column   int    `json:"column"` // 1-based
+	Message  string `json:"message"`
+}
+
+var keywords = wordSet(`SELECT FROM WHERE JOIN LEFT RIGHT INNER OUTER FULL CROSS ON AS AND OR NOT NULL IS IN
+	CASE WHEN THEN ELSE END GROUP BY ORDER HAVING UNION ALL DISTINCT WITH LIMIT TOP OFFSET FETCH NEXT
+	ROWS ONLY INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE VIEW OVER PARTITION ASC DESC LIKE ILIKE
+	BETWEEN EXISTS QUALIFY CAST WINDOW USING LATERAL APPLY PIVOT UNPIVOT EXCEPT INTERSECT MINUS`)
+
+// tsqlFunctions are T-SQL functions other warehouses don't have, with what to use instead
+var tsqlFunctions = map[string]string{
+	"ISNULL":     "COALESCE",
+	"GETDATE":    "CURRENT_TIMESTAMP",
+	"GETUTCDATE": "CURRENT_TIMESTAMP",
+	"LEN":        "LENGTH",
+	"CHARINDEX":  "POSITION",
+	"NEWID":      "a UUID function of the target",
+	"DATALENGTH": "OCTET_LENGTH",
+}
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// Lint checks SQL against the rules that aren't disabled. Violations are ordered by
+// position.
+func Lint(sql string, opts Options) []Violation {
+	disabled := map[string]bool{}
+	for _, code := range opts.Style.Disabled {
+		disabled[strings.ToUpper(code)] = true
+	}
+	l := &linter{opts: opts, disabled: disabled, violations: []Violation{}}
+
+	l.lintLines(sql)
+	var tokens []token
+	for _, t := range tokenize(sql) {
+		if t.kind != tokenComment {
+			tokens = append(tokens, t)
+		}
+	}
+	l.lintKeywordCase(tokens)
+	l.lintReferences(tokens)
+	l.lintStatements(tokens)
+
+	sort.SliceStable(l.violations, func(i, j int) bool {
+		a, b := l.violations[i], l.violations[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.violations
+}
+
+type linter struct {
+	opts       Options
+	disabled   map[string]bool
+	violations []Violation
+}
+
+func (l *linter) add(code, severity string, line, column int, format string, args ...interface{}) {
+	if l.disabled[code] {
+		return
+	}
+	l.violations = append(l.violations, Violation{
+		Code: code, Rule: Rules[code], Severity: severity, Line: line, Column: column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// lintLines checks the layout of each line
+func (l *linter) lintLines(sql string) {
+	maxLength := l.opts.Style.MaxLineLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLineLength
+	}
+	for i, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		n := i + 1
+		if trimmed := strings.TrimRight(line, " \t"); trimmed != line {
+			l.add("LT01", SeverityWarning, n, utf8.RuneCountInString(trimmed)+1, "Trailing whitespace")
+		}
+		if indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]; strings.Contains(indent, "\t") {
+			l.add("LT02", SeverityWarning, n, 1, "Indent with spaces, not tabs")
+		}
+		if length := utf8.RuneCountInString(line); length > maxLength {
+			l.add("LT05", SeverityWarning, n, maxLength+1, "Line is %d characters, longer than %d", length, maxLength)
+		}
+	}
+}
+
+// lintKeywordCase checks that keywords use the style's case, or the case of the first
+// keyword when the style has none
+func (l *linter) lintKeywordCase(tokens []token) {
+	want := strings.ToLower(l.opts.Style.KeywordCase)
+	for _, t := range tokens {
+		if t.kind != tokenWord || !keywords[strings.ToUpper(t.text)] {
+			continue
+		}
+		got := "mixed"
+		switch t.text {
+		case strings.ToUpper(t.text):
+			got = "upper"
+		case strings.ToLower(t.text):
+			got = "lower"
+		}
+		if want == "" && got != "mixed" {
+			want = got
+			continue
+		}
+		if got != want {
+			expected := strings.ToUpper(t.text)
+			if want == "lower" {
+				expected = strings.ToLower(t.text)
+			}
+			l.add("CP01", SeverityWarning, t.line, t.column, "Keyword %s should be %s", t.text, expected)
+		}
+	}
+}
+
+// lintReferences checks what FROM and JOIN read from
+func (l *linter) lintReferences(tokens []token) {
+	ctes := map[string]bool{}
+	for i := 1; i+2 < len(tokens); i++ {
+		// WITH name AS ( or , name AS (
+		prev := tokens[i-1]
+		if (prev.is("WITH") || prev.text == ",") && tokens[i].kind == tokenWord && tokens[i+1].is("AS") && tokens[i+2].text == "(" {
+			ctes[strings.ToLower(tokens[i].text)] = true
+		}
+	}
+
+	for i, t := range tokens {
+		if !t.is("FROM") && !t.is("JOIN") {
+			continue
+		}
+		start := i + 1
+		end := start
+		// A relation is a Jinja call or dotted names; subqueries and functions are skipped
+		for end < len(tokens) {
+			part := tokens[end]
+			if part.kind != tokenWord && part.kind != tokenQuoted && part.kind != tokenJinja {
+				break
+			}
+			end++
+			if end < len(tokens) && tokens[end].text == "." {
+				end++
+				continue
+			}
+			break
+		}
+		if end == start || (end < len(tokens) && tokens[end].text == "(") {
+			continue
+		}
+		relation := tokens[start:end]
+
+		if l.opts.DBT && len(relation) > 0 && relation[0].kind != tokenJinja && !(len(relation) == 1 && ctes[strings.ToLower(relation[0].text)]) {
+			l.add("RF01", SeverityWarning, relation[0].line, relation[0].column,
+				"%s is hardcoded; select from {{ ref() }} or {{ source() }} so dbt tracks the dependency", joinTokens(relation))
+		}
+		if end < len(tokens) {
+			alias := tokens[end]
+			if alias.kind == tokenWord && !keywords[strings.ToUpper(alias.text)] && !isJoinWord(alias) {
+				l.add("AL01", SeverityWarning, alias.line, alias.column, "Alias %s implicitly; write AS %s", alias.text, alias.text)
+			}
+		}
+	}
+}
+
+func isJoinWord(t token) bool {
+	for _, w := range []string{"NATURAL", "OUTER", "FULL", "LEFT", "RIGHT", "INNER", "CROSS", "WITH", "TABLESAMPLE"} {
+		if t.is(w) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinTokens(tokens []token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+// lintStatements checks select lists, terminators and dialect constructs
+func (l *linter) lintStatements(tokens []token) {
+	for i, t := range tokens {
+		var prev, next token
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		if t.text == "*" && (prev.is("SELECT") || prev.is("DISTINCT") || prev.text == ",") {
+			l.add("AM04", SeverityWarning, t.line, t.column, "SELECT * makes the model's columns depend on its input; list them")
+		}
+		if t.text == ";" && l.opts.DBT {
+			l.add("CV06", SeverityError, t.line, t.column, "dbt models can't end statements with ;, dbt wraps them in its own")
+		}
+
+		if l.opts.TSQL {
+			switch {
+			case t.is("LIMIT"), t.is("ILIKE"), t.is("QUALIFY"):
+				l.add("DL02", SeverityError, t.line, t.column, "%s isn't supported by %s", strings.ToUpper(t.text), l.dialectName())
+			case t.text == "::":
+				l.add("DL02", SeverityError, t.line, t.column, ":: casts aren't supported by %s; use CAST(... AS ...)", l.dialectName())
+			case t.text == "||":
+				l.add("DL02", SeverityError, t.line, t.column, "|| isn't supported by %s; use CONCAT()", l.dialectName())
+			}
+			continue
+		}
+		switch {
+		case t.is("TOP") && (prev.is("SELECT") || prev.is("DISTINCT")):
+			l.add("DL01", SeverityError, t.line, t.column, "TOP is T-SQL; use LIMIT on %s", l.dialectName())
+		case t.kind == tokenQuoted && strings.HasPrefix(t.text, "["):
+			l.add("DL01", SeverityError, t.line, t.column, "%s is a T-SQL bracketed identifier; use double quotes on %s", t.text, l.dialectName())
+		case t.is("NOLOCK"):
+			l.add("DL01", SeverityError, t.line, t.column, "NOLOCK is a T-SQL table hint; remove it on %s", l.dialectName())
+		case t.kind == tokenWord && strings.HasPrefix(t.text, "@") && !strings.HasPrefix(t.text, "@@"):
+			l.add("DL01", SeverityError, t.line, t.column, "%s is a T-SQL variable; use a dbt var() or a literal on %s", t.text, l.dialectName())
+		case t.kind == tokenWord && next.text == "(":
+			if instead, ok := tsqlFunctions[strings.ToUpper(t.text)]; ok {
+				l.add("DL01", SeverityError, t.line, t.column, "%s() is T-SQL; use %s on %s", strings.ToUpper(t.text), instead, l.dialectName())
+			}
+		}
+	}
+}
+
+func (l *linter) dialectName() string {
+	if l.opts.Dialect == "" {
+		return "the target"
+	}
+	return l.opts.Dialect
+}
@@ -0,0 +1,97 @@
+package sqllint
+
+import (
+	"reflect"
+	"testing"
+)
+
+type found struct {
+	Code   string
+	Line   int
+	Column int
+}
+
+func codes(violations []Violation) []found {
+	got := []found{}
+	for _, v := range violations {
+		got = append(got, found{v.Code, v.Line, v.Column})
+	}
+	return got
+}
+
+func TestLintTSQLForSnowflake(t *testing.T) {
+	sql := "SELECT TOP 10 o.[OrderID], ISNULL(o.Status, 'new') AS status \n" +
+		"from Sales.Orders o WITH (NOLOCK)\n" +
+		"WHERE o.CustomerID = @customer"
+
+	got := codes(Lint(sql, Options{Dialect: "snowflake"}))
+	want := []found{
+		{"DL01", 1, 8},  // TOP
+		{"DL01", 1, 17}, // [OrderID]
+		{"DL01", 1, 28}, // ISNULL(
+		{"LT01", 1, 61}, // Trailing space
+		{"CP01", 2, 1},  // from
+		{"AL01", 2, 19}, // Implicit alias o
+		{"DL01", 2, 27}, // NOLOCK
+		{"DL01", 3, 22}, // @customer
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestLintDBTModelForFabric(t *testing.T) {
+	sql := `with orders as (
+    select * from {{ source('sales', 'orders') }}
+),
+
+customers as (
+    select customer_id, name from sales.customers
+)
+
+select o.order_id::int as order_id, c.name
+from orders as o
+join customers as c on c.customer_id = o.customer_id
+qualify row_number() over (partition by o.order_id order by o.updated_at desc) = 1;
+`
+	got := codes(Lint(sql, Options{Dialect: "fabric", TSQL: true, DBT: true}))
+	want := []found{
+		{"AM04", 2, 12}, // select *
+		{"RF01", 6, 35}, // sales.customers
+		{"DL02", 9, 18}, // ::
+		{"DL02", 12, 1}, // qualify
+		{"CV06", 12, 83},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestLintStyle(t *testing.T) {
+	sql := "SELECT id\n\tFROM {{ ref('stg_orders') }} -- " + "a comment that makes this line long"
+	got := codes(Lint(sql, Options{Dialect: "postgresql", DBT: true, Style: Style{
+		KeywordCase:   "lower",
+		MaxLineLength: 40,
+		Disabled:      []string{"lt02"},
+	}}))
+	want := []found{
+		{"CP01", 1, 1},
+		{"CP01", 2, 2},
+		{"LT05", 2, 41},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint = %v, want %v", got, want)
+	}
+}
+
+func TestTokenizeSkipsQuotedContent(t *testing.T) {
+	tokens := tokenize("select 'it''s -- not a comment', \"Order ID\" /* from x */ from t")
+	var kinds []tokenKind
+	for _, tk := range tokens {
+		kinds = append(kinds, tk.kind)
+	}
+	want := []tokenKind{tokenWord, tokenString, tokenPunct, tokenQuoted, tokenComment, tokenWord, tokenWord}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("kinds = %v, want %v", kinds, want)
+	}
+}
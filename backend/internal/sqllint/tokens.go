@@ -0,0 +1,127 @@
+package sqllint
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenWord    tokenKind = iota // Keyword, identifier or function name
+	tokenQuoted                   // "quoted", [bracketed] or `backticked` identifier
+	tokenString                   // 'string literal'
+	tokenNumber                   // Numeric literal
+	tokenJinja                    // {{ ... }}, {% ... %} or {# ... #}
+	tokenComment                  // -- line or /* block */ comment
+	tokenPunct                    // Operator or punctuation
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int // 1-based
+	column int // 1-based, in runes
+}
+
+func (t token) is(word string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, word)
+}
+
+// tokenize splits SQL into tokens, skipping whitespace. Unterminated strings, comments and
+// Jinja tags run to the end of the input.
+func tokenize(sql string) []token {
+	runes := []rune(sql)
+	var tokens []token
+	line, column := 1, 1
+	advance := func(n int) {
+		for _, r := range runes[:n] {
+			if r == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+		}
+		runes = runes[n:]
+	}
+	// until returns the length up to and including the first close after start, or the
+	// whole input when it isn't found
+	until := func(start int, close string) int {
+		c := []rune(close)
+		for i := start; i+len(c) <= len(runes); i++ {
+			if string(runes[i:i+len(c)]) == close {
+				return i + len(c)
+			}
+		}
+		return len(runes)
+	}
+
+	for len(runes) > 0 {
+		r := runes[0]
+		var next rune
+		if len(runes) > 1 {
+			next = runes[1]
+		}
+		kind, n := tokenPunct, 1
+		switch {
+		case unicode.IsSpace(r):
+			advance(1)
+			continue
+		case r == '-' && next == '-':
+			kind, n = tokenComment, 2
+			for n < len(runes) && runes[n] != '\n' {
+				n++
+			}
+		case r == '/' && next == '*':
+			kind, n = tokenComment, until(2, "*/")
+		case r == '{' && next == '{':
+			kind, n = tokenJinja, until(2, "}}")
+		case r == '{' && next == '%':
+			kind, n = tokenJinja, until(2, "%}")
+		case r == '{' && next == '#':
+			kind, n = tokenJinja, until(2, "#}")
+		case r == '\'':
+			kind, n = tokenString, quotedLength(runes, '\'')
+		case r == '"':
+			kind, n = tokenQuoted, quotedLength(runes, '"')
+		case r == '`':
+			kind, n = tokenQuoted, quotedLength(runes, '`')
+		case r == '[':
+			kind, n = tokenQuoted, quotedLength(runes, ']')
+		case unicode.IsDigit(r) || (r == '.' && unicode.IsDigit(next)):
+			kind, n = tokenNumber, 1
+			for n < len(runes) && (unicode.IsDigit(runes[n]) || runes[n] == '.') {
+				n++
+			}
+		case unicode.IsLetter(r) || r == '_' || r == '@' || r == '#':
+			kind, n = tokenWord, 1
+			for n < len(runes) && (unicode.IsLetter(runes[n]) || unicode.IsDigit(runes[n]) || runes[n] == '_' || runes[n] == '$' || runes[n] == '@' || runes[n] == '#') {
+				n++
+			}
+		case len(runes) > 1:
+			switch string(runes[:2]) {
+			case "::", "||", "<>", "!=", ">=", "<=":
+				n = 2
+			}
+		}
+		tokens = append(tokens, token{kind: kind, text: string(runes[:n]), line: line, column: column})
+		advance(n)
+	}
+	return tokens
+}
+
+// quotedLength is the length of a quoted token starting at runes[0], where a doubled
+// closing quote is an escaped one
+func quotedLength(runes []rune, close rune) int {
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == close {
+			if i+1 < len(runes) && runes[i+1] == close {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(runes)
+}
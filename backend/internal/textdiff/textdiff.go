@@ -0,0 +1,177 @@
+// Package textdiff renders line-based unified diffs of generated files
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCells bounds the LCS table. Larger inputs are diffed as a full replacement of the
+// lines between their common prefix and suffix.
+const maxCells = 1 << 22
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Stats counts the lines a diff adds and removes
+type Stats struct {
+	Additions int
+	Deletions int
+}
+
+// Unified returns the unified diff of a and b with the given number of context lines
+// around each change, or "" when they're equal. An empty fromName or toName is shown as
+// /dev/null, as for added and removed files.
+func Unified(fromName, toName, a, b string, context int) (string, Stats) {
+	if a == b {
+		return "", Stats{}
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var stats Stats
+	var changes []int
+	for i, o := range ops {
+		switch o.kind {
+		case '-':
+			stats.Deletions++
+			changes = append(changes, i)
+		case '+':
+			stats.Additions++
+			changes = append(changes, i)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", label(fromName), label(toName))
+
+	// Line numbers in a and b before each op
+	aPos := make([]int, len(ops)+1)
+	bPos := make([]int, len(ops)+1)
+	for i, o := range ops {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if o.kind != '+' {
+			aPos[i+1]++
+		}
+		if o.kind != '-' {
+			bPos[i+1]++
+		}
+	}
+
+	for i := 0; i < len(changes); {
+		first, last := changes[i], changes[i]
+		for i++; i < len(changes) && changes[i]-last <= 2*context+1; i++ {
+			last = changes[i]
+		}
+		start := max(0, first-context)
+		end := min(len(ops), last+1+context)
+
+		aCount, bCount := aPos[end]-aPos[start], bPos[end]-bPos[start]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aPos[start], aCount), hunkRange(bPos[start], bCount))
+		for _, o := range ops[start:end] {
+			sb.WriteByte(o.kind)
+			sb.WriteString(o.line)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String(), stats
+}
+
+func label(name string) string {
+	if name == "" {
+		return "/dev/null"
+	}
+	return name
+}
+
+// hunkRange formats the start,count of a hunk header. An empty range starts at the line
+// before it.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the edit script turning a into b, from their longest common
+// subsequence
+func diffLines(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+func diffMiddle(a, b []string) []op {
+	var ops []op
+	if (len(a)+1)*(len(b)+1) > maxCells {
+		for _, line := range a {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, op{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	width := len(b) + 1
+	lcs := make([]int, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
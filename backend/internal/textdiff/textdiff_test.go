@@ -0,0 +1,50 @@
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	a := "select\n    id,\n    name\nfrom customers\n"
+	b := "select\n    id,\n    full_name\nfrom customers\nwhere active\n"
+
+	diff, stats := Unified("a/stg_customers.sql", "b/stg_customers.sql", a, b, 1)
+	want := "--- a/stg_customers.sql\n+++ b/stg_customers.sql\n" +
+		"@@ -2,3 +2,4 @@\n" +
+		"     id,\n" +
+		"-    name\n" +
+		"+    full_name\n" +
+		" from customers\n" +
+		"+where active\n"
+	if diff != want {
+		t.Errorf("diff =\n%s\nwant\n%s", diff, want)
+	}
+	if stats.Additions != 2 || stats.Deletions != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n"
+	b := "1\nTWO\n3\n4\n5\n6\nSEVEN\n8\n"
+
+	diff, _ := Unified("a", "b", a, b, 1)
+	want := "--- a\n+++ b\n" +
+		"@@ -1,3 +1,3 @@\n 1\n-2\n+TWO\n 3\n" +
+		"@@ -6,3 +6,3 @@\n 6\n-7\n+SEVEN\n 8\n"
+	if diff != want {
+		t.Errorf("diff =\n%s\nwant\n%s", diff, want)
+	}
+}
+
+func TestUnifiedAddedFile(t *testing.T) {
+	diff, stats := Unified("", "b/new.sql", "", "select 1\n", 3)
+	want := "--- /dev/null\n+++ b/new.sql\n@@ -0,0 +1 @@\n+select 1\n"
+	if diff != want || stats.Additions != 1 {
+		t.Errorf("diff = %q, stats = %+v", diff, stats)
+	}
+}
+
+func TestUnifiedEqual(t *testing.T) {
+	if diff, _ := Unified("a", "b", "x\n", "x\n", 3); diff != "" {
+		t.Errorf("diff = %q", diff)
+	}
+}
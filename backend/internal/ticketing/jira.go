@@ -0,0 +1,41 @@
+package ticketing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// jira files issues through the Jira REST API v2, whose descriptions and comments are
+// plain text rather than Atlassian document format
+type jira struct {
+	*client
+}
+
+func (j *jira) Create(ctx context.Context, ticket Ticket) (Reference, error) {
+	var issue struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.cfg.Project},
+			"issuetype":   map[string]string{"name": j.cfg.IssueType},
+			"summary":     ticket.Summary,
+			"description": ticket.Description,
+		},
+	}, &issue)
+	if err != nil {
+		return Reference{}, err
+	}
+	if issue.Key == "" {
+		return Reference{}, fmt.Errorf("jira returned no issue key")
+	}
+	return Reference{Key: issue.Key, ID: issue.ID, URL: j.cfg.BaseURL + "/browse/" + url.PathEscape(issue.Key)}, nil
+}
+
+func (j *jira) Comment(ctx context.Context, ref Reference, text string) error {
+	return j.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(ref.Key)+"/comment",
+		map[string]string{"body": text}, nil)
+}
@@ -0,0 +1,49 @@
+package ticketing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// serviceNow files records in a ServiceNow table, change_request by default, through
+// the Table API
+type serviceNow struct {
+	*client
+}
+
+func (s *serviceNow) Create(ctx context.Context, ticket Ticket) (Reference, error) {
+	var resp struct {
+		Result struct {
+			SysID  string `json:"sys_id"`
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	err := s.do(ctx, http.MethodPost, "/api/now/table/"+s.cfg.Project, map[string]string{
+		"short_description": ticket.Summary,
+		"description":       ticket.Description,
+	}, &resp)
+	if err != nil {
+		return Reference{}, err
+	}
+	if resp.Result.SysID == "" {
+		return Reference{}, fmt.Errorf("servicenow returned no sys_id")
+	}
+	key := resp.Result.Number
+	if key == "" {
+		key = resp.Result.SysID // Tables without a number field
+	}
+	return Reference{Key: key, ID: resp.Result.SysID, URL: s.recordURL(resp.Result.SysID)}, nil
+}
+
+// Comment adds a work note, which is journaled on the record rather than replacing it
+func (s *serviceNow) Comment(ctx context.Context, ref Reference, text string) error {
+	return s.do(ctx, http.MethodPatch, "/api/now/table/"+s.cfg.Project+"/"+url.PathEscape(ref.ID),
+		map[string]string{"work_notes": text}, nil)
+}
+
+// recordURL opens the record inside the ServiceNow navigation frame
+func (s *serviceNow) recordURL(sysID string) string {
+	return s.cfg.BaseURL + "/nav_to.do?uri=" + url.QueryEscape(s.cfg.Project+".do?sys_id="+sysID)
+}
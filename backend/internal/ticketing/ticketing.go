@@ -0,0 +1,173 @@
+// Package ticketing opens and updates the change tickets that track migrations in an
+// organization's Jira or ServiceNow instance
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Supported ticketing providers
+const (
+	ProviderJira       = "jira"
+	ProviderServiceNow = "servicenow"
+)
+
+// Migration events a ticket can be created or updated on
+const (
+	EventStarted   = "started"
+	EventCompleted = "completed"
+	EventFailed    = "failed"
+)
+
+// Events lists the migration events, in the order they happen
+var Events = []string{EventStarted, EventCompleted, EventFailed}
+
+// Defaults for the optional settings
+const (
+	DefaultJiraIssueType   = "Task"
+	DefaultServiceNowTable = "change_request"
+	maxErrorBodyBytes      = 500
+	defaultRequestTimeout  = 15 * time.Second
+)
+
+var (
+	jiraProjectRegex     = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,9}$`)
+	serviceNowTableRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,79}$`)
+)
+
+// Config is an organization's ticketing instance and where tickets are filed in it
+type Config struct {
+	Provider string
+	BaseURL  string // e.g. https://contoso.atlassian.net or https://contoso.service-now.com
+	Username string // Jira account email or ServiceNow user
+	APIToken string // Jira API token or ServiceNow password
+	// Project is the Jira project key, or the ServiceNow table (change_request by default)
+	Project string
+	// IssueType is the Jira issue type (Task by default); unused for ServiceNow
+	IssueType string
+}
+
+// Normalize trims the config and fills in defaults
+func (c *Config) Normalize() {
+	c.Provider = strings.ToLower(strings.TrimSpace(c.Provider))
+	c.BaseURL = strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	c.Username = strings.TrimSpace(c.Username)
+	c.Project = strings.TrimSpace(c.Project)
+	c.IssueType = strings.TrimSpace(c.IssueType)
+	switch c.Provider {
+	case ProviderJira:
+		c.Project = strings.ToUpper(c.Project)
+		if c.IssueType == "" {
+			c.IssueType = DefaultJiraIssueType
+		}
+	case ProviderServiceNow:
+		if c.Project == "" {
+			c.Project = DefaultServiceNowTable
+		}
+		c.IssueType = ""
+	}
+}
+
+// Validate checks a normalized config, returning one message per problem
+func (c Config) Validate() []string {
+	var details []string
+	if c.Provider != ProviderJira && c.Provider != ProviderServiceNow {
+		details = append(details, "provider must be jira or servicenow")
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme != "https" || u.Host == "" || u.RawQuery != "" {
+		details = append(details, "base_url must be an https URL")
+	}
+	if c.Username == "" {
+		details = append(details, "username is required")
+	}
+	switch c.Provider {
+	case ProviderJira:
+		if !jiraProjectRegex.MatchString(c.Project) {
+			details = append(details, "project must be a Jira project key such as DATA")
+		}
+	case ProviderServiceNow:
+		if !serviceNowTableRegex.MatchString(c.Project) {
+			details = append(details, "project must be a ServiceNow table such as change_request")
+		}
+	}
+	return details
+}
+
+// Ticket is what a migration's ticket is opened with
+type Ticket struct {
+	Summary     string
+	Description string
+}
+
+// Reference identifies a ticket that was opened
+type Reference struct {
+	Key string // Human-readable key: DATA-123 or CHG0030001
+	ID  string // Jira issue ID or ServiceNow sys_id
+	URL string // Where the ticket is viewed
+}
+
+// Tracker opens tickets and adds updates to them
+type Tracker interface {
+	Create(ctx context.Context, ticket Ticket) (Reference, error)
+	// Comment adds an update to a ticket: a Jira comment or a ServiceNow work note
+	Comment(ctx context.Context, ref Reference, text string) error
+}
+
+// New returns the tracker of a validated config
+func New(cfg Config) (Tracker, error) {
+	c := &client{cfg: cfg, httpClient: &http.Client{Timeout: defaultRequestTimeout}}
+	switch cfg.Provider {
+	case ProviderJira:
+		return &jira{c}, nil
+	case ProviderServiceNow:
+		return &serviceNow{c}, nil
+	}
+	return nil, fmt.Errorf("unsupported ticketing provider %q", cfg.Provider)
+}
+
+// client sends JSON requests with basic auth, which both providers accept for API tokens
+type client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.cfg.Username, c.cfg.APIToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", c.cfg.Provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return fmt.Errorf("%s API error (status %d): %s", c.cfg.Provider, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.cfg.Provider, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package ticketing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// recorded is a request a fake instance received
+type recorded struct {
+	Method, Path, User string
+	Body               map[string]interface{}
+}
+
+func fakeInstance(t *testing.T, responses map[string]string) (*httptest.Server, *[]recorded) {
+	t.Helper()
+	var requests []recorded
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		req := recorded{Method: r.Method, Path: r.URL.Path, User: user}
+		json.NewDecoder(r.Body).Decode(&req.Body)
+		requests = append(requests, req)
+		body, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			http.Error(w, `{"errorMessages":["not found"]}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := Config{Provider: " Jira ", BaseURL: "https://contoso.atlassian.net/", Username: "ops@contoso.com", Project: "data"}
+	cfg.Normalize()
+	if details := cfg.Validate(); len(details) > 0 {
+		t.Fatalf("details = %v", details)
+	}
+	if cfg.BaseURL != "https://contoso.atlassian.net" || cfg.Project != "DATA" || cfg.IssueType != DefaultJiraIssueType {
+		t.Errorf("normalized = %+v", cfg)
+	}
+
+	cfg = Config{Provider: "servicenow", BaseURL: "http://contoso.service-now.com", Project: "Change Request"}
+	cfg.Normalize()
+	want := []string{
+		"base_url must be an https URL",
+		"username is required",
+		"project must be a ServiceNow table such as change_request",
+	}
+	if details := cfg.Validate(); !reflect.DeepEqual(details, want) {
+		t.Errorf("details = %q", details)
+	}
+}
+
+func TestJira(t *testing.T) {
+	srv, requests := fakeInstance(t, map[string]string{
+		"POST /rest/api/2/issue":                `{"id":"10042","key":"DATA-7"}`,
+		"POST /rest/api/2/issue/DATA-7/comment": `{"id":"1"}`,
+	})
+	cfg := Config{Provider: ProviderJira, BaseURL: srv.URL, Username: "ops@contoso.com", APIToken: "token", Project: "DATA"}
+	cfg.Normalize()
+	tracker, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := tracker.Create(context.Background(), Ticket{Summary: "Migration started", Description: "details"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != (Reference{Key: "DATA-7", ID: "10042", URL: srv.URL + "/browse/DATA-7"}) {
+		t.Errorf("ref = %+v", ref)
+	}
+	if err := tracker.Comment(context.Background(), ref, "Migration completed"); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := (*requests)[0].Body["fields"].(map[string]interface{})
+	if fields["summary"] != "Migration started" || fields["issuetype"].(map[string]interface{})["name"] != "Task" ||
+		fields["project"].(map[string]interface{})["key"] != "DATA" || (*requests)[0].User != "ops@contoso.com" {
+		t.Errorf("create request = %+v", (*requests)[0])
+	}
+	if (*requests)[1].Body["body"] != "Migration completed" {
+		t.Errorf("comment request = %+v", (*requests)[1])
+	}
+
+	if err := tracker.Comment(context.Background(), Reference{Key: "DATA-8"}, "x"); err == nil {
+		t.Error("comment on a missing issue succeeded")
+	}
+}
+
+func TestServiceNow(t *testing.T) {
+	srv, requests := fakeInstance(t, map[string]string{
+		"POST /api/now/table/change_request":       `{"result":{"sys_id":"a1b2","number":"CHG0030001"}}`,
+		"PATCH /api/now/table/change_request/a1b2": `{"result":{}}`,
+	})
+	cfg := Config{Provider: ProviderServiceNow, BaseURL: srv.URL, Username: "svc_datamigrate", APIToken: "secret"}
+	cfg.Normalize()
+	tracker, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := tracker.Create(context.Background(), Ticket{Summary: "Migration started", Description: "details"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Reference{Key: "CHG0030001", ID: "a1b2", URL: srv.URL + "/nav_to.do?uri=change_request.do%3Fsys_id%3Da1b2"}
+	if ref != want {
+		t.Errorf("ref = %+v", ref)
+	}
+	if err := tracker.Comment(context.Background(), ref, "Migration failed"); err != nil {
+		t.Fatal(err)
+	}
+	if (*requests)[0].Body["short_description"] != "Migration started" || (*requests)[1].Body["work_notes"] != "Migration failed" {
+		t.Errorf("requests = %+v", *requests)
+	}
+}
@@ -62,9 +62,16 @@ var ValidDBTypes = map[string]bool{
 	"databricks": true,
 	"redshift":   true,
 	"fabric":     true,
+	"synapse":    true,
 	"spark":      true,
 }
 
+// Host suffixes of the Azure T-SQL warehouse endpoints
+const (
+	FabricHostSuffix  = ".datawarehouse.fabric.microsoft.com"
+	SynapseHostSuffix = ".sql.azuresynapse.net"
+)
+
 // Reserved or dangerous names that shouldn't be allowed
 var reservedNames = []string{
 	"admin", "root", "system", "null", "undefined",
@@ -82,6 +89,9 @@ var (
 	// Username: alphanumeric, underscore, hyphen, at, period, backslash (for domain\user)
 	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-@.\\]+$`)
 
+	// Entra ID tenant and client IDs
+	guidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 	// Host: valid hostname or IP address patterns
 	hostRegex = regexp.MustCompile(`^[a-zA-Z0-9\-_.]+$`)
 
@@ -366,8 +376,46 @@ func (v *ConnectionValidator) ValidateConnection(name, dbType, host string, port
 	return result
 }
 
+// ValidateAzureWarehouse validates Fabric and Synapse connections. Fabric only accepts
+// Entra ID, so it needs a service principal: tenant ID, client ID (username) and secret (password).
+func (v *ConnectionValidator) ValidateAzureWarehouse(dbType, host, username, password, tenantID, workspace string) *ValidationResult {
+	result := NewValidationResult()
+	host = strings.ToLower(host)
+
+	switch dbType {
+	case "fabric":
+		if !strings.HasSuffix(host, FabricHostSuffix) {
+			result.AddError("host", "Host must be the warehouse SQL connection string (*"+FabricHostSuffix+")")
+		}
+		if !guidRegex.MatchString(tenantID) {
+			result.AddError("extra_config.tenant_id", "Tenant ID must be a GUID")
+		}
+		if !guidRegex.MatchString(username) {
+			result.AddError("username", "Username must be the service principal's client ID")
+		}
+		if password == "" {
+			result.AddError("password", "Password must be the service principal's client secret")
+		}
+		if strings.TrimSpace(workspace) == "" {
+			result.AddError("extra_config.workspace", "Workspace is required")
+		}
+	case "synapse":
+		if !strings.HasSuffix(host, SynapseHostSuffix) {
+			result.AddError("host", "Host must be the dedicated SQL pool endpoint (*"+SynapseHostSuffix+")")
+		}
+	}
+
+	return result
+}
+
 // containsSQLInjection checks if the input contains SQL injection patterns
 func (v *ConnectionValidator) containsSQLInjection(input string) bool {
+	return ContainsSQLInjection(input)
+}
+
+// ContainsSQLInjection checks if user-supplied SQL contains SQL injection patterns:
+// statements, UNION SELECT, tautologies, trailing comments and timing functions
+func ContainsSQLInjection(input string) bool {
 	for _, pattern := range sqlInjectionPatterns {
 		if pattern.MatchString(input) {
 			return true
@@ -88,7 +88,7 @@ class ApiService {
 
   // Auth endpoints
   async login(email: string, password: string) {
-    const response = await this.request<{ access_token: string; user: any }>('/auth/login', {
+    const response = await this.request<{ access_token: string; user: any; password_expired?: boolean }>('/auth/login', {
       method: 'POST',
       body: { email, password },
     })
@@ -42,6 +42,8 @@ export const useAuthStore = defineStore('auth', () => {
   const loading = ref(false)
   const error = ref<string | null>(null)
   const initialized = ref(false)
+  // The session can only change the password until it signs in again with a new one
+  const passwordExpired = ref(false)
 
   // Getters
   const isAuthenticated = computed(() => !!token.value)
@@ -75,6 +77,7 @@ export const useAuthStore = defineStore('auth', () => {
         const response = await api.login(credentials.email, credentials.password)
         token.value = response.access_token
         user.value = response.user
+        passwordExpired.value = !!response.password_expired
         return true
       }
     } catch (err: any) {
@@ -129,6 +132,7 @@ export const useAuthStore = defineStore('auth', () => {
     } finally {
       token.value = null
       user.value = null
+      passwordExpired.value = false
       localStorage.removeItem('access_token')
     }
   }
@@ -166,6 +170,7 @@ export const useAuthStore = defineStore('auth', () => {
     loading,
     error,
     initialized,
+    passwordExpired,
     isAuthenticated,
     isAdmin,
     login,
@@ -187,6 +187,13 @@ resource "docker_container" "prometheus" {
     read_only      = true
   }
 
+  # Mount alerting rules (rules/datamigrate-slo.yml comes from GET /metrics/alerts)
+  volumes {
+    host_path      = abspath("${path.module}/rules")
+    container_path = "/etc/prometheus/rules"
+    read_only      = true
+  }
+
   networks_advanced {
     name = docker_network.datamigrate.name
   }